@@ -71,6 +71,16 @@ type ClusterSpec struct {
 	// this feature is highly experimental, and parts of it might still be not implemented.
 	// +optional
 	Topology *Topology `json:"topology,omitempty"`
+
+	// MetadataPropagation defines labels and annotations continuously propagated from the Cluster to all
+	// the objects of the Cluster: the ControlPlane, MachineDeployments, MachineSets, MachinePools, Machines,
+	// the infrastructure and bootstrap objects they reference, and Nodes.
+	// Labels and annotations removed from MetadataPropagation are removed from those objects; in case of conflicts,
+	// the values defined in MetadataPropagation take precedence over the ones defined on the objects or their templates.
+	// Keys in the cluster.x-k8s.io, kubernetes.io and k8s.io domains are not allowed, except for the node.cluster.x-k8s.io
+	// domain; only the labels in the node.cluster.x-k8s.io domain are propagated to Nodes.
+	// +optional
+	MetadataPropagation *ObjectMeta `json:"metadataPropagation,omitempty"`
 }
 
 // Topology encapsulates the information of the managed resources.
@@ -103,6 +113,42 @@ type Topology struct {
 	// VariableClasses defined in the ClusterClass.
 	// +optional
 	Variables []ClusterVariable `json:"variables,omitempty"`
+
+	// Rollout defines constraints on the rollouts of the Cluster, i.e. the operations replacing its Machines.
+	// +optional
+	Rollout *TopologyRollout `json:"rollout,omitempty"`
+}
+
+// TopologyRollout defines constraints on the rollouts of a Cluster with a managed topology.
+type TopologyRollout struct {
+	// Windows are the rollout windows during which the topology controller, the control plane and the
+	// MachineDeployments are allowed to start machine-replacing operations, e.g. upgrades.
+	// Changes made outside the windows are accepted, but their rollout is deferred until the next window starts;
+	// rollouts already started when a window ends are completed.
+	// If empty, rollouts can be started at any time.
+	// +optional
+	// +kubebuilder:validation:MaxItems=32
+	Windows []RolloutWindow `json:"windows,omitempty"`
+}
+
+// RolloutWindow is a recurring time window during which rollouts can be started.
+type RolloutWindow struct {
+	// Schedule is the start of the window in the cron format, with five space-separated fields:
+	// minute, hour, day of month, month and day of week, e.g. "0 22 * * 1-5" for 22:00 from Monday to Friday.
+	// Each field supports "*", values, ranges, e.g. "1-5", lists, e.g. "1,3,5", and steps, e.g. "*/15".
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=256
+	Schedule string `json:"schedule"`
+
+	// Duration is how long the window stays open after each start defined by the schedule, e.g. "4h".
+	Duration metav1.Duration `json:"duration"`
+
+	// TimeZone is the name of the time zone of the schedule in the IANA time zone database, e.g. "Europe/Rome".
+	// If not set, UTC is used.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=256
+	TimeZone *string `json:"timeZone,omitempty"`
 }
 
 // ControlPlaneTopology specifies the parameters for the control plane nodes in the cluster.
@@ -29,6 +29,11 @@ const (
 	// external objects(bootstrap and infrastructure providers).
 	ClusterNameLabel = "cluster.x-k8s.io/cluster-name"
 
+	// ClusterShardLabel is the label that can be applied to Cluster objects to explicitly assign the Cluster,
+	// and all the objects belonging to it, to a shard when controllers are sharded; the value is the shard index.
+	// Clusters without this label are assigned to a shard using a hash of their namespace and name.
+	ClusterShardLabel = "cluster.x-k8s.io/shard"
+
 	// ClusterTopologyOwnedLabel is the label set on all the object which are managed as part of a ClusterTopology.
 	ClusterTopologyOwnedLabel = "topology.cluster.x-k8s.io/owned"
 
@@ -92,6 +97,14 @@ const (
 	// LabelsFromMachineAnnotation is the annotation set on nodes to track the labels originated from machines.
 	LabelsFromMachineAnnotation = "cluster.x-k8s.io/labels-from-machine"
 
+	// LabelsFromClusterAnnotation is the annotation set on the objects of a Cluster to track the labels
+	// propagated from the Cluster's spec.metadataPropagation.
+	LabelsFromClusterAnnotation = "cluster.x-k8s.io/labels-from-cluster"
+
+	// AnnotationsFromClusterAnnotation is the annotation set on the objects of a Cluster to track the annotations
+	// propagated from the Cluster's spec.metadataPropagation.
+	AnnotationsFromClusterAnnotation = "cluster.x-k8s.io/annotations-from-cluster"
+
 	// OwnerNameAnnotation is the annotation set on nodes identifying the owner name.
 	OwnerNameAnnotation = "cluster.x-k8s.io/owner-name"
 
@@ -102,6 +115,35 @@ const (
 	// on the reconciled object.
 	PausedAnnotation = "cluster.x-k8s.io/paused"
 
+	// PausedAnnotationPrefix is the prefix of the annotations that can be applied to any Cluster API object
+	// to record why a specific actor paused the reconciliation of a resource, e.g. `paused.cluster.x-k8s.io/clusterctl: move`.
+	//
+	// Each actor owns its own annotation, and reconciliation resumes only when all of them have been removed
+	// together with the PausedAnnotation.
+	PausedAnnotationPrefix = "paused.cluster.x-k8s.io/"
+
+	// CorrelationIDAnnotation is an annotation that can be applied to Cluster objects to override the correlation ID
+	// added to the logs of all the controllers reconciling the Cluster and the objects belonging to it,
+	// e.g. to follow a specific upgrade using an ID defined by an external tool.
+	CorrelationIDAnnotation = "cluster.x-k8s.io/correlation-id"
+
+	// ReconcileConcurrencyAnnotation is an annotation that can be applied to Cluster objects to override the maximum
+	// number of objects belonging to the Cluster which are reconciled concurrently, e.g. "2"; "0" means unlimited.
+	ReconcileConcurrencyAnnotation = "cluster.x-k8s.io/reconcile-concurrency"
+
+	// ReconcileQPSAnnotation is an annotation that can be applied to Cluster objects to override the maximum
+	// number of reconciles per second of the objects belonging to the Cluster, e.g. "0.5"; "0" means unlimited.
+	ReconcileQPSAnnotation = "cluster.x-k8s.io/reconcile-qps"
+
+	// WorkloadClusterAuthAnnotation is an annotation that can be applied to Cluster objects to make the management
+	// cluster authenticate to the workload cluster using an exec credential plugin ("exec") or an OIDC token exchange
+	// ("oidc-token-exchange") configured in the <cluster>-auth Secret, instead of the credentials in the kubeconfig Secret.
+	// The <cluster>-auth Secret must have the cluster.x-k8s.io/cluster-name label, given that the managers only cache the
+	// Secrets with this label; the kubeconfig Secrets generated by Cluster API for such Clusters have no client certificate.
+	// Only the exec credential plugins, token exchange endpoints and subject token files allowed by the operator with the
+	// --workload-cluster-auth-* flags of the managers can be used.
+	WorkloadClusterAuthAnnotation = "cluster.x-k8s.io/workload-cluster-auth"
+
 	// DisableMachineCreateAnnotation is an annotation that can be used to signal a MachineSet to stop creating new machines.
 	// It is utilized in the OnDelete MachineDeploymentStrategy to allow the MachineDeployment controller to scale down
 	// older MachineSets when Machines are deleted and add the new replicas to the latest MachineSet.
@@ -117,6 +159,34 @@ const (
 	// when KCP or a machineset scales down. This annotation is given top priority on all delete policies.
 	DeleteMachineAnnotation = "cluster.x-k8s.io/delete-machine"
 
+	// DecisionActionAnnotation is the annotation set by Cluster API controllers on a Machine when they decide to
+	// execute a destructive action on it, e.g. delete, remediate or roll out the Machine; it records the action.
+	DecisionActionAnnotation = "audit.cluster.x-k8s.io/action"
+
+	// DecisionReasonAnnotation is the annotation recording why the action in the DecisionActionAnnotation was decided.
+	DecisionReasonAnnotation = "audit.cluster.x-k8s.io/reason"
+
+	// DecisionControllerAnnotation is the annotation recording the controller which decided the action
+	// in the DecisionActionAnnotation.
+	DecisionControllerAnnotation = "audit.cluster.x-k8s.io/decided-by"
+
+	// DecisionTimestampAnnotation is the annotation recording when the action in the DecisionActionAnnotation
+	// was decided, in RFC3339 format.
+	DecisionTimestampAnnotation = "audit.cluster.x-k8s.io/decided-at"
+
+	// DecisionConditionsAnnotation is the annotation recording, as JSON, a snapshot of the Machine conditions
+	// which were not true when the action in the DecisionActionAnnotation was decided.
+	DecisionConditionsAnnotation = "audit.cluster.x-k8s.io/conditions"
+
+	// ConditionsTransitionHistoryAnnotation is the annotation recording, as JSON, the last transitions of the conditions
+	// of an object for which transition history tracking is enabled; see the util/conditions/v1beta2 package.
+	ConditionsTransitionHistoryAnnotation = "cluster.x-k8s.io/conditions-transition-history"
+
+	// MirroredConditionsSourceAnnotation is the annotation recording, as JSON, the generation of the source object
+	// each mirrored condition of an object refers to, for the conditions mirrored with the RecordMirrorSource option;
+	// see the util/conditions/v1beta2 package.
+	MirroredConditionsSourceAnnotation = "cluster.x-k8s.io/mirrored-conditions-source"
+
 	// TemplateClonedFromNameAnnotation is the infrastructure machine annotation that stores the name of the infrastructure template resource
 	// that was cloned for the machine. This annotation is set only during cloning a template. Older/adopted machines will not have this annotation.
 	TemplateClonedFromNameAnnotation = "cluster.x-k8s.io/cloned-from-name"
@@ -194,6 +264,27 @@ const (
 	// Note: It can be used by setting as top level annotation on MachineDeployment and MachineSets.
 	AutoscalerMaxSizeAnnotation = "cluster.x-k8s.io/cluster-api-autoscaler-node-group-max-size"
 
+	// AutoscalerCapacityLabelsAnnotation defines the labels of the Nodes of a node group when scaling from zero,
+	// as a comma separated list of key=value pairs.
+	// The annotation is used by the autoscaler.
+	// The annotation definition is copied from kubernetes/autoscaler.
+	// Ref:https://github.com/kubernetes/autoscaler/blob/d8336cca37dbfa5d1cb7b7e453bd511172d6e5e7/cluster-autoscaler/cloudprovider/clusterapi/clusterapi_unstructured.go#L45
+	// Note: It is set to the architecture of the Machines, if not already set, by the MachineDeployment controller on
+	// MachineDeployments, and by the MachineSet controller on MachineSets not owned by a MachineDeployment.
+	AutoscalerCapacityLabelsAnnotation = "capacity.cluster-autoscaler.kubernetes.io/labels"
+
+	// MachineArchitectureAnnotation is the CPU architecture of Machines, using the GOARCH values, e.g. amd64 or arm64.
+	// It can be set on MachineDeployments or MachineSets (in spec.template.metadata.annotations or in the top level
+	// annotations) and on KubeadmControlPlanes; if not set, it is propagated from status.nodeInfo.architecture of
+	// the infrastructure machine template, if reported by the infrastructure provider.
+	// The annotation is propagated to the Machines and used to resolve the images deployed to them, e.g.
+	// with per-architecture image mirrors.
+	// Note: This is an annotation and not a field because it is a hint observed from the infrastructure provider
+	// rather than desired state: it must be settable on MachineDeployments, MachineSets, KubeadmControlPlanes and
+	// Machines alike without API changes to all of them, and changing it must not trigger a rollout, like changes to
+	// the annotations in the Machine template.
+	MachineArchitectureAnnotation = "cluster.x-k8s.io/architecture"
+
 	// VariableDefinitionFromInline indicates a patch or variable was defined in the `.spec` of a ClusterClass
 	// rather than from an external patch extension.
 	VariableDefinitionFromInline = "inline"
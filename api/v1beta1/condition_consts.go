@@ -102,6 +102,27 @@ const (
 	// NOTE: Having the control plane machine available is a pre-condition for joining additional control planes
 	// or workers nodes.
 	WaitingForControlPlaneAvailableReason = "WaitingForControlPlaneAvailable"
+
+	// ClusterAcceptedCondition reports if a new Cluster has been accepted for provisioning. This condition is set to false
+	// only while a new Cluster is not accepted because of the safeguards against the runaway growth of the caches of the
+	// controllers, and it is removed as soon as the Cluster is accepted.
+	ClusterAcceptedCondition ConditionType = "Accepted"
+
+	// CacheObjectLimitExceededReason (Severity=Warning) documents a new Cluster not accepted for provisioning because
+	// the caches of the controllers hold more objects of a type than allowed.
+	CacheObjectLimitExceededReason = "CacheObjectLimitExceeded"
+
+	// KubeconfigCredentialsRotatedCondition reports if the client certificates in the kubeconfig Secrets generated by
+	// Cluster API for a Cluster are rotated before they expire; it is set only if such Secrets exist.
+	KubeconfigCredentialsRotatedCondition ConditionType = "KubeconfigCredentialsRotated"
+
+	// KubeconfigCredentialsVerificationFailedReason (Severity=Warning) documents a Cluster whose kubeconfig credentials
+	// have not been rotated because the new credentials could not be used to access the workload cluster.
+	KubeconfigCredentialsVerificationFailedReason = "VerificationFailed"
+
+	// KubeconfigCredentialsRotationFailedReason (Severity=Warning) documents a Cluster whose kubeconfig credentials
+	// have not been rotated because of an error, e.g. the Cluster CA is not available.
+	KubeconfigCredentialsRotationFailedReason = "RotationFailed"
 )
 
 // Conditions and condition Reasons for the Machine object.
@@ -216,6 +237,18 @@ const (
 	// This reason is used when the Machine controller is unable to list Nodes to find
 	// the corresponding Node for a Machine by ProviderID.
 	NodeInspectionFailedReason = "NodeInspectionFailed"
+
+	// NodeAdoptionFailedReason (Severity=Error) documents a Machine failing to adopt the Node set in the
+	// machine.cluster.x-k8s.io/adopt-node annotation, e.g. because the Node has a different provider ID.
+	NodeAdoptionFailedReason = "NodeAdoptionFailed"
+
+	// WaitingForNodeIdentityVerificationReason (Severity=Info) documents a Machine waiting for the VerifyNodeIdentity
+	// Runtime Extensions to verify the identity of the Node before associating it to the Machine.
+	WaitingForNodeIdentityVerificationReason = "WaitingForNodeIdentityVerification"
+
+	// NodeIdentityVerificationFailedReason (Severity=Error) documents a Machine whose Node has been rejected, or could
+	// not be verified, by the VerifyNodeIdentity Runtime Extensions; the Node is not associated to the Machine.
+	NodeIdentityVerificationFailedReason = "NodeIdentityVerificationFailed"
 )
 
 // Conditions and condition Reasons for the MachineHealthCheck object.
@@ -237,6 +270,15 @@ const (
 	// machines required (i.e. Spec.Replicas-MaxUnavailable when MachineDeploymentStrategyType = RollingUpdate) are up and running for at least minReadySeconds.
 	MachineDeploymentAvailableCondition ConditionType = "Available"
 
+	// MachineDeploymentRolloutAllowedCondition documents whether the MachineDeployment is allowed to start a rollout
+	// according to the rollout windows defined in the Cluster topology.
+	// NOTE: This condition is set only for MachineDeployments of Clusters defining rollout windows.
+	MachineDeploymentRolloutAllowedCondition ConditionType = "RolloutAllowed"
+
+	// RolloutDeferredReason (Severity=Info) documents a rollout deferred until the next rollout window
+	// defined in the Cluster topology starts.
+	RolloutDeferredReason = "RolloutDeferred"
+
 	// MachineSetReadyCondition reports a summary of current status of the MachineSet owned by the MachineDeployment.
 	MachineSetReadyCondition ConditionType = "MachineSetReady"
 
@@ -329,6 +371,10 @@ const (
 	// not yet completed because the upgrade for at least one of the MachinePools has been deferred.
 	TopologyReconciledMachinePoolsUpgradeDeferredReason = "MachinePoolsUpgradeDeferred"
 
+	// TopologyReconciledRolloutDeferredReason (Severity=Info) documents reconciliation of a Cluster topology
+	// not yet completed because the upgrade of the Cluster has been deferred until the next rollout window starts.
+	TopologyReconciledRolloutDeferredReason = "RolloutDeferred"
+
 	// TopologyReconciledHookBlockingReason (Severity=Info) documents reconciliation of a Cluster topology
 	// not yet completed because at least one of the lifecycle hooks is blocking.
 	TopologyReconciledHookBlockingReason = "LifecycleHookBlocking"
@@ -35,6 +35,11 @@ const (
 	// MachineProviderIDField is used to index Machines by ProviderID. It's useful to find Machines
 	// in a management cluster from Nodes in a workload cluster.
 	MachineProviderIDField = "spec.providerID"
+
+	// MachineClusterNodeField is used to index Machines by Cluster and by the name and the ProviderID of their Node.
+	// It's useful to find the Machine of a Node in a workload cluster with a single lookup, also when Nodes
+	// in different workload clusters have the same name or ProviderID.
+	MachineClusterNodeField = "cluster.node"
 )
 
 // ByMachineNode adds the machine node name index to the
@@ -89,3 +94,46 @@ func machineByProviderID(o client.Object) []string {
 
 	return []string{providerID}
 }
+
+// ByMachineClusterNode adds the machine cluster node index to the
+// managers cache.
+func ByMachineClusterNode(ctx context.Context, mgr ctrl.Manager) error {
+	if err := mgr.GetCache().IndexField(ctx, &clusterv1.Machine{},
+		MachineClusterNodeField,
+		MachineByClusterNode,
+	); err != nil {
+		return errors.Wrap(err, "error setting index field")
+	}
+
+	return nil
+}
+
+// MachineByClusterNode contains the logic to index Machines by Cluster and by the name and the ProviderID of their Node.
+func MachineByClusterNode(o client.Object) []string {
+	machine, ok := o.(*clusterv1.Machine)
+	if !ok {
+		panic(fmt.Sprintf("Expected a Machine but got a %T", o))
+	}
+
+	cluster := client.ObjectKey{Namespace: machine.Namespace, Name: machine.Spec.ClusterName}
+	var keys []string
+	if machine.Status.NodeRef != nil {
+		keys = append(keys, ClusterNodeNameIndexKey(cluster, machine.Status.NodeRef.Name))
+	}
+	if providerID := ptr.Deref(machine.Spec.ProviderID, ""); providerID != "" {
+		keys = append(keys, ClusterNodeProviderIDIndexKey(cluster, providerID))
+	}
+	return keys
+}
+
+// ClusterNodeNameIndexKey returns the MachineClusterNodeField index key of the Machine
+// with a Node with the given name in the given Cluster.
+func ClusterNodeNameIndexKey(cluster client.ObjectKey, nodeName string) string {
+	return fmt.Sprintf("%s/name/%s", cluster, nodeName)
+}
+
+// ClusterNodeProviderIDIndexKey returns the MachineClusterNodeField index key of the Machine
+// with a Node with the given ProviderID in the given Cluster.
+func ClusterNodeProviderIDIndexKey(cluster client.ObjectKey, providerID string) string {
+	return fmt.Sprintf("%s/providerID/%s", cluster, providerID)
+}
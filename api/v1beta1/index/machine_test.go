@@ -21,6 +21,7 @@ import (
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -101,3 +102,46 @@ func TestIndexMachineByProviderID(t *testing.T) {
 		})
 	}
 }
+
+func TestIndexMachineByClusterNode(t *testing.T) {
+	cluster := client.ObjectKey{Namespace: "ns", Name: "cluster"}
+
+	testCases := []struct {
+		name     string
+		object   client.Object
+		expected []string
+	}{
+		{
+			name:     "when the machine has neither a NodeRef nor a providerID",
+			object:   &clusterv1.Machine{},
+			expected: []string{},
+		},
+		{
+			name: "when the machine has a NodeRef and a providerID",
+			object: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns"},
+				Spec: clusterv1.MachineSpec{
+					ClusterName: "cluster",
+					ProviderID:  ptr.To("aws://region/zone/id"),
+				},
+				Status: clusterv1.MachineStatus{
+					NodeRef: &corev1.ObjectReference{
+						Name: "node1",
+					},
+				},
+			},
+			expected: []string{
+				ClusterNodeNameIndexKey(cluster, "node1"),
+				ClusterNodeProviderIDIndexKey(cluster, "aws://region/zone/id"),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			got := MachineByClusterNode(tc.object)
+			g.Expect(got).To(ConsistOf(tc.expected))
+		})
+	}
+}
@@ -69,6 +69,12 @@ const (
 	// This annotation can only be used on Control Plane Machines.
 	MachineCertificatesExpiryDateAnnotation = "machine.cluster.x-k8s.io/certificates-expiry"
 
+	// AdoptNodeAnnotation marks a Machine adopting an already running host and its Node instead of provisioning a new one.
+	// The value is the name of the Node to adopt; if empty, the Node is matched by the provider ID reported by
+	// the infrastructure provider. If the Node doesn't have a provider ID yet, it is set to the one of the Machine.
+	// Machines adopting a Node don't require spec.bootstrap to be set, given the host is already bootstrapped.
+	AdoptNodeAnnotation = "machine.cluster.x-k8s.io/adopt-node"
+
 	// NodeRoleLabelPrefix is one of the CAPI managed Node label prefixes.
 	NodeRoleLabelPrefix = "node-role.kubernetes.io"
 	// NodeRestrictionLabelDomain is one of the CAPI managed Node label domains.
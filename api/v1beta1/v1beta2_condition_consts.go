@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+// Conditions types that are used across different objects, following the metav1.Condition format
+// used by the v1beta2 API.
+const (
+	// AvailableV1Beta2Condition reports if an object is available; it is used by objects like the Cluster, the
+	// MachineDeployment or the control plane to summarize the state of the object and of its dependants.
+	AvailableV1Beta2Condition = "Available"
+
+	// ReadyV1Beta2Condition reports if an object is ready; it is used by objects like the Machine to summarize
+	// the state of the object and of its dependants.
+	ReadyV1Beta2Condition = "Ready"
+
+	// DeletingV1Beta2Condition surfaces details about an object being deleted; it is true if the object is being deleted.
+	DeletingV1Beta2Condition = "Deleting"
+
+	// PausedV1Beta2Condition is true if the Cluster or the object is paused, either by spec.paused on the Cluster
+	// or by the paused annotations; the message lists the active pause reasons.
+	PausedV1Beta2Condition = "Paused"
+)
+
+// Reasons that are used across different objects, following the metav1.Condition format
+// used by the v1beta2 API.
+const (
+	// PausedV1Beta2Reason surfaces when an object is paused.
+	PausedV1Beta2Reason = "Paused"
+
+	// NotPausedV1Beta2Reason surfaces when an object is not paused.
+	NotPausedV1Beta2Reason = "NotPaused"
+
+	// NotYetReportedV1Beta2Reason surfaces when a condition is not yet reported by the object it is read from,
+	// e.g. when mirroring a condition from an object that is not yet reconciled.
+	NotYetReportedV1Beta2Reason = "NotYetReported"
+
+	// StaleReportV1Beta2Reason surfaces when a condition is read from an object which has not reconciled it recently,
+	// e.g. when mirroring a condition with an ObservedGeneration older than the generation of the source object.
+	StaleReportV1Beta2Reason = "StaleReport"
+
+	// MultipleReasonsReportedV1Beta2Reason surfaces when a condition aggregates conditions from several objects
+	// reporting different reasons.
+	MultipleReasonsReportedV1Beta2Reason = "MultipleReasonsReported"
+
+	// NoReasonReportedV1Beta2Reason surfaces when a condition converted from the v1beta1 format has no reason,
+	// given that the reason is required in the metav1.Condition format.
+	NoReasonReportedV1Beta2Reason = "NoReasonReported"
+
+	// MachineDoesNotExistV1Beta2Reason surfaces when the infrastructure or the Kubernetes node of a Machine does not exist,
+	// e.g. when a provider reports that the instance backing the Machine has not been found.
+	MachineDoesNotExistV1Beta2Reason = "MachineDoesNotExist"
+)
@@ -26,6 +26,7 @@ import (
 
 	kubeadmbootstrapcontrollers "sigs.k8s.io/cluster-api/bootstrap/kubeadm/internal/controllers"
 	"sigs.k8s.io/cluster-api/controllers/remote"
+	"sigs.k8s.io/cluster-api/util/certs"
 )
 
 // Following types provides access to reconcilers implemented in internal/controllers, thus
@@ -48,6 +49,10 @@ type KubeadmConfigReconciler struct {
 
 	// TokenTTL is the amount of time a bootstrap token (and therefore a KubeadmConfig) will be valid.
 	TokenTTL time.Duration
+
+	// CASigner, if set, is used to issue the cluster certificate authorities as intermediate CAs
+	// signed by an external signer, so the root CA keys are never stored in the management cluster.
+	CASigner certs.Signer
 }
 
 // SetupWithManager sets up the reconciler with the Manager.
@@ -58,5 +63,6 @@ func (r *KubeadmConfigReconciler) SetupWithManager(ctx context.Context, mgr ctrl
 		Tracker:             r.Tracker,
 		WatchFilterValue:    r.WatchFilterValue,
 		TokenTTL:            r.TokenTTL,
+		CASigner:            r.CASigner,
 	}).SetupWithManager(ctx, mgr, options)
 }
@@ -51,13 +51,18 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/internal/util/kubeadm"
 	"sigs.k8s.io/cluster-api/internal/util/taints"
 	"sigs.k8s.io/cluster-api/util"
-	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/certs"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/container"
 	clog "sigs.k8s.io/cluster-api/util/log"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/paused"
 	"sigs.k8s.io/cluster-api/util/predicates"
+	"sigs.k8s.io/cluster-api/util/priority"
+	"sigs.k8s.io/cluster-api/util/requeue"
 	"sigs.k8s.io/cluster-api/util/secret"
 )
 
@@ -88,6 +93,10 @@ type KubeadmConfigReconciler struct {
 
 	// TokenTTL is the amount of time a bootstrap token (and therefore a KubeadmConfig) will be valid.
 	TokenTTL time.Duration
+
+	// CASigner, if set, is used to issue the cluster certificate authorities as intermediate CAs
+	// signed by an external signer, so the root CA keys are never stored in the management cluster.
+	CASigner certs.Signer
 }
 
 // Scope is a scoped struct used during reconciliation.
@@ -107,13 +116,12 @@ func (r *KubeadmConfigReconciler) SetupWithManager(ctx context.Context, mgr ctrl
 		r.TokenTTL = DefaultTokenTTL
 	}
 
-	b := ctrl.NewControllerManagedBy(mgr).
-		For(&bootstrapv1.KubeadmConfig{}).
+	b := priority.For(ctrl.NewControllerManagedBy(mgr), &bootstrapv1.KubeadmConfig{}).
 		WithOptions(options).
 		Watches(
 			&clusterv1.Machine{},
 			handler.EnqueueRequestsFromMapFunc(r.MachineToBootstrapMapFunc),
-		).WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue))
+		).WithEventFilter(predicates.ResourceHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue))
 
 	if feature.Gates.Enabled(feature.MachinePool) {
 		b = b.Watches(
@@ -127,13 +135,13 @@ func (r *KubeadmConfigReconciler) SetupWithManager(ctx context.Context, mgr ctrl
 		handler.EnqueueRequestsFromMapFunc(r.ClusterToKubeadmConfigs),
 		builder.WithPredicates(
 			predicates.All(ctrl.LoggerFrom(ctx),
-				predicates.ClusterUnpausedAndInfrastructureReady(ctrl.LoggerFrom(ctx)),
+				predicates.ClusterPausedTransitionsOrInfrastructureReady(ctrl.LoggerFrom(ctx)),
 				predicates.ResourceHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue),
 			),
 		),
 	)
 
-	if err := b.Complete(r); err != nil {
+	if err := b.Complete(requeue.Reconciler(r)); err != nil {
 		return errors.Wrap(err, "failed setting up with a controller manager")
 	}
 	return nil
@@ -195,9 +203,12 @@ func (r *KubeadmConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, err
 	}
 
-	if annotations.IsPaused(cluster, config) {
-		log.Info("Reconciliation is paused for this object")
-		return ctrl.Result{}, nil
+	// Set the Paused condition, and return early if the object or Cluster is paused.
+	if isPaused, err := paused.EnsurePausedCondition(ctx, r.Client, cluster, config); err != nil || isPaused {
+		if isPaused {
+			log.Info("Reconciliation is paused for this object")
+		}
+		return ctrl.Result{}, err
 	}
 
 	scope := &Scope{
@@ -401,13 +412,13 @@ func (r *KubeadmConfigReconciler) handleClusterNotInitialized(ctx context.Contex
 
 	// if it's NOT a control plane machine, requeue
 	if !scope.ConfigOwner.IsControlPlaneMachine() {
-		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		return ctrl.Result{RequeueAfter: requeue.After(requeue.NotFoundErrorClass)}, nil
 	}
 
 	// if the machine has not ClusterConfiguration and InitConfiguration, requeue
 	if scope.Config.Spec.InitConfiguration == nil && scope.Config.Spec.ClusterConfiguration == nil {
 		scope.Info("Control plane is not ready, requeuing joining control planes until ready.")
-		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		return ctrl.Result{RequeueAfter: requeue.After(requeue.NotFoundErrorClass)}, nil
 	}
 
 	machine := &clusterv1.Machine{}
@@ -420,7 +431,7 @@ func (r *KubeadmConfigReconciler) handleClusterNotInitialized(ctx context.Contex
 	// if not the first, requeue
 	if !r.KubeadmInitLock.Lock(ctx, scope.Cluster, machine) {
 		scope.Info("A control plane is already being initialized, requeuing until control plane is ready")
-		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		return ctrl.Result{RequeueAfter: requeue.After(requeue.NotFoundErrorClass)}, nil
 	}
 
 	defer func() {
@@ -472,7 +483,13 @@ func (r *KubeadmConfigReconciler) handleClusterNotInitialized(ctx context.Contex
 	// injects into config.ClusterConfiguration values from top level object
 	r.reconcileTopLevelObjectSettings(ctx, scope.Cluster, machine, scope.Config)
 
-	clusterdata, err := kubeadmtypes.MarshalClusterConfigurationForVersion(scope.Config.Spec.ClusterConfiguration, parsedVersion)
+	clusterConfiguration, err := rewriteClusterConfigurationImages(scope.Config.Spec.ClusterConfiguration, scope.ConfigOwner.GetAnnotations()[clusterv1.MachineArchitectureAnnotation])
+	if err != nil {
+		scope.Error(err, "Failed to rewrite cluster configuration images")
+		return ctrl.Result{}, err
+	}
+
+	clusterdata, err := kubeadmtypes.MarshalClusterConfigurationForVersion(clusterConfiguration, parsedVersion)
 	if err != nil {
 		scope.Error(err, "Failed to marshal cluster configuration")
 		return ctrl.Result{}, err
@@ -484,12 +501,13 @@ func (r *KubeadmConfigReconciler) handleClusterNotInitialized(ctx context.Contex
 	// Otherwise rely on certificates generated by the ControlPlane controller.
 	// Note: A cluster does not have a ControlPlane reference when using standalone CP machines.
 	if scope.Cluster.Spec.ControlPlaneRef == nil {
-		err = certificates.LookupOrGenerateCached(
+		err = certificates.LookupOrGenerateCachedWithSigner(
 			ctx,
 			r.SecretCachingClient,
 			r.Client,
 			util.ObjectKey(scope.Cluster),
-			*metav1.NewControllerRef(scope.Config, bootstrapv1.GroupVersion.WithKind("KubeadmConfig")))
+			*metav1.NewControllerRef(scope.Config, bootstrapv1.GroupVersion.WithKind("KubeadmConfig")),
+			r.CASigner)
 	} else {
 		err = certificates.LookupCached(ctx,
 			r.SecretCachingClient,
@@ -1019,6 +1037,42 @@ func (r *KubeadmConfigReconciler) reconcileDiscovery(ctx context.Context, cluste
 	return ctrl.Result{}, nil
 }
 
+// rewriteClusterConfigurationImages returns a copy of the ClusterConfiguration with the image repositories rewritten
+// according to the configured image mirrors, including the ones for the architecture of the Machine, if known;
+// the ClusterConfiguration in the KubeadmConfig is not modified.
+func rewriteClusterConfigurationImages(in *bootstrapv1.ClusterConfiguration, architecture string) (*bootstrapv1.ClusterConfiguration, error) {
+	rewriter := container.GetImageRewriter()
+	if rewriter == nil {
+		return in, nil
+	}
+
+	out := in.DeepCopy()
+	// If the image repository is not set, rewrite the default one so the mirror applies to all the Kubernetes images.
+	imageRepository := out.ImageRepository
+	if imageRepository == "" {
+		imageRepository = kubeadm.DefaultImageRepository
+	}
+	rewritten, err := rewriter.RewriteRepositoryForArchitecture(imageRepository, architecture)
+	if err != nil {
+		return nil, err
+	}
+	if rewritten != imageRepository {
+		out.ImageRepository = rewritten
+	}
+
+	if out.DNS.ImageRepository != "" {
+		if out.DNS.ImageRepository, err = rewriter.RewriteRepositoryForArchitecture(out.DNS.ImageRepository, architecture); err != nil {
+			return nil, err
+		}
+	}
+	if out.Etcd.Local != nil && out.Etcd.Local.ImageRepository != "" {
+		if out.Etcd.Local.ImageRepository, err = rewriter.RewriteRepositoryForArchitecture(out.Etcd.Local.ImageRepository, architecture); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
 // reconcileTopLevelObjectSettings injects into config.ClusterConfiguration values from top level objects like cluster and machine.
 // The implementation func respect user provided config values, but in case some of them are missing, values from top level objects are used.
 func (r *KubeadmConfigReconciler) reconcileTopLevelObjectSettings(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine, config *bootstrapv1.KubeadmConfig) {
@@ -27,6 +27,7 @@ import (
 
 	"github.com/spf13/pflag"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/selection"
@@ -52,7 +53,14 @@ import (
 	"sigs.k8s.io/cluster-api/feature"
 	bootstrapv1alpha3 "sigs.k8s.io/cluster-api/internal/apis/bootstrap/kubeadm/v1alpha3"
 	bootstrapv1alpha4 "sigs.k8s.io/cluster-api/internal/apis/bootstrap/kubeadm/v1alpha4"
+	"sigs.k8s.io/cluster-api/internal/util/metadatacache"
+	"sigs.k8s.io/cluster-api/util/container"
 	"sigs.k8s.io/cluster-api/util/flags"
+	"sigs.k8s.io/cluster-api/util/priority"
+	"sigs.k8s.io/cluster-api/util/requeue"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/cluster-api/util/shard"
+	"sigs.k8s.io/cluster-api/util/webhookcerts"
 	"sigs.k8s.io/cluster-api/version"
 )
 
@@ -67,7 +75,6 @@ var (
 	leaderElectionRenewDeadline time.Duration
 	leaderElectionRetryPeriod   time.Duration
 	watchFilterValue            string
-	watchNamespace              string
 	profilerAddress             string
 	enableContentionProfiling   bool
 	syncPeriod                  time.Duration
@@ -77,11 +84,25 @@ var (
 	webhookCertDir              string
 	healthAddr                  string
 	tlsOptions                  = flags.TLSOptions{}
-	diagnosticsOptions          = flags.DiagnosticsOptions{}
-	logOptions                  = logs.NewOptions()
+	namespaceOptions            = flags.NamespaceOptions{}
+	webhookCertOptions          = flags.WebhookCertOptions{
+		ServiceName:  "capi-kubeadm-bootstrap-webhook-service",
+		SecretName:   "capi-kubeadm-bootstrap-webhook-service-cert",
+		ProviderName: "bootstrap-kubeadm",
+	}
+	keyEncryptionOptions       = flags.KeyEncryptionOptions{}
+	caSignerOptions            = flags.CASignerOptions{}
+	requeueOptions             = flags.RequeueOptions{}
+	workloadClusterAuthOptions = flags.WorkloadClusterAuthOptions{}
+	priorityOptions            = flags.PriorityOptions{}
+	shardingOptions            = flags.ShardingOptions{}
+	imageMirrorOptions         = flags.ImageMirrorOptions{}
+	diagnosticsOptions         = flags.DiagnosticsOptions{}
+	logOptions                 = logs.NewOptions()
 	// CABPK specific flags.
 	clusterConcurrency             int
 	clusterCacheTrackerConcurrency int
+	clusterCacheTrackerIdleTimeout time.Duration
 	kubeadmConfigConcurrency       int
 	tokenTTL                       time.Duration
 )
@@ -93,6 +114,7 @@ func init() {
 	_ = bootstrapv1alpha3.AddToScheme(scheme)
 	_ = bootstrapv1alpha4.AddToScheme(scheme)
 	_ = bootstrapv1.AddToScheme(scheme)
+	_ = apiextensionsv1.AddToScheme(scheme)
 }
 
 // InitFlags initializes the flags.
@@ -111,9 +133,6 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&leaderElectionRetryPeriod, "leader-elect-retry-period", 2*time.Second,
 		"Duration the LeaderElector clients should wait between tries of actions (duration string)")
 
-	fs.StringVar(&watchNamespace, "namespace", "",
-		"Namespace that the controller watches to reconcile cluster-api objects. If unspecified, the controller watches for cluster-api objects across all namespaces.")
-
 	fs.StringVar(&watchFilterValue, "watch-filter", "",
 		fmt.Sprintf("Label value that the controller watches to reconcile cluster-api objects. Label key is always %s. If unspecified, the controller watches for all cluster-api objects.", clusterv1.WatchLabel))
 
@@ -130,6 +149,10 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&clusterCacheTrackerConcurrency, "clustercachetracker-concurrency", 10,
 		"Number of clusters to process simultaneously")
 
+	fs.DurationVar(&clusterCacheTrackerIdleTimeout, "clustercachetracker-idle-timeout", 0,
+		"Duration after which the connection to a workload cluster which is not used by any controller is torn down; "+
+			"it is re-established on the next use. If zero, connections are never torn down for being idle.")
+
 	fs.IntVar(&kubeadmConfigConcurrency, "kubeadmconfig-concurrency", 10,
 		"Number of kubeadm configs to process simultaneously")
 
@@ -156,6 +179,15 @@ func InitFlags(fs *pflag.FlagSet) {
 
 	flags.AddDiagnosticsOptions(fs, &diagnosticsOptions)
 	flags.AddTLSOptions(fs, &tlsOptions)
+	flags.AddNamespaceOptions(fs, &namespaceOptions)
+	flags.AddWebhookCertOptions(fs, &webhookCertOptions)
+	flags.AddKeyEncryptionOptions(fs, &keyEncryptionOptions)
+	flags.AddCASignerOptions(fs, &caSignerOptions)
+	flags.AddRequeueOptions(fs, &requeueOptions)
+	flags.AddWorkloadClusterAuthOptions(fs, &workloadClusterAuthOptions)
+	flags.AddPriorityOptions(fs, &priorityOptions)
+	flags.AddShardingOptions(fs, &shardingOptions)
+	flags.AddImageMirrorOptions(fs, &imageMirrorOptions)
 
 	feature.MutableGates.AddFlag(fs)
 }
@@ -194,13 +226,53 @@ func main() {
 		os.Exit(1)
 	}
 
+	webhookCerts, err := flags.GetWebhookCertOptions(webhookCertOptions, webhookCertDir)
+	if err != nil {
+		setupLog.Error(err, "unable to configure webhook certificates")
+		os.Exit(1)
+	}
+
+	keyEncryptionService, err := flags.GetKeyEncryptionService(keyEncryptionOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure cluster certificate key encryption")
+		os.Exit(1)
+	}
+	secret.SetKeyEncryptionService(keyEncryptionService)
+
+	requeuePolicy, err := flags.GetRequeuePolicy(requeueOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure requeue policy")
+		os.Exit(1)
+	}
+	requeue.SetPolicy(requeuePolicy)
+
+	workloadClusterAuthPolicy, err := flags.GetWorkloadClusterAuthPolicy(workloadClusterAuthOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure workload cluster authentication")
+		os.Exit(1)
+	}
+	remote.SetWorkloadClusterAuthPolicy(workloadClusterAuthPolicy)
+
+	priorityPolicy, err := flags.GetPriorityPolicy(priorityOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure priority policy")
+		os.Exit(1)
+	}
+	priority.SetPolicy(priorityPolicy)
+
+	imageRewriter, err := flags.GetImageRewriter(imageMirrorOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure image mirrors")
+		os.Exit(1)
+	}
+	container.SetImageRewriter(imageRewriter)
+
 	diagnosticsOpts := flags.GetDiagnosticsOptions(diagnosticsOptions)
 
-	var watchNamespaces map[string]cache.Config
-	if watchNamespace != "" {
-		watchNamespaces = map[string]cache.Config{
-			watchNamespace: {},
-		}
+	watchNamespaces, err := flags.GetWatchNamespaces(namespaceOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure watch namespaces")
+		os.Exit(1)
 	}
 
 	if enableContentionProfiling {
@@ -213,7 +285,7 @@ func main() {
 	ctrlOptions := ctrl.Options{
 		Scheme:                     scheme,
 		LeaderElection:             enableLeaderElection,
-		LeaderElectionID:           "kubeadm-bootstrap-manager-leader-election-capi",
+		LeaderElectionID:           flags.GetShardLeaderElectionID("kubeadm-bootstrap-manager-leader-election-capi", shardingOptions),
 		LeaseDuration:              &leaderElectionLeaseDuration,
 		RenewDeadline:              &leaderElectionRenewDeadline,
 		RetryPeriod:                &leaderElectionRetryPeriod,
@@ -225,9 +297,10 @@ func main() {
 			DefaultNamespaces: watchNamespaces,
 			SyncPeriod:        &syncPeriod,
 			ByObject: map[client.Object]cache.ByObject{
-				// Note: Only Secrets with the cluster name label are cached.
+				// Note: Only Secrets with the cluster name label are cached, and only their metadata is cached.
 				// The default client of the manager won't use the cache for secrets at all (see Client.Cache.DisableFor).
-				// The cached secrets will only be used by the secretCachingClient we create below.
+				// The cached metadata will only be used by the secretCachingClient we create below, which fetches
+				// the full Secrets from the API server only when they changed.
 				&corev1.Secret{}: {
 					Label: clusterSecretCacheSelector,
 				},
@@ -256,9 +329,17 @@ func main() {
 		os.Exit(1)
 	}
 
+	managerShard, err := flags.GetShard(shardingOptions, mgr.GetClient())
+	if err != nil {
+		setupLog.Error(err, "unable to configure sharding")
+		os.Exit(1)
+	}
+	shard.Set(managerShard)
+
 	// Setup the context that's going to be used in controllers and for the manager.
 	ctx := ctrl.SetupSignalHandler()
 
+	setupWebhookCerts(ctx, mgr, webhookCerts)
 	setupChecks(mgr)
 	setupWebhooks(mgr)
 	setupReconcilers(ctx, mgr)
@@ -270,6 +351,29 @@ func main() {
 	}
 }
 
+// setupWebhookCerts ensures the self-managed webhook certificates exist before the webhook server is started
+// and keeps them valid while the manager is running.
+func setupWebhookCerts(ctx context.Context, mgr ctrl.Manager, options *webhookcerts.Options) {
+	if options == nil {
+		return
+	}
+	// The cache of the manager is not started yet, and there is no need to cache these objects anyway.
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		setupLog.Error(err, "unable to create client for webhook certificates")
+		os.Exit(1)
+	}
+	certManager := webhookcerts.New(c, *options)
+	if _, err := certManager.Ensure(ctx); err != nil {
+		setupLog.Error(err, "unable to ensure webhook certificates")
+		os.Exit(1)
+	}
+	if err := mgr.Add(certManager); err != nil {
+		setupLog.Error(err, "unable to add webhook certificates manager")
+		os.Exit(1)
+	}
+}
+
 func setupChecks(mgr ctrl.Manager) {
 	if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
 		setupLog.Error(err, "unable to create ready check")
@@ -283,11 +387,8 @@ func setupChecks(mgr ctrl.Manager) {
 }
 
 func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
-	secretCachingClient, err := client.New(mgr.GetConfig(), client.Options{
-		HTTPClient: mgr.GetHTTPClient(),
-		Cache: &client.CacheOptions{
-			Reader: mgr.GetCache(),
-		},
+	secretCachingClient, err := metadatacache.NewClient(mgr.GetClient(), mgr.GetCache(), metadatacache.Options{
+		Objects: []client.Object{&corev1.Secret{}},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to create secret caching client")
@@ -301,6 +402,7 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 		remote.ClusterCacheTrackerOptions{
 			SecretCachingClient: secretCachingClient,
 			ControllerName:      controllerName,
+			IdleTimeout:         clusterCacheTrackerIdleTimeout,
 			Log:                 &ctrl.Log,
 		},
 	)
@@ -317,12 +419,25 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 		os.Exit(1)
 	}
 
+	// CertificateSigningRequests are only created and read back while signing, so there is no need to cache them.
+	caSignerClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		setupLog.Error(err, "unable to create client for cluster CA signer")
+		os.Exit(1)
+	}
+	caSigner, err := flags.GetCASigner(caSignerOptions, caSignerClient)
+	if err != nil {
+		setupLog.Error(err, "unable to create cluster CA signer")
+		os.Exit(1)
+	}
+
 	if err := (&kubeadmbootstrapcontrollers.KubeadmConfigReconciler{
 		Client:              mgr.GetClient(),
 		SecretCachingClient: secretCachingClient,
 		Tracker:             tracker,
 		WatchFilterValue:    watchFilterValue,
 		TokenTTL:            tokenTTL,
+		CASigner:            caSigner,
 	}).SetupWithManager(ctx, mgr, concurrency(kubeadmConfigConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KubeadmConfig")
 		os.Exit(1)
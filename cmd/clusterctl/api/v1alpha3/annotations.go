@@ -41,4 +41,18 @@ const (
 	// `clusterctl move` is invoked, then NO resources for ANY workload cluster will be created on the
 	// destination management cluster until the annotation is removed.
 	BlockMoveAnnotation = "clusterctl.cluster.x-k8s.io/block-move"
+
+	// BackupEncryptedAnnotation is set by clusterctl move --to-directory on Secrets with data encrypted in the backup;
+	// the value is the ID of the encryption key. The annotation is removed when the Secret is restored.
+	BackupEncryptedAnnotation = "clusterctl.cluster.x-k8s.io/backup-encrypted"
+
+	// HelmChartAnnotation is set by clusterctl on the provider inventory entries of providers installed from a Helm chart;
+	// the value is the path or the OCI reference of the chart, while the version of the chart is the version of the provider.
+	HelmChartAnnotation = "clusterctl.cluster.x-k8s.io/helm-chart"
+
+	// HelmReleaseAnnotation reports the name of the Helm release used for rendering the chart of a provider.
+	HelmReleaseAnnotation = "clusterctl.cluster.x-k8s.io/helm-release"
+
+	// HelmValuesSHA256Annotation reports the SHA256 checksum of the values file used for rendering the chart of a provider, if any.
+	HelmValuesSHA256Annotation = "clusterctl.cluster.x-k8s.io/helm-values-sha256"
 )
@@ -31,6 +31,9 @@ const (
 	// ClusterctlCoreLabelCertManagerValue define the value for ClusterctlCoreLabel to be used for cert-manager objects.
 	ClusterctlCoreLabelCertManagerValue = "cert-manager"
 
+	// ClusterctlCoreLabelAuditValue define the value for ClusterctlCoreLabel to be used for the records of the audit trail.
+	ClusterctlCoreLabelAuditValue = "audit"
+
 	// ClusterctlMoveLabel can be set on CRDs that providers wish to move but that are not part of a Cluster.
 	ClusterctlMoveLabel = "clusterctl.cluster.x-k8s.io/move"
 
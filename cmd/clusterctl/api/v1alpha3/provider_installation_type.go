@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	// ProviderInstallationFinalizer allows the ProviderInstallation controller to delete the provider
+	// before removing the ProviderInstallation from the API server.
+	ProviderInstallationFinalizer = "providerinstallation.clusterctl.cluster.x-k8s.io"
+)
+
+// Conditions and condition reasons for ProviderInstallations.
+const (
+	// WaitingForCoreProviderReason (Severity=Info) documents a ProviderInstallation waiting for the core provider
+	// to be installed before installing a provider of another type.
+	WaitingForCoreProviderReason = "WaitingForCoreProvider"
+
+	// InstallationFailedReason (Severity=Error) documents a ProviderInstallation failing to install the provider.
+	InstallationFailedReason = "InstallationFailed"
+
+	// UpgradeFailedReason (Severity=Error) documents a ProviderInstallation failing to upgrade the provider.
+	UpgradeFailedReason = "UpgradeFailed"
+
+	// WaitingForProviderReason (Severity=Info) documents a ProviderInstallation waiting for the Deployments
+	// of the provider installed or upgraded to be available.
+	WaitingForProviderReason = "WaitingForProvider"
+
+	// RepositoryNotAllowedReason (Severity=Error) documents a ProviderInstallation using a repository
+	// or a clusterctl configuration the controller is not allowed to use.
+	RepositoryNotAllowedReason = "RepositoryNotAllowed"
+)
+
+// ProviderInstallationSpec defines the desired state of a provider in the management cluster.
+type ProviderInstallationSpec struct {
+	// ProviderName is the name of the provider, e.g. cluster-api, kubeadm or aws.
+	ProviderName string `json:"providerName"`
+
+	// Type is the type of the provider, e.g. InfrastructureProvider.
+	// See ProviderType for a list of supported values.
+	// +kubebuilder:validation:Enum=CoreProvider;BootstrapProvider;ControlPlaneProvider;InfrastructureProvider;IPAMProvider;RuntimeExtensionProvider;AddonProvider
+	Type string `json:"type"`
+
+	// Version is the version of the provider to install; changing the version upgrades the provider.
+	Version string `json:"version"`
+
+	// TargetNamespace is the namespace where the provider is installed.
+	// If empty, the default namespace of the provider is used.
+	// +optional
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+
+	// URL is the URL of the repository the provider components are fetched from,
+	// using the same format of the url field in the clusterctl configuration file.
+	// If empty, the repository configured by default in clusterctl for the provider is used.
+	// The URL must match one of the repositories the controller is allowed to use.
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// VariablesSecretRef is a reference to a Secret in the same namespace of the ProviderInstallation
+	// containing the values of the variables used in the provider components and in the clusterctl configuration,
+	// e.g. AWS_B64ENCODED_CREDENTIALS or GITHUB_TOKEN; keys changing where the components are fetched from,
+	// like providers, images, cert-manager and overridesFolder, are not allowed.
+	// +optional
+	VariablesSecretRef *corev1.LocalObjectReference `json:"variablesSecretRef,omitempty"`
+}
+
+// ProviderInstallationStatus defines the observed state of a provider in the management cluster.
+type ProviderInstallationStatus struct {
+	// InstalledVersion is the version of the provider currently installed, as recorded in the clusterctl inventory.
+	// +optional
+	InstalledVersion string `json:"installedVersion,omitempty"`
+
+	// ObservedGeneration is the latest generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions defines current state of the ProviderInstallation.
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:resource:path=providerinstallations,scope=Namespaced,categories=cluster-api
+// +kubebuilder:storageversion
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Type",type="string",JSONPath=".spec.type"
+// +kubebuilder:printcolumn:name="Provider",type="string",JSONPath=".spec.providerName"
+// +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".spec.version"
+// +kubebuilder:printcolumn:name="Installed",type="string",JSONPath=".status.installedVersion"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Time duration since creation of ProviderInstallation"
+
+// ProviderInstallation defines the desired state of a provider in the management cluster; it is reconciled
+// by the ProviderInstallation controller using the same logic of clusterctl init and clusterctl upgrade apply.
+type ProviderInstallation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProviderInstallationSpec   `json:"spec,omitempty"`
+	Status ProviderInstallationStatus `json:"status,omitempty"`
+}
+
+// GetProviderType parses the Type field and returns a ProviderType.
+func (p *ProviderInstallation) GetProviderType() ProviderType {
+	return ProviderType(p.Spec.Type)
+}
+
+// GetConditions returns the set of conditions for this object.
+func (p *ProviderInstallation) GetConditions() clusterv1.Conditions {
+	return p.Status.Conditions
+}
+
+// SetConditions sets the conditions on this object.
+func (p *ProviderInstallation) SetConditions(conditions clusterv1.Conditions) {
+	p.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+
+// ProviderInstallationList contains a list of ProviderInstallation.
+type ProviderInstallationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProviderInstallation `json:"items"`
+}
+
+func init() {
+	objectTypes = append(objectTypes, &ProviderInstallation{}, &ProviderInstallationList{})
+}
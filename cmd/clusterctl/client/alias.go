@@ -45,6 +45,12 @@ type UpgradePlan cluster.UpgradePlan
 // upgraded to a different version.
 type CertManagerUpgradePlan cluster.CertManagerUpgradePlan
 
+// ProviderUpgradeDiff describes the changes that upgrading a provider applies to the management cluster.
+type ProviderUpgradeDiff cluster.ProviderUpgradeDiff
+
+// ValidationIssue is an issue found when validating the files of a provider repository.
+type ValidationIssue repository.ValidationIssue
+
 // Kubeconfig is a type that specifies inputs related to the actual kubeconfig.
 type Kubeconfig cluster.Kubeconfig
 
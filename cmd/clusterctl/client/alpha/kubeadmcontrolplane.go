@@ -22,10 +22,16 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	"sigs.k8s.io/cluster-api/controllers/external"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
 )
 
@@ -73,3 +79,81 @@ func patchKubeadmControlPlane(ctx context.Context, proxy cluster.Proxy, name, na
 	}
 	return nil
 }
+
+// getMachinesForKubeadmControlPlane returns the control plane Machines controlled by the KubeadmControlPlane.
+func getMachinesForKubeadmControlPlane(ctx context.Context, proxy cluster.Proxy, kcp *controlplanev1.KubeadmControlPlane) ([]*clusterv1.Machine, error) {
+	log := logf.Log
+	c, err := proxy.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	machineList := &clusterv1.MachineList{}
+	if err := c.List(ctx, machineList, client.InNamespace(kcp.Namespace), client.MatchingLabels{clusterv1.MachineControlPlaneNameLabel: kcp.Name}); err != nil {
+		return nil, errors.Wrapf(err, "failed to list Machines for KubeadmControlPlane %s/%s", kcp.Namespace, kcp.Name)
+	}
+
+	machines := make([]*clusterv1.Machine, 0, len(machineList.Items))
+	for idx := range machineList.Items {
+		m := &machineList.Items[idx]
+
+		// Skip this Machine if its controller ref is not pointing to this KubeadmControlPlane
+		if !metav1.IsControlledBy(m, kcp) {
+			log.V(5).Info("Skipping Machine, controller ref does not match KubeadmControlPlane", "machine", m.Name)
+			continue
+		}
+		machines = append(machines, m)
+	}
+	return machines, nil
+}
+
+// findKubeadmControlPlanePreviousRevision returns the version and the infrastructure machine template of the most
+// recently created Machine that does not match the current KubeadmControlPlane spec.
+// The infrastructure machine template is detected from the cloned-from annotations of the infrastructure machine.
+func findKubeadmControlPlanePreviousRevision(ctx context.Context, proxy cluster.Proxy, kcp *controlplanev1.KubeadmControlPlane, machines []*clusterv1.Machine) (string, *corev1.ObjectReference, error) {
+	c, err := proxy.NewClient(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var (
+		previousMachine       *clusterv1.Machine
+		previousVersion       string
+		previousInfraTemplate *corev1.ObjectReference
+	)
+	for _, m := range machines {
+		if m.Spec.Version == nil || (previousMachine != nil && !previousMachine.CreationTimestamp.Before(&m.CreationTimestamp)) {
+			continue
+		}
+
+		infraMachine, err := external.Get(ctx, c, &m.Spec.InfrastructureRef, m.Namespace)
+		if err != nil {
+			if apierrors.IsNotFound(errors.Cause(err)) {
+				continue
+			}
+			return "", nil, err
+		}
+		templateName, ok := infraMachine.GetAnnotations()[clusterv1.TemplateClonedFromNameAnnotation]
+		if !ok {
+			continue
+		}
+
+		currentInfraTemplate := kcp.Spec.MachineTemplate.InfrastructureRef
+		if *m.Spec.Version == kcp.Spec.Version && templateName == currentInfraTemplate.Name {
+			continue
+		}
+
+		previousMachine = m
+		previousVersion = *m.Spec.Version
+		previousInfraTemplate = &corev1.ObjectReference{
+			APIVersion: currentInfraTemplate.APIVersion,
+			Kind:       currentInfraTemplate.Kind,
+			Namespace:  currentInfraTemplate.Namespace,
+			Name:       templateName,
+		}
+	}
+
+	if previousMachine == nil {
+		return "", nil, errors.Errorf("no rollout history found for KubeadmControlPlane %s/%s: no Machines with a previous version or infrastructure machine template", kcp.Namespace, kcp.Name)
+	}
+	return previousVersion, previousInfraTemplate, nil
+}
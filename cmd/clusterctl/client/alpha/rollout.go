@@ -38,6 +38,7 @@ var validResourceTypes = []string{
 
 var validRollbackResourceTypes = []string{
 	MachineDeployment,
+	KubeadmControlPlane,
 }
 
 // Rollout defines the behavior of a rollout implementation.
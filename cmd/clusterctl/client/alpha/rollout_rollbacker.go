@@ -25,7 +25,10 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/version"
 )
 
 // ObjectRollbacker will issue a rollback on the specified cluster-api resource.
@@ -42,6 +45,20 @@ func (r *rollout) ObjectRollbacker(ctx context.Context, proxy cluster.Proxy, ref
 		if err := rollbackMachineDeployment(ctx, proxy, deployment, toRevision); err != nil {
 			return err
 		}
+	case KubeadmControlPlane:
+		kcp, err := getKubeadmControlPlane(ctx, proxy, ref.Name, ref.Namespace)
+		if err != nil || kcp == nil {
+			return errors.Wrapf(err, "failed to get %v/%v", ref.Kind, ref.Name)
+		}
+		if annotations.HasPaused(kcp.GetObjectMeta()) {
+			return errors.Errorf("can't rollback a paused KubeadmControlPlane: please run 'clusterctl rollout resume %v/%v' first", ref.Kind, ref.Name)
+		}
+		if toRevision != 0 {
+			return errors.Errorf("KubeadmControlPlane does not keep a revision history: --to-revision is not supported for %v/%v", ref.Kind, ref.Name)
+		}
+		if err := rollbackKubeadmControlPlane(ctx, proxy, kcp); err != nil {
+			return err
+		}
 	default:
 		return errors.Errorf("invalid resource type %q, valid values are %v", ref.Kind, validRollbackResourceTypes)
 	}
@@ -80,3 +97,81 @@ func rollbackMachineDeployment(ctx context.Context, proxy cluster.Proxy, md *clu
 	md.Spec.Template = revMSTemplate
 	return patchHelper.Patch(ctx, md)
 }
+
+// rollbackKubeadmControlPlane will rollback to the version and infrastructure machine template used by the
+// previous control plane Machines of this KubeadmControlPlane.
+// NOTE: KubeadmControlPlane does not keep a revision history, so the previous revision can only be detected
+// while the Machines created from it still exist, e.g. while a rollout is in progress or is stuck.
+func rollbackKubeadmControlPlane(ctx context.Context, proxy cluster.Proxy, kcp *controlplanev1.KubeadmControlPlane) error {
+	log := logf.Log
+	c, err := proxy.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	machines, err := getMachinesForKubeadmControlPlane(ctx, proxy, kcp)
+	if err != nil {
+		return err
+	}
+	log.V(7).Info("Found Machines", "count", len(machines))
+	previousVersion, previousInfraTemplate, err := findKubeadmControlPlanePreviousRevision(ctx, proxy, kcp, machines)
+	if err != nil {
+		return err
+	}
+	log.V(7).Info("Found previous revision", "version", previousVersion, "infrastructureTemplate", previousInfraTemplate.Name)
+	patchHelper, err := patch.NewHelper(kcp, c)
+	if err != nil {
+		return err
+	}
+
+	// Kubernetes version downgrades are not supported by kubeadm, so the version is rolled back only if it is safe;
+	// otherwise only the infrastructure machine template is rolled back.
+	if err := canRollbackKubeadmControlPlaneVersion(kcp, machines, previousVersion); err != nil {
+		if previousInfraTemplate.Name == kcp.Spec.MachineTemplate.InfrastructureRef.Name {
+			return errors.Wrapf(err, "failed to rollback KubeadmControlPlane %s/%s", kcp.Namespace, kcp.Name)
+		}
+		log.Info("Rolling back only the infrastructure machine template", "reason", err.Error())
+	} else {
+		kcp.Spec.Version = previousVersion
+	}
+	kcp.Spec.MachineTemplate.InfrastructureRef = *previousInfraTemplate
+	return patchHelper.Patch(ctx, kcp)
+}
+
+// canRollbackKubeadmControlPlaneVersion returns an error if the version of the KubeadmControlPlane can't be rolled back
+// to the previous version, i.e. if the previous version has a lower minor version, or if it is a lower patch version
+// and some Machines already run the current version.
+func canRollbackKubeadmControlPlaneVersion(kcp *controlplanev1.KubeadmControlPlane, machines []*clusterv1.Machine, previousVersion string) error {
+	if previousVersion == kcp.Spec.Version {
+		return nil
+	}
+
+	current, err := version.ParseMajorMinorPatchTolerant(kcp.Spec.Version)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse the version %q of KubeadmControlPlane %s/%s", kcp.Spec.Version, kcp.Namespace, kcp.Name)
+	}
+	previous, err := version.ParseMajorMinorPatchTolerant(previousVersion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse the previous version %q of KubeadmControlPlane %s/%s", previousVersion, kcp.Namespace, kcp.Name)
+	}
+	if previous.GTE(current) {
+		return nil
+	}
+
+	if previous.Major < current.Major || previous.Minor < current.Minor {
+		return errors.Errorf("the version can't be rolled back from %s to %s: downgrading the Kubernetes minor version is not supported", kcp.Spec.Version, previousVersion)
+	}
+	for _, m := range machines {
+		if m.Spec.Version == nil {
+			continue
+		}
+		machineVersion, err := version.ParseMajorMinorPatchTolerant(*m.Spec.Version)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse the version %q of Machine %s/%s", *m.Spec.Version, m.Namespace, m.Name)
+		}
+		if machineVersion.GT(previous) {
+			return errors.Errorf("the version can't be rolled back from %s to %s: Machine %s/%s already runs version %s", kcp.Spec.Version, previousVersion, m.Namespace, m.Name, *m.Spec.Version)
+		}
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ package alpha
 import (
 	"context"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
@@ -28,6 +29,8 @@ import (
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test/providers/infrastructure"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
 )
 
 func Test_ObjectRollbacker(t *testing.T) {
@@ -259,3 +262,160 @@ func Test_ObjectRollbacker(t *testing.T) {
 		})
 	}
 }
+
+func Test_ObjectRollbacker_KubeadmControlPlane(t *testing.T) {
+	kcp := &controlplanev1.KubeadmControlPlane{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "KubeadmControlPlane",
+			APIVersion: controlplanev1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-kcp",
+			Namespace: "default",
+		},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			Version: "v1.29.0",
+			MachineTemplate: controlplanev1.KubeadmControlPlaneMachineTemplate{
+				InfrastructureRef: corev1.ObjectReference{
+					APIVersion: infrastructure.GroupVersion.String(),
+					Kind:       "GenericInfrastructureMachineTemplate",
+					Name:       "cp-template-new",
+				},
+			},
+		},
+	}
+	machine := func(name, version, infraTemplate string, created time.Time) []client.Object {
+		return []client.Object{
+			&clusterv1.Machine{
+				TypeMeta: metav1.TypeMeta{
+					Kind: "Machine",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              name,
+					Namespace:         "default",
+					CreationTimestamp: metav1.NewTime(created),
+					Labels: map[string]string{
+						clusterv1.ClusterNameLabel:             "test",
+						clusterv1.MachineControlPlaneNameLabel: kcp.Name,
+					},
+					OwnerReferences: []metav1.OwnerReference{
+						*metav1.NewControllerRef(kcp, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane")),
+					},
+				},
+				Spec: clusterv1.MachineSpec{
+					ClusterName: "test",
+					Version:     ptr.To(version),
+					InfrastructureRef: corev1.ObjectReference{
+						APIVersion: infrastructure.GroupVersion.String(),
+						Kind:       "GenericInfrastructureMachine",
+						Name:       name,
+					},
+				},
+			},
+			&infrastructure.GenericInfrastructureMachine{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: infrastructure.GroupVersion.String(),
+					Kind:       "GenericInfrastructureMachine",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "default",
+					Annotations: map[string]string{
+						clusterv1.TemplateClonedFromNameAnnotation: infraTemplate,
+					},
+				},
+			},
+		}
+	}
+	kcpWithVersion := func(version string) *controlplanev1.KubeadmControlPlane {
+		kcp := kcp.DeepCopy()
+		kcp.Spec.Version = version
+		return kcp
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name              string
+		objs              []client.Object
+		toRevision        int64
+		wantErr           bool
+		wantVersion       string
+		wantInfraTemplate string
+	}{
+		{
+			name: "kubeadmcontrolplane should rollback to the most recent previous version and template",
+			objs: append(append(
+				machine("m-old", "v1.29.0", "cp-template-old", now.Add(-2*time.Hour)),
+				machine("m-previous", "v1.29.1", "cp-template-previous", now.Add(-time.Hour))...),
+				kcpWithVersion("v1.29.2")),
+			wantVersion:       "v1.29.1",
+			wantInfraTemplate: "cp-template-previous",
+		},
+		{
+			name: "kubeadmcontrolplane should rollback only the template if the previous version has a lower minor version",
+			objs: append(append(
+				machine("m-previous", "v1.28.0", "cp-template-previous", now.Add(-time.Hour)),
+				machine("m-new", "v1.29.0", "cp-template-new", now)...),
+				kcp),
+			wantVersion:       "v1.29.0",
+			wantInfraTemplate: "cp-template-previous",
+		},
+		{
+			name: "kubeadmcontrolplane should rollback only the template if a Machine already runs the current version",
+			objs: append(append(
+				machine("m-previous", "v1.29.0", "cp-template-previous", now.Add(-time.Hour)),
+				machine("m-new", "v1.29.1", "cp-template-new", now)...),
+				kcpWithVersion("v1.29.1")),
+			wantVersion:       "v1.29.1",
+			wantInfraTemplate: "cp-template-previous",
+		},
+		{
+			name: "kubeadmcontrolplane should not rollback if only the version changed and it has a lower minor version",
+			objs: append(append(
+				machine("m-previous", "v1.28.0", "cp-template-new", now.Add(-time.Hour)),
+				machine("m-new", "v1.29.0", "cp-template-new", now)...),
+				kcp),
+			wantErr: true,
+		},
+		{
+			name: "kubeadmcontrolplane should not rollback because all Machines are up to date",
+			objs: append(
+				machine("m-new", "v1.29.0", "cp-template-new", now),
+				kcp),
+			wantErr: true,
+		},
+		{
+			name: "kubeadmcontrolplane should not rollback to a specific revision",
+			objs: append(
+				machine("m-previous", "v1.28.0", "cp-template-previous", now),
+				kcp),
+			toRevision: 1,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			r := newRolloutClient()
+			proxy := test.NewFakeProxy().WithObjs(tt.objs...)
+			ref := corev1.ObjectReference{
+				Kind:      KubeadmControlPlane,
+				Name:      kcp.Name,
+				Namespace: kcp.Namespace,
+			}
+			err := r.ObjectRollbacker(context.Background(), proxy, ref, tt.toRevision)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			cl, err := proxy.NewClient(context.Background())
+			g.Expect(err).ToNot(HaveOccurred())
+			got := &controlplanev1.KubeadmControlPlane{}
+			g.Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(kcp), got)).To(Succeed())
+			g.Expect(got.Spec.Version).To(Equal(tt.wantVersion))
+			g.Expect(got.Spec.MachineTemplate.InfrastructureRef.Name).To(Equal(tt.wantInfraTemplate))
+			g.Expect(got.Spec.MachineTemplate.InfrastructureRef.Kind).To(Equal("GenericInfrastructureMachineTemplate"))
+		})
+	}
+}
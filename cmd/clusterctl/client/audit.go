@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	"sigs.k8s.io/cluster-api/version"
+)
+
+// disableAuditVariable is the clusterctl configuration variable disabling the audit trail of the operations.
+const disableAuditVariable = "CLUSTERCTL_DISABLE_AUDIT"
+
+// auditedOperation records a clusterctl operation into the audit trail of a management cluster.
+type auditedOperation struct {
+	clusterClient cluster.Client
+	record        cluster.OperationRecord
+	previous      []clusterctlv1.Provider
+}
+
+// startAuditedOperation starts recording an operation against the management cluster,
+// or returns nil if the audit trail is disabled.
+func (c *clusterctlClient) startAuditedOperation(ctx context.Context, clusterClient cluster.Client, operation cluster.Operation) *auditedOperation {
+	if disabled, err := c.configClient.Variables().Get(disableAuditVariable); err == nil && strings.EqualFold(disabled, "true") {
+		return nil
+	}
+
+	o := &auditedOperation{
+		clusterClient: clusterClient,
+		record: cluster.OperationRecord{
+			Operation:         operation,
+			StartTime:         metav1.Now(),
+			ClusterctlVersion: version.Get().GitVersion,
+		},
+	}
+	// The inventory does not exist before the first init.
+	if providers, err := clusterClient.ProviderInventory().List(ctx); err == nil {
+		o.previous = providers.Items
+	}
+	return o
+}
+
+// countObjects records the number of Clusters and Machines in the namespace, or in all namespaces if empty.
+func (o *auditedOperation) countObjects(ctx context.Context, namespace string) {
+	if o == nil {
+		return
+	}
+	o.record.Namespace = namespace
+
+	c, err := o.clusterClient.Proxy().NewClient(ctx)
+	if err != nil {
+		return
+	}
+	o.record.ObjectCounts = map[string]int{}
+	for kind, list := range map[string]client.ObjectList{
+		"Cluster": &clusterv1.ClusterList{},
+		"Machine": &clusterv1.MachineList{},
+	} {
+		if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			continue
+		}
+		o.record.ObjectCounts[kind] = meta.LenList(list)
+	}
+}
+
+// complete records the operation with the outcome of the given error; failures to record the operation are logged,
+// but they do not fail the operation.
+func (o *auditedOperation) complete(ctx context.Context, err error) {
+	if o == nil {
+		return
+	}
+	log := logf.Log
+
+	o.record.EndTime = metav1.Now()
+	o.record.Outcome = cluster.OperationSucceeded
+	if err != nil {
+		o.record.Outcome = cluster.OperationFailed
+		o.record.Error = err.Error()
+	}
+
+	var current []clusterctlv1.Provider
+	if providers, err := o.clusterClient.ProviderInventory().List(ctx); err == nil {
+		current = providers.Items
+	}
+	o.record.Providers = providerRecords(o.previous, current)
+
+	if err := o.clusterClient.Audit().Record(ctx, o.record); err != nil {
+		log.Info("Failed to record the operation into the audit trail of the management cluster", "error", err.Error())
+	}
+}
+
+// providerRecords returns the records of the providers installed after the operation, including the previous
+// version of the changed ones, and of the providers removed by the operation.
+func providerRecords(previous, current []clusterctlv1.Provider) []cluster.ProviderRecord {
+	previousVersions := map[string]string{}
+	for _, p := range previous {
+		previousVersions[p.InstanceName()] = p.Version
+	}
+
+	records := []cluster.ProviderRecord{}
+	for _, p := range current {
+		record := cluster.ProviderRecord{Name: p.ProviderName, Type: p.Type, Namespace: p.Namespace, Version: p.Version}
+		if previousVersion, ok := previousVersions[p.InstanceName()]; ok && previousVersion != p.Version {
+			record.PreviousVersion = previousVersion
+		}
+		delete(previousVersions, p.InstanceName())
+		records = append(records, record)
+	}
+	for _, p := range previous {
+		if _, removed := previousVersions[p.InstanceName()]; removed {
+			records = append(records, cluster.ProviderRecord{Name: p.ProviderName, Type: p.Type, Namespace: p.Namespace, PreviousVersion: p.Version})
+		}
+	}
+	return records
+}
@@ -18,6 +18,7 @@ package client
 
 import (
 	"context"
+	"io"
 
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/alpha"
@@ -32,6 +33,9 @@ type Client interface {
 	// GetProvidersConfig returns the list of providers configured for this instance of clusterctl.
 	GetProvidersConfig() ([]Provider, error)
 
+	// ValidateRepositories validates the repositories of the configured providers against the clusterctl provider contract.
+	ValidateRepositories(ctx context.Context, options ValidateRepositoriesOptions) ([]RepositoryValidation, error)
+
 	// GetProviderComponents returns the provider components for a given provider with options including targetNamespace.
 	GetProviderComponents(ctx context.Context, provider string, providerType clusterctlv1.ProviderType, options ComponentsOptions) (Components, error)
 
@@ -44,6 +48,9 @@ type Client interface {
 	// InitImages returns the list of images required for executing the init command.
 	InitImages(ctx context.Context, options InitOptions) ([]string, error)
 
+	// ResolveImages resolves the tags of container images to digests, so the images can be pinned.
+	ResolveImages(ctx context.Context, options ResolveImagesOptions) ([]ResolvedImage, error)
+
 	// GetClusterTemplate returns a workload cluster template.
 	GetClusterTemplate(ctx context.Context, options GetClusterTemplateOptions) (Template, error)
 
@@ -65,6 +72,9 @@ type Client interface {
 	// ApplyUpgrade executes an upgrade plan.
 	ApplyUpgrade(ctx context.Context, options ApplyUpgradeOptions) error
 
+	// DiffUpgrade returns the changes that ApplyUpgrade applies to the management cluster, without applying them.
+	DiffUpgrade(ctx context.Context, options ApplyUpgradeOptions) ([]ProviderUpgradeDiff, error)
+
 	// ProcessYAML provides a direct way to process a yaml and inspect its
 	// variables.
 	ProcessYAML(ctx context.Context, options ProcessYAMLOptions) (YamlPrinter, error)
@@ -72,6 +82,18 @@ type Client interface {
 	// DescribeCluster returns the object tree representing the status of a Cluster API cluster.
 	DescribeCluster(ctx context.Context, options DescribeClusterOptions) (*tree.ObjectTree, error)
 
+	// GetClusters returns the summary of the status of the workload clusters in the management cluster.
+	GetClusters(ctx context.Context, options GetClustersOptions) ([]ClusterSummary, error)
+
+	// GetFeatureGates returns the feature gates supported by each provider installed in the management cluster.
+	GetFeatureGates(ctx context.Context, options GetFeatureGatesOptions) ([]ProviderFeatureGates, error)
+
+	// SetFeatureGates changes the feature gates of the providers installed in the management cluster.
+	SetFeatureGates(ctx context.Context, options SetFeatureGatesOptions) error
+
+	// CollectDiagnostics writes to w a gzipped tarball with the diagnostics data of the management cluster, e.g. for support cases.
+	CollectDiagnostics(ctx context.Context, w io.Writer, options CollectDiagnosticsOptions) error
+
 	// AlphaClient is an Interface for alpha features in clusterctl
 	AlphaClient
 }
@@ -19,6 +19,7 @@ package client
 import (
 	"context"
 	"fmt"
+	"io"
 	"testing"
 	"time"
 
@@ -123,6 +124,18 @@ func (f fakeClient) ApplyUpgrade(ctx context.Context, options ApplyUpgradeOption
 	return f.internalClient.ApplyUpgrade(ctx, options)
 }
 
+func (f fakeClient) ValidateRepositories(ctx context.Context, options ValidateRepositoriesOptions) ([]RepositoryValidation, error) {
+	return f.internalClient.ValidateRepositories(ctx, options)
+}
+
+func (f fakeClient) ResolveImages(ctx context.Context, options ResolveImagesOptions) ([]ResolvedImage, error) {
+	return f.internalClient.ResolveImages(ctx, options)
+}
+
+func (f fakeClient) DiffUpgrade(ctx context.Context, options ApplyUpgradeOptions) ([]ProviderUpgradeDiff, error) {
+	return f.internalClient.DiffUpgrade(ctx, options)
+}
+
 func (f fakeClient) ProcessYAML(ctx context.Context, options ProcessYAMLOptions) (YamlPrinter, error) {
 	return f.internalClient.ProcessYAML(ctx, options)
 }
@@ -135,6 +148,22 @@ func (f fakeClient) DescribeCluster(ctx context.Context, options DescribeCluster
 	return f.internalClient.DescribeCluster(ctx, options)
 }
 
+func (f fakeClient) GetClusters(ctx context.Context, options GetClustersOptions) ([]ClusterSummary, error) {
+	return f.internalClient.GetClusters(ctx, options)
+}
+
+func (f fakeClient) GetFeatureGates(ctx context.Context, options GetFeatureGatesOptions) ([]ProviderFeatureGates, error) {
+	return f.internalClient.GetFeatureGates(ctx, options)
+}
+
+func (f fakeClient) SetFeatureGates(ctx context.Context, options SetFeatureGatesOptions) error {
+	return f.internalClient.SetFeatureGates(ctx, options)
+}
+
+func (f fakeClient) CollectDiagnostics(ctx context.Context, w io.Writer, options CollectDiagnosticsOptions) error {
+	return f.internalClient.CollectDiagnostics(ctx, w, options)
+}
+
 func (f fakeClient) RolloutPause(ctx context.Context, options RolloutPauseOptions) error {
 	return f.internalClient.RolloutPause(ctx, options)
 }
@@ -325,6 +354,14 @@ func (f *fakeClusterClient) Topology() cluster.TopologyClient {
 	return f.internalclient.Topology()
 }
 
+func (f *fakeClusterClient) Audit() cluster.AuditClient {
+	return f.internalclient.Audit()
+}
+
+func (f *fakeClusterClient) Diagnostics() cluster.DiagnosticsClient {
+	return f.internalclient.Diagnostics()
+}
+
 func (f *fakeClusterClient) WithObjs(objs ...client.Object) *fakeClusterClient {
 	f.fakeProxy.WithObjs(objs...)
 	return f
@@ -0,0 +1,318 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+)
+
+const (
+	// AuditNamespace is the namespace where the records of the clusterctl operations are stored.
+	AuditNamespace = "clusterctl-audit"
+
+	// OperationRecordDataName is the key of the operation record in the ConfigMaps of the audit trail.
+	OperationRecordDataName = "record.json"
+
+	// auditOperationLabel is the label set on the ConfigMaps of the audit trail to the name of the operation.
+	auditOperationLabel = "clusterctl.cluster.x-k8s.io/operation"
+
+	// maxOperationRecords is the number of operation records kept in the audit trail; older records are deleted.
+	maxOperationRecords = 100
+)
+
+// Operation is a clusterctl operation recorded into the audit trail.
+type Operation string
+
+const (
+	// InitOperation is recorded by clusterctl init.
+	InitOperation Operation = "init"
+
+	// UpgradeOperation is recorded by clusterctl upgrade apply.
+	UpgradeOperation Operation = "upgrade"
+
+	// MoveOperation is recorded by clusterctl move, both on the source and on the target management cluster.
+	MoveOperation Operation = "move"
+
+	// DeleteOperation is recorded by clusterctl delete.
+	DeleteOperation Operation = "delete"
+
+	// FeatureGatesOperation is recorded by clusterctl config feature-gates when changing feature gates.
+	FeatureGatesOperation Operation = "feature-gates"
+)
+
+// OperationOutcome is the outcome of a clusterctl operation.
+type OperationOutcome string
+
+const (
+	// OperationSucceeded is recorded when an operation completed successfully.
+	OperationSucceeded OperationOutcome = "Succeeded"
+
+	// OperationFailed is recorded when an operation failed.
+	OperationFailed OperationOutcome = "Failed"
+)
+
+// OperationRecord is the record of a clusterctl operation performed against a management cluster.
+type OperationRecord struct {
+	// Operation is the clusterctl operation.
+	Operation Operation `json:"operation"`
+
+	// User is the user authenticated by the management cluster.
+	User string `json:"user,omitempty"`
+
+	// LocalUser is the user running clusterctl on the local machine, in the user@host format.
+	LocalUser string `json:"localUser,omitempty"`
+
+	// StartTime is when the operation started.
+	StartTime metav1.Time `json:"startTime"`
+
+	// EndTime is when the operation completed.
+	EndTime metav1.Time `json:"endTime"`
+
+	// ClusterctlVersion is the version of clusterctl performing the operation.
+	ClusterctlVersion string `json:"clusterctlVersion,omitempty"`
+
+	// Providers are the providers added, changed or removed by the operation, or installed when the operation completed.
+	Providers []ProviderRecord `json:"providers,omitempty"`
+
+	// Namespace is the namespace of the objects the operation was performed against, if any, e.g. for move.
+	Namespace string `json:"namespace,omitempty"`
+
+	// ObjectCounts are the number of objects by kind the operation was performed against, e.g. for move.
+	ObjectCounts map[string]int `json:"objectCounts,omitempty"`
+
+	// Outcome is the outcome of the operation.
+	Outcome OperationOutcome `json:"outcome"`
+
+	// Error is the error returned by the operation, if it failed.
+	Error string `json:"error,omitempty"`
+}
+
+// ProviderRecord is the record of a provider in an OperationRecord.
+type ProviderRecord struct {
+	// Name is the name of the provider, e.g. kubeadm.
+	Name string `json:"name"`
+
+	// Type is the type of the provider, e.g. BootstrapProvider.
+	Type string `json:"type"`
+
+	// Namespace is the namespace the provider is installed in.
+	Namespace string `json:"namespace"`
+
+	// Version is the version of the provider when the operation completed; empty if the provider was removed.
+	Version string `json:"version,omitempty"`
+
+	// PreviousVersion is the version of the provider before the operation, if it was changed or removed.
+	PreviousVersion string `json:"previousVersion,omitempty"`
+}
+
+// AuditClient has methods to work with the audit trail of the clusterctl operations performed against a management cluster.
+type AuditClient interface {
+	// Record stores the record of an operation in the audit trail; User and LocalUser are detected if not set.
+	Record(ctx context.Context, record OperationRecord) error
+
+	// List returns the records of the operations in the audit trail, oldest first.
+	List(ctx context.Context) ([]OperationRecord, error)
+}
+
+// auditClient implements AuditClient.
+type auditClient struct {
+	proxy Proxy
+}
+
+// ensure auditClient implements AuditClient.
+var _ AuditClient = &auditClient{}
+
+// newAuditClient returns an auditClient.
+func newAuditClient(proxy Proxy) *auditClient {
+	return &auditClient{proxy: proxy}
+}
+
+func (a *auditClient) Record(ctx context.Context, record OperationRecord) error {
+	log := logf.Log
+
+	c, err := a.proxy.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	if record.User == "" {
+		record.User = authenticatedUser(ctx, c)
+	}
+	if record.LocalUser == "" {
+		record.LocalUser = localUser()
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal operation record")
+	}
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: AuditNamespace}}
+	if err := c.Create(ctx, ns); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create Namespace %s", AuditNamespace)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("clusterctl-%s-", record.Operation),
+			Namespace:    AuditNamespace,
+			Labels: map[string]string{
+				clusterctlv1.ClusterctlCoreLabel: clusterctlv1.ClusterctlCoreLabelAuditValue,
+				auditOperationLabel:              string(record.Operation),
+			},
+		},
+		Data: map[string]string{
+			OperationRecordDataName: string(data),
+		},
+	}
+	if err := c.Create(ctx, cm); err != nil {
+		return errors.Wrap(err, "failed to create operation record")
+	}
+	log.V(5).Info("Recorded operation", "ConfigMap", cm.Name)
+
+	// Record an Event too, so the operation shows up when looking at the recent events of the management cluster.
+	eventType := corev1.EventTypeNormal
+	if record.Outcome == OperationFailed {
+		eventType = corev1.EventTypeWarning
+	}
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: cm.Name + "-",
+			Namespace:    AuditNamespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Namespace:  cm.Namespace,
+			Name:       cm.Name,
+			UID:        cm.UID,
+		},
+		Reason:         fmt.Sprintf("Clusterctl%s%s", strings.ToUpper(string(record.Operation[:1])), record.Operation[1:]),
+		Message:        fmt.Sprintf("clusterctl %s by %s %s", record.Operation, record.User, strings.ToLower(string(record.Outcome))),
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: "clusterctl"},
+		FirstTimestamp: record.EndTime,
+		LastTimestamp:  record.EndTime,
+		Count:          1,
+	}
+	if err := c.Create(ctx, event); err != nil {
+		log.V(5).Info("Failed to record event for operation", "error", err)
+	}
+
+	return a.prune(ctx, c)
+}
+
+func (a *auditClient) List(ctx context.Context) ([]OperationRecord, error) {
+	c, err := a.proxy.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	configMaps, err := listOperationRecords(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]OperationRecord, 0, len(configMaps))
+	for _, cm := range configMaps {
+		record := OperationRecord{}
+		if err := json.Unmarshal([]byte(cm.Data[OperationRecordDataName]), &record); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal operation record %s", cm.Name)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// prune deletes the oldest operation records exceeding maxOperationRecords.
+func (a *auditClient) prune(ctx context.Context, c client.Client) error {
+	configMaps, err := listOperationRecords(ctx, c)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(configMaps)-maxOperationRecords; i++ {
+		if err := c.Delete(ctx, &configMaps[i]); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete operation record %s", configMaps[i].Name)
+		}
+	}
+	return nil
+}
+
+// listOperationRecords returns the ConfigMaps of the audit trail, oldest first.
+func listOperationRecords(ctx context.Context, c client.Client) ([]corev1.ConfigMap, error) {
+	configMapList := &corev1.ConfigMapList{}
+	if err := c.List(ctx, configMapList, client.InNamespace(AuditNamespace), client.MatchingLabels{
+		clusterctlv1.ClusterctlCoreLabel: clusterctlv1.ClusterctlCoreLabelAuditValue,
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to list operation records")
+	}
+	configMaps := configMapList.Items
+	sort.SliceStable(configMaps, func(i, j int) bool {
+		ti, tj := startTime(configMaps[i]), startTime(configMaps[j])
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return configMaps[i].Name < configMaps[j].Name
+	})
+	return configMaps, nil
+}
+
+// startTime returns the start time of the operation recorded in the ConfigMap, falling back to its creation timestamp.
+func startTime(cm corev1.ConfigMap) metav1.Time {
+	record := OperationRecord{}
+	if err := json.Unmarshal([]byte(cm.Data[OperationRecordDataName]), &record); err != nil || record.StartTime.IsZero() {
+		return cm.CreationTimestamp
+	}
+	return record.StartTime
+}
+
+// authenticatedUser returns the user authenticated by the management cluster, if it supports SelfSubjectReviews.
+func authenticatedUser(ctx context.Context, c client.Client) string {
+	review := &authenticationv1.SelfSubjectReview{}
+	if err := c.Create(ctx, review); err != nil {
+		logf.Log.V(5).Info("Failed to detect the user authenticated by the management cluster", "error", err)
+		return ""
+	}
+	return review.Status.UserInfo.Username
+}
+
+// localUser returns the user running clusterctl on the local machine.
+func localUser() string {
+	name := ""
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	host, _ := os.Hostname()
+	if name == "" || host == "" {
+		return name
+	}
+	return fmt.Sprintf("%s@%s", name, host)
+}
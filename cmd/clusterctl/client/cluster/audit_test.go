@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_auditClient_Record(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	proxy := test.NewFakeProxy()
+	a := newAuditClient(proxy)
+
+	start := time.Now()
+	record := OperationRecord{
+		Operation: UpgradeOperation,
+		User:      "admin",
+		StartTime: metav1.NewTime(start),
+		EndTime:   metav1.NewTime(start.Add(time.Minute)),
+		Providers: []ProviderRecord{
+			{Name: "cluster-api", Type: "CoreProvider", Namespace: "capi-system", Version: "v1.1.0", PreviousVersion: "v1.0.0"},
+		},
+		Outcome: OperationSucceeded,
+	}
+	g.Expect(a.Record(ctx, record)).To(Succeed())
+
+	records, err := a.List(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(records).To(HaveLen(1))
+	g.Expect(records[0].Operation).To(Equal(UpgradeOperation))
+	g.Expect(records[0].User).To(Equal("admin"))
+	g.Expect(records[0].Providers).To(Equal(record.Providers))
+	g.Expect(records[0].Outcome).To(Equal(OperationSucceeded))
+
+	c, err := proxy.NewClient(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	events := &corev1.EventList{}
+	g.Expect(c.List(ctx, events, client.InNamespace(AuditNamespace))).To(Succeed())
+	g.Expect(events.Items).To(HaveLen(1))
+	g.Expect(events.Items[0].Reason).To(Equal("ClusterctlUpgrade"))
+	g.Expect(events.Items[0].Type).To(Equal(corev1.EventTypeNormal))
+}
+
+func Test_auditClient_RecordPrunesOldestRecords(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	a := newAuditClient(test.NewFakeProxy())
+
+	// Times are serialized with a precision of one second.
+	start := time.Now().Truncate(time.Second)
+	for i := 0; i < maxOperationRecords+2; i++ {
+		g.Expect(a.Record(ctx, OperationRecord{
+			Operation: InitOperation,
+			User:      "admin",
+			StartTime: metav1.NewTime(start.Add(time.Duration(i) * time.Second)),
+			Outcome:   OperationSucceeded,
+		})).To(Succeed())
+	}
+
+	records, err := a.List(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(records).To(HaveLen(maxOperationRecords))
+	g.Expect(records[0].StartTime.Time).To(BeTemporally("==", start.Add(2*time.Second)))
+}
@@ -0,0 +1,476 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+)
+
+// backupManifestFilename is the name of the file, written in the backup directory, with the manifest of the latest
+// snapshot of the backup.
+const backupManifestFilename = "clusterctl-backup-manifest.json"
+
+// backupObjectsDirectory is the directory, in the backup directory, where the files of all the snapshots are stored
+// by their SHA256 checksum, so files unchanged across snapshots are stored only once.
+const backupObjectsDirectory = "objects"
+
+// backupSnapshotsDirectory is the directory, in the backup directory, where the manifest of each snapshot is stored.
+const backupSnapshotsDirectory = "snapshots"
+
+// BackupEncryptionKeySize is the size of the keys used for encrypting Secrets in a backup.
+const BackupEncryptionKeySize = 32
+
+// BackupOptions are the options for writing a backup with ToDirectory and for restoring it with FromDirectory.
+type BackupOptions struct {
+	// EncryptionKey is the key used for encrypting the data of the Secrets with AES-256-GCM when writing a backup,
+	// and for decrypting it when restoring the backup. If empty, Secrets are written in plain text.
+	EncryptionKey []byte
+
+	// Verify requires, before restoring any object, the backup to have a manifest, all the files in the backup to match
+	// the checksums in the manifest, and the encryption key to match the key used for writing the backup.
+	Verify bool
+}
+
+func (o BackupOptions) validate() error {
+	if len(o.EncryptionKey) > 0 && len(o.EncryptionKey) != BackupEncryptionKeySize {
+		return errors.Errorf("invalid encryption key: expected %d bytes, got %d", BackupEncryptionKeySize, len(o.EncryptionKey))
+	}
+	return nil
+}
+
+// backupManifest describes the files in a snapshot of a backup directory.
+type backupManifest struct {
+	// Snapshot is incremented every time a backup is written to the same directory.
+	Snapshot int `json:"snapshot"`
+
+	// CreatedAt is the time when the snapshot has been written.
+	CreatedAt time.Time `json:"createdAt"`
+
+	// EncryptionKeyID is the ID of the key used for encrypting Secrets, if any.
+	EncryptionKeyID string `json:"encryptionKeyID,omitempty"`
+
+	// Files are the files in the snapshot, keyed by file name.
+	Files map[string]backupFile `json:"files"`
+}
+
+// backupFile describes a file in a snapshot of a backup directory.
+type backupFile struct {
+	// SHA256 is the checksum of the file, which is also the name of the file in the objects directory.
+	SHA256 string `json:"sha256"`
+
+	// Snapshot is the snapshot when the file has been written last time.
+	Snapshot int `json:"snapshot"`
+}
+
+// readBackupManifest reads the manifest in a backup directory; it returns nil if the manifest does not exist, e.g.
+// for backups written by older versions of clusterctl.
+func readBackupManifest(directory string) (*backupManifest, error) {
+	data, err := os.ReadFile(filepath.Clean(filepath.Join(directory, backupManifestFilename)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	manifest := &backupManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, errors.Wrapf(err, "failed to read backup manifest %s", backupManifestFilename)
+	}
+	return manifest, nil
+}
+
+// backupObjectPath returns the path of the file with the given checksum in a backup directory.
+func backupObjectPath(directory, checksum string) string {
+	return filepath.Clean(filepath.Join(directory, backupObjectsDirectory, checksum))
+}
+
+// backupSnapshotPath returns the path of the manifest of the given snapshot in a backup directory.
+func backupSnapshotPath(directory string, snapshot int) string {
+	return filepath.Clean(filepath.Join(directory, backupSnapshotsDirectory, fmt.Sprintf("%d.json", snapshot)))
+}
+
+// readBackupObject reads a file of a snapshot from a backup directory.
+func readBackupObject(directory string, file backupFile) ([]byte, error) {
+	return os.ReadFile(backupObjectPath(directory, file.SHA256))
+}
+
+// writeFileAtomically writes a file by writing a temporary file in the same directory and then renaming it, so
+// the file is never left partially written.
+func writeFileAtomically(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck // The temporary file does not exist anymore if it has been renamed.
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// backupWriter writes the files of a backup snapshot to a directory.
+// Files are stored in the objects directory by their checksum, so files with the same content of a previous snapshot
+// are not written again, and files of previous snapshots are never changed or removed. The snapshot becomes the
+// latest one only when completing it, by atomically replacing the manifest of the backup; if writing a snapshot
+// is interrupted, the backup still contains the previous snapshot.
+type backupWriter struct {
+	directory string
+	key       []byte
+	previous  *backupManifest
+	current   *backupManifest
+}
+
+func newBackupWriter(directory string, options BackupOptions) (*backupWriter, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
+	previous, err := readBackupManifest(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dir := range []string{backupObjectsDirectory, backupSnapshotsDirectory} {
+		if err := os.MkdirAll(filepath.Join(directory, dir), 0700); err != nil {
+			return nil, err
+		}
+	}
+
+	current := &backupManifest{
+		Snapshot:  1,
+		CreatedAt: time.Now().UTC(),
+		Files:     map[string]backupFile{},
+	}
+	if previous != nil {
+		current.Snapshot = previous.Snapshot + 1
+	}
+	if len(options.EncryptionKey) > 0 {
+		current.EncryptionKeyID = encryptionKeyID(options.EncryptionKey)
+	}
+
+	return &backupWriter{
+		directory: directory,
+		key:       options.EncryptionKey,
+		previous:  previous,
+		current:   current,
+	}, nil
+}
+
+// write writes an object to a file of the backup, encrypting the data of Secrets if an encryption key is set.
+func (w *backupWriter) write(filename string, obj *unstructured.Unstructured) error {
+	if len(w.key) > 0 && isSecret(obj) {
+		if err := encryptSecret(obj, w.key); err != nil {
+			return err
+		}
+	}
+
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	checksum := fileChecksum(data)
+	file := backupFile{SHA256: checksum, Snapshot: w.current.Snapshot}
+
+	// If a file with the same content has been written by a previous snapshot, keep it as it is.
+	if existing, err := readBackupObject(w.directory, file); err == nil && fileChecksum(existing) == checksum {
+		if w.previous != nil {
+			if previous, ok := w.previous.Files[filename]; ok && previous.SHA256 == checksum {
+				file = previous
+			}
+		}
+		w.current.Files[filename] = file
+		return nil
+	}
+
+	if err := writeFileAtomically(backupObjectPath(w.directory, checksum), data); err != nil {
+		return err
+	}
+
+	w.current.Files[filename] = file
+	return nil
+}
+
+// complete writes the manifest of the snapshot, and then atomically replaces the manifest of the backup with it.
+func (w *backupWriter) complete() error {
+	data, err := json.MarshalIndent(w.current, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomically(backupSnapshotPath(w.directory, w.current.Snapshot), data); err != nil {
+		return errors.Wrapf(err, "failed to write the manifest of snapshot %d", w.current.Snapshot)
+	}
+	return writeFileAtomically(filepath.Join(w.directory, backupManifestFilename), data)
+}
+
+// verifyBackup checks that a backup directory has a manifest, that all the files listed in the manifest exist and match
+// their checksum, that there are no files not part of the backup, and that the encryption key is the one used for
+// writing the backup.
+func verifyBackup(directory string, manifest *backupManifest, key []byte) error {
+	if manifest == nil {
+		return errors.Errorf("the backup manifest %s does not exist in %s", backupManifestFilename, directory)
+	}
+
+	errList := []error{}
+	switch {
+	case manifest.EncryptionKeyID != "" && len(key) == 0:
+		errList = append(errList, errors.New("the backup is encrypted, but no encryption key is provided"))
+	case manifest.EncryptionKeyID != "" && manifest.EncryptionKeyID != encryptionKeyID(key):
+		errList = append(errList, errors.New("the backup is encrypted with a different encryption key"))
+	}
+
+	filenames := make([]string, 0, len(manifest.Files))
+	for filename := range manifest.Files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		data, err := readBackupObject(directory, manifest.Files[filename])
+		if err != nil {
+			if os.IsNotExist(err) {
+				errList = append(errList, errors.Errorf("file %s is missing", filename))
+				continue
+			}
+			return err
+		}
+		if fileChecksum(data) != manifest.Files[filename].SHA256 {
+			errList = append(errList, errors.Errorf("file %s does not match the checksum in the manifest", filename))
+		}
+	}
+
+	files, err := os.ReadDir(directory)
+	if err != nil {
+		return err
+	}
+	for i := range files {
+		switch filename := files[i].Name(); filename {
+		case backupManifestFilename, backupObjectsDirectory, backupSnapshotsDirectory:
+		default:
+			errList = append(errList, errors.Errorf("file %s is not part of the backup", filename))
+		}
+	}
+
+	return kerrors.NewAggregate(errList)
+}
+
+// readBackupObjects reads the YAML or JSON documents of the files in the latest snapshot of a backup directory, or of
+// all the files in the directory if it has no manifest, e.g. for backups written by older versions of clusterctl.
+func readBackupObjects(directory string) ([][]byte, error) {
+	manifest, err := readBackupManifest(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest == nil {
+		files, err := os.ReadDir(directory)
+		if err != nil {
+			return nil, err
+		}
+
+		rawYAMLs := make([][]byte, 0, len(files))
+		for i := range files {
+			// Directories are not part of backups written by older versions of clusterctl, e.g. the objects
+			// directory of a first snapshot that has been interrupted.
+			if files[i].IsDir() {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Clean(filepath.Join(directory, files[i].Name())))
+			if err != nil {
+				return nil, err
+			}
+			rawYAMLs = append(rawYAMLs, data)
+		}
+		return rawYAMLs, nil
+	}
+
+	filenames := make([]string, 0, len(manifest.Files))
+	for filename := range manifest.Files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	rawYAMLs := make([][]byte, 0, len(filenames))
+	for _, filename := range filenames {
+		data, err := readBackupObject(directory, manifest.Files[filename])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read file %s", filename)
+		}
+		rawYAMLs = append(rawYAMLs, data)
+	}
+	return rawYAMLs, nil
+}
+
+func fileChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// encryptionKeyID returns an ID for an encryption key, which allows to detect if a backup is restored with the wrong key
+// without disclosing the key.
+func encryptionKeyID(key []byte) string {
+	mac := hmac.New(sha256.New, deriveBackupKey(key, "key-id"))
+	mac.Write([]byte(backupManifestFilename))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// deriveBackupKey derives from the encryption key a subkey for the given purpose, so the encryption key is used only
+// for AES-256-GCM and never directly as an HMAC key.
+func deriveBackupKey(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("clusterctl-backup/" + purpose))
+	return mac.Sum(nil)
+}
+
+func isSecret(obj *unstructured.Unstructured) bool {
+	return obj.GetAPIVersion() == "v1" && obj.GetKind() == "Secret"
+}
+
+// encryptSecret encrypts the values in the data of a Secret with AES-256-GCM, and marks the Secret with the
+// BackupEncryptedAnnotation.
+// Nb. The nonce is derived from the value being encrypted, with an HMAC keyed with a subkey of the encryption key,
+// instead of being random, so the same Secret is always encrypted to the same content, and unchanged Secrets are
+// not written again in incremental snapshots.
+func encryptSecret(obj *unstructured.Unstructured, key []byte) error {
+	aead, err := newBackupCipher(key)
+	if err != nil {
+		return err
+	}
+	nonceKey := deriveBackupKey(key, "nonce")
+
+	data, _, err := unstructured.NestedStringMap(obj.Object, "data")
+	if err != nil {
+		return errors.Wrapf(err, "failed to read data from Secret %s/%s", obj.GetNamespace(), obj.GetName())
+	}
+	for k, v := range data {
+		plaintext, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return errors.Wrapf(err, "failed to decode %s in Secret %s/%s", k, obj.GetNamespace(), obj.GetName())
+		}
+		additionalData := secretAdditionalData(obj, k)
+
+		mac := hmac.New(sha256.New, nonceKey)
+		mac.Write(additionalData)
+		mac.Write(plaintext)
+		nonce := mac.Sum(nil)[:aead.NonceSize()]
+
+		data[k] = base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, additionalData))
+	}
+	if len(data) > 0 {
+		if err := unstructured.SetNestedStringMap(obj.Object, data, "data"); err != nil {
+			return err
+		}
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[clusterctlv1.BackupEncryptedAnnotation] = encryptionKeyID(key)
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// decryptSecret decrypts the data of a Secret encrypted by encryptSecret, and removes the BackupEncryptedAnnotation;
+// Secrets without the annotation are left unchanged.
+func decryptSecret(obj *unstructured.Unstructured, key []byte) error {
+	annotations := obj.GetAnnotations()
+	keyID, ok := annotations[clusterctlv1.BackupEncryptedAnnotation]
+	if !ok {
+		return nil
+	}
+	if len(key) == 0 {
+		return errors.Errorf("Secret %s/%s is encrypted, but no encryption key is provided", obj.GetNamespace(), obj.GetName())
+	}
+	if keyID != encryptionKeyID(key) {
+		return errors.Errorf("Secret %s/%s is encrypted with a different encryption key", obj.GetNamespace(), obj.GetName())
+	}
+
+	aead, err := newBackupCipher(key)
+	if err != nil {
+		return err
+	}
+
+	data, _, err := unstructured.NestedStringMap(obj.Object, "data")
+	if err != nil {
+		return errors.Wrapf(err, "failed to read data from Secret %s/%s", obj.GetNamespace(), obj.GetName())
+	}
+	for k, v := range data {
+		ciphertext, err := base64.StdEncoding.DecodeString(v)
+		if err != nil || len(ciphertext) < aead.NonceSize() {
+			return errors.Errorf("failed to decode %s in Secret %s/%s", k, obj.GetNamespace(), obj.GetName())
+		}
+		nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+		plaintext, err := aead.Open(nil, nonce, ciphertext, secretAdditionalData(obj, k))
+		if err != nil {
+			return errors.Wrapf(err, "failed to decrypt %s in Secret %s/%s", k, obj.GetNamespace(), obj.GetName())
+		}
+		data[k] = base64.StdEncoding.EncodeToString(plaintext)
+	}
+	if len(data) > 0 {
+		if err := unstructured.SetNestedStringMap(obj.Object, data, "data"); err != nil {
+			return err
+		}
+	}
+
+	delete(annotations, clusterctlv1.BackupEncryptedAnnotation)
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+func newBackupCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid encryption key")
+	}
+	return cipher.NewGCM(block)
+}
+
+// secretAdditionalData binds an encrypted value to the Secret and to the key it belongs to, so encrypted values
+// cannot be swapped across Secrets in the backup.
+func secretAdditionalData(obj *unstructured.Unstructured, key string) []byte {
+	return []byte(fmt.Sprintf("%s/%s/%s", obj.GetNamespace(), obj.GetName(), key))
+}
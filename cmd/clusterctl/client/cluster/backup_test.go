@@ -0,0 +1,284 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+var testBackupEncryptionKey = []byte("0123456789abcdef0123456789abcdef")
+
+func newTestBackupWriter(t *testing.T, directory string) *backupWriter {
+	t.Helper()
+
+	w, err := newBackupWriter(directory, BackupOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func newTestSecret(name string, data map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("Secret")
+	obj.SetNamespace("ns1")
+	obj.SetName(name)
+	encoded := map[string]interface{}{}
+	for k, v := range data {
+		encoded[k] = base64.StdEncoding.EncodeToString([]byte(v))
+	}
+	obj.Object["data"] = encoded
+	return obj
+}
+
+func Test_backupWriter(t *testing.T) {
+	g := NewWithT(t)
+
+	dir := t.TempDir()
+
+	// First snapshot.
+	w, err := newBackupWriter(dir, BackupOptions{})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(w.write("a.yaml", newTestSecret("a", map[string]string{"value": "a"}))).To(Succeed())
+	g.Expect(w.write("b.yaml", newTestSecret("b", map[string]string{"value": "b"}))).To(Succeed())
+	g.Expect(w.complete()).To(Succeed())
+
+	manifest, err := readBackupManifest(dir)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(manifest.Snapshot).To(Equal(1))
+	g.Expect(manifest.Files).To(HaveLen(2))
+	g.Expect(manifest.Files["a.yaml"].Snapshot).To(Equal(1))
+	g.Expect(verifyBackup(dir, manifest, nil)).To(Succeed())
+
+	// Second snapshot: a is unchanged, b is removed, c is added.
+	w, err = newBackupWriter(dir, BackupOptions{})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(w.write("a.yaml", newTestSecret("a", map[string]string{"value": "a"}))).To(Succeed())
+	g.Expect(w.write("c.yaml", newTestSecret("c", map[string]string{"value": "c"}))).To(Succeed())
+	g.Expect(w.complete()).To(Succeed())
+
+	manifest, err = readBackupManifest(dir)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(manifest.Snapshot).To(Equal(2))
+	g.Expect(manifest.Files).To(HaveLen(2))
+	g.Expect(manifest.Files["a.yaml"].Snapshot).To(Equal(1))
+	g.Expect(manifest.Files["c.yaml"].Snapshot).To(Equal(2))
+	g.Expect(verifyBackup(dir, manifest, nil)).To(Succeed())
+
+	// The manifest of each snapshot is kept, and the files of previous snapshots are not removed.
+	g.Expect(backupSnapshotPath(dir, 1)).To(BeAnExistingFile())
+	g.Expect(backupSnapshotPath(dir, 2)).To(BeAnExistingFile())
+	objs, err := (&objectMover{}).filesToObjs(dir)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(objectNames(objs)).To(ConsistOf("a", "c"))
+
+	// Third snapshot, interrupted before completing: the backup still contains the second snapshot.
+	w, err = newBackupWriter(dir, BackupOptions{})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(w.write("a.yaml", newTestSecret("a", map[string]string{"value": "changed"}))).To(Succeed())
+	g.Expect(w.write("d.yaml", newTestSecret("d", map[string]string{"value": "d"}))).To(Succeed())
+
+	manifest, err = readBackupManifest(dir)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(manifest.Snapshot).To(Equal(2))
+	g.Expect(verifyBackup(dir, manifest, nil)).To(Succeed())
+	objs, err = (&objectMover{}).filesToObjs(dir)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(objectNames(objs)).To(ConsistOf("a", "c"))
+	value, _, _ := unstructured.NestedString(objs[0].Object, "data", "value")
+	g.Expect(value).To(Equal(base64.StdEncoding.EncodeToString([]byte("a"))))
+}
+
+func objectNames(objs []unstructured.Unstructured) []string {
+	names := make([]string, 0, len(objs))
+	for i := range objs {
+		names = append(names, objs[i].GetName())
+	}
+	return names
+}
+
+// backupObjectPathOf returns the path of a file of the latest snapshot of a backup directory.
+func backupObjectPathOf(dir, filename string) (string, error) {
+	manifest, err := readBackupManifest(dir)
+	if err != nil {
+		return "", err
+	}
+	return backupObjectPath(dir, manifest.Files[filename].SHA256), nil
+}
+
+func Test_verifyBackup(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  func(dir string) error
+		key     []byte
+		wantErr string
+	}{
+		{
+			name: "valid backup",
+			key:  testBackupEncryptionKey,
+		},
+		{
+			name:    "missing manifest",
+			tamper:  func(dir string) error { return os.Remove(filepath.Join(dir, backupManifestFilename)) },
+			key:     testBackupEncryptionKey,
+			wantErr: "does not exist",
+		},
+		{
+			name:    "missing file",
+			tamper: func(dir string) error {
+				path, err := backupObjectPathOf(dir, "a.yaml")
+				if err != nil {
+					return err
+				}
+				return os.Remove(path)
+			},
+			key:     testBackupEncryptionKey,
+			wantErr: "file a.yaml is missing",
+		},
+		{
+			name: "modified file",
+			tamper: func(dir string) error {
+				path, err := backupObjectPathOf(dir, "a.yaml")
+				if err != nil {
+					return err
+				}
+				return os.WriteFile(path, []byte("{}"), 0600)
+			},
+			key:     testBackupEncryptionKey,
+			wantErr: "file a.yaml does not match the checksum",
+		},
+		{
+			name:    "unexpected file",
+			tamper:  func(dir string) error { return os.WriteFile(filepath.Join(dir, "b.yaml"), []byte("{}"), 0600) },
+			key:     testBackupEncryptionKey,
+			wantErr: "file b.yaml is not part of the backup",
+		},
+		{
+			name:    "missing encryption key",
+			wantErr: "no encryption key is provided",
+		},
+		{
+			name:    "wrong encryption key",
+			key:     []byte("abcdef0123456789abcdef0123456789"),
+			wantErr: "different encryption key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			dir := t.TempDir()
+			w, err := newBackupWriter(dir, BackupOptions{EncryptionKey: testBackupEncryptionKey})
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(w.write("a.yaml", newTestSecret("a", map[string]string{"value": "a"}))).To(Succeed())
+			g.Expect(w.complete()).To(Succeed())
+
+			if tt.tamper != nil {
+				g.Expect(tt.tamper(dir)).To(Succeed())
+			}
+
+			manifest, err := readBackupManifest(dir)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			err = verifyBackup(dir, manifest, tt.key)
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
+
+func Test_encryptSecret(t *testing.T) {
+	g := NewWithT(t)
+
+	secret := newTestSecret("foo", map[string]string{"value": "secret"})
+	original := secret.DeepCopy()
+
+	g.Expect(encryptSecret(secret, testBackupEncryptionKey)).To(Succeed())
+	g.Expect(secret.GetAnnotations()).To(HaveKey(clusterctlv1.BackupEncryptedAnnotation))
+	value, _, _ := unstructured.NestedString(secret.Object, "data", "value")
+	g.Expect(value).ToNot(Equal(original.Object["data"].(map[string]interface{})["value"]))
+
+	// Encryption is deterministic, so unchanged Secrets are not written again in incremental snapshots.
+	again := original.DeepCopy()
+	g.Expect(encryptSecret(again, testBackupEncryptionKey)).To(Succeed())
+	g.Expect(again).To(Equal(secret))
+
+	// Decryption fails with a different key.
+	g.Expect(decryptSecret(secret.DeepCopy(), []byte("abcdef0123456789abcdef0123456789"))).ToNot(Succeed())
+	g.Expect(decryptSecret(secret.DeepCopy(), nil)).ToNot(Succeed())
+
+	// Decryption fails if the encrypted value is moved to another Secret.
+	moved := secret.DeepCopy()
+	moved.SetName("bar")
+	g.Expect(decryptSecret(moved, testBackupEncryptionKey)).ToNot(Succeed())
+
+	g.Expect(decryptSecret(secret, testBackupEncryptionKey)).To(Succeed())
+	g.Expect(secret).To(Equal(original))
+}
+
+func Test_objectMover_toDirectoryEncrypted(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx := context.Background()
+
+	graph := getObjectGraphWithObjs(test.NewFakeCluster("ns1", "foo").Objs())
+	g.Expect(graph.getDiscoveryTypes(ctx)).To(Succeed())
+	g.Expect(graph.Discovery(ctx, "")).To(Succeed())
+
+	mover := objectMover{
+		fromProxy: graph.proxy,
+	}
+
+	dir := t.TempDir()
+	options := BackupOptions{EncryptionKey: testBackupEncryptionKey, Verify: true}
+	g.Expect(mover.toDirectory(ctx, graph, dir, options)).To(Succeed())
+
+	manifest, err := readBackupManifest(dir)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(verifyBackup(dir, manifest, testBackupEncryptionKey)).To(Succeed())
+
+	objs, err := mover.filesToObjs(dir)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(objs).To(HaveLen(len(graph.uidToNode)))
+
+	secrets := 0
+	for i := range objs {
+		if !isSecret(&objs[i]) {
+			g.Expect(objs[i].GetAnnotations()).ToNot(HaveKey(clusterctlv1.BackupEncryptedAnnotation))
+			continue
+		}
+		secrets++
+		g.Expect(objs[i].GetAnnotations()).To(HaveKey(clusterctlv1.BackupEncryptedAnnotation))
+		g.Expect(decryptSecret(&objs[i], testBackupEncryptionKey)).To(Succeed())
+		g.Expect(objs[i].GetAnnotations()).ToNot(HaveKey(clusterctlv1.BackupEncryptedAnnotation))
+	}
+	g.Expect(secrets).To(BeNumerically(">", 0))
+}
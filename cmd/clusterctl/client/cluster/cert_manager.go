@@ -419,8 +419,8 @@ func (cm *certManagerClient) getManifestObjs(ctx context.Context, certManagerCon
 	}
 
 	// Apply image overrides.
-	objs, err = util.FixImages(objs, func(image string) (string, error) {
-		return cm.configClient.ImageMeta().AlterImage(config.CertManagerImageComponent, image)
+	objs, err = util.FixImages(objs, func(containerName, image string) (string, error) {
+		return cm.configClient.ImageMeta().AlterContainerImage(config.CertManagerImageComponent, containerName, image)
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to apply image override to the cert-manager manifest")
@@ -82,6 +82,12 @@ type Client interface {
 
 	// Topology returns a TopologyClient that can be used for performing dry run executions of the topology reconciler.
 	Topology() TopologyClient
+
+	// Audit returns an AuditClient that can be used for recording the clusterctl operations performed against the management cluster.
+	Audit() AuditClient
+
+	// Diagnostics returns a DiagnosticsClient that can be used for collecting diagnostics data from the management cluster.
+	Diagnostics() DiagnosticsClient
 }
 
 // PollImmediateWaiter tries a condition func until it returns true, an error, or the timeout is reached.
@@ -147,6 +153,14 @@ func (c *clusterClient) Topology() TopologyClient {
 	return newTopologyClient(c.proxy, c.ProviderInventory())
 }
 
+func (c *clusterClient) Audit() AuditClient {
+	return newAuditClient(c.proxy)
+}
+
+func (c *clusterClient) Diagnostics() DiagnosticsClient {
+	return newDiagnosticsClient(c.proxy)
+}
+
 // Option is a configuration option supplied to New.
 type Option func(*clusterClient)
 
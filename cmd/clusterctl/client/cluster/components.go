@@ -23,11 +23,9 @@ import (
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
-	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -71,6 +69,12 @@ type ComponentsClient interface {
 
 	// ValidateNoObjectsExist checks if custom resources of the custom resource definitions exist and returns an error if so.
 	ValidateNoObjectsExist(ctx context.Context, provider clusterctlv1.Provider) error
+
+	// GetUsage returns the Clusters and Machines depending on the provider.
+	GetUsage(ctx context.Context, provider clusterctlv1.Provider) (*ProviderUsage, error)
+
+	// CountObjects returns the number of objects existing for each of the Kinds defined in the provider's CRDs.
+	CountObjects(ctx context.Context, provider clusterctlv1.Provider) ([]ProviderObjects, error)
 }
 
 // providerComponents implements ComponentsClient.
@@ -266,46 +270,14 @@ func (p *providerComponents) ValidateNoObjectsExist(ctx context.Context, provide
 	log := logf.Log
 	log.Info("Checking for CRs", "Provider", provider.Name, "Version", provider.Version, "Namespace", provider.Namespace)
 
-	proxyClient, err := p.proxy.NewClient(ctx)
+	objects, err := p.CountObjects(ctx, provider)
 	if err != nil {
 		return err
 	}
 
-	// Fetch all the components belonging to a provider.
-	// We want that the delete operation is able to clean-up everything.
-	labels := map[string]string{
-		clusterctlv1.ClusterctlLabel: "",
-		clusterv1.ProviderNameLabel:  provider.ManifestLabel(),
-	}
-
-	customResources := &apiextensionsv1.CustomResourceDefinitionList{}
-	if err := proxyClient.List(ctx, customResources, client.MatchingLabels(labels)); err != nil {
-		return err
-	}
-
-	// Filter the resources according to the delete options
 	crsHavingObjects := []string{}
-	for _, crd := range customResources.Items {
-		crd := crd
-		storageVersion, err := storageVersionForCRD(&crd)
-		if err != nil {
-			return err
-		}
-
-		list := &unstructured.UnstructuredList{}
-		list.SetGroupVersionKind(schema.GroupVersionKind{
-			Group:   crd.Spec.Group,
-			Version: storageVersion,
-			Kind:    crd.Spec.Names.ListKind,
-		})
-
-		if err := proxyClient.List(ctx, list); err != nil {
-			return err
-		}
-
-		if len(list.Items) > 0 {
-			crsHavingObjects = append(crsHavingObjects, crd.Kind)
-		}
+	for _, o := range objects {
+		crsHavingObjects = append(crsHavingObjects, o.Kind)
 	}
 
 	if len(crsHavingObjects) > 0 {
@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+)
+
+// ProviderUsage lists the Clusters and Machines depending on a provider, e.g. because they reference
+// objects of the Kinds defined in the provider's CRDs; all the Clusters and Machines depend on the core provider.
+// Deleting a provider in use leaves the workload clusters without a controller reconciling them.
+type ProviderUsage struct {
+	Provider clusterctlv1.Provider
+
+	// Clusters depending on the provider, in the namespace/name format.
+	Clusters []string
+
+	// Machines depending on the provider, in the namespace/name format.
+	Machines []string
+}
+
+// InUse returns true if any Cluster or Machine depends on the provider.
+func (u *ProviderUsage) InUse() bool {
+	return len(u.Clusters) > 0 || len(u.Machines) > 0
+}
+
+// ProviderObjects is the number of objects existing for a Kind defined in the provider's CRDs.
+type ProviderObjects struct {
+	// Kind of the objects, in the Kind.group format.
+	Kind string
+
+	// Count is the number of objects.
+	Count int
+}
+
+func (p *providerComponents) GetUsage(ctx context.Context, provider clusterctlv1.Provider) (*ProviderUsage, error) {
+	proxyClient, err := p.proxy.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &ProviderUsage{Provider: provider}
+
+	// Gets the Kinds defined by the provider; Clusters and Machines referencing objects of those Kinds depend on the provider.
+	kinds := sets.Set[schema.GroupKind]{}
+	isCoreProvider := provider.GetProviderType() == clusterctlv1.CoreProviderType
+	if !isCoreProvider {
+		crds, err := providerCRDs(ctx, proxyClient, provider)
+		if err != nil {
+			return nil, err
+		}
+		for _, crd := range crds {
+			kinds.Insert(schema.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind})
+		}
+		if kinds.Len() == 0 {
+			return usage, nil
+		}
+	}
+	dependsOnProvider := func(refs ...*corev1.ObjectReference) bool {
+		if isCoreProvider {
+			return true
+		}
+		for _, ref := range refs {
+			if ref != nil && kinds.Has(ref.GroupVersionKind().GroupKind()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	clusters := &clusterv1.ClusterList{}
+	if err := proxyClient.List(ctx, clusters); err != nil && !isKindNotServed(err) {
+		return nil, errors.Wrap(err, "failed to list Clusters")
+	}
+	for _, c := range clusters.Items {
+		if dependsOnProvider(c.Spec.InfrastructureRef, c.Spec.ControlPlaneRef) {
+			usage.Clusters = append(usage.Clusters, client.ObjectKeyFromObject(&c).String())
+		}
+	}
+
+	machines := &clusterv1.MachineList{}
+	if err := proxyClient.List(ctx, machines); err != nil && !isKindNotServed(err) {
+		return nil, errors.Wrap(err, "failed to list Machines")
+	}
+	for _, m := range machines.Items {
+		if dependsOnProvider(&m.Spec.InfrastructureRef, m.Spec.Bootstrap.ConfigRef) {
+			usage.Machines = append(usage.Machines, client.ObjectKeyFromObject(&m).String())
+		}
+	}
+
+	sort.Strings(usage.Clusters)
+	sort.Strings(usage.Machines)
+	return usage, nil
+}
+
+func (p *providerComponents) CountObjects(ctx context.Context, provider clusterctlv1.Provider) ([]ProviderObjects, error) {
+	proxyClient, err := p.proxy.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	crds, err := providerCRDs(ctx, proxyClient, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := []ProviderObjects{}
+	for i := range crds {
+		crd := &crds[i]
+		storageVersion, err := storageVersionForCRD(crd)
+		if err != nil {
+			return nil, err
+		}
+
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(schema.GroupVersionKind{
+			Group:   crd.Spec.Group,
+			Version: storageVersion,
+			Kind:    crd.Spec.Names.ListKind,
+		})
+		if err := proxyClient.List(ctx, list); err != nil {
+			return nil, err
+		}
+
+		if len(list.Items) > 0 {
+			objects = append(objects, ProviderObjects{
+				Kind:  schema.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}.String(),
+				Count: len(list.Items),
+			})
+		}
+	}
+	return objects, nil
+}
+
+// providerCRDs returns the CRDs installed by a provider.
+func providerCRDs(ctx context.Context, c client.Client, provider clusterctlv1.Provider) ([]apiextensionsv1.CustomResourceDefinition, error) {
+	labels := map[string]string{
+		clusterctlv1.ClusterctlLabel: "",
+		clusterv1.ProviderNameLabel:  provider.ManifestLabel(),
+	}
+
+	crds := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := c.List(ctx, crds, client.MatchingLabels(labels)); err != nil {
+		return nil, err
+	}
+	return crds.Items, nil
+}
+
+// isKindNotServed returns true if the error is returned when listing a Kind not served by the management cluster,
+// e.g. Machines before Cluster API is installed.
+func isKindNotServed(err error) bool {
+	return meta.IsNoMatchError(err) || apierrors.IsNotFound(err)
+}
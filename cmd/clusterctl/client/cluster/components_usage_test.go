@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_providerComponents_GetUsage(t *testing.T) {
+	infraCRD := &apiextensionsv1.CustomResourceDefinition{
+		TypeMeta: metav1.TypeMeta{Kind: "CustomResourceDefinition", APIVersion: apiextensionsv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "infraclusters.infrastructure.cluster.x-k8s.io",
+			Labels: map[string]string{clusterctlv1.ClusterctlLabel: "", clusterv1.ProviderNameLabel: "infrastructure-infra"},
+		},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group:    "infrastructure.cluster.x-k8s.io",
+			Names:    apiextensionsv1.CustomResourceDefinitionNames{Kind: "InfraCluster", ListKind: "InfraClusterList"},
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1beta1", Storage: true}},
+		},
+	}
+	infraCluster := &unstructured.Unstructured{}
+	infraCluster.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1beta1")
+	infraCluster.SetKind("InfraCluster")
+	infraCluster.SetNamespace("ns1")
+	infraCluster.SetName("cluster1")
+
+	cluster := &clusterv1.Cluster{
+		TypeMeta:   metav1.TypeMeta{Kind: "Cluster", APIVersion: clusterv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "cluster1"},
+		Spec: clusterv1.ClusterSpec{
+			InfrastructureRef: &corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: "InfraCluster", Namespace: "ns1", Name: "cluster1"},
+		},
+	}
+	machine := &clusterv1.Machine{
+		TypeMeta:   metav1.TypeMeta{Kind: "Machine", APIVersion: clusterv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "machine1"},
+		Spec: clusterv1.MachineSpec{
+			ClusterName:       "cluster1",
+			InfrastructureRef: corev1.ObjectReference{APIVersion: "infrastructure.other.x-k8s.io/v1beta1", Kind: "OtherMachine", Namespace: "ns1", Name: "machine1"},
+		},
+	}
+
+	infraProvider := clusterctlv1.Provider{ObjectMeta: metav1.ObjectMeta{Name: "infrastructure-infra", Namespace: "ns1"}, ProviderName: "infra", Type: string(clusterctlv1.InfrastructureProviderType)}
+	coreProvider := clusterctlv1.Provider{ObjectMeta: metav1.ObjectMeta{Name: "cluster-api", Namespace: "capi-system"}, ProviderName: "cluster-api", Type: string(clusterctlv1.CoreProviderType)}
+
+	tests := []struct {
+		name         string
+		provider     clusterctlv1.Provider
+		initObjs     []client.Object
+		wantClusters []string
+		wantMachines []string
+		wantObjects  []ProviderObjects
+	}{
+		{
+			name:     "Provider without Clusters or Machines",
+			provider: infraProvider,
+			initObjs: []client.Object{infraCRD},
+		},
+		{
+			name:         "Provider used by a Cluster",
+			provider:     infraProvider,
+			initObjs:     []client.Object{infraCRD, infraCluster, cluster, machine},
+			wantClusters: []string{"ns1/cluster1"},
+			wantObjects:  []ProviderObjects{{Kind: "InfraCluster.infrastructure.cluster.x-k8s.io", Count: 1}},
+		},
+		{
+			name:         "Core provider is used by all the Clusters and Machines",
+			provider:     coreProvider,
+			initObjs:     []client.Object{infraCRD, cluster, machine},
+			wantClusters: []string{"ns1/cluster1"},
+			wantMachines: []string{"ns1/machine1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := newComponentsClient(test.NewFakeProxy().WithObjs(tt.initObjs...))
+
+			usage, err := c.GetUsage(context.Background(), tt.provider)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(usage.Clusters).To(Equal(tt.wantClusters))
+			g.Expect(usage.Machines).To(Equal(tt.wantMachines))
+			g.Expect(usage.InUse()).To(Equal(len(tt.wantClusters) > 0 || len(tt.wantMachines) > 0))
+
+			objects, err := c.CountObjects(context.Background(), tt.provider)
+			g.Expect(err).ToNot(HaveOccurred())
+			if tt.wantObjects == nil {
+				g.Expect(objects).To(BeEmpty())
+				return
+			}
+			g.Expect(objects).To(Equal(tt.wantObjects))
+		})
+	}
+}
@@ -38,6 +38,7 @@ import (
 // CRDMigrator interface defines methods for migrating CRs to the storage version of new CRDs.
 type CRDMigrator interface {
 	Run(ctx context.Context, objs []unstructured.Unstructured) error
+	MigrateStoredVersions(ctx context.Context, objs []unstructured.Unstructured) error
 }
 
 // crdMigrator migrates CRs to the storage version of new CRDs.
@@ -75,6 +76,60 @@ func (m *crdMigrator) Run(ctx context.Context, objs []unstructured.Unstructured)
 	return nil
 }
 
+// MigrateStoredVersions migrates CRs of installed CRDs to their storage version.
+// This is necessary after a new CRD changes the storage version, so the previous
+// storage version can be dropped from the CRD status and then from the CRD in a later release.
+// Note: This requires conversion webhooks of the new provider version to be up and running.
+func (m *crdMigrator) MigrateStoredVersions(ctx context.Context, objs []unstructured.Unstructured) error {
+	for i := range objs {
+		obj := objs[i]
+
+		if obj.GetKind() == "CustomResourceDefinition" {
+			if _, err := m.migrateStoredVersions(ctx, obj.GetName()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// migrateStoredVersions migrates CRs of an installed CRD to its storage version, if
+// the CRD status reports versions other than the storage version as stored versions.
+func (m *crdMigrator) migrateStoredVersions(ctx context.Context, name string) (bool, error) {
+	log := logf.Log
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := retryWithExponentialBackoff(ctx, newReadBackoff(), func(ctx context.Context) error {
+		return m.Client.Get(ctx, client.ObjectKey{Name: name}, crd)
+	}); err != nil {
+		return false, errors.Wrapf(err, "failed to get CRD %q", name)
+	}
+
+	storageVersion, err := storageVersionForCRD(crd)
+	if err != nil {
+		return false, err
+	}
+
+	// If the storage version is the only stored version, nothing to do.
+	storedVersions := sets.Set[string]{}.Insert(crd.Status.StoredVersions...)
+	if storedVersions.Len() == 0 || storedVersions.Equal(sets.New(storageVersion)) {
+		log.V(2).Info("Storage version migration check passed", "name", crd.Name)
+		return false, nil
+	}
+
+	log.Info("Storage version migration required", "kind", crd.Spec.Names.Kind, "storageVersion", storageVersion, "storedVersions", strings.Join(sets.List(storedVersions), ","))
+
+	if err := m.migrateResourcesForCRD(ctx, crd, storageVersion); err != nil {
+		return false, err
+	}
+
+	if err := m.patchCRDStoredVersions(ctx, crd, storageVersion); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 // run migrates CRs of a new CRD.
 // This is necessary when the new CRD drops or stops serving
 // a version which was previously used as a storage version.
@@ -313,3 +313,99 @@ func (u UpgradeCountingClient) Update(ctx context.Context, obj client.Object, op
 	u.count[obj.GetObjectKind().GroupVersionKind().String()]++
 	return u.Client.Update(ctx, obj, opts...)
 }
+
+func Test_CRDMigrator_migrateStoredVersions(t *testing.T) {
+	newCR := func(name string) unstructured.Unstructured {
+		return unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "foo/v1",
+				"kind":       "Foo",
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": metav1.NamespaceDefault,
+				},
+			},
+		}
+	}
+	newCRD := func(storedVersions ...string) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Group: "foo",
+				Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "Foo", ListKind: "FooList"},
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+					{Name: "v1", Storage: true, Served: true},
+					{Name: "v1beta1", Served: true},
+				},
+			},
+			Status: apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: storedVersions},
+		}
+	}
+
+	tests := []struct {
+		name               string
+		CRs                []unstructured.Unstructured
+		crd                *apiextensionsv1.CustomResourceDefinition
+		wantIsMigrated     bool
+		wantStoredVersions []string
+		wantErr            bool
+	}{
+		{
+			name:    "Error if the CRD does not have a storage version",
+			crd:     &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Status: apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: []string{"v1"}}},
+			wantErr: true,
+		},
+		{
+			name:               "No-op if the storage version is the only stored version",
+			CRs:                []unstructured.Unstructured{newCR("cr1")},
+			crd:                newCRD("v1"),
+			wantIsMigrated:     false,
+			wantStoredVersions: []string{"v1"},
+		},
+		{
+			name:               "Migrate CRs if a previous storage version is a stored version",
+			CRs:                []unstructured.Unstructured{newCR("cr1"), newCR("cr2")},
+			crd:                newCRD("v1beta1", "v1"),
+			wantIsMigrated:     true,
+			wantStoredVersions: []string{"v1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			objs := []client.Object{tt.crd}
+			for i := range tt.CRs {
+				objs = append(objs, &tt.CRs[i])
+			}
+
+			c, err := test.NewFakeProxy().WithObjs(objs...).NewClient(context.Background())
+			g.Expect(err).ToNot(HaveOccurred())
+			countingClient := newUpgradeCountingClient(c)
+
+			m := crdMigrator{
+				Client: countingClient,
+			}
+
+			isMigrated, err := m.migrateStoredVersions(context.Background(), "foo")
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(isMigrated).To(Equal(tt.wantIsMigrated))
+
+			// Check all the objects has been migrated, if required.
+			wantMigrated := 0
+			if tt.wantIsMigrated {
+				wantMigrated = len(tt.CRs)
+			}
+			g.Expect(countingClient.count["foo/v1, Kind=Foo"]).To(Equal(wantMigrated))
+
+			// Check storage versions has been cleaned up.
+			crd := &apiextensionsv1.CustomResourceDefinition{}
+			g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(tt.crd), crd)).To(Succeed())
+			g.Expect(crd.Status.StoredVersions).To(Equal(tt.wantStoredVersions))
+		})
+	}
+}
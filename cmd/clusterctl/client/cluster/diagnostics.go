@@ -0,0 +1,483 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+)
+
+// diagnosticsBundleDirectory is the directory, at the root of the diagnostics bundle, containing all the files.
+const diagnosticsBundleDirectory = "clusterctl-diagnostics"
+
+// diagnosticsRedacted is the value replacing the sensitive data in the diagnostics bundle.
+const diagnosticsRedacted = "REDACTED"
+
+// diagnosticsObjectKinds are the kinds of the Cluster API objects collected in the diagnostics bundle, together with their conditions.
+// NOTE: if the CRDs for a kind are not installed in the management cluster, e.g. for KubeadmControlPlanes, listing
+// the objects fails and the error is reported in the bundle.
+var diagnosticsObjectKinds = []schema.GroupVersionKind{
+	clusterv1.GroupVersion.WithKind("Cluster"),
+	clusterv1.GroupVersion.WithKind("MachineDeployment"),
+	clusterv1.GroupVersion.WithKind("MachineSet"),
+	clusterv1.GroupVersion.WithKind("Machine"),
+	clusterv1.GroupVersion.WithKind("MachineHealthCheck"),
+	clusterv1.GroupVersion.WithKind("MachinePool"),
+	{Group: "controlplane.cluster.x-k8s.io", Version: "v1beta1", Kind: "KubeadmControlPlane"},
+}
+
+// diagnosticsRedactPatterns are the patterns of the sensitive data redacted from all the files in the diagnostics bundle,
+// e.g. tokens and passwords in the controller logs.
+// NOTE: the first capturing group, if any, is preserved.
+var diagnosticsRedactPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`),
+	regexp.MustCompile(`(?i)((?:password|passwd|token|secret|api[-_]?key|access[-_]?key|credentials?)["']?\s*[:=]\s*["']?)[^\s"',}]+`),
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+}
+
+// DiagnosticsOptions are the options for collecting a diagnostics bundle.
+type DiagnosticsOptions struct {
+	// Namespace where the Cluster API objects and the Events are collected from. If empty, all the namespaces are used.
+	// NOTE: the controller logs, the CRDs and the webhook configurations are always collected for all the providers.
+	Namespace string
+
+	// LogsSince limits the controller logs to the logs newer than the given duration. If zero, all the logs are collected.
+	LogsSince time.Duration
+
+	// EventsSince limits the Events to the Events that happened within the given duration. If zero, all the Events are collected.
+	EventsSince time.Duration
+
+	// SkipLogs skips collecting the controller logs.
+	SkipLogs bool
+
+	// RedactPatterns are additional regular expressions for the data to be redacted from the diagnostics bundle;
+	// if a pattern has a capturing group, the text matching the first group is preserved.
+	RedactPatterns []string
+}
+
+// Validate validates the DiagnosticsOptions.
+func (o DiagnosticsOptions) Validate() error {
+	if o.LogsSince < 0 {
+		return errors.Errorf("invalid logs since %s: must not be negative", o.LogsSince)
+	}
+	if o.EventsSince < 0 {
+		return errors.Errorf("invalid events since %s: must not be negative", o.EventsSince)
+	}
+	if _, err := o.redactPatterns(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o DiagnosticsOptions) redactPatterns() ([]*regexp.Regexp, error) {
+	patterns := append([]*regexp.Regexp{}, diagnosticsRedactPatterns...)
+	for _, p := range o.RedactPatterns {
+		r, err := regexp.Compile(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid redact pattern %q", p)
+		}
+		patterns = append(patterns, r)
+	}
+	return patterns, nil
+}
+
+// DiagnosticsClient has methods to collect diagnostics data from the management cluster.
+type DiagnosticsClient interface {
+	// Collect writes to w a gzipped tarball with the controller logs, the CRDs, the provider inventory, the Cluster API objects
+	// with their conditions, the recent Events and the webhook configurations of the management cluster.
+	// Secrets are never collected, and sensitive data is redacted from all the files in the tarball.
+	// Collecting diagnostics is best effort: failures in collecting a piece of data are reported in the errors.txt
+	// file in the tarball, and do not stop the collection of the remaining data.
+	Collect(ctx context.Context, w io.Writer, options DiagnosticsOptions) error
+}
+
+// podLogsReader returns the logs of a container, or of its previous instance, newer than the given duration.
+type podLogsReader func(ctx context.Context, namespace, name, container string, previous bool, since time.Duration) ([]byte, error)
+
+// diagnosticsClient implements DiagnosticsClient.
+type diagnosticsClient struct {
+	proxy    Proxy
+	podLogs  podLogsReader
+	now      func() time.Time
+	patterns []*regexp.Regexp
+}
+
+// ensure diagnosticsClient implements DiagnosticsClient.
+var _ DiagnosticsClient = &diagnosticsClient{}
+
+// newDiagnosticsClient returns a diagnosticsClient.
+func newDiagnosticsClient(proxy Proxy) *diagnosticsClient {
+	return &diagnosticsClient{
+		proxy:   proxy,
+		podLogs: newPodLogsReader(proxy),
+		now:     time.Now,
+	}
+}
+
+// newPodLogsReader returns a podLogsReader using a clientset for the management cluster.
+func newPodLogsReader(proxy Proxy) podLogsReader {
+	return func(ctx context.Context, namespace, name, container string, previous bool, since time.Duration) ([]byte, error) {
+		config, err := proxy.GetConfig()
+		if err != nil {
+			return nil, err
+		}
+		cs, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create the client-set for the management cluster")
+		}
+
+		options := &corev1.PodLogOptions{Container: container, Previous: previous}
+		if since > 0 {
+			options.SinceSeconds = ptr.To(int64(since.Seconds()))
+		}
+		return cs.CoreV1().Pods(namespace).GetLogs(name, options).DoRaw(ctx)
+	}
+}
+
+func (d *diagnosticsClient) Collect(ctx context.Context, w io.Writer, options DiagnosticsOptions) error {
+	log := logf.Log
+
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	patterns, err := options.redactPatterns()
+	if err != nil {
+		return err
+	}
+	d.patterns = patterns
+
+	c, err := d.proxy.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	gw := gzip.NewWriter(w)
+	b := &diagnosticsBundle{tw: tar.NewWriter(gw), now: d.now(), redact: d.redact}
+
+	log.Info("Collecting provider inventory")
+	providerNamespaces := d.collectProviders(ctx, c, b)
+
+	log.Info("Collecting provider CRDs and webhook configurations")
+	d.collectProviderComponents(ctx, c, b)
+
+	log.Info("Collecting Cluster API objects")
+	d.collectObjects(ctx, c, b, options.Namespace)
+
+	log.Info("Collecting Events")
+	d.collectEvents(ctx, c, b, options.Namespace, options.EventsSince)
+
+	log.Info("Collecting controller pods and logs")
+	d.collectPods(ctx, c, b, providerNamespaces, options)
+
+	if len(b.errs) > 0 {
+		b.add("errors.txt", []byte(strings.Join(b.errs, "\n")+"\n"))
+	}
+
+	if b.err != nil {
+		return errors.Wrap(b.err, "failed to write the diagnostics bundle")
+	}
+	if err := b.tw.Close(); err != nil {
+		return errors.Wrap(err, "failed to write the diagnostics bundle")
+	}
+	if err := gw.Close(); err != nil {
+		return errors.Wrap(err, "failed to write the diagnostics bundle")
+	}
+	return nil
+}
+
+// collectProviders adds the provider inventory to the bundle, and returns the namespaces where the providers are installed.
+func (d *diagnosticsClient) collectProviders(ctx context.Context, c client.Client, b *diagnosticsBundle) []string {
+	providers := &unstructured.UnstructuredList{}
+	providers.SetGroupVersionKind(clusterctlv1.GroupVersion.WithKind("ProviderList"))
+	if err := c.List(ctx, providers); err != nil {
+		b.reportError(errors.Wrap(err, "failed to list providers"))
+		return nil
+	}
+	b.addObject("providers.yaml", providers)
+
+	namespaces := sets.Set[string]{}
+	for _, p := range providers.Items {
+		namespaces.Insert(p.GetNamespace())
+	}
+	return sets.List(namespaces)
+}
+
+// collectProviderComponents adds the CRDs and the webhook configurations of the providers to the bundle.
+func (d *diagnosticsClient) collectProviderComponents(ctx context.Context, c client.Client, b *diagnosticsBundle) {
+	for _, component := range []struct {
+		gvk       schema.GroupVersionKind
+		directory string
+	}{
+		{gvk: schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinitionList"}, directory: "crds"},
+		{gvk: schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "ValidatingWebhookConfigurationList"}, directory: "webhooks/validating"},
+		{gvk: schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "MutatingWebhookConfigurationList"}, directory: "webhooks/mutating"},
+	} {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(component.gvk)
+		if err := c.List(ctx, list, client.HasLabels{clusterv1.ProviderNameLabel}); err != nil {
+			b.reportError(errors.Wrapf(err, "failed to list %s", strings.TrimSuffix(component.gvk.Kind, "List")))
+			continue
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			b.addObject(path.Join(component.directory, obj.GetName()+".yaml"), obj)
+		}
+	}
+}
+
+// collectObjects adds the Cluster API objects to the bundle, together with a summary of their conditions.
+func (d *diagnosticsClient) collectObjects(ctx context.Context, c client.Client, b *diagnosticsBundle, namespace string) {
+	conditions := &bytes.Buffer{}
+	tw := tabwriter.NewWriter(conditions, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tNAMESPACE\tNAME\tTYPE\tSTATUS\tSEVERITY\tREASON\tMESSAGE")
+
+	for _, gvk := range diagnosticsObjectKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			b.reportError(errors.Wrapf(err, "failed to list %s", gvk.Kind))
+			continue
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			b.addObject(path.Join("resources", obj.GetNamespace(), strings.ToLower(gvk.Kind), obj.GetName()+".yaml"), obj)
+
+			objConditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+			for _, condition := range objConditions {
+				condition, ok := condition.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", gvk.Kind, obj.GetNamespace(), obj.GetName(),
+					condition["type"], condition["status"], stringOrEmpty(condition["severity"]), stringOrEmpty(condition["reason"]), stringOrEmpty(condition["message"]))
+			}
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		b.reportError(errors.Wrap(err, "failed to write conditions"))
+		return
+	}
+	b.add("conditions.txt", conditions.Bytes())
+}
+
+// collectEvents adds the Events that happened within the given duration to the bundle, grouped by namespace.
+func (d *diagnosticsClient) collectEvents(ctx context.Context, c client.Client, b *diagnosticsBundle, namespace string, since time.Duration) {
+	events := &corev1.EventList{}
+	if err := c.List(ctx, events, client.InNamespace(namespace)); err != nil {
+		b.reportError(errors.Wrap(err, "failed to list Events"))
+		return
+	}
+
+	eventsByNamespace := map[string][]corev1.Event{}
+	for _, e := range events.Items {
+		if since > 0 {
+			if t := eventTime(e); !t.IsZero() && b.now.Sub(t) > since {
+				continue
+			}
+		}
+		eventsByNamespace[e.Namespace] = append(eventsByNamespace[e.Namespace], e)
+	}
+
+	for eventsNamespace, namespaceEvents := range eventsByNamespace {
+		sort.SliceStable(namespaceEvents, func(i, j int) bool {
+			return eventTime(namespaceEvents[i]).Before(eventTime(namespaceEvents[j]))
+		})
+		list := &corev1.EventList{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "EventList"},
+			Items:    namespaceEvents,
+		}
+		b.addYAML(path.Join("events", eventsNamespace+".yaml"), list)
+	}
+}
+
+// eventTime returns the last time an Event happened.
+func eventTime(e corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case e.Series != nil && !e.Series.LastObservedTime.IsZero():
+		return e.Series.LastObservedTime.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
+
+// collectPods adds the Pods in the provider namespaces to the bundle, together with the logs of their containers.
+func (d *diagnosticsClient) collectPods(ctx context.Context, c client.Client, b *diagnosticsBundle, namespaces []string, options DiagnosticsOptions) {
+	for _, namespace := range namespaces {
+		pods := &corev1.PodList{}
+		if err := c.List(ctx, pods, client.InNamespace(namespace)); err != nil {
+			b.reportError(errors.Wrapf(err, "failed to list Pods in namespace %s", namespace))
+			continue
+		}
+
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			pod.APIVersion, pod.Kind = "v1", "Pod"
+			// Drop the environment variables, as they can contain sensitive data.
+			for j := range pod.Spec.Containers {
+				pod.Spec.Containers[j].Env = nil
+			}
+			for j := range pod.Spec.InitContainers {
+				pod.Spec.InitContainers[j].Env = nil
+			}
+			b.addYAML(path.Join("pods", pod.Namespace, pod.Name+".yaml"), pod)
+
+			if options.SkipLogs {
+				continue
+			}
+			for _, status := range pod.Status.ContainerStatuses {
+				d.collectLogs(ctx, b, pod, status.Name, false, options.LogsSince)
+				// Collect the logs of the previous instance too, as they are usually the most interesting ones if the container is restarting.
+				if status.RestartCount > 0 {
+					d.collectLogs(ctx, b, pod, status.Name, true, options.LogsSince)
+				}
+			}
+		}
+	}
+}
+
+func (d *diagnosticsClient) collectLogs(ctx context.Context, b *diagnosticsBundle, pod *corev1.Pod, container string, previous bool, since time.Duration) {
+	filename := container + ".log"
+	if previous {
+		filename = container + ".previous.log"
+	}
+
+	logs, err := d.podLogs(ctx, pod.Namespace, pod.Name, container, previous, since)
+	if err != nil {
+		b.reportError(errors.Wrapf(err, "failed to get logs for container %s of Pod %s/%s", container, pod.Namespace, pod.Name))
+		return
+	}
+	b.add(path.Join("logs", pod.Namespace, pod.Name, filename), logs)
+}
+
+// redact replaces the sensitive data in data.
+func (d *diagnosticsClient) redact(data []byte) []byte {
+	for _, p := range d.patterns {
+		if p.NumSubexp() > 0 {
+			data = p.ReplaceAll(data, []byte("${1}"+diagnosticsRedacted))
+			continue
+		}
+		data = p.ReplaceAll(data, []byte(diagnosticsRedacted))
+	}
+	return data
+}
+
+func stringOrEmpty(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
+
+// diagnosticsBundle writes the files of a diagnostics bundle to a tarball.
+type diagnosticsBundle struct {
+	tw     *tar.Writer
+	now    time.Time
+	redact func([]byte) []byte
+
+	// err is the first error writing to the tarball; after an error, no more files are written.
+	err error
+
+	// errs are the errors collecting the diagnostics data.
+	errs []string
+}
+
+// reportError records an error collecting the diagnostics data.
+func (b *diagnosticsBundle) reportError(err error) {
+	logf.Log.V(1).Info("Failed to collect diagnostics data", "error", err.Error())
+	b.errs = append(b.errs, err.Error())
+}
+
+// addObject adds an object to the bundle, dropping the metadata not relevant for diagnostics.
+func (b *diagnosticsBundle) addObject(filename string, obj runtime.Unstructured) {
+	content := obj.UnstructuredContent()
+	dropDiagnosticsIrrelevantMetadata(content)
+	if items, ok := content["items"].([]interface{}); ok {
+		for _, item := range items {
+			if item, ok := item.(map[string]interface{}); ok {
+				dropDiagnosticsIrrelevantMetadata(item)
+			}
+		}
+	}
+	b.addYAML(filename, content)
+}
+
+func dropDiagnosticsIrrelevantMetadata(obj map[string]interface{}) {
+	unstructured.RemoveNestedField(obj, "metadata", "managedFields")
+	unstructured.RemoveNestedField(obj, "metadata", "annotations", corev1.LastAppliedConfigAnnotation)
+}
+
+// addYAML adds an object serialized as YAML to the bundle.
+func (b *diagnosticsBundle) addYAML(filename string, obj interface{}) {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		b.reportError(errors.Wrapf(err, "failed to serialize %s", filename))
+		return
+	}
+	b.add(filename, data)
+}
+
+// add adds a file to the bundle, redacting the sensitive data.
+func (b *diagnosticsBundle) add(filename string, data []byte) {
+	if b.err != nil {
+		return
+	}
+
+	data = b.redact(data)
+	if err := b.tw.WriteHeader(&tar.Header{
+		Name:    path.Join(diagnosticsBundleDirectory, filename),
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: b.now,
+	}); err != nil {
+		b.err = err
+		return
+	}
+	if _, err := b.tw.Write(data); err != nil {
+		b.err = err
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_diagnosticsClient_Collect(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	providerLabels := map[string]string{clusterv1.ProviderNameLabel: "cluster-api"}
+
+	objs := []client.Object{
+		&apiextensionsv1.CustomResourceDefinition{
+			TypeMeta:   metav1.TypeMeta{APIVersion: apiextensionsv1.SchemeGroupVersion.String(), Kind: "CustomResourceDefinition"},
+			ObjectMeta: metav1.ObjectMeta{Name: "clusters.cluster.x-k8s.io", Labels: providerLabels},
+		},
+		&apiextensionsv1.CustomResourceDefinition{
+			TypeMeta:   metav1.TypeMeta{APIVersion: apiextensionsv1.SchemeGroupVersion.String(), Kind: "CustomResourceDefinition"},
+			ObjectMeta: metav1.ObjectMeta{Name: "foos.example.com"},
+		},
+		&admissionregistrationv1.ValidatingWebhookConfiguration{
+			TypeMeta:   metav1.TypeMeta{APIVersion: admissionregistrationv1.SchemeGroupVersion.String(), Kind: "ValidatingWebhookConfiguration"},
+			ObjectMeta: metav1.ObjectMeta{Name: "capi-validating-webhook-configuration", Labels: providerLabels},
+		},
+		&clusterv1.Cluster{
+			TypeMeta: metav1.TypeMeta{APIVersion: clusterv1.GroupVersion.String(), Kind: "Cluster"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "my-cluster",
+				Namespace:   "default",
+				Annotations: map[string]string{corev1.LastAppliedConfigAnnotation: "{}"},
+			},
+			Status: clusterv1.ClusterStatus{
+				Conditions: clusterv1.Conditions{
+					{Type: clusterv1.ReadyCondition, Status: corev1.ConditionFalse, Severity: clusterv1.ConditionSeverityWarning, Reason: "WaitingForControlPlane", Message: "control plane is not ready"},
+				},
+			},
+		},
+		&corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{Name: "my-cluster-kubeconfig", Namespace: "default"},
+			Data:       map[string][]byte{"value": []byte("kubeconfig")},
+		},
+		&corev1.Event{
+			TypeMeta:      metav1.TypeMeta{APIVersion: "v1", Kind: "Event"},
+			ObjectMeta:    metav1.ObjectMeta{Name: "recent", Namespace: "default"},
+			Reason:        "RecentReason",
+			LastTimestamp: metav1.NewTime(now.Add(-10 * time.Minute)),
+		},
+		&corev1.Event{
+			TypeMeta:      metav1.TypeMeta{APIVersion: "v1", Kind: "Event"},
+			ObjectMeta:    metav1.ObjectMeta{Name: "old", Namespace: "default"},
+			Reason:        "OldReason",
+			LastTimestamp: metav1.NewTime(now.Add(-2 * time.Hour)),
+		},
+		&corev1.Pod{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: "capi-controller-manager", Namespace: "capi-system"},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name: "manager",
+					Env:  []corev1.EnvVar{{Name: "SOME_VARIABLE", Value: "some-value"}},
+				}},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "manager", RestartCount: 1}},
+			},
+		},
+	}
+
+	logs := func(_ context.Context, namespace, name, container string, previous bool, since time.Duration) ([]byte, error) {
+		if previous {
+			return []byte("panic: something went wrong\n"), nil
+		}
+		return []byte("reconciling " + namespace + "/" + name + "/" + container + " since " + since.String() + " with token=abc123 and Authorization: Bearer eyJhbGciOi\n"), nil
+	}
+
+	tests := []struct {
+		name      string
+		options   DiagnosticsOptions
+		wantFiles map[string][]string
+		wantNot   []string
+		wantErr   bool
+	}{
+		{
+			name:    "Collect all the diagnostics data",
+			options: DiagnosticsOptions{LogsSince: time.Hour, EventsSince: time.Hour, RedactPatterns: []string{`(reconciling )capi-system`}},
+			wantFiles: map[string][]string{
+				"providers.yaml":                      {"cluster-api"},
+				"crds/clusters.cluster.x-k8s.io.yaml": {"clusters.cluster.x-k8s.io"},
+				"webhooks/validating/capi-validating-webhook-configuration.yaml": {"capi-validating-webhook-configuration"},
+				"resources/default/cluster/my-cluster.yaml":                      {"my-cluster"},
+				"conditions.txt":      {"Cluster", "my-cluster", "Ready", "False", "Warning", "WaitingForControlPlane", "control plane is not ready"},
+				"events/default.yaml": {"RecentReason"},
+				"pods/capi-system/capi-controller-manager.yaml":                 {"manager"},
+				"logs/capi-system/capi-controller-manager/manager.log":          {"reconciling REDACTED/capi-controller-manager/manager since 1h0m0s", "token=REDACTED", "Bearer REDACTED"},
+				"logs/capi-system/capi-controller-manager/manager.previous.log": {"panic: something went wrong"},
+			},
+			wantNot: []string{"crds/foos.example.com.yaml", "errors.txt", "my-cluster-kubeconfig", "OldReason", "SOME_VARIABLE", "abc123", "eyJhbGciOi", corev1.LastAppliedConfigAnnotation},
+		},
+		{
+			name:    "Skip logs",
+			options: DiagnosticsOptions{SkipLogs: true},
+			wantFiles: map[string][]string{
+				"pods/capi-system/capi-controller-manager.yaml": {"manager"},
+				"events/default.yaml":                           {"RecentReason", "OldReason"},
+			},
+			wantNot: []string{"logs/capi-system/capi-controller-manager/manager.log"},
+		},
+		{
+			name:    "Invalid redact pattern",
+			options: DiagnosticsOptions{RedactPatterns: []string{"("}},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid logs since",
+			options: DiagnosticsOptions{LogsSince: -time.Hour},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			proxy := test.NewFakeProxy().
+				WithProviderInventory("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "capi-system").
+				WithObjs(objs...)
+			d := newDiagnosticsClient(proxy)
+			d.podLogs = logs
+			d.now = func() time.Time { return now }
+
+			out := &bytes.Buffer{}
+			err := d.Collect(context.Background(), out, tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+
+			files := readDiagnosticsBundle(g, out)
+			for name, want := range tt.wantFiles {
+				g.Expect(files).To(HaveKey(name))
+				for _, w := range want {
+					g.Expect(files[name]).To(ContainSubstring(w), "file %s", name)
+				}
+			}
+			all := strings.Builder{}
+			for name, content := range files {
+				all.WriteString(name + "\n" + content + "\n")
+			}
+			for _, n := range tt.wantNot {
+				g.Expect(all.String()).ToNot(ContainSubstring(n))
+			}
+		})
+	}
+}
+
+func readDiagnosticsBundle(g *WithT, r io.Reader) map[string]string {
+	gr, err := gzip.NewReader(r)
+	g.Expect(err).ToNot(HaveOccurred())
+	tr := tar.NewReader(gr)
+
+	files := map[string]string{}
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(h.Name).To(HavePrefix(diagnosticsBundleDirectory + "/"))
+
+		data, err := io.ReadAll(tr)
+		g.Expect(err).ToNot(HaveOccurred())
+		files[strings.TrimPrefix(h.Name, diagnosticsBundleDirectory+"/")] = string(data)
+	}
+	return files
+}
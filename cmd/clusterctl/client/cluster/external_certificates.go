@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+)
+
+const (
+	certManagerGroup           = "cert-manager.io"
+	certManagerInjectCAFrom    = "cert-manager.io/inject-ca-from"
+	externalCertificateCAKey   = "ca.crt"
+	externalCertificateCertKey = corev1.TLSCertKey
+	externalCertificateKeyKey  = corev1.TLSPrivateKeyKey
+)
+
+// ExternalCertificatesOptions defines the options used for installing providers with webhook serving certificates
+// supplied from existing Secrets instead of certificates issued by cert-manager.
+type ExternalCertificatesOptions struct {
+	// Enabled instructs clusterctl to drop the cert-manager Certificates and Issuers from the provider components,
+	// to use the existing Secrets with the names defined in the Certificates, and to set the CA bundle in the
+	// webhook configurations and in the CRDs with conversion webhooks instead of relying on the cert-manager CA injector.
+	Enabled bool
+
+	// CABundle is the PEM encoded CA bundle used to verify the webhook serving certificates. If empty, the ca.crt key
+	// of the Secrets is used, e.g. when the Secrets are issued by an external issuer.
+	CABundle []byte
+}
+
+// useExternalCertificates alters the provider components to use the webhook serving certificates in existing Secrets,
+// so the components can be installed without cert-manager.
+// It returns an error if any of the Secrets does not exist or does not contain a certificate, a key and,
+// if a CA bundle is not provided, a CA certificate.
+func useExternalCertificates(ctx context.Context, proxy Proxy, components repository.Components, opts ExternalCertificatesOptions) error {
+	if !opts.Enabled {
+		return nil
+	}
+
+	c, err := proxy.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	return repository.AlterComponents(components, func(objs []unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+		// Gets the Secret of each Certificate, dropping cert-manager objects from the components.
+		secrets := map[string]*corev1.Secret{}
+		ret := make([]unstructured.Unstructured, 0, len(objs))
+		for _, o := range objs {
+			if o.GroupVersionKind().Group != certManagerGroup {
+				ret = append(ret, o)
+				continue
+			}
+			if o.GetKind() != "Certificate" {
+				continue
+			}
+
+			secretName, _, err := unstructured.NestedString(o.Object, "spec", "secretName")
+			if err != nil || secretName == "" {
+				return nil, errors.Errorf("failed to get the Secret name of Certificate %s/%s", o.GetNamespace(), o.GetName())
+			}
+			secret, err := getExternalCertificateSecret(ctx, c, o.GetNamespace(), secretName, len(opts.CABundle) == 0)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to use an existing Secret for Certificate %s/%s", o.GetNamespace(), o.GetName())
+			}
+			secrets[o.GetNamespace()+"/"+o.GetName()] = secret
+		}
+
+		// Replace the cert-manager CA injection with the CA bundle.
+		for i := range ret {
+			o := &ret[i]
+			annotations := o.GetAnnotations()
+			certificate, ok := annotations[certManagerInjectCAFrom]
+			if !ok {
+				continue
+			}
+
+			caBundle := opts.CABundle
+			if len(caBundle) == 0 {
+				secret, ok := secrets[certificate]
+				if !ok {
+					return nil, errors.Errorf("failed to get the CA bundle for %s %s: Certificate %s is not part of the provider components", o.GetKind(), o.GetName(), certificate)
+				}
+				caBundle = secret.Data[externalCertificateCAKey]
+			}
+			if err := setCABundle(o, caBundle); err != nil {
+				return nil, err
+			}
+
+			delete(annotations, certManagerInjectCAFrom)
+			o.SetAnnotations(annotations)
+		}
+
+		logf.Log.V(1).Info("Using existing Secrets for webhook serving certificates", "Provider", components.ManifestLabel(), "Secrets", len(secrets))
+		return ret, nil
+	})
+}
+
+// getExternalCertificateSecret gets a Secret with a webhook serving certificate, checking that it contains
+// the certificate, the key and, if required, the CA certificate.
+func getExternalCertificateSecret(ctx context.Context, c client.Client, namespace, name string, requireCA bool) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errors.Errorf("Secret %s/%s does not exist", namespace, name)
+		}
+		return nil, errors.Wrapf(err, "failed to get Secret %s/%s", namespace, name)
+	}
+
+	keys := []string{externalCertificateCertKey, externalCertificateKeyKey}
+	if requireCA {
+		keys = append(keys, externalCertificateCAKey)
+	}
+	missing := []string{}
+	for _, k := range keys {
+		if len(secret.Data[k]) == 0 {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.Errorf("Secret %s/%s does not contain %s", namespace, name, strings.Join(missing, ", "))
+	}
+	return secret, nil
+}
+
+// setCABundle sets the CA bundle of the webhooks in a webhook configuration, or of the conversion webhook of a CRD.
+func setCABundle(o *unstructured.Unstructured, caBundle []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(caBundle)
+
+	switch o.GetKind() {
+	case "MutatingWebhookConfiguration", "ValidatingWebhookConfiguration":
+		webhooks, _, err := unstructured.NestedSlice(o.Object, "webhooks")
+		if err != nil {
+			return errors.Wrapf(err, "failed to set the CA bundle for %s %s", o.GetKind(), o.GetName())
+		}
+		for i := range webhooks {
+			webhook, ok := webhooks[i].(map[string]interface{})
+			if !ok {
+				return errors.Errorf("failed to set the CA bundle for %s %s: invalid webhook", o.GetKind(), o.GetName())
+			}
+			if err := unstructured.SetNestedField(webhook, encoded, "clientConfig", "caBundle"); err != nil {
+				return errors.Wrapf(err, "failed to set the CA bundle for %s %s", o.GetKind(), o.GetName())
+			}
+		}
+		return unstructured.SetNestedSlice(o.Object, webhooks, "webhooks")
+	case "CustomResourceDefinition":
+		if _, ok, _ := unstructured.NestedMap(o.Object, "spec", "conversion", "webhook"); !ok {
+			return nil
+		}
+		return unstructured.SetNestedField(o.Object, encoded, "spec", "conversion", "webhook", "clientConfig", "caBundle")
+	}
+	return errors.Errorf("failed to set the CA bundle for %s %s: unsupported kind", o.GetKind(), o.GetName())
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	yaml "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+const externalCertificatesComponentsYAML = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns1
+---
+apiVersion: cert-manager.io/v1
+kind: Issuer
+metadata:
+  name: selfsigned-issuer
+  namespace: ns1
+spec:
+  selfSigned: {}
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: serving-cert
+  namespace: ns1
+spec:
+  secretName: webhook-server-cert
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: validating-webhook-configuration
+  annotations:
+    cert-manager.io/inject-ca-from: ns1/serving-cert
+webhooks:
+- name: validation.infra.cluster.x-k8s.io
+  clientConfig:
+    service:
+      name: webhook-service
+      namespace: ns1
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: infraclusters.infrastructure.cluster.x-k8s.io
+  annotations:
+    cert-manager.io/inject-ca-from: ns1/serving-cert
+spec:
+  conversion:
+    strategy: Webhook
+    webhook:
+      clientConfig:
+        service:
+          name: webhook-service
+          namespace: ns1
+`
+
+func newExternalCertificatesComponents(t *testing.T) repository.Components {
+	t.Helper()
+
+	configClient, err := config.New(context.Background(), "", config.InjectReader(test.NewFakeReader()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	components, err := repository.NewComponents(repository.ComponentsInput{
+		Provider:     config.NewProvider("infra", "", clusterctlv1.InfrastructureProviderType),
+		ConfigClient: configClient,
+		Processor:    yaml.NewSimpleProcessor(),
+		RawYaml:      []byte(externalCertificatesComponentsYAML),
+		Options:      repository.ComponentsOptions{Version: "v1.0.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return components
+}
+
+func Test_useExternalCertificates(t *testing.T) {
+	secret := func(data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "webhook-server-cert"},
+			Data:       data,
+		}
+	}
+
+	tests := []struct {
+		name         string
+		opts         ExternalCertificatesOptions
+		secret       *corev1.Secret
+		wantCABundle string
+		wantErr      string
+	}{
+		{
+			name:         "uses the CA certificate of the Secret",
+			opts:         ExternalCertificatesOptions{Enabled: true},
+			secret:       secret(map[string][]byte{"tls.crt": []byte("crt"), "tls.key": []byte("key"), "ca.crt": []byte("secret-ca")}),
+			wantCABundle: "secret-ca",
+		},
+		{
+			name:         "uses the given CA bundle",
+			opts:         ExternalCertificatesOptions{Enabled: true, CABundle: []byte("external-ca")},
+			secret:       secret(map[string][]byte{"tls.crt": []byte("crt"), "tls.key": []byte("key")}),
+			wantCABundle: "external-ca",
+		},
+		{
+			name:    "fails if the Secret does not have a CA certificate",
+			opts:    ExternalCertificatesOptions{Enabled: true},
+			secret:  secret(map[string][]byte{"tls.crt": []byte("crt"), "tls.key": []byte("key")}),
+			wantErr: "Secret ns1/webhook-server-cert does not contain ca.crt",
+		},
+		{
+			name:    "fails if the Secret does not exist",
+			opts:    ExternalCertificatesOptions{Enabled: true, CABundle: []byte("external-ca")},
+			wantErr: "Secret ns1/webhook-server-cert does not exist",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			proxy := test.NewFakeProxy()
+			if tt.secret != nil {
+				proxy = proxy.WithObjs(tt.secret)
+			}
+			components := newExternalCertificatesComponents(t)
+
+			err := useExternalCertificates(context.Background(), proxy, components, tt.opts)
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+
+			wantCABundle := base64.StdEncoding.EncodeToString([]byte(tt.wantCABundle))
+			kinds := []string{}
+			for _, o := range components.Objs() {
+				kinds = append(kinds, o.GetKind())
+				g.Expect(o.GetAnnotations()).ToNot(HaveKey(certManagerInjectCAFrom))
+
+				switch o.GetKind() {
+				case "ValidatingWebhookConfiguration":
+					webhooks, _, _ := unstructured.NestedSlice(o.Object, "webhooks")
+					g.Expect(webhooks).To(HaveLen(1))
+					caBundle, _, _ := unstructured.NestedString(webhooks[0].(map[string]interface{}), "clientConfig", "caBundle")
+					g.Expect(caBundle).To(Equal(wantCABundle))
+				case "CustomResourceDefinition":
+					caBundle, _, _ := unstructured.NestedString(o.Object, "spec", "conversion", "webhook", "clientConfig", "caBundle")
+					g.Expect(caBundle).To(Equal(wantCABundle))
+				}
+			}
+			g.Expect(kinds).To(ConsistOf("Namespace", "ValidatingWebhookConfiguration", "CustomResourceDefinition"))
+		})
+	}
+}
+
+func Test_useExternalCertificatesDisabled(t *testing.T) {
+	g := NewWithT(t)
+
+	components := newExternalCertificatesComponents(t)
+	g.Expect(useExternalCertificates(context.Background(), test.NewFakeProxy(), components, ExternalCertificatesOptions{})).To(Succeed())
+	g.Expect(components.Objs()).To(HaveLen(5))
+}
@@ -0,0 +1,396 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+)
+
+func (o *objectMover) Handover(ctx context.Context, namespace, clusterName string, toCluster Client, dryRun bool, mutators ...ResourceMutatorFunc) error {
+	log := logf.Log
+	log.Info("Performing handover...", "Cluster", klog.KRef(namespace, clusterName))
+	o.dryRun = dryRun
+	if o.dryRun {
+		log.Info("********************************************************")
+		log.Info("This is a dry-run handover, will not perform any real action")
+		log.Info("********************************************************")
+	}
+
+	// checks that all the required providers in place in the target cluster.
+	if !o.dryRun {
+		if err := o.checkTargetProviders(ctx, toCluster.ProviderInventory()); err != nil {
+			return errors.Wrap(err, "failed to check providers in target cluster")
+		}
+	}
+
+	objectGraph, err := o.getClusterObjectGraph(ctx, namespace, clusterName)
+	if err != nil {
+		return errors.Wrap(err, "failed to get object graph")
+	}
+
+	// Checks if Cluster API has already completed the provisioning of the infrastructure for the Cluster.
+	if !o.dryRun {
+		if err := o.checkProvisioningCompleted(ctx, objectGraph); err != nil {
+			return errors.Wrap(err, "failed to check for provisioned infrastructure")
+		}
+	}
+
+	// Hand over the Cluster to the target cluster.
+	var proxy Proxy
+	if !o.dryRun {
+		proxy = toCluster.Proxy()
+	}
+
+	return o.handover(ctx, objectGraph, proxy, mutators...)
+}
+
+// getClusterObjectGraph returns the object graph with the objects required for handing over a single Cluster.
+func (o *objectMover) getClusterObjectGraph(ctx context.Context, namespace, clusterName string) (*objectGraph, error) {
+	objectGraph := newObjectGraph(o.fromProxy, o.fromProviderInventory)
+
+	// Gets all the types defined by the CRDs installed by clusterctl plus the ConfigMap/Secret core types.
+	if err := objectGraph.getDiscoveryTypes(ctx); err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve discovery types")
+	}
+
+	if err := objectGraph.Discovery(ctx, namespace); err != nil {
+		return nil, errors.Wrap(err, "failed to discover the object graph")
+	}
+
+	// Drops from the graph all the objects not required by the Cluster, e.g. other Clusters in the same namespace.
+	if err := objectGraph.filterCluster(namespace, clusterName); err != nil {
+		return nil, err
+	}
+
+	// Check whether nodes are not included in GVK considered for move
+	objectGraph.checkVirtualNode()
+
+	return objectGraph, nil
+}
+
+// handover moves a single Cluster to the target management cluster minimizing the time the Cluster is paused:
+//   - Pre-copy: all the objects are created in the target cluster while the Cluster is still reconciled in the source cluster;
+//     the Cluster is created paused in the target cluster.
+//   - Switchover: the Cluster is paused in the source cluster, the changes happened after the pre-copy are synced to the
+//     target cluster, and all the objects are verified to exist in the target cluster.
+//     If anything fails up to this point, the Cluster is resumed in the source cluster.
+//   - Completion: the objects are deleted from the source cluster, and the Cluster is resumed in the target cluster.
+//
+// Nb. Objects shared with other Clusters, e.g. the ClusterClass, are created in the target cluster if they do not exist, but
+// they are not deleted from the source cluster.
+func (o *objectMover) handover(ctx context.Context, graph *objectGraph, toProxy Proxy, mutators ...ResourceMutatorFunc) (retErr error) {
+	log := logf.Log
+
+	clusters := graph.getClusters()
+	if len(clusters) != 1 {
+		return errors.Errorf("expected exactly one Cluster to hand over, got %d", len(clusters))
+	}
+	cluster := clusters[0]
+	namespace, clusterName := cluster.identity.Namespace, cluster.identity.Name
+
+	// Cluster objects are always created paused in the target cluster, so the controllers do not reconcile them
+	// until the handover is completed.
+	targetMutators := append([]ResourceMutatorFunc{pauseClusterMutator}, mutators...)
+
+	log.Info("Pre-copying objects to the target cluster", "Cluster", klog.KRef(namespace, clusterName), "objects", len(graph.getMoveNodes()))
+	preCopySequence := getMoveSequence(graph)
+	preCopied, err := o.syncObjects(ctx, preCopySequence, toProxy, nil, targetMutators...)
+	if err != nil {
+		return errors.Wrap(err, "error pre-copying objects to the target cluster")
+	}
+
+	// Sets the pause field on the Cluster object in the source management cluster, so the controllers stop reconciling it.
+	log.Info("Pausing the source cluster", "Cluster", klog.KRef(namespace, clusterName))
+	pausedAt := time.Now()
+	if err := setClusterPause(ctx, o.fromProxy, clusters, true, o.dryRun); err != nil {
+		return err
+	}
+
+	// If the switchover fails, reset the pause field on the Cluster object in the source management cluster,
+	// so the Cluster is still reconciled by the source management cluster.
+	switchedOver := false
+	defer func() {
+		if retErr == nil || switchedOver {
+			return
+		}
+		log.Info("Handover failed, resuming the source cluster", "Cluster", klog.KRef(namespace, clusterName))
+		if err := setClusterPause(ctx, o.fromProxy, clusters, false, o.dryRun); err != nil {
+			retErr = kerrors.NewAggregate([]error{retErr, errors.Wrap(err, "error resuming the source cluster")})
+		}
+	}()
+
+	// Discovers the objects again, so objects created or deleted after the pre-copy are considered.
+	graph, err = o.getClusterObjectGraph(ctx, namespace, clusterName)
+	if err != nil {
+		return errors.Wrap(err, "failed to get object graph")
+	}
+
+	log.Info("Waiting for all resources to be ready to move")
+	// exponential backoff configuration which returns durations for a total time of ~2m.
+	// Example: 0, 5s, 8s, 11s, 17s, 26s, 38s, 57s, 86s, 128s
+	waitForMoveUnblockedBackoff := wait.Backoff{
+		Duration: 5 * time.Second,
+		Factor:   1.5,
+		Steps:    10,
+		Jitter:   0.1,
+	}
+	if err := waitReadyForMove(ctx, o.fromProxy, graph.getMoveNodes(), o.dryRun, waitForMoveUnblockedBackoff); err != nil {
+		return errors.Wrap(err, "error waiting for resources to be ready to move")
+	}
+
+	log.Info("Syncing changes to the target cluster")
+	syncSequence := getMoveSequence(graph)
+	synced, err := o.syncObjects(ctx, syncSequence, toProxy, preCopied, targetMutators...)
+	if err != nil {
+		return errors.Wrap(err, "error syncing objects to the target cluster")
+	}
+
+	// Delete from the target cluster the objects deleted from the source cluster after the pre-copy, in reverse order.
+	for groupIndex := len(preCopySequence.groups) - 1; groupIndex >= 0; groupIndex-- {
+		if err := o.deleteStaleTargetGroup(ctx, preCopySequence.getGroup(groupIndex), synced, toProxy, mutators...); err != nil {
+			return errors.Wrap(err, "error deleting stale objects from the target cluster")
+		}
+	}
+
+	log.Info("Verifying objects in the target cluster")
+	if err := o.verifyTargetObjects(ctx, graph.getMoveNodes(), toProxy, mutators...); err != nil {
+		return err
+	}
+	switchedOver = true
+
+	// Delete all objects group by group in reverse order.
+	log.Info("Deleting objects from the source cluster")
+	for groupIndex := len(syncSequence.groups) - 1; groupIndex >= 0; groupIndex-- {
+		if err := o.deleteGroup(ctx, syncSequence.getGroup(groupIndex)); err != nil {
+			return err
+		}
+	}
+
+	// Reset the pause field on the Cluster object in the target management cluster, so the controllers start reconciling it.
+	log.Info("Resuming the target cluster", "Cluster", klog.KRef(namespace, clusterName), "pauseDuration", time.Since(pausedAt).Round(time.Second).String())
+	return setClusterPause(ctx, toProxy, clusters, false, o.dryRun, mutators...)
+}
+
+// pauseClusterMutator sets the paused field on Cluster objects.
+func pauseClusterMutator(u *unstructured.Unstructured) error {
+	if u.GroupVersionKind().GroupKind() != clusterv1.GroupVersion.WithKind(clusterv1.ClusterKind).GroupKind() {
+		return nil
+	}
+	return unstructured.SetNestedField(u.Object, true, "spec", "paused")
+}
+
+// syncObjects creates or updates in the target management cluster the objects corresponding to the nodes in a move sequence,
+// skipping the objects not changed since a previous sync; it returns the resource versions of the synced source objects.
+func (o *objectMover) syncObjects(ctx context.Context, sequence *moveSequence, toProxy Proxy, previouslySynced map[string]string, mutators ...ResourceMutatorFunc) (map[string]string, error) {
+	synced := map[string]string{}
+	syncTargetObjectBackoff := newWriteBackoff()
+
+	// Maintain a cache of namespaces that have been verified to already exist.
+	existingNamespaces := sets.New[string]()
+	for groupIndex := 0; groupIndex < len(sequence.groups); groupIndex++ {
+		errList := []error{}
+		for _, nodeToSync := range sequence.getGroup(groupIndex) {
+			// Nb. The operation is wrapped in a retry loop to make handover more resilient to unexpected conditions.
+			err := retryWithExponentialBackoff(ctx, syncTargetObjectBackoff, func(ctx context.Context) error {
+				return o.syncTargetObject(ctx, nodeToSync, toProxy, previouslySynced, synced, mutators, existingNamespaces)
+			})
+			if err != nil {
+				errList = append(errList, err)
+			}
+		}
+		if len(errList) > 0 {
+			return nil, kerrors.NewAggregate(errList)
+		}
+	}
+	return synced, nil
+}
+
+// syncTargetObject creates or updates the Kubernetes object in the target management cluster corresponding to the object graph node,
+// unless the source object has not changed since a previous sync.
+func (o *objectMover) syncTargetObject(ctx context.Context, nodeToSync *node, toProxy Proxy, previouslySynced, synced map[string]string, mutators []ResourceMutatorFunc, existingNamespaces sets.Set[string]) error {
+	if o.dryRun {
+		return o.createTargetObject(ctx, nodeToSync, toProxy, mutators, existingNamespaces)
+	}
+
+	cFrom, err := o.fromProxy.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Read the resource version of the source object before copying it, so changes happening during the copy are synced later.
+	sourceObj := &metav1.PartialObjectMetadata{}
+	sourceObj.SetGroupVersionKind(nodeToSync.identity.GroupVersionKind())
+	if err := cFrom.Get(ctx, client.ObjectKey{Namespace: nodeToSync.identity.Namespace, Name: nodeToSync.identity.Name}, sourceObj); err != nil {
+		return errors.Wrapf(err, "error reading %q %s/%s",
+			sourceObj.GroupVersionKind(), nodeToSync.identity.Namespace, nodeToSync.identity.Name)
+	}
+	key := handoverKey(nodeToSync)
+
+	if resourceVersion, ok := previouslySynced[key]; ok && resourceVersion == sourceObj.GetResourceVersion() {
+		// The object has not changed since the previous sync, so only the UID of the target object is required
+		// to rebuild the owner reference chain.
+		targetObj, err := getTargetObjectMetadata(ctx, nodeToSync, toProxy, mutators...)
+		if err == nil {
+			logf.Log.V(5).Info("Object not changed, skipping sync", nodeToSync.identity.Kind, nodeToSync.identity.Name, "Namespace", nodeToSync.identity.Namespace)
+			nodeToSync.newUID = targetObj.GetUID()
+			synced[key] = resourceVersion
+			return nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	if err := o.createTargetObject(ctx, nodeToSync, toProxy, mutators, existingNamespaces); err != nil {
+		return err
+	}
+	synced[key] = sourceObj.GetResourceVersion()
+	return nil
+}
+
+// deleteStaleTargetGroup deletes from the target management cluster the objects corresponding to the nodes in a moveGroup
+// which have not been synced, i.e. the objects deleted from the source cluster after the pre-copy.
+func (o *objectMover) deleteStaleTargetGroup(ctx context.Context, group moveGroup, synced map[string]string, toProxy Proxy, mutators ...ResourceMutatorFunc) error {
+	if o.dryRun {
+		return nil
+	}
+
+	deleteTargetObjectBackoff := newWriteBackoff()
+	errList := []error{}
+	for i := range group {
+		nodeToDelete := group[i]
+		if _, ok := synced[handoverKey(nodeToDelete)]; ok {
+			continue
+		}
+		// Don't delete objects which could be used by other Clusters in the target cluster.
+		if nodeToDelete.isGlobal || nodeToDelete.isGlobalHierarchy || nodeToDelete.shared {
+			continue
+		}
+
+		// Nb. The operation is wrapped in a retry loop to make handover more resilient to unexpected conditions.
+		err := retryWithExponentialBackoff(ctx, deleteTargetObjectBackoff, func(ctx context.Context) error {
+			return deleteTargetObject(ctx, nodeToDelete, toProxy, mutators...)
+		})
+		if err != nil {
+			errList = append(errList, err)
+		}
+	}
+
+	return kerrors.NewAggregate(errList)
+}
+
+// deleteTargetObject deletes the Kubernetes object corresponding to the node from the target management cluster, taking care of
+// removing all the finalizers so the objects gets immediately deleted (force delete).
+func deleteTargetObject(ctx context.Context, nodeToDelete *node, toProxy Proxy, mutators ...ResourceMutatorFunc) error {
+	log := logf.Log
+	log.V(1).Info("Deleting stale", nodeToDelete.identity.Kind, nodeToDelete.identity.Name, "Namespace", nodeToDelete.identity.Namespace)
+
+	cTo, err := toProxy.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	targetObj, err := getTargetObjectMetadata(ctx, nodeToDelete, toProxy, mutators...)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if len(targetObj.GetFinalizers()) > 0 {
+		if err := cTo.Patch(ctx, targetObj, removeFinalizersPatch); err != nil {
+			return errors.Wrapf(err, "error removing finalizers from %q %s/%s",
+				targetObj.GroupVersionKind(), targetObj.GetNamespace(), targetObj.GetName())
+		}
+	}
+
+	if err := cTo.Delete(ctx, targetObj); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "error deleting %q %s/%s",
+			targetObj.GroupVersionKind(), targetObj.GetNamespace(), targetObj.GetName())
+	}
+	return nil
+}
+
+// verifyTargetObjects checks that all the objects corresponding to the nodes exist in the target management cluster.
+func (o *objectMover) verifyTargetObjects(ctx context.Context, nodes []*node, toProxy Proxy, mutators ...ResourceMutatorFunc) error {
+	if o.dryRun {
+		return nil
+	}
+
+	errList := []error{}
+	for _, n := range nodes {
+		if _, err := getTargetObjectMetadata(ctx, n, toProxy, mutators...); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	if len(errList) > 0 {
+		return errors.Wrap(kerrors.NewAggregate(errList), "failed to verify objects in the target cluster")
+	}
+	return nil
+}
+
+// getTargetObjectMetadata reads the metadata of the object corresponding to the node from the target management cluster.
+func getTargetObjectMetadata(ctx context.Context, n *node, toProxy Proxy, mutators ...ResourceMutatorFunc) (*metav1.PartialObjectMetadata, error) {
+	cTo, err := toProxy.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Since only the metadata are read, the ONLY affect that mutators can have here is on namespace of the object.
+	mutatedObj, err := applyMutators(&metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: n.identity.APIVersion,
+			Kind:       n.identity.Kind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      n.identity.Name,
+			Namespace: n.identity.Namespace,
+		},
+	}, mutators...)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(n.identity.GroupVersionKind())
+	if err := cTo.Get(ctx, client.ObjectKeyFromObject(mutatedObj), obj); err != nil {
+		return nil, errors.Wrapf(err, "error reading %q %s/%s in the target cluster",
+			obj.GroupVersionKind(), mutatedObj.GetNamespace(), mutatedObj.GetName())
+	}
+	return obj, nil
+}
+
+// handoverKey returns a key identifying the object corresponding to a node across discoveries.
+func handoverKey(n *node) string {
+	return fmt.Sprintf("%s, %s/%s", n.identity.GroupVersionKind().GroupKind(), n.identity.Namespace, n.identity.Name)
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func handoverTestObjs() []client.Object {
+	objs := test.NewFakeClusterClass("ns1", "class1").Objs()
+	objs = append(objs, test.NewFakeCluster("ns1", "foo1").WithTopologyClass("class1").Objs()...)
+	objs = append(objs, test.NewFakeCluster("ns1", "foo2").WithTopologyClass("class1").Objs()...)
+	return deduplicateObjects(objs)
+}
+
+func Test_objectGraph_filterCluster(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	graph := getObjectGraphWithObjs(handoverTestObjs())
+	g.Expect(graph.getDiscoveryTypes(ctx)).To(Succeed())
+	g.Expect(graph.Discovery(ctx, "")).To(Succeed())
+
+	g.Expect(graph.filterCluster("ns1", "does-not-exist")).ToNot(Succeed())
+	g.Expect(graph.filterCluster("ns1", "foo1")).To(Succeed())
+
+	got := map[string]bool{}
+	for _, n := range graph.getMoveNodes() {
+		got[n.identity.Kind+", "+n.identity.Namespace+"/"+n.identity.Name] = n.shared
+	}
+	g.Expect(got).To(Equal(map[string]bool{
+		"ClusterClass, ns1/class1":                         true,
+		"GenericInfrastructureClusterTemplate, ns1/class1": true,
+		"GenericControlPlaneTemplate, ns1/class1":          true,
+		"Cluster, ns1/foo1":                                false,
+		"Secret, ns1/foo1-ca":                              false,
+		"Secret, ns1/foo1-kubeconfig":                      false,
+		"GenericInfrastructureCluster, ns1/foo1":           false,
+	}))
+}
+
+func Test_objectMover_handover(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	graph := getObjectGraphWithObjs(handoverTestObjs())
+	g.Expect(graph.getDiscoveryTypes(ctx)).To(Succeed())
+	g.Expect(graph.Discovery(ctx, "ns1")).To(Succeed())
+	g.Expect(graph.filterCluster("ns1", "foo1")).To(Succeed())
+
+	toProxy := getFakeProxyWithCRDs()
+
+	mover := objectMover{
+		fromProxy:             graph.proxy,
+		fromProviderInventory: graph.providerInventory,
+	}
+	g.Expect(mover.handover(ctx, graph, toProxy)).To(Succeed())
+
+	csFrom, err := graph.proxy.NewClient(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	csTo, err := toProxy.NewClient(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// The objects of the Cluster are moved, the shared objects are copied, and the other Clusters are not touched.
+	tests := []struct {
+		obj          client.Object
+		key          client.ObjectKey
+		wantInSource bool
+		wantInTarget bool
+	}{
+		{obj: &clusterv1.ClusterClass{}, key: client.ObjectKey{Namespace: "ns1", Name: "class1"}, wantInSource: true, wantInTarget: true},
+		{obj: &clusterv1.Cluster{}, key: client.ObjectKey{Namespace: "ns1", Name: "foo1"}, wantInSource: false, wantInTarget: true},
+		{obj: &corev1.Secret{}, key: client.ObjectKey{Namespace: "ns1", Name: "foo1-kubeconfig"}, wantInSource: false, wantInTarget: true},
+		{obj: &clusterv1.Cluster{}, key: client.ObjectKey{Namespace: "ns1", Name: "foo2"}, wantInSource: true, wantInTarget: false},
+		{obj: &corev1.Secret{}, key: client.ObjectKey{Namespace: "ns1", Name: "foo2-kubeconfig"}, wantInSource: true, wantInTarget: false},
+	}
+	for _, tt := range tests {
+		err := csFrom.Get(ctx, tt.key, tt.obj)
+		g.Expect(err == nil).To(Equal(tt.wantInSource), "%T %s in source cluster", tt.obj, tt.key)
+		if err != nil {
+			g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		}
+		err = csTo.Get(ctx, tt.key, tt.obj)
+		g.Expect(err == nil).To(Equal(tt.wantInTarget), "%T %s in target cluster", tt.obj, tt.key)
+		if err != nil {
+			g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		}
+	}
+
+	// The Cluster is resumed in the target cluster.
+	cluster := &clusterv1.Cluster{}
+	g.Expect(csTo.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "foo1"}, cluster)).To(Succeed())
+	g.Expect(cluster.Spec.Paused).To(BeFalse())
+
+	// Other Clusters are not paused in the source cluster.
+	g.Expect(csFrom.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "foo2"}, cluster)).To(Succeed())
+	g.Expect(cluster.Spec.Paused).To(BeFalse())
+}
+
+func Test_objectMover_syncObjects(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	graph := getObjectGraphWithObjs(test.NewFakeCluster("ns1", "foo").Objs())
+	g.Expect(graph.getDiscoveryTypes(ctx)).To(Succeed())
+	g.Expect(graph.Discovery(ctx, "ns1")).To(Succeed())
+	g.Expect(graph.filterCluster("ns1", "foo")).To(Succeed())
+
+	toProxy := getFakeProxyWithCRDs()
+
+	mover := objectMover{
+		fromProxy:             graph.proxy,
+		fromProviderInventory: graph.providerInventory,
+	}
+
+	// Pre-copy the objects, creating the Cluster paused.
+	sequence := getMoveSequence(graph)
+	preCopied, err := mover.syncObjects(ctx, sequence, toProxy, nil, pauseClusterMutator)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(preCopied).To(HaveLen(len(graph.getMoveNodes())))
+
+	csFrom, err := graph.proxy.NewClient(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	csTo, err := toProxy.NewClient(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	cluster := &clusterv1.Cluster{}
+	g.Expect(csTo.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "foo"}, cluster)).To(Succeed())
+	g.Expect(cluster.Spec.Paused).To(BeTrue())
+
+	// Change an object in the source cluster, and sync again.
+	secret := &corev1.Secret{}
+	secretKey := client.ObjectKey{Namespace: "ns1", Name: "foo-kubeconfig"}
+	g.Expect(csFrom.Get(ctx, secretKey, secret)).To(Succeed())
+	secret.Labels = map[string]string{"changed": ""}
+	g.Expect(csFrom.Update(ctx, secret)).To(Succeed())
+
+	synced, err := mover.syncObjects(ctx, sequence, toProxy, preCopied, pauseClusterMutator)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(synced).To(HaveLen(len(preCopied)))
+
+	g.Expect(csTo.Get(ctx, secretKey, secret)).To(Succeed())
+	g.Expect(secret.Labels).To(HaveKey("changed"))
+
+	// Objects not synced, e.g. because they have been deleted from the source cluster, are deleted from the target cluster.
+	g.Expect(synced).To(HaveKey("Secret, ns1/foo-kubeconfig"))
+	delete(synced, "Secret, ns1/foo-kubeconfig")
+	for groupIndex := len(sequence.groups) - 1; groupIndex >= 0; groupIndex-- {
+		g.Expect(mover.deleteStaleTargetGroup(ctx, sequence.getGroup(groupIndex), synced, toProxy)).To(Succeed())
+	}
+	err = csTo.Get(ctx, secretKey, secret)
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	g.Expect(csTo.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "foo"}, cluster)).To(Succeed())
+}
+
+func Test_pauseClusterMutator(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &unstructured.Unstructured{}
+	cluster.SetGroupVersionKind(clusterv1.GroupVersion.WithKind(clusterv1.ClusterKind))
+	g.Expect(pauseClusterMutator(cluster)).To(Succeed())
+	paused, _, err := unstructured.NestedBool(cluster.Object, "spec", "paused")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(paused).To(BeTrue())
+
+	machine := &unstructured.Unstructured{}
+	machine.SetGroupVersionKind(clusterv1.GroupVersion.WithKind("Machine"))
+	g.Expect(pauseClusterMutator(machine)).To(Succeed())
+	g.Expect(machine.Object).ToNot(HaveKey("spec"))
+}
@@ -23,23 +23,18 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
-	appsv1 "k8s.io/api/apps/v1"
-	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/version"
-	"k8s.io/apimachinery/pkg/util/wait"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/scheme"
-	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/util"
 	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
 	"sigs.k8s.io/cluster-api/util/contract"
 )
@@ -70,6 +65,13 @@ type ProviderInstaller interface {
 type InstallOptions struct {
 	WaitProviders       bool
 	WaitProviderTimeout time.Duration
+
+	// ReadinessTimeouts defines the timeout of each check performed when waiting for the providers to be ready;
+	// if a timeout is not set, WaitProviderTimeout is used.
+	ReadinessTimeouts ProviderReadinessTimeouts
+
+	// ExternalCertificates defines the webhook serving certificates to use instead of cert-manager, if enabled.
+	ExternalCertificates ExternalCertificatesOptions
 }
 
 // providerInstaller implements ProviderInstaller.
@@ -94,6 +96,14 @@ func (i *providerInstaller) Add(components repository.Components) {
 }
 
 func (i *providerInstaller) Install(ctx context.Context, opts InstallOptions) ([]repository.Components, error) {
+	// Use the existing webhook serving certificates, if requested; this is done for all the providers before installing
+	// any of them, so missing certificates are detected before changing the management cluster.
+	for _, components := range i.installQueue {
+		if err := useExternalCertificates(ctx, i.proxy, components, opts.ExternalCertificates); err != nil {
+			return nil, err
+		}
+	}
+
 	ret := make([]repository.Components, 0, len(i.installQueue))
 	for _, components := range i.installQueue {
 		if err := installComponentsAndUpdateInventory(ctx, components, i.providerComponents, i.providerInventory); err != nil {
@@ -132,44 +142,7 @@ func waitForProvidersReady(ctx context.Context, opts InstallOptions, installQueu
 	log := logf.Log
 	log.Info("Waiting for providers to be available...")
 
-	return waitManagerDeploymentsReady(ctx, opts, installQueue, proxy)
-}
-
-// waitManagerDeploymentsReady waits till the installed manager deployments are ready.
-func waitManagerDeploymentsReady(ctx context.Context, opts InstallOptions, installQueue []repository.Components, proxy Proxy) error {
-	for _, components := range installQueue {
-		for _, obj := range components.Objs() {
-			if util.IsDeploymentWithManager(obj) {
-				if err := waitDeploymentReady(ctx, obj, opts.WaitProviderTimeout, proxy); err != nil {
-					return errors.Wrapf(err, "deployment %q is not ready after %s", obj.GetName(), opts.WaitProviderTimeout)
-				}
-			}
-		}
-	}
-	return nil
-}
-
-func waitDeploymentReady(ctx context.Context, deployment unstructured.Unstructured, timeout time.Duration, proxy Proxy) error {
-	return wait.PollUntilContextTimeout(ctx, 100*time.Millisecond, timeout, false, func(ctx context.Context) (bool, error) {
-		c, err := proxy.NewClient(ctx)
-		if err != nil {
-			return false, err
-		}
-		key := client.ObjectKey{
-			Namespace: deployment.GetNamespace(),
-			Name:      deployment.GetName(),
-		}
-		dep := &appsv1.Deployment{}
-		if err := c.Get(ctx, key, dep); err != nil {
-			return false, err
-		}
-		for _, c := range dep.Status.Conditions {
-			if c.Type == appsv1.DeploymentAvailable && c.Status == corev1.ConditionTrue {
-				return true, nil
-			}
-		}
-		return false, nil
-	})
+	return checkProvidersReadiness(ctx, opts, installQueue, proxy)
 }
 
 func (i *providerInstaller) Validate(ctx context.Context) error {
@@ -18,7 +18,9 @@ package dryrun
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
 
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -83,6 +85,11 @@ type Client struct {
 	fakeClient client.Client
 	apiReader  client.Reader
 
+	// serverClient, if set, is used for sending write operations to a Kubernetes Cluster with server-side dry-run,
+	// so the objects in the internal object tracker reflect the actual result of the write operations, e.g.
+	// including defaulting and mutations by webhooks, and write operations rejected by the Cluster fail.
+	serverClient client.Client
+
 	changeTracker *changeTracker
 }
 
@@ -125,6 +132,18 @@ func NewClient(apiReader client.Reader, objs []client.Object) *Client {
 	}
 }
 
+// NewServerSideDryRunClient returns a new dry run Client that, in addition to what a Client returned by NewClient does,
+// sends all the Create, Patch and Delete operations to the Kubernetes Cluster with server-side dry-run, and uses the
+// objects returned by the Kubernetes Cluster as the result of the operations.
+// Nb. Patch operations on objects that only exist in the internal object tracker, e.g. because they have been
+// created by a previous operation, are not sent to the Kubernetes Cluster, given that the Kubernetes Cluster does not
+// persist objects created with server-side dry-run.
+func NewServerSideDryRunClient(serverClient client.Client, objs []client.Object) *Client {
+	c := NewClient(serverClient, objs)
+	c.serverClient = serverClient
+	return c
+}
+
 // Get retrieves an object for the given object key from the internal object tracker.
 // If the object does not exist in the internal object tracker it tries to fetch the object
 // from the Kubernetes Cluster using the apiReader client (if apiReader is not nil).
@@ -179,6 +198,17 @@ func (c *Client) List(ctx context.Context, list client.ObjectList, opts ...clien
 
 // Create saves the object in the internal object tracker.
 func (c *Client) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if c.serverClient != nil {
+		serverObj := obj.DeepCopyObject().(client.Object)
+		if err := c.serverClient.Create(ctx, serverObj, append(opts, client.DryRunAll)...); err != nil {
+			return err
+		}
+		// The object is not persisted by the Kubernetes Cluster, so it must be created in the internal object tracker.
+		serverObj.SetResourceVersion("")
+		if err := copyObject(serverObj, obj); err != nil {
+			return err
+		}
+	}
 	err := c.fakeClient.Create(ctx, obj, opts...)
 	if err == nil {
 		id := trackerIDFor(obj)
@@ -201,6 +231,11 @@ func (c *Client) Create(ctx context.Context, obj client.Object, opts ...client.C
 // Delete deletes the given obj from internal object tracker.
 // Delete will not affect objects in the Kubernetes Cluster.
 func (c *Client) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if c.serverClient != nil {
+		if err := c.serverClient.Delete(ctx, obj.DeepCopyObject().(client.Object), append(opts, client.DryRunAll)...); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
 	err := c.fakeClient.Delete(ctx, obj, opts...)
 	if err != nil {
 		if !apierrors.IsNotFound(err) {
@@ -265,7 +300,22 @@ func (c *Client) Patch(ctx context.Context, obj client.Object, patch client.Patc
 	if err := c.ensureObjInFakeClient(ctx, obj); err != nil {
 		return errors.Wrap(err, "failed to ensure object is available in fake object tracker")
 	}
+	// Compute the result of the patch on the object in the Kubernetes Cluster before patching the internal object tracker,
+	// where the object is going to be replaced by the result.
+	var serverObj client.Object
+	if c.serverClient != nil {
+		serverObj = obj.DeepCopyObject().(client.Object)
+		if err := c.serverClient.Patch(ctx, serverObj, patch, append(opts, client.DryRunAll)...); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			serverObj = nil
+		}
+	}
 	err := c.fakeClient.Patch(ctx, obj, patch, opts...)
+	if err == nil && serverObj != nil {
+		err = c.replaceInFakeClient(ctx, serverObj, obj)
+	}
 	if err == nil {
 		id := trackerIDFor(obj)
 		// If the object is not already tracked, track the modify operation.
@@ -353,6 +403,11 @@ func (c *Client) Changes(ctx context.Context) (*ChangeSummary, error) {
 			if err := c.Scheme().Convert(op.originalValue, before, nil); err != nil {
 				return nil, errors.Wrapf(err, "failed to convert %s to unstructured", client.ObjectKeyFromObject(op.originalValue).String())
 			}
+			// Managed fields are dropped from the objects returned by server-side dry-run, drop them from
+			// the initial object too so they do not show up as a change.
+			if c.serverClient != nil {
+				before.SetManagedFields(nil)
+			}
 			changes.Modified = append(changes.Modified, &PatchSummary{
 				Before: before,
 				After:  after,
@@ -388,6 +443,38 @@ func (c *Client) ensureObjInFakeClient(ctx context.Context, obj client.Object) e
 	return nil
 }
 
+// replaceInFakeClient replaces the object in the internal object tracker with newObj, e.g. an object returned by
+// a server-side dry-run operation, and then copies the stored object in obj.
+func (c *Client) replaceInFakeClient(ctx context.Context, newObj, obj client.Object) error {
+	newObj = newObj.DeepCopyObject().(client.Object)
+	newObj.SetResourceVersion(obj.GetResourceVersion())
+	if err := c.fakeClient.Update(ctx, newObj); err != nil {
+		return errors.Wrap(err, "failed to store the result of the server-side dry-run in the fake object tracker")
+	}
+	return copyObject(newObj, obj)
+}
+
+// copyObject replaces the content of the dst object with the content of the src object, preserving the GroupVersionKind of dst.
+func copyObject(src, dst client.Object) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %s", client.ObjectKeyFromObject(src))
+	}
+	gvk := dst.GetObjectKind().GroupVersionKind()
+	v := reflect.ValueOf(dst).Elem()
+	v.Set(reflect.Zero(v.Type()))
+	if err := json.Unmarshal(data, dst); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal %s", client.ObjectKeyFromObject(src))
+	}
+	// Objects returned by typed clients do not have the GroupVersionKind set, while it is required for tracking the changes.
+	if !gvk.Empty() {
+		dst.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+	// Drop managed fields, they are not relevant for dry run.
+	dst.SetManagedFields(nil)
+	return nil
+}
+
 // mergeLists merges the 2 lists a and b by adding every item in b
 // that is not in a to list a.
 // List a will be merged list.
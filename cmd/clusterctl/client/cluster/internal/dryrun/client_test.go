@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestServerSideDryRunClient(t *testing.T) {
+	configMapTypeMeta := metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"}
+	existing := &corev1.ConfigMap{
+		TypeMeta:   configMapTypeMeta,
+		ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: metav1.NamespaceDefault},
+		Data:       map[string]string{"key": "value"},
+	}
+
+	// newServerClient returns a client for a fake server that adds a "defaulted" label to the objects
+	// sent with dry-run, thus mimicking a mutating webhook, and rejects objects named "invalid".
+	newServerClient := func(g *WithT) client.Client {
+		return fake.NewClientBuilder().WithScheme(localScheme).WithObjects(existing.DeepCopy()).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				g.Expect(opts).To(ContainElement(client.DryRunAll))
+				if obj.GetName() == "invalid" {
+					return apierrors.NewBadRequest("invalid object")
+				}
+				obj.SetLabels(map[string]string{"defaulted": "true"})
+				return nil
+			},
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				g.Expect(opts).To(ContainElement(client.DryRunAll))
+				// The fake client does not return the result of the patch in dry-run mode, so the patch is applied.
+				if err := c.Patch(ctx, obj, patch); err != nil {
+					return err
+				}
+				obj.SetLabels(map[string]string{"defaulted": "true"})
+				return nil
+			},
+			Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+				g.Expect(opts).To(ContainElement(client.DryRunAll))
+				return c.Get(ctx, client.ObjectKeyFromObject(obj), &corev1.ConfigMap{})
+			},
+		}).Build()
+	}
+
+	t.Run("Create uses the object returned by the server", func(t *testing.T) {
+		g := NewWithT(t)
+		c := NewServerSideDryRunClient(newServerClient(g), nil)
+
+		obj := &corev1.ConfigMap{TypeMeta: configMapTypeMeta, ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: metav1.NamespaceDefault}}
+		g.Expect(c.Create(context.Background(), obj)).To(Succeed())
+		g.Expect(obj.GetLabels()).To(HaveKeyWithValue("defaulted", "true"))
+
+		changes, err := c.Changes(context.Background())
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(changes.Created).To(HaveLen(1))
+		g.Expect(changes.Created[0].GetLabels()).To(HaveKeyWithValue("defaulted", "true"))
+	})
+
+	t.Run("Create fails if the server rejects the object", func(t *testing.T) {
+		g := NewWithT(t)
+		c := NewServerSideDryRunClient(newServerClient(g), nil)
+
+		obj := &corev1.ConfigMap{TypeMeta: configMapTypeMeta, ObjectMeta: metav1.ObjectMeta{Name: "invalid", Namespace: metav1.NamespaceDefault}}
+		g.Expect(c.Create(context.Background(), obj)).ToNot(Succeed())
+
+		changes, err := c.Changes(context.Background())
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(changes.Created).To(BeEmpty())
+	})
+
+	t.Run("Patch uses the object returned by the server", func(t *testing.T) {
+		g := NewWithT(t)
+		c := NewServerSideDryRunClient(newServerClient(g), nil)
+
+		obj := &corev1.ConfigMap{TypeMeta: configMapTypeMeta}
+		g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(existing), obj)).To(Succeed())
+		original := obj.DeepCopy()
+		obj.Data["key"] = "new-value"
+		g.Expect(c.Patch(context.Background(), obj, client.MergeFrom(original))).To(Succeed())
+		g.Expect(obj.GetLabels()).To(HaveKeyWithValue("defaulted", "true"))
+		g.Expect(obj.Data).To(HaveKeyWithValue("key", "new-value"))
+
+		changes, err := c.Changes(context.Background())
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(changes.Modified).To(HaveLen(1))
+		g.Expect(changes.Modified[0].After.GetLabels()).To(HaveKeyWithValue("defaulted", "true"))
+	})
+
+	t.Run("Patch of objects that only exist in the dry run client is not sent to the server", func(t *testing.T) {
+		g := NewWithT(t)
+		c := NewServerSideDryRunClient(newServerClient(g), []client.Object{
+			&corev1.ConfigMap{TypeMeta: configMapTypeMeta, ObjectMeta: metav1.ObjectMeta{Name: "local", Namespace: metav1.NamespaceDefault}},
+		})
+
+		obj := &corev1.ConfigMap{TypeMeta: configMapTypeMeta}
+		g.Expect(c.Get(context.Background(), client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "local"}, obj)).To(Succeed())
+		original := obj.DeepCopy()
+		obj.Data = map[string]string{"key": "value"}
+		g.Expect(c.Patch(context.Background(), obj, client.MergeFrom(original))).To(Succeed())
+		g.Expect(obj.GetLabels()).ToNot(HaveKey("defaulted"))
+		g.Expect(obj.Data).To(HaveKeyWithValue("key", "value"))
+	})
+
+	t.Run("Delete is sent to the server", func(t *testing.T) {
+		g := NewWithT(t)
+		c := NewServerSideDryRunClient(newServerClient(g), nil)
+
+		obj := &corev1.ConfigMap{TypeMeta: configMapTypeMeta}
+		g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(existing), obj)).To(Succeed())
+		g.Expect(c.Delete(context.Background(), obj)).To(Succeed())
+
+		changes, err := c.Changes(context.Background())
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(changes.Deleted).To(HaveLen(1))
+	})
+}
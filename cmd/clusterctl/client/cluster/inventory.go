@@ -239,12 +239,26 @@ func checkInventoryCRDs(ctx context.Context, proxy Proxy) (bool, error) {
 		return false, errors.Wrap(err, "failed to check if the clusterctl inventory CRD exists")
 	}
 
+	found := false
 	for _, version := range crd.Spec.Versions {
 		if version.Name == clusterctlv1.GroupVersion.Version {
-			return true, nil
+			found = true
+			break
 		}
 	}
-	return true, errors.Errorf("clusterctl inventory CRD does not defines the %s version", clusterctlv1.GroupVersion.Version)
+	if !found {
+		return true, errors.Errorf("clusterctl inventory CRD does not defines the %s version", clusterctlv1.GroupVersion.Version)
+	}
+
+	// Management clusters initialized with older versions of clusterctl do not have the ProviderInstallation CRD;
+	// in this case CRDs are installed again, skipping the ones already existing.
+	if err := c.Get(ctx, client.ObjectKey{Name: fmt.Sprintf("providerinstallations.%s", clusterctlv1.GroupVersion.Group)}, &apiextensionsv1.CustomResourceDefinition{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "failed to check if the clusterctl ProviderInstallation CRD exists")
+	}
+	return true, nil
 }
 
 func (p *inventoryClient) createObj(ctx context.Context, o unstructured.Unstructured) error {
@@ -0,0 +1,241 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+)
+
+// movePlan describes what a move operation is going to do, as computed during a dry-run move.
+type movePlan struct {
+	// paused are the objects paused in the source cluster for the duration of the move.
+	paused []corev1.ObjectReference
+
+	// groups are the objects moved to the target cluster, grouped according to the move sequence.
+	groups [][]movePlanObject
+
+	// blockers are the issues preventing the move operation from completing.
+	blockers []error
+}
+
+// movePlanObject describes an object moved to the target cluster.
+type movePlanObject struct {
+	identity corev1.ObjectReference
+
+	// mutations are the changes applied to the object when it is created in the target cluster,
+	// e.g. a different namespace set by a mutator, or the owner references rebuilt with the UIDs of the new owners.
+	mutations []string
+}
+
+// dryRunMove computes the plan of a move operation without performing any real action, logs it, and returns an error
+// if any blocker is detected; checks on the target cluster are performed only if a target cluster is provided.
+func (o *objectMover) dryRunMove(ctx context.Context, graph *objectGraph, toCluster Client, mutators ...ResourceMutatorFunc) error {
+	var toProxy Proxy
+	var toInventory InventoryClient
+	if toCluster != nil {
+		toProxy = toCluster.Proxy()
+		toInventory = toCluster.ProviderInventory()
+	}
+
+	plan, err := o.planMove(ctx, graph, toProxy, toInventory, mutators...)
+	if err != nil {
+		return err
+	}
+	plan.log(toProxy != nil)
+
+	if len(plan.blockers) > 0 {
+		return errors.Wrapf(kerrors.NewAggregate(plan.blockers), "move dry-run detected %d blocker(s)", len(plan.blockers))
+	}
+	return nil
+}
+
+// planMove computes the plan of a move operation; toProxy and toInventory are optional, and if they are not set
+// the checks on the target cluster are skipped.
+func (o *objectMover) planMove(ctx context.Context, graph *objectGraph, toProxy Proxy, toInventory InventoryClient, mutators ...ResourceMutatorFunc) (*movePlan, error) {
+	plan := &movePlan{}
+
+	for _, n := range graph.getClusters() {
+		plan.paused = append(plan.paused, n.identity)
+	}
+	for _, n := range notShared(graph.getClusterClasses()) {
+		plan.paused = append(plan.paused, n.identity)
+	}
+
+	cFrom, err := o.fromProxy.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	moveSequence := getMoveSequence(graph)
+	for groupIndex := 0; groupIndex < len(moveSequence.groups); groupIndex++ {
+		var group []movePlanObject
+		for _, n := range moveSequence.getGroup(groupIndex) {
+			object, err := planMoveObject(ctx, cFrom, n, mutators...)
+			if err != nil {
+				return nil, err
+			}
+			group = append(group, object)
+		}
+		plan.groups = append(plan.groups, group)
+	}
+
+	// Objects which are not yet provisioned can't be safely paused.
+	if err := o.checkProvisioningCompleted(ctx, graph); err != nil {
+		plan.addBlockers(err)
+	}
+
+	for _, n := range graph.getMoveNodes() {
+		if n.blockingMove {
+			plan.blockers = append(plan.blockers, errors.Errorf("%s %s is blocking the move with the %s annotation", n.identity.Kind, objectRefString(n.identity), clusterctlv1.BlockMoveAnnotation))
+		}
+	}
+
+	if toProxy == nil {
+		return plan, nil
+	}
+
+	if toInventory != nil {
+		if err := o.checkTargetProviders(ctx, toInventory); err != nil {
+			plan.addBlockers(err)
+		}
+	}
+
+	toGraph := newObjectGraph(toProxy, toInventory)
+	if err := toGraph.getDiscoveryTypes(ctx); err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve discovery types from the target cluster")
+	}
+	missingTypes := sets.Set[string]{}
+	for _, n := range graph.getMoveNodes() {
+		typeMeta := metav1.TypeMeta{Kind: n.identity.Kind, APIVersion: n.identity.APIVersion}
+		if _, ok := toGraph.types[getKindAPIString(typeMeta)]; !ok {
+			missingTypes.Insert(fmt.Sprintf("%s (%s)", typeMeta.Kind, typeMeta.APIVersion))
+		}
+	}
+	for _, t := range sets.List(missingTypes) {
+		plan.blockers = append(plan.blockers, errors.Errorf("the CRD for %s is missing in the target cluster", t))
+	}
+
+	return plan, nil
+}
+
+// planMoveObject computes the changes applied to an object when it is created in the target cluster.
+func planMoveObject(ctx context.Context, cFrom client.Client, n *node, mutators ...ResourceMutatorFunc) (movePlanObject, error) {
+	object := movePlanObject{identity: n.identity}
+
+	owners := []string{}
+	for owner := range n.owners {
+		owners = append(owners, fmt.Sprintf("%s/%s", owner.identity.Kind, owner.identity.Name))
+	}
+	sort.Strings(owners)
+	if len(owners) > 0 {
+		object.mutations = append(object.mutations, fmt.Sprintf("ownerReferences rebuilt for %s", strings.Join(owners, ", ")))
+	}
+
+	if len(mutators) == 0 {
+		return object, nil
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(n.identity.APIVersion)
+	obj.SetKind(n.identity.Kind)
+	if err := cFrom.Get(ctx, client.ObjectKey{Namespace: n.identity.Namespace, Name: n.identity.Name}, obj); err != nil {
+		return movePlanObject{}, errors.Wrapf(err, "error reading %q %s/%s",
+			obj.GroupVersionKind(), n.identity.Namespace, n.identity.Name)
+	}
+	// Nb. Mutators could change obj in place, so the namespace and the name are compared with the node identity.
+	mutated, err := applyMutators(obj, mutators...)
+	if err != nil {
+		return movePlanObject{}, err
+	}
+	if mutated.GetNamespace() != n.identity.Namespace {
+		object.mutations = append(object.mutations, fmt.Sprintf("namespace %s -> %s", n.identity.Namespace, mutated.GetNamespace()))
+	}
+	if mutated.GetName() != n.identity.Name {
+		object.mutations = append(object.mutations, fmt.Sprintf("name %s -> %s", n.identity.Name, mutated.GetName()))
+	}
+	return object, nil
+}
+
+// addBlockers adds the given error to the blockers, flattening aggregate errors.
+func (p *movePlan) addBlockers(err error) {
+	var aggregate kerrors.Aggregate
+	if errors.As(err, &aggregate) {
+		p.blockers = append(p.blockers, aggregate.Errors()...)
+		return
+	}
+	p.blockers = append(p.blockers, err)
+}
+
+// log logs the plan.
+func (p *movePlan) log(targetChecked bool) {
+	log := logf.Log
+
+	log.Info("Objects to be paused in the source cluster", "count", len(p.paused))
+	for _, ref := range p.paused {
+		log.Info(fmt.Sprintf("  %s %s", ref.Kind, objectRefString(ref)))
+	}
+
+	count := 0
+	for _, group := range p.groups {
+		count += len(group)
+	}
+	log.Info("Objects to be moved to the target cluster", "count", count, "groups", len(p.groups))
+	for i, group := range p.groups {
+		log.Info(fmt.Sprintf("  Group %d", i+1))
+		for _, object := range group {
+			line := fmt.Sprintf("    %s %s", object.identity.Kind, objectRefString(object.identity))
+			if len(object.mutations) > 0 {
+				line = fmt.Sprintf("%s: %s", line, strings.Join(object.mutations, "; "))
+			}
+			log.Info(line)
+		}
+	}
+
+	if !targetChecked {
+		log.Info("Target cluster not provided, skipping checks on the target cluster")
+	}
+	if len(p.blockers) == 0 {
+		log.Info("No blockers detected")
+		return
+	}
+	log.Info("Blockers detected", "count", len(p.blockers))
+	for _, err := range p.blockers {
+		log.Info(fmt.Sprintf("  %s", err))
+	}
+}
+
+// objectRefString returns namespace/name for namespaced objects, or name for global objects.
+func objectRefString(ref corev1.ObjectReference) string {
+	if ref.Namespace == "" {
+		return ref.Name
+	}
+	return fmt.Sprintf("%s/%s", ref.Namespace, ref.Name)
+}
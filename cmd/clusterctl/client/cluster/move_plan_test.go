@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_objectMover_planMove(t *testing.T) {
+	namespaceMutator := func(u *unstructured.Unstructured) error {
+		u.SetNamespace("ns2")
+		return nil
+	}
+
+	tests := []struct {
+		name          string
+		toProxy       Proxy
+		mutators      []ResourceMutatorFunc
+		wantMutations map[string][]string
+		wantBlockers  []string
+	}{
+		{
+			name: "Reports owner references rebuilt in the target cluster",
+			wantMutations: map[string][]string{
+				"Cluster ns1/foo":                      nil,
+				"GenericInfrastructureCluster ns1/foo": {"ownerReferences rebuilt for Cluster/foo"},
+				"Secret ns1/foo-ca":                    nil,
+				"Secret ns1/foo-kubeconfig":            {"ownerReferences rebuilt for Cluster/foo"},
+			},
+		},
+		{
+			name:     "Reports namespaces changed by mutators",
+			mutators: []ResourceMutatorFunc{namespaceMutator},
+			wantMutations: map[string][]string{
+				"Cluster ns1/foo":           {"namespace ns1 -> ns2"},
+				"Secret ns1/foo-kubeconfig": {"ownerReferences rebuilt for Cluster/foo", "namespace ns1 -> ns2"},
+			},
+		},
+		{
+			name:    "Reports CRDs missing in the target cluster",
+			toProxy: test.NewFakeProxy(),
+			wantBlockers: []string{
+				"the CRD for Cluster (cluster.x-k8s.io/v1beta1) is missing in the target cluster",
+				"the CRD for GenericInfrastructureCluster (infrastructure.cluster.x-k8s.io/v1beta1) is missing in the target cluster",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			ctx := context.Background()
+
+			graph := getObjectGraphWithObjs(test.NewFakeCluster("ns1", "foo").Objs())
+			g.Expect(graph.getDiscoveryTypes(ctx)).To(Succeed())
+			g.Expect(graph.Discovery(ctx, "")).To(Succeed())
+
+			mover := objectMover{
+				fromProxy: graph.proxy,
+				dryRun:    true,
+			}
+			plan, err := mover.planMove(ctx, graph, tt.toProxy, nil, tt.mutators...)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			g.Expect(plan.paused).To(HaveLen(1))
+			g.Expect(plan.paused[0].Kind).To(Equal("Cluster"))
+			g.Expect(plan.groups).To(HaveLen(2))
+
+			gotMutations := map[string][]string{}
+			for _, group := range plan.groups {
+				for _, object := range group {
+					gotMutations[object.identity.Kind+" "+objectRefString(object.identity)] = object.mutations
+				}
+			}
+			for ref, want := range tt.wantMutations {
+				g.Expect(gotMutations).To(HaveKeyWithValue(ref, want))
+			}
+
+			for _, want := range tt.wantBlockers {
+				g.Expect(kerrors.NewAggregate(plan.blockers).Error()).To(ContainSubstring(want))
+			}
+		})
+	}
+}
+
+func Test_objectMover_dryRunMove(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx := context.Background()
+
+	graph := getObjectGraphWithObjs(test.NewFakeCluster("ns1", "foo").Objs())
+	g.Expect(graph.getDiscoveryTypes(ctx)).To(Succeed())
+	g.Expect(graph.Discovery(ctx, "")).To(Succeed())
+
+	mover := objectMover{
+		fromProxy: graph.proxy,
+		dryRun:    true,
+	}
+
+	// The fake Cluster is not yet provisioned, so it is reported as a blocker.
+	err := mover.dryRunMove(ctx, graph, nil)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("move dry-run detected"))
+	g.Expect(err.Error()).To(ContainSubstring("still provisioning the infrastructure"))
+}
@@ -19,8 +19,6 @@ package cluster
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/pkg/errors"
@@ -28,6 +26,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -51,13 +50,34 @@ type ResourceMutatorFunc func(u *unstructured.Unstructured) error
 // ObjectMover defines methods for moving Cluster API objects to another management cluster.
 type ObjectMover interface {
 	// Move moves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a target management cluster.
+	// In dry-run mode, Move reports the objects that would be paused, moved and mutated, and returns an error if any blocker
+	// is detected, e.g. objects not yet provisioned or CRDs missing in the target cluster; toCluster is optional in dry-run mode.
 	Move(ctx context.Context, namespace string, toCluster Client, dryRun bool, mutators ...ResourceMutatorFunc) error
 
-	// ToDirectory writes all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a target directory.
-	ToDirectory(ctx context.Context, namespace string, directory string) error
-
-	// FromDirectory reads all the Cluster API objects existing in a configured directory to a target management cluster.
-	FromDirectory(ctx context.Context, toCluster Client, directory string) error
+	// MoveSelected moves the Clusters existing in a namespace (or in all the namespaces if empty) with labels matching
+	// the selector, and the objects they depend on, to a target management cluster; objects not belonging to the selected
+	// Clusters are not moved, and objects shared with other Clusters, e.g. a ClusterClass, are copied but not deleted.
+	MoveSelected(ctx context.Context, namespace string, selector labels.Selector, toCluster Client, dryRun bool, mutators ...ResourceMutatorFunc) error
+
+	// ToDirectory writes all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a target directory;
+	// mutators are applied to the objects before writing them.
+	// If the directory contains a previous backup, only the objects changed since then are written again, and the manifest
+	// of the backup with the checksums of all the files is updated.
+	ToDirectory(ctx context.Context, namespace string, directory string, options BackupOptions, mutators ...ResourceMutatorFunc) error
+
+	// FromDirectory reads all the Cluster API objects existing in a configured directory to a target management cluster;
+	// mutators are applied to the objects after reading them.
+	// If required by the options, the backup is verified against its manifest before restoring any object.
+	FromDirectory(ctx context.Context, toCluster Client, directory string, options BackupOptions, mutators ...ResourceMutatorFunc) error
+
+	// Handover moves a single Cluster existing in a namespace, and the objects it depends on, to a target management cluster,
+	// pausing the Cluster only for the time required to sync the changes happened after copying its objects.
+	Handover(ctx context.Context, namespace, clusterName string, toCluster Client, dryRun bool, mutators ...ResourceMutatorFunc) error
+
+	// MoveWithCheckpoint moves the Clusters existing in a namespace (or in all the namespaces if empty) with labels matching
+	// the selector like MoveSelected, recording the progress in a checkpoint file; if the checkpoint file exists, the interrupted
+	// move is resumed without creating again the objects already moved. The checkpoint file is removed when the move completes.
+	MoveWithCheckpoint(ctx context.Context, namespace string, selector labels.Selector, toCluster Client, checkpointFile string, mutators ...ResourceMutatorFunc) error
 }
 
 // objectMover implements the ObjectMover interface.
@@ -65,14 +85,25 @@ type objectMover struct {
 	fromProxy             Proxy
 	fromProviderInventory InventoryClient
 	dryRun                bool
+
+	// checkpoint records the progress of the move, if any.
+	checkpoint *moveCheckpoint
 }
 
 // ensure objectMover implements the ObjectMover interface.
 var _ ObjectMover = &objectMover{}
 
 func (o *objectMover) Move(ctx context.Context, namespace string, toCluster Client, dryRun bool, mutators ...ResourceMutatorFunc) error {
+	return o.MoveSelected(ctx, namespace, labels.Everything(), toCluster, dryRun, mutators...)
+}
+
+func (o *objectMover) MoveSelected(ctx context.Context, namespace string, selector labels.Selector, toCluster Client, dryRun bool, mutators ...ResourceMutatorFunc) error {
 	log := logf.Log
-	log.Info("Performing move...")
+	if selector.Empty() {
+		log.Info("Performing move...")
+	} else {
+		log.Info("Performing move...", "selector", selector.String())
+	}
 	o.dryRun = dryRun
 	if o.dryRun {
 		log.Info("********************************************************")
@@ -87,11 +118,16 @@ func (o *objectMover) Move(ctx context.Context, namespace string, toCluster Clie
 		}
 	}
 
-	objectGraph, err := o.getObjectGraph(ctx, namespace)
+	objectGraph, err := o.getObjectGraph(ctx, namespace, selector)
 	if err != nil {
 		return errors.Wrap(err, "failed to get object graph")
 	}
 
+	// In dry-run mode, report what the move is going to do and the detected blockers, without performing any real action.
+	if o.dryRun {
+		return o.dryRunMove(ctx, objectGraph, toCluster, mutators...)
+	}
+
 	// Move the objects to the target cluster.
 	var proxy Proxy
 	if !o.dryRun {
@@ -101,27 +137,42 @@ func (o *objectMover) Move(ctx context.Context, namespace string, toCluster Clie
 	return o.move(ctx, objectGraph, proxy, mutators...)
 }
 
-func (o *objectMover) ToDirectory(ctx context.Context, namespace string, directory string) error {
+func (o *objectMover) ToDirectory(ctx context.Context, namespace string, directory string, options BackupOptions, mutators ...ResourceMutatorFunc) error {
 	log := logf.Log
 	log.Info("Moving to directory...")
 
-	objectGraph, err := o.getObjectGraph(ctx, namespace)
+	objectGraph, err := o.getObjectGraph(ctx, namespace, labels.Everything())
 	if err != nil {
 		return errors.Wrap(err, "failed to get object graph")
 	}
 
-	return o.toDirectory(ctx, objectGraph, directory)
+	return o.toDirectory(ctx, objectGraph, directory, options, mutators...)
 }
 
-func (o *objectMover) FromDirectory(ctx context.Context, toCluster Client, directory string) error {
+func (o *objectMover) FromDirectory(ctx context.Context, toCluster Client, directory string, options BackupOptions, mutators ...ResourceMutatorFunc) error {
 	log := logf.Log
 	log.Info("Moving from directory...")
 
+	if err := options.validate(); err != nil {
+		return err
+	}
+
+	manifest, err := readBackupManifest(directory)
+	if err != nil {
+		return err
+	}
+	if options.Verify {
+		log.Info(fmt.Sprintf("Verifying backup in %s", directory))
+		if err := verifyBackup(directory, manifest, options.EncryptionKey); err != nil {
+			return errors.Wrap(err, "failed to verify backup")
+		}
+	}
+
 	// Build an empty object graph used for the fromDirectory sequence not tied to a specific namespace
 	objectGraph := newObjectGraph(o.fromProxy, o.fromProviderInventory)
 
 	// Gets all the types defined by the CRDs installed by clusterctl plus the ConfigMap/Secret core types.
-	err := objectGraph.getDiscoveryTypes(ctx)
+	err = objectGraph.getDiscoveryTypes(ctx)
 	if err != nil {
 		return errors.Wrap(err, "failed to retrieve discovery types")
 	}
@@ -131,6 +182,23 @@ func (o *objectMover) FromDirectory(ctx context.Context, toCluster Client, direc
 		return errors.Wrap(err, "failed to process object files")
 	}
 
+	// Secrets encrypted in the backup are decrypted before applying mutators, so mutators operate on the actual data.
+	for i := range objs {
+		if err := decryptSecret(&objs[i], options.EncryptionKey); err != nil {
+			return err
+		}
+	}
+
+	// Mutators are applied before building the graph, so the graph is built with e.g. the namespaces in the target cluster.
+	for i := range objs {
+		for _, mutator := range mutators {
+			if err := mutator(&objs[i]); err != nil {
+				return errors.Wrapf(err, "error applying resource mutator to %q %s/%s",
+					objs[i].GroupVersionKind(), objs[i].GetNamespace(), objs[i].GetName())
+			}
+		}
+	}
+
 	for i := range objs {
 		if err = objectGraph.addRestoredObj(&objs[i]); err != nil {
 			return err
@@ -157,23 +225,11 @@ func (o *objectMover) filesToObjs(dir string) ([]unstructured.Unstructured, erro
 	log := logf.Log
 	log.Info(fmt.Sprintf("Restoring files from %s", dir))
 
-	files, err := os.ReadDir(dir)
+	rawYAMLs, err := readBackupObjects(dir)
 	if err != nil {
 		return nil, err
 	}
 
-	rawYAMLs := make([][]byte, 0)
-	for i := range files {
-		path := filepath.Clean(filepath.Join(dir, files[i].Name()))
-
-		byObj, err := os.ReadFile(path)
-		if err != nil {
-			return nil, err
-		}
-
-		rawYAMLs = append(rawYAMLs, byObj)
-	}
-
 	processedYAMLs := yaml.JoinYaml(rawYAMLs...)
 
 	objs, err := yaml.ToUnstructured(processedYAMLs)
@@ -184,7 +240,9 @@ func (o *objectMover) filesToObjs(dir string) ([]unstructured.Unstructured, erro
 	return objs, nil
 }
 
-func (o *objectMover) getObjectGraph(ctx context.Context, namespace string) (*objectGraph, error) {
+// getObjectGraph returns the object graph with the objects in a namespace; if the selector is not empty, only the Clusters
+// matching the selector and the objects they depend on are included in the graph.
+func (o *objectMover) getObjectGraph(ctx context.Context, namespace string, selector labels.Selector) (*objectGraph, error) {
 	objectGraph := newObjectGraph(o.fromProxy, o.fromProviderInventory)
 
 	// Gets all the types defined by the CRDs installed by clusterctl plus the ConfigMap/Secret core types.
@@ -200,12 +258,22 @@ func (o *objectMover) getObjectGraph(ctx context.Context, namespace string) (*ob
 		return nil, errors.Wrap(err, "failed to discover the object graph")
 	}
 
+	// Drops from the graph all the objects not required by the selected Clusters.
+	if !selector.Empty() {
+		if err := o.filterClustersBySelector(ctx, objectGraph, namespace, selector); err != nil {
+			return nil, err
+		}
+	}
+
 	// Checks if Cluster API has already completed the provisioning of the infrastructure for the objects involved in the move/toDirectory operation.
 	// This is required because if the infrastructure is provisioned, then we can reasonably assume that the objects we are moving/backing up are
 	// not currently waiting for long-running reconciliation loops, and so we can safely rely on the pause field on the Cluster object
 	// for blocking any further object reconciliation on the source objects.
-	if err := o.checkProvisioningCompleted(ctx, objectGraph); err != nil {
-		return nil, errors.Wrap(err, "failed to check for provisioned infrastructure")
+	// Nb. In dry-run mode, objects not yet provisioned are reported as blockers in the move plan.
+	if !o.dryRun {
+		if err := o.checkProvisioningCompleted(ctx, objectGraph); err != nil {
+			return nil, errors.Wrap(err, "failed to check for provisioned infrastructure")
+		}
 	}
 
 	// Check whether nodes are not included in GVK considered for move
@@ -221,11 +289,40 @@ func newObjectMover(fromProxy Proxy, fromProviderInventory InventoryClient) *obj
 	}
 }
 
+// filterClustersBySelector drops from the graph all the objects not required by the Clusters matching the selector.
+func (o *objectMover) filterClustersBySelector(ctx context.Context, graph *objectGraph, namespace string, selector labels.Selector) error {
+	c, err := o.fromProxy.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	clusterList := &clusterv1.ClusterList{}
+	if err := retryWithExponentialBackoff(ctx, newReadBackoff(), func(ctx context.Context) error {
+		return c.List(ctx, clusterList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector})
+	}); err != nil {
+		return errors.Wrapf(err, "failed to list Clusters matching selector %q", selector.String())
+	}
+
+	selected := sets.Set[string]{}
+	for _, cluster := range clusterList.Items {
+		selected.Insert(client.ObjectKeyFromObject(&cluster).String())
+	}
+	var clusters []*node
+	for _, n := range graph.getClusters() {
+		if selected.Has(client.ObjectKey{Namespace: n.identity.Namespace, Name: n.identity.Name}.String()) {
+			clusters = append(clusters, n)
+		}
+	}
+	if len(clusters) == 0 {
+		return errors.Errorf("failed to find Clusters matching selector %q", selector.String())
+	}
+
+	graph.filterClusters(clusters)
+	return nil
+}
+
 // checkProvisioningCompleted checks if Cluster API has already completed the provisioning of the infrastructure for the objects involved in the move operation.
 func (o *objectMover) checkProvisioningCompleted(ctx context.Context, graph *objectGraph) error {
-	if o.dryRun {
-		return nil
-	}
 	errList := []error{}
 
 	// Checking all the clusters have infrastructure is ready
@@ -324,14 +421,20 @@ func (o *objectMover) move(ctx context.Context, graph *objectGraph, toProxy Prox
 	clusterClasses := graph.getClusterClasses()
 	log.Info("Moving Cluster API objects", "ClusterClasses", len(clusterClasses))
 
+	// Records the Clusters and ClusterClasses to resume in the target cluster, in case the move gets interrupted.
+	if err := o.checkpoint.startCreating(clusters, clusterClasses); err != nil {
+		return err
+	}
+
 	// Sets the pause field on the Cluster object in the source management cluster, so the controllers stop reconciling it.
 	log.V(1).Info("Pausing the source cluster")
 	if err := setClusterPause(ctx, o.fromProxy, clusters, true, o.dryRun); err != nil {
 		return err
 	}
 
+	// Nb. ClusterClasses shared with Clusters which are not moved are not paused, because they are still in use in the source cluster.
 	log.V(1).Info("Pausing the source ClusterClasses")
-	if err := setClusterClassPause(ctx, o.fromProxy, clusterClasses, true, o.dryRun); err != nil {
+	if err := setClusterClassPause(ctx, o.fromProxy, notShared(clusterClasses), true, o.dryRun); err != nil {
 		return errors.Wrap(err, "error pausing ClusterClasses")
 	}
 
@@ -361,10 +464,18 @@ func (o *objectMover) move(ctx context.Context, graph *objectGraph, toProxy Prox
 
 	// Create all objects group by group, ensuring all the ownerReferences are re-created.
 	log.Info("Creating objects in the target cluster")
+	total := 0
+	for _, group := range moveSequence.groups {
+		total += len(group)
+	}
+	created := 0
 	for groupIndex := 0; groupIndex < len(moveSequence.groups); groupIndex++ {
-		if err := o.createGroup(ctx, moveSequence.getGroup(groupIndex), toProxy, mutators...); err != nil {
+		group := moveSequence.getGroup(groupIndex)
+		if err := o.createGroup(ctx, group, toProxy, mutators...); err != nil {
 			return err
 		}
+		created += len(group)
+		logMoveProgress(moveCreatingPhase, created, total)
 	}
 
 	// Nb. mutators used after this point (after creating the resources on target clusters) are mainly intended for
@@ -372,11 +483,45 @@ func (o *objectMover) move(ctx context.Context, graph *objectGraph, toProxy Prox
 	// mutators affecting non metadata fields are no-op after this point.
 
 	// Delete all objects group by group in reverse order.
-	log.Info("Deleting objects from the source cluster")
+	deleteGroups := []moveGroup{}
+	toDelete := []*node{}
 	for groupIndex := len(moveSequence.groups) - 1; groupIndex >= 0; groupIndex-- {
-		if err := o.deleteGroup(ctx, moveSequence.getGroup(groupIndex)); err != nil {
+		group := moveGroup{}
+		for _, n := range moveSequence.getGroup(groupIndex) {
+			if !n.isGlobal && !n.isGlobalHierarchy && !n.shared {
+				group = append(group, n)
+			}
+		}
+		deleteGroups = append(deleteGroups, group)
+		toDelete = append(toDelete, group...)
+	}
+	if err := o.checkpoint.startDeleting(toDelete); err != nil {
+		return err
+	}
+
+	return o.completeMove(ctx, toProxy, deleteGroups, len(toDelete), clusters, clusterClasses, mutators...)
+}
+
+// completeMove deletes the objects from the source cluster, group by group, and then resumes the Clusters and the ClusterClasses
+// in the target cluster; total is the number of objects to delete, including the objects already deleted by an interrupted move.
+func (o *objectMover) completeMove(ctx context.Context, toProxy Proxy, deleteGroups []moveGroup, total int, clusters, clusterClasses []*node, mutators ...ResourceMutatorFunc) error {
+	log := logf.Log
+
+	log.Info("Deleting objects from the source cluster")
+	deleted := total
+	for _, group := range deleteGroups {
+		deleted -= len(group)
+	}
+	for _, group := range deleteGroups {
+		if err := o.deleteGroup(ctx, group); err != nil {
 			return err
 		}
+		deleted += len(group)
+		logMoveProgress(moveDeletingPhase, deleted, total)
+	}
+
+	if err := o.checkpoint.startResuming(); err != nil {
+		return err
 	}
 
 	// Resume the ClusterClasses in the target management cluster, so the controllers start reconciling it.
@@ -390,9 +535,14 @@ func (o *objectMover) move(ctx context.Context, graph *objectGraph, toProxy Prox
 	return setClusterPause(ctx, toProxy, clusters, false, o.dryRun, mutators...)
 }
 
-func (o *objectMover) toDirectory(ctx context.Context, graph *objectGraph, directory string) error {
+func (o *objectMover) toDirectory(ctx context.Context, graph *objectGraph, directory string, options BackupOptions, mutators ...ResourceMutatorFunc) error {
 	log := logf.Log
 
+	backup, err := newBackupWriter(directory, options)
+	if err != nil {
+		return err
+	}
+
 	clusters := graph.getClusters()
 	log.Info("Starting move of Cluster API objects", "Clusters", len(clusters))
 
@@ -420,11 +570,16 @@ func (o *objectMover) toDirectory(ctx context.Context, graph *objectGraph, direc
 	// Save all objects group by group
 	log.Info(fmt.Sprintf("Saving files to %s", directory))
 	for groupIndex := 0; groupIndex < len(moveSequence.groups); groupIndex++ {
-		if err := o.backupGroup(ctx, moveSequence.getGroup(groupIndex), directory); err != nil {
+		if err := o.backupGroup(ctx, moveSequence.getGroup(groupIndex), backup, mutators...); err != nil {
 			return err
 		}
 	}
 
+	// Write the manifest of the snapshot, making it the latest snapshot of the backup.
+	if err := backup.complete(); err != nil {
+		return errors.Wrap(err, "failed to write backup manifest")
+	}
+
 	// Resume the ClusterClasses in the target management cluster, so the controllers start reconciling it.
 	log.V(1).Info("Resuming the target ClusterClasses")
 	if err := setClusterClassPause(ctx, o.fromProxy, clusterClasses, false, o.dryRun); err != nil {
@@ -553,6 +708,17 @@ func getMoveSequence(graph *objectGraph) *moveSequence {
 	return moveSequence
 }
 
+// notShared returns the nodes which are not shared with Clusters not being moved.
+func notShared(nodes []*node) []*node {
+	var ret []*node
+	for _, n := range nodes {
+		if !n.shared {
+			ret = append(ret, n)
+		}
+	}
+	return ret
+}
+
 // setClusterPause sets the paused field on nodes referring to Cluster objects.
 func setClusterPause(ctx context.Context, proxy Proxy, clusters []*node, value bool, dryRun bool, mutators ...ResourceMutatorFunc) error {
 	if dryRun {
@@ -867,11 +1033,20 @@ func (o *objectMover) createGroup(ctx context.Context, group moveGroup, toProxy
 	// Nb. This prevents us from making repetitive (and expensive) calls in listing all namespaces to ensure a namespace exists before creating a resource.
 	existingNamespaces := sets.New[string]()
 	for _, nodeToCreate := range group {
+		// Skips the objects already created by an interrupted move, restoring the UID used for re-creating the ownerReferences.
+		if uid, ok := o.checkpoint.createdUID(nodeToCreate); ok {
+			nodeToCreate.newUID = uid
+			continue
+		}
+
 		// Creates the Kubernetes object corresponding to the nodeToCreate.
 		// Nb. The operation is wrapped in a retry loop to make move more resilient to unexpected conditions.
 		err := retryWithExponentialBackoff(ctx, createTargetObjectBackoff, func(ctx context.Context) error {
 			return o.createTargetObject(ctx, nodeToCreate, toProxy, mutators, existingNamespaces)
 		})
+		if err == nil {
+			err = o.checkpoint.created(nodeToCreate)
+		}
 		if err != nil {
 			errList = append(errList, err)
 		}
@@ -884,7 +1059,7 @@ func (o *objectMover) createGroup(ctx context.Context, group moveGroup, toProxy
 	return nil
 }
 
-func (o *objectMover) backupGroup(ctx context.Context, group moveGroup, directory string) error {
+func (o *objectMover) backupGroup(ctx context.Context, group moveGroup, backup *backupWriter, mutators ...ResourceMutatorFunc) error {
 	backupTargetObjectBackoff := newWriteBackoff()
 	errList := []error{}
 
@@ -892,7 +1067,7 @@ func (o *objectMover) backupGroup(ctx context.Context, group moveGroup, director
 		// Backs-up the Kubernetes object corresponding to the nodeToBackup.
 		// Nb. The operation is wrapped in a retry loop to make move more resilient to unexpected conditions.
 		err := retryWithExponentialBackoff(ctx, backupTargetObjectBackoff, func(ctx context.Context) error {
-			return o.backupTargetObject(ctx, nodeToBackup, directory)
+			return o.backupTargetObject(ctx, nodeToBackup, backup, mutators...)
 		})
 		if err != nil {
 			errList = append(errList, err)
@@ -996,10 +1171,26 @@ func (o *objectMover) createTargetObject(ctx context.Context, nodeToCreate *node
 		}
 
 		// If the object already exists, try to update it if it is node a global object / something belonging to a global object hierarchy (e.g. a secrets owned by a global identity object).
-		if nodeToCreate.isGlobal || nodeToCreate.isGlobalHierarchy {
+		switch {
+		case nodeToCreate.isGlobal || nodeToCreate.isGlobalHierarchy:
 			log.V(5).Info("Object already exists, skipping upgrade because it is global/it is owned by a global object", nodeToCreate.identity.Kind, nodeToCreate.identity.Name, "Namespace", nodeToCreate.identity.Namespace)
-		} else {
-			// Nb. This should not happen, but it is supported to make move more resilient to unexpected interrupt/restarts of the move process.
+		case nodeToCreate.shared:
+			// Nb. Objects shared with other Clusters, e.g. a ClusterClass, could be already in use by other Clusters in the target cluster.
+			log.V(5).Info("Object already exists, skipping update because it is shared with other Clusters", nodeToCreate.identity.Kind, nodeToCreate.identity.Name, "Namespace", nodeToCreate.identity.Namespace)
+
+			// Stores the UID of the existing object, so the owner reference chain can be rebuilt.
+			existingTargetObj := &unstructured.Unstructured{}
+			existingTargetObj.SetAPIVersion(obj.GetAPIVersion())
+			existingTargetObj.SetKind(obj.GetKind())
+			if err := cTo.Get(ctx, client.ObjectKeyFromObject(obj), existingTargetObj); err != nil {
+				return errors.Wrapf(err, "error reading resource for %q %s/%s",
+					existingTargetObj.GroupVersionKind(), existingTargetObj.GetNamespace(), existingTargetObj.GetName())
+			}
+			nodeToCreate.newUID = existingTargetObj.GetUID()
+			return nil
+		default:
+			// Nb. This is expected when syncing the changes of a Cluster during a handover; otherwise it should not happen,
+			// but it is supported to make move more resilient to unexpected interrupt/restarts of the move process.
 			log.V(5).Info("Object already exists, updating", nodeToCreate.identity.Kind, nodeToCreate.identity.Name, "Namespace", nodeToCreate.identity.Namespace)
 
 			// Retrieve the UID and the resource version for the update.
@@ -1030,7 +1221,7 @@ func (o *objectMover) createTargetObject(ctx context.Context, nodeToCreate *node
 	return nil
 }
 
-func (o *objectMover) backupTargetObject(ctx context.Context, nodeToCreate *node, directory string) error {
+func (o *objectMover) backupTargetObject(ctx context.Context, nodeToCreate *node, backup *backupWriter, mutators ...ResourceMutatorFunc) error {
 	log := logf.Log
 	log.V(1).Info("Saving", nodeToCreate.identity.Kind, nodeToCreate.identity.Name, "Namespace", nodeToCreate.identity.Namespace)
 
@@ -1053,32 +1244,13 @@ func (o *objectMover) backupTargetObject(ctx context.Context, nodeToCreate *node
 			obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
 	}
 
-	// Get JSON for object and write it into the configured directory
-	byObj, err := obj.MarshalJSON()
-	if err != nil {
-		return err
-	}
-
-	filenameObj := nodeToCreate.getFilename()
-	objectFile := filepath.Join(directory, filenameObj)
-
-	// If file exists, then remove it to be written again
-	_, err = os.Stat(objectFile)
-	if err != nil && !os.IsNotExist(err) {
-		return err
-	}
-	if err == nil {
-		if err := os.Remove(objectFile); err != nil {
-			return err
-		}
-	}
-
-	err = os.WriteFile(objectFile, byObj, 0600)
+	obj, err = applyMutators(obj, mutators...)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// Write the object into the configured directory
+	return backup.write(nodeToCreate.getFilename(), obj)
 }
 
 func (o *objectMover) restoreTargetObject(ctx context.Context, nodeToCreate *node, toProxy Proxy) error {
@@ -1174,6 +1346,9 @@ func (o *objectMover) deleteGroup(ctx context.Context, group moveGroup) error {
 		err := retryWithExponentialBackoff(ctx, deleteSourceObjectBackoff, func(ctx context.Context) error {
 			return o.deleteSourceObject(ctx, nodeToDelete)
 		})
+		if err == nil {
+			err = o.checkpoint.deleted(nodeToDelete)
+		}
 
 		if err != nil {
 			errList = append(errList, err)
@@ -1192,7 +1367,8 @@ var (
 // the objects gets immediately deleted (force delete).
 func (o *objectMover) deleteSourceObject(ctx context.Context, nodeToDelete *node) error {
 	// Don't delete cluster-wide nodes or nodes that are below a hierarchy that starts with a global object (e.g. a secrets owned by a global identity object).
-	if nodeToDelete.isGlobal || nodeToDelete.isGlobalHierarchy {
+	// Don't delete nodes shared with other Clusters during a handover, e.g. a ClusterClass.
+	if nodeToDelete.isGlobal || nodeToDelete.isGlobalHierarchy || nodeToDelete.shared {
 		return nil
 	}
 
@@ -1249,10 +1425,6 @@ func (o *objectMover) deleteSourceObject(ctx context.Context, nodeToDelete *node
 
 // checkTargetProviders checks that all the providers installed in the source cluster exists in the target cluster as well (with a version >= of the current version).
 func (o *objectMover) checkTargetProviders(ctx context.Context, toInventory InventoryClient) error {
-	if o.dryRun {
-		return nil
-	}
-
 	// Gets the list of providers in the source/target cluster.
 	fromProviders, err := o.fromProviderInventory.List(ctx)
 	if err != nil {
@@ -0,0 +1,355 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+)
+
+// movePhase is a phase of a move operation.
+type movePhase string
+
+const (
+	// moveCreatingPhase is the phase where the source objects are paused and created in the target cluster.
+	moveCreatingPhase movePhase = "Creating"
+
+	// moveDeletingPhase is the phase where all the objects exist in the target cluster, and they are deleted from the source cluster.
+	moveDeletingPhase movePhase = "Deleting"
+
+	// moveResumingPhase is the phase where the objects are deleted from the source cluster, and the Clusters
+	// and ClusterClasses are resumed in the target cluster.
+	moveResumingPhase movePhase = "Resuming"
+)
+
+// moveCheckpoint records the progress of a move, so an interrupted move can be resumed without creating again
+// the objects already moved, and without leaving the Clusters paused in both the source and the target cluster.
+// The checkpoint is written to its file at the start of each phase, while the objects created or deleted
+// during a phase are appended to a journal file next to it, which is compacted into the checkpoint file at the
+// start of the next phase; this keeps the cost of recording each object constant for large moves.
+// All the methods are no-op on a nil checkpoint, i.e. for moves without a checkpoint file.
+type moveCheckpoint struct {
+	path string
+
+	// Namespace, Selector, FromServer and ToServer identify the move the checkpoint belongs to.
+	Namespace  string `json:"namespace"`
+	Selector   string `json:"selector,omitempty"`
+	FromServer string `json:"fromServer"`
+	ToServer   string `json:"toServer"`
+
+	// Phase is the current phase of the move.
+	Phase movePhase `json:"phase"`
+
+	// Clusters and ClusterClasses are the objects to resume in the target cluster when completing the move.
+	Clusters       []corev1.ObjectReference `json:"clusters,omitempty"`
+	ClusterClasses []corev1.ObjectReference `json:"clusterClasses,omitempty"`
+
+	// Created maps the UIDs of the source objects already created in the target cluster to the UIDs in the target cluster.
+	Created map[types.UID]types.UID `json:"created,omitempty"`
+
+	// ToDelete are the objects to delete from the source cluster, in the order of deletion.
+	ToDelete []corev1.ObjectReference `json:"toDelete,omitempty"`
+
+	// Deleted are the UIDs of the objects already deleted from the source cluster.
+	Deleted map[types.UID]bool `json:"deleted,omitempty"`
+}
+
+// moveJournalEntry is a line of the journal of a moveCheckpoint, recording an object created in the target cluster
+// or deleted from the source cluster.
+type moveJournalEntry struct {
+	UID     types.UID `json:"uid"`
+	NewUID  types.UID `json:"newUID,omitempty"`
+	Deleted bool      `json:"deleted,omitempty"`
+}
+
+// loadMoveCheckpoint reads the checkpoint of a move from a file, or returns a new checkpoint if the file does not exist.
+// It returns an error if the checkpoint in the file belongs to a different move.
+func loadMoveCheckpoint(path, namespace string, selector labels.Selector, fromProxy, toProxy Proxy) (*moveCheckpoint, error) {
+	c := &moveCheckpoint{
+		path:       path,
+		Namespace:  namespace,
+		FromServer: proxyServer(fromProxy),
+		ToServer:   proxyServer(toProxy),
+		Created:    map[types.UID]types.UID{},
+		Deleted:    map[types.UID]bool{},
+	}
+	if selector != nil && !selector.Empty() {
+		c.Selector = selector.String()
+	}
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read move checkpoint %s", path)
+	}
+
+	previous := &moveCheckpoint{}
+	if err := json.Unmarshal(data, previous); err != nil {
+		return nil, errors.Wrapf(err, "failed to read move checkpoint %s", path)
+	}
+	if previous.Namespace != c.Namespace || previous.Selector != c.Selector || previous.FromServer != c.FromServer || previous.ToServer != c.ToServer {
+		return nil, errors.Errorf("move checkpoint %s belongs to a different move: namespace %q, selector %q, from %q to %q",
+			path, previous.Namespace, previous.Selector, previous.FromServer, previous.ToServer)
+	}
+
+	previous.path = path
+	if previous.Created == nil {
+		previous.Created = map[types.UID]types.UID{}
+	}
+	if previous.Deleted == nil {
+		previous.Deleted = map[types.UID]bool{}
+	}
+	if err := previous.replayJournal(); err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// journalPath returns the path of the journal of the checkpoint.
+func (c *moveCheckpoint) journalPath() string {
+	return c.path + ".journal"
+}
+
+// replayJournal applies the entries of the journal to the checkpoint.
+func (c *moveCheckpoint) replayJournal() error {
+	data, err := os.ReadFile(filepath.Clean(c.journalPath()))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to read move checkpoint journal %s", c.journalPath())
+	}
+
+	lines := bytes.Split(data, []byte("\n"))
+	// Note: the last line is empty, unless the move has been interrupted while appending it; in this case
+	// the entry is ignored, and the object is processed again when resuming the move.
+	for _, line := range lines[:len(lines)-1] {
+		entry := &moveJournalEntry{}
+		if err := json.Unmarshal(line, entry); err != nil {
+			return errors.Wrapf(err, "failed to read move checkpoint journal %s", c.journalPath())
+		}
+		if entry.Deleted {
+			c.Deleted[entry.UID] = true
+			continue
+		}
+		c.Created[entry.UID] = entry.NewUID
+	}
+	return nil
+}
+
+// appendJournal appends an entry to the journal of the checkpoint.
+func (c *moveCheckpoint) appendJournal(entry moveJournalEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal move checkpoint journal entry")
+	}
+	f, err := os.OpenFile(c.journalPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write move checkpoint journal %s", c.journalPath())
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return errors.Wrapf(err, "failed to write move checkpoint journal %s", c.journalPath())
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrapf(err, "failed to write move checkpoint journal %s", c.journalPath())
+	}
+	return nil
+}
+
+// proxyServer returns the address of the API server a proxy connects to.
+func proxyServer(proxy Proxy) string {
+	if proxy == nil {
+		return ""
+	}
+	config, err := proxy.GetConfig()
+	if err != nil || config == nil {
+		return ""
+	}
+	return config.Host
+}
+
+// isResuming returns true if the checkpoint records a move already started.
+func (c *moveCheckpoint) isResuming() bool {
+	return c != nil && c.Phase != ""
+}
+
+// startCreating records the Clusters and ClusterClasses to resume when completing the move, and starts the Creating phase.
+func (c *moveCheckpoint) startCreating(clusters, clusterClasses []*node) error {
+	if c == nil {
+		return nil
+	}
+	c.Phase = moveCreatingPhase
+	c.Clusters = identities(clusters)
+	c.ClusterClasses = identities(clusterClasses)
+	return c.save()
+}
+
+// createdUID returns the UID in the target cluster of an object already created by the move.
+func (c *moveCheckpoint) createdUID(n *node) (types.UID, bool) {
+	if c == nil {
+		return "", false
+	}
+	uid, ok := c.Created[n.identity.UID]
+	return uid, ok
+}
+
+// created records an object created in the target cluster.
+func (c *moveCheckpoint) created(n *node) error {
+	if c == nil {
+		return nil
+	}
+	c.Created[n.identity.UID] = n.newUID
+	return c.appendJournal(moveJournalEntry{UID: n.identity.UID, NewUID: n.newUID})
+}
+
+// startDeleting records the objects to delete from the source cluster, and starts the Deleting phase.
+func (c *moveCheckpoint) startDeleting(nodes []*node) error {
+	if c == nil {
+		return nil
+	}
+	c.Phase = moveDeletingPhase
+	c.ToDelete = identities(nodes)
+	return c.save()
+}
+
+// deleted records an object deleted from the source cluster.
+func (c *moveCheckpoint) deleted(n *node) error {
+	if c == nil {
+		return nil
+	}
+	c.Deleted[n.identity.UID] = true
+	return c.appendJournal(moveJournalEntry{UID: n.identity.UID, Deleted: true})
+}
+
+// startResuming starts the Resuming phase.
+func (c *moveCheckpoint) startResuming() error {
+	if c == nil {
+		return nil
+	}
+	c.Phase = moveResumingPhase
+	return c.save()
+}
+
+// remainingToDelete returns the nodes for the objects still to delete from the source cluster.
+func (c *moveCheckpoint) remainingToDelete() moveGroup {
+	group := moveGroup{}
+	for _, ref := range c.ToDelete {
+		if !c.Deleted[ref.UID] {
+			group = append(group, &node{identity: ref})
+		}
+	}
+	return group
+}
+
+// save writes the checkpoint to its file, compacting the journal into it.
+func (c *moveCheckpoint) save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal move checkpoint")
+	}
+	// The checkpoint is written to a temporary file first, so an interruption can't leave a partially written checkpoint.
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write move checkpoint %s", c.path)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return errors.Wrapf(err, "failed to write move checkpoint %s", c.path)
+	}
+	// Note: the journal is removed only once its entries are stored in the checkpoint file; as replaying entries
+	// already stored in the checkpoint file is a no-op, an interruption before removing it is harmless.
+	if err := os.Remove(c.journalPath()); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to remove move checkpoint journal %s", c.journalPath())
+	}
+	return nil
+}
+
+func (c *moveCheckpoint) remove() error {
+	if c == nil {
+		return nil
+	}
+	if err := os.Remove(c.journalPath()); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to remove move checkpoint journal %s", c.journalPath())
+	}
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to remove move checkpoint %s", c.path)
+	}
+	return nil
+}
+
+func identities(nodes []*node) []corev1.ObjectReference {
+	refs := make([]corev1.ObjectReference, 0, len(nodes))
+	for _, n := range nodes {
+		refs = append(refs, n.identity)
+	}
+	return refs
+}
+
+func nodesFromIdentities(refs []corev1.ObjectReference) []*node {
+	nodes := make([]*node, 0, len(refs))
+	for _, ref := range refs {
+		nodes = append(nodes, &node{identity: ref})
+	}
+	return nodes
+}
+
+// logMoveProgress reports the number of objects processed in a phase of the move.
+func logMoveProgress(phase movePhase, done, total int) {
+	logf.Log.Info("Move progress", "phase", phase, "objects", fmt.Sprintf("%d/%d", done, total))
+}
+
+func (o *objectMover) MoveWithCheckpoint(ctx context.Context, namespace string, selector labels.Selector, toCluster Client, checkpointFile string, mutators ...ResourceMutatorFunc) error {
+	log := logf.Log
+
+	checkpoint, err := loadMoveCheckpoint(checkpointFile, namespace, selector, o.fromProxy, toCluster.Proxy())
+	if err != nil {
+		return err
+	}
+	o.checkpoint = checkpoint
+	defer func() { o.checkpoint = nil }()
+
+	if checkpoint.isResuming() {
+		log.Info("Resuming move...", "phase", checkpoint.Phase, "checkpoint", checkpointFile)
+	}
+
+	// Once all the objects exist in the target cluster, the source cluster could not have the objects required for discovering
+	// the object graph anymore, so the move is completed using only the objects recorded in the checkpoint.
+	if checkpoint.Phase == moveDeletingPhase || checkpoint.Phase == moveResumingPhase {
+		err = o.completeMove(ctx, toCluster.Proxy(), []moveGroup{checkpoint.remainingToDelete()}, len(checkpoint.ToDelete),
+			nodesFromIdentities(checkpoint.Clusters), nodesFromIdentities(checkpoint.ClusterClasses), mutators...)
+	} else {
+		err = o.MoveSelected(ctx, namespace, selector, toCluster, false, mutators...)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "move interrupted, the progress is recorded in %s; run the move again with the same checkpoint file to resume it", checkpointFile)
+	}
+
+	return checkpoint.remove()
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test/providers/infrastructure"
+)
+
+func Test_loadMoveCheckpoint(t *testing.T) {
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	fromProxy := test.NewFakeProxy()
+	toProxy := test.NewFakeProxy()
+
+	// A new checkpoint is returned when the file does not exist.
+	checkpoint, err := loadMoveCheckpoint(path, "ns1", labels.SelectorFromSet(labels.Set{"tenant": "a"}), fromProxy, toProxy)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(checkpoint.isResuming()).To(BeFalse())
+
+	cluster := &node{
+		identity: corev1.ObjectReference{APIVersion: clusterv1.GroupVersion.String(), Kind: "Cluster", Namespace: "ns1", Name: "foo", UID: "source-uid"},
+		newUID:   "target-uid",
+	}
+	g.Expect(checkpoint.startCreating([]*node{cluster}, nil)).To(Succeed())
+	g.Expect(checkpoint.created(cluster)).To(Succeed())
+
+	// The checkpoint written to the file is read back for the same move.
+	resumed, err := loadMoveCheckpoint(path, "ns1", labels.SelectorFromSet(labels.Set{"tenant": "a"}), fromProxy, toProxy)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(resumed.isResuming()).To(BeTrue())
+	g.Expect(resumed.Phase).To(Equal(moveCreatingPhase))
+	g.Expect(resumed.Clusters).To(HaveLen(1))
+	uid, ok := resumed.createdUID(cluster)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(uid).To(BeEquivalentTo("target-uid"))
+
+	// The checkpoint can't be used for a different move.
+	_, err = loadMoveCheckpoint(path, "ns2", labels.SelectorFromSet(labels.Set{"tenant": "a"}), fromProxy, toProxy)
+	g.Expect(err).To(HaveOccurred())
+	_, err = loadMoveCheckpoint(path, "ns1", labels.Everything(), fromProxy, toProxy)
+	g.Expect(err).To(HaveOccurred())
+
+	// A nil checkpoint records nothing.
+	var noCheckpoint *moveCheckpoint
+	g.Expect(noCheckpoint.isResuming()).To(BeFalse())
+	g.Expect(noCheckpoint.created(cluster)).To(Succeed())
+	_, ok = noCheckpoint.createdUID(cluster)
+	g.Expect(ok).To(BeFalse())
+	g.Expect(noCheckpoint.remove()).To(Succeed())
+
+	g.Expect(resumed.remove()).To(Succeed())
+	g.Expect(path).ToNot(BeAnExistingFile())
+}
+
+func Test_moveCheckpoint_journal(t *testing.T) {
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	fromProxy := test.NewFakeProxy()
+	toProxy := test.NewFakeProxy()
+
+	checkpoint, err := loadMoveCheckpoint(path, "ns1", nil, fromProxy, toProxy)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	nodes := []*node{
+		{identity: corev1.ObjectReference{Kind: "Cluster", Namespace: "ns1", Name: "foo", UID: "uid-1"}, newUID: "new-uid-1"},
+		{identity: corev1.ObjectReference{Kind: "Machine", Namespace: "ns1", Name: "m1", UID: "uid-2"}, newUID: "new-uid-2"},
+		{identity: corev1.ObjectReference{Kind: "Machine", Namespace: "ns1", Name: "m2", UID: "uid-3"}, newUID: "new-uid-3"},
+	}
+	g.Expect(checkpoint.startCreating(nodes[:1], nil)).To(Succeed())
+	checkpointData, err := os.ReadFile(path) //nolint:gosec
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// Recording the objects created appends them to the journal, without writing the checkpoint file again.
+	g.Expect(checkpoint.created(nodes[0])).To(Succeed())
+	g.Expect(checkpoint.created(nodes[1])).To(Succeed())
+	g.Expect(os.ReadFile(path)).To(Equal(checkpointData)) //nolint:gosec
+	g.Expect(checkpoint.journalPath()).To(BeAnExistingFile())
+
+	// An entry partially written when the move has been interrupted is ignored.
+	f, err := os.OpenFile(checkpoint.journalPath(), os.O_APPEND|os.O_WRONLY, 0600)
+	g.Expect(err).ToNot(HaveOccurred())
+	_, err = f.WriteString(`{"uid":"uid-3","new`)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(f.Close()).To(Succeed())
+
+	resumed, err := loadMoveCheckpoint(path, "ns1", nil, fromProxy, toProxy)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(resumed.Created).To(HaveLen(2))
+	uid, ok := resumed.createdUID(nodes[1])
+	g.Expect(ok).To(BeTrue())
+	g.Expect(uid).To(BeEquivalentTo("new-uid-2"))
+	_, ok = resumed.createdUID(nodes[2])
+	g.Expect(ok).To(BeFalse())
+
+	// The journal is compacted into the checkpoint file at the start of the next phase.
+	g.Expect(resumed.startDeleting(nodes)).To(Succeed())
+	g.Expect(resumed.journalPath()).ToNot(BeAnExistingFile())
+	g.Expect(resumed.deleted(nodes[0])).To(Succeed())
+
+	resumed, err = loadMoveCheckpoint(path, "ns1", nil, fromProxy, toProxy)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(resumed.Created).To(HaveLen(2))
+	g.Expect(resumed.remainingToDelete()).To(HaveLen(2))
+
+	g.Expect(resumed.remove()).To(Succeed())
+	g.Expect(path).ToNot(BeAnExistingFile())
+	g.Expect(resumed.journalPath()).ToNot(BeAnExistingFile())
+}
+
+func Test_objectMover_move_resumeCreating(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	graph := getObjectGraphWithObjs(test.NewFakeCluster("ns1", "foo").Objs())
+	g.Expect(graph.getDiscoveryTypes(ctx)).To(Succeed())
+	g.Expect(graph.Discovery(ctx, "ns1")).To(Succeed())
+
+	toProxy := getFakeProxyWithCRDs()
+	mover := objectMover{
+		fromProxy: graph.proxy,
+	}
+
+	// Simulate a move interrupted after creating the Cluster in the target cluster.
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	checkpoint, err := loadMoveCheckpoint(path, "ns1", labels.Everything(), graph.proxy, toProxy)
+	g.Expect(err).ToNot(HaveOccurred())
+	clusterNode := graph.getClusters()[0]
+	g.Expect(checkpoint.startCreating(graph.getClusters(), nil)).To(Succeed())
+	g.Expect(mover.createTargetObject(ctx, clusterNode, toProxy, nil, sets.New[string]())).To(Succeed())
+	g.Expect(checkpoint.created(clusterNode)).To(Succeed())
+	clusterNode.newUID = ""
+
+	csTo, err := toProxy.NewClient(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	cluster := &clusterv1.Cluster{}
+	clusterKey := client.ObjectKey{Namespace: "ns1", Name: "foo"}
+	g.Expect(csTo.Get(ctx, clusterKey, cluster)).To(Succeed())
+	cluster.Labels = map[string]string{"resumed": "true"}
+	g.Expect(csTo.Update(ctx, cluster)).To(Succeed())
+
+	// Resume the move.
+	mover.checkpoint, err = loadMoveCheckpoint(path, "ns1", labels.Everything(), graph.proxy, toProxy)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(mover.move(ctx, graph, toProxy)).To(Succeed())
+	g.Expect(mover.checkpoint.Phase).To(Equal(moveResumingPhase))
+	g.Expect(mover.checkpoint.Created).To(HaveLen(len(graph.getMoveNodes())))
+
+	// The Cluster already created is not created again, and the other objects are created with the ownerReferences to the Cluster.
+	g.Expect(csTo.Get(ctx, clusterKey, cluster)).To(Succeed())
+	g.Expect(cluster.Labels).To(HaveKeyWithValue("resumed", "true"))
+	g.Expect(cluster.Spec.Paused).To(BeFalse())
+
+	infraCluster := &infrastructure.GenericInfrastructureCluster{}
+	g.Expect(csTo.Get(ctx, clusterKey, infraCluster)).To(Succeed())
+	g.Expect(infraCluster.OwnerReferences).To(ContainElement(HaveField("UID", cluster.UID)))
+
+	csFrom, err := graph.proxy.NewClient(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(apierrors.IsNotFound(csFrom.Get(ctx, clusterKey, &clusterv1.Cluster{}))).To(BeTrue())
+}
+
+func Test_objectMover_MoveWithCheckpoint_resumeDeleting(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	graph := getObjectGraphWithObjs(test.NewFakeCluster("ns1", "foo").Objs())
+	g.Expect(graph.getDiscoveryTypes(ctx)).To(Succeed())
+	g.Expect(graph.Discovery(ctx, "ns1")).To(Succeed())
+
+	toProxy := getFakeProxyWithCRDs()
+	mover := objectMover{
+		fromProxy:             graph.proxy,
+		fromProviderInventory: graph.providerInventory,
+	}
+
+	// Simulate a move interrupted after creating all the objects and deleting the InfrastructureCluster from the source cluster.
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	checkpoint, err := loadMoveCheckpoint(path, "ns1", labels.Everything(), graph.proxy, toProxy)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(checkpoint.startCreating(graph.getClusters(), nil)).To(Succeed())
+	for _, n := range graph.getMoveNodes() {
+		g.Expect(mover.createTargetObject(ctx, n, toProxy, nil, sets.New[string]())).To(Succeed())
+	}
+	g.Expect(setClusterPause(ctx, toProxy, graph.getClusters(), true, false)).To(Succeed())
+	g.Expect(checkpoint.startDeleting(graph.getMoveNodes())).To(Succeed())
+	for _, n := range graph.getMoveNodes() {
+		if n.identity.Kind == "GenericInfrastructureCluster" {
+			g.Expect(checkpoint.deleted(n)).To(Succeed())
+		}
+	}
+
+	// Resume the move.
+	g.Expect(mover.MoveWithCheckpoint(ctx, "ns1", labels.Everything(), New(Kubeconfig{}, nil, InjectProxy(toProxy)), path)).To(Succeed())
+	_, err = os.Stat(path)
+	g.Expect(os.IsNotExist(err)).To(BeTrue())
+
+	// The objects not yet deleted are deleted from the source cluster, and the Cluster is resumed in the target cluster.
+	csFrom, err := graph.proxy.NewClient(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	clusterKey := client.ObjectKey{Namespace: "ns1", Name: "foo"}
+	g.Expect(apierrors.IsNotFound(csFrom.Get(ctx, clusterKey, &clusterv1.Cluster{}))).To(BeTrue())
+	g.Expect(csFrom.Get(ctx, clusterKey, &infrastructure.GenericInfrastructureCluster{})).To(Succeed())
+
+	csTo, err := toProxy.NewClient(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	cluster := &clusterv1.Cluster{}
+	g.Expect(csTo.Get(ctx, clusterKey, cluster)).To(Succeed())
+	g.Expect(cluster.Spec.Paused).To(BeFalse())
+}
@@ -21,7 +21,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"testing"
 	"time"
 
@@ -30,6 +29,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -752,7 +752,8 @@ func Test_objectMover_backupTargetObject(t *testing.T) {
 			defer os.RemoveAll(dir)
 
 			for _, node := range graph.uidToNode {
-				err = mover.backupTargetObject(ctx, node, dir)
+				backup := newTestBackupWriter(t, dir)
+				err = mover.backupTargetObject(ctx, node, backup)
 				if tt.wantErr {
 					g.Expect(err).To(HaveOccurred())
 					return
@@ -767,7 +768,8 @@ func Test_objectMover_backupTargetObject(t *testing.T) {
 					t.Errorf("Could not access file map: %v\n", expectedFilename)
 				}
 
-				path := filepath.Join(dir, expectedFilename)
+				g.Expect(backup.current.Files).To(HaveKey(expectedFilename))
+				path := backupObjectPath(dir, backup.current.Files[expectedFilename].SHA256)
 				fileContents, err := os.ReadFile(path) //nolint:gosec
 				if err != nil {
 					g.Expect(err).ToNot(HaveOccurred())
@@ -783,11 +785,11 @@ func Test_objectMover_backupTargetObject(t *testing.T) {
 				fmt.Printf("Actual file content %v\n", string(fileContents))
 				g.Expect(string(fileContents)).To(Equal(expectedFileContents))
 
-				// Add delay so we ensure the file ModTime of updated files is different from old ones in the original files
+				// Add delay so we ensure the file ModTime of updated files would be different from old ones in the original files
 				time.Sleep(time.Millisecond * 50)
 
-				// Running backupTargetObject should override any existing files since it represents a new toDirectory
-				err = mover.backupTargetObject(ctx, node, dir)
+				// Running backupTargetObject again should not write again files with the same content
+				err = mover.backupTargetObject(ctx, node, newTestBackupWriter(t, dir))
 				if tt.wantErr {
 					g.Expect(err).To(HaveOccurred())
 					return
@@ -801,7 +803,7 @@ func Test_objectMover_backupTargetObject(t *testing.T) {
 					return
 				}
 
-				g.Expect(firstFileStat.ModTime()).To(BeTemporally("<", secondFileStat.ModTime()))
+				g.Expect(firstFileStat.ModTime()).To(Equal(secondFileStat.ModTime()))
 			}
 		})
 	}
@@ -950,7 +952,7 @@ func Test_objectMover_toDirectory(t *testing.T) {
 			}
 			defer os.RemoveAll(dir)
 
-			err = mover.toDirectory(ctx, graph, dir)
+			err = mover.toDirectory(ctx, graph, dir, BackupOptions{})
 			if tt.wantErr {
 				g.Expect(err).To(HaveOccurred())
 				return
@@ -978,19 +980,14 @@ func Test_objectMover_toDirectory(t *testing.T) {
 				g.Expect(err).ToNot(HaveOccurred())
 
 				// objects are stored in the temporary directory with the expected filename
-				files, err := os.ReadDir(dir)
+				manifest, err := readBackupManifest(dir)
 				g.Expect(err).ToNot(HaveOccurred())
 
 				expectedFilename := node.getFilename()
-				found := false
-				for _, f := range files {
-					if strings.Contains(f.Name(), expectedFilename) {
-						found = true
-					}
-				}
-
-				if !found {
+				if file, ok := manifest.Files[expectedFilename]; !ok {
 					missingFiles = append(missingFiles, expectedFilename)
+				} else {
+					g.Expect(backupObjectPath(dir, file.SHA256)).To(BeAnExistingFile())
 				}
 			}
 
@@ -2408,3 +2405,54 @@ func TestWaitReadyForMove(t *testing.T) {
 		})
 	}
 }
+
+func Test_objectMover_moveSelected(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	objs := handoverTestObjs()
+	for _, o := range objs {
+		if c, ok := o.(*clusterv1.Cluster); ok && c.Name == "foo1" {
+			c.Labels = map[string]string{"tenant": "a"}
+		}
+	}
+	graph := getObjectGraphWithObjs(objs)
+	g.Expect(graph.getDiscoveryTypes(ctx)).To(Succeed())
+	g.Expect(graph.Discovery(ctx, "ns1")).To(Succeed())
+
+	mover := objectMover{
+		fromProxy:             graph.proxy,
+		fromProviderInventory: graph.providerInventory,
+	}
+	g.Expect(mover.filterClustersBySelector(ctx, graph, "ns1", labels.SelectorFromSet(labels.Set{"tenant": "b"}))).ToNot(Succeed())
+	g.Expect(mover.filterClustersBySelector(ctx, graph, "ns1", labels.SelectorFromSet(labels.Set{"tenant": "a"}))).To(Succeed())
+
+	got := map[string]bool{}
+	for _, n := range graph.getMoveNodes() {
+		got[n.identity.Kind+", "+n.identity.Namespace+"/"+n.identity.Name] = n.shared
+	}
+	g.Expect(got).To(HaveKeyWithValue("ClusterClass, ns1/class1", true))
+	g.Expect(got).To(HaveKeyWithValue("Cluster, ns1/foo1", false))
+	g.Expect(got).ToNot(HaveKey("Cluster, ns1/foo2"))
+
+	toProxy := getFakeProxyWithCRDs()
+	g.Expect(mover.move(ctx, graph, toProxy)).To(Succeed())
+
+	csFrom, err := graph.proxy.NewClient(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	csTo, err := toProxy.NewClient(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// The selected Cluster is moved, the other Cluster is not touched.
+	cluster := &clusterv1.Cluster{}
+	g.Expect(csTo.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "foo1"}, cluster)).To(Succeed())
+	g.Expect(apierrors.IsNotFound(csFrom.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "foo1"}, cluster))).To(BeTrue())
+	g.Expect(csFrom.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "foo2"}, cluster)).To(Succeed())
+	g.Expect(cluster.Spec.Paused).To(BeFalse())
+
+	// The shared ClusterClass is copied to the target cluster, and it is not paused nor deleted in the source cluster.
+	clusterClass := &clusterv1.ClusterClass{}
+	g.Expect(csTo.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "class1"}, clusterClass)).To(Succeed())
+	g.Expect(csFrom.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "class1"}, clusterClass)).To(Succeed())
+	g.Expect(clusterClass.Annotations).ToNot(HaveKey(clusterv1.PausedAnnotation))
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ValidateNamespaceMapping validates a namespace mapping, i.e. that source and target namespaces are valid namespace names,
+// and that two source namespaces are not mapped to the same target namespace.
+func ValidateNamespaceMapping(mapping map[string]string) error {
+	targets := map[string]string{}
+	for source, target := range mapping {
+		for _, namespace := range []string{source, target} {
+			if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+				return errors.Errorf("invalid namespace %q in namespace mapping %s=%s: %v", namespace, source, target, errs)
+			}
+		}
+		if other, ok := targets[target]; ok {
+			return errors.Errorf("invalid namespace mapping: both %q and %q are mapped to %q", source, other, target)
+		}
+		targets[target] = source
+	}
+	return nil
+}
+
+// NamespaceMappingMutator returns a ResourceMutatorFunc moving objects from the source to the target namespaces of the
+// mapping; besides the namespace of the object, the namespace of object references is rewritten as well, e.g. the
+// infrastructureRef and controlPlaneRef of a Cluster, the templates referenced by a ClusterClass or a MachineDeployment,
+// or a secret referenced by an identity. Object references are detected as nested objects with both a namespace and a name.
+// Owner references do not need to be rewritten, because owners are always in the same namespace of the objects they own.
+// Objects in namespaces not included in the mapping and global objects are not changed.
+func NamespaceMappingMutator(mapping map[string]string) ResourceMutatorFunc {
+	return func(u *unstructured.Unstructured) error {
+		if len(mapping) == 0 {
+			return nil
+		}
+		if target, ok := mapping[u.GetNamespace()]; ok {
+			u.SetNamespace(target)
+		}
+		for key, value := range u.Object {
+			if key == "metadata" {
+				continue
+			}
+			u.Object[key] = mapReferencedNamespaces(value, mapping)
+		}
+		return nil
+	}
+}
+
+// mapReferencedNamespaces rewrites the namespace of all the object references nested in value.
+func mapReferencedNamespaces(value interface{}, mapping map[string]string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if namespace, ok := v["namespace"].(string); ok {
+			if _, hasName := v["name"]; hasName {
+				if target, ok := mapping[namespace]; ok {
+					v["namespace"] = target
+				}
+			}
+		}
+		for key, nested := range v {
+			v[key] = mapReferencedNamespaces(nested, mapping)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = mapReferencedNamespaces(v[i], mapping)
+		}
+		return v
+	default:
+		return value
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func TestValidateNamespaceMapping(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(ValidateNamespaceMapping(map[string]string{"ns1": "ns2", "ns3": "ns4"})).To(Succeed())
+	g.Expect(ValidateNamespaceMapping(map[string]string{"ns1": ""})).ToNot(Succeed())
+	g.Expect(ValidateNamespaceMapping(map[string]string{"Ns1": "ns2"})).ToNot(Succeed())
+	g.Expect(ValidateNamespaceMapping(map[string]string{"ns1": "ns2", "ns3": "ns2"})).ToNot(Succeed())
+}
+
+func TestNamespaceMappingMutator(t *testing.T) {
+	g := NewWithT(t)
+
+	mutator := NamespaceMappingMutator(map[string]string{"ns1": "ns2"})
+
+	cluster := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": clusterv1.GroupVersion.String(),
+		"kind":       clusterv1.ClusterKind,
+		"metadata": map[string]interface{}{
+			"name":      "foo",
+			"namespace": "ns1",
+		},
+		"spec": map[string]interface{}{
+			"infrastructureRef": map[string]interface{}{"kind": "GenericInfrastructureCluster", "name": "foo", "namespace": "ns1"},
+			"controlPlaneRef":   map[string]interface{}{"kind": "GenericControlPlane", "name": "foo", "namespace": "ns3"},
+			"topology": map[string]interface{}{
+				"workers": map[string]interface{}{
+					"machineDeployments": []interface{}{
+						map[string]interface{}{"class": "default", "name": "md1"},
+					},
+				},
+			},
+		},
+	}}
+	g.Expect(mutator(cluster)).To(Succeed())
+	g.Expect(cluster.GetNamespace()).To(Equal("ns2"))
+	g.Expect(nestedString(cluster.Object, "spec", "infrastructureRef", "namespace")).To(Equal("ns2"))
+	// Namespaces not in the mapping are not changed.
+	g.Expect(nestedString(cluster.Object, "spec", "controlPlaneRef", "namespace")).To(Equal("ns3"))
+
+	// Object references nested in lists are rewritten as well.
+	template := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": clusterv1.GroupVersion.String(),
+		"kind":       "ClusterClass",
+		"metadata": map[string]interface{}{
+			"name":      "class1",
+			"namespace": "ns1",
+		},
+		"spec": map[string]interface{}{
+			"workers": map[string]interface{}{
+				"machineDeployments": []interface{}{
+					map[string]interface{}{
+						"class": "default",
+						"template": map[string]interface{}{
+							"bootstrap": map[string]interface{}{
+								"ref": map[string]interface{}{"kind": "GenericBootstrapConfigTemplate", "name": "class1", "namespace": "ns1"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+	g.Expect(mutator(template)).To(Succeed())
+	machineDeployments, _, err := unstructured.NestedSlice(template.Object, "spec", "workers", "machineDeployments")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(nestedString(machineDeployments[0].(map[string]interface{}), "template", "bootstrap", "ref", "namespace")).To(Equal("ns2"))
+
+	// Global objects are not changed.
+	global := &unstructured.Unstructured{}
+	global.SetKind("GenericClusterInfrastructureIdentity")
+	global.SetName("foo")
+	g.Expect(mutator(global)).To(Succeed())
+	g.Expect(global.GetNamespace()).To(BeEmpty())
+}
+
+func Test_objectMover_toDirectory_withNamespaceMapping(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	graph := getObjectGraphWithObjs(test.NewFakeCluster("ns1", "foo").Objs())
+	g.Expect(graph.getDiscoveryTypes(ctx)).To(Succeed())
+	g.Expect(graph.Discovery(ctx, "")).To(Succeed())
+
+	dir := t.TempDir()
+	mover := objectMover{fromProxy: graph.proxy}
+	g.Expect(mover.toDirectory(ctx, graph, dir, BackupOptions{}, NamespaceMappingMutator(map[string]string{"ns1": "ns2"}))).To(Succeed())
+
+	objs, err := mover.filesToObjs(dir)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(objs).To(HaveLen(len(graph.getMoveNodes())))
+	for _, obj := range objs {
+		g.Expect(obj.GetNamespace()).To(Equal("ns2"), "%s %s", obj.GetKind(), obj.GetName())
+		if obj.GetKind() == clusterv1.ClusterKind {
+			g.Expect(nestedString(obj.Object, "spec", "infrastructureRef", "namespace")).To(Equal("ns2"))
+		}
+	}
+
+	// Objects are not changed in the source cluster.
+	cFrom, err := graph.proxy.NewClient(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	cluster := &clusterv1.Cluster{}
+	g.Expect(cFrom.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "foo"}, cluster)).To(Succeed())
+	g.Expect(cluster.Spec.InfrastructureRef.Namespace).To(Equal("ns1"))
+}
+
+func nestedString(obj map[string]interface{}, fields ...string) string {
+	value, _, _ := unstructured.NestedString(obj, fields...)
+	return value
+}
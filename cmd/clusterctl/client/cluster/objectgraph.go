@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -28,6 +29,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -40,6 +42,14 @@ import (
 const clusterTopologyNameKey = "cluster.spec.topology.class"
 const clusterResourceSetBindingClusterNameKey = "clusterresourcesetbinding.spec.clustername"
 
+const (
+	// discoveryConcurrency is the number of types discovered concurrently.
+	discoveryConcurrency = 5
+
+	// discoveryPageSize is the number of objects read with every list call during discovery.
+	discoveryPageSize = 500
+)
+
 type empty struct{}
 
 type ownerReferenceAttributes struct {
@@ -74,6 +84,11 @@ type node struct {
 	// When this flag is true the object should not be deleted from the source cluster.
 	isGlobalHierarchy bool
 
+	// shared gets set to true if this object is required by the Clusters being handed over or selectively moved, but it
+	// can be used by other Clusters as well, e.g. a ClusterClass.
+	// When this flag is true the object should not be updated in the target cluster nor deleted from the source cluster.
+	shared bool
+
 	// virtual records if this node was discovered indirectly, e.g. by processing an OwnerRef, but not yet observed as a concrete object.
 	virtual bool
 
@@ -418,6 +433,8 @@ func getCRDList(ctx context.Context, proxy Proxy, crdList *apiextensionsv1.Custo
 
 // Discovery reads all the Kubernetes objects existing in a namespace (or in all namespaces if empty) for the types received in input, and then adds
 // everything to the objects graph.
+// Types are discovered concurrently, and objects are listed in pages and added to the graph page by page,
+// so the memory required does not depend on the number of objects of a type.
 func (o *objectGraph) Discovery(ctx context.Context, namespace string) error {
 	log := logf.Log
 	log.Info("Discovering Cluster API objects")
@@ -427,48 +444,64 @@ func (o *objectGraph) Discovery(ctx context.Context, namespace string) error {
 		selectors = append(selectors, client.InNamespace(namespace))
 	}
 
-	discoveryBackoff := newReadBackoff()
+	// Secrets from the namespaces of the infrastructure providers should be included too.
+	var providerNamespaces []string
 	for _, discoveryType := range o.types {
-		typeMeta := discoveryType.typeMeta
-		objList := new(unstructured.UnstructuredList)
-
-		if err := retryWithExponentialBackoff(ctx, discoveryBackoff, func(ctx context.Context) error {
-			return getObjList(ctx, o.proxy, typeMeta, selectors, objList)
-		}); err != nil {
+		if discoveryType.typeMeta.GetObjectKind().GroupVersionKind().GroupKind() != corev1.SchemeGroupVersion.WithKind("Secret").GroupKind() {
+			continue
+		}
+		providers, err := o.providerInventory.List(ctx)
+		if err != nil {
 			return err
 		}
-
-		// if we are discovering Secrets, also secrets from the providers namespace should be included.
-		if discoveryType.typeMeta.GetObjectKind().GroupVersionKind().GroupKind() == corev1.SchemeGroupVersion.WithKind("Secret").GroupKind() {
-			providers, err := o.providerInventory.List(ctx)
-			if err != nil {
-				return err
-			}
-			for _, p := range providers.Items {
-				if p.Type == string(clusterctlv1.InfrastructureProviderType) {
-					providerNamespaceSelector := []client.ListOption{client.InNamespace(p.Namespace)}
-					providerNamespaceSecretList := new(unstructured.UnstructuredList)
-					if err := retryWithExponentialBackoff(ctx, discoveryBackoff, func(ctx context.Context) error {
-						return getObjList(ctx, o.proxy, typeMeta, providerNamespaceSelector, providerNamespaceSecretList)
-					}); err != nil {
-						return err
-					}
-					objList.Items = append(objList.Items, providerNamespaceSecretList.Items...)
-				}
+		for _, p := range providers.Items {
+			if p.Type == string(clusterctlv1.InfrastructureProviderType) {
+				providerNamespaces = append(providerNamespaces, p.Namespace)
 			}
 		}
+	}
 
-		if len(objList.Items) == 0 {
-			continue
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		graphLock sync.Mutex
+		errsLock  sync.Mutex
+		errs      []error
+		wg        sync.WaitGroup
+	)
+	addObj := func(obj *unstructured.Unstructured) error {
+		graphLock.Lock()
+		defer graphLock.Unlock()
+		if err := o.addObj(obj); err != nil {
+			return errors.Wrapf(err, "failed to add obj (Kind=%s, Name=%s) to graph", obj.GetKind(), obj.GetName())
 		}
-
-		log.V(5).Info(typeMeta.Kind, "Count", len(objList.Items))
-		for i := range objList.Items {
-			obj := objList.Items[i]
-			if err := o.addObj(&obj); err != nil {
-				return errors.Wrapf(err, "failed to add obj (Kind=%s, Name=%s) to graph", obj.GetKind(), obj.GetName())
+		return nil
+	}
+
+	discoveryTypes := make(chan *discoveryTypeInfo)
+	for i := 0; i < discoveryConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for discoveryType := range discoveryTypes {
+				if err := o.discoverType(ctx, discoveryType, selectors, providerNamespaces, addObj); err != nil {
+					errsLock.Lock()
+					errs = append(errs, err)
+					errsLock.Unlock()
+					cancel()
+				}
 			}
-		}
+		}()
+	}
+	for _, discoveryType := range o.types {
+		discoveryTypes <- discoveryType
+	}
+	close(discoveryTypes)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return kerrors.NewAggregate(errs)
 	}
 
 	log.V(1).Info("Total objects", "Count", len(o.uidToNode))
@@ -483,6 +516,77 @@ func (o *objectGraph) Discovery(ctx context.Context, namespace string) error {
 	return nil
 }
 
+// discoverType reads all the Kubernetes objects of a type existing in the namespace defined by selectors,
+// passing them to addObj; Secrets of the providerNamespaces are read too.
+func (o *objectGraph) discoverType(ctx context.Context, discoveryType *discoveryTypeInfo, selectors []client.ListOption, providerNamespaces []string, addObj func(obj *unstructured.Unstructured) error) error {
+	log := logf.Log
+	typeMeta := discoveryType.typeMeta
+
+	count := 0
+	addObjs := func(objList *unstructured.UnstructuredList) error {
+		for i := range objList.Items {
+			if err := addObj(&objList.Items[i]); err != nil {
+				return err
+			}
+		}
+		count += len(objList.Items)
+		return nil
+	}
+
+	if err := listObjPages(ctx, o.proxy, typeMeta, selectors, addObjs); err != nil {
+		return err
+	}
+
+	if typeMeta.GetObjectKind().GroupVersionKind().GroupKind() == corev1.SchemeGroupVersion.WithKind("Secret").GroupKind() {
+		for _, providerNamespace := range providerNamespaces {
+			if err := listObjPages(ctx, o.proxy, typeMeta, []client.ListOption{client.InNamespace(providerNamespace)}, addObjs); err != nil {
+				return err
+			}
+		}
+	}
+
+	if count > 0 {
+		log.V(5).Info(typeMeta.Kind, "Count", count)
+	}
+	return nil
+}
+
+// listObjPages lists the Kubernetes objects of a type in pages of discoveryPageSize objects, calling fn for every page.
+// Every page is read with retries; if the list expires while reading the pages, it is restarted from the first page,
+// so fn can be called more than once for the same objects.
+func listObjPages(ctx context.Context, proxy Proxy, typeMeta metav1.TypeMeta, selectors []client.ListOption, fn func(objList *unstructured.UnstructuredList) error) error {
+	discoveryBackoff := newReadBackoff()
+	continueToken := ""
+	for {
+		objList := new(unstructured.UnstructuredList)
+		pageSelectors := append([]client.ListOption{client.Limit(discoveryPageSize), client.Continue(continueToken)}, selectors...)
+		expired := false
+		if err := retryWithExponentialBackoff(ctx, discoveryBackoff, func(ctx context.Context) error {
+			err := getObjList(ctx, proxy, typeMeta, pageSelectors, objList)
+			if continueToken != "" && apierrors.IsResourceExpired(errors.Cause(err)) {
+				expired = true
+				return nil
+			}
+			return err
+		}); err != nil {
+			return err
+		}
+		if expired {
+			continueToken = ""
+			continue
+		}
+
+		if err := fn(objList); err != nil {
+			return err
+		}
+
+		continueToken = objList.GetContinue()
+		if continueToken == "" {
+			return nil
+		}
+	}
+}
+
 func getObjList(ctx context.Context, proxy Proxy, typeMeta metav1.TypeMeta, selectors []client.ListOption, objList *unstructured.UnstructuredList) error {
 	c, err := proxy.NewClient(ctx)
 	if err != nil {
@@ -665,6 +769,103 @@ func (o *objectGraph) setTenant(node, tenant *node, isGlobalHierarchy bool) {
 	}
 }
 
+// filterCluster removes from the graph all the nodes not required for handing over a single Cluster, i.e. all the nodes
+// except the ones belonging to the Cluster and the ones the Cluster depends on, e.g. its ClusterClass and the related templates.
+// Nodes the Cluster depends on, but which do not belong to the Cluster, are marked as shared.
+func (o *objectGraph) filterCluster(namespace, name string) error {
+	var cluster *node
+	for _, c := range o.getClusters() {
+		if c.identity.Namespace == namespace && c.identity.Name == name {
+			cluster = c
+			break
+		}
+	}
+	if cluster == nil {
+		return errors.Errorf("failed to find Cluster %s/%s", namespace, name)
+	}
+
+	o.filterClusters([]*node{cluster})
+	return nil
+}
+
+// filterClusters removes from the graph all the nodes not required for moving the given Clusters, i.e. all the nodes
+// except the ones belonging to the Clusters and the ones the Clusters depend on, e.g. their ClusterClasses and the related templates.
+// Nodes the Clusters depend on, but which do not belong to any of the Clusters, are marked as shared.
+func (o *objectGraph) filterClusters(clusters []*node) {
+	selected := map[*node]empty{}
+	for _, c := range clusters {
+		selected[c] = empty{}
+	}
+	belongsToSelected := func(n *node) bool {
+		for t := range n.tenant {
+			if _, ok := selected[t]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Collect the nodes belonging to the Clusters and their owners, e.g. the ClusterClass or the ClusterResourceSet
+	// soft owning a ClusterResourceSetBinding.
+	required := map[*node]empty{}
+	for _, n := range o.getMoveNodes() {
+		if belongsToSelected(n) {
+			o.addWithOwners(n, required)
+		}
+	}
+
+	// Collect the tenants of these nodes, and add all the nodes belonging only to those tenants, e.g. the templates of the ClusterClass.
+	// Nb. Nodes belonging to other Clusters are not added, because the other Clusters are not tenants of the required nodes.
+	tenants := map[*node]empty{}
+	for n := range required {
+		for t := range n.tenant {
+			tenants[t] = empty{}
+		}
+	}
+	for _, n := range o.getMoveNodes() {
+		if len(n.tenant) == 0 {
+			continue
+		}
+		belongsToTenants := true
+		for t := range n.tenant {
+			if _, ok := tenants[t]; !ok {
+				belongsToTenants = false
+				break
+			}
+		}
+		if belongsToTenants {
+			o.addWithOwners(n, required)
+		}
+	}
+
+	for uid, n := range o.uidToNode {
+		if _, ok := required[n]; !ok {
+			delete(o.uidToNode, uid)
+			continue
+		}
+		if !belongsToSelected(n) {
+			n.shared = true
+		}
+	}
+}
+
+// addWithOwners adds a node and all its owners/softOwners, recursively, to a set of nodes; only nodes to be moved are added.
+func (o *objectGraph) addWithOwners(n *node, nodes map[*node]empty) {
+	if _, ok := nodes[n]; ok {
+		return
+	}
+	if len(n.tenant) == 0 && !n.forceMove {
+		return
+	}
+	nodes[n] = empty{}
+	for owner := range n.owners {
+		o.addWithOwners(owner, nodes)
+	}
+	for owner := range n.softOwners {
+		o.addWithOwners(owner, nodes)
+	}
+}
+
 // checkVirtualNode logs if nodes are still virtual.
 func (o *objectGraph) checkVirtualNode() {
 	log := logf.Log
@@ -20,16 +20,20 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strconv"
 	"testing"
 
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
 	"sigs.k8s.io/cluster-api/internal/test/builder"
@@ -2516,6 +2520,76 @@ func Test_objectGraph_setGlobalIdentityTenants(t *testing.T) {
 	}
 }
 
+func Test_listObjPages(t *testing.T) {
+	g := NewWithT(t)
+
+	proxy := &pagingProxy{
+		FakeProxy: test.NewFakeProxy().WithObjs(
+			test.NewFakeCluster("ns1", "cluster1").Objs()...,
+		).WithObjs(
+			test.NewFakeCluster("ns1", "cluster2").Objs()...,
+		),
+		expireOnce: true,
+	}
+
+	typeMeta := metav1.TypeMeta{Kind: "Cluster", APIVersion: clusterv1.GroupVersion.String()}
+	var got []string
+	err := listObjPages(context.Background(), proxy, typeMeta, []client.ListOption{client.InNamespace("ns1")}, func(objList *unstructured.UnstructuredList) error {
+		for _, obj := range objList.Items {
+			got = append(got, obj.GetName())
+		}
+		return nil
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// The first page is read twice, because the list expired while reading the second page.
+	g.Expect(got).To(Equal([]string{"cluster1", "cluster1", "cluster2"}))
+	g.Expect(proxy.limits).To(HaveEach(int64(discoveryPageSize)))
+}
+
+// pagingProxy is a FakeProxy returning list results one object per page,
+// optionally failing the first request for the second page with a resource expired error.
+type pagingProxy struct {
+	*test.FakeProxy
+	expireOnce bool
+	limits     []int64
+}
+
+func (p *pagingProxy) NewClient(ctx context.Context) (client.Client, error) {
+	c, err := p.FakeProxy.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return interceptor.NewClient(c.(client.WithWatch), interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+			p.limits = append(p.limits, listOpts.Limit)
+
+			start := 0
+			if listOpts.Continue != "" {
+				if p.expireOnce {
+					p.expireOnce = false
+					return apierrors.NewResourceExpired("continue token expired")
+				}
+				start, _ = strconv.Atoi(listOpts.Continue)
+			}
+
+			listOpts.Limit = 0
+			listOpts.Continue = ""
+			if err := c.List(ctx, list, listOpts); err != nil {
+				return err
+			}
+			u := list.(*unstructured.UnstructuredList)
+			sort.Slice(u.Items, func(i, j int) bool { return u.Items[i].GetName() < u.Items[j].GetName() })
+			if start+1 < len(u.Items) {
+				u.SetContinue(strconv.Itoa(start + 1))
+			}
+			u.Items = u.Items[start : start+1]
+			return nil
+		},
+	}), nil
+}
+
 func deduplicateObjects(objs []client.Object) []client.Object {
 	res := []client.Object{}
 	uniqueObjectKeys := sets.Set[string]{}
@@ -0,0 +1,491 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/util"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+)
+
+// ProviderReadinessCheck defines a check performed when waiting for the providers to be ready.
+type ProviderReadinessCheck string
+
+const (
+	// CRDsEstablishedCheck checks that the CRDs of the providers are established and their names accepted.
+	CRDsEstablishedCheck ProviderReadinessCheck = "CRDsEstablished"
+
+	// DeploymentsAvailableCheck checks that the manager Deployments of the providers are available.
+	DeploymentsAvailableCheck ProviderReadinessCheck = "DeploymentsAvailable"
+
+	// WebhooksReachableCheck checks that the Services of the webhooks of the providers are backed by ready endpoints,
+	// and that the API server can reach the webhook servers, by calling them through the service proxy of the API server.
+	WebhooksReachableCheck ProviderReadinessCheck = "WebhooksReachable"
+)
+
+// providerReadinessPollInterval is the interval between two probes of the provider components.
+var providerReadinessPollInterval = 500 * time.Millisecond
+
+// webhookProbeTimeout is the timeout of a call to a webhook server through the service proxy of the API server.
+const webhookProbeTimeout = 10 * time.Second
+
+// callWebhookService calls a webhook server through the service proxy of the API server;
+// it is a variable so it can be replaced in tests, where there is no API server.
+var callWebhookService = func(ctx context.Context, proxy Proxy, service webhookService) error {
+	config, err := proxy.GetConfig()
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return errors.New("no REST configuration for the management cluster")
+	}
+	config = rest.CopyConfig(config)
+	config.Timeout = webhookProbeTimeout
+	cs, err := corev1client.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	_, err = cs.Services(service.namespace).ProxyGet("https", service.name, strconv.Itoa(int(service.port)), service.path, nil).DoRaw(ctx)
+	return err
+}
+
+// ProviderReadinessTimeouts defines the timeout of each check performed when waiting for the providers to be ready.
+// If a timeout is not set, the WaitProviderTimeout is used.
+type ProviderReadinessTimeouts struct {
+	CRDs        time.Duration
+	Deployments time.Duration
+	Webhooks    time.Duration
+}
+
+// ProviderReadinessFailure reports a provider component which did not become ready.
+type ProviderReadinessFailure struct {
+	// Provider is the manifest label of the provider, e.g. infrastructure-docker.
+	Provider string
+
+	// Check is the readiness check that failed.
+	Check ProviderReadinessCheck
+
+	// Kind, Namespace and Name identify the component which is not ready.
+	Kind      string
+	Namespace string
+	Name      string
+
+	// Timeout is the time waited for the component to become ready.
+	Timeout time.Duration
+
+	// Reason is the last observed reason why the component is not ready.
+	Reason string
+}
+
+// ProviderReadinessError reports all the provider components which did not become ready.
+type ProviderReadinessError struct {
+	Failures []ProviderReadinessFailure
+}
+
+func (e *ProviderReadinessError) Error() string {
+	var b strings.Builder
+	b.WriteString("providers are not ready:")
+	for _, f := range e.Failures {
+		name := f.Name
+		if f.Namespace != "" {
+			name = f.Namespace + "/" + f.Name
+		}
+		fmt.Fprintf(&b, "\n  * %s: %s check failed: %s %s is not ready after %s: %s", f.Provider, f.Check, f.Kind, name, f.Timeout, f.Reason)
+	}
+	return b.String()
+}
+
+// readinessProbe returns if a component is ready, or the reason why it is not ready.
+type readinessProbe func(ctx context.Context, c client.Client) (bool, string)
+
+// providerComponent is a provider component to be probed by a readiness check.
+type providerComponent struct {
+	provider  string
+	kind      string
+	namespace string
+	name      string
+	probe     readinessProbe
+}
+
+// checkProvidersReadiness waits till the CRDs of the providers are established, the manager Deployments are available and
+// the webhooks are reachable, in this order. Each check waits for all the components of all the providers with its own timeout,
+// and the checks for a provider are skipped if a previous check for the same provider failed.
+// If any component does not become ready, a ProviderReadinessError reporting all the components not ready is returned.
+func checkProvidersReadiness(ctx context.Context, opts InstallOptions, installQueue []repository.Components, proxy Proxy) error {
+	c, err := proxy.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	timeout := func(t time.Duration) time.Duration {
+		if t == 0 {
+			return opts.WaitProviderTimeout
+		}
+		return t
+	}
+	checks := []struct {
+		check      ProviderReadinessCheck
+		timeout    time.Duration
+		components func(repository.Components) []providerComponent
+	}{
+		{check: CRDsEstablishedCheck, timeout: timeout(opts.ReadinessTimeouts.CRDs), components: crdComponents},
+		{check: DeploymentsAvailableCheck, timeout: timeout(opts.ReadinessTimeouts.Deployments), components: deploymentComponents},
+		{check: WebhooksReachableCheck, timeout: timeout(opts.ReadinessTimeouts.Webhooks), components: func(components repository.Components) []providerComponent {
+			return webhookComponents(components, proxy)
+		}},
+	}
+
+	report := &ProviderReadinessError{}
+	failedProviders := sets.Set[string]{}
+	for _, check := range checks {
+		components := []providerComponent{}
+		for _, providerComponents := range installQueue {
+			if failedProviders.Has(providerComponents.ManifestLabel()) {
+				continue
+			}
+			components = append(components, check.components(providerComponents)...)
+		}
+
+		failures, err := waitProviderComponentsReady(ctx, c, check.check, check.timeout, components)
+		if err != nil {
+			return err
+		}
+		for _, f := range failures {
+			failedProviders.Insert(f.Provider)
+		}
+		report.Failures = append(report.Failures, failures...)
+	}
+
+	if len(report.Failures) > 0 {
+		return report
+	}
+	return nil
+}
+
+// waitProviderComponentsReady probes the components till all of them are ready or the timeout expires,
+// and returns a failure for each component not ready.
+func waitProviderComponentsReady(ctx context.Context, c client.Client, check ProviderReadinessCheck, timeout time.Duration, components []providerComponent) ([]ProviderReadinessFailure, error) {
+	if len(components) == 0 {
+		return nil, nil
+	}
+
+	log := logf.Log
+	log.V(1).Info("Waiting for provider components", "Check", check, "Components", len(components), "Timeout", timeout)
+
+	ready := make([]bool, len(components))
+	reasons := make([]string, len(components))
+	for i := range reasons {
+		reasons[i] = "not probed"
+	}
+	_ = wait.PollUntilContextTimeout(ctx, providerReadinessPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		allReady := true
+		for i, component := range components {
+			if ready[i] {
+				continue
+			}
+			ok, reason := component.probe(ctx, c)
+			// Keep the reasons observed before the timeout, which are more meaningful than the timeout error.
+			if ctx.Err() != nil {
+				return false, nil
+			}
+			ready[i], reasons[i] = ok, reason
+			allReady = allReady && ok
+		}
+		return allReady, nil
+	})
+
+	// If the parent context has been cancelled, the components have not been given the time to become ready.
+	if err := ctx.Err(); err != nil {
+		return nil, errors.Wrapf(err, "failed to wait for the %s check", check)
+	}
+
+	failures := []ProviderReadinessFailure{}
+	for i, component := range components {
+		if ready[i] {
+			continue
+		}
+		failures = append(failures, ProviderReadinessFailure{
+			Provider:  component.provider,
+			Check:     check,
+			Kind:      component.kind,
+			Namespace: component.namespace,
+			Name:      component.name,
+			Timeout:   timeout,
+			Reason:    reasons[i],
+		})
+	}
+	return failures, nil
+}
+
+// crdComponents returns the CRDs of a provider, probed till they are established and their names accepted.
+func crdComponents(components repository.Components) []providerComponent {
+	ret := []providerComponent{}
+	for _, obj := range components.Objs() {
+		if obj.GetKind() != customResourceDefinitionKind {
+			continue
+		}
+		name := obj.GetName()
+		ret = append(ret, providerComponent{
+			provider: components.ManifestLabel(),
+			kind:     customResourceDefinitionKind,
+			name:     name,
+			probe: func(ctx context.Context, c client.Client) (bool, string) {
+				crd := &apiextensionsv1.CustomResourceDefinition{}
+				if err := c.Get(ctx, client.ObjectKey{Name: name}, crd); err != nil {
+					return false, getErrorReason(err)
+				}
+				for _, conditionType := range []apiextensionsv1.CustomResourceDefinitionConditionType{apiextensionsv1.NamesAccepted, apiextensionsv1.Established} {
+					if reason, ok := crdConditionTrue(crd, conditionType); !ok {
+						return false, reason
+					}
+				}
+				return true, ""
+			},
+		})
+	}
+	return ret
+}
+
+func crdConditionTrue(crd *apiextensionsv1.CustomResourceDefinition, conditionType apiextensionsv1.CustomResourceDefinitionConditionType) (string, bool) {
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type != conditionType {
+			continue
+		}
+		if condition.Status == apiextensionsv1.ConditionTrue {
+			return "", true
+		}
+		return conditionReason(string(conditionType), string(condition.Status), condition.Reason, condition.Message), false
+	}
+	return fmt.Sprintf("%s condition not reported", conditionType), false
+}
+
+// deploymentComponents returns the manager Deployments of a provider, probed till they are available.
+func deploymentComponents(components repository.Components) []providerComponent {
+	ret := []providerComponent{}
+	for _, obj := range components.Objs() {
+		if !util.IsDeploymentWithManager(obj) {
+			continue
+		}
+		key := client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+		ret = append(ret, providerComponent{
+			provider:  components.ManifestLabel(),
+			kind:      obj.GetKind(),
+			namespace: key.Namespace,
+			name:      key.Name,
+			probe: func(ctx context.Context, c client.Client) (bool, string) {
+				deployment := &appsv1.Deployment{}
+				if err := c.Get(ctx, key, deployment); err != nil {
+					return false, getErrorReason(err)
+				}
+				return deploymentAvailable(ctx, c, deployment)
+			},
+		})
+	}
+	return ret
+}
+
+// deploymentAvailable returns if a Deployment is available, or the reason why it is not available, including
+// the reason why a container of its Pods is waiting, if any, e.g. ImagePullBackOff.
+func deploymentAvailable(ctx context.Context, c client.Client, deployment *appsv1.Deployment) (bool, string) {
+	reasons := []string{}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type != appsv1.DeploymentAvailable {
+			continue
+		}
+		if condition.Status == corev1.ConditionTrue {
+			return true, ""
+		}
+		reasons = append(reasons, conditionReason(string(condition.Type), string(condition.Status), condition.Reason, condition.Message))
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	reasons = append([]string{fmt.Sprintf("%d/%d replicas available", deployment.Status.AvailableReplicas, replicas)}, reasons...)
+
+	if deployment.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+		pods := &corev1.PodList{}
+		if err == nil && c.List(ctx, pods, client.InNamespace(deployment.Namespace), client.MatchingLabelsSelector{Selector: selector}) == nil {
+			for _, pod := range pods.Items {
+				for _, status := range pod.Status.ContainerStatuses {
+					if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+						reasons = append(reasons, fmt.Sprintf("container %s of Pod %s is waiting: %s", status.Name, pod.Name, status.State.Waiting.Reason))
+					}
+				}
+			}
+		}
+	}
+	return false, strings.Join(reasons, "; ")
+}
+
+// webhookService is the Service of a webhook, with the port and the path the API server calls.
+type webhookService struct {
+	namespace string
+	name      string
+	port      int32
+	path      string
+}
+
+// webhookComponents returns the Services of the webhooks and of the conversion webhooks of a provider, probed till
+// they are backed by ready endpoints, and the webhook servers can be called through the service proxy of the API server.
+func webhookComponents(components repository.Components, proxy Proxy) []providerComponent {
+	services := []webhookService{}
+	addService := func(clientConfig map[string]interface{}) {
+		service := webhookService{port: 443, path: "/"}
+		service.namespace, _, _ = unstructured.NestedString(clientConfig, "service", "namespace")
+		service.name, _, _ = unstructured.NestedString(clientConfig, "service", "name")
+		if service.name == "" {
+			return
+		}
+		if port, ok, _ := unstructured.NestedInt64(clientConfig, "service", "port"); ok {
+			service.port = int32(port)
+		}
+		if path, ok, _ := unstructured.NestedString(clientConfig, "service", "path"); ok && path != "" {
+			service.path = path
+		}
+		// Note: a Service is probed once, using the path of its first webhook.
+		for _, s := range services {
+			if s.namespace == service.namespace && s.name == service.name && s.port == service.port {
+				return
+			}
+		}
+		services = append(services, service)
+	}
+
+	for _, obj := range components.Objs() {
+		switch obj.GetKind() {
+		case "ValidatingWebhookConfiguration", "MutatingWebhookConfiguration":
+			webhooks, _, _ := unstructured.NestedSlice(obj.Object, "webhooks")
+			for _, webhook := range webhooks {
+				if webhook, ok := webhook.(map[string]interface{}); ok {
+					if clientConfig, ok, _ := unstructured.NestedMap(webhook, "clientConfig"); ok {
+						addService(clientConfig)
+					}
+				}
+			}
+		case customResourceDefinitionKind:
+			if clientConfig, ok, _ := unstructured.NestedMap(obj.Object, "spec", "conversion", "webhook", "clientConfig"); ok {
+				addService(clientConfig)
+			}
+		}
+	}
+
+	ret := make([]providerComponent, 0, len(services))
+	for _, service := range services {
+		key := client.ObjectKey{Namespace: service.namespace, Name: service.name}
+		ret = append(ret, providerComponent{
+			provider:  components.ManifestLabel(),
+			kind:      "Service",
+			namespace: key.Namespace,
+			name:      key.Name,
+			probe: func(ctx context.Context, c client.Client) (bool, string) {
+				if err := c.Get(ctx, key, &corev1.Service{}); err != nil {
+					return false, getErrorReason(err)
+				}
+				if ok, reason := endpointsReady(ctx, c, key); !ok {
+					return false, reason
+				}
+				if err := callWebhookService(ctx, proxy, service); !webhookResponded(err) {
+					return false, fmt.Sprintf("webhook server not reachable from the API server: %s", err)
+				}
+				return true, ""
+			},
+		})
+	}
+	return ret
+}
+
+// endpointsReady returns if a Service is backed by ready endpoints, or the reason why it is not.
+func endpointsReady(ctx context.Context, c client.Client, key client.ObjectKey) (bool, string) {
+	endpoints := &corev1.Endpoints{}
+	if err := c.Get(ctx, key, endpoints); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, "no endpoints"
+		}
+		return false, getErrorReason(err)
+	}
+	notReady := 0
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return true, ""
+		}
+		notReady += len(subset.NotReadyAddresses)
+	}
+	return false, fmt.Sprintf("no ready endpoints, %d not ready", notReady)
+}
+
+// webhookResponded returns if the result of a call through the service proxy of the API server comes from the
+// webhook server: as the webhook servers only accept admission and conversion reviews, any response is fine, while
+// the API server fails with ServiceUnavailable if it can't reach the webhook server.
+func webhookResponded(err error) bool {
+	if err == nil {
+		return true
+	}
+	var statusErr *apierrors.StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	switch statusErr.Status().Code {
+	case http.StatusServiceUnavailable:
+		message := statusErr.Status().Message
+		return !strings.Contains(message, "error trying to reach service") && !strings.Contains(message, "no endpoints available")
+	case http.StatusUnauthorized, http.StatusForbidden:
+		// The call has been rejected by the API server, e.g. the user is not allowed to use the service proxy.
+		return false
+	}
+	return true
+}
+
+func conditionReason(conditionType, status, reason, message string) string {
+	ret := fmt.Sprintf("%s condition is %s", conditionType, status)
+	if reason != "" {
+		ret += ", reason " + reason
+	}
+	if message != "" {
+		ret += ": " + message
+	}
+	return ret
+}
+
+func getErrorReason(err error) string {
+	if apierrors.IsNotFound(err) {
+		return "not found"
+	}
+	return err.Error()
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	yaml "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+const providerReadinessComponentsYAML = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns1
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: infraclusters.infrastructure.cluster.x-k8s.io
+spec:
+  conversion:
+    strategy: Webhook
+    webhook:
+      clientConfig:
+        service:
+          name: webhook-service
+          namespace: ns1
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: infra-controller-manager
+  namespace: ns1
+spec:
+  selector:
+    matchLabels:
+      control-plane: controller-manager
+  template:
+    metadata:
+      labels:
+        control-plane: controller-manager
+    spec:
+      containers:
+      - name: manager
+        image: registry.k8s.io/infra-controller:v1.0.0
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: validating-webhook-configuration
+webhooks:
+- name: validation.infra.cluster.x-k8s.io
+  clientConfig:
+    service:
+      name: webhook-service
+      namespace: ns1
+`
+
+func Test_checkProvidersReadiness(t *testing.T) {
+	crd := func(established apiextensionsv1.ConditionStatus) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			TypeMeta:   metav1.TypeMeta{Kind: "CustomResourceDefinition", APIVersion: apiextensionsv1.SchemeGroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: "infraclusters.infrastructure.cluster.x-k8s.io"},
+			Status: apiextensionsv1.CustomResourceDefinitionStatus{
+				Conditions: []apiextensionsv1.CustomResourceDefinitionCondition{
+					{Type: apiextensionsv1.NamesAccepted, Status: apiextensionsv1.ConditionTrue},
+					{Type: apiextensionsv1.Established, Status: established, Reason: "Installing"},
+				},
+			},
+		}
+	}
+	deployment := func(available corev1.ConditionStatus) *appsv1.Deployment {
+		availableReplicas := int32(0)
+		if available == corev1.ConditionTrue {
+			availableReplicas = 1
+		}
+		return &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: appsv1.SchemeGroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "infra-controller-manager"},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"control-plane": "controller-manager"}},
+			},
+			Status: appsv1.DeploymentStatus{
+				AvailableReplicas: availableReplicas,
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentAvailable, Status: available, Reason: "MinimumReplicasUnavailable"},
+				},
+			},
+		}
+	}
+	pullingPod := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "infra-controller-manager-abc", Labels: map[string]string{"control-plane": "controller-manager"}},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "manager", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+			},
+		},
+	}
+	service := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "webhook-service"},
+	}
+	endpoints := func(ready bool) *corev1.Endpoints {
+		subset := corev1.EndpointSubset{}
+		if ready {
+			subset.Addresses = []corev1.EndpointAddress{{IP: "10.0.0.1"}}
+		} else {
+			subset.NotReadyAddresses = []corev1.EndpointAddress{{IP: "10.0.0.1"}}
+		}
+		return &corev1.Endpoints{
+			TypeMeta:   metav1.TypeMeta{Kind: "Endpoints", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "webhook-service"},
+			Subsets:    []corev1.EndpointSubset{subset},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		objs         []client.Object
+		webhookErr   error
+		timeouts     ProviderReadinessTimeouts
+		wantFailures []ProviderReadinessFailure
+	}{
+		{
+			name: "all the components are ready",
+			objs: []client.Object{crd(apiextensionsv1.ConditionTrue), deployment(corev1.ConditionTrue), service, endpoints(true)},
+		},
+		{
+			name:     "CRD not established, the following checks are skipped",
+			objs:     []client.Object{crd(apiextensionsv1.ConditionFalse)},
+			timeouts: ProviderReadinessTimeouts{CRDs: 50 * time.Millisecond},
+			wantFailures: []ProviderReadinessFailure{
+				{
+					Provider: "infrastructure-infra",
+					Check:    CRDsEstablishedCheck,
+					Kind:     "CustomResourceDefinition",
+					Name:     "infraclusters.infrastructure.cluster.x-k8s.io",
+					Timeout:  50 * time.Millisecond,
+					Reason:   "Established condition is False, reason Installing",
+				},
+			},
+		},
+		{
+			name:     "Deployment not available",
+			objs:     []client.Object{crd(apiextensionsv1.ConditionTrue), deployment(corev1.ConditionFalse), pullingPod},
+			timeouts: ProviderReadinessTimeouts{Deployments: 50 * time.Millisecond},
+			wantFailures: []ProviderReadinessFailure{
+				{
+					Provider:  "infrastructure-infra",
+					Check:     DeploymentsAvailableCheck,
+					Kind:      "Deployment",
+					Namespace: "ns1",
+					Name:      "infra-controller-manager",
+					Timeout:   50 * time.Millisecond,
+					Reason:    "0/1 replicas available; Available condition is False, reason MinimumReplicasUnavailable; container manager of Pod infra-controller-manager-abc is waiting: ImagePullBackOff",
+				},
+			},
+		},
+		{
+			name:     "webhook without ready endpoints",
+			objs:     []client.Object{crd(apiextensionsv1.ConditionTrue), deployment(corev1.ConditionTrue), service, endpoints(false)},
+			timeouts: ProviderReadinessTimeouts{Webhooks: 50 * time.Millisecond},
+			wantFailures: []ProviderReadinessFailure{
+				{
+					Provider:  "infrastructure-infra",
+					Check:     WebhooksReachableCheck,
+					Kind:      "Service",
+					Namespace: "ns1",
+					Name:      "webhook-service",
+					Timeout:   50 * time.Millisecond,
+					Reason:    "no ready endpoints, 1 not ready",
+				},
+			},
+		},
+		{
+			name:       "webhook server answering with an error is reachable",
+			objs:       []client.Object{crd(apiextensionsv1.ConditionTrue), deployment(corev1.ConditionTrue), service, endpoints(true)},
+			webhookErr: apierrors.NewBadRequest("request body is empty"),
+		},
+		{
+			name:       "webhook server not reachable from the API server",
+			objs:       []client.Object{crd(apiextensionsv1.ConditionTrue), deployment(corev1.ConditionTrue), service, endpoints(true)},
+			webhookErr: apierrors.NewServiceUnavailable("error trying to reach service: dial tcp 10.0.0.1:9443: connect: connection refused"),
+			timeouts:   ProviderReadinessTimeouts{Webhooks: 50 * time.Millisecond},
+			wantFailures: []ProviderReadinessFailure{
+				{
+					Provider:  "infrastructure-infra",
+					Check:     WebhooksReachableCheck,
+					Kind:      "Service",
+					Namespace: "ns1",
+					Name:      "webhook-service",
+					Timeout:   50 * time.Millisecond,
+					Reason:    "webhook server not reachable from the API server: error trying to reach service: dial tcp 10.0.0.1:9443: connect: connection refused",
+				},
+			},
+		},
+		{
+			name: "webhook Service not found, WaitProviderTimeout is used",
+			objs: []client.Object{crd(apiextensionsv1.ConditionTrue), deployment(corev1.ConditionTrue)},
+			wantFailures: []ProviderReadinessFailure{
+				{
+					Provider:  "infrastructure-infra",
+					Check:     WebhooksReachableCheck,
+					Kind:      "Service",
+					Namespace: "ns1",
+					Name:      "webhook-service",
+					Timeout:   100 * time.Millisecond,
+					Reason:    "not found",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			defer func(f func(context.Context, Proxy, webhookService) error) { callWebhookService = f }(callWebhookService)
+			var calledServices []webhookService
+			callWebhookService = func(_ context.Context, _ Proxy, service webhookService) error {
+				calledServices = append(calledServices, service)
+				return tt.webhookErr
+			}
+
+			configClient, err := config.New(context.Background(), "", config.InjectReader(test.NewFakeReader()))
+			g.Expect(err).ToNot(HaveOccurred())
+			components, err := repository.NewComponents(repository.ComponentsInput{
+				Provider:     config.NewProvider("infra", "", clusterctlv1.InfrastructureProviderType),
+				ConfigClient: configClient,
+				Processor:    yaml.NewSimpleProcessor(),
+				RawYaml:      []byte(providerReadinessComponentsYAML),
+				Options:      repository.ComponentsOptions{Version: "v1.0.0"},
+			})
+			g.Expect(err).ToNot(HaveOccurred())
+
+			opts := InstallOptions{
+				WaitProviders:       true,
+				WaitProviderTimeout: 100 * time.Millisecond,
+				ReadinessTimeouts:   tt.timeouts,
+			}
+			err = checkProvidersReadiness(context.Background(), opts, []repository.Components{components}, test.NewFakeProxy().WithObjs(tt.objs...))
+			if tt.wantFailures == nil {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(calledServices).To(ConsistOf(webhookService{namespace: "ns1", name: "webhook-service", port: 443, path: "/"}))
+				return
+			}
+
+			var readinessErr *ProviderReadinessError
+			g.Expect(err).To(BeAssignableToTypeOf(readinessErr))
+			readinessErr = err.(*ProviderReadinessError)
+			g.Expect(readinessErr.Failures).To(Equal(tt.wantFailures))
+		})
+	}
+}
+
+func TestProviderReadinessError_Error(t *testing.T) {
+	g := NewWithT(t)
+
+	err := &ProviderReadinessError{
+		Failures: []ProviderReadinessFailure{
+			{Provider: "cluster-api", Check: CRDsEstablishedCheck, Kind: "CustomResourceDefinition", Name: "clusters.cluster.x-k8s.io", Timeout: time.Minute, Reason: "not found"},
+			{Provider: "infrastructure-docker", Check: DeploymentsAvailableCheck, Kind: "Deployment", Namespace: "capd-system", Name: "capd-controller-manager", Timeout: 2 * time.Minute, Reason: "0/1 replicas available"},
+		},
+	}
+	g.Expect(err.Error()).To(Equal("providers are not ready:\n" +
+		"  * cluster-api: CRDsEstablished check failed: CustomResourceDefinition clusters.cluster.x-k8s.io is not ready after 1m0s: not found\n" +
+		"  * infrastructure-docker: DeploymentsAvailable check failed: Deployment capd-system/capd-controller-manager is not ready after 2m0s: 0/1 replicas available"))
+}
+
+func Test_webhookResponded(t *testing.T) {
+	gr := schema.GroupResource{Resource: "services"}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "no error", err: nil, want: true},
+		{name: "error returned by the webhook server", err: apierrors.NewBadRequest("request body is empty"), want: true},
+		{name: "ServiceUnavailable returned by the webhook server", err: apierrors.NewServiceUnavailable("shutting down"), want: true},
+		{name: "webhook server not reachable", err: apierrors.NewServiceUnavailable("error trying to reach service: dial tcp: i/o timeout"), want: false},
+		{name: "no endpoints", err: apierrors.NewServiceUnavailable("no endpoints available for service \"webhook-service\""), want: false},
+		{name: "service proxy forbidden", err: apierrors.NewForbidden(gr, "webhook-service", errors.New("not allowed")), want: false},
+		{name: "API server not reachable", err: errors.New("connection refused"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(webhookResponded(tt.err)).To(Equal(tt.want))
+		})
+	}
+}
@@ -44,6 +44,7 @@ import (
 	"sigs.k8s.io/cluster-api/feature"
 	clusterclasscontroller "sigs.k8s.io/cluster-api/internal/controllers/clusterclass"
 	clustertopologycontroller "sigs.k8s.io/cluster-api/internal/controllers/topology/cluster"
+	runtimeclient "sigs.k8s.io/cluster-api/internal/runtime/client"
 	"sigs.k8s.io/cluster-api/internal/webhooks"
 	"sigs.k8s.io/cluster-api/util/contract"
 )
@@ -51,6 +52,9 @@ import (
 const (
 	maxClusterPerInput        = 1
 	maxClusterClassesPerInput = 1
+
+	// serverSideDryRunFieldOwner is the field manager used when applying the input objects with server-side dry-run.
+	serverSideDryRunFieldOwner = "clusterctl"
 )
 
 // TopologyClient has methods to work with ClusterClass and ManagedTopologies.
@@ -80,6 +84,11 @@ type TopologyPlanInput struct {
 	Objs              []*unstructured.Unstructured
 	TargetClusterName string
 	TargetNamespace   string
+
+	// ServerSideDryRun instructs Plan to use the management cluster for validating the changes: the input objects
+	// and the changes computed by the topology reconciler are sent to the API server as server-side dry-run requests,
+	// and the Runtime Extensions registered in the management cluster are called for computing the desired state.
+	ServerSideDryRun bool
 }
 
 // PatchSummary defined the patch observed on an object.
@@ -130,6 +139,25 @@ func (t *topologyClient) Plan(ctx context.Context, in *TopologyPlanInput) (*Topo
 		}
 	}
 
+	var runtimeClient runtimeclient.Client
+	if in.ServerSideDryRun {
+		if c == nil {
+			return nil, errors.New("server-side dry-run requires a reachable management cluster with Cluster API installed")
+		}
+		var err error
+		runtimeClient, err = newDryRunRuntimeClient(ctx, t.proxy, c)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create a Runtime SDK client for the management cluster")
+		}
+		if runtimeClient != nil {
+			// Enable the RuntimeSDK feature gate so that the reconcilers call the Runtime Extensions.
+			// Note: We don't need to disable it later because the CLI is short lived.
+			if err := feature.Gates.(featuregate.MutableFeatureGate).Set(fmt.Sprintf("%s=%v", feature.RuntimeSDK, true)); err != nil {
+				return nil, errors.Wrapf(err, "failed to enable %s feature gate", feature.RuntimeSDK)
+			}
+		}
+	}
+
 	// Prepare the inputs for dry running the reconciler. This includes steps like setting missing namespaces on objects
 	// and adjusting cluster objects to reflect updated state.
 	if err := t.prepareInput(ctx, in, c); err != nil {
@@ -140,22 +168,35 @@ func (t *topologyClient) Plan(ctx context.Context, in *TopologyPlanInput) (*Topo
 	// This mimics the defaulting and validation webhooks that will run on the objects during a real execution.
 	// Running defaulting and validation on these objects helps to improve the UX of using the plan operation.
 	// This is especially important when working with Clusters and ClusterClasses that use variable and patches.
-	if err := t.runDefaultAndValidationWebhooks(ctx, in, c); err != nil {
+	if err := t.runDefaultAndValidationWebhooks(ctx, in, c, runtimeClient); err != nil {
 		return nil, errors.Wrap(err, "failed defaulting and validation on input objects")
 	}
 
+	// Send the input objects to the API server, so they get defaulted and validated by the real webhooks,
+	// including the ones of the providers.
+	if in.ServerSideDryRun {
+		if err := t.serverSideDryRunInput(ctx, in, c, runtimeClient); err != nil {
+			return nil, errors.Wrap(err, "failed server-side dry-run of input objects")
+		}
+	}
+
 	objs := []client.Object{}
 	// Add all the objects from the input to the list used when initializing the dry run client.
 	for _, o := range in.Objs {
 		objs = append(objs, o)
 	}
-	// Add mock CRDs of all the provider objects in the input to the list used when initializing the dry run client.
-	// Adding these CRDs makes sure that UpdateReferenceAPIContract calls in the reconciler can work.
-	for _, o := range t.generateCRDs(in.Objs) {
-		objs = append(objs, o)
+	var dryRunClient *dryrun.Client
+	if in.ServerSideDryRun {
+		// The CRDs of the provider objects are read from the management cluster.
+		dryRunClient = dryrun.NewServerSideDryRunClient(c, objs)
+	} else {
+		// Add mock CRDs of all the provider objects in the input to the list used when initializing the dry run client.
+		// Adding these CRDs makes sure that UpdateReferenceAPIContract calls in the reconciler can work.
+		for _, o := range t.generateCRDs(in.Objs) {
+			objs = append(objs, o)
+		}
+		dryRunClient = dryrun.NewClient(c, objs)
 	}
-
-	dryRunClient := dryrun.NewClient(c, objs)
 	// Calculate affected ClusterClasses.
 	affectedClusterClasses, err := t.affectedClusterClasses(ctx, in, dryRunClient)
 	if err != nil {
@@ -203,6 +244,7 @@ func (t *topologyClient) Plan(ctx context.Context, in *TopologyPlanInput) (*Topo
 		Client:                    dryRunClient,
 		APIReader:                 dryRunClient,
 		UnstructuredCachingClient: dryRunClient,
+		RuntimeClient:             runtimeClient,
 	}
 	reconciler.SetupForDryRun(&noOpRecorder{})
 	request := reconcile.Request{NamespacedName: *targetCluster}
@@ -363,7 +405,7 @@ func (t *topologyClient) prepareClusters(ctx context.Context, clusters []*unstru
 // ValidateCreate is performed.
 // *Important Note*: We cannot perform defaulting and validation on provider objects as we do not have access to
 // that code.
-func (t *topologyClient) runDefaultAndValidationWebhooks(ctx context.Context, in *TopologyPlanInput, apiReader client.Reader) error {
+func (t *topologyClient) runDefaultAndValidationWebhooks(ctx context.Context, in *TopologyPlanInput, apiReader client.Reader, runtimeClient runtimeclient.Client) error {
 	// Enable the ClusterTopology feature gate so that the defaulter and validators do not complain.
 	// Note: We don't need to disable it later because the CLI is short lived.
 	if err := feature.Gates.(featuregate.MutableFeatureGate).Set(fmt.Sprintf("%s=%v", feature.ClusterTopology, true)); err != nil {
@@ -415,7 +457,7 @@ func (t *topologyClient) runDefaultAndValidationWebhooks(ctx context.Context, in
 	// This is required as validation of Cluster objects might need access to ClusterClass objects that are in the input.
 	// Cluster variable defaulting and validation relies on the ClusterClass `.status.variables` which is added
 	// during ClusterClass reconciliation.
-	reconciledClusterClasses, err := t.reconcileClusterClasses(ctx, in.Objs, apiReader)
+	reconciledClusterClasses, err := t.reconcileClusterClasses(ctx, in.Objs, apiReader, runtimeClient)
 	if err != nil {
 		return errors.Wrapf(err, "failed to reconcile ClusterClasses for defaulting and validating")
 	}
@@ -439,7 +481,7 @@ func (t *topologyClient) runDefaultAndValidationWebhooks(ctx context.Context, in
 	return nil
 }
 
-func (t *topologyClient) reconcileClusterClasses(ctx context.Context, inputObjects []*unstructured.Unstructured, apiReader client.Reader) ([]client.Object, error) {
+func (t *topologyClient) reconcileClusterClasses(ctx context.Context, inputObjects []*unstructured.Unstructured, apiReader client.Reader, runtimeClient runtimeclient.Client) ([]client.Object, error) {
 	reconciliationObjects := []client.Object{}
 	// From the inputs gather all the objects that are not ClusterClasses.
 	// These objects will be used when initializing a dryrun client to use in the reconciler.
@@ -480,7 +522,7 @@ func (t *topologyClient) reconcileClusterClasses(ctx context.Context, inputObjec
 	// This is required as Clusters are validated based of variable definitions in the ClusterClass `.status.variables`.
 	reconciledClusterClasses := []client.Object{}
 	for _, class := range allClusterClasses {
-		reconciledClusterClass, err := reconcileClusterClass(ctx, apiReader, class, reconciliationObjects)
+		reconciledClusterClass, err := reconcileClusterClass(ctx, apiReader, class, reconciliationObjects, runtimeClient)
 		if err != nil {
 			return nil, errors.Wrapf(err, "ClusterClass %s could not be reconciled for dry run", class.GetName())
 		}
@@ -506,7 +548,7 @@ func (t *topologyClient) reconcileClusterClasses(ctx context.Context, inputObjec
 	return reconciledClusterClasses, nil
 }
 
-func reconcileClusterClass(ctx context.Context, apiReader client.Reader, class client.Object, reconciliationObjects []client.Object) (*unstructured.Unstructured, error) {
+func reconcileClusterClass(ctx context.Context, apiReader client.Reader, class client.Object, reconciliationObjects []client.Object, runtimeClient runtimeclient.Client) (*unstructured.Unstructured, error) {
 	targetClusterClass := client.ObjectKey{Namespace: class.GetNamespace(), Name: class.GetName()}
 	reconciliationObjects = append(reconciliationObjects, class)
 
@@ -517,6 +559,7 @@ func reconcileClusterClass(ctx context.Context, apiReader client.Reader, class c
 	clusterClassReconciler := &clusterclasscontroller.Reconciler{
 		Client:                    reconcilerClient,
 		UnstructuredCachingClient: reconcilerClient,
+		RuntimeClient:             runtimeClient,
 	}
 
 	if _, err := clusterClassReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: targetClusterClass}); err != nil {
@@ -540,6 +583,36 @@ func reconcileClusterClass(ctx context.Context, apiReader client.Reader, class c
 	return obj, nil
 }
 
+// serverSideDryRunInput applies the input objects to the management cluster with server-side apply in dry-run mode,
+// and replaces them with the objects returned by the API server, thus getting them defaulted and validated by all
+// the webhooks, including the ones of the providers.
+// ClusterClasses are then reconciled locally, so that their status reflects the variables defined in the input.
+func (t *topologyClient) serverSideDryRunInput(ctx context.Context, in *TopologyPlanInput, c client.Client, runtimeClient runtimeclient.Client) error {
+	for _, obj := range in.Objs {
+		appliedObj := obj.DeepCopy()
+		appliedObj.SetManagedFields(nil)
+		appliedObj.SetResourceVersion("")
+		if err := c.Patch(ctx, appliedObj, client.Apply, client.DryRunAll, client.ForceOwnership, client.FieldOwner(serverSideDryRunFieldOwner)); err != nil {
+			return errors.Wrapf(err, "failed to dry-run apply %s %s/%s", obj.GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName())
+		}
+		appliedObj.SetManagedFields(nil)
+		obj.Object = appliedObj.Object
+	}
+
+	reconciliationObjects := []client.Object{}
+	for _, o := range filterObjects(in.Objs, clusterv1.GroupVersion.WithKind("ClusterClass")) {
+		reconciliationObjects = append(reconciliationObjects, o)
+	}
+	for _, class := range getClusterClasses(in.Objs) {
+		reconciledClusterClass, err := reconcileClusterClass(ctx, c, class, reconciliationObjects, runtimeClient)
+		if err != nil {
+			return errors.Wrapf(err, "ClusterClass %s could not be reconciled for dry run", class.GetName())
+		}
+		class.Object = reconciledClusterClass.Object
+	}
+	return nil
+}
+
 func (t *topologyClient) defaultAndValidateObjs(ctx context.Context, objs []*unstructured.Unstructured, o client.Object, defaulter crwebhook.CustomDefaulter, validator crwebhook.CustomValidator, apiReader client.Reader) error {
 	for _, obj := range objs {
 		// The defaulter and validator need a typed object. Convert the unstructured obj to a typed object.
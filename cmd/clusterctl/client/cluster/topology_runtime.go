@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	runtimev1 "sigs.k8s.io/cluster-api/exp/runtime/api/v1alpha1"
+	runtimecatalog "sigs.k8s.io/cluster-api/exp/runtime/catalog"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	runtimeclient "sigs.k8s.io/cluster-api/internal/runtime/client"
+	runtimeregistry "sigs.k8s.io/cluster-api/internal/runtime/registry"
+)
+
+// newDryRunRuntimeClient returns a Runtime SDK client for the Runtime Extensions registered in the management cluster.
+// The extensions are called through the API server service proxy, given that clusterctl usually runs outside the cluster.
+// If the management cluster does not serve ExtensionConfigs, nil is returned.
+func newDryRunRuntimeClient(ctx context.Context, proxy Proxy, c client.Client) (runtimeclient.Client, error) {
+	extensionConfigs := &runtimev1.ExtensionConfigList{}
+	if err := c.List(ctx, extensionConfigs); err != nil {
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to list ExtensionConfigs")
+	}
+
+	config, err := proxy.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := runtimecatalog.New()
+	if err := runtimehooksv1.AddToCatalog(catalog); err != nil {
+		return nil, errors.Wrap(err, "failed to create the Runtime SDK catalog")
+	}
+
+	runtimeClient := runtimeclient.New(runtimeclient.Options{
+		Catalog:      catalog,
+		Registry:     runtimeregistry.New(),
+		Client:       c,
+		ServiceProxy: config,
+	})
+	if err := runtimeClient.WarmUp(extensionConfigs); err != nil {
+		return nil, errors.Wrap(err, "failed to register the ExtensionConfigs")
+	}
+
+	return &dryRunRuntimeClient{Client: runtimeClient}, nil
+}
+
+// dryRunRuntimeClient is a Runtime SDK client that calls the extensions used for computing the desired state
+// of a Cluster, e.g. GeneratePatches, while skipping lifecycle hooks, which should not be triggered by a dry run.
+type dryRunRuntimeClient struct {
+	runtimeclient.Client
+}
+
+// CallAllExtensions skips calling the lifecycle hook and returns a successful, non-blocking response.
+func (c *dryRunRuntimeClient) CallAllExtensions(_ context.Context, hook runtimecatalog.Hook, forObject metav1.Object, _ runtimehooksv1.RequestObject, response runtimehooksv1.ResponseObject) error {
+	logf.Log.V(5).Info("Skipping lifecycle hook during dry run", "hook", runtimecatalog.HookName(hook), "object", client.ObjectKey{Namespace: forObject.GetNamespace(), Name: forObject.GetName()}.String())
+	response.SetStatus(runtimehooksv1.ResponseStatusSuccess)
+	return nil
+}
@@ -396,6 +396,19 @@ func Test_topologyClient_Plan(t *testing.T) {
 	}
 }
 
+func Test_topologyClient_Plan_ServerSideDryRunRequiresCluster(t *testing.T) {
+	g := NewWithT(t)
+
+	proxy := test.NewFakeProxy().WithClusterAvailable(false)
+	tc := newTopologyClient(proxy, newInventoryClient(proxy, nil))
+
+	_, err := tc.Plan(context.Background(), &TopologyPlanInput{
+		Objs:             mustToUnstructured(newClusterClassAndClusterYAML),
+		ServerSideDryRun: true,
+	})
+	g.Expect(err).To(MatchError(ContainSubstring("server-side dry-run requires a reachable management cluster")))
+}
+
 func MatchTopologyPlanOutputItem(kind, namespace, namePrefix string) types.GomegaMatcher {
 	return &topologyPlanOutputItemMatcher{kind, namespace, namePrefix}
 }
@@ -41,11 +41,21 @@ type ProviderUpgrader interface {
 	// Plan returns a set of suggested Upgrade plans for the management cluster.
 	Plan(ctx context.Context) ([]UpgradePlan, error)
 
+	// PlanWithOptions returns a set of suggested Upgrade plans for the management cluster, complying with the given
+	// constraints, e.g. allowing only patch releases, and optionally reporting the changes to the CRDs of each provider.
+	PlanWithOptions(ctx context.Context, options UpgradePlanOptions) ([]UpgradePlan, error)
+
 	// ApplyPlan executes an upgrade following an UpgradePlan generated by clusterctl.
 	ApplyPlan(ctx context.Context, opts UpgradeOptions, clusterAPIVersion string) error
 
 	// ApplyCustomPlan plan executes an upgrade using the UpgradeItems provided by the user.
 	ApplyCustomPlan(ctx context.Context, opts UpgradeOptions, providersToUpgrade ...UpgradeItem) error
+
+	// DiffPlan returns the changes that ApplyPlan applies to the management cluster, without applying them.
+	DiffPlan(ctx context.Context, clusterAPIVersion string) ([]ProviderUpgradeDiff, error)
+
+	// DiffCustomPlan returns the changes that ApplyCustomPlan applies to the management cluster, without applying them.
+	DiffCustomPlan(ctx context.Context, providersToUpgrade ...UpgradeItem) ([]ProviderUpgradeDiff, error)
 }
 
 // UpgradePlan defines a list of possible upgrade targets for a management cluster.
@@ -58,6 +68,18 @@ type UpgradePlan struct {
 type UpgradeOptions struct {
 	WaitProviders       bool
 	WaitProviderTimeout time.Duration
+
+	// ReadinessTimeouts defines the timeout of each check performed when waiting for the providers to be ready;
+	// if a timeout is not set, WaitProviderTimeout is used.
+	ReadinessTimeouts ProviderReadinessTimeouts
+
+	// MigrateStorageVersions instructs the upgrader to migrate the CRs of the upgraded providers to the
+	// storage version of their CRDs once the new providers are ready, and to drop the previous storage
+	// versions from the CRD status.
+	MigrateStorageVersions bool
+
+	// ExternalCertificates defines the webhook serving certificates to use instead of cert-manager, if enabled.
+	ExternalCertificates ExternalCertificatesOptions
 }
 
 // isPartialUpgrade returns true if at least one upgradeItem in the plan does not have a target version.
@@ -74,6 +96,10 @@ func (u *UpgradePlan) isPartialUpgrade() bool {
 type UpgradeItem struct {
 	clusterctlv1.Provider
 	NextVersion string
+
+	// CRDChanges are the changes to the provider CRDs when upgrading to NextVersion; this is set only
+	// by PlanWithOptions, when required.
+	CRDChanges []CRDChange
 }
 
 // UpgradeRef returns a string identifying the upgrade item; this string is derived by the provider.
@@ -92,6 +118,14 @@ type providerUpgrader struct {
 var _ ProviderUpgrader = &providerUpgrader{}
 
 func (u *providerUpgrader) Plan(ctx context.Context) ([]UpgradePlan, error) {
+	return u.PlanWithOptions(ctx, UpgradePlanOptions{})
+}
+
+func (u *providerUpgrader) PlanWithOptions(ctx context.Context, options UpgradePlanOptions) ([]UpgradePlan, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
 	log := logf.Log
 	log.Info("Checking new release availability...")
 
@@ -132,7 +166,12 @@ func (u *providerUpgrader) Plan(ctx context.Context) ([]UpgradePlan, error) {
 	// e.g. v1alpha4, cluster-api --> v0.5.1, kubeadm bootstrap --> v0.5.1, aws --> v0.Y.4 (not supported in current clusterctl release, but upgrade plan should report these options).
 	ret := make([]UpgradePlan, 0)
 	for _, contract := range contractsForUpgrade {
-		upgradePlan, err := u.getUpgradePlan(ctx, providerList.Items, contract)
+		// If required, drop the upgrade plans changing the contract of the management cluster.
+		if options.NoContractChanges && coreUpgradeInfo.currentContract != contract {
+			continue
+		}
+
+		upgradePlan, err := u.getUpgradePlan(ctx, providerList.Items, contract, options)
 		if err != nil {
 			return nil, err
 		}
@@ -144,6 +183,17 @@ func (u *providerUpgrader) Plan(ctx context.Context) ([]UpgradePlan, error) {
 			continue
 		}
 
+		if options.IncludeCRDChanges {
+			for i := range upgradePlan.Providers {
+				if upgradePlan.Providers[i].NextVersion == "" {
+					continue
+				}
+				if upgradePlan.Providers[i].CRDChanges, err = u.getCRDChanges(ctx, upgradePlan.Providers[i]); err != nil {
+					return nil, err
+				}
+			}
+		}
+
 		ret = append(ret, *upgradePlan)
 	}
 
@@ -164,7 +214,7 @@ func (u *providerUpgrader) ApplyPlan(ctx context.Context, opts UpgradeOptions, c
 		return err
 	}
 
-	upgradePlan, err := u.getUpgradePlan(ctx, providerList.Items, contract)
+	upgradePlan, err := u.getUpgradePlan(ctx, providerList.Items, contract, UpgradePlanOptions{})
 	if err != nil {
 		return err
 	}
@@ -190,14 +240,15 @@ func (u *providerUpgrader) ApplyCustomPlan(ctx context.Context, opts UpgradeOpti
 
 // getUpgradePlan returns the upgrade plan for a specific set of providers/contract
 // NB. this function is used both for upgrade plan and upgrade apply.
-func (u *providerUpgrader) getUpgradePlan(ctx context.Context, providers []clusterctlv1.Provider, contract string) (*UpgradePlan, error) {
+func (u *providerUpgrader) getUpgradePlan(ctx context.Context, providers []clusterctlv1.Provider, contract string, options UpgradePlanOptions) (*UpgradePlan, error) {
 	upgradeItems := []UpgradeItem{}
 	for _, provider := range providers {
-		// Gets the upgrade info for the provider.
+		// Gets the upgrade info for the provider, considering only the next versions allowed by the options.
 		providerUpgradeInfo, err := u.getUpgradeInfo(ctx, provider)
 		if err != nil {
 			return nil, err
 		}
+		providerUpgradeInfo = providerUpgradeInfo.filterNextVersions(options)
 
 		// Identifies the next available version with the target contract for the provider, if available.
 		nextVersion := providerUpgradeInfo.getLatestNextVersion(contract)
@@ -377,6 +428,11 @@ func (u *providerUpgrader) doUpgrade(ctx context.Context, upgradePlan *UpgradePl
 			return err
 		}
 
+		// Check the existing webhook serving certificates, if requested, before changing the management cluster.
+		if err := useExternalCertificates(ctx, u.proxy, components, opts.ExternalCertificates); err != nil {
+			return err
+		}
+
 		c, err := u.proxy.NewClient(ctx)
 		if err != nil {
 			return err
@@ -422,6 +478,10 @@ func (u *providerUpgrader) doUpgrade(ctx context.Context, upgradePlan *UpgradePl
 			return err
 		}
 
+		if err := useExternalCertificates(ctx, u.proxy, components, opts.ExternalCertificates); err != nil {
+			return err
+		}
+
 		installQueue = append(installQueue, components)
 
 		// Delete the provider, preserving CRD, namespace and the inventory.
@@ -447,7 +507,33 @@ func (u *providerUpgrader) doUpgrade(ctx context.Context, upgradePlan *UpgradePl
 		}
 	}
 
-	return waitForProvidersReady(ctx, InstallOptions(opts), installQueue, u.proxy)
+	installOpts := InstallOptions{
+		WaitProviders:       opts.WaitProviders,
+		WaitProviderTimeout: opts.WaitProviderTimeout,
+		ReadinessTimeouts:   opts.ReadinessTimeouts,
+	}
+	if !opts.MigrateStorageVersions {
+		return waitForProvidersReady(ctx, installOpts, installQueue, u.proxy)
+	}
+
+	// Migrate CRs to the storage version of the new CRDs.
+	// Note: We have to wait for the new providers to be ready, so conversion webhooks of the new provider
+	// versions are used during the migration.
+	installOpts.WaitProviders = true
+	if err := waitForProvidersReady(ctx, installOpts, installQueue, u.proxy); err != nil {
+		return err
+	}
+
+	c, err := u.proxy.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+	for _, components := range installQueue {
+		if err := NewCRDMigrator(c).MigrateStoredVersions(ctx, components.Objs()); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (u *providerUpgrader) scaleDownProvider(ctx context.Context, provider clusterctlv1.Provider) error {
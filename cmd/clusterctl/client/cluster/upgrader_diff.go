@@ -0,0 +1,662 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/scheme"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+)
+
+// ProviderUpgradeDiff describes the changes that upgrading a provider applies to the management cluster.
+type ProviderUpgradeDiff struct {
+	// UpgradeItem is the provider to be upgraded, including the changes to the API versions of its CRDs.
+	UpgradeItem
+
+	// ImageChanges are the changes to the images of the provider Deployments.
+	ImageChanges []ImageChange
+
+	// CRDSchemaChanges are the fields added or removed from the schemas of the API versions served both by
+	// the installed and by the new CRDs.
+	CRDSchemaChanges []CRDSchemaChange
+
+	// RBACChanges are the changes to the provider ClusterRoles, ClusterRoleBindings, Roles and RoleBindings.
+	RBACChanges []ObjectChange
+
+	// WebhookChanges are the changes to the provider ValidatingWebhookConfigurations and MutatingWebhookConfigurations.
+	WebhookChanges []ObjectChange
+}
+
+// ImageChange describes a change to the image of a container in a Deployment.
+type ImageChange struct {
+	// Deployment is the namespace/name of the Deployment.
+	Deployment string
+
+	// Container is the name of the container.
+	Container string
+
+	// CurrentImage is the image currently used by the container, empty if the container is added by the upgrade.
+	CurrentImage string
+
+	// NextImage is the image used after the upgrade, empty if the container is removed by the upgrade.
+	NextImage string
+}
+
+// CRDSchemaChange describes the fields added or removed from the schema of an API version of a CRD.
+type CRDSchemaChange struct {
+	// CRD is the name of the CRD, e.g. machines.cluster.x-k8s.io.
+	CRD string
+
+	// Version is the API version the schema belongs to.
+	Version string
+
+	// AddedFields are the paths of the fields added to the schema, e.g. spec.template.spec.foo.
+	AddedFields []string
+
+	// RemovedFields are the paths of the fields removed from the schema.
+	RemovedFields []string
+}
+
+// ObjectChangeOperation defines the operation applied to an object by an upgrade.
+type ObjectChangeOperation string
+
+const (
+	// ObjectAdded means the object is created by the upgrade.
+	ObjectAdded ObjectChangeOperation = "Added"
+
+	// ObjectRemoved means the object is deleted by the upgrade.
+	ObjectRemoved ObjectChangeOperation = "Removed"
+
+	// ObjectModified means the object is replaced by a different one by the upgrade.
+	ObjectModified ObjectChangeOperation = "Modified"
+)
+
+// ObjectChange describes the change applied to an object by an upgrade.
+type ObjectChange struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Operation ObjectChangeOperation
+
+	// Details are the entries added, prefixed by "+", removed, prefixed by "-", or changed, prefixed by "~";
+	// e.g. the rules of a ClusterRole or the webhooks of a ValidatingWebhookConfiguration.
+	Details []string
+}
+
+var (
+	upgradeDiffRBACKinds = []schema.GroupVersionKind{
+		rbacv1.SchemeGroupVersion.WithKind("ClusterRole"),
+		rbacv1.SchemeGroupVersion.WithKind("ClusterRoleBinding"),
+		rbacv1.SchemeGroupVersion.WithKind("Role"),
+		rbacv1.SchemeGroupVersion.WithKind("RoleBinding"),
+	}
+	upgradeDiffWebhookKinds = []schema.GroupVersionKind{
+		admissionregistrationv1.SchemeGroupVersion.WithKind("ValidatingWebhookConfiguration"),
+		admissionregistrationv1.SchemeGroupVersion.WithKind("MutatingWebhookConfiguration"),
+	}
+	upgradeDiffDeploymentKind = appsv1.SchemeGroupVersion.WithKind("Deployment")
+)
+
+func (u *providerUpgrader) DiffPlan(ctx context.Context, contract string) ([]ProviderUpgradeDiff, error) {
+	if contract != clusterv1.GroupVersion.Version {
+		return nil, errors.Errorf("current version of clusterctl could only upgrade to %s contract, requested %s", clusterv1.GroupVersion.Version, contract)
+	}
+
+	providerList, err := u.providerInventory.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	upgradePlan, err := u.getUpgradePlan(ctx, providerList.Items, contract, UpgradePlanOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return u.diffUpgrade(ctx, upgradePlan)
+}
+
+func (u *providerUpgrader) DiffCustomPlan(ctx context.Context, upgradeItems ...UpgradeItem) ([]ProviderUpgradeDiff, error) {
+	upgradePlan, err := u.createCustomPlan(ctx, upgradeItems)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.diffUpgrade(ctx, upgradePlan)
+}
+
+// diffUpgrade returns the changes that doUpgrade applies to the management cluster for the given upgrade plan.
+func (u *providerUpgrader) diffUpgrade(ctx context.Context, upgradePlan *UpgradePlan) ([]ProviderUpgradeDiff, error) {
+	log := logf.Log
+	log.Info("Computing the changes of the upgrade...")
+
+	c, err := u.proxy.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Report the providers in the same order used by doUpgrade.
+	providers := upgradePlan.Providers
+	sort.Slice(providers, func(a, b int) bool {
+		return providers[a].GetProviderType().Order() < providers[b].GetProviderType().Order()
+	})
+
+	diffs := []ProviderUpgradeDiff{}
+	for _, upgradeItem := range providers {
+		// If there is not a specified next version, skip it (we are already up-to-date).
+		if upgradeItem.NextVersion == "" {
+			continue
+		}
+
+		diff, err := u.diffUpgradeItem(ctx, c, upgradeItem)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compute the changes for the %s provider", upgradeItem.InstanceName())
+		}
+		diffs = append(diffs, *diff)
+	}
+	return diffs, nil
+}
+
+// diffUpgradeItem compares the objects of the provider installed in the management cluster with the provider
+// components for the target version.
+func (u *providerUpgrader) diffUpgradeItem(ctx context.Context, c client.Client, upgradeItem UpgradeItem) (*ProviderUpgradeDiff, error) {
+	components, err := u.getUpgradeComponents(ctx, upgradeItem)
+	if err != nil {
+		return nil, err
+	}
+	nextObjs := components.Objs()
+
+	diff := &ProviderUpgradeDiff{UpgradeItem: upgradeItem}
+	diff.CRDChanges, diff.CRDSchemaChanges, err = diffCRDs(ctx, c, nextObjs)
+	if err != nil {
+		return nil, err
+	}
+
+	currentObjs, err := listProviderObjects(ctx, c, upgradeItem.Provider, upgradeDiffDeploymentKind)
+	if err != nil {
+		return nil, err
+	}
+	diff.ImageChanges, err = diffImages(currentObjs, filterObjsByKind(nextObjs, upgradeDiffDeploymentKind))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, gvk := range upgradeDiffRBACKinds {
+		currentObjs, err := listProviderObjects(ctx, c, upgradeItem.Provider, gvk)
+		if err != nil {
+			return nil, err
+		}
+		changes, err := diffObjects(gvk.Kind, currentObjs, filterObjsByKind(nextObjs, gvk), rbacDetails)
+		if err != nil {
+			return nil, err
+		}
+		diff.RBACChanges = append(diff.RBACChanges, changes...)
+	}
+
+	for _, gvk := range upgradeDiffWebhookKinds {
+		currentObjs, err := listProviderObjects(ctx, c, upgradeItem.Provider, gvk)
+		if err != nil {
+			return nil, err
+		}
+		changes, err := diffObjects(gvk.Kind, currentObjs, filterObjsByKind(nextObjs, gvk), webhookDetails)
+		if err != nil {
+			return nil, err
+		}
+		diff.WebhookChanges = append(diff.WebhookChanges, changes...)
+	}
+
+	return diff, nil
+}
+
+// listProviderObjects lists the objects of the given kind belonging to a provider in the management cluster.
+func listProviderObjects(ctx context.Context, c client.Client, provider clusterctlv1.Provider, gvk schema.GroupVersionKind) ([]unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err := retryWithExponentialBackoff(ctx, newReadBackoff(), func(ctx context.Context) error {
+		return c.List(ctx, list, client.MatchingLabels{clusterv1.ProviderNameLabel: provider.ManifestLabel()})
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to list %s objects for the %s provider", gvk.Kind, provider.InstanceName())
+	}
+	return list.Items, nil
+}
+
+func filterObjsByKind(objs []unstructured.Unstructured, gvk schema.GroupVersionKind) []unstructured.Unstructured {
+	ret := []unstructured.Unstructured{}
+	for _, o := range objs {
+		if o.GroupVersionKind().GroupKind() == gvk.GroupKind() {
+			ret = append(ret, o)
+		}
+	}
+	return ret
+}
+
+// diffObjects pairs current and next objects by namespace and name and returns the changes between them;
+// objects existing in both the lists are reported only if detailsFunc returns at least one detail.
+func diffObjects(kind string, currentObjs, nextObjs []unstructured.Unstructured, detailsFunc func(current, next *unstructured.Unstructured) ([]string, error)) ([]ObjectChange, error) {
+	current := map[client.ObjectKey]*unstructured.Unstructured{}
+	next := map[client.ObjectKey]*unstructured.Unstructured{}
+	keys := []client.ObjectKey{}
+	for i := range currentObjs {
+		key := client.ObjectKeyFromObject(&currentObjs[i])
+		current[key] = &currentObjs[i]
+		keys = append(keys, key)
+	}
+	for i := range nextObjs {
+		key := client.ObjectKeyFromObject(&nextObjs[i])
+		next[key] = &nextObjs[i]
+		if _, ok := current[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	changes := []ObjectChange{}
+	for _, key := range keys {
+		details, err := detailsFunc(current[key], next[key])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compare %s %s", kind, key)
+		}
+
+		change := ObjectChange{Kind: kind, Namespace: key.Namespace, Name: key.Name, Details: details}
+		switch {
+		case current[key] == nil:
+			change.Operation = ObjectAdded
+		case next[key] == nil:
+			change.Operation = ObjectRemoved
+		default:
+			if len(details) == 0 {
+				continue
+			}
+			change.Operation = ObjectModified
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// diffImages returns the changes to the images of the containers of the current and next Deployments.
+func diffImages(currentObjs, nextObjs []unstructured.Unstructured) ([]ImageChange, error) {
+	currentImages, err := deploymentImages(currentObjs)
+	if err != nil {
+		return nil, err
+	}
+	nextImages, err := deploymentImages(nextObjs)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []containerKey{}
+	for key := range currentImages {
+		keys = append(keys, key)
+	}
+	for key := range nextImages {
+		if _, ok := currentImages[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].deployment != keys[j].deployment {
+			return keys[i].deployment < keys[j].deployment
+		}
+		return keys[i].container < keys[j].container
+	})
+
+	changes := []ImageChange{}
+	for _, key := range keys {
+		if currentImages[key] == nextImages[key] {
+			continue
+		}
+		changes = append(changes, ImageChange{
+			Deployment:   key.deployment,
+			Container:    key.container,
+			CurrentImage: currentImages[key],
+			NextImage:    nextImages[key],
+		})
+	}
+	return changes, nil
+}
+
+// containerKey identifies a container in a Deployment.
+type containerKey struct {
+	deployment string
+	container  string
+}
+
+// deploymentImages returns the images of the containers of the given Deployments.
+func deploymentImages(objs []unstructured.Unstructured) (map[containerKey]string, error) {
+	images := map[containerKey]string{}
+	for i := range objs {
+		deployment := &appsv1.Deployment{}
+		if err := scheme.Scheme.Convert(&objs[i], deployment, nil); err != nil {
+			return nil, errors.Wrapf(err, "failed to convert Deployment %s", client.ObjectKeyFromObject(&objs[i]))
+		}
+		containers := append([]corev1.Container{}, deployment.Spec.Template.Spec.InitContainers...)
+		containers = append(containers, deployment.Spec.Template.Spec.Containers...)
+		for _, container := range containers {
+			images[containerKey{deployment: client.ObjectKeyFromObject(&objs[i]).String(), container: container.Name}] = container.Image
+		}
+	}
+	return images, nil
+}
+
+// diffCRDs returns the changes to the API versions and to the schemas of the CRDs in the given provider components.
+func diffCRDs(ctx context.Context, c client.Client, objs []unstructured.Unstructured) ([]CRDChange, []CRDSchemaChange, error) {
+	changes := []CRDChange{}
+	schemaChanges := []CRDSchemaChange{}
+	for _, obj := range objs {
+		if obj.GetKind() != "CustomResourceDefinition" {
+			continue
+		}
+
+		nextCRD := &apiextensionsv1.CustomResourceDefinition{}
+		if err := scheme.Scheme.Convert(&obj, nextCRD, nil); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to convert CRD %q", obj.GetName())
+		}
+
+		currentCRD := &apiextensionsv1.CustomResourceDefinition{}
+		if err := retryWithExponentialBackoff(ctx, newReadBackoff(), func(ctx context.Context) error {
+			return c.Get(ctx, client.ObjectKeyFromObject(nextCRD), currentCRD)
+		}); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, nil, err
+			}
+			currentCRD = nil
+		}
+
+		if change := compareCRDs(currentCRD, nextCRD); change != nil {
+			changes = append(changes, *change)
+		}
+		schemaChanges = append(schemaChanges, compareCRDSchemas(currentCRD, nextCRD)...)
+	}
+	return changes, schemaChanges, nil
+}
+
+// compareCRDSchemas returns the fields added or removed from the schemas of the API versions served by both CRDs.
+func compareCRDSchemas(currentCRD, nextCRD *apiextensionsv1.CustomResourceDefinition) []CRDSchemaChange {
+	if currentCRD == nil {
+		return nil
+	}
+
+	currentFields := map[string]sets.Set[string]{}
+	for _, v := range currentCRD.Spec.Versions {
+		if v.Served && v.Schema != nil {
+			currentFields[v.Name] = schemaFields(v.Schema.OpenAPIV3Schema)
+		}
+	}
+
+	changes := []CRDSchemaChange{}
+	for _, v := range nextCRD.Spec.Versions {
+		current, ok := currentFields[v.Name]
+		if !v.Served || v.Schema == nil || !ok {
+			continue
+		}
+		next := schemaFields(v.Schema.OpenAPIV3Schema)
+		added, removed := next.Difference(current), current.Difference(next)
+		if added.Len() == 0 && removed.Len() == 0 {
+			continue
+		}
+		changes = append(changes, CRDSchemaChange{
+			CRD:           nextCRD.Name,
+			Version:       v.Name,
+			AddedFields:   sets.List(added),
+			RemovedFields: sets.List(removed),
+		})
+	}
+	return changes
+}
+
+// schemaFields returns the paths of all the fields in a schema, e.g. spec.template.spec.foo; items of arrays
+// are identified by "[]" and additional properties of maps by "*".
+func schemaFields(props *apiextensionsv1.JSONSchemaProps) sets.Set[string] {
+	fields := sets.Set[string]{}
+	var walk func(props *apiextensionsv1.JSONSchemaProps, path string)
+	walk = func(props *apiextensionsv1.JSONSchemaProps, path string) {
+		if props == nil {
+			return
+		}
+		for name := range props.Properties {
+			p := props.Properties[name]
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			fields.Insert(fieldPath)
+			walk(&p, fieldPath)
+		}
+		if props.Items != nil && props.Items.Schema != nil {
+			walk(props.Items.Schema, path+"[]")
+		}
+		if props.AdditionalProperties != nil && props.AdditionalProperties.Schema != nil {
+			walk(props.AdditionalProperties.Schema, path+".*")
+		}
+	}
+	walk(props, "")
+	return fields
+}
+
+// rbacDetails returns the rules added or removed from a ClusterRole or Role, or the role reference
+// and subjects added or removed from a ClusterRoleBinding or RoleBinding.
+func rbacDetails(current, next *unstructured.Unstructured) ([]string, error) {
+	currentEntries, err := rbacEntries(current)
+	if err != nil {
+		return nil, err
+	}
+	nextEntries, err := rbacEntries(next)
+	if err != nil {
+		return nil, err
+	}
+	return setDetails(currentEntries, nextEntries), nil
+}
+
+func rbacEntries(obj *unstructured.Unstructured) (sets.Set[string], error) {
+	entries := sets.Set[string]{}
+	if obj == nil {
+		return entries, nil
+	}
+
+	switch obj.GetKind() {
+	case "ClusterRole":
+		role := &rbacv1.ClusterRole{}
+		if err := scheme.Scheme.Convert(obj, role, nil); err != nil {
+			return nil, err
+		}
+		// The rules of aggregated ClusterRoles are computed by the API server.
+		if role.AggregationRule != nil {
+			for _, selector := range role.AggregationRule.ClusterRoleSelectors {
+				entries.Insert(fmt.Sprintf("aggregate %s", metav1.FormatLabelSelector(&selector)))
+			}
+			return entries, nil
+		}
+		for _, rule := range role.Rules {
+			entries.Insert(policyRuleString(rule))
+		}
+	case "Role":
+		role := &rbacv1.Role{}
+		if err := scheme.Scheme.Convert(obj, role, nil); err != nil {
+			return nil, err
+		}
+		for _, rule := range role.Rules {
+			entries.Insert(policyRuleString(rule))
+		}
+	case "ClusterRoleBinding":
+		binding := &rbacv1.ClusterRoleBinding{}
+		if err := scheme.Scheme.Convert(obj, binding, nil); err != nil {
+			return nil, err
+		}
+		entries.Insert(bindingEntries(binding.RoleRef, binding.Subjects)...)
+	case "RoleBinding":
+		binding := &rbacv1.RoleBinding{}
+		if err := scheme.Scheme.Convert(obj, binding, nil); err != nil {
+			return nil, err
+		}
+		entries.Insert(bindingEntries(binding.RoleRef, binding.Subjects)...)
+	}
+	return entries, nil
+}
+
+func policyRuleString(rule rbacv1.PolicyRule) string {
+	parts := []string{}
+	for _, f := range []struct {
+		name   string
+		values []string
+	}{
+		{"apiGroups", rule.APIGroups},
+		{"resources", rule.Resources},
+		{"resourceNames", rule.ResourceNames},
+		{"nonResourceURLs", rule.NonResourceURLs},
+		{"verbs", rule.Verbs},
+	} {
+		if len(f.values) == 0 {
+			continue
+		}
+		values := make([]string, 0, len(f.values))
+		for _, v := range f.values {
+			values = append(values, fmt.Sprintf("%q", v))
+		}
+		sort.Strings(values)
+		parts = append(parts, fmt.Sprintf("%s=[%s]", f.name, strings.Join(values, ",")))
+	}
+	return strings.Join(parts, " ")
+}
+
+func bindingEntries(roleRef rbacv1.RoleRef, subjects []rbacv1.Subject) []string {
+	entries := []string{fmt.Sprintf("roleRef %s/%s", roleRef.Kind, roleRef.Name)}
+	for _, s := range subjects {
+		name := s.Name
+		if s.Namespace != "" {
+			name = s.Namespace + "/" + s.Name
+		}
+		entries = append(entries, fmt.Sprintf("subject %s %s", s.Kind, name))
+	}
+	return entries
+}
+
+// webhookDetails returns the webhooks added, removed or changed in a ValidatingWebhookConfiguration or MutatingWebhookConfiguration.
+// NOTE: Only the fields set in the new webhooks are compared, given that the webhooks in the management cluster
+// have the default values set by the API server and the CA bundle injected.
+func webhookDetails(current, next *unstructured.Unstructured) ([]string, error) {
+	currentWebhooks, err := webhooksByName(current)
+	if err != nil {
+		return nil, err
+	}
+	nextWebhooks, err := webhooksByName(next)
+	if err != nil {
+		return nil, err
+	}
+
+	details := []string{}
+	for _, name := range sets.List(sets.KeySet(currentWebhooks).Union(sets.KeySet(nextWebhooks))) {
+		currentWebhook, inCurrent := currentWebhooks[name]
+		nextWebhook, inNext := nextWebhooks[name]
+		switch {
+		case !inCurrent:
+			details = append(details, fmt.Sprintf("+ webhook %s", name))
+		case !inNext:
+			details = append(details, fmt.Sprintf("- webhook %s", name))
+		default:
+			if paths := changedFields(currentWebhook, nextWebhook, ""); len(paths) > 0 {
+				details = append(details, fmt.Sprintf("~ webhook %s: %s", name, strings.Join(paths, ", ")))
+			}
+		}
+	}
+	return details, nil
+}
+
+// webhooksByName returns the webhooks of a webhook configuration by name, without the CA bundle.
+func webhooksByName(obj *unstructured.Unstructured) (map[string]map[string]interface{}, error) {
+	ret := map[string]map[string]interface{}{}
+	if obj == nil {
+		return ret, nil
+	}
+
+	webhookObjs, _, err := unstructured.NestedSlice(obj.Object, "webhooks")
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range webhookObjs {
+		webhook, ok := w.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("invalid webhook in %s %s", obj.GetKind(), obj.GetName())
+		}
+		webhook = runtime.DeepCopyJSON(webhook)
+		unstructured.RemoveNestedField(webhook, "clientConfig", "caBundle")
+		name, _, _ := unstructured.NestedString(webhook, "name")
+		ret[name] = webhook
+	}
+	return ret, nil
+}
+
+// changedFields returns the paths of the fields set in next with a value different from current.
+func changedFields(current, next map[string]interface{}, path string) []string {
+	paths := []string{}
+	for key, nextValue := range next {
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+		currentValue, ok := current[key]
+		if !ok {
+			paths = append(paths, fieldPath)
+			continue
+		}
+		currentMap, currentIsMap := currentValue.(map[string]interface{})
+		nextMap, nextIsMap := nextValue.(map[string]interface{})
+		if currentIsMap && nextIsMap {
+			paths = append(paths, changedFields(currentMap, nextMap, fieldPath)...)
+			continue
+		}
+		if !reflect.DeepEqual(currentValue, nextValue) {
+			paths = append(paths, fieldPath)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// setDetails returns the entries added to next, prefixed by "+", followed by the entries removed from current, prefixed by "-".
+func setDetails(current, next sets.Set[string]) []string {
+	details := []string{}
+	for _, e := range sets.List(next.Difference(current)) {
+		details = append(details, "+ "+e)
+	}
+	for _, e := range sets.List(current.Difference(next)) {
+		details = append(details, "- "+e)
+	}
+	return details
+}
@@ -0,0 +1,297 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+var upgradeDiffComponentsYAML = []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: capi-controller-manager
+  namespace: capi-system
+spec:
+  template:
+    spec:
+      containers:
+      - name: manager
+        image: registry.k8s.io/cluster-api/cluster-api-controller:v1.1.0
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: capi-manager-role
+rules:
+- apiGroups: ["cluster.x-k8s.io"]
+  resources: ["clusters"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: ["cluster.x-k8s.io"]
+  resources: ["machines"]
+  verbs: ["get", "list", "watch"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: capi-manager-rolebinding
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: capi-manager-role
+subjects:
+- kind: ServiceAccount
+  name: capi-manager
+  namespace: capi-system
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: capi-validating-webhook-configuration
+webhooks:
+- name: validation.cluster.cluster.x-k8s.io
+  admissionReviewVersions: ["v1"]
+  sideEffects: None
+  failurePolicy: Fail
+  clientConfig:
+    service:
+      name: capi-webhook-service
+      namespace: capi-system
+      path: /validate-cluster-x-k8s-io-v1beta1-cluster
+- name: validation.machine.cluster.x-k8s.io
+  admissionReviewVersions: ["v1"]
+  sideEffects: None
+  clientConfig:
+    service:
+      name: capi-webhook-service
+      namespace: capi-system
+      path: /validate-cluster-x-k8s-io-v1beta1-machine
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: clusters.cluster.x-k8s.io
+spec:
+  group: cluster.x-k8s.io
+  names:
+    kind: Cluster
+    plural: clusters
+  scope: Namespaced
+  versions:
+  - name: v1beta1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              paused:
+                type: boolean
+              availabilityGates:
+                type: array
+                items:
+                  type: object
+                  properties:
+                    conditionType:
+                      type: string
+`)
+
+func Test_providerUpgrader_DiffCustomPlan(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx := context.Background()
+
+	providerLabels := map[string]string{clusterv1.ProviderNameLabel: "cluster-api"}
+	currentObjs := []client.Object{
+		&appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: "capi-controller-manager", Namespace: "capi-system", Labels: providerLabels},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "manager", Image: "registry.k8s.io/cluster-api/cluster-api-controller:v1.0.0"}},
+					},
+				},
+			},
+		},
+		&rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: "capi-manager-role", Labels: providerLabels},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{"cluster.x-k8s.io"}, Resources: []string{"clusters"}, Verbs: []string{"get", "list", "watch"}},
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"*"}},
+			},
+		},
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: "capi-manager-rolebinding", Labels: providerLabels},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "capi-manager-role"},
+			Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "capi-manager", Namespace: "capi-system"}},
+		},
+		&rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+			ObjectMeta: metav1.ObjectMeta{Name: "capi-leader-election-role", Namespace: "capi-system", Labels: providerLabels},
+		},
+		&admissionregistrationv1.ValidatingWebhookConfiguration{
+			TypeMeta:   metav1.TypeMeta{APIVersion: admissionregistrationv1.SchemeGroupVersion.String(), Kind: "ValidatingWebhookConfiguration"},
+			ObjectMeta: metav1.ObjectMeta{Name: "capi-validating-webhook-configuration", Labels: providerLabels},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{
+				{
+					// Defaulted by the API server and with the CA bundle injected.
+					Name:                    "validation.cluster.cluster.x-k8s.io",
+					AdmissionReviewVersions: []string{"v1"},
+					SideEffects:             ptr.To(admissionregistrationv1.SideEffectClassNone),
+					FailurePolicy:           ptr.To(admissionregistrationv1.Ignore),
+					TimeoutSeconds:          ptr.To[int32](10),
+					ClientConfig: admissionregistrationv1.WebhookClientConfig{
+						CABundle: []byte("ca"),
+						Service: &admissionregistrationv1.ServiceReference{
+							Name:      "capi-webhook-service",
+							Namespace: "capi-system",
+							Path:      ptr.To("/validate-cluster-x-k8s-io-v1beta1-cluster"),
+							Port:      ptr.To[int32](443),
+						},
+					},
+				},
+			},
+		},
+		&apiextensionsv1.CustomResourceDefinition{
+			TypeMeta:   metav1.TypeMeta{APIVersion: apiextensionsv1.SchemeGroupVersion.String(), Kind: "CustomResourceDefinition"},
+			ObjectMeta: metav1.ObjectMeta{Name: "clusters.cluster.x-k8s.io", Labels: providerLabels},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Group: "cluster.x-k8s.io",
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+					Name:    "v1beta1",
+					Served:  true,
+					Storage: true,
+					Schema: &apiextensionsv1.CustomResourceValidation{
+						OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+							Type: "object",
+							Properties: map[string]apiextensionsv1.JSONSchemaProps{
+								"spec": {
+									Type: "object",
+									Properties: map[string]apiextensionsv1.JSONSchemaProps{
+										"paused":         {Type: "boolean"},
+										"legacyTopology": {Type: "string"},
+									},
+								},
+							},
+						},
+					},
+				}},
+			},
+			Status: apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: []string{"v1beta1"}},
+		},
+	}
+
+	reader := test.NewFakeReader().
+		WithProvider("cluster-api", clusterctlv1.CoreProviderType, "https://somewhere.com")
+	repositories := map[string]repository.Repository{
+		"cluster-api": repository.NewMemoryRepository().
+			WithPaths("root", "components.yaml").
+			WithVersions("v1.0.0", "v1.1.0").
+			WithMetadata("v1.1.0", &clusterctlv1.Metadata{
+				ReleaseSeries: []clusterctlv1.ReleaseSeries{
+					{Major: 1, Minor: 0, Contract: test.CurrentCAPIContract},
+					{Major: 1, Minor: 1, Contract: test.CurrentCAPIContract},
+				},
+			}).
+			WithFile("v1.1.0", "components.yaml", upgradeDiffComponentsYAML),
+	}
+	proxy := test.NewFakeProxy().
+		WithProviderInventory("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "capi-system").
+		WithObjs(currentObjs...)
+
+	configClient, _ := config.New(ctx, "", config.InjectReader(reader))
+	u := &providerUpgrader{
+		configClient: configClient,
+		proxy:        proxy,
+		repositoryClientFactory: func(ctx context.Context, provider config.Provider, configClient config.Client, _ ...repository.Option) (repository.Client, error) {
+			return repository.New(ctx, provider, configClient, repository.InjectRepository(repositories[provider.ManifestLabel()]))
+		},
+		providerInventory: newInventoryClient(proxy, nil),
+	}
+
+	diffs, err := u.DiffCustomPlan(ctx, UpgradeItem{
+		Provider:    fakeProvider("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "capi-system"),
+		NextVersion: "v1.1.0",
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(diffs).To(HaveLen(1))
+	diff := diffs[0]
+
+	g.Expect(diff.NextVersion).To(Equal("v1.1.0"))
+	g.Expect(diff.ImageChanges).To(Equal([]ImageChange{{
+		Deployment:   "capi-system/capi-controller-manager",
+		Container:    "manager",
+		CurrentImage: "registry.k8s.io/cluster-api/cluster-api-controller:v1.0.0",
+		NextImage:    "registry.k8s.io/cluster-api/cluster-api-controller:v1.1.0",
+	}}))
+	g.Expect(diff.CRDChanges).To(BeEmpty())
+	g.Expect(diff.CRDSchemaChanges).To(Equal([]CRDSchemaChange{{
+		CRD:           "clusters.cluster.x-k8s.io",
+		Version:       "v1beta1",
+		AddedFields:   []string{"spec.availabilityGates", "spec.availabilityGates[].conditionType"},
+		RemovedFields: []string{"spec.legacyTopology"},
+	}}))
+	g.Expect(diff.RBACChanges).To(Equal([]ObjectChange{
+		{
+			Kind:      "ClusterRole",
+			Name:      "capi-manager-role",
+			Operation: ObjectModified,
+			Details: []string{
+				`+ apiGroups=["cluster.x-k8s.io"] resources=["machines"] verbs=["get","list","watch"]`,
+				`- apiGroups=[""] resources=["secrets"] verbs=["*"]`,
+			},
+		},
+		{
+			Kind:      "Role",
+			Namespace: "capi-system",
+			Name:      "capi-leader-election-role",
+			Operation: ObjectRemoved,
+			Details:   []string{},
+		},
+	}))
+	g.Expect(diff.WebhookChanges).To(Equal([]ObjectChange{{
+		Kind:      "ValidatingWebhookConfiguration",
+		Name:      "capi-validating-webhook-configuration",
+		Operation: ObjectModified,
+		Details: []string{
+			"~ webhook validation.cluster.cluster.x-k8s.io: failurePolicy",
+			"+ webhook validation.machine.cluster.x-k8s.io",
+		},
+	}}))
+}
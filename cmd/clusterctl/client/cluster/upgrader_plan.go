@@ -0,0 +1,194 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+)
+
+// UpgradeVersionChange defines the maximum change allowed between the current and the next version of a provider.
+type UpgradeVersionChange string
+
+const (
+	// UpgradeVersionChangeAny allows upgrading to any version.
+	UpgradeVersionChangeAny UpgradeVersionChange = ""
+
+	// UpgradeVersionChangeMinor allows upgrading only to versions with the same major version, e.g. from v1.5.2 to v1.6.0.
+	UpgradeVersionChangeMinor UpgradeVersionChange = "minor"
+
+	// UpgradeVersionChangePatch allows upgrading only to versions with the same major and minor version, e.g. from v1.5.2 to v1.5.3.
+	UpgradeVersionChangePatch UpgradeVersionChange = "patch"
+)
+
+// UpgradePlanOptions defines the options used to compute upgrade plans.
+type UpgradePlanOptions struct {
+	// NoContractChanges drops the upgrade plans changing the API Version of Cluster API (contract) of the management cluster.
+	NoContractChanges bool
+
+	// MaxVersionChange limits the next versions of the providers to the versions within the given change from the current version;
+	// if providers have no versions allowed by the policy, their upgrade items have no next version.
+	MaxVersionChange UpgradeVersionChange
+
+	// IncludeCRDChanges instructs the upgrader to compute, for each provider with a next version, how the upgrade changes
+	// its CRDs, e.g. API versions added or removed, or storage versions changing.
+	// Note: This requires reading the provider components for the next versions.
+	IncludeCRDChanges bool
+}
+
+// Validate validates the UpgradePlanOptions.
+func (o UpgradePlanOptions) Validate() error {
+	switch o.MaxVersionChange {
+	case UpgradeVersionChangeAny, UpgradeVersionChangeMinor, UpgradeVersionChangePatch:
+		return nil
+	default:
+		return errors.Errorf("invalid max version change %q, must be one of %q or %q", o.MaxVersionChange, UpgradeVersionChangeMinor, UpgradeVersionChangePatch)
+	}
+}
+
+// allows returns true if the options allow upgrading from the current to the next version.
+func (o UpgradePlanOptions) allows(currentVersion, nextVersion *version.Version) bool {
+	switch o.MaxVersionChange {
+	case UpgradeVersionChangeMinor:
+		return currentVersion.Major() == nextVersion.Major()
+	case UpgradeVersionChangePatch:
+		return currentVersion.Major() == nextVersion.Major() && currentVersion.Minor() == nextVersion.Minor()
+	default:
+		return true
+	}
+}
+
+// CRDChange describes how the upgrade of a provider changes one of its CRDs.
+type CRDChange struct {
+	// Name of the CRD, e.g. machines.cluster.x-k8s.io.
+	Name string `json:"name"`
+
+	// Added is true if the CRD is not installed in the management cluster yet.
+	Added bool `json:"added,omitempty"`
+
+	// CurrentStorageVersion is the storage version of the installed CRD, if any.
+	CurrentStorageVersion string `json:"currentStorageVersion,omitempty"`
+
+	// NextStorageVersion is the storage version of the new CRD.
+	NextStorageVersion string `json:"nextStorageVersion,omitempty"`
+
+	// AddedVersions are the API versions served by the new CRD but not by the installed CRD.
+	AddedVersions []string `json:"addedVersions,omitempty"`
+
+	// RemovedVersions are the API versions served by the installed CRD but not by the new CRD.
+	RemovedVersions []string `json:"removedVersions,omitempty"`
+
+	// MigrationRequired is true if the new CRD does not serve a version used as storage version by the objects in
+	// the management cluster, and thus the objects are migrated to the current storage version during the upgrade.
+	MigrationRequired bool `json:"migrationRequired,omitempty"`
+}
+
+// filterNextVersions returns the upgradeInfo for a provider with only the next versions allowed by the options.
+func (i *upgradeInfo) filterNextVersions(options UpgradePlanOptions) *upgradeInfo {
+	if options.MaxVersionChange == UpgradeVersionChangeAny {
+		return i
+	}
+
+	nextVersions := []version.Version{}
+	for j := range i.nextVersions {
+		if options.allows(i.currentVersion, &i.nextVersions[j]) {
+			nextVersions = append(nextVersions, i.nextVersions[j])
+		}
+	}
+
+	filtered := *i
+	filtered.nextVersions = nextVersions
+	return &filtered
+}
+
+// getCRDChanges returns the changes to the CRDs of a provider when upgrading to the next version.
+func (u *providerUpgrader) getCRDChanges(ctx context.Context, upgradeItem UpgradeItem) ([]CRDChange, error) {
+	configRepository, err := u.configClient.Providers().Get(upgradeItem.ProviderName, upgradeItem.GetProviderType())
+	if err != nil {
+		return nil, err
+	}
+
+	providerRepository, err := u.repositoryClientFactory(ctx, configRepository, u.configClient)
+	if err != nil {
+		return nil, err
+	}
+
+	// Variables are not required for reading the CRDs, so the template processing is skipped.
+	components, err := providerRepository.Components().Get(ctx, repository.ComponentsOptions{
+		Version:             upgradeItem.NextVersion,
+		TargetNamespace:     upgradeItem.Namespace,
+		SkipTemplateProcess: true,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get components for the %s provider version %s", upgradeItem.InstanceName(), upgradeItem.NextVersion)
+	}
+
+	c, err := u.proxy.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, _, err := diffCRDs(ctx, c, components.Objs())
+	return changes, err
+}
+
+// compareCRDs returns the changes between the installed CRD, nil if not installed, and the new CRD;
+// it returns nil if there are no changes to the API versions.
+func compareCRDs(currentCRD, nextCRD *apiextensionsv1.CustomResourceDefinition) *CRDChange {
+	change := &CRDChange{Name: nextCRD.Name}
+	change.NextStorageVersion, _ = storageVersionForCRD(nextCRD)
+
+	nextServed := servedVersionsForCRD(nextCRD)
+	if currentCRD == nil {
+		change.Added = true
+		change.AddedVersions = sets.List(nextServed)
+		return change
+	}
+
+	change.CurrentStorageVersion, _ = storageVersionForCRD(currentCRD)
+	currentServed := servedVersionsForCRD(currentCRD)
+	if added := nextServed.Difference(currentServed); added.Len() > 0 {
+		change.AddedVersions = sets.List(added)
+	}
+	if removed := currentServed.Difference(nextServed); removed.Len() > 0 {
+		change.RemovedVersions = sets.List(removed)
+	}
+
+	// Same check of crdMigrator.run: objects have to be migrated if a stored version is not served anymore.
+	change.MigrationRequired = !nextServed.HasAll(currentCRD.Status.StoredVersions...)
+
+	if change.CurrentStorageVersion == change.NextStorageVersion && len(change.AddedVersions) == 0 && len(change.RemovedVersions) == 0 && !change.MigrationRequired {
+		return nil
+	}
+	return change
+}
+
+func servedVersionsForCRD(crd *apiextensionsv1.CustomResourceDefinition) sets.Set[string] {
+	served := sets.Set[string]{}
+	for _, v := range crd.Spec.Versions {
+		if v.Served {
+			served.Insert(v.Name)
+		}
+	}
+	return served
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_providerUpgrader_PlanWithOptions(t *testing.T) {
+	// core v1.0.0, with v1.0.1 and v1.1.0 for the current contract, and v2.0.0 for the next contract;
+	// infra v2.0.0, with v2.0.1 and v2.1.0 for the current contract, and v3.0.0 for the next contract.
+	reader := test.NewFakeReader().
+		WithProvider("cluster-api", clusterctlv1.CoreProviderType, "https://somewhere.com").
+		WithProvider("infra", clusterctlv1.InfrastructureProviderType, "https://somewhere.com")
+	repositories := map[string]repository.Repository{
+		"cluster-api": repository.NewMemoryRepository().
+			WithVersions("v1.0.0", "v1.0.1", "v1.1.0", "v2.0.0").
+			WithMetadata("v2.0.0", &clusterctlv1.Metadata{
+				ReleaseSeries: []clusterctlv1.ReleaseSeries{
+					{Major: 1, Minor: 0, Contract: test.CurrentCAPIContract},
+					{Major: 1, Minor: 1, Contract: test.CurrentCAPIContract},
+					{Major: 2, Minor: 0, Contract: test.NextCAPIContractNotSupported},
+				},
+			}),
+		"infrastructure-infra": repository.NewMemoryRepository().
+			WithVersions("v2.0.0", "v2.0.1", "v2.1.0", "v3.0.0").
+			WithMetadata("v3.0.0", &clusterctlv1.Metadata{
+				ReleaseSeries: []clusterctlv1.ReleaseSeries{
+					{Major: 2, Minor: 0, Contract: test.CurrentCAPIContract},
+					{Major: 2, Minor: 1, Contract: test.CurrentCAPIContract},
+					{Major: 3, Minor: 0, Contract: test.NextCAPIContractNotSupported},
+				},
+			}),
+	}
+	proxy := test.NewFakeProxy().
+		WithProviderInventory("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "cluster-api-system").
+		WithProviderInventory("infra", clusterctlv1.InfrastructureProviderType, "v2.0.0", "infra-system")
+
+	currentContractPlan := func(coreVersion, infraVersion string) UpgradePlan {
+		return UpgradePlan{
+			Contract: test.CurrentCAPIContract,
+			Providers: []UpgradeItem{
+				{
+					Provider:    fakeProvider("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "cluster-api-system"),
+					NextVersion: coreVersion,
+				},
+				{
+					Provider:    fakeProvider("infra", clusterctlv1.InfrastructureProviderType, "v2.0.0", "infra-system"),
+					NextVersion: infraVersion,
+				},
+			},
+		}
+	}
+	nextContractPlan := UpgradePlan{
+		Contract: test.NextCAPIContractNotSupported,
+		Providers: []UpgradeItem{
+			{
+				Provider:    fakeProvider("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "cluster-api-system"),
+				NextVersion: "v2.0.0",
+			},
+			{
+				Provider:    fakeProvider("infra", clusterctlv1.InfrastructureProviderType, "v2.0.0", "infra-system"),
+				NextVersion: "v3.0.0",
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		options UpgradePlanOptions
+		want    []UpgradePlan
+		wantErr bool
+	}{
+		{
+			name:    "No constraints",
+			options: UpgradePlanOptions{},
+			want:    []UpgradePlan{currentContractPlan("v1.1.0", "v2.1.0"), nextContractPlan},
+		},
+		{
+			name:    "No contract changes",
+			options: UpgradePlanOptions{NoContractChanges: true},
+			want:    []UpgradePlan{currentContractPlan("v1.1.0", "v2.1.0")},
+		},
+		{
+			name:    "Only minor releases, dropping the next contract because it requires a major release",
+			options: UpgradePlanOptions{MaxVersionChange: UpgradeVersionChangeMinor},
+			want:    []UpgradePlan{currentContractPlan("v1.1.0", "v2.1.0")},
+		},
+		{
+			name:    "Only patch releases",
+			options: UpgradePlanOptions{MaxVersionChange: UpgradeVersionChangePatch},
+			want:    []UpgradePlan{currentContractPlan("v1.0.1", "v2.0.1")},
+		},
+		{
+			name:    "Invalid max version change",
+			options: UpgradePlanOptions{MaxVersionChange: "major"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			ctx := context.Background()
+
+			configClient, _ := config.New(ctx, "", config.InjectReader(reader))
+
+			u := &providerUpgrader{
+				configClient: configClient,
+				repositoryClientFactory: func(ctx context.Context, provider config.Provider, configClient config.Client, _ ...repository.Option) (repository.Client, error) {
+					return repository.New(ctx, provider, configClient, repository.InjectRepository(repositories[provider.ManifestLabel()]))
+				},
+				providerInventory: newInventoryClient(proxy, nil),
+			}
+			got, err := u.PlanWithOptions(ctx, tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(BeComparableTo(tt.want), cmp.Diff(got, tt.want))
+		})
+	}
+}
+
+func Test_compareCRDs(t *testing.T) {
+	crd := func(storageVersion string, storedVersions []string, servedVersions ...string) *apiextensionsv1.CustomResourceDefinition {
+		c := &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "foos.example.com"},
+			Status:     apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: storedVersions},
+		}
+		for _, v := range servedVersions {
+			c.Spec.Versions = append(c.Spec.Versions, apiextensionsv1.CustomResourceDefinitionVersion{Name: v, Served: true, Storage: v == storageVersion})
+		}
+		return c
+	}
+
+	tests := []struct {
+		name       string
+		currentCRD *apiextensionsv1.CustomResourceDefinition
+		nextCRD    *apiextensionsv1.CustomResourceDefinition
+		want       *CRDChange
+	}{
+		{
+			name:    "CRD added",
+			nextCRD: crd("v1beta1", nil, "v1alpha1", "v1beta1"),
+			want:    &CRDChange{Name: "foos.example.com", Added: true, NextStorageVersion: "v1beta1", AddedVersions: []string{"v1alpha1", "v1beta1"}},
+		},
+		{
+			name:       "No changes",
+			currentCRD: crd("v1beta1", []string{"v1beta1"}, "v1beta1"),
+			nextCRD:    crd("v1beta1", nil, "v1beta1"),
+			want:       nil,
+		},
+		{
+			name:       "Version added and storage version changed",
+			currentCRD: crd("v1beta1", []string{"v1beta1"}, "v1beta1"),
+			nextCRD:    crd("v1beta2", nil, "v1beta1", "v1beta2"),
+			want:       &CRDChange{Name: "foos.example.com", CurrentStorageVersion: "v1beta1", NextStorageVersion: "v1beta2", AddedVersions: []string{"v1beta2"}},
+		},
+		{
+			name:       "Stored version removed, migration required",
+			currentCRD: crd("v1beta1", []string{"v1alpha4", "v1beta1"}, "v1alpha4", "v1beta1"),
+			nextCRD:    crd("v1beta1", nil, "v1beta1"),
+			want:       &CRDChange{Name: "foos.example.com", CurrentStorageVersion: "v1beta1", NextStorageVersion: "v1beta1", RemovedVersions: []string{"v1alpha4"}, MigrationRequired: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got := compareCRDs(tt.currentCRD, tt.nextCRD)
+			g.Expect(got).To(BeComparableTo(tt.want))
+		})
+	}
+}
@@ -18,8 +18,15 @@ package cluster
 
 import (
 	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	utilkubeconfig "sigs.k8s.io/cluster-api/util/kubeconfig"
@@ -29,6 +36,88 @@ import (
 type WorkloadCluster interface {
 	// GetKubeconfig returns the kubeconfig of the workload cluster.
 	GetKubeconfig(ctx context.Context, workloadClusterName string, namespace string) (string, error)
+
+	// GetKubeconfigWithAuth returns the kubeconfig of the workload cluster, using the given authentication
+	// instead of the client certificate embedded in the kubeconfig generated by Cluster API.
+	GetKubeconfigWithAuth(ctx context.Context, workloadClusterName string, namespace string, auth KubeconfigAuth) (string, error)
+}
+
+const (
+	// KubeconfigExecAudienceEnv is the environment variable used to pass the token audience to exec credential plugins.
+	KubeconfigExecAudienceEnv = "CLUSTERCTL_TOKEN_AUDIENCE"
+
+	// KubeconfigExecExpiryEnv is the environment variable used to pass the requested token expiry to exec credential plugins.
+	KubeconfigExecExpiryEnv = "CLUSTERCTL_TOKEN_EXPIRY"
+
+	kubeconfigExecAPIVersion = "client.authentication.k8s.io/v1"
+	kubeconfigOIDCProvider   = "oidc"
+)
+
+// KubeconfigAuth defines how a kubeconfig authenticates to the workload cluster; at most one of Exec and OIDC can be set.
+// If none is set, the client certificate generated by Cluster API is used.
+type KubeconfigAuth struct {
+	// Exec configures an exec credential plugin for getting credentials.
+	Exec *KubeconfigExecAuth
+
+	// OIDC configures the OIDC auth provider for getting credentials.
+	OIDC *KubeconfigOIDCAuth
+}
+
+// KubeconfigExecAuth defines an exec credential plugin.
+type KubeconfigExecAuth struct {
+	// Command is the command to execute.
+	Command string
+
+	// Args are the arguments to pass to the command.
+	Args []string
+
+	// Env are additional environment variables to pass to the command.
+	Env map[string]string
+
+	// Audience is the audience of the tokens requested by the plugin; it is passed to the plugin
+	// with the CLUSTERCTL_TOKEN_AUDIENCE environment variable.
+	Audience string
+
+	// Expiry is the requested expiry of the tokens; it is passed to the plugin
+	// with the CLUSTERCTL_TOKEN_EXPIRY environment variable.
+	Expiry time.Duration
+}
+
+// KubeconfigOIDCAuth defines the OIDC auth provider.
+type KubeconfigOIDCAuth struct {
+	// IssuerURL is the URL of the OIDC issuer.
+	IssuerURL string
+
+	// Audience is the OIDC client ID, used as the audience of the ID tokens.
+	Audience string
+
+	// ExtraScopes are the scopes to request in addition to the openid scope.
+	ExtraScopes []string
+}
+
+// Validate validates the KubeconfigAuth.
+func (a KubeconfigAuth) Validate() error {
+	if a.Exec != nil && a.OIDC != nil {
+		return errors.New("only one of exec and OIDC authentication can be used")
+	}
+	if a.Exec != nil {
+		if a.Exec.Command == "" {
+			return errors.New("the command for the exec credential plugin must be set")
+		}
+		if a.Exec.Expiry < 0 {
+			return errors.New("the token expiry can't be negative")
+		}
+	}
+	if a.OIDC != nil {
+		if a.OIDC.Audience == "" {
+			return errors.New("the audience for OIDC authentication must be set")
+		}
+		issuer, err := url.Parse(a.OIDC.IssuerURL)
+		if err != nil || issuer.Scheme != "https" || issuer.Host == "" {
+			return errors.Errorf("invalid OIDC issuer URL %q, it must be an https URL", a.OIDC.IssuerURL)
+		}
+	}
+	return nil
 }
 
 // workloadCluster implements WorkloadCluster.
@@ -59,3 +148,89 @@ func (p *workloadCluster) GetKubeconfig(ctx context.Context, workloadClusterName
 	}
 	return string(dataBytes), nil
 }
+
+func (p *workloadCluster) GetKubeconfigWithAuth(ctx context.Context, workloadClusterName string, namespace string, auth KubeconfigAuth) (string, error) {
+	if err := auth.Validate(); err != nil {
+		return "", err
+	}
+
+	kubeconfig, err := p.GetKubeconfig(ctx, workloadClusterName, namespace)
+	if err != nil {
+		return "", err
+	}
+	if auth.Exec == nil && auth.OIDC == nil {
+		return kubeconfig, nil
+	}
+
+	config, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse the kubeconfig for Cluster %s/%s", namespace, workloadClusterName)
+	}
+	if err := setKubeconfigAuth(config, workloadClusterName, auth); err != nil {
+		return "", errors.Wrapf(err, "failed to set authentication in the kubeconfig for Cluster %s/%s", namespace, workloadClusterName)
+	}
+
+	out, err := clientcmd.Write(*config)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to write the kubeconfig for Cluster %s/%s", namespace, workloadClusterName)
+	}
+	return string(out), nil
+}
+
+// setKubeconfigAuth replaces the users of a kubeconfig, usually the admin user with the client certificate
+// generated by Cluster API, with a single user using the given authentication.
+func setKubeconfigAuth(config *clientcmdapi.Config, workloadClusterName string, auth KubeconfigAuth) error {
+	authInfo := clientcmdapi.NewAuthInfo()
+	userName := fmt.Sprintf("%s-exec", workloadClusterName)
+	if auth.Exec != nil {
+		authInfo.Exec = &clientcmdapi.ExecConfig{
+			APIVersion:      kubeconfigExecAPIVersion,
+			Command:         auth.Exec.Command,
+			Args:            auth.Exec.Args,
+			InteractiveMode: clientcmdapi.IfAvailableExecInteractiveMode,
+		}
+
+		env := []clientcmdapi.ExecEnvVar{}
+		for name, value := range auth.Exec.Env {
+			env = append(env, clientcmdapi.ExecEnvVar{Name: name, Value: value})
+		}
+		sort.Slice(env, func(i, j int) bool { return env[i].Name < env[j].Name })
+		if auth.Exec.Audience != "" {
+			env = append(env, clientcmdapi.ExecEnvVar{Name: KubeconfigExecAudienceEnv, Value: auth.Exec.Audience})
+		}
+		if auth.Exec.Expiry != 0 {
+			env = append(env, clientcmdapi.ExecEnvVar{Name: KubeconfigExecExpiryEnv, Value: auth.Exec.Expiry.String()})
+		}
+		authInfo.Exec.Env = env
+	}
+	if auth.OIDC != nil {
+		userName = fmt.Sprintf("%s-oidc", workloadClusterName)
+		authInfo.AuthProvider = &clientcmdapi.AuthProviderConfig{
+			Name: kubeconfigOIDCProvider,
+			Config: map[string]string{
+				"idp-issuer-url": auth.OIDC.IssuerURL,
+				"client-id":      auth.OIDC.Audience,
+			},
+		}
+		if len(auth.OIDC.ExtraScopes) > 0 {
+			authInfo.AuthProvider.Config["extra-scopes"] = strings.Join(auth.OIDC.ExtraScopes, ",")
+		}
+	}
+
+	currentContext, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return errors.Errorf("the current context %q does not exist", config.CurrentContext)
+	}
+
+	contextName := fmt.Sprintf("%s@%s", userName, currentContext.Cluster)
+	kubeContext := clientcmdapi.NewContext()
+	kubeContext.Cluster = currentContext.Cluster
+	kubeContext.Namespace = currentContext.Namespace
+	kubeContext.AuthInfo = userName
+
+	// Drop all the users and the contexts, so the admin client certificate is not distributed.
+	config.AuthInfos = map[string]*clientcmdapi.AuthInfo{userName: authInfo}
+	config.Contexts = map[string]*clientcmdapi.Context{contextName: kubeContext}
+	config.CurrentContext = contextName
+	return nil
+}
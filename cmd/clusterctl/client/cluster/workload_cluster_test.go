@@ -19,10 +19,13 @@ package cluster
 import (
 	"context"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
@@ -100,3 +103,144 @@ users:
 		})
 	}
 }
+
+func Test_WorkloadCluster_GetKubeconfigWithAuth(t *testing.T) {
+	kubeconfigSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test1-kubeconfig",
+			Namespace: "test",
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: "test1"},
+		},
+		Data: map[string][]byte{
+			secret.KubeconfigDataName: []byte(`
+clusters:
+- cluster:
+    certificate-authority-data: c3R1ZmY=
+    server: https://test-cluster-api:6443
+  name: test1
+contexts:
+- context:
+    cluster: test1
+    user: test1-admin
+  name: test1-admin@test1
+current-context: test1-admin@test1
+kind: Config
+preferences: {}
+users:
+- name: test1-admin
+  user:
+    client-certificate-data: c3R1ZmYtY2VydC1kYXRh
+    client-key-data: c3R1ZmYta2V5LWRhdGE=
+`),
+		},
+	}
+
+	tests := []struct {
+		name        string
+		auth        KubeconfigAuth
+		wantUser    string
+		wantAuth    *clientcmdapi.AuthInfo
+		wantErr     string
+		wantCertOut bool
+	}{
+		{
+			name:        "Without authentication, the kubeconfig is not changed",
+			auth:        KubeconfigAuth{},
+			wantCertOut: true,
+		},
+		{
+			name: "Exec credential plugin",
+			auth: KubeconfigAuth{Exec: &KubeconfigExecAuth{
+				Command:  "get-token",
+				Args:     []string{"--cluster", "test1"},
+				Env:      map[string]string{"B": "b", "A": "a"},
+				Audience: "test1",
+				Expiry:   time.Hour,
+			}},
+			wantUser: "test1-exec",
+			wantAuth: &clientcmdapi.AuthInfo{
+				Exec: &clientcmdapi.ExecConfig{
+					APIVersion: "client.authentication.k8s.io/v1",
+					Command:    "get-token",
+					Args:       []string{"--cluster", "test1"},
+					Env: []clientcmdapi.ExecEnvVar{
+						{Name: "A", Value: "a"},
+						{Name: "B", Value: "b"},
+						{Name: KubeconfigExecAudienceEnv, Value: "test1"},
+						{Name: KubeconfigExecExpiryEnv, Value: "1h0m0s"},
+					},
+					InteractiveMode: clientcmdapi.IfAvailableExecInteractiveMode,
+				},
+			},
+		},
+		{
+			name: "OIDC auth provider",
+			auth: KubeconfigAuth{OIDC: &KubeconfigOIDCAuth{
+				IssuerURL:   "https://issuer.example.com",
+				Audience:    "kubernetes",
+				ExtraScopes: []string{"email", "groups"},
+			}},
+			wantUser: "test1-oidc",
+			wantAuth: &clientcmdapi.AuthInfo{
+				AuthProvider: &clientcmdapi.AuthProviderConfig{
+					Name: "oidc",
+					Config: map[string]string{
+						"idp-issuer-url": "https://issuer.example.com",
+						"client-id":      "kubernetes",
+						"extra-scopes":   "email,groups",
+					},
+				},
+			},
+		},
+		{
+			name:    "Exec without command",
+			auth:    KubeconfigAuth{Exec: &KubeconfigExecAuth{}},
+			wantErr: "the command for the exec credential plugin must be set",
+		},
+		{
+			name:    "OIDC with an invalid issuer URL",
+			auth:    KubeconfigAuth{OIDC: &KubeconfigOIDCAuth{IssuerURL: "http://issuer.example.com", Audience: "kubernetes"}},
+			wantErr: "it must be an https URL",
+		},
+		{
+			name:    "Both exec and OIDC",
+			auth:    KubeconfigAuth{Exec: &KubeconfigExecAuth{Command: "get-token"}, OIDC: &KubeconfigOIDCAuth{IssuerURL: "https://issuer.example.com", Audience: "kubernetes"}},
+			wantErr: "only one of exec and OIDC authentication can be used",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			wc := newWorkloadCluster(test.NewFakeProxy().WithObjs(kubeconfigSecret))
+			data, err := wc.GetKubeconfigWithAuth(context.Background(), "test1", "test", tt.auth)
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+
+			if tt.wantCertOut {
+				g.Expect(data).To(Equal(string(kubeconfigSecret.Data[secret.KubeconfigDataName])))
+				return
+			}
+			g.Expect(data).ToNot(ContainSubstring("client-certificate-data"))
+			g.Expect(data).ToNot(ContainSubstring("client-key-data"))
+
+			config, err := clientcmd.Load([]byte(data))
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(config.AuthInfos).To(HaveLen(1))
+			g.Expect(config.AuthInfos).To(HaveKey(tt.wantUser))
+			authInfo := config.AuthInfos[tt.wantUser]
+			authInfo.LocationOfOrigin = ""
+			tt.wantAuth.Extensions = authInfo.Extensions
+			g.Expect(authInfo).To(BeComparableTo(tt.wantAuth))
+
+			g.Expect(config.CurrentContext).To(Equal(tt.wantUser + "@test1"))
+			g.Expect(config.Contexts[config.CurrentContext].AuthInfo).To(Equal(tt.wantUser))
+			g.Expect(config.Contexts[config.CurrentContext].Cluster).To(Equal("test1"))
+			g.Expect(config.Clusters["test1"].CertificateAuthorityData).To(Equal([]byte("stuff")))
+		})
+	}
+}
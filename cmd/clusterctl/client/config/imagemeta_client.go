@@ -29,14 +29,19 @@ const (
 	// CertManagerImageComponent define the name of the cert-manager component in image overrides.
 	CertManagerImageComponent = "cert-manager"
 
-	imagesConfigKey = "images"
-	allImageConfig  = "all"
+	imagesConfigKey       = "images"
+	allImageConfig        = "all"
+	containersImageConfig = "containers"
 )
 
 // ImageMetaClient has methods to work with image meta configurations.
 type ImageMetaClient interface {
 	// AlterImage alters an image name according to the current image override configurations.
 	AlterImage(component, image string) (string, error)
+
+	// AlterContainerImage alters the image of a container according to the current image override configurations,
+	// including the ones defined for the container name.
+	AlterContainerImage(component, containerName, image string) (string, error)
 }
 
 // imageMetaClient implements ImageMetaClient.
@@ -56,13 +61,17 @@ func newImageMetaClient(reader Reader) *imageMetaClient {
 }
 
 func (p *imageMetaClient) AlterImage(component, imageString string) (string, error) {
+	return p.AlterContainerImage(component, "", imageString)
+}
+
+func (p *imageMetaClient) AlterContainerImage(component, containerName, imageString string) (string, error) {
 	image, err := container.ImageFromString(imageString)
 	if err != nil {
 		return "", err
 	}
 
-	// Gets the image meta that applies to the selected component/image; if none, returns early
-	meta, err := p.getImageMeta(component, image.Name)
+	// Gets the image meta that applies to the selected component/image/container; if none, returns early
+	meta, err := p.getImageMeta(component, containerName, image.Name)
 	if err != nil {
 		return "", err
 	}
@@ -71,13 +80,22 @@ func (p *imageMetaClient) AlterImage(component, imageString string) (string, err
 	}
 
 	// Apply the image meta to image name
-	return meta.ApplyToImage(image), nil
+	alteredImage := meta.ApplyToImage(image)
+	if err := container.ValidateImageReference(alteredImage); err != nil {
+		return "", errors.Wrapf(err, "invalid image override for component %q", component)
+	}
+	return alteredImage, nil
 }
 
-// getImageMeta returns the image meta that applies to the selected component/image.
-func (p *imageMetaClient) getImageMeta(component, imageName string) (*imageMeta, error) {
+// getImageMeta returns the image meta that applies to the selected component/image/container.
+func (p *imageMetaClient) getImageMeta(component, containerName, imageName string) (*imageMeta, error) {
+	cacheKey := imageMetaCacheKey(component, imageName)
+	if containerName != "" {
+		cacheKey = fmt.Sprintf("%s@%s", cacheKey, containerName)
+	}
+
 	// if the image meta for the component is already known, return it
-	if im, ok := p.imageMetaCache[imageMetaCacheKey(component, imageName)]; ok {
+	if im, ok := p.imageMetaCache[cacheKey]; ok {
 		return im, nil
 	}
 
@@ -89,7 +107,7 @@ func (p *imageMetaClient) getImageMeta(component, imageName string) (*imageMeta,
 
 	// If there are not image override configurations, return.
 	if meta == nil {
-		p.imageMetaCache[imageMetaCacheKey(component, imageName)] = nil
+		p.imageMetaCache[cacheKey] = nil
 		return nil, nil
 	}
 
@@ -97,6 +115,7 @@ func (p *imageMetaClient) getImageMeta(component, imageName string) (*imageMeta,
 	//	- all the components,
 	//	- the component (and to all its images)
 	//	- the selected component/image
+	//	- the selected component/containers/container
 	//	and returns the union of all the above.
 	m := &imageMeta{}
 	if allMeta, ok := meta[allImageConfig]; ok {
@@ -106,12 +125,17 @@ func (p *imageMetaClient) getImageMeta(component, imageName string) (*imageMeta,
 	if componentMeta, ok := meta[component]; ok {
 		m.Union(&componentMeta)
 	}
-	p.imageMetaCache[component] = m
 
 	if imageNameMeta, ok := meta[imageMetaCacheKey(component, imageName)]; ok {
 		m.Union(&imageNameMeta)
 	}
-	p.imageMetaCache[imageMetaCacheKey(component, imageName)] = m
+
+	if containerName != "" {
+		if containerMeta, ok := meta[fmt.Sprintf("%s/%s/%s", component, containersImageConfig, containerName)]; ok {
+			m.Union(&containerMeta)
+		}
+	}
+	p.imageMetaCache[cacheKey] = m
 
 	return m, nil
 }
@@ -127,6 +151,10 @@ type imageMeta struct {
 
 	// Tag allows to specify a tag for the images.
 	Tag string `json:"tag,omitempty"`
+
+	// Digest allows to pin the images to a digest, e.g. sha256:...; the digest takes precedence over the tag
+	// when pulling the images.
+	Digest string `json:"digest,omitempty"`
 }
 
 // Union allows to merge two imageMeta transformation; in case both the imageMeta defines new values for the same field,
@@ -138,6 +166,9 @@ func (i *imageMeta) Union(other *imageMeta) {
 	if other.Tag != "" {
 		i.Tag = other.Tag
 	}
+	if other.Digest != "" {
+		i.Digest = other.Digest
+	}
 }
 
 // ApplyToImage changes an image name applying the transformations defined in the current imageMeta.
@@ -148,6 +179,11 @@ func (i *imageMeta) ApplyToImage(image container.Image) string {
 	}
 	if i.Tag != "" {
 		image.Tag = i.Tag
+		// The digest of the original image does not apply to a different tag.
+		image.Digest = ""
+	}
+	if i.Digest != "" {
+		image.Digest = i.Digest
 	}
 
 	// returns the resulting image name
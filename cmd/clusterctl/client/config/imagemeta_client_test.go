@@ -257,3 +257,100 @@ func Test_imageMetaClient_AlterImage(t *testing.T) {
 		})
 	}
 }
+
+func Test_imageMetaClient_AlterContainerImage(t *testing.T) {
+	digest := "sha256:0123456789012345678901234567890123456789012345678901234567890123"
+
+	tests := []struct {
+		name          string
+		images        string
+		containerName string
+		image         string
+		want          string
+		wantErr       bool
+	}{
+		{
+			name: "image config for a container: only the image of the container should be changed",
+			images: `
+cluster-api/containers/kube-rbac-proxy:
+  repository: foo-repository.io
+  tag: foo-tag
+`,
+			containerName: "kube-rbac-proxy",
+			image:         "gcr.io/kubebuilder/kube-rbac-proxy:v0.15.0",
+			want:          "foo-repository.io/kube-rbac-proxy:foo-tag",
+		},
+		{
+			name: "image config for a container: the image of other containers should not be changed",
+			images: `
+cluster-api/containers/kube-rbac-proxy:
+  repository: foo-repository.io
+`,
+			containerName: "manager",
+			image:         "registry.k8s.io/cluster-api/cluster-api-controller:v1.6.0",
+			want:          "registry.k8s.io/cluster-api/cluster-api-controller:v1.6.0",
+		},
+		{
+			name: "image config for a container takes precedence over the image config for the component/image",
+			images: `
+all:
+  repository: all-repository.io
+cluster-api/cluster-api-controller:
+  tag: image-tag
+cluster-api/containers/manager:
+  tag: container-tag
+`,
+			containerName: "manager",
+			image:         "registry.k8s.io/cluster-api/cluster-api-controller:v1.6.0",
+			want:          "all-repository.io/cluster-api-controller:container-tag",
+		},
+		{
+			name: "image config with digest: the image should be pinned to the digest",
+			images: `
+all:
+  repository: all-repository.io
+cluster-api/containers/manager:
+  digest: ` + digest + `
+`,
+			containerName: "manager",
+			image:         "registry.k8s.io/cluster-api/cluster-api-controller:v1.6.0",
+			want:          "all-repository.io/cluster-api-controller:v1.6.0@" + digest,
+		},
+		{
+			name: "image config with tag: the digest of the original image should be dropped",
+			images: `
+cluster-api:
+  tag: v1.6.1
+`,
+			containerName: "manager",
+			image:         "registry.k8s.io/cluster-api/cluster-api-controller:v1.6.0@" + digest,
+			want:          "registry.k8s.io/cluster-api/cluster-api-controller:v1.6.1",
+		},
+		{
+			name: "fails if the digest is invalid",
+			images: `
+cluster-api/containers/manager:
+  digest: sha256:invalid
+`,
+			containerName: "manager",
+			image:         "registry.k8s.io/cluster-api/cluster-api-controller:v1.6.0",
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			p := newImageMetaClient(test.NewFakeReader().WithVar(imagesConfigKey, tt.images))
+
+			got, err := p.AlterContainerImage("cluster-api", tt.containerName, tt.image)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -19,6 +19,16 @@ package config
 const (
 	// GitHubTokenVariable defines a variable hosting the GitHub access token.
 	GitHubTokenVariable = "github-token"
+
+	// OCIUsernameVariable defines a variable hosting the username used to authenticate to OCI registries.
+	OCIUsernameVariable = "oci-username"
+
+	// OCIPasswordVariable defines a variable hosting the password or the token used to authenticate to OCI registries.
+	OCIPasswordVariable = "oci-password"
+
+	// OCICosignPublicKeyVariable defines a variable hosting the path of the cosign public key used to verify
+	// the signatures of the OCI artifacts hosting provider repositories.
+	OCICosignPublicKeyVariable = "oci-cosign-public-key"
 )
 
 // VariablesClient has methods to work with environment variables and with variables defined in the clusterctl configuration file.
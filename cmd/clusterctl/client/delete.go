@@ -18,6 +18,8 @@ package client
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -25,6 +27,7 @@ import (
 
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
 )
 
 // DeleteOptions carries the options supported by Delete.
@@ -66,14 +69,21 @@ type DeleteOptions struct {
 
 	// SkipInventory forces the deletion of the inventory items used by clusterctl to track providers.
 	SkipInventory bool
+
+	// Force allows to delete providers used by Clusters or Machines, thus leaving the workload clusters
+	// without a controller reconciling them.
+	Force bool
 }
 
-func (c *clusterctlClient) Delete(ctx context.Context, options DeleteOptions) error {
+func (c *clusterctlClient) Delete(ctx context.Context, options DeleteOptions) (retErr error) {
 	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
 	if err != nil {
 		return err
 	}
 
+	audit := c.startAuditedOperation(ctx, clusterClient, cluster.DeleteOperation)
+	defer func() { audit.complete(ctx, retErr) }()
+
 	// Ensure this command only runs against management clusters with the current Cluster API contract.
 	if err := clusterClient.ProviderInventory().CheckCAPIContract(ctx); err != nil {
 		return err
@@ -157,8 +167,25 @@ func (c *clusterctlClient) Delete(ctx context.Context, options DeleteOptions) er
 		}
 	}
 
+	// Ensure no workload clusters depend on the providers to delete, unless forced.
+	// NOTE: The usage is computed before deleting the providers, because it can't be computed after deleting the provider's CRDs.
+	usages := make([]*cluster.ProviderUsage, 0, len(providersToDelete))
+	errList := []error{}
+	for _, provider := range providersToDelete {
+		usage, err := clusterClient.ProviderComponents().GetUsage(ctx, provider)
+		if err != nil {
+			return err
+		}
+		usages = append(usages, usage)
+		if usage.InUse() && !options.Force {
+			errList = append(errList, errors.Errorf("provider %q is used by %s. Please delete the workload clusters first, or use --force to delete the provider anyway and orphan them", provider.Name, describeUsage(usage)))
+		}
+	}
+	if len(errList) > 0 {
+		return kerrors.NewAggregate(errList)
+	}
+
 	if options.IncludeCRDs {
-		errList := []error{}
 		for _, provider := range providersToDelete {
 			err = clusterClient.ProviderComponents().ValidateNoObjectsExist(ctx, provider)
 			if err != nil {
@@ -177,9 +204,54 @@ func (c *clusterctlClient) Delete(ctx context.Context, options DeleteOptions) er
 		}
 	}
 
+	reportOrphanedObjects(ctx, clusterClient, usages)
 	return nil
 }
 
+// reportOrphanedObjects logs the objects left behind by the deletion of the providers, which are no longer reconciled:
+// the objects of the Kinds defined in the provider's CRDs, if the CRDs are not deleted, and the Clusters and Machines
+// depending on the providers, if the deletion is forced.
+func reportOrphanedObjects(ctx context.Context, clusterClient cluster.Client, usages []*cluster.ProviderUsage) {
+	log := logf.Log
+
+	for _, usage := range usages {
+		// NOTE: the report is best effort, given that the providers are already deleted.
+		objects, err := clusterClient.ProviderComponents().CountObjects(ctx, usage.Provider)
+		if err != nil {
+			log.V(5).Info("Failed to count the objects left behind by the provider", "Provider", usage.Provider.Name, "Error", err.Error())
+			continue
+		}
+		if len(objects) == 0 && !usage.InUse() {
+			continue
+		}
+
+		counts := make([]string, 0, len(objects))
+		for _, o := range objects {
+			counts = append(counts, fmt.Sprintf("%s=%d", o.Kind, o.Count))
+		}
+		keysAndValues := []interface{}{"Provider", usage.Provider.Name}
+		if len(counts) > 0 {
+			keysAndValues = append(keysAndValues, "Objects", strings.Join(counts, ", "))
+		}
+		if usage.InUse() {
+			keysAndValues = append(keysAndValues, "WorkloadClusters", describeUsage(usage))
+		}
+		log.Info("Objects left behind are no longer reconciled", keysAndValues...)
+	}
+}
+
+// describeUsage describes the Clusters and Machines depending on a provider.
+func describeUsage(usage *cluster.ProviderUsage) string {
+	var parts []string
+	if len(usage.Clusters) > 0 {
+		parts = append(parts, fmt.Sprintf("Clusters [%s]", strings.Join(usage.Clusters, ", ")))
+	}
+	if len(usage.Machines) > 0 {
+		parts = append(parts, fmt.Sprintf("Machines [%s]", strings.Join(usage.Machines, ", ")))
+	}
+	return strings.Join(parts, " and ")
+}
+
 func appendProviders(list []clusterctlv1.Provider, providerType clusterctlv1.ProviderType, names ...string) ([]clusterctlv1.Provider, error) {
 	for _, name := range names {
 		if name == "" {
@@ -21,8 +21,10 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 )
@@ -184,6 +186,37 @@ func Test_clusterctlClient_Delete(t *testing.T) {
 				clusterctlv1.ManifestLabel(infraProviderConfig.Name(), infraProviderConfig.Type())),
 			wantErr: false,
 		},
+		{
+			name: "Delete a provider used by a Cluster",
+			fields: fields{
+				client: fakeClusterForDelete(withWorkloadCluster),
+			},
+			args: args{
+				options: DeleteOptions{
+					Kubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					CoreProvider: capiProviderConfig.Name(),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Force the deletion of a provider used by a Cluster",
+			fields: fields{
+				client: fakeClusterForDelete(withWorkloadCluster),
+			},
+			args: args{
+				options: DeleteOptions{
+					Kubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					CoreProvider: capiProviderConfig.Name(),
+					Force:        true,
+				},
+			},
+			wantProviders: sets.Set[string]{}.Insert(
+				clusterctlv1.ManifestLabel(bootstrapProviderConfig.Name(), bootstrapProviderConfig.Type()),
+				clusterctlv1.ManifestLabel(controlPlaneProviderConfig.Name(), controlPlaneProviderConfig.Type()),
+				clusterctlv1.ManifestLabel(infraProviderConfig.Name(), infraProviderConfig.Type())),
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -217,7 +250,7 @@ func Test_clusterctlClient_Delete(t *testing.T) {
 }
 
 // clusterctl client for a management cluster with capi and bootstrap provider.
-func fakeClusterForDelete() *fakeClient {
+func fakeClusterForDelete(opts ...func(*fakeClusterClient)) *fakeClient {
 	ctx := context.Background()
 
 	config1 := newFakeConfig(ctx).
@@ -238,6 +271,9 @@ func fakeClusterForDelete() *fakeClient {
 	cluster1.fakeProxy.WithProviderInventory(controlPlaneProviderConfig.Name(), controlPlaneProviderConfig.Type(), providerVersion, "capi-kubeadm-control-plane-system")
 	cluster1.fakeProxy.WithProviderInventory(infraProviderConfig.Name(), infraProviderConfig.Type(), providerVersion, namespace)
 	cluster1.fakeProxy.WithFakeCAPISetup()
+	for _, o := range opts {
+		o(cluster1)
+	}
 
 	client := newFakeClient(ctx, config1).
 		// fake repository for capi, bootstrap, controlplane and infra provider (matching provider's config)
@@ -250,3 +286,11 @@ func fakeClusterForDelete() *fakeClient {
 
 	return client
 }
+
+// withWorkloadCluster adds a workload Cluster to the management cluster.
+func withWorkloadCluster(c *fakeClusterClient) {
+	c.fakeProxy.WithObjs(&clusterv1.Cluster{
+		TypeMeta:   metav1.TypeMeta{Kind: "Cluster", APIVersion: clusterv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "workload"},
+	})
+}
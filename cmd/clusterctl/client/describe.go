@@ -55,8 +55,13 @@ type DescribeClusterOptions struct {
 	Echo bool
 
 	// Grouping groups machines objects in case the ready conditions
-	// have the same Status, Severity and Reason.
+	// have the same Status, Severity and Reason; when using v1beta2 conditions,
+	// machine objects are grouped in case all their conditions have the same Type, Status and Reason.
 	Grouping bool
+
+	// ConditionsFormat defines the format of the conditions used for echo and grouping,
+	// and that should be used in the presentation layer; defaults to v1beta1.
+	ConditionsFormat tree.ConditionsFormat
 }
 
 // DescribeCluster returns the object tree representing the status of a Cluster API cluster.
@@ -96,5 +101,6 @@ func (c *clusterctlClient) DescribeCluster(ctx context.Context, options Describe
 		AddTemplateVirtualNode:  options.AddTemplateVirtualNode,
 		Echo:                    options.Echo,
 		Grouping:                options.Grouping,
+		ConditionsFormat:        options.ConditionsFormat,
 	})
 }
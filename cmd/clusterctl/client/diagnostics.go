@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+// CollectDiagnosticsOptions carries the options supported by CollectDiagnostics.
+type CollectDiagnosticsOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Namespace where the Cluster API objects and the Events are collected from. If empty, all the namespaces are used.
+	Namespace string
+
+	// LogsSince limits the controller logs to the logs newer than the given duration. If zero, all the logs are collected.
+	LogsSince time.Duration
+
+	// EventsSince limits the Events to the Events that happened within the given duration. If zero, all the Events are collected.
+	EventsSince time.Duration
+
+	// SkipLogs skips collecting the controller logs.
+	SkipLogs bool
+
+	// RedactPatterns are additional regular expressions for the data to be redacted from the diagnostics bundle.
+	RedactPatterns []string
+}
+
+// CollectDiagnostics writes to w a gzipped tarball with the diagnostics data of the management cluster.
+func (c *clusterctlClient) CollectDiagnostics(ctx context.Context, w io.Writer, options CollectDiagnosticsOptions) error {
+	diagnosticsOptions := cluster.DiagnosticsOptions{
+		Namespace:      options.Namespace,
+		LogsSince:      options.LogsSince,
+		EventsSince:    options.EventsSince,
+		SkipLogs:       options.SkipLogs,
+		RedactPatterns: options.RedactPatterns,
+	}
+	if err := diagnosticsOptions.Validate(); err != nil {
+		return err
+	}
+
+	// gets access to the management cluster
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return err
+	}
+
+	// NOTE: the Cluster API contract of the management cluster is not checked, so diagnostics can be collected
+	// from management clusters with a broken or partial installation too.
+	if err := clusterClient.Proxy().CheckClusterAvailable(ctx); err != nil {
+		return err
+	}
+
+	return clusterClient.Diagnostics().Collect(ctx, w, diagnosticsOptions)
+}
@@ -0,0 +1,327 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+)
+
+const (
+	// featureGatesArg is the flag used by providers to configure feature gates.
+	featureGatesArg = "--feature-gates"
+
+	// managerContainerName is the name of the container running the provider controllers.
+	managerContainerName = "manager"
+)
+
+// GetFeatureGatesOptions carries the options supported by GetFeatureGates.
+type GetFeatureGatesOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+}
+
+// SetFeatureGatesOptions carries the options supported by SetFeatureGates.
+type SetFeatureGatesOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Providers are the providers to change, identified by the name of their inventory entry
+	// (e.g. cluster-api or bootstrap-kubeadm); if empty, all the providers supporting the feature gates are changed.
+	Providers []string
+
+	// FeatureGates are the values of the feature gates to set.
+	FeatureGates map[string]bool
+
+	// WaitProviderTimeout sets the timeout per provider Deployment to roll out after the change.
+	WaitProviderTimeout time.Duration
+}
+
+// FeatureGate is a feature gate of a provider and its current value.
+type FeatureGate struct {
+	Name    string
+	Enabled bool
+}
+
+// ProviderFeatureGates are the feature gates supported by a provider installed in the management cluster.
+type ProviderFeatureGates struct {
+	// Provider is the provider in the clusterctl inventory.
+	Provider clusterctlv1.Provider
+
+	// FeatureGates are the feature gates configured in the provider Deployments, sorted by name.
+	FeatureGates []FeatureGate
+}
+
+// providerDeployments are the Deployments running the controllers of a provider.
+type providerDeployments struct {
+	provider    clusterctlv1.Provider
+	deployments []appsv1.Deployment
+}
+
+// GetFeatureGates returns the feature gates supported by each provider installed in the management cluster.
+func (c *clusterctlClient) GetFeatureGates(ctx context.Context, options GetFeatureGatesOptions) ([]ProviderFeatureGates, error) {
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return nil, err
+	}
+
+	providers, err := getProviderDeployments(ctx, clusterClient)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []ProviderFeatureGates{}
+	for _, p := range providers {
+		featureGates := map[string]bool{}
+		for i := range p.deployments {
+			for name, enabled := range getFeatureGates(&p.deployments[i]) {
+				featureGates[name] = enabled
+			}
+		}
+		if len(featureGates) == 0 {
+			continue
+		}
+
+		item := ProviderFeatureGates{Provider: p.provider}
+		for _, name := range sets.List(sets.KeySet(featureGates)) {
+			item.FeatureGates = append(item.FeatureGates, FeatureGate{Name: name, Enabled: featureGates[name]})
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// SetFeatureGates changes the feature gates of the providers installed in the management cluster by patching the
+// args of the provider Deployments; providers are changed one at a time, waiting for their Deployments to roll out.
+func (c *clusterctlClient) SetFeatureGates(ctx context.Context, options SetFeatureGatesOptions) (retErr error) {
+	log := logf.Log
+
+	if len(options.FeatureGates) == 0 {
+		return errors.New("at least one feature gate must be specified")
+	}
+
+	// Default WaitProviderTimeout as we cannot rely on defaulting in the CLI
+	// when clusterctl is used as a library.
+	if options.WaitProviderTimeout.Nanoseconds() == 0 {
+		options.WaitProviderTimeout = time.Duration(5*60) * time.Second
+	}
+
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return err
+	}
+
+	providers, err := getProviderDeployments(ctx, clusterClient)
+	if err != nil {
+		return err
+	}
+
+	// Select the providers to change, and validate all the changes before patching any Deployment.
+	if len(options.Providers) > 0 {
+		requested := sets.New[string](options.Providers...)
+		selected := []providerDeployments{}
+		for _, p := range providers {
+			if requested.Has(p.provider.Name) {
+				selected = append(selected, p)
+				requested.Delete(p.provider.Name)
+			}
+		}
+		if requested.Len() > 0 {
+			return errors.Errorf("providers %s are not installed in the management cluster", strings.Join(sets.List(requested), ", "))
+		}
+		providers = selected
+	}
+
+	supported := sets.Set[string]{}
+	for _, p := range providers {
+		for i := range p.deployments {
+			supported.Insert(sets.List(sets.KeySet(getFeatureGates(&p.deployments[i])))...)
+		}
+	}
+	for name := range options.FeatureGates {
+		if !supported.Has(name) {
+			return errors.Errorf("feature gate %q is not supported by the selected providers, supported feature gates are: %s", name, strings.Join(sets.List(supported), ", "))
+		}
+	}
+
+	audit := c.startAuditedOperation(ctx, clusterClient, cluster.FeatureGatesOperation)
+	defer func() { audit.complete(ctx, retErr) }()
+
+	mgmtClient, err := clusterClient.Proxy().NewClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range providers {
+		for i := range p.deployments {
+			deployment := &p.deployments[i]
+			original := deployment.DeepCopy()
+			if !setFeatureGates(deployment, options.FeatureGates) {
+				continue
+			}
+
+			log.Info("Changing feature gates", "Provider", p.provider.Name, "Deployment", client.ObjectKeyFromObject(deployment))
+			if err := mgmtClient.Patch(ctx, deployment, client.MergeFrom(original)); err != nil {
+				return errors.Wrapf(err, "failed to patch Deployment %s", client.ObjectKeyFromObject(deployment))
+			}
+
+			// Wait for the Deployment to roll out before changing the next one, so a faulty configuration
+			// does not affect more than one provider.
+			if err := waitDeploymentRolledOut(ctx, mgmtClient, deployment, options.WaitProviderTimeout); err != nil {
+				return errors.Wrapf(err, "deployment %s is not rolled out after %s", client.ObjectKeyFromObject(deployment), options.WaitProviderTimeout)
+			}
+		}
+	}
+	return nil
+}
+
+// getProviderDeployments returns the Deployments running the controllers of each provider in the management cluster,
+// with the core provider first.
+func getProviderDeployments(ctx context.Context, clusterClient cluster.Client) ([]providerDeployments, error) {
+	providerList, err := clusterClient.ProviderInventory().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := clusterClient.Proxy().NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	providers := append(providerList.FilterCore(), providerList.FilterNonCore()...)
+
+	result := []providerDeployments{}
+	for _, provider := range providers {
+		deploymentList := &appsv1.DeploymentList{}
+		if err := c.List(ctx, deploymentList, client.InNamespace(provider.Namespace), client.MatchingLabels{clusterv1.ProviderNameLabel: provider.ManifestLabel()}); err != nil {
+			return nil, errors.Wrapf(err, "failed to list Deployments for provider %s", provider.InstanceName())
+		}
+
+		item := providerDeployments{provider: provider}
+		for _, d := range deploymentList.Items {
+			if getManagerContainer(&d) != nil {
+				item.deployments = append(item.deployments, d)
+			}
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// getManagerContainer returns the container running the provider controllers, if any.
+func getManagerContainer(deployment *appsv1.Deployment) *corev1.Container {
+	for i := range deployment.Spec.Template.Spec.Containers {
+		if deployment.Spec.Template.Spec.Containers[i].Name == managerContainerName {
+			return &deployment.Spec.Template.Spec.Containers[i]
+		}
+	}
+	return nil
+}
+
+// getFeatureGates returns the feature gates configured in the args of the manager container.
+func getFeatureGates(deployment *appsv1.Deployment) map[string]bool {
+	featureGates := map[string]bool{}
+	container := getManagerContainer(deployment)
+	if container == nil {
+		return featureGates
+	}
+	for _, arg := range container.Args {
+		value, ok := strings.CutPrefix(arg, featureGatesArg+"=")
+		if !ok {
+			continue
+		}
+		for _, gate := range strings.Split(value, ",") {
+			name, enabled, ok := strings.Cut(strings.TrimSpace(gate), "=")
+			if !ok {
+				continue
+			}
+			if b, err := strconv.ParseBool(enabled); err == nil {
+				featureGates[name] = b
+			}
+		}
+	}
+	return featureGates
+}
+
+// setFeatureGates sets the value of the feature gates already configured in the args of the manager container,
+// preserving their order; it returns true if the args have been changed.
+func setFeatureGates(deployment *appsv1.Deployment, values map[string]bool) bool {
+	container := getManagerContainer(deployment)
+	if container == nil {
+		return false
+	}
+
+	changed := false
+	for j, arg := range container.Args {
+		value, ok := strings.CutPrefix(arg, featureGatesArg+"=")
+		if !ok {
+			continue
+		}
+		gates := strings.Split(value, ",")
+		for k, gate := range gates {
+			name, current, ok := strings.Cut(strings.TrimSpace(gate), "=")
+			if !ok {
+				continue
+			}
+			desired, ok := values[name]
+			if !ok {
+				continue
+			}
+			if b, err := strconv.ParseBool(current); err == nil && b == desired {
+				continue
+			}
+			gates[k] = name + "=" + strconv.FormatBool(desired)
+			changed = true
+		}
+		container.Args[j] = featureGatesArg + "=" + strings.Join(gates, ",")
+	}
+	return changed
+}
+
+// waitDeploymentRolledOut waits until all the replicas of a Deployment are updated and available.
+func waitDeploymentRolledOut(ctx context.Context, c client.Client, deployment *appsv1.Deployment, timeout time.Duration) error {
+	return wait.PollUntilContextTimeout(ctx, 100*time.Millisecond, timeout, true, func(ctx context.Context) (bool, error) {
+		d := &appsv1.Deployment{}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(deployment), d); err != nil {
+			return false, err
+		}
+		replicas := int32(1)
+		if d.Spec.Replicas != nil {
+			replicas = *d.Spec.Replicas
+		}
+		return d.Status.ObservedGeneration >= d.Generation &&
+			d.Status.UpdatedReplicas == replicas &&
+			d.Status.AvailableReplicas == replicas &&
+			d.Status.Replicas == replicas, nil
+	})
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+func fakeClientForFeatureGates() (*fakeClient, *fakeClusterClient) {
+	core := config.NewProvider("cluster-api", "https://somewhere.com", clusterctlv1.CoreProviderType)
+	bootstrap := config.NewProvider("kubeadm", "https://somewhere.com", clusterctlv1.BootstrapProviderType)
+
+	deployment := func(namespace, name, provider string, args ...string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      name,
+				Labels:    map[string]string{clusterv1.ProviderNameLabel: provider},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "manager", Args: append([]string{"--leader-elect"}, args...)}},
+					},
+				},
+			},
+			Status: appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+		}
+	}
+
+	ctx := context.Background()
+
+	config1 := newFakeConfig(ctx).
+		WithProvider(core).
+		WithProvider(bootstrap)
+
+	cluster1 := newFakeCluster(cluster.Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}, config1).
+		WithProviderInventory(core.Name(), core.Type(), "v1.7.0", "capi-system").
+		WithProviderInventory(bootstrap.Name(), bootstrap.Type(), "v1.7.0", "capi-kubeadm-bootstrap-system").
+		WithObjs(
+			deployment("capi-system", "capi-controller-manager", "cluster-api", "--feature-gates=MachinePool=true,ClusterTopology=false"),
+			deployment("capi-kubeadm-bootstrap-system", "capi-kubeadm-bootstrap-controller-manager", "bootstrap-kubeadm", "--feature-gates=MachinePool=true,KubeadmBootstrapFormatIgnition=false"),
+		)
+
+	client := newFakeClient(ctx, config1).
+		WithCluster(cluster1)
+
+	return client, cluster1
+}
+
+func Test_clusterctlClient_GetFeatureGates(t *testing.T) {
+	g := NewWithT(t)
+
+	c, _ := fakeClientForFeatureGates()
+	got, err := c.GetFeatureGates(context.Background(), GetFeatureGatesOptions{Kubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(HaveLen(2))
+	g.Expect(got[0].Provider.Name).To(Equal("cluster-api"))
+	g.Expect(got[0].FeatureGates).To(Equal([]FeatureGate{{Name: "ClusterTopology", Enabled: false}, {Name: "MachinePool", Enabled: true}}))
+	g.Expect(got[1].Provider.Name).To(Equal("bootstrap-kubeadm"))
+	g.Expect(got[1].FeatureGates).To(Equal([]FeatureGate{{Name: "KubeadmBootstrapFormatIgnition", Enabled: false}, {Name: "MachinePool", Enabled: true}}))
+}
+
+func Test_clusterctlClient_SetFeatureGates(t *testing.T) {
+	kubeconfig := Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}
+
+	getArgs := func(g *WithT, cluster *fakeClusterClient, namespace, name string) []string {
+		c, err := cluster.Proxy().NewClient(context.Background())
+		g.Expect(err).ToNot(HaveOccurred())
+		d := &appsv1.Deployment{}
+		g.Expect(c.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: name}, d)).To(Succeed())
+		return d.Spec.Template.Spec.Containers[0].Args
+	}
+
+	tests := []struct {
+		name            string
+		options         SetFeatureGatesOptions
+		wantErr         bool
+		wantCoreArgs    []string
+		wantKubeadmArgs []string
+	}{
+		{
+			name:            "set a feature gate on all the providers supporting it",
+			options:         SetFeatureGatesOptions{FeatureGates: map[string]bool{"MachinePool": false}},
+			wantCoreArgs:    []string{"--leader-elect", "--feature-gates=MachinePool=false,ClusterTopology=false"},
+			wantKubeadmArgs: []string{"--leader-elect", "--feature-gates=MachinePool=false,KubeadmBootstrapFormatIgnition=false"},
+		},
+		{
+			name:            "set a feature gate on a specific provider",
+			options:         SetFeatureGatesOptions{Providers: []string{"bootstrap-kubeadm"}, FeatureGates: map[string]bool{"MachinePool": false}},
+			wantCoreArgs:    []string{"--leader-elect", "--feature-gates=MachinePool=true,ClusterTopology=false"},
+			wantKubeadmArgs: []string{"--leader-elect", "--feature-gates=MachinePool=false,KubeadmBootstrapFormatIgnition=false"},
+		},
+		{
+			name:    "fails for a feature gate not supported by the selected providers",
+			options: SetFeatureGatesOptions{Providers: []string{"bootstrap-kubeadm"}, FeatureGates: map[string]bool{"ClusterTopology": true}},
+			wantErr: true,
+		},
+		{
+			name:    "fails for a provider not installed",
+			options: SetFeatureGatesOptions{Providers: []string{"infrastructure-docker"}, FeatureGates: map[string]bool{"MachinePool": true}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c, cluster := fakeClientForFeatureGates()
+			tt.options.Kubeconfig = kubeconfig
+			tt.options.WaitProviderTimeout = time.Second
+
+			err := c.SetFeatureGates(context.Background(), tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				// No Deployment is changed if validation fails.
+				g.Expect(getArgs(g, cluster, "capi-system", "capi-controller-manager")).To(ContainElement("--feature-gates=MachinePool=true,ClusterTopology=false"))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(getArgs(g, cluster, "capi-system", "capi-controller-manager")).To(Equal(tt.wantCoreArgs))
+			g.Expect(getArgs(g, cluster, "capi-kubeadm-bootstrap-system", "capi-kubeadm-bootstrap-controller-manager")).To(Equal(tt.wantKubeadmArgs))
+		})
+	}
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	"sigs.k8s.io/cluster-api/internal/contract"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// GetClustersOptions carries the options supported by GetClusters.
+type GetClustersOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Namespace where the workload clusters are located. If unspecified, the clusters in all the namespaces are returned.
+	Namespace string
+}
+
+// ReplicasSummary reports the desired and the ready replicas of a set of machines.
+type ReplicasSummary struct {
+	Desired int32 `json:"desired"`
+	Ready   int32 `json:"ready"`
+}
+
+// ClusterSummary reports the status of a workload cluster.
+type ClusterSummary struct {
+	Namespace         string      `json:"namespace"`
+	Name              string      `json:"name"`
+	CreationTimestamp metav1.Time `json:"creationTimestamp"`
+	Phase             string      `json:"phase"`
+
+	// ClusterClass is the name of the ClusterClass of the cluster, if the cluster uses a managed topology.
+	ClusterClass string `json:"clusterClass,omitempty"`
+
+	// KubernetesVersion is the version of the topology, or the version of the control plane.
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+
+	// ControlPlane reports the replicas of the control plane.
+	ControlPlane ReplicasSummary `json:"controlPlane"`
+
+	// Workers reports the replicas of the MachineDeployments and of the MachinePools of the cluster.
+	Workers ReplicasSummary `json:"workers"`
+
+	// ControlPlaneRef and InfrastructureRef are the references to the control plane and to the infrastructure of the cluster.
+	ControlPlaneRef   *corev1.ObjectReference `json:"controlPlaneRef,omitempty"`
+	InfrastructureRef *corev1.ObjectReference `json:"infrastructureRef,omitempty"`
+
+	// FailingCondition is the condition of the cluster with status False and the highest severity, if any;
+	// the Ready condition is reported only if there are no other conditions with status False.
+	FailingCondition *clusterv1.Condition `json:"failingCondition,omitempty"`
+}
+
+// GetClusters returns the summary of the status of the workload clusters in the management cluster, sorted by namespace and name.
+func (c *clusterctlClient) GetClusters(ctx context.Context, options GetClustersOptions) ([]ClusterSummary, error) {
+	// gets access to the management cluster
+	cluster, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure this command only runs against management clusters with the current Cluster API contract.
+	if err := cluster.ProviderInventory().CheckCAPIContract(ctx); err != nil {
+		return nil, err
+	}
+
+	mgmtClient, err := cluster.Proxy().NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := &clusterv1.ClusterList{}
+	if err := mgmtClient.List(ctx, clusters, client.InNamespace(options.Namespace)); err != nil {
+		return nil, errors.Wrap(err, "failed to list Clusters")
+	}
+
+	ret := make([]ClusterSummary, 0, len(clusters.Items))
+	for i := range clusters.Items {
+		summary, err := getClusterSummary(ctx, mgmtClient, &clusters.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, summary)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Namespace != ret[j].Namespace {
+			return ret[i].Namespace < ret[j].Namespace
+		}
+		return ret[i].Name < ret[j].Name
+	})
+	return ret, nil
+}
+
+func getClusterSummary(ctx context.Context, c client.Client, cluster *clusterv1.Cluster) (ClusterSummary, error) {
+	summary := ClusterSummary{
+		Namespace:         cluster.Namespace,
+		Name:              cluster.Name,
+		CreationTimestamp: cluster.CreationTimestamp,
+		Phase:             cluster.Status.Phase,
+		ControlPlaneRef:   cluster.Spec.ControlPlaneRef,
+		InfrastructureRef: cluster.Spec.InfrastructureRef,
+		FailingCondition:  topFailingCondition(cluster),
+	}
+	if cluster.Spec.Topology != nil {
+		summary.ClusterClass = cluster.Spec.Topology.Class
+		summary.KubernetesVersion = cluster.Spec.Topology.Version
+	}
+
+	if err := setControlPlaneSummary(ctx, c, cluster, &summary); err != nil {
+		return ClusterSummary{}, err
+	}
+	if err := setWorkersSummary(ctx, c, cluster, &summary); err != nil {
+		return ClusterSummary{}, err
+	}
+	return summary, nil
+}
+
+// setControlPlaneSummary sets the control plane replicas, and the Kubernetes version if not defined by the topology,
+// reading the control plane object; for clusters without a control plane object, the control plane Machines are counted.
+func setControlPlaneSummary(ctx context.Context, c client.Client, cluster *clusterv1.Cluster, summary *ClusterSummary) error {
+	if cluster.Spec.ControlPlaneRef == nil {
+		machines := &clusterv1.MachineList{}
+		if err := c.List(ctx, machines, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: cluster.Name}, client.HasLabels{clusterv1.MachineControlPlaneLabel}); err != nil {
+			return errors.Wrapf(err, "failed to list control plane Machines for Cluster %s/%s", cluster.Namespace, cluster.Name)
+		}
+		for i := range machines.Items {
+			summary.ControlPlane.Desired++
+			if conditions.IsTrue(&machines.Items[i], clusterv1.ReadyCondition) {
+				summary.ControlPlane.Ready++
+			}
+		}
+		return nil
+	}
+
+	ref := cluster.Spec.ControlPlaneRef
+	controlPlane := &unstructured.Unstructured{}
+	controlPlane.SetAPIVersion(ref.APIVersion)
+	controlPlane.SetKind(ref.Kind)
+	if err := c.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: ref.Name}, controlPlane); err != nil {
+		// The control plane could be not yet created, or its CRD not yet installed.
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get %s %s/%s for Cluster %s/%s", ref.Kind, cluster.Namespace, ref.Name, cluster.Namespace, cluster.Name)
+	}
+
+	if replicas, err := contract.ControlPlane().Replicas().Get(controlPlane); err == nil {
+		summary.ControlPlane.Desired = int32(*replicas)
+	}
+	if readyReplicas, err := contract.ControlPlane().ReadyReplicas().Get(controlPlane); err == nil {
+		summary.ControlPlane.Ready = int32(*readyReplicas)
+	}
+	if summary.KubernetesVersion == "" {
+		if version, err := contract.ControlPlane().Version().Get(controlPlane); err == nil {
+			summary.KubernetesVersion = *version
+		}
+	}
+	return nil
+}
+
+// setWorkersSummary sets the workers replicas, summing the replicas of the MachineDeployments and of the MachinePools of the cluster.
+func setWorkersSummary(ctx context.Context, c client.Client, cluster *clusterv1.Cluster, summary *ClusterSummary) error {
+	machineDeployments := &clusterv1.MachineDeploymentList{}
+	if err := c.List(ctx, machineDeployments, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: cluster.Name}); err != nil {
+		return errors.Wrapf(err, "failed to list MachineDeployments for Cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+	for _, md := range machineDeployments.Items {
+		if md.Spec.Replicas != nil {
+			summary.Workers.Desired += *md.Spec.Replicas
+		}
+		summary.Workers.Ready += md.Status.ReadyReplicas
+	}
+
+	// MachinePools are optional, the CRD is not installed if the feature is disabled.
+	machinePools := &expv1.MachinePoolList{}
+	if err := c.List(ctx, machinePools, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: cluster.Name}); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to list MachinePools for Cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+	for _, mp := range machinePools.Items {
+		if mp.Spec.Replicas != nil {
+			summary.Workers.Desired += *mp.Spec.Replicas
+		}
+		summary.Workers.Ready += mp.Status.ReadyReplicas
+	}
+	return nil
+}
+
+// topFailingCondition returns the condition with status False and the highest severity, preferring the conditions
+// other than Ready, which summarizes them.
+func topFailingCondition(cluster *clusterv1.Cluster) *clusterv1.Condition {
+	severityOrder := map[clusterv1.ConditionSeverity]int{
+		clusterv1.ConditionSeverityError:   3,
+		clusterv1.ConditionSeverityWarning: 2,
+		clusterv1.ConditionSeverityInfo:    1,
+	}
+
+	var top *clusterv1.Condition
+	for i := range cluster.Status.Conditions {
+		condition := &cluster.Status.Conditions[i]
+		if condition.Status != corev1.ConditionFalse || condition.Type == clusterv1.ReadyCondition {
+			continue
+		}
+		if top == nil || severityOrder[condition.Severity] > severityOrder[top.Severity] {
+			top = condition
+		}
+	}
+	if top == nil {
+		if ready := conditions.Get(cluster, clusterv1.ReadyCondition); ready != nil && ready.Status == corev1.ConditionFalse {
+			top = ready
+		}
+	}
+	if top == nil {
+		return nil
+	}
+	return top.DeepCopy()
+}
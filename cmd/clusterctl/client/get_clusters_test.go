@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+)
+
+func Test_clusterctlClient_GetClusters(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx := context.Background()
+
+	core := config.NewProvider("cluster-api", "https://somewhere.com", clusterctlv1.CoreProviderType)
+	config1 := newFakeConfig(ctx).WithProvider(core)
+
+	topologyCluster := &clusterv1.Cluster{
+		TypeMeta:   metav1.TypeMeta{Kind: "Cluster", APIVersion: clusterv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "cluster1"},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneRef: &corev1.ObjectReference{Kind: "KubeadmControlPlane", APIVersion: controlplanev1.GroupVersion.String(), Name: "cluster1-cp"},
+			Topology:        &clusterv1.Topology{Class: "quick-start", Version: "v1.29.0"},
+		},
+		Status: clusterv1.ClusterStatus{
+			Phase: string(clusterv1.ClusterPhaseProvisioned),
+			Conditions: clusterv1.Conditions{
+				{Type: clusterv1.ReadyCondition, Status: corev1.ConditionFalse, Severity: clusterv1.ConditionSeverityWarning, Reason: "ScalingUp"},
+				{Type: clusterv1.InfrastructureReadyCondition, Status: corev1.ConditionTrue},
+				{Type: clusterv1.ControlPlaneReadyCondition, Status: corev1.ConditionFalse, Severity: clusterv1.ConditionSeverityInfo, Reason: "ScalingUp"},
+				{Type: clusterv1.TopologyReconciledCondition, Status: corev1.ConditionFalse, Severity: clusterv1.ConditionSeverityWarning, Reason: "ReconcileFailed", Message: "failed"},
+			},
+		},
+	}
+	controlPlane := &controlplanev1.KubeadmControlPlane{
+		TypeMeta:   metav1.TypeMeta{Kind: "KubeadmControlPlane", APIVersion: controlplanev1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "cluster1-cp"},
+		Spec:       controlplanev1.KubeadmControlPlaneSpec{Replicas: ptr.To[int32](3), Version: "v1.28.0"},
+		Status:     controlplanev1.KubeadmControlPlaneStatus{ReadyReplicas: 2},
+	}
+	machineDeployment := func(name string, replicas, readyReplicas int32) *clusterv1.MachineDeployment {
+		return &clusterv1.MachineDeployment{
+			TypeMeta:   metav1.TypeMeta{Kind: "MachineDeployment", APIVersion: clusterv1.GroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: name, Labels: map[string]string{clusterv1.ClusterNameLabel: "cluster1"}},
+			Spec:       clusterv1.MachineDeploymentSpec{ClusterName: "cluster1", Replicas: ptr.To(replicas)},
+			Status:     clusterv1.MachineDeploymentStatus{ReadyReplicas: readyReplicas},
+		}
+	}
+
+	// A cluster without a control plane object, and without failing conditions.
+	machinesCluster := &clusterv1.Cluster{
+		TypeMeta:   metav1.TypeMeta{Kind: "Cluster", APIVersion: clusterv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "cluster2"},
+		Status: clusterv1.ClusterStatus{
+			Phase:      string(clusterv1.ClusterPhaseProvisioned),
+			Conditions: clusterv1.Conditions{{Type: clusterv1.ReadyCondition, Status: corev1.ConditionTrue}},
+		},
+	}
+	controlPlaneMachine := &clusterv1.Machine{
+		TypeMeta: metav1.TypeMeta{Kind: "Machine", APIVersion: clusterv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "cluster2-cp", Labels: map[string]string{
+			clusterv1.ClusterNameLabel:         "cluster2",
+			clusterv1.MachineControlPlaneLabel: "",
+		}},
+		Spec:   clusterv1.MachineSpec{ClusterName: "cluster2"},
+		Status: clusterv1.MachineStatus{Conditions: clusterv1.Conditions{{Type: clusterv1.ReadyCondition, Status: corev1.ConditionTrue}}},
+	}
+
+	cluster1 := newFakeCluster(cluster.Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}, config1).
+		WithProviderInventory(core.Name(), core.Type(), "v1.7.0", "capi-system").
+		WithObjs(
+			&apiextensionsv1.CustomResourceDefinition{
+				TypeMeta: metav1.TypeMeta{Kind: "CustomResourceDefinition", APIVersion: apiextensionsv1.SchemeGroupVersion.String()},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   fmt.Sprintf("clusters.%s", clusterv1.GroupVersion.Group),
+					Labels: map[string]string{clusterv1.GroupVersion.String(): "v1beta1"},
+				},
+				Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+					Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Storage: true, Name: clusterv1.GroupVersion.Version}},
+				},
+			},
+			topologyCluster,
+			controlPlane,
+			machineDeployment("cluster1-md-0", 2, 2),
+			machineDeployment("cluster1-md-1", 3, 1),
+			machinesCluster,
+			controlPlaneMachine,
+		)
+	c := newFakeClient(ctx, config1).WithCluster(cluster1)
+
+	got, err := c.GetClusters(ctx, GetClustersOptions{Kubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(HaveLen(2))
+
+	g.Expect(got[0].Namespace).To(Equal("ns1"))
+	g.Expect(got[0].Name).To(Equal("cluster2"))
+	g.Expect(got[0].ControlPlane).To(Equal(ReplicasSummary{Desired: 1, Ready: 1}))
+	g.Expect(got[0].Workers).To(Equal(ReplicasSummary{}))
+	g.Expect(got[0].FailingCondition).To(BeNil())
+
+	g.Expect(got[1].Namespace).To(Equal("ns2"))
+	g.Expect(got[1].Name).To(Equal("cluster1"))
+	g.Expect(got[1].Phase).To(Equal("Provisioned"))
+	g.Expect(got[1].ClusterClass).To(Equal("quick-start"))
+	g.Expect(got[1].KubernetesVersion).To(Equal("v1.29.0"))
+	g.Expect(got[1].ControlPlane).To(Equal(ReplicasSummary{Desired: 3, Ready: 2}))
+	g.Expect(got[1].Workers).To(Equal(ReplicasSummary{Desired: 5, Ready: 3}))
+	g.Expect(got[1].FailingCondition).ToNot(BeNil())
+	g.Expect(got[1].FailingCondition.Type).To(Equal(clusterv1.TopologyReconciledCondition))
+
+	got, err = c.GetClusters(ctx, GetClustersOptions{Kubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}, Namespace: "ns1"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(HaveLen(1))
+	g.Expect(got[0].Name).To(Equal("cluster2"))
+}
@@ -20,6 +20,8 @@ import (
 	"context"
 
 	"github.com/pkg/errors"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 )
 
 // GetKubeconfigOptions carries all the options supported by GetKubeconfig.
@@ -33,9 +35,17 @@ type GetKubeconfigOptions struct {
 
 	// WorkloadClusterName is the name of the workload cluster.
 	WorkloadClusterName string
+
+	// Auth defines how the kubeconfig authenticates to the workload cluster, e.g. using an exec credential plugin
+	// or OIDC, instead of the admin client certificate generated by Cluster API.
+	Auth cluster.KubeconfigAuth
 }
 
 func (c *clusterctlClient) GetKubeconfig(ctx context.Context, options GetKubeconfigOptions) (string, error) {
+	if err := options.Auth.Validate(); err != nil {
+		return "", err
+	}
+
 	// gets access to the management cluster
 	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
 	if err != nil {
@@ -58,5 +68,5 @@ func (c *clusterctlClient) GetKubeconfig(ctx context.Context, options GetKubecon
 		options.Namespace = currentNamespace
 	}
 
-	return clusterClient.WorkloadCluster().GetKubeconfig(ctx, options.WorkloadClusterName, options.Namespace)
+	return clusterClient.WorkloadCluster().GetKubeconfigWithAuth(ctx, options.WorkloadClusterName, options.Namespace, options.Auth)
 }
@@ -54,6 +54,16 @@ func Test_clusterctlClient_GetKubeconfig(t *testing.T) {
 			options:   GetKubeconfigOptions{Kubeconfig: Kubeconfig(kubeconfig)},
 			expectErr: true,
 		},
+		{
+			name:   "returns error if the authentication is invalid",
+			client: badClient,
+			options: GetKubeconfigOptions{
+				Kubeconfig: Kubeconfig(kubeconfig),
+				Namespace:  "default",
+				Auth:       cluster.KubeconfigAuth{Exec: &cluster.KubeconfigExecAuth{}},
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, tt := range tests {
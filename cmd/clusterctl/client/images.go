@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"strings"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+)
+
+// ResolveImagesOptions carries the options supported by ResolveImages.
+type ResolveImagesOptions struct {
+	// Images to resolve, e.g. the images returned by InitImages.
+	Images []string
+}
+
+// ResolvedImage is a container image with the digest its tag resolves to.
+type ResolvedImage struct {
+	// Image is the container image, as referenced in the provider components.
+	Image string `json:"image"`
+
+	// Digest is the digest of the image manifest, or of the image index for multi-arch images.
+	Digest string `json:"digest,omitempty"`
+}
+
+// PinnedImage returns the image pinned to its digest.
+func (i ResolvedImage) PinnedImage() string {
+	if strings.Contains(i.Image, "@") {
+		return i.Image
+	}
+	return i.Image + "@" + i.Digest
+}
+
+func (c *clusterctlClient) ResolveImages(ctx context.Context, options ResolveImagesOptions) ([]ResolvedImage, error) {
+	resolved := make([]ResolvedImage, 0, len(options.Images))
+	for _, image := range options.Images {
+		digest, err := repository.ResolveImageDigest(ctx, c.configClient, image)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, ResolvedImage{Image: image, Digest: digest})
+	}
+	return resolved, nil
+}
@@ -76,6 +76,13 @@ type InitOptions struct {
 	// WaitProviderTimeout sets the timeout per provider wait installation
 	WaitProviderTimeout time.Duration
 
+	// WaitCRDsTimeout, WaitDeploymentsTimeout and WaitWebhooksTimeout set the timeouts for the CRDs of the providers
+	// to be established, for the provider Deployments to be available and for the provider webhooks to be reachable;
+	// if a timeout is not set, WaitProviderTimeout is used.
+	WaitCRDsTimeout        time.Duration
+	WaitDeploymentsTimeout time.Duration
+	WaitWebhooksTimeout    time.Duration
+
 	// SkipTemplateProcess allows for skipping the call to the template processor, including also variable replacement in the component YAML.
 	// NOTE this works only if the rawYaml is a valid yaml by itself, like e.g when using envsubst/the simple processor.
 	skipTemplateProcess bool
@@ -84,13 +91,21 @@ type InitOptions struct {
 	// NOTE this should only be used for development
 	IgnoreValidationErrors bool
 
+	// ExternalCertificates instructs clusterctl to use webhook serving certificates supplied in existing Secrets,
+	// with the names defined in the cert-manager Certificates of the providers, instead of installing cert-manager.
+	ExternalCertificates bool
+
+	// ExternalCABundle is the PEM encoded CA bundle set in the webhook configurations when using ExternalCertificates;
+	// if empty, the ca.crt key of the Secrets is used.
+	ExternalCABundle []byte
+
 	// allowMissingProviderCRD is used to allow for a missing provider CRD when listing images.
 	// It is set to false to enforce that provider CRD is available when performing the standard init operation.
 	allowMissingProviderCRD bool
 }
 
 // Init initializes a management cluster by adding the requested list of providers.
-func (c *clusterctlClient) Init(ctx context.Context, options InitOptions) ([]Components, error) {
+func (c *clusterctlClient) Init(ctx context.Context, options InitOptions) (_ []Components, retErr error) {
 	log := logf.Log
 
 	// Default WaitProviderTimeout as we cannot rely on defaulting in the CLI
@@ -105,6 +120,9 @@ func (c *clusterctlClient) Init(ctx context.Context, options InitOptions) ([]Com
 		return nil, err
 	}
 
+	audit := c.startAuditedOperation(ctx, clusterClient, cluster.InitOperation)
+	defer func() { audit.complete(ctx, retErr) }()
+
 	// ensure the custom resource definitions required by clusterctl are in place
 	if err := clusterClient.ProviderInventory().EnsureCustomResourceDefinitions(ctx); err != nil {
 		return nil, err
@@ -140,15 +158,27 @@ func (c *clusterctlClient) Init(ctx context.Context, options InitOptions) ([]Com
 		log.Error(err, "Ignoring validation errors")
 	}
 
-	// Before installing the providers, ensure the cert-manager Webhook is in place.
-	certManager := clusterClient.CertManager()
-	if err := certManager.EnsureInstalled(ctx); err != nil {
-		return nil, err
+	// Before installing the providers, ensure the cert-manager Webhook is in place, unless the webhook serving
+	// certificates are supplied in existing Secrets.
+	if !options.ExternalCertificates {
+		certManager := clusterClient.CertManager()
+		if err := certManager.EnsureInstalled(ctx); err != nil {
+			return nil, err
+		}
 	}
 
 	installOpts := cluster.InstallOptions{
 		WaitProviders:       options.WaitProviders,
 		WaitProviderTimeout: options.WaitProviderTimeout,
+		ReadinessTimeouts: cluster.ProviderReadinessTimeouts{
+			CRDs:        options.WaitCRDsTimeout,
+			Deployments: options.WaitDeploymentsTimeout,
+			Webhooks:    options.WaitWebhooksTimeout,
+		},
+		ExternalCertificates: cluster.ExternalCertificatesOptions{
+			Enabled:  options.ExternalCertificates,
+			CABundle: options.ExternalCABundle,
+		},
 	}
 	components, err := installer.Install(ctx, installOpts)
 	if err != nil {
@@ -204,11 +234,14 @@ func (c *clusterctlClient) InitImages(ctx context.Context, options InitOptions)
 		return nil, err
 	}
 
-	// Gets the list of container images required for the cert-manager (if not already installed).
-	certManager := clusterClient.CertManager()
-	images, err := certManager.Images(ctx)
-	if err != nil {
-		return nil, err
+	// Gets the list of container images required for the cert-manager (if not already installed and if required).
+	images := []string{}
+	if !options.ExternalCertificates {
+		certManager := clusterClient.CertManager()
+		images, err = certManager.Images(ctx)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Appends the list of container images required for the selected providers.
@@ -21,6 +21,7 @@ import (
 	"os"
 
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 )
@@ -39,6 +40,21 @@ type MoveOptions struct {
 	// namespace will be used.
 	Namespace string
 
+	// Cluster is the name of a single Cluster to hand over to the target management cluster. If specified, only the
+	// Cluster and the objects it depends on are moved, and the Cluster is paused only for the time required to sync the
+	// changes happened after copying its objects to the target management cluster.
+	Cluster string
+
+	// Selector is a label selector for the Clusters to move to the target management cluster, e.g. "tenant=foo".
+	// If specified, only the Clusters matching the selector and the objects they depend on are moved, leaving
+	// the other Clusters in the namespace untouched.
+	Selector string
+
+	// NamespaceMapping maps source namespaces to target namespaces, e.g. {"tenant-a": "prod-tenant-a"}; objects in the
+	// source namespaces are moved to the target namespaces, rewriting the namespace of the object references as well.
+	// It applies to moves to a target management cluster, to a directory, and from a directory.
+	NamespaceMapping map[string]string
+
 	// ExperimentalResourceMutatorFn accepts any number of resource mutator functions that are applied on all resources being moved.
 	// This is an experimental feature and is exposed only from the library and not (yet) through the CLI.
 	ExperimentalResourceMutators []cluster.ResourceMutatorFunc
@@ -47,9 +63,23 @@ type MoveOptions struct {
 	FromDirectory string
 
 	// ToDirectory save configuration to directory.
+	// If the directory contains a previous backup, only the objects changed since then are written again.
 	ToDirectory string
 
-	// DryRun means the move action is a dry run, no real action will be performed.
+	// BackupEncryptionKey is a 32 bytes key used for encrypting the data of Secrets when saving to ToDirectory, and for
+	// decrypting it when applying from FromDirectory. If empty, Secrets are saved in plain text.
+	BackupEncryptionKey []byte
+
+	// VerifyBackup requires the backup in FromDirectory to be verified against its manifest before applying any object.
+	VerifyBackup bool
+
+	// CheckpointFile is the path of a file recording the progress of the move. If the file exists, the move interrupted
+	// when writing it is resumed without creating again the objects already moved; the file is removed when the move completes.
+	CheckpointFile string
+
+	// DryRun means the move action is a dry run, no real action will be performed; the objects that would be paused,
+	// moved and mutated are reported, and an error is returned if any blocker is detected.
+	// If ToKubeconfig is set, the target management cluster is checked for blockers as well, e.g. missing CRDs.
 	DryRun bool
 }
 
@@ -66,6 +96,35 @@ func (c *clusterctlClient) Move(ctx context.Context, options MoveOptions) error
 		return errors.Errorf("at least one of FromDirectory, ToDirectory and ToKubeconfig must be set")
 	}
 
+	if options.Cluster != "" && (options.FromDirectory != "" || options.ToDirectory != "") {
+		return errors.Errorf("can't set Cluster together with FromDirectory or ToDirectory")
+	}
+
+	if options.Selector != "" && (options.Cluster != "" || options.FromDirectory != "" || options.ToDirectory != "") {
+		return errors.Errorf("can't set Selector together with Cluster, FromDirectory or ToDirectory")
+	}
+
+	if len(options.BackupEncryptionKey) > 0 && options.FromDirectory == "" && options.ToDirectory == "" {
+		return errors.Errorf("can't set BackupEncryptionKey without FromDirectory or ToDirectory")
+	}
+
+	if options.VerifyBackup && options.FromDirectory == "" {
+		return errors.Errorf("can't set VerifyBackup without FromDirectory")
+	}
+
+	if options.CheckpointFile != "" && (options.DryRun || options.Cluster != "" || options.FromDirectory != "" || options.ToDirectory != "") {
+		return errors.Errorf("can't set CheckpointFile together with DryRun, Cluster, FromDirectory or ToDirectory")
+	}
+
+	if len(options.NamespaceMapping) > 0 {
+		if err := cluster.ValidateNamespaceMapping(options.NamespaceMapping); err != nil {
+			return err
+		}
+		mutators := make([]cluster.ResourceMutatorFunc, 0, len(options.ExperimentalResourceMutators)+1)
+		mutators = append(mutators, cluster.NamespaceMappingMutator(options.NamespaceMapping))
+		options.ExperimentalResourceMutators = append(mutators, options.ExperimentalResourceMutators...)
+	}
+
 	if options.ToDirectory != "" {
 		return c.toDirectory(ctx, options)
 	} else if options.FromDirectory != "" {
@@ -75,7 +134,7 @@ func (c *clusterctlClient) Move(ctx context.Context, options MoveOptions) error
 	return c.move(ctx, options)
 }
 
-func (c *clusterctlClient) move(ctx context.Context, options MoveOptions) error {
+func (c *clusterctlClient) move(ctx context.Context, options MoveOptions) (retErr error) {
 	// Get the client for interacting with the source management cluster.
 	fromCluster, err := c.getClusterClient(ctx, options.FromKubeconfig)
 	if err != nil {
@@ -92,27 +151,62 @@ func (c *clusterctlClient) move(ctx context.Context, options MoveOptions) error
 	}
 
 	var toCluster cluster.Client
+	if options.DryRun && options.ToKubeconfig != (Kubeconfig{}) {
+		// Get the client for checking the target management cluster; nothing is recorded in dry-run mode.
+		if toCluster, err = c.getClusterClient(ctx, options.ToKubeconfig); err != nil {
+			return err
+		}
+	}
 	if !options.DryRun {
 		// Get the client for interacting with the target management cluster.
 		if toCluster, err = c.getClusterClient(ctx, options.ToKubeconfig); err != nil {
 			return err
 		}
+
+		// Record the move on both the source and the target management cluster.
+		fromAudit := c.startAuditedOperation(ctx, fromCluster, cluster.MoveOperation)
+		fromAudit.countObjects(ctx, options.Namespace)
+		defer func() { fromAudit.complete(ctx, retErr) }()
+		toAudit := c.startAuditedOperation(ctx, toCluster, cluster.MoveOperation)
+		if fromAudit != nil && toAudit != nil {
+			toAudit.record.Namespace = fromAudit.record.Namespace
+			toAudit.record.ObjectCounts = fromAudit.record.ObjectCounts
+		}
+		defer func() { toAudit.complete(ctx, retErr) }()
 	}
 
+	if options.Cluster != "" {
+		return fromCluster.ObjectMover().Handover(ctx, options.Namespace, options.Cluster, toCluster, options.DryRun, options.ExperimentalResourceMutators...)
+	}
+	selector := labels.Everything()
+	if options.Selector != "" {
+		if selector, err = labels.Parse(options.Selector); err != nil {
+			return errors.Wrapf(err, "invalid selector %q", options.Selector)
+		}
+	}
+	if options.CheckpointFile != "" {
+		return fromCluster.ObjectMover().MoveWithCheckpoint(ctx, options.Namespace, selector, toCluster, options.CheckpointFile, options.ExperimentalResourceMutators...)
+	}
+	if options.Selector != "" {
+		return fromCluster.ObjectMover().MoveSelected(ctx, options.Namespace, selector, toCluster, options.DryRun, options.ExperimentalResourceMutators...)
+	}
 	return fromCluster.ObjectMover().Move(ctx, options.Namespace, toCluster, options.DryRun, options.ExperimentalResourceMutators...)
 }
 
-func (c *clusterctlClient) fromDirectory(ctx context.Context, options MoveOptions) error {
+func (c *clusterctlClient) fromDirectory(ctx context.Context, options MoveOptions) (retErr error) {
 	toCluster, err := c.getClusterClient(ctx, options.ToKubeconfig)
 	if err != nil {
 		return err
 	}
 
+	audit := c.startAuditedOperation(ctx, toCluster, cluster.MoveOperation)
+	defer func() { audit.complete(ctx, retErr) }()
+
 	if _, err := os.Stat(options.FromDirectory); os.IsNotExist(err) {
 		return err
 	}
 
-	return toCluster.ObjectMover().FromDirectory(ctx, toCluster, options.FromDirectory)
+	return toCluster.ObjectMover().FromDirectory(ctx, toCluster, options.FromDirectory, options.backupOptions(), options.ExperimentalResourceMutators...)
 }
 
 func (c *clusterctlClient) toDirectory(ctx context.Context, options MoveOptions) error {
@@ -134,7 +228,7 @@ func (c *clusterctlClient) toDirectory(ctx context.Context, options MoveOptions)
 		return err
 	}
 
-	return fromCluster.ObjectMover().ToDirectory(ctx, options.Namespace, options.ToDirectory)
+	return fromCluster.ObjectMover().ToDirectory(ctx, options.Namespace, options.ToDirectory, options.backupOptions(), options.ExperimentalResourceMutators...)
 }
 
 func (c *clusterctlClient) getClusterClient(ctx context.Context, kubeconfig Kubeconfig) (cluster.Client, error) {
@@ -154,3 +248,10 @@ func (c *clusterctlClient) getClusterClient(ctx context.Context, kubeconfig Kube
 	}
 	return cluster, nil
 }
+
+func (o MoveOptions) backupOptions() cluster.BackupOptions {
+	return cluster.BackupOptions{
+		EncryptionKey: o.BackupEncryptionKey,
+		Verify:        o.VerifyBackup,
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/labels"
 
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
@@ -94,6 +95,62 @@ func Test_clusterctlClient_Move(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "does not return error if cluster client is found when handing over a Cluster",
+			fields: fields{
+				client: fakeClientForMove(),
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+					Cluster:        "foo",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "returns an error if VerifyBackup is set without FromDirectory",
+			fields: fields{
+				client: fakeClientForMove(),
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToDirectory:    "/var/cache/toDirectory",
+					VerifyBackup:   true,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "returns an error if BackupEncryptionKey is set without FromDirectory or ToDirectory",
+			fields: fields{
+				client: fakeClientForMove(),
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig:      Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToKubeconfig:        Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+					BackupEncryptionKey: []byte("0123456789abcdef0123456789abcdef"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "returns an error if both Cluster and ToDirectory are set",
+			fields: fields{
+				client: fakeClientForMove(),
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToDirectory:    "/var/cache/toDirectory",
+					Cluster:        "foo",
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "returns an error if neither FromDirectory, ToDirectory, or ToKubeconfig is set",
 			fields: fields{
@@ -109,6 +166,78 @@ func Test_clusterctlClient_Move(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "does not return an error if Selector is set",
+			fields: fields{
+				client: fakeClientForMove(),
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+					Selector:       "tenant=foo",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "returns an error if Selector is invalid",
+			fields: fields{
+				client: fakeClientForMove(),
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+					Selector:       "tenant in (",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "does not return an error if CheckpointFile is set",
+			fields: fields{
+				client: fakeClientForMove(),
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+					CheckpointFile: "move-checkpoint.json",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "returns an error if both CheckpointFile and Cluster are set",
+			fields: fields{
+				client: fakeClientForMove(),
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+					Cluster:        "foo",
+					CheckpointFile: "move-checkpoint.json",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "returns an error if both Selector and Cluster are set",
+			fields: fields{
+				client: fakeClientForMove(),
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+					Cluster:        "foo",
+					Selector:       "tenant=foo",
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "does not return an error if dryRun but neither FromDirectory, ToDirectory, or ToKubeconfig is set",
 			fields: fields{
@@ -305,13 +434,22 @@ type fakeObjectMover struct {
 	moveErr          error
 	toDirectoryErr   error
 	fromDirectoryErr error
+	handoverErr      error
 }
 
 func (f *fakeObjectMover) Move(_ context.Context, _ string, _ cluster.Client, _ bool, _ ...cluster.ResourceMutatorFunc) error {
 	return f.moveErr
 }
 
-func (f *fakeObjectMover) ToDirectory(_ context.Context, _ string, _ string) error {
+func (f *fakeObjectMover) MoveSelected(_ context.Context, _ string, _ labels.Selector, _ cluster.Client, _ bool, _ ...cluster.ResourceMutatorFunc) error {
+	return f.moveErr
+}
+
+func (f *fakeObjectMover) MoveWithCheckpoint(_ context.Context, _ string, _ labels.Selector, _ cluster.Client, _ string, _ ...cluster.ResourceMutatorFunc) error {
+	return f.moveErr
+}
+
+func (f *fakeObjectMover) ToDirectory(_ context.Context, _ string, _ string, _ cluster.BackupOptions, _ ...cluster.ResourceMutatorFunc) error {
 	return f.toDirectoryErr
 }
 
@@ -319,10 +457,14 @@ func (f *fakeObjectMover) Backup(_ context.Context, _ string, _ string) error {
 	return f.toDirectoryErr
 }
 
-func (f *fakeObjectMover) FromDirectory(_ context.Context, _ cluster.Client, _ string) error {
+func (f *fakeObjectMover) FromDirectory(_ context.Context, _ cluster.Client, _ string, _ cluster.BackupOptions, _ ...cluster.ResourceMutatorFunc) error {
 	return f.fromDirectoryErr
 }
 
 func (f *fakeObjectMover) Restore(_ context.Context, _ cluster.Client, _ string) error {
 	return f.fromDirectoryErr
 }
+
+func (f *fakeObjectMover) Handover(_ context.Context, _, _ string, _ cluster.Client, _ bool, _ ...cluster.ResourceMutatorFunc) error {
+	return f.handoverErr
+}
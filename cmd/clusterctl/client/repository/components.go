@@ -96,6 +96,9 @@ type components struct {
 	images          []string
 	targetNamespace string
 	objs            []unstructured.Unstructured
+
+	// inventoryAnnotations are added to the provider inventory entry, e.g. for recording the Helm chart of the provider.
+	inventoryAnnotations map[string]string
 }
 
 // ensure components implement Components.
@@ -127,9 +130,10 @@ func (c *components) InventoryObject() clusterctlv1.Provider {
 			Kind:       "Provider",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: c.targetNamespace,
-			Name:      c.ManifestLabel(),
-			Labels:    labels,
+			Namespace:   c.targetNamespace,
+			Name:        c.ManifestLabel(),
+			Labels:      labels,
+			Annotations: c.inventoryAnnotations,
 		},
 		ProviderName: c.Name(),
 		Type:         string(c.Type()),
@@ -181,6 +185,9 @@ type ComponentsInput struct {
 	Processor    yaml.Processor
 	RawYaml      []byte
 	Options      ComponentsOptions
+
+	// InventoryAnnotations are added to the provider inventory entry, e.g. for recording the Helm chart of the provider.
+	InventoryAnnotations map[string]string
 }
 
 // NewComponents returns a new objects embedding a component YAML file
@@ -215,8 +222,8 @@ func NewComponents(input ComponentsInput) (Components, error) {
 	}
 
 	// Apply image overrides, if defined
-	objs, err = util.FixImages(objs, func(image string) (string, error) {
-		return input.ConfigClient.ImageMeta().AlterImage(input.Provider.ManifestLabel(), image)
+	objs, err = util.FixImages(objs, func(containerName, image string) (string, error) {
+		return input.ConfigClient.ImageMeta().AlterContainerImage(input.Provider.ManifestLabel(), containerName, image)
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to apply image overrides")
@@ -260,12 +267,13 @@ func NewComponents(input ComponentsInput) (Components, error) {
 	objs = addCommonLabels(objs, input.Provider)
 
 	return &components{
-		Provider:        input.Provider,
-		version:         input.Options.Version,
-		variables:       variables,
-		images:          images,
-		targetNamespace: input.Options.TargetNamespace,
-		objs:            objs,
+		Provider:             input.Provider,
+		version:              input.Options.Version,
+		variables:            variables,
+		images:               images,
+		targetNamespace:      input.Options.TargetNamespace,
+		objs:                 objs,
+		inventoryAnnotations: input.InventoryAnnotations,
 	}, nil
 }
 
@@ -65,7 +65,17 @@ func (f *componentsClient) Get(ctx context.Context, options ComponentsOptions) (
 	if err != nil {
 		return nil, err
 	}
-	return NewComponents(ComponentsInput{f.provider, f.configClient, f.processor, file, options})
+	input := ComponentsInput{f.provider, f.configClient, f.processor, file, options, nil}
+	if r, ok := f.repository.(inventoryAnnotator); ok {
+		input.InventoryAnnotations = r.inventoryAnnotations(options.Version)
+	}
+	return NewComponents(input)
+}
+
+// inventoryAnnotator is implemented by repositories recording in the provider inventory where the components
+// come from, e.g. the Helm chart the components are rendered from.
+type inventoryAnnotator interface {
+	inventoryAnnotations(version string) map[string]string
 }
 
 func (f *componentsClient) getRawBytes(ctx context.Context, options *ComponentsOptions) ([]byte, error) {
@@ -0,0 +1,488 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/blang/semver/v4"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+const (
+	helmChartFile      = "Chart.yaml"
+	helmValuesFile     = "values.yaml"
+	helmTemplatesDir   = "templates"
+	helmCRDsDir        = "crds"
+	helmChartsDir      = "charts"
+	helmNotesFile      = "NOTES.txt"
+	helmMaxArchiveSize = 100 * 1024 * 1024
+	helmNoValue        = "<no value>"
+
+	// helmHookAnnotation is the annotation defining the hooks an object of a chart is created for.
+	helmHookAnnotation = "helm.sh/hook"
+
+	// helmCapabilitiesKubeVersionDefault and helmCapabilitiesHelmVersionDefault are the versions reported by the
+	// .Capabilities built-in object; like helm template, the components are rendered without connecting to the cluster.
+	helmCapabilitiesKubeVersionDefault = "v1.29.0"
+	helmCapabilitiesHelmVersionDefault = "v3.14.0"
+)
+
+// helmChart is a Helm chart read from a chart directory or from a chart archive.
+//
+// clusterctl renders charts without depending on Helm, supporting the features commonly used for packaging providers:
+// the .Values, .Release, .Chart, .Files, .Template, .Capabilities and .Subcharts built-in objects, the Sprig functions
+// and the include, tpl, required, toYaml, fromYaml, toJson and fromJson functions; CRDs in the crds folder are included
+// as they are. Charts with hooks or with dependencies are not supported: clusterctl applies all the objects of a chart
+// at once, so it can't honour the ordering and lifecycle semantics of hooks.
+type helmChart struct {
+	Metadata helmChartMetadata
+
+	// values are the default values of the chart.
+	values map[string]interface{}
+
+	// files are all the files of the chart, keyed by the path relative to the chart root.
+	files map[string][]byte
+}
+
+// helmChartMetadata is the subset of the Chart.yaml file used by clusterctl.
+type helmChartMetadata struct {
+	APIVersion   string            `json:"apiVersion"`
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	AppVersion   string            `json:"appVersion,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	Type         string            `json:"type,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	Dependencies []interface{}     `json:"dependencies,omitempty"`
+}
+
+// helmRelease is the .Release built-in object of a chart.
+type helmRelease struct {
+	Name      string
+	Namespace string
+	Service   string
+	IsInstall bool
+	IsUpgrade bool
+	Revision  int
+}
+
+// helmCapabilities is the .Capabilities built-in object of a chart.
+type helmCapabilities struct {
+	KubeVersion helmCapabilitiesKubeVersion
+	APIVersions helmCapabilitiesAPIVersions
+	HelmVersion helmCapabilitiesHelmVersion
+}
+
+// helmCapabilitiesKubeVersion is the .Capabilities.KubeVersion built-in object of a chart.
+type helmCapabilitiesKubeVersion struct {
+	Version string
+	Major   string
+	Minor   string
+}
+
+// String returns the Kubernetes version.
+func (v helmCapabilitiesKubeVersion) String() string {
+	return v.Version
+}
+
+// GitVersion returns the Kubernetes version; it is deprecated in Helm but still used by charts.
+func (v helmCapabilitiesKubeVersion) GitVersion() string {
+	return v.Version
+}
+
+// helmCapabilitiesAPIVersions is the .Capabilities.APIVersions built-in object of a chart.
+type helmCapabilitiesAPIVersions []string
+
+// Has returns true if the API version, in the group/version or group/version/kind form, is available.
+func (v helmCapabilitiesAPIVersions) Has(apiVersion string) bool {
+	return slices.Contains(v, apiVersion)
+}
+
+// helmCapabilitiesHelmVersion is the .Capabilities.HelmVersion built-in object of a chart.
+type helmCapabilitiesHelmVersion struct {
+	Version string
+}
+
+// newHelmCapabilities returns the capabilities of the default cluster assumed by helm template, i.e. the
+// helmCapabilitiesKubeVersionDefault Kubernetes version serving the built-in Kubernetes API versions.
+func newHelmCapabilities() helmCapabilities {
+	apiVersions := sets.Set[string]{}
+	for gvk := range scheme.Scheme.AllKnownTypes() {
+		apiVersions.Insert(gvk.GroupVersion().String(), gvk.GroupVersion().String()+"/"+gvk.Kind)
+	}
+	version := semver.MustParse(strings.TrimPrefix(helmCapabilitiesKubeVersionDefault, "v"))
+	return helmCapabilities{
+		KubeVersion: helmCapabilitiesKubeVersion{
+			Version: helmCapabilitiesKubeVersionDefault,
+			Major:   strconv.FormatUint(version.Major, 10),
+			Minor:   strconv.FormatUint(version.Minor, 10),
+		},
+		APIVersions: sets.List(apiVersions),
+		HelmVersion: helmCapabilitiesHelmVersion{Version: helmCapabilitiesHelmVersionDefault},
+	}
+}
+
+// helmFiles is the .Files built-in object of a chart.
+type helmFiles map[string][]byte
+
+// Get returns the content of a file of the chart, or an empty string if the file does not exist.
+func (f helmFiles) Get(name string) string {
+	return string(f[name])
+}
+
+// GetBytes returns the content of a file of the chart, or nil if the file does not exist.
+func (f helmFiles) GetBytes(name string) []byte {
+	return f[name]
+}
+
+// loadHelmChartDirectory reads a chart from a directory.
+func loadHelmChartDirectory(dir string) (*helmChart, error) {
+	files := map[string][]byte{}
+	err := filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		relativePath, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(p) //nolint:gosec
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(relativePath)] = content
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read Helm chart %s", dir)
+	}
+	return newHelmChart(files)
+}
+
+// loadHelmChartArchive reads a chart from a gzipped tar archive, as created by helm package;
+// the files of the chart are expected to be in a single folder named after the chart.
+func loadHelmChartArchive(archive []byte) (*helmChart, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read Helm chart archive")
+	}
+	defer gz.Close()
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(io.LimitReader(gz, helmMaxArchiveSize))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read Helm chart archive")
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		_, relativePath, ok := strings.Cut(path.Clean(header.Name), "/")
+		if !ok || strings.HasPrefix(relativePath, "../") {
+			return nil, errors.Errorf("failed to read Helm chart archive: invalid file %q", header.Name)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read Helm chart archive: failed to read file %q", header.Name)
+		}
+		files[relativePath] = content
+	}
+	return newHelmChart(files)
+}
+
+func newHelmChart(files map[string][]byte) (*helmChart, error) {
+	chartFile, ok := files[helmChartFile]
+	if !ok {
+		return nil, errors.Errorf("invalid Helm chart: %s not found", helmChartFile)
+	}
+	c := &helmChart{
+		values: map[string]interface{}{},
+		files:  files,
+	}
+	if err := yaml.Unmarshal(chartFile, &c.Metadata); err != nil {
+		return nil, errors.Wrapf(err, "invalid Helm chart: failed to read %s", helmChartFile)
+	}
+	if c.Metadata.Name == "" || c.Metadata.Version == "" {
+		return nil, errors.Errorf("invalid Helm chart: %s must define the name and the version of the chart", helmChartFile)
+	}
+	if c.Metadata.Type == "library" {
+		return nil, errors.Errorf("invalid Helm chart %s: library charts can't be installed", c.Metadata.Name)
+	}
+	if len(c.Metadata.Dependencies) > 0 {
+		return nil, errors.Errorf("invalid Helm chart %s: charts with dependencies are not supported", c.Metadata.Name)
+	}
+	for p := range files {
+		if strings.HasPrefix(p, helmChartsDir+"/") {
+			return nil, errors.Errorf("invalid Helm chart %s: charts with dependencies are not supported", c.Metadata.Name)
+		}
+	}
+
+	if values, ok := files[helmValuesFile]; ok {
+		if err := yaml.Unmarshal(values, &c.values); err != nil {
+			return nil, errors.Wrapf(err, "invalid Helm chart %s: failed to read %s", c.Metadata.Name, helmValuesFile)
+		}
+		if c.values == nil {
+			c.values = map[string]interface{}{}
+		}
+	}
+	return c, nil
+}
+
+// render renders the chart for a release, using the given values on top of the default values of the chart.
+// The output is the YAML with the CRDs of the chart and the objects created by the templates.
+func (c *helmChart) render(release helmRelease, values map[string]interface{}) ([]byte, error) {
+	t := texttemplate.New(c.Metadata.Name).Option("missingkey=zero")
+	t.Funcs(helmFuncMap(t))
+
+	paths := make([]string, 0, len(c.files))
+	for p := range c.files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	// All the templates, including the partials, are parsed so they can be included by each other.
+	templates := []string{}
+	for _, p := range paths {
+		if !strings.HasPrefix(p, helmTemplatesDir+"/") {
+			continue
+		}
+		name := c.Metadata.Name + "/" + p
+		if _, err := t.New(name).Parse(string(c.files[p])); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse Helm chart template %s", name)
+		}
+		base := path.Base(p)
+		if strings.HasPrefix(base, "_") || base == helmNotesFile {
+			continue
+		}
+		templates = append(templates, name)
+	}
+
+	data := map[string]interface{}{
+		"Values":  mergeHelmValues(deepCopyHelmValues(c.values), values),
+		"Release": release,
+		"Chart":   c.Metadata,
+		"Files":   helmFiles(c.files),
+		// Charts with dependencies are not supported, so there are no subcharts.
+		"Subcharts":    map[string]interface{}{},
+		"Capabilities": newHelmCapabilities(),
+	}
+
+	docs := []string{}
+	for _, p := range paths {
+		if strings.HasPrefix(p, helmCRDsDir+"/") && (path.Ext(p) == ".yaml" || path.Ext(p) == ".yml") {
+			docs = append(docs, string(c.files[p]))
+		}
+	}
+	for _, name := range templates {
+		templateData := map[string]interface{}{}
+		for k, v := range data {
+			templateData[k] = v
+		}
+		templateData["Template"] = map[string]interface{}{
+			"Name":     name,
+			"BasePath": c.Metadata.Name + "/" + helmTemplatesDir,
+		}
+
+		var out strings.Builder
+		if err := t.ExecuteTemplate(&out, name, templateData); err != nil {
+			return nil, errors.Wrapf(err, "failed to render Helm chart template %s", name)
+		}
+		if rendered := strings.ReplaceAll(out.String(), helmNoValue, ""); strings.TrimSpace(rendered) != "" {
+			docs = append(docs, rendered)
+		}
+	}
+
+	// Parse the output, so invalid YAML is detected while rendering and empty documents are dropped.
+	objs, err := utilyaml.ToUnstructured([]byte(strings.Join(docs, "\n---\n")))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the output of Helm chart %s", c.Metadata.Name)
+	}
+
+	if err := checkHelmHooks(c.Metadata.Name, objs); err != nil {
+		return nil, err
+	}
+
+	// Charts usually rely on the release namespace instead of creating it, while a namespace is required in the components YAML.
+	if ns, err := inspectTargetNamespace(objs); err != nil {
+		return nil, err
+	} else if ns == "" {
+		objs = addNamespaceIfMissing(objs, release.Namespace)
+	}
+	return utilyaml.FromUnstructured(objs)
+}
+
+// checkHelmHooks returns an error if any of the objects of a chart is a hook.
+func checkHelmHooks(chartName string, objs []unstructured.Unstructured) error {
+	hooks := []string{}
+	for _, o := range objs {
+		if _, ok := o.GetAnnotations()[helmHookAnnotation]; ok {
+			hooks = append(hooks, fmt.Sprintf("%s %s", o.GetKind(), o.GetName()))
+		}
+	}
+	if len(hooks) > 0 {
+		return errors.Errorf("Helm chart %s uses hooks, which are not supported: %s", chartName, strings.Join(hooks, ", "))
+	}
+	return nil
+}
+
+// helmFuncMap returns the functions available in the templates of a chart:
+// the Sprig functions, except the functions reading the environment, and the functions added by Helm.
+func helmFuncMap(t *texttemplate.Template) texttemplate.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	delete(funcs, "env")
+	delete(funcs, "expandenv")
+
+	funcs["toYaml"] = func(v interface{}) string {
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSuffix(string(data), "\n")
+	}
+	funcs["fromYaml"] = func(s string) map[string]interface{} {
+		m := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(s), &m); err != nil {
+			m["Error"] = err.Error()
+		}
+		return m
+	}
+	funcs["toJson"] = func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+	funcs["fromJson"] = func(s string) map[string]interface{} {
+		m := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(s), &m); err != nil {
+			m["Error"] = err.Error()
+		}
+		return m
+	}
+	funcs["required"] = func(message string, v interface{}) (interface{}, error) {
+		if v == nil {
+			return nil, errors.New(message)
+		}
+		if s, ok := v.(string); ok && s == "" {
+			return nil, errors.New(message)
+		}
+		return v, nil
+	}
+	funcs["include"] = func(name string, data interface{}) (string, error) {
+		var out strings.Builder
+		if err := t.ExecuteTemplate(&out, name, data); err != nil {
+			return "", err
+		}
+		return out.String(), nil
+	}
+	funcs["tpl"] = func(text string, data interface{}) (string, error) {
+		tpl := texttemplate.New("tpl").Option("missingkey=zero").Funcs(helmFuncMap(t))
+		for _, named := range t.Templates() {
+			if _, err := tpl.AddParseTree(named.Name(), named.Tree); err != nil {
+				return "", err
+			}
+		}
+		if _, err := tpl.New("tpl").Parse(text); err != nil {
+			return "", err
+		}
+		var out strings.Builder
+		if err := tpl.ExecuteTemplate(&out, "tpl", data); err != nil {
+			return "", err
+		}
+		return strings.ReplaceAll(out.String(), helmNoValue, ""), nil
+	}
+	// lookup requires a connection to the cluster, which is not available when rendering the components; like
+	// helm template, an empty result is returned.
+	funcs["lookup"] = func(string, string, string, string) (map[string]interface{}, error) {
+		return map[string]interface{}{}, nil
+	}
+	return funcs
+}
+
+// readHelmValuesFile reads a values file.
+func readHelmValuesFile(p string) (map[string]interface{}, []byte, error) {
+	content, err := os.ReadFile(filepath.Clean(p))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read Helm values file %s", p)
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read Helm values file %s", p)
+	}
+	return values, content, nil
+}
+
+// mergeHelmValues merges src into dst, like Helm does with values files: maps are merged recursively,
+// any other value in src replaces the value in dst, and null values in src remove the key from dst.
+func mergeHelmValues(dst, src map[string]interface{}) map[string]interface{} {
+	for k, v := range src {
+		if v == nil {
+			delete(dst, k)
+			continue
+		}
+		srcMap, srcIsMap := v.(map[string]interface{})
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			dst[k] = mergeHelmValues(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+	return dst
+}
+
+func deepCopyHelmValues(values map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if m, ok := v.(map[string]interface{}); ok {
+			out[k] = deepCopyHelmValues(m)
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+const (
+	ociImageIndexMediaType         = "application/vnd.oci.image.index.v1+json"
+	ociDockerManifestListMediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+var ociCacheImageDigests = map[string]string{}
+
+// ResolveImageDigest returns the digest of a container image, by getting the manifest for the image tag from the registry.
+// For multi-arch images the digest of the image index is returned, so the digest applies to all the platforms.
+// If the image is already pinned to a digest, the digest is returned without contacting the registry.
+// The OCI username and password in the clusterctl configuration, if any, are used to authenticate only to the registries
+// of the OCI provider repositories in the clusterctl configuration, and only if requested by the registry; they are never
+// sent to other registries, e.g. to registry.k8s.io.
+func ResolveImageDigest(ctx context.Context, configClient config.Client, image string, opts ...ociRepositoryOption) (string, error) {
+	if configClient == nil {
+		return "", errors.New("invalid arguments: configClient can't be nil")
+	}
+
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse image %q", image)
+	}
+	if digested, ok := named.(reference.Digested); ok {
+		return digested.Digest().String(), nil
+	}
+	tagged, ok := reference.TagNameOnly(named).(reference.Tagged)
+	if !ok {
+		return "", errors.Errorf("failed to get the tag of image %q", image)
+	}
+
+	registry := reference.Domain(named)
+	if registry == ociDockerHubRegistry {
+		registry = ociDockerHubRegistryEndpoint
+	}
+
+	cacheID := fmt.Sprintf("%s/%s:%s", registry, reference.Path(named), tagged.Tag())
+	if digest, ok := ociCacheImageDigests[cacheID]; ok {
+		return digest, nil
+	}
+
+	r := &ociRepository{
+		httpClient: http.DefaultClient,
+		registry:   registry,
+		repository: reference.Path(named),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	ociRegistries, err := ociProviderRegistries(configClient)
+	if err != nil {
+		return "", err
+	}
+	if ociRegistries.Has(r.registry) {
+		if username, err := configClient.Variables().Get(config.OCIUsernameVariable); err == nil {
+			r.username = username
+		}
+		if password, err := configClient.Variables().Get(config.OCIPasswordVariable); err == nil {
+			r.password = password
+		}
+	}
+
+	accept := strings.Join([]string{ociImageIndexMediaType, ociDockerManifestListMediaType, ociManifestMediaType, ociDockerManifestMediaType}, ", ")
+	response, err := r.get(ctx, fmt.Sprintf("https://%s/v2/%s/manifests/%s", r.registry, r.repository, tagged.Tag()), accept)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve the digest of image %q", image)
+	}
+	defer response.Body.Close()
+
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read the manifest of image %q", image)
+	}
+
+	digest := ociDigest(content)
+	ociCacheImageDigests[cacheID] = digest
+	return digest, nil
+}
+
+// ociProviderRegistries returns the registries of the OCI provider repositories in the clusterctl configuration.
+func ociProviderRegistries(configClient config.Client) (sets.Set[string], error) {
+	providers, err := configClient.Providers().List()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the providers from the clusterctl configuration")
+	}
+	registries := sets.Set[string]{}
+	for _, provider := range providers {
+		u, err := url.Parse(provider.URL())
+		if err != nil || u.Scheme != ociScheme || u.Host == "" {
+			continue
+		}
+		registry := u.Host
+		if registry == ociDockerHubRegistry {
+			registry = ociDockerHubRegistryEndpoint
+		}
+		registries.Insert(registry)
+	}
+	return registries, nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_ResolveImageDigest(t *testing.T) {
+	ctx := context.Background()
+
+	registry := newFakeOCIRegistry(t)
+	digest := registry.push("v1.0.0", map[string]string{"image": "content"})
+
+	configClient, err := config.New(ctx, "", config.InjectReader(test.NewFakeReader()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("resolves the digest of a tag", func(t *testing.T) {
+		g := NewWithT(t)
+
+		got, err := ResolveImageDigest(ctx, configClient, fmt.Sprintf("%s/org/provider:v1.0.0", registry.host()), injectOCIHTTPClient(registry.server.Client()))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(got).To(Equal(digest))
+	})
+
+	t.Run("returns the digest of images already pinned to a digest", func(t *testing.T) {
+		g := NewWithT(t)
+
+		pinned := "sha256:0123456789012345678901234567890123456789012345678901234567890123"
+		got, err := ResolveImageDigest(ctx, configClient, fmt.Sprintf("%s/org/provider:v1.0.0@%s", registry.host(), pinned))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(got).To(Equal(pinned))
+	})
+
+	t.Run("fails for unknown tags", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := ResolveImageDigest(ctx, configClient, fmt.Sprintf("%s/org/provider:v2.0.0", registry.host()), injectOCIHTTPClient(registry.server.Client()))
+		g.Expect(err).To(MatchError(errNotFound))
+	})
+
+	t.Run("sends the credentials only to the registries of the OCI provider repositories, when requested", func(t *testing.T) {
+		g := NewWithT(t)
+
+		var authorizations []string
+		mux := http.NewServeMux()
+		mux.HandleFunc("/v2/org/image/manifests/v1.0.0", func(w http.ResponseWriter, req *http.Request) {
+			authorizations = append(authorizations, req.Header.Get("Authorization"))
+			if username, password, ok := req.BasicAuth(); !ok || username != "user" || password != "secret" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="fake"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte("manifest"))
+		})
+		server := httptest.NewTLSServer(mux)
+		defer server.Close()
+		host := strings.TrimPrefix(server.URL, "https://")
+
+		reader := test.NewFakeReader().
+			WithVar(config.OCIUsernameVariable, "user").
+			WithVar(config.OCIPasswordVariable, "secret")
+		otherRegistryConfigClient, err := config.New(ctx, "", config.InjectReader(reader))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		// The credentials are not sent to registries not used by OCI provider repositories.
+		_, err = ResolveImageDigest(ctx, otherRegistryConfigClient, fmt.Sprintf("%s/org/image:v1.0.0", host), injectOCIHTTPClient(server.Client()))
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(authorizations).To(Equal([]string{""}))
+
+		// The credentials are sent to the registries used by OCI provider repositories, only after the challenge.
+		authorizations = nil
+		reader = reader.WithProvider("oci-provider", clusterctlv1.InfrastructureProviderType, fmt.Sprintf("oci://%s/org/provider:v1.0.0/infrastructure-components.yaml", host))
+		ociRegistryConfigClient, err := config.New(ctx, "", config.InjectReader(reader))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		got, err := ResolveImageDigest(ctx, ociRegistryConfigClient, fmt.Sprintf("%s/org/image:v1.0.0", host), injectOCIHTTPClient(server.Client()))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(got).To(Equal(ociDigest([]byte("manifest"))))
+		g.Expect(authorizations).To(HaveLen(2))
+		g.Expect(authorizations[0]).To(BeEmpty())
+		g.Expect(authorizations[1]).To(HavePrefix("Basic "))
+	})
+}
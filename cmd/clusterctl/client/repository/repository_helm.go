@@ -0,0 +1,381 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/yaml"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+const (
+	helmScheme              = "helm"
+	helmOCIScheme           = "helm+oci"
+	helmComponentsPath      = "components.yaml"
+	helmChartLayerMediaType = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+
+	// helmChartContractAnnotation is the annotation of a chart reporting the Cluster API contract implemented by the provider;
+	// it is used when the chart does not contain a metadata.yaml file.
+	helmChartContractAnnotation = "cluster.x-k8s.io/contract"
+)
+
+// helmRepository provides support for providers packaged as a Helm chart, either stored on the local filesystem
+// as a chart directory or a chart archive, or pushed to an OCI registry with helm push:
+//
+//	helm:///{path-to-chart-directory-or-archive}[?values={path-to-values-file}&namespace={namespace}]
+//	helm+oci://{registry}/{repository}:{latest|version}[?values={path-to-values-file}&namespace={namespace}]
+//
+// The components YAML is rendered from the chart using the values file, if any, for a release named after the provider
+// and installed in the given namespace, or in {provider-label}-system if not specified.
+// The metadata YAML is read from the chart, if the chart contains a metadata.yaml file; otherwise, the chart version is
+// assumed to implement the Cluster API contract in the cluster.x-k8s.io/contract annotation of the chart, defaulting to
+// the current contract.
+type helmRepository struct {
+	providerConfig config.Provider
+	chart          string
+	chartPath      string
+	oci            *ociRepository
+	values         map[string]interface{}
+	valuesSHA256   string
+	namespace      string
+	defaultVersion string
+
+	// versionTags maps the versions of the provider to the tags of the charts in the OCI registry.
+	versionTags map[string]string
+
+	// charts caches the charts, keyed by version.
+	charts map[string]*helmChart
+}
+
+var _ Repository = &helmRepository{}
+
+type helmRepositoryOption func(*helmRepository)
+
+func injectHelmHTTPClient(c *http.Client) helmRepositoryOption {
+	return func(r *helmRepository) {
+		if r.oci != nil {
+			r.oci.httpClient = c
+		}
+	}
+}
+
+// NewHelmRepository returns a helmRepository implementation.
+func NewHelmRepository(ctx context.Context, providerConfig config.Provider, configVariablesClient config.VariablesClient, opts ...helmRepositoryOption) (Repository, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	rURL, err := url.Parse(providerConfig.URL())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	repo := &helmRepository{
+		providerConfig: providerConfig,
+		namespace:      rURL.Query().Get("namespace"),
+		versionTags:    map[string]string{},
+		charts:         map[string]*helmChart{},
+	}
+	if repo.namespace == "" {
+		repo.namespace = providerConfig.ManifestLabel() + "-system"
+	}
+	if valuesFile := rURL.Query().Get("values"); valuesFile != "" {
+		values, content, err := readHelmValuesFile(valuesFile)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(content)
+		repo.values = values
+		repo.valuesSHA256 = hex.EncodeToString(sum[:])
+	}
+
+	switch rURL.Scheme {
+	case helmScheme:
+		if err := repo.setLocalChart(rURL); err != nil {
+			return nil, err
+		}
+	case helmOCIScheme:
+		if err := repo.setOCIChart(rURL, providerConfig, configVariablesClient); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf("invalid url: a Helm chart url should use the %s or the %s scheme", helmScheme, helmOCIScheme)
+	}
+
+	// Process helmRepositoryOptions.
+	for _, o := range opts {
+		o(repo)
+	}
+
+	if repo.defaultVersion == latestVersionTag {
+		repo.defaultVersion, err = latestContractRelease(ctx, repo, clusterv1.GroupVersion.Version)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get latest release")
+		}
+	}
+
+	return repo, nil
+}
+
+// setLocalChart reads the chart from the local filesystem; the version of the chart is the only version available.
+func (r *helmRepository) setLocalChart(rURL *url.URL) error {
+	chartPath := rURL.Path
+	if runtime.GOOS == "windows" {
+		chartPath = strings.TrimPrefix(chartPath, "/")
+	}
+	chartPath = filepath.Clean(filepath.FromSlash(chartPath))
+	if rURL.Host != "" || !filepath.IsAbs(chartPath) {
+		return errors.New("invalid url: a Helm chart url should be in the form helm:///{path-to-chart-directory-or-archive}, with an absolute path")
+	}
+
+	info, err := os.Stat(chartPath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read Helm chart %s", chartPath)
+	}
+	var chart *helmChart
+	if info.IsDir() {
+		chart, err = loadHelmChartDirectory(chartPath)
+	} else {
+		var archive []byte
+		if archive, err = os.ReadFile(chartPath); err != nil {
+			return errors.Wrapf(err, "failed to read Helm chart %s", chartPath)
+		}
+		chart, err = loadHelmChartArchive(archive)
+	}
+	if err != nil {
+		return err
+	}
+
+	r.chart = chartPath
+	r.chartPath = chartPath
+	r.defaultVersion = helmVersion(chart.Metadata.Version)
+	r.charts[r.defaultVersion] = chart
+	return nil
+}
+
+// setOCIChart configures the access to the chart in the OCI registry; versions are the tags of the chart.
+func (r *helmRepository) setOCIChart(rURL *url.URL, providerConfig config.Provider, configVariablesClient config.VariablesClient) error {
+	reference := strings.TrimPrefix(rURL.Path, "/")
+	i := strings.LastIndex(reference, ":")
+	if rURL.Host == "" || i <= 0 || i == len(reference)-1 {
+		return errors.New("invalid url: a Helm chart url should be in the form helm+oci://{registry}/{repository}:{latest|version}")
+	}
+	repository, tag := reference[:i], reference[i+1:]
+
+	registry := rURL.Host
+	if registry == ociDockerHubRegistry {
+		registry = ociDockerHubRegistryEndpoint
+	}
+	r.oci = &ociRepository{
+		providerConfig:        providerConfig,
+		configVariablesClient: configVariablesClient,
+		httpClient:            http.DefaultClient,
+		registry:              registry,
+		repository:            repository,
+		rootPath:              ".",
+		componentsPath:        helmComponentsPath,
+	}
+	if err := r.oci.setCredentials(configVariablesClient); err != nil {
+		return err
+	}
+
+	r.chart = fmt.Sprintf("%s://%s/%s", ociScheme, rURL.Host, repository)
+	r.defaultVersion = tag
+	if tag != latestVersionTag {
+		r.defaultVersion = helmVersion(strings.ReplaceAll(tag, "_", "+"))
+		r.versionTags[r.defaultVersion] = tag
+	}
+	return nil
+}
+
+// helmVersion returns the provider version for a chart version; chart versions usually do not have the v prefix
+// used by clusterctl for the provider versions.
+func helmVersion(chartVersion string) string {
+	if strings.HasPrefix(chartVersion, "v") {
+		return chartVersion
+	}
+	return "v" + chartVersion
+}
+
+// DefaultVersion returns defaultVersion field of helmRepository struct.
+func (r *helmRepository) DefaultVersion() string {
+	return r.defaultVersion
+}
+
+// RootPath returns the empty string as it is not applicable to Helm charts.
+func (r *helmRepository) RootPath() string {
+	return ""
+}
+
+// ComponentsPath returns the name of the components YAML rendered from the chart.
+func (r *helmRepository) ComponentsPath() string {
+	return helmComponentsPath
+}
+
+// GetVersions returns the list of versions that are available in a provider repository.
+// For charts on the local filesystem, only the version of the chart is available.
+func (r *helmRepository) GetVersions(ctx context.Context) ([]string, error) {
+	if r.oci == nil {
+		return []string{r.defaultVersion}, nil
+	}
+
+	// Helm replaces the + in chart versions with _ in the tags.
+	tags, err := r.oci.GetVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	versions := []string{}
+	for _, tag := range tags {
+		if tag == latestVersionTag {
+			continue
+		}
+		v := helmVersion(strings.ReplaceAll(tag, "_", "+"))
+		r.versionTags[v] = tag
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+// GetFile returns a file for a given provider version: the components YAML rendered from the chart, the metadata YAML,
+// or any other file in the chart, e.g. a cluster template.
+func (r *helmRepository) GetFile(ctx context.Context, version, path string) ([]byte, error) {
+	if version == "" {
+		version = r.defaultVersion
+	}
+	chart, err := r.getChart(ctx, version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get file %q with version %q", path, version)
+	}
+
+	switch path {
+	case helmComponentsPath:
+		content, err := chart.render(helmRelease{
+			Name:      r.providerConfig.Name(),
+			Namespace: r.namespace,
+			Service:   "Helm",
+			IsInstall: true,
+			Revision:  1,
+		}, r.values)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get file %q with version %q", path, version)
+		}
+		return content, nil
+	case metadataFile:
+		if content, ok := chart.files[metadataFile]; ok {
+			return content, nil
+		}
+		return helmChartMetadataFile(chart, version)
+	}
+
+	if content, ok := chart.files[path]; ok {
+		return content, nil
+	}
+	return nil, errors.Wrapf(errNotFound, "failed to get file %q with version %q: the Helm chart does not contain the file", path, version)
+}
+
+// getChart returns the chart for a version, downloading it from the OCI registry if required.
+func (r *helmRepository) getChart(ctx context.Context, version string) (*helmChart, error) {
+	if chart, ok := r.charts[version]; ok {
+		return chart, nil
+	}
+	if r.oci == nil {
+		return nil, errors.Wrapf(errNotFound, "the Helm chart %s has version %s", r.chartPath, r.defaultVersion)
+	}
+
+	tag, ok := r.versionTags[version]
+	if !ok {
+		if _, err := r.GetVersions(ctx); err != nil {
+			return nil, err
+		}
+		if tag, ok = r.versionTags[version]; !ok {
+			return nil, errors.Wrapf(errNotFound, "the Helm chart %s does not have version %s", r.chart, version)
+		}
+	}
+
+	manifest, digest, err := r.oci.getManifest(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+	if r.oci.cosignPublicKey != nil {
+		if err := r.oci.verifySignature(ctx, digest); err != nil {
+			return nil, err
+		}
+	}
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != helmChartLayerMediaType {
+			continue
+		}
+		archive, err := r.oci.getBlob(ctx, layer.Digest)
+		if err != nil {
+			return nil, err
+		}
+		chart, err := loadHelmChartArchive(archive)
+		if err != nil {
+			return nil, err
+		}
+		r.charts[version] = chart
+		return chart, nil
+	}
+	return nil, errors.Errorf("the artifact %s:%s is not a Helm chart", r.oci.repository, tag)
+}
+
+// helmChartMetadataFile returns a metadata YAML with a release series for the version of the chart.
+func helmChartMetadataFile(chart *helmChart, chartVersion string) ([]byte, error) {
+	v, err := version.ParseSemantic(chartVersion)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid version %q of Helm chart %s", chartVersion, chart.Metadata.Name)
+	}
+	contract := chart.Metadata.Annotations[helmChartContractAnnotation]
+	if contract == "" {
+		contract = clusterv1.GroupVersion.Version
+	}
+
+	metadata := &clusterctlv1.Metadata{
+		ReleaseSeries: []clusterctlv1.ReleaseSeries{
+			{Major: v.Major(), Minor: v.Minor(), Contract: contract},
+		},
+	}
+	metadata.SetGroupVersionKind(clusterctlv1.GroupVersion.WithKind("Metadata"))
+	return yaml.Marshal(metadata)
+}
+
+// inventoryAnnotations returns the annotations recording the chart and the release in the provider inventory.
+func (r *helmRepository) inventoryAnnotations(_ string) map[string]string {
+	annotations := map[string]string{
+		clusterctlv1.HelmChartAnnotation:   r.chart,
+		clusterctlv1.HelmReleaseAnnotation: r.providerConfig.Name(),
+	}
+	if r.valuesSHA256 != "" {
+		annotations[clusterctlv1.HelmValuesSHA256Annotation] = r.valuesSHA256
+	}
+	return annotations
+}
@@ -0,0 +1,349 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+func testHelmChartFiles(version string) map[string]string {
+	return map[string]string{
+		"Chart.yaml":  fmt.Sprintf("apiVersion: v2\nname: provider\nversion: %s\nannotations:\n  cluster.x-k8s.io/contract: v1beta1\n", version),
+		"values.yaml": "replicas: 1\nimage:\n  repository: registry.example.com/provider\n  tag: v1\n",
+		"templates/_helpers.tpl": `{{- define "provider.labels" -}}
+app: {{ .Release.Name }}
+{{- end -}}`,
+		"templates/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}-controller-manager
+  namespace: {{ .Release.Namespace }}
+  labels:
+    {{- include "provider.labels" . | nindent 4 }}
+spec:
+  replicas: {{ .Values.replicas }}
+  template:
+    spec:
+      containers:
+      - name: manager
+        image: {{ .Values.image.repository }}:{{ .Values.image.tag }}
+`,
+		"templates/NOTES.txt":             "Installed {{ .Chart.Name }}",
+		"crds/providers.example.com.yaml": "apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: providers.example.com\n",
+	}
+}
+
+func writeHelmChartDirectory(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "provider")
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func helmChartArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: "provider/" + name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func Test_helmRepository_newHelmRepository(t *testing.T) {
+	chartDir := writeHelmChartDirectory(t, testHelmChartFiles("1.2.3"))
+
+	tests := []struct {
+		name          string
+		url           string
+		wantVersion   string
+		wantNamespace string
+		wantedErr     string
+	}{
+		{
+			name:          "can create a new Helm repo from a chart directory",
+			url:           "helm://" + filepath.ToSlash(chartDir),
+			wantVersion:   "v1.2.3",
+			wantNamespace: "infrastructure-test-system",
+		},
+		{
+			name:          "can create a new Helm repo with a namespace",
+			url:           "helm://" + filepath.ToSlash(chartDir) + "?namespace=ns1",
+			wantVersion:   "v1.2.3",
+			wantNamespace: "ns1",
+		},
+		{
+			name:      "relative path",
+			url:       "helm://charts/provider",
+			wantedErr: "invalid url: a Helm chart url should be in the form helm:///",
+		},
+		{
+			name:      "missing chart",
+			url:       "helm://" + filepath.ToSlash(filepath.Join(chartDir, "missing")),
+			wantedErr: "failed to read Helm chart",
+		},
+		{
+			name:      "missing values file",
+			url:       "helm://" + filepath.ToSlash(chartDir) + "?values=/missing-values.yaml",
+			wantedErr: "failed to read Helm values file",
+		},
+		{
+			name:      "missing OCI tag",
+			url:       "helm+oci://registry.example.com/charts/provider",
+			wantedErr: "invalid url: a Helm chart url should be in the form helm+oci://",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			providerConfig := config.NewProvider("test", tt.url, clusterctlv1.InfrastructureProviderType)
+			got, err := NewHelmRepository(context.Background(), providerConfig, test.NewFakeVariableClient())
+			if tt.wantedErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantedErr)))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+
+			g.Expect(got.DefaultVersion()).To(Equal(tt.wantVersion))
+			g.Expect(got.(*helmRepository).namespace).To(Equal(tt.wantNamespace))
+		})
+	}
+}
+
+func Test_helmRepository_GetFile(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx := context.Background()
+
+	chartDir := writeHelmChartDirectory(t, testHelmChartFiles("1.2.3"))
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	g.Expect(os.WriteFile(valuesFile, []byte("replicas: 3\nimage:\n  tag: v2\n"), 0600)).To(Succeed())
+
+	repo, err := NewHelmRepository(ctx,
+		config.NewProvider("test", fmt.Sprintf("helm://%s?values=%s", filepath.ToSlash(chartDir), filepath.ToSlash(valuesFile)), clusterctlv1.InfrastructureProviderType),
+		test.NewFakeVariableClient(),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(repo.GetVersions(ctx)).To(ConsistOf("v1.2.3"))
+
+	// The components YAML includes the CRDs, the rendered templates and the release namespace.
+	components, err := repo.GetFile(ctx, "", helmComponentsPath)
+	g.Expect(err).ToNot(HaveOccurred())
+	objs, err := utilyaml.ToUnstructured(components)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	kinds := []string{}
+	for _, o := range objs {
+		kinds = append(kinds, o.GetKind())
+		if o.GetKind() != "Deployment" {
+			continue
+		}
+		g.Expect(o.GetName()).To(Equal("test-controller-manager"))
+		g.Expect(o.GetNamespace()).To(Equal("infrastructure-test-system"))
+		g.Expect(o.GetLabels()).To(HaveKeyWithValue("app", "test"))
+		g.Expect(o.Object["spec"]).To(HaveKeyWithValue("replicas", BeEquivalentTo(3)))
+		g.Expect(string(components)).To(ContainSubstring("image: registry.example.com/provider:v2"))
+	}
+	g.Expect(kinds).To(ConsistOf("CustomResourceDefinition", "Deployment", "Namespace"))
+
+	// The metadata YAML is generated from the contract annotation of the chart.
+	metadata, err := repo.GetFile(ctx, "v1.2.3", metadataFile)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(metadata)).To(ContainSubstring("contract: v1beta1"))
+	g.Expect(string(metadata)).To(ContainSubstring("major: 1"))
+	g.Expect(string(metadata)).To(ContainSubstring("minor: 2"))
+
+	_, err = repo.GetFile(ctx, "v1.2.3", "cluster-template.yaml")
+	g.Expect(err).To(MatchError(errNotFound))
+
+	_, err = repo.GetFile(ctx, "v2.0.0", helmComponentsPath)
+	g.Expect(err).To(MatchError(errNotFound))
+
+	// The chart, the release and the values file are recorded in the inventory.
+	annotations := repo.(*helmRepository).inventoryAnnotations("v1.2.3")
+	g.Expect(annotations).To(HaveKeyWithValue(clusterctlv1.HelmChartAnnotation, filepath.Clean(chartDir)))
+	g.Expect(annotations).To(HaveKeyWithValue(clusterctlv1.HelmReleaseAnnotation, "test"))
+	g.Expect(annotations).To(HaveKey(clusterctlv1.HelmValuesSHA256Annotation))
+}
+
+func Test_helmRepository_GetFileOCI(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx := context.Background()
+
+	registry := newFakeOCIRegistry(t)
+	for _, v := range []string{"1.0.0", "1.1.0"} {
+		archive := helmChartArchive(t, testHelmChartFiles(v))
+		registry.tags = append(registry.tags, v)
+		registry.addManifest(v, ociManifest{
+			Layers: []ociDescriptor{{
+				MediaType: helmChartLayerMediaType,
+				Digest:    registry.addBlob(archive),
+				Size:      int64(len(archive)),
+			}},
+		})
+	}
+	registry.push("0.9.0", map[string]string{"components.yaml": "not a chart"})
+
+	repo, err := NewHelmRepository(ctx,
+		config.NewProvider("test", fmt.Sprintf("helm+oci://%s/org/provider:latest", registry.host()), clusterctlv1.InfrastructureProviderType),
+		test.NewFakeVariableClient(),
+		injectHelmHTTPClient(registry.server.Client()),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(repo.DefaultVersion()).To(Equal("v1.1.0"))
+	g.Expect(repo.GetVersions(ctx)).To(ConsistOf("v0.9.0", "v1.0.0", "v1.1.0"))
+
+	components, err := repo.GetFile(ctx, "v1.0.0", helmComponentsPath)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(components)).To(ContainSubstring("name: test-controller-manager"))
+
+	_, err = repo.GetFile(ctx, "v0.9.0", helmComponentsPath)
+	g.Expect(err).To(MatchError(ContainSubstring("is not a Helm chart")))
+
+	g.Expect(repo.(*helmRepository).inventoryAnnotations("v1.0.0")).To(Equal(map[string]string{
+		clusterctlv1.HelmChartAnnotation:   fmt.Sprintf("oci://%s/org/provider", registry.host()),
+		clusterctlv1.HelmReleaseAnnotation: "test",
+	}))
+}
+
+func Test_helmChart_render(t *testing.T) {
+	g := NewWithT(t)
+
+	files := map[string][]byte{
+		"Chart.yaml": []byte("apiVersion: v2\nname: provider\nversion: 1.0.0\n"),
+		"templates/capabilities.yaml": []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: capabilities
+data:
+  kubeVersion: {{ .Capabilities.KubeVersion.Version | quote }}
+  kubeMinor: {{ .Capabilities.KubeVersion.Minor | quote }}
+  {{- if semverCompare ">=1.25-0" .Capabilities.KubeVersion.GitVersion }}
+  policy: {{ ternary "policy/v1" "policy/v1beta1" (.Capabilities.APIVersions.Has "policy/v1/PodDisruptionBudget") }}
+  {{- end }}
+  helmVersion: {{ .Capabilities.HelmVersion.Version | quote }}
+  subcharts: {{ len .Subcharts | quote }}
+`),
+	}
+	chart, err := newHelmChart(files)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	components, err := chart.render(helmRelease{Name: "test", Namespace: "ns1", IsInstall: true}, nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	objs, err := utilyaml.ToUnstructured(components)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	names := []string{}
+	for _, o := range objs {
+		names = append(names, o.GetName())
+		if o.GetName() != "capabilities" {
+			continue
+		}
+		g.Expect(o.Object["data"]).To(Equal(map[string]interface{}{
+			"kubeVersion": helmCapabilitiesKubeVersionDefault,
+			"kubeMinor":   "29",
+			"policy":      "policy/v1",
+			"helmVersion": helmCapabilitiesHelmVersionDefault,
+			"subcharts":   "0",
+		}))
+	}
+	g.Expect(names).To(ConsistOf("ns1", "capabilities"))
+}
+
+func Test_helmChart_renderWithHooks(t *testing.T) {
+	g := NewWithT(t)
+
+	files := map[string][]byte{
+		"Chart.yaml": []byte("apiVersion: v2\nname: provider\nversion: 1.0.0\n"),
+		"templates/hooks.yaml": []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: pre-install
+  annotations:
+    helm.sh/hook: pre-install,pre-upgrade
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: test
+  annotations:
+    helm.sh/hook: test
+`),
+	}
+	chart, err := newHelmChart(files)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	_, err = chart.render(helmRelease{Name: "test", Namespace: "ns1", IsInstall: true}, nil)
+	g.Expect(err).To(MatchError(ContainSubstring("Helm chart provider uses hooks, which are not supported: ConfigMap pre-install, Pod test")))
+}
+
+func Test_mergeHelmValues(t *testing.T) {
+	g := NewWithT(t)
+
+	dst := map[string]interface{}{
+		"replicas": 1,
+		"image":    map[string]interface{}{"repository": "r", "tag": "v1"},
+		"debug":    true,
+	}
+	src := map[string]interface{}{
+		"image": map[string]interface{}{"tag": "v2"},
+		"debug": nil,
+	}
+	g.Expect(mergeHelmValues(dst, src)).To(Equal(map[string]interface{}{
+		"replicas": 1,
+		"image":    map[string]interface{}{"repository": "r", "tag": "v2"},
+	}))
+}
@@ -0,0 +1,559 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+const (
+	ociScheme                    = "oci"
+	ociDockerHubRegistry         = "docker.io"
+	ociDockerHubRegistryEndpoint = "registry-1.docker.io"
+	ociManifestMediaType         = "application/vnd.oci.image.manifest.v1+json"
+	ociDockerManifestMediaType   = "application/vnd.docker.distribution.manifest.v2+json"
+	ociTitleAnnotation           = "org.opencontainers.image.title"
+	cosignSimpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	cosignSignatureAnnotation    = "dev.cosignproject.cosign/signature"
+	cosignSignatureTagSuffix     = ".sig"
+)
+
+var (
+	ociDigestRegex         = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+	ociAuthParamRegex      = regexp.MustCompile(`(\w+)="([^"]*)"`)
+	ociNextLinkRegex       = regexp.MustCompile(`<([^>]+)>;\s*rel="?next"?`)
+	ociRequestTimeout      = 30 * time.Second
+	ociCacheManifests      = map[string]*ociManifest{}
+	ociCacheManifestDigest = map[string]string{}
+)
+
+// ociRepository provides support for providers hosted as OCI artifacts in an OCI registry.
+//
+// Each provider version is an artifact tagged with the version, storing each file, e.g. the components YAML or
+// the metadata YAML, as a layer named with the "org.opencontainers.image.title" annotation, as pushed by `oras push`.
+// The artifact for the default version can be pinned to a digest, and artifacts can be verified
+// using cosign signatures and a cosign public key.
+type ociRepository struct {
+	providerConfig        config.Provider
+	configVariablesClient config.VariablesClient
+	httpClient            *http.Client
+	registry              string
+	repository            string
+	defaultVersion        string
+	digest                string
+	rootPath              string
+	componentsPath        string
+	username              string
+	password              string
+	basicAuth             bool
+	token                 string
+	cosignPublicKey       *ecdsa.PublicKey
+}
+
+var _ Repository = &ociRepository{}
+
+type ociRepositoryOption func(*ociRepository)
+
+func injectOCIHTTPClient(c *http.Client) ociRepositoryOption {
+	return func(r *ociRepository) {
+		r.httpClient = c
+	}
+}
+
+// ociManifest is the subset of an OCI image manifest used by clusterctl.
+type ociManifest struct {
+	MediaType string          `json:"mediaType,omitempty"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+// ociDescriptor is the subset of an OCI content descriptor used by clusterctl.
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// cosignPayload is the subset of a cosign simple signing payload used by clusterctl.
+type cosignPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// NewOCIRepository returns an ociRepository implementation.
+func NewOCIRepository(ctx context.Context, providerConfig config.Provider, configVariablesClient config.VariablesClient, opts ...ociRepositoryOption) (Repository, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	rURL, err := url.Parse(providerConfig.URL())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	invalidURLErr := errors.New("invalid url: an OCI repository url should be in the form oci://{registry}/{repository}:{latest|version-tag}[@{digest}]/{componentsClient.yaml}")
+
+	// Check if the url is an OCI repository, with the components file name as the last element of the path.
+	path := strings.TrimPrefix(rURL.Path, "/")
+	sep := strings.LastIndex(path, "/")
+	if rURL.Scheme != ociScheme || rURL.Host == "" || sep <= 0 || sep == len(path)-1 {
+		return nil, invalidURLErr
+	}
+	reference, componentsPath := path[:sep], path[sep+1:]
+
+	// Extract the digest, if any, and the tag from the artifact reference.
+	digest := ""
+	if i := strings.Index(reference, "@"); i >= 0 {
+		reference, digest = reference[:i], reference[i+1:]
+		if !ociDigestRegex.MatchString(digest) {
+			return nil, errors.Errorf("invalid url: invalid digest %q, only sha256 digests are supported", digest)
+		}
+	}
+	i := strings.LastIndex(reference, ":")
+	if i <= 0 || i == len(reference)-1 {
+		return nil, invalidURLErr
+	}
+	repository, defaultVersion := reference[:i], reference[i+1:]
+	if digest != "" && defaultVersion == latestVersionTag {
+		return nil, errors.New("invalid url: an OCI repository url pinned to a digest can't use the latest version")
+	}
+
+	registry := rURL.Host
+	if registry == ociDockerHubRegistry {
+		registry = ociDockerHubRegistryEndpoint
+	}
+
+	repo := &ociRepository{
+		providerConfig:        providerConfig,
+		configVariablesClient: configVariablesClient,
+		httpClient:            http.DefaultClient,
+		registry:              registry,
+		repository:            repository,
+		defaultVersion:        defaultVersion,
+		digest:                digest,
+		rootPath:              ".",
+		componentsPath:        componentsPath,
+	}
+
+	// Process ociRepositoryOptions.
+	for _, o := range opts {
+		o(repo)
+	}
+
+	if err := repo.setCredentials(configVariablesClient); err != nil {
+		return nil, err
+	}
+
+	if defaultVersion == latestVersionTag {
+		repo.defaultVersion, err = latestContractRelease(ctx, repo, clusterv1.GroupVersion.Version)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get latest release")
+		}
+	}
+
+	return repo, nil
+}
+
+// setCredentials reads the credentials for the registry and the cosign public key from the clusterctl variables.
+func (r *ociRepository) setCredentials(configVariablesClient config.VariablesClient) error {
+	if username, err := configVariablesClient.Get(config.OCIUsernameVariable); err == nil {
+		r.username = username
+	}
+	if password, err := configVariablesClient.Get(config.OCIPasswordVariable); err == nil {
+		r.password = password
+	}
+	if keyPath, err := configVariablesClient.Get(config.OCICosignPublicKeyVariable); err == nil && keyPath != "" {
+		r.cosignPublicKey, err = readCosignPublicKey(keyPath)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DefaultVersion returns defaultVersion field of ociRepository struct.
+func (r *ociRepository) DefaultVersion() string {
+	return r.defaultVersion
+}
+
+// RootPath returns rootPath field of ociRepository struct.
+func (r *ociRepository) RootPath() string {
+	return r.rootPath
+}
+
+// ComponentsPath returns componentsPath field of ociRepository struct.
+func (r *ociRepository) ComponentsPath() string {
+	return r.componentsPath
+}
+
+// GetVersions returns the list of versions that are available in a provider repository.
+// If the repository url is pinned to a digest, only the default version is available.
+func (r *ociRepository) GetVersions(ctx context.Context) ([]string, error) {
+	if r.digest != "" {
+		return []string{r.defaultVersion}, nil
+	}
+
+	cacheID := fmt.Sprintf("%s/%s", r.registry, r.repository)
+	if versions, ok := cacheVersions[cacheID]; ok {
+		return versions, nil
+	}
+
+	versions := []string{}
+	next := fmt.Sprintf("https://%s/v2/%s/tags/list", r.registry, r.repository)
+	for next != "" {
+		response, err := r.get(ctx, next, "application/json")
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the list of versions for %s", cacheID)
+		}
+
+		tags := struct {
+			Tags []string `json:"tags"`
+		}{}
+		err = json.NewDecoder(response.Body).Decode(&tags)
+		response.Body.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode the list of versions for %s", cacheID)
+		}
+		for _, tag := range tags.Tags {
+			// Skip the cosign signatures, stored as tags in the same repository.
+			if strings.HasSuffix(tag, cosignSignatureTagSuffix) {
+				continue
+			}
+			versions = append(versions, tag)
+		}
+
+		next = ""
+		if match := ociNextLinkRegex.FindStringSubmatch(response.Header.Get("Link")); match != nil {
+			nextURL, err := response.Request.URL.Parse(match[1])
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse the next page of the list of versions for %s", cacheID)
+			}
+			next = nextURL.String()
+		}
+	}
+
+	cacheVersions[cacheID] = versions
+	return versions, nil
+}
+
+// GetFile returns a file for a given provider version.
+func (r *ociRepository) GetFile(ctx context.Context, version, path string) ([]byte, error) {
+	reference := version
+	if version == r.defaultVersion && r.digest != "" {
+		reference = r.digest
+	}
+
+	cacheID := fmt.Sprintf("%s/%s@%s/%s", r.registry, r.repository, reference, path)
+	if content, ok := cacheFiles[cacheID]; ok {
+		return content, nil
+	}
+
+	manifest, digest, err := r.getManifest(ctx, reference)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get file %q with version %q", path, version)
+	}
+
+	if r.cosignPublicKey != nil {
+		if err := r.verifySignature(ctx, digest); err != nil {
+			return nil, errors.Wrapf(err, "failed to verify version %q", version)
+		}
+	}
+
+	for _, layer := range manifest.Layers {
+		if layer.Annotations[ociTitleAnnotation] != path {
+			continue
+		}
+
+		content, err := r.getBlob(ctx, layer.Digest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get file %q with version %q", path, version)
+		}
+		cacheFiles[cacheID] = content
+		return content, nil
+	}
+	return nil, errors.Errorf("failed to get file %q with version %q: the artifact %s:%s does not contain the file", path, version, r.repository, version)
+}
+
+// getManifest returns the manifest of the artifact for a tag or a digest, and the digest of the manifest.
+func (r *ociRepository) getManifest(ctx context.Context, reference string) (*ociManifest, string, error) {
+	cacheID := fmt.Sprintf("%s/%s@%s", r.registry, r.repository, reference)
+	if manifest, ok := ociCacheManifests[cacheID]; ok {
+		return manifest, ociCacheManifestDigest[cacheID], nil
+	}
+
+	response, err := r.get(ctx, fmt.Sprintf("https://%s/v2/%s/manifests/%s", r.registry, r.repository, reference), ociManifestMediaType+", "+ociDockerManifestMediaType)
+	if err != nil {
+		return nil, "", err
+	}
+	defer response.Body.Close()
+
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to read the manifest for %s:%s", r.repository, reference)
+	}
+
+	digest := ociDigest(content)
+	if ociDigestRegex.MatchString(reference) && digest != reference {
+		return nil, "", errors.Errorf("the manifest for %s@%s does not match the digest, got %s", r.repository, reference, digest)
+	}
+
+	manifest := &ociManifest{}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, "", errors.Wrapf(err, "failed to decode the manifest for %s:%s", r.repository, reference)
+	}
+
+	ociCacheManifests[cacheID] = manifest
+	ociCacheManifestDigest[cacheID] = digest
+	return manifest, digest, nil
+}
+
+// getBlob returns a blob, after checking the content matches the digest.
+func (r *ociRepository) getBlob(ctx context.Context, digest string) ([]byte, error) {
+	if !ociDigestRegex.MatchString(digest) {
+		return nil, errors.Errorf("invalid digest %q, only sha256 digests are supported", digest)
+	}
+
+	response, err := r.get(ctx, fmt.Sprintf("https://%s/v2/%s/blobs/%s", r.registry, r.repository, digest), "")
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read blob %s", digest)
+	}
+	if got := ociDigest(content); got != digest {
+		return nil, errors.Errorf("blob %s does not match the digest, got %s", digest, got)
+	}
+	return content, nil
+}
+
+// verifySignature checks there is a cosign signature for the manifest digest, signed with the cosign public key.
+func (r *ociRepository) verifySignature(ctx context.Context, digest string) error {
+	signatureTag := strings.Replace(digest, ":", "-", 1) + cosignSignatureTagSuffix
+	manifest, _, err := r.getManifest(ctx, signatureTag)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the cosign signatures for %s@%s", r.repository, digest)
+	}
+
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != cosignSimpleSigningMediaType {
+			continue
+		}
+		signature, err := base64.StdEncoding.DecodeString(layer.Annotations[cosignSignatureAnnotation])
+		if err != nil {
+			continue
+		}
+		payload, err := r.getBlob(ctx, layer.Digest)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the cosign signature payload for %s@%s", r.repository, digest)
+		}
+
+		hash := sha256.Sum256(payload)
+		if !ecdsa.VerifyASN1(r.cosignPublicKey, hash[:], signature) {
+			continue
+		}
+
+		// The signature must be for this artifact, and not e.g. for another artifact signed with the same key.
+		p := &cosignPayload{}
+		if err := json.Unmarshal(payload, p); err != nil {
+			continue
+		}
+		if p.Critical.Image.DockerManifestDigest == digest {
+			return nil
+		}
+	}
+	return errors.Errorf("no valid cosign signature found for %s@%s", r.repository, digest)
+}
+
+// get executes a GET request against the registry, authenticating if requested by the registry.
+// NOTE: The credentials are only sent in response to an authentication challenge of the registry.
+func (r *ociRepository) get(ctx context.Context, url, accept string) (*http.Response, error) {
+	response, err := r.do(ctx, url, accept, r.basicAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusUnauthorized && r.token == "" && !r.basicAuth {
+		challenge := response.Header.Get("WWW-Authenticate")
+		response.Body.Close()
+		if err := r.authenticate(ctx, challenge); err != nil {
+			return nil, err
+		}
+		response, err = r.do(ctx, url, accept, r.basicAuth)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		if response.StatusCode == http.StatusNotFound {
+			return nil, errors.Wrapf(errNotFound, "failed to get %q", url)
+		}
+		return nil, errors.Errorf("failed to get %q, got %d", url, response.StatusCode)
+	}
+	return response, nil
+}
+
+// do executes a GET request, using the bearer token if any, or the username and the password if withCredentials is true.
+func (r *ociRepository) do(ctx context.Context, url, accept string, withCredentials bool) (*http.Response, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, ociRequestTimeout)
+	request, err := http.NewRequestWithContext(timeoutctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		cancel()
+		return nil, errors.Wrapf(err, "failed to get %q: failed to create request", url)
+	}
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+	switch {
+	case r.token != "":
+		request.Header.Set("Authorization", "Bearer "+r.token)
+	case withCredentials && (r.username != "" || r.password != ""):
+		request.SetBasicAuth(r.username, r.password)
+	}
+
+	response, err := r.httpClient.Do(request)
+	if err != nil {
+		cancel()
+		return nil, errors.Wrapf(err, "failed to get %q", url)
+	}
+	response.Body = &cancelOnCloseReader{ReadCloser: response.Body, cancel: cancel}
+	return response, nil
+}
+
+// authenticate gets a bearer token as requested by the challenge of the registry, or enables basic authentication
+// if requested by the registry; the username and the password, if any, are used to get the token.
+func (r *ociRepository) authenticate(ctx context.Context, challenge string) error {
+	if strings.HasPrefix(strings.ToLower(challenge), "basic") {
+		if r.username == "" && r.password == "" {
+			return errors.Errorf("failed to authenticate to %s: credentials are required", r.registry)
+		}
+		r.basicAuth = true
+		return nil
+	}
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return errors.Errorf("failed to authenticate to %s: unsupported authentication challenge %q", r.registry, challenge)
+	}
+
+	params := map[string]string{}
+	for _, match := range ociAuthParamRegex.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(match[1])] = match[2]
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return errors.Errorf("failed to authenticate to %s: invalid realm in authentication challenge %q", r.registry, challenge)
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", r.repository)
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	response, err := r.do(ctx, realm.String(), "application/json", true)
+	if err != nil {
+		return errors.Wrapf(err, "failed to authenticate to %s", r.registry)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return errors.Errorf("failed to authenticate to %s, got %d", r.registry, response.StatusCode)
+	}
+
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return errors.Wrapf(err, "failed to authenticate to %s: failed to decode token", r.registry)
+	}
+	r.token = token.Token
+	if r.token == "" {
+		r.token = token.AccessToken
+	}
+	if r.token == "" {
+		return errors.Errorf("failed to authenticate to %s: no token returned", r.registry)
+	}
+	return nil
+}
+
+// cancelOnCloseReader cancels the context of a request when the response body is closed.
+type cancelOnCloseReader struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnCloseReader) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+func ociDigest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// readCosignPublicKey reads a PEM encoded ECDSA public key, as generated by `cosign generate-key-pair`.
+func readCosignPublicKey(path string) (*ecdsa.PublicKey, error) {
+	content, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the cosign public key %q", path)
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.Errorf("failed to read the cosign public key %q: no PEM data found", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the cosign public key %q", path)
+	}
+	ecdsaKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.Errorf("invalid cosign public key %q: only ECDSA keys are supported", path)
+	}
+	return ecdsaKey, nil
+}
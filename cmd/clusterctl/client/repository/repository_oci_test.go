@@ -0,0 +1,359 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+// fakeOCIRegistry is a minimal OCI registry, requiring bearer token authentication.
+type fakeOCIRegistry struct {
+	server    *httptest.Server
+	tags      []string
+	manifests map[string][]byte
+	blobs     map[string][]byte
+}
+
+func newFakeOCIRegistry(t *testing.T) *fakeOCIRegistry {
+	t.Helper()
+
+	r := &fakeOCIRegistry{
+		manifests: map[string][]byte{},
+		blobs:     map[string][]byte{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("scope") != "repository:org/provider:pull" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, `{"token": "test-token"}`)
+	})
+	mux.HandleFunc("/v2/org/provider/", func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer test-token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake",scope="repository:org/provider:pull"`, r.server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		path := strings.TrimPrefix(req.URL.Path, "/v2/org/provider/")
+		switch {
+		case path == "tags/list":
+			// Return one tag per page, to test pagination.
+			i := 0
+			if last := req.URL.Query().Get("last"); last != "" {
+				for i < len(r.tags) && r.tags[i] != last {
+					i++
+				}
+				i++
+			}
+			if i >= len(r.tags) {
+				fmt.Fprint(w, `{"name": "org/provider", "tags": []}`)
+				return
+			}
+			if i < len(r.tags)-1 {
+				w.Header().Set("Link", fmt.Sprintf(`</v2/org/provider/tags/list?n=1&last=%s>; rel="next"`, r.tags[i]))
+			}
+			fmt.Fprintf(w, `{"name": "org/provider", "tags": [%q]}`, r.tags[i])
+		case strings.HasPrefix(path, "manifests/"):
+			manifest, ok := r.manifests[strings.TrimPrefix(path, "manifests/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", ociManifestMediaType)
+			_, _ = w.Write(manifest)
+		case strings.HasPrefix(path, "blobs/"):
+			blob, ok := r.blobs[strings.TrimPrefix(path, "blobs/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(blob)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	r.server = httptest.NewTLSServer(mux)
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+// host returns the host of the registry, to be used in oci:// urls.
+func (r *fakeOCIRegistry) host() string {
+	return strings.TrimPrefix(r.server.URL, "https://")
+}
+
+func (r *fakeOCIRegistry) addBlob(content []byte) string {
+	digest := ociDigest(content)
+	r.blobs[digest] = content
+	return digest
+}
+
+func (r *fakeOCIRegistry) addManifest(tag string, manifest ociManifest) string {
+	manifest.MediaType = ociManifestMediaType
+	content, _ := json.Marshal(manifest)
+	digest := ociDigest(content)
+	r.manifests[digest] = content
+	if tag != "" {
+		r.manifests[tag] = content
+	}
+	return digest
+}
+
+// push adds an artifact with the given files, returning the digest of the artifact.
+func (r *fakeOCIRegistry) push(tag string, files map[string]string) string {
+	manifest := ociManifest{}
+	for name, content := range files {
+		manifest.Layers = append(manifest.Layers, ociDescriptor{
+			MediaType:   "application/yaml",
+			Digest:      r.addBlob([]byte(content)),
+			Size:        int64(len(content)),
+			Annotations: map[string]string{ociTitleAnnotation: name},
+		})
+	}
+	r.tags = append(r.tags, tag)
+	return r.addManifest(tag, manifest)
+}
+
+// sign adds a cosign signature for the artifact with the given digest.
+func (r *fakeOCIRegistry) sign(digest string, key *ecdsa.PrivateKey) {
+	payload := []byte(fmt.Sprintf(`{"critical": {"identity": {"docker-reference": "org/provider"}, "image": {"docker-manifest-digest": %q}, "type": "cosign container image signature"}}`, digest))
+	hash := sha256.Sum256(payload)
+	signature, _ := ecdsa.SignASN1(rand.Reader, key, hash[:])
+
+	r.addManifest(strings.Replace(digest, ":", "-", 1)+cosignSignatureTagSuffix, ociManifest{
+		Layers: []ociDescriptor{{
+			MediaType:   cosignSimpleSigningMediaType,
+			Digest:      r.addBlob(payload),
+			Size:        int64(len(payload)),
+			Annotations: map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(signature)},
+		}},
+	})
+}
+
+func writeCosignPublicKey(t *testing.T, key *ecdsa.PrivateKey) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "cosign.pub")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func Test_ociRepository_newOCIRepository(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+
+	tests := []struct {
+		name      string
+		url       string
+		want      *ociRepository
+		wantedErr string
+	}{
+		{
+			name: "can create a new OCI repo",
+			url:  "oci://registry.example.com/org/provider:v1.0.0/components.yaml",
+			want: &ociRepository{
+				registry:       "registry.example.com",
+				repository:     "org/provider",
+				defaultVersion: "v1.0.0",
+				rootPath:       ".",
+				componentsPath: "components.yaml",
+			},
+		},
+		{
+			name: "can create a new OCI repo pinned to a digest",
+			url:  "oci://registry.example.com:5000/org/provider:v1.0.0@" + digest + "/components.yaml",
+			want: &ociRepository{
+				registry:       "registry.example.com:5000",
+				repository:     "org/provider",
+				defaultVersion: "v1.0.0",
+				digest:         digest,
+				rootPath:       ".",
+				componentsPath: "components.yaml",
+			},
+		},
+		{
+			name: "uses the Docker Hub registry endpoint",
+			url:  "oci://docker.io/org/provider:v1.0.0/components.yaml",
+			want: &ociRepository{
+				registry:       "registry-1.docker.io",
+				repository:     "org/provider",
+				defaultVersion: "v1.0.0",
+				rootPath:       ".",
+				componentsPath: "components.yaml",
+			},
+		},
+		{
+			name:      "missing components file",
+			url:       "oci://registry.example.com/org/provider:v1.0.0",
+			wantedErr: "invalid url: an OCI repository url should be in the form",
+		},
+		{
+			name:      "missing tag",
+			url:       "oci://registry.example.com/org/provider/components.yaml",
+			wantedErr: "invalid url: an OCI repository url should be in the form",
+		},
+		{
+			name:      "invalid digest",
+			url:       "oci://registry.example.com/org/provider:v1.0.0@sha256:abc/components.yaml",
+			wantedErr: "invalid url: invalid digest \"sha256:abc\"",
+		},
+		{
+			name:      "latest pinned to a digest",
+			url:       "oci://registry.example.com/org/provider:latest@" + digest + "/components.yaml",
+			wantedErr: "invalid url: an OCI repository url pinned to a digest can't use the latest version",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			providerConfig := config.NewProvider("test", tt.url, clusterctlv1.CoreProviderType)
+			configVariablesClient := test.NewFakeVariableClient()
+			got, err := NewOCIRepository(context.Background(), providerConfig, configVariablesClient)
+			if tt.wantedErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantedErr)))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+
+			tt.want.providerConfig = providerConfig
+			tt.want.configVariablesClient = configVariablesClient
+			tt.want.httpClient = http.DefaultClient
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_ociRepository_GetFile(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx := context.Background()
+
+	registry := newFakeOCIRegistry(t)
+	registry.push("v1.0.0", map[string]string{"components.yaml": "v1.0.0", "metadata.yaml": "metadata"})
+	registry.push("v1.1.0", map[string]string{"components.yaml": "v1.1.0", "metadata.yaml": "metadata"})
+	registry.push("v2.0.0-alpha.0", map[string]string{"components.yaml": "v2.0.0-alpha.0"})
+
+	repo, err := NewOCIRepository(ctx,
+		config.NewProvider("test", fmt.Sprintf("oci://%s/org/provider:latest/components.yaml", registry.host()), clusterctlv1.CoreProviderType),
+		test.NewFakeVariableClient(),
+		injectOCIHTTPClient(registry.server.Client()),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// The latest version is resolved using the (paginated) list of versions.
+	g.Expect(repo.DefaultVersion()).To(Equal("v1.1.0"))
+	g.Expect(repo.GetVersions(ctx)).To(ConsistOf("v1.0.0", "v1.1.0", "v2.0.0-alpha.0"))
+
+	g.Expect(repo.GetFile(ctx, "v1.0.0", "components.yaml")).To(Equal([]byte("v1.0.0")))
+
+	_, err = repo.GetFile(ctx, "v2.0.0-alpha.0", "metadata.yaml")
+	g.Expect(err).To(MatchError(ContainSubstring("does not contain the file")))
+
+	_, err = repo.GetFile(ctx, "v3.0.0", "components.yaml")
+	g.Expect(err).To(MatchError(errNotFound))
+}
+
+func Test_ociRepository_GetFilePinnedDigest(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx := context.Background()
+
+	registry := newFakeOCIRegistry(t)
+	digest := registry.push("v1.0.0", map[string]string{"components.yaml": "pinned"})
+	registry.push("v1.1.0", map[string]string{"components.yaml": "v1.1.0"})
+
+	// Move the v1.0.0 tag to another artifact; the pinned digest must still be used.
+	registry.push("v1.0.0", map[string]string{"components.yaml": "moved"})
+
+	repo, err := NewOCIRepository(ctx,
+		config.NewProvider("test", fmt.Sprintf("oci://%s/org/provider:v1.0.0@%s/components.yaml", registry.host(), digest), clusterctlv1.CoreProviderType),
+		test.NewFakeVariableClient(),
+		injectOCIHTTPClient(registry.server.Client()),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(repo.GetVersions(ctx)).To(ConsistOf("v1.0.0"))
+	g.Expect(repo.GetFile(ctx, "v1.0.0", "components.yaml")).To(Equal([]byte("pinned")))
+
+	// Tampered blobs are detected.
+	for blobDigest := range registry.blobs {
+		registry.blobs[blobDigest] = []byte("tampered")
+	}
+	_, err = repo.GetFile(ctx, "v1.1.0", "components.yaml")
+	g.Expect(err).To(MatchError(ContainSubstring("does not match the digest")))
+}
+
+func Test_ociRepository_GetFileCosign(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx := context.Background()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	g.Expect(err).ToNot(HaveOccurred())
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	registry := newFakeOCIRegistry(t)
+	registry.sign(registry.push("v1.0.0", map[string]string{"components.yaml": "signed"}), key)
+	registry.sign(registry.push("v1.1.0", map[string]string{"components.yaml": "signed with another key"}), otherKey)
+	registry.push("v1.2.0", map[string]string{"components.yaml": "not signed"})
+
+	repo, err := NewOCIRepository(ctx,
+		config.NewProvider("test", fmt.Sprintf("oci://%s/org/provider:v1.0.0/components.yaml", registry.host()), clusterctlv1.CoreProviderType),
+		test.NewFakeVariableClient().WithVar(config.OCICosignPublicKeyVariable, writeCosignPublicKey(t, key)),
+		injectOCIHTTPClient(registry.server.Client()),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(repo.GetVersions(ctx)).To(ConsistOf("v1.0.0", "v1.1.0", "v1.2.0"))
+	g.Expect(repo.GetFile(ctx, "v1.0.0", "components.yaml")).To(Equal([]byte("signed")))
+
+	_, err = repo.GetFile(ctx, "v1.1.0", "components.yaml")
+	g.Expect(err).To(MatchError(ContainSubstring("no valid cosign signature found")))
+
+	_, err = repo.GetFile(ctx, "v1.2.0", "components.yaml")
+	g.Expect(err).To(MatchError(ContainSubstring("failed to get the cosign signatures")))
+}
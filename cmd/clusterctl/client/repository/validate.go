@@ -0,0 +1,319 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	yaml "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/util"
+	"sigs.k8s.io/cluster-api/util/container"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+const (
+	componentsFile         = "components"
+	defaultClusterTemplate = "cluster-template.yaml"
+	certManagerInjectCA    = "cert-manager.io/inject-ca-from"
+
+	// validationNamespace is the namespace used when reading the templates to validate.
+	validationNamespace = "default"
+)
+
+var (
+	contractRegex = regexp.MustCompile(`^v[1-9][0-9]*((alpha|beta)[1-9][0-9]*)?$`)
+	variableRegex = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+	// templateFlagVariables are the variables set by the flags of clusterctl generate cluster.
+	templateFlagVariables = map[string]string{
+		"KUBERNETES_VERSION":          "--kubernetes-version",
+		"CONTROL_PLANE_MACHINE_COUNT": "--control-plane-machine-count",
+		"WORKER_MACHINE_COUNT":        "--worker-machine-count",
+	}
+)
+
+// ValidationSeverity defines the severity of a ValidationIssue.
+type ValidationSeverity string
+
+const (
+	// ValidationError is an issue preventing clusterctl from installing the provider or from using its templates.
+	ValidationError ValidationSeverity = "Error"
+
+	// ValidationWarning is an issue not following the clusterctl conventions, which might surprise users.
+	ValidationWarning ValidationSeverity = "Warning"
+)
+
+// ValidationIssue is an issue found when validating the files of a provider repository.
+type ValidationIssue struct {
+	Severity ValidationSeverity `json:"severity"`
+	File     string             `json:"file"`
+	Message  string             `json:"message"`
+}
+
+// Validate checks the files of a provider repository for a version follow the clusterctl provider contract, thus
+// finding packaging mistakes before users install the provider: the metadata.yaml release series and contracts,
+// the components YAML conventions (namespace, labels, webhooks) and the variables used by the default cluster template.
+func Validate(ctx context.Context, repo Client, version string) []ValidationIssue {
+	if version == "" {
+		version = repo.DefaultVersion()
+	}
+
+	v := &validator{}
+	v.validateMetadata(ctx, repo, version)
+	v.validateComponents(ctx, repo, version)
+	if repo.Type() == clusterctlv1.InfrastructureProviderType {
+		v.validateClusterTemplate(ctx, repo, version)
+	}
+	return v.issues
+}
+
+// validator collects the issues found when validating a provider repository.
+type validator struct {
+	issues []ValidationIssue
+}
+
+func (v *validator) errorf(file, format string, a ...interface{}) {
+	v.issues = append(v.issues, ValidationIssue{Severity: ValidationError, File: file, Message: fmt.Sprintf(format, a...)})
+}
+
+func (v *validator) warningf(file, format string, a ...interface{}) {
+	v.issues = append(v.issues, ValidationIssue{Severity: ValidationWarning, File: file, Message: fmt.Sprintf(format, a...)})
+}
+
+func (v *validator) validateMetadata(ctx context.Context, repo Client, providerVersion string) {
+	metadata, err := repo.Metadata(providerVersion).Get(ctx)
+	if err != nil {
+		v.errorf(metadataFile, "failed to read the metadata: %v", err)
+		return
+	}
+
+	if len(metadata.ReleaseSeries) == 0 {
+		v.errorf(metadataFile, "no release series defined")
+		return
+	}
+
+	releaseSeries := sets.Set[string]{}
+	for _, rs := range metadata.ReleaseSeries {
+		series := fmt.Sprintf("v%d.%d", rs.Major, rs.Minor)
+		if releaseSeries.Has(series) {
+			v.errorf(metadataFile, "release series %s is defined more than once", series)
+		}
+		releaseSeries.Insert(series)
+
+		if !contractRegex.MatchString(rs.Contract) {
+			v.errorf(metadataFile, "release series %s has invalid contract %q, the contract must be a Cluster API version, e.g. %s", series, rs.Contract, clusterv1.GroupVersion.Version)
+		}
+	}
+
+	parsedVersion, err := version.ParseSemantic(providerVersion)
+	if err != nil {
+		v.errorf(metadataFile, "failed to parse version %q: %v", providerVersion, err)
+		return
+	}
+	rs := metadata.GetReleaseSeriesForVersion(parsedVersion)
+	if rs == nil {
+		v.errorf(metadataFile, "no release series defined for version %s, a release series for v%d.%d is required", providerVersion, parsedVersion.Major(), parsedVersion.Minor())
+		return
+	}
+	if rs.Contract != clusterv1.GroupVersion.Version {
+		v.warningf(metadataFile, "version %s implements contract %s, while this version of clusterctl supports installing providers implementing contract %s", providerVersion, rs.Contract, clusterv1.GroupVersion.Version)
+	}
+}
+
+func (v *validator) validateComponents(ctx context.Context, repo Client, providerVersion string) {
+	file := componentsFile
+
+	raw, err := repo.Components().Raw(ctx, ComponentsOptions{Version: providerVersion})
+	if err != nil {
+		v.errorf(file, "failed to read the components: %v", err)
+		return
+	}
+
+	processor := yaml.NewSimpleProcessor()
+	variables, err := processor.GetVariables(raw)
+	if err != nil {
+		v.errorf(file, "failed to get the variables: %v", err)
+	}
+	v.validateVariableNames(file, variables)
+
+	objs, err := utilyaml.ToUnstructured(raw)
+	if err != nil {
+		v.errorf(file, "failed to parse the components: %v", err)
+		return
+	}
+
+	// The components must define a single namespace hosting all the namespaced objects; clusterctl uses it as default
+	// target namespace and changes it when a different target namespace is requested.
+	namespaces := []string{}
+	for _, o := range objs {
+		if o.GetKind() == namespaceKind {
+			namespaces = append(namespaces, o.GetName())
+		}
+	}
+	switch len(namespaces) {
+	case 0:
+		v.errorf(file, "no Namespace defined, the components must define the Namespace where the provider is installed")
+	case 1:
+		for _, o := range objs {
+			if util.IsResourceNamespaced(o.GetKind()) && o.GetNamespace() != namespaces[0] {
+				v.errorf(file, "%s %q is not in the provider Namespace %q", o.GetKind(), o.GetName(), namespaces[0])
+			}
+		}
+	default:
+		v.errorf(file, "%d Namespaces defined %v, the components must define a single Namespace where the provider is installed", len(namespaces), namespaces)
+	}
+
+	managerFound := false
+	services := sets.Set[string]{}
+	for _, o := range objs {
+		if util.IsDeploymentWithManager(o) {
+			managerFound = true
+		}
+		if o.GetKind() == "Service" {
+			services.Insert(fmt.Sprintf("%s/%s", o.GetNamespace(), o.GetName()))
+		}
+		if value, ok := o.GetLabels()[clusterv1.ProviderNameLabel]; ok && value != repo.ManifestLabel() {
+			v.warningf(file, "%s %q has label %s=%s, which is changed to %s when installing the provider", o.GetKind(), o.GetName(), clusterv1.ProviderNameLabel, value, repo.ManifestLabel())
+		}
+	}
+	if !managerFound {
+		v.errorf(file, "no Deployment with a container named %q, which clusterctl uses to identify the provider controller", "manager")
+	}
+
+	images, err := util.InspectImages(objs)
+	if err != nil {
+		v.errorf(file, "failed to get the images: %v", err)
+	}
+	for _, image := range images {
+		// Images using variables, e.g. for the tag, are validated only after the variables are substituted at install time.
+		if strings.Contains(image, "${") {
+			continue
+		}
+		if err := container.ValidateImageReference(image); err != nil {
+			v.errorf(file, "invalid image: %v", err)
+		}
+	}
+
+	for i := range objs {
+		v.validateComponentsObject(file, repo.Type(), &objs[i], services)
+	}
+}
+
+// validateComponentsObject validates the CRDs and the webhook configurations of a provider.
+func (v *validator) validateComponentsObject(file string, providerType clusterctlv1.ProviderType, o *unstructured.Unstructured, services sets.Set[string]) {
+	switch o.GetKind() {
+	case customResourceDefinitionKind:
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(o.Object, crd); err != nil {
+			v.errorf(file, "failed to parse CustomResourceDefinition %q: %v", o.GetName(), err)
+			return
+		}
+
+		// Cluster API uses the contract label on the CRDs of the objects it references, e.g. the InfrastructureCluster,
+		// to get the API version to use for the current contract.
+		if providerType != clusterctlv1.CoreProviderType {
+			if _, ok := crd.Labels[clusterv1.GroupVersion.String()]; !ok {
+				v.errorf(file, "CustomResourceDefinition %q has no %s label, which Cluster API uses to identify the API versions implementing the contract", crd.Name, clusterv1.GroupVersion.String())
+			}
+		}
+
+		if crd.Spec.Conversion != nil && crd.Spec.Conversion.Webhook != nil && crd.Spec.Conversion.Webhook.ClientConfig != nil {
+			v.validateWebhookClientConfig(file, "CustomResourceDefinition", crd.Name, crd.Annotations, crd.Spec.Conversion.Webhook.ClientConfig.Service != nil, func() (string, string) {
+				return crd.Spec.Conversion.Webhook.ClientConfig.Service.Namespace, crd.Spec.Conversion.Webhook.ClientConfig.Service.Name
+			}, len(crd.Spec.Conversion.Webhook.ClientConfig.CABundle) > 0, services)
+		}
+	case validatingWebhookConfigurationKind:
+		config := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(o.Object, config); err != nil {
+			v.errorf(file, "failed to parse ValidatingWebhookConfiguration %q: %v", o.GetName(), err)
+			return
+		}
+		for _, w := range config.Webhooks {
+			v.validateWebhookClientConfig(file, validatingWebhookConfigurationKind, config.Name, config.Annotations, w.ClientConfig.Service != nil, func() (string, string) {
+				return w.ClientConfig.Service.Namespace, w.ClientConfig.Service.Name
+			}, len(w.ClientConfig.CABundle) > 0, services)
+		}
+	case mutatingWebhookConfigurationKind:
+		config := &admissionregistrationv1.MutatingWebhookConfiguration{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(o.Object, config); err != nil {
+			v.errorf(file, "failed to parse MutatingWebhookConfiguration %q: %v", o.GetName(), err)
+			return
+		}
+		for _, w := range config.Webhooks {
+			v.validateWebhookClientConfig(file, mutatingWebhookConfigurationKind, config.Name, config.Annotations, w.ClientConfig.Service != nil, func() (string, string) {
+				return w.ClientConfig.Service.Namespace, w.ClientConfig.Service.Name
+			}, len(w.ClientConfig.CABundle) > 0, services)
+		}
+	}
+}
+
+// validateWebhookClientConfig checks a webhook is served by a Service of the provider, and that the CA bundle
+// is set, or injected by cert-manager.
+func (v *validator) validateWebhookClientConfig(file, kind, name string, annotations map[string]string, hasService bool, service func() (string, string), hasCABundle bool, services sets.Set[string]) {
+	if hasService {
+		namespace, serviceName := service()
+		if !services.Has(fmt.Sprintf("%s/%s", namespace, serviceName)) {
+			v.errorf(file, "%s %q uses Service %s/%s, which is not defined in the components", kind, name, namespace, serviceName)
+		}
+	}
+	if _, ok := annotations[certManagerInjectCA]; !ok && !hasCABundle {
+		v.warningf(file, "%s %q has no CA bundle and no %s annotation", kind, name, certManagerInjectCA)
+	}
+}
+
+func (v *validator) validateClusterTemplate(ctx context.Context, repo Client, providerVersion string) {
+	template, err := repo.Templates(providerVersion).Get(ctx, "", validationNamespace, true)
+	if err != nil {
+		v.warningf(defaultClusterTemplate, "failed to read the default cluster template, clusterctl generate cluster requires the --flavor flag: %v", err)
+		return
+	}
+
+	variables := template.Variables()
+	v.validateVariableNames(defaultClusterTemplate, variables)
+
+	// The name of the cluster passed to clusterctl generate cluster is set as CLUSTER_NAME.
+	if !slices.Contains(variables, "CLUSTER_NAME") {
+		v.errorf(defaultClusterTemplate, "the template does not use the CLUSTER_NAME variable, thus ignoring the name passed to clusterctl generate cluster")
+	}
+	for _, variable := range sets.List(sets.KeySet(templateFlagVariables)) {
+		if !slices.Contains(variables, variable) {
+			v.warningf(defaultClusterTemplate, "the template does not use the %s variable, thus ignoring the %s flag of clusterctl generate cluster", variable, templateFlagVariables[variable])
+		}
+	}
+}
+
+// validateVariableNames checks variables follow the naming convention, so they can be set as environment variables.
+func (v *validator) validateVariableNames(file string, variables []string) {
+	for _, variable := range variables {
+		if !variableRegex.MatchString(variable) {
+			v.warningf(file, "variable %q should only use upper case letters, digits and underscores", variable)
+		}
+	}
+}
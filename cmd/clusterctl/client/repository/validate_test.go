@@ -0,0 +1,247 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+const (
+	validateNamespaceYAML = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: infra-system
+`
+	validateCRDYAML = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: infraclusters.infrastructure.cluster.x-k8s.io
+  labels:
+    cluster.x-k8s.io/v1beta1: v1beta1
+spec:
+  group: infrastructure.cluster.x-k8s.io
+  names:
+    kind: InfraCluster
+  scope: Namespaced
+`
+	validateDeploymentYAML = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: infra-controller-manager
+  namespace: infra-system
+spec:
+  template:
+    spec:
+      containers:
+      - name: manager
+        image: registry.k8s.io/infra/manager:${TAG:=v1.0.0}
+`
+	validateWebhookYAML = `apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: infra-validating-webhook-configuration
+webhooks:
+- name: validation.infracluster.infrastructure.cluster.x-k8s.io
+  clientConfig:
+    service:
+      name: infra-webhook-service
+      namespace: infra-system
+`
+	validateTemplateYAML = `apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: ${CLUSTER_NAME}
+spec:
+  topology:
+    version: ${KUBERNETES_VERSION}
+    controlPlane:
+      replicas: ${CONTROL_PLANE_MACHINE_COUNT}
+    workers:
+      machineDeployments:
+      - replicas: ${WORKER_MACHINE_COUNT}
+`
+)
+
+func Test_Validate(t *testing.T) {
+	metadata := &clusterctlv1.Metadata{
+		ReleaseSeries: []clusterctlv1.ReleaseSeries{
+			{Major: 1, Minor: 0, Contract: "v1beta1"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		metadata   *clusterctlv1.Metadata
+		components []string
+		template   string
+		want       []ValidationIssue
+	}{
+		{
+			name:       "no issues for a repository following the conventions",
+			metadata:   metadata,
+			components: []string{validateNamespaceYAML, validateCRDYAML, validateDeploymentYAML},
+			template:   validateTemplateYAML,
+			want:       nil,
+		},
+		{
+			name: "reports release series issues",
+			metadata: &clusterctlv1.Metadata{
+				ReleaseSeries: []clusterctlv1.ReleaseSeries{
+					{Major: 0, Minor: 9, Contract: "v1beta1"},
+					{Major: 0, Minor: 9, Contract: "beta1"},
+				},
+			},
+			components: []string{validateNamespaceYAML, validateCRDYAML, validateDeploymentYAML},
+			template:   validateTemplateYAML,
+			want: []ValidationIssue{
+				{Severity: ValidationError, File: "metadata.yaml", Message: "release series v0.9 is defined more than once"},
+				{Severity: ValidationError, File: "metadata.yaml", Message: `release series v0.9 has invalid contract "beta1", the contract must be a Cluster API version, e.g. v1beta1`},
+				{Severity: ValidationError, File: "metadata.yaml", Message: "no release series defined for version v1.0.0, a release series for v1.0 is required"},
+			},
+		},
+		{
+			name: "reports an older contract",
+			metadata: &clusterctlv1.Metadata{
+				ReleaseSeries: []clusterctlv1.ReleaseSeries{
+					{Major: 1, Minor: 0, Contract: "v1alpha4"},
+				},
+			},
+			components: []string{validateNamespaceYAML, validateCRDYAML, validateDeploymentYAML},
+			template:   validateTemplateYAML,
+			want: []ValidationIssue{
+				{Severity: ValidationWarning, File: "metadata.yaml", Message: "version v1.0.0 implements contract v1alpha4, while this version of clusterctl supports installing providers implementing contract v1beta1"},
+			},
+		},
+		{
+			name:     "reports components without namespace, contract label and manager",
+			metadata: metadata,
+			components: []string{validateCRDYAML, `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: inframachines.infrastructure.cluster.x-k8s.io
+spec:
+  group: infrastructure.cluster.x-k8s.io
+  names:
+    kind: InfraMachine
+  scope: Namespaced
+`},
+			template: validateTemplateYAML,
+			want: []ValidationIssue{
+				{Severity: ValidationError, File: "components", Message: "no Namespace defined, the components must define the Namespace where the provider is installed"},
+				{Severity: ValidationError, File: "components", Message: `no Deployment with a container named "manager", which clusterctl uses to identify the provider controller`},
+				{Severity: ValidationError, File: "components", Message: `CustomResourceDefinition "inframachines.infrastructure.cluster.x-k8s.io" has no cluster.x-k8s.io/v1beta1 label, which Cluster API uses to identify the API versions implementing the contract`},
+			},
+		},
+		{
+			name:     "reports objects outside the provider namespace and lower case variables",
+			metadata: metadata,
+			components: []string{validateNamespaceYAML, validateCRDYAML, validateDeploymentYAML, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: infra-config
+  namespace: kube-system
+data:
+  region: ${region}
+`},
+			template: validateTemplateYAML,
+			want: []ValidationIssue{
+				{Severity: ValidationWarning, File: "components", Message: `variable "region" should only use upper case letters, digits and underscores`},
+				{Severity: ValidationError, File: "components", Message: `ConfigMap "infra-config" is not in the provider Namespace "infra-system"`},
+			},
+		},
+		{
+			name:       "reports webhooks without service and CA bundle",
+			metadata:   metadata,
+			components: []string{validateNamespaceYAML, validateCRDYAML, validateDeploymentYAML, validateWebhookYAML},
+			template:   validateTemplateYAML,
+			want: []ValidationIssue{
+				{Severity: ValidationError, File: "components", Message: `ValidatingWebhookConfiguration "infra-validating-webhook-configuration" uses Service infra-system/infra-webhook-service, which is not defined in the components`},
+				{Severity: ValidationWarning, File: "components", Message: `ValidatingWebhookConfiguration "infra-validating-webhook-configuration" has no CA bundle and no cert-manager.io/inject-ca-from annotation`},
+			},
+		},
+		{
+			name:       "reports templates not using the clusterctl generate cluster variables",
+			metadata:   metadata,
+			components: []string{validateNamespaceYAML, validateCRDYAML, validateDeploymentYAML},
+			template: `apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: my-cluster
+spec:
+  topology:
+    version: ${KUBERNETES_VERSION}
+    controlPlane:
+      replicas: ${CONTROL_PLANE_MACHINE_COUNT}
+`,
+			want: []ValidationIssue{
+				{Severity: ValidationError, File: "cluster-template.yaml", Message: "the template does not use the CLUSTER_NAME variable, thus ignoring the name passed to clusterctl generate cluster"},
+				{Severity: ValidationWarning, File: "cluster-template.yaml", Message: "the template does not use the WORKER_MACHINE_COUNT variable, thus ignoring the --worker-machine-count flag of clusterctl generate cluster"},
+			},
+		},
+		{
+			name:       "reports a missing default template",
+			metadata:   metadata,
+			components: []string{validateNamespaceYAML, validateCRDYAML, validateDeploymentYAML},
+			want: []ValidationIssue{
+				{Severity: ValidationWarning, File: "cluster-template.yaml", Message: `failed to read the default cluster template, clusterctl generate cluster requires the --flavor flag: failed to read "cluster-template.yaml" from provider's repository "infrastructure-infra": unable to get file cluster-template.yaml for version v1.0.0`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			ctx := context.Background()
+
+			repository := NewMemoryRepository().
+				WithPaths("root", "components.yaml").
+				WithDefaultVersion("v1.0.0").
+				WithFile("v1.0.0", "components.yaml", []byte(joinYAML(tt.components))).
+				WithMetadata("v1.0.0", tt.metadata)
+			if tt.template != "" {
+				repository = repository.WithFile("v1.0.0", "cluster-template.yaml", []byte(tt.template))
+			}
+
+			configClient, err := config.New(ctx, "", config.InjectReader(test.NewFakeReader()))
+			g.Expect(err).ToNot(HaveOccurred())
+
+			repoClient, err := New(ctx, config.NewProvider("infra", "url", clusterctlv1.InfrastructureProviderType), configClient, InjectRepository(repository))
+			g.Expect(err).ToNot(HaveOccurred())
+
+			g.Expect(Validate(ctx, repoClient, "")).To(Equal(tt.want))
+		})
+	}
+}
+
+func joinYAML(docs []string) string {
+	joined := ""
+	for i, doc := range docs {
+		if i > 0 {
+			joined += "---\n"
+		}
+		joined += doc
+	}
+	return joined
+}
@@ -0,0 +1,297 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package scaffold implements the generation of the skeleton of a new Cluster API provider.
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"go/format"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+)
+
+// templates contains the files of the provider skeleton; the path of each file is a template as well,
+// and files under the resource folder are rendered once for each API type of the provider.
+//
+//go:embed all:templates
+var templates embed.FS
+
+const (
+	templatesRoot = "templates"
+	resourceDir   = "resource"
+	templateExt   = ".tmpl"
+)
+
+// Options are the inputs for generating a provider skeleton.
+type Options struct {
+	// ProviderType is the type of the provider; only infrastructure, bootstrap and control plane providers are supported.
+	ProviderType clusterctlv1.ProviderType
+
+	// Name of the provider, e.g. foo for the cluster-api-provider-foo infrastructure provider.
+	Name string
+
+	// Module is the Go module of the provider. If unspecified, github.com/example/cluster-api-provider-<name> is used.
+	Module string
+
+	// APIVersion is the version of the provider API types. If unspecified, v1alpha1 is used.
+	APIVersion string
+
+	// ClusterAPIVersion is the version of the Cluster API Go module the provider depends on.
+	ClusterAPIVersion string
+
+	// ControllerRuntimeVersion is the version of the controller-runtime Go module the provider depends on;
+	// if unspecified, go mod tidy picks the version required by Cluster API.
+	ControllerRuntimeVersion string
+}
+
+// File is a file of the provider skeleton.
+type File struct {
+	// Path of the file, relative to the root of the provider repository.
+	Path string
+
+	// Content of the file.
+	Content []byte
+}
+
+// Role of an API type in the Cluster API contract.
+const (
+	infrastructureClusterRole = "InfrastructureCluster"
+	infrastructureMachineRole = "InfrastructureMachine"
+	bootstrapConfigRole       = "BootstrapConfig"
+	controlPlaneRole          = "ControlPlane"
+)
+
+// resource is an API type of the provider, implementing one of the roles defined in the Cluster API contract.
+type resource struct {
+	Role   string
+	Kind   string
+	Lower  string
+	Plural string
+}
+
+// data is the input of the templates.
+type data struct {
+	Name                     string
+	KindPrefix               string
+	Module                   string
+	APIVersion               string
+	Group                    string
+	GroupPrefix              string
+	GroupPath                string
+	APIAlias                 string
+	ProviderType             string
+	ManifestLabel            string
+	ComponentsFile           string
+	Namespace                string
+	ContractVersion          string
+	ClusterAPIVersion        string
+	ClusterAPIMajor          uint
+	ClusterAPIMinor          uint
+	ControllerRuntimeVersion string
+	Resources                []resource
+
+	// Resource is set when rendering the files of a single API type.
+	Resource resource
+}
+
+// IsInfrastructure returns true when generating an infrastructure provider.
+func (d data) IsInfrastructure() bool {
+	return d.ProviderType == string(clusterctlv1.InfrastructureProviderType)
+}
+
+// Generate returns the files of a buildable skeleton for a new provider, wired to the current Cluster API contract:
+// API types with the fields required by the contract and the v1beta2 conditions, controllers, webhooks,
+// the kustomize configuration generating the components YAML following the clusterctl conventions,
+// and an e2e test harness based on the Cluster API e2e test framework.
+func Generate(options Options) ([]File, error) {
+	d, err := newData(options)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []File{}
+	err = fs.WalkDir(templates, templatesRoot, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		relativePath := strings.TrimSuffix(strings.TrimPrefix(p, templatesRoot+"/"), templateExt)
+		content, err := templates.ReadFile(p)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read template %s", p)
+		}
+
+		// Files in the resource folder are rendered for every API type; the resource folder is removed from the path.
+		if dir, file, ok := strings.Cut(relativePath, resourceDir+"/"); ok {
+			for _, r := range d.Resources {
+				rd := *d
+				rd.Resource = r
+				f, err := render(dir+file, string(content), rd)
+				if err != nil {
+					return err
+				}
+				files = append(files, f)
+			}
+			return nil
+		}
+
+		f, err := render(relativePath, string(content), *d)
+		if err != nil {
+			return err
+		}
+		// Skip files rendered empty, e.g. files only relevant for infrastructure providers.
+		if len(bytes.TrimSpace(f.Content)) > 0 {
+			files = append(files, f)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+func newData(options Options) (*data, error) {
+	if errs := validation.IsDNS1123Label(options.Name); len(errs) > 0 {
+		return nil, errors.Errorf("invalid provider name %q: %s", options.Name, strings.Join(errs, ", "))
+	}
+	clusterAPIVersion, err := version.ParseSemantic(options.ClusterAPIVersion)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid Cluster API version %q", options.ClusterAPIVersion)
+	}
+
+	d := &data{
+		Name:                     options.Name,
+		KindPrefix:               kindPrefix(options.Name),
+		Module:                   options.Module,
+		APIVersion:               options.APIVersion,
+		ProviderType:             string(options.ProviderType),
+		ManifestLabel:            clusterctlv1.ManifestLabel(options.Name, options.ProviderType),
+		ContractVersion:          clusterv1.GroupVersion.Version,
+		ClusterAPIVersion:        options.ClusterAPIVersion,
+		ClusterAPIMajor:          clusterAPIVersion.Major(),
+		ClusterAPIMinor:          clusterAPIVersion.Minor(),
+		ControllerRuntimeVersion: options.ControllerRuntimeVersion,
+	}
+	if d.Module == "" {
+		d.Module = "github.com/example/cluster-api-provider-" + options.Name
+	}
+	if d.APIVersion == "" {
+		d.APIVersion = "v1alpha1"
+	}
+
+	switch options.ProviderType {
+	case clusterctlv1.InfrastructureProviderType:
+		d.GroupPrefix = "infrastructure"
+		d.ComponentsFile = "infrastructure-components.yaml"
+		d.Resources = []resource{
+			newResource(infrastructureClusterRole, d.KindPrefix+"Cluster"),
+			newResource(infrastructureMachineRole, d.KindPrefix+"Machine"),
+		}
+	case clusterctlv1.BootstrapProviderType:
+		d.GroupPrefix = "bootstrap"
+		d.ComponentsFile = "bootstrap-components.yaml"
+		d.Resources = []resource{
+			newResource(bootstrapConfigRole, d.KindPrefix+"Config"),
+		}
+	case clusterctlv1.ControlPlaneProviderType:
+		d.GroupPrefix = "controlplane"
+		d.ComponentsFile = "control-plane-components.yaml"
+		d.Resources = []resource{
+			newResource(controlPlaneRole, d.KindPrefix+"ControlPlane"),
+		}
+	default:
+		return nil, errors.Errorf("invalid provider type %q, the supported types are %s, %s and %s", options.ProviderType,
+			clusterctlv1.InfrastructureProviderType, clusterctlv1.BootstrapProviderType, clusterctlv1.ControlPlaneProviderType)
+	}
+	d.Group = d.GroupPrefix + ".cluster.x-k8s.io"
+	d.GroupPath = strings.ReplaceAll(d.Group, ".", "-")
+	d.APIAlias = strings.TrimSuffix(d.GroupPrefix, "structure") + "v1"
+	d.Namespace = d.ManifestLabel + "-system"
+	return d, nil
+}
+
+func newResource(role, kind string) resource {
+	return resource{
+		Role:   role,
+		Kind:   kind,
+		Lower:  strings.ToLower(kind),
+		Plural: strings.ToLower(kind) + "s",
+	}
+}
+
+// kindPrefix returns the prefix for the Kinds of the provider API types, e.g. MyCloud for the my-cloud provider.
+func kindPrefix(name string) string {
+	prefix := ""
+	for _, part := range strings.Split(name, "-") {
+		if part == "" {
+			continue
+		}
+		prefix += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return prefix
+}
+
+// render executes the templates for the path and the content of a file, formatting Go files.
+func render(filePath, content string, d data) (File, error) {
+	renderedPath, err := execute(filePath, filePath, d)
+	if err != nil {
+		return File{}, err
+	}
+	p := string(renderedPath)
+	renderedContent, err := execute(filePath, content, d)
+	if err != nil {
+		return File{}, err
+	}
+
+	if path.Ext(p) == ".go" && len(bytes.TrimSpace(renderedContent)) > 0 {
+		formatted, err := format.Source(renderedContent)
+		if err != nil {
+			return File{}, errors.Wrapf(err, "failed to format %s", p)
+		}
+		renderedContent = formatted
+	}
+	return File{Path: p, Content: renderedContent}, nil
+}
+
+func execute(name, text string, d data) ([]byte, error) {
+	t, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse template %s", name)
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, d); err != nil {
+		return nil, errors.Wrapf(err, "failed to execute template %s", name)
+	}
+	return out.Bytes(), nil
+}
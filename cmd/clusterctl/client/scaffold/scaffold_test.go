@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffold
+
+import (
+	"go/parser"
+	"go/token"
+	"path"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     Options
+		wantFiles   []string
+		unwantFiles []string
+		wantErr     bool
+	}{
+		{
+			name: "infrastructure provider",
+			options: Options{
+				ProviderType:      clusterctlv1.InfrastructureProviderType,
+				Name:              "my-cloud",
+				ClusterAPIVersion: "v1.7.0",
+			},
+			wantFiles: []string{
+				"go.mod",
+				"main.go",
+				"metadata.yaml",
+				"api/v1alpha1/groupversion_info.go",
+				"api/v1alpha1/mycloudcluster_types.go",
+				"api/v1alpha1/mycloudclustertemplate_types.go",
+				"api/v1alpha1/mycloudmachine_types.go",
+				"api/v1alpha1/mycloudmachinetemplate_types.go",
+				"internal/controllers/mycloudcluster_controller.go",
+				"internal/controllers/mycloudmachine_controller.go",
+				"internal/webhooks/mycloudcluster_webhook.go",
+				"internal/webhooks/mycloudmachinetemplate_webhook.go",
+				"config/default/kustomization.yaml",
+				"templates/cluster-template.yaml",
+				"test/e2e/e2e_suite_test.go",
+				"test/e2e/quick_start_test.go",
+				"test/e2e/config/e2e.yaml",
+				"test/e2e/data/shared/metadata.yaml",
+			},
+		},
+		{
+			name: "bootstrap provider",
+			options: Options{
+				ProviderType:      clusterctlv1.BootstrapProviderType,
+				Name:              "foo",
+				Module:            "example.com/foo",
+				APIVersion:        "v1beta1",
+				ClusterAPIVersion: "v1.7.0",
+			},
+			wantFiles: []string{
+				"api/v1beta1/fooconfig_types.go",
+				"api/v1beta1/fooconfigtemplate_types.go",
+				"internal/controllers/fooconfig_controller.go",
+			},
+			unwantFiles: []string{
+				"templates/cluster-template.yaml",
+				"test/e2e/e2e_suite_test.go",
+			},
+		},
+		{
+			name: "control plane provider",
+			options: Options{
+				ProviderType:      clusterctlv1.ControlPlaneProviderType,
+				Name:              "foo",
+				ClusterAPIVersion: "v1.7.0",
+			},
+			wantFiles: []string{
+				"api/v1alpha1/foocontrolplane_types.go",
+				"internal/controllers/foocontrolplane_controller.go",
+			},
+			unwantFiles: []string{
+				"templates/cluster-template.yaml",
+			},
+		},
+		{
+			name: "fails for an unsupported provider type",
+			options: Options{
+				ProviderType:      clusterctlv1.CoreProviderType,
+				Name:              "foo",
+				ClusterAPIVersion: "v1.7.0",
+			},
+			wantErr: true,
+		},
+		{
+			name: "fails for an invalid name",
+			options: Options{
+				ProviderType:      clusterctlv1.InfrastructureProviderType,
+				Name:              "My_Cloud",
+				ClusterAPIVersion: "v1.7.0",
+			},
+			wantErr: true,
+		},
+		{
+			name: "fails for an invalid Cluster API version",
+			options: Options{
+				ProviderType:      clusterctlv1.InfrastructureProviderType,
+				Name:              "foo",
+				ClusterAPIVersion: "main",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			files, err := Generate(tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+
+			paths := []string{}
+			for _, f := range files {
+				paths = append(paths, f.Path)
+
+				g.Expect(f.Content).ToNot(BeEmpty(), "file %s is empty", f.Path)
+				g.Expect(string(f.Content)).ToNot(ContainSubstring("<no value>"), "file %s uses an undefined template field", f.Path)
+				if path.Ext(f.Path) == ".go" {
+					_, err := parser.ParseFile(token.NewFileSet(), f.Path, f.Content, parser.AllErrors)
+					g.Expect(err).ToNot(HaveOccurred(), "file %s is not valid Go code", f.Path)
+				}
+				if f.Path == "go.mod" {
+					module := tt.options.Module
+					if module == "" {
+						module = "github.com/example/cluster-api-provider-" + tt.options.Name
+					}
+					g.Expect(strings.HasPrefix(string(f.Content), "module "+module+"\n")).To(BeTrue())
+					g.Expect(string(f.Content)).To(ContainSubstring("sigs.k8s.io/cluster-api " + tt.options.ClusterAPIVersion))
+				}
+			}
+			g.Expect(paths).To(ContainElements(tt.wantFiles))
+			for _, p := range tt.unwantFiles {
+				g.Expect(paths).ToNot(ContainElement(p))
+			}
+		})
+	}
+}
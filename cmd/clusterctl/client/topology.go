@@ -41,6 +41,11 @@ type TopologyPlanOptions struct {
 	// This namespace is used as default for objects with missing namespaces.
 	// If the namespace of any of the input objects conflicts with Namespace an error is returned.
 	Namespace string
+
+	// ServerSideDryRun instructs the operation to send the input objects and the changes computed by the
+	// topology reconciler to the management cluster as server-side dry-run requests, and to call the
+	// Runtime Extensions registered in the management cluster.
+	ServerSideDryRun bool
 }
 
 // TopologyPlanOutput defines the output of the topology plan operation.
@@ -58,6 +63,7 @@ func (c *clusterctlClient) TopologyPlan(ctx context.Context, options TopologyPla
 		Objs:              options.Objs,
 		TargetClusterName: options.Cluster,
 		TargetNamespace:   options.Namespace,
+		ServerSideDryRun:  options.ServerSideDryRun,
 	})
 
 	return out, err
@@ -54,8 +54,12 @@ type DiscoverOptions struct {
 	Echo bool
 
 	// Grouping groups machine objects in case the ready conditions
-	// have the same Status, Severity and Reason.
+	// have the same Status, Severity and Reason; when using v1beta2 conditions,
+	// machine objects are grouped in case all their conditions have the same Type, Status and Reason.
 	Grouping bool
+
+	// ConditionsFormat defines the format of the conditions used for echo and grouping; defaults to v1beta1.
+	ConditionsFormat ConditionsFormat
 }
 
 func (d DiscoverOptions) toObjectTreeOptions() ObjectTreeOptions {
@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
@@ -32,6 +33,31 @@ import (
 	"sigs.k8s.io/cluster-api/util"
 )
 
+// ConditionsFormat defines the format of the conditions used when building and presenting an ObjectTree.
+type ConditionsFormat string
+
+const (
+	// ConditionsFormatV1Beta1 uses the conditions in the Cluster API v1beta1 format, stored in status.conditions.
+	ConditionsFormatV1Beta1 ConditionsFormat = "v1beta1"
+
+	// ConditionsFormatV1Beta2 uses the conditions in the metav1.Condition format used by the Cluster API v1beta2 API,
+	// stored in status.v1beta2.conditions.
+	ConditionsFormatV1Beta2 ConditionsFormat = "v1beta2"
+
+	// ConditionsFormatBoth uses both the v1beta1 and the v1beta2 conditions.
+	ConditionsFormatBoth ConditionsFormat = "both"
+)
+
+// UseV1Beta1 returns true if the v1beta1 conditions should be used; this is the default if no format is set.
+func (f ConditionsFormat) UseV1Beta1() bool {
+	return f == "" || f == ConditionsFormatV1Beta1 || f == ConditionsFormatBoth
+}
+
+// UseV1Beta2 returns true if the v1beta2 conditions should be used.
+func (f ConditionsFormat) UseV1Beta2() bool {
+	return f == ConditionsFormatV1Beta2 || f == ConditionsFormatBoth
+}
+
 // ObjectTreeOptions defines the options for an ObjectTree.
 type ObjectTreeOptions struct {
 	// ShowOtherConditions is a list of comma separated kind or kind/name for which we should add   the ShowObjectConditionsAnnotation
@@ -55,8 +81,12 @@ type ObjectTreeOptions struct {
 	Echo bool
 
 	// Grouping groups sibling object in case the ready conditions
-	// have the same Status, Severity and Reason
+	// have the same Status, Severity and Reason; when using v1beta2 conditions,
+	// sibling objects are grouped in case all their conditions have the same Type, Status and Reason.
 	Grouping bool
+
+	// ConditionsFormat defines the format of the conditions used for echo and grouping; defaults to v1beta1.
+	ConditionsFormat ConditionsFormat
 }
 
 // ObjectTree defines an object tree representing the status of a Cluster API cluster.
@@ -92,7 +122,6 @@ func (od ObjectTree) Add(parent, obj client.Object, opts ...AddObjectOption) (ad
 	addOpts.ApplyOptions(opts)
 
 	objReady := GetReadyCondition(obj)
-	parentReady := GetReadyCondition(parent)
 
 	// If it is requested to show all the conditions for the object, add
 	// the ShowObjectConditionsAnnotation to signal this to the presentation layer.
@@ -103,10 +132,8 @@ func (od ObjectTree) Add(parent, obj client.Object, opts ...AddObjectOption) (ad
 	// If the object should be hidden if the object's ready condition is true ot it has the
 	// same Status, Severity and Reason of the parent's object ready condition (it is an echo),
 	// return early.
-	if addOpts.NoEcho && !od.options.Echo {
-		if (objReady != nil && objReady.Status == corev1.ConditionTrue) || hasSameReadyStatusSeverityAndReason(parentReady, objReady) {
-			return false, false
-		}
+	if addOpts.NoEcho && !od.options.Echo && od.isEcho(parent, obj) {
+		return false, false
 	}
 
 	// If it is requested to use a meta name for the object in the presentation layer, add
@@ -133,9 +160,9 @@ func (od ObjectTree) Add(parent, obj client.Object, opts ...AddObjectOption) (ad
 			s := siblings[i]
 			sReady := GetReadyCondition(s)
 
-			// If the object's ready condition has a different Status, Severity and Reason than the sibling object,
+			// If the object's conditions are different from the conditions of the sibling object,
 			// move on (they should not be grouped).
-			if !hasSameReadyStatusSeverityAndReason(objReady, sReady) {
+			if !od.hasSameConditions(obj, s) {
 				continue
 			}
 
@@ -216,6 +243,36 @@ func (od ObjectTree) GetObjectsByParent(id types.UID) []client.Object {
 	return out
 }
 
+// isEcho returns true if the object's state is already represented by its parent, i.e. for each conditions format in use, the
+// object's ready condition is true or it has the same Status, Severity and Reason of the parent's ready condition.
+func (od ObjectTree) isEcho(parent, obj client.Object) bool {
+	if od.options.ConditionsFormat.UseV1Beta1() {
+		objReady := GetReadyCondition(obj)
+		if !(objReady != nil && objReady.Status == corev1.ConditionTrue) && !hasSameReadyStatusSeverityAndReason(GetReadyCondition(parent), objReady) {
+			return false
+		}
+	}
+	if od.options.ConditionsFormat.UseV1Beta2() {
+		objReady := GetV1Beta2Condition(obj, clusterv1.ReadyV1Beta2Condition)
+		if !(objReady != nil && objReady.Status == metav1.ConditionTrue) && !hasSameStatusAndReason(GetV1Beta2Condition(parent, clusterv1.ReadyV1Beta2Condition), objReady) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasSameConditions returns true if two objects should be grouped, i.e. for each conditions format in use, they have the
+// same ready condition Status, Severity and Reason (v1beta1) or the same Type, Status and Reason for all the conditions (v1beta2).
+func (od ObjectTree) hasSameConditions(a, b client.Object) bool {
+	if od.options.ConditionsFormat.UseV1Beta1() && !hasSameReadyStatusSeverityAndReason(GetReadyCondition(a), GetReadyCondition(b)) {
+		return false
+	}
+	if od.options.ConditionsFormat.UseV1Beta2() && !hasSameV1Beta2Conditions(GetV1Beta2Conditions(a), GetV1Beta2Conditions(b)) {
+		return false
+	}
+	return true
+}
+
 func hasSameReadyStatusSeverityAndReason(a, b *clusterv1.Condition) bool {
 	if a == nil && b == nil {
 		return true
@@ -229,6 +286,31 @@ func hasSameReadyStatusSeverityAndReason(a, b *clusterv1.Condition) bool {
 		a.Reason == b.Reason
 }
 
+func hasSameStatusAndReason(a, b *metav1.Condition) bool {
+	if a == nil && b == nil {
+		return true
+	}
+	if (a == nil) != (b == nil) {
+		return false
+	}
+
+	return a.Status == b.Status &&
+		a.Reason == b.Reason
+}
+
+func hasSameV1Beta2Conditions(a, b []metav1.Condition) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		c := meta.FindStatusCondition(b, a[i].Type)
+		if c == nil || !hasSameStatusAndReason(&a[i], c) {
+			return false
+		}
+	}
+	return true
+}
+
 func createGroupNode(sibling client.Object, siblingReady *clusterv1.Condition, obj client.Object, objReady *clusterv1.Condition) *unstructured.Unstructured {
 	kind := fmt.Sprintf("%sGroup", obj.GetObjectKind().GroupVersionKind().Kind)
 
@@ -249,6 +331,9 @@ func createGroupNode(sibling client.Object, siblingReady *clusterv1.Condition, o
 		objReady.Message = ""
 		setReadyCondition(groupNode, objReady)
 	}
+
+	// Update the group's v1beta2 conditions.
+	setGroupV1Beta2Conditions(groupNode, GetV1Beta2Conditions(obj), GetV1Beta2Conditions(sibling))
 	return groupNode
 }
 
@@ -289,6 +374,26 @@ func updateGroupNode(groupObj client.Object, groupReady *clusterv1.Condition, ob
 		groupReady.Message = ""
 		setReadyCondition(groupObj, groupReady)
 	}
+
+	// Update the group's v1beta2 conditions.
+	setGroupV1Beta2Conditions(groupObj, GetV1Beta2Conditions(groupObj), GetV1Beta2Conditions(obj))
+}
+
+// setGroupV1Beta2Conditions sets on a group object the v1beta2 conditions of an object in the group, using the oldest
+// transition time among the ones of the other object and dropping messages that could be different for each object in the group.
+func setGroupV1Beta2Conditions(groupObj client.Object, objConditions, otherConditions []metav1.Condition) {
+	if len(objConditions) == 0 {
+		return
+	}
+	groupConditions := make([]metav1.Condition, 0, len(objConditions))
+	for _, c := range objConditions {
+		if other := meta.FindStatusCondition(otherConditions, c.Type); other != nil && other.LastTransitionTime.Before(&c.LastTransitionTime) {
+			c.LastTransitionTime = other.LastTransitionTime
+		}
+		c.Message = ""
+		groupConditions = append(groupConditions, c)
+	}
+	setV1Beta2Conditions(groupObj, groupConditions)
 }
 
 func isObjDebug(obj client.Object, debugFilter string) bool {
@@ -724,6 +724,124 @@ func Test_Add_Grouping(t *testing.T) {
 	}
 }
 
+func Test_Add_GroupingV1Beta2(t *testing.T) {
+	parent := fakeCluster("parent",
+		withClusterAnnotation(GroupingObjectAnnotation, "True"),
+	)
+
+	ready := metav1.Condition{Type: clusterv1.ReadyV1Beta2Condition, Status: metav1.ConditionTrue, Reason: "Ready"}
+	upToDate := metav1.Condition{Type: "UpToDate", Status: metav1.ConditionTrue, Reason: "UpToDate"}
+	notUpToDate := metav1.Condition{Type: "UpToDate", Status: metav1.ConditionFalse, Reason: "NotUpToDate"}
+
+	tests := []struct {
+		name             string
+		conditionsFormat ConditionsFormat
+		siblings         []client.Object
+		obj              client.Object
+		wantNodesPrefix  []string
+		wantVisible      bool
+	}{
+		{
+			name:             "should group child node if it has the same v1beta2 conditions of an existing one",
+			conditionsFormat: ConditionsFormatV1Beta2,
+			siblings:         []client.Object{fakeV1Beta2Machine("first-machine", ready, upToDate)},
+			obj:              fakeV1Beta2Machine("second-machine", ready, upToDate),
+			wantNodesPrefix:  []string{"zz"},
+			wantVisible:      false,
+		},
+		{
+			name:             "should not group child node if it has a different v1beta2 condition",
+			conditionsFormat: ConditionsFormatV1Beta2,
+			siblings:         []client.Object{fakeV1Beta2Machine("first-machine", ready, upToDate)},
+			obj:              fakeV1Beta2Machine("second-machine", ready, notUpToDate),
+			wantNodesPrefix:  []string{"first-machine", "second-machine"},
+			wantVisible:      true,
+		},
+		{
+			name:             "should not group child node if it has a different set of v1beta2 conditions",
+			conditionsFormat: ConditionsFormatV1Beta2,
+			siblings:         []client.Object{fakeV1Beta2Machine("first-machine", ready, upToDate)},
+			obj:              fakeV1Beta2Machine("second-machine", ready),
+			wantNodesPrefix:  []string{"first-machine", "second-machine"},
+			wantVisible:      true,
+		},
+		{
+			name:             "should group child node with different v1beta2 conditions when using v1beta1 conditions",
+			conditionsFormat: ConditionsFormatV1Beta1,
+			siblings:         []client.Object{fakeV1Beta2Machine("first-machine", ready, upToDate)},
+			obj:              fakeV1Beta2Machine("second-machine", ready, notUpToDate),
+			wantNodesPrefix:  []string{"zz"},
+			wantVisible:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := parent.DeepCopy()
+			tree := NewObjectTree(root, ObjectTreeOptions{ConditionsFormat: tt.conditionsFormat})
+
+			for i := range tt.siblings {
+				tree.Add(parent, tt.siblings[i])
+			}
+
+			g := NewWithT(t)
+			getAdded, gotVisible := tree.Add(root, tt.obj)
+			g.Expect(getAdded).To(BeTrue())
+			g.Expect(gotVisible).To(Equal(tt.wantVisible))
+
+			gotObjs := tree.GetObjectsByParent("parent")
+			g.Expect(gotObjs).To(HaveLen(len(tt.wantNodesPrefix)))
+			for _, obj := range gotObjs {
+				found := false
+				for _, prefix := range tt.wantNodesPrefix {
+					if strings.HasPrefix(obj.GetName(), prefix) {
+						found = true
+						break
+					}
+				}
+				g.Expect(found).To(BeTrue(), "Found object with name %q, waiting for one of %s", obj.GetName(), tt.wantNodesPrefix)
+
+				if IsGroupObject(obj) && tt.conditionsFormat.UseV1Beta2() {
+					// The group gets the v1beta2 conditions of the grouped objects, without messages.
+					g.Expect(GetV1Beta2Conditions(obj)).To(ConsistOf(
+						HaveField("Type", ready.Type),
+						HaveField("Type", upToDate.Type),
+					))
+				}
+			}
+		})
+	}
+}
+
+func Test_GetV1Beta2Conditions(t *testing.T) {
+	g := NewWithT(t)
+
+	// NOTE: Conditions read from unstructured objects have the transition time in the local time zone and truncated to seconds.
+	now := metav1.Time{Time: time.Now().Local().Truncate(time.Second)}
+	available := metav1.Condition{Type: clusterv1.AvailableV1Beta2Condition, Status: metav1.ConditionFalse, Reason: "NotAvailable", LastTransitionTime: now}
+	ready := metav1.Condition{Type: clusterv1.ReadyV1Beta2Condition, Status: metav1.ConditionTrue, Reason: "Ready", LastTransitionTime: now}
+	paused := metav1.Condition{Type: clusterv1.PausedV1Beta2Condition, Status: metav1.ConditionFalse, Reason: "NotPaused", LastTransitionTime: now}
+
+	// Objects without v1beta2 conditions.
+	g.Expect(GetV1Beta2Conditions(fakeMachine("m"))).To(BeEmpty())
+	g.Expect(GetV1Beta2SummaryCondition(VirtualObject("ns", "Workers", "workers"))).To(BeNil())
+
+	// The Available condition is used as summary if defined.
+	obj := fakeV1Beta2Machine("m", ready, available, paused)
+	g.Expect(GetV1Beta2Conditions(obj)).To(Equal([]metav1.Condition{ready, available, paused}))
+	g.Expect(GetV1Beta2SummaryCondition(obj)).To(Equal(&available))
+	g.Expect(GetOtherV1Beta2Conditions(obj)).To(Equal([]metav1.Condition{paused, ready}))
+
+	// The Ready condition is used as summary otherwise.
+	obj = fakeV1Beta2Machine("m", paused, ready)
+	g.Expect(GetV1Beta2SummaryCondition(obj)).To(Equal(&ready))
+	g.Expect(GetOtherV1Beta2Conditions(obj)).To(Equal([]metav1.Condition{paused}))
+
+	// Other conditions are sorted by severity.
+	bootstrapReady := metav1.Condition{Type: "BootstrapConfigReady", Status: metav1.ConditionFalse, Reason: "NotReady", LastTransitionTime: now}
+	obj = fakeV1Beta2Machine("m", paused, ready, bootstrapReady)
+	g.Expect(GetOtherV1Beta2Conditions(obj)).To(Equal([]metav1.Condition{bootstrapReady, paused}))
+}
+
 type clusterOption func(*clusterv1.Cluster)
 
 func fakeCluster(name string, options ...clusterOption) *clusterv1.Cluster {
@@ -782,3 +900,14 @@ func withMachineCondition(c *clusterv1.Condition) func(*clusterv1.Machine) {
 		conditions.Set(m, c)
 	}
 }
+
+func fakeV1Beta2Machine(name string, conditions ...metav1.Condition) *unstructured.Unstructured {
+	m := &unstructured.Unstructured{}
+	m.SetAPIVersion(clusterv1.GroupVersion.String())
+	m.SetKind("Machine")
+	m.SetNamespace("ns")
+	m.SetName(name)
+	m.SetUID(types.UID(name))
+	setV1Beta2Conditions(m, conditions)
+	return m
+}
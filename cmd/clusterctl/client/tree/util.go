@@ -21,11 +21,14 @@ import (
 	"sort"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	v1beta2conditions "sigs.k8s.io/cluster-api/util/conditions/v1beta2"
 )
 
 // GetReadyCondition returns the ReadyCondition for an object, if defined.
@@ -56,6 +59,58 @@ func GetOtherConditions(obj client.Object) []*clusterv1.Condition {
 	return conditions
 }
 
+// GetV1Beta2Conditions returns the v1beta2 conditions for an object, if defined.
+// The conditions are read from objects implementing GetV1Beta2Conditions or, for unstructured objects,
+// from status.v1beta2.conditions.
+func GetV1Beta2Conditions(obj client.Object) []metav1.Condition {
+	if getter, ok := obj.(v1beta2conditions.Getter); ok {
+		return getter.GetV1Beta2Conditions()
+	}
+
+	objUnstructured, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+	return v1beta2conditions.UnstructuredGetter(objUnstructured).GetV1Beta2Conditions()
+}
+
+// GetV1Beta2Condition returns the v1beta2 condition with the given type for an object, if defined.
+func GetV1Beta2Condition(obj client.Object, conditionType string) *metav1.Condition {
+	return meta.FindStatusCondition(GetV1Beta2Conditions(obj), conditionType)
+}
+
+// GetV1Beta2SummaryCondition returns the v1beta2 condition summarizing the state of an object, if defined; the
+// Available condition is used if defined, e.g. for Clusters or MachineDeployments, otherwise the Ready condition is used,
+// e.g. for Machines.
+func GetV1Beta2SummaryCondition(obj client.Object) *metav1.Condition {
+	if available := GetV1Beta2Condition(obj, clusterv1.AvailableV1Beta2Condition); available != nil {
+		return available
+	}
+	return GetV1Beta2Condition(obj, clusterv1.ReadyV1Beta2Condition)
+}
+
+// GetOtherV1Beta2Conditions returns the other v1beta2 conditions (all the conditions except the summary condition)
+// for an object, if defined; conditions are sorted by severity, from the most to the least severe.
+func GetOtherV1Beta2Conditions(obj client.Object) []metav1.Condition {
+	summary := GetV1Beta2SummaryCondition(obj)
+	var conditions []metav1.Condition
+	for _, c := range GetV1Beta2Conditions(obj) {
+		if summary == nil || c.Type != summary.Type {
+			conditions = append(conditions, c)
+		}
+	}
+	v1beta2conditions.SortBySeverity(conditions)
+	return conditions
+}
+
+func setV1Beta2Conditions(obj client.Object, conditions []metav1.Condition) {
+	objUnstructured, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	v1beta2conditions.UnstructuredSetter(objUnstructured).SetV1Beta2Conditions(conditions)
+}
+
 func setReadyCondition(obj client.Object, ready *clusterv1.Condition) {
 	setter := objToSetter(obj)
 	if setter == nil {
@@ -35,6 +35,17 @@ const upgradeItemProviderNameError = "invalid provider name %q. Provider name sh
 type PlanUpgradeOptions struct {
 	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty, default discovery rules apply.
 	Kubeconfig Kubeconfig
+
+	// NoContractChanges drops the upgrade plans changing the API Version of Cluster API (contract) of the management cluster.
+	NoContractChanges bool
+
+	// MaxVersionChange limits the next versions of the providers to the versions within the given change from the
+	// current version, e.g. "patch" allows only patch releases. If empty, any version is allowed.
+	MaxVersionChange cluster.UpgradeVersionChange
+
+	// IncludeCRDChanges reports, for each provider with a next version, how the upgrade changes its CRDs,
+	// e.g. API versions added or removed, or storage versions changing.
+	IncludeCRDChanges bool
 }
 
 func (c *clusterctlClient) PlanCertManagerUpgrade(ctx context.Context, options PlanUpgradeOptions) (CertManagerUpgradePlan, error) {
@@ -66,7 +77,11 @@ func (c *clusterctlClient) PlanUpgrade(ctx context.Context, options PlanUpgradeO
 		return nil, err
 	}
 
-	upgradePlans, err := clusterClient.ProviderUpgrader().Plan(ctx)
+	upgradePlans, err := clusterClient.ProviderUpgrader().PlanWithOptions(ctx, cluster.UpgradePlanOptions{
+		NoContractChanges: options.NoContractChanges,
+		MaxVersionChange:  options.MaxVersionChange,
+		IncludeCRDChanges: options.IncludeCRDChanges,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -123,9 +138,28 @@ type ApplyUpgradeOptions struct {
 
 	// WaitProviderTimeout sets the timeout per provider upgrade.
 	WaitProviderTimeout time.Duration
+
+	// WaitCRDsTimeout, WaitDeploymentsTimeout and WaitWebhooksTimeout set the timeouts for the CRDs of the providers
+	// to be established, for the provider Deployments to be available and for the provider webhooks to be reachable;
+	// if a timeout is not set, WaitProviderTimeout is used.
+	WaitCRDsTimeout        time.Duration
+	WaitDeploymentsTimeout time.Duration
+	WaitWebhooksTimeout    time.Duration
+
+	// MigrateStorageVersions instructs the upgrade apply command to migrate the objects of the upgraded providers
+	// to the storage version of their CRDs, after waiting for the providers to be successfully upgraded.
+	MigrateStorageVersions bool
+
+	// ExternalCertificates instructs clusterctl to use webhook serving certificates supplied in existing Secrets,
+	// with the names defined in the cert-manager Certificates of the providers, instead of installing cert-manager.
+	ExternalCertificates bool
+
+	// ExternalCABundle is the PEM encoded CA bundle set in the webhook configurations when using ExternalCertificates;
+	// if empty, the ca.crt key of the Secrets is used.
+	ExternalCABundle []byte
 }
 
-func (c *clusterctlClient) ApplyUpgrade(ctx context.Context, options ApplyUpgradeOptions) error {
+func (c *clusterctlClient) ApplyUpgrade(ctx context.Context, options ApplyUpgradeOptions) (retErr error) {
 	if options.Contract != "" && options.Contract != clusterv1.GroupVersion.Version {
 		return errors.Errorf("current version of clusterctl could only upgrade to %s contract, requested %s", clusterv1.GroupVersion.Version, options.Contract)
 	}
@@ -142,6 +176,9 @@ func (c *clusterctlClient) ApplyUpgrade(ctx context.Context, options ApplyUpgrad
 		return err
 	}
 
+	audit := c.startAuditedOperation(ctx, clusterClient, cluster.UpgradeOperation)
+	defer func() { audit.complete(ctx, retErr) }()
+
 	// Ensure this command only runs against management clusters with the current Cluster API contract.
 	if err := clusterClient.ProviderInventory().CheckCAPIContract(ctx); err != nil {
 		return err
@@ -156,11 +193,88 @@ func (c *clusterctlClient) ApplyUpgrade(ctx context.Context, options ApplyUpgrad
 	// NOTE: it is safe to upgrade to latest version of cert-manager given that it provides
 	// conversion web-hooks around Issuer/Certificate kinds, so installing an older versions of providers
 	// should continue to work with the latest cert-manager.
-	certManager := clusterClient.CertManager()
-	if err := certManager.EnsureLatestVersion(ctx); err != nil {
+	// When the webhook serving certificates are supplied in existing Secrets, cert-manager is not required.
+	if !options.ExternalCertificates {
+		certManager := clusterClient.CertManager()
+		if err := certManager.EnsureLatestVersion(ctx); err != nil {
+			return err
+		}
+	}
+
+	opts := cluster.UpgradeOptions{
+		WaitProviders:       options.WaitProviders,
+		WaitProviderTimeout: options.WaitProviderTimeout,
+		ReadinessTimeouts: cluster.ProviderReadinessTimeouts{
+			CRDs:        options.WaitCRDsTimeout,
+			Deployments: options.WaitDeploymentsTimeout,
+			Webhooks:    options.WaitWebhooksTimeout,
+		},
+		MigrateStorageVersions: options.MigrateStorageVersions,
+		ExternalCertificates: cluster.ExternalCertificatesOptions{
+			Enabled:  options.ExternalCertificates,
+			CABundle: options.ExternalCABundle,
+		},
+	}
+
+	// If we are upgrading a specific set of providers only, process the providers and call ApplyCustomPlan.
+	upgradeItems, err := customUpgradeItems(ctx, clusterClient, options)
+	if err != nil {
 		return err
 	}
+	if upgradeItems != nil {
+		// Execute the upgrade using the custom upgrade items
+		return clusterClient.ProviderUpgrader().ApplyCustomPlan(ctx, opts, upgradeItems...)
+	}
+
+	// Otherwise we are upgrading a whole management cluster according to a clusterctl generated upgrade plan.
+	return clusterClient.ProviderUpgrader().ApplyPlan(ctx, opts, options.Contract)
+}
+
+// DiffUpgrade returns the changes that ApplyUpgrade applies to the management cluster with the given options,
+// without applying them.
+// NOTE: WaitProviders, WaitProviderTimeout and MigrateStorageVersions are ignored, and differently from ApplyUpgrade
+// the clusterctl CRDs and cert-manager are not upgraded.
+func (c *clusterctlClient) DiffUpgrade(ctx context.Context, options ApplyUpgradeOptions) ([]ProviderUpgradeDiff, error) {
+	if options.Contract != "" && options.Contract != clusterv1.GroupVersion.Version {
+		return nil, errors.Errorf("current version of clusterctl could only upgrade to %s contract, requested %s", clusterv1.GroupVersion.Version, options.Contract)
+	}
+
+	// Get the client for interacting with the management cluster.
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure this command only runs against management clusters with the current Cluster API contract.
+	if err := clusterClient.ProviderInventory().CheckCAPIContract(ctx); err != nil {
+		return nil, err
+	}
+
+	upgradeItems, err := customUpgradeItems(ctx, clusterClient, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []cluster.ProviderUpgradeDiff
+	if upgradeItems != nil {
+		diffs, err = clusterClient.ProviderUpgrader().DiffCustomPlan(ctx, upgradeItems...)
+	} else {
+		diffs, err = clusterClient.ProviderUpgrader().DiffPlan(ctx, options.Contract)
+	}
+	if err != nil {
+		return nil, err
+	}
 
+	ret := make([]ProviderUpgradeDiff, 0, len(diffs))
+	for _, diff := range diffs {
+		ret = append(ret, ProviderUpgradeDiff(diff))
+	}
+	return ret, nil
+}
+
+// customUpgradeItems converts the upgrade references in the options back into UpgradeItems;
+// it returns nil if the options do not define a custom upgrade, e.g. when upgrading by contract.
+func customUpgradeItems(ctx context.Context, clusterClient cluster.Client, options ApplyUpgradeOptions) ([]cluster.UpgradeItem, error) {
 	// Check if the user want a custom upgrade
 	isCustomUpgrade := options.CoreProvider != "" ||
 		len(options.BootstrapProviders) > 0 ||
@@ -169,54 +283,43 @@ func (c *clusterctlClient) ApplyUpgrade(ctx context.Context, options ApplyUpgrad
 		len(options.IPAMProviders) > 0 ||
 		len(options.RuntimeExtensionProviders) > 0 ||
 		len(options.AddonProviders) > 0
-
-	opts := cluster.UpgradeOptions{
-		WaitProviders:       options.WaitProviders,
-		WaitProviderTimeout: options.WaitProviderTimeout,
+	if !isCustomUpgrade {
+		return nil, nil
 	}
 
-	// If we are upgrading a specific set of providers only, process the providers and call ApplyCustomPlan.
-	if isCustomUpgrade {
-		// Converts upgrade references back into an UpgradeItem.
-		upgradeItems := []cluster.UpgradeItem{}
-
-		if options.CoreProvider != "" {
-			upgradeItems, err = addUpgradeItems(ctx, clusterClient, upgradeItems, clusterctlv1.CoreProviderType, options.CoreProvider)
-			if err != nil {
-				return err
-			}
-		}
-		upgradeItems, err = addUpgradeItems(ctx, clusterClient, upgradeItems, clusterctlv1.BootstrapProviderType, options.BootstrapProviders...)
-		if err != nil {
-			return err
-		}
-		upgradeItems, err = addUpgradeItems(ctx, clusterClient, upgradeItems, clusterctlv1.ControlPlaneProviderType, options.ControlPlaneProviders...)
-		if err != nil {
-			return err
-		}
-		upgradeItems, err = addUpgradeItems(ctx, clusterClient, upgradeItems, clusterctlv1.InfrastructureProviderType, options.InfrastructureProviders...)
+	upgradeItems := []cluster.UpgradeItem{}
+	var err error
+	if options.CoreProvider != "" {
+		upgradeItems, err = addUpgradeItems(ctx, clusterClient, upgradeItems, clusterctlv1.CoreProviderType, options.CoreProvider)
 		if err != nil {
-			return err
-		}
-		upgradeItems, err = addUpgradeItems(ctx, clusterClient, upgradeItems, clusterctlv1.IPAMProviderType, options.IPAMProviders...)
-		if err != nil {
-			return err
-		}
-		upgradeItems, err = addUpgradeItems(ctx, clusterClient, upgradeItems, clusterctlv1.RuntimeExtensionProviderType, options.RuntimeExtensionProviders...)
-		if err != nil {
-			return err
-		}
-		upgradeItems, err = addUpgradeItems(ctx, clusterClient, upgradeItems, clusterctlv1.AddonProviderType, options.AddonProviders...)
-		if err != nil {
-			return err
+			return nil, err
 		}
-
-		// Execute the upgrade using the custom upgrade items
-		return clusterClient.ProviderUpgrader().ApplyCustomPlan(ctx, opts, upgradeItems...)
 	}
-
-	// Otherwise we are upgrading a whole management cluster according to a clusterctl generated upgrade plan.
-	return clusterClient.ProviderUpgrader().ApplyPlan(ctx, opts, options.Contract)
+	upgradeItems, err = addUpgradeItems(ctx, clusterClient, upgradeItems, clusterctlv1.BootstrapProviderType, options.BootstrapProviders...)
+	if err != nil {
+		return nil, err
+	}
+	upgradeItems, err = addUpgradeItems(ctx, clusterClient, upgradeItems, clusterctlv1.ControlPlaneProviderType, options.ControlPlaneProviders...)
+	if err != nil {
+		return nil, err
+	}
+	upgradeItems, err = addUpgradeItems(ctx, clusterClient, upgradeItems, clusterctlv1.InfrastructureProviderType, options.InfrastructureProviders...)
+	if err != nil {
+		return nil, err
+	}
+	upgradeItems, err = addUpgradeItems(ctx, clusterClient, upgradeItems, clusterctlv1.IPAMProviderType, options.IPAMProviders...)
+	if err != nil {
+		return nil, err
+	}
+	upgradeItems, err = addUpgradeItems(ctx, clusterClient, upgradeItems, clusterctlv1.RuntimeExtensionProviderType, options.RuntimeExtensionProviders...)
+	if err != nil {
+		return nil, err
+	}
+	upgradeItems, err = addUpgradeItems(ctx, clusterClient, upgradeItems, clusterctlv1.AddonProviderType, options.AddonProviders...)
+	if err != nil {
+		return nil, err
+	}
+	return upgradeItems, nil
 }
 
 func addUpgradeItems(ctx context.Context, clusterClient cluster.Client, upgradeItems []cluster.UpgradeItem, providerType clusterctlv1.ProviderType, providers ...string) ([]cluster.UpgradeItem, error) {
@@ -133,6 +133,33 @@ func Test_clusterctlClient_PlanUpgrade(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "does not return error if only patch releases are allowed",
+			fields: fields{
+				client: fakeClientForUpgrade(), // core v1.0.0 (v1.0.1 available), infra v2.0.0 (v2.0.1 available)
+			},
+			args: args{
+				options: PlanUpgradeOptions{
+					Kubeconfig:        Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					NoContractChanges: true,
+					MaxVersionChange:  cluster.UpgradeVersionChangePatch,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "returns an error if the max version change is not valid",
+			fields: fields{
+				client: fakeClientForUpgrade(), // core v1.0.0 (v1.0.1 available), infra v2.0.0 (v2.0.1 available)
+			},
+			args: args{
+				options: PlanUpgradeOptions{
+					Kubeconfig:       Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					MaxVersionChange: "major",
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
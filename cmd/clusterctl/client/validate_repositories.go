@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+)
+
+// ValidateRepositoriesOptions carries the options supported by ValidateRepositories.
+type ValidateRepositoriesOptions struct {
+	// Providers to validate, in the form type-name[:version], e.g. infrastructure-aws:v2.4.0 or cluster-api;
+	// if unspecified, all the configured providers are validated. When the version is unspecified, the default
+	// version of the provider repository is validated, e.g. the latest release.
+	Providers []string
+}
+
+// RepositoryValidation is the result of validating a provider repository.
+type RepositoryValidation struct {
+	// Provider whose repository is validated.
+	Provider Provider
+
+	// Version of the provider validated.
+	Version string
+
+	// Issues found in the provider repository.
+	Issues []ValidationIssue
+}
+
+// HasErrors returns true if any issue with Error severity was found.
+func (v RepositoryValidation) HasErrors() bool {
+	for _, issue := range v.Issues {
+		if issue.Severity == repository.ValidationError {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *clusterctlClient) ValidateRepositories(ctx context.Context, options ValidateRepositoriesOptions) ([]RepositoryValidation, error) {
+	providers, err := c.configClient.Providers().List()
+	if err != nil {
+		return nil, err
+	}
+
+	// Gets the providers to validate, with the requested versions.
+	versions := map[string]string{}
+	if len(options.Providers) > 0 {
+		selected := []config.Provider{}
+		for _, p := range options.Providers {
+			name, version, err := parseProviderName(p)
+			if err != nil {
+				return nil, err
+			}
+			provider, ok := providerByManifestLabel(providers, name)
+			if !ok {
+				return nil, errors.Errorf("failed to get configuration for provider %q; the provider name should be in the form type-name, e.g. infrastructure-aws", name)
+			}
+			selected = append(selected, provider)
+			versions[name] = version
+		}
+		providers = selected
+	}
+
+	validations := make([]RepositoryValidation, 0, len(providers))
+	for _, provider := range providers {
+		repo, err := c.repositoryClientFactory(ctx, RepositoryClientFactoryInput{Provider: provider})
+		if err != nil {
+			return nil, err
+		}
+
+		version := versions[provider.ManifestLabel()]
+		if version == "" {
+			version = repo.DefaultVersion()
+		}
+
+		validation := RepositoryValidation{Provider: provider, Version: version}
+		for _, issue := range repository.Validate(ctx, repo, version) {
+			validation.Issues = append(validation.Issues, ValidationIssue(issue))
+		}
+		validations = append(validations, validation)
+	}
+	return validations, nil
+}
+
+// providerByManifestLabel returns the provider with the given manifest label, e.g. infrastructure-aws.
+func providerByManifestLabel(providers []config.Provider, label string) (config.Provider, bool) {
+	for _, p := range providers {
+		if p.ManifestLabel() == label {
+			return p, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+func Test_clusterctlClient_ValidateRepositories(t *testing.T) {
+	ctx := context.Background()
+
+	bootstrapConfig := config.NewProvider("p1", "url", clusterctlv1.BootstrapProviderType)
+	infraConfig := config.NewProvider("p2", "url", clusterctlv1.InfrastructureProviderType)
+
+	config1 := newFakeConfig(ctx).
+		WithProvider(bootstrapConfig).
+		WithProvider(infraConfig)
+
+	metadata := &clusterctlv1.Metadata{
+		ReleaseSeries: []clusterctlv1.ReleaseSeries{
+			{Major: 1, Minor: 0, Contract: "v1beta1"},
+		},
+	}
+	bootstrapRepository := newFakeRepository(ctx, bootstrapConfig, config1).
+		WithPaths("root", "components.yaml").
+		WithDefaultVersion("v1.0.0").
+		WithFile("v1.0.0", "components.yaml", componentsYAML("ns1")).
+		WithMetadata("v1.0.0", metadata)
+	infraRepository := newFakeRepository(ctx, infraConfig, config1).
+		WithPaths("root", "components.yaml").
+		WithDefaultVersion("v1.1.0").
+		WithVersions("v1.0.0", "v1.1.0").
+		WithFile("v1.0.0", "components.yaml", componentsYAML("ns2")).
+		WithFile("v1.1.0", "components.yaml", componentsYAML("ns2")).
+		WithMetadata("v1.0.0", metadata).
+		WithMetadata("v1.1.0", metadata)
+
+	client := newFakeClient(ctx, config1).
+		WithRepository(bootstrapRepository).
+		WithRepository(infraRepository)
+
+	tests := []struct {
+		name         string
+		providers    []string
+		wantVersions map[string]string
+		wantErr      bool
+	}{
+		{
+			name:         "validates the default version of the providers",
+			providers:    []string{"bootstrap-p1", "infrastructure-p2"},
+			wantVersions: map[string]string{"bootstrap-p1": "v1.0.0", "infrastructure-p2": "v1.1.0"},
+		},
+		{
+			name:         "validates the requested providers and versions",
+			providers:    []string{"infrastructure-p2:v1.0.0"},
+			wantVersions: map[string]string{"infrastructure-p2": "v1.0.0"},
+		},
+		{
+			name:      "fails for unknown providers",
+			providers: []string{"infrastructure-p3"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := client.ValidateRepositories(ctx, ValidateRepositoriesOptions{Providers: tt.providers})
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+
+			gotVersions := map[string]string{}
+			for _, v := range got {
+				gotVersions[v.Provider.ManifestLabel()] = v.Version
+				// The components used for testing have no controller, thus the validation reports errors.
+				g.Expect(v.HasErrors()).To(BeTrue())
+			}
+			g.Expect(gotVersions).To(Equal(tt.wantVersions))
+		})
+	}
+}
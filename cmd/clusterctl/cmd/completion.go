@@ -22,12 +22,15 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 )
@@ -212,6 +215,89 @@ func resourceNameCompletionFunc(kubeconfigFlag, contextFlag, namespaceFlag *pfla
 	}
 }
 
+// singleArgCompletionFunc wraps a completion func for commands accepting a single argument, e.g. the name of a Cluster,
+// so nothing is completed once the argument is set.
+func singleArgCompletionFunc(fn func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return fn(cmd, args, toComplete)
+	}
+}
+
+// providerCompletionFunc returns a completion func for the names of the providers of the given type installed in the
+// management cluster, e.g. aws for the infrastructure-aws provider; if withVersion is true, the names are completed with
+// the version separator, e.g. aws:, so the version can be typed next.
+// If providerType is empty, the names of the provider inventory entries, e.g. infrastructure-aws, are completed instead.
+func providerCompletionFunc(kubeconfigFlag, contextFlag *pflag.Flag, providerType clusterctlv1.ProviderType, withVersion bool) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		ctx := context.Background()
+
+		configClient, err := config.New(ctx, cfgFile)
+		if err != nil {
+			return completionError(err)
+		}
+
+		clusterClient := cluster.New(cluster.Kubeconfig{Path: kubeconfigFlag.Value.String(), Context: contextFlag.Value.String()}, configClient)
+		providers, err := clusterClient.ProviderInventory().List(ctx)
+		if err != nil {
+			return completionError(err)
+		}
+
+		directive := cobra.ShellCompDirectiveNoFileComp
+		if withVersion {
+			directive |= cobra.ShellCompDirectiveNoSpace
+		}
+		return providerCompletions(providers.Items, providerType, withVersion, toComplete), directive
+	}
+}
+
+// providerCompletions returns the completions for the providers of the given type matching toComplete.
+// Given that the provider flags accept a comma separated list, only the last element of the list is completed.
+func providerCompletions(providers []clusterctlv1.Provider, providerType clusterctlv1.ProviderType, withVersion bool, toComplete string) []string {
+	var listPrefix string
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		listPrefix, toComplete = toComplete[:i+1], toComplete[i+1:]
+	}
+
+	names := sets.Set[string]{}
+	for _, p := range providers {
+		name := p.Name
+		if providerType != "" {
+			if p.GetProviderType() != providerType {
+				continue
+			}
+			name = p.ProviderName
+		}
+		if withVersion {
+			name += ":"
+		}
+		if strings.HasPrefix(name, toComplete) {
+			names.Insert(listPrefix + name)
+		}
+	}
+	return sets.List(names)
+}
+
+// registerProviderFlagsCompletion registers the completion for the flags selecting providers by type, e.g. --infrastructure,
+// with the providers installed in the management cluster.
+func registerProviderFlagsCompletion(cmd *cobra.Command, withVersion bool) {
+	kubeconfigFlag := cmd.Flags().Lookup("kubeconfig")
+	contextFlag := cmd.Flags().Lookup("kubeconfig-context")
+	for flagName, providerType := range map[string]clusterctlv1.ProviderType{
+		"core":              clusterctlv1.CoreProviderType,
+		"bootstrap":         clusterctlv1.BootstrapProviderType,
+		"control-plane":     clusterctlv1.ControlPlaneProviderType,
+		"infrastructure":    clusterctlv1.InfrastructureProviderType,
+		"ipam":              clusterctlv1.IPAMProviderType,
+		"runtime-extension": clusterctlv1.RuntimeExtensionProviderType,
+		"addon":             clusterctlv1.AddonProviderType,
+	} {
+		_ = cmd.RegisterFlagCompletionFunc(flagName, providerCompletionFunc(kubeconfigFlag, contextFlag, providerType, withVersion))
+	}
+}
+
 func completionError(err error) ([]string, cobra.ShellCompDirective) {
 	cobra.CompError(err.Error())
 	return nil, cobra.ShellCompDirectiveError
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+)
+
+func Test_providerCompletions(t *testing.T) {
+	provider := func(name string, providerType clusterctlv1.ProviderType, namespace string) clusterctlv1.Provider {
+		return clusterctlv1.Provider{
+			ObjectMeta:   metav1.ObjectMeta{Name: clusterctlv1.ManifestLabel(name, providerType), Namespace: namespace},
+			ProviderName: name,
+			Type:         string(providerType),
+		}
+	}
+	providers := []clusterctlv1.Provider{
+		provider("cluster-api", clusterctlv1.CoreProviderType, "capi-system"),
+		provider("kubeadm", clusterctlv1.BootstrapProviderType, "capi-kubeadm-bootstrap-system"),
+		provider("aws", clusterctlv1.InfrastructureProviderType, "capa-system"),
+		provider("aws", clusterctlv1.InfrastructureProviderType, "capa-system-2"),
+		provider("azure", clusterctlv1.InfrastructureProviderType, "capz-system"),
+	}
+
+	tests := []struct {
+		name         string
+		providerType clusterctlv1.ProviderType
+		withVersion  bool
+		toComplete   string
+		want         []string
+	}{
+		{
+			name:         "Complete the providers of a type",
+			providerType: clusterctlv1.InfrastructureProviderType,
+			want:         []string{"aws", "azure"},
+		},
+		{
+			name:         "Complete the providers matching the prefix, with the version separator",
+			providerType: clusterctlv1.InfrastructureProviderType,
+			withVersion:  true,
+			toComplete:   "aw",
+			want:         []string{"aws:"},
+		},
+		{
+			name:         "Complete the last element of a list",
+			providerType: clusterctlv1.InfrastructureProviderType,
+			toComplete:   "aws,az",
+			want:         []string{"aws,azure"},
+		},
+		{
+			name:       "Complete the provider inventory names",
+			toComplete: "bootstrap-",
+			want:       []string{"bootstrap-kubeadm"},
+		},
+		{
+			name:         "No providers of a type",
+			providerType: clusterctlv1.IPAMProviderType,
+			want:         []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(providerCompletions(providers, tt.providerType, tt.withVersion, tt.toComplete)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_singleArgCompletionFunc(t *testing.T) {
+	g := NewWithT(t)
+
+	fn := singleArgCompletionFunc(func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return []string{"cluster1"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	comps, _ := fn(&cobra.Command{}, nil, "")
+	g.Expect(comps).To(ConsistOf("cluster1"))
+
+	comps, directive := fn(&cobra.Command{}, []string{"cluster1"}, "")
+	g.Expect(comps).To(BeEmpty())
+	g.Expect(directive).To(Equal(cobra.ShellCompDirectiveNoFileComp))
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type configFeatureGatesOptions struct {
+	kubeconfig          string
+	kubeconfigContext   string
+	providers           []string
+	set                 []string
+	waitProviderTimeout int
+}
+
+var cfo = &configFeatureGatesOptions{}
+
+var configFeatureGatesCmd = &cobra.Command{
+	Use:   "feature-gates",
+	Args:  cobra.NoArgs,
+	Short: "Display or change the feature gates of the providers in the management cluster",
+	Long: LongDesc(`
+		Display the feature gates supported by each provider installed in the management cluster and their current values.
+
+		Use --set to change feature gates; the args of the provider Deployments are patched one provider at a time,
+		waiting for each Deployment to roll out before changing the next one. Only feature gates already configured
+		in the provider Deployments can be changed, and all the changes are validated before changing any provider.`),
+
+	Example: Examples(`
+		# Display the feature gates of all the providers.
+		clusterctl config feature-gates
+
+		# Enable the ClusterTopology feature gate on all the providers supporting it.
+		clusterctl config feature-gates --set ClusterTopology=true
+
+		# Disable the MachinePool feature gate on the kubeadm bootstrap provider only.
+		clusterctl config feature-gates --provider bootstrap-kubeadm --set MachinePool=false`),
+
+	RunE: func(*cobra.Command, []string) error {
+		return runConfigFeatureGates(os.Stdout)
+	},
+}
+
+func init() {
+	configFeatureGatesCmd.Flags().StringVar(&cfo.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig for the management cluster. If unspecified, default discovery rules apply.")
+	configFeatureGatesCmd.Flags().StringVar(&cfo.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	configFeatureGatesCmd.Flags().StringSliceVar(&cfo.providers, "provider", nil,
+		"Providers to display or change, identified by the name of their inventory entry (e.g. cluster-api or bootstrap-kubeadm). If empty, all the providers are considered.")
+	configFeatureGatesCmd.Flags().StringSliceVar(&cfo.set, "set", nil,
+		"Feature gates to set, in the Name=true|false format.")
+	configFeatureGatesCmd.Flags().IntVar(&cfo.waitProviderTimeout, "wait-provider-timeout", 5*60,
+		"Wait timeout per provider Deployment to roll out after changing feature gates (in seconds).")
+
+	// completions
+	_ = configFeatureGatesCmd.RegisterFlagCompletionFunc("provider", providerCompletionFunc(
+		configFeatureGatesCmd.Flags().Lookup("kubeconfig"),
+		configFeatureGatesCmd.Flags().Lookup("kubeconfig-context"),
+		"",
+		false,
+	))
+
+	configCmd.AddCommand(configFeatureGatesCmd)
+}
+
+func runConfigFeatureGates(out io.Writer) error {
+	ctx := context.Background()
+
+	c, err := client.New(ctx, cfgFile)
+	if err != nil {
+		return err
+	}
+
+	kubeconfig := client.Kubeconfig{Path: cfo.kubeconfig, Context: cfo.kubeconfigContext}
+
+	if len(cfo.set) > 0 {
+		featureGates, err := parseFeatureGates(cfo.set)
+		if err != nil {
+			return err
+		}
+		if err := c.SetFeatureGates(ctx, client.SetFeatureGatesOptions{
+			Kubeconfig:          kubeconfig,
+			Providers:           cfo.providers,
+			FeatureGates:        featureGates,
+			WaitProviderTimeout: time.Duration(cfo.waitProviderTimeout) * time.Second,
+		}); err != nil {
+			return err
+		}
+	}
+
+	providers, err := c.GetFeatureGates(ctx, client.GetFeatureGatesOptions{Kubeconfig: kubeconfig})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tNAMESPACE\tTYPE\tFEATURE GATE\tENABLED")
+	for _, p := range providers {
+		if len(cfo.providers) > 0 && !slices.Contains(cfo.providers, p.Provider.Name) {
+			continue
+		}
+		for _, f := range p.FeatureGates {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\n", p.Provider.Name, p.Provider.Namespace, p.Provider.Type, f.Name, f.Enabled)
+		}
+	}
+	return w.Flush()
+}
+
+// parseFeatureGates parses feature gates in the Name=true|false format.
+func parseFeatureGates(values []string) (map[string]bool, error) {
+	featureGates := map[string]bool{}
+	for _, v := range values {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok || name == "" {
+			return nil, errors.Errorf("invalid feature gate %q, expected format is Name=true|false", v)
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.Errorf("invalid value for feature gate %q, expected true or false", name)
+		}
+		featureGates[name] = enabled
+	}
+	return featureGates, nil
+}
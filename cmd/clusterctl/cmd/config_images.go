@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type configImagesOptions struct {
+	kubeconfig                string
+	kubeconfigContext         string
+	coreProvider              string
+	bootstrapProviders        []string
+	controlPlaneProviders     []string
+	infrastructureProviders   []string
+	ipamProviders             []string
+	runtimeExtensionProviders []string
+	addonProviders            []string
+	resolve                   bool
+	output                    string
+}
+
+var cio = &configImagesOptions{}
+
+var configImagesCmd = &cobra.Command{
+	Use:   "images",
+	Args:  cobra.NoArgs,
+	Short: "Display the container images of the providers, with the image overrides applied",
+	Long: LongDesc(`
+		Display the container images required for initializing a management cluster with the selected providers,
+		after applying the image overrides defined in the clusterctl configuration file.
+
+		Use --resolve to resolve the image tags to digests by querying the image registries; the resulting list of
+		images pinned to digests can be used to mirror the images in air-gapped environments, and the digests can be
+		used to pin the images using the digest field of the image overrides.`),
+
+	Example: Examples(`
+		# Display the images for the default providers and the aws infrastructure provider.
+		clusterctl config images --infrastructure aws
+
+		# Display the images pinned to the digests of their tags.
+		clusterctl config images --infrastructure aws --resolve
+
+		# Print a mirroring manifest with the images and their digests, in yaml format.
+		clusterctl config images --infrastructure aws --resolve -o yaml`),
+
+	RunE: func(*cobra.Command, []string) error {
+		return runConfigImages(os.Stdout)
+	},
+}
+
+func init() {
+	configImagesCmd.Flags().StringVar(&cio.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig for the management cluster. If unspecified, default discovery rules apply.")
+	configImagesCmd.Flags().StringVar(&cio.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	configImagesCmd.Flags().StringVar(&cio.coreProvider, "core", "",
+		"Core provider version (e.g. cluster-api:v1.1.5). If unspecified, Cluster API's latest release is used.")
+	configImagesCmd.Flags().StringSliceVarP(&cio.infrastructureProviders, "infrastructure", "i", nil,
+		"Infrastructure providers and versions (e.g. aws:v0.5.0).")
+	configImagesCmd.Flags().StringSliceVarP(&cio.bootstrapProviders, "bootstrap", "b", nil,
+		"Bootstrap providers and versions (e.g. kubeadm:v1.1.5). If unspecified, Kubeadm bootstrap provider's latest release is used.")
+	configImagesCmd.Flags().StringSliceVarP(&cio.controlPlaneProviders, "control-plane", "c", nil,
+		"Control plane providers and versions (e.g. kubeadm:v1.1.5). If unspecified, the Kubeadm control plane provider's latest release is used.")
+	configImagesCmd.Flags().StringSliceVar(&cio.ipamProviders, "ipam", nil,
+		"IPAM providers and versions (e.g. in-cluster:v0.1.0).")
+	configImagesCmd.Flags().StringSliceVar(&cio.runtimeExtensionProviders, "runtime-extension", nil,
+		"Runtime extension providers and versions.")
+	configImagesCmd.Flags().StringSliceVar(&cio.addonProviders, "addon", nil,
+		"Add-on providers and versions (e.g. helm:v0.1.0).")
+	configImagesCmd.Flags().BoolVar(&cio.resolve, "resolve", false,
+		"Resolve the image tags to digests by querying the image registries.")
+	configImagesCmd.Flags().StringVarP(&cio.output, "output", "o", RepositoriesOutputText,
+		fmt.Sprintf("Output format. Valid values: %v.", RepositoriesOutputs))
+
+	configCmd.AddCommand(configImagesCmd)
+}
+
+func runConfigImages(out io.Writer) error {
+	if cio.output != RepositoriesOutputText && cio.output != RepositoriesOutputYaml {
+		return errors.Errorf("invalid output format %q, valid values: %v", cio.output, RepositoriesOutputs)
+	}
+
+	ctx := context.Background()
+
+	c, err := client.New(ctx, cfgFile)
+	if err != nil {
+		return err
+	}
+
+	images, err := c.InitImages(ctx, client.InitOptions{
+		Kubeconfig:                client.Kubeconfig{Path: cio.kubeconfig, Context: cio.kubeconfigContext},
+		CoreProvider:              cio.coreProvider,
+		BootstrapProviders:        cio.bootstrapProviders,
+		ControlPlaneProviders:     cio.controlPlaneProviders,
+		InfrastructureProviders:   cio.infrastructureProviders,
+		IPAMProviders:             cio.ipamProviders,
+		RuntimeExtensionProviders: cio.runtimeExtensionProviders,
+		AddonProviders:            cio.addonProviders,
+		LogUsageInstructions:      false,
+	})
+	if err != nil {
+		return err
+	}
+
+	resolved := make([]client.ResolvedImage, 0, len(images))
+	if cio.resolve {
+		resolved, err = c.ResolveImages(ctx, client.ResolveImagesOptions{Images: images})
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, image := range images {
+			resolved = append(resolved, client.ResolvedImage{Image: image})
+		}
+	}
+
+	return printImages(out, resolved, cio.output)
+}
+
+// printImages prints the images, pinned to their digest if resolved, one per line or in yaml format.
+func printImages(out io.Writer, images []client.ResolvedImage, output string) error {
+	if output == RepositoriesOutputYaml {
+		y, err := yaml.Marshal(images)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(out, string(y))
+		return err
+	}
+
+	for _, image := range images {
+		if image.Digest == "" {
+			fmt.Fprintln(out, image.Image)
+			continue
+		}
+		fmt.Fprintln(out, image.PinnedImage())
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+func Test_printImages(t *testing.T) {
+	digest := "sha256:0123456789012345678901234567890123456789012345678901234567890123"
+
+	tests := []struct {
+		name   string
+		images []client.ResolvedImage
+		output string
+		want   string
+	}{
+		{
+			name: "text output without digests",
+			images: []client.ResolvedImage{
+				{Image: "registry.k8s.io/cluster-api/cluster-api-controller:v1.6.0"},
+			},
+			output: RepositoriesOutputText,
+			want:   "registry.k8s.io/cluster-api/cluster-api-controller:v1.6.0\n",
+		},
+		{
+			name: "text output with digests",
+			images: []client.ResolvedImage{
+				{Image: "registry.k8s.io/cluster-api/cluster-api-controller:v1.6.0", Digest: digest},
+				{Image: "registry.k8s.io/cluster-api/kube-rbac-proxy:v0.15.0@" + digest, Digest: digest},
+			},
+			output: RepositoriesOutputText,
+			want: "registry.k8s.io/cluster-api/cluster-api-controller:v1.6.0@" + digest + "\n" +
+				"registry.k8s.io/cluster-api/kube-rbac-proxy:v0.15.0@" + digest + "\n",
+		},
+		{
+			name: "yaml output with digests",
+			images: []client.ResolvedImage{
+				{Image: "registry.k8s.io/cluster-api/cluster-api-controller:v1.6.0", Digest: digest},
+			},
+			output: RepositoriesOutputYaml,
+			want:   "- digest: " + digest + "\n  image: registry.k8s.io/cluster-api/cluster-api-controller:v1.6.0\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			var out bytes.Buffer
+			g.Expect(printImages(&out, tt.images, tt.output)).To(Succeed())
+			g.Expect(out.String()).To(Equal(tt.want))
+		})
+	}
+}
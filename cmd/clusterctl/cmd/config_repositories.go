@@ -44,7 +44,9 @@ var (
 )
 
 type configRepositoriesOptions struct {
-	output string
+	output    string
+	validate  bool
+	providers []string
 }
 
 var cro = &configRepositoriesOptions{}
@@ -57,16 +59,30 @@ var configRepositoryCmd = &cobra.Command{
 		Display the list of providers and their repository configurations.
 
 		clusterctl ships with a list of known providers; if necessary, edit
-		$XDG_CONFIG_HOME/cluster-api/clusterctl.yaml file to add a new provider or to customize existing ones.`),
+		$XDG_CONFIG_HOME/cluster-api/clusterctl.yaml file to add a new provider or to customize existing ones.
+
+		Use --validate to download the provider repositories and check they follow the clusterctl provider contract:
+		the release series and contracts in metadata.yaml, the conventions for the components YAML (namespace,
+		labels, webhooks) and the variables used by the default cluster template. The command fails if any error
+		is found; warnings report deviations from the conventions which might surprise users.`),
 
 	Example: Examples(`
 		# Displays the list of available providers.
 		clusterctl config repositories
 
 		# Print the list of available providers in yaml format.
-		clusterctl config repositories -o yaml`),
+		clusterctl config repositories -o yaml
+
+		# Validate the repository of a provider in development, configured in the clusterctl configuration file.
+		clusterctl config repositories --validate --provider infrastructure-my-infra
+
+		# Validate a specific version of a provider.
+		clusterctl config repositories --validate --provider infrastructure-aws:v2.4.0`),
 
 	RunE: func(*cobra.Command, []string) error {
+		if cro.validate {
+			return runValidateRepositories(cfgFile, os.Stdout)
+		}
 		return runGetRepositories(cfgFile, os.Stdout)
 	},
 }
@@ -74,6 +90,10 @@ var configRepositoryCmd = &cobra.Command{
 func init() {
 	configRepositoryCmd.Flags().StringVarP(&cro.output, "output", "o", RepositoriesOutputText,
 		fmt.Sprintf("Output format. Valid values: %v.", RepositoriesOutputs))
+	configRepositoryCmd.Flags().BoolVar(&cro.validate, "validate", false,
+		"Download the provider repositories and validate them against the clusterctl provider contract.")
+	configRepositoryCmd.Flags().StringSliceVar(&cro.providers, "provider", nil,
+		"Providers and versions to validate (e.g. infrastructure-aws:v2.4.0). If unspecified, all the providers are validated.")
 	configCmd.AddCommand(configRepositoryCmd)
 }
 
@@ -116,3 +136,76 @@ func runGetRepositories(cfgFile string, out io.Writer) error {
 	}
 	return w.Flush()
 }
+
+func runValidateRepositories(cfgFile string, out io.Writer) error {
+	if cro.output != RepositoriesOutputText && cro.output != RepositoriesOutputYaml {
+		return errors.Errorf("invalid output format %q, valid values: %v", cro.output, RepositoriesOutputs)
+	}
+
+	ctx := context.Background()
+
+	c, err := client.New(ctx, cfgFile)
+	if err != nil {
+		return err
+	}
+
+	validations, err := c.ValidateRepositories(ctx, client.ValidateRepositoriesOptions{Providers: cro.providers})
+	if err != nil {
+		return err
+	}
+
+	if err := printRepositoryValidations(out, validations, cro.output); err != nil {
+		return err
+	}
+
+	for _, v := range validations {
+		if v.HasErrors() {
+			return errors.New("provider repositories validation failed")
+		}
+	}
+	return nil
+}
+
+// repositoryValidationOutput is the yaml representation of a client.RepositoryValidation.
+type repositoryValidationOutput struct {
+	Provider string                   `json:"provider"`
+	Version  string                   `json:"version"`
+	Issues   []client.ValidationIssue `json:"issues,omitempty"`
+}
+
+// printRepositoryValidations prints the issues found in each provider repository, in a table or in yaml format.
+func printRepositoryValidations(out io.Writer, validations []client.RepositoryValidation, output string) error {
+	if output == RepositoriesOutputYaml {
+		outputs := make([]repositoryValidationOutput, 0, len(validations))
+		for _, v := range validations {
+			outputs = append(outputs, repositoryValidationOutput{Provider: v.Provider.ManifestLabel(), Version: v.Version, Issues: v.Issues})
+		}
+		y, err := yaml.Marshal(outputs)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(out, string(y))
+		return err
+	}
+
+	for i, v := range validations {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "Provider %s, version %s:\n", v.Provider.ManifestLabel(), v.Version)
+		if len(v.Issues) == 0 {
+			fmt.Fprintln(out, "No issues found")
+			continue
+		}
+
+		w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+		fmt.Fprintln(w, "SEVERITY\tFILE\tMESSAGE")
+		for _, issue := range v.Issues {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", issue.Severity, issue.File, issue.Message)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -25,6 +25,11 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
 )
 
 func Test_runGetRepositories(t *testing.T) {
@@ -356,3 +361,61 @@ var expectedOutputYaml = `- File: core_components.yaml
   ProviderType: AddonProvider
   URL: https://github.com/kubernetes-sigs/cluster-api-addon-provider-helm/releases/latest/
 `
+
+func Test_printRepositoryValidations(t *testing.T) {
+	validations := []client.RepositoryValidation{
+		{
+			Provider: config.NewProvider("kubeadm", "url", clusterctlv1.BootstrapProviderType),
+			Version:  "v1.6.0",
+		},
+		{
+			Provider: config.NewProvider("my-infra", "url", clusterctlv1.InfrastructureProviderType),
+			Version:  "v0.1.0",
+			Issues: []client.ValidationIssue{
+				{Severity: repository.ValidationError, File: "metadata.yaml", Message: "no release series defined"},
+				{Severity: repository.ValidationWarning, File: "cluster-template.yaml", Message: "the template does not use the WORKER_MACHINE_COUNT variable"},
+			},
+		},
+	}
+
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{
+			output: RepositoriesOutputText,
+			want: `Provider bootstrap-kubeadm, version v1.6.0:
+No issues found
+
+Provider infrastructure-my-infra, version v0.1.0:
+SEVERITY   FILE                    MESSAGE
+Error      metadata.yaml           no release series defined
+Warning    cluster-template.yaml   the template does not use the WORKER_MACHINE_COUNT variable
+`,
+		},
+		{
+			output: RepositoriesOutputYaml,
+			want: `- provider: bootstrap-kubeadm
+  version: v1.6.0
+- issues:
+  - file: metadata.yaml
+    message: no release series defined
+    severity: Error
+  - file: cluster-template.yaml
+    message: the template does not use the WORKER_MACHINE_COUNT variable
+    severity: Warning
+  provider: infrastructure-my-infra
+  version: v0.1.0
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			g := NewWithT(t)
+
+			buf := bytes.NewBufferString("")
+			g.Expect(printRepositoryValidations(buf, validations, tt.output)).To(Succeed())
+			g.Expect(cmp.Diff(tt.want, buf.String())).To(BeEmpty())
+		})
+	}
+}
@@ -38,6 +38,7 @@ type deleteOptions struct {
 	includeNamespace          bool
 	includeCRDs               bool
 	deleteAll                 bool
+	force                     bool
 }
 
 var dd = &deleteOptions{}
@@ -47,7 +48,12 @@ var deleteCmd = &cobra.Command{
 	GroupID: groupManagement,
 	Short:   "Delete one or more providers from the management cluster",
 	Long: LongDesc(`
-		Delete one or more providers from the management cluster.`),
+		Delete one or more providers from the management cluster.
+
+		Providers used by Clusters or Machines, e.g. an infrastructure provider referenced by the infrastructureRef
+		of a Cluster, are not deleted unless --force is set, because deleting them leaves the workload clusters
+		without a controller reconciling them. After the deletion, the objects left behind by the deleted providers
+		are reported.`),
 
 	Example: Examples(`
 		# Deletes the AWS provider
@@ -79,6 +85,11 @@ var deleteCmd = &cobra.Command{
 		# Cluster API Providers are orphaned and there might be ongoing costs incurred as a result of this.
 		clusterctl delete --infrastructure aws --include-namespace
 
+		# Delete the AWS infrastructure provider even if it is still used by Clusters or Machines.
+		# Important! As a consequence of this operation, the workload clusters using the AWS infrastructure provider
+		# are no longer reconciled, and the corresponding resources on AWS are orphaned.
+		clusterctl delete --infrastructure aws --force
+
 		# Reset the management cluster to its original state
 		# Important! As a consequence of this operation all the corresponding resources on target clouds
 		# are "orphaned" and thus there may be ongoing costs incurred as a result of this.
@@ -117,6 +128,11 @@ func init() {
 
 	deleteCmd.Flags().BoolVar(&dd.deleteAll, "all", false,
 		"Force deletion of all the providers")
+	deleteCmd.Flags().BoolVar(&dd.force, "force", false,
+		"Delete the providers even if they are used by Clusters or Machines, leaving the workload clusters without a controller reconciling them")
+
+	// completions
+	registerProviderFlagsCompletion(deleteCmd, false)
 
 	RootCmd.AddCommand(deleteCmd)
 }
@@ -157,5 +173,6 @@ func runDelete() error {
 		RuntimeExtensionProviders: dd.runtimeExtensionProviders,
 		AddonProviders:            dd.addonProviders,
 		DeleteAll:                 dd.deleteAll,
+		Force:                     dd.force,
 	})
 }
@@ -30,12 +30,15 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/sets"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/tree"
+	v1beta2conditions "sigs.k8s.io/cluster-api/util/conditions/v1beta2"
 )
 
 const (
@@ -66,6 +69,12 @@ type describeClusterOptions struct {
 	grouping                bool
 	disableGrouping         bool
 	color                   bool
+	conditionsFormat        string
+	output                  string
+	showConditionTypes      string
+	onlyProblems            bool
+	pageSize                int
+	page                    int
 }
 
 var dc = &describeClusterOptions{}
@@ -94,7 +103,26 @@ var describeClusterClusterCmd = &cobra.Command{
 
 		# Describe the cluster named test-1 showing the MachineInfrastructure and BootstrapConfig objects
 		# also when their status is the same as the status of the corresponding machine object.
-		clusterctl describe cluster test-1 --echo`),
+		clusterctl describe cluster test-1 --echo
+
+		# Describe the cluster named test-1 using the v1beta2 conditions, showing all the v1beta2 conditions for machines.
+		clusterctl describe cluster test-1 --conditions-format v1beta2 --show-conditions Machine
+
+		# Describe the cluster named test-1 showing only the objects with problems, and the objects they
+		# depend on; healthy branches of the tree are collapsed.
+		clusterctl describe cluster test-1 --only-problems
+
+		# Describe the cluster named test-1 showing only the InfrastructureReady and ControlPlaneReady conditions of all the objects.
+		clusterctl describe cluster test-1 --show-conditions all --show-condition-types InfrastructureReady,ControlPlaneReady
+
+		# Describe the cluster named test-1 with hundreds of machines, showing the second page of 50 machines.
+		clusterctl describe cluster test-1 --grouping=false --page-size 50 --page 2
+
+		# Describe the cluster named test-1 in json format, e.g. for processing the conditions in a script.
+		clusterctl describe cluster test-1 -o json
+
+		# Render the cluster named test-1 as a graph using Graphviz.
+		clusterctl describe cluster test-1 -o dot | dot -Tsvg > test-1.svg`),
 
 	Args: func(_ *cobra.Command, args []string) error {
 		if len(args) != 1 {
@@ -132,16 +160,28 @@ func init() {
 		"Disable grouping machines when ready condition has the same Status, Severity and Reason.")
 	_ = describeClusterClusterCmd.Flags().MarkDeprecated("disable-grouping",
 		"use --grouping instead.")
+	describeClusterClusterCmd.Flags().StringVar(&dc.conditionsFormat, "conditions-format", string(tree.ConditionsFormatV1Beta1),
+		fmt.Sprintf("The format of the conditions to show and to use for grouping objects, one of %s, %s or %s.", tree.ConditionsFormatV1Beta1, tree.ConditionsFormatV1Beta2, tree.ConditionsFormatBoth))
+	describeClusterClusterCmd.Flags().StringVarP(&dc.output, "output", "o", DescribeClusterOutputText,
+		fmt.Sprintf("Output format. Valid values: %v. Structured outputs include all the conditions of all the objects, and are never colored.", DescribeClusterOutputs))
+	describeClusterClusterCmd.Flags().StringVar(&dc.showConditionTypes, "show-condition-types", "",
+		"list of comma separated condition types to show for the objects selected by --show-conditions, e.g. InfrastructureReady,ControlPlaneReady; if empty, all the conditions are shown. Only for the text output.")
+	describeClusterClusterCmd.Flags().BoolVar(&dc.onlyProblems, "only-problems", false,
+		"Show only the objects with problems, e.g. not ready or being deleted, and their parents, collapsing the healthy branches of the tree. Only for the text output.")
+	describeClusterClusterCmd.Flags().IntVar(&dc.pageSize, "page-size", 0,
+		"The maximum number of children to show for each object, e.g. the machines of a MachineDeployment; 0 shows all the children. Only for the text output.")
+	describeClusterClusterCmd.Flags().IntVar(&dc.page, "page", 1,
+		"The page of children to show for the objects with more children than --page-size. Only for the text output.")
 	describeClusterClusterCmd.Flags().BoolVarP(&dc.color, "color", "c", false, "Enable or disable color output; if not set color is enabled by default only if using tty. The flag is overridden by the NO_COLOR env variable if set.")
 
 	// completions
-	describeClusterClusterCmd.ValidArgsFunction = resourceNameCompletionFunc(
+	describeClusterClusterCmd.ValidArgsFunction = singleArgCompletionFunc(resourceNameCompletionFunc(
 		describeClusterClusterCmd.Flags().Lookup("kubeconfig"),
 		describeClusterClusterCmd.Flags().Lookup("kubeconfig-context"),
 		describeClusterClusterCmd.Flags().Lookup("namespace"),
 		clusterv1.GroupVersion.String(),
 		"cluster",
-	)
+	))
 
 	describeCmd.AddCommand(describeClusterClusterCmd)
 }
@@ -149,6 +189,46 @@ func init() {
 func runDescribeCluster(cmd *cobra.Command, name string) error {
 	ctx := context.Background()
 
+	conditionsFormat := tree.ConditionsFormat(dc.conditionsFormat)
+	switch conditionsFormat {
+	case tree.ConditionsFormatV1Beta1, tree.ConditionsFormatV1Beta2, tree.ConditionsFormatBoth:
+	default:
+		return errors.Errorf("invalid value %q for the --conditions-format flag, must be one of %s, %s or %s", dc.conditionsFormat, tree.ConditionsFormatV1Beta1, tree.ConditionsFormatV1Beta2, tree.ConditionsFormatBoth)
+	}
+
+	switch dc.output {
+	case DescribeClusterOutputText, DescribeClusterOutputJSON, DescribeClusterOutputYaml, DescribeClusterOutputDot:
+	default:
+		return errors.Errorf("invalid output format %q, valid values: %v", dc.output, DescribeClusterOutputs)
+	}
+
+	if dc.pageSize < 0 {
+		return errors.Errorf("invalid value %d for the --page-size flag, must be greater than or equal to 0", dc.pageSize)
+	}
+	if dc.page < 1 {
+		return errors.Errorf("invalid value %d for the --page flag, must be greater than 0", dc.page)
+	}
+	if dc.output != DescribeClusterOutputText {
+		for _, flag := range []string{"show-condition-types", "only-problems", "page-size", "page"} {
+			if cmd.Flags().Changed(flag) {
+				return errors.Errorf("the --%s flag can be used only with the %s output", flag, DescribeClusterOutputText)
+			}
+		}
+	}
+	view := treeView{
+		onlyProblems: dc.onlyProblems,
+		pageSize:     dc.pageSize,
+		page:         dc.page,
+	}
+	for _, t := range strings.Split(dc.showConditionTypes, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			if view.conditionTypes == nil {
+				view.conditionTypes = sets.New[string]()
+			}
+			view.conditionTypes.Insert(t)
+		}
+	}
+
 	c, err := client.New(ctx, cfgFile)
 	if err != nil {
 		return err
@@ -165,6 +245,7 @@ func runDescribeCluster(cmd *cobra.Command, name string) error {
 		AddTemplateVirtualNode:  true,
 		Echo:                    dc.echo,
 		Grouping:                dc.grouping && !dc.disableGrouping,
+		ConditionsFormat:        conditionsFormat,
 	})
 	if err != nil {
 		return err
@@ -174,19 +255,69 @@ func runDescribeCluster(cmd *cobra.Command, name string) error {
 		color.NoColor = !dc.color
 	}
 
-	printObjectTree(tree)
+	switch dc.output {
+	case DescribeClusterOutputJSON:
+		color.NoColor = true
+		return printObjectTreeJSON(os.Stdout, tree, conditionsFormat)
+	case DescribeClusterOutputYaml:
+		color.NoColor = true
+		return printObjectTreeYAML(os.Stdout, tree, conditionsFormat)
+	case DescribeClusterOutputDot:
+		color.NoColor = true
+		return printObjectTreeDOT(os.Stdout, tree, conditionsFormat)
+	}
+
+	if conditionsFormat.UseV1Beta1() {
+		printObjectTree(tree, view)
+	}
+	if conditionsFormat.UseV1Beta1() && conditionsFormat.UseV1Beta2() {
+		fmt.Println()
+	}
+	if conditionsFormat.UseV1Beta2() {
+		printObjectTreeV1Beta2(tree, view)
+	}
 	return nil
 }
 
+// treeView defines how to present the object tree in the text output.
+type treeView struct {
+	// conditionTypes are the types of the conditions to show for the objects selected by --show-conditions;
+	// if empty, all the conditions are shown.
+	conditionTypes sets.Set[string]
+
+	// onlyProblems shows only the objects with problems and their parents, collapsing healthy branches.
+	onlyProblems bool
+
+	// pageSize is the maximum number of children to show for each object; 0 shows all the children.
+	pageSize int
+
+	// page is the page of children to show for objects with more than pageSize children, starting from 1.
+	page int
+}
+
 // printObjectTree prints the cluster status to stdout.
-func printObjectTree(tree *tree.ObjectTree) {
+func printObjectTree(tree *tree.ObjectTree, view treeView) {
 	// Creates the output table
 	tbl := tablewriter.NewWriter(os.Stdout)
 	tbl.SetHeader([]string{"NAME", "READY", "SEVERITY", "REASON", "SINCE", "MESSAGE"})
 
 	formatTableTree(tbl)
 	// Add row for the root object, the cluster, and recursively for all the nodes representing the cluster status.
-	addObjectRow("", tbl, tree, tree.GetRoot())
+	addObjectRow("", tbl, tree, tree.GetRoot(), view)
+
+	// Prints the output table
+	tbl.Render()
+}
+
+// printObjectTreeV1Beta2 prints the cluster status to stdout using the v1beta2 conditions.
+func printObjectTreeV1Beta2(tree *tree.ObjectTree, view treeView) {
+	// Creates the output table
+	tbl := tablewriter.NewWriter(os.Stdout)
+	tbl.SetHeader([]string{"NAME", "STATUS", "REASON", "SINCE", "MESSAGE"})
+
+	formatTableTree(tbl)
+	// Add row for the root object, the cluster, and recursively for all the nodes representing the cluster status.
+	addObjectRowV1Beta2("", tbl, tree, tree.GetRoot(), view)
 
 	// Prints the output table
 	tbl.Render()
@@ -210,7 +341,7 @@ func formatTableTree(tbl *tablewriter.Table) {
 
 // addObjectRow add a row for a given object, and recursively for all the object's children.
 // NOTE: each row name gets a prefix, that generates a tree view like representation.
-func addObjectRow(prefix string, tbl *tablewriter.Table, objectTree *tree.ObjectTree, obj ctrlclient.Object) {
+func addObjectRow(prefix string, tbl *tablewriter.Table, objectTree *tree.ObjectTree, obj ctrlclient.Object, view treeView) {
 	// Gets the descriptor for the object's ready condition, if any.
 	readyDescriptor := conditionDescriptor{readyColor: gray}
 	if ready := tree.GetReadyCondition(obj); ready != nil {
@@ -219,12 +350,7 @@ func addObjectRow(prefix string, tbl *tablewriter.Table, objectTree *tree.Object
 
 	// If the object is a group object, override the condition message with the list of objects in the group. e.g machine-1, machine-2, ...
 	if tree.IsGroupObject(obj) {
-		items := strings.Split(tree.GetGroupItems(obj), tree.GroupItemsSeparator)
-		if len(items) <= 2 {
-			readyDescriptor.message = gray.Sprintf("See %s", strings.Join(items, tree.GroupItemsSeparator))
-		} else {
-			readyDescriptor.message = gray.Sprintf("See %s, ...", strings.Join(items[:2], tree.GroupItemsSeparator))
-		}
+		readyDescriptor.message = getGroupMessage(obj)
 	}
 
 	// Gets the row name for the object.
@@ -244,32 +370,170 @@ func addObjectRow(prefix string, tbl *tablewriter.Table, objectTree *tree.Object
 
 	// If it is required to show all the conditions for the object, add a row for each object's conditions.
 	if tree.IsShowConditionsObject(obj) {
-		addOtherConditions(prefix, tbl, objectTree, obj)
+		addOtherConditions(prefix, tbl, objectTree, obj, view)
 	}
 
 	// Add a row for each object's children, taking care of updating the tree view prefix.
-	childrenObj := objectTree.GetObjectsByParent(obj.GetUID())
+	childrenObj, notes := getVisibleChildren(objectTree, obj, view, hasProblem)
+	rows := len(childrenObj) + len(notes)
+	for i, child := range childrenObj {
+		addObjectRow(getChildPrefix(prefix, i, rows), tbl, objectTree, child, view)
+	}
+	for i, note := range notes {
+		tbl.Append([]string{fmt.Sprintf("%s%s", gray.Sprint(getChildPrefix(prefix, len(childrenObj)+i, rows)), gray.Sprint(note)), "", "", "", "", ""})
+	}
+}
 
-	// printBefore returns true if children[i] should be printed before children[j]. Objects are sorted by z-order and
-	// row name such that objects with higher z-order are printed first, and objects with the same z-order are
-	// printed in alphabetical order.
-	printBefore := func(i, j int) bool {
+// addObjectRowV1Beta2 add a row for a given object using the v1beta2 conditions, and recursively for all the object's children.
+// NOTE: each row name gets a prefix, that generates a tree view like representation.
+func addObjectRowV1Beta2(prefix string, tbl *tablewriter.Table, objectTree *tree.ObjectTree, obj ctrlclient.Object, view treeView) {
+	// Gets the descriptor for the object's summary condition, Available or Ready, if any.
+	summaryDescriptor := v1beta2ConditionDescriptor{statusColor: gray}
+	if summary := tree.GetV1Beta2SummaryCondition(obj); summary != nil {
+		summaryDescriptor = newV1Beta2ConditionDescriptor(summary)
+	}
+
+	// If the object is a group object, override the condition message with the list of objects in the group. e.g machine-1, machine-2, ...
+	if tree.IsGroupObject(obj) {
+		summaryDescriptor.message = getGroupMessage(obj)
+	}
+
+	// Add the row representing the object that includes
+	// - The row name with the tree view prefix.
+	// - The object's summary condition.
+	tbl.Append([]string{
+		fmt.Sprintf("%s%s", gray.Sprint(prefix), getRowName(obj)),
+		summaryDescriptor.statusColor.Sprint(summaryDescriptor.status),
+		summaryDescriptor.statusColor.Sprint(summaryDescriptor.reason),
+		summaryDescriptor.age,
+		summaryDescriptor.message})
+
+	// If it is required to show all the conditions for the object, add a row for each object's conditions.
+	if tree.IsShowConditionsObject(obj) {
+		addOtherV1Beta2Conditions(prefix, tbl, objectTree, obj, view)
+	}
+
+	// Add a row for each object's children, taking care of updating the tree view prefix.
+	childrenObj, notes := getVisibleChildren(objectTree, obj, view, hasV1Beta2Problem)
+	rows := len(childrenObj) + len(notes)
+	for i, child := range childrenObj {
+		addObjectRowV1Beta2(getChildPrefix(prefix, i, rows), tbl, objectTree, child, view)
+	}
+	for i, note := range notes {
+		tbl.Append([]string{fmt.Sprintf("%s%s", gray.Sprint(getChildPrefix(prefix, len(childrenObj)+i, rows)), gray.Sprint(note)), "", "", "", ""})
+	}
+}
+
+// getVisibleChildren returns the children of an object to show in the tree view, and the notes to show after
+// them, e.g. the number of children not shown because healthy or because in another page.
+func getVisibleChildren(objectTree *tree.ObjectTree, obj ctrlclient.Object, view treeView, isProblem func(ctrlclient.Object) bool) ([]ctrlclient.Object, []string) {
+	children := getSortedChildren(objectTree, obj)
+	notes := []string{}
+
+	if view.onlyProblems {
+		problems := []ctrlclient.Object{}
+		for _, child := range children {
+			if isBranchWithProblems(objectTree, child, isProblem) {
+				problems = append(problems, child)
+			}
+		}
+		if healthy := len(children) - len(problems); healthy > 0 {
+			notes = append(notes, fmt.Sprintf("%d healthy %s hidden", healthy, pluralize(healthy, "object")))
+		}
+		children = problems
+	}
+
+	if view.pageSize > 0 && len(children) > view.pageSize {
+		total := len(children)
+		start := min((view.page-1)*view.pageSize, total)
+		end := min(start+view.pageSize, total)
+		children = children[start:end]
+		if start == end {
+			notes = append(notes, fmt.Sprintf("No objects in page %d, %d %s in %d pages", view.page, total, pluralize(total, "object"), (total+view.pageSize-1)/view.pageSize))
+		} else {
+			note := fmt.Sprintf("Showing %d-%d of %d %s", start+1, end, total, pluralize(total, "object"))
+			if end < total {
+				note += fmt.Sprintf(", use --page %d for more", view.page+1)
+			}
+			notes = append(notes, note)
+		}
+	}
+	return children, notes
+}
+
+// isBranchWithProblems returns true if an object or any of its descendants has problems.
+func isBranchWithProblems(objectTree *tree.ObjectTree, obj ctrlclient.Object, isProblem func(ctrlclient.Object) bool) bool {
+	if isProblem(obj) {
+		return true
+	}
+	for _, child := range objectTree.GetObjectsByParent(obj.GetUID()) {
+		if isBranchWithProblems(objectTree, child, isProblem) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasProblem returns true if an object is being deleted or its ready condition is not true.
+func hasProblem(obj ctrlclient.Object) bool {
+	if !obj.GetDeletionTimestamp().IsZero() {
+		return true
+	}
+	ready := tree.GetReadyCondition(obj)
+	return ready != nil && ready.Status != corev1.ConditionTrue
+}
+
+// hasV1Beta2Problem returns true if an object is being deleted or its v1beta2 summary condition, Available or Ready,
+// does not report the desired state.
+func hasV1Beta2Problem(obj ctrlclient.Object) bool {
+	if !obj.GetDeletionTimestamp().IsZero() {
+		return true
+	}
+	summary := tree.GetV1Beta2SummaryCondition(obj)
+	if summary == nil {
+		return false
+	}
+	if negativePolarityV1Beta2Conditions.Has(summary.Type) {
+		return summary.Status != metav1.ConditionFalse
+	}
+	return summary.Status != metav1.ConditionTrue
+}
+
+// pluralize returns the plural of a word if count is not 1.
+func pluralize(count int, word string) string {
+	if count == 1 {
+		return word
+	}
+	return flect.Pluralize(word)
+}
+
+// getSortedChildren returns the children of an object in the order they should be printed. Objects are sorted by z-order and
+// row name such that objects with higher z-order are printed first, and objects with the same z-order are
+// printed in alphabetical order.
+func getSortedChildren(objectTree *tree.ObjectTree, obj ctrlclient.Object) []ctrlclient.Object {
+	childrenObj := objectTree.GetObjectsByParent(obj.GetUID())
+	sort.Slice(childrenObj, func(i, j int) bool {
 		if tree.GetZOrder(childrenObj[i]) == tree.GetZOrder(childrenObj[j]) {
 			return getRowName(childrenObj[i]) < getRowName(childrenObj[j])
 		}
 
 		return tree.GetZOrder(childrenObj[i]) > tree.GetZOrder(childrenObj[j])
-	}
-	sort.Slice(childrenObj, printBefore)
+	})
+	return childrenObj
+}
 
-	for i, child := range childrenObj {
-		addObjectRow(getChildPrefix(prefix, i, len(childrenObj)), tbl, objectTree, child)
+// getGroupMessage returns the message for a group object, listing the objects in the group. e.g machine-1, machine-2, ...
+func getGroupMessage(obj ctrlclient.Object) string {
+	items := strings.Split(tree.GetGroupItems(obj), tree.GroupItemsSeparator)
+	if len(items) <= 2 {
+		return gray.Sprintf("See %s", strings.Join(items, tree.GroupItemsSeparator))
 	}
+	return gray.Sprintf("See %s, ...", strings.Join(items[:2], tree.GroupItemsSeparator))
 }
 
 // addOtherConditions adds a row for each object condition except the ready condition,
 // which is already represented on the object's main row.
-func addOtherConditions(prefix string, tbl *tablewriter.Table, objectTree *tree.ObjectTree, obj ctrlclient.Object) {
+func addOtherConditions(prefix string, tbl *tablewriter.Table, objectTree *tree.ObjectTree, obj ctrlclient.Object, view treeView) {
 	// Add a row for each other condition, taking care of updating the tree view prefix.
 	// In this case the tree prefix get a filler, to indent conditions from objects, and eventually a
 	// and additional pipe if the object has children that should be presented after the conditions.
@@ -279,7 +543,12 @@ func addOtherConditions(prefix string, tbl *tablewriter.Table, objectTree *tree.
 		childrenPipe = pipe
 	}
 
-	otherConditions := tree.GetOtherConditions(obj)
+	otherConditions := []*clusterv1.Condition{}
+	for _, c := range tree.GetOtherConditions(obj) {
+		if view.conditionTypes.Len() == 0 || view.conditionTypes.Has(string(c.Type)) {
+			otherConditions = append(otherConditions, c)
+		}
+	}
 	for i := range otherConditions {
 		otherCondition := otherConditions[i]
 		otherDescriptor := newConditionDescriptor(otherCondition)
@@ -294,6 +563,34 @@ func addOtherConditions(prefix string, tbl *tablewriter.Table, objectTree *tree.
 	}
 }
 
+// addOtherV1Beta2Conditions adds a row for each object v1beta2 condition except the summary condition,
+// which is already represented on the object's main row.
+func addOtherV1Beta2Conditions(prefix string, tbl *tablewriter.Table, objectTree *tree.ObjectTree, obj ctrlclient.Object, view treeView) {
+	filler := strings.Repeat(" ", 10)
+	childrenPipe := indent
+	if objectTree.IsObjectWithChild(obj.GetUID()) {
+		childrenPipe = pipe
+	}
+
+	otherConditions := []metav1.Condition{}
+	for _, c := range tree.GetOtherV1Beta2Conditions(obj) {
+		if view.conditionTypes.Len() == 0 || view.conditionTypes.Has(c.Type) {
+			otherConditions = append(otherConditions, c)
+		}
+	}
+	for i := range otherConditions {
+		otherCondition := otherConditions[i]
+		otherDescriptor := newV1Beta2ConditionDescriptor(&otherCondition)
+		otherConditionPrefix := getChildPrefix(prefix+childrenPipe+filler, i, len(otherConditions))
+		tbl.Append([]string{
+			fmt.Sprintf("%s%s", gray.Sprint(otherConditionPrefix), cyan.Sprint(otherCondition.Type)),
+			otherDescriptor.statusColor.Sprint(otherDescriptor.status),
+			otherDescriptor.statusColor.Sprint(otherDescriptor.reason),
+			otherDescriptor.age,
+			otherDescriptor.message})
+	}
+}
+
 // getChildPrefix return the tree view prefix for a row representing a child object.
 func getChildPrefix(currentPrefix string, childIndex, childCount int) string {
 	nextPrefix := currentPrefix
@@ -394,3 +691,64 @@ func newConditionDescriptor(c *clusterv1.Condition) conditionDescriptor {
 
 	return v
 }
+
+// negativePolarityV1Beta2Conditions are the v1beta2 conditions for which the True status reports
+// a state requiring the user attention, e.g. Paused or Deleting; for all the other conditions the
+// True status reports the desired state, e.g. Available or Ready.
+var negativePolarityV1Beta2Conditions = sets.New[string](
+	clusterv1.PausedV1Beta2Condition,
+	clusterv1.DeletingV1Beta2Condition,
+	"RollingOut",
+	"ScalingUp",
+	"ScalingDown",
+	"Remediating",
+)
+
+// v1beta2ConditionDescriptor contains all the info for representing a v1beta2 condition.
+type v1beta2ConditionDescriptor struct {
+	statusColor *color.Color
+	age         string
+	status      string
+	reason      string
+	message     string
+}
+
+// newV1Beta2ConditionDescriptor returns a v1beta2ConditionDescriptor for the given condition.
+func newV1Beta2ConditionDescriptor(c *metav1.Condition) v1beta2ConditionDescriptor {
+	v := v1beta2ConditionDescriptor{}
+
+	v.status = string(c.Status)
+	v.reason = c.Reason
+	// v1beta2 messages can span multiple lines, e.g. when listing the issues of all the objects in a
+	// Cluster; join them to keep the table readable. Machine-readable payloads are not shown.
+	v.message = strings.Join(strings.Fields(strings.ReplaceAll(v1beta2conditions.TrimMessagePayload(c.Message), "\n", " ")), " ")
+
+	// Eventually cut the message to keep the table dimension under control.
+	if len(v.message) > 100 {
+		v.message = fmt.Sprintf("%s ...", v.message[:100])
+	}
+
+	// Compute the condition age.
+	v.age = duration.HumanDuration(time.Since(c.LastTransitionTime.Time))
+
+	// Determine the color to be used for showing the conditions according to Status and to the condition polarity.
+	negativePolarity := negativePolarityV1Beta2Conditions.Has(c.Type)
+	switch c.Status {
+	case metav1.ConditionTrue:
+		v.statusColor = green
+		if negativePolarity {
+			v.statusColor = yellow
+		}
+	case metav1.ConditionFalse:
+		v.statusColor = red
+		if negativePolarity {
+			v.statusColor = green
+		}
+	case metav1.ConditionUnknown:
+		v.statusColor = white
+	default:
+		v.statusColor = gray
+	}
+
+	return v
+}
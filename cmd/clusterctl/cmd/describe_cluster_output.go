@@ -0,0 +1,217 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/tree"
+)
+
+const (
+	// DescribeClusterOutputText is an option used to print the cluster as a tree view table.
+	DescribeClusterOutputText = "text"
+	// DescribeClusterOutputJSON is an option used to print the cluster in json format.
+	DescribeClusterOutputJSON = "json"
+	// DescribeClusterOutputYaml is an option used to print the cluster in yaml format.
+	DescribeClusterOutputYaml = "yaml"
+	// DescribeClusterOutputDot is an option used to print the cluster as a graph in the Graphviz dot format.
+	DescribeClusterOutputDot = "dot"
+)
+
+var (
+	// DescribeClusterOutputs is a list of valid describe cluster outputs.
+	DescribeClusterOutputs = []string{DescribeClusterOutputText, DescribeClusterOutputJSON, DescribeClusterOutputYaml, DescribeClusterOutputDot}
+)
+
+// describeClusterNode represents an object of the object tree in the structured outputs.
+type describeClusterNode struct {
+	// DisplayName is the name of the object in the tree view, e.g. "ClusterInfrastructure - DockerCluster/test1" or "3 Machines...".
+	DisplayName string `json:"displayName"`
+
+	Kind       string `json:"kind"`
+	APIVersion string `json:"apiVersion,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+
+	// MetaName is the name describing the role of the object in the Cluster, if any, e.g. ClusterInfrastructure.
+	MetaName string `json:"metaName,omitempty"`
+
+	// Virtual is true if the object does not exist in the management cluster, e.g. the Workers node or a group node.
+	Virtual bool `json:"virtual,omitempty"`
+
+	// Deleting is true if the object is being deleted.
+	Deleting bool `json:"deleting,omitempty"`
+
+	// GroupItems are the names of the objects grouped by a group node, if the object is a group node.
+	GroupItems []string `json:"groupItems,omitempty"`
+
+	// Conditions are the conditions of the object, with the Ready condition first; set only when using the v1beta1 conditions.
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+
+	// V1Beta2Conditions are the v1beta2 conditions of the object; set only when using the v1beta2 conditions.
+	V1Beta2Conditions []metav1.Condition `json:"v1beta2Conditions,omitempty"`
+
+	Children []*describeClusterNode `json:"children,omitempty"`
+}
+
+// newDescribeClusterNode returns the describeClusterNode for an object, and recursively for all the object's children.
+func newDescribeClusterNode(objectTree *tree.ObjectTree, obj ctrlclient.Object, conditionsFormat tree.ConditionsFormat) *describeClusterNode {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	node := &describeClusterNode{
+		DisplayName: getRowName(obj),
+		Kind:        gvk.Kind,
+		APIVersion:  gvk.GroupVersion().String(),
+		Namespace:   obj.GetNamespace(),
+		Name:        obj.GetName(),
+		MetaName:    tree.GetMetaName(obj),
+		Virtual:     tree.IsVirtualObject(obj),
+		Deleting:    !obj.GetDeletionTimestamp().IsZero(),
+	}
+	if tree.IsGroupObject(obj) {
+		node.GroupItems = strings.Split(tree.GetGroupItems(obj), tree.GroupItemsSeparator)
+	}
+
+	if conditionsFormat.UseV1Beta1() {
+		if ready := tree.GetReadyCondition(obj); ready != nil {
+			node.Conditions = append(node.Conditions, *ready)
+		}
+		for _, c := range tree.GetOtherConditions(obj) {
+			node.Conditions = append(node.Conditions, *c)
+		}
+	}
+	if conditionsFormat.UseV1Beta2() {
+		node.V1Beta2Conditions = tree.GetV1Beta2Conditions(obj)
+	}
+
+	for _, child := range getSortedChildren(objectTree, obj) {
+		node.Children = append(node.Children, newDescribeClusterNode(objectTree, child, conditionsFormat))
+	}
+	return node
+}
+
+// printObjectTreeJSON prints the cluster status in json format.
+func printObjectTreeJSON(w io.Writer, objectTree *tree.ObjectTree, conditionsFormat tree.ConditionsFormat) error {
+	out, err := json.MarshalIndent(newDescribeClusterNode(objectTree, objectTree.GetRoot(), conditionsFormat), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
+
+// printObjectTreeYAML prints the cluster status in yaml format.
+func printObjectTreeYAML(w io.Writer, objectTree *tree.ObjectTree, conditionsFormat tree.ConditionsFormat) error {
+	out, err := yaml.Marshal(newDescribeClusterNode(objectTree, objectTree.GetRoot(), conditionsFormat))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(w, string(out))
+	return err
+}
+
+// printObjectTreeDOT prints the cluster status as a graph in the Graphviz dot format, e.g. to be rendered with
+// `dot -Tsvg`; each object is a node, labelled with its summary condition and colored according to its status.
+// The v1beta2 summary condition is used only if the v1beta1 conditions are not used.
+func printObjectTreeDOT(w io.Writer, objectTree *tree.ObjectTree, conditionsFormat tree.ConditionsFormat) error {
+	root := newDescribeClusterNode(objectTree, objectTree.GetRoot(), conditionsFormat)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(root.DisplayName))
+	b.WriteString("  node [shape=box, style=rounded];\n")
+
+	id := 0
+	var addNode func(node *describeClusterNode) string
+	addNode = func(node *describeClusterNode) string {
+		nodeID := fmt.Sprintf("n%d", id)
+		id++
+
+		label, color := node.DisplayName, "gray"
+		if status, text := node.summary(conditionsFormat); status != "" {
+			label = fmt.Sprintf("%s\n%s", label, text)
+			color = dotStatusColor(status)
+		}
+		fmt.Fprintf(&b, "  %s [label=%s, color=%s];\n", nodeID, dotQuote(label), color)
+
+		for _, child := range node.Children {
+			childID := addNode(child)
+			fmt.Fprintf(&b, "  %s -> %s;\n", nodeID, childID)
+		}
+		return nodeID
+	}
+	addNode(root)
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// summary returns the status and a short description of the condition summarizing the state of the node, if any.
+func (n *describeClusterNode) summary(conditionsFormat tree.ConditionsFormat) (string, string) {
+	if conditionsFormat.UseV1Beta1() {
+		for _, c := range n.Conditions {
+			if c.Type != clusterv1.ReadyCondition {
+				continue
+			}
+			if c.Reason != "" {
+				return string(c.Status), fmt.Sprintf("%s: %s (%s)", c.Type, c.Status, c.Reason)
+			}
+			return string(c.Status), fmt.Sprintf("%s: %s", c.Type, c.Status)
+		}
+		return "", ""
+	}
+
+	for _, conditionType := range []string{clusterv1.AvailableV1Beta2Condition, clusterv1.ReadyV1Beta2Condition} {
+		for _, c := range n.V1Beta2Conditions {
+			if c.Type != conditionType {
+				continue
+			}
+			if c.Reason != "" {
+				return string(c.Status), fmt.Sprintf("%s: %s (%s)", c.Type, c.Status, c.Reason)
+			}
+			return string(c.Status), fmt.Sprintf("%s: %s", c.Type, c.Status)
+		}
+	}
+	return "", ""
+}
+
+func dotStatusColor(status string) string {
+	switch status {
+	case string(metav1.ConditionTrue):
+		return "green"
+	case string(metav1.ConditionFalse):
+		return "red"
+	default:
+		return "orange"
+	}
+}
+
+// dotQuote returns a quoted dot ID; new lines are preserved as line breaks in labels.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/fatih/color"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/tree"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	v1beta2conditions "sigs.k8s.io/cluster-api/util/conditions/v1beta2"
+)
+
+func describeClusterOutputTestTree() *tree.ObjectTree {
+	root := fakeObject("root",
+		withCondition(conditions.TrueCondition(clusterv1.ReadyCondition)),
+		withCondition(conditions.TrueCondition("C1")),
+	)
+	objectTree := tree.NewObjectTree(root, tree.ObjectTreeOptions{})
+
+	// Using an unstructured object for the child, so it can have both v1beta1 and v1beta2 conditions.
+	child := &unstructured.Unstructured{}
+	child.SetKind("Object")
+	child.SetNamespace("ns")
+	child.SetName("child")
+	child.SetUID(types.UID("child"))
+	conditions.Set(conditions.UnstructuredSetter(child), conditions.FalseCondition(clusterv1.ReadyCondition, "NotReady", clusterv1.ConditionSeverityWarning, "not ready"))
+	v1beta2conditions.UnstructuredSet(child, metav1.Condition{Type: clusterv1.AvailableV1Beta2Condition, Status: metav1.ConditionFalse, Reason: "NotAvailable"})
+	objectTree.Add(root, child)
+	return objectTree
+}
+
+func Test_printObjectTreeJSON(t *testing.T) {
+	g := NewWithT(t)
+	color.NoColor = true
+
+	var out bytes.Buffer
+	g.Expect(printObjectTreeJSON(&out, describeClusterOutputTestTree(), tree.ConditionsFormatBoth)).To(Succeed())
+
+	node := &describeClusterNode{}
+	g.Expect(json.Unmarshal(out.Bytes(), node)).To(Succeed())
+	g.Expect(node.DisplayName).To(Equal("Object/root"))
+	g.Expect(node.Name).To(Equal("root"))
+	g.Expect(node.Conditions).To(HaveLen(2))
+	g.Expect(node.Conditions[0].Type).To(Equal(clusterv1.ReadyCondition))
+	g.Expect(node.Children).To(HaveLen(1))
+	g.Expect(node.Children[0].Conditions[0].Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+	g.Expect(node.Children[0].V1Beta2Conditions).To(HaveLen(1))
+}
+
+func Test_printObjectTreeYAML(t *testing.T) {
+	g := NewWithT(t)
+	color.NoColor = true
+
+	var out bytes.Buffer
+	g.Expect(printObjectTreeYAML(&out, describeClusterOutputTestTree(), tree.ConditionsFormatV1Beta2)).To(Succeed())
+
+	node := &describeClusterNode{}
+	g.Expect(yaml.Unmarshal(out.Bytes(), node)).To(Succeed())
+	g.Expect(node.Conditions).To(BeEmpty())
+	g.Expect(node.Children).To(HaveLen(1))
+	g.Expect(node.Children[0].V1Beta2Conditions[0].Reason).To(Equal("NotAvailable"))
+}
+
+func Test_printObjectTreeDOT(t *testing.T) {
+	tests := []struct {
+		name             string
+		conditionsFormat tree.ConditionsFormat
+		want             string
+	}{
+		{
+			name:             "Nodes are labelled with the Ready condition",
+			conditionsFormat: tree.ConditionsFormatV1Beta1,
+			want: `digraph "Object/root" {
+  node [shape=box, style=rounded];
+  n0 [label="Object/root\nReady: True", color=green];
+  n1 [label="Object/child\nReady: False (NotReady)", color=red];
+  n0 -> n1;
+}
+`,
+		},
+		{
+			name:             "Nodes are labelled with the v1beta2 summary condition",
+			conditionsFormat: tree.ConditionsFormatV1Beta2,
+			want: `digraph "Object/root" {
+  node [shape=box, style=rounded];
+  n0 [label="Object/root", color=gray];
+  n1 [label="Object/child\nAvailable: False (NotAvailable)", color=red];
+  n0 -> n1;
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			color.NoColor = true
+
+			var out bytes.Buffer
+			g.Expect(printObjectTreeDOT(&out, describeClusterOutputTestTree(), tt.conditionsFormat)).To(Succeed())
+			g.Expect(out.String()).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_dotQuote(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(dotQuote("a \"b\"\nc\\d")).To(Equal(`"a \"b\"\nc\\d"`))
+}
@@ -27,12 +27,15 @@ import (
 	. "github.com/onsi/gomega"
 	gtype "github.com/onsi/gomega/types"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/tree"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	v1beta2conditions "sigs.k8s.io/cluster-api/util/conditions/v1beta2"
 )
 
 func Test_getRowName(t *testing.T) {
@@ -152,6 +155,66 @@ func Test_newConditionDescriptor_truncateMessages(t *testing.T) {
 	}
 }
 
+func Test_newV1Beta2ConditionDescriptor(t *testing.T) {
+	tests := []struct {
+		name              string
+		condition         *metav1.Condition
+		expectStatusColor *color.Color
+		expectMessage     string
+	}{
+		{
+			name:              "True condition should be green",
+			condition:         &metav1.Condition{Type: "Available", Status: metav1.ConditionTrue},
+			expectStatusColor: green,
+		},
+		{
+			name:              "False condition should be red",
+			condition:         &metav1.Condition{Type: "Available", Status: metav1.ConditionFalse},
+			expectStatusColor: red,
+		},
+		{
+			name:              "Unknown condition should be white",
+			condition:         &metav1.Condition{Type: "Available", Status: metav1.ConditionUnknown},
+			expectStatusColor: white,
+		},
+		{
+			name:              "True condition with negative polarity should be yellow",
+			condition:         &metav1.Condition{Type: "Paused", Status: metav1.ConditionTrue},
+			expectStatusColor: yellow,
+		},
+		{
+			name:              "False condition with negative polarity should be green",
+			condition:         &metav1.Condition{Type: "Deleting", Status: metav1.ConditionFalse},
+			expectStatusColor: green,
+		},
+		{
+			name:              "Condition without status should be gray",
+			condition:         &metav1.Condition{},
+			expectStatusColor: gray,
+		},
+		{
+			name:              "Multiline messages are joined",
+			condition:         &metav1.Condition{Type: "Available", Status: metav1.ConditionFalse, Message: "* Machine m1:\n  * NotReady"},
+			expectStatusColor: red,
+			expectMessage:     "* Machine m1: * NotReady",
+		},
+		{
+			name:              "Long message are truncated",
+			condition:         &metav1.Condition{Type: "Available", Status: metav1.ConditionFalse, Message: strings.Repeat("s", 150)},
+			expectStatusColor: red,
+			expectMessage:     fmt.Sprintf("%s ...", strings.Repeat("s", 100)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			got := newV1Beta2ConditionDescriptor(tt.condition)
+			g.Expect(got.statusColor).To(Equal(tt.expectStatusColor))
+			g.Expect(got.message).To(Equal(tt.expectMessage))
+		})
+	}
+}
+
 func Test_TreePrefix(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -286,14 +349,168 @@ func Test_TreePrefix(t *testing.T) {
 			formatTableTree(tbl)
 
 			// Add row for the root object, the cluster, and recursively for all the nodes representing the cluster status.
-			addObjectRow("", tbl, tt.objectTree, tt.objectTree.GetRoot())
+			addObjectRow("", tbl, tt.objectTree, tt.objectTree.GetRoot(), treeView{})
+			tbl.Render()
+
+			g.Expect(output.String()).Should(MatchTable(tt.expectPrefix))
+		})
+	}
+}
+
+func Test_TreeView(t *testing.T) {
+	tests := []struct {
+		name         string
+		objectTree   *tree.ObjectTree
+		view         treeView
+		expectPrefix []string
+	}{
+		{
+			name: "Only problems collapses healthy branches",
+			objectTree: func() *tree.ObjectTree {
+				root := fakeObject("root", withCondition(conditions.FalseCondition(clusterv1.ReadyCondition, "R", clusterv1.ConditionSeverityWarning, "")))
+				objectTree := tree.NewObjectTree(root, tree.ObjectTreeOptions{})
+
+				o1 := fakeObject("child1", withCondition(conditions.TrueCondition(clusterv1.ReadyCondition)))
+				o1_1 := fakeObject("child1.1", withCondition(conditions.FalseCondition(clusterv1.ReadyCondition, "R", clusterv1.ConditionSeverityWarning, "")))
+				o1_2 := fakeObject("child1.2", withCondition(conditions.TrueCondition(clusterv1.ReadyCondition)))
+				o2 := fakeObject("child2", withCondition(conditions.TrueCondition(clusterv1.ReadyCondition)))
+				o2_1 := fakeObject("child2.1", withCondition(conditions.TrueCondition(clusterv1.ReadyCondition)))
+				o3 := fakeObject("child3", withDeletionTimestamp)
+				objectTree.Add(root, o1)
+				objectTree.Add(o1, o1_1)
+				objectTree.Add(o1, o1_2)
+				objectTree.Add(root, o2)
+				objectTree.Add(o2, o2_1)
+				objectTree.Add(root, o3)
+				return objectTree
+			}(),
+			view: treeView{onlyProblems: true},
+			expectPrefix: []string{
+				"Object/root",
+				"├─!! DELETED !! Object/child3", // deleted objects are sorted first
+				"├─Object/child1",
+				"│ ├─Object/child1.1",
+				"│ └─1 healthy object hidden",
+				"└─1 healthy object hidden",
+			},
+		},
+		{
+			name:       "Paging shows a page of children",
+			objectTree: fakeObjectTreeWithChildren(5),
+			view:       treeView{pageSize: 2, page: 2},
+			expectPrefix: []string{
+				"Object/root",
+				"├─Object/child3",
+				"├─Object/child4",
+				"└─Showing 3-4 of 5 objects, use --page 3 for more",
+			},
+		},
+		{
+			name:       "Paging shows the last page of children",
+			objectTree: fakeObjectTreeWithChildren(5),
+			view:       treeView{pageSize: 2, page: 3},
+			expectPrefix: []string{
+				"Object/root",
+				"├─Object/child5",
+				"└─Showing 5-5 of 5 objects",
+			},
+		},
+		{
+			name:       "Paging reports pages without children",
+			objectTree: fakeObjectTreeWithChildren(5),
+			view:       treeView{pageSize: 2, page: 4},
+			expectPrefix: []string{
+				"Object/root",
+				"└─No objects in page 4, 5 objects in 3 pages",
+			},
+		},
+		{
+			name: "Condition types filter the conditions",
+			objectTree: func() *tree.ObjectTree {
+				root := fakeObject("root",
+					withAnnotation(tree.ShowObjectConditionsAnnotation, "True"),
+					withCondition(conditions.TrueCondition("C1")),
+					withCondition(conditions.TrueCondition("C2")),
+					withCondition(conditions.TrueCondition("C3")),
+				)
+				return tree.NewObjectTree(root, tree.ObjectTreeOptions{})
+			}(),
+			view: treeView{conditionTypes: sets.New[string]("C1", "C3")},
+			expectPrefix: []string{
+				"Object/root",
+				"            ├─C1",
+				"            └─C3",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			var output bytes.Buffer
+
+			tbl := tablewriter.NewWriter(&output)
+			formatTableTree(tbl)
+			addObjectRow("", tbl, tt.objectTree, tt.objectTree.GetRoot(), tt.view)
 			tbl.Render()
 
 			g.Expect(output.String()).Should(MatchTable(tt.expectPrefix))
+			g.Expect(strings.Split(strings.TrimSpace(output.String()), "\n")).To(HaveLen(len(tt.expectPrefix)))
 		})
 	}
 }
 
+func Test_hasV1Beta2Problem(t *testing.T) {
+	tests := []struct {
+		name      string
+		obj       ctrlclient.Object
+		expectRes bool
+	}{
+		{
+			name:      "Object without conditions has no problems",
+			obj:       fakeV1Beta2Object(),
+			expectRes: false,
+		},
+		{
+			name:      "Object available has no problems",
+			obj:       fakeV1Beta2Object(metav1.Condition{Type: clusterv1.AvailableV1Beta2Condition, Status: metav1.ConditionTrue}, metav1.Condition{Type: clusterv1.ReadyV1Beta2Condition, Status: metav1.ConditionFalse}),
+			expectRes: false,
+		},
+		{
+			name:      "Object not ready has problems",
+			obj:       fakeV1Beta2Object(metav1.Condition{Type: clusterv1.ReadyV1Beta2Condition, Status: metav1.ConditionFalse}),
+			expectRes: true,
+		},
+		{
+			name:      "Object with unknown readiness has problems",
+			obj:       fakeV1Beta2Object(metav1.Condition{Type: clusterv1.ReadyV1Beta2Condition, Status: metav1.ConditionUnknown}),
+			expectRes: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(hasV1Beta2Problem(tt.obj)).To(Equal(tt.expectRes))
+		})
+	}
+}
+
+func fakeObjectTreeWithChildren(children int) *tree.ObjectTree {
+	root := fakeObject("root")
+	objectTree := tree.NewObjectTree(root, tree.ObjectTreeOptions{})
+	for i := 1; i <= children; i++ {
+		objectTree.Add(root, fakeObject(fmt.Sprintf("child%d", i)))
+	}
+	return objectTree
+}
+
+func fakeV1Beta2Object(v1beta2Conditions ...metav1.Condition) ctrlclient.Object {
+	obj := &unstructured.Unstructured{}
+	obj.SetKind("Object")
+	obj.SetName("obj")
+	v1beta2conditions.UnstructuredSetter(obj).SetV1Beta2Conditions(v1beta2Conditions)
+	return obj
+}
+
 type objectOption func(object ctrlclient.Object)
 
 func fakeObject(name string, options ...objectOption) ctrlclient.Object {
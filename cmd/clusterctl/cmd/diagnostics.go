@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type diagnosticsOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+	namespace         string
+	output            string
+	logsSince         time.Duration
+	eventsSince       time.Duration
+	skipLogs          bool
+	redactPatterns    []string
+}
+
+var diag = &diagnosticsOptions{}
+
+var diagnosticsCmd = &cobra.Command{
+	Use:     "diagnostics",
+	GroupID: groupDebug,
+	Short:   "Collect a diagnostics bundle from a management cluster",
+	Long: LongDesc(`
+		Collect a diagnostics bundle from a management cluster, e.g. for attaching it to a support case.
+
+		The bundle is a gzipped tarball with the provider inventory, the provider CRDs and webhook configurations,
+		the Cluster API objects with a summary of their conditions, the recent Events, and the controller pods with their logs.
+
+		Secrets are never collected, and tokens, passwords and private keys are redacted from all the files in the bundle;
+		please review the bundle before sharing it anyway.`),
+
+	Example: Examples(`
+		# Collect a diagnostics bundle from the management cluster into clusterctl-diagnostics-<timestamp>.tar.gz.
+		clusterctl diagnostics
+
+		# Collect the Cluster API objects and the Events only from the namespace foo, into the given file.
+		clusterctl diagnostics -n foo -o diagnostics.tar.gz
+
+		# Collect the controller logs of the last 2 hours, and redact the given IP addresses too.
+		clusterctl diagnostics --logs-since 2h --redact '10\.0\.[0-9]+\.[0-9]+'
+
+		# Write the diagnostics bundle to stdout.
+		clusterctl diagnostics -o - > diagnostics.tar.gz`),
+
+	Args: cobra.NoArgs,
+	RunE: func(*cobra.Command, []string) error {
+		return runDiagnostics()
+	},
+}
+
+func init() {
+	diagnosticsCmd.Flags().StringVar(&diag.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file to use for accessing the management cluster. If unspecified, default discovery rules apply.")
+	diagnosticsCmd.Flags().StringVar(&diag.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	diagnosticsCmd.Flags().StringVarP(&diag.namespace, "namespace", "n", "",
+		"The namespace where the Cluster API objects and the Events are collected from. If unspecified, all the namespaces are used.")
+	diagnosticsCmd.Flags().StringVarP(&diag.output, "output", "o", "",
+		"The file the diagnostics bundle is written to, or - for stdout. If unspecified, clusterctl-diagnostics-<timestamp>.tar.gz is used.")
+	diagnosticsCmd.Flags().DurationVar(&diag.logsSince, "logs-since", 24*time.Hour,
+		"Only collect the controller logs newer than the given duration. If 0, all the logs are collected.")
+	diagnosticsCmd.Flags().DurationVar(&diag.eventsSince, "events-since", time.Hour,
+		"Only collect the Events that happened within the given duration. If 0, all the Events are collected.")
+	diagnosticsCmd.Flags().BoolVar(&diag.skipLogs, "skip-logs", false,
+		"Skip collecting the controller logs.")
+	diagnosticsCmd.Flags().StringSliceVar(&diag.redactPatterns, "redact", nil,
+		"Additional regular expressions for the data to be redacted from the diagnostics bundle; if a pattern has a capturing group, the text matching the first group is preserved.")
+
+	diagnosticsCmd.MarkFlagsMutuallyExclusive("logs-since", "skip-logs")
+
+	RootCmd.AddCommand(diagnosticsCmd)
+}
+
+func runDiagnostics() error {
+	ctx := context.Background()
+
+	c, err := client.New(ctx, cfgFile)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	output := diag.output
+	if output != "-" {
+		if output == "" {
+			output = fmt.Sprintf("clusterctl-diagnostics-%s.tar.gz", time.Now().UTC().Format("20060102-150405"))
+		}
+		// The bundle can contain sensitive data not matching the redact patterns, so it is readable only by the current user.
+		f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create the diagnostics bundle file %q", output)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := c.CollectDiagnostics(ctx, w, client.CollectDiagnosticsOptions{
+		Kubeconfig:     client.Kubeconfig{Path: diag.kubeconfig, Context: diag.kubeconfigContext},
+		Namespace:      diag.namespace,
+		LogsSince:      diag.logsSince,
+		EventsSince:    diag.eventsSince,
+		SkipLogs:       diag.skipLogs,
+		RedactPatterns: diag.redactPatterns,
+	}); err != nil {
+		return err
+	}
+
+	if output != "-" {
+		fmt.Fprintf(os.Stderr, "Diagnostics bundle written to %s\n", output)
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -43,6 +44,7 @@ type generateClusterOptions struct {
 	configMapDataKey   string
 
 	listVariables bool
+	interactive   bool
 
 	output string
 }
@@ -90,7 +92,11 @@ var generateClusterClusterCmd = &cobra.Command{
 		clusterctl generate cluster my-cluster --from ~/workspace/cluster-template.yaml
 
 		# Prints the list of variables required by the yaml file for creating workload cluster.
-		clusterctl generate cluster my-cluster --list-variables`),
+		clusterctl generate cluster my-cluster --list-variables
+
+		# Generates a yaml file for creating workload clusters using a ClusterClass, prompting
+		# for the values of the ClusterClass variables.
+		clusterctl generate cluster my-cluster --flavor development --interactive`),
 
 	Args: func(_ *cobra.Command, args []string) error {
 		if len(args) != 1 {
@@ -141,6 +147,8 @@ func init() {
 	// other flags
 	generateClusterClusterCmd.Flags().BoolVar(&gc.listVariables, "list-variables", false,
 		"Returns the list of variables expected by the template instead of the template yaml")
+	generateClusterClusterCmd.Flags().BoolVar(&gc.interactive, "interactive", false,
+		"Prompt for the values of the variables of the ClusterClass used by the workload cluster template, validating them against the variable schemas")
 	generateClusterClusterCmd.Flags().StringVar(&gc.output, "write-to", "", "Specify the output file to write the template to, defaults to STDOUT if the flag is not set")
 
 	generateCmd.AddCommand(generateClusterClusterCmd)
@@ -149,6 +157,13 @@ func init() {
 func runGenerateClusterTemplate(cmd *cobra.Command, name string) error {
 	ctx := context.Background()
 
+	if gc.interactive && gc.listVariables {
+		return errors.New("--interactive and --list-variables are mutually exclusive")
+	}
+	if gc.interactive && gc.url == "-" {
+		return errors.New("--interactive can't be used when reading the template from stdin")
+	}
+
 	c, err := client.New(ctx, cfgFile)
 	if err != nil {
 		return err
@@ -199,5 +214,12 @@ func runGenerateClusterTemplate(cmd *cobra.Command, name string) error {
 		return printVariablesOutput(template, templateOptions)
 	}
 
+	if gc.interactive {
+		// Prompts are written to stderr, so the template can be written to stdout.
+		if err := fillClusterVariablesInteractively(ctx, os.Stdin, os.Stderr, template); err != nil {
+			return err
+		}
+	}
+
 	return printYamlOutput(template, gc.output)
 }
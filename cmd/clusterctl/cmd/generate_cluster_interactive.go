@@ -0,0 +1,368 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/internal/topology/variables"
+)
+
+// fillClusterVariablesInteractively prompts for the variables of the ClusterClass used by the Cluster in the template,
+// and sets them in the Cluster topology.
+func fillClusterVariablesInteractively(ctx context.Context, in io.Reader, out io.Writer, template client.Template) error {
+	objs := template.Objs()
+	var clusterObj *unstructured.Unstructured
+	for i := range objs {
+		if objs[i].GroupVersionKind().GroupKind() != clusterv1.GroupVersion.WithKind("Cluster").GroupKind() {
+			continue
+		}
+		if class, _, _ := unstructured.NestedString(objs[i].Object, "spec", "topology", "class"); class != "" {
+			clusterObj = &objs[i]
+			break
+		}
+	}
+	if clusterObj == nil {
+		return errors.New("the --interactive flag requires a template with a Cluster using a ClusterClass")
+	}
+
+	class, _, _ := unstructured.NestedString(clusterObj.Object, "spec", "topology", "class")
+	clusterClass, err := getClusterClassForTemplate(ctx, objs, clusterObj.GetNamespace(), class)
+	if err != nil {
+		return err
+	}
+
+	current, err := getTopologyVariables(clusterObj)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Cluster %s uses ClusterClass %s; please provide the values for the ClusterClass variables.\n", clusterObj.GetName(), clusterClass.Name)
+	values, err := promptClusterVariables(in, out, clusterClassVariableDefinitions(clusterClass), current)
+	if err != nil {
+		return err
+	}
+	return setTopologyVariables(clusterObj, values)
+}
+
+// getClusterClassForTemplate returns the ClusterClass from the template, or from the management cluster if the ClusterClass is not in the template.
+func getClusterClassForTemplate(ctx context.Context, objs []unstructured.Unstructured, namespace, name string) (*clusterv1.ClusterClass, error) {
+	clusterClass := &clusterv1.ClusterClass{}
+	for i := range objs {
+		if objs[i].GroupVersionKind().GroupKind() != clusterv1.GroupVersion.WithKind("ClusterClass").GroupKind() ||
+			objs[i].GetName() != name || objs[i].GetNamespace() != namespace {
+			continue
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(objs[i].Object, clusterClass); err != nil {
+			return nil, errors.Wrapf(err, "failed to convert ClusterClass %s", name)
+		}
+		return clusterClass, nil
+	}
+
+	configClient, err := config.New(ctx, cfgFile)
+	if err != nil {
+		return nil, err
+	}
+	c, err := cluster.New(cluster.Kubeconfig{Path: gc.kubeconfig, Context: gc.kubeconfigContext}, configClient).Proxy().NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: name}, clusterClass); err != nil {
+		return nil, errors.Wrapf(err, "failed to get ClusterClass %s/%s, it is not in the template nor in the management cluster", namespace, name)
+	}
+	return clusterClass, nil
+}
+
+// clusterClassVariableDefinitions returns the variables defined in a ClusterClass, including the variables
+// defined by external patches and reported only in the ClusterClass status.
+func clusterClassVariableDefinitions(clusterClass *clusterv1.ClusterClass) []clusterv1.ClusterClassVariable {
+	definitions := append([]clusterv1.ClusterClassVariable{}, clusterClass.Spec.Variables...)
+	for _, v := range clusterClass.Status.Variables {
+		found := false
+		for _, d := range definitions {
+			if d.Name == v.Name {
+				found = true
+				break
+			}
+		}
+		if found || len(v.Definitions) == 0 {
+			continue
+		}
+		definitions = append(definitions, clusterv1.ClusterClassVariable{
+			Name:     v.Name,
+			Required: v.Definitions[0].Required,
+			Metadata: v.Definitions[0].Metadata,
+			Schema:   v.Definitions[0].Schema,
+		})
+	}
+	return definitions
+}
+
+// promptClusterVariables prompts for the value of each variable, showing the type, the description, the allowed values
+// and the default of each variable, and validating the values against the variable schema.
+// If there is a current value for a variable, it is used as the default.
+func promptClusterVariables(in io.Reader, out io.Writer, definitions []clusterv1.ClusterClassVariable, current []clusterv1.ClusterVariable) ([]clusterv1.ClusterVariable, error) {
+	reader := bufio.NewReader(in)
+	currentValues := map[string]clusterv1.ClusterVariable{}
+	for _, v := range current {
+		currentValues[v.Name] = v
+	}
+
+	values := []clusterv1.ClusterVariable{}
+	for i := range definitions {
+		definition := &definitions[i]
+		schema := definition.Schema.OpenAPIV3Schema
+
+		// The current value takes precedence over the default value in the schema.
+		var defaultValue *apiextensionsv1.JSON
+		if v, ok := currentValues[definition.Name]; ok {
+			defaultValue = &v.Value
+		} else if schema.Default != nil {
+			defaultValue = schema.Default
+		}
+
+		fmt.Fprintln(out, "")
+		fmt.Fprintf(out, "%s (%s)\n", definition.Name, describeVariableType(definition))
+		if schema.Description != "" {
+			fmt.Fprintf(out, "  %s\n", schema.Description)
+		}
+		if len(schema.Enum) > 0 {
+			enum := []string{}
+			for _, e := range schema.Enum {
+				enum = append(enum, string(e.Raw))
+			}
+			fmt.Fprintf(out, "  Allowed values: %s\n", strings.Join(enum, ", "))
+		}
+		if schema.Type == "object" || schema.Type == "array" {
+			fmt.Fprintf(out, "  Enter the value as a single line of JSON or YAML flow style, e.g. %s\n", exampleVariableValue(schema))
+		}
+
+		for {
+			if defaultValue != nil {
+				fmt.Fprintf(out, "Value for %s [%s]: ", definition.Name, string(defaultValue.Raw))
+			} else {
+				fmt.Fprintf(out, "Value for %s: ", definition.Name)
+			}
+
+			line, err := reader.ReadString('\n')
+			if err != nil && (err != io.EOF || line == "") {
+				if err == io.EOF {
+					return nil, errors.Errorf("failed to read the value for variable %q: unexpected end of input", definition.Name)
+				}
+				return nil, errors.Wrapf(err, "failed to read the value for variable %q", definition.Name)
+			}
+			line = strings.TrimSpace(line)
+
+			var value *apiextensionsv1.JSON
+			switch {
+			case line != "":
+				value, err = parseVariableValue(line, schema.Type)
+				if err != nil {
+					fmt.Fprintf(out, "Invalid value: %v\n", err)
+					continue
+				}
+			case defaultValue != nil:
+				value = defaultValue
+			case definition.Required:
+				fmt.Fprintf(out, "Invalid value: variable %q is required\n", definition.Name)
+				continue
+			}
+
+			// The variable is optional, and it has no default.
+			if value == nil {
+				break
+			}
+
+			v := clusterv1.ClusterVariable{Name: definition.Name, Value: *value}
+			if current, ok := currentValues[definition.Name]; ok {
+				v.DefinitionFrom = current.DefinitionFrom
+			}
+			if errs := variables.ValidateClusterVariable(&v, definition, field.NewPath(definition.Name)); len(errs) > 0 {
+				fmt.Fprintf(out, "Invalid value: %v\n", errs.ToAggregate())
+				continue
+			}
+			values = append(values, v)
+			break
+		}
+	}
+
+	// Preserve current values for variables not defined in the ClusterClass, so the webhook can report them.
+	for _, v := range current {
+		found := false
+		for _, d := range definitions {
+			if d.Name == v.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+// describeVariableType returns a short description of the variable type, e.g. "integer, required, minimum 1".
+func describeVariableType(definition *clusterv1.ClusterClassVariable) string {
+	schema := definition.Schema.OpenAPIV3Schema
+	description := []string{schema.Type}
+	if schema.Type == "" {
+		description = []string{"any"}
+	}
+	if schema.Type == "array" && schema.Items != nil && schema.Items.Type != "" {
+		description = []string{fmt.Sprintf("array of %s", schema.Items.Type)}
+	}
+	if definition.Required {
+		description = append(description, "required")
+	}
+	if schema.Format != "" {
+		description = append(description, fmt.Sprintf("format %s", schema.Format))
+	}
+	if schema.Pattern != "" {
+		description = append(description, fmt.Sprintf("pattern %s", schema.Pattern))
+	}
+	if schema.Minimum != nil {
+		description = append(description, fmt.Sprintf("minimum %d", *schema.Minimum))
+	}
+	if schema.Maximum != nil {
+		description = append(description, fmt.Sprintf("maximum %d", *schema.Maximum))
+	}
+	if schema.MinLength != nil {
+		description = append(description, fmt.Sprintf("min length %d", *schema.MinLength))
+	}
+	if schema.MaxLength != nil {
+		description = append(description, fmt.Sprintf("max length %d", *schema.MaxLength))
+	}
+	return strings.Join(description, ", ")
+}
+
+// exampleVariableValue returns an example value for object and array variables.
+func exampleVariableValue(schema clusterv1.JSONSchemaProps) string {
+	if schema.Example != nil {
+		return string(schema.Example.Raw)
+	}
+	if schema.Type == "array" {
+		return `["a", "b"]`
+	}
+	if len(schema.Properties) > 0 {
+		keys := make([]string, 0, len(schema.Properties))
+		for k := range schema.Properties {
+			keys = append(keys, k)
+		}
+		return fmt.Sprintf(`{"%s": ...}`, slices.Min(keys))
+	}
+	return `{"key": "value"}`
+}
+
+// parseVariableValue parses a value entered by the user according to the variable type.
+func parseVariableValue(input, variableType string) (*apiextensionsv1.JSON, error) {
+	var value interface{}
+	switch variableType {
+	case "string":
+		// Strings can be entered with or without quotes.
+		if unquoted, err := strconv.Unquote(input); err == nil && strings.HasPrefix(input, `"`) {
+			input = unquoted
+		}
+		value = input
+	case "integer":
+		i, err := strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("%q is not an integer", input)
+		}
+		value = i
+	case "number":
+		n, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			return nil, errors.Errorf("%q is not a number", input)
+		}
+		value = n
+	case "boolean":
+		b, err := strconv.ParseBool(input)
+		if err != nil {
+			return nil, errors.Errorf("%q is not a boolean", input)
+		}
+		value = b
+	default:
+		if err := yaml.Unmarshal([]byte(input), &value); err != nil {
+			return nil, errors.Errorf("%q is not valid JSON or YAML", input)
+		}
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return &apiextensionsv1.JSON{Raw: raw}, nil
+}
+
+// getTopologyVariables returns the variables of a Cluster topology.
+func getTopologyVariables(clusterObj *unstructured.Unstructured) ([]clusterv1.ClusterVariable, error) {
+	items, _, err := unstructured.NestedSlice(clusterObj.Object, "spec", "topology", "variables")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get variables from Cluster %s", clusterObj.GetName())
+	}
+
+	values := []clusterv1.ClusterVariable{}
+	for _, item := range items {
+		raw, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		v := clusterv1.ClusterVariable{}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, errors.Wrapf(err, "failed to get variables from Cluster %s", clusterObj.GetName())
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// setTopologyVariables sets the variables of a Cluster topology.
+func setTopologyVariables(clusterObj *unstructured.Unstructured, values []clusterv1.ClusterVariable) error {
+	items := []interface{}{}
+	for _, v := range values {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		item := map[string]interface{}{}
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+	return unstructured.SetNestedSlice(clusterObj.Object, items, "spec", "topology", "variables")
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/utils/ptr"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	yaml "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_promptClusterVariables(t *testing.T) {
+	definitions := []clusterv1.ClusterClassVariable{
+		{
+			Name:     "replicas",
+			Required: true,
+			Schema: clusterv1.VariableSchema{OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+				Type:    "integer",
+				Minimum: ptr.To[int64](1),
+			}},
+		},
+		{
+			Name: "region",
+			Schema: clusterv1.VariableSchema{OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+				Type:        "string",
+				Description: "The region of the cluster.",
+				Enum:        []apiextensionsv1.JSON{{Raw: []byte(`"eu"`)}, {Raw: []byte(`"us"`)}},
+				Default:     &apiextensionsv1.JSON{Raw: []byte(`"eu"`)},
+			}},
+		},
+		{
+			Name: "proxy",
+			Schema: clusterv1.VariableSchema{OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]clusterv1.JSONSchemaProps{
+					"url": {Type: "string"},
+				},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		current    []clusterv1.ClusterVariable
+		input      string
+		want       []clusterv1.ClusterVariable
+		wantOutput []string
+		wantErr    bool
+	}{
+		{
+			name:  "Values are parsed according to the type, defaults are used for empty values and optional variables are skipped",
+			input: "3\n\n\n",
+			want: []clusterv1.ClusterVariable{
+				{Name: "replicas", Value: apiextensionsv1.JSON{Raw: []byte(`3`)}},
+				{Name: "region", Value: apiextensionsv1.JSON{Raw: []byte(`"eu"`)}},
+			},
+			wantOutput: []string{
+				"replicas (integer, required, minimum 1)",
+				"The region of the cluster.",
+				`Allowed values: "eu", "us"`,
+				`Value for region ["eu"]: `,
+				`e.g. {"url": ...}`,
+			},
+		},
+		{
+			name:  "Invalid values are prompted again",
+			input: "abc\n0\n\n2\nasia\nus\n{url: http://proxy}\n",
+			want: []clusterv1.ClusterVariable{
+				{Name: "replicas", Value: apiextensionsv1.JSON{Raw: []byte(`2`)}},
+				{Name: "region", Value: apiextensionsv1.JSON{Raw: []byte(`"us"`)}},
+				{Name: "proxy", Value: apiextensionsv1.JSON{Raw: []byte(`{"url":"http://proxy"}`)}},
+			},
+			wantOutput: []string{
+				`Invalid value: "abc" is not an integer`,
+				"should be greater than or equal to 1",
+				`Invalid value: variable "replicas" is required`,
+				"supported values",
+			},
+		},
+		{
+			name: "Current values are used as defaults, and values for unknown variables are preserved",
+			current: []clusterv1.ClusterVariable{
+				{Name: "replicas", Value: apiextensionsv1.JSON{Raw: []byte(`5`)}},
+				{Name: "unknown", Value: apiextensionsv1.JSON{Raw: []byte(`true`)}},
+			},
+			input: "\n\n\n",
+			want: []clusterv1.ClusterVariable{
+				{Name: "replicas", Value: apiextensionsv1.JSON{Raw: []byte(`5`)}},
+				{Name: "region", Value: apiextensionsv1.JSON{Raw: []byte(`"eu"`)}},
+				{Name: "unknown", Value: apiextensionsv1.JSON{Raw: []byte(`true`)}},
+			},
+			wantOutput: []string{
+				"Value for replicas [5]: ",
+			},
+		},
+		{
+			name:    "Fails at the end of the input",
+			input:   "abc\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			var out bytes.Buffer
+			got, err := promptClusterVariables(strings.NewReader(tt.input), &out, definitions, tt.current)
+			if tt.wantErr {
+				g.Expect(err).To(MatchError(ContainSubstring("unexpected end of input")))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+			for _, o := range tt.wantOutput {
+				g.Expect(out.String()).To(ContainSubstring(o))
+			}
+		})
+	}
+}
+
+func Test_parseVariableValue(t *testing.T) {
+	tests := []struct {
+		input        string
+		variableType string
+		want         string
+		wantErr      bool
+	}{
+		{input: "foo", variableType: "string", want: `"foo"`},
+		{input: `"foo bar"`, variableType: "string", want: `"foo bar"`},
+		{input: "123", variableType: "string", want: `"123"`},
+		{input: "1.5", variableType: "number", want: `1.5`},
+		{input: "1.5", variableType: "integer", wantErr: true},
+		{input: "true", variableType: "boolean", want: `true`},
+		{input: "yes", variableType: "boolean", wantErr: true},
+		{input: "[a, b]", variableType: "array", want: `["a","b"]`},
+		{input: `{"a": 1}`, variableType: "object", want: `{"a":1}`},
+		{input: "{a: 1", variableType: "object", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.variableType+" "+tt.input, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := parseVariableValue(tt.input, tt.variableType)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(got.Raw)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_fillClusterVariablesInteractively(t *testing.T) {
+	g := NewWithT(t)
+
+	template, err := repository.NewTemplate(repository.TemplateInput{
+		RawArtifact: []byte(`apiVersion: cluster.x-k8s.io/v1beta1
+kind: ClusterClass
+metadata:
+  name: quick-start
+  namespace: ns1
+spec:
+  variables:
+  - name: replicas
+    required: true
+    schema:
+      openAPIV3Schema:
+        type: integer
+---
+apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: my-cluster
+  namespace: ns1
+spec:
+  topology:
+    class: quick-start
+    version: v1.30.0
+    variables:
+    - name: replicas
+      value: 1
+`),
+		ConfigVariablesClient: test.NewFakeVariableClient(),
+		Processor:             yaml.NewSimpleProcessor(),
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	var out bytes.Buffer
+	g.Expect(fillClusterVariablesInteractively(context.Background(), strings.NewReader("3\n"), &out, template)).To(Succeed())
+	g.Expect(out.String()).To(ContainSubstring("Cluster my-cluster uses ClusterClass quick-start"))
+
+	got, err := template.Yaml()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(got)).To(ContainSubstring("    variables:\n    - name: replicas\n      value: 3\n"))
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/scaffold"
+	"sigs.k8s.io/cluster-api/version"
+)
+
+type generateProviderScaffoldOptions struct {
+	kind              string
+	name              string
+	module            string
+	apiVersion        string
+	clusterAPIVersion string
+	outputDir         string
+}
+
+var gpso = &generateProviderScaffoldOptions{}
+
+// scaffoldProviderTypes maps the values of the --kind flag to the provider types.
+var scaffoldProviderTypes = map[string]clusterctlv1.ProviderType{
+	"infrastructure": clusterctlv1.InfrastructureProviderType,
+	"bootstrap":      clusterctlv1.BootstrapProviderType,
+	"control-plane":  clusterctlv1.ControlPlaneProviderType,
+}
+
+var generateProviderScaffoldCmd = &cobra.Command{
+	Use:   "provider-scaffold",
+	Args:  cobra.NoArgs,
+	Short: "Generate the skeleton of a new provider",
+	Long: LongDesc(`
+		Generate the skeleton of a new provider.
+
+		The skeleton is a buildable Go module wired to the current Cluster API contract: the API types with
+		the fields required by the contract and the v1beta2 conditions, the controllers, the webhooks, the
+		kustomize configuration for building the components YAML following the clusterctl conventions and
+		an e2e test harness based on the Cluster API e2e test framework.`),
+
+	Example: Examples(`
+		# Generates the skeleton of the foo infrastructure provider in the cluster-api-provider-foo folder.
+		clusterctl generate provider-scaffold --kind infrastructure --name foo
+
+		# Generates the skeleton of the foo bootstrap provider using a specific Go module.
+		clusterctl generate provider-scaffold --kind bootstrap --name foo --module github.com/foo-org/cluster-api-bootstrap-provider-foo`),
+
+	RunE: func(*cobra.Command, []string) error {
+		return runGenerateProviderScaffold(os.Stdout)
+	},
+}
+
+func init() {
+	generateProviderScaffoldCmd.Flags().StringVar(&gpso.kind, "kind", "",
+		"The kind of provider to generate, one of infrastructure, bootstrap or control-plane.")
+	generateProviderScaffoldCmd.Flags().StringVar(&gpso.name, "name", "",
+		"The name of the provider, e.g. foo for the cluster-api-provider-foo infrastructure provider.")
+	generateProviderScaffoldCmd.Flags().StringVar(&gpso.module, "module", "",
+		"The Go module of the provider. If unspecified, github.com/example/cluster-api-provider-<name> is used.")
+	generateProviderScaffoldCmd.Flags().StringVar(&gpso.apiVersion, "api-version", "v1alpha1",
+		"The version of the provider API types.")
+	generateProviderScaffoldCmd.Flags().StringVar(&gpso.clusterAPIVersion, "cluster-api-version", "",
+		"The version of Cluster API the provider depends on. If unspecified, the version of clusterctl is used.")
+	generateProviderScaffoldCmd.Flags().StringVar(&gpso.outputDir, "output-dir", "",
+		"The folder where to generate the provider. If unspecified, cluster-api-provider-<name> is used.")
+
+	_ = generateProviderScaffoldCmd.MarkFlagRequired("kind")
+	_ = generateProviderScaffoldCmd.MarkFlagRequired("name")
+
+	generateCmd.AddCommand(generateProviderScaffoldCmd)
+}
+
+func runGenerateProviderScaffold(w io.Writer) error {
+	providerType, ok := scaffoldProviderTypes[gpso.kind]
+	if !ok {
+		return errors.Errorf("invalid --kind %q, the supported kinds are infrastructure, bootstrap and control-plane", gpso.kind)
+	}
+
+	clusterAPIVersion := gpso.clusterAPIVersion
+	if clusterAPIVersion == "" {
+		clusterAPIVersion = version.Get().GitVersion
+		if clusterAPIVersion == "" {
+			return errors.New("unable to detect the version of clusterctl, please set --cluster-api-version")
+		}
+	}
+
+	files, err := scaffold.Generate(scaffold.Options{
+		ProviderType:      providerType,
+		Name:              gpso.name,
+		Module:            gpso.module,
+		APIVersion:        gpso.apiVersion,
+		ClusterAPIVersion: clusterAPIVersion,
+	})
+	if err != nil {
+		return err
+	}
+
+	outputDir := gpso.outputDir
+	if outputDir == "" {
+		outputDir = "cluster-api-provider-" + gpso.name
+	}
+	if err := writeProviderScaffold(outputDir, files); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Provider skeleton generated in %s\n\n", outputDir)
+	fmt.Fprintf(w, "Next steps:\n")
+	fmt.Fprintf(w, "  cd %s\n", outputDir)
+	fmt.Fprintf(w, "  go mod tidy\n")
+	fmt.Fprintf(w, "  make generate\n")
+	fmt.Fprintf(w, "  make build\n")
+	return nil
+}
+
+// writeProviderScaffold writes the files of the provider skeleton, refusing to overwrite an existing
+// folder unless it is empty.
+func writeProviderScaffold(outputDir string, files []scaffold.File) error {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to read %s", outputDir)
+	}
+	if len(entries) > 0 {
+		return errors.Errorf("%s already exists and is not empty", outputDir)
+	}
+
+	for _, f := range files {
+		p := filepath.Join(outputDir, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(p), 0750); err != nil {
+			return errors.Wrapf(err, "failed to create the folder for %s", p)
+		}
+		if err := os.WriteFile(p, f.Content, 0600); err != nil {
+			return errors.Wrapf(err, "failed to write %s", p)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_runGenerateProviderScaffold(t *testing.T) {
+	g := NewWithT(t)
+
+	outputDir := filepath.Join(t.TempDir(), "cluster-api-provider-foo")
+	gpso = &generateProviderScaffoldOptions{
+		kind:              "infrastructure",
+		name:              "foo",
+		apiVersion:        "v1alpha1",
+		clusterAPIVersion: "v1.7.0",
+		outputDir:         outputDir,
+	}
+	defer func() { gpso = &generateProviderScaffoldOptions{} }()
+
+	out := &bytes.Buffer{}
+	g.Expect(runGenerateProviderScaffold(out)).To(Succeed())
+	g.Expect(out.String()).To(ContainSubstring("Provider skeleton generated in " + outputDir))
+	g.Expect(filepath.Join(outputDir, "go.mod")).To(BeAnExistingFile())
+	g.Expect(filepath.Join(outputDir, "api", "v1alpha1", "foocluster_types.go")).To(BeAnExistingFile())
+
+	// Generating again in the same folder fails, thus preserving the changes to the generated files.
+	g.Expect(runGenerateProviderScaffold(out)).ToNot(Succeed())
+
+	// Generating in an existing empty folder succeeds.
+	emptyDir := filepath.Join(t.TempDir(), "empty")
+	g.Expect(os.MkdirAll(emptyDir, 0750)).To(Succeed())
+	gpso.outputDir = emptyDir
+	g.Expect(runGenerateProviderScaffold(out)).To(Succeed())
+
+	gpso.kind = "core"
+	g.Expect(runGenerateProviderScaffold(out)).ToNot(Succeed())
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+const (
+	// GetClustersOutputText is an option used to print the clusters in text format.
+	GetClustersOutputText = "text"
+	// GetClustersOutputWide is an option used to print the clusters in text format, with additional columns.
+	GetClustersOutputWide = "wide"
+	// GetClustersOutputJSON is an option used to print the clusters in json format.
+	GetClustersOutputJSON = "json"
+)
+
+var (
+	// GetClustersOutputs is a list of valid get clusters outputs.
+	GetClustersOutputs = []string{GetClustersOutputText, GetClustersOutputWide, GetClustersOutputJSON}
+)
+
+type getClustersOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+	namespace         string
+	output            string
+}
+
+var gcl = &getClustersOptions{}
+
+var getClustersCmd = &cobra.Command{
+	Use:   "clusters",
+	Short: "Gets a summary of the workload clusters in the management cluster",
+	Long: LongDesc(`
+		Gets a summary of the workload clusters in the management cluster, with their phase, ClusterClass,
+		Kubernetes version, control plane and worker replicas, and the top failing condition.
+
+		Use 'clusterctl describe cluster' to get the status of all the objects of a workload cluster.`),
+
+	Example: Examples(`
+		# Gets a summary of the workload clusters in all the namespaces.
+		clusterctl get clusters
+
+		# Gets a summary of the workload clusters in a namespace, with the references to the control plane
+		# and to the infrastructure, and the message of the top failing condition.
+		clusterctl get clusters --namespace foo -o wide
+
+		# Gets a summary of the workload clusters in json format.
+		clusterctl get clusters -o json`),
+
+	Args: cobra.NoArgs,
+	RunE: func(*cobra.Command, []string) error {
+		return runGetClusters(os.Stdout)
+	},
+}
+
+func init() {
+	getClustersCmd.Flags().StringVarP(&gcl.namespace, "namespace", "n", "",
+		"Namespace where the workload clusters exist. If unspecified, the clusters in all the namespaces are listed.")
+	getClustersCmd.Flags().StringVar(&gcl.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file to use for accessing the management cluster. If unspecified, default discovery rules apply.")
+	getClustersCmd.Flags().StringVar(&gcl.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	getClustersCmd.Flags().StringVarP(&gcl.output, "output", "o", GetClustersOutputText,
+		fmt.Sprintf("Output format. Valid values: %v.", GetClustersOutputs))
+
+	getCmd.AddCommand(getClustersCmd)
+}
+
+func runGetClusters(out io.Writer) error {
+	if gcl.output != GetClustersOutputText && gcl.output != GetClustersOutputWide && gcl.output != GetClustersOutputJSON {
+		return errors.Errorf("invalid output format %q, valid values: %v", gcl.output, GetClustersOutputs)
+	}
+
+	ctx := context.Background()
+
+	c, err := client.New(ctx, cfgFile)
+	if err != nil {
+		return err
+	}
+
+	clusters, err := c.GetClusters(ctx, client.GetClustersOptions{
+		Kubeconfig: client.Kubeconfig{Path: gcl.kubeconfig, Context: gcl.kubeconfigContext},
+		Namespace:  gcl.namespace,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printClusters(out, clusters, gcl.output, time.Now())
+}
+
+// printClusters prints the summary of the clusters in a table or in json format.
+func printClusters(out io.Writer, clusters []client.ClusterSummary, output string, now time.Time) error {
+	if output == GetClustersOutputJSON {
+		j, err := json.MarshalIndent(clusters, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(j))
+		return err
+	}
+
+	if len(clusters) == 0 {
+		fmt.Fprintln(out, "No clusters found.")
+		return nil
+	}
+
+	wide := output == GetClustersOutputWide
+	w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+	header := "NAMESPACE\tNAME\tCLUSTERCLASS\tPHASE\tVERSION\tCONTROL PLANE\tWORKERS\tCONDITION\tAGE"
+	if wide {
+		header += "\tCONTROL PLANE REF\tINFRASTRUCTURE REF\tMESSAGE"
+	}
+	fmt.Fprintln(w, header)
+
+	for _, cluster := range clusters {
+		condition := ""
+		message := ""
+		if cluster.FailingCondition != nil {
+			condition = string(cluster.FailingCondition.Type)
+			if cluster.FailingCondition.Reason != "" {
+				condition += " (" + cluster.FailingCondition.Reason + ")"
+			}
+			message = cluster.FailingCondition.Message
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d/%d\t%d/%d\t%s\t%s",
+			cluster.Namespace,
+			cluster.Name,
+			cluster.ClusterClass,
+			cluster.Phase,
+			cluster.KubernetesVersion,
+			cluster.ControlPlane.Ready, cluster.ControlPlane.Desired,
+			cluster.Workers.Ready, cluster.Workers.Desired,
+			condition,
+			duration.HumanDuration(now.Sub(cluster.CreationTimestamp.Time)),
+		)
+		if wide {
+			fmt.Fprintf(w, "\t%s\t%s\t%s", objectRefString(cluster.ControlPlaneRef), objectRefString(cluster.InfrastructureRef), message)
+		}
+		fmt.Fprintln(w)
+	}
+	return w.Flush()
+}
+
+// objectRefString returns a reference in the kind/name form.
+func objectRefString(ref *corev1.ObjectReference) string {
+	if ref == nil {
+		return ""
+	}
+	return ref.Kind + "/" + ref.Name
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+func Test_printClusters(t *testing.T) {
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	clusters := []client.ClusterSummary{
+		{
+			Namespace:         "ns1",
+			Name:              "cluster1",
+			CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Hour)),
+			Phase:             "Provisioned",
+			ClusterClass:      "quick-start",
+			KubernetesVersion: "v1.29.0",
+			ControlPlane:      client.ReplicasSummary{Desired: 3, Ready: 2},
+			Workers:           client.ReplicasSummary{Desired: 5, Ready: 3},
+			ControlPlaneRef:   &corev1.ObjectReference{Kind: "KubeadmControlPlane", Name: "cluster1-cp"},
+			FailingCondition: &clusterv1.Condition{
+				Type:    clusterv1.TopologyReconciledCondition,
+				Status:  corev1.ConditionFalse,
+				Reason:  "ReconcileFailed",
+				Message: "failed to reconcile",
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{
+			name:   "text",
+			output: GetClustersOutputText,
+			want: []string{
+				"NAMESPACE   NAME       CLUSTERCLASS   PHASE         VERSION   CONTROL PLANE   WORKERS   CONDITION                              AGE",
+				"ns1         cluster1   quick-start    Provisioned   v1.29.0   2/3             3/5       TopologyReconciled (ReconcileFailed)   120m",
+			},
+		},
+		{
+			name:   "wide",
+			output: GetClustersOutputWide,
+			want: []string{
+				"CONTROL PLANE REF                 INFRASTRUCTURE REF   MESSAGE",
+				"KubeadmControlPlane/cluster1-cp                        failed to reconcile",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			var out bytes.Buffer
+			g.Expect(printClusters(&out, clusters, tt.output, now)).To(Succeed())
+			for _, want := range tt.want {
+				g.Expect(out.String()).To(ContainSubstring(want))
+			}
+		})
+	}
+}
+
+func Test_printClustersJSON(t *testing.T) {
+	g := NewWithT(t)
+
+	clusters := []client.ClusterSummary{{Namespace: "ns1", Name: "cluster1", ControlPlane: client.ReplicasSummary{Desired: 1, Ready: 1}}}
+
+	var out bytes.Buffer
+	g.Expect(printClusters(&out, clusters, GetClustersOutputJSON, time.Now())).To(Succeed())
+
+	got := []client.ClusterSummary{}
+	g.Expect(json.Unmarshal(out.Bytes(), &got)).To(Succeed())
+	g.Expect(got).To(HaveLen(1))
+	g.Expect(got[0].Name).To(Equal("cluster1"))
+	g.Expect(got[0].ControlPlane).To(Equal(client.ReplicasSummary{Desired: 1, Ready: 1}))
+
+	out.Reset()
+	g.Expect(printClusters(&out, nil, GetClustersOutputText, time.Now())).To(Succeed())
+	g.Expect(strings.TrimSpace(out.String())).To(Equal("No clusters found."))
+}
@@ -19,18 +19,44 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+const (
+	// GetKubeconfigAuthCertificate is an option used to get a kubeconfig using the admin client certificate generated by Cluster API.
+	GetKubeconfigAuthCertificate = "certificate"
+	// GetKubeconfigAuthExec is an option used to get a kubeconfig using an exec credential plugin.
+	GetKubeconfigAuthExec = "exec"
+	// GetKubeconfigAuthOIDC is an option used to get a kubeconfig using the OIDC auth provider.
+	GetKubeconfigAuthOIDC = "oidc"
+)
+
+var (
+	// GetKubeconfigAuths is a list of valid get kubeconfig authentications.
+	GetKubeconfigAuths = []string{GetKubeconfigAuthCertificate, GetKubeconfigAuthExec, GetKubeconfigAuthOIDC}
 )
 
 type getKubeconfigOptions struct {
 	kubeconfig        string
 	kubeconfigContext string
 	namespace         string
+
+	auth            string
+	audience        string
+	tokenExpiry     time.Duration
+	execCommand     string
+	execArgs        []string
+	execEnv         []string
+	oidcIssuerURL   string
+	oidcExtraScopes []string
 }
 
 var gk = &getKubeconfigOptions{}
@@ -46,7 +72,13 @@ var getKubeconfigCmd = &cobra.Command{
 		clusterctl get kubeconfig <name of workload cluster>
 
 		# Get the workload cluster's kubeconfig in a particular namespace.
-		clusterctl get kubeconfig <name of workload cluster> --namespace foo`),
+		clusterctl get kubeconfig <name of workload cluster> --namespace foo
+
+		# Get the workload cluster's kubeconfig using an exec credential plugin instead of the admin client certificate.
+		clusterctl get kubeconfig <name of workload cluster> --auth exec --exec-command my-token-plugin --audience my-cluster --token-expiry 1h
+
+		# Get the workload cluster's kubeconfig using OIDC instead of the admin client certificate.
+		clusterctl get kubeconfig <name of workload cluster> --auth oidc --oidc-issuer-url https://issuer.example.com --audience kubernetes`),
 
 	Args: func(_ *cobra.Command, args []string) error {
 		if len(args) != 1 {
@@ -67,14 +99,32 @@ func init() {
 	getKubeconfigCmd.Flags().StringVar(&gk.kubeconfigContext, "kubeconfig-context", "",
 		"Context to be used within the kubeconfig file. If empty, current context will be used.")
 
+	// flags for the authentication
+	getKubeconfigCmd.Flags().StringVar(&gk.auth, "auth", GetKubeconfigAuthCertificate,
+		fmt.Sprintf("Authentication used by the kubeconfig. Valid values: %v. With exec or oidc, the admin client certificate is not included in the kubeconfig.", GetKubeconfigAuths))
+	getKubeconfigCmd.Flags().StringVar(&gk.audience, "audience", "",
+		"Audience of the tokens. With exec, it is passed to the plugin with the CLUSTERCTL_TOKEN_AUDIENCE environment variable; with oidc, it is the OIDC client ID.")
+	getKubeconfigCmd.Flags().DurationVar(&gk.tokenExpiry, "token-expiry", 0,
+		"Requested expiry of the tokens, passed to the exec credential plugin with the CLUSTERCTL_TOKEN_EXPIRY environment variable. Only valid with exec.")
+	getKubeconfigCmd.Flags().StringVar(&gk.execCommand, "exec-command", "",
+		"Command of the exec credential plugin. Only valid with exec.")
+	getKubeconfigCmd.Flags().StringArrayVar(&gk.execArgs, "exec-arg", nil,
+		"Argument to pass to the exec credential plugin; can be repeated. Only valid with exec.")
+	getKubeconfigCmd.Flags().StringArrayVar(&gk.execEnv, "exec-env", nil,
+		"Environment variable to pass to the exec credential plugin, in the form NAME=VALUE; can be repeated. Only valid with exec.")
+	getKubeconfigCmd.Flags().StringVar(&gk.oidcIssuerURL, "oidc-issuer-url", "",
+		"URL of the OIDC issuer. Only valid with oidc.")
+	getKubeconfigCmd.Flags().StringSliceVar(&gk.oidcExtraScopes, "oidc-extra-scopes", nil,
+		"Scopes to request in addition to the openid scope, e.g. email,groups. Only valid with oidc.")
+
 	// completions
-	getKubeconfigCmd.ValidArgsFunction = resourceNameCompletionFunc(
+	getKubeconfigCmd.ValidArgsFunction = singleArgCompletionFunc(resourceNameCompletionFunc(
 		getKubeconfigCmd.Flags().Lookup("kubeconfig"),
 		getKubeconfigCmd.Flags().Lookup("kubeconfig-context"),
 		getKubeconfigCmd.Flags().Lookup("namespace"),
 		clusterv1.GroupVersion.String(),
 		"cluster",
-	)
+	))
 
 	getCmd.AddCommand(getKubeconfigCmd)
 }
@@ -87,10 +137,16 @@ func runGetKubeconfig(workloadClusterName string) error {
 		return err
 	}
 
+	auth, err := gk.kubeconfigAuth()
+	if err != nil {
+		return err
+	}
+
 	options := client.GetKubeconfigOptions{
 		Kubeconfig:          client.Kubeconfig{Path: gk.kubeconfig, Context: gk.kubeconfigContext},
 		WorkloadClusterName: workloadClusterName,
 		Namespace:           gk.namespace,
+		Auth:                auth,
 	}
 
 	out, err := c.GetKubeconfig(ctx, options)
@@ -100,3 +156,51 @@ func runGetKubeconfig(workloadClusterName string) error {
 	fmt.Println(out)
 	return nil
 }
+
+// kubeconfigAuth returns the authentication for the kubeconfig, checking the flags are consistent with the selected authentication.
+func (o *getKubeconfigOptions) kubeconfigAuth() (cluster.KubeconfigAuth, error) {
+	execFlagsSet := o.execCommand != "" || len(o.execArgs) > 0 || len(o.execEnv) > 0 || o.tokenExpiry != 0
+	oidcFlagsSet := o.oidcIssuerURL != "" || len(o.oidcExtraScopes) > 0
+
+	switch o.auth {
+	case GetKubeconfigAuthCertificate:
+		if execFlagsSet || oidcFlagsSet || o.audience != "" {
+			return cluster.KubeconfigAuth{}, errors.Errorf("the exec and oidc flags can't be used with --auth %s", GetKubeconfigAuthCertificate)
+		}
+		return cluster.KubeconfigAuth{}, nil
+	case GetKubeconfigAuthExec:
+		if oidcFlagsSet {
+			return cluster.KubeconfigAuth{}, errors.Errorf("the oidc flags can't be used with --auth %s", GetKubeconfigAuthExec)
+		}
+		env := map[string]string{}
+		for _, e := range o.execEnv {
+			name, value, ok := strings.Cut(e, "=")
+			if !ok || name == "" {
+				return cluster.KubeconfigAuth{}, errors.Errorf("invalid exec environment variable %q, it must be in the form NAME=VALUE", e)
+			}
+			env[name] = value
+		}
+		return cluster.KubeconfigAuth{
+			Exec: &cluster.KubeconfigExecAuth{
+				Command:  o.execCommand,
+				Args:     o.execArgs,
+				Env:      env,
+				Audience: o.audience,
+				Expiry:   o.tokenExpiry,
+			},
+		}, nil
+	case GetKubeconfigAuthOIDC:
+		if execFlagsSet {
+			return cluster.KubeconfigAuth{}, errors.Errorf("the exec flags can't be used with --auth %s", GetKubeconfigAuthOIDC)
+		}
+		return cluster.KubeconfigAuth{
+			OIDC: &cluster.KubeconfigOIDCAuth{
+				IssuerURL:   o.oidcIssuerURL,
+				Audience:    o.audience,
+				ExtraScopes: o.oidcExtraScopes,
+			},
+		}, nil
+	default:
+		return cluster.KubeconfigAuth{}, errors.Errorf("invalid authentication %q, valid values: %v", o.auth, GetKubeconfigAuths)
+	}
+}
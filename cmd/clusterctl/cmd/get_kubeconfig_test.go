@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+func Test_getKubeconfigOptions_kubeconfigAuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		options getKubeconfigOptions
+		want    cluster.KubeconfigAuth
+		wantErr bool
+	}{
+		{
+			name:    "certificate",
+			options: getKubeconfigOptions{auth: GetKubeconfigAuthCertificate},
+			want:    cluster.KubeconfigAuth{},
+		},
+		{
+			name:    "certificate with exec flags",
+			options: getKubeconfigOptions{auth: GetKubeconfigAuthCertificate, execCommand: "get-token"},
+			wantErr: true,
+		},
+		{
+			name:    "exec",
+			options: getKubeconfigOptions{auth: GetKubeconfigAuthExec, execCommand: "get-token", execEnv: []string{"A=a=b"}, audience: "test"},
+			want: cluster.KubeconfigAuth{Exec: &cluster.KubeconfigExecAuth{
+				Command:  "get-token",
+				Env:      map[string]string{"A": "a=b"},
+				Audience: "test",
+			}},
+		},
+		{
+			name:    "exec with invalid environment variable",
+			options: getKubeconfigOptions{auth: GetKubeconfigAuthExec, execCommand: "get-token", execEnv: []string{"A"}},
+			wantErr: true,
+		},
+		{
+			name:    "oidc",
+			options: getKubeconfigOptions{auth: GetKubeconfigAuthOIDC, oidcIssuerURL: "https://issuer.example.com", audience: "kubernetes"},
+			want: cluster.KubeconfigAuth{OIDC: &cluster.KubeconfigOIDCAuth{
+				IssuerURL: "https://issuer.example.com",
+				Audience:  "kubernetes",
+			}},
+		},
+		{
+			name:    "oidc with exec flags",
+			options: getKubeconfigOptions{auth: GetKubeconfigAuthOIDC, oidcIssuerURL: "https://issuer.example.com", tokenExpiry: 1},
+			wantErr: true,
+		},
+		{
+			name:    "invalid authentication",
+			options: getKubeconfigOptions{auth: "token"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := tt.options.kubeconfigAuth()
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(BeComparableTo(tt.want))
+		})
+	}
+}
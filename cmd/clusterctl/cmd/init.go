@@ -18,8 +18,11 @@ package cmd
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
@@ -39,6 +42,11 @@ type initOptions struct {
 	validate                  bool
 	waitProviders             bool
 	waitProviderTimeout       int
+	waitCRDsTimeout           int
+	waitDeploymentsTimeout    int
+	waitWebhooksTimeout       int
+	externalCertificates      bool
+	externalCABundle          string
 }
 
 var initOpts = &initOptions{}
@@ -82,7 +90,11 @@ var initCmd = &cobra.Command{
 		clusterctl init --infrastructure=aws,vsphere
 
 		# Initialize a management cluster with a custom target namespace for the provider resources.
-		clusterctl init --infrastructure aws --target-namespace foo`),
+		clusterctl init --infrastructure aws --target-namespace foo
+
+		# Initialize a management cluster without installing cert-manager, using the webhook serving certificates
+		# in existing Secrets and the given CA bundle.
+		clusterctl init --infrastructure aws --external-certificates --external-ca-bundle ca.crt`),
 	Args: cobra.NoArgs,
 	RunE: func(*cobra.Command, []string) error {
 		return runInit()
@@ -108,14 +120,24 @@ func init() {
 		"Runtime extension providers and versions to add to the management cluster; please note that clusterctl doesn't include any default runtime extensions and thus it is required to use custom configuration files to register runtime extensions.")
 	initCmd.PersistentFlags().StringSliceVar(&initOpts.addonProviders, "addon", nil,
 		"Add-on providers and versions (e.g. helm:v0.1.0) to add to the management cluster.")
+	initCmd.PersistentFlags().BoolVar(&initOpts.externalCertificates, "external-certificates", false,
+		"Use the webhook serving certificates in existing Secrets, named as in the cert-manager Certificates of the providers, instead of installing cert-manager.")
 	initCmd.Flags().StringVarP(&initOpts.targetNamespace, "target-namespace", "n", "",
 		"The target namespace where the providers should be deployed. If unspecified, the provider components' default namespace is used.")
 	initCmd.Flags().BoolVar(&initOpts.waitProviders, "wait-providers", false,
 		"Wait for providers to be installed.")
 	initCmd.Flags().IntVar(&initOpts.waitProviderTimeout, "wait-provider-timeout", 5*60,
 		"Wait timeout per provider installation in seconds. This value is ignored if --wait-providers is false")
+	initCmd.Flags().IntVar(&initOpts.waitCRDsTimeout, "wait-crds-timeout", 0,
+		"Wait timeout in seconds for the CRDs of the providers to be established. If unspecified, --wait-provider-timeout is used.")
+	initCmd.Flags().IntVar(&initOpts.waitDeploymentsTimeout, "wait-deployments-timeout", 0,
+		"Wait timeout in seconds for the Deployments of the providers to be available. If unspecified, --wait-provider-timeout is used.")
+	initCmd.Flags().IntVar(&initOpts.waitWebhooksTimeout, "wait-webhooks-timeout", 0,
+		"Wait timeout in seconds for the webhooks of the providers to be reachable. If unspecified, --wait-provider-timeout is used.")
 	initCmd.Flags().BoolVar(&initOpts.validate, "validate", true,
 		"If true, clusterctl will validate that the deployments will succeed on the management cluster.")
+	initCmd.Flags().StringVar(&initOpts.externalCABundle, "external-ca-bundle", "",
+		"Path to the PEM encoded CA bundle of the webhook serving certificates. If unspecified, the ca.crt key of the Secrets is used. This value is ignored if --external-certificates is false")
 
 	initCmd.AddCommand(initListImagesCmd)
 	RootCmd.AddCommand(initCmd)
@@ -129,6 +151,11 @@ func runInit() error {
 		return err
 	}
 
+	caBundle, err := readExternalCABundle(initOpts.externalCertificates, initOpts.externalCABundle)
+	if err != nil {
+		return err
+	}
+
 	options := client.InitOptions{
 		Kubeconfig:                client.Kubeconfig{Path: initOpts.kubeconfig, Context: initOpts.kubeconfigContext},
 		CoreProvider:              initOpts.coreProvider,
@@ -142,7 +169,12 @@ func runInit() error {
 		LogUsageInstructions:      true,
 		WaitProviders:             initOpts.waitProviders,
 		WaitProviderTimeout:       time.Duration(initOpts.waitProviderTimeout) * time.Second,
+		WaitCRDsTimeout:           time.Duration(initOpts.waitCRDsTimeout) * time.Second,
+		WaitDeploymentsTimeout:    time.Duration(initOpts.waitDeploymentsTimeout) * time.Second,
+		WaitWebhooksTimeout:       time.Duration(initOpts.waitWebhooksTimeout) * time.Second,
 		IgnoreValidationErrors:    !initOpts.validate,
+		ExternalCertificates:      initOpts.externalCertificates,
+		ExternalCABundle:          caBundle,
 	}
 
 	if _, err := c.Init(ctx, options); err != nil {
@@ -150,3 +182,15 @@ func runInit() error {
 	}
 	return nil
 }
+
+// readExternalCABundle reads the CA bundle of the webhook serving certificates, if using external certificates.
+func readExternalCABundle(externalCertificates bool, path string) ([]byte, error) {
+	if !externalCertificates || path == "" {
+		return nil, nil
+	}
+	caBundle, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the CA bundle %s", path)
+	}
+	return caBundle, nil
+}
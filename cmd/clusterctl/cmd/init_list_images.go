@@ -66,6 +66,7 @@ func runInitListImages() error {
 		RuntimeExtensionProviders: initOpts.runtimeExtensionProviders,
 		AddonProviders:            initOpts.addonProviders,
 		LogUsageInstructions:      false,
+		ExternalCertificates:      initOpts.externalCertificates,
 	}
 
 	images, err := c.InitImages(ctx, options)
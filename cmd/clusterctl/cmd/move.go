@@ -17,12 +17,16 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"os"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 )
 
 type moveOptions struct {
@@ -31,8 +35,14 @@ type moveOptions struct {
 	toKubeconfig          string
 	toKubeconfigContext   string
 	namespace             string
+	cluster               string
+	selector              string
+	namespaceMapping      map[string]string
 	fromDirectory         string
 	toDirectory           string
+	encryptionKeyFile     string
+	verify                bool
+	checkpointFile        string
 	dryRun                bool
 }
 
@@ -56,6 +66,25 @@ var moveCmd = &cobra.Command{
 
 		Read Cluster API objects and all dependencies from a directory into a management cluster.
 		clusterctl move --from-directory /tmp/backup-directory
+
+		Write an encrypted backup to a directory, and then verify it before reading it into a management cluster.
+		clusterctl move --to-directory /tmp/backup-directory --encryption-key-file backup.key
+		clusterctl move --from-directory /tmp/backup-directory --encryption-key-file backup.key --verify
+
+		Move only the Clusters with the given labels, and all their dependencies, leaving the other Clusters in the namespace untouched.
+		clusterctl move --selector tenant=foo --to-kubeconfig=target-kubeconfig.yaml
+
+		Move Cluster API objects from the tenant-a namespace to the prod-tenant-a namespace of the destination management cluster.
+		clusterctl move -n tenant-a --namespace-mapping tenant-a=prod-tenant-a --to-kubeconfig=target-kubeconfig.yaml
+
+		Check what a move would do, and whether anything is blocking it, without performing any real action.
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml --dry-run
+
+		Hand over a single Cluster and all its dependencies to another management cluster, pausing it only for the final sync.
+		clusterctl move --cluster my-cluster --to-kubeconfig=target-kubeconfig.yaml
+
+		Record the progress of the move in a checkpoint file; if the move gets interrupted, run the same command again to resume it.
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml --checkpoint-file move-checkpoint.json
 	`),
 	Args: cobra.NoArgs,
 	RunE: func(*cobra.Command, []string) error {
@@ -74,16 +103,38 @@ func init() {
 		"Context to be used within the kubeconfig file for the destination management cluster. If empty, current context will be used.")
 	moveCmd.Flags().StringVarP(&mo.namespace, "namespace", "n", "",
 		"The namespace where the workload cluster is hosted. If unspecified, the current context's namespace is used.")
+	moveCmd.Flags().StringVar(&mo.cluster, "cluster", "",
+		"The name of a single Cluster to hand over to the destination management cluster. If unspecified, all the Clusters in the namespace are moved.")
+	moveCmd.Flags().StringVarP(&mo.selector, "selector", "l", "",
+		"Label selector for the Clusters to move to the destination management cluster, e.g. tenant=foo. If unspecified, all the Clusters in the namespace are moved.")
+	moveCmd.Flags().StringToStringVar(&mo.namespaceMapping, "namespace-mapping", nil,
+		"Namespaces to remap during the move, in the form source=target, e.g. --namespace-mapping tenant-a=prod-tenant-a; objects in the source namespace are moved to the target namespace, together with their object references.")
+	moveCmd.Flags().StringVar(&mo.checkpointFile, "checkpoint-file", "",
+		"Path to a file recording the progress of the move. If the file exists, the interrupted move is resumed without creating again the objects already moved; the file is removed when the move completes.")
 	moveCmd.Flags().BoolVar(&mo.dryRun, "dry-run", false,
-		"Enable dry run, don't really perform the move actions")
+		"Enable dry run, don't really perform the move actions; report the objects that would be paused, moved and mutated, and fail if any blocker is detected. If --to-kubeconfig is set, the destination management cluster is checked as well.")
 	moveCmd.Flags().StringVar(&mo.toDirectory, "to-directory", "",
 		"Write Cluster API objects and all dependencies from a management cluster to directory.")
 	moveCmd.Flags().StringVar(&mo.fromDirectory, "from-directory", "",
 		"Read Cluster API objects and all dependencies from a directory into a management cluster.")
+	moveCmd.Flags().StringVar(&mo.encryptionKeyFile, "encryption-key-file", "",
+		"Path to a file with the 32 bytes key, raw or base64 encoded, used for encrypting Secrets when using --to-directory and for decrypting them when using --from-directory.")
+	moveCmd.Flags().BoolVar(&mo.verify, "verify", false,
+		"Verify the backup in the directory against its manifest before reading any object when using --from-directory.")
 
 	moveCmd.MarkFlagsMutuallyExclusive("to-directory", "to-kubeconfig")
 	moveCmd.MarkFlagsMutuallyExclusive("from-directory", "to-directory")
 	moveCmd.MarkFlagsMutuallyExclusive("from-directory", "kubeconfig")
+	moveCmd.MarkFlagsMutuallyExclusive("cluster", "to-directory")
+	moveCmd.MarkFlagsMutuallyExclusive("cluster", "from-directory")
+	moveCmd.MarkFlagsMutuallyExclusive("selector", "cluster")
+	moveCmd.MarkFlagsMutuallyExclusive("selector", "to-directory")
+	moveCmd.MarkFlagsMutuallyExclusive("selector", "from-directory")
+	moveCmd.MarkFlagsMutuallyExclusive("verify", "to-directory")
+	moveCmd.MarkFlagsMutuallyExclusive("checkpoint-file", "cluster")
+	moveCmd.MarkFlagsMutuallyExclusive("checkpoint-file", "dry-run")
+	moveCmd.MarkFlagsMutuallyExclusive("checkpoint-file", "to-directory")
+	moveCmd.MarkFlagsMutuallyExclusive("checkpoint-file", "from-directory")
 
 	RootCmd.AddCommand(moveCmd)
 }
@@ -98,17 +149,53 @@ func runMove() error {
 		return errors.New("please specify a target cluster using the --to-kubeconfig flag when not using --dry-run, --to-directory or --from-directory")
 	}
 
+	if mo.verify && mo.fromDirectory == "" {
+		return errors.New("please specify a directory using the --from-directory flag when using --verify")
+	}
+
+	var encryptionKey []byte
+	if mo.encryptionKeyFile != "" {
+		if mo.toDirectory == "" && mo.fromDirectory == "" {
+			return errors.New("please specify a directory using the --to-directory or the --from-directory flag when using --encryption-key-file")
+		}
+		var err error
+		if encryptionKey, err = readEncryptionKey(mo.encryptionKeyFile); err != nil {
+			return err
+		}
+	}
+
 	c, err := client.New(ctx, cfgFile)
 	if err != nil {
 		return err
 	}
 
 	return c.Move(ctx, client.MoveOptions{
-		FromKubeconfig: client.Kubeconfig{Path: mo.fromKubeconfig, Context: mo.fromKubeconfigContext},
-		ToKubeconfig:   client.Kubeconfig{Path: mo.toKubeconfig, Context: mo.toKubeconfigContext},
-		FromDirectory:  mo.fromDirectory,
-		ToDirectory:    mo.toDirectory,
-		Namespace:      mo.namespace,
-		DryRun:         mo.dryRun,
+		FromKubeconfig:      client.Kubeconfig{Path: mo.fromKubeconfig, Context: mo.fromKubeconfigContext},
+		ToKubeconfig:        client.Kubeconfig{Path: mo.toKubeconfig, Context: mo.toKubeconfigContext},
+		FromDirectory:       mo.fromDirectory,
+		ToDirectory:         mo.toDirectory,
+		Namespace:           mo.namespace,
+		Cluster:             mo.cluster,
+		Selector:            mo.selector,
+		NamespaceMapping:    mo.namespaceMapping,
+		BackupEncryptionKey: encryptionKey,
+		VerifyBackup:        mo.verify,
+		CheckpointFile:      mo.checkpointFile,
+		DryRun:              mo.dryRun,
 	})
 }
+
+// readEncryptionKey reads the key for encrypting backups from a file, either as raw bytes or base64 encoded.
+func readEncryptionKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read encryption key file %s", path)
+	}
+	if key, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data))); err == nil && len(key) == cluster.BackupEncryptionKeySize {
+		return key, nil
+	}
+	if len(data) == cluster.BackupEncryptionKeySize {
+		return data, nil
+	}
+	return nil, errors.Errorf("invalid encryption key file %s: expected %d bytes, raw or base64 encoded", path, cluster.BackupEncryptionKeySize)
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const pluginPrefix = "clusterctl-"
+
+var pluginCmd = &cobra.Command{
+	Use:     "plugin",
+	GroupID: groupOther,
+	Short:   "Provides utilities for interacting with plugins",
+	Long: LongDesc(`
+		Provides utilities for interacting with plugins.
+
+		Plugins are executables named clusterctl-{command} available in the PATH, providing
+		the clusterctl {command} command; e.g. the clusterctl-foo executable provides the clusterctl foo command.`),
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all the plugins available in the PATH",
+	Long: LongDesc(`
+		List all the plugins available in the PATH, i.e. all the executables named clusterctl-{command}.
+
+		Warnings are printed for plugins shadowed by other plugins with the same name earlier in the PATH,
+		and for plugins shadowed by clusterctl commands.`),
+
+	Example: Examples(`
+		# List all the plugins available in the PATH.
+		clusterctl plugin list`),
+
+	Args: cobra.NoArgs,
+	RunE: func(*cobra.Command, []string) error {
+		return runPluginList(os.Stdout, os.Stderr, filepath.SplitList(os.Getenv("PATH")))
+	},
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginListCmd)
+	RootCmd.AddCommand(pluginCmd)
+}
+
+// pluginInfo describes a plugin found in the PATH.
+type pluginInfo struct {
+	// Path is the full path of the plugin executable.
+	Path string
+	// Warnings are the reasons why the plugin can't be invoked, if any.
+	Warnings []string
+}
+
+func runPluginList(out, errOut io.Writer, paths []string) error {
+	plugins := listPlugins(paths)
+	if len(plugins) == 0 {
+		return errors.New("unable to find any clusterctl plugins in your PATH")
+	}
+
+	fmt.Fprintln(out, "The following clusterctl-compatible plugins are available:")
+	fmt.Fprintln(out, "")
+	warnings := 0
+	for _, p := range plugins {
+		fmt.Fprintln(out, p.Path)
+		for _, w := range p.Warnings {
+			fmt.Fprintf(errOut, "  - warning: %s\n", w)
+			warnings++
+		}
+	}
+	if warnings > 0 {
+		fmt.Fprintf(errOut, "\nfound %d plugin warning(s)\n", warnings)
+	}
+	return nil
+}
+
+// listPlugins returns the plugins found in the given paths, in the same order used for invoking plugins.
+func listPlugins(paths []string) []pluginInfo {
+	plugins := []pluginInfo{}
+	found := map[string]string{}
+	for _, dir := range paths {
+		if strings.TrimSpace(dir) == "" {
+			continue
+		}
+
+		// Skip directories that can't be read, like kubectl plugin list does.
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasPrefix(entry.Name(), pluginPrefix) {
+				continue
+			}
+
+			p := pluginInfo{Path: filepath.Join(dir, entry.Name())}
+			name := pluginName(entry.Name())
+
+			if !isExecutable(p.Path) {
+				p.Warnings = append(p.Warnings, fmt.Sprintf("%s identified as a clusterctl plugin, but it is not executable", p.Path))
+			}
+			if other, ok := found[name]; ok {
+				p.Warnings = append(p.Warnings, fmt.Sprintf("%s is overshadowed by a similarly named plugin: %s", p.Path, other))
+			} else {
+				found[name] = p.Path
+			}
+			if cmd, _, err := RootCmd.Find(strings.Split(name, "-")); err == nil && cmd != RootCmd {
+				p.Warnings = append(p.Warnings, fmt.Sprintf("%s overwrites existing command: %q", p.Path, cmd.CommandPath()))
+			}
+			plugins = append(plugins, p)
+		}
+	}
+	return plugins
+}
+
+// pluginName returns the command path implemented by a plugin executable, e.g. foo-bar for clusterctl-foo-bar.
+func pluginName(filename string) string {
+	name := strings.TrimPrefix(filename, pluginPrefix)
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return name
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".bat", ".cmd", ".com", ".exe", ".ps1":
+			return true
+		}
+		return false
+	}
+	return info.Mode()&0111 != 0
+}
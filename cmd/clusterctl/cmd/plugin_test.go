@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_listPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are identified using the file extension on windows")
+	}
+
+	g := NewWithT(t)
+
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	writePlugin := func(dir, name string, mode os.FileMode) string {
+		path := filepath.Join(dir, name)
+		g.Expect(os.WriteFile(path, []byte("#!/bin/sh\n"), mode)).To(Succeed())
+		return path
+	}
+
+	foo := writePlugin(dir1, "clusterctl-foo", 0700)
+	notExecutable := writePlugin(dir1, "clusterctl-bar", 0600)
+	_ = writePlugin(dir1, "kubectl-foo", 0700)
+	shadowed := writePlugin(dir2, "clusterctl-foo", 0700)
+	overwrite := writePlugin(dir2, "clusterctl-version", 0700)
+
+	plugins := listPlugins([]string{dir1, "", filepath.Join(dir1, "does-not-exist"), dir2})
+	g.Expect(plugins).To(ConsistOf(
+		pluginInfo{Path: notExecutable, Warnings: []string{notExecutable + " identified as a clusterctl plugin, but it is not executable"}},
+		pluginInfo{Path: foo},
+		pluginInfo{Path: shadowed, Warnings: []string{shadowed + " is overshadowed by a similarly named plugin: " + foo}},
+		pluginInfo{Path: overwrite, Warnings: []string{overwrite + " overwrites existing command: \"clusterctl version\""}},
+	))
+}
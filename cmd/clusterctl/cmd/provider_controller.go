@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/controllers"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/scheme"
+)
+
+type providerControllerOptions struct {
+	kubeconfig          string
+	kubeconfigContext   string
+	metricsBindAddr     string
+	concurrency         int
+	allowedRepositories []string
+}
+
+var pc = &providerControllerOptions{}
+
+var providerControllerCmd = &cobra.Command{
+	Use:   "provider-controller",
+	Short: "Run a controller reconciling ProviderInstallation objects in the management cluster",
+	Long: LongDesc(`
+		Run a controller reconciling ProviderInstallation objects in the management cluster.
+
+		Each ProviderInstallation defines the desired version of a provider; the controller installs, upgrades and
+		deletes providers using the same logic of clusterctl init, clusterctl upgrade apply and clusterctl delete.
+
+		The controller can run locally or inside the management cluster; in the latter case the in-cluster
+		configuration is used if no kubeconfig is provided.`),
+
+	Example: Examples(`
+		# Run the provider controller against the management cluster of the current kubeconfig context.
+		clusterctl alpha provider-controller
+
+		# Run the provider controller against a specific management cluster.
+		clusterctl alpha provider-controller --kubeconfig=management.kubeconfig
+
+		# Run the provider controller allowing ProviderInstallations to use the repositories of an organization.
+		clusterctl alpha provider-controller --allowed-repositories=https://github.com/my-org/`),
+	Args: cobra.NoArgs,
+	RunE: func(*cobra.Command, []string) error {
+		return runProviderController()
+	},
+}
+
+func init() {
+	providerControllerCmd.Flags().StringVar(&pc.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig for the management cluster. If unspecified, default discovery rules apply, falling back to the in-cluster configuration.")
+	providerControllerCmd.Flags().StringVar(&pc.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	providerControllerCmd.Flags().StringVar(&pc.metricsBindAddr, "metrics-bind-addr", "0",
+		"The address the metrics endpoint binds to. Use 0 to disable the metrics endpoint.")
+	providerControllerCmd.Flags().IntVar(&pc.concurrency, "concurrency", 1,
+		"Number of ProviderInstallations to process simultaneously.")
+	providerControllerCmd.Flags().StringSliceVar(&pc.allowedRepositories, "allowed-repositories", nil,
+		"Comma-separated list of URL prefixes of the repositories ProviderInstallations can set in spec.url. If empty, only the repositories configured by default in clusterctl can be used.")
+
+	alphaCmd.AddCommand(providerControllerCmd)
+}
+
+func runProviderController() error {
+	ctx := ctrl.SetupSignalHandler()
+
+	kubeconfig := client.Kubeconfig{Path: pc.kubeconfig, Context: pc.kubeconfigContext}
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = pc.kubeconfig
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		loadingRules,
+		&clientcmd.ConfigOverrides{CurrentContext: pc.kubeconfigContext},
+	).ClientConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get the configuration for the management cluster")
+	}
+
+	// The clusterctl library requires a kubeconfig file, so one is generated from the in-cluster configuration when required.
+	if pc.kubeconfig == "" && os.Getenv(clientcmd.RecommendedConfigPathEnvVar) == "" {
+		if _, err := os.Stat(clientcmd.RecommendedHomeFile); os.IsNotExist(err) {
+			path, err := writeInClusterKubeconfig(restConfig)
+			if err != nil {
+				return err
+			}
+			defer os.Remove(path)
+			kubeconfig = client.Kubeconfig{Path: path}
+		}
+	}
+
+	// Ensure the clusterctl CRDs, including the ProviderInstallation CRD, are installed.
+	reader := config.NewMemoryReader()
+	if err := reader.Init(ctx, ""); err != nil {
+		return err
+	}
+	configClient, err := config.New(ctx, "", config.InjectReader(reader))
+	if err != nil {
+		return err
+	}
+	if err := cluster.New(cluster.Kubeconfig(kubeconfig), configClient).ProviderInventory().EnsureCustomResourceDefinitions(ctx); err != nil {
+		return err
+	}
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
+		Scheme:  scheme.Scheme,
+		Metrics: metricsserver.Options{BindAddress: pc.metricsBindAddr},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create the controller manager")
+	}
+
+	if err := (&controllers.ProviderInstallationReconciler{
+		Client:              mgr.GetClient(),
+		Kubeconfig:          kubeconfig,
+		AllowedRepositories: pc.allowedRepositories,
+	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: pc.concurrency}); err != nil {
+		return err
+	}
+
+	return mgr.Start(ctx)
+}
+
+// writeInClusterKubeconfig writes a kubeconfig file for the in-cluster configuration and returns its path.
+func writeInClusterKubeconfig(restConfig *rest.Config) (string, error) {
+	f, err := os.CreateTemp("", "clusterctl-kubeconfig-*")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create kubeconfig file")
+	}
+	if err := f.Close(); err != nil {
+		return "", errors.Wrap(err, "failed to create kubeconfig file")
+	}
+
+	kubeconfig := clientcmdapi.Config{
+		Clusters: map[string]*clientcmdapi.Cluster{
+			"in-cluster": {
+				Server:                   restConfig.Host,
+				CertificateAuthority:     restConfig.TLSClientConfig.CAFile,
+				CertificateAuthorityData: restConfig.TLSClientConfig.CAData,
+			},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			"in-cluster": {
+				Token:     restConfig.BearerToken,
+				TokenFile: restConfig.BearerTokenFile,
+			},
+		},
+		Contexts: map[string]*clientcmdapi.Context{
+			"in-cluster": {Cluster: "in-cluster", AuthInfo: "in-cluster"},
+		},
+		CurrentContext: "in-cluster",
+	}
+	if err := clientcmd.WriteToFile(kubeconfig, f.Name()); err != nil {
+		return "", errors.Wrap(err, "failed to write kubeconfig file")
+	}
+	return f.Name(), nil
+}
@@ -38,14 +38,22 @@ var undoOpt = &undoOptions{}
 
 var (
 	undoLong = templates.LongDesc(`
-		Rollback to a previous rollout.`)
+		Rollback to a previous rollout.
+
+		MachineDeployments are rolled back to the template of a previous MachineSet revision.
+		KubeadmControlPlanes do not keep a revision history, so they are rolled back to the version and
+		infrastructure machine template of the most recent control plane Machines not matching the current spec;
+		this is possible only while those Machines still exist, e.g. while a rollout is in progress.`)
 
 	undoExample = templates.Examples(`
 		# Rollback to the previous deployment
 		clusterctl alpha rollout undo machinedeployment/my-md-0
 
 		# Rollback to previous machinedeployment --to-revision=3
-		clusterctl alpha rollout undo machinedeployment/my-md-0 --to-revision=3`)
+		clusterctl alpha rollout undo machinedeployment/my-md-0 --to-revision=3
+
+		# Rollback a kubeadmcontrolplane to the previous version and infrastructure machine template
+		clusterctl alpha rollout undo kubeadmcontrolplane/my-kcp`)
 )
 
 // NewCmdRolloutUndo returns a Command instance for 'rollout undo' sub command.
@@ -65,7 +73,7 @@ func NewCmdRolloutUndo(cfgFile string) *cobra.Command {
 	cmd.Flags().StringVar(&undoOpt.kubeconfigContext, "kubeconfig-context", "",
 		"Context to be used within the kubeconfig file. If empty, current context will be used.")
 	cmd.Flags().StringVarP(&undoOpt.namespace, "namespace", "n", "", "Namespace where the resource(s) reside. If unspecified, the defult namespace will be used.")
-	cmd.Flags().Int64Var(&undoOpt.toRevision, "to-revision", undoOpt.toRevision, "The revision to rollback to. Default to 0 (last revision).")
+	cmd.Flags().Int64Var(&undoOpt.toRevision, "to-revision", undoOpt.toRevision, "The revision to rollback to. Default to 0 (last revision). Not supported for KubeadmControlPlanes.")
 
 	return cmd
 }
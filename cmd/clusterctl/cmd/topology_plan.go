@@ -47,6 +47,7 @@ type topologyPlanOptions struct {
 	cluster           string
 	namespace         string
 	outDir            string
+	serverSideDryRun  bool
 }
 
 var tp = &topologyPlanOptions{}
@@ -63,6 +64,11 @@ var topologyPlanCmd = &cobra.Command{
 
 		Note: Among all the objects in the input defaulting and validation will be performed only for Cluster
 		and ClusterClasses. All other objects in the input are expected to be valid and have default values.
+
+		When using --server-side-dry-run, the input objects and the changes computed by the topology reconciler are
+		sent to the management cluster as server-side dry-run requests; this runs defaulting and validation webhooks
+		of all the providers, and calls the Runtime Extensions registered in the management cluster for computing
+		external patches. No change is persisted in the management cluster, and lifecycle hooks are not called.
 	`),
 	Example: Examples(`
 		# List all the objects that will be created and modified when creating a new cluster.
@@ -82,6 +88,9 @@ var topologyPlanCmd = &cobra.Command{
 
 		# List the clusters and ClusterClasses impacted by a template change.
 		clusterctl alpha topology plan -f modified-template.yaml -o output/
+
+		# List the changes when modifying a cluster, validating them against the management cluster.
+		clusterctl alpha topology plan -f modified-cluster.yaml --server-side-dry-run -o output/
 	`),
 	Args: cobra.NoArgs,
 	RunE: func(*cobra.Command, []string) error {
@@ -90,15 +99,16 @@ var topologyPlanCmd = &cobra.Command{
 }
 
 func init() {
-	topologyPlanCmd.Flags().StringVar(&initOpts.kubeconfig, "kubeconfig", "",
+	topologyPlanCmd.Flags().StringVar(&tp.kubeconfig, "kubeconfig", "",
 		"Path to the kubeconfig for the management cluster. If unspecified, default discovery rules apply.")
-	topologyPlanCmd.Flags().StringVar(&initOpts.kubeconfigContext, "kubeconfig-context", "",
+	topologyPlanCmd.Flags().StringVar(&tp.kubeconfigContext, "kubeconfig-context", "",
 		"Context to be used within the kubeconfig file. If empty, current context will be used.")
 
 	topologyPlanCmd.Flags().StringArrayVarP(&tp.files, "file", "f", nil, "path to the file with new or modified resources to be applied; the file should not contain more than one Cluster or more than one ClusterClass")
 	topologyPlanCmd.Flags().StringVarP(&tp.cluster, "cluster", "c", "", "name of the target cluster; this parameter is required when more than one cluster is affected")
 	topologyPlanCmd.Flags().StringVarP(&tp.namespace, "namespace", "n", "", "target namespace for the operation. If specified, it is used as default namespace for objects with missing namespace")
 	topologyPlanCmd.Flags().StringVarP(&tp.outDir, "output-directory", "o", "", "output directory to write details about created/modified objects")
+	topologyPlanCmd.Flags().BoolVar(&tp.serverSideDryRun, "server-side-dry-run", false, "send the input objects and the computed changes to the management cluster as server-side dry-run requests, and call the Runtime Extensions registered in the management cluster")
 
 	if err := topologyPlanCmd.MarkFlagRequired("file"); err != nil {
 		panic(err)
@@ -134,10 +144,11 @@ func runTopologyPlan() error {
 	}
 
 	out, err := c.TopologyPlan(ctx, client.TopologyPlanOptions{
-		Kubeconfig: client.Kubeconfig{Path: tp.kubeconfig, Context: tp.kubeconfigContext},
-		Objs:       convertToPtrSlice(objs),
-		Cluster:    tp.cluster,
-		Namespace:  tp.namespace,
+		Kubeconfig:       client.Kubeconfig{Path: tp.kubeconfig, Context: tp.kubeconfigContext},
+		Objs:             convertToPtrSlice(objs),
+		Cluster:          tp.cluster,
+		Namespace:        tp.namespace,
+		ServerSideDryRun: tp.serverSideDryRun,
 	})
 	if err != nil {
 		return err
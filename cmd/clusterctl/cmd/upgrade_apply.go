@@ -18,12 +18,18 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 )
 
 type upgradeApplyOptions struct {
@@ -39,6 +45,13 @@ type upgradeApplyOptions struct {
 	addonProviders            []string
 	waitProviders             bool
 	waitProviderTimeout       int
+	waitCRDsTimeout           int
+	waitDeploymentsTimeout    int
+	waitWebhooksTimeout       int
+	migrateStorageVersions    bool
+	dryRun                    bool
+	externalCertificates      bool
+	externalCABundle          string
 }
 
 var ua = &upgradeApplyOptions{}
@@ -52,6 +65,9 @@ var upgradeApplyCmd = &cobra.Command{
 		New version should be applied ensuring all the providers uses the same cluster API version
 		in order to guarantee the proper functioning of the management cluster.
 
+		Use --dry-run to review the changes to images, CRDs, RBAC and webhook configurations of each
+		provider before upgrading the management cluster.
+
  		Specifying the provider using namespace/name:version is deprecated and will be dropped in a future release.`),
 
 	Example: Examples(`
@@ -60,7 +76,18 @@ var upgradeApplyCmd = &cobra.Command{
 		clusterctl upgrade apply --contract v1alpha4
 
 		# Upgrades only the aws provider to the v2.0.1 version.
-		clusterctl upgrade apply --infrastructure aws:v2.0.1`),
+		clusterctl upgrade apply --infrastructure aws:v2.0.1
+
+		# Upgrades all the providers and migrates their objects to the storage version of the new CRDs.
+		clusterctl upgrade apply --contract v1beta1 --migrate-storage-versions
+
+		# Shows the changes to images, CRDs, RBAC and webhook configurations of each provider,
+		# without upgrading the management cluster.
+		clusterctl upgrade apply --contract v1beta1 --dry-run
+
+		# Upgrades all the providers without upgrading cert-manager, using the webhook serving certificates
+		# in existing Secrets and the given CA bundle.
+		clusterctl upgrade apply --contract v1beta1 --external-certificates --external-ca-bundle ca.crt`),
 	Args: cobra.NoArgs,
 	RunE: func(*cobra.Command, []string) error {
 		return runUpgradeApply()
@@ -92,7 +119,24 @@ func init() {
 	upgradeApplyCmd.Flags().BoolVar(&ua.waitProviders, "wait-providers", false,
 		"Wait for providers to be upgraded.")
 	upgradeApplyCmd.Flags().IntVar(&ua.waitProviderTimeout, "wait-provider-timeout", 5*60,
-		"Wait timeout per provider upgrade in seconds. This value is ignored if --wait-providers is false")
+		"Wait timeout per provider upgrade in seconds. This value is ignored if --wait-providers and --migrate-storage-versions are false")
+	upgradeApplyCmd.Flags().IntVar(&ua.waitCRDsTimeout, "wait-crds-timeout", 0,
+		"Wait timeout in seconds for the CRDs of the providers to be established. If unspecified, --wait-provider-timeout is used.")
+	upgradeApplyCmd.Flags().IntVar(&ua.waitDeploymentsTimeout, "wait-deployments-timeout", 0,
+		"Wait timeout in seconds for the Deployments of the providers to be available. If unspecified, --wait-provider-timeout is used.")
+	upgradeApplyCmd.Flags().IntVar(&ua.waitWebhooksTimeout, "wait-webhooks-timeout", 0,
+		"Wait timeout in seconds for the webhooks of the providers to be reachable. If unspecified, --wait-provider-timeout is used.")
+	upgradeApplyCmd.Flags().BoolVar(&ua.migrateStorageVersions, "migrate-storage-versions", false,
+		"Migrate the objects of the upgraded providers to the storage version of their CRDs. This implies waiting for providers to be upgraded.")
+	upgradeApplyCmd.Flags().BoolVar(&ua.dryRun, "dry-run", false,
+		"Show the changes to images, CRDs, RBAC and webhook configurations of each provider, without upgrading the management cluster.")
+	upgradeApplyCmd.Flags().BoolVar(&ua.externalCertificates, "external-certificates", false,
+		"Use the webhook serving certificates in existing Secrets, named as in the cert-manager Certificates of the providers, instead of upgrading cert-manager.")
+	upgradeApplyCmd.Flags().StringVar(&ua.externalCABundle, "external-ca-bundle", "",
+		"Path to the PEM encoded CA bundle of the webhook serving certificates. If unspecified, the ca.crt key of the Secrets is used. This value is ignored if --external-certificates is false")
+
+	// completions
+	registerProviderFlagsCompletion(upgradeApplyCmd, true)
 }
 
 func runUpgradeApply() error {
@@ -118,7 +162,12 @@ func runUpgradeApply() error {
 		return errors.New("The --contract flag can't be used in combination with --core, --bootstrap, --control-plane, --infrastructure, --ipam, --extension, --addon")
 	}
 
-	return c.ApplyUpgrade(ctx, client.ApplyUpgradeOptions{
+	caBundle, err := readExternalCABundle(ua.externalCertificates, ua.externalCABundle)
+	if err != nil {
+		return err
+	}
+
+	options := client.ApplyUpgradeOptions{
 		Kubeconfig:                client.Kubeconfig{Path: ua.kubeconfig, Context: ua.kubeconfigContext},
 		Contract:                  ua.contract,
 		CoreProvider:              ua.coreProvider,
@@ -130,5 +179,106 @@ func runUpgradeApply() error {
 		AddonProviders:            ua.addonProviders,
 		WaitProviders:             ua.waitProviders,
 		WaitProviderTimeout:       time.Duration(ua.waitProviderTimeout) * time.Second,
-	})
+		WaitCRDsTimeout:           time.Duration(ua.waitCRDsTimeout) * time.Second,
+		WaitDeploymentsTimeout:    time.Duration(ua.waitDeploymentsTimeout) * time.Second,
+		WaitWebhooksTimeout:       time.Duration(ua.waitWebhooksTimeout) * time.Second,
+		MigrateStorageVersions:    ua.migrateStorageVersions,
+		ExternalCertificates:      ua.externalCertificates,
+		ExternalCABundle:          caBundle,
+	}
+
+	if ua.dryRun {
+		diffs, err := c.DiffUpgrade(ctx, options)
+		if err != nil {
+			return err
+		}
+		return printUpgradeDiffs(os.Stdout, diffs)
+	}
+
+	return c.ApplyUpgrade(ctx, options)
+}
+
+// printUpgradeDiffs prints the changes that the upgrade applies to each provider.
+func printUpgradeDiffs(out io.Writer, diffs []client.ProviderUpgradeDiff) error {
+	if len(diffs) == 0 {
+		fmt.Fprintln(out, "All the providers are already up to date, there are no changes to apply.")
+		return nil
+	}
+
+	for _, diff := range diffs {
+		fmt.Fprintf(out, "Provider %s (%s): %s -> %s\n\n", diff.Provider.InstanceName(), diff.Provider.Type, diff.Provider.Version, diff.NextVersion)
+
+		w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+		fmt.Fprintln(w, "Images:")
+		if len(diff.ImageChanges) == 0 {
+			fmt.Fprintln(w, "  No changes")
+		} else {
+			fmt.Fprintln(w, "  DEPLOYMENT\tCONTAINER\tCURRENT IMAGE\tNEXT IMAGE")
+			for _, change := range diff.ImageChanges {
+				fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", change.Deployment, change.Container, prettifyImage(change.CurrentImage), prettifyImage(change.NextImage))
+			}
+		}
+		fmt.Fprintln(w, "")
+
+		fmt.Fprintln(w, "CRDs:")
+		if len(diff.CRDChanges) == 0 && len(diff.CRDSchemaChanges) == 0 {
+			fmt.Fprintln(w, "  No changes")
+		} else {
+			fmt.Fprintln(w, "  CRD\tCHANGES")
+			for _, change := range diff.CRDChanges {
+				fmt.Fprintf(w, "  %s\t%s\n", change.Name, describeCRDChange(change))
+			}
+			for _, change := range diff.CRDSchemaChanges {
+				fmt.Fprintf(w, "  %s\t%s\n", change.CRD, describeCRDSchemaChange(change))
+			}
+		}
+		fmt.Fprintln(w, "")
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		printObjectChanges(out, "RBAC", diff.RBACChanges)
+		printObjectChanges(out, "Webhooks", diff.WebhookChanges)
+	}
+
+	fmt.Fprintln(out, "This is a dry run, no changes have been applied to the management cluster.")
+	return nil
+}
+
+// describeCRDSchemaChange returns a short description of the changes to the schema of a CRD version.
+func describeCRDSchemaChange(change cluster.CRDSchemaChange) string {
+	descriptions := []string{}
+	if len(change.AddedFields) > 0 {
+		descriptions = append(descriptions, fmt.Sprintf("added %s", strings.Join(change.AddedFields, ", ")))
+	}
+	if len(change.RemovedFields) > 0 {
+		descriptions = append(descriptions, fmt.Sprintf("removed %s", strings.Join(change.RemovedFields, ", ")))
+	}
+	return fmt.Sprintf("%s schema: %s", change.Version, strings.Join(descriptions, "; "))
+}
+
+// printObjectChanges prints the objects changed by the upgrade, followed by the details of every change.
+func printObjectChanges(out io.Writer, title string, changes []cluster.ObjectChange) {
+	fmt.Fprintf(out, "%s:\n", title)
+	if len(changes) == 0 {
+		fmt.Fprintln(out, "  No changes")
+	}
+	for _, change := range changes {
+		name := change.Name
+		if change.Namespace != "" {
+			name = change.Namespace + "/" + change.Name
+		}
+		fmt.Fprintf(out, "  %s %s %s\n", change.Operation, change.Kind, name)
+		for _, detail := range change.Details {
+			fmt.Fprintf(out, "    %s\n", detail)
+		}
+	}
+	fmt.Fprintln(out, "")
+}
+
+func prettifyImage(image string) string {
+	if image == "" {
+		return "-"
+	}
+	return image
 }
@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+func Test_printUpgradeDiffs(t *testing.T) {
+	core := clusterctlv1.Provider{ProviderName: "cluster-api", Type: string(clusterctlv1.CoreProviderType), Version: "v1.0.0"}
+	core.Name = "cluster-api"
+	core.Namespace = "capi-system"
+
+	tests := []struct {
+		name  string
+		diffs []client.ProviderUpgradeDiff
+		want  []string
+	}{
+		{
+			name:  "No providers to upgrade",
+			diffs: nil,
+			want:  []string{"All the providers are already up to date"},
+		},
+		{
+			name: "Provider with changes",
+			diffs: []client.ProviderUpgradeDiff{
+				{
+					UpgradeItem: cluster.UpgradeItem{
+						Provider:    core,
+						NextVersion: "v1.1.0",
+						CRDChanges:  []cluster.CRDChange{{Name: "clusters.cluster.x-k8s.io", CurrentStorageVersion: "v1beta1", NextStorageVersion: "v1beta1", AddedVersions: []string{"v1beta2"}}},
+					},
+					ImageChanges: []cluster.ImageChange{{Deployment: "capi-system/capi-controller-manager", Container: "manager", CurrentImage: "capi:v1.0.0", NextImage: "capi:v1.1.0"}},
+					CRDSchemaChanges: []cluster.CRDSchemaChange{{
+						CRD: "clusters.cluster.x-k8s.io", Version: "v1beta1", AddedFields: []string{"spec.availabilityGates"}, RemovedFields: []string{"spec.legacyTopology"},
+					}},
+					RBACChanges: []cluster.ObjectChange{{
+						Kind: "ClusterRole", Name: "capi-manager-role", Operation: cluster.ObjectModified,
+						Details: []string{`+ apiGroups=[""] resources=["secrets"] verbs=["get"]`},
+					}},
+				},
+			},
+			want: []string{
+				"Provider capi-system/cluster-api (CoreProvider): v1.0.0 -> v1.1.0",
+				"capi-system/capi-controller-manager", "capi:v1.0.0", "capi:v1.1.0",
+				"added v1beta2",
+				"v1beta1 schema: added spec.availabilityGates; removed spec.legacyTopology",
+				"Modified ClusterRole capi-manager-role",
+				`    + apiGroups=[""] resources=["secrets"] verbs=["get"]`,
+				"Webhooks:\n  No changes",
+				"This is a dry run",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			var out bytes.Buffer
+			g.Expect(printUpgradeDiffs(&out, tt.diffs)).To(Succeed())
+			for _, w := range tt.want {
+				g.Expect(out.String()).To(ContainSubstring(w))
+			}
+		})
+	}
+}
@@ -18,21 +18,42 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"text/tabwriter"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 )
 
 type upgradePlanOptions struct {
 	kubeconfig        string
 	kubeconfigContext string
+	output            string
+	noContractChanges bool
+	maxVersionChange  string
+	showCRDChanges    bool
 }
 
+const (
+	// UpgradePlanOutputText is an option used to print the upgrade plans in text format.
+	UpgradePlanOutputText = "text"
+	// UpgradePlanOutputJSON is an option used to print the upgrade plans in json format.
+	UpgradePlanOutputJSON = "json"
+)
+
+var (
+	// UpgradePlanOutputs is a list of valid upgrade plan outputs.
+	UpgradePlanOutputs = []string{UpgradePlanOutputText, UpgradePlanOutputJSON}
+)
+
 var up = &upgradePlanOptions{}
 
 var upgradePlanCmd = &cobra.Command{
@@ -47,11 +68,18 @@ var upgradePlanCmd = &cobra.Command{
 
 		Then, for each provider, the following upgrade options are provided:
 		- The latest patch release for the current API Version of Cluster API (contract).
-		- The latest patch release for the next API Version of Cluster API (contract), if available.`),
+		- The latest patch release for the next API Version of Cluster API (contract), if available.
+
+		The upgrade options can be limited by constraints, e.g. allowing only patch releases, and the plan
+		can be printed in json format, e.g. for gating upgrades in automation.`),
 
 	Example: Examples(`
 		# Gets the recommended target versions for upgrading Cluster API providers.
-		clusterctl upgrade plan`),
+		clusterctl upgrade plan
+
+		# Gets the recommended target versions allowing only patch releases, in json format,
+		# including the changes to the CRDs of each provider.
+		clusterctl upgrade plan --no-contract-changes --max-version-change patch --show-crd-changes -o json`),
 
 	RunE: func(*cobra.Command, []string) error {
 		return runUpgradePlan()
@@ -63,22 +91,85 @@ func init() {
 		"Path to the kubeconfig file to use for accessing the management cluster. If empty, default discovery rules apply.")
 	upgradePlanCmd.Flags().StringVar(&up.kubeconfigContext, "kubeconfig-context", "",
 		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	upgradePlanCmd.Flags().StringVarP(&up.output, "output", "o", UpgradePlanOutputText,
+		fmt.Sprintf("Output format. Valid values: %v.", UpgradePlanOutputs))
+	upgradePlanCmd.Flags().BoolVar(&up.noContractChanges, "no-contract-changes", false,
+		"Do not propose upgrades changing the API Version of Cluster API (contract) of the management cluster.")
+	upgradePlanCmd.Flags().StringVar(&up.maxVersionChange, "max-version-change", "",
+		fmt.Sprintf("Propose only upgrades within the given change from the current version of each provider, one of %q or %q. If empty, any version is allowed.", cluster.UpgradeVersionChangeMinor, cluster.UpgradeVersionChangePatch))
+	upgradePlanCmd.Flags().BoolVar(&up.showCRDChanges, "show-crd-changes", false,
+		"Show the changes to the CRDs of each provider, e.g. API versions added or removed, or storage versions changing. This requires reading the provider components for the target versions.")
+}
+
+// upgradePlanOutput is the json representation of the upgrade plans.
+type upgradePlanOutput struct {
+	CertManager *upgradePlanCertManagerOutput `json:"certManager,omitempty"`
+	Plans       []upgradePlanContractOutput   `json:"plans"`
+}
+
+type upgradePlanCertManagerOutput struct {
+	From          string `json:"from"`
+	To            string `json:"to"`
+	ShouldUpgrade bool   `json:"shouldUpgrade"`
+}
+
+type upgradePlanContractOutput struct {
+	Contract string `json:"contract"`
+	// Supported is true if the current version of clusterctl can apply the upgrade plan.
+	Supported        bool                        `json:"supported"`
+	UpgradeAvailable bool                        `json:"upgradeAvailable"`
+	Providers        []upgradePlanProviderOutput `json:"providers"`
+}
+
+type upgradePlanProviderOutput struct {
+	Name           string              `json:"name"`
+	Namespace      string              `json:"namespace"`
+	Type           string              `json:"type"`
+	CurrentVersion string              `json:"currentVersion"`
+	NextVersion    string              `json:"nextVersion,omitempty"`
+	CRDChanges     []cluster.CRDChange `json:"crdChanges,omitempty"`
 }
 
 func runUpgradePlan() error {
 	ctx := context.Background()
 
+	if up.output != UpgradePlanOutputText && up.output != UpgradePlanOutputJSON {
+		return errors.Errorf("invalid output format %q, valid values: %v", up.output, UpgradePlanOutputs)
+	}
+
 	c, err := client.New(ctx, cfgFile)
 	if err != nil {
 		return err
 	}
 
-	certManUpgradePlan, err := c.PlanCertManagerUpgrade(ctx, client.PlanUpgradeOptions{
-		Kubeconfig: client.Kubeconfig{Path: up.kubeconfig, Context: up.kubeconfigContext},
-	})
+	options := client.PlanUpgradeOptions{
+		Kubeconfig:        client.Kubeconfig{Path: up.kubeconfig, Context: up.kubeconfigContext},
+		NoContractChanges: up.noContractChanges,
+		MaxVersionChange:  cluster.UpgradeVersionChange(up.maxVersionChange),
+		IncludeCRDChanges: up.showCRDChanges,
+	}
+
+	certManUpgradePlan, err := c.PlanCertManagerUpgrade(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	upgradePlans, err := c.PlanUpgrade(ctx, options)
 	if err != nil {
 		return err
 	}
+
+	// ensure upgrade plans are sorted consistently (by CoreProvider.Namespace, Contract).
+	sortUpgradePlans(upgradePlans)
+	for _, plan := range upgradePlans {
+		// ensure provider are sorted consistently (by Type, Name, Namespace).
+		sortUpgradeItems(plan)
+	}
+
+	if up.output == UpgradePlanOutputJSON {
+		return printUpgradePlanJSON(os.Stdout, certManUpgradePlan, upgradePlans)
+	}
+
 	if !certManUpgradePlan.ExternallyManaged {
 		if certManUpgradePlan.ShouldUpgrade {
 			fmt.Printf("Cert-Manager will be upgraded from %q to %q\n\n", certManUpgradePlan.From, certManUpgradePlan.To)
@@ -87,26 +178,12 @@ func runUpgradePlan() error {
 		}
 	}
 
-	upgradePlans, err := c.PlanUpgrade(ctx, client.PlanUpgradeOptions{
-		Kubeconfig: client.Kubeconfig{Path: up.kubeconfig, Context: up.kubeconfigContext},
-	})
-
-	if err != nil {
-		return err
-	}
-
 	if len(upgradePlans) == 0 {
 		fmt.Println("There are no providers in the cluster. Please use clusterctl init to initialize a Cluster API management cluster.")
 		return nil
 	}
 
-	// ensure upgrade plans are sorted consistently (by CoreProvider.Namespace, Contract).
-	sortUpgradePlans(upgradePlans)
-
 	for _, plan := range upgradePlans {
-		// ensure provider are sorted consistently (by Type, Name, Namespace).
-		sortUpgradeItems(plan)
-
 		upgradeAvailable := false
 
 		fmt.Println("")
@@ -125,6 +202,12 @@ func runUpgradePlan() error {
 		}
 		fmt.Println("")
 
+		if up.showCRDChanges && upgradeAvailable {
+			if err := printCRDChanges(plan); err != nil {
+				return err
+			}
+		}
+
 		if upgradeAvailable {
 			if plan.Contract == clusterv1.GroupVersion.Version {
 				fmt.Println("You can now apply the upgrade by executing the following command:")
@@ -141,3 +224,91 @@ func runUpgradePlan() error {
 
 	return nil
 }
+
+// printCRDChanges prints the changes to the CRDs of the providers in an upgrade plan.
+func printCRDChanges(plan client.UpgradePlan) error {
+	fmt.Println("Changes to the CRDs:")
+	fmt.Println("")
+	w := tabwriter.NewWriter(os.Stdout, 10, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tCRD\tCHANGES")
+	changes := 0
+	for _, upgradeItem := range plan.Providers {
+		for _, change := range upgradeItem.CRDChanges {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", upgradeItem.Provider.InstanceName(), change.Name, describeCRDChange(change))
+			changes++
+		}
+	}
+	if changes == 0 {
+		fmt.Fprintln(w, "-\t-\tNo changes to the API versions")
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Println("")
+	return nil
+}
+
+// describeCRDChange returns a short description of the changes to a CRD.
+func describeCRDChange(change cluster.CRDChange) string {
+	if change.Added {
+		return fmt.Sprintf("new CRD, serving %s", strings.Join(change.AddedVersions, ", "))
+	}
+
+	descriptions := []string{}
+	if change.CurrentStorageVersion != change.NextStorageVersion {
+		descriptions = append(descriptions, fmt.Sprintf("storage version %s -> %s", change.CurrentStorageVersion, change.NextStorageVersion))
+	}
+	if len(change.AddedVersions) > 0 {
+		descriptions = append(descriptions, fmt.Sprintf("added %s", strings.Join(change.AddedVersions, ", ")))
+	}
+	if len(change.RemovedVersions) > 0 {
+		descriptions = append(descriptions, fmt.Sprintf("removed %s", strings.Join(change.RemovedVersions, ", ")))
+	}
+	if change.MigrationRequired {
+		descriptions = append(descriptions, "objects will be migrated to the storage version")
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+// printUpgradePlanJSON prints the upgrade plans in json format.
+func printUpgradePlanJSON(w io.Writer, certManUpgradePlan client.CertManagerUpgradePlan, upgradePlans []client.UpgradePlan) error {
+	out := upgradePlanOutput{
+		Plans: []upgradePlanContractOutput{},
+	}
+	if !certManUpgradePlan.ExternallyManaged {
+		out.CertManager = &upgradePlanCertManagerOutput{
+			From:          certManUpgradePlan.From,
+			To:            certManUpgradePlan.To,
+			ShouldUpgrade: certManUpgradePlan.ShouldUpgrade,
+		}
+	}
+
+	for _, plan := range upgradePlans {
+		planOut := upgradePlanContractOutput{
+			Contract:  plan.Contract,
+			Supported: plan.Contract == clusterv1.GroupVersion.Version,
+			Providers: []upgradePlanProviderOutput{},
+		}
+		for _, upgradeItem := range plan.Providers {
+			planOut.Providers = append(planOut.Providers, upgradePlanProviderOutput{
+				Name:           upgradeItem.Provider.Name,
+				Namespace:      upgradeItem.Provider.Namespace,
+				Type:           upgradeItem.Provider.Type,
+				CurrentVersion: upgradeItem.Provider.Version,
+				NextVersion:    upgradeItem.NextVersion,
+				CRDChanges:     upgradeItem.CRDChanges,
+			})
+			if upgradeItem.NextVersion != "" {
+				planOut.UpgradeAvailable = true
+			}
+		}
+		out.Plans = append(out.Plans, planOut)
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+func Test_printUpgradePlanJSON(t *testing.T) {
+	g := NewWithT(t)
+
+	core := clusterctlv1.Provider{ProviderName: "cluster-api", Type: string(clusterctlv1.CoreProviderType), Version: "v1.0.0"}
+	core.Name = "cluster-api"
+	core.Namespace = "capi-system"
+
+	upgradePlans := []client.UpgradePlan{
+		{
+			Contract: clusterv1.GroupVersion.Version,
+			Providers: []cluster.UpgradeItem{
+				{
+					Provider:    core,
+					NextVersion: "v1.0.1",
+					CRDChanges:  []cluster.CRDChange{{Name: "clusters.cluster.x-k8s.io", AddedVersions: []string{"v1beta2"}}},
+				},
+			},
+		},
+		{
+			Contract:  "v1alpha99",
+			Providers: []cluster.UpgradeItem{{Provider: core}},
+		},
+	}
+
+	var out bytes.Buffer
+	g.Expect(printUpgradePlanJSON(&out, client.CertManagerUpgradePlan{ExternallyManaged: true}, upgradePlans)).To(Succeed())
+
+	got := &upgradePlanOutput{}
+	g.Expect(json.Unmarshal(out.Bytes(), got)).To(Succeed())
+	g.Expect(got.CertManager).To(BeNil())
+	g.Expect(got.Plans).To(HaveLen(2))
+	g.Expect(got.Plans[0].Supported).To(BeTrue())
+	g.Expect(got.Plans[0].UpgradeAvailable).To(BeTrue())
+	g.Expect(got.Plans[0].Providers).To(ConsistOf(upgradePlanProviderOutput{
+		Name:           "cluster-api",
+		Namespace:      "capi-system",
+		Type:           string(clusterctlv1.CoreProviderType),
+		CurrentVersion: "v1.0.0",
+		NextVersion:    "v1.0.1",
+		CRDChanges:     []cluster.CRDChange{{Name: "clusters.cluster.x-k8s.io", AddedVersions: []string{"v1beta2"}}},
+	}))
+	g.Expect(got.Plans[1].Supported).To(BeFalse())
+	g.Expect(got.Plans[1].UpgradeAvailable).To(BeFalse())
+}
+
+func Test_describeCRDChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		change cluster.CRDChange
+		want   string
+	}{
+		{
+			name:   "CRD added",
+			change: cluster.CRDChange{Added: true, AddedVersions: []string{"v1alpha1", "v1beta1"}},
+			want:   "new CRD, serving v1alpha1, v1beta1",
+		},
+		{
+			name:   "Storage version changed",
+			change: cluster.CRDChange{CurrentStorageVersion: "v1beta1", NextStorageVersion: "v1beta2", AddedVersions: []string{"v1beta2"}},
+			want:   "storage version v1beta1 -> v1beta2; added v1beta2",
+		},
+		{
+			name:   "Version removed",
+			change: cluster.CRDChange{CurrentStorageVersion: "v1beta1", NextStorageVersion: "v1beta1", RemovedVersions: []string{"v1alpha4"}, MigrationRequired: true},
+			want:   "removed v1alpha4; objects will be migrated to the storage version",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(describeCRDChange(tt.change)).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,449 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controllers implements the controllers for the clusterctl API types.
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	clusterctlclient "sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/finalizers"
+	"sigs.k8s.io/cluster-api/util/patch"
+)
+
+// providerReadinessRequeueAfter is the interval between checks of the Deployments of a provider installed or upgraded.
+const providerReadinessRequeueAfter = 10 * time.Second
+
+// reservedConfigKeys are the keys of the clusterctl configuration which can't be set by the variables Secret
+// of a ProviderInstallation, because they change where the provider components and images are fetched from.
+var reservedConfigKeys = []string{config.ProvidersConfigKey, "images", config.CertManagerConfigKey, "overridesFolder"}
+
+// ProviderInstallationReconciler reconciles a ProviderInstallation object by installing, upgrading and deleting
+// providers with the same logic of clusterctl init, clusterctl upgrade apply and clusterctl delete.
+type ProviderInstallationReconciler struct {
+	Client client.Client
+
+	// Kubeconfig is the kubeconfig used by the clusterctl library to access the management cluster.
+	Kubeconfig clusterctlclient.Kubeconfig
+
+	// AllowedRepositories are the URL prefixes of the repositories ProviderInstallations can set in spec.url;
+	// if empty, only the repositories configured by default in clusterctl can be used.
+	AllowedRepositories []string
+
+	// NewClusterctlClient returns the clusterctl client for a clusterctl configuration;
+	// if not set, a clusterctl client using the given configuration reader is created.
+	NewClusterctlClient func(ctx context.Context, reader config.Reader) (clusterctlclient.Client, error)
+}
+
+func (r *ProviderInstallationReconciler) SetupWithManager(_ context.Context, mgr ctrl.Manager, options controller.Options) error {
+	if r.NewClusterctlClient == nil {
+		r.NewClusterctlClient = newClusterctlClient
+	}
+
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&clusterctlv1.ProviderInstallation{}).
+		// Watch the inventory, so ProviderInstallations waiting for the core provider are reconciled as soon as it is installed.
+		Watches(
+			&clusterctlv1.Provider{},
+			handler.EnqueueRequestsFromMapFunc(r.providerToProviderInstallations),
+		).
+		WithOptions(options).
+		Complete(r)
+	if err != nil {
+		return errors.Wrap(err, "failed setting up with a controller manager")
+	}
+
+	return nil
+}
+
+func (r *ProviderInstallationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	// Fetch the ProviderInstallation instance.
+	providerInstallation := &clusterctlv1.ProviderInstallation{}
+	if err := r.Client.Get(ctx, req.NamespacedName, providerInstallation); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Add finalizer first if not set to avoid the race condition between init and delete.
+	if finalizerAdded, err := finalizers.EnsureFinalizer(ctx, r.Client, providerInstallation, clusterctlv1.ProviderInstallationFinalizer); err != nil || finalizerAdded {
+		return ctrl.Result{}, err
+	}
+
+	// Initialize the patch helper.
+	patchHelper, err := patch.NewHelper(providerInstallation, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	defer func() {
+		// Always attempt to Patch the ProviderInstallation object and status after each reconciliation.
+		if err := patchHelper.Patch(ctx, providerInstallation,
+			patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{clusterv1.ReadyCondition}},
+			patch.WithStatusObservedGeneration{},
+		); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	// Handle deletion reconciliation loop.
+	if !providerInstallation.ObjectMeta.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.reconcileDelete(ctx, providerInstallation)
+	}
+
+	return r.reconcileNormal(ctx, providerInstallation)
+}
+
+func (r *ProviderInstallationReconciler) reconcileNormal(ctx context.Context, providerInstallation *clusterctlv1.ProviderInstallation) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	providers := &clusterctlv1.ProviderList{}
+	if err := r.Client.List(ctx, providers); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to list providers in the clusterctl inventory")
+	}
+
+	installed := getInstalledProvider(providers, providerInstallation)
+	if installed != nil {
+		providerInstallation.Status.InstalledVersion = installed.Version
+		if installed.Version == providerInstallation.Spec.Version {
+			return r.reconcileProviderReadiness(ctx, providerInstallation, installed)
+		}
+	}
+
+	// Providers other than the core provider can be installed only after the core provider,
+	// given that clusterctl init would otherwise add the default core provider.
+	if installed == nil && providerInstallation.GetProviderType() != clusterctlv1.CoreProviderType &&
+		len(providers.FilterCore()) == 0 {
+		log.Info("Waiting for the core provider to be installed")
+		conditions.MarkFalse(providerInstallation, clusterv1.ReadyCondition, clusterctlv1.WaitingForCoreProviderReason, clusterv1.ConditionSeverityInfo, "")
+		return ctrl.Result{}, nil
+	}
+
+	variables, err := r.getVariables(ctx, providerInstallation)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Only the repositories the controller is allowed to use can be used, so ProviderInstallations can't be used to
+	// install arbitrary components with the permissions of the controller.
+	if err := r.validateRepository(providerInstallation, variables); err != nil {
+		log.Error(err, "Repository not allowed")
+		conditions.MarkFalse(providerInstallation, clusterv1.ReadyCondition, clusterctlv1.RepositoryNotAllowedReason, clusterv1.ConditionSeverityError, err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	c, err := r.getClusterctlClient(ctx, providerInstallation, variables)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Note: clusterctl does not wait for the providers to be ready, so the reconciler is not blocked while the provider
+	// starts; readiness is checked at the next reconciliations, once the new version is recorded in the inventory.
+	if installed == nil {
+		log.Info("Installing provider", "version", providerInstallation.Spec.Version)
+		options := clusterctlclient.InitOptions{
+			Kubeconfig:      r.Kubeconfig,
+			TargetNamespace: providerInstallation.Spec.TargetNamespace,
+		}
+		if err := setInitProvider(&options, providerInstallation); err != nil {
+			return ctrl.Result{}, err
+		}
+		if _, err := c.Init(ctx, options); err != nil {
+			conditions.MarkFalse(providerInstallation, clusterv1.ReadyCondition, clusterctlv1.InstallationFailedReason, clusterv1.ConditionSeverityError, err.Error())
+			return ctrl.Result{}, errors.Wrapf(err, "failed to install provider %s", providerInstallation.Spec.ProviderName)
+		}
+	} else {
+		log.Info("Upgrading provider", "fromVersion", installed.Version, "version", providerInstallation.Spec.Version)
+		options := clusterctlclient.ApplyUpgradeOptions{
+			Kubeconfig:             r.Kubeconfig,
+			MigrateStorageVersions: true,
+		}
+		if err := setUpgradeProvider(&options, providerInstallation); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := c.ApplyUpgrade(ctx, options); err != nil {
+			conditions.MarkFalse(providerInstallation, clusterv1.ReadyCondition, clusterctlv1.UpgradeFailedReason, clusterv1.ConditionSeverityError, err.Error())
+			return ctrl.Result{}, errors.Wrapf(err, "failed to upgrade provider %s", providerInstallation.Spec.ProviderName)
+		}
+	}
+
+	providerInstallation.Status.InstalledVersion = providerInstallation.Spec.Version
+	conditions.MarkFalse(providerInstallation, clusterv1.ReadyCondition, clusterctlv1.WaitingForProviderReason, clusterv1.ConditionSeverityInfo, "")
+	return ctrl.Result{RequeueAfter: providerReadinessRequeueAfter}, nil
+}
+
+// reconcileProviderReadiness sets the Ready condition of a ProviderInstallation whose version is installed according
+// to the availability of the Deployments of the provider, and requeues till they are all available.
+func (r *ProviderInstallationReconciler) reconcileProviderReadiness(ctx context.Context, providerInstallation *clusterctlv1.ProviderInstallation, installed *clusterctlv1.Provider) (ctrl.Result, error) {
+	deployments := &appsv1.DeploymentList{}
+	if err := r.Client.List(ctx, deployments, client.InNamespace(installed.Namespace), client.MatchingLabels{clusterv1.ProviderNameLabel: installed.ManifestLabel()}); err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to list Deployments of provider %s", providerInstallation.Spec.ProviderName)
+	}
+
+	notAvailable := []string{}
+	for i := range deployments.Items {
+		if !isDeploymentAvailable(&deployments.Items[i]) {
+			notAvailable = append(notAvailable, deployments.Items[i].Name)
+		}
+	}
+	if len(notAvailable) > 0 {
+		sort.Strings(notAvailable)
+		conditions.MarkFalse(providerInstallation, clusterv1.ReadyCondition, clusterctlv1.WaitingForProviderReason, clusterv1.ConditionSeverityInfo,
+			"Deployments %s are not available", strings.Join(notAvailable, ", "))
+		return ctrl.Result{RequeueAfter: providerReadinessRequeueAfter}, nil
+	}
+
+	conditions.MarkTrue(providerInstallation, clusterv1.ReadyCondition)
+	return ctrl.Result{}, nil
+}
+
+func (r *ProviderInstallationReconciler) reconcileDelete(ctx context.Context, providerInstallation *clusterctlv1.ProviderInstallation) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	providers := &clusterctlv1.ProviderList{}
+	if err := r.Client.List(ctx, providers); err != nil {
+		return errors.Wrap(err, "failed to list providers in the clusterctl inventory")
+	}
+
+	// Delete the provider, preserving CRDs and the provider namespace like clusterctl delete does by default.
+	if getInstalledProvider(providers, providerInstallation) != nil {
+		variables, err := r.getVariables(ctx, providerInstallation)
+		if err != nil {
+			return err
+		}
+		c, err := r.getClusterctlClient(ctx, providerInstallation, variables)
+		if err != nil {
+			return err
+		}
+
+		log.Info("Deleting provider")
+		options := clusterctlclient.DeleteOptions{
+			Kubeconfig: r.Kubeconfig,
+		}
+		if err := setDeleteProvider(&options, providerInstallation); err != nil {
+			return err
+		}
+		if err := c.Delete(ctx, options); err != nil {
+			return errors.Wrapf(err, "failed to delete provider %s", providerInstallation.Spec.ProviderName)
+		}
+	}
+
+	controllerutil.RemoveFinalizer(providerInstallation, clusterctlv1.ProviderInstallationFinalizer)
+	return nil
+}
+
+// validateRepository returns an error if the ProviderInstallation uses a repository not in AllowedRepositories,
+// or if its variables change where the provider components and images are fetched from.
+func (r *ProviderInstallationReconciler) validateRepository(providerInstallation *clusterctlv1.ProviderInstallation, variables map[string]string) error {
+	if url := providerInstallation.Spec.URL; url != "" {
+		allowed := false
+		for _, prefix := range r.AllowedRepositories {
+			if strings.HasPrefix(url, prefix) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return errors.Errorf("repository %q is not allowed; allowed repositories: %v", url, r.AllowedRepositories)
+		}
+	}
+
+	for _, k := range reservedConfigKeys {
+		if _, ok := variables[k]; ok {
+			return errors.Errorf("variables Secret %s can't set the %q clusterctl configuration key", providerInstallation.Spec.VariablesSecretRef.Name, k)
+		}
+	}
+	return nil
+}
+
+// getVariables returns the variables from the Secret referenced by the ProviderInstallation, if any.
+func (r *ProviderInstallationReconciler) getVariables(ctx context.Context, providerInstallation *clusterctlv1.ProviderInstallation) (map[string]string, error) {
+	variables := map[string]string{}
+	ref := providerInstallation.Spec.VariablesSecretRef
+	if ref == nil {
+		return variables, nil
+	}
+
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: providerInstallation.Namespace, Name: ref.Name}
+	if err := r.Client.Get(ctx, key, secret); err != nil {
+		return nil, errors.Wrapf(err, "failed to get variables Secret %s", key)
+	}
+	for k, v := range secret.Data {
+		variables[k] = string(v)
+	}
+	return variables, nil
+}
+
+// getClusterctlClient returns a clusterctl client for the ProviderInstallation, using the repository URL
+// and the variables from the ProviderInstallation instead of the clusterctl configuration file.
+func (r *ProviderInstallationReconciler) getClusterctlClient(ctx context.Context, providerInstallation *clusterctlv1.ProviderInstallation, variables map[string]string) (clusterctlclient.Client, error) {
+	reader := config.NewMemoryReader()
+	for k, v := range variables {
+		reader.Set(k, v)
+	}
+
+	if providerInstallation.Spec.URL != "" {
+		if _, err := reader.AddProvider(providerInstallation.Spec.ProviderName, providerInstallation.GetProviderType(), providerInstallation.Spec.URL); err != nil {
+			return nil, errors.Wrapf(err, "failed to add repository for provider %s", providerInstallation.Spec.ProviderName)
+		}
+	}
+
+	if err := reader.Init(ctx, ""); err != nil {
+		return nil, errors.Wrap(err, "failed to initialize the clusterctl configuration")
+	}
+
+	return r.NewClusterctlClient(ctx, reader)
+}
+
+// providerToProviderInstallations enqueues all the ProviderInstallations when the clusterctl inventory changes.
+func (r *ProviderInstallationReconciler) providerToProviderInstallations(ctx context.Context, _ client.Object) []reconcile.Request {
+	providerInstallations := &clusterctlv1.ProviderInstallationList{}
+	if err := r.Client.List(ctx, providerInstallations); err != nil {
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(providerInstallations.Items))
+	for i := range providerInstallations.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&providerInstallations.Items[i])})
+	}
+	return requests
+}
+
+func newClusterctlClient(ctx context.Context, reader config.Reader) (clusterctlclient.Client, error) {
+	configClient, err := config.New(ctx, "", config.InjectReader(reader))
+	if err != nil {
+		return nil, err
+	}
+	return clusterctlclient.New(ctx, "", clusterctlclient.InjectConfig(configClient))
+}
+
+// isDeploymentAvailable returns true if all the replicas of the Deployment are updated and available.
+func isDeploymentAvailable(deployment *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	return deployment.Status.ObservedGeneration >= deployment.Generation &&
+		deployment.Status.UpdatedReplicas >= replicas &&
+		deployment.Status.AvailableReplicas >= replicas
+}
+
+// getInstalledProvider returns the provider from the clusterctl inventory matching the ProviderInstallation, if any.
+func getInstalledProvider(providers *clusterctlv1.ProviderList, providerInstallation *clusterctlv1.ProviderInstallation) *clusterctlv1.Provider {
+	matching := providers.FilterByProviderNameAndType(providerInstallation.Spec.ProviderName, providerInstallation.GetProviderType())
+	for i := range matching {
+		if providerInstallation.Spec.TargetNamespace != "" && matching[i].Namespace != providerInstallation.Spec.TargetNamespace {
+			continue
+		}
+		return &matching[i]
+	}
+	return nil
+}
+
+func setInitProvider(options *clusterctlclient.InitOptions, providerInstallation *clusterctlv1.ProviderInstallation) error {
+	provider := fmt.Sprintf("%s:%s", providerInstallation.Spec.ProviderName, providerInstallation.Spec.Version)
+	switch providerInstallation.GetProviderType() {
+	case clusterctlv1.CoreProviderType:
+		options.CoreProvider = provider
+		// Opt-out from the default bootstrap and control plane providers, which are managed by their own ProviderInstallation, if any.
+		options.BootstrapProviders = []string{clusterctlclient.NoopProvider}
+		options.ControlPlaneProviders = []string{clusterctlclient.NoopProvider}
+	case clusterctlv1.BootstrapProviderType:
+		options.BootstrapProviders = []string{provider}
+	case clusterctlv1.ControlPlaneProviderType:
+		options.ControlPlaneProviders = []string{provider}
+	case clusterctlv1.InfrastructureProviderType:
+		options.InfrastructureProviders = []string{provider}
+	case clusterctlv1.IPAMProviderType:
+		options.IPAMProviders = []string{provider}
+	case clusterctlv1.RuntimeExtensionProviderType:
+		options.RuntimeExtensionProviders = []string{provider}
+	case clusterctlv1.AddonProviderType:
+		options.AddonProviders = []string{provider}
+	default:
+		return errors.Errorf("unsupported provider type %q", providerInstallation.Spec.Type)
+	}
+	return nil
+}
+
+func setUpgradeProvider(options *clusterctlclient.ApplyUpgradeOptions, providerInstallation *clusterctlv1.ProviderInstallation) error {
+	provider := fmt.Sprintf("%s:%s", providerInstallation.Spec.ProviderName, providerInstallation.Spec.Version)
+	switch providerInstallation.GetProviderType() {
+	case clusterctlv1.CoreProviderType:
+		options.CoreProvider = provider
+	case clusterctlv1.BootstrapProviderType:
+		options.BootstrapProviders = []string{provider}
+	case clusterctlv1.ControlPlaneProviderType:
+		options.ControlPlaneProviders = []string{provider}
+	case clusterctlv1.InfrastructureProviderType:
+		options.InfrastructureProviders = []string{provider}
+	case clusterctlv1.IPAMProviderType:
+		options.IPAMProviders = []string{provider}
+	case clusterctlv1.RuntimeExtensionProviderType:
+		options.RuntimeExtensionProviders = []string{provider}
+	case clusterctlv1.AddonProviderType:
+		options.AddonProviders = []string{provider}
+	default:
+		return errors.Errorf("unsupported provider type %q", providerInstallation.Spec.Type)
+	}
+	return nil
+}
+
+func setDeleteProvider(options *clusterctlclient.DeleteOptions, providerInstallation *clusterctlv1.ProviderInstallation) error {
+	provider := providerInstallation.Spec.ProviderName
+	switch providerInstallation.GetProviderType() {
+	case clusterctlv1.CoreProviderType:
+		options.CoreProvider = provider
+	case clusterctlv1.BootstrapProviderType:
+		options.BootstrapProviders = []string{provider}
+	case clusterctlv1.ControlPlaneProviderType:
+		options.ControlPlaneProviders = []string{provider}
+	case clusterctlv1.InfrastructureProviderType:
+		options.InfrastructureProviders = []string{provider}
+	case clusterctlv1.IPAMProviderType:
+		options.IPAMProviders = []string{provider}
+	case clusterctlv1.RuntimeExtensionProviderType:
+		options.RuntimeExtensionProviders = []string{provider}
+	case clusterctlv1.AddonProviderType:
+		options.AddonProviders = []string{provider}
+	default:
+		return errors.Errorf("unsupported provider type %q", providerInstallation.Spec.Type)
+	}
+	return nil
+}
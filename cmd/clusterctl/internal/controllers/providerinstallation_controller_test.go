@@ -0,0 +1,278 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	clusterctlclient "sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/scheme"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+type fakeClusterctlClient struct {
+	clusterctlclient.Client
+
+	initOptions    []clusterctlclient.InitOptions
+	upgradeOptions []clusterctlclient.ApplyUpgradeOptions
+	deleteOptions  []clusterctlclient.DeleteOptions
+}
+
+func (f *fakeClusterctlClient) Init(_ context.Context, options clusterctlclient.InitOptions) ([]clusterctlclient.Components, error) {
+	f.initOptions = append(f.initOptions, options)
+	return nil, nil
+}
+
+func (f *fakeClusterctlClient) ApplyUpgrade(_ context.Context, options clusterctlclient.ApplyUpgradeOptions) error {
+	f.upgradeOptions = append(f.upgradeOptions, options)
+	return nil
+}
+
+func (f *fakeClusterctlClient) Delete(_ context.Context, options clusterctlclient.DeleteOptions) error {
+	f.deleteOptions = append(f.deleteOptions, options)
+	return nil
+}
+
+func TestProviderInstallationReconciler(t *testing.T) {
+	ctx := context.Background()
+
+	newProviderInstallation := func(name string, providerType clusterctlv1.ProviderType, version string) *clusterctlv1.ProviderInstallation {
+		return &clusterctlv1.ProviderInstallation{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  "default",
+				Name:       name,
+				Finalizers: []string{clusterctlv1.ProviderInstallationFinalizer},
+			},
+			Spec: clusterctlv1.ProviderInstallationSpec{
+				ProviderName:       name,
+				Type:               string(providerType),
+				Version:            version,
+				VariablesSecretRef: &corev1.LocalObjectReference{Name: "variables"},
+			},
+		}
+	}
+	newProvider := func(name string, providerType clusterctlv1.ProviderType, version string) *clusterctlv1.Provider {
+		return &clusterctlv1.Provider{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: name + "-system",
+				Name:      clusterctlv1.ManifestLabel(name, providerType),
+			},
+			ProviderName: name,
+			Type:         string(providerType),
+			Version:      version,
+		}
+	}
+	deployment := func(name string, providerType clusterctlv1.ProviderType, availableReplicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: name + "-system",
+				Name:      name + "-controller-manager",
+				Labels:    map[string]string{clusterv1.ProviderNameLabel: clusterctlv1.ManifestLabel(name, providerType)},
+			},
+			Spec: appsv1.DeploymentSpec{Replicas: ptr.To[int32](1)},
+			Status: appsv1.DeploymentStatus{
+				UpdatedReplicas:   1,
+				AvailableReplicas: availableReplicas,
+			},
+		}
+	}
+	variables := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "variables"},
+		Data:       map[string][]byte{"GITHUB_TOKEN": []byte("token")},
+	}
+
+	reconcile := func(g *WithT, c client.Client, providerInstallation *clusterctlv1.ProviderInstallation) (*fakeClusterctlClient, *clusterctlv1.ProviderInstallation, ctrl.Result) {
+		clusterctlClient := &fakeClusterctlClient{}
+		r := &ProviderInstallationReconciler{
+			Client:              c,
+			AllowedRepositories: []string{"https://github.com/my-org/"},
+			NewClusterctlClient: func(_ context.Context, reader config.Reader) (clusterctlclient.Client, error) {
+				g.Expect(reader.Get("GITHUB_TOKEN")).To(Equal("token"))
+				return clusterctlClient, nil
+			},
+		}
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(providerInstallation)})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		got := &clusterctlv1.ProviderInstallation{}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(providerInstallation), got); err != nil {
+			return clusterctlClient, nil, result
+		}
+		return clusterctlClient, got, result
+	}
+
+	t.Run("waits for the core provider before installing other providers", func(t *testing.T) {
+		g := NewWithT(t)
+
+		providerInstallation := newProviderInstallation("docker", clusterctlv1.InfrastructureProviderType, "v1.7.0")
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(providerInstallation, variables).WithStatusSubresource(providerInstallation).Build()
+
+		clusterctlClient, got, _ := reconcile(g, c, providerInstallation)
+		g.Expect(clusterctlClient.initOptions).To(BeEmpty())
+		g.Expect(conditions.GetReason(got, clusterv1.ReadyCondition)).To(Equal(clusterctlv1.WaitingForCoreProviderReason))
+	})
+
+	t.Run("installs the core provider without default providers", func(t *testing.T) {
+		g := NewWithT(t)
+
+		providerInstallation := newProviderInstallation("cluster-api", clusterctlv1.CoreProviderType, "v1.7.0")
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(providerInstallation, variables).WithStatusSubresource(providerInstallation).Build()
+
+		clusterctlClient, got, result := reconcile(g, c, providerInstallation)
+		g.Expect(clusterctlClient.initOptions).To(HaveLen(1))
+		g.Expect(clusterctlClient.initOptions[0].CoreProvider).To(Equal("cluster-api:v1.7.0"))
+		g.Expect(clusterctlClient.initOptions[0].BootstrapProviders).To(Equal([]string{clusterctlclient.NoopProvider}))
+		g.Expect(clusterctlClient.initOptions[0].ControlPlaneProviders).To(Equal([]string{clusterctlclient.NoopProvider}))
+		g.Expect(clusterctlClient.initOptions[0].WaitProviders).To(BeFalse())
+		g.Expect(got.Status.InstalledVersion).To(Equal("v1.7.0"))
+		g.Expect(conditions.GetReason(got, clusterv1.ReadyCondition)).To(Equal(clusterctlv1.WaitingForProviderReason))
+		g.Expect(result.RequeueAfter).To(Equal(providerReadinessRequeueAfter))
+	})
+
+	t.Run("installs a provider once the core provider is installed", func(t *testing.T) {
+		g := NewWithT(t)
+
+		providerInstallation := newProviderInstallation("docker", clusterctlv1.InfrastructureProviderType, "v1.7.0")
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(providerInstallation, variables, newProvider("cluster-api", clusterctlv1.CoreProviderType, "v1.7.0")).
+			WithStatusSubresource(providerInstallation).Build()
+
+		clusterctlClient, got, _ := reconcile(g, c, providerInstallation)
+		g.Expect(clusterctlClient.initOptions).To(HaveLen(1))
+		g.Expect(clusterctlClient.initOptions[0].InfrastructureProviders).To(Equal([]string{"docker:v1.7.0"}))
+		g.Expect(conditions.GetReason(got, clusterv1.ReadyCondition)).To(Equal(clusterctlv1.WaitingForProviderReason))
+	})
+
+	t.Run("upgrades a provider when the version changes", func(t *testing.T) {
+		g := NewWithT(t)
+
+		providerInstallation := newProviderInstallation("docker", clusterctlv1.InfrastructureProviderType, "v1.7.1")
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(providerInstallation, variables,
+				newProvider("cluster-api", clusterctlv1.CoreProviderType, "v1.7.0"),
+				newProvider("docker", clusterctlv1.InfrastructureProviderType, "v1.7.0")).
+			WithStatusSubresource(providerInstallation).Build()
+
+		clusterctlClient, got, _ := reconcile(g, c, providerInstallation)
+		g.Expect(clusterctlClient.initOptions).To(BeEmpty())
+		g.Expect(clusterctlClient.upgradeOptions).To(HaveLen(1))
+		g.Expect(clusterctlClient.upgradeOptions[0].InfrastructureProviders).To(Equal([]string{"docker:v1.7.1"}))
+		g.Expect(clusterctlClient.upgradeOptions[0].WaitProviders).To(BeFalse())
+		g.Expect(got.Status.InstalledVersion).To(Equal("v1.7.1"))
+		g.Expect(conditions.GetReason(got, clusterv1.ReadyCondition)).To(Equal(clusterctlv1.WaitingForProviderReason))
+	})
+
+	t.Run("waits for the Deployments of the provider to be available", func(t *testing.T) {
+		g := NewWithT(t)
+
+		providerInstallation := newProviderInstallation("docker", clusterctlv1.InfrastructureProviderType, "v1.7.0")
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(providerInstallation, variables,
+				newProvider("docker", clusterctlv1.InfrastructureProviderType, "v1.7.0"),
+				deployment("docker", clusterctlv1.InfrastructureProviderType, 0)).
+			WithStatusSubresource(providerInstallation).Build()
+
+		clusterctlClient, got, result := reconcile(g, c, providerInstallation)
+		g.Expect(clusterctlClient.initOptions).To(BeEmpty())
+		g.Expect(clusterctlClient.upgradeOptions).To(BeEmpty())
+		g.Expect(conditions.GetReason(got, clusterv1.ReadyCondition)).To(Equal(clusterctlv1.WaitingForProviderReason))
+		g.Expect(conditions.GetMessage(got, clusterv1.ReadyCondition)).To(Equal("Deployments docker-controller-manager are not available"))
+		g.Expect(result.RequeueAfter).To(Equal(providerReadinessRequeueAfter))
+	})
+
+	t.Run("does not install providers from repositories not allowed", func(t *testing.T) {
+		g := NewWithT(t)
+
+		providerInstallation := newProviderInstallation("cluster-api", clusterctlv1.CoreProviderType, "v1.7.0")
+		providerInstallation.Spec.URL = "https://github.com/other-org/cluster-api/releases/latest/core-components.yaml"
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(providerInstallation, variables).WithStatusSubresource(providerInstallation).Build()
+
+		clusterctlClient, got, _ := reconcile(g, c, providerInstallation)
+		g.Expect(clusterctlClient.initOptions).To(BeEmpty())
+		g.Expect(conditions.GetReason(got, clusterv1.ReadyCondition)).To(Equal(clusterctlv1.RepositoryNotAllowedReason))
+	})
+
+	t.Run("installs providers from allowed repositories", func(t *testing.T) {
+		g := NewWithT(t)
+
+		providerInstallation := newProviderInstallation("cluster-api", clusterctlv1.CoreProviderType, "v1.7.0")
+		providerInstallation.Spec.URL = "https://github.com/my-org/cluster-api/releases/latest/core-components.yaml"
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(providerInstallation, variables).WithStatusSubresource(providerInstallation).Build()
+
+		clusterctlClient, _, _ := reconcile(g, c, providerInstallation)
+		g.Expect(clusterctlClient.initOptions).To(HaveLen(1))
+	})
+
+	t.Run("does not install providers with variables changing the repositories", func(t *testing.T) {
+		g := NewWithT(t)
+
+		providerInstallation := newProviderInstallation("cluster-api", clusterctlv1.CoreProviderType, "v1.7.0")
+		variables := variables.DeepCopy()
+		variables.Data[config.ProvidersConfigKey] = []byte("- name: cluster-api\n  url: https://example.com/core-components.yaml\n  type: CoreProvider")
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(providerInstallation, variables).WithStatusSubresource(providerInstallation).Build()
+
+		clusterctlClient, got, _ := reconcile(g, c, providerInstallation)
+		g.Expect(clusterctlClient.initOptions).To(BeEmpty())
+		g.Expect(conditions.GetReason(got, clusterv1.ReadyCondition)).To(Equal(clusterctlv1.RepositoryNotAllowedReason))
+	})
+
+	t.Run("does nothing when the provider is up to date", func(t *testing.T) {
+		g := NewWithT(t)
+
+		providerInstallation := newProviderInstallation("docker", clusterctlv1.InfrastructureProviderType, "v1.7.0")
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(providerInstallation, variables,
+				newProvider("docker", clusterctlv1.InfrastructureProviderType, "v1.7.0"),
+				deployment("docker", clusterctlv1.InfrastructureProviderType, 1)).
+			WithStatusSubresource(providerInstallation).Build()
+
+		clusterctlClient, got, _ := reconcile(g, c, providerInstallation)
+		g.Expect(clusterctlClient.initOptions).To(BeEmpty())
+		g.Expect(clusterctlClient.upgradeOptions).To(BeEmpty())
+		g.Expect(got.Status.InstalledVersion).To(Equal("v1.7.0"))
+		g.Expect(conditions.IsTrue(got, clusterv1.ReadyCondition)).To(BeTrue())
+	})
+
+	t.Run("deletes the provider", func(t *testing.T) {
+		g := NewWithT(t)
+
+		providerInstallation := newProviderInstallation("docker", clusterctlv1.InfrastructureProviderType, "v1.7.0")
+		providerInstallation.DeletionTimestamp = &metav1.Time{Time: metav1.Now().Time}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(providerInstallation, variables, newProvider("docker", clusterctlv1.InfrastructureProviderType, "v1.7.0")).
+			WithStatusSubresource(providerInstallation).Build()
+
+		clusterctlClient, got, _ := reconcile(g, c, providerInstallation)
+		g.Expect(clusterctlClient.deleteOptions).To(HaveLen(1))
+		g.Expect(clusterctlClient.deleteOptions[0].InfrastructureProviders).To(Equal([]string{"docker"}))
+		g.Expect(clusterctlClient.deleteOptions[0].IncludeCRDs).To(BeFalse())
+		g.Expect(got).To(BeNil())
+	})
+}
@@ -102,10 +102,10 @@ func IsResourceNamespaced(kind string) bool {
 	}
 }
 
-// FixImages alters images using the give alter func
+// FixImages alters images using the give alter func; the alter func gets the name of the container and its image.
 // NB. The implemented approach is specific for the provider components YAML & for the cert-manager manifest; it is not
 // intended to cover all the possible objects used to deploy containers existing in Kubernetes.
-func FixImages(objs []unstructured.Unstructured, alterImageFunc func(image string) (string, error)) ([]unstructured.Unstructured, error) {
+func FixImages(objs []unstructured.Unstructured, alterImageFunc func(containerName, image string) (string, error)) ([]unstructured.Unstructured, error) {
 	for i := range objs {
 		if err := fixDeploymentImages(&objs[i], alterImageFunc); err != nil {
 			return nil, err
@@ -117,7 +117,7 @@ func FixImages(objs []unstructured.Unstructured, alterImageFunc func(image strin
 	return objs, nil
 }
 
-func fixDeploymentImages(o *unstructured.Unstructured, alterImageFunc func(image string) (string, error)) error {
+func fixDeploymentImages(o *unstructured.Unstructured, alterImageFunc func(containerName, image string) (string, error)) error {
 	if o.GetKind() != deploymentKind {
 		return nil
 	}
@@ -136,7 +136,7 @@ func fixDeploymentImages(o *unstructured.Unstructured, alterImageFunc func(image
 	return scheme.Scheme.Convert(d, o, nil)
 }
 
-func fixDaemonSetImages(o *unstructured.Unstructured, alterImageFunc func(image string) (string, error)) error {
+func fixDaemonSetImages(o *unstructured.Unstructured, alterImageFunc func(containerName, image string) (string, error)) error {
 	if o.GetKind() != daemonSetKind {
 		return nil
 	}
@@ -154,7 +154,7 @@ func fixDaemonSetImages(o *unstructured.Unstructured, alterImageFunc func(image
 	return scheme.Scheme.Convert(d, o, nil)
 }
 
-func fixPodSpecImages(podSpec *corev1.PodSpec, alterImageFunc func(image string) (string, error)) error {
+func fixPodSpecImages(podSpec *corev1.PodSpec, alterImageFunc func(containerName, image string) (string, error)) error {
 	if err := fixContainersImage(podSpec.Containers, alterImageFunc); err != nil {
 		return errors.Wrapf(err, "failed to fix containers")
 	}
@@ -164,10 +164,10 @@ func fixPodSpecImages(podSpec *corev1.PodSpec, alterImageFunc func(image string)
 	return nil
 }
 
-func fixContainersImage(containers []corev1.Container, alterImageFunc func(image string) (string, error)) error {
+func fixContainersImage(containers []corev1.Container, alterImageFunc func(containerName, image string) (string, error)) error {
 	for j := range containers {
 		container := &containers[j]
-		image, err := alterImageFunc(container.Image)
+		image, err := alterImageFunc(container.Name, container.Image)
 		if err != nil {
 			return errors.Wrapf(err, "failed to fix image for container %s", container.Name)
 		}
@@ -178,7 +178,7 @@ func Test_inspectImages(t *testing.T) {
 func TestFixImages(t *testing.T) {
 	type args struct {
 		objs           []unstructured.Unstructured
-		alterImageFunc func(image string) (string, error)
+		alterImageFunc func(containerName, image string) (string, error)
 	}
 	tests := []struct {
 		name    string
@@ -213,7 +213,7 @@ func TestFixImages(t *testing.T) {
 						},
 					},
 				},
-				alterImageFunc: func(image string) (string, error) {
+				alterImageFunc: func(_, image string) (string, error) {
 					return fmt.Sprintf("foo-%s", image), nil
 				},
 			},
@@ -247,7 +247,7 @@ func TestFixImages(t *testing.T) {
 						},
 					},
 				},
-				alterImageFunc: func(image string) (string, error) {
+				alterImageFunc: func(_, image string) (string, error) {
 					return fmt.Sprintf("foo-%s", image), nil
 				},
 			},
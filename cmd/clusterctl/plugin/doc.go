@@ -0,0 +1,27 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package plugin implements a small SDK for writing clusterctl plugins.
+
+clusterctl plugins are executables named clusterctl-{command} available in the PATH, e.g. the
+clusterctl-foo executable provides the `clusterctl foo` command.
+
+Plugins written in Go can use this package to get the clusterctl configuration, the clusterctl client,
+the client for the management cluster and its provider inventory, and the clusterctl logger, initialized
+with the same flags, configuration file and variables used by the clusterctl commands.
+*/
+package plugin
@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+)
+
+const (
+	// logLevelVariable is the variable used by clusterctl to set the log level verbosity.
+	logLevelVariable = "CLUSTERCTL_LOG_LEVEL"
+)
+
+// Options are the options used to initialize a plugin; they match the global options of the clusterctl commands,
+// so plugins can be invoked with the same flags used for clusterctl.
+type Options struct {
+	// ConfigFile is the path to the clusterctl configuration file or to a remote location.
+	// If empty, `$XDG_CONFIG_HOME/cluster-api/clusterctl.yaml` is used.
+	ConfigFile string
+
+	// Kubeconfig is the path to the kubeconfig file to use for accessing the management cluster.
+	// If empty, default discovery rules apply.
+	Kubeconfig string
+
+	// KubeconfigContext is the context to be used within the kubeconfig file.
+	// If empty, the current context is used.
+	KubeconfigContext string
+
+	// Verbosity is the log level verbosity. If 0, the CLUSTERCTL_LOG_LEVEL variable is used.
+	Verbosity int
+}
+
+// AddFlags adds the flags for the options to a flag set, using the same names and descriptions of the clusterctl flags.
+func (o *Options) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.ConfigFile, "config", "",
+		"Path to clusterctl configuration (default is `$XDG_CONFIG_HOME/cluster-api/clusterctl.yaml`) or to a remote location (i.e. https://example.com/clusterctl.yaml)")
+	flags.StringVar(&o.Kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file to use for accessing the management cluster. If empty, default discovery rules apply.")
+	flags.StringVar(&o.KubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	flags.IntVarP(&o.Verbosity, "v", "v", 0,
+		"Set the log level verbosity. This overrides the CLUSTERCTL_LOG_LEVEL environment variable.")
+}
+
+// Plugin provides access to the clusterctl configuration, to the management cluster and to the logger for clusterctl plugins.
+type Plugin struct {
+	configClient  config.Client
+	client        client.Client
+	clusterClient cluster.Client
+	kubeconfig    client.Kubeconfig
+	log           logr.Logger
+}
+
+// New returns a Plugin initialized like the clusterctl commands: it reads the clusterctl configuration
+// and sets the clusterctl logger as the global logger for the clusterctl library and for controller-runtime.
+func New(ctx context.Context, options Options) (*Plugin, error) {
+	configClient, err := config.New(ctx, options.ConfigFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the clusterctl configuration")
+	}
+
+	verbosity := options.Verbosity
+	if verbosity == 0 {
+		if v, err := configClient.Variables().Get(logLevelVariable); err == nil && v != "" {
+			verbosity, err = strconv.Atoi(v)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to convert %s string to an int", logLevelVariable)
+			}
+		}
+	}
+	log := logf.NewLogger(logf.WithThreshold(&verbosity))
+	logf.SetLogger(log)
+	ctrl.SetLogger(log)
+
+	c, err := client.New(ctx, options.ConfigFile, client.InjectConfig(configClient))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Plugin{
+		configClient:  configClient,
+		client:        c,
+		clusterClient: cluster.New(cluster.Kubeconfig{Path: options.Kubeconfig, Context: options.KubeconfigContext}, configClient),
+		kubeconfig:    client.Kubeconfig{Path: options.Kubeconfig, Context: options.KubeconfigContext},
+		log:           log,
+	}, nil
+}
+
+// Config returns the clusterctl configuration, e.g. to read variables or the provider repositories.
+func (p *Plugin) Config() config.Client {
+	return p.configClient
+}
+
+// Client returns the clusterctl client; the Kubeconfig method returns the kubeconfig to be used in the options
+// of the client methods for targeting the management cluster.
+func (p *Plugin) Client() client.Client {
+	return p.client
+}
+
+// Kubeconfig returns the kubeconfig for accessing the management cluster.
+func (p *Plugin) Kubeconfig() client.Kubeconfig {
+	return p.kubeconfig
+}
+
+// ManagementCluster returns the client for the management cluster.
+func (p *Plugin) ManagementCluster() cluster.Client {
+	return p.clusterClient
+}
+
+// Providers returns the providers in the inventory of the management cluster.
+func (p *Plugin) Providers(ctx context.Context) ([]clusterctlv1.Provider, error) {
+	if err := p.clusterClient.Proxy().CheckClusterAvailable(ctx); err != nil {
+		return nil, err
+	}
+
+	providerList, err := p.clusterClient.ProviderInventory().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return providerList.Items, nil
+}
+
+// Logger returns the clusterctl logger.
+func (p *Plugin) Logger() logr.Logger {
+	return p.log
+}
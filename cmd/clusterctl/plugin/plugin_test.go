@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+func TestNew(t *testing.T) {
+	g := NewWithT(t)
+
+	configFile := filepath.Join(t.TempDir(), "clusterctl.yaml")
+	g.Expect(os.WriteFile(configFile, []byte("CLUSTERCTL_LOG_LEVEL: \"3\"\nFOO: bar\n"), 0600)).To(Succeed())
+
+	options := Options{}
+	flags := pflag.NewFlagSet("clusterctl-foo", pflag.ContinueOnError)
+	options.AddFlags(flags)
+	g.Expect(flags.Parse([]string{"--config", configFile, "--kubeconfig", "/tmp/kubeconfig", "--kubeconfig-context", "mgmt"})).To(Succeed())
+
+	p, err := New(context.Background(), options)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(p.Kubeconfig()).To(Equal(client.Kubeconfig{Path: "/tmp/kubeconfig", Context: "mgmt"}))
+	g.Expect(p.Config().Variables().Get("FOO")).To(Equal("bar"))
+	g.Expect(p.Client()).ToNot(BeNil())
+	g.Expect(p.ManagementCluster().Kubeconfig()).To(Equal(cluster.Kubeconfig{Path: "/tmp/kubeconfig", Context: "mgmt"}))
+
+	// The log level verbosity is read from the configuration if not set.
+	g.Expect(p.Logger().V(3).Enabled()).To(BeTrue())
+	g.Expect(p.Logger().V(4).Enabled()).To(BeFalse())
+}
+
+func TestNewInvalidLogLevel(t *testing.T) {
+	g := NewWithT(t)
+
+	configFile := filepath.Join(t.TempDir(), "clusterctl.yaml")
+	g.Expect(os.WriteFile(configFile, []byte("CLUSTERCTL_LOG_LEVEL: high\n"), 0600)).To(Succeed())
+
+	_, err := New(context.Background(), Options{ConfigFile: configFile})
+	g.Expect(err).To(MatchError(ContainSubstring("failed to convert CLUSTERCTL_LOG_LEVEL string to an int")))
+}
@@ -50,7 +50,7 @@ func NewClusterClient(ctx context.Context, sourceName string, c client.Client, c
 
 // RESTConfig returns a configuration instance to be used with a Kubernetes client.
 func RESTConfig(ctx context.Context, sourceName string, c client.Reader, cluster client.ObjectKey) (*restclient.Config, error) {
-	kubeConfig, err := kcfg.FromSecret(ctx, c, cluster)
+	kubeConfig, err := kcfg.InternalFromSecret(ctx, c, cluster)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to retrieve kubeconfig secret for Cluster %s/%s", cluster.Namespace, cluster.Name)
 	}
@@ -60,6 +60,10 @@ func RESTConfig(ctx context.Context, sourceName string, c client.Reader, cluster
 		return nil, errors.Wrapf(err, "failed to create REST configuration for Cluster %s/%s", cluster.Namespace, cluster.Name)
 	}
 
+	if err := configureAuth(ctx, c, cluster, restConfig); err != nil {
+		return nil, errors.Wrapf(err, "failed to configure authentication for Cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+
 	restConfig.UserAgent = DefaultClusterAPIUserAgent(sourceName)
 	restConfig.Timeout = defaultClientTimeout
 
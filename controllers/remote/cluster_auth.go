@@ -0,0 +1,356 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	restclient "k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	clientcmdapiv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+const (
+	// ExecAuth is the value of the WorkloadClusterAuthAnnotation making the management cluster authenticate
+	// to the workload cluster using one of the exec credential plugins configured by the operator of the management
+	// cluster, selected by the ExecPluginAuthDataName key of the <cluster>-auth Secret.
+	ExecAuth = "exec"
+
+	// OIDCTokenExchangeAuth is the value of the WorkloadClusterAuthAnnotation making the management cluster
+	// authenticate to the workload cluster using a token obtained by exchanging a subject token at the OAuth 2.0
+	// token exchange (RFC 8693) endpoint configured in the <cluster>-auth Secret; both the endpoint and the subject
+	// token file must be allowed by the operator of the management cluster.
+	OIDCTokenExchangeAuth = "oidc-token-exchange"
+)
+
+const (
+	// ExecPluginAuthDataName is the key of the <cluster>-auth Secret storing the name of the exec credential plugin
+	// to use, among the ones configured in the WorkloadClusterAuthPolicy.
+	ExecPluginAuthDataName = "exec-plugin"
+
+	// TokenURLAuthDataName is the key of the <cluster>-auth Secret storing the URL of the token exchange endpoint;
+	// it must be one of the TokenURLs of the WorkloadClusterAuthPolicy.
+	TokenURLAuthDataName = "token-url"
+
+	// ClientIDAuthDataName is the key of the <cluster>-auth Secret storing the client ID used for the token exchange.
+	ClientIDAuthDataName = "client-id"
+
+	// ClientSecretAuthDataName is the key of the <cluster>-auth Secret storing the optional client secret
+	// used for the token exchange.
+	ClientSecretAuthDataName = "client-secret"
+
+	// AudienceAuthDataName is the key of the <cluster>-auth Secret storing the optional audience of the exchanged token.
+	AudienceAuthDataName = "audience"
+
+	// ScopeAuthDataName is the key of the <cluster>-auth Secret storing the optional scope of the exchanged token.
+	ScopeAuthDataName = "scope"
+
+	// RequestedTokenTypeAuthDataName is the key of the <cluster>-auth Secret storing the optional type of the
+	// exchanged token; it defaults to an ID token.
+	RequestedTokenTypeAuthDataName = "requested-token-type"
+
+	// SubjectTokenFileAuthDataName is the key of the <cluster>-auth Secret storing the path of the token to exchange;
+	// it must be one of the SubjectTokenFiles of the WorkloadClusterAuthPolicy, and it can be omitted if the policy
+	// allows a single subject token file.
+	SubjectTokenFileAuthDataName = "subject-token-file"
+)
+
+const (
+	tokenExchangeGrantType     = "urn:ietf:params:oauth:grant-type:token-exchange"
+	jwtTokenType               = "urn:ietf:params:oauth:token-type:jwt"
+	idTokenType                = "urn:ietf:params:oauth:token-type:id_token"
+	tokenExchangeClientTimeout = 10 * time.Second
+)
+
+// WorkloadClusterAuthPolicy defines the authentication methods the owners of a Cluster can choose from using the
+// WorkloadClusterAuthAnnotation; it is set by the operator of the management cluster, given that the Cluster and
+// the <cluster>-auth Secret are not trusted.
+type WorkloadClusterAuthPolicy struct {
+	// ExecPlugins are the exec credential plugins which can be used, by name.
+	ExecPlugins map[string]*clientcmdapi.ExecConfig
+
+	// TokenURLs are the token exchange endpoints which can be used.
+	TokenURLs []string
+
+	// SubjectTokenFiles are the files storing the subject tokens which can be exchanged.
+	SubjectTokenFiles []string
+}
+
+var (
+	authPolicyLock sync.RWMutex
+	authPolicy     WorkloadClusterAuthPolicy
+)
+
+// SetWorkloadClusterAuthPolicy sets the WorkloadClusterAuthPolicy used when creating the rest.Config of workload clusters.
+// By default no exec credential plugin, token exchange endpoint or subject token file can be used.
+func SetWorkloadClusterAuthPolicy(p WorkloadClusterAuthPolicy) {
+	authPolicyLock.Lock()
+	defer authPolicyLock.Unlock()
+	authPolicy = p
+}
+
+func getWorkloadClusterAuthPolicy() WorkloadClusterAuthPolicy {
+	authPolicyLock.RLock()
+	defer authPolicyLock.RUnlock()
+	return authPolicy
+}
+
+// configureAuth replaces the credentials in the rest.Config of a workload cluster with the authentication
+// configured by the WorkloadClusterAuthAnnotation of the Cluster, if any.
+func configureAuth(ctx context.Context, c client.Reader, cluster client.ObjectKey, config *restclient.Config) error {
+	clusterObj := &clusterv1.Cluster{}
+	if err := c.Get(ctx, cluster, clusterObj); err != nil {
+		// Note: readers whose scheme does not include Cluster API types can only use the kubeconfig Secret.
+		if apierrors.IsNotFound(err) || runtime.IsNotRegisteredError(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get Cluster %s", cluster)
+	}
+	mode, ok := clusterObj.Annotations[clusterv1.WorkloadClusterAuthAnnotation]
+	if !ok {
+		return nil
+	}
+
+	authSecret, err := secret.GetFromNamespacedName(ctx, c, cluster, secret.WorkloadClusterAuth)
+	if err != nil {
+		// Note: the controllers only cache the Secrets with the cluster name label.
+		if apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get %s authentication Secret for Cluster %s: the Secret must exist and have the %s label",
+				mode, cluster, clusterv1.ClusterNameLabel)
+		}
+		return errors.Wrapf(err, "failed to get %s authentication Secret for Cluster %s", mode, cluster)
+	}
+
+	policy := getWorkloadClusterAuthPolicy()
+	switch mode {
+	case ExecAuth:
+		execConfig, err := execConfigFromSecret(policy, cluster, authSecret.Data)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s authentication Secret for Cluster %s", mode, cluster)
+		}
+		clearCredentials(config)
+		config.ExecProvider = execConfig
+	case OIDCTokenExchangeAuth:
+		ts, err := tokenExchangeSourceFromSecret(policy, authSecret.Data)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s authentication Secret for Cluster %s", mode, cluster)
+		}
+		clearCredentials(config)
+		source := oauth2.ReuseTokenSource(nil, ts)
+		config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &oauth2.Transport{Source: source, Base: rt}
+		})
+	default:
+		return errors.Errorf("invalid value %q of the %s annotation of Cluster %s: must be %q or %q",
+			mode, clusterv1.WorkloadClusterAuthAnnotation, cluster, ExecAuth, OIDCTokenExchangeAuth)
+	}
+	return nil
+}
+
+// clearCredentials removes all the credentials from the rest.Config, leaving the server and its CA untouched.
+func clearCredentials(config *restclient.Config) {
+	config.CertData = nil
+	config.KeyData = nil
+	config.CertFile = ""
+	config.KeyFile = ""
+	config.BearerToken = ""
+	config.BearerTokenFile = ""
+	config.Username = ""
+	config.Password = ""
+	config.AuthProvider = nil
+	config.ExecProvider = nil
+}
+
+// ParseExecConfig parses an exec credential plugin configuration, in the format of the exec field of a kubeconfig user.
+func ParseExecConfig(raw []byte) (*clientcmdapi.ExecConfig, error) {
+	in := &clientcmdapiv1.ExecConfig{}
+	if err := yaml.UnmarshalStrict(raw, in); err != nil {
+		return nil, err
+	}
+	if in.Command == "" {
+		return nil, errors.New("command must be set")
+	}
+	if in.APIVersion == "" {
+		return nil, errors.New("apiVersion must be set")
+	}
+
+	out := &clientcmdapi.ExecConfig{
+		Command:            in.Command,
+		Args:               in.Args,
+		APIVersion:         in.APIVersion,
+		InstallHint:        in.InstallHint,
+		ProvideClusterInfo: in.ProvideClusterInfo,
+		// Controllers have no terminal, plugins must never prompt for input.
+		InteractiveMode: clientcmdapi.NeverExecInteractiveMode,
+	}
+	for _, env := range in.Env {
+		out.Env = append(out.Env, clientcmdapi.ExecEnvVar{Name: env.Name, Value: env.Value})
+	}
+	return out, nil
+}
+
+// execConfigFromSecret returns the exec credential plugin of the policy selected in the data of the <cluster>-auth Secret;
+// the plugin gets the namespace and the name of the Cluster in the CLUSTER_NAMESPACE and CLUSTER_NAME environment variables.
+func execConfigFromSecret(policy WorkloadClusterAuthPolicy, cluster client.ObjectKey, data map[string][]byte) (*clientcmdapi.ExecConfig, error) {
+	name := string(data[ExecPluginAuthDataName])
+	if name == "" {
+		return nil, errors.Errorf("missing key %q", ExecPluginAuthDataName)
+	}
+	plugin, ok := policy.ExecPlugins[name]
+	if !ok {
+		return nil, errors.Errorf("key %q: exec credential plugin %q is not allowed", ExecPluginAuthDataName, name)
+	}
+
+	out := plugin.DeepCopy()
+	out.Env = append(out.Env,
+		clientcmdapi.ExecEnvVar{Name: "CLUSTER_NAMESPACE", Value: cluster.Namespace},
+		clientcmdapi.ExecEnvVar{Name: "CLUSTER_NAME", Value: cluster.Name},
+	)
+	return out, nil
+}
+
+// tokenExchangeSource is an oauth2.TokenSource exchanging a subject token for a token accepted by the workload cluster,
+// as defined by OAuth 2.0 Token Exchange (RFC 8693).
+type tokenExchangeSource struct {
+	tokenURL           string
+	clientID           string
+	clientSecret       string
+	audience           string
+	scope              string
+	requestedTokenType string
+	subjectTokenFile   string
+	httpClient         *http.Client
+}
+
+// tokenExchangeSourceFromSecret returns the tokenExchangeSource configured in the data of the <cluster>-auth Secret,
+// ensuring the token exchange endpoint and the subject token file are allowed by the policy.
+func tokenExchangeSourceFromSecret(policy WorkloadClusterAuthPolicy, data map[string][]byte) (*tokenExchangeSource, error) {
+	ts := &tokenExchangeSource{
+		tokenURL:           string(data[TokenURLAuthDataName]),
+		clientID:           string(data[ClientIDAuthDataName]),
+		clientSecret:       string(data[ClientSecretAuthDataName]),
+		audience:           string(data[AudienceAuthDataName]),
+		scope:              string(data[ScopeAuthDataName]),
+		requestedTokenType: string(data[RequestedTokenTypeAuthDataName]),
+		subjectTokenFile:   string(data[SubjectTokenFileAuthDataName]),
+		httpClient:         &http.Client{Timeout: tokenExchangeClientTimeout},
+	}
+	if ts.tokenURL == "" {
+		return nil, errors.Errorf("missing key %q", TokenURLAuthDataName)
+	}
+	if !slices.Contains(policy.TokenURLs, ts.tokenURL) {
+		return nil, errors.Errorf("key %q: token exchange endpoint %q is not allowed", TokenURLAuthDataName, ts.tokenURL)
+	}
+	if ts.clientID == "" {
+		return nil, errors.Errorf("missing key %q", ClientIDAuthDataName)
+	}
+	if ts.requestedTokenType == "" {
+		ts.requestedTokenType = idTokenType
+	}
+	if ts.subjectTokenFile == "" {
+		if len(policy.SubjectTokenFiles) != 1 {
+			return nil, errors.Errorf("missing key %q", SubjectTokenFileAuthDataName)
+		}
+		ts.subjectTokenFile = policy.SubjectTokenFiles[0]
+	}
+	if !slices.Contains(policy.SubjectTokenFiles, ts.subjectTokenFile) {
+		return nil, errors.Errorf("key %q: subject token file %q is not allowed", SubjectTokenFileAuthDataName, ts.subjectTokenFile)
+	}
+	return ts, nil
+}
+
+// Token implements oauth2.TokenSource.
+func (ts *tokenExchangeSource) Token() (*oauth2.Token, error) {
+	subjectToken, err := os.ReadFile(ts.subjectTokenFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read subject token from %s", ts.subjectTokenFile)
+	}
+
+	form := url.Values{
+		"grant_type":           {tokenExchangeGrantType},
+		"subject_token":        {strings.TrimSpace(string(subjectToken))},
+		"subject_token_type":   {jwtTokenType},
+		"requested_token_type": {ts.requestedTokenType},
+	}
+	if ts.audience != "" {
+		form.Set("audience", ts.audience)
+	}
+	if ts.scope != "" {
+		form.Set("scope", ts.scope)
+	}
+	// Public clients identify themselves in the request body, confidential clients authenticate using basic auth.
+	if ts.clientSecret == "" {
+		form.Set("client_id", ts.clientID)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, ts.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create token exchange request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if ts.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(ts.clientID), url.QueryEscape(ts.clientSecret))
+	}
+
+	resp, err := ts.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to exchange token at %s", ts.tokenURL)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read token exchange response from %s", ts.tokenURL)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to exchange token at %s: %s: %s", ts.tokenURL, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	tokenResponse := struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}{}
+	if err := json.Unmarshal(body, &tokenResponse); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse token exchange response from %s", ts.tokenURL)
+	}
+	if tokenResponse.AccessToken == "" {
+		return nil, errors.Errorf("token exchange response from %s does not contain an access_token", ts.tokenURL)
+	}
+
+	token := &oauth2.Token{AccessToken: tokenResponse.AccessToken, TokenType: "Bearer"}
+	if tokenResponse.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(tokenResponse.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
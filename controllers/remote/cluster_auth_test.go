@@ -0,0 +1,253 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+func TestRESTConfigWithWorkloadClusterAuth(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = clusterv1.AddToScheme(scheme)
+
+	kubeconfigSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test1-kubeconfig", Namespace: metav1.NamespaceDefault},
+		Data: map[string][]byte{
+			secret.KubeconfigDataName: []byte(validKubeConfig + `  user:
+    client-certificate-data: Y2VydA==
+    client-key-data: a2V5
+`),
+		},
+	}
+	cluster := func(auth string) *clusterv1.Cluster {
+		c := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test1", Namespace: metav1.NamespaceDefault}}
+		if auth != "" {
+			c.Annotations = map[string]string{clusterv1.WorkloadClusterAuthAnnotation: auth}
+		}
+		return c
+	}
+	authSecret := func(data map[string]string) *corev1.Secret {
+		s := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "test1-auth", Namespace: metav1.NamespaceDefault},
+			Data:       map[string][]byte{},
+		}
+		for k, v := range data {
+			s.Data[k] = []byte(v)
+		}
+		return s
+	}
+
+	t.Run("uses the kubeconfig credentials without annotation", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(kubeconfigSecret, cluster("")).Build()
+		config, err := RESTConfig(ctx, "test-source", c, clusterWithValidKubeConfig)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(config.CertData).To(Equal([]byte("cert")))
+		g.Expect(config.ExecProvider).To(BeNil())
+	})
+
+	t.Run("uses an exec credential plugin allowed by the policy", func(t *testing.T) {
+		g := NewWithT(t)
+
+		plugin, err := ParseExecConfig([]byte(`
+apiVersion: client.authentication.k8s.io/v1
+command: get-token
+args: ["--region", "eu"]
+env:
+- name: FOO
+  value: bar
+`))
+		g.Expect(err).ToNot(HaveOccurred())
+		setWorkloadClusterAuthPolicy(t, WorkloadClusterAuthPolicy{ExecPlugins: map[string]*clientcmdapi.ExecConfig{"token": plugin}})
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(kubeconfigSecret, cluster(ExecAuth), authSecret(map[string]string{
+			ExecPluginAuthDataName: "token",
+		})).Build()
+		config, err := RESTConfig(ctx, "test-source", c, clusterWithValidKubeConfig)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(config.CertData).To(BeEmpty())
+		g.Expect(config.KeyData).To(BeEmpty())
+		g.Expect(config.ExecProvider).To(Equal(&clientcmdapi.ExecConfig{
+			Command: "get-token",
+			Args:    []string{"--region", "eu"},
+			Env: []clientcmdapi.ExecEnvVar{
+				{Name: "FOO", Value: "bar"},
+				{Name: "CLUSTER_NAMESPACE", Value: metav1.NamespaceDefault},
+				{Name: "CLUSTER_NAME", Value: "test1"},
+			},
+			APIVersion:      "client.authentication.k8s.io/v1",
+			InteractiveMode: clientcmdapi.NeverExecInteractiveMode,
+		}))
+		// The plugin of the policy is not modified.
+		g.Expect(plugin.Env).To(HaveLen(1))
+	})
+
+	t.Run("fails with an exec credential plugin not allowed by the policy", func(t *testing.T) {
+		g := NewWithT(t)
+
+		setWorkloadClusterAuthPolicy(t, WorkloadClusterAuthPolicy{})
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(kubeconfigSecret, cluster(ExecAuth), authSecret(map[string]string{
+			ExecPluginAuthDataName: "sh",
+		})).Build()
+		_, err := RESTConfig(ctx, "test-source", c, clusterWithValidKubeConfig)
+		g.Expect(err).To(MatchError(ContainSubstring(`exec credential plugin "sh" is not allowed`)))
+	})
+
+	t.Run("fails if the auth secret is missing", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(kubeconfigSecret, cluster(ExecAuth)).Build()
+		_, err := RESTConfig(ctx, "test-source", c, clusterWithValidKubeConfig)
+		g.Expect(err).To(MatchError(ContainSubstring("not found")))
+	})
+
+	t.Run("fails with an invalid annotation", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(kubeconfigSecret, cluster("basic"), authSecret(nil)).Build()
+		_, err := RESTConfig(ctx, "test-source", c, clusterWithValidKubeConfig)
+		g.Expect(err).To(MatchError(ContainSubstring(`invalid value "basic"`)))
+	})
+
+	t.Run("exchanges the subject token for an access token", func(t *testing.T) {
+		g := NewWithT(t)
+
+		subjectTokenFile := filepath.Join(t.TempDir(), "token")
+		g.Expect(os.WriteFile(subjectTokenFile, []byte("subject-token\n"), 0600)).To(Succeed())
+
+		exchanges := 0
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			exchanges++
+			g.Expect(r.ParseForm()).To(Succeed())
+			g.Expect(r.PostForm.Get("grant_type")).To(Equal(tokenExchangeGrantType))
+			g.Expect(r.PostForm.Get("subject_token")).To(Equal("subject-token"))
+			g.Expect(r.PostForm.Get("subject_token_type")).To(Equal(jwtTokenType))
+			g.Expect(r.PostForm.Get("requested_token_type")).To(Equal(idTokenType))
+			g.Expect(r.PostForm.Get("audience")).To(Equal("test1"))
+			user, password, ok := r.BasicAuth()
+			g.Expect(ok).To(BeTrue())
+			g.Expect(user).To(Equal("capi"))
+			g.Expect(password).To(Equal("secret"))
+			_, _ = w.Write([]byte(`{"access_token":"exchanged-token","token_type":"N_A","expires_in":3600}`))
+		}))
+		defer tokenServer.Close()
+
+		var authorization []string
+		apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = append(authorization, r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		defer apiServer.Close()
+
+		setWorkloadClusterAuthPolicy(t, WorkloadClusterAuthPolicy{TokenURLs: []string{tokenServer.URL}, SubjectTokenFiles: []string{subjectTokenFile}})
+
+		// The subject token file is omitted, given that the policy allows a single one.
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(kubeconfigSecret, cluster(OIDCTokenExchangeAuth), authSecret(map[string]string{
+			TokenURLAuthDataName:     tokenServer.URL,
+			ClientIDAuthDataName:     "capi",
+			ClientSecretAuthDataName: "secret",
+			AudienceAuthDataName:     "test1",
+		})).Build()
+		config, err := RESTConfig(ctx, "test-source", c, clusterWithValidKubeConfig)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(config.CertData).To(BeEmpty())
+
+		httpClient := &http.Client{Transport: config.WrapTransport(http.DefaultTransport)}
+		for i := 0; i < 2; i++ {
+			resp, err := httpClient.Get(apiServer.URL)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.Body.Close()).To(Succeed())
+		}
+		g.Expect(authorization).To(Equal([]string{"Bearer exchanged-token", "Bearer exchanged-token"}))
+		// The exchanged token is reused until it expires.
+		g.Expect(exchanges).To(Equal(1))
+	})
+
+	t.Run("fails if the token url is missing", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(kubeconfigSecret, cluster(OIDCTokenExchangeAuth), authSecret(map[string]string{
+			ClientIDAuthDataName: "capi",
+		})).Build()
+		_, err := RESTConfig(ctx, "test-source", c, clusterWithValidKubeConfig)
+		g.Expect(err).To(MatchError(ContainSubstring(`missing key "token-url"`)))
+	})
+
+	t.Run("fails if the token url is not allowed by the policy", func(t *testing.T) {
+		g := NewWithT(t)
+
+		setWorkloadClusterAuthPolicy(t, WorkloadClusterAuthPolicy{TokenURLs: []string{"https://sts.example.com/token"}, SubjectTokenFiles: []string{"/var/run/secrets/tokens/sts"}})
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(kubeconfigSecret, cluster(OIDCTokenExchangeAuth), authSecret(map[string]string{
+			TokenURLAuthDataName: "https://attacker.example.com/token",
+			ClientIDAuthDataName: "capi",
+		})).Build()
+		_, err := RESTConfig(ctx, "test-source", c, clusterWithValidKubeConfig)
+		g.Expect(err).To(MatchError(ContainSubstring(`token exchange endpoint "https://attacker.example.com/token" is not allowed`)))
+	})
+
+	t.Run("fails if the subject token file is not allowed by the policy", func(t *testing.T) {
+		g := NewWithT(t)
+
+		setWorkloadClusterAuthPolicy(t, WorkloadClusterAuthPolicy{TokenURLs: []string{"https://sts.example.com/token"}, SubjectTokenFiles: []string{"/var/run/secrets/tokens/sts"}})
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(kubeconfigSecret, cluster(OIDCTokenExchangeAuth), authSecret(map[string]string{
+			TokenURLAuthDataName:         "https://sts.example.com/token",
+			ClientIDAuthDataName:         "capi",
+			SubjectTokenFileAuthDataName: "/var/run/secrets/kubernetes.io/serviceaccount/token",
+		})).Build()
+		_, err := RESTConfig(ctx, "test-source", c, clusterWithValidKubeConfig)
+		g.Expect(err).To(MatchError(ContainSubstring(`subject token file "/var/run/secrets/kubernetes.io/serviceaccount/token" is not allowed`)))
+	})
+
+	t.Run("fails if the subject token file is missing and the policy does not allow a single one", func(t *testing.T) {
+		g := NewWithT(t)
+
+		setWorkloadClusterAuthPolicy(t, WorkloadClusterAuthPolicy{TokenURLs: []string{"https://sts.example.com/token"}})
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(kubeconfigSecret, cluster(OIDCTokenExchangeAuth), authSecret(map[string]string{
+			TokenURLAuthDataName: "https://sts.example.com/token",
+			ClientIDAuthDataName: "capi",
+		})).Build()
+		_, err := RESTConfig(ctx, "test-source", c, clusterWithValidKubeConfig)
+		g.Expect(err).To(MatchError(ContainSubstring(`missing key "subject-token-file"`)))
+	})
+}
+
+func setWorkloadClusterAuthPolicy(t *testing.T, p WorkloadClusterAuthPolicy) {
+	t.Helper()
+	SetWorkloadClusterAuthPolicy(p)
+	t.Cleanup(func() { SetWorkloadClusterAuthPolicy(WorkloadClusterAuthPolicy{}) })
+}
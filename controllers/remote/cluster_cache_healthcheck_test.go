@@ -152,6 +152,39 @@ func TestClusterCacheHealthCheck(t *testing.T) {
 			}, 5*time.Second, 1*time.Second).Should(BeTrue())
 		})
 
+		t.Run("with an idle cluster accessor", func(t *testing.T) {
+			g := NewWithT(t)
+			ns := setup(t, g)
+			defer teardown(t, g, ns)
+
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			cct.idleTimeout = time.Minute
+			defer func() { cct.idleTimeout = 0 }()
+			accessor, ok := cct.loadAccessor(testClusterKey)
+			g.Expect(ok).To(BeTrue())
+			accessor.lastUsed.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+
+			httpClient, err := rest.HTTPClientFor(env.Config)
+			g.Expect(err).ToNot(HaveOccurred())
+			go cct.healthCheckCluster(ctx, &healthCheckInput{
+				cluster:            testClusterKey,
+				cfg:                env.Config,
+				httpClient:         httpClient,
+				interval:           testPollInterval,
+				requestTimeout:     testPollTimeout,
+				unhealthyThreshold: testUnhealthyThreshold,
+				path:               "/",
+			})
+
+			// The cluster accessor should be deleted because it has not been used for longer than the idle timeout.
+			g.Eventually(func() bool {
+				_, ok := cct.loadAccessor(testClusterKey)
+				return ok
+			}, 5*time.Second, 1*time.Second).Should(BeFalse())
+		})
+
 		t.Run("during creation of a new cluster accessor", func(t *testing.T) {
 			g := NewWithT(t)
 			ns := setup(t, g)
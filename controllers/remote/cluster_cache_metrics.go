@@ -0,0 +1,303 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	clientCertificateCredentialType = "client-certificate"
+	tokenCredentialType             = "token"
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		connectionUp,
+		healthCheckConsecutiveFailures,
+		requestDuration,
+		requestErrors,
+		credentialsExpirationTimestamp,
+		accessors,
+		idleEvictions,
+	)
+}
+
+var (
+	connectionUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capi_cluster_cache_connection_up",
+		Help: "Whether the connection to the workload cluster is up (1) or down (0).",
+	}, []string{"cluster"})
+
+	healthCheckConsecutiveFailures = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capi_cluster_cache_health_check_consecutive_failures",
+		Help: "Number of consecutive failed health checks of the workload cluster; the connection is dropped when the threshold is reached.",
+	}, []string{"cluster"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "capi_cluster_cache_request_duration_seconds",
+		Help:    "Latency of the calls to the workload cluster API server issued by the cached client, by verb; reads served by the cache are not included.",
+		Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"cluster", "verb"})
+
+	requestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "capi_cluster_cache_request_errors_total",
+		Help: "Number of failed calls to the workload cluster API server issued by the cached client, by verb; reads served by the cache are not included.",
+	}, []string{"cluster", "verb"})
+
+	credentialsExpirationTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capi_cluster_cache_credentials_expiration_timestamp_seconds",
+		Help: "Expiration time, as unix timestamp, of the credentials used to connect to the workload cluster, by type.",
+	}, []string{"cluster", "type"})
+
+	accessors = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "capi_cluster_cache_accessors",
+		Help: "Number of workload clusters for which a client, cache and watches are currently established.",
+	})
+
+	idleEvictions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "capi_cluster_cache_idle_evictions_total",
+		Help: "Number of times the connection to the workload cluster has been torn down because it was not used for longer than the idle timeout.",
+	}, []string{"cluster"})
+)
+
+// recordConnectionUp records that a connection to the workload cluster has been established using the given rest.Config.
+func recordConnectionUp(cluster client.ObjectKey, config *rest.Config) {
+	connectionUp.WithLabelValues(cluster.String()).Set(1)
+	healthCheckConsecutiveFailures.WithLabelValues(cluster.String()).Set(0)
+
+	credentialsExpirationTimestamp.DeletePartialMatch(prometheus.Labels{"cluster": cluster.String()})
+	if expiry, ok := clientCertificateExpiry(config); ok {
+		credentialsExpirationTimestamp.WithLabelValues(cluster.String(), clientCertificateCredentialType).Set(float64(expiry.Unix()))
+	}
+	if expiry, ok := tokenExpiry(config); ok {
+		credentialsExpirationTimestamp.WithLabelValues(cluster.String(), tokenCredentialType).Set(float64(expiry.Unix()))
+	}
+}
+
+// recordConnectionDown records that the connection to the workload cluster has been dropped.
+func recordConnectionDown(cluster client.ObjectKey) {
+	connectionUp.WithLabelValues(cluster.String()).Set(0)
+}
+
+// recordHealthCheckFailures records the number of consecutive failed health checks of the workload cluster.
+func recordHealthCheckFailures(cluster client.ObjectKey, failures int) {
+	healthCheckConsecutiveFailures.WithLabelValues(cluster.String()).Set(float64(failures))
+}
+
+// recordAccessors records the number of workload clusters for which a connection is established.
+func recordAccessors(count int) {
+	accessors.Set(float64(count))
+}
+
+// recordIdleEviction records that the connection to the workload cluster has been torn down for being idle.
+func recordIdleEviction(cluster client.ObjectKey) {
+	idleEvictions.WithLabelValues(cluster.String()).Inc()
+}
+
+// deleteClusterMetrics deletes all the metrics of a workload cluster, e.g. after the Cluster has been deleted.
+func deleteClusterMetrics(cluster client.ObjectKey) {
+	labels := prometheus.Labels{"cluster": cluster.String()}
+	connectionUp.DeletePartialMatch(labels)
+	healthCheckConsecutiveFailures.DeletePartialMatch(labels)
+	requestDuration.DeletePartialMatch(labels)
+	requestErrors.DeletePartialMatch(labels)
+	credentialsExpirationTimestamp.DeletePartialMatch(labels)
+	idleEvictions.DeletePartialMatch(labels)
+}
+
+// clientCertificateExpiry returns the NotAfter of the client certificate in the rest.Config, if any.
+func clientCertificateExpiry(config *rest.Config) (time.Time, bool) {
+	data := config.CertData
+	if len(data) == 0 && config.CertFile != "" {
+		var err error
+		if data, err = os.ReadFile(config.CertFile); err != nil {
+			return time.Time{}, false
+		}
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return time.Time{}, false
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return cert.NotAfter, true
+}
+
+// tokenExpiry returns the expiration of the bearer token in the rest.Config, if any and if it is a JWT with an exp claim.
+func tokenExpiry(config *rest.Config) (time.Time, bool) {
+	parts := strings.Split(config.BearerToken, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	claims := struct {
+		Exp int64 `json:"exp"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
+
+// newClientWithMetrics returns a new client which records latency and errors by verb of the calls reaching
+// the workload cluster API server, i.e. all the writes, including the ones to subresources, and the reads of
+// the uncached objects; reads served by the cache are not recorded.
+func newClientWithMetrics(c client.Client, cluster client.ObjectKey, uncachedObjects []client.Object) client.Client {
+	uncachedGVKs := map[schema.GroupVersionKind]struct{}{}
+	for _, obj := range uncachedObjects {
+		// Note: creating the client already failed if the GroupVersionKind of an uncached object can't be determined.
+		if gvk, err := c.GroupVersionKindFor(obj); err == nil {
+			uncachedGVKs[gvk] = struct{}{}
+		}
+	}
+	return clientWithMetrics{
+		Client:       c,
+		cluster:      cluster.String(),
+		uncachedGVKs: uncachedGVKs,
+	}
+}
+
+type clientWithMetrics struct {
+	client.Client
+	cluster      string
+	uncachedGVKs map[schema.GroupVersionKind]struct{}
+}
+
+var _ client.Client = &clientWithMetrics{}
+
+func (c clientWithMetrics) observe(verb string, start time.Time, err error) {
+	requestDuration.WithLabelValues(c.cluster, verb).Observe(time.Since(start).Seconds())
+	if err != nil {
+		requestErrors.WithLabelValues(c.cluster, verb).Inc()
+	}
+}
+
+// isUncached returns true if reads of the object bypass the cache, using the same logic of
+// the controller-runtime client for objects in the DisableFor cache option.
+func (c clientWithMetrics) isUncached(obj runtime.Object) bool {
+	gvk, err := c.GroupVersionKindFor(obj)
+	if err != nil {
+		return false
+	}
+	if meta.IsListType(obj) {
+		gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	}
+	_, ok := c.uncachedGVKs[gvk]
+	return ok
+}
+
+func (c clientWithMetrics) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) (err error) {
+	if !c.isUncached(obj) {
+		return c.Client.Get(ctx, key, obj, opts...)
+	}
+	defer func(start time.Time) { c.observe("get", start, err) }(time.Now())
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func (c clientWithMetrics) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) (err error) {
+	if !c.isUncached(list) {
+		return c.Client.List(ctx, list, opts...)
+	}
+	defer func(start time.Time) { c.observe("list", start, err) }(time.Now())
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c clientWithMetrics) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) (err error) {
+	defer func(start time.Time) { c.observe("create", start, err) }(time.Now())
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c clientWithMetrics) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) (err error) {
+	defer func(start time.Time) { c.observe("update", start, err) }(time.Now())
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c clientWithMetrics) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) (err error) {
+	defer func(start time.Time) { c.observe("patch", start, err) }(time.Now())
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c clientWithMetrics) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) (err error) {
+	defer func(start time.Time) { c.observe("delete", start, err) }(time.Now())
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c clientWithMetrics) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) (err error) {
+	defer func(start time.Time) { c.observe("deletecollection", start, err) }(time.Now())
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c clientWithMetrics) Status() client.SubResourceWriter {
+	return c.SubResource("status")
+}
+
+func (c clientWithMetrics) SubResource(subResource string) client.SubResourceClient {
+	return subResourceClientWithMetrics{
+		SubResourceClient: c.Client.SubResource(subResource),
+		client:            c,
+	}
+}
+
+// subResourceClientWithMetrics records latency and errors by verb of the calls to subresources, which always reach
+// the workload cluster API server.
+type subResourceClientWithMetrics struct {
+	client.SubResourceClient
+	client clientWithMetrics
+}
+
+var _ client.SubResourceClient = &subResourceClientWithMetrics{}
+
+func (c subResourceClientWithMetrics) Get(ctx context.Context, obj, subResource client.Object, opts ...client.SubResourceGetOption) (err error) {
+	defer func(start time.Time) { c.client.observe("get", start, err) }(time.Now())
+	return c.SubResourceClient.Get(ctx, obj, subResource, opts...)
+}
+
+func (c subResourceClientWithMetrics) Create(ctx context.Context, obj, subResource client.Object, opts ...client.SubResourceCreateOption) (err error) {
+	defer func(start time.Time) { c.client.observe("create", start, err) }(time.Now())
+	return c.SubResourceClient.Create(ctx, obj, subResource, opts...)
+}
+
+func (c subResourceClientWithMetrics) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) (err error) {
+	defer func(start time.Time) { c.client.observe("update", start, err) }(time.Now())
+	return c.SubResourceClient.Update(ctx, obj, opts...)
+}
+
+func (c subResourceClientWithMetrics) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) (err error) {
+	defer func(start time.Time) { c.client.observe("patch", start, err) }(time.Now())
+	return c.SubResourceClient.Patch(ctx, obj, patch, opts...)
+}
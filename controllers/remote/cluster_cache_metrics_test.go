@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api/util/certs"
+)
+
+func TestRecordConnectionMetrics(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := client.ObjectKey{Namespace: "default", Name: "test-metrics"}
+	defer deleteClusterMetrics(cluster)
+
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, exp.Unix())))
+	config := &rest.Config{BearerToken: "header." + payload + ".signature"}
+
+	recordConnectionUp(cluster, config)
+	g.Expect(testutil.ToFloat64(connectionUp.WithLabelValues(cluster.String()))).To(Equal(1.0))
+	g.Expect(testutil.ToFloat64(credentialsExpirationTimestamp.WithLabelValues(cluster.String(), tokenCredentialType))).To(Equal(float64(exp.Unix())))
+
+	recordHealthCheckFailures(cluster, 3)
+	g.Expect(testutil.ToFloat64(healthCheckConsecutiveFailures.WithLabelValues(cluster.String()))).To(Equal(3.0))
+
+	recordConnectionDown(cluster)
+	g.Expect(testutil.ToFloat64(connectionUp.WithLabelValues(cluster.String()))).To(Equal(0.0))
+
+	recordIdleEviction(cluster)
+	g.Expect(testutil.ToFloat64(idleEvictions.WithLabelValues(cluster.String()))).To(Equal(1.0))
+
+	deleteClusterMetrics(cluster)
+	g.Expect(testutil.CollectAndCount(connectionUp)).To(Equal(0))
+	g.Expect(testutil.CollectAndCount(credentialsExpirationTimestamp)).To(Equal(0))
+	g.Expect(testutil.CollectAndCount(idleEvictions)).To(Equal(0))
+}
+
+func TestClientCertificateExpiry(t *testing.T) {
+	g := NewWithT(t)
+
+	key, err := certs.NewPrivateKey()
+	g.Expect(err).ToNot(HaveOccurred())
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "admin"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour).Truncate(time.Second),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	g.Expect(err).ToNot(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	expiry, ok := clientCertificateExpiry(&rest.Config{TLSClientConfig: rest.TLSClientConfig{CertData: certs.EncodeCertPEM(cert)}})
+	g.Expect(ok).To(BeTrue())
+	g.Expect(expiry).To(Equal(cert.NotAfter))
+
+	_, ok = clientCertificateExpiry(&rest.Config{})
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestTokenExpiry(t *testing.T) {
+	g := NewWithT(t)
+
+	_, ok := tokenExpiry(&rest.Config{BearerToken: "not-a-jwt"})
+	g.Expect(ok).To(BeFalse())
+
+	_, ok = tokenExpiry(&rest.Config{BearerToken: "header." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"foo"}`)) + ".signature"})
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestClientWithMetrics(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := client.ObjectKey{Namespace: "default", Name: "test-client-metrics"}
+	defer deleteClusterMetrics(cluster)
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	c := newClientWithMetrics(fake.NewClientBuilder().WithObjects(node).WithStatusSubresource(&corev1.Node{}).Build(), cluster, []client.Object{&corev1.ConfigMap{}})
+
+	// Writes are recorded.
+	g.Expect(c.Create(context.Background(), &corev1.ConfigMap{})).ToNot(Succeed())
+	g.Expect(testutil.ToFloat64(requestErrors.WithLabelValues(cluster.String(), "create"))).To(Equal(1.0))
+
+	// Reads of uncached objects are recorded, while reads served by the cache are not.
+	g.Expect(c.List(context.Background(), &corev1.ConfigMapList{})).To(Succeed())
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(node), node)).To(Succeed())
+	g.Expect(c.List(context.Background(), &corev1.SecretList{})).To(Succeed())
+	g.Expect(testutil.CollectAndCount(requestDuration)).To(Equal(2))
+	g.Expect(testutil.ToFloat64(requestErrors.WithLabelValues(cluster.String(), "list"))).To(Equal(0.0))
+
+	// Writes to subresources are recorded.
+	original := node.DeepCopy()
+	node.Status.Phase = corev1.NodeRunning
+	g.Expect(c.Status().Patch(context.Background(), node, client.MergeFrom(original))).To(Succeed())
+	g.Expect(c.SubResource("status").Update(context.Background(), &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "does-not-exist"}})).ToNot(Succeed())
+	g.Expect(testutil.CollectAndCount(requestDuration)).To(Equal(4))
+	g.Expect(testutil.ToFloat64(requestErrors.WithLabelValues(cluster.String(), "update"))).To(Equal(1.0))
+}
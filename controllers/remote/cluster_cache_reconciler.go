@@ -27,11 +27,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/internal/util/metrics"
 	"sigs.k8s.io/cluster-api/util/predicates"
+	"sigs.k8s.io/cluster-api/util/throttle"
 )
 
 // ClusterCacheReconciler is responsible for stopping remote cluster caches when
-// the cluster for the remote cache is being deleted.
+// the cluster for the remote cache is being deleted; it also deletes the per-cluster
+// reconcile metrics and limiters of the cluster.
 type ClusterCacheReconciler struct {
 	Client  client.Client
 	Tracker *ClusterCacheTracker
@@ -74,6 +77,9 @@ func (r *ClusterCacheReconciler) Reconcile(ctx context.Context, req reconcile.Re
 	log.V(2).Info("Cluster no longer exists")
 
 	r.Tracker.deleteAccessor(ctx, req.NamespacedName)
+	deleteClusterMetrics(req.NamespacedName)
+	metrics.ForgetCluster(req.NamespacedName)
+	throttle.ForgetCluster(req.NamespacedName)
 
 	return reconcile.Result{}, nil
 }
@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -49,6 +50,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/cachelimit"
 	"sigs.k8s.io/cluster-api/util/certs"
 	"sigs.k8s.io/cluster-api/util/conditions"
 )
@@ -61,6 +63,9 @@ const (
 	clusterCacheControllerName    = "cluster-cache-tracker"
 )
 
+// errClusterIdle is returned by the health check when the clusterAccessor has not been used for longer than the idle timeout.
+var errClusterIdle = errors.New("cluster accessor is idle")
+
 // ErrClusterLocked is returned in methods that require cluster-level locking
 // if the cluster is already locked by another concurrent call.
 var ErrClusterLocked = errors.New("cluster is locked already")
@@ -70,6 +75,9 @@ type ClusterCacheTracker struct {
 	log                   logr.Logger
 	clientUncachedObjects []client.Object
 
+	// cacheByObject restricts what is cached for specific types.
+	cacheByObject map[client.Object]cache.ByObject
+
 	client client.Client
 
 	// SecretCachingClient is a client which caches secrets.
@@ -89,6 +97,10 @@ type ClusterCacheTracker struct {
 
 	indexes []Index
 
+	// idleTimeout is the duration after which clusterAccessors which have not been used are deleted.
+	// If zero, clusterAccessors are never deleted for being idle.
+	idleTimeout time.Duration
+
 	// controllerName is the name of the controller.
 	// This is used to calculate the user agent string.
 	controllerName string
@@ -118,10 +130,21 @@ type ClusterCacheTrackerOptions struct {
 	ClientUncachedObjects []client.Object
 	Indexes               []Index
 
+	// CacheByObject restricts what is cached for specific types, e.g. to cache only the Pods
+	// in a namespace when reading them through the cache returned by GetCache.
+	CacheByObject map[client.Object]cache.ByObject
+
 	// ControllerName is the name of the controller.
 	// This is used to calculate the user agent string.
 	// If not set, it defaults to "cluster-cache-tracker".
 	ControllerName string
+
+	// IdleTimeout is the duration after which the client, cache and watches of a workload cluster which
+	// have not been used by any controller are torn down; they are re-created on the next use.
+	// Note: Events of the watches of a workload cluster are not delivered while it is idle, so controllers
+	// relying on them must reconcile periodically with a shorter interval than IdleTimeout.
+	// If not set, connections to workload clusters are never torn down for being idle.
+	IdleTimeout time.Duration
 }
 
 func setDefaultOptions(opts *ClusterCacheTrackerOptions) {
@@ -170,12 +193,14 @@ func NewClusterCacheTracker(manager ctrl.Manager, options ClusterCacheTrackerOpt
 		controllerPodMetadata: controllerPodMetadata,
 		log:                   *options.Log,
 		clientUncachedObjects: options.ClientUncachedObjects,
+		cacheByObject:         options.CacheByObject,
 		client:                manager.GetClient(),
 		secretCachingClient:   options.SecretCachingClient,
 		scheme:                manager.GetScheme(),
 		clusterAccessors:      make(map[client.ObjectKey]*clusterAccessor),
 		clusterLock:           newKeyedMutex(),
 		indexes:               options.Indexes,
+		idleTimeout:           options.IdleTimeout,
 	}, nil
 }
 
@@ -194,6 +219,18 @@ func (t *ClusterCacheTracker) GetReader(ctx context.Context, cluster client.Obje
 	return t.GetClient(ctx, cluster)
 }
 
+// GetCache returns the cache for the given cluster.
+// Reading an object from the cache starts an informer for its type, unless the type is
+// already watched; types not watched should be restricted with the CacheByObject option.
+func (t *ClusterCacheTracker) GetCache(ctx context.Context, cluster client.ObjectKey) (cache.Cache, error) {
+	accessor, err := t.getClusterAccessor(ctx, cluster, t.indexes...)
+	if err != nil {
+		return nil, err
+	}
+
+	return accessor.cache, nil
+}
+
 // GetRESTConfig returns a cached REST config for the given cluster.
 func (t *ClusterCacheTracker) GetRESTConfig(ctc context.Context, cluster client.ObjectKey) (*rest.Config, error) {
 	accessor, err := t.getClusterAccessor(ctc, cluster, t.indexes...)
@@ -221,6 +258,19 @@ type clusterAccessor struct {
 	watches                  sets.Set[string]
 	config                   *rest.Config
 	etcdClientCertificateKey *rsa.PrivateKey
+
+	// lastUsed is the time, as unix nano, the clusterAccessor has been used for the last time.
+	lastUsed atomic.Int64
+}
+
+// touch records that the clusterAccessor is being used.
+func (a *clusterAccessor) touch() {
+	a.lastUsed.Store(time.Now().UnixNano())
+}
+
+// idleFor returns the time elapsed since the clusterAccessor has been used for the last time.
+func (a *clusterAccessor) idleFor() time.Duration {
+	return time.Since(time.Unix(0, a.lastUsed.Load()))
 }
 
 // clusterAccessorExists returns true if a clusterAccessor exists for cluster.
@@ -247,6 +297,7 @@ func (t *ClusterCacheTracker) storeAccessor(cluster client.ObjectKey, accessor *
 	defer t.clusterAccessorsLock.Unlock()
 
 	t.clusterAccessors[cluster] = accessor
+	recordAccessors(len(t.clusterAccessors))
 }
 
 // getClusterAccessor returns a clusterAccessor for cluster.
@@ -259,6 +310,7 @@ func (t *ClusterCacheTracker) getClusterAccessor(ctx context.Context, cluster cl
 
 	// If the clusterAccessor already exists, return early.
 	if accessor, ok := t.loadAccessor(cluster); ok {
+		accessor.touch()
 		return accessor, nil
 	}
 
@@ -274,6 +326,7 @@ func (t *ClusterCacheTracker) getClusterAccessor(ctx context.Context, cluster cl
 	// Until we got the cluster lock a different goroutine might have initialized the clusterAccessor
 	// for this cluster successfully already. If this is the case we return it.
 	if accessor, ok := t.loadAccessor(cluster); ok {
+		accessor.touch()
 		return accessor, nil
 	}
 
@@ -285,6 +338,7 @@ func (t *ClusterCacheTracker) getClusterAccessor(ctx context.Context, cluster cl
 	}
 
 	log.V(4).Info("Storing new cluster accessor")
+	accessor.touch()
 	t.storeAccessor(cluster, accessor)
 	return accessor, nil
 }
@@ -361,6 +415,8 @@ func (t *ClusterCacheTracker) newClusterAccessor(ctx context.Context, cluster cl
 		return nil, errors.Wrapf(err, "error creating etcd client key for remote cluster %q", cluster.String())
 	}
 
+	recordConnectionUp(cluster, config)
+
 	return &clusterAccessor{
 		cache:                    cachedClient.Cache,
 		config:                   config,
@@ -448,8 +504,9 @@ func (t *ClusterCacheTracker) createCachedClient(ctx context.Context, config *re
 		HTTPClient: httpClient,
 		Scheme:     t.scheme,
 		Mapper:     mapper,
+		ByObject:   t.cacheByObject,
 	}
-	remoteCache, err := cache.New(config, cacheOptions)
+	remoteCache, err := cachelimit.NewCacheFunc(cluster.String())(config, cacheOptions)
 	if err != nil {
 		return nil, errors.Wrapf(err, "error creating cached client for remote cluster %q: error creating cache", cluster.String())
 	}
@@ -491,6 +548,7 @@ func (t *ClusterCacheTracker) createCachedClient(ctx context.Context, config *re
 	defer cacheSyncCtxCancel()
 	if !cache.WaitForCacheSync(cacheSyncCtx) {
 		cache.Stop()
+		cachelimit.Forget(cluster.String())
 		return nil, fmt.Errorf("failed waiting for cache for remote cluster %v to sync: %w", cluster, cacheCtx.Err())
 	}
 
@@ -501,6 +559,9 @@ func (t *ClusterCacheTracker) createCachedClient(ctx context.Context, config *re
 	// It should be reasonable to have Get and List calls timeout within the duration configured in the restConfig.
 	cachedClient = newClientWithTimeout(cachedClient, config.Timeout)
 
+	// Wrap the client with a client that records latency and errors of the calls reaching the workload cluster API server.
+	cachedClient = newClientWithMetrics(cachedClient, cluster, t.clientUncachedObjects)
+
 	// Start cluster healthcheck!!!
 	go t.healthCheckCluster(cacheCtx, &healthCheckInput{
 		cluster:    cluster,
@@ -514,6 +575,13 @@ func (t *ClusterCacheTracker) createCachedClient(ctx context.Context, config *re
 	}, nil
 }
 
+// ResetAccessor removes the clusterAccessor of the given Cluster, if any, so the next call accessing the Cluster
+// creates a new one reading the kubeconfig Secret again, e.g. after its credentials have been rotated.
+// NOTE: The watches on the Cluster are stopped; controllers re-create them when calling Watch.
+func (t *ClusterCacheTracker) ResetAccessor(ctx context.Context, cluster client.ObjectKey) {
+	t.deleteAccessor(ctx, cluster)
+}
+
 // deleteAccessor stops a clusterAccessor's cache and removes the clusterAccessor from the tracker.
 func (t *ClusterCacheTracker) deleteAccessor(_ context.Context, cluster client.ObjectKey) {
 	t.clusterAccessorsLock.Lock()
@@ -528,9 +596,12 @@ func (t *ClusterCacheTracker) deleteAccessor(_ context.Context, cluster client.O
 	log.V(2).Info("Deleting clusterAccessor")
 	log.V(4).Info("Stopping cache")
 	a.cache.Stop()
+	cachelimit.Forget(cluster.String())
 	log.V(4).Info("Cache stopped")
 
 	delete(t.clusterAccessors, cluster)
+	recordConnectionDown(cluster)
+	recordAccessors(len(t.clusterAccessors))
 }
 
 // Watcher is a scoped-down interface from Controller that only knows how to watch.
@@ -642,6 +713,12 @@ func (t *ClusterCacheTracker) healthCheckCluster(ctx context.Context, in *health
 			return false, restClientErr
 		}
 
+		// If the clusterAccessor has not been used for longer than the idle timeout, tear it down;
+		// it will be re-created on the next use.
+		if accessor, ok := t.loadAccessor(in.cluster); ok && t.idleTimeout > 0 && accessor.idleFor() > t.idleTimeout {
+			return false, errClusterIdle
+		}
+
 		cluster := &clusterv1.Cluster{}
 		if err := t.client.Get(ctx, in.cluster, cluster); err != nil {
 			if apierrors.IsNotFound(err) {
@@ -684,6 +761,7 @@ func (t *ClusterCacheTracker) healthCheckCluster(ctx context.Context, in *health
 		} else {
 			unhealthyCount = 0
 		}
+		recordHealthCheckFailures(in.cluster, unhealthyCount)
 
 		if unhealthyCount >= in.unhealthyThreshold {
 			// Cluster is now considered unhealthy.
@@ -699,7 +777,11 @@ func (t *ClusterCacheTracker) healthCheckCluster(ctx context.Context, in *health
 	// cache context into wait.PollUntilContextCancel).
 	// NB. Log all errors that occurred even if this error might just be from a cancel of the cache context
 	// when the cache is stopped. Logging an error in this case is not a problem and makes debugging easier.
-	if err != nil {
+	switch {
+	case errors.Is(err, errClusterIdle):
+		t.log.V(2).Info("Deleting idle clusterAccessor", "Cluster", klog.KRef(in.cluster.Namespace, in.cluster.Name), "idleTimeout", t.idleTimeout)
+		recordIdleEviction(in.cluster)
+	case err != nil:
 		t.log.Error(err, "Error health checking cluster", "Cluster", klog.KRef(in.cluster.Namespace, in.cluster.Name))
 	}
 	// Ensure in any case that the accessor is deleted (even if it is a no-op).
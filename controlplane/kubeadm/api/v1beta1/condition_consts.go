@@ -54,6 +54,10 @@ const (
 	// RollingUpdateInProgressReason (Severity=Warning) documents a KubeadmControlPlane object executing a
 	// rolling upgrade for aligning the machines spec to the desired state.
 	RollingUpdateInProgressReason = "RollingUpdateInProgress"
+
+	// RolloutDeferredReason (Severity=Info) documents a KubeadmControlPlane object deferring the rolling upgrade
+	// for aligning the machines spec to the desired state until the next rollout window of the Cluster starts.
+	RolloutDeferredReason = "RolloutDeferred"
 )
 
 const (
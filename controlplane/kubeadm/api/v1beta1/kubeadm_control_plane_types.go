@@ -117,6 +117,16 @@ type KubeadmControlPlaneSpec struct {
 	// The RemediationStrategy that controls how control plane machine remediation happens.
 	// +optional
 	RemediationStrategy *RemediationStrategy `json:"remediationStrategy,omitempty"`
+
+	// FailureDomainSelectionStrategy is the strategy used to select the failure domains
+	// control plane machines are created in and deleted from.
+	// Balanced (default) keeps the same number of machines in every failure domain;
+	// Weighted spreads machines proportionally to the capacity hinted by the "cluster.x-k8s.io/weight"
+	// attribute of each failure domain; Sticky always places the n-th up-to-date machine in the same failure domain,
+	// e.g. the machines replacing each other during rollouts, but does not guarantee machines are spread across failure domains.
+	// +optional
+	// +kubebuilder:validation:Enum=Balanced;Weighted;Sticky
+	FailureDomainSelectionStrategy FailureDomainSelectionStrategyType `json:"failureDomainSelectionStrategy,omitempty"`
 }
 
 // KubeadmControlPlaneMachineTemplate defines the template for Machines
@@ -149,6 +159,20 @@ type KubeadmControlPlaneMachineTemplate struct {
 	NodeDeletionTimeout *metav1.Duration `json:"nodeDeletionTimeout,omitempty"`
 }
 
+// FailureDomainSelectionStrategyType defines the strategy used to select the failure domains of control plane machines.
+type FailureDomainSelectionStrategyType string
+
+const (
+	// BalancedFailureDomainSelectionStrategyType keeps the same number of machines in every failure domain.
+	BalancedFailureDomainSelectionStrategyType FailureDomainSelectionStrategyType = "Balanced"
+
+	// WeightedFailureDomainSelectionStrategyType spreads machines proportionally to the capacity of each failure domain.
+	WeightedFailureDomainSelectionStrategyType FailureDomainSelectionStrategyType = "Weighted"
+
+	// StickyFailureDomainSelectionStrategyType always places the n-th up-to-date machine in the same failure domain.
+	StickyFailureDomainSelectionStrategyType FailureDomainSelectionStrategyType = "Sticky"
+)
+
 // RolloutBefore describes when a rollout should be performed on the KCP machines.
 type RolloutBefore struct {
 	// CertificatesExpiryDays indicates a rollout needs to be performed if the
@@ -101,6 +101,16 @@ type KubeadmControlPlaneTemplateResourceSpec struct {
 	// The RemediationStrategy that controls how control plane machine remediation happens.
 	// +optional
 	RemediationStrategy *RemediationStrategy `json:"remediationStrategy,omitempty"`
+
+	// FailureDomainSelectionStrategy is the strategy used to select the failure domains
+	// control plane machines are created in and deleted from.
+	// Balanced (default) keeps the same number of machines in every failure domain;
+	// Weighted spreads machines proportionally to the capacity hinted by the "cluster.x-k8s.io/weight"
+	// attribute of each failure domain; Sticky always places the n-th up-to-date machine in the same failure domain,
+	// e.g. the machines replacing each other during rollouts, but does not guarantee machines are spread across failure domains.
+	// +optional
+	// +kubebuilder:validation:Enum=Balanced;Weighted;Sticky
+	FailureDomainSelectionStrategy FailureDomainSelectionStrategyType `json:"failureDomainSelectionStrategy,omitempty"`
 }
 
 // KubeadmControlPlaneTemplateMachineTemplate defines the template for Machines
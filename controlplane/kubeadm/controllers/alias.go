@@ -26,6 +26,7 @@ import (
 
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	kubeadmcontrolplanecontrollers "sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/controllers"
+	"sigs.k8s.io/cluster-api/util/certs"
 )
 
 // KubeadmControlPlaneReconciler reconciles a KubeadmControlPlane object.
@@ -39,16 +40,37 @@ type KubeadmControlPlaneReconciler struct {
 
 	// WatchFilterValue is the label value used to filter events prior to reconciliation.
 	WatchFilterValue string
+
+	// CASigner, if set, is used to issue the cluster certificate authorities as intermediate CAs
+	// signed by an external signer, so the root CA keys are never stored in the management cluster.
+	CASigner certs.Signer
+
+	// KubeconfigClientCertTTL is the lifespan of the client certificate in the Kubeconfig generated for the cluster;
+	// if not set, certs.DefaultCertDuration is used. The Kubeconfig is regenerated by the first reconciliation after
+	// half of the TTL has elapsed, so the TTL should be significantly longer than the sync period.
+	KubeconfigClientCertTTL time.Duration
+
+	// WatchControlPlaneComponents enables watching the control plane Nodes and the static pods of the control plane
+	// components in the workload cluster instead of polling for their health.
+	WatchControlPlaneComponents bool
+
+	// HealthCheckPollInterval is how often the health of the control plane Nodes and components is checked
+	// while it is not healthy and they are not watched.
+	HealthCheckPollInterval time.Duration
 }
 
 // SetupWithManager sets up the reconciler with the Manager.
 func (r *KubeadmControlPlaneReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
 	return (&kubeadmcontrolplanecontrollers.KubeadmControlPlaneReconciler{
-		Client:              r.Client,
-		SecretCachingClient: r.SecretCachingClient,
-		Tracker:             r.Tracker,
-		EtcdDialTimeout:     r.EtcdDialTimeout,
-		EtcdCallTimeout:     r.EtcdCallTimeout,
-		WatchFilterValue:    r.WatchFilterValue,
+		Client:                      r.Client,
+		SecretCachingClient:         r.SecretCachingClient,
+		Tracker:                     r.Tracker,
+		EtcdDialTimeout:             r.EtcdDialTimeout,
+		EtcdCallTimeout:             r.EtcdCallTimeout,
+		WatchFilterValue:            r.WatchFilterValue,
+		CASigner:                    r.CASigner,
+		KubeconfigClientCertTTL:     r.KubeconfigClientCertTTL,
+		WatchControlPlaneComponents: r.WatchControlPlaneComponents,
+		HealthCheckPollInterval:     r.HealthCheckPollInterval,
 	}).SetupWithManager(ctx, mgr, options)
 }
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/rest"
@@ -34,6 +35,7 @@ import (
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/collections"
 	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/cluster-api/util/tracing"
 )
 
 const (
@@ -57,6 +59,11 @@ type Management struct {
 	Tracker             *remote.ClusterCacheTracker
 	EtcdDialTimeout     time.Duration
 	EtcdCallTimeout     time.Duration
+
+	// CacheStaticPods instructs the workload cluster to read the static pods of the control plane components
+	// from the ClusterCacheTracker's cache instead of the API server, falling back to the API server on errors.
+	// The cache of the Pods should be restricted to the kube-system namespace using the CacheByObject option of the ClusterCacheTracker.
+	CacheStaticPods bool
 }
 
 // RemoteClusterConnectionError represents a failure to connect to a remote cluster.
@@ -102,7 +109,13 @@ func (m *Management) GetMachinePoolsForCluster(ctx context.Context, cluster *clu
 
 // GetWorkloadCluster builds a cluster object.
 // The cluster comes with an etcd client generator to connect to any etcd pod living on a managed machine.
-func (m *Management) GetWorkloadCluster(ctx context.Context, clusterKey client.ObjectKey) (WorkloadCluster, error) {
+func (m *Management) GetWorkloadCluster(ctx context.Context, clusterKey client.ObjectKey) (_ WorkloadCluster, reterr error) {
+	ctx, span := tracing.Start(ctx, "Management.GetWorkloadCluster",
+		attribute.String("k8s.namespace.name", clusterKey.Namespace),
+		attribute.String("k8s.cluster.name", clusterKey.Name),
+	)
+	defer func() { tracing.End(span, reterr) }()
+
 	// TODO(chuckha): Inject this dependency.
 	// TODO(chuckha): memoize this function. The workload client only exists as long as a reconciliation loop.
 	restConfig, err := m.Tracker.GetRESTConfig(ctx, clusterKey)
@@ -117,6 +130,14 @@ func (m *Management) GetWorkloadCluster(ctx context.Context, clusterKey client.O
 		return nil, &RemoteClusterConnectionError{Name: clusterKey.String(), Err: err}
 	}
 
+	var staticPodReader client.Reader
+	if m.CacheStaticPods {
+		staticPodReader, err = m.Tracker.GetCache(ctx, clusterKey)
+		if err != nil {
+			return nil, &RemoteClusterConnectionError{Name: clusterKey.String(), Err: err}
+		}
+	}
+
 	// Retrieves the etcd CA key Pair
 	crtData, keyData, err := m.getEtcdCAKeyPair(ctx, clusterKey)
 	if err != nil {
@@ -157,6 +178,7 @@ func (m *Management) GetWorkloadCluster(ctx context.Context, clusterKey client.O
 	return &Workload{
 		restConfig:          restConfig,
 		Client:              c,
+		staticPodReader:     staticPodReader,
 		CoreDNSMigrator:     &CoreDNSMigrator{},
 		etcdClientGenerator: NewEtcdClientGenerator(restConfig, tlsConfig, m.EtcdDialTimeout, m.EtcdCallTimeout),
 	}, nil
@@ -187,7 +209,10 @@ func (m *Management) getEtcdCAKeyPair(ctx context.Context, clusterKey client.Obj
 	if !ok {
 		return nil, nil, errors.Errorf("etcd tls crt does not exist for cluster %s/%s", clusterKey.Namespace, clusterKey.Name)
 	}
-	keyData := etcdCASecret.Data[secret.TLSKeyDataName]
+	keyData, _, err := secret.KeyData(ctx, etcdCASecret)
+	if err != nil {
+		return nil, nil, err
+	}
 	return crtData, keyData, nil
 }
 
@@ -204,7 +229,10 @@ func (m *Management) getAPIServerEtcdClientCert(ctx context.Context, clusterKey
 	if !ok {
 		return tls.Certificate{}, errors.Errorf("etcd tls crt does not exist for cluster %s/%s", clusterKey.Namespace, clusterKey.Name)
 	}
-	keyData, ok := apiServerEtcdClientCertificateSecret.Data[secret.TLSKeyDataName]
+	keyData, ok, err := secret.KeyData(ctx, apiServerEtcdClientCertificateSecret)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
 	if !ok {
 		return tls.Certificate{}, errors.Errorf("etcd tls key does not exist for cluster %s/%s", clusterKey.Namespace, clusterKey.Name)
 	}
@@ -18,6 +18,7 @@ package internal
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -127,15 +128,32 @@ func (c *ControlPlane) FailureDomainWithMostMachines(ctx context.Context, machin
 		// in the cluster status.
 		return notInFailureDomains.Oldest().Spec.FailureDomain
 	}
-	return failuredomains.PickMost(ctx, c.Cluster.Status.FailureDomains.FilterControlPlane(), c.Machines, machines)
+	return c.FailureDomainSelector().PickForScaleDown(ctx, c.Cluster.Status.FailureDomains.FilterControlPlane(), c.Machines, machines)
 }
 
-// NextFailureDomainForScaleUp returns the failure domain with the fewest number of up-to-date machines.
+// NextFailureDomainForScaleUp returns the failure domain the new machine should be created in, according to
+// the failure domain selection strategy of the KubeadmControlPlane and the up-to-date machines.
 func (c *ControlPlane) NextFailureDomainForScaleUp(ctx context.Context) *string {
 	if len(c.Cluster.Status.FailureDomains.FilterControlPlane()) == 0 {
 		return nil
 	}
-	return failuredomains.PickFewest(ctx, c.FailureDomains().FilterControlPlane(), c.UpToDateMachines())
+	upToDateMachines := c.UpToDateMachines()
+	// The new machine is identified by its index among the up-to-date machines, which, unlike its name,
+	// is the same for the machine replacing it during the next rollout.
+	key := fmt.Sprintf("%s/%d", c.KCP.Name, len(upToDateMachines))
+	return c.FailureDomainSelector().PickForScaleUp(ctx, c.FailureDomains().FilterControlPlane(), upToDateMachines, key)
+}
+
+// FailureDomainSelector returns the failuredomains.Selector for the failure domain selection strategy of the KubeadmControlPlane.
+func (c *ControlPlane) FailureDomainSelector() failuredomains.Selector {
+	switch c.KCP.Spec.FailureDomainSelectionStrategy {
+	case controlplanev1.WeightedFailureDomainSelectionStrategyType:
+		return failuredomains.NewWeightedSelector()
+	case controlplanev1.StickyFailureDomainSelectionStrategyType:
+		return failuredomains.NewStickySelector()
+	default:
+		return failuredomains.NewBalancedSelector()
+	}
 }
 
 // InitialControlPlaneConfig returns a new KubeadmConfigSpec that is to be used for an initializing control plane.
@@ -184,6 +202,12 @@ func (c *ControlPlane) MachinesNeedingRollout() (collections.Machines, map[strin
 	return machinesNeedingRollout, rolloutReasons
 }
 
+// MachinesWithExpiringCertificates returns the machines, among the given ones, whose certificates expire within
+// KCP.Spec.RolloutBefore.CertificatesExpiryDays.
+func (c *ControlPlane) MachinesWithExpiringCertificates(machines collections.Machines) collections.Machines {
+	return machines.Filter(collections.ShouldRolloutBefore(&c.reconciliationTime, c.KCP.Spec.RolloutBefore))
+}
+
 // UpToDateMachines returns the machines that are up to date with the control
 // plane's configuration and therefore do not require rollout.
 func (c *ControlPlane) UpToDateMachines() collections.Machines {
@@ -18,15 +18,18 @@ package internal
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/collections"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/failuredomains"
 )
 
 func TestControlPlane(t *testing.T) {
@@ -61,6 +64,54 @@ func TestControlPlane(t *testing.T) {
 			g.Expect(*controlPlane.FailureDomainWithMostMachines(ctx, controlPlane.Machines)).To(Equal("unknown"))
 		})
 	})
+
+	t.Run("Failure domain selection strategy", func(t *testing.T) {
+		controlPlane := &ControlPlane{
+			KCP: &controlplanev1.KubeadmControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "kcp"}},
+			Cluster: &clusterv1.Cluster{
+				Status: clusterv1.ClusterStatus{
+					FailureDomains: clusterv1.FailureDomains{
+						"one": failureDomain(true),
+						"two": failureDomain(true),
+					},
+				},
+			},
+		}
+
+		g.Expect(controlPlane.FailureDomainSelector()).To(Equal(failuredomains.NewBalancedSelector()))
+		controlPlane.KCP.Spec.FailureDomainSelectionStrategy = controlplanev1.WeightedFailureDomainSelectionStrategyType
+		g.Expect(controlPlane.FailureDomainSelector()).To(Equal(failuredomains.NewWeightedSelector()))
+		controlPlane.KCP.Spec.FailureDomainSelectionStrategy = controlplanev1.StickyFailureDomainSelectionStrategyType
+		g.Expect(controlPlane.FailureDomainSelector()).To(Equal(failuredomains.NewStickySelector()))
+
+		// The new machine is identified by its index among the up-to-date machines, so the machine
+		// replacing it during the next rollout is created in the same failure domain.
+		fd := controlPlane.NextFailureDomainForScaleUp(ctx)
+		g.Expect(fd).ToNot(BeNil())
+		g.Expect(fd).To(Equal(failuredomains.NewStickySelector().PickForScaleUp(ctx, controlPlane.Cluster.Status.FailureDomains, nil, "kcp/0")))
+		g.Expect(controlPlane.NextFailureDomainForScaleUp(ctx)).To(Equal(fd))
+	})
+}
+
+func TestMachinesWithExpiringCertificates(t *testing.T) {
+	g := NewWithT(t)
+
+	now := metav1.Now()
+	expiring := machine("expiring")
+	expiring.Status.CertificatesExpiryDate = &metav1.Time{Time: now.Add(5 * 24 * time.Hour)}
+	notExpiring := machine("not-expiring")
+	notExpiring.Status.CertificatesExpiryDate = &metav1.Time{Time: now.Add(30 * 24 * time.Hour)}
+	withoutExpiryDate := machine("without-expiry-date")
+	machines := collections.FromMachines(expiring, notExpiring, withoutExpiryDate)
+
+	c := &ControlPlane{
+		KCP:                &controlplanev1.KubeadmControlPlane{},
+		reconciliationTime: now,
+	}
+	g.Expect(c.MachinesWithExpiringCertificates(machines)).To(BeEmpty())
+
+	c.KCP.Spec.RolloutBefore = &controlplanev1.RolloutBefore{CertificatesExpiryDays: ptr.To[int32](7)}
+	g.Expect(c.MachinesWithExpiringCertificates(machines).Names()).To(ConsistOf("expiring"))
 }
 
 func TestHasUnhealthyMachine(t *testing.T) {
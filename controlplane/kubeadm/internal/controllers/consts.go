@@ -27,7 +27,7 @@ const (
 	// up/down if some preflight check for those operation has failed.
 	preflightFailedRequeueAfter = 15 * time.Second
 
-	// dependentCertRequeueAfter is how long to wait before checking again to see if
-	// dependent certificates have been created.
-	dependentCertRequeueAfter = 30 * time.Second
+	// defaultHealthCheckPollInterval is how often to check again the health of the control plane Nodes and
+	// components while it is not healthy, if they are not watched.
+	defaultHealthCheckPollInterval = 20 * time.Second
 )
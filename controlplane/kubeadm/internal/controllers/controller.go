@@ -37,6 +37,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
@@ -46,14 +47,23 @@ import (
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/internal/contract"
+	"sigs.k8s.io/cluster-api/internal/util/metrics"
+	"sigs.k8s.io/cluster-api/internal/util/rolloutwindow"
 	"sigs.k8s.io/cluster-api/internal/util/ssa"
 	"sigs.k8s.io/cluster-api/util"
-	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/certs"
 	"sigs.k8s.io/cluster-api/util/collections"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	clog "sigs.k8s.io/cluster-api/util/log"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/paused"
 	"sigs.k8s.io/cluster-api/util/predicates"
+	"sigs.k8s.io/cluster-api/util/priority"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/requeue"
 	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/cluster-api/util/throttle"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	"sigs.k8s.io/cluster-api/util/version"
 )
 
@@ -84,27 +94,47 @@ type KubeadmControlPlaneReconciler struct {
 	// WatchFilterValue is the label value used to filter events prior to reconciliation.
 	WatchFilterValue string
 
+	// CASigner, if set, is used to issue the cluster certificate authorities as intermediate CAs
+	// signed by an external signer, so the root CA keys are never stored in the management cluster.
+	CASigner certs.Signer
+
+	// KubeconfigClientCertTTL is the lifespan of the client certificate in the Kubeconfig generated for the cluster;
+	// if not set, certs.DefaultCertDuration is used. The Kubeconfig is regenerated by the first reconciliation after
+	// half of the TTL has elapsed, so the TTL should be significantly longer than the sync period.
+	KubeconfigClientCertTTL time.Duration
+
+	// WatchControlPlaneComponents enables watching the control plane Nodes and the static pods of the control plane
+	// components in the workload cluster, so that their changes are reconciled as they happen instead of polling
+	// for them every HealthCheckPollInterval; if a watch cannot be established, polling is used as a fallback.
+	// Static pods are then read from the ClusterCacheTracker's cache, which should be configured with
+	// internal.StaticPodsCacheByObject for Pods.
+	WatchControlPlaneComponents bool
+
+	// HealthCheckPollInterval is how often the health of the control plane Nodes and components is checked
+	// while it is not healthy and they are not watched; if not set, defaultHealthCheckPollInterval is used.
+	HealthCheckPollInterval time.Duration
+
 	managementCluster         internal.ManagementCluster
 	managementClusterUncached internal.ManagementCluster
 	ssaCache                  ssa.Cache
 }
 
 func (r *KubeadmControlPlaneReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
-	c, err := ctrl.NewControllerManagedBy(mgr).
-		For(&controlplanev1.KubeadmControlPlane{}).
+	c, err := priority.For(ctrl.NewControllerManagedBy(mgr), &controlplanev1.KubeadmControlPlane{}).
 		Owns(&clusterv1.Machine{}).
 		WithOptions(options).
-		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
+		WithEventFilter(predicates.ResourceHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
 		Watches(
 			&clusterv1.Cluster{},
 			handler.EnqueueRequestsFromMapFunc(r.ClusterToKubeadmControlPlane),
 			builder.WithPredicates(
 				predicates.All(ctrl.LoggerFrom(ctx),
 					predicates.ResourceHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue),
-					predicates.ClusterUnpausedAndInfrastructureReady(ctrl.LoggerFrom(ctx)),
+					predicates.ClusterPausedTransitionsOrInfrastructureReady(ctrl.LoggerFrom(ctx)),
 				),
 			),
-		).Build(r)
+		).Build(requeue.Reconciler(throttle.Reconciler("kubeadmcontrolplane", r.Client, &controlplanev1.KubeadmControlPlane{},
+		metrics.InstrumentReconciler("kubeadmcontrolplane", r.Client, &controlplanev1.KubeadmControlPlane{}, r))))
 	if err != nil {
 		return errors.Wrap(err, "failed setting up with a controller manager")
 	}
@@ -123,6 +153,7 @@ func (r *KubeadmControlPlaneReconciler) SetupWithManager(ctx context.Context, mg
 			Tracker:             r.Tracker,
 			EtcdDialTimeout:     r.EtcdDialTimeout,
 			EtcdCallTimeout:     r.EtcdCallTimeout,
+			CacheStaticPods:     r.WatchControlPlaneComponents,
 		}
 	}
 
@@ -134,6 +165,9 @@ func (r *KubeadmControlPlaneReconciler) SetupWithManager(ctx context.Context, mg
 }
 
 func (r *KubeadmControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res ctrl.Result, reterr error) {
+	ctx, span := tracing.StartReconcile(ctx, "KubeadmControlPlane", req)
+	defer func() { tracing.End(span, reterr) }()
+
 	log := ctrl.LoggerFrom(ctx)
 
 	// Fetch the KubeadmControlPlane instance.
@@ -157,10 +191,14 @@ func (r *KubeadmControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.
 	}
 	log = log.WithValues("Cluster", klog.KObj(cluster))
 	ctx = ctrl.LoggerInto(ctx, log)
+	ctx, log = clog.AddCorrelationID(ctx, cluster)
 
-	if annotations.IsPaused(cluster, kcp) {
-		log.Info("Reconciliation is paused for this object")
-		return ctrl.Result{}, nil
+	// Set the Paused condition, and return early if the object or Cluster is paused.
+	if isPaused, err := paused.EnsurePausedCondition(ctx, r.Client, cluster, kcp); err != nil || isPaused {
+		if isPaused {
+			log.Info("Reconciliation is paused for this object")
+		}
+		return ctrl.Result{}, err
 	}
 
 	// Initialize the patch helper.
@@ -198,6 +236,9 @@ func (r *KubeadmControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.
 		return ctrl.Result{}, nil
 	}
 
+	// Watch the control plane Nodes and components, if enabled, so that polling for their health is not required.
+	watchingControlPlaneComponents := r.watchControlPlaneComponents(ctx, cluster, kcp)
+
 	defer func() {
 		// Always attempt to update status.
 		if err := r.updateStatus(ctx, controlPlane); err != nil {
@@ -217,21 +258,19 @@ func (r *KubeadmControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.
 		}
 
 		// Only requeue if there is no error, Requeue or RequeueAfter and the object does not have a deletion timestamp.
-		if reterr == nil && res.IsZero() && kcp.ObjectMeta.DeletionTimestamp.IsZero() {
+		// If the control plane Nodes and components are watched, changes to them trigger a reconcile and no requeue is required.
+		if reterr == nil && res.IsZero() && kcp.ObjectMeta.DeletionTimestamp.IsZero() && !watchingControlPlaneComponents {
 			// Make KCP requeue in case node status is not ready, so we can check for node status without waiting for a full
 			// resync (by default 10 minutes).
-			// The alternative solution would be to watch the control plane nodes in the Cluster - similar to how the
-			// MachineSet and MachineHealthCheck controllers watch the nodes under their control.
 			if !kcp.Status.Ready {
-				res = ctrl.Result{RequeueAfter: 20 * time.Second}
+				res = ctrl.Result{RequeueAfter: r.healthCheckPollInterval()}
 			}
 
 			// Make KCP requeue if ControlPlaneComponentsHealthyCondition is false so we can check for control plane component
 			// status without waiting for a full resync (by default 10 minutes).
 			// Otherwise this condition can lead to a delay in provisioning MachineDeployments when MachineSet preflight checks are enabled.
-			// The alternative solution to this requeue would be watching the relevant pods inside each workload cluster which would be very expensive.
 			if conditions.IsFalse(kcp, controlplanev1.ControlPlaneComponentsHealthyCondition) {
-				res = ctrl.Result{RequeueAfter: 20 * time.Second}
+				res = ctrl.Result{RequeueAfter: r.healthCheckPollInterval()}
 			}
 		}
 	}()
@@ -259,6 +298,58 @@ func (r *KubeadmControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.
 	return res, err
 }
 
+func (r *KubeadmControlPlaneReconciler) healthCheckPollInterval() time.Duration {
+	if r.HealthCheckPollInterval > 0 {
+		return r.HealthCheckPollInterval
+	}
+	return defaultHealthCheckPollInterval
+}
+
+// watchControlPlaneComponents watches the control plane Nodes and the static pods of the control plane components
+// in the workload cluster, if enabled, returning true if both watches are established.
+func (r *KubeadmControlPlaneReconciler) watchControlPlaneComponents(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane) bool {
+	log := ctrl.LoggerFrom(ctx)
+
+	if !r.WatchControlPlaneComponents || r.Tracker == nil || r.controller == nil {
+		return false
+	}
+
+	if !conditions.IsTrue(cluster, clusterv1.ControlPlaneInitializedCondition) {
+		log.V(5).Info("Skipping control plane components watching setup because control plane is not initialized")
+		return false
+	}
+
+	eventHandler := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []ctrl.Request {
+		return []ctrl.Request{{NamespacedName: util.ObjectKey(kcp)}}
+	})
+
+	if err := r.Tracker.Watch(ctx, remote.WatchInput{
+		Name:         "kcp-watchControlPlaneNodes",
+		Cluster:      util.ObjectKey(cluster),
+		Watcher:      r.controller,
+		Kind:         &corev1.Node{},
+		EventHandler: eventHandler,
+		Predicates:   []predicate.Predicate{predicate.NewPredicateFuncs(internal.IsControlPlaneNode)},
+	}); err != nil {
+		log.V(4).Info("Failed to watch control plane Nodes, polling for their health", "err", err.Error())
+		return false
+	}
+
+	if err := r.Tracker.Watch(ctx, remote.WatchInput{
+		Name:         "kcp-watchControlPlaneStaticPods",
+		Cluster:      util.ObjectKey(cluster),
+		Watcher:      r.controller,
+		Kind:         &corev1.Pod{},
+		EventHandler: eventHandler,
+		Predicates:   []predicate.Predicate{predicate.NewPredicateFuncs(internal.IsControlPlaneStaticPod)},
+	}); err != nil {
+		log.V(4).Info("Failed to watch control plane static pods, polling for their health", "err", err.Error())
+		return false
+	}
+
+	return true
+}
+
 // initControlPlaneScope initializes the control plane scope; this includes also checking for orphan machines and
 // adopt them if necessary.
 // The func also returns a boolean indicating if adoptableMachine have been found and processed, but this doesn't imply those machines
@@ -344,6 +435,10 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, controlPl
 		return ctrl.Result{}, err
 	}
 
+	if err := r.reconcileArchitecture(ctx, controlPlane.KCP); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Wait for the cluster infrastructure to be ready before creating machines
 	if !controlPlane.Cluster.Status.InfrastructureReady {
 		log.Info("Cluster infrastructure is not ready yet")
@@ -368,6 +463,12 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, controlPl
 		}
 		return result, err
 	}
+	// Requeue when the client certificate of the kubeconfig Secret must be rotated, unless the reconcile is requeued earlier.
+	defer func() {
+		if reterr == nil {
+			res = util.LowestNonZeroResult(res, r.kubeconfigRotationResult(ctx, controlPlane))
+		}
+	}()
 
 	if err := r.syncMachines(ctx, controlPlane); err != nil {
 		return ctrl.Result{}, errors.Wrap(err, "failed to sync Machines")
@@ -403,6 +504,23 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, controlPl
 		for _, rolloutReason := range rolloutReasons {
 			reasons = append(reasons, rolloutReason)
 		}
+		// Defer the rollout until the next rollout window of the Cluster starts, unless the rollout is already in progress
+		// or the certificates of some machines are about to expire, because waiting could let them expire.
+		if conditions.GetReason(controlPlane.KCP, controlplanev1.MachinesSpecUpToDateCondition) != controlplanev1.RollingUpdateInProgressReason &&
+			len(controlPlane.MachinesWithExpiringCertificates(machinesNeedingRollout)) == 0 {
+			allowed, nextStart, err := rolloutwindow.Check(controlPlane.Cluster, time.Now())
+			if err != nil {
+				return ctrl.Result{}, errors.Wrap(err, "failed to check rollout windows")
+			}
+			if !allowed {
+				log.Info(fmt.Sprintf("Rollout of Control Plane machines deferred until the next rollout window starts: %s", strings.Join(reasons, ",")), "machinesNeedingRollout", machinesNeedingRollout.Names(), "nextStart", nextStart)
+				conditions.MarkFalse(controlPlane.KCP, controlplanev1.MachinesSpecUpToDateCondition, controlplanev1.RolloutDeferredReason, clusterv1.ConditionSeverityInfo, "Rollout of %d replicas with outdated spec deferred until the next rollout window starting at %s", len(machinesNeedingRollout), nextStart.UTC().Format(time.RFC3339))
+				if nextStart.IsZero() {
+					return ctrl.Result{}, nil
+				}
+				return ctrl.Result{RequeueAfter: time.Until(nextStart)}, nil
+			}
+		}
 		log.Info(fmt.Sprintf("Rolling out Control Plane machines: %s", strings.Join(reasons, ",")), "machinesNeedingRollout", machinesNeedingRollout.Names())
 		conditions.MarkFalse(controlPlane.KCP, controlplanev1.MachinesSpecUpToDateCondition, controlplanev1.RollingUpdateInProgressReason, clusterv1.ConditionSeverityWarning, "Rolling %d replicas with outdated spec (%d replicas up to date)", len(machinesNeedingRollout), len(controlPlane.Machines)-len(machinesNeedingRollout))
 		return r.upgradeControlPlane(ctx, controlPlane, machinesNeedingRollout)
@@ -491,7 +609,7 @@ func (r *KubeadmControlPlaneReconciler) reconcileClusterCertificates(ctx context
 	}
 	certificates := secret.NewCertificatesForInitialControlPlane(config.ClusterConfiguration)
 	controllerRef := metav1.NewControllerRef(controlPlane.KCP, controlplanev1.GroupVersion.WithKind(kubeadmControlPlaneKind))
-	if err := certificates.LookupOrGenerateCached(ctx, r.SecretCachingClient, r.Client, util.ObjectKey(controlPlane.Cluster), *controllerRef); err != nil {
+	if err := certificates.LookupOrGenerateCachedWithSigner(ctx, r.SecretCachingClient, r.Client, util.ObjectKey(controlPlane.Cluster), *controllerRef, r.CASigner); err != nil {
 		log.Error(err, "unable to lookup or create cluster certificates")
 		conditions.MarkFalse(controlPlane.KCP, controlplanev1.CertificatesAvailableCondition, controlplanev1.CertificatesGenerationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
 		return err
@@ -564,8 +682,7 @@ func (r *KubeadmControlPlaneReconciler) reconcileDelete(ctx context.Context, con
 	}
 	if len(errs) > 0 {
 		err := kerrors.NewAggregate(errs)
-		r.recorder.Eventf(controlPlane.KCP, corev1.EventTypeWarning, "FailedDelete",
-			"Failed to delete control plane Machines for cluster %s control plane: %v", klog.KObj(controlPlane.Cluster), err)
+		capirecord.Emit(r.recorder, controlPlane.KCP, capirecord.FailedDeleteReason, "control plane Machines for Cluster", klog.KObj(controlPlane.Cluster), err)
 		return ctrl.Result{}, err
 	}
 	conditions.MarkFalse(controlPlane.KCP, controlplanev1.ResizedCondition, clusterv1.DeletingReason, clusterv1.ConditionSeverityInfo, "")
@@ -870,7 +987,8 @@ func (r *KubeadmControlPlaneReconciler) adoptMachines(ctx context.Context, kcp *
 		}
 
 		if !util.IsSupportedVersionSkew(kcpVersion, machineVersion) {
-			r.recorder.Eventf(kcp, corev1.EventTypeWarning, "AdoptionFailed", "Could not adopt Machine %s/%s: its version (%q) is outside supported +/- one minor version skew from KCP's (%q)", m.Namespace, m.Name, *m.Spec.Version, kcp.Spec.Version)
+			capirecord.Emit(r.recorder, kcp, capirecord.AdoptionFailedReason, klog.KObj(m),
+				fmt.Sprintf("its version (%q) is outside supported +/- one minor version skew from KCP's (%q)", *m.Spec.Version, kcp.Spec.Version))
 			// avoid returning an error here so we don't cause the KCP controller to spin until the operator clarifies their intent
 			return nil
 		}
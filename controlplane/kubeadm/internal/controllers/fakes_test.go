@@ -71,6 +71,8 @@ type fakeWorkloadCluster struct {
 	Status                     internal.ClusterStatus
 	EtcdMembersResult          []string
 	APIServerCertificateExpiry *time.Time
+	// ClusterConfiguration, if set, is mutated by UpdateClusterConfiguration like the kubeadm-config ConfigMap.
+	ClusterConfiguration *bootstrapv1.ClusterConfiguration
 }
 
 func (f fakeWorkloadCluster) ForwardEtcdLeadership(_ context.Context, _ *clusterv1.Machine, leaderCandidate *clusterv1.Machine) error {
@@ -112,10 +114,6 @@ func (f fakeWorkloadCluster) UpdateKubernetesVersionInKubeadmConfigMap(semver.Ve
 	return nil
 }
 
-func (f fakeWorkloadCluster) UpdateEtcdLocalInKubeadmConfigMap(*bootstrapv1.LocalEtcd) func(*bootstrapv1.ClusterConfiguration) {
-	return nil
-}
-
 func (f fakeWorkloadCluster) UpdateKubeletConfigMap(_ context.Context, _ semver.Version) error {
 	return nil
 }
@@ -132,7 +130,15 @@ func (f fakeWorkloadCluster) EtcdMembers(_ context.Context) ([]string, error) {
 	return f.EtcdMembersResult, nil
 }
 
-func (f fakeWorkloadCluster) UpdateClusterConfiguration(context.Context, semver.Version, ...func(*bootstrapv1.ClusterConfiguration)) error {
+func (f fakeWorkloadCluster) UpdateClusterConfiguration(_ context.Context, _ semver.Version, mutators ...func(*bootstrapv1.ClusterConfiguration)) error {
+	if f.ClusterConfiguration == nil {
+		return nil
+	}
+	for _, mutator := range mutators {
+		if mutator != nil {
+			mutator(f.ClusterConfiguration)
+		}
+	}
 	return nil
 }
 
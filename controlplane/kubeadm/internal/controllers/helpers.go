@@ -20,7 +20,9 @@ import (
 	"context"
 	"encoding/json"
 	"strings"
+	"time"
 
+	"github.com/blang/semver/v4"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -29,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apiserver/pkg/storage/names"
+	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -37,13 +40,16 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/external"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/internal/contract"
 	"sigs.k8s.io/cluster-api/internal/util/ssa"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/certs"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/requeue"
 	"sigs.k8s.io/cluster-api/util/secret"
 )
 
@@ -55,6 +61,17 @@ func (r *KubeadmControlPlaneReconciler) reconcileKubeconfig(ctx context.Context,
 		return ctrl.Result{}, nil
 	}
 
+	var kubeconfigOpts []kubeconfig.Option
+	if r.KubeconfigClientCertTTL > 0 {
+		kubeconfigOpts = append(kubeconfigOpts, kubeconfig.WithClientCertificateTTL(r.KubeconfigClientCertTTL))
+	}
+	// Note: if the management cluster authenticates to the workload cluster using the WorkloadClusterAuthAnnotation,
+	// the kubeconfig Secret only stores the endpoint and the CA of the cluster, and no long-lived client certificate.
+	withoutClientCert := annotations.HasWorkloadClusterAuth(controlPlane.Cluster)
+	if withoutClientCert {
+		kubeconfigOpts = append(kubeconfigOpts, kubeconfig.WithoutClientCertificate())
+	}
+
 	controllerOwnerRef := *metav1.NewControllerRef(controlPlane.KCP, controlplanev1.GroupVersion.WithKind(kubeadmControlPlaneKind))
 	clusterName := util.ObjectKey(controlPlane.Cluster)
 	configSecret, err := secret.GetFromNamespacedName(ctx, r.SecretCachingClient, clusterName, secret.Kubeconfig)
@@ -66,9 +83,10 @@ func (r *KubeadmControlPlaneReconciler) reconcileKubeconfig(ctx context.Context,
 			clusterName,
 			endpoint.String(),
 			controllerOwnerRef,
+			kubeconfigOpts...,
 		)
 		if errors.Is(createErr, kubeconfig.ErrDependentCertificateNotFound) {
-			return ctrl.Result{RequeueAfter: dependentCertRequeueAfter}, nil
+			return ctrl.Result{RequeueAfter: requeue.After(requeue.NotFoundErrorClass)}, nil
 		}
 		// always return if we have just created in order to skip rotation checks
 		return ctrl.Result{}, createErr
@@ -85,14 +103,18 @@ func (r *KubeadmControlPlaneReconciler) reconcileKubeconfig(ctx context.Context,
 		return ctrl.Result{}, nil
 	}
 
-	needsRotation, err := kubeconfig.NeedsClientCertRotation(configSecret, certs.ClientCertificateRenewalDuration)
+	needsRotation, err := kubeconfig.NeedsClientCertRotation(configSecret, r.kubeconfigRenewalThreshold())
+	if withoutClientCert {
+		// Remove the client certificate of kubeconfig Secrets generated before the annotation has been set.
+		needsRotation, err = kubeconfig.HasClientCertificate(configSecret)
+	}
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
 	if needsRotation {
 		log.Info("rotating kubeconfig secret")
-		if err := kubeconfig.RegenerateSecret(ctx, r.Client, configSecret); err != nil {
+		if err := kubeconfig.RegenerateSecret(ctx, r.Client, configSecret, kubeconfigOpts...); err != nil {
 			return ctrl.Result{}, errors.Wrap(err, "failed to regenerate kubeconfig")
 		}
 	}
@@ -100,6 +122,37 @@ func (r *KubeadmControlPlaneReconciler) reconcileKubeconfig(ctx context.Context,
 	return ctrl.Result{}, nil
 }
 
+// kubeconfigRenewalThreshold returns the time before its expiry after which the client certificate of the
+// kubeconfig Secret is rotated.
+func (r *KubeadmControlPlaneReconciler) kubeconfigRenewalThreshold() time.Duration {
+	if r.KubeconfigClientCertTTL > 0 {
+		return r.KubeconfigClientCertTTL / 2
+	}
+	return certs.ClientCertificateRenewalDuration
+}
+
+// kubeconfigRotationResult returns a result requeueing the KubeadmControlPlane when the client certificate of the
+// kubeconfig Secret must be rotated, so short-lived client certificates are rotated even if nothing else changes.
+func (r *KubeadmControlPlaneReconciler) kubeconfigRotationResult(ctx context.Context, controlPlane *internal.ControlPlane) ctrl.Result {
+	if annotations.HasWorkloadClusterAuth(controlPlane.Cluster) {
+		return ctrl.Result{}
+	}
+	configSecret, err := secret.GetFromNamespacedName(ctx, r.SecretCachingClient, util.ObjectKey(controlPlane.Cluster), secret.Kubeconfig)
+	if err != nil || !util.IsControlledBy(configSecret, controlPlane.KCP) {
+		return ctrl.Result{}
+	}
+	rotationTime, err := kubeconfig.ClientCertRotationTime(configSecret, r.kubeconfigRenewalThreshold())
+	if err != nil || rotationTime.IsZero() {
+		return ctrl.Result{}
+	}
+	// Note: the rotation time can be in the past if the cache did not observe a rotated kubeconfig Secret yet.
+	requeueAfter := time.Until(rotationTime)
+	if requeueAfter < time.Second {
+		requeueAfter = time.Second
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}
+}
+
 // Ensure the KubeadmConfigSecret has an owner reference to the control plane if it is not a user-provided secret.
 func (r *KubeadmControlPlaneReconciler) adoptKubeconfigSecret(ctx context.Context, configSecret *corev1.Secret, kcp *controlplanev1.KubeadmControlPlane) (reterr error) {
 	patchHelper, err := patch.NewHelper(configSecret, r.Client)
@@ -162,6 +215,60 @@ func (r *KubeadmControlPlaneReconciler) reconcileExternalReference(ctx context.C
 	return patchHelper.Patch(ctx, obj)
 }
 
+// reconcileArchitecture sets the architecture annotation on the KubeadmControlPlane from status.nodeInfo.architecture
+// of the infrastructure machine template, if it is not set on the KubeadmControlPlane or in the MachineTemplate.
+func (r *KubeadmControlPlaneReconciler) reconcileArchitecture(ctx context.Context, kcp *controlplanev1.KubeadmControlPlane) error {
+	if _, ok := kcp.Annotations[clusterv1.MachineArchitectureAnnotation]; ok {
+		return nil
+	}
+	if _, ok := kcp.Spec.MachineTemplate.ObjectMeta.Annotations[clusterv1.MachineArchitectureAnnotation]; ok {
+		return nil
+	}
+	ref := &kcp.Spec.MachineTemplate.InfrastructureRef
+	if !strings.HasSuffix(ref.Kind, clusterv1.TemplateSuffix) {
+		return nil
+	}
+
+	template, err := external.Get(ctx, r.Client, ref, kcp.Namespace)
+	if err != nil {
+		return err
+	}
+	arch, err := contract.InfrastructureMachineTemplate().NodeInfo().Architecture().Get(template)
+	if err != nil {
+		if errors.Is(err, contract.ErrFieldNotFound) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get architecture from %s %s", template.GetKind(), klog.KObj(template))
+	}
+	if *arch != "" {
+		annotations.AddAnnotations(kcp, map[string]string{clusterv1.MachineArchitectureAnnotation: *arch})
+	}
+	return nil
+}
+
+// machineArchitecture returns the CPU architecture of the control plane Machines, if known.
+func machineArchitecture(kcp *controlplanev1.KubeadmControlPlane) string {
+	if arch, ok := kcp.Spec.MachineTemplate.ObjectMeta.Annotations[clusterv1.MachineArchitectureAnnotation]; ok {
+		return arch
+	}
+	return kcp.Annotations[clusterv1.MachineArchitectureAnnotation]
+}
+
+// kubeadmConfigMapImageRepository returns the imageRepository to be set in the kubeadm-config ConfigMap, rewritten
+// according to the configured image mirrors for the architecture of the control plane Machines, like it is done
+// for the KubeadmConfigs.
+// NOTE: kubeadm reads the imageRepository from the kubeadm-config ConfigMap when joining control plane Machines
+// and when upgrading them.
+func kubeadmConfigMapImageRepository(kcp *controlplanev1.KubeadmControlPlane, parsedVersion semver.Version) (string, error) {
+	// Get the imageRepository or the correct value if nothing is set and a migration is necessary.
+	imageRepository := internal.ImageRepositoryFromClusterConfig(kcp.Spec.KubeadmConfigSpec.ClusterConfiguration, parsedVersion)
+	imageRepository, err := internal.RewriteImageRepository(imageRepository, machineArchitecture(kcp))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to rewrite the image repository")
+	}
+	return imageRepository, nil
+}
+
 func (r *KubeadmControlPlaneReconciler) cloneConfigsAndGenerateMachine(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane, bootstrapSpec *bootstrapv1.KubeadmConfigSpec, failureDomain *string) error {
 	var errs []error
 
@@ -363,6 +470,13 @@ func (r *KubeadmControlPlaneReconciler) computeDesiredMachine(kcp *controlplanev
 		if remediationData, ok := kcp.Annotations[controlplanev1.RemediationInProgressAnnotation]; ok {
 			annotations[controlplanev1.RemediationForAnnotation] = remediationData
 		}
+
+		// Propagate the architecture of the control plane, unless it is set in the MachineTemplate.
+		if arch, ok := kcp.Annotations[clusterv1.MachineArchitectureAnnotation]; ok {
+			if _, ok := kcp.Spec.MachineTemplate.ObjectMeta.Annotations[clusterv1.MachineArchitectureAnnotation]; !ok {
+				annotations[clusterv1.MachineArchitectureAnnotation] = arch
+			}
+		}
 	} else {
 		// Updating an existing machine
 		machineName = existingMachine.Name
@@ -381,6 +495,13 @@ func (r *KubeadmControlPlaneReconciler) computeDesiredMachine(kcp *controlplanev
 		if remediationData, ok := existingMachine.Annotations[controlplanev1.RemediationForAnnotation]; ok {
 			annotations[controlplanev1.RemediationForAnnotation] = remediationData
 		}
+
+		// Preserve the architecture the machine has been created with, unless it is set in the MachineTemplate.
+		if arch, ok := existingMachine.Annotations[clusterv1.MachineArchitectureAnnotation]; ok {
+			if _, ok := kcp.Spec.MachineTemplate.ObjectMeta.Annotations[clusterv1.MachineArchitectureAnnotation]; !ok {
+				annotations[clusterv1.MachineArchitectureAnnotation] = arch
+			}
+		}
 	}
 
 	// Construct the basic Machine.
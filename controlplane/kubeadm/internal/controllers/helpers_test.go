@@ -37,6 +37,7 @@ import (
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/cluster-api/util/requeue"
 	"sigs.k8s.io/cluster-api/util/secret"
 )
 
@@ -141,7 +142,7 @@ func TestReconcileKubeconfigMissingCACertificate(t *testing.T) {
 
 	result, err := r.reconcileKubeconfig(ctx, controlPlane)
 	g.Expect(err).ToNot(HaveOccurred())
-	g.Expect(result).To(BeComparableTo(ctrl.Result{RequeueAfter: dependentCertRequeueAfter}))
+	g.Expect(result).To(BeComparableTo(ctrl.Result{RequeueAfter: requeue.After(requeue.NotFoundErrorClass)}))
 
 	kubeconfigSecret := &corev1.Secret{}
 	secretName := client.ObjectKey{
@@ -292,6 +293,133 @@ func TestKubeadmControlPlaneReconciler_reconcileKubeconfig(t *testing.T) {
 	g.Expect(kubeconfigSecret.Labels).To(HaveKeyWithValue(clusterv1.ClusterNameLabel, cluster.Name))
 }
 
+func TestKubeadmControlPlaneReconciler_reconcileKubeconfigWithWorkloadClusterAuth(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "test.local", Port: 8443},
+		},
+	}
+
+	kcp := &controlplanev1.KubeadmControlPlane{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "KubeadmControlPlane",
+			APIVersion: controlplanev1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			Version: "v1.16.6",
+		},
+	}
+
+	clusterCerts := secret.NewCertificatesForInitialControlPlane(&bootstrapv1.ClusterConfiguration{})
+	g.Expect(clusterCerts.Generate()).To(Succeed())
+	caCert := clusterCerts.GetByPurpose(secret.ClusterCA)
+	existingCACertSecret := caCert.AsSecret(
+		client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "foo"},
+		*metav1.NewControllerRef(kcp, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane")),
+	)
+
+	fakeClient := newFakeClient(kcp.DeepCopy(), existingCACertSecret.DeepCopy())
+	r := &KubeadmControlPlaneReconciler{
+		Client:              fakeClient,
+		SecretCachingClient: fakeClient,
+		recorder:            record.NewFakeRecorder(32),
+	}
+
+	controlPlane := &internal.ControlPlane{
+		KCP:     kcp,
+		Cluster: cluster,
+	}
+	secretName := client.ObjectKey{
+		Namespace: metav1.NamespaceDefault,
+		Name:      secret.Name(cluster.Name, secret.Kubeconfig),
+	}
+
+	// The kubeconfig Secret is generated with a client certificate if the Cluster does not use the annotation.
+	_, err := r.reconcileKubeconfig(ctx, controlPlane)
+	g.Expect(err).ToNot(HaveOccurred())
+	kubeconfigSecret := &corev1.Secret{}
+	g.Expect(r.Client.Get(ctx, secretName, kubeconfigSecret)).To(Succeed())
+	g.Expect(kubeconfig.HasClientCertificate(kubeconfigSecret)).To(BeTrue())
+
+	// The client certificate is removed once the Cluster uses the annotation.
+	cluster.Annotations = map[string]string{clusterv1.WorkloadClusterAuthAnnotation: "exec"}
+	_, err = r.reconcileKubeconfig(ctx, controlPlane)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(r.Client.Get(ctx, secretName, kubeconfigSecret)).To(Succeed())
+	g.Expect(kubeconfig.HasClientCertificate(kubeconfigSecret)).To(BeFalse())
+}
+
+func TestKubeadmControlPlaneReconciler_kubeconfigRotationResult(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "test.local", Port: 8443},
+		},
+	}
+
+	kcp := &controlplanev1.KubeadmControlPlane{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "KubeadmControlPlane",
+			APIVersion: controlplanev1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			Version: "v1.16.6",
+		},
+	}
+
+	clusterCerts := secret.NewCertificatesForInitialControlPlane(&bootstrapv1.ClusterConfiguration{})
+	g.Expect(clusterCerts.Generate()).To(Succeed())
+	caCert := clusterCerts.GetByPurpose(secret.ClusterCA)
+	existingCACertSecret := caCert.AsSecret(
+		client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "foo"},
+		*metav1.NewControllerRef(kcp, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane")),
+	)
+
+	fakeClient := newFakeClient(kcp.DeepCopy(), existingCACertSecret.DeepCopy())
+	r := &KubeadmControlPlaneReconciler{
+		Client:                  fakeClient,
+		SecretCachingClient:     fakeClient,
+		recorder:                record.NewFakeRecorder(32),
+		KubeconfigClientCertTTL: 2 * time.Hour,
+	}
+
+	controlPlane := &internal.ControlPlane{
+		KCP:     kcp,
+		Cluster: cluster,
+	}
+
+	// No requeue before the kubeconfig Secret is created.
+	g.Expect(r.kubeconfigRotationResult(ctx, controlPlane)).To(BeComparableTo(ctrl.Result{}))
+
+	// The reconcile is requeued when half of the client certificate TTL is left.
+	_, err := r.reconcileKubeconfig(ctx, controlPlane)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(r.kubeconfigRotationResult(ctx, controlPlane).RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+
+	// No requeue if the kubeconfig Secret does not store a client certificate.
+	cluster.Annotations = map[string]string{clusterv1.WorkloadClusterAuthAnnotation: "exec"}
+	g.Expect(r.kubeconfigRotationResult(ctx, controlPlane)).To(BeComparableTo(ctrl.Result{}))
+}
+
 func TestCloneConfigsAndGenerateMachine(t *testing.T) {
 	setup := func(t *testing.T, g *WithT) *corev1.Namespace {
 		t.Helper()
@@ -652,6 +780,22 @@ func TestKubeadmControlPlaneReconciler_computeDesiredMachine(t *testing.T) {
 		g.Expect(kcp.Spec.MachineTemplate.ObjectMeta.Labels).To(Equal(kcpMachineTemplateObjectMetaCopy.Labels))
 		g.Expect(kcp.Spec.MachineTemplate.ObjectMeta.Annotations).To(Equal(kcpMachineTemplateObjectMetaCopy.Annotations))
 	})
+
+	t.Run("should propagate the architecture", func(t *testing.T) {
+		g := NewWithT(t)
+
+		kcp := kcp.DeepCopy()
+		kcp.Annotations = map[string]string{clusterv1.MachineArchitectureAnnotation: "arm64"}
+		createdMachine, err := (&KubeadmControlPlaneReconciler{}).computeDesiredMachine(kcp, cluster, nil, nil)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(createdMachine.Annotations).To(HaveKeyWithValue(clusterv1.MachineArchitectureAnnotation, "arm64"))
+
+		// The architecture of an existing Machine is preserved.
+		kcp.Annotations[clusterv1.MachineArchitectureAnnotation] = "amd64"
+		updatedMachine, err := (&KubeadmControlPlaneReconciler{}).computeDesiredMachine(kcp, cluster, nil, createdMachine)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(updatedMachine.Annotations).To(HaveKeyWithValue(clusterv1.MachineArchitectureAnnotation, "arm64"))
+	})
 }
 
 func TestKubeadmControlPlaneReconciler_generateKubeadmConfig(t *testing.T) {
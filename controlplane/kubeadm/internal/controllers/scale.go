@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/blang/semver/v4"
@@ -26,13 +27,19 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/util/audit"
 	"sigs.k8s.io/cluster-api/util/collections"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/container"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/requeue"
+	"sigs.k8s.io/cluster-api/util/version"
 )
 
 func (r *KubeadmControlPlaneReconciler) initializeControlPlane(ctx context.Context, controlPlane *internal.ControlPlane) (ctrl.Result, error) {
@@ -42,7 +49,7 @@ func (r *KubeadmControlPlaneReconciler) initializeControlPlane(ctx context.Conte
 	fd := controlPlane.NextFailureDomainForScaleUp(ctx)
 	if err := r.cloneConfigsAndGenerateMachine(ctx, controlPlane.Cluster, controlPlane.KCP, bootstrapSpec, fd); err != nil {
 		logger.Error(err, "Failed to create initial control plane Machine")
-		r.recorder.Eventf(controlPlane.KCP, corev1.EventTypeWarning, "FailedInitialization", "Failed to create initial control plane Machine for cluster %s control plane: %v", klog.KObj(controlPlane.Cluster), err)
+		capirecord.Emit(r.recorder, controlPlane.KCP, capirecord.FailedInitializationReason, klog.KObj(controlPlane.Cluster), err)
 		return ctrl.Result{}, err
 	}
 
@@ -58,12 +65,16 @@ func (r *KubeadmControlPlaneReconciler) scaleUpControlPlane(ctx context.Context,
 		return result, err
 	}
 
+	if err := r.reconcileJoinImageRepository(ctx, controlPlane); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Create the bootstrap configuration
 	bootstrapSpec := controlPlane.JoinControlPlaneConfig()
 	fd := controlPlane.NextFailureDomainForScaleUp(ctx)
 	if err := r.cloneConfigsAndGenerateMachine(ctx, controlPlane.Cluster, controlPlane.KCP, bootstrapSpec, fd); err != nil {
 		logger.Error(err, "Failed to create additional control plane Machine")
-		r.recorder.Eventf(controlPlane.KCP, corev1.EventTypeWarning, "FailedScaleUp", "Failed to create additional control plane Machine for cluster % control plane: %v", klog.KObj(controlPlane.Cluster), err)
+		capirecord.Emit(r.recorder, controlPlane.KCP, capirecord.FailedScaleUpReason, klog.KObj(controlPlane.Cluster), err)
 		return ctrl.Result{}, err
 	}
 
@@ -71,6 +82,34 @@ func (r *KubeadmControlPlaneReconciler) scaleUpControlPlane(ctx context.Context,
 	return ctrl.Result{Requeue: true}, nil
 }
 
+// reconcileJoinImageRepository sets the imageRepository in the kubeadm-config ConfigMap, which kubeadm reads when
+// joining control plane Machines, to the one rewritten according to the configured image mirrors for the architecture
+// of the control plane Machines, e.g. if the architecture became known after the control plane was initialized.
+func (r *KubeadmControlPlaneReconciler) reconcileJoinImageRepository(ctx context.Context, controlPlane *internal.ControlPlane) error {
+	if container.GetImageRewriter() == nil || controlPlane.KCP.Spec.KubeadmConfigSpec.ClusterConfiguration == nil {
+		return nil
+	}
+
+	parsedVersion, err := semver.ParseTolerant(controlPlane.KCP.Spec.Version)
+	if err != nil {
+		return requeue.Terminal(errors.Wrapf(err, "failed to parse kubernetes version %q", controlPlane.KCP.Spec.Version))
+	}
+	parsedVersionTolerant, err := version.ParseMajorMinorPatchTolerant(controlPlane.KCP.Spec.Version)
+	if err != nil {
+		return requeue.Terminal(errors.Wrapf(err, "failed to parse kubernetes version %q", controlPlane.KCP.Spec.Version))
+	}
+	imageRepository, err := kubeadmConfigMapImageRepository(controlPlane.KCP, parsedVersionTolerant)
+	if err != nil {
+		return err
+	}
+
+	workloadCluster, err := controlPlane.GetWorkloadCluster(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get remote client for workload cluster")
+	}
+	return workloadCluster.UpdateClusterConfiguration(ctx, parsedVersion, workloadCluster.UpdateImageRepositoryInKubeadmConfigMap(imageRepository))
+}
+
 func (r *KubeadmControlPlaneReconciler) scaleDownControlPlane(
 	ctx context.Context,
 	controlPlane *internal.ControlPlane,
@@ -125,10 +164,24 @@ func (r *KubeadmControlPlaneReconciler) scaleDownControlPlane(
 	}
 
 	logger = logger.WithValues("Machine", klog.KObj(machineToDelete))
+	decision := audit.Decision{
+		Action:     audit.DeleteAction,
+		Reason:     fmt.Sprintf("KubeadmControlPlane %s is scaling down to %d replicas", klog.KObj(controlPlane.KCP), ptr.Deref(controlPlane.KCP.Spec.Replicas, 0)),
+		Controller: "kubeadmcontrolplane",
+	}
+	if _, ok := outdatedMachines[machineToDelete.Name]; ok {
+		decision.Action = audit.RolloutAction
+		decision.Reason = fmt.Sprintf("Machine is being rolled out by KubeadmControlPlane %s", klog.KObj(controlPlane.KCP))
+		if _, rolloutReasons := controlPlane.MachinesNeedingRollout(); rolloutReasons[machineToDelete.Name] != "" {
+			decision.Reason += ": " + rolloutReasons[machineToDelete.Name]
+		}
+	}
+	if err := audit.RecordMachineDecision(ctx, r.Client, r.recorder, machineToDelete, decision); err != nil {
+		return ctrl.Result{}, err
+	}
 	if err := r.Client.Delete(ctx, machineToDelete); err != nil && !apierrors.IsNotFound(err) {
 		logger.Error(err, "Failed to delete control plane machine")
-		r.recorder.Eventf(controlPlane.KCP, corev1.EventTypeWarning, "FailedScaleDown",
-			"Failed to delete control plane Machine %s for cluster %s control plane: %v", machineToDelete.Name, klog.KObj(controlPlane.Cluster), err)
+		capirecord.Emit(r.recorder, controlPlane.KCP, capirecord.FailedScaleDownReason, klog.KObj(machineToDelete), klog.KObj(controlPlane.Cluster), err)
 		return ctrl.Result{}, err
 	}
 
@@ -199,8 +252,7 @@ loopmachines:
 	}
 	if len(machineErrors) > 0 {
 		aggregatedError := kerrors.NewAggregate(machineErrors)
-		r.recorder.Eventf(controlPlane.KCP, corev1.EventTypeWarning, "ControlPlaneUnhealthy",
-			"Waiting for control plane to pass preflight checks to continue reconciliation: %v", aggregatedError)
+		capirecord.Emit(r.recorder, controlPlane.KCP, capirecord.ControlPlaneUnhealthyReason, aggregatedError)
 		logger.Info("Waiting for control plane to pass preflight checks", "failures", aggregatedError.Error())
 
 		return ctrl.Result{RequeueAfter: preflightFailedRequeueAfter}, nil
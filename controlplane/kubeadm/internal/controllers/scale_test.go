@@ -37,6 +37,7 @@ import (
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/collections"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/container"
 )
 
 func TestKubeadmControlPlaneReconciler_initializeControlPlane(t *testing.T) {
@@ -240,6 +241,49 @@ func TestKubeadmControlPlaneReconciler_scaleUpControlPlane(t *testing.T) {
 	})
 }
 
+func TestKubeadmControlPlaneReconciler_reconcileJoinImageRepository(t *testing.T) {
+	defer container.SetImageRewriter(container.GetImageRewriter())
+
+	g := NewWithT(t)
+
+	rewriter, err := container.NewImageRewriter([]container.ImageMirror{
+		{Source: "registry.k8s.io", Target: "mirror.example.com/registry.k8s.io"},
+		{Source: "registry.k8s.io", Target: "arm64.example.com/registry.k8s.io", Architecture: "arm64"},
+	}, nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	container.SetImageRewriter(rewriter)
+
+	kcp := &controlplanev1.KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{clusterv1.MachineArchitectureAnnotation: "arm64"},
+		},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			Version: "v1.30.0",
+			KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+				ClusterConfiguration: &bootstrapv1.ClusterConfiguration{
+					ImageRepository: "registry.k8s.io",
+				},
+			},
+		},
+	}
+	// The kubeadm-config ConfigMap has been written when the architecture was not known yet.
+	kubeadmConfigMap := &bootstrapv1.ClusterConfiguration{
+		ImageRepository: "mirror.example.com/registry.k8s.io",
+	}
+
+	r := &KubeadmControlPlaneReconciler{}
+	controlPlane := &internal.ControlPlane{
+		KCP:     kcp,
+		Cluster: &clusterv1.Cluster{},
+	}
+	controlPlane.InjectTestManagementCluster(&fakeManagementCluster{
+		Workload: fakeWorkloadCluster{ClusterConfiguration: kubeadmConfigMap},
+	})
+
+	g.Expect(r.reconcileJoinImageRepository(ctx, controlPlane)).To(Succeed())
+	g.Expect(kubeadmConfigMap.ImageRepository).To(Equal("arm64.example.com/registry.k8s.io"))
+}
+
 func TestKubeadmControlPlaneReconciler_scaleDownControlPlane_NoError(t *testing.T) {
 	t.Run("deletes control plane Machine if preflight checks pass", func(t *testing.T) {
 		g := NewWithT(t)
@@ -28,6 +28,8 @@ import (
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/collections"
+	"sigs.k8s.io/cluster-api/util/container"
+	"sigs.k8s.io/cluster-api/util/requeue"
 	"sigs.k8s.io/cluster-api/util/version"
 )
 
@@ -39,7 +41,7 @@ func (r *KubeadmControlPlaneReconciler) upgradeControlPlane(
 	logger := ctrl.LoggerFrom(ctx)
 
 	if controlPlane.KCP.Spec.RolloutStrategy == nil || controlPlane.KCP.Spec.RolloutStrategy.RollingUpdate == nil {
-		return ctrl.Result{}, errors.New("rolloutStrategy is not set")
+		return ctrl.Result{}, requeue.Terminal(errors.New("rolloutStrategy is not set"))
 	}
 
 	// TODO: handle reconciliation of etcd members and kubeadm config in case they get out of sync with cluster
@@ -52,7 +54,7 @@ func (r *KubeadmControlPlaneReconciler) upgradeControlPlane(
 
 	parsedVersion, err := semver.ParseTolerant(controlPlane.KCP.Spec.Version)
 	if err != nil {
-		return ctrl.Result{}, errors.Wrapf(err, "failed to parse kubernetes version %q", controlPlane.KCP.Spec.Version)
+		return ctrl.Result{}, requeue.Terminal(errors.Wrapf(err, "failed to parse kubernetes version %q", controlPlane.KCP.Spec.Version))
 	}
 
 	if err := workloadCluster.ReconcileKubeletRBACRole(ctx, parsedVersion); err != nil {
@@ -85,8 +87,10 @@ func (r *KubeadmControlPlaneReconciler) upgradeControlPlane(
 			return ctrl.Result{}, errors.Wrapf(err, "failed to parse kubernetes version %q", controlPlane.KCP.Spec.Version)
 		}
 
-		// Get the imageRepository or the correct value if nothing is set and a migration is necessary.
-		imageRepository := internal.ImageRepositoryFromClusterConfig(controlPlane.KCP.Spec.KubeadmConfigSpec.ClusterConfiguration, parsedVersionTolerant)
+		imageRepository, err := kubeadmConfigMapImageRepository(controlPlane.KCP, parsedVersionTolerant)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
 
 		kubeadmCMMutators = append(kubeadmCMMutators,
 			workloadCluster.UpdateImageRepositoryInKubeadmConfigMap(imageRepository),
@@ -97,8 +101,14 @@ func (r *KubeadmControlPlaneReconciler) upgradeControlPlane(
 
 		// Etcd local and external are mutually exclusive and they cannot be switched, once set.
 		if controlPlane.KCP.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.Local != nil {
+			etcdLocal := controlPlane.KCP.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.Local.DeepCopy()
+			if etcdLocal.ImageRepository != "" {
+				if etcdLocal.ImageRepository, err = container.GetImageRewriter().RewriteRepositoryForArchitecture(etcdLocal.ImageRepository, machineArchitecture(controlPlane.KCP)); err != nil {
+					return ctrl.Result{}, errors.Wrap(err, "failed to rewrite the etcd image repository")
+				}
+			}
 			kubeadmCMMutators = append(kubeadmCMMutators,
-				workloadCluster.UpdateEtcdLocalInKubeadmConfigMap(controlPlane.KCP.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.Local))
+				workloadCluster.UpdateEtcdLocalInKubeadmConfigMap(etcdLocal))
 		} else {
 			kubeadmCMMutators = append(kubeadmCMMutators,
 				workloadCluster.UpdateEtcdExternalInKubeadmConfigMap(controlPlane.KCP.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.External))
@@ -33,11 +33,13 @@ import (
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
 	"sigs.k8s.io/cluster-api/internal/test/builder"
 	"sigs.k8s.io/cluster-api/internal/util/ssa"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/collections"
+	"sigs.k8s.io/cluster-api/util/container"
 )
 
 const UpdatedVersion string = "v1.17.4"
@@ -281,3 +283,104 @@ func machine(name string, opts ...machineOpt) *clusterv1.Machine {
 	}
 	return m
 }
+
+func TestKubeadmControlPlaneReconciler_upgradeControlPlaneRewritesImageRepositories(t *testing.T) {
+	defer container.SetImageRewriter(container.GetImageRewriter())
+
+	tests := []struct {
+		name                    string
+		imageRepository         string
+		etcdImageRepository     string
+		architecture            string
+		wantImageRepository     string
+		wantEtcdImageRepository string
+	}{
+		{
+			name:                    "rewrites the image repositories set in the KubeadmControlPlane",
+			imageRepository:         "registry.k8s.io",
+			etcdImageRepository:     "registry.k8s.io/etcd",
+			wantImageRepository:     "mirror.example.com/registry.k8s.io",
+			wantEtcdImageRepository: "mirror.example.com/registry.k8s.io/etcd",
+		},
+		{
+			name:                    "rewrites the default image repository if the KubeadmControlPlane does not set one",
+			wantImageRepository:     "mirror.example.com/registry.k8s.io",
+			wantEtcdImageRepository: "",
+		},
+		{
+			name:                    "rewrites the image repositories for the architecture of the control plane Machines",
+			imageRepository:         "registry.k8s.io",
+			etcdImageRepository:     "registry.k8s.io/etcd",
+			architecture:            "arm64",
+			wantImageRepository:     "arm64.example.com/registry.k8s.io",
+			wantEtcdImageRepository: "arm64.example.com/registry.k8s.io/etcd",
+		},
+		{
+			name:                    "does not rewrite image repositories without a mirror",
+			imageRepository:         "example.com/kubernetes",
+			etcdImageRepository:     "example.com/etcd",
+			wantImageRepository:     "example.com/kubernetes",
+			wantEtcdImageRepository: "example.com/etcd",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			rewriter, err := container.NewImageRewriter([]container.ImageMirror{
+				{Source: "registry.k8s.io", Target: "mirror.example.com/registry.k8s.io"},
+				{Source: "registry.k8s.io", Target: "arm64.example.com/registry.k8s.io", Architecture: "arm64"},
+			}, nil)
+			g.Expect(err).ToNot(HaveOccurred())
+			container.SetImageRewriter(rewriter)
+
+			kcp := &controlplanev1.KubeadmControlPlane{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{clusterv1.MachineArchitectureAnnotation: tt.architecture},
+				},
+				Spec: controlplanev1.KubeadmControlPlaneSpec{
+					Version:  "v1.30.0",
+					Replicas: ptr.To[int32](1),
+					// Use a rollout strategy type upgradeControlPlane does not act on, so it returns after updating the kubeadm-config ConfigMap.
+					RolloutStrategy: &controlplanev1.RolloutStrategy{
+						RollingUpdate: &controlplanev1.RollingUpdate{},
+					},
+					KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+						ClusterConfiguration: &bootstrapv1.ClusterConfiguration{
+							ImageRepository: tt.imageRepository,
+							Etcd: bootstrapv1.Etcd{
+								Local: &bootstrapv1.LocalEtcd{
+									ImageMeta: bootstrapv1.ImageMeta{ImageRepository: tt.etcdImageRepository},
+								},
+							},
+						},
+					},
+				},
+			}
+			kubeadmConfigMap := &bootstrapv1.ClusterConfiguration{
+				Etcd: bootstrapv1.Etcd{
+					Local: &bootstrapv1.LocalEtcd{},
+				},
+			}
+
+			r := &KubeadmControlPlaneReconciler{}
+			controlPlane := &internal.ControlPlane{
+				KCP:     kcp,
+				Cluster: &clusterv1.Cluster{},
+			}
+			controlPlane.InjectTestManagementCluster(&fakeManagementCluster{
+				Workload: fakeWorkloadCluster{ClusterConfiguration: kubeadmConfigMap},
+			})
+
+			_, err = r.upgradeControlPlane(ctx, controlPlane, nil)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			g.Expect(kubeadmConfigMap.ImageRepository).To(Equal(tt.wantImageRepository))
+			g.Expect(kubeadmConfigMap.Etcd.Local.ImageRepository).To(Equal(tt.wantEtcdImageRepository))
+			// The KubeadmControlPlane must not be modified.
+			g.Expect(kcp.Spec.KubeadmConfigSpec.ClusterConfiguration.ImageRepository).To(Equal(tt.imageRepository))
+			g.Expect(kcp.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.Local.ImageRepository).To(Equal(tt.etcdImageRepository))
+		})
+	}
+}
@@ -232,6 +232,7 @@ func (webhook *KubeadmControlPlane) ValidateUpdate(_ context.Context, oldObj, ne
 		// spec
 		{spec, "replicas"},
 		{spec, "version"},
+		{spec, "failureDomainSelectionStrategy"},
 		{spec, "remediationStrategy"},
 		{spec, "remediationStrategy", "*"},
 		{spec, "rolloutAfter"},
@@ -392,6 +393,23 @@ func validateKubeadmControlPlaneSpec(s controlplanev1.KubeadmControlPlaneSpec, n
 
 	allErrs = append(allErrs, validateRolloutBefore(s.RolloutBefore, pathPrefix.Child("rolloutBefore"))...)
 	allErrs = append(allErrs, validateRolloutStrategy(s.RolloutStrategy, s.Replicas, pathPrefix.Child("rolloutStrategy"))...)
+	allErrs = append(allErrs, validateFailureDomainSelectionStrategy(s.FailureDomainSelectionStrategy, pathPrefix.Child("failureDomainSelectionStrategy"))...)
+
+	return allErrs
+}
+
+func validateFailureDomainSelectionStrategy(strategy controlplanev1.FailureDomainSelectionStrategyType, pathPrefix *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	switch strategy {
+	case "", controlplanev1.BalancedFailureDomainSelectionStrategyType, controlplanev1.WeightedFailureDomainSelectionStrategyType, controlplanev1.StickyFailureDomainSelectionStrategyType:
+	default:
+		allErrs = append(allErrs, field.NotSupported(pathPrefix, strategy, []string{
+			string(controlplanev1.BalancedFailureDomainSelectionStrategyType),
+			string(controlplanev1.WeightedFailureDomainSelectionStrategyType),
+			string(controlplanev1.StickyFailureDomainSelectionStrategyType),
+		}))
+	}
 
 	return allErrs
 }
@@ -154,6 +154,9 @@ func TestKubeadmControlPlaneValidateCreate(t *testing.T) {
 		CertificatesExpiryDays: ptr.To[int32](5), // less than minimum
 	}
 
+	invalidFailureDomainSelectionStrategy := valid.DeepCopy()
+	invalidFailureDomainSelectionStrategy.Spec.FailureDomainSelectionStrategy = "Random"
+
 	invalidIgnitionConfiguration := valid.DeepCopy()
 	invalidIgnitionConfiguration.Spec.KubeadmConfigSpec.Ignition = &bootstrapv1.IgnitionSpec{}
 
@@ -242,6 +245,11 @@ func TestKubeadmControlPlaneValidateCreate(t *testing.T) {
 			expectErr: true,
 			kcp:       invalidRolloutBeforeCertificateExpiryDays,
 		},
+		{
+			name:      "should return error when given an invalid failureDomainSelectionStrategy",
+			expectErr: true,
+			kcp:       invalidFailureDomainSelectionStrategy,
+		},
 
 		{
 			name:                  "should return error when Ignition configuration is invalid",
@@ -156,3 +156,22 @@ func TestKubeadmControlPlaneTemplateValidationMetadata(t *testing.T) {
 		g.Expect(warnings).To(BeEmpty())
 	})
 }
+
+func TestKubeadmControlPlaneTemplateValidationFailureDomainSelectionStrategy(t *testing.T) {
+	t.Run("create kubeadmcontrolplanetemplate should not pass if failureDomainSelectionStrategy is invalid", func(t *testing.T) {
+		g := NewWithT(t)
+		kcpTemplate := &controlplanev1.KubeadmControlPlaneTemplate{
+			Spec: controlplanev1.KubeadmControlPlaneTemplateSpec{
+				Template: controlplanev1.KubeadmControlPlaneTemplateResource{
+					Spec: controlplanev1.KubeadmControlPlaneTemplateResourceSpec{
+						FailureDomainSelectionStrategy: "Random",
+					},
+				},
+			},
+		}
+		webhook := &KubeadmControlPlaneTemplate{}
+		warnings, err := webhook.ValidateCreate(ctx, kcpTemplate)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(warnings).To(BeEmpty())
+	})
+}
@@ -134,6 +134,10 @@ type Workload struct {
 	CoreDNSMigrator     coreDNSMigrator
 	etcdClientGenerator etcdClientFor
 	restConfig          *rest.Config
+
+	// staticPodReader, if set, is used to read the static pods of the control plane components
+	// instead of Client; Client is used as a fallback if reading from staticPodReader fails.
+	staticPodReader ctrlclient.Reader
 }
 
 var _ WorkloadCluster = &Workload{}
@@ -588,6 +592,12 @@ func (w *Workload) UpdateKubeProxyImageInfo(ctx context.Context, kcp *controlpla
 		}
 	}
 
+	// Rewrite the image according to the configured image mirrors, if any.
+	newImageName, err = containerutil.GetImageRewriter().Rewrite(newImageName)
+	if err != nil {
+		return err
+	}
+
 	if container.Image != newImageName {
 		helper, err := patch.NewHelper(ds, w.Client)
 		if err != nil {
@@ -653,3 +663,28 @@ func ImageRepositoryFromClusterConfig(clusterConfig *bootstrapv1.ClusterConfigur
 	// Use defaulting or current values otherwise.
 	return ""
 }
+
+// RewriteImageRepository returns the image repository rewritten according to the image mirrors configured
+// with containerutil.SetImageRewriter, including the ones for the architecture of the control plane Machines if
+// known, so the kubeadm-config ConfigMap references the same repositories used by the KubeadmConfigs of the Machines.
+// If the image repository is empty, the default kubeadm image repository is rewritten instead; empty is returned
+// if no mirror applies, so kubeadm defaulting keeps working.
+func RewriteImageRepository(imageRepository, architecture string) (string, error) {
+	rewriter := containerutil.GetImageRewriter()
+	if rewriter == nil {
+		return imageRepository, nil
+	}
+
+	repository := imageRepository
+	if repository == "" {
+		repository = kubeadm.DefaultImageRepository
+	}
+	rewritten, err := rewriter.RewriteRepositoryForArchitecture(repository, architecture)
+	if err != nil {
+		return "", err
+	}
+	if imageRepository == "" && rewritten == repository {
+		return "", nil
+	}
+	return rewritten, nil
+}
@@ -20,12 +20,17 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -36,6 +41,42 @@ import (
 	"sigs.k8s.io/cluster-api/util/conditions"
 )
 
+const (
+	// staticPodTierLabel and staticPodTierControlPlane identify the static pods of the control plane components generated by kubeadm.
+	staticPodTierLabel        = "tier"
+	staticPodTierControlPlane = "control-plane"
+
+	// staticPodCacheReadTimeout is how long to wait for a static pod to be read from the cache before probing the API server.
+	staticPodCacheReadTimeout = 10 * time.Second
+)
+
+// StaticPodsCacheByObject returns the cache configuration restricting the Pods cached for a workload cluster
+// to the static pods of the control plane components generated by kubeadm.
+func StaticPodsCacheByObject() cache.ByObject {
+	return cache.ByObject{
+		Namespaces: map[string]cache.Config{metav1.NamespaceSystem: {}},
+		Label:      labels.SelectorFromSet(labels.Set{staticPodTierLabel: staticPodTierControlPlane}),
+	}
+}
+
+// IsControlPlaneNode returns true if obj is a control plane Node.
+func IsControlPlaneNode(obj ctrlclient.Object) bool {
+	if _, ok := obj.(*corev1.Node); !ok {
+		return false
+	}
+	_, isControlPlane := obj.GetLabels()[labelNodeRoleControlPlane]
+	_, isOldControlPlane := obj.GetLabels()[labelNodeRoleOldControlPlane]
+	return isControlPlane || isOldControlPlane
+}
+
+// IsControlPlaneStaticPod returns true if obj is the static pod of a control plane component generated by kubeadm.
+func IsControlPlaneStaticPod(obj ctrlclient.Object) bool {
+	if _, ok := obj.(*corev1.Pod); !ok {
+		return false
+	}
+	return obj.GetNamespace() == metav1.NamespaceSystem && obj.GetLabels()[staticPodTierLabel] == staticPodTierControlPlane
+}
+
 // UpdateEtcdConditions is responsible for updating machine conditions reflecting the status of all the etcd members.
 // This operation is best effort, in the sense that in case of problems in retrieving member status, it sets
 // the condition to Unknown state without returning any error.
@@ -385,7 +426,7 @@ func (w *Workload) updateStaticPodCondition(ctx context.Context, machine *cluste
 	}
 
 	pod := corev1.Pod{}
-	if err := w.Client.Get(ctx, podKey, &pod); err != nil {
+	if err := w.getStaticPod(ctx, podKey, &pod); err != nil {
 		// If there is an error getting the Pod, do not set any conditions.
 		if apierrors.IsNotFound(err) {
 			conditions.MarkFalse(machine, staticPodCondition, controlplanev1.PodMissingReason, clusterv1.ConditionSeverityError, "Pod %s is missing", podKey.Name)
@@ -493,6 +534,21 @@ func nodeReadyUnknown(node corev1.Node) bool {
 	return false
 }
 
+// getStaticPod reads a static pod from the staticPodReader, if any, falling back to probing the API server
+// if the static pod cannot be read from it, e.g. because the cache is not synced.
+func (w *Workload) getStaticPod(ctx context.Context, podKey ctrlclient.ObjectKey, pod *corev1.Pod) error {
+	if w.staticPodReader != nil {
+		readCtx, cancel := context.WithTimeout(ctx, staticPodCacheReadTimeout)
+		defer cancel()
+		err := w.staticPodReader.Get(readCtx, podKey, pod)
+		if err == nil || apierrors.IsNotFound(err) {
+			return err
+		}
+		ctrl.LoggerFrom(ctx).V(4).Info("Failed to read static pod from cache, reading it from the API server", "Pod", klog.KRef(podKey.Namespace, podKey.Name), "err", err.Error())
+	}
+	return w.Client.Get(ctx, podKey, pod)
+}
+
 func podCondition(pod corev1.Pod, condition corev1.PodConditionType) corev1.ConditionStatus {
 	for _, c := range pod.Status.Conditions {
 		if c.Type == condition {
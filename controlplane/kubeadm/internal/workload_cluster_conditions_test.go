@@ -1080,6 +1080,83 @@ func withCondition(condition corev1.PodConditionType, status corev1.ConditionSta
 	}
 }
 
+func TestGetStaticPod(t *testing.T) {
+	podKey := client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: staticPodName("kube-apiserver", "n1")}
+	cachedPod := fakePod(podKey.Name, withPhase(corev1.PodRunning))
+	livePod := fakePod(podKey.Name, withPhase(corev1.PodPending))
+
+	tests := []struct {
+		name            string
+		staticPodReader *fakeClient
+		wantPhase       corev1.PodPhase
+		wantNotFound    bool
+		wantLiveRead    bool
+	}{
+		{
+			name:         "reads from the API server without a static pod reader",
+			wantPhase:    corev1.PodPending,
+			wantLiveRead: true,
+		},
+		{
+			name: "reads from the static pod reader",
+			staticPodReader: &fakeClient{
+				get: map[string]interface{}{podKey.String(): cachedPod},
+			},
+			wantPhase: corev1.PodRunning,
+		},
+		{
+			name:            "does not fall back to the API server if the static pod is not found",
+			staticPodReader: &fakeClient{},
+			wantNotFound:    true,
+		},
+		{
+			name: "falls back to the API server if the static pod reader fails",
+			staticPodReader: &fakeClient{
+				getErr: errors.New("cache not synced"),
+			},
+			wantPhase:    corev1.PodPending,
+			wantLiveRead: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			liveClient := &fakeClient{
+				get: map[string]interface{}{podKey.String(): livePod},
+			}
+			w := &Workload{Client: liveClient}
+			if tt.staticPodReader != nil {
+				w.staticPodReader = tt.staticPodReader
+			}
+
+			pod := &corev1.Pod{}
+			err := w.getStaticPod(ctx, podKey, pod)
+			g.Expect(liveClient.getCalled).To(Equal(tt.wantLiveRead))
+			if tt.wantNotFound {
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(pod.Status.Phase).To(Equal(tt.wantPhase))
+		})
+	}
+}
+
+func TestIsControlPlaneStaticPod(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(IsControlPlaneStaticPod(fakePod("kube-apiserver-n1", func(p *corev1.Pod) {
+		p.Labels = map[string]string{"tier": "control-plane"}
+	}))).To(BeTrue())
+	g.Expect(IsControlPlaneStaticPod(fakePod("coredns"))).To(BeFalse())
+	g.Expect(IsControlPlaneStaticPod(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"tier": "control-plane"}}})).To(BeFalse())
+
+	g.Expect(IsControlPlaneNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{labelNodeRoleControlPlane: ""}}})).To(BeTrue())
+	g.Expect(IsControlPlaneNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{labelNodeRoleOldControlPlane: ""}}})).To(BeTrue())
+	g.Expect(IsControlPlaneNode(&corev1.Node{})).To(BeFalse())
+}
+
 func TestAggregateFromMachinesToKCP(t *testing.T) {
 	conditionType := controlplanev1.ControlPlaneComponentsHealthyCondition
 	unhealthyReason := "unhealthy reason"
@@ -144,8 +144,17 @@ func (w *Workload) UpdateCoreDNS(ctx context.Context, kcp *controlplanev1.Kubead
 		return errors.Wrapf(err, "failed to validate CoreDNS")
 	}
 
+	// Rewrite the CoreDNS image repository according to the configured image mirrors before writing it to the
+	// kubeadm-config ConfigMap, like it is done for the CoreDNS Deployment.
+	dns := clusterConfig.DNS.DeepCopy()
+	if dns.ImageRepository != "" {
+		if dns.ImageRepository, err = containerutil.GetImageRewriter().RewriteRepository(dns.ImageRepository); err != nil {
+			return errors.Wrap(err, "failed to rewrite the CoreDNS image repository")
+		}
+	}
+
 	// Perform the upgrade.
-	if err := w.UpdateClusterConfiguration(ctx, version, w.updateCoreDNSImageInfoInKubeadmConfigMap(&clusterConfig.DNS)); err != nil {
+	if err := w.UpdateClusterConfiguration(ctx, version, w.updateCoreDNSImageInfoInKubeadmConfigMap(dns)); err != nil {
 		return err
 	}
 	if err := w.updateCoreDNSCorefile(ctx, info); err != nil {
@@ -238,6 +247,12 @@ func (w *Workload) getCoreDNSInfo(ctx context.Context, clusterConfig *bootstrapv
 		toImageName = coreDNSImageName
 	}
 
+	// Rewrite the image according to the configured image mirrors, if any.
+	toImage, err := containerutil.GetImageRewriter().Rewrite(fmt.Sprintf("%s/%s:%s", toImageRepository, toImageName, toImageTag))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to rewrite coredns image")
+	}
+
 	return &coreDNSInfo{
 		Corefile:               corefile,
 		Deployment:             deployment,
@@ -246,7 +261,7 @@ func (w *Workload) getCoreDNSInfo(ctx context.Context, clusterConfig *bootstrapv
 		FromImageTag:           parsedImage.Tag,
 		ToImageTag:             toImageTag,
 		FromImage:              container.Image,
-		ToImage:                fmt.Sprintf("%s/%s:%s", toImageRepository, toImageName, toImageTag),
+		ToImage:                toImage,
 	}, nil
 }
 
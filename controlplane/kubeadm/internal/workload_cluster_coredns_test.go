@@ -32,6 +32,7 @@ import (
 
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	containerutil "sigs.k8s.io/cluster-api/util/container"
 	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
 )
 
@@ -176,6 +177,10 @@ func TestUpdateCoreDNS(t *testing.T) {
 		expectUpdates bool
 		expectImage   string
 		expectRules   []rbacv1.PolicyRule
+		imageMirrors  []containerutil.ImageMirror
+		// expectImageRepository is the DNS imageRepository expected in the kubeadm-config ConfigMap,
+		// if different from the one in the KubeadmControlPlane.
+		expectImageRepository string
 	}{
 		{
 			name: "returns early without error if skip core dns annotation is present",
@@ -373,6 +378,33 @@ func TestUpdateCoreDNS(t *testing.T) {
 			expectUpdates: true,
 			expectImage:   "k8s.gcr.io/coredns:1.7.0",
 		},
+		{
+			name: "upgrade from Kubernetes v1.18.x to v1.19.y with an image mirror (from k8s.gcr.io/coredns:1.6.7 to mirror.example.com/k8s.gcr.io/coredns:1.7.0)",
+			kcp: &controlplanev1.KubeadmControlPlane{
+				Spec: controlplanev1.KubeadmControlPlaneSpec{
+					KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+						ClusterConfiguration: &bootstrapv1.ClusterConfiguration{
+							DNS: bootstrapv1.DNS{
+								ImageMeta: bootstrapv1.ImageMeta{
+									ImageRepository: "k8s.gcr.io",
+									ImageTag:        "1.7.0",
+								},
+							},
+						},
+					},
+				},
+			},
+			migrator: &fakeMigrator{
+				migratedCorefile: "updated-core-file",
+			},
+			semver:                semver1191,
+			objs:                  []client.Object{deplWithImage("k8s.gcr.io/coredns:1.6.7"), cm, kubeadmCM},
+			expectErr:             false,
+			expectUpdates:         true,
+			expectImage:           "mirror.example.com/k8s.gcr.io/coredns:1.7.0",
+			imageMirrors:          []containerutil.ImageMirror{{Source: "k8s.gcr.io", Target: "mirror.example.com/k8s.gcr.io"}},
+			expectImageRepository: "mirror.example.com/k8s.gcr.io",
+		},
 		{
 			name: "kubeadm defaults, upgrade from Kubernetes v1.19.x to v1.20.y (stay on k8s.gcr.io/coredns:1.7.0)",
 			kcp: &controlplanev1.KubeadmControlPlane{
@@ -513,11 +545,16 @@ func TestUpdateCoreDNS(t *testing.T) {
 				_ = env.CleanupAndWait(ctx, tt.objs...)
 			})
 
+			rewriter, err := containerutil.NewImageRewriter(tt.imageMirrors, nil)
+			g.Expect(err).ToNot(HaveOccurred())
+			defer containerutil.SetImageRewriter(containerutil.GetImageRewriter())
+			containerutil.SetImageRewriter(rewriter)
+
 			w := &Workload{
 				Client:          env.GetClient(),
 				CoreDNSMigrator: tt.migrator,
 			}
-			err := w.UpdateCoreDNS(ctx, tt.kcp, tt.semver)
+			err = w.UpdateCoreDNS(ctx, tt.kcp, tt.semver)
 
 			if tt.expectErr {
 				g.Expect(err).To(HaveOccurred())
@@ -532,7 +569,11 @@ func TestUpdateCoreDNS(t *testing.T) {
 					var expectedKubeadmConfigMap corev1.ConfigMap
 					g.Expect(env.Get(ctx, client.ObjectKey{Name: kubeadmConfigKey, Namespace: metav1.NamespaceSystem}, &expectedKubeadmConfigMap)).To(Succeed())
 					g.Expect(expectedKubeadmConfigMap.Data).To(HaveKeyWithValue("ClusterConfiguration", ContainSubstring(tt.kcp.Spec.KubeadmConfigSpec.ClusterConfiguration.DNS.ImageTag)))
-					g.Expect(expectedKubeadmConfigMap.Data).To(HaveKeyWithValue("ClusterConfiguration", ContainSubstring(tt.kcp.Spec.KubeadmConfigSpec.ClusterConfiguration.DNS.ImageRepository)))
+					expectImageRepository := tt.kcp.Spec.KubeadmConfigSpec.ClusterConfiguration.DNS.ImageRepository
+					if tt.expectImageRepository != "" {
+						expectImageRepository = tt.expectImageRepository
+					}
+					g.Expect(expectedKubeadmConfigMap.Data).To(HaveKeyWithValue("ClusterConfiguration", ContainSubstring(expectImageRepository)))
 					return nil
 				}, "5s").Should(Succeed())
 
@@ -35,6 +35,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	containerutil "sigs.k8s.io/cluster-api/util/container"
 	"sigs.k8s.io/cluster-api/util/version"
 	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
 )
@@ -1368,3 +1369,81 @@ func newKubeProxyDSWithImage(image string) appsv1.DaemonSet {
 	ds.Spec.Template.Spec.Containers[0].Image = image
 	return ds
 }
+
+func TestRewriteImageRepository(t *testing.T) {
+	defer containerutil.SetImageRewriter(containerutil.GetImageRewriter())
+
+	tests := []struct {
+		name            string
+		mirrors         []containerutil.ImageMirror
+		imageRepository string
+		architecture    string
+		want            string
+		wantErr         bool
+	}{
+		{
+			name:            "returns the image repository if there are no image mirrors",
+			imageRepository: "registry.k8s.io",
+			want:            "registry.k8s.io",
+		},
+		{
+			name:            "rewrites the image repository",
+			mirrors:         []containerutil.ImageMirror{{Source: "registry.k8s.io", Target: "mirror.example.com/k8s"}},
+			imageRepository: "registry.k8s.io",
+			want:            "mirror.example.com/k8s",
+		},
+		{
+			name: "rewrites the image repository for the architecture of the control plane Machines",
+			mirrors: []containerutil.ImageMirror{
+				{Source: "registry.k8s.io", Target: "mirror.example.com/k8s"},
+				{Source: "registry.k8s.io", Target: "arm64.example.com/k8s", Architecture: "arm64"},
+			},
+			imageRepository: "registry.k8s.io",
+			architecture:    "arm64",
+			want:            "arm64.example.com/k8s",
+		},
+		{
+			name:    "rewrites the default image repository if the image repository is empty",
+			mirrors: []containerutil.ImageMirror{{Source: "registry.k8s.io", Target: "mirror.example.com/k8s"}},
+			want:    "mirror.example.com/k8s",
+		},
+		{
+			name:    "returns empty if the image repository is empty and no image mirror applies to the default image repository",
+			mirrors: []containerutil.ImageMirror{{Source: "example.com", Target: "mirror.example.com/example"}},
+			want:    "",
+		},
+		{
+			name:            "returns the image repository if no image mirror applies",
+			mirrors:         []containerutil.ImageMirror{{Source: "registry.k8s.io", Target: "mirror.example.com/k8s"}},
+			imageRepository: "example.com/k8s",
+			want:            "example.com/k8s",
+		},
+		{
+			name:            "returns an error for an invalid image repository",
+			mirrors:         []containerutil.ImageMirror{{Source: "registry.k8s.io", Target: "mirror.example.com/k8s"}},
+			imageRepository: "Invalid Repository",
+			wantErr:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			var rewriter *containerutil.ImageRewriter
+			if tt.mirrors != nil {
+				var err error
+				rewriter, err = containerutil.NewImageRewriter(tt.mirrors, nil)
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+			containerutil.SetImageRewriter(rewriter)
+
+			got, err := RewriteImageRepository(tt.imageRepository, tt.architecture)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
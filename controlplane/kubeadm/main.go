@@ -43,6 +43,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -50,13 +51,23 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
 	kubeadmcontrolplanecontrollers "sigs.k8s.io/cluster-api/controlplane/kubeadm/controllers"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcd"
 	kcpwebhooks "sigs.k8s.io/cluster-api/controlplane/kubeadm/webhooks"
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api/feature"
 	controlplanev1alpha3 "sigs.k8s.io/cluster-api/internal/apis/controlplane/kubeadm/v1alpha3"
 	controlplanev1alpha4 "sigs.k8s.io/cluster-api/internal/apis/controlplane/kubeadm/v1alpha4"
+	"sigs.k8s.io/cluster-api/internal/util/metadatacache"
+	"sigs.k8s.io/cluster-api/internal/util/metrics"
+	"sigs.k8s.io/cluster-api/util/container"
 	"sigs.k8s.io/cluster-api/util/flags"
+	"sigs.k8s.io/cluster-api/util/priority"
+	"sigs.k8s.io/cluster-api/util/requeue"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/cluster-api/util/shard"
+	"sigs.k8s.io/cluster-api/util/throttle"
+	"sigs.k8s.io/cluster-api/util/webhookcerts"
 	"sigs.k8s.io/cluster-api/version"
 )
 
@@ -71,7 +82,6 @@ var (
 	leaderElectionRenewDeadline time.Duration
 	leaderElectionRetryPeriod   time.Duration
 	watchFilterValue            string
-	watchNamespace              string
 	profilerAddress             string
 	enableContentionProfiling   bool
 	syncPeriod                  time.Duration
@@ -81,13 +91,33 @@ var (
 	webhookCertDir              string
 	healthAddr                  string
 	tlsOptions                  = flags.TLSOptions{}
-	diagnosticsOptions          = flags.DiagnosticsOptions{}
-	logOptions                  = logs.NewOptions()
+	namespaceOptions            = flags.NamespaceOptions{}
+	webhookCertOptions          = flags.WebhookCertOptions{
+		ServiceName:  "capi-kubeadm-control-plane-webhook-service",
+		SecretName:   "capi-kubeadm-control-plane-webhook-service-cert",
+		ProviderName: "control-plane-kubeadm",
+	}
+	keyEncryptionOptions       = flags.KeyEncryptionOptions{}
+	caSignerOptions            = flags.CASignerOptions{}
+	requeueOptions             = flags.RequeueOptions{}
+	workloadClusterAuthOptions = flags.WorkloadClusterAuthOptions{}
+	priorityOptions            = flags.PriorityOptions{}
+	throttleOptions            = flags.ThrottleOptions{}
+	shardingOptions            = flags.ShardingOptions{}
+	tracingOptions             = flags.TracingOptions{}
+	conditionsMetricsOptions   = flags.ConditionsMetricsOptions{}
+	imageMirrorOptions         = flags.ImageMirrorOptions{}
+	diagnosticsOptions         = flags.DiagnosticsOptions{}
+	logOptions                 = logs.NewOptions()
 	// KCP specific flags.
 	kubeadmControlPlaneConcurrency int
 	clusterCacheTrackerConcurrency int
+	clusterCacheTrackerIdleTimeout time.Duration
 	etcdDialTimeout                time.Duration
 	etcdCallTimeout                time.Duration
+	kubeconfigClientCertTTL        time.Duration
+	watchControlPlaneComponents    bool
+	healthCheckPollInterval        time.Duration
 )
 
 func init() {
@@ -117,9 +147,6 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&leaderElectionRetryPeriod, "leader-elect-retry-period", 5*time.Second,
 		"Duration the LeaderElector clients should wait between tries of actions (duration string)")
 
-	fs.StringVar(&watchNamespace, "namespace", "",
-		"Namespace that the controller watches to reconcile cluster-api objects. If unspecified, the controller watches for cluster-api objects across all namespaces.")
-
 	fs.StringVar(&watchFilterValue, "watch-filter", "",
 		fmt.Sprintf("Label value that the controller watches to reconcile cluster-api objects. Label key is always %s. If unspecified, the controller watches for all cluster-api objects.", clusterv1.WatchLabel))
 
@@ -135,6 +162,10 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&clusterCacheTrackerConcurrency, "clustercachetracker-concurrency", 10,
 		"Number of clusters to process simultaneously")
 
+	fs.DurationVar(&clusterCacheTrackerIdleTimeout, "clustercachetracker-idle-timeout", 0,
+		"Duration after which the connection to a workload cluster which is not used by any controller is torn down; "+
+			"it is re-established on the next use. If zero, connections are never torn down for being idle.")
+
 	fs.DurationVar(&syncPeriod, "sync-period", 10*time.Minute,
 		"The minimum interval at which watched resources are reconciled (e.g. 15m)")
 
@@ -159,8 +190,29 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&etcdCallTimeout, "etcd-call-timeout-duration", etcd.DefaultCallTimeout,
 		"Duration that the etcd client waits at most for read and write operations to etcd.")
 
+	fs.DurationVar(&kubeconfigClientCertTTL, "kubeconfig-client-cert-ttl", 0,
+		"Lifespan of the client certificate in the Kubeconfig generated for each cluster; it must be at least four times the sync period. If not set, certificates are valid for one year.")
+
+	fs.BoolVar(&watchControlPlaneComponents, "watch-control-plane-components", true,
+		"Watch the control plane Nodes and the static pods of the control plane components in workload clusters instead of polling for their health.")
+
+	fs.DurationVar(&healthCheckPollInterval, "control-plane-health-check-poll-interval", 20*time.Second,
+		"Interval at which the health of the control plane Nodes and components is checked while it is not healthy and they are not watched.")
+
 	flags.AddDiagnosticsOptions(fs, &diagnosticsOptions)
 	flags.AddTLSOptions(fs, &tlsOptions)
+	flags.AddNamespaceOptions(fs, &namespaceOptions)
+	flags.AddWebhookCertOptions(fs, &webhookCertOptions)
+	flags.AddKeyEncryptionOptions(fs, &keyEncryptionOptions)
+	flags.AddCASignerOptions(fs, &caSignerOptions)
+	flags.AddRequeueOptions(fs, &requeueOptions)
+	flags.AddWorkloadClusterAuthOptions(fs, &workloadClusterAuthOptions)
+	flags.AddPriorityOptions(fs, &priorityOptions)
+	flags.AddThrottleOptions(fs, &throttleOptions)
+	flags.AddShardingOptions(fs, &shardingOptions)
+	flags.AddTracingOptions(fs, &tracingOptions)
+	flags.AddConditionsMetricsOptions(fs, &conditionsMetricsOptions)
+	flags.AddImageMirrorOptions(fs, &imageMirrorOptions)
 
 	feature.MutableGates.AddFlag(fs)
 }
@@ -188,6 +240,11 @@ func main() {
 	// klog.Background will automatically use the right logger.
 	ctrl.SetLogger(klog.Background())
 
+	if kubeconfigClientCertTTL != 0 && kubeconfigClientCertTTL < 4*syncPeriod {
+		setupLog.Error(fmt.Errorf("--kubeconfig-client-cert-ttl must be at least four times --sync-period (%s)", syncPeriod), "unable to start manager")
+		os.Exit(1)
+	}
+
 	restConfig := ctrl.GetConfigOrDie()
 	restConfig.QPS = restConfigQPS
 	restConfig.Burst = restConfigBurst
@@ -199,13 +256,60 @@ func main() {
 		os.Exit(1)
 	}
 
+	webhookCerts, err := flags.GetWebhookCertOptions(webhookCertOptions, webhookCertDir)
+	if err != nil {
+		setupLog.Error(err, "unable to configure webhook certificates")
+		os.Exit(1)
+	}
+
+	keyEncryptionService, err := flags.GetKeyEncryptionService(keyEncryptionOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure cluster certificate key encryption")
+		os.Exit(1)
+	}
+	secret.SetKeyEncryptionService(keyEncryptionService)
+
+	requeuePolicy, err := flags.GetRequeuePolicy(requeueOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure requeue policy")
+		os.Exit(1)
+	}
+	requeue.SetPolicy(requeuePolicy)
+
+	workloadClusterAuthPolicy, err := flags.GetWorkloadClusterAuthPolicy(workloadClusterAuthOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure workload cluster authentication")
+		os.Exit(1)
+	}
+	remote.SetWorkloadClusterAuthPolicy(workloadClusterAuthPolicy)
+
+	priorityPolicy, err := flags.GetPriorityPolicy(priorityOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure priority policy")
+		os.Exit(1)
+	}
+	priority.SetPolicy(priorityPolicy)
+
+	throttlePolicy, err := flags.GetThrottlePolicy(throttleOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure per-cluster reconcile limits")
+		os.Exit(1)
+	}
+	throttle.SetPolicy(throttlePolicy)
+
+	imageRewriter, err := flags.GetImageRewriter(imageMirrorOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure image mirrors")
+		os.Exit(1)
+	}
+	container.SetImageRewriter(imageRewriter)
+
 	diagnosticsOpts := flags.GetDiagnosticsOptions(diagnosticsOptions)
 
-	var watchNamespaces map[string]cache.Config
-	if watchNamespace != "" {
-		watchNamespaces = map[string]cache.Config{
-			watchNamespace: {},
-		}
+	watchNamespaces, err := flags.GetWatchNamespaces(namespaceOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure watch namespaces")
+		os.Exit(1)
 	}
 
 	if enableContentionProfiling {
@@ -218,7 +322,7 @@ func main() {
 	ctrlOptions := ctrl.Options{
 		Scheme:                     scheme,
 		LeaderElection:             enableLeaderElection,
-		LeaderElectionID:           "kubeadm-control-plane-manager-leader-election-capi",
+		LeaderElectionID:           flags.GetShardLeaderElectionID("kubeadm-control-plane-manager-leader-election-capi", shardingOptions),
 		LeaseDuration:              &leaderElectionLeaseDuration,
 		RenewDeadline:              &leaderElectionRenewDeadline,
 		RetryPeriod:                &leaderElectionRetryPeriod,
@@ -230,9 +334,10 @@ func main() {
 			DefaultNamespaces: watchNamespaces,
 			SyncPeriod:        &syncPeriod,
 			ByObject: map[client.Object]cache.ByObject{
-				// Note: Only Secrets with the cluster name label are cached.
+				// Note: Only Secrets with the cluster name label are cached, and only their metadata is cached.
 				// The default client of the manager won't use the cache for secrets at all (see Client.Cache.DisableFor).
-				// The cached secrets will only be used by the secretCachingClient we create below.
+				// The cached metadata will only be used by the secretCachingClient we create below, which fetches
+				// the full Secrets from the API server only when they changed.
 				&corev1.Secret{}: {
 					Label: clusterSecretCacheSelector,
 				},
@@ -266,20 +371,63 @@ func main() {
 		os.Exit(1)
 	}
 
+	managerShard, err := flags.GetShard(shardingOptions, mgr.GetClient())
+	if err != nil {
+		setupLog.Error(err, "unable to configure sharding")
+		os.Exit(1)
+	}
+	shard.Set(managerShard)
+
 	// Setup the context that's going to be used in controllers and for the manager.
 	ctx := ctrl.SetupSignalHandler()
 
+	setupWebhookCerts(ctx, mgr, webhookCerts)
 	setupChecks(mgr)
 	setupReconcilers(ctx, mgr)
+	setupConditionsMetrics(mgr)
 	setupWebhooks(mgr)
 
+	shutdownTracing, err := flags.SetupTracing(ctx, "capi-kubeadm-control-plane-controller-manager", tracingOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to setup tracing")
+		os.Exit(1)
+	}
+
 	setupLog.Info("starting manager", "version", version.Get().String())
-	if err := mgr.Start(ctx); err != nil {
+	err = mgr.Start(ctx)
+	// Flush the spans not exported yet before exiting.
+	if err := shutdownTracing(context.Background()); err != nil {
+		setupLog.Error(err, "unable to shutdown tracing")
+	}
+	if err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
 
+// setupWebhookCerts ensures the self-managed webhook certificates exist before the webhook server is started
+// and keeps them valid while the manager is running.
+func setupWebhookCerts(ctx context.Context, mgr ctrl.Manager, options *webhookcerts.Options) {
+	if options == nil {
+		return
+	}
+	// The cache of the manager is not started yet, and there is no need to cache these objects anyway.
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		setupLog.Error(err, "unable to create client for webhook certificates")
+		os.Exit(1)
+	}
+	certManager := webhookcerts.New(c, *options)
+	if _, err := certManager.Ensure(ctx); err != nil {
+		setupLog.Error(err, "unable to ensure webhook certificates")
+		os.Exit(1)
+	}
+	if err := mgr.Add(certManager); err != nil {
+		setupLog.Error(err, "unable to add webhook certificates manager")
+		os.Exit(1)
+	}
+}
+
 func setupChecks(mgr ctrl.Manager) {
 	if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
 		setupLog.Error(err, "unable to create ready check")
@@ -292,24 +440,41 @@ func setupChecks(mgr ctrl.Manager) {
 	}
 }
 
+// setupConditionsMetrics registers the collector exposing the conditions of the objects reconciled by the manager,
+// if enabled with --conditions-metrics-types.
+func setupConditionsMetrics(mgr ctrl.Manager) {
+	if len(conditionsMetricsOptions.ConditionTypes) == 0 {
+		return
+	}
+	ctrlmetrics.Registry.MustRegister(metrics.NewConditionsCollector(mgr.GetClient(), conditionsMetricsOptions.ConditionTypes, &controlplanev1.KubeadmControlPlaneList{}))
+}
+
 func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
-	secretCachingClient, err := client.New(mgr.GetConfig(), client.Options{
-		HTTPClient: mgr.GetHTTPClient(),
-		Cache: &client.CacheOptions{
-			Reader: mgr.GetCache(),
-		},
+	secretCachingClient, err := metadatacache.NewClient(mgr.GetClient(), mgr.GetCache(), metadatacache.Options{
+		Objects: []client.Object{&corev1.Secret{}},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to create secret caching client")
 		os.Exit(1)
 	}
 
+	// Restrict the cache of Pods to the static pods of the control plane components, which are read
+	// from the cache when the control plane components are watched.
+	var cacheByObject map[client.Object]cache.ByObject
+	if watchControlPlaneComponents {
+		cacheByObject = map[client.Object]cache.ByObject{
+			&corev1.Pod{}: internal.StaticPodsCacheByObject(),
+		}
+	}
+
 	// Set up a ClusterCacheTracker to provide to controllers
 	// requiring a connection to a remote cluster
 	tracker, err := remote.NewClusterCacheTracker(mgr, remote.ClusterCacheTrackerOptions{
 		SecretCachingClient: secretCachingClient,
 		ControllerName:      controllerName,
+		IdleTimeout:         clusterCacheTrackerIdleTimeout,
 		Log:                 &ctrl.Log,
+		CacheByObject:       cacheByObject,
 		ClientUncachedObjects: []client.Object{
 			&corev1.ConfigMap{},
 			&corev1.Secret{},
@@ -331,13 +496,29 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 		os.Exit(1)
 	}
 
+	// CertificateSigningRequests are only created and read back while signing, so there is no need to cache them.
+	caSignerClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		setupLog.Error(err, "unable to create client for cluster CA signer")
+		os.Exit(1)
+	}
+	caSigner, err := flags.GetCASigner(caSignerOptions, caSignerClient)
+	if err != nil {
+		setupLog.Error(err, "unable to create cluster CA signer")
+		os.Exit(1)
+	}
+
 	if err := (&kubeadmcontrolplanecontrollers.KubeadmControlPlaneReconciler{
-		Client:              mgr.GetClient(),
-		SecretCachingClient: secretCachingClient,
-		Tracker:             tracker,
-		WatchFilterValue:    watchFilterValue,
-		EtcdDialTimeout:     etcdDialTimeout,
-		EtcdCallTimeout:     etcdCallTimeout,
+		Client:                      mgr.GetClient(),
+		SecretCachingClient:         secretCachingClient,
+		Tracker:                     tracker,
+		WatchFilterValue:            watchFilterValue,
+		EtcdDialTimeout:             etcdDialTimeout,
+		EtcdCallTimeout:             etcdCallTimeout,
+		KubeconfigClientCertTTL:     kubeconfigClientCertTTL,
+		WatchControlPlaneComponents: watchControlPlaneComponents,
+		HealthCheckPollInterval:     healthCheckPollInterval,
+		CASigner:                    caSigner,
 	}).SetupWithManager(ctx, mgr, concurrency(kubeadmControlPlaneConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KubeadmControlPlane")
 		os.Exit(1)
@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	// ClusterGroupUpgradeStartedAnnotation is the annotation set on a Cluster by the ClusterGroup controller when
+	// it starts upgrading the Cluster, or when it first observes a Cluster already set to the upgrade but not healthy;
+	// its value is the start time in the RFC3339 format.
+	ClusterGroupUpgradeStartedAnnotation = "clustergroup.cluster.x-k8s.io/upgrade-started"
+)
+
+// ClusterGroupUpgradePhase is the phase of the upgrade of a ClusterGroup.
+type ClusterGroupUpgradePhase string
+
+const (
+	// ClusterGroupUpgradePhaseProgressing is the phase of a ClusterGroup upgrading its Clusters.
+	ClusterGroupUpgradePhaseProgressing ClusterGroupUpgradePhase = "Progressing"
+
+	// ClusterGroupUpgradePhasePaused is the phase of a ClusterGroup whose upgrade is paused;
+	// Clusters already upgrading complete their upgrade, but no new upgrade is started.
+	ClusterGroupUpgradePhasePaused ClusterGroupUpgradePhase = "Paused"
+
+	// ClusterGroupUpgradePhaseCompleted is the phase of a ClusterGroup whose Clusters are all upgraded and healthy.
+	ClusterGroupUpgradePhaseCompleted ClusterGroupUpgradePhase = "Completed"
+
+	// ClusterGroupUpgradePhaseFailed is the phase of a ClusterGroup with at least one Cluster not healthy
+	// within the progress deadline after starting its upgrade; no new upgrade is started.
+	ClusterGroupUpgradePhaseFailed ClusterGroupUpgradePhase = "Failed"
+
+	// ClusterGroupUpgradePhaseAborted is the phase of a ClusterGroup whose upgrade has been aborted;
+	// Clusters already upgrading complete their upgrade, but no new upgrade is started.
+	ClusterGroupUpgradePhaseAborted ClusterGroupUpgradePhase = "Aborted"
+)
+
+// ANCHOR: ClusterGroupSpec
+
+// ClusterGroupSpec defines the desired state of ClusterGroup.
+type ClusterGroupSpec struct {
+	// ClusterSelector selects the Clusters of the group in the namespace of the ClusterGroup.
+	// Only Clusters with a managed topology can be upgraded by the ClusterGroup.
+	ClusterSelector metav1.LabelSelector `json:"clusterSelector"`
+
+	// Upgrade defines the upgrade to roll out across the Clusters of the group.
+	// +optional
+	Upgrade *ClusterGroupUpgrade `json:"upgrade,omitempty"`
+}
+
+// ANCHOR_END: ClusterGroupSpec
+
+// ANCHOR: ClusterGroupUpgrade
+
+// ClusterGroupUpgrade defines the upgrade of the Clusters of a ClusterGroup.
+type ClusterGroupUpgrade struct {
+	// Version is the Kubernetes version to set in the spec.topology.version field of the Clusters.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Class is the name of the ClusterClass to set in the spec.topology.class field of the Clusters.
+	// +optional
+	Class string `json:"class,omitempty"`
+
+	// CanarySelector selects the canary Clusters of the group; canary Clusters are upgraded first,
+	// and the other Clusters are upgraded only after all the canary Clusters are upgraded and healthy.
+	// +optional
+	CanarySelector *metav1.LabelSelector `json:"canarySelector,omitempty"`
+
+	// MaxConcurrency is the maximum number of Clusters upgrading at the same time; defaults to 1.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrency *int32 `json:"maxConcurrency,omitempty"`
+
+	// ProgressDeadline is the maximum time for a Cluster to be healthy after its upgrade started;
+	// if a Cluster is not healthy within the deadline the upgrade of the group fails, and no new upgrade is started.
+	// A Cluster is healthy when it is ready, its topology is reconciled, its control plane runs the upgrade version
+	// and its MachineDeployments are rolled out to the upgrade version.
+	// If not set, there is no deadline.
+	// +optional
+	ProgressDeadline *metav1.Duration `json:"progressDeadline,omitempty"`
+
+	// Paused pauses the upgrade: Clusters already upgrading complete their upgrade, but no new upgrade is started.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// Abort aborts the upgrade: Clusters already upgrading complete their upgrade, no new upgrade is started
+	// and the upgrade is reported as aborted. Unlike Paused, Abort is meant to stop an upgrade that should not
+	// be completed, e.g. before changing it to a new version.
+	// +optional
+	Abort bool `json:"abort,omitempty"`
+}
+
+// ANCHOR_END: ClusterGroupUpgrade
+
+// ANCHOR: ClusterGroupStatus
+
+// ClusterGroupStatus defines the observed state of ClusterGroup.
+type ClusterGroupStatus struct {
+	// Phase is the phase of the upgrade of the group.
+	// +optional
+	Phase ClusterGroupUpgradePhase `json:"phase,omitempty"`
+
+	// Clusters is the number of Clusters of the group with a managed topology.
+	// +optional
+	Clusters int32 `json:"clusters"`
+
+	// UpdatedClusters is the number of Clusters of the group upgraded and healthy.
+	// +optional
+	UpdatedClusters int32 `json:"updatedClusters"`
+
+	// UpgradingClusters are the names of the Clusters of the group upgrading.
+	// +optional
+	UpgradingClusters []string `json:"upgradingClusters,omitempty"`
+
+	// FailedClusters are the names of the Clusters of the group not healthy within the progress deadline.
+	// +optional
+	FailedClusters []string `json:"failedClusters,omitempty"`
+
+	// ObservedGeneration is the latest generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions defines current service state of the ClusterGroup.
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+}
+
+// ANCHOR_END: ClusterGroupStatus
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=clustergroups,scope=Namespaced,categories=cluster-api
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".spec.upgrade.version",description="Kubernetes version of the upgrade"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Phase of the upgrade"
+// +kubebuilder:printcolumn:name="Clusters",type="integer",JSONPath=".status.clusters",description="Number of Clusters of the group"
+// +kubebuilder:printcolumn:name="Updated",type="integer",JSONPath=".status.updatedClusters",description="Number of Clusters upgraded and healthy"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Time duration since creation of ClusterGroup"
+// +k8s:conversion-gen=false
+
+// ClusterGroup is the Schema for the clustergroups API.
+// A ClusterGroup rolls out an upgrade across a set of Clusters with a managed topology, one batch at a time,
+// waiting for the upgraded Clusters to be healthy before upgrading the next ones.
+type ClusterGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterGroupSpec   `json:"spec,omitempty"`
+	Status ClusterGroupStatus `json:"status,omitempty"`
+}
+
+// GetConditions returns the set of conditions for this object.
+func (g *ClusterGroup) GetConditions() clusterv1.Conditions {
+	return g.Status.Conditions
+}
+
+// SetConditions sets the conditions on this object.
+func (g *ClusterGroup) SetConditions(conditions clusterv1.Conditions) {
+	g.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterGroupList contains a list of ClusterGroup.
+type ClusterGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterGroup `json:"items"`
+}
+
+func init() {
+	objectTypes = append(objectTypes, &ClusterGroup{}, &ClusterGroupList{})
+}
@@ -28,3 +28,39 @@ const (
 	// to be ready.
 	WaitingForReplicasReadyReason = "WaitingForReplicasReady"
 )
+
+// Conditions and condition Reasons for the OrphanReport object.
+
+const (
+	// NoOrphansCondition reports whether the last scan of an OrphanReport detected orphaned resources.
+	NoOrphansCondition clusterv1.ConditionType = "NoOrphans"
+
+	// OrphansDetectedReason (Severity=Warning) documents an OrphanReport whose last scan detected orphaned resources.
+	OrphansDetectedReason = "OrphansDetected"
+
+	// ScanFailedReason (Severity=Error) documents an OrphanReport whose last scan failed.
+	ScanFailedReason = "ScanFailed"
+
+	// OrphanCleanupFailedReason (Severity=Error) documents an OrphanReport failing to delete orphaned resources.
+	OrphanCleanupFailedReason = "OrphanCleanupFailed"
+)
+
+// Conditions and condition Reasons for the ClusterGroup object.
+
+const (
+	// ClusterGroupUpgradeCompletedCondition reports whether all the Clusters of a ClusterGroup are upgraded and healthy.
+	ClusterGroupUpgradeCompletedCondition clusterv1.ConditionType = "UpgradeCompleted"
+
+	// ClusterGroupUpgradeInProgressReason (Severity=Info) documents a ClusterGroup upgrading its Clusters.
+	ClusterGroupUpgradeInProgressReason = "UpgradeInProgress"
+
+	// ClusterGroupUpgradePausedReason (Severity=Info) documents a ClusterGroup whose upgrade is paused.
+	ClusterGroupUpgradePausedReason = "UpgradePaused"
+
+	// ClusterGroupUpgradeAbortedReason (Severity=Warning) documents a ClusterGroup whose upgrade has been aborted.
+	ClusterGroupUpgradeAbortedReason = "UpgradeAborted"
+
+	// ClusterGroupUpgradeFailedReason (Severity=Error) documents a ClusterGroup with at least one Cluster
+	// not healthy within the progress deadline.
+	ClusterGroupUpgradeFailedReason = "UpgradeFailed"
+)
@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// OrphanCleanupPolicy defines what to do with the orphaned resources detected by an OrphanReport.
+// +kubebuilder:validation:Enum=Report;Delete
+type OrphanCleanupPolicy string
+
+const (
+	// OrphanCleanupPolicyReport only reports orphaned resources.
+	OrphanCleanupPolicyReport OrphanCleanupPolicy = "Report"
+
+	// OrphanCleanupPolicyDelete reports and deletes orphaned resources.
+	OrphanCleanupPolicyDelete OrphanCleanupPolicy = "Delete"
+)
+
+// OrphanReason is the reason why a resource is considered orphaned.
+type OrphanReason string
+
+const (
+	// OrphanReasonClusterNotFound is used for resources labeled with the name of a Cluster that does not exist.
+	OrphanReasonClusterNotFound OrphanReason = "ClusterNotFound"
+
+	// OrphanReasonOwnerNotFound is used for resources whose owners do not exist.
+	OrphanReasonOwnerNotFound OrphanReason = "OwnerNotFound"
+
+	// OrphanReasonExternal is used for external resources, e.g. cloud resources, reported as orphaned by a provider.
+	OrphanReasonExternal OrphanReason = "External"
+)
+
+// ANCHOR: OrphanReportSpec
+
+// OrphanReportSpec defines the desired state of OrphanReport.
+type OrphanReportSpec struct {
+	// CleanupPolicy defines what to do with the detected orphaned resources.
+	// Report only reports them in the status, Delete also deletes them; defaults to Report.
+	// +kubebuilder:default=Report
+	// +optional
+	CleanupPolicy OrphanCleanupPolicy `json:"cleanupPolicy,omitempty"`
+
+	// MinimumAge is the minimum age of a resource before it can be considered orphaned, so resources
+	// being created are not reported while their owners are set; defaults to 10 minutes.
+	// +optional
+	MinimumAge *metav1.Duration `json:"minimumAge,omitempty"`
+
+	// ScanInterval is the interval between scans of the namespace of the OrphanReport; defaults to 10 minutes.
+	// +optional
+	ScanInterval *metav1.Duration `json:"scanInterval,omitempty"`
+}
+
+// ANCHOR_END: OrphanReportSpec
+
+// ANCHOR: OrphanedResource
+
+// OrphanedResource is a resource not backed by any live Cluster or Machine.
+type OrphanedResource struct {
+	// APIVersion of the orphaned resource; empty for external resources.
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Kind of the orphaned resource.
+	Kind string `json:"kind"`
+
+	// Name of the orphaned resource, or its identifier for external resources.
+	Name string `json:"name"`
+
+	// Provider is the name of the provider which reported the external resource; empty for Kubernetes objects.
+	// +optional
+	Provider string `json:"provider,omitempty"`
+
+	// Reason is the reason why the resource is considered orphaned.
+	Reason OrphanReason `json:"reason"`
+
+	// Message is a human readable message with details about the orphaned resource.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ANCHOR_END: OrphanedResource
+
+// ANCHOR: OrphanReportStatus
+
+// OrphanReportStatus defines the observed state of OrphanReport.
+type OrphanReportStatus struct {
+	// Orphans are the orphaned resources detected by the last scan.
+	// +optional
+	Orphans []OrphanedResource `json:"orphans,omitempty"`
+
+	// OrphanCount is the number of orphaned resources detected by the last scan.
+	// +optional
+	OrphanCount int32 `json:"orphanCount"`
+
+	// DeletedCount is the number of orphaned resources deleted by the last scan.
+	// +optional
+	DeletedCount int32 `json:"deletedCount,omitempty"`
+
+	// LastScanTime is the time of the last scan.
+	// +optional
+	LastScanTime *metav1.Time `json:"lastScanTime,omitempty"`
+
+	// ObservedGeneration is the latest generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions defines current service state of the OrphanReport.
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+}
+
+// ANCHOR_END: OrphanReportStatus
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=orphanreports,scope=Namespaced,categories=cluster-api
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Policy",type="string",JSONPath=".spec.cleanupPolicy",description="Cleanup policy of the orphaned resources"
+// +kubebuilder:printcolumn:name="Orphans",type="integer",JSONPath=".status.orphanCount",description="Number of orphaned resources detected by the last scan"
+// +kubebuilder:printcolumn:name="Last Scan",type="date",JSONPath=".status.lastScanTime",description="Time of the last scan"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Time duration since creation of OrphanReport"
+// +k8s:conversion-gen=false
+
+// OrphanReport is the Schema for the orphanreports API.
+// An OrphanReport periodically scans its namespace for infrastructure resources not backed by any live
+// Cluster or Machine, e.g. because a Cluster has been force-deleted, and reports them in its status.
+type OrphanReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OrphanReportSpec   `json:"spec,omitempty"`
+	Status OrphanReportStatus `json:"status,omitempty"`
+}
+
+// GetConditions returns the set of conditions for this object.
+func (r *OrphanReport) GetConditions() clusterv1.Conditions {
+	return r.Status.Conditions
+}
+
+// SetConditions sets the conditions on this object.
+func (r *OrphanReport) SetConditions(conditions clusterv1.Conditions) {
+	r.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+
+// OrphanReportList contains a list of OrphanReport.
+type OrphanReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OrphanReport `json:"items"`
+}
+
+func init() {
+	objectTypes = append(objectTypes, &OrphanReport{}, &OrphanReportList{})
+}
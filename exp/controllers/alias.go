@@ -25,6 +25,7 @@ import (
 
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	machinepool "sigs.k8s.io/cluster-api/exp/internal/controllers"
+	"sigs.k8s.io/cluster-api/exp/orphans"
 )
 
 // MachinePoolReconciler reconciles a MachinePool object.
@@ -45,3 +46,40 @@ func (r *MachinePoolReconciler) SetupWithManager(ctx context.Context, mgr ctrl.M
 		WatchFilterValue: r.WatchFilterValue,
 	}).SetupWithManager(ctx, mgr, options)
 }
+
+// OrphanReportReconciler reconciles an OrphanReport object.
+type OrphanReportReconciler struct {
+	Client    client.Client
+	APIReader client.Reader
+
+	// Detectors are used to detect orphaned external resources, e.g. cloud resources, in addition to
+	// the orphaned infrastructure objects.
+	Detectors []orphans.ExternalResourceDetector
+
+	// WatchFilterValue is the label value used to filter events prior to reconciliation.
+	WatchFilterValue string
+}
+
+func (r *OrphanReportReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	return (&machinepool.OrphanReportReconciler{
+		Client:           r.Client,
+		APIReader:        r.APIReader,
+		Detectors:        r.Detectors,
+		WatchFilterValue: r.WatchFilterValue,
+	}).SetupWithManager(ctx, mgr, options)
+}
+
+// ClusterGroupReconciler reconciles a ClusterGroup object.
+type ClusterGroupReconciler struct {
+	Client client.Client
+
+	// WatchFilterValue is the label value used to filter events prior to reconciliation.
+	WatchFilterValue string
+}
+
+func (r *ClusterGroupReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	return (&machinepool.ClusterGroupReconciler{
+		Client:           r.Client,
+		WatchFilterValue: r.WatchFilterValue,
+	}).SetupWithManager(ctx, mgr, options)
+}
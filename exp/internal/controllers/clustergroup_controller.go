@@ -0,0 +1,427 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	"sigs.k8s.io/cluster-api/internal/contract"
+	"sigs.k8s.io/cluster-api/internal/topology/check"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+)
+
+// +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments;machines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clustergroups;clustergroups/status,verbs=get;list;watch;update;patch
+
+// clusterGroupRequeueAfter is the interval between checks of the Clusters of a ClusterGroup while upgrading.
+const clusterGroupRequeueAfter = 30 * time.Second
+
+// ClusterGroupReconciler reconciles a ClusterGroup object.
+type ClusterGroupReconciler struct {
+	Client client.Client
+
+	// WatchFilterValue is the label value used to filter events prior to reconciliation.
+	WatchFilterValue string
+
+	recorder record.EventRecorder
+}
+
+func (r *ClusterGroupReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&expv1.ClusterGroup{}).
+		Watches(
+			&clusterv1.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(r.clusterToClusterGroups),
+		).
+		WithOptions(options).
+		WithEventFilter(predicates.ResourceHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
+		Complete(r)
+	if err != nil {
+		return errors.Wrap(err, "failed setting up with a controller manager")
+	}
+
+	r.recorder = mgr.GetEventRecorderFor("clustergroup-controller")
+	return nil
+}
+
+func (r *ClusterGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	group := &expv1.ClusterGroup{}
+	if err := r.Client.Get(ctx, req.NamespacedName, group); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !group.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	// Initialize the patch helper.
+	patchHelper, err := patch.NewHelper(group, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	defer func() {
+		// Always update the readyCondition with the summary of the ClusterGroup conditions.
+		conditions.SetSummary(group, conditions.WithConditions(expv1.ClusterGroupUpgradeCompletedCondition))
+
+		patchOpts := []patch.Option{
+			patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+				clusterv1.ReadyCondition,
+				expv1.ClusterGroupUpgradeCompletedCondition,
+			}},
+		}
+		if reterr == nil {
+			patchOpts = append(patchOpts, patch.WithStatusObservedGeneration{})
+		}
+		if err := patchHelper.Patch(ctx, group, patchOpts...); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	return r.reconcile(ctx, group)
+}
+
+func (r *ClusterGroupReconciler) reconcile(ctx context.Context, group *expv1.ClusterGroup) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	clusters, err := r.getClusters(ctx, group)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	group.Status.Clusters = int32(len(clusters))
+	group.Status.UpdatedClusters = 0
+	group.Status.UpgradingClusters = nil
+	group.Status.FailedClusters = nil
+
+	upgrade := group.Spec.Upgrade
+	if upgrade == nil {
+		group.Status.Phase = ""
+		conditions.Delete(group, expv1.ClusterGroupUpgradeCompletedCondition)
+		return ctrl.Result{}, nil
+	}
+
+	canarySelector := labels.Nothing()
+	if upgrade.CanarySelector != nil {
+		if canarySelector, err = metav1.LabelSelectorAsSelector(upgrade.CanarySelector); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to parse canary selector")
+		}
+	}
+
+	// Classify the Clusters of the group.
+	now := time.Now()
+	var pendingCanaries, pending []*clusterv1.Cluster
+	upgradingCanaries := 0
+	for _, cluster := range clusters {
+		if !isClusterUpgradeTarget(cluster, upgrade) {
+			if canarySelector.Matches(labels.Set(cluster.Labels)) {
+				pendingCanaries = append(pendingCanaries, cluster)
+			} else {
+				pending = append(pending, cluster)
+			}
+			continue
+		}
+
+		healthy, err := r.isClusterHealthy(ctx, cluster, upgrade)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if healthy {
+			group.Status.UpdatedClusters++
+			if err := r.completeClusterUpgrade(ctx, cluster); err != nil {
+				return ctrl.Result{}, err
+			}
+			continue
+		}
+
+		// A Cluster set to the upgrade outside of the group, e.g. by its owner, is tracked like the Clusters
+		// upgraded by the group, so it can't hold a slot of MaxConcurrency beyond the progress deadline.
+		if _, ok := cluster.Annotations[expv1.ClusterGroupUpgradeStartedAnnotation]; !ok {
+			if err := r.markClusterUpgradeStarted(ctx, cluster, now); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		group.Status.UpgradingClusters = append(group.Status.UpgradingClusters, cluster.Name)
+		if canarySelector.Matches(labels.Set(cluster.Labels)) {
+			upgradingCanaries++
+		}
+		if isClusterUpgradeExpired(cluster, upgrade, now) {
+			group.Status.FailedClusters = append(group.Status.FailedClusters, cluster.Name)
+		}
+	}
+
+	switch {
+	case len(pendingCanaries)+len(pending) == 0 && len(group.Status.UpgradingClusters) == 0:
+		group.Status.Phase = expv1.ClusterGroupUpgradePhaseCompleted
+		conditions.MarkTrue(group, expv1.ClusterGroupUpgradeCompletedCondition)
+		return ctrl.Result{}, nil
+	case len(group.Status.FailedClusters) > 0:
+		group.Status.Phase = expv1.ClusterGroupUpgradePhaseFailed
+		conditions.MarkFalse(group, expv1.ClusterGroupUpgradeCompletedCondition, expv1.ClusterGroupUpgradeFailedReason, clusterv1.ConditionSeverityError,
+			"Clusters %s not healthy within the progress deadline", strings.Join(group.Status.FailedClusters, ", "))
+		return ctrl.Result{RequeueAfter: clusterGroupRequeueAfter}, nil
+	case upgrade.Abort:
+		group.Status.Phase = expv1.ClusterGroupUpgradePhaseAborted
+		conditions.MarkFalse(group, expv1.ClusterGroupUpgradeCompletedCondition, expv1.ClusterGroupUpgradeAbortedReason, clusterv1.ConditionSeverityWarning,
+			"Upgrade aborted, %d of %d Clusters upgraded", group.Status.UpdatedClusters, group.Status.Clusters)
+		return ctrl.Result{RequeueAfter: clusterGroupRequeueAfter}, nil
+	case upgrade.Paused:
+		group.Status.Phase = expv1.ClusterGroupUpgradePhasePaused
+		conditions.MarkFalse(group, expv1.ClusterGroupUpgradeCompletedCondition, expv1.ClusterGroupUpgradePausedReason, clusterv1.ConditionSeverityInfo,
+			"Upgrade paused, %d of %d Clusters upgraded", group.Status.UpdatedClusters, group.Status.Clusters)
+		return ctrl.Result{RequeueAfter: clusterGroupRequeueAfter}, nil
+	}
+
+	group.Status.Phase = expv1.ClusterGroupUpgradePhaseProgressing
+
+	// Canary Clusters are upgraded first; the other Clusters are upgraded only after all the canary Clusters are healthy.
+	candidates := pendingCanaries
+	if len(pendingCanaries) == 0 && upgradingCanaries == 0 {
+		candidates = pending
+	}
+
+	slots := int(ptr.Deref(upgrade.MaxConcurrency, 1)) - len(group.Status.UpgradingClusters)
+	for i := 0; i < slots && i < len(candidates); i++ {
+		cluster := candidates[i]
+		log.Info("Starting Cluster upgrade", "Cluster", klog.KObj(cluster), "version", upgrade.Version, "class", upgrade.Class)
+		if err := r.startClusterUpgrade(ctx, cluster, upgrade, now); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.recorder.Eventf(group, "Normal", "ClusterUpgradeStarted", "Started upgrade of Cluster %s", cluster.Name)
+		group.Status.UpgradingClusters = append(group.Status.UpgradingClusters, cluster.Name)
+	}
+	sort.Strings(group.Status.UpgradingClusters)
+
+	conditions.MarkFalse(group, expv1.ClusterGroupUpgradeCompletedCondition, expv1.ClusterGroupUpgradeInProgressReason, clusterv1.ConditionSeverityInfo,
+		"Upgrading Clusters %s, %d of %d Clusters upgraded", strings.Join(group.Status.UpgradingClusters, ", "), group.Status.UpdatedClusters, group.Status.Clusters)
+	return ctrl.Result{RequeueAfter: clusterGroupRequeueAfter}, nil
+}
+
+// getClusters returns the Clusters of the group with a managed topology, sorted by name.
+func (r *ClusterGroupReconciler) getClusters(ctx context.Context, group *expv1.ClusterGroup) ([]*clusterv1.Cluster, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&group.Spec.ClusterSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse cluster selector")
+	}
+	// If a ClusterGroup with an empty selector creeps in, it should match nothing, not everything.
+	if selector.Empty() {
+		return nil, nil
+	}
+
+	clusterList := &clusterv1.ClusterList{}
+	if err := r.Client.List(ctx, clusterList, client.InNamespace(group.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, errors.Wrap(err, "failed to list Clusters")
+	}
+
+	clusters := []*clusterv1.Cluster{}
+	for i := range clusterList.Items {
+		cluster := &clusterList.Items[i]
+		if cluster.Spec.Topology == nil || !cluster.DeletionTimestamp.IsZero() {
+			continue
+		}
+		clusters = append(clusters, cluster)
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].Name < clusters[j].Name })
+	return clusters, nil
+}
+
+// isClusterUpgradeTarget returns true if the topology of the Cluster is set to the upgrade.
+func isClusterUpgradeTarget(cluster *clusterv1.Cluster, upgrade *expv1.ClusterGroupUpgrade) bool {
+	return (upgrade.Version == "" || cluster.Spec.Topology.Version == upgrade.Version) &&
+		(upgrade.Class == "" || cluster.Spec.Topology.Class == upgrade.Class)
+}
+
+// isClusterHealthy returns true if the Cluster is ready, its topology is reconciled, its control plane
+// runs the upgrade version and its MachineDeployments are rolled out to the upgrade version.
+func (r *ClusterGroupReconciler) isClusterHealthy(ctx context.Context, cluster *clusterv1.Cluster, upgrade *expv1.ClusterGroupUpgrade) (bool, error) {
+	if cluster.Status.ObservedGeneration < cluster.Generation ||
+		!conditions.IsTrue(cluster, clusterv1.ReadyCondition) ||
+		!conditions.IsTrue(cluster, clusterv1.TopologyReconciledCondition) {
+		return false, nil
+	}
+
+	// The TopologyReconciled condition could not yet reflect a version change, so also check the control plane version.
+	if upgrade.Version != "" && cluster.Spec.ControlPlaneRef != nil {
+		controlPlane, err := external.Get(ctx, r.Client, cluster.Spec.ControlPlaneRef, cluster.Namespace)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to get control plane of Cluster %s", klog.KObj(cluster))
+		}
+		version, err := contract.ControlPlane().StatusVersion().Get(controlPlane)
+		if err != nil {
+			if errors.Is(err, contract.ErrFieldNotFound) {
+				return false, nil
+			}
+			return false, errors.Wrapf(err, "failed to get control plane version of Cluster %s", klog.KObj(cluster))
+		}
+		if *version != upgrade.Version {
+			return false, nil
+		}
+	}
+
+	// The TopologyReconciled condition is true as soon as the last MachineDeployment picks up the version,
+	// so also check that all the MachineDeployments are rolled out.
+	return r.areMachineDeploymentsRolledOut(ctx, cluster, upgrade)
+}
+
+// areMachineDeploymentsRolledOut returns true if all the MachineDeployments of the topology of the Cluster have the
+// upgrade version, none of them is upgrading and all their replicas are up to date and available.
+func (r *ClusterGroupReconciler) areMachineDeploymentsRolledOut(ctx context.Context, cluster *clusterv1.Cluster, upgrade *expv1.ClusterGroupUpgrade) (bool, error) {
+	mds := &clusterv1.MachineDeploymentList{}
+	if err := r.Client.List(ctx, mds, client.InNamespace(cluster.Namespace), client.MatchingLabels{
+		clusterv1.ClusterNameLabel:          cluster.Name,
+		clusterv1.ClusterTopologyOwnedLabel: "",
+	}); err != nil {
+		return false, errors.Wrapf(err, "failed to list MachineDeployments of Cluster %s", klog.KObj(cluster))
+	}
+	for i := range mds.Items {
+		md := &mds.Items[i]
+		if upgrade.Version != "" && ptr.Deref(md.Spec.Template.Spec.Version, "") != upgrade.Version {
+			return false, nil
+		}
+		replicas := ptr.Deref(md.Spec.Replicas, 1)
+		if md.Status.ObservedGeneration < md.Generation ||
+			md.Status.Replicas != replicas ||
+			md.Status.UpdatedReplicas != replicas ||
+			md.Status.AvailableReplicas != replicas {
+			return false, nil
+		}
+		upgrading, err := check.IsMachineDeploymentUpgrading(ctx, r.Client, md)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to check MachineDeployments of Cluster %s", klog.KObj(cluster))
+		}
+		if upgrading {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// isClusterUpgradeExpired returns true if the Cluster is not healthy within the progress deadline of the upgrade.
+func isClusterUpgradeExpired(cluster *clusterv1.Cluster, upgrade *expv1.ClusterGroupUpgrade, now time.Time) bool {
+	if upgrade.ProgressDeadline == nil {
+		return false
+	}
+	started, err := time.Parse(time.RFC3339, cluster.Annotations[expv1.ClusterGroupUpgradeStartedAnnotation])
+	if err != nil {
+		return false
+	}
+	return now.Sub(started) > upgrade.ProgressDeadline.Duration
+}
+
+// startClusterUpgrade sets the topology of the Cluster to the upgrade.
+func (r *ClusterGroupReconciler) startClusterUpgrade(ctx context.Context, cluster *clusterv1.Cluster, upgrade *expv1.ClusterGroupUpgrade, now time.Time) error {
+	patchHelper, err := patch.NewHelper(cluster, r.Client)
+	if err != nil {
+		return err
+	}
+	if upgrade.Version != "" {
+		cluster.Spec.Topology.Version = upgrade.Version
+	}
+	if upgrade.Class != "" {
+		cluster.Spec.Topology.Class = upgrade.Class
+	}
+	if cluster.Annotations == nil {
+		cluster.Annotations = map[string]string{}
+	}
+	cluster.Annotations[expv1.ClusterGroupUpgradeStartedAnnotation] = now.UTC().Format(time.RFC3339)
+	if err := patchHelper.Patch(ctx, cluster); err != nil {
+		return errors.Wrapf(err, "failed to upgrade Cluster %s", klog.KObj(cluster))
+	}
+	return nil
+}
+
+// markClusterUpgradeStarted sets the upgrade started annotation on a Cluster already set to the upgrade.
+func (r *ClusterGroupReconciler) markClusterUpgradeStarted(ctx context.Context, cluster *clusterv1.Cluster, now time.Time) error {
+	patchHelper, err := patch.NewHelper(cluster, r.Client)
+	if err != nil {
+		return err
+	}
+	if cluster.Annotations == nil {
+		cluster.Annotations = map[string]string{}
+	}
+	cluster.Annotations[expv1.ClusterGroupUpgradeStartedAnnotation] = now.UTC().Format(time.RFC3339)
+	if err := patchHelper.Patch(ctx, cluster); err != nil {
+		return errors.Wrapf(err, "failed to set %s annotation on Cluster %s", expv1.ClusterGroupUpgradeStartedAnnotation, klog.KObj(cluster))
+	}
+	return nil
+}
+
+// completeClusterUpgrade removes the upgrade started annotation from an upgraded Cluster.
+func (r *ClusterGroupReconciler) completeClusterUpgrade(ctx context.Context, cluster *clusterv1.Cluster) error {
+	if _, ok := cluster.Annotations[expv1.ClusterGroupUpgradeStartedAnnotation]; !ok {
+		return nil
+	}
+	patchHelper, err := patch.NewHelper(cluster, r.Client)
+	if err != nil {
+		return err
+	}
+	delete(cluster.Annotations, expv1.ClusterGroupUpgradeStartedAnnotation)
+	if err := patchHelper.Patch(ctx, cluster); err != nil {
+		return errors.Wrapf(err, "failed to remove %s annotation from Cluster %s", expv1.ClusterGroupUpgradeStartedAnnotation, klog.KObj(cluster))
+	}
+	return nil
+}
+
+// clusterToClusterGroups maps a Cluster to the ClusterGroups selecting it.
+func (r *ClusterGroupReconciler) clusterToClusterGroups(ctx context.Context, o client.Object) []reconcile.Request {
+	cluster, ok := o.(*clusterv1.Cluster)
+	if !ok {
+		panic(errors.Errorf("expected a Cluster but got a %T", o))
+	}
+
+	groupList := &expv1.ClusterGroupList{}
+	if err := r.Client.List(ctx, groupList, client.InNamespace(cluster.Namespace)); err != nil {
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, group := range groupList.Items {
+		selector, err := metav1.LabelSelectorAsSelector(&group.Spec.ClusterSelector)
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(cluster.Labels)) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&group)})
+	}
+	return requests
+}
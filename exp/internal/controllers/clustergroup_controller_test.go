@@ -0,0 +1,301 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	"sigs.k8s.io/cluster-api/internal/test/builder"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestClusterGroupReconciler(t *testing.T) {
+	const (
+		fromVersion = "v1.29.0"
+		toVersion   = "v1.30.0"
+	)
+
+	newCluster := func(name, version string, canary, healthy bool) *clusterv1.Cluster {
+		cluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: metav1.NamespaceDefault,
+				Name:      name,
+				Labels:    map[string]string{"fleet": "prod"},
+			},
+			Spec: clusterv1.ClusterSpec{
+				Topology: &clusterv1.Topology{Class: "class", Version: version},
+			},
+		}
+		if canary {
+			cluster.Labels["canary"] = ""
+		}
+		if healthy {
+			conditions.MarkTrue(cluster, clusterv1.ReadyCondition)
+			conditions.MarkTrue(cluster, clusterv1.TopologyReconciledCondition)
+		}
+		return cluster
+	}
+	upgradeStarted := func(cluster *clusterv1.Cluster, startTime time.Time) *clusterv1.Cluster {
+		cluster.Annotations = map[string]string{expv1.ClusterGroupUpgradeStartedAnnotation: startTime.UTC().Format(time.RFC3339)}
+		return cluster
+	}
+	withControlPlane := func(cluster *clusterv1.Cluster, controlPlane client.Object) *clusterv1.Cluster {
+		cluster.Spec.ControlPlaneRef = &corev1.ObjectReference{
+			APIVersion: builder.ControlPlaneGroupVersion.String(),
+			Kind:       builder.GenericControlPlaneKind,
+			Namespace:  metav1.NamespaceDefault,
+			Name:       controlPlane.GetName(),
+		}
+		return cluster
+	}
+	machineDeployment := func(clusterName, version string, updatedReplicas int32) client.Object {
+		return &clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: metav1.NamespaceDefault,
+				Name:      clusterName + "-md",
+				Labels: map[string]string{
+					clusterv1.ClusterNameLabel:          clusterName,
+					clusterv1.ClusterTopologyOwnedLabel: "",
+				},
+			},
+			Spec: clusterv1.MachineDeploymentSpec{
+				ClusterName: clusterName,
+				Replicas:    ptr.To[int32](2),
+				Template: clusterv1.MachineTemplateSpec{
+					Spec: clusterv1.MachineSpec{ClusterName: clusterName, Version: ptr.To(version)},
+				},
+			},
+			Status: clusterv1.MachineDeploymentStatus{
+				Replicas:          2,
+				UpdatedReplicas:   updatedReplicas,
+				AvailableReplicas: 2,
+			},
+		}
+	}
+	controlPlane := func(name, statusVersion string) client.Object {
+		return builder.ControlPlane(metav1.NamespaceDefault, name).
+			WithVersion(toVersion).
+			WithStatusFields(map[string]interface{}{"status.version": statusVersion}).
+			Build()
+	}
+
+	tests := []struct {
+		name                  string
+		upgrade               *expv1.ClusterGroupUpgrade
+		objs                  []client.Object
+		wantPhase             expv1.ClusterGroupUpgradePhase
+		wantUpdatedClusters   int32
+		wantUpgradingClusters []string
+		wantFailedClusters    []string
+		wantVersions          map[string]string
+		wantAnnotated         []string
+	}{
+		{
+			name:    "upgrades the canary Clusters first",
+			upgrade: &expv1.ClusterGroupUpgrade{Version: toVersion, MaxConcurrency: ptr.To[int32](2), CanarySelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "canary", Operator: metav1.LabelSelectorOpExists}}}},
+			objs: []client.Object{
+				newCluster("a", fromVersion, false, true),
+				newCluster("b", fromVersion, true, true),
+				newCluster("c", fromVersion, false, true),
+			},
+			wantPhase:             expv1.ClusterGroupUpgradePhaseProgressing,
+			wantUpgradingClusters: []string{"b"},
+			wantVersions:          map[string]string{"a": fromVersion, "b": toVersion, "c": fromVersion},
+			wantAnnotated:         []string{"b"},
+		},
+		{
+			name:    "upgrades the other Clusters up to the max concurrency after the canary Clusters are healthy",
+			upgrade: &expv1.ClusterGroupUpgrade{Version: toVersion, MaxConcurrency: ptr.To[int32](2), CanarySelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "canary", Operator: metav1.LabelSelectorOpExists}}}},
+			objs: []client.Object{
+				newCluster("a", fromVersion, false, true),
+				newCluster("b", toVersion, true, true),
+				newCluster("c", fromVersion, false, true),
+				newCluster("d", fromVersion, false, true),
+			},
+			wantPhase:             expv1.ClusterGroupUpgradePhaseProgressing,
+			wantUpdatedClusters:   1,
+			wantUpgradingClusters: []string{"a", "c"},
+			wantVersions:          map[string]string{"a": toVersion, "b": toVersion, "c": toVersion, "d": fromVersion},
+			wantAnnotated:         []string{"a", "c"},
+		},
+		{
+			name:    "waits for upgrading Clusters to be healthy",
+			upgrade: &expv1.ClusterGroupUpgrade{Version: toVersion},
+			objs: []client.Object{
+				withControlPlane(newCluster("a", toVersion, false, true), controlPlane("cp-a", fromVersion)),
+				controlPlane("cp-a", fromVersion),
+				newCluster("b", fromVersion, false, true),
+			},
+			wantPhase:             expv1.ClusterGroupUpgradePhaseProgressing,
+			wantUpgradingClusters: []string{"a"},
+			wantVersions:          map[string]string{"a": toVersion, "b": fromVersion},
+			wantAnnotated:         []string{"a"},
+		},
+		{
+			name:    "waits for the MachineDeployments of upgrading Clusters to be rolled out",
+			upgrade: &expv1.ClusterGroupUpgrade{Version: toVersion},
+			objs: []client.Object{
+				withControlPlane(newCluster("a", toVersion, false, true), controlPlane("cp-a", toVersion)),
+				controlPlane("cp-a", toVersion),
+				machineDeployment("a", toVersion, 1),
+				newCluster("b", fromVersion, false, true),
+			},
+			wantPhase:             expv1.ClusterGroupUpgradePhaseProgressing,
+			wantUpgradingClusters: []string{"a"},
+			wantVersions:          map[string]string{"a": toVersion, "b": fromVersion},
+			wantAnnotated:         []string{"a"},
+		},
+		{
+			name:    "waits for the MachineDeployments of upgrading Clusters to pick up the version",
+			upgrade: &expv1.ClusterGroupUpgrade{Version: toVersion},
+			objs: []client.Object{
+				withControlPlane(newCluster("a", toVersion, false, true), controlPlane("cp-a", toVersion)),
+				controlPlane("cp-a", toVersion),
+				machineDeployment("a", fromVersion, 2),
+				newCluster("b", fromVersion, false, true),
+			},
+			wantPhase:             expv1.ClusterGroupUpgradePhaseProgressing,
+			wantUpgradingClusters: []string{"a"},
+			wantVersions:          map[string]string{"a": toVersion, "b": fromVersion},
+			wantAnnotated:         []string{"a"},
+		},
+		{
+			name:    "tracks not healthy Clusters already set to the upgrade, so they are bounded by the progress deadline",
+			upgrade: &expv1.ClusterGroupUpgrade{Version: toVersion, ProgressDeadline: &metav1.Duration{Duration: time.Minute}},
+			objs: []client.Object{
+				newCluster("a", toVersion, false, false),
+				newCluster("b", fromVersion, false, true),
+			},
+			wantPhase:             expv1.ClusterGroupUpgradePhaseProgressing,
+			wantUpgradingClusters: []string{"a"},
+			wantVersions:          map[string]string{"a": toVersion, "b": fromVersion},
+			wantAnnotated:         []string{"a"},
+		},
+		{
+			name:    "does not start new upgrades if paused",
+			upgrade: &expv1.ClusterGroupUpgrade{Version: toVersion, Paused: true},
+			objs: []client.Object{
+				newCluster("a", fromVersion, false, true),
+			},
+			wantPhase:    expv1.ClusterGroupUpgradePhasePaused,
+			wantVersions: map[string]string{"a": fromVersion},
+		},
+		{
+			name:    "does not start new upgrades if aborted",
+			upgrade: &expv1.ClusterGroupUpgrade{Version: toVersion, Abort: true},
+			objs: []client.Object{
+				newCluster("a", fromVersion, false, true),
+			},
+			wantPhase:    expv1.ClusterGroupUpgradePhaseAborted,
+			wantVersions: map[string]string{"a": fromVersion},
+		},
+		{
+			name:    "fails if a Cluster is not healthy within the progress deadline",
+			upgrade: &expv1.ClusterGroupUpgrade{Version: toVersion, ProgressDeadline: &metav1.Duration{Duration: time.Minute}},
+			objs: []client.Object{
+				upgradeStarted(newCluster("a", toVersion, false, false), time.Now().Add(-time.Hour)),
+				newCluster("b", fromVersion, false, true),
+			},
+			wantPhase:             expv1.ClusterGroupUpgradePhaseFailed,
+			wantUpgradingClusters: []string{"a"},
+			wantFailedClusters:    []string{"a"},
+			wantVersions:          map[string]string{"a": toVersion, "b": fromVersion},
+			wantAnnotated:         []string{"a"},
+		},
+		{
+			name:    "completes when all the Clusters are upgraded and healthy",
+			upgrade: &expv1.ClusterGroupUpgrade{Version: toVersion},
+			objs: []client.Object{
+				withControlPlane(upgradeStarted(newCluster("a", toVersion, false, true), time.Now()), controlPlane("cp-a", toVersion)),
+				controlPlane("cp-a", toVersion),
+				machineDeployment("a", toVersion, 2),
+				newCluster("b", toVersion, false, true),
+			},
+			wantPhase:           expv1.ClusterGroupUpgradePhaseCompleted,
+			wantUpdatedClusters: 2,
+			wantVersions:        map[string]string{"a": toVersion, "b": toVersion},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			group := &expv1.ClusterGroup{
+				ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "group"},
+				Spec: expv1.ClusterGroupSpec{
+					ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"fleet": "prod"}},
+					Upgrade:         tt.upgrade,
+				},
+			}
+			// Clusters not matching the selector are ignored.
+			other := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "other"},
+				Spec:       clusterv1.ClusterSpec{Topology: &clusterv1.Topology{Class: "class", Version: fromVersion}},
+			}
+
+			scheme := runtime.NewScheme()
+			g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+			g.Expect(expv1.AddToScheme(scheme)).To(Succeed())
+
+			c := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(append(tt.objs, group, other)...).
+				WithStatusSubresource(&expv1.ClusterGroup{}).
+				Build()
+			r := &ClusterGroupReconciler{
+				Client:   c,
+				recorder: record.NewFakeRecorder(32),
+			}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(group)})
+			g.Expect(err).ToNot(HaveOccurred())
+
+			g.Expect(c.Get(ctx, client.ObjectKeyFromObject(group), group)).To(Succeed())
+			g.Expect(group.Status.Phase).To(Equal(tt.wantPhase))
+			g.Expect(group.Status.Clusters).To(Equal(int32(len(tt.wantVersions))))
+			g.Expect(group.Status.UpdatedClusters).To(Equal(tt.wantUpdatedClusters))
+			g.Expect(group.Status.UpgradingClusters).To(Equal(tt.wantUpgradingClusters))
+			g.Expect(group.Status.FailedClusters).To(Equal(tt.wantFailedClusters))
+			g.Expect(conditions.IsTrue(group, expv1.ClusterGroupUpgradeCompletedCondition)).To(Equal(tt.wantPhase == expv1.ClusterGroupUpgradePhaseCompleted))
+
+			for name, version := range tt.wantVersions {
+				cluster := &clusterv1.Cluster{}
+				g.Expect(c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: name}, cluster)).To(Succeed())
+				g.Expect(cluster.Spec.Topology.Version).To(Equal(version), "Cluster %s", name)
+				_, hasAnnotation := cluster.Annotations[expv1.ClusterGroupUpgradeStartedAnnotation]
+				g.Expect(hasAnnotation).To(Equal(slices.Contains(tt.wantAnnotated, name)), "Cluster %s", name)
+			}
+
+			g.Expect(c.Get(ctx, client.ObjectKeyFromObject(other), other)).To(Succeed())
+			g.Expect(other.Spec.Topology.Version).To(Equal(fromVersion))
+		})
+	}
+}
@@ -144,6 +144,9 @@ func (r *MachinePoolReconciler) reconcileExternal(ctx context.Context, cluster *
 		return external.ReconcileOutput{}, err
 	}
 
+	// Propagate the labels and annotations defined in the Cluster's spec.metadataPropagation.
+	labels.PropagateClusterMetadata(cluster, obj)
+
 	// Set the Cluster label.
 	labels := obj.GetLabels()
 	if labels == nil {
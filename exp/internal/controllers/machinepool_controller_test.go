@@ -116,7 +116,7 @@ func TestMachinePoolFinalizer(t *testing.T) {
 					clusterCorrectMeta,
 					machinePoolValidCluster,
 					machinePoolWithFinalizer,
-				).Build(),
+				).WithStatusSubresource(&expv1.MachinePool{}).Build(),
 			}
 
 			_, _ = mr.Reconcile(ctx, tc.request)
@@ -726,6 +726,11 @@ func TestMachinePoolConditions(t *testing.T) {
 
 				g.Expect(getter.GetConditions()).NotTo(BeEmpty())
 				for _, c := range getter.GetConditions() {
+					if c.Type == clusterv1.PausedV1Beta2Condition {
+						// The Paused condition is false when the MachinePool is not paused.
+						g.Expect(c.Status).To(Equal(corev1.ConditionFalse))
+						continue
+					}
 					g.Expect(c.Status).To(Equal(corev1.ConditionTrue))
 				}
 			},
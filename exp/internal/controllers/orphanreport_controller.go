@@ -0,0 +1,376 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	"sigs.k8s.io/cluster-api/exp/orphans"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+)
+
+// +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=*,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=orphanreports;orphanreports/status,verbs=get;list;watch;update;patch
+
+const (
+	// infrastructureGroup is the API group of the infrastructure providers.
+	infrastructureGroup = "infrastructure.cluster.x-k8s.io"
+
+	// defaultOrphanMinimumAge is the default minimum age of a resource before it can be considered orphaned.
+	defaultOrphanMinimumAge = 10 * time.Minute
+
+	// defaultOrphanScanInterval is the default interval between scans of an OrphanReport.
+	defaultOrphanScanInterval = 10 * time.Minute
+)
+
+// OrphanReportReconciler reconciles an OrphanReport object.
+type OrphanReportReconciler struct {
+	Client    client.Client
+	APIReader client.Reader
+
+	// Detectors are used to detect orphaned external resources, e.g. cloud resources, in addition to
+	// the orphaned infrastructure objects.
+	Detectors []orphans.ExternalResourceDetector
+
+	// WatchFilterValue is the label value used to filter events prior to reconciliation.
+	WatchFilterValue string
+
+	recorder record.EventRecorder
+}
+
+func (r *OrphanReportReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		// Status changes, e.g. the ones written at every scan, must not trigger a new scan.
+		For(&expv1.OrphanReport{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithOptions(options).
+		WithEventFilter(predicates.ResourceHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
+		Complete(r)
+	if err != nil {
+		return errors.Wrap(err, "failed setting up with a controller manager")
+	}
+
+	r.recorder = mgr.GetEventRecorderFor("orphanreport-controller")
+	return nil
+}
+
+func (r *OrphanReportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	report := &expv1.OrphanReport{}
+	if err := r.Client.Get(ctx, req.NamespacedName, report); err != nil {
+		if apierrors.IsNotFound(err) {
+			deleteOrphanReportMetrics(req.Namespace, req.Name)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !report.DeletionTimestamp.IsZero() {
+		deleteOrphanReportMetrics(report.Namespace, report.Name)
+		return ctrl.Result{}, nil
+	}
+
+	// Initialize the patch helper.
+	patchHelper, err := patch.NewHelper(report, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	defer func() {
+		// Always update the readyCondition with the summary of the OrphanReport conditions.
+		conditions.SetSummary(report, conditions.WithConditions(expv1.NoOrphansCondition))
+
+		patchOpts := []patch.Option{
+			patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+				clusterv1.ReadyCondition,
+				expv1.NoOrphansCondition,
+			}},
+		}
+		if reterr == nil {
+			patchOpts = append(patchOpts, patch.WithStatusObservedGeneration{})
+		}
+		if err := patchHelper.Patch(ctx, report, patchOpts...); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	return r.reconcile(ctx, report)
+}
+
+func (r *OrphanReportReconciler) reconcile(ctx context.Context, report *expv1.OrphanReport) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	scanInterval := defaultOrphanScanInterval
+	if report.Spec.ScanInterval != nil && report.Spec.ScanInterval.Duration > 0 {
+		scanInterval = report.Spec.ScanInterval.Duration
+	}
+
+	orphaned, err := r.scan(ctx, report)
+	if err != nil {
+		conditions.MarkFalse(report, expv1.NoOrphansCondition, expv1.ScanFailedReason, clusterv1.ConditionSeverityError, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	now := metav1.Now()
+	report.Status.Orphans = orphaned
+	report.Status.OrphanCount = int32(len(orphaned))
+	report.Status.DeletedCount = 0
+	report.Status.LastScanTime = &now
+	setOrphanReportMetrics(report)
+
+	if len(orphaned) == 0 {
+		conditions.MarkTrue(report, expv1.NoOrphansCondition)
+		return ctrl.Result{RequeueAfter: scanInterval}, nil
+	}
+
+	log.Info(fmt.Sprintf("Detected %d orphaned resources", len(orphaned)))
+	conditions.MarkFalse(report, expv1.NoOrphansCondition, expv1.OrphansDetectedReason, clusterv1.ConditionSeverityWarning,
+		"%d orphaned resources detected", len(orphaned))
+
+	if report.Spec.CleanupPolicy == expv1.OrphanCleanupPolicyDelete {
+		deleted, err := r.deleteOrphans(ctx, report, orphaned)
+		report.Status.DeletedCount = deleted
+		if err != nil {
+			conditions.MarkFalse(report, expv1.NoOrphansCondition, expv1.OrphanCleanupFailedReason, clusterv1.ConditionSeverityError, err.Error())
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{RequeueAfter: scanInterval}, nil
+}
+
+// scan returns the orphaned infrastructure objects in the namespace of the OrphanReport, followed by the
+// orphaned external resources reported by the detectors.
+func (r *OrphanReportReconciler) scan(ctx context.Context, report *expv1.OrphanReport) ([]expv1.OrphanedResource, error) {
+	minimumAge := defaultOrphanMinimumAge
+	if report.Spec.MinimumAge != nil {
+		minimumAge = report.Spec.MinimumAge.Duration
+	}
+
+	clusterList := &clusterv1.ClusterList{}
+	if err := r.Client.List(ctx, clusterList, client.InNamespace(report.Namespace)); err != nil {
+		return nil, errors.Wrapf(err, "failed to list Clusters")
+	}
+	clusterNames := sets.Set[string]{}
+	for _, c := range clusterList.Items {
+		clusterNames.Insert(c.Name)
+	}
+
+	kinds, err := r.infrastructureKinds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []expv1.OrphanedResource{}
+	for _, gvk := range kinds {
+		list := &metav1.PartialObjectMetadataList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := r.APIReader.List(ctx, list, client.InNamespace(report.Namespace)); err != nil {
+			return nil, errors.Wrapf(err, "failed to list %s", gvk.Kind)
+		}
+
+		for i := range list.Items {
+			obj := &list.Items[i]
+			// Skip objects being deleted, paused (e.g. while being moved), or too recent to have their owners set.
+			if !obj.DeletionTimestamp.IsZero() || annotations.HasPaused(obj) || time.Since(obj.CreationTimestamp.Time) < minimumAge {
+				continue
+			}
+
+			reason, message, err := r.orphanReason(ctx, obj, clusterNames)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to check %s %s", gvk.Kind, obj.Name)
+			}
+			if reason == "" {
+				continue
+			}
+			result = append(result, expv1.OrphanedResource{
+				APIVersion: gvk.GroupVersion().String(),
+				Kind:       gvk.Kind,
+				Name:       obj.Name,
+				Reason:     reason,
+				Message:    message,
+			})
+		}
+	}
+
+	for _, d := range r.Detectors {
+		external, err := d.DetectOrphans(ctx, report.Namespace, clusterList.Items)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to detect orphaned resources using %s", d.Name())
+		}
+		for _, e := range external {
+			result = append(result, expv1.OrphanedResource{
+				Kind:     e.Kind,
+				Name:     e.ID,
+				Provider: d.Name(),
+				Reason:   expv1.OrphanReasonExternal,
+				Message:  e.Message,
+			})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Provider != result[j].Provider {
+			return result[i].Provider < result[j].Provider
+		}
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// infrastructureKinds returns the kinds of the infrastructure objects, e.g. InfraClusters and InfraMachines,
+// defined by the namespaced CRDs of the infrastructure providers compatible with the current contract.
+// Templates and identities, e.g. AWSClusterControllerIdentity, are excluded because they are not owned by Clusters
+// or Machines by design, as well as cluster-scoped kinds, which do not belong to the namespace of the OrphanReport.
+func (r *OrphanReportReconciler) infrastructureKinds(ctx context.Context) ([]schema.GroupVersionKind, error) {
+	crdList := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := r.APIReader.List(ctx, crdList, client.HasLabels{clusterv1.GroupVersion.String()}); err != nil {
+		return nil, errors.Wrap(err, "failed to list CustomResourceDefinitions")
+	}
+
+	kinds := []schema.GroupVersionKind{}
+	for _, crd := range crdList.Items {
+		if crd.Spec.Group != infrastructureGroup || crd.Spec.Scope != apiextensionsv1.NamespaceScoped {
+			continue
+		}
+		if strings.HasSuffix(crd.Spec.Names.Kind, "Template") || strings.HasSuffix(crd.Spec.Names.Kind, "Identity") {
+			continue
+		}
+		for _, v := range crd.Spec.Versions {
+			if v.Storage && v.Served {
+				kinds = append(kinds, schema.GroupVersionKind{Group: crd.Spec.Group, Version: v.Name, Kind: crd.Spec.Names.Kind})
+			}
+		}
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i].Kind < kinds[j].Kind })
+	return kinds, nil
+}
+
+// orphanReason returns the reason why an object is orphaned, or an empty reason if it is not.
+// Only objects with a Cluster name label referencing a Cluster which does not exist, or with owner references
+// to objects which do not exist, are orphaned; objects without both of them are not, given they can be shared
+// or unowned by design.
+func (r *OrphanReportReconciler) orphanReason(ctx context.Context, obj *metav1.PartialObjectMetadata, clusterNames sets.Set[string]) (expv1.OrphanReason, string, error) {
+	clusterName, hasClusterName := obj.Labels[clusterv1.ClusterNameLabel]
+	if hasClusterName && !clusterNames.Has(clusterName) {
+		return expv1.OrphanReasonClusterNotFound, fmt.Sprintf("Cluster %s does not exist", clusterName), nil
+	}
+
+	if len(obj.OwnerReferences) == 0 {
+		return "", "", nil
+	}
+
+	owners := []string{}
+	for _, ref := range obj.OwnerReferences {
+		exists, err := r.ownerExists(ctx, obj.Namespace, ref)
+		if err != nil {
+			return "", "", err
+		}
+		if exists {
+			return "", "", nil
+		}
+		owners = append(owners, fmt.Sprintf("%s %s", ref.Kind, ref.Name))
+	}
+	return expv1.OrphanReasonOwnerNotFound, fmt.Sprintf("owners %s do not exist", strings.Join(owners, ", ")), nil
+}
+
+// ownerExists returns true if the object referenced by an owner reference exists and has the same UID.
+// Owners whose kind is not served, e.g. because the API version has been removed or the provider is being upgraded,
+// make the check fail, given that it is not possible to tell if they exist.
+func (r *OrphanReportReconciler) ownerExists(ctx context.Context, namespace string, ref metav1.OwnerReference) (bool, error) {
+	owner := &metav1.PartialObjectMetadata{}
+	owner.SetGroupVersionKind(schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind))
+	if err := r.APIReader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, owner); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if meta.IsNoMatchError(err) {
+			return false, errors.Wrapf(err, "failed to get owner %s %s", ref.Kind, ref.Name)
+		}
+		return false, err
+	}
+	return owner.UID == ref.UID, nil
+}
+
+// deleteOrphans deletes the orphaned resources and returns how many of them have been deleted.
+func (r *OrphanReportReconciler) deleteOrphans(ctx context.Context, report *expv1.OrphanReport, orphaned []expv1.OrphanedResource) (int32, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	detectors := map[string]orphans.ExternalResourceDetector{}
+	for _, d := range r.Detectors {
+		detectors[d.Name()] = d
+	}
+
+	var deleted int32
+	var errs []error
+	for _, o := range orphaned {
+		var err error
+		if o.Provider != "" {
+			d, ok := detectors[o.Provider]
+			if !ok {
+				continue
+			}
+			err = d.DeleteOrphan(ctx, report.Namespace, orphans.ExternalResource{Kind: o.Kind, ID: o.Name, Message: o.Message})
+		} else {
+			obj := &metav1.PartialObjectMetadata{}
+			obj.SetGroupVersionKind(schema.FromAPIVersionAndKind(o.APIVersion, o.Kind))
+			obj.SetNamespace(report.Namespace)
+			obj.SetName(o.Name)
+			if err = r.Client.Delete(ctx, obj); apierrors.IsNotFound(err) {
+				err = nil
+			}
+		}
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to delete orphaned %s %s", o.Kind, o.Name))
+			continue
+		}
+
+		deleted++
+		log.Info(fmt.Sprintf("Deleted orphaned %s %s", o.Kind, o.Name), "provider", o.Provider)
+		r.recorder.Eventf(report, corev1.EventTypeNormal, "OrphanDeleted", "Deleted orphaned %s %s", o.Kind, o.Name)
+		orphansDeletedTotal.WithLabelValues(report.Namespace, o.Kind, o.Provider).Inc()
+	}
+	return deleted, kerrors.NewAggregate(errs)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		orphanedResources,
+		orphansDeletedTotal,
+	)
+}
+
+var (
+	orphanedResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capi_orphanreport_orphaned_resources",
+		Help: "Number of orphaned resources detected by the last scan of an OrphanReport.",
+	}, []string{"namespace", "orphanreport", "kind", "provider"})
+
+	orphansDeletedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "capi_orphanreport_deleted_resources_total",
+		Help: "Total number of orphaned resources deleted by OrphanReports with the Delete cleanup policy.",
+	}, []string{"namespace", "kind", "provider"})
+)
+
+// setOrphanReportMetrics sets the orphaned resources gauge from the last scan of the OrphanReport,
+// dropping the series of kinds without orphaned resources anymore.
+func setOrphanReportMetrics(report *expv1.OrphanReport) {
+	deleteOrphanReportMetrics(report.Namespace, report.Name)
+
+	type key struct{ kind, provider string }
+	counts := map[key]int{}
+	for _, o := range report.Status.Orphans {
+		counts[key{kind: o.Kind, provider: o.Provider}]++
+	}
+	for k, count := range counts {
+		orphanedResources.WithLabelValues(report.Namespace, report.Name, k.kind, k.provider).Set(float64(count))
+	}
+}
+
+// deleteOrphanReportMetrics deletes the orphaned resources gauge series of an OrphanReport.
+func deleteOrphanReportMetrics(namespace, name string) {
+	orphanedResources.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "orphanreport": name})
+}
@@ -0,0 +1,303 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	"sigs.k8s.io/cluster-api/exp/orphans"
+	"sigs.k8s.io/cluster-api/internal/test/builder"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+type fakeDetector struct {
+	orphans []orphans.ExternalResource
+	deleted []orphans.ExternalResource
+}
+
+func (d *fakeDetector) Name() string { return "fake" }
+
+func (d *fakeDetector) DetectOrphans(_ context.Context, _ string, _ []clusterv1.Cluster) ([]orphans.ExternalResource, error) {
+	return d.orphans, nil
+}
+
+func (d *fakeDetector) DeleteOrphan(_ context.Context, _ string, resource orphans.ExternalResource) error {
+	d.deleted = append(d.deleted, resource)
+	return nil
+}
+
+// noMatchReader returns a NoMatch error when getting objects of a group, like the API server does for groups which are not served.
+type noMatchReader struct {
+	client.Reader
+	group string
+}
+
+func (r *noMatchReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if gvk := obj.GetObjectKind().GroupVersionKind(); gvk.Group == r.group {
+		return &meta.NoKindMatchError{GroupKind: gvk.GroupKind(), SearchedVersions: []string{gvk.Version}}
+	}
+	return r.Reader.Get(ctx, key, obj, opts...)
+}
+
+func TestOrphanReportReconciler(t *testing.T) {
+	machineGVK := builder.InfrastructureGroupVersion.WithKind(builder.GenericInfrastructureMachineKind)
+	old := metav1.NewTime(time.Now().Add(-time.Hour))
+
+	infraMachine := func(name string, mutate func(u *unstructured.Unstructured)) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(machineGVK)
+		u.SetNamespace(metav1.NamespaceDefault)
+		u.SetName(name)
+		u.SetCreationTimestamp(old)
+		if mutate != nil {
+			mutate(u)
+		}
+		return u
+	}
+
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "cluster"}}
+	machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "machine", UID: "machine-uid"}}
+
+	objs := []client.Object{
+		builder.GenericInfrastructureMachineCRD.DeepCopy(),
+		cluster,
+		machine,
+		// Owned by an existing Machine.
+		infraMachine("owned", func(u *unstructured.Unstructured) {
+			u.SetLabels(map[string]string{clusterv1.ClusterNameLabel: "cluster"})
+			u.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: clusterv1.GroupVersion.String(), Kind: "Machine", Name: "machine", UID: "machine-uid"}})
+		}),
+		// Belongs to a Cluster which has been deleted.
+		infraMachine("cluster-not-found", func(u *unstructured.Unstructured) {
+			u.SetLabels(map[string]string{clusterv1.ClusterNameLabel: "deleted-cluster"})
+		}),
+		// Owned by a Machine which has been deleted.
+		infraMachine("owner-not-found", func(u *unstructured.Unstructured) {
+			u.SetLabels(map[string]string{clusterv1.ClusterNameLabel: "cluster"})
+			u.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: clusterv1.GroupVersion.String(), Kind: "Machine", Name: "deleted-machine", UID: "deleted-uid"}})
+		}),
+		// Owned by a Machine which has been recreated with the same name.
+		infraMachine("owner-uid-mismatch", func(u *unstructured.Unstructured) {
+			u.SetLabels(map[string]string{clusterv1.ClusterNameLabel: "cluster"})
+			u.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: clusterv1.GroupVersion.String(), Kind: "Machine", Name: "machine", UID: "other-uid"}})
+		}),
+		// Neither the cluster name label nor owners are set, e.g. shared by design.
+		infraMachine("no-owner", nil),
+		// Too recent to be considered orphaned.
+		infraMachine("recent", func(u *unstructured.Unstructured) {
+			u.SetCreationTimestamp(metav1.Now())
+		}),
+		// Paused, e.g. while being moved.
+		infraMachine("paused", func(u *unstructured.Unstructured) {
+			u.SetAnnotations(map[string]string{clusterv1.PausedAnnotation: ""})
+		}),
+	}
+
+	newReconciler := func(g *WithT, report *expv1.OrphanReport, detector *fakeDetector, extraObjs ...client.Object) *OrphanReportReconciler {
+		scheme := runtime.NewScheme()
+		g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		g.Expect(expv1.AddToScheme(scheme)).To(Succeed())
+		g.Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+		// The fake client requires the infrastructure kinds to be registered to list them as metadata only.
+		scheme.AddKnownTypeWithName(machineGVK, &unstructured.Unstructured{})
+		scheme.AddKnownTypeWithName(machineGVK.GroupVersion().WithKind(machineGVK.Kind+"List"), &unstructured.UnstructuredList{})
+
+		restMapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{clusterv1.GroupVersion, expv1.GroupVersion, builder.InfrastructureGroupVersion})
+		restMapper.Add(machineGVK, meta.RESTScopeNamespace)
+		restMapper.Add(clusterv1.GroupVersion.WithKind("Cluster"), meta.RESTScopeNamespace)
+		restMapper.Add(clusterv1.GroupVersion.WithKind("Machine"), meta.RESTScopeNamespace)
+		restMapper.Add(expv1.GroupVersion.WithKind("OrphanReport"), meta.RESTScopeNamespace)
+		restMapper.Add(apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"), meta.RESTScopeRoot)
+
+		c := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithRESTMapper(restMapper).
+			WithObjects(append(append(append([]client.Object{}, objs...), extraObjs...), report)...).
+			WithStatusSubresource(&expv1.OrphanReport{}).
+			Build()
+
+		r := &OrphanReportReconciler{
+			Client:    c,
+			APIReader: c,
+			recorder:  record.NewFakeRecorder(32),
+		}
+		if detector != nil {
+			r.Detectors = []orphans.ExternalResourceDetector{detector}
+		}
+		return r
+	}
+
+	t.Run("reports orphaned infrastructure objects and external resources", func(t *testing.T) {
+		g := NewWithT(t)
+
+		report := &expv1.OrphanReport{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "report"}}
+		detector := &fakeDetector{orphans: []orphans.ExternalResource{{Kind: "VirtualMachine", ID: "vm-1", Message: "VirtualMachine of Cluster deleted-cluster"}}}
+		r := newReconciler(g, report, detector)
+
+		res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(report)})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(res.RequeueAfter).To(Equal(defaultOrphanScanInterval))
+
+		g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(report), report)).To(Succeed())
+		g.Expect(report.Status.OrphanCount).To(Equal(int32(4)))
+		g.Expect(report.Status.DeletedCount).To(Equal(int32(0)))
+		g.Expect(report.Status.LastScanTime).ToNot(BeNil())
+
+		reasons := map[string]expv1.OrphanReason{}
+		for _, o := range report.Status.Orphans {
+			reasons[o.Name] = o.Reason
+		}
+		g.Expect(reasons).To(Equal(map[string]expv1.OrphanReason{
+			"cluster-not-found":  expv1.OrphanReasonClusterNotFound,
+			"owner-not-found":    expv1.OrphanReasonOwnerNotFound,
+			"owner-uid-mismatch": expv1.OrphanReasonOwnerNotFound,
+			"vm-1":               expv1.OrphanReasonExternal,
+		}))
+		g.Expect(report.Status.Orphans[0].Provider).To(BeEmpty())
+		g.Expect(report.Status.Orphans[3].Provider).To(Equal("fake"))
+
+		g.Expect(conditions.IsFalse(report, expv1.NoOrphansCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(report, expv1.NoOrphansCondition)).To(Equal(expv1.OrphansDetectedReason))
+
+		// Objects are not deleted with the Report policy.
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(machineGVK)
+		g.Expect(r.Client.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "cluster-not-found"}, u)).To(Succeed())
+		g.Expect(detector.deleted).To(BeEmpty())
+	})
+
+	t.Run("deletes orphaned resources with the Delete policy", func(t *testing.T) {
+		g := NewWithT(t)
+
+		report := &expv1.OrphanReport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "report"},
+			Spec:       expv1.OrphanReportSpec{CleanupPolicy: expv1.OrphanCleanupPolicyDelete},
+		}
+		detector := &fakeDetector{orphans: []orphans.ExternalResource{{Kind: "VirtualMachine", ID: "vm-1"}}}
+		r := newReconciler(g, report, detector)
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(report)})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(report), report)).To(Succeed())
+		g.Expect(report.Status.OrphanCount).To(Equal(int32(4)))
+		g.Expect(report.Status.DeletedCount).To(Equal(int32(4)))
+		g.Expect(detector.deleted).To(Equal([]orphans.ExternalResource{{Kind: "VirtualMachine", ID: "vm-1"}}))
+
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(machineGVK.GroupVersion().WithKind(machineGVK.Kind + "List"))
+		g.Expect(r.Client.List(ctx, list, client.InNamespace(metav1.NamespaceDefault))).To(Succeed())
+		names := []string{}
+		for _, u := range list.Items {
+			names = append(names, u.GetName())
+		}
+		g.Expect(names).To(ConsistOf("owned", "no-owner", "recent", "paused"))
+	})
+
+	t.Run("fails without deleting if the kind of an owner is not served", func(t *testing.T) {
+		g := NewWithT(t)
+
+		report := &expv1.OrphanReport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "report"},
+			Spec:       expv1.OrphanReportSpec{CleanupPolicy: expv1.OrphanCleanupPolicyDelete},
+		}
+		r := newReconciler(g, report, nil, infraMachine("unknown-owner-kind", func(u *unstructured.Unstructured) {
+			u.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "unknown.cluster.x-k8s.io/v1beta1", Kind: "Unknown", Name: "owner", UID: "owner-uid"}})
+		}))
+		r.APIReader = &noMatchReader{Reader: r.APIReader, group: "unknown.cluster.x-k8s.io"}
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(report)})
+		g.Expect(err).To(HaveOccurred())
+
+		g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(report), report)).To(Succeed())
+		g.Expect(conditions.GetReason(report, expv1.NoOrphansCondition)).To(Equal(expv1.ScanFailedReason))
+
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(machineGVK)
+		g.Expect(r.Client.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "cluster-not-found"}, u)).To(Succeed())
+	})
+
+	t.Run("reports no orphans", func(t *testing.T) {
+		g := NewWithT(t)
+
+		report := &expv1.OrphanReport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "report"},
+			Spec:       expv1.OrphanReportSpec{ScanInterval: &metav1.Duration{Duration: time.Minute}},
+		}
+		r := newReconciler(g, report, nil)
+
+		res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(report)})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(res.RequeueAfter).To(Equal(time.Minute))
+
+		g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(report), report)).To(Succeed())
+		g.Expect(report.Status.Orphans).To(BeEmpty())
+		g.Expect(conditions.IsTrue(report, expv1.NoOrphansCondition)).To(BeTrue())
+		g.Expect(conditions.IsTrue(report, clusterv1.ReadyCondition)).To(BeTrue())
+	})
+}
+
+func TestOrphanReportReconciler_infrastructureKinds(t *testing.T) {
+	g := NewWithT(t)
+
+	crd := func(kind string, scope apiextensionsv1.ResourceScope) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   strings.ToLower(kind) + "s." + infrastructureGroup,
+				Labels: map[string]string{clusterv1.GroupVersion.String(): "v1beta1"},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Group:    infrastructureGroup,
+				Names:    apiextensionsv1.CustomResourceDefinitionNames{Kind: kind},
+				Scope:    scope,
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1beta1", Served: true, Storage: true}},
+			},
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	g.Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		crd("FooMachine", apiextensionsv1.NamespaceScoped),
+		crd("FooMachineTemplate", apiextensionsv1.NamespaceScoped),
+		crd("FooClusterIdentity", apiextensionsv1.NamespaceScoped),
+		crd("FooClusterControllerIdentity", apiextensionsv1.ClusterScoped),
+		crd("FooGlobalConfig", apiextensionsv1.ClusterScoped),
+	).Build()
+
+	r := &OrphanReportReconciler{Client: c, APIReader: c}
+	kinds, err := r.infrastructureKinds(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(kinds).To(Equal([]schema.GroupVersionKind{{Group: infrastructureGroup, Version: "v1beta1", Kind: "FooMachine"}}))
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package orphans defines the extension point used by providers to report orphaned external resources,
+// e.g. cloud resources, to the OrphanReport controller.
+package orphans
+
+import (
+	"context"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// ExternalResource is an external resource, e.g. a cloud resource, not backed by any live Cluster.
+type ExternalResource struct {
+	// Kind of the resource, e.g. VirtualMachine or LoadBalancer.
+	Kind string
+
+	// ID of the resource in the external system.
+	ID string
+
+	// Message is a human readable message with details about the resource, e.g. the name of the Cluster it belonged to.
+	Message string
+}
+
+// ExternalResourceDetector detects external resources, e.g. cloud resources, not backed by any live Cluster.
+// Providers implement an ExternalResourceDetector and run the OrphanReport controller in their own manager
+// to report the external resources leaked by force-deleted Clusters.
+type ExternalResourceDetector interface {
+	// Name returns the name of the detector, usually the name of the provider.
+	Name() string
+
+	// DetectOrphans returns the external resources created for the namespace which do not belong to any
+	// of the given live Clusters.
+	DetectOrphans(ctx context.Context, namespace string, clusters []clusterv1.Cluster) ([]ExternalResource, error)
+
+	// DeleteOrphan deletes an external resource previously returned by DetectOrphans.
+	DeleteOrphan(ctx context.Context, namespace string, resource ExternalResource) error
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	runtimecatalog "sigs.k8s.io/cluster-api/exp/runtime/catalog"
+)
+
+// VerifyNodeIdentityRequest is the request of the VerifyNodeIdentity hook.
+// +kubebuilder:object:root=true
+type VerifyNodeIdentityRequest struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// CommonRequest contains fields common to all request types.
+	CommonRequest `json:",inline"`
+
+	// Cluster is the cluster object the Machine belongs to.
+	Cluster clusterv1.Cluster `json:"cluster"`
+
+	// Machine is the Machine object claiming the Node.
+	Machine clusterv1.Machine `json:"machine"`
+
+	// Node is the Node object in the workload cluster matching the Machine.
+	Node corev1.Node `json:"node"`
+}
+
+var _ RetryResponseObject = &VerifyNodeIdentityResponse{}
+
+// VerifyNodeIdentityResponse is the response of the VerifyNodeIdentity hook.
+// +kubebuilder:object:root=true
+type VerifyNodeIdentityResponse struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// CommonRetryResponse contains Status, Message and RetryAfterSeconds fields.
+	CommonRetryResponse `json:",inline"`
+}
+
+// VerifyNodeIdentity is the hook that will be called before a Node is associated to a Machine,
+// to verify the Node is the one provisioned for the Machine.
+func VerifyNodeIdentity(*VerifyNodeIdentityRequest, *VerifyNodeIdentityResponse) {}
+
+func init() {
+	catalogBuilder.RegisterHook(VerifyNodeIdentity, &runtimecatalog.HookMeta{
+		Tags:    []string{"Node Identity Hooks"},
+		Summary: "Cluster API Runtime will call this hook before a Node is associated to a Machine",
+		Description: "Cluster API Runtime will call this hook after a Node matching the Machine has been found in the workload cluster, " +
+			"and immediately before the Machine's status.nodeRef is set and the Node is initialized.\n" +
+			"\n" +
+			"Notes:\n" +
+			"- The call's request contains the Cluster, the Machine and the Node objects\n" +
+			"- Runtime Extension implementers can use this hook to verify the identity of the Node, e.g. using a TPM quote, " +
+			"a cloud instance identity document or a signed bootstrap nonce\n" +
+			"- This is a blocking hook; returning a RetryAfterSeconds greater than zero delays the association, e.g. " +
+			"while waiting for the identity evidence to be available\n" +
+			"- Returning a Failure status rejects the Node; the Node is not associated to the Machine until the hook succeeds",
+	})
+}
@@ -1046,3 +1046,56 @@ func (in *Variable) DeepCopy() *Variable {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerifyNodeIdentityRequest) DeepCopyInto(out *VerifyNodeIdentityRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.CommonRequest.DeepCopyInto(&out.CommonRequest)
+	in.Cluster.DeepCopyInto(&out.Cluster)
+	in.Machine.DeepCopyInto(&out.Machine)
+	in.Node.DeepCopyInto(&out.Node)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerifyNodeIdentityRequest.
+func (in *VerifyNodeIdentityRequest) DeepCopy() *VerifyNodeIdentityRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(VerifyNodeIdentityRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VerifyNodeIdentityRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerifyNodeIdentityResponse) DeepCopyInto(out *VerifyNodeIdentityResponse) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.CommonRetryResponse = in.CommonRetryResponse
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerifyNodeIdentityResponse.
+func (in *VerifyNodeIdentityResponse) DeepCopy() *VerifyNodeIdentityResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(VerifyNodeIdentityResponse)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VerifyNodeIdentityResponse) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
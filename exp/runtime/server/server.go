@@ -36,6 +36,7 @@ import (
 
 	runtimecatalog "sigs.k8s.io/cluster-api/exp/runtime/catalog"
 	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	"sigs.k8s.io/cluster-api/util/identity"
 )
 
 // DefaultPort is the default port that the webhook server serves.
@@ -45,6 +46,7 @@ var DefaultPort = 9443
 type Server struct {
 	webhook.Server
 	catalog  *runtimecatalog.Catalog
+	verifier *identity.Verifier
 	handlers map[string]ExtensionHandler
 }
 
@@ -73,6 +75,11 @@ type Options struct {
 	// TLSOpts is used to allow configuring the TLS config used for the server.
 	// This also allows providing a certificate via GetCertificate.
 	TLSOpts []func(*tls.Config)
+
+	// Verifier, if set, is used to verify the workload identity of the callers of the extension handlers,
+	// e.g. the Cluster API controllers; requests without a valid identity token are rejected.
+	// It is also possible to restrict the callers of each extension handler using ExtensionHandler.Authorizer.
+	Verifier *identity.Verifier
 }
 
 // New creates a new runtime webhook server based on the given Options.
@@ -102,6 +109,7 @@ func New(options Options) (*Server, error) {
 	return &Server{
 		Server:   webhookServer,
 		catalog:  options.Catalog,
+		verifier: options.Verifier,
 		handlers: map[string]ExtensionHandler{},
 	}, nil
 }
@@ -134,6 +142,10 @@ type ExtensionHandler struct {
 	// If left undefined, this will be defaulted to FailurePolicyFail when processing the answer to the discovery
 	// call for this server.
 	FailurePolicy *runtimehooksv1.FailurePolicy
+
+	// Authorizer restricts the callers of the extension handler, e.g. to the controllers with a given SPIFFE ID.
+	// It is used only if the Server verifies the identity of the callers; if not set, any verified caller is allowed.
+	Authorizer identity.Authorizer
 }
 
 // AddExtensionHandler adds an extension handler to the server.
@@ -233,8 +245,11 @@ func (s *Server) Start(ctx context.Context) error {
 	for handlerPath, h := range s.handlers {
 		handler := h
 
-		wrappedHandler := s.wrapHandler(handler)
-		s.Server.Register(handlerPath, http.HandlerFunc(wrappedHandler))
+		var wrappedHandler http.Handler = http.HandlerFunc(s.wrapHandler(handler))
+		if s.verifier != nil {
+			wrappedHandler = identity.Middleware(s.verifier, handler.Authorizer, wrappedHandler)
+		}
+		s.Server.Register(handlerPath, wrappedHandler)
 	}
 
 	return s.Server.Start(ctx)
@@ -20,6 +20,7 @@ package desiredstate
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -46,6 +47,7 @@ import (
 	topologynames "sigs.k8s.io/cluster-api/internal/topology/names"
 	"sigs.k8s.io/cluster-api/internal/topology/ownerrefs"
 	"sigs.k8s.io/cluster-api/internal/topology/selectors"
+	"sigs.k8s.io/cluster-api/internal/util/rolloutwindow"
 	"sigs.k8s.io/cluster-api/internal/webhooks"
 	"sigs.k8s.io/cluster-api/util"
 )
@@ -511,6 +513,19 @@ func (g *generator) computeControlPlaneVersion(ctx context.Context, s *scope.Sco
 		return *currentVersion, nil
 	}
 
+	// At this point the control plane and the machine deployments are stable; defer the upgrade
+	// if none of the rollout windows of the Cluster is open.
+	allowed, nextStart, err := rolloutwindow.Check(s.Current.Cluster, time.Now())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to check rollout windows")
+	}
+	if !allowed {
+		log.Infof("Cluster upgrade to version %q is deferred until the next rollout window starts", desiredVersion)
+		s.UpgradeTracker.ControlPlane.IsRolloutDeferred = true
+		s.UpgradeTracker.ControlPlane.NextRolloutWindowStart = nextStart
+		return *currentVersion, nil
+	}
+
 	if feature.Gates.Enabled(feature.RuntimeSDK) {
 		// At this point the control plane and the machine deployments are stable and we are almost ready to pick
 		// up the desiredVersion. Call the BeforeClusterUpgrade hook before picking up the desired version.
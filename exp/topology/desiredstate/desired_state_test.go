@@ -684,7 +684,9 @@ func TestComputeControlPlaneVersion(t *testing.T) {
 			controlPlaneObj             *unstructured.Unstructured
 			upgradingMachineDeployments []string
 			upgradingMachinePools       []string
+			rolloutWindows              []clusterv1.RolloutWindow
 			expectedVersion             string
+			expectedRolloutDeferred     bool
 			wantErr                     bool
 		}{
 			{
@@ -809,6 +811,52 @@ func TestComputeControlPlaneVersion(t *testing.T) {
 					Build(),
 				expectedVersion: "v1.2.2",
 			},
+			{
+				name:            "should return cluster.spec.topology.version if one of the rollout windows is open",
+				hookResponse:    nonBlockingBeforeClusterUpgradeResponse,
+				topologyVersion: "v1.2.3",
+				controlPlaneObj: builder.ControlPlane("test1", "cp1").
+					WithSpecFields(map[string]interface{}{
+						"spec.version":  "v1.2.2",
+						"spec.replicas": int64(2),
+					}).
+					WithStatusFields(map[string]interface{}{
+						"status.version":             "v1.2.2",
+						"status.replicas":            int64(2),
+						"status.updatedReplicas":     int64(2),
+						"status.readyReplicas":       int64(2),
+						"status.unavailableReplicas": int64(0),
+					}).
+					Build(),
+				rolloutWindows: []clusterv1.RolloutWindow{
+					{Schedule: "0 0 1 1 *", Duration: metav1.Duration{Duration: time.Minute}},
+					{Schedule: "* * * * *", Duration: metav1.Duration{Duration: time.Hour}},
+				},
+				expectedVersion: "v1.2.3",
+			},
+			{
+				name:            "should return controlplane.spec.version if none of the rollout windows is open",
+				hookResponse:    nonBlockingBeforeClusterUpgradeResponse,
+				topologyVersion: "v1.2.3",
+				controlPlaneObj: builder.ControlPlane("test1", "cp1").
+					WithSpecFields(map[string]interface{}{
+						"spec.version":  "v1.2.2",
+						"spec.replicas": int64(2),
+					}).
+					WithStatusFields(map[string]interface{}{
+						"status.version":             "v1.2.2",
+						"status.replicas":            int64(2),
+						"status.updatedReplicas":     int64(2),
+						"status.readyReplicas":       int64(2),
+						"status.unavailableReplicas": int64(0),
+					}).
+					Build(),
+				rolloutWindows: []clusterv1.RolloutWindow{
+					{Schedule: "0 0 1 1 *", Duration: metav1.Duration{Duration: time.Minute}},
+				},
+				expectedVersion:         "v1.2.2",
+				expectedRolloutDeferred: true,
+			},
 			{
 				name:            "should fail if the BeforeClusterUpgrade hooks returns a failure response",
 				hookResponse:    failureBeforeClusterUpgradeResponse,
@@ -853,6 +901,9 @@ func TestComputeControlPlaneVersion(t *testing.T) {
 					UpgradeTracker:      scope.NewUpgradeTracker(),
 					HookResponseTracker: scope.NewHookResponseTracker(),
 				}
+				if len(tt.rolloutWindows) > 0 {
+					s.Current.Cluster.Spec.Topology = &clusterv1.Topology{Rollout: &clusterv1.TopologyRollout{Windows: tt.rolloutWindows}}
+				}
 				if len(tt.upgradingMachineDeployments) > 0 {
 					s.UpgradeTracker.MachineDeployments.MarkUpgrading(tt.upgradingMachineDeployments...)
 				}
@@ -882,6 +933,7 @@ func TestComputeControlPlaneVersion(t *testing.T) {
 					// Verify that if the upgrade is pending it is captured in the upgrade tracker.
 					upgradePending := tt.expectedVersion != tt.topologyVersion
 					g.Expect(s.UpgradeTracker.ControlPlane.IsPendingUpgrade).To(Equal(upgradePending))
+					g.Expect(s.UpgradeTracker.ControlPlane.IsRolloutDeferred).To(Equal(tt.expectedRolloutDeferred))
 				}
 			})
 		}
@@ -16,7 +16,11 @@ limitations under the License.
 
 package scope
 
-import "k8s.io/apimachinery/pkg/util/sets"
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
 
 // UpgradeTracker is a helper to capture the upgrade status and make upgrade decisions.
 type UpgradeTracker struct {
@@ -34,8 +38,18 @@ type ControlPlaneUpgradeTracker struct {
 	// - Upgrade is blocked by BeforeClusterUpgrade hook
 	// - Upgrade is blocked because the current ControlPlane is not stable (provisioning OR scaling OR upgrading)
 	// - Upgrade is blocked because any of the current MachineDeployments or MachinePools are upgrading.
+	// - Upgrade is deferred because none of the rollout windows of the Cluster is open.
 	IsPendingUpgrade bool
 
+	// IsRolloutDeferred is true if the Control Plane is not going to pick up the new version in the current
+	// reconcile loop because none of the rollout windows of the Cluster is open. False otherwise.
+	// Note: If IsRolloutDeferred is true IsPendingUpgrade is true as well.
+	IsRolloutDeferred bool
+
+	// NextRolloutWindowStart is the time the next rollout window of the Cluster starts; it is set only if
+	// IsRolloutDeferred is true, and it is the zero time if none of the windows starts in the next years.
+	NextRolloutWindowStart time.Time
+
 	// IsProvisioning is true if the current Control Plane is being provisioned for the first time. False otherwise.
 	IsProvisioning bool
 
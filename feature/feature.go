@@ -61,6 +61,16 @@ const (
 	//
 	// alpha: v1.5
 	MachineSetPreflightChecks featuregate.Feature = "MachineSetPreflightChecks"
+
+	// OrphanDetection is a feature gate for the detection and cleanup of orphaned infrastructure resources.
+	//
+	// alpha: v1.7
+	OrphanDetection featuregate.Feature = "OrphanDetection"
+
+	// ClusterGroup is a feature gate for the ClusterGroup functionality, rolling out upgrades across a set of Clusters.
+	//
+	// alpha: v1.7
+	ClusterGroup featuregate.Feature = "ClusterGroup"
 )
 
 func init() {
@@ -77,4 +87,6 @@ var defaultClusterAPIFeatureGates = map[featuregate.Feature]featuregate.FeatureS
 	KubeadmBootstrapFormatIgnition: {Default: false, PreRelease: featuregate.Alpha},
 	RuntimeSDK:                     {Default: false, PreRelease: featuregate.Alpha},
 	MachineSetPreflightChecks:      {Default: false, PreRelease: featuregate.Alpha},
+	OrphanDetection:                {Default: false, PreRelease: featuregate.Alpha},
+	ClusterGroup:                   {Default: false, PreRelease: featuregate.Alpha},
 }
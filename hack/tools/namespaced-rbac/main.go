@@ -0,0 +1,326 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// main is the main package for namespaced-rbac.
+//
+// namespaced-rbac reads the components YAML of a provider from stdin and writes to stdout the components
+// restricted to an allow-list of namespaces:
+//   - ClusterRoleBindings are replaced by RoleBindings in each of the namespaces; the rules of the ClusterRoles
+//     for cluster-scoped resources are moved to "<name>-cluster-scoped" ClusterRoles bound cluster-wide.
+//     Aggregated ClusterRoles get a "<name>-cluster-scoped" twin aggregating the cluster-scoped rules.
+//   - Admission webhooks get a namespaceSelector matching only the namespaces.
+//   - The manager containers get the --namespace flag set to the namespaces.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+const (
+	// clusterScopedSuffix is the suffix of the ClusterRoles and ClusterRoleBindings for cluster-scoped resources.
+	clusterScopedSuffix = "-cluster-scoped"
+
+	// managerContainerName is the name of the container of the controllers of the providers.
+	managerContainerName = "manager"
+)
+
+// clusterScopedResources are the cluster-scoped resources the Cluster API providers are granted permissions for,
+// by API group. Wildcard resources are considered namespaced.
+var clusterScopedResources = map[string]sets.Set[string]{
+	"":                             sets.New("namespaces", "nodes", "persistentvolumes"),
+	"admissionregistration.k8s.io": sets.New("mutatingwebhookconfigurations", "validatingwebhookconfigurations"),
+	"apiextensions.k8s.io":         sets.New("customresourcedefinitions", "customresourcedefinitions/status"),
+	"apiregistration.k8s.io":       sets.New("apiservices"),
+	"authentication.k8s.io":        sets.New("tokenreviews"),
+	"authorization.k8s.io":         sets.New("subjectaccessreviews", "selfsubjectaccessreviews"),
+	"certificates.k8s.io":          sets.New("certificatesigningrequests", "certificatesigningrequests/approval", "certificatesigningrequests/status"),
+	"rbac.authorization.k8s.io":    sets.New("clusterroles", "clusterrolebindings"),
+	"runtime.cluster.x-k8s.io":     sets.New("extensionconfigs", "extensionconfigs/status"),
+	"storage.k8s.io":               sets.New("storageclasses"),
+}
+
+var namespacesFlag = flag.String("namespaces", "", "Comma-separated list of namespaces the provider is restricted to")
+
+func main() {
+	flag.Parse()
+	if *namespacesFlag == "" {
+		klog.Fatal("Expected flag \"namespaces\" to be set")
+	}
+
+	in, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		klog.Fatalf("Failed to read components: %v", err)
+	}
+	out, err := restrictToNamespaces(in, strings.Split(*namespacesFlag, ","))
+	if err != nil {
+		klog.Fatalf("Failed to restrict components to namespaces: %v", err)
+	}
+	if _, err := os.Stdout.Write(out); err != nil {
+		klog.Fatalf("Failed to write components: %v", err)
+	}
+}
+
+// restrictToNamespaces returns the components YAML restricted to the given namespaces.
+func restrictToNamespaces(components []byte, namespaces []string) ([]byte, error) {
+	objs, err := utilyaml.ToUnstructured(components)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse components")
+	}
+
+	// Split the ClusterRoles first, so the ClusterRoleBindings can be bound to the cluster-scoped ClusterRoles.
+	splitRoles := map[string][]*rbacv1.ClusterRole{}
+	for _, obj := range objs {
+		if obj.GetKind() != "ClusterRole" {
+			continue
+		}
+		role := &rbacv1.ClusterRole{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, role); err != nil {
+			return nil, errors.Wrapf(err, "failed to convert ClusterRole %s", obj.GetName())
+		}
+		namespaced, clusterScoped := splitClusterRole(role)
+		splitRoles[role.Name] = []*rbacv1.ClusterRole{namespaced}
+		if clusterScoped != nil {
+			splitRoles[role.Name] = append(splitRoles[role.Name], clusterScoped)
+		}
+	}
+
+	result := []unstructured.Unstructured{}
+	for _, obj := range objs {
+		switch obj.GetKind() {
+		case "ClusterRole":
+			for _, r := range splitRoles[obj.GetName()] {
+				u, err := toUnstructured(r)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, *u)
+			}
+			continue
+		case "ClusterRoleBinding":
+			binding := &rbacv1.ClusterRoleBinding{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, binding); err != nil {
+				return nil, errors.Wrapf(err, "failed to convert ClusterRoleBinding %s", obj.GetName())
+			}
+			for _, b := range splitClusterRoleBinding(binding, namespaces, len(splitRoles[binding.RoleRef.Name]) > 1) {
+				u, err := toUnstructured(b)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, *u)
+			}
+			continue
+		case "MutatingWebhookConfiguration", "ValidatingWebhookConfiguration":
+			if err := restrictWebhooks(&obj, namespaces); err != nil {
+				return nil, err
+			}
+		case "Deployment":
+			if err := restrictManager(&obj, namespaces); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, obj)
+	}
+
+	return utilyaml.FromUnstructured(result)
+}
+
+// splitClusterRole returns the ClusterRole with only the rules for namespaced resources, and a ClusterRole with the
+// rules for cluster-scoped resources, if any. The labels to aggregate the ClusterRole and the aggregation rules
+// are suffixed, so the cluster-scoped ClusterRoles are aggregated into the twin of the aggregated ClusterRoles.
+func splitClusterRole(role *rbacv1.ClusterRole) (*rbacv1.ClusterRole, *rbacv1.ClusterRole) {
+	clusterScoped := &rbacv1.ClusterRole{
+		TypeMeta: role.TypeMeta,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        role.Name + clusterScopedSuffix,
+			Labels:      suffixKeys(role.Labels),
+			Annotations: role.Annotations,
+		},
+	}
+	if role.AggregationRule != nil {
+		clusterScoped.AggregationRule = &rbacv1.AggregationRule{}
+		for _, selector := range role.AggregationRule.ClusterRoleSelectors {
+			clusterScoped.AggregationRule.ClusterRoleSelectors = append(clusterScoped.AggregationRule.ClusterRoleSelectors, metav1.LabelSelector{
+				MatchLabels: suffixKeys(selector.MatchLabels),
+			})
+		}
+		return role, clusterScoped
+	}
+
+	namespacedRules := []rbacv1.PolicyRule{}
+	for _, rule := range role.Rules {
+		if len(rule.NonResourceURLs) > 0 {
+			clusterScoped.Rules = append(clusterScoped.Rules, rule)
+			continue
+		}
+		namespaced := rule.DeepCopy()
+		namespaced.Resources = nil
+		for _, resource := range rule.Resources {
+			if !isClusterScoped(rule.APIGroups, resource) {
+				namespaced.Resources = append(namespaced.Resources, resource)
+				continue
+			}
+			clusterScopedRule := rule.DeepCopy()
+			clusterScopedRule.Resources = []string{resource}
+			clusterScoped.Rules = append(clusterScoped.Rules, *clusterScopedRule)
+		}
+		if len(namespaced.Resources) > 0 {
+			namespacedRules = append(namespacedRules, *namespaced)
+		}
+	}
+	if len(clusterScoped.Rules) == 0 {
+		return role, nil
+	}
+	role.Rules = namespacedRules
+	return role, clusterScoped
+}
+
+// isClusterScoped returns true if the resource is cluster-scoped in any of the API groups.
+func isClusterScoped(apiGroups []string, resource string) bool {
+	for _, group := range apiGroups {
+		if clusterScopedResources[group].Has(resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// suffixKeys returns the given labels with clusterScopedSuffix appended to the keys of the aggregation labels.
+func suffixKeys(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	suffixed := map[string]string{}
+	for k, v := range labels {
+		if strings.Contains(k, "aggregate-to-") {
+			k += clusterScopedSuffix
+		}
+		suffixed[k] = v
+	}
+	return suffixed
+}
+
+// splitClusterRoleBinding returns a RoleBinding to the same ClusterRole in each of the namespaces and, if the
+// ClusterRole has rules for cluster-scoped resources, a ClusterRoleBinding to the cluster-scoped ClusterRole.
+func splitClusterRoleBinding(binding *rbacv1.ClusterRoleBinding, namespaces []string, clusterScoped bool) []runtime.Object {
+	bindings := []runtime.Object{}
+	for _, namespace := range namespaces {
+		bindings = append(bindings, &rbacv1.RoleBinding{
+			TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        binding.Name,
+				Namespace:   namespace,
+				Labels:      binding.Labels,
+				Annotations: binding.Annotations,
+			},
+			RoleRef:  binding.RoleRef,
+			Subjects: binding.Subjects,
+		})
+	}
+	if clusterScoped {
+		clusterScopedBinding := binding.DeepCopy()
+		clusterScopedBinding.Name += clusterScopedSuffix
+		clusterScopedBinding.RoleRef.Name += clusterScopedSuffix
+		bindings = append(bindings, clusterScopedBinding)
+	}
+	return bindings
+}
+
+// restrictWebhooks adds to the admission webhooks a namespaceSelector matching only the given namespaces.
+func restrictWebhooks(obj *unstructured.Unstructured, namespaces []string) error {
+	webhooks, _, err := unstructured.NestedSlice(obj.Object, "webhooks")
+	if err != nil {
+		return errors.Wrapf(err, "failed to get webhooks of %s %s", obj.GetKind(), obj.GetName())
+	}
+	values := make([]interface{}, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		values = append(values, namespace)
+	}
+	for i := range webhooks {
+		webhook, ok := webhooks[i].(map[string]interface{})
+		if !ok {
+			return errors.Errorf("invalid webhook in %s %s", obj.GetKind(), obj.GetName())
+		}
+		expressions, _, err := unstructured.NestedSlice(webhook, "namespaceSelector", "matchExpressions")
+		if err != nil {
+			return errors.Wrapf(err, "failed to get namespaceSelector of %s %s", obj.GetKind(), obj.GetName())
+		}
+		expressions = append(expressions, map[string]interface{}{
+			"key":      corev1.LabelMetadataName,
+			"operator": string(metav1.LabelSelectorOpIn),
+			"values":   values,
+		})
+		if err := unstructured.SetNestedSlice(webhook, expressions, "namespaceSelector", "matchExpressions"); err != nil {
+			return errors.Wrapf(err, "failed to set namespaceSelector of %s %s", obj.GetKind(), obj.GetName())
+		}
+	}
+	return unstructured.SetNestedSlice(obj.Object, webhooks, "webhooks")
+}
+
+// restrictManager sets the --namespace flag of the manager containers to the given namespaces.
+func restrictManager(obj *unstructured.Unstructured, namespaces []string) error {
+	containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+	if err != nil {
+		return errors.Wrapf(err, "failed to get containers of Deployment %s", obj.GetName())
+	}
+	for i := range containers {
+		container, ok := containers[i].(map[string]interface{})
+		if !ok || container["name"] != managerContainerName {
+			continue
+		}
+		args, _, err := unstructured.NestedStringSlice(container, "args")
+		if err != nil {
+			return errors.Wrapf(err, "failed to get args of Deployment %s", obj.GetName())
+		}
+		restricted := []interface{}{}
+		for j := 0; j < len(args); j++ {
+			if args[j] == "--namespace" {
+				j++
+				continue
+			}
+			if strings.HasPrefix(args[j], "--namespace=") {
+				continue
+			}
+			restricted = append(restricted, args[j])
+		}
+		restricted = append(restricted, fmt.Sprintf("--namespace=%s", strings.Join(namespaces, ",")))
+		container["args"] = restricted
+	}
+	return unstructured.SetNestedSlice(obj.Object, containers, "spec", "template", "spec", "containers")
+}
+
+func toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert to unstructured")
+	}
+	unstructured.RemoveNestedField(u, "metadata", "creationTimestamp")
+	return &unstructured.Unstructured{Object: u}, nil
+}
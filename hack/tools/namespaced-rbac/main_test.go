@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// main is the main package for namespaced-rbac.
+package main
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+var components = `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: capi-aggregated-manager-role
+aggregationRule:
+  clusterRoleSelectors:
+  - matchLabels:
+      cluster.x-k8s.io/aggregate-to-manager: "true"
+rules: []
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: capi-manager-role
+  labels:
+    cluster.x-k8s.io/aggregate-to-manager: "true"
+rules:
+- apiGroups:
+  - ""
+  resources:
+  - namespaces
+  - secrets
+  verbs:
+  - get
+  - list
+  - watch
+- apiGroups:
+  - cluster.x-k8s.io
+  resources:
+  - clusters
+  verbs:
+  - get
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: capi-manager-rolebinding
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: capi-aggregated-manager-role
+subjects:
+- kind: ServiceAccount
+  name: capi-manager
+  namespace: capi-system
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: capi-validating-webhook-configuration
+webhooks:
+- name: validation.cluster.cluster.x-k8s.io
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: capi-controller-manager
+  namespace: capi-system
+spec:
+  template:
+    spec:
+      containers:
+      - name: manager
+        args:
+        - --leader-elect
+        - --namespace=tenant-z
+`
+
+func TestRestrictToNamespaces(t *testing.T) {
+	g := NewWithT(t)
+
+	out, err := restrictToNamespaces([]byte(components), []string{"tenant-a", "tenant-b"})
+	g.Expect(err).ToNot(HaveOccurred())
+	objs, err := utilyaml.ToUnstructured(out)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	find := func(kind, namespace, name string) *unstructured.Unstructured {
+		for i := range objs {
+			if objs[i].GetKind() == kind && objs[i].GetNamespace() == namespace && objs[i].GetName() == name {
+				return &objs[i]
+			}
+		}
+		return nil
+	}
+	g.Expect(objs).To(HaveLen(9))
+
+	// The rules for cluster-scoped resources are moved to a ClusterRole aggregated into the twin of the aggregated ClusterRole.
+	role := find("ClusterRole", "", "capi-manager-role")
+	g.Expect(role).ToNot(BeNil())
+	rules, _, _ := unstructured.NestedSlice(role.Object, "rules")
+	g.Expect(rules).To(HaveLen(2))
+	g.Expect(rules[0]).To(HaveKeyWithValue("resources", []interface{}{"secrets"}))
+	clusterScopedRole := find("ClusterRole", "", "capi-manager-role-cluster-scoped")
+	g.Expect(clusterScopedRole).ToNot(BeNil())
+	g.Expect(clusterScopedRole.GetLabels()).To(HaveKeyWithValue("cluster.x-k8s.io/aggregate-to-manager-cluster-scoped", "true"))
+	rules, _, _ = unstructured.NestedSlice(clusterScopedRole.Object, "rules")
+	g.Expect(rules).To(HaveLen(1))
+	g.Expect(rules[0]).To(HaveKeyWithValue("resources", []interface{}{"namespaces"}))
+	aggregatedRole := find("ClusterRole", "", "capi-aggregated-manager-role-cluster-scoped")
+	g.Expect(aggregatedRole).ToNot(BeNil())
+	selectors, _, _ := unstructured.NestedSlice(aggregatedRole.Object, "aggregationRule", "clusterRoleSelectors")
+	g.Expect(selectors).To(HaveLen(1))
+	g.Expect(selectors[0]).To(HaveKeyWithValue("matchLabels", map[string]interface{}{"cluster.x-k8s.io/aggregate-to-manager-cluster-scoped": "true"}))
+
+	// The ClusterRoleBinding is replaced by RoleBindings and a ClusterRoleBinding to the cluster-scoped ClusterRole.
+	g.Expect(find("ClusterRoleBinding", "", "capi-manager-rolebinding")).To(BeNil())
+	g.Expect(find("RoleBinding", "tenant-a", "capi-manager-rolebinding")).ToNot(BeNil())
+	g.Expect(find("RoleBinding", "tenant-b", "capi-manager-rolebinding")).ToNot(BeNil())
+	clusterScopedBinding := find("ClusterRoleBinding", "", "capi-manager-rolebinding-cluster-scoped")
+	g.Expect(clusterScopedBinding).ToNot(BeNil())
+	roleRef, _, _ := unstructured.NestedString(clusterScopedBinding.Object, "roleRef", "name")
+	g.Expect(roleRef).To(Equal("capi-aggregated-manager-role-cluster-scoped"))
+
+	// The webhooks only match objects in the namespaces.
+	webhookConfiguration := find("ValidatingWebhookConfiguration", "", "capi-validating-webhook-configuration")
+	g.Expect(webhookConfiguration).ToNot(BeNil())
+	webhooks, _, _ := unstructured.NestedSlice(webhookConfiguration.Object, "webhooks")
+	expressions, _, _ := unstructured.NestedSlice(webhooks[0].(map[string]interface{}), "namespaceSelector", "matchExpressions")
+	g.Expect(expressions).To(ConsistOf(map[string]interface{}{
+		"key":      "kubernetes.io/metadata.name",
+		"operator": "In",
+		"values":   []interface{}{"tenant-a", "tenant-b"},
+	}))
+
+	// The manager only watches the namespaces.
+	deployment := find("Deployment", "capi-system", "capi-controller-manager")
+	g.Expect(deployment).ToNot(BeNil())
+	containers, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	g.Expect(containers[0]).To(HaveKeyWithValue("args", []interface{}{"--leader-elect", "--namespace=tenant-a,tenant-b"}))
+}
@@ -48,6 +48,7 @@ func (src *Cluster) ConvertTo(dstRaw conversion.Hub) error {
 	if restored.Spec.Topology != nil {
 		dst.Spec.Topology = restored.Spec.Topology
 	}
+	dst.Spec.MetadataPropagation = restored.Spec.MetadataPropagation
 
 	return nil
 }
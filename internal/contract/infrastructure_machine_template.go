@@ -49,3 +49,29 @@ func (c *InfrastructureMachineTemplateTemplate) Metadata() *Metadata {
 		path: Path{"spec", "template", "metadata"},
 	}
 }
+
+// NodeInfo provides access to the status.nodeInfo of an InfrastructureMachineTemplate.
+// Note: this field is optional; it is used to report the properties of the Nodes created from the template, e.g.
+// the architecture used to resolve images or by the autoscaler when scaling from zero.
+func (c *InfrastructureMachineTemplateContract) NodeInfo() *InfrastructureMachineTemplateNodeInfo {
+	return &InfrastructureMachineTemplateNodeInfo{}
+}
+
+// InfrastructureMachineTemplateNodeInfo provides a helper struct for working with the status.nodeInfo of an InfrastructureMachineTemplate.
+type InfrastructureMachineTemplateNodeInfo struct{}
+
+// Architecture provides access to the status.nodeInfo.architecture field of an InfrastructureMachineTemplate,
+// e.g. amd64 or arm64.
+func (c *InfrastructureMachineTemplateNodeInfo) Architecture() *String {
+	return &String{
+		path: Path{"status", "nodeInfo", "architecture"},
+	}
+}
+
+// OperatingSystem provides access to the status.nodeInfo.operatingSystem field of an InfrastructureMachineTemplate,
+// e.g. linux or windows.
+func (c *InfrastructureMachineTemplateNodeInfo) OperatingSystem() *String {
+	return &String{
+		path: Path{"status", "nodeInfo", "operatingSystem"},
+	}
+}
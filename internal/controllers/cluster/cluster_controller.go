@@ -42,18 +42,29 @@ import (
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/internal/hooks"
+	"sigs.k8s.io/cluster-api/internal/util/metrics"
 	"sigs.k8s.io/cluster-api/util"
-	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/cachelimit"
 	"sigs.k8s.io/cluster-api/util/collections"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/finalizers"
+	clog "sigs.k8s.io/cluster-api/util/log"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/paused"
 	"sigs.k8s.io/cluster-api/util/predicates"
+	"sigs.k8s.io/cluster-api/util/priority"
+	"sigs.k8s.io/cluster-api/util/requeue"
+	"sigs.k8s.io/cluster-api/util/throttle"
+	"sigs.k8s.io/cluster-api/util/tracing"
 )
 
 const (
 	// deleteRequeueAfter is how long to wait before checking again to see if the cluster still has children during
 	// deletion.
 	deleteRequeueAfter = 5 * time.Second
+
+	// notAcceptedRequeueAfter is how long to wait before checking again if a new Cluster can be accepted for provisioning.
+	notAcceptedRequeueAfter = time.Minute
 )
 
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
@@ -77,15 +88,15 @@ type Reconciler struct {
 }
 
 func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
-	c, err := ctrl.NewControllerManagedBy(mgr).
-		For(&clusterv1.Cluster{}).
+	c, err := priority.For(ctrl.NewControllerManagedBy(mgr), &clusterv1.Cluster{}).
 		Watches(
 			&clusterv1.Machine{},
 			handler.EnqueueRequestsFromMapFunc(r.controlPlaneMachineToCluster),
 		).
 		WithOptions(options).
-		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
-		Build(r)
+		WithEventFilter(predicates.ResourceHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
+		Build(requeue.Reconciler(throttle.Reconciler("cluster", r.Client, &clusterv1.Cluster{},
+			metrics.InstrumentReconciler("cluster", r.Client, &clusterv1.Cluster{}, r))))
 
 	if err != nil {
 		return errors.Wrap(err, "failed setting up with a controller manager")
@@ -100,6 +111,9 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opt
 }
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx, span := tracing.StartReconcile(ctx, "Cluster", req)
+	defer func() { tracing.End(span, reterr) }()
+
 	log := ctrl.LoggerFrom(ctx)
 
 	// Fetch the Cluster instance.
@@ -115,10 +129,19 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Re
 		return ctrl.Result{}, err
 	}
 
-	// Return early if the object or Cluster is paused.
-	if annotations.IsPaused(cluster, cluster) {
-		log.Info("Reconciliation is paused for this object")
-		return ctrl.Result{}, nil
+	ctx, log = clog.AddCorrelationID(ctx, cluster)
+
+	// Set the Paused condition, and return early if the object or Cluster is paused.
+	if isPaused, err := paused.EnsurePausedCondition(ctx, r.Client, cluster, cluster); err != nil || isPaused {
+		if isPaused {
+			log.Info("Reconciliation is paused for this object")
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Add finalizer first if not set to avoid the race condition between init and delete.
+	if finalizerAdded, err := finalizers.EnsureFinalizer(ctx, r.Client, cluster, clusterv1.ClusterFinalizer); err != nil || finalizerAdded {
+		return ctrl.Result{}, err
 	}
 
 	// Initialize the patch helper.
@@ -147,13 +170,6 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Re
 		return r.reconcileDelete(ctx, cluster)
 	}
 
-	// Add finalizer first if not set to avoid the race condition between init and delete.
-	// Note: Finalizers in general can only be added when the deletionTimestamp is not set.
-	if !controllerutil.ContainsFinalizer(cluster, clusterv1.ClusterFinalizer) {
-		controllerutil.AddFinalizer(cluster, clusterv1.ClusterFinalizer)
-		return ctrl.Result{}, nil
-	}
-
 	// Handle normal reconciliation loop.
 	return r.reconcile(ctx, cluster)
 }
@@ -175,6 +191,7 @@ func patchCluster(ctx context.Context, patchHelper *patch.Helper, cluster *clust
 			clusterv1.ReadyCondition,
 			clusterv1.ControlPlaneReadyCondition,
 			clusterv1.InfrastructureReadyCondition,
+			clusterv1.ClusterAcceptedCondition,
 		}},
 	)
 	return patchHelper.Patch(ctx, cluster, options...)
@@ -184,6 +201,17 @@ func patchCluster(ctx context.Context, patchHelper *patch.Helper, cluster *clust
 func (r *Reconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx)
 
+	// Do not accept new Clusters for provisioning while the caches are growing out of control,
+	// so the manager degrades gracefully instead of running out of memory.
+	if cluster.Status.Phase == "" || conditions.IsFalse(cluster, clusterv1.ClusterAcceptedCondition) {
+		if reject, message := cachelimit.RejectNewClusters(); reject {
+			log.Info("Not accepting the Cluster for provisioning", "reason", message)
+			conditions.MarkFalse(cluster, clusterv1.ClusterAcceptedCondition, clusterv1.CacheObjectLimitExceededReason, clusterv1.ConditionSeverityWarning, message)
+			return ctrl.Result{RequeueAfter: notAcceptedRequeueAfter}, nil
+		}
+		conditions.Delete(cluster, clusterv1.ClusterAcceptedCondition)
+	}
+
 	if cluster.Spec.Topology != nil {
 		if cluster.Spec.ControlPlaneRef == nil || cluster.Spec.InfrastructureRef == nil {
 			// TODO: add a condition to surface this scenario
@@ -203,7 +231,9 @@ func (r *Reconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster)
 	errs := []error{}
 	for _, phase := range phases {
 		// Call the inner reconciliation methods.
-		phaseResult, err := phase(ctx, cluster)
+		phaseCtx, span := tracing.StartPhase(ctx, phase)
+		phaseResult, err := phase(phaseCtx, cluster)
+		tracing.End(span, err)
 		if err != nil {
 			errs = append(errs, err)
 		}
@@ -19,11 +19,11 @@ package cluster
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -32,12 +32,16 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/internal/contract"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/controlplane"
 	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	capilabels "sigs.k8s.io/cluster-api/util/labels"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/requeue"
 	"sigs.k8s.io/cluster-api/util/secret"
 )
 
@@ -87,7 +91,7 @@ func (r *Reconciler) reconcileExternal(ctx context.Context, cluster *clusterv1.C
 	if err != nil {
 		if apierrors.IsNotFound(errors.Cause(err)) {
 			log.Info("Could not find external object for cluster, requeuing", "refGroupVersionKind", ref.GroupVersionKind(), "refName", ref.Name)
-			return external.ReconcileOutput{RequeueAfter: 30 * time.Second}, nil
+			return external.ReconcileOutput{RequeueAfter: requeue.After(requeue.NotFoundErrorClass)}, nil
 		}
 		return external.ReconcileOutput{}, err
 	}
@@ -122,6 +126,9 @@ func (r *Reconciler) reconcileExternal(ctx context.Context, cluster *clusterv1.C
 	labels[clusterv1.ClusterNameLabel] = cluster.Name
 	obj.SetLabels(labels)
 
+	// Propagate the labels and annotations defined in the Cluster's spec.metadataPropagation.
+	capilabels.PropagateClusterMetadata(cluster, obj)
+
 	// Always attempt to Patch the external object.
 	if err := patchHelper.Patch(ctx, obj); err != nil {
 		return external.ReconcileOutput{}, err
@@ -262,8 +269,9 @@ func (r *Reconciler) reconcileControlPlane(ctx context.Context, cluster *cluster
 
 	// Update cluster.Status.ControlPlaneInitialized if it hasn't already been set
 	// Determine if the control plane provider is initialized.
+	// NOTE: Externally managed control planes not reporting status.initialized are initialized once ready.
 	if !conditions.IsTrue(cluster, clusterv1.ControlPlaneInitializedCondition) {
-		initialized, err := external.IsInitialized(controlPlaneConfig)
+		initialized, err := controlplane.IsInitialized(controlPlaneConfig)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
@@ -274,9 +282,33 @@ func (r *Reconciler) reconcileControlPlane(ctx context.Context, cluster *cluster
 		}
 	}
 
+	if util.IsExternalManagedControlPlane(controlPlaneConfig) {
+		r.checkExternalManagedControlPlaneVersion(ctx, cluster, controlPlaneConfig)
+	}
+
 	return ctrl.Result{}, nil
 }
 
+// checkExternalManagedControlPlaneVersion surfaces when an externally managed control plane reports a version
+// newer than spec.version, e.g. because the managed service upgraded it automatically; in this case the Kubernetes
+// version skew policy assumed when upgrading the control plane and the workers is not guaranteed anymore.
+func (r *Reconciler) checkExternalManagedControlPlaneVersion(ctx context.Context, cluster *clusterv1.Cluster, controlPlaneConfig *unstructured.Unstructured) {
+	log := ctrl.LoggerFrom(ctx)
+
+	desiredVersion, err := contract.ControlPlane().Version().Get(controlPlaneConfig)
+	if err != nil {
+		return
+	}
+	reportedVersion, err := contract.ControlPlane().StatusVersion().Get(controlPlaneConfig)
+	if err != nil || *reportedVersion == "" {
+		return
+	}
+	if err := controlplane.CheckReportedVersion(*desiredVersion, *reportedVersion); err != nil {
+		log.Info("Externally managed control plane version skew detected", "err", err.Error())
+		r.recorder.Eventf(cluster, corev1.EventTypeWarning, "ControlPlaneVersionSkew", "Control plane %s: %v", controlPlaneConfig.GetName(), err)
+	}
+}
+
 func (r *Reconciler) reconcileKubeconfig(ctx context.Context, cluster *clusterv1.Cluster) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx)
 
@@ -297,7 +329,7 @@ func (r *Reconciler) reconcileKubeconfig(ctx context.Context, cluster *clusterv1
 		if err := kubeconfig.CreateSecret(ctx, r.Client, cluster); err != nil {
 			if err == kubeconfig.ErrDependentCertificateNotFound {
 				log.Info("Could not find secret for cluster, requeuing", "Secret", secret.ClusterCA)
-				return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+				return ctrl.Result{RequeueAfter: requeue.After(requeue.NotFoundErrorClass)}, nil
 			}
 			return ctrl.Result{}, err
 		}
@@ -32,6 +32,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/internal/test/builder"
+	"sigs.k8s.io/cluster-api/util/conditions"
 )
 
 func TestClusterReconcilePhases(t *testing.T) {
@@ -153,6 +154,59 @@ func TestClusterReconcilePhases(t *testing.T) {
 		}
 	})
 
+	t.Run("reconcile externally managed control plane", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "test-namespace",
+			},
+			Spec: clusterv1.ClusterSpec{
+				ControlPlaneRef: &corev1.ObjectReference{
+					APIVersion: builder.ControlPlaneGroupVersion.String(),
+					Kind:       builder.GenericControlPlaneKind,
+					Name:       "test",
+					Namespace:  "test-namespace",
+				},
+			},
+		}
+		// The control plane does not report status.initialized, and the managed service upgraded it beyond spec.version.
+		controlPlane := &unstructured.Unstructured{Object: map[string]interface{}{
+			"kind":       builder.GenericControlPlaneKind,
+			"apiVersion": builder.ControlPlaneGroupVersion.String(),
+			"metadata": map[string]interface{}{
+				"name":      "test",
+				"namespace": "test-namespace",
+			},
+			"spec": map[string]interface{}{
+				"version": "v1.29.0",
+			},
+			"status": map[string]interface{}{
+				"ready":                       true,
+				"externalManagedControlPlane": true,
+				"version":                     "v1.29.3",
+			},
+		}}
+
+		c := fake.NewClientBuilder().
+			WithObjects(builder.GenericControlPlaneCRD.DeepCopy(), cluster, controlPlane).
+			Build()
+		recorder := record.NewFakeRecorder(32)
+		r := &Reconciler{
+			Client:                    c,
+			UnstructuredCachingClient: c,
+			recorder:                  recorder,
+		}
+
+		_, err := r.reconcileControlPlane(ctx, cluster)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(cluster.Status.ControlPlaneReady).To(BeTrue())
+		g.Expect(conditions.IsTrue(cluster, clusterv1.ControlPlaneInitializedCondition)).To(BeTrue())
+		g.Expect(recorder.Events).To(Receive(ContainSubstring("ControlPlaneReady")))
+		g.Expect(recorder.Events).To(Receive(ContainSubstring("ControlPlaneVersionSkew")))
+	})
+
 	t.Run("reconcile kubeconfig", func(t *testing.T) {
 		cluster := &clusterv1.Cluster{
 			ObjectMeta: metav1.ObjectMeta{
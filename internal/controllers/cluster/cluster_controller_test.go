@@ -26,8 +26,10 @@ import (
 	utilfeature "k8s.io/component-base/featuregate/testing"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
@@ -35,6 +37,7 @@ import (
 	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/internal/test/builder"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/cachelimit"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 )
@@ -808,3 +811,44 @@ func TestReconcileControlPlaneInitializedControlPlaneRef(t *testing.T) {
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(conditions.Has(c, clusterv1.ControlPlaneInitializedCondition)).To(BeFalse())
 }
+
+func TestClusterReconciler_reconcileNotAccepted(t *testing.T) {
+	g := NewWithT(t)
+
+	informers := &informertest.FakeInformers{Scheme: fakeScheme}
+	cachelimit.SetPolicy(cachelimit.Policy{MaxObjectsPerType: 1, RejectNewClusters: true})
+	defer cachelimit.SetPolicy(cachelimit.Policy{})
+	c := cachelimit.NewCache("test", informers, fakeScheme)
+	defer cachelimit.Forget("test")
+
+	machines, err := c.GetInformer(ctx, &clusterv1.Machine{})
+	g.Expect(err).ToNot(HaveOccurred())
+	machines.(*controllertest.FakeInformer).Add(&clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "m1"}})
+	machines.(*controllertest.FakeInformer).Add(&clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "m2"}})
+
+	// Topology without refs, so the reconcile returns as soon as the Cluster is accepted.
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: "test"},
+		Spec:       clusterv1.ClusterSpec{Topology: &clusterv1.Topology{}},
+	}
+
+	r := &Reconciler{}
+	res, err := r.reconcile(ctx, cluster)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(res.RequeueAfter).To(Equal(notAcceptedRequeueAfter))
+	g.Expect(conditions.IsFalse(cluster, clusterv1.ClusterAcceptedCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(cluster, clusterv1.ClusterAcceptedCondition)).To(Equal(clusterv1.CacheObjectLimitExceededReason))
+
+	// A Cluster not accepted is still not accepted after its phase has been set.
+	cluster.Status.Phase = string(clusterv1.ClusterPhaseProvisioning)
+	res, err = r.reconcile(ctx, cluster)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(res.RequeueAfter).To(Equal(notAcceptedRequeueAfter))
+
+	// The Cluster is accepted when the cache is back within the limit.
+	machines.(*controllertest.FakeInformer).Delete(&clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "m1"}})
+	res, err = r.reconcile(ctx, cluster)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(res.IsZero()).To(BeTrue())
+	g.Expect(conditions.Has(cluster, clusterv1.ClusterAcceptedCondition)).To(BeFalse())
+}
@@ -0,0 +1,413 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfigrotation
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+const (
+	// verifyTimeout is the timeout of the call to the workload cluster verifying new credentials.
+	verifyTimeout = 10 * time.Second
+)
+
+// kubeconfigPurposes are the purposes of the kubeconfig Secrets whose client certificates are rotated.
+var kubeconfigPurposes = []secret.Purpose{secret.Kubeconfig, secret.InternalKubeconfig}
+
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;patch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch;patch
+
+// Reconciler rotates the client certificates in the kubeconfig Secrets generated by Cluster API for a Cluster,
+// that are the Secrets with the cluster name label and the Cluster API Secret type which are not controlled by
+// another object; kubeconfig Secrets controlled by a control plane provider are rotated by the provider.
+// If only the kubeconfig Secret handed out to users exists, the controller-internal kubeconfig Secret is created,
+// so the management cluster controllers use separate credentials.
+type Reconciler struct {
+	Client client.Client
+
+	// Tracker is used to reset the connection to the workload cluster after its kubeconfig Secrets have changed,
+	// so the new credentials are used; if not set, the connection is not reset.
+	Tracker *remote.ClusterCacheTracker
+
+	// SecretCachingClient is the client used to read the kubeconfig Secrets; if not set, Client is used.
+	SecretCachingClient client.Client
+
+	// WatchFilterValue is the label value used to filter events prior to reconciliation.
+	WatchFilterValue string
+
+	// RenewalThreshold is how long before the expiry of a client certificate the certificate is rotated;
+	// if not set, certs.ClientCertificateRenewalDuration is used.
+	RenewalThreshold time.Duration
+
+	// verifyKubeconfig checks that a kubeconfig can be used to access the workload cluster.
+	verifyKubeconfig func(ctx context.Context, data []byte) error
+}
+
+func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&clusterv1.Cluster{}).
+		Named("kubeconfigrotation").
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(kubeconfigSecretToCluster),
+			builder.OnlyMetadata,
+		).
+		WithOptions(options).
+		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
+		Complete(r)
+	if err != nil {
+		return errors.Wrap(err, "failed setting up with a controller manager")
+	}
+
+	if r.verifyKubeconfig == nil {
+		r.verifyKubeconfig = r.defaultVerifyKubeconfig
+	}
+	return nil
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	cluster := &clusterv1.Cluster{}
+	if err := r.Client.Get(ctx, req.NamespacedName, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Return early if the Cluster is paused or it is being deleted.
+	if annotations.IsPaused(cluster, cluster) || !cluster.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	patchHelper, err := patch.NewHelper(cluster, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, cluster, patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+			clusterv1.KubeconfigCredentialsRotatedCondition,
+		}}); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	return r.reconcile(ctx, cluster)
+}
+
+func (r *Reconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	configSecrets, err := r.generatedKubeconfigSecrets(ctx, cluster)
+	if err != nil {
+		conditions.MarkFalse(cluster, clusterv1.KubeconfigCredentialsRotatedCondition, clusterv1.KubeconfigCredentialsRotationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, err
+	}
+	if len(configSecrets) == 0 {
+		conditions.Delete(cluster, clusterv1.KubeconfigCredentialsRotatedCondition)
+		return ctrl.Result{}, nil
+	}
+
+	if annotations.HasWorkloadClusterAuth(cluster) {
+		return ctrl.Result{}, r.removeClientCertificates(ctx, cluster, configSecrets)
+	}
+
+	internalSecret, err := r.ensureInternalKubeconfigSecret(ctx, cluster, configSecrets)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if internalSecret != nil {
+		configSecrets = append(configSecrets, internalSecret)
+		r.resetAccessor(ctx, cluster)
+	}
+
+	threshold := r.RenewalThreshold
+	if threshold <= 0 {
+		threshold = certs.ClientCertificateRenewalDuration
+	}
+
+	var due []*corev1.Secret
+	var nextRotation time.Time
+	for _, configSecret := range configSecrets {
+		rotationTime, err := kubeconfig.ClientCertRotationTime(configSecret, threshold)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to get the rotation time of Secret %s", klog.KObj(configSecret))
+			conditions.MarkFalse(cluster, clusterv1.KubeconfigCredentialsRotatedCondition, clusterv1.KubeconfigCredentialsRotationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return ctrl.Result{}, err
+		}
+		// Note: kubeconfigs without client certificates, e.g. using tokens, are not rotated.
+		if rotationTime.IsZero() {
+			continue
+		}
+		if time.Now().Before(rotationTime) {
+			nextRotation = earliest(nextRotation, rotationTime)
+			continue
+		}
+		due = append(due, configSecret)
+	}
+
+	// Generate and verify all the new kubeconfigs before storing any of them, so the credentials in use
+	// are never replaced with credentials which do not work.
+	rotated := make([][]byte, len(due))
+	for i, configSecret := range due {
+		data, err := kubeconfig.Regenerate(ctx, r.Client, configSecret)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to regenerate Secret %s", klog.KObj(configSecret))
+			conditions.MarkFalse(cluster, clusterv1.KubeconfigCredentialsRotatedCondition, clusterv1.KubeconfigCredentialsRotationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return ctrl.Result{}, err
+		}
+		if err := r.verifyKubeconfig(ctx, data); err != nil {
+			err = errors.Wrapf(err, "failed to verify the new credentials for Secret %s", klog.KObj(configSecret))
+			conditions.MarkFalse(cluster, clusterv1.KubeconfigCredentialsRotatedCondition, clusterv1.KubeconfigCredentialsVerificationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return ctrl.Result{}, err
+		}
+		rotated[i] = data
+	}
+
+	for i, configSecret := range due {
+		// Note: the optimistic lock makes the patch fail if the Secret has been changed in the meantime,
+		// e.g. by a user replacing the kubeconfig, instead of overwriting the change.
+		original := configSecret.DeepCopy()
+		configSecret.Data[secret.KubeconfigDataName] = rotated[i]
+		if err := r.Client.Patch(ctx, configSecret, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+			err = errors.Wrapf(err, "failed to patch Secret %s", klog.KObj(configSecret))
+			conditions.MarkFalse(cluster, clusterv1.KubeconfigCredentialsRotatedCondition, clusterv1.KubeconfigCredentialsRotationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return ctrl.Result{}, err
+		}
+		log.Info("Rotated kubeconfig client certificate", "Secret", klog.KObj(configSecret))
+
+		rotationTime, err := kubeconfig.ClientCertRotationTime(configSecret, threshold)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to get the rotation time of Secret %s", klog.KObj(configSecret))
+		}
+		nextRotation = earliest(nextRotation, rotationTime)
+	}
+
+	if len(due) > 0 {
+		r.resetAccessor(ctx, cluster)
+	}
+
+	conditions.MarkTrue(cluster, clusterv1.KubeconfigCredentialsRotatedCondition)
+	if nextRotation.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: time.Until(nextRotation)}, nil
+}
+
+// removeClientCertificates removes the client certificates from the kubeconfig Secrets of a Cluster whose management
+// cluster access is configured with the WorkloadClusterAuthAnnotation, so no long-lived credentials are stored;
+// the controller-internal kubeconfig Secret is not created for such Clusters.
+func (r *Reconciler) removeClientCertificates(ctx context.Context, cluster *clusterv1.Cluster, configSecrets []*corev1.Secret) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	for _, configSecret := range configSecrets {
+		hasClientCert, err := kubeconfig.HasClientCertificate(configSecret)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to read Secret %s", klog.KObj(configSecret))
+			conditions.MarkFalse(cluster, clusterv1.KubeconfigCredentialsRotatedCondition, clusterv1.KubeconfigCredentialsRotationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return err
+		}
+		if !hasClientCert {
+			continue
+		}
+
+		data, err := kubeconfig.Regenerate(ctx, r.Client, configSecret, kubeconfig.WithoutClientCertificate())
+		if err != nil {
+			err = errors.Wrapf(err, "failed to regenerate Secret %s", klog.KObj(configSecret))
+			conditions.MarkFalse(cluster, clusterv1.KubeconfigCredentialsRotatedCondition, clusterv1.KubeconfigCredentialsRotationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return err
+		}
+		original := configSecret.DeepCopy()
+		configSecret.Data[secret.KubeconfigDataName] = data
+		if err := r.Client.Patch(ctx, configSecret, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+			err = errors.Wrapf(err, "failed to patch Secret %s", klog.KObj(configSecret))
+			conditions.MarkFalse(cluster, clusterv1.KubeconfigCredentialsRotatedCondition, clusterv1.KubeconfigCredentialsRotationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return err
+		}
+		log.Info("Removed kubeconfig client certificate", "Secret", klog.KObj(configSecret))
+	}
+
+	conditions.MarkTrue(cluster, clusterv1.KubeconfigCredentialsRotatedCondition)
+	return nil
+}
+
+// generatedKubeconfigSecrets returns the kubeconfig Secrets of the Cluster which are generated by Cluster API.
+func (r *Reconciler) generatedKubeconfigSecrets(ctx context.Context, cluster *clusterv1.Cluster) ([]*corev1.Secret, error) {
+	c := r.SecretCachingClient
+	if c == nil {
+		c = r.Client
+	}
+
+	var configSecrets []*corev1.Secret
+	for _, purpose := range kubeconfigPurposes {
+		configSecret, err := secret.GetFromNamespacedName(ctx, c, client.ObjectKeyFromObject(cluster), purpose)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to get %s Secret", purpose)
+		}
+		if !isGeneratedKubeconfigSecret(configSecret, cluster) {
+			continue
+		}
+		configSecrets = append(configSecrets, configSecret)
+	}
+	return configSecrets, nil
+}
+
+// ensureInternalKubeconfigSecret creates the controller-internal kubeconfig Secret, with a new client certificate,
+// if it does not exist and the Cluster API generated the kubeconfig Secret handed out to users; the created Secret
+// is returned, or nil if no Secret has been created.
+func (r *Reconciler) ensureInternalKubeconfigSecret(ctx context.Context, cluster *clusterv1.Cluster, configSecrets []*corev1.Secret) (*corev1.Secret, error) {
+	var userSecret *corev1.Secret
+	for _, configSecret := range configSecrets {
+		_, purpose, err := secret.ParseSecretName(configSecret.Name)
+		if err != nil {
+			continue
+		}
+		switch purpose {
+		case secret.InternalKubeconfig:
+			return nil, nil
+		case secret.Kubeconfig:
+			userSecret = configSecret
+		}
+	}
+	if userSecret == nil {
+		return nil, nil
+	}
+
+	// Note: the internal kubeconfig Secret could exist without being generated by Cluster API, e.g. if provided by users.
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: secret.Name(cluster.Name, secret.InternalKubeconfig)}, &corev1.Secret{}); err == nil {
+		return nil, nil
+	} else if !apierrors.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "failed to get %s Secret", secret.InternalKubeconfig)
+	}
+
+	data, err := kubeconfig.Regenerate(ctx, r.Client, userSecret)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to generate %s Secret", secret.InternalKubeconfig)
+		conditions.MarkFalse(cluster, clusterv1.KubeconfigCredentialsRotatedCondition, clusterv1.KubeconfigCredentialsRotationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return nil, err
+	}
+	if err := r.verifyKubeconfig(ctx, data); err != nil {
+		err = errors.Wrapf(err, "failed to verify the credentials for %s Secret", secret.InternalKubeconfig)
+		conditions.MarkFalse(cluster, clusterv1.KubeconfigCredentialsRotatedCondition, clusterv1.KubeconfigCredentialsVerificationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return nil, err
+	}
+
+	internalSecret := kubeconfig.GenerateInternalSecretWithOwner(client.ObjectKeyFromObject(cluster), data, metav1.OwnerReference{
+		APIVersion: clusterv1.GroupVersion.String(),
+		Kind:       "Cluster",
+		Name:       cluster.Name,
+		UID:        cluster.UID,
+	})
+	if err := r.Client.Create(ctx, internalSecret); err != nil {
+		err = errors.Wrapf(err, "failed to create Secret %s", klog.KObj(internalSecret))
+		conditions.MarkFalse(cluster, clusterv1.KubeconfigCredentialsRotatedCondition, clusterv1.KubeconfigCredentialsRotationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return nil, err
+	}
+	ctrl.LoggerFrom(ctx).Info("Created controller-internal kubeconfig", "Secret", klog.KObj(internalSecret))
+	return internalSecret, nil
+}
+
+// resetAccessor resets the connection to the workload cluster, so the new credentials are used.
+func (r *Reconciler) resetAccessor(ctx context.Context, cluster *clusterv1.Cluster) {
+	if r.Tracker == nil {
+		return
+	}
+	r.Tracker.ResetAccessor(ctx, client.ObjectKeyFromObject(cluster))
+}
+
+// defaultVerifyKubeconfig checks that a kubeconfig can be used to access the workload cluster.
+func (r *Reconciler) defaultVerifyKubeconfig(ctx context.Context, data []byte) error {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(data)
+	if err != nil {
+		return errors.Wrap(err, "failed to create REST configuration")
+	}
+	restConfig.Timeout = verifyTimeout
+	restConfig.UserAgent = remote.DefaultClusterAPIUserAgent("cluster-api-kubeconfig-rotation")
+
+	c, err := client.New(restConfig, client.Options{Scheme: r.Client.Scheme()})
+	if err != nil {
+		return errors.Wrap(err, "failed to create client")
+	}
+	// Note: unlike discovery, reading a Namespace requires the request to be authenticated and authorized.
+	return c.Get(ctx, client.ObjectKey{Name: metav1.NamespaceSystem}, &corev1.Namespace{})
+}
+
+// kubeconfigSecretToCluster maps a kubeconfig Secret to the Cluster it belongs to.
+func kubeconfigSecretToCluster(_ context.Context, o client.Object) []reconcile.Request {
+	clusterName, ok := o.GetLabels()[clusterv1.ClusterNameLabel]
+	if !ok {
+		return nil
+	}
+	name, purpose, err := secret.ParseSecretName(o.GetName())
+	if err != nil || name != clusterName || (purpose != secret.Kubeconfig && purpose != secret.InternalKubeconfig) {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: o.GetNamespace(), Name: clusterName}}}
+}
+
+// isGeneratedKubeconfigSecret returns true if the kubeconfig Secret has been generated by Cluster API for the Cluster,
+// i.e. it has the cluster name label and the Cluster API Secret type, and it is not controlled by an object other
+// than the Cluster, e.g. by a control plane provider.
+func isGeneratedKubeconfigSecret(configSecret *corev1.Secret, cluster *clusterv1.Cluster) bool {
+	if configSecret.Labels[clusterv1.ClusterNameLabel] != cluster.Name || configSecret.Type != clusterv1.ClusterSecretType {
+		return false
+	}
+	ref := metav1.GetControllerOf(configSecret)
+	if ref == nil {
+		return true
+	}
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return false
+	}
+	return gv.Group == clusterv1.GroupVersion.Group && ref.Kind == "Cluster" && ref.Name == cluster.Name
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || b.Before(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,284 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfigrotation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = clusterv1.AddToScheme(scheme)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault, UID: "uid"},
+	}
+	clusterKey := client.ObjectKeyFromObject(cluster)
+
+	ca := &secret.Certificate{Purpose: secret.ClusterCA}
+	if err := ca.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	caSecret := ca.AsSecret(clusterKey, metav1.OwnerReference{})
+
+	// kubeconfigSecret returns a kubeconfig Secret generated by Cluster API with a client certificate expiring after the given TTL.
+	kubeconfigSecret := func(g *WithT, purpose secret.Purpose, ttl time.Duration) *corev1.Secret {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(caSecret).Build()
+		g.Expect(kubeconfig.CreateSecretWithOwner(ctx, c, clusterKey, "https://test.example.com:6443", metav1.OwnerReference{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Cluster",
+			Name:       cluster.Name,
+			UID:        cluster.UID,
+		}, kubeconfig.WithClientCertificateTTL(ttl))).To(Succeed())
+		s, err := secret.GetFromNamespacedName(ctx, c, clusterKey, secret.Kubeconfig)
+		g.Expect(err).ToNot(HaveOccurred())
+		s.Name = secret.Name(cluster.Name, purpose)
+		s.ResourceVersion = ""
+		return s
+	}
+	clientCertNotAfter := func(g *WithT, c client.Client, purpose secret.Purpose) time.Time {
+		s, err := secret.GetFromNamespacedName(ctx, c, clusterKey, purpose)
+		g.Expect(err).ToNot(HaveOccurred())
+		config, err := clientcmd.Load(s.Data[secret.KubeconfigDataName])
+		g.Expect(err).ToNot(HaveOccurred())
+		cert, err := certs.DecodeCertPEM(config.AuthInfos["test-admin"].ClientCertificateData)
+		g.Expect(err).ToNot(HaveOccurred())
+		return cert.NotAfter
+	}
+
+	t.Run("rotates the kubeconfig Secrets owned by the Cluster", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&clusterv1.Cluster{}).WithObjects(
+			cluster.DeepCopy(), caSecret,
+			kubeconfigSecret(g, secret.Kubeconfig, time.Hour),
+			kubeconfigSecret(g, secret.InternalKubeconfig, time.Hour),
+		).Build()
+		verified := 0
+		r := &Reconciler{
+			Client: c,
+			verifyKubeconfig: func(context.Context, []byte) error {
+				verified++
+				return nil
+			},
+		}
+
+		res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: clusterKey})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(verified).To(Equal(2))
+		g.Expect(clientCertNotAfter(g, c, secret.Kubeconfig)).To(BeTemporally(">", time.Now().Add(certs.ClientCertificateRenewalDuration)))
+		g.Expect(clientCertNotAfter(g, c, secret.InternalKubeconfig)).To(BeTemporally(">", time.Now().Add(certs.ClientCertificateRenewalDuration)))
+		g.Expect(res.RequeueAfter).To(BeNumerically("~", certs.DefaultCertDuration-certs.ClientCertificateRenewalDuration, time.Hour))
+
+		updated := &clusterv1.Cluster{}
+		g.Expect(c.Get(ctx, clusterKey, updated)).To(Succeed())
+		g.Expect(conditions.IsTrue(updated, clusterv1.KubeconfigCredentialsRotatedCondition)).To(BeTrue())
+	})
+
+	t.Run("rotates the kubeconfig Secrets with the cluster name label and the Cluster API Secret type", func(t *testing.T) {
+		g := NewWithT(t)
+
+		configSecret := kubeconfigSecret(g, secret.Kubeconfig, time.Hour)
+		configSecret.OwnerReferences = nil
+		internalSecret := kubeconfigSecret(g, secret.InternalKubeconfig, time.Hour)
+		internalSecret.OwnerReferences = nil
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&clusterv1.Cluster{}).WithObjects(
+			cluster.DeepCopy(), caSecret, configSecret, internalSecret,
+		).Build()
+		r := &Reconciler{
+			Client:           c,
+			verifyKubeconfig: func(context.Context, []byte) error { return nil },
+		}
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: clusterKey})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(clientCertNotAfter(g, c, secret.Kubeconfig)).To(BeTemporally(">", time.Now().Add(certs.ClientCertificateRenewalDuration)))
+		g.Expect(clientCertNotAfter(g, c, secret.InternalKubeconfig)).To(BeTemporally(">", time.Now().Add(certs.ClientCertificateRenewalDuration)))
+	})
+
+	t.Run("creates the controller-internal kubeconfig Secret", func(t *testing.T) {
+		g := NewWithT(t)
+
+		configSecret := kubeconfigSecret(g, secret.Kubeconfig, 10*time.Hour)
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&clusterv1.Cluster{}).WithObjects(
+			cluster.DeepCopy(), caSecret, configSecret,
+		).Build()
+		verified := 0
+		r := &Reconciler{
+			Client:           c,
+			RenewalThreshold: time.Hour,
+			verifyKubeconfig: func(context.Context, []byte) error {
+				verified++
+				return nil
+			},
+		}
+
+		res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: clusterKey})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(verified).To(Equal(1))
+		g.Expect(res.RequeueAfter).To(BeNumerically("~", 9*time.Hour, time.Minute))
+
+		internalSecret, err := secret.GetFromNamespacedName(ctx, c, clusterKey, secret.InternalKubeconfig)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(internalSecret.Type).To(Equal(clusterv1.ClusterSecretType))
+		g.Expect(internalSecret.Labels).To(HaveKeyWithValue(clusterv1.ClusterNameLabel, cluster.Name))
+		g.Expect(internalSecret.OwnerReferences).To(HaveLen(1))
+		g.Expect(internalSecret.OwnerReferences[0].Kind).To(Equal("Cluster"))
+		g.Expect(internalSecret.Data[secret.KubeconfigDataName]).ToNot(Equal(configSecret.Data[secret.KubeconfigDataName]))
+		g.Expect(clientCertNotAfter(g, c, secret.InternalKubeconfig)).To(BeTemporally(">", time.Now().Add(certs.ClientCertificateRenewalDuration)))
+	})
+
+	t.Run("does not rotate credentials before the renewal threshold", func(t *testing.T) {
+		g := NewWithT(t)
+
+		configSecret := kubeconfigSecret(g, secret.Kubeconfig, 10*time.Hour)
+		internalSecret := kubeconfigSecret(g, secret.InternalKubeconfig, 10*time.Hour)
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&clusterv1.Cluster{}).WithObjects(
+			cluster.DeepCopy(), caSecret, configSecret, internalSecret,
+		).Build()
+		r := &Reconciler{
+			Client:           c,
+			RenewalThreshold: time.Hour,
+			verifyKubeconfig: func(context.Context, []byte) error { return errors.New("must not be called") },
+		}
+
+		res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: clusterKey})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(res.RequeueAfter).To(BeNumerically("~", 9*time.Hour, time.Minute))
+		g.Expect(clientCertNotAfter(g, c, secret.Kubeconfig)).To(BeTemporally("<", time.Now().Add(10*time.Hour)))
+	})
+
+	t.Run("does not store credentials failing verification", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&clusterv1.Cluster{}).WithObjects(
+			cluster.DeepCopy(), caSecret,
+			kubeconfigSecret(g, secret.Kubeconfig, time.Hour),
+		).Build()
+		r := &Reconciler{
+			Client:           c,
+			verifyKubeconfig: func(context.Context, []byte) error { return errors.New("unauthorized") },
+		}
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: clusterKey})
+		g.Expect(err).To(MatchError(ContainSubstring("unauthorized")))
+		g.Expect(clientCertNotAfter(g, c, secret.Kubeconfig)).To(BeTemporally("<", time.Now().Add(time.Hour)))
+		_, err = secret.GetFromNamespacedName(ctx, c, clusterKey, secret.InternalKubeconfig)
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		updated := &clusterv1.Cluster{}
+		g.Expect(c.Get(ctx, clusterKey, updated)).To(Succeed())
+		g.Expect(conditions.IsFalse(updated, clusterv1.KubeconfigCredentialsRotatedCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(updated, clusterv1.KubeconfigCredentialsRotatedCondition)).To(Equal(clusterv1.KubeconfigCredentialsVerificationFailedReason))
+	})
+
+	t.Run("removes the client certificates of Clusters using the workload cluster auth annotation", func(t *testing.T) {
+		g := NewWithT(t)
+
+		authCluster := cluster.DeepCopy()
+		authCluster.Annotations = map[string]string{clusterv1.WorkloadClusterAuthAnnotation: "exec"}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&clusterv1.Cluster{}).WithObjects(
+			authCluster, caSecret,
+			kubeconfigSecret(g, secret.Kubeconfig, 10*time.Hour),
+		).Build()
+		r := &Reconciler{
+			Client:           c,
+			verifyKubeconfig: func(context.Context, []byte) error { return errors.New("must not be called") },
+		}
+
+		res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: clusterKey})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(res.IsZero()).To(BeTrue())
+
+		configSecret, err := secret.GetFromNamespacedName(ctx, c, clusterKey, secret.Kubeconfig)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(kubeconfig.HasClientCertificate(configSecret)).To(BeFalse())
+		_, err = secret.GetFromNamespacedName(ctx, c, clusterKey, secret.InternalKubeconfig)
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		updated := &clusterv1.Cluster{}
+		g.Expect(c.Get(ctx, clusterKey, updated)).To(Succeed())
+		g.Expect(conditions.IsTrue(updated, clusterv1.KubeconfigCredentialsRotatedCondition)).To(BeTrue())
+	})
+
+	t.Run("ignores kubeconfig Secrets not generated by Cluster API", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlledSecret := kubeconfigSecret(g, secret.Kubeconfig, time.Hour)
+		controlledSecret.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "controlplane.cluster.x-k8s.io/v1beta1",
+			Kind:       "KubeadmControlPlane",
+			Name:       "test",
+			Controller: ptr.To(true),
+		}}
+		untypedSecret := kubeconfigSecret(g, secret.InternalKubeconfig, time.Hour)
+		untypedSecret.Type = corev1.SecretTypeOpaque
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&clusterv1.Cluster{}).WithObjects(
+			cluster.DeepCopy(), caSecret, controlledSecret, untypedSecret,
+		).Build()
+		r := &Reconciler{
+			Client:           c,
+			verifyKubeconfig: func(context.Context, []byte) error { return errors.New("must not be called") },
+		}
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: clusterKey})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		updated := &clusterv1.Cluster{}
+		g.Expect(c.Get(ctx, clusterKey, updated)).To(Succeed())
+		g.Expect(conditions.Has(updated, clusterv1.KubeconfigCredentialsRotatedCondition)).To(BeFalse())
+	})
+}
+
+func TestKubeconfigSecretToCluster(t *testing.T) {
+	g := NewWithT(t)
+
+	secretFor := func(name string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceDefault,
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: "test"},
+		}}
+	}
+
+	g.Expect(kubeconfigSecretToCluster(context.Background(), secretFor("test-kubeconfig"))).To(HaveLen(1))
+	g.Expect(kubeconfigSecretToCluster(context.Background(), secretFor("test-kubeconfig-internal"))).To(HaveLen(1))
+	g.Expect(kubeconfigSecretToCluster(context.Background(), secretFor("test-ca"))).To(BeEmpty())
+}
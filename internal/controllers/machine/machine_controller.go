@@ -47,14 +47,24 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
 	"sigs.k8s.io/cluster-api/controllers/remote"
+	runtimeclient "sigs.k8s.io/cluster-api/internal/runtime/client"
+	"sigs.k8s.io/cluster-api/internal/util/metrics"
 	"sigs.k8s.io/cluster-api/internal/util/ssa"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/collections"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/finalizers"
+	capilabels "sigs.k8s.io/cluster-api/util/labels"
 	clog "sigs.k8s.io/cluster-api/util/log"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/paused"
 	"sigs.k8s.io/cluster-api/util/predicates"
+	"sigs.k8s.io/cluster-api/util/priority"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/requeue"
+	"sigs.k8s.io/cluster-api/util/throttle"
+	"sigs.k8s.io/cluster-api/util/tracing"
 )
 
 var (
@@ -79,6 +89,10 @@ type Reconciler struct {
 	APIReader                 client.Reader
 	Tracker                   *remote.ClusterCacheTracker
 
+	// RuntimeClient is used to call the VerifyNodeIdentity Runtime Extensions; it is required only
+	// if the RuntimeSDK feature gate is enabled.
+	RuntimeClient runtimeclient.Client
+
 	// WatchFilterValue is the label value used to filter events prior to reconciliation.
 	WatchFilterValue string
 
@@ -113,10 +127,9 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opt
 		r.nodeDeletionRetryTimeout = 10 * time.Second
 	}
 
-	c, err := ctrl.NewControllerManagedBy(mgr).
-		For(&clusterv1.Machine{}).
+	c, err := priority.For(ctrl.NewControllerManagedBy(mgr), &clusterv1.Machine{}).
 		WithOptions(options).
-		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
+		WithEventFilter(predicates.ResourceHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
 		Watches(
 			&clusterv1.Cluster{},
 			handler.EnqueueRequestsFromMapFunc(clusterToMachines),
@@ -124,8 +137,9 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opt
 				// TODO: should this wait for Cluster.Status.InfrastructureReady similar to Infra Machine resources?
 				predicates.All(ctrl.LoggerFrom(ctx),
 					predicates.Any(ctrl.LoggerFrom(ctx),
-						predicates.ClusterUnpaused(ctrl.LoggerFrom(ctx)),
+						predicates.ClusterPausedTransitions(ctrl.LoggerFrom(ctx)),
 						predicates.ClusterControlPlaneInitialized(ctrl.LoggerFrom(ctx)),
+						predicates.ClusterMetadataPropagationChanged(ctrl.LoggerFrom(ctx)),
 					),
 					predicates.ResourceHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue),
 				),
@@ -138,7 +152,8 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opt
 			&clusterv1.MachineDeployment{},
 			handler.EnqueueRequestsFromMapFunc(mdToMachines),
 		).
-		Build(r)
+		Build(requeue.Reconciler(throttle.Reconciler("machine", r.Client, &clusterv1.Machine{},
+			metrics.InstrumentReconciler("machine", r.Client, &clusterv1.Machine{}, r))))
 	if err != nil {
 		return errors.Wrap(err, "failed setting up with a controller manager")
 	}
@@ -154,6 +169,9 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opt
 }
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx, span := tracing.StartReconcile(ctx, "Machine", req)
+	defer func() { tracing.End(span, reterr) }()
+
 	// Fetch the Machine instance
 	m := &clusterv1.Machine{}
 	if err := r.Client.Get(ctx, req.NamespacedName, m); err != nil {
@@ -183,10 +201,19 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Re
 			m.Spec.ClusterName, m.Name, m.Namespace)
 	}
 
-	// Return early if the object or Cluster is paused.
-	if annotations.IsPaused(cluster, m) {
-		log.Info("Reconciliation is paused for this object")
-		return ctrl.Result{}, nil
+	ctx, log = clog.AddCorrelationID(ctx, cluster)
+
+	// Set the Paused condition, and return early if the object or Cluster is paused.
+	if isPaused, err := paused.EnsurePausedCondition(ctx, r.Client, cluster, m); err != nil || isPaused {
+		if isPaused {
+			log.Info("Reconciliation is paused for this object")
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Add finalizer first if not set to avoid the race condition between init and delete.
+	if finalizerAdded, err := finalizers.EnsureFinalizer(ctx, r.Client, m, clusterv1.MachineFinalizer); err != nil || finalizerAdded {
+		return ctrl.Result{}, err
 	}
 
 	// Initialize the patch helper
@@ -194,6 +221,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Re
 	if err != nil {
 		return ctrl.Result{}, err
 	}
+	original := m.DeepCopy()
 
 	defer func() {
 		r.reconcilePhase(ctx, m)
@@ -206,7 +234,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Re
 		}
 		if err := patchMachine(ctx, patchHelper, m, patchOpts...); err != nil {
 			reterr = kerrors.NewAggregate([]error{reterr, err})
+			return
 		}
+		metrics.RecordMachineLifecycleTransitions(original, m)
 	}()
 
 	// Reconcile labels.
@@ -215,6 +245,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Re
 	}
 	m.Labels[clusterv1.ClusterNameLabel] = m.Spec.ClusterName
 
+	// Propagate the labels and annotations defined in the Cluster's spec.metadataPropagation.
+	capilabels.PropagateClusterMetadata(cluster, m)
+
 	// Handle deletion reconciliation loop.
 	if !m.ObjectMeta.DeletionTimestamp.IsZero() {
 		res, err := r.reconcileDelete(ctx, cluster, m)
@@ -227,13 +260,6 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Re
 		return res, err
 	}
 
-	// Add finalizer first if not set to avoid the race condition between init and delete.
-	// Note: Finalizers in general can only be added when the deletionTimestamp is not set.
-	if !controllerutil.ContainsFinalizer(m, clusterv1.MachineFinalizer) {
-		controllerutil.AddFinalizer(m, clusterv1.MachineFinalizer)
-		return ctrl.Result{}, nil
-	}
-
 	// Handle normal reconciliation loop.
 	res, err := r.reconcile(ctx, cluster, m)
 	// Requeue if the reconcile failed because the ClusterCacheTracker was locked for
@@ -311,7 +337,9 @@ func (r *Reconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster,
 	}
 	for _, phase := range phases {
 		// Call the inner reconciliation methods.
-		phaseResult, err := phase(ctx, s)
+		phaseCtx, span := tracing.StartPhase(ctx, phase)
+		phaseResult, err := phase(phaseCtx, s)
+		tracing.End(span, err)
 		if err != nil {
 			errs = append(errs, err)
 		}
@@ -391,13 +419,13 @@ func (r *Reconciler) reconcileDelete(ctx context.Context, cluster *clusterv1.Clu
 			if result, err := r.drainNode(ctx, cluster, m.Status.NodeRef.Name); !result.IsZero() || err != nil {
 				if err != nil {
 					conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
-					r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDrainNode", "error draining Machine's node %q: %v", m.Status.NodeRef.Name, err)
+					capirecord.Emit(r.recorder, m, capirecord.FailedDrainNodeReason, m.Status.NodeRef.Name, err)
 				}
 				return result, err
 			}
 
 			conditions.MarkTrue(m, clusterv1.DrainingSucceededCondition)
-			r.recorder.Eventf(m, corev1.EventTypeNormal, "SuccessfulDrainNode", "success draining Machine's node %q", m.Status.NodeRef.Name)
+			capirecord.Emit(r.recorder, m, capirecord.SuccessfulDrainNodeReason, m.Status.NodeRef.Name)
 		}
 
 		// After node draining is completed, and if isNodeVolumeDetachingAllowed returns True, make sure all
@@ -412,14 +440,14 @@ func (r *Reconciler) reconcileDelete(ctx context.Context, cluster *clusterv1.Clu
 
 			if ok, err := r.shouldWaitForNodeVolumes(ctx, cluster, m.Status.NodeRef.Name); ok || err != nil {
 				if err != nil {
-					r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedWaitForVolumeDetach", "error waiting for node volumes detaching, Machine's node %q: %v", m.Status.NodeRef.Name, err)
+					capirecord.Emit(r.recorder, m, capirecord.FailedWaitForVolumeDetachReason, m.Status.NodeRef.Name, err)
 					return ctrl.Result{}, err
 				}
 				log.Info("Waiting for node volumes to be detached", "Node", klog.KRef("", m.Status.NodeRef.Name))
 				return ctrl.Result{}, nil
 			}
 			conditions.MarkTrue(m, clusterv1.VolumeDetachSucceededCondition)
-			r.recorder.Eventf(m, corev1.EventTypeNormal, "NodeVolumesDetached", "success waiting for node volumes detaching Machine's node %q", m.Status.NodeRef.Name)
+			capirecord.Emit(r.recorder, m, capirecord.NodeVolumesDetachedReason, m.Status.NodeRef.Name)
 		}
 	}
 
@@ -477,7 +505,7 @@ func (r *Reconciler) reconcileDelete(ctx context.Context, cluster *clusterv1.Clu
 		if waitErr != nil {
 			log.Error(deleteNodeErr, "Timed out deleting node", "Node", klog.KRef("", m.Status.NodeRef.Name))
 			conditions.MarkFalse(m, clusterv1.MachineNodeHealthyCondition, clusterv1.DeletionFailedReason, clusterv1.ConditionSeverityWarning, "")
-			r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDeleteNode", "error deleting Machine's node: %v", deleteNodeErr)
+			capirecord.Emit(r.recorder, m, capirecord.FailedDeleteNodeReason, deleteNodeErr)
 
 			// If the node deletion timeout is not expired yet, requeue the Machine for reconciliation.
 			if m.Spec.NodeDeletionTimeout == nil || m.Spec.NodeDeletionTimeout.Nanoseconds() == 0 || m.DeletionTimestamp.Add(m.Spec.NodeDeletionTimeout.Duration).After(time.Now()) {
@@ -678,13 +706,14 @@ func (r *Reconciler) drainNode(ctx context.Context, cluster *clusterv1.Cluster,
 	if err := kubedrain.RunCordonOrUncordon(drainer, node, true); err != nil {
 		// Machine will be re-reconciled after a cordon failure.
 		log.Error(err, "Cordon failed")
-		return ctrl.Result{}, errors.Wrapf(err, "unable to cordon node %v", node.Name)
+		return ctrl.Result{}, requeue.Transient(errors.Wrapf(err, "unable to cordon node %v", node.Name))
 	}
 
 	if err := kubedrain.RunNodeDrain(drainer, node.Name); err != nil {
 		// Machine will be re-reconciled after a drain failure.
-		log.Error(err, "Drain failed, retry in 20s")
-		return ctrl.Result{RequeueAfter: 20 * time.Second}, nil
+		requeueAfter := requeue.After(requeue.TransientErrorClass)
+		log.Error(err, fmt.Sprintf("Drain failed, retry in %s", requeueAfter))
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
 	log.Info("Drain successful")
@@ -859,62 +888,49 @@ func (r *Reconciler) watchClusterNodes(ctx context.Context, cluster *clusterv1.C
 		Cluster:      util.ObjectKey(cluster),
 		Watcher:      r.controller,
 		Kind:         &corev1.Node{},
-		EventHandler: handler.EnqueueRequestsFromMapFunc(r.nodeToMachine),
+		EventHandler: handler.EnqueueRequestsFromMapFunc(r.nodeToMachine(util.ObjectKey(cluster))),
 	})
 }
 
-func (r *Reconciler) nodeToMachine(ctx context.Context, o client.Object) []reconcile.Request {
-	node, ok := o.(*corev1.Node)
-	if !ok {
-		panic(fmt.Sprintf("Expected a Node but got a %T", o))
-	}
-
-	var filters []client.ListOption
-	// Match by clusterName when the node has the annotation.
-	if clusterName, ok := node.GetAnnotations()[clusterv1.ClusterNameAnnotation]; ok {
-		filters = append(filters, client.MatchingLabels{
-			clusterv1.ClusterNameLabel: clusterName,
-		})
-	}
+// nodeToMachine returns a MapFunc mapping Nodes of the given Cluster to their Machine.
+// Machines are looked up using the MachineClusterNodeField index, first by Node name and then by ProviderID,
+// so every event is resolved with at most two index lookups, independently of the number of Machines.
+func (r *Reconciler) nodeToMachine(cluster client.ObjectKey) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		node, ok := o.(*corev1.Node)
+		if !ok {
+			panic(fmt.Sprintf("Expected a Node but got a %T", o))
+		}
 
-	// Match by namespace when the node has the annotation.
-	if namespace, ok := node.GetAnnotations()[clusterv1.ClusterNamespaceAnnotation]; ok {
-		filters = append(filters, client.InNamespace(namespace))
-	}
+		// Match by nodeName and status.nodeRef.name.
+		if req, ok := r.machineForClusterNode(ctx, index.ClusterNodeNameIndexKey(cluster, node.Name)); ok {
+			return []reconcile.Request{req}
+		}
 
-	// Match by nodeName and status.nodeRef.name.
-	machineList := &clusterv1.MachineList{}
-	if err := r.Client.List(
-		ctx,
-		machineList,
-		append(filters, client.MatchingFields{index.MachineNodeNameField: node.Name})...); err != nil {
+		// Otherwise let's match by providerID. This is useful when e.g the NodeRef has not been set yet.
+		if node.Spec.ProviderID == "" {
+			return nil
+		}
+		if req, ok := r.machineForClusterNode(ctx, index.ClusterNodeProviderIDIndexKey(cluster, node.Spec.ProviderID)); ok {
+			return []reconcile.Request{req}
+		}
 		return nil
 	}
+}
 
-	// There should be exactly 1 Machine for the node.
-	if len(machineList.Items) == 1 {
-		return []reconcile.Request{{NamespacedName: util.ObjectKey(&machineList.Items[0])}}
-	}
-
-	// Otherwise let's match by providerID. This is useful when e.g the NodeRef has not been set yet.
-	// Match by providerID
-	if node.Spec.ProviderID == "" {
-		return nil
-	}
-	machineList = &clusterv1.MachineList{}
-	if err := r.Client.List(
-		ctx,
-		machineList,
-		append(filters, client.MatchingFields{index.MachineProviderIDField: node.Spec.ProviderID})...); err != nil {
-		return nil
+// machineForClusterNode returns the request for the Machine with the given MachineClusterNodeField index key;
+// it returns false if there is not exactly one such Machine.
+func (r *Reconciler) machineForClusterNode(ctx context.Context, key string) (reconcile.Request, bool) {
+	machineList := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machineList, client.MatchingFields{index.MachineClusterNodeField: key}); err != nil {
+		return reconcile.Request{}, false
 	}
 
 	// There should be exactly 1 Machine for the node.
-	if len(machineList.Items) == 1 {
-		return []reconcile.Request{{NamespacedName: util.ObjectKey(&machineList.Items[0])}}
+	if len(machineList.Items) != 1 {
+		return reconcile.Request{}, false
 	}
-
-	return nil
+	return reconcile.Request{NamespacedName: util.ObjectKey(&machineList.Items[0])}, true
 }
 
 // writer implements io.Writer interface as a pass-through for klog.
@@ -20,9 +20,11 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -32,11 +34,16 @@ import (
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/api/v1beta1/index"
+	runtimecatalog "sigs.k8s.io/cluster-api/exp/runtime/catalog"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/internal/controllers/machinedeployment/mdutil"
 	"sigs.k8s.io/cluster-api/internal/util/taints"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/labels"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
 )
 
 var (
@@ -69,6 +76,15 @@ func (r *Reconciler) reconcileNode(ctx context.Context, s *scope) (ctrl.Result,
 
 	// Even if Status.NodeRef exists, continue to do the following checks to make sure Node is healthy
 	node, err := r.getNode(ctx, remoteClient, *machine.Spec.ProviderID)
+	if err == ErrNodeNotFound && machine.Status.NodeRef == nil && machine.Annotations[clusterv1.AdoptNodeAnnotation] != "" {
+		// If the Machine is adopting an existing Node, look it up by name, given it might not have a provider ID yet.
+		node, err = r.adoptNode(ctx, remoteClient, machine, machine.Annotations[clusterv1.AdoptNodeAnnotation])
+		if err != nil && err != ErrNodeNotFound {
+			capirecord.Emit(r.recorder, machine, capirecord.FailedAdoptReason, "Node", machine.Annotations[clusterv1.AdoptNodeAnnotation], err)
+			conditions.MarkFalse(machine, clusterv1.MachineNodeHealthyCondition, clusterv1.NodeAdoptionFailedReason, clusterv1.ConditionSeverityError, err.Error())
+			return ctrl.Result{}, err
+		}
+	}
 	if err != nil {
 		if err == ErrNodeNotFound {
 			// While a NodeRef is set in the status, failing to get that node means the node is deleted.
@@ -81,13 +97,22 @@ func (r *Reconciler) reconcileNode(ctx context.Context, s *scope) (ctrl.Result,
 			// No need to requeue here. Nodes emit an event that triggers reconciliation.
 			return ctrl.Result{}, nil
 		}
-		r.recorder.Event(machine, corev1.EventTypeWarning, "Failed to retrieve Node by ProviderID", err.Error())
+		capirecord.Emit(r.recorder, machine, capirecord.FailedSetNodeRefReason, err)
 		conditions.MarkUnknown(machine, clusterv1.MachineNodeHealthyCondition, clusterv1.NodeInspectionFailedReason, "Failed to get the Node for this Machine by ProviderID")
 		return ctrl.Result{}, err
 	}
 
 	// Set the Machine NodeRef.
 	if machine.Status.NodeRef == nil {
+		// Verify the identity of the Node before associating it to the Machine, so a rogue Node claiming
+		// the Machine's provider ID, e.g. using a leaked bootstrap token, doesn't get the Machine's labels and taints removed.
+		if feature.Gates.Enabled(feature.RuntimeSDK) {
+			res, err := r.verifyNodeIdentity(ctx, cluster, machine, node)
+			if err != nil || !res.IsZero() {
+				return res, err
+			}
+		}
+
 		machine.Status.NodeRef = &corev1.ObjectReference{
 			APIVersion: corev1.SchemeGroupVersion.String(),
 			Kind:       "Node",
@@ -95,7 +120,7 @@ func (r *Reconciler) reconcileNode(ctx context.Context, s *scope) (ctrl.Result,
 			UID:        node.UID,
 		}
 		log.Info("Infrastructure provider reporting spec.providerID, Kubernetes node is now available", machine.Spec.InfrastructureRef.Kind, klog.KRef(machine.Spec.InfrastructureRef.Namespace, machine.Spec.InfrastructureRef.Name), "providerID", *machine.Spec.ProviderID, "node", klog.KRef("", machine.Status.NodeRef.Name))
-		r.recorder.Event(machine, corev1.EventTypeNormal, "SuccessfulSetNodeRef", machine.Status.NodeRef.Name)
+		capirecord.Emit(r.recorder, machine, capirecord.SuccessfulSetNodeRefReason, machine.Status.NodeRef.Name)
 	}
 
 	// Set the NodeSystemInfo.
@@ -116,7 +141,7 @@ func (r *Reconciler) reconcileNode(ctx context.Context, s *scope) (ctrl.Result,
 	// Compute labels to be propagated from Machines to nodes.
 	// NOTE: CAPI should manage only a subset of node labels, everything else should be preserved.
 	// NOTE: Once we reconcile node labels for the first time, the NodeUninitializedTaint is removed from the node.
-	nodeLabels := getManagedLabels(machine.Labels)
+	nodeLabels := labels.MachineToNodeLabels.Select(machine.Labels)
 
 	// Get interruptible instance status from the infrastructure provider and set the interruptible label on the node.
 	interruptible := false
@@ -135,14 +160,14 @@ func (r *Reconciler) reconcileNode(ctx context.Context, s *scope) (ctrl.Result,
 	_, nodeHadInterruptibleLabel := node.Labels[clusterv1.InterruptibleLabel]
 
 	// Reconcile node taints
-	if err := r.patchNode(ctx, remoteClient, node, nodeLabels, nodeAnnotations, machine); err != nil {
+	if err := r.patchNode(ctx, remoteClient, cluster, node, nodeLabels, nodeAnnotations, machine); err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "failed to reconcile Node %s", klog.KObj(node))
 	}
 	if !nodeHadInterruptibleLabel && interruptible {
 		// If the interruptible label is added to the node then record the event.
 		// Nb. Only record the event if the node previously did not have the label to avoid recording
 		// the event during every reconcile.
-		r.recorder.Event(machine, corev1.EventTypeNormal, "SuccessfulSetInterruptibleNodeLabel", node.Name)
+		capirecord.Emit(r.recorder, machine, capirecord.SuccessfulSetInterruptibleNodeLabelReason, node.Name)
 	}
 
 	// Do the remaining node health checks, then set the node health to true if all checks pass.
@@ -160,24 +185,28 @@ func (r *Reconciler) reconcileNode(ctx context.Context, s *scope) (ctrl.Result,
 	return ctrl.Result{}, nil
 }
 
-// getManagedLabels gets a map[string]string and returns another map[string]string
-// filtering out labels not managed by CAPI.
-func getManagedLabels(labels map[string]string) map[string]string {
-	managedLabels := make(map[string]string)
-	for key, value := range labels {
-		dnsSubdomainOrName := strings.Split(key, "/")[0]
-		if dnsSubdomainOrName == clusterv1.NodeRoleLabelPrefix {
-			managedLabels[key] = value
-		}
-		if dnsSubdomainOrName == clusterv1.NodeRestrictionLabelDomain || strings.HasSuffix(dnsSubdomainOrName, "."+clusterv1.NodeRestrictionLabelDomain) {
-			managedLabels[key] = value
-		}
-		if dnsSubdomainOrName == clusterv1.ManagedNodeLabelDomain || strings.HasSuffix(dnsSubdomainOrName, "."+clusterv1.ManagedNodeLabelDomain) {
-			managedLabels[key] = value
-		}
-	}
+// verifyNodeIdentity calls the VerifyNodeIdentity Runtime Extensions before a Node is associated to a Machine;
+// a non-zero result is returned while the extensions are waiting for the identity evidence of the Node.
+func (r *Reconciler) verifyNodeIdentity(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine, node *corev1.Node) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
 
-	return managedLabels
+	hookRequest := &runtimehooksv1.VerifyNodeIdentityRequest{
+		Cluster: *cluster,
+		Machine: *machine,
+		Node:    *node,
+	}
+	hookResponse := &runtimehooksv1.VerifyNodeIdentityResponse{}
+	if err := r.RuntimeClient.CallAllExtensions(ctx, runtimehooksv1.VerifyNodeIdentity, machine, hookRequest, hookResponse); err != nil {
+		capirecord.Emit(r.recorder, machine, capirecord.FailedVerifyNodeIdentityReason, node.Name, err)
+		conditions.MarkFalse(machine, clusterv1.MachineNodeHealthyCondition, clusterv1.NodeIdentityVerificationFailedReason, clusterv1.ConditionSeverityError, err.Error())
+		return ctrl.Result{}, err
+	}
+	if hookResponse.RetryAfterSeconds != 0 {
+		log.Info(fmt.Sprintf("Association of Node %s is blocked by %q hook", node.Name, runtimecatalog.HookName(runtimehooksv1.VerifyNodeIdentity)))
+		conditions.MarkFalse(machine, clusterv1.MachineNodeHealthyCondition, clusterv1.WaitingForNodeIdentityVerificationReason, clusterv1.ConditionSeverityInfo, hookResponse.Message)
+		return ctrl.Result{RequeueAfter: time.Duration(hookResponse.RetryAfterSeconds) * time.Second}, nil
+	}
+	return ctrl.Result{}, nil
 }
 
 // summarizeNodeConditions summarizes a Node's conditions and returns the summary of condition statuses and concatenate failed condition messages:
@@ -221,6 +250,35 @@ func summarizeNodeConditions(node *corev1.Node) (corev1.ConditionStatus, string)
 	return corev1.ConditionUnknown, message
 }
 
+// adoptNode returns the Node with the given name to be adopted by the Machine, setting its provider ID
+// to the one of the Machine if it is not set yet; the Node is adopted only if it doesn't have a
+// different provider ID.
+func (r *Reconciler) adoptNode(ctx context.Context, c client.Client, machine *clusterv1.Machine, nodeName string) (*corev1.Node, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	node := &corev1.Node{}
+	if err := c.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, ErrNodeNotFound
+		}
+		return nil, errors.Wrapf(err, "failed to get Node %s", nodeName)
+	}
+
+	if node.Spec.ProviderID != "" {
+		// Nodes with the ProviderID of the Machine are found by getNode, so this is a different one.
+		return nil, errors.Errorf("Node %s has providerID %q, which does not match the providerID %q of the Machine", nodeName, node.Spec.ProviderID, *machine.Spec.ProviderID)
+	}
+
+	patch := client.MergeFrom(node.DeepCopy())
+	node.Spec.ProviderID = *machine.Spec.ProviderID
+	if err := c.Patch(ctx, node, patch); err != nil {
+		return nil, errors.Wrapf(err, "failed to set providerID on Node %s", nodeName)
+	}
+	log.Info("Adopted existing Node", "node", klog.KObj(node), "providerID", node.Spec.ProviderID)
+	capirecord.Emit(r.recorder, machine, capirecord.SuccessfulAdoptReason, "Node", node.Name)
+	return node, nil
+}
+
 func (r *Reconciler) getNode(ctx context.Context, c client.Reader, providerID string) (*corev1.Node, error) {
 	nodeList := corev1.NodeList{}
 	if err := c.List(ctx, &nodeList, client.MatchingFields{index.NodeProviderIDField: providerID}); err != nil {
@@ -255,9 +313,12 @@ func (r *Reconciler) getNode(ctx context.Context, c client.Reader, providerID st
 	return &nodeList.Items[0], nil
 }
 
+// nodeLabelsPropagation is used to merge the labels computed for a Node into the existing Node labels.
+var nodeLabelsPropagation = labels.PropagationModel{Authoritative: labels.MatchAll()}
+
 // PatchNode is required to workaround an issue on Node.Status.Address which is incorrectly annotated as patchStrategy=merge
 // and this causes SSA patch to fail in case there are two addresses with the same key https://github.com/kubernetes-sigs/cluster-api/issues/8417
-func (r *Reconciler) patchNode(ctx context.Context, remoteClient client.Client, node *corev1.Node, newLabels, newAnnotations map[string]string, m *clusterv1.Machine) error {
+func (r *Reconciler) patchNode(ctx context.Context, remoteClient client.Client, cluster *clusterv1.Cluster, node *corev1.Node, newLabels, newAnnotations map[string]string, m *clusterv1.Machine) error {
 	newNode := node.DeepCopy()
 
 	// Adds the annotations CAPI sets on the node.
@@ -267,29 +328,21 @@ func (r *Reconciler) patchNode(ctx context.Context, remoteClient client.Client,
 	// NOTE: in order to handle deletion we are tracking the labels set from the Machine in an annotation.
 	// At the next reconcile we are going to use this for deleting labels previously set by the Machine, but
 	// not present anymore. Labels not set from machines should be always preserved.
-	if newNode.Labels == nil {
-		newNode.Labels = make(map[string]string)
-	}
-	hasLabelChanges := false
-	labelsFromPreviousReconcile := strings.Split(newNode.Annotations[clusterv1.LabelsFromMachineAnnotation], ",")
-	if len(labelsFromPreviousReconcile) == 1 && labelsFromPreviousReconcile[0] == "" {
-		labelsFromPreviousReconcile = []string{}
-	}
-	labelsFromCurrentReconcile := []string{}
-	for k, v := range newLabels {
-		if cur, ok := newNode.Labels[k]; !ok || cur != v {
-			newNode.Labels[k] = v
-			hasLabelChanges = true
-		}
-		labelsFromCurrentReconcile = append(labelsFromCurrentReconcile, k)
-	}
-	for _, k := range labelsFromPreviousReconcile {
-		if _, ok := newLabels[k]; !ok {
-			delete(newNode.Labels, k)
-			hasLabelChanges = true
-		}
-	}
-	annotations.AddAnnotations(newNode, map[string]string{clusterv1.LabelsFromMachineAnnotation: strings.Join(labelsFromCurrentReconcile, ",")})
+	labelsFromPreviousReconcile := []string{}
+	if v := newNode.Annotations[clusterv1.LabelsFromMachineAnnotation]; v != "" {
+		labelsFromPreviousReconcile = strings.Split(v, ",")
+	}
+	// Note: newLabels have been already selected according to labels.MachineToNodeLabels and include labels computed
+	// by CAPI, like the interruptible label, so all of them are merged as authoritative.
+	mergedLabels := nodeLabelsPropagation.Merge(newNode.Labels, newLabels, labelsFromPreviousReconcile, false)
+	newNode.Labels = mergedLabels.Merged
+	hasLabelChanges := mergedLabels.Changed
+	annotations.AddAnnotations(newNode, map[string]string{clusterv1.LabelsFromMachineAnnotation: strings.Join(mergedLabels.Propagated, ",")})
+
+	// Propagate the labels and annotations defined in the Cluster's spec.metadataPropagation; those are tracked
+	// separately from the labels propagated from the Machine, and take precedence over them in case of conflicts.
+	// Note: like for the labels from the Machine, only the labels allowed by labels.MachineToNodeLabels are propagated.
+	hasClusterMetadataChanges := labels.PropagateClusterMetadataToNode(cluster, newNode)
 
 	// Drop the NodeUninitializedTaint taint on the node given that we are reconciling labels.
 	hasTaintChanges := taints.RemoveNodeTaint(newNode, clusterv1.NodeUninitializedTaint)
@@ -305,7 +358,7 @@ func (r *Reconciler) patchNode(ctx context.Context, remoteClient client.Client,
 		hasTaintChanges = taints.RemoveNodeTaint(newNode, clusterv1.NodeOutdatedRevisionTaint) || hasTaintChanges
 	}
 
-	if !hasAnnotationChanges && !hasLabelChanges && !hasTaintChanges {
+	if !hasAnnotationChanges && !hasLabelChanges && !hasClusterMetadataChanges && !hasTaintChanges {
 		return nil
 	}
 
@@ -26,15 +26,21 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/remote"
+	runtimecatalog "sigs.k8s.io/cluster-api/exp/runtime/catalog"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	fakeruntimeclient "sigs.k8s.io/cluster-api/internal/runtime/client/fake"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
 )
 
@@ -167,6 +173,57 @@ func TestGetNode(t *testing.T) {
 	}
 }
 
+func TestAdoptNode(t *testing.T) {
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "machine",
+			Namespace:   metav1.NamespaceDefault,
+			Annotations: map[string]string{clusterv1.AdoptNodeAnnotation: "node-1"},
+		},
+		Spec: clusterv1.MachineSpec{
+			ProviderID: ptr.To("preprovisioned://node-1"),
+		},
+	}
+
+	t.Run("sets the providerID of a Node without providerID", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewClientBuilder().WithObjects(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}).Build()
+		r := &Reconciler{recorder: record.NewFakeRecorder(10)}
+
+		node, err := r.adoptNode(ctx, c, machine, "node-1")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(node.Spec.ProviderID).To(Equal("preprovisioned://node-1"))
+
+		gotNode := &corev1.Node{}
+		g.Expect(c.Get(ctx, client.ObjectKey{Name: "node-1"}, gotNode)).To(Succeed())
+		g.Expect(gotNode.Spec.ProviderID).To(Equal("preprovisioned://node-1"))
+	})
+
+	t.Run("fails for a Node with a different providerID", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewClientBuilder().WithObjects(&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Spec:       corev1.NodeSpec{ProviderID: "aws:///us-east-1a/i-1234"},
+		}).Build()
+		r := &Reconciler{recorder: record.NewFakeRecorder(10)}
+
+		_, err := r.adoptNode(ctx, c, machine, "node-1")
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("returns ErrNodeNotFound if the Node does not exist", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewClientBuilder().Build()
+		r := &Reconciler{recorder: record.NewFakeRecorder(10)}
+
+		_, err := r.adoptNode(ctx, c, machine, "node-1")
+		g.Expect(err).To(MatchError(ErrNodeNotFound))
+	})
+}
+
 func TestNodeLabelSync(t *testing.T) {
 	defaultCluster := &clusterv1.Cluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -416,6 +473,95 @@ func TestNodeLabelSync(t *testing.T) {
 	})
 }
 
+func TestVerifyNodeIdentity(t *testing.T) {
+	catalog := runtimecatalog.New()
+	_ = runtimehooksv1.AddToCatalog(catalog)
+	gvh, err := catalog.GroupVersionHook(runtimehooksv1.VerifyNodeIdentity)
+	if err != nil {
+		panic("unable to compute GVH")
+	}
+
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: metav1.NamespaceDefault}}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+
+	tests := []struct {
+		name         string
+		response     *runtimehooksv1.VerifyNodeIdentityResponse
+		wantResult   ctrl.Result
+		wantErr      bool
+		wantReason   string
+		wantSeverity clusterv1.ConditionSeverity
+	}{
+		{
+			name: "verified node",
+			response: &runtimehooksv1.VerifyNodeIdentityResponse{
+				CommonRetryResponse: runtimehooksv1.CommonRetryResponse{
+					CommonResponse: runtimehooksv1.CommonResponse{Status: runtimehooksv1.ResponseStatusSuccess},
+				},
+			},
+			wantResult: ctrl.Result{},
+		},
+		{
+			name: "waiting for the identity evidence",
+			response: &runtimehooksv1.VerifyNodeIdentityResponse{
+				CommonRetryResponse: runtimehooksv1.CommonRetryResponse{
+					CommonResponse:    runtimehooksv1.CommonResponse{Status: runtimehooksv1.ResponseStatusSuccess, Message: "waiting for TPM quote"},
+					RetryAfterSeconds: 10,
+				},
+			},
+			wantResult:   ctrl.Result{RequeueAfter: 10 * time.Second},
+			wantReason:   clusterv1.WaitingForNodeIdentityVerificationReason,
+			wantSeverity: clusterv1.ConditionSeverityInfo,
+		},
+		{
+			name: "rejected node",
+			response: &runtimehooksv1.VerifyNodeIdentityResponse{
+				CommonRetryResponse: runtimehooksv1.CommonRetryResponse{
+					CommonResponse: runtimehooksv1.CommonResponse{Status: runtimehooksv1.ResponseStatusFailure, Message: "instance identity mismatch"},
+				},
+			},
+			wantErr:      true,
+			wantReason:   clusterv1.NodeIdentityVerificationFailedReason,
+			wantSeverity: clusterv1.ConditionSeverityError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: metav1.NamespaceDefault}}
+			runtimeClient := fakeruntimeclient.NewRuntimeClientBuilder().
+				WithCatalog(catalog).
+				WithCallAllExtensionResponses(map[runtimecatalog.GroupVersionHook]runtimehooksv1.ResponseObject{
+					gvh: tt.response,
+				}).
+				Build()
+
+			r := &Reconciler{
+				RuntimeClient: runtimeClient,
+				recorder:      record.NewFakeRecorder(10),
+			}
+
+			res, err := r.verifyNodeIdentity(ctx, cluster, machine, node)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+			g.Expect(res).To(Equal(tt.wantResult))
+			g.Expect(runtimeClient.CallAllCount(runtimehooksv1.VerifyNodeIdentity)).To(Equal(1))
+
+			if tt.wantReason == "" {
+				g.Expect(conditions.Has(machine, clusterv1.MachineNodeHealthyCondition)).To(BeFalse())
+				return
+			}
+			g.Expect(conditions.IsFalse(machine, clusterv1.MachineNodeHealthyCondition)).To(BeTrue())
+			g.Expect(conditions.GetReason(machine, clusterv1.MachineNodeHealthyCondition)).To(Equal(tt.wantReason))
+			g.Expect(conditions.GetSeverity(machine, clusterv1.MachineNodeHealthyCondition)).To(HaveValue(Equal(tt.wantSeverity)))
+		})
+	}
+}
+
 func TestSummarizeNodeConditions(t *testing.T) {
 	testCases := []struct {
 		name       string
@@ -480,40 +626,6 @@ func TestSummarizeNodeConditions(t *testing.T) {
 	}
 }
 
-func TestGetManagedLabels(t *testing.T) {
-	// Create managedLabels map from known managed prefixes.
-	managedLabels := map[string]string{
-		clusterv1.NodeRoleLabelPrefix + "/anyRole": "",
-
-		clusterv1.ManagedNodeLabelDomain:                                  "",
-		"custom-prefix." + clusterv1.ManagedNodeLabelDomain:               "",
-		clusterv1.ManagedNodeLabelDomain + "/anything":                    "",
-		"custom-prefix." + clusterv1.ManagedNodeLabelDomain + "/anything": "",
-
-		clusterv1.NodeRestrictionLabelDomain:                                  "",
-		"custom-prefix." + clusterv1.NodeRestrictionLabelDomain:               "",
-		clusterv1.NodeRestrictionLabelDomain + "/anything":                    "",
-		"custom-prefix." + clusterv1.NodeRestrictionLabelDomain + "/anything": "",
-	}
-
-	// Append arbitrary labels.
-	allLabels := map[string]string{
-		"foo":                               "",
-		"bar":                               "",
-		"company.xyz/node.cluster.x-k8s.io": "not-managed",
-		"gpu-node.cluster.x-k8s.io":         "not-managed",
-		"company.xyz/node-restriction.kubernetes.io": "not-managed",
-		"gpu-node-restriction.kubernetes.io":         "not-managed",
-	}
-	for k, v := range managedLabels {
-		allLabels[k] = v
-	}
-
-	g := NewWithT(t)
-	got := getManagedLabels(allLabels)
-	g.Expect(got).To(BeEquivalentTo(managedLabels))
-}
-
 func TestPatchNode(t *testing.T) {
 	clusterName := "test-cluster"
 
@@ -921,7 +1033,7 @@ func TestPatchNode(t *testing.T) {
 				_ = env.CleanupAndWait(ctx, oldNode, machine, ms, md)
 			})
 
-			err := r.patchNode(ctx, env, oldNode, tc.newLabels, tc.newAnnotations, tc.machine)
+			err := r.patchNode(ctx, env, &clusterv1.Cluster{}, oldNode, tc.newLabels, tc.newAnnotations, tc.machine)
 			g.Expect(err).ToNot(HaveOccurred())
 
 			g.Eventually(func(g Gomega) {
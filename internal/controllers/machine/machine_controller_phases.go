@@ -40,6 +40,7 @@ import (
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
+	capilabels "sigs.k8s.io/cluster-api/util/labels"
 	"sigs.k8s.io/cluster-api/util/patch"
 )
 
@@ -171,6 +172,9 @@ func (r *Reconciler) ensureExternalOwnershipAndWatch(ctx context.Context, cluste
 	labels[clusterv1.ClusterNameLabel] = m.Spec.ClusterName
 	obj.SetLabels(labels)
 
+	// Propagate the labels and annotations defined in the Cluster's spec.metadataPropagation.
+	capilabels.PropagateClusterMetadata(cluster, obj)
+
 	// Always attempt to Patch the external object.
 	if err := patchHelper.Patch(ctx, obj); err != nil {
 		return external.ReconcileOutput{}, err
@@ -187,6 +191,11 @@ func (r *Reconciler) reconcileBootstrap(ctx context.Context, s *scope) (ctrl.Res
 
 	// If the Bootstrap ref is nil (and so the machine should use user generated data secret), return.
 	if m.Spec.Bootstrap.ConfigRef == nil {
+		// Machines adopting an existing Node without bootstrap data don't need to be bootstrapped.
+		if _, ok := m.Annotations[clusterv1.AdoptNodeAnnotation]; ok && m.Spec.Bootstrap.DataSecretName == nil {
+			m.Status.BootstrapReady = true
+			conditions.MarkTrue(m, clusterv1.BootstrapReadyCondition)
+		}
 		return ctrl.Result{}, nil
 	}
 
@@ -682,6 +682,33 @@ func TestReconcileBootstrap(t *testing.T) {
 				g.Expect(*m.Spec.Bootstrap.DataSecretName).To(ContainSubstring("secret-data"))
 			},
 		},
+		{
+			name: "new machine adopting a Node, no bootstrap",
+			bootstrapConfig: map[string]interface{}{
+				"kind":       "GenericBootstrapConfig",
+				"apiVersion": "bootstrap.cluster.x-k8s.io/v1beta1",
+				"metadata": map[string]interface{}{
+					"name":      "bootstrap-config1",
+					"namespace": metav1.NamespaceDefault,
+				},
+			},
+			machine: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "machine-test",
+					Namespace: metav1.NamespaceDefault,
+					Annotations: map[string]string{
+						clusterv1.AdoptNodeAnnotation: "node-1",
+					},
+				},
+			},
+			expectResult: ctrl.Result{},
+			expectError:  false,
+			expected: func(g *WithT, m *clusterv1.Machine) {
+				g.Expect(m.Status.BootstrapReady).To(BeTrue())
+				g.Expect(conditions.IsTrue(m, clusterv1.BootstrapReadyCondition)).To(BeTrue())
+				g.Expect(m.Spec.Bootstrap.DataSecretName).To(BeNil())
+			},
+		},
 		{
 			name: "new machine, bootstrap config ready with no data",
 			bootstrapConfig: map[string]interface{}{
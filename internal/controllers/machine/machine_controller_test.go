@@ -575,7 +575,7 @@ func TestMachineFinalizer(t *testing.T) {
 				clusterCorrectMeta,
 				machineValidCluster,
 				machineWithFinalizer,
-			).Build()
+			).WithStatusSubresource(&clusterv1.Machine{}).Build()
 			mr := &Reconciler{
 				Client:                    c,
 				UnstructuredCachingClient: c,
@@ -2221,7 +2221,7 @@ func TestNodeToMachine(t *testing.T) {
 		UnstructuredCachingClient: env,
 	}
 	for _, node := range fakeNodes {
-		request := r.nodeToMachine(ctx, node)
+		request := r.nodeToMachine(util.ObjectKey(testCluster))(ctx, node)
 		g.Expect(request).To(BeEquivalentTo([]reconcile.Request{
 			{
 				NamespacedName: client.ObjectKeyFromObject(expectedMachine),
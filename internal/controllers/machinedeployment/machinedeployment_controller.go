@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -29,6 +30,7 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -37,13 +39,24 @@ import (
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/internal/controllers/machinedeployment/mdutil"
+	"sigs.k8s.io/cluster-api/internal/util/metrics"
+	"sigs.k8s.io/cluster-api/internal/util/rolloutwindow"
 	"sigs.k8s.io/cluster-api/internal/util/ssa"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
+	capilabels "sigs.k8s.io/cluster-api/util/labels"
+	clog "sigs.k8s.io/cluster-api/util/log"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/paused"
 	"sigs.k8s.io/cluster-api/util/predicates"
+	"sigs.k8s.io/cluster-api/util/priority"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/requeue"
+	"sigs.k8s.io/cluster-api/util/throttle"
+	"sigs.k8s.io/cluster-api/util/tracing"
 )
 
 var (
@@ -80,26 +93,31 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opt
 		return err
 	}
 
-	err = ctrl.NewControllerManagedBy(mgr).
-		For(&clusterv1.MachineDeployment{}).
-		Owns(&clusterv1.MachineSet{}).
+	b := priority.For(ctrl.NewControllerManagedBy(mgr), &clusterv1.MachineDeployment{})
+	// Status-only changes of the owned MachineSets are batched, so the status of the MachineDeployment is not recomputed
+	// and written for every individual change.
+	err = priority.Owns(b, mgr, &clusterv1.MachineDeployment{}, &clusterv1.MachineSet{}).
 		// Watches enqueues MachineDeployment for corresponding MachineSet resources, if no managed controller reference (owner) exists.
 		Watches(
 			&clusterv1.MachineSet{},
 			handler.EnqueueRequestsFromMapFunc(r.MachineSetToDeployments),
 		).
 		WithOptions(options).
-		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
+		WithEventFilter(predicates.ResourceHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
 		Watches(
 			&clusterv1.Cluster{},
 			handler.EnqueueRequestsFromMapFunc(clusterToMachineDeployments),
 			builder.WithPredicates(
 				// TODO: should this wait for Cluster.Status.InfrastructureReady similar to Infra Machine resources?
 				predicates.All(ctrl.LoggerFrom(ctx),
-					predicates.ClusterUnpaused(ctrl.LoggerFrom(ctx)),
+					predicates.Any(ctrl.LoggerFrom(ctx),
+						predicates.ClusterPausedTransitions(ctrl.LoggerFrom(ctx)),
+						predicates.ClusterMetadataPropagationChanged(ctrl.LoggerFrom(ctx)),
+					),
 				),
 			),
-		).Complete(r)
+		).Complete(requeue.Reconciler(throttle.Reconciler("machinedeployment", r.Client, &clusterv1.MachineDeployment{},
+		metrics.InstrumentReconciler("machinedeployment", r.Client, &clusterv1.MachineDeployment{}, r))))
 	if err != nil {
 		return errors.Wrap(err, "failed setting up with a controller manager")
 	}
@@ -110,6 +128,9 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opt
 }
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx, span := tracing.StartReconcile(ctx, "MachineDeployment", req)
+	defer func() { tracing.End(span, reterr) }()
+
 	log := ctrl.LoggerFrom(ctx)
 
 	// Fetch the MachineDeployment instance.
@@ -132,10 +153,14 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Re
 		return ctrl.Result{}, err
 	}
 
-	// Return early if the object or Cluster is paused.
-	if annotations.IsPaused(cluster, deployment) {
-		log.Info("Reconciliation is paused for this object")
-		return ctrl.Result{}, nil
+	ctx, log = clog.AddCorrelationID(ctx, cluster)
+
+	// Set the Paused condition, and return early if the object or Cluster is paused.
+	if isPaused, err := paused.EnsurePausedCondition(ctx, r.Client, cluster, deployment); err != nil || isPaused {
+		if isPaused {
+			log.Info("Reconciliation is paused for this object")
+		}
+		return ctrl.Result{}, err
 	}
 
 	// Initialize the patch helper
@@ -162,11 +187,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Re
 		return ctrl.Result{}, nil
 	}
 
-	err = r.reconcile(ctx, cluster, deployment)
+	result, err := r.reconcile(ctx, cluster, deployment)
 	if err != nil {
-		r.recorder.Eventf(deployment, corev1.EventTypeWarning, "ReconcileError", "%v", err)
+		capirecord.Emit(r.recorder, deployment, capirecord.ReconcileErrorReason, err)
 	}
-	return ctrl.Result{}, err
+	return result, err
 }
 
 func patchMachineDeployment(ctx context.Context, patchHelper *patch.Helper, md *clusterv1.MachineDeployment, options ...patch.Option) error {
@@ -182,12 +207,13 @@ func patchMachineDeployment(ctx context.Context, patchHelper *patch.Helper, md *
 		patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
 			clusterv1.ReadyCondition,
 			clusterv1.MachineDeploymentAvailableCondition,
+			clusterv1.MachineDeploymentRolloutAllowedCondition,
 		}},
 	)
 	return patchHelper.Patch(ctx, md, options...)
 }
 
-func (r *Reconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster, md *clusterv1.MachineDeployment) error {
+func (r *Reconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster, md *clusterv1.MachineDeployment) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx)
 	log.V(4).Info("Reconcile MachineDeployment")
 
@@ -204,6 +230,9 @@ func (r *Reconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster,
 
 	md.Labels[clusterv1.ClusterNameLabel] = md.Spec.ClusterName
 
+	// Propagate the labels and annotations defined in the Cluster's spec.metadataPropagation.
+	capilabels.PropagateClusterMetadata(cluster, md)
+
 	// Ensure the MachineDeployment is owned by the Cluster.
 	md.SetOwnerReferences(util.EnsureOwnerRef(md.GetOwnerReferences(), metav1.OwnerReference{
 		APIVersion: clusterv1.GroupVersion.String(),
@@ -214,18 +243,18 @@ func (r *Reconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster,
 
 	// Make sure to reconcile the external infrastructure reference.
 	if err := reconcileExternalTemplateReference(ctx, r.UnstructuredCachingClient, cluster, &md.Spec.Template.Spec.InfrastructureRef); err != nil {
-		return err
+		return ctrl.Result{}, err
 	}
 	// Make sure to reconcile the external bootstrap reference, if any.
 	if md.Spec.Template.Spec.Bootstrap.ConfigRef != nil {
 		if err := reconcileExternalTemplateReference(ctx, r.UnstructuredCachingClient, cluster, md.Spec.Template.Spec.Bootstrap.ConfigRef); err != nil {
-			return err
+			return ctrl.Result{}, err
 		}
 	}
 
 	msList, err := r.getMachineSetsForDeployment(ctx, md)
 	if err != nil {
-		return err
+		return ctrl.Result{}, err
 	}
 
 	// If not already present, add a label specifying the MachineDeployment name to MachineSets.
@@ -241,11 +270,11 @@ func (r *Reconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster,
 
 		helper, err := patch.NewHelper(machineSet, r.Client)
 		if err != nil {
-			return errors.Wrapf(err, "failed to apply %s label to MachineSet %q", clusterv1.MachineDeploymentNameLabel, machineSet.Name)
+			return ctrl.Result{}, errors.Wrapf(err, "failed to apply %s label to MachineSet %q", clusterv1.MachineDeploymentNameLabel, machineSet.Name)
 		}
 		machineSet.Labels[clusterv1.MachineDeploymentNameLabel] = md.Name
 		if err := helper.Patch(ctx, machineSet); err != nil {
-			return errors.Wrapf(err, "failed to apply %s label to MachineSet %q", clusterv1.MachineDeploymentNameLabel, machineSet.Name)
+			return ctrl.Result{}, errors.Wrapf(err, "failed to apply %s label to MachineSet %q", clusterv1.MachineDeploymentNameLabel, machineSet.Name)
 		}
 	}
 
@@ -258,30 +287,99 @@ func (r *Reconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster,
 	for idx := range msList {
 		machineSet := msList[idx]
 		if err := ssa.CleanUpManagedFieldsForSSAAdoption(ctx, r.Client, machineSet, machineDeploymentManagerName); err != nil {
-			return errors.Wrapf(err, "failed to clean up managedFields of MachineSet %s", klog.KObj(machineSet))
+			return ctrl.Result{}, errors.Wrapf(err, "failed to clean up managedFields of MachineSet %s", klog.KObj(machineSet))
 		}
 	}
 
+	setAutoscalerCapacityLabels(md, msList)
+
 	if md.Spec.Paused {
-		return r.sync(ctx, md, msList)
+		return ctrl.Result{}, r.sync(ctx, md, msList)
 	}
 
 	if md.Spec.Strategy == nil {
-		return errors.Errorf("missing MachineDeployment strategy")
+		return ctrl.Result{}, errors.Errorf("missing MachineDeployment strategy")
+	}
+
+	// Defer the rollout if it is not allowed by the rollout windows of the Cluster; in the meantime
+	// the MachineDeployment is only scaled, like when it is paused.
+	deferred, nextStart, err := rolloutDeferred(cluster, md, msList, time.Now())
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if deferred {
+		log.Info("Rollout deferred until the next rollout window starts", "nextStart", nextStart)
+		conditions.MarkFalse(md, clusterv1.MachineDeploymentRolloutAllowedCondition, clusterv1.RolloutDeferredReason, clusterv1.ConditionSeverityInfo,
+			"Rollout deferred until the next rollout window starting at %s", nextStart.UTC().Format(time.RFC3339))
+		if err := r.sync(ctx, md, msList); err != nil {
+			return ctrl.Result{}, err
+		}
+		if nextStart.IsZero() {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{RequeueAfter: time.Until(nextStart)}, nil
+	}
+	if conditions.Has(md, clusterv1.MachineDeploymentRolloutAllowedCondition) {
+		conditions.MarkTrue(md, clusterv1.MachineDeploymentRolloutAllowedCondition)
 	}
 
 	if md.Spec.Strategy.Type == clusterv1.RollingUpdateMachineDeploymentStrategyType {
 		if md.Spec.Strategy.RollingUpdate == nil {
-			return errors.Errorf("missing MachineDeployment settings for strategy type: %s", md.Spec.Strategy.Type)
+			return ctrl.Result{}, errors.Errorf("missing MachineDeployment settings for strategy type: %s", md.Spec.Strategy.Type)
 		}
-		return r.rolloutRolling(ctx, md, msList)
+		return ctrl.Result{}, r.rolloutRolling(ctx, md, msList)
 	}
 
 	if md.Spec.Strategy.Type == clusterv1.OnDeleteMachineDeploymentStrategyType {
-		return r.rolloutOnDelete(ctx, md, msList)
+		return ctrl.Result{}, r.rolloutOnDelete(ctx, md, msList)
+	}
+
+	return ctrl.Result{}, errors.Errorf("unexpected deployment strategy type: %s", md.Spec.Strategy.Type)
+}
+
+// setAutoscalerCapacityLabels sets the autoscaler capacity labels used when scaling the MachineDeployment from zero
+// to the architecture of the Machines, as computed by the MachineSet controller for the MachineSet matching the
+// current template of the MachineDeployment; capacity labels already set on the MachineDeployment are preserved.
+func setAutoscalerCapacityLabels(md *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) {
+	if _, ok := md.Annotations[clusterv1.AutoscalerCapacityLabelsAnnotation]; ok {
+		return
+	}
+	newMS := mdutil.FindNewMachineSet(md, msList, &metav1.Time{Time: time.Now()})
+	if newMS == nil {
+		return
+	}
+	arch := newMS.Annotations[clusterv1.MachineArchitectureAnnotation]
+	if arch == "" {
+		return
+	}
+	annotations.AddAnnotations(md, map[string]string{
+		clusterv1.AutoscalerCapacityLabelsAnnotation: fmt.Sprintf("%s=%s", corev1.LabelArchStable, arch),
+	})
+}
+
+// rolloutDeferred returns true if the MachineDeployment requires a rollout, i.e. a new MachineSet has to be created to
+// replace existing Machines, while none of the rollout windows of the Cluster is open; in this case it also returns
+// the time the next window starts. Rollouts already in progress and the creation of the first MachineSet are never deferred.
+func rolloutDeferred(cluster *clusterv1.Cluster, md *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet, now time.Time) (bool, time.Time, error) {
+	if mdutil.FindNewMachineSet(md, msList, &metav1.Time{Time: now}) != nil {
+		return false, time.Time{}, nil
+	}
+	hasMachines := false
+	for _, ms := range msList {
+		if ptr.Deref(ms.Spec.Replicas, 0) > 0 || ms.Status.Replicas > 0 {
+			hasMachines = true
+			break
+		}
+	}
+	if !hasMachines {
+		return false, time.Time{}, nil
 	}
 
-	return errors.Errorf("unexpected deployment strategy type: %s", md.Spec.Strategy.Type)
+	allowed, nextStart, err := rolloutwindow.Check(cluster, now)
+	if err != nil {
+		return false, time.Time{}, errors.Wrap(err, "failed to check rollout windows")
+	}
+	return !allowed, nextStart, nil
 }
 
 // getMachineSetsForDeployment returns a list of MachineSets associated with a MachineDeployment.
@@ -320,11 +418,11 @@ func (r *Reconciler) getMachineSetsForDeployment(ctx context.Context, md *cluste
 		if metav1.GetControllerOf(ms) == nil {
 			if err := r.adoptOrphan(ctx, md, ms); err != nil {
 				log.Error(err, "Failed to adopt MachineSet into MachineDeployment")
-				r.recorder.Eventf(md, corev1.EventTypeWarning, "FailedAdopt", "Failed to adopt MachineSet %q: %v", ms.Name, err)
+				capirecord.Emit(r.recorder, md, capirecord.FailedAdoptReason, "MachineSet", klog.KObj(ms), err)
 				continue
 			}
 			log.Info("Adopted MachineSet into MachineDeployment")
-			r.recorder.Eventf(md, corev1.EventTypeNormal, "SuccessfulAdopt", "Adopted MachineSet %q", ms.Name)
+			capirecord.Emit(r.recorder, md, capirecord.SuccessfulAdoptReason, "MachineSet", klog.KObj(ms))
 		}
 
 		if !metav1.IsControlledBy(ms, md) {
@@ -993,3 +993,152 @@ func updateMachineDeployment(ctx context.Context, c client.Client, md *clusterv1
 		return patchHelper.Patch(ctx, md)
 	})
 }
+
+func TestRolloutDeferred(t *testing.T) {
+	// Monday 12:00 UTC, outside the nightly window.
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	nightly := clusterv1.RolloutWindow{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: 4 * time.Hour}}
+
+	cluster := func(windows ...clusterv1.RolloutWindow) *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			Spec: clusterv1.ClusterSpec{
+				Topology: &clusterv1.Topology{Rollout: &clusterv1.TopologyRollout{Windows: windows}},
+			},
+		}
+	}
+	md := &clusterv1.MachineDeployment{
+		Spec: clusterv1.MachineDeploymentSpec{
+			Template: clusterv1.MachineTemplateSpec{
+				Spec: clusterv1.MachineSpec{Version: ptr.To("v1.30.0")},
+			},
+		},
+	}
+	machineSet := func(version string, replicas int32) *clusterv1.MachineSet {
+		return &clusterv1.MachineSet{
+			Spec: clusterv1.MachineSetSpec{
+				Replicas: ptr.To(replicas),
+				Template: clusterv1.MachineTemplateSpec{
+					Spec: clusterv1.MachineSpec{Version: ptr.To(version)},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		cluster       *clusterv1.Cluster
+		msList        []*clusterv1.MachineSet
+		wantDeferred  bool
+		wantNextStart time.Time
+	}{
+		{
+			name:    "not deferred without rollout windows",
+			cluster: cluster(),
+			msList:  []*clusterv1.MachineSet{machineSet("v1.29.0", 3)},
+		},
+		{
+			name:          "deferred outside the rollout windows",
+			cluster:       cluster(nightly),
+			msList:        []*clusterv1.MachineSet{machineSet("v1.29.0", 3)},
+			wantDeferred:  true,
+			wantNextStart: time.Date(2024, 3, 4, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "not deferred if the rollout is already in progress",
+			cluster: cluster(nightly),
+			msList:  []*clusterv1.MachineSet{machineSet("v1.29.0", 2), machineSet("v1.30.0", 1)},
+		},
+		{
+			name:    "not deferred if there are no Machines to replace",
+			cluster: cluster(nightly),
+			msList:  []*clusterv1.MachineSet{machineSet("v1.29.0", 0)},
+		},
+		{
+			name:    "not deferred for the first MachineSet",
+			cluster: cluster(nightly),
+		},
+		{
+			name:    "not deferred inside a rollout window",
+			cluster: cluster(clusterv1.RolloutWindow{Schedule: "0 10 * * 1", Duration: metav1.Duration{Duration: 4 * time.Hour}}),
+			msList:  []*clusterv1.MachineSet{machineSet("v1.29.0", 3)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			deferred, nextStart, err := rolloutDeferred(tt.cluster, md, tt.msList, now)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(deferred).To(Equal(tt.wantDeferred))
+			g.Expect(nextStart.Equal(tt.wantNextStart)).To(BeTrue())
+		})
+	}
+}
+
+func TestSetAutoscalerCapacityLabels(t *testing.T) {
+	newMachineDeployment := func() *clusterv1.MachineDeployment {
+		return &clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "md", Namespace: "default"},
+			Spec: clusterv1.MachineDeploymentSpec{
+				Template: clusterv1.MachineTemplateSpec{
+					Spec: clusterv1.MachineSpec{
+						ClusterName: "test-cluster",
+						InfrastructureRef: corev1.ObjectReference{
+							Kind: "GenericInfrastructureMachineTemplate",
+							Name: "infra-template-2",
+						},
+					},
+				},
+			},
+		}
+	}
+	newMachineSet := func(name, infraTemplate, arch string) *clusterv1.MachineSet {
+		return &clusterv1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Annotations: map[string]string{clusterv1.MachineArchitectureAnnotation: arch},
+			},
+			Spec: clusterv1.MachineSetSpec{
+				Template: clusterv1.MachineTemplateSpec{
+					Spec: clusterv1.MachineSpec{
+						ClusterName: "test-cluster",
+						InfrastructureRef: corev1.ObjectReference{
+							Kind: "GenericInfrastructureMachineTemplate",
+							Name: infraTemplate,
+						},
+					},
+				},
+			},
+		}
+	}
+	msList := []*clusterv1.MachineSet{
+		newMachineSet("ms-old", "infra-template-1", "amd64"),
+		newMachineSet("ms-new", "infra-template-2", "arm64"),
+	}
+
+	t.Run("sets the capacity labels from the architecture of the new MachineSet", func(t *testing.T) {
+		g := NewWithT(t)
+
+		md := newMachineDeployment()
+		setAutoscalerCapacityLabels(md, msList)
+		g.Expect(md.Annotations).To(HaveKeyWithValue(clusterv1.AutoscalerCapacityLabelsAnnotation, "kubernetes.io/arch=arm64"))
+	})
+
+	t.Run("preserves the capacity labels set on the MachineDeployment", func(t *testing.T) {
+		g := NewWithT(t)
+
+		md := newMachineDeployment()
+		md.Annotations = map[string]string{clusterv1.AutoscalerCapacityLabelsAnnotation: "foo=bar"}
+		setAutoscalerCapacityLabels(md, msList)
+		g.Expect(md.Annotations).To(HaveKeyWithValue(clusterv1.AutoscalerCapacityLabelsAnnotation, "foo=bar"))
+	})
+
+	t.Run("does not set the capacity labels if there is no new MachineSet", func(t *testing.T) {
+		g := NewWithT(t)
+
+		md := newMachineDeployment()
+		setAutoscalerCapacityLabels(md, msList[:1])
+		g.Expect(md.Annotations).ToNot(HaveKey(clusterv1.AutoscalerCapacityLabelsAnnotation))
+	})
+}
@@ -23,7 +23,6 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
-	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -41,7 +40,9 @@ import (
 	"sigs.k8s.io/cluster-api/internal/util/hash"
 	"sigs.k8s.io/cluster-api/internal/util/ssa"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/labels"
 	"sigs.k8s.io/cluster-api/util/patch"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
 )
 
 // sync is responsible for reconciling deployments on scaling events or when they
@@ -144,7 +145,7 @@ func (r *Reconciler) updateMachineSet(ctx context.Context, deployment *clusterv1
 	// Update the MachineSet to propagate in-place mutable fields from the MachineDeployment.
 	err = ssa.Patch(ctx, r.Client, machineDeploymentManagerName, updatedMS, ssa.WithCachingProxy{Cache: r.ssaCache, Original: ms})
 	if err != nil {
-		r.recorder.Eventf(deployment, corev1.EventTypeWarning, "FailedUpdate", "Failed to update MachineSet %s: %v", klog.KObj(updatedMS), err)
+		capirecord.Emit(r.recorder, deployment, capirecord.FailedUpdateReason, "MachineSet", klog.KObj(updatedMS), err)
 		return nil, errors.Wrapf(err, "failed to update MachineSet %s", klog.KObj(updatedMS))
 	}
 
@@ -165,11 +166,11 @@ func (r *Reconciler) createMachineSetAndWait(ctx context.Context, deployment *cl
 
 	// Create the MachineSet.
 	if err := ssa.Patch(ctx, r.Client, machineDeploymentManagerName, newMS); err != nil {
-		r.recorder.Eventf(deployment, corev1.EventTypeWarning, "FailedCreate", "Failed to create MachineSet %s: %v", klog.KObj(newMS), err)
+		capirecord.Emit(r.recorder, deployment, capirecord.FailedCreateReason, "MachineSet", klog.KObj(newMS), err)
 		return nil, errors.Wrapf(err, "failed to create new MachineSet %s", klog.KObj(newMS))
 	}
 	log.V(4).Info("Created new MachineSet", "MachineSet", klog.KObj(newMS))
-	r.recorder.Eventf(deployment, corev1.EventTypeNormal, "SuccessfulCreate", "Created MachineSet %s", klog.KObj(newMS))
+	capirecord.Emit(r.recorder, deployment, capirecord.SuccessfulCreateReason, "MachineSet", klog.KObj(newMS))
 
 	// Keep trying to get the MachineSet. This will force the cache to update and prevent any future reconciliation of
 	// the MachineDeployment to reconcile with an outdated list of MachineSets which could lead to unwanted creation of
@@ -317,7 +318,7 @@ func (r *Reconciler) computeDesiredMachineSet(ctx context.Context, deployment *c
 	if desiredMS.Annotations, err = mdutil.ComputeMachineSetAnnotations(ctx, deployment, oldMSs, existingMS); err != nil {
 		return nil, errors.Wrap(err, "failed to compute desired MachineSet: failed to compute annotations")
 	}
-	desiredMS.Spec.Template.Annotations = cloneStringMap(deployment.Spec.Template.Annotations)
+	desiredMS.Spec.Template.Annotations = labels.TemplateToObject.Select(deployment.Spec.Template.Annotations)
 
 	// Set all other in-place mutable fields.
 	desiredMS.Spec.MinReadySeconds = ptr.Deref(deployment.Spec.MinReadySeconds, 0)
@@ -333,15 +334,6 @@ func (r *Reconciler) computeDesiredMachineSet(ctx context.Context, deployment *c
 	return desiredMS, nil
 }
 
-// cloneStringMap clones a string map.
-func cloneStringMap(in map[string]string) map[string]string {
-	out := map[string]string{}
-	for k, v := range in {
-		out[k] = v
-	}
-	return out
-}
-
 const (
 	maxNameLength          = 63
 	randomLength           = 5
@@ -583,13 +575,11 @@ func (r *Reconciler) scaleMachineSet(ctx context.Context, ms *clusterv1.MachineS
 	mdutil.SetReplicasAnnotations(ms, *(deployment.Spec.Replicas), *(deployment.Spec.Replicas)+mdutil.MaxSurge(*deployment))
 
 	if err := patchHelper.Patch(ctx, ms); err != nil {
-		r.recorder.Eventf(deployment, corev1.EventTypeWarning, "FailedScale", "Failed to scale MachineSet %v: %v",
-			client.ObjectKeyFromObject(ms), err)
+		capirecord.Emit(r.recorder, deployment, capirecord.FailedScaleReason, "MachineSet", klog.KObj(ms), err)
 		return err
 	}
 
-	r.recorder.Eventf(deployment, corev1.EventTypeNormal, "SuccessfulScale", "Scaled MachineSet %v: %d -> %d",
-		client.ObjectKeyFromObject(ms), originalReplicas, *ms.Spec.Replicas)
+	capirecord.Emit(r.recorder, deployment, capirecord.SuccessfulScaleReason, "MachineSet", klog.KObj(ms), originalReplicas, *ms.Spec.Replicas)
 
 	return nil
 }
@@ -634,10 +624,10 @@ func (r *Reconciler) cleanupDeployment(ctx context.Context, oldMSs []*clusterv1.
 		if err := r.Client.Delete(ctx, ms); err != nil && !apierrors.IsNotFound(err) {
 			// Return error instead of aggregating and continuing DELETEs on the theory
 			// that we may be overloading the api server.
-			r.recorder.Eventf(deployment, corev1.EventTypeWarning, "FailedDelete", "Failed to delete MachineSet %q: %v", ms.Name, err)
+			capirecord.Emit(r.recorder, deployment, capirecord.FailedDeleteReason, "MachineSet", klog.KObj(ms), err)
 			return err
 		}
-		r.recorder.Eventf(deployment, corev1.EventTypeNormal, "SuccessfulDelete", "Deleted MachineSet %q", ms.Name)
+		capirecord.Emit(r.recorder, deployment, capirecord.SuccessfulDeleteReason, "MachineSet", klog.KObj(ms))
 	}
 
 	return nil
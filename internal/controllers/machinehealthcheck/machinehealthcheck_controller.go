@@ -51,7 +51,9 @@ import (
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/paused"
 	"sigs.k8s.io/cluster-api/util/predicates"
+	"sigs.k8s.io/cluster-api/util/requeue"
 )
 
 const (
@@ -92,18 +94,18 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opt
 			handler.EnqueueRequestsFromMapFunc(r.machineToMachineHealthCheck),
 		).
 		WithOptions(options).
-		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
+		WithEventFilter(predicates.ResourceHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
 		Watches(
 			&clusterv1.Cluster{},
 			handler.EnqueueRequestsFromMapFunc(r.clusterToMachineHealthCheck),
 			builder.WithPredicates(
 				// TODO: should this wait for Cluster.Status.InfrastructureReady similar to Infra Machine resources?
 				predicates.All(ctrl.LoggerFrom(ctx),
-					predicates.ClusterUnpaused(ctrl.LoggerFrom(ctx)),
+					predicates.ClusterPausedTransitions(ctrl.LoggerFrom(ctx)),
 					predicates.ResourceHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue),
 				),
 			),
-		).Build(r)
+		).Build(requeue.Reconciler(r))
 	if err != nil {
 		return errors.Wrap(err, "failed setting up with a controller manager")
 	}
@@ -139,10 +141,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Re
 		return ctrl.Result{}, err
 	}
 
-	// Return early if the object or Cluster is paused.
-	if annotations.IsPaused(cluster, m) {
-		log.Info("Reconciliation is paused for this object")
-		return ctrl.Result{}, nil
+	// Set the Paused condition, and return early if the object or Cluster is paused.
+	if isPaused, err := paused.EnsurePausedCondition(ctx, r.Client, cluster, m); err != nil || isPaused {
+		if isPaused {
+			log.Info("Reconciliation is paused for this object")
+		}
+		return ctrl.Result{}, err
 	}
 
 	// Initialize the patch helper
@@ -509,18 +513,21 @@ func (r *Reconciler) machineToMachineHealthCheck(ctx context.Context, o client.O
 	return requests
 }
 
-func (r *Reconciler) nodeToMachineHealthCheck(ctx context.Context, o client.Object) []reconcile.Request {
-	node, ok := o.(*corev1.Node)
-	if !ok {
-		panic(fmt.Sprintf("Expected a corev1.Node, got %T", o))
-	}
+// nodeToMachineHealthCheck returns a MapFunc mapping Nodes of the given Cluster to the MachineHealthChecks of their Machine.
+func (r *Reconciler) nodeToMachineHealthCheck(cluster client.ObjectKey) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		node, ok := o.(*corev1.Node)
+		if !ok {
+			panic(fmt.Sprintf("Expected a corev1.Node, got %T", o))
+		}
 
-	machine, err := getMachineFromNode(ctx, r.Client, node.Name)
-	if machine == nil || err != nil {
-		return nil
-	}
+		machine, err := getMachineFromNode(ctx, r.Client, cluster, node.Name)
+		if machine == nil || err != nil {
+			return nil
+		}
 
-	return r.machineToMachineHealthCheck(ctx, machine)
+		return r.machineToMachineHealthCheck(ctx, machine)
+	}
 }
 
 func (r *Reconciler) watchClusterNodes(ctx context.Context, cluster *clusterv1.Cluster) error {
@@ -534,29 +541,24 @@ func (r *Reconciler) watchClusterNodes(ctx context.Context, cluster *clusterv1.C
 		Cluster:      util.ObjectKey(cluster),
 		Watcher:      r.controller,
 		Kind:         &corev1.Node{},
-		EventHandler: handler.EnqueueRequestsFromMapFunc(r.nodeToMachineHealthCheck),
+		EventHandler: handler.EnqueueRequestsFromMapFunc(r.nodeToMachineHealthCheck(util.ObjectKey(cluster))),
 	})
 }
 
-// getMachineFromNode retrieves the machine with a nodeRef to nodeName
+// getMachineFromNode retrieves the machine of the given cluster with a nodeRef to nodeName
 // There should at most one machine with a given nodeRef, returns an error otherwise.
-func getMachineFromNode(ctx context.Context, c client.Client, nodeName string) (*clusterv1.Machine, error) {
+func getMachineFromNode(ctx context.Context, c client.Client, cluster client.ObjectKey, nodeName string) (*clusterv1.Machine, error) {
 	machineList := &clusterv1.MachineList{}
 	if err := c.List(
 		ctx,
 		machineList,
-		client.MatchingFields{index.MachineNodeNameField: nodeName},
+		client.MatchingFields{index.MachineClusterNodeField: index.ClusterNodeNameIndexKey(cluster, nodeName)},
 	); err != nil {
 		return nil, errors.Wrap(err, "failed getting machine list")
 	}
-	// TODO(vincepri): Remove this loop once controller runtime fake client supports
-	// adding indexes on objects.
 	items := []*clusterv1.Machine{}
 	for i := range machineList.Items {
-		machine := &machineList.Items[i]
-		if machine.Status.NodeRef != nil && machine.Status.NodeRef.Name == nodeName {
-			items = append(items, machine)
-		}
+		items = append(items, &machineList.Items[i])
 	}
 	if len(items) != 1 {
 		return nil, errors.Errorf("expecting one machine for node %v, got %v", nodeName, machineNames(items))
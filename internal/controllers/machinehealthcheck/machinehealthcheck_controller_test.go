@@ -2049,7 +2049,7 @@ func TestMachineToMachineHealthCheck(t *testing.T) {
 
 func TestNodeToMachineHealthCheck(t *testing.T) {
 	fakeClient := fake.NewClientBuilder().
-		WithIndex(&clusterv1.Machine{}, index.MachineNodeNameField, index.MachineByNodeName).
+		WithIndex(&clusterv1.Machine{}, index.MachineClusterNodeField, index.MachineByClusterNode).
 		WithStatusSubresource(&clusterv1.MachineHealthCheck{}, &clusterv1.Machine{}).
 		Build()
 
@@ -2169,7 +2169,7 @@ func TestNodeToMachineHealthCheck(t *testing.T) {
 				gs.Eventually(checkStatus).Should(BeComparableTo(o.Status))
 			}
 
-			got := r.nodeToMachineHealthCheck(ctx, tc.object)
+			got := r.nodeToMachineHealthCheck(client.ObjectKey{Namespace: namespace, Name: clusterName})(ctx, tc.object)
 			gs.Expect(got).To(ConsistOf(tc.expected))
 		})
 	}
@@ -45,16 +45,25 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	"sigs.k8s.io/cluster-api/internal/contract"
 	"sigs.k8s.io/cluster-api/internal/controllers/machine"
+	"sigs.k8s.io/cluster-api/internal/util/metrics"
 	"sigs.k8s.io/cluster-api/internal/util/ssa"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/audit"
 	"sigs.k8s.io/cluster-api/util/collections"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
+	capilabels "sigs.k8s.io/cluster-api/util/labels"
 	"sigs.k8s.io/cluster-api/util/labels/format"
 	clog "sigs.k8s.io/cluster-api/util/log"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/paused"
 	"sigs.k8s.io/cluster-api/util/predicates"
+	"sigs.k8s.io/cluster-api/util/priority"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/requeue"
+	"sigs.k8s.io/cluster-api/util/throttle"
+	"sigs.k8s.io/cluster-api/util/tracing"
 )
 
 var (
@@ -97,27 +106,32 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opt
 		return err
 	}
 
-	err = ctrl.NewControllerManagedBy(mgr).
-		For(&clusterv1.MachineSet{}).
-		Owns(&clusterv1.Machine{}).
+	b := priority.For(ctrl.NewControllerManagedBy(mgr), &clusterv1.MachineSet{})
+	// Status-only changes of the owned Machines are batched, so the status of the MachineSet is not recomputed
+	// and written for every individual change.
+	err = priority.Owns(b, mgr, &clusterv1.MachineSet{}, &clusterv1.Machine{}).
 		// Watches enqueues MachineSet for corresponding Machine resources, if no managed controller reference (owner) exists.
 		Watches(
 			&clusterv1.Machine{},
 			handler.EnqueueRequestsFromMapFunc(r.MachineToMachineSets),
 		).
 		WithOptions(options).
-		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
+		WithEventFilter(predicates.ResourceHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
 		Watches(
 			&clusterv1.Cluster{},
 			handler.EnqueueRequestsFromMapFunc(clusterToMachineSets),
 			builder.WithPredicates(
 				// TODO: should this wait for Cluster.Status.InfrastructureReady similar to Infra Machine resources?
 				predicates.All(ctrl.LoggerFrom(ctx),
-					predicates.ClusterUnpaused(ctrl.LoggerFrom(ctx)),
+					predicates.Any(ctrl.LoggerFrom(ctx),
+						predicates.ClusterPausedTransitions(ctrl.LoggerFrom(ctx)),
+						predicates.ClusterMetadataPropagationChanged(ctrl.LoggerFrom(ctx)),
+					),
 					predicates.ResourceHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue),
 				),
 			),
-		).Complete(r)
+		).Complete(requeue.Reconciler(throttle.Reconciler("machineset", r.Client, &clusterv1.MachineSet{},
+		metrics.InstrumentReconciler("machineset", r.Client, &clusterv1.MachineSet{}, r))))
 	if err != nil {
 		return errors.Wrap(err, "failed setting up with a controller manager")
 	}
@@ -128,6 +142,9 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opt
 }
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx, span := tracing.StartReconcile(ctx, "MachineSet", req)
+	defer func() { tracing.End(span, reterr) }()
+
 	machineSet := &clusterv1.MachineSet{}
 	if err := r.Client.Get(ctx, req.NamespacedName, machineSet); err != nil {
 		if apierrors.IsNotFound(err) {
@@ -154,10 +171,14 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Re
 		return ctrl.Result{}, err
 	}
 
-	// Return early if the object or Cluster is paused.
-	if annotations.IsPaused(cluster, machineSet) {
-		log.Info("Reconciliation is paused for this object")
-		return ctrl.Result{}, nil
+	ctx, log = clog.AddCorrelationID(ctx, cluster)
+
+	// Set the Paused condition, and return early if the object or Cluster is paused.
+	if isPaused, err := paused.EnsurePausedCondition(ctx, r.Client, cluster, machineSet); err != nil || isPaused {
+		if isPaused {
+			log.Info("Reconciliation is paused for this object")
+		}
+		return ctrl.Result{}, err
 	}
 
 	// Initialize the patch helper
@@ -187,7 +208,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Re
 			log.V(5).Info("Requeuing because another worker has the lock on the ClusterCacheTracker")
 			return ctrl.Result{RequeueAfter: time.Minute}, nil
 		}
-		r.recorder.Eventf(machineSet, corev1.EventTypeWarning, "ReconcileError", "%v", err)
+		capirecord.Emit(r.recorder, machineSet, capirecord.ReconcileErrorReason, err)
 	}
 	return result, err
 }
@@ -223,6 +244,9 @@ func (r *Reconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster,
 	}
 	machineSet.Labels[clusterv1.ClusterNameLabel] = machineSet.Spec.ClusterName
 
+	// Propagate the labels and annotations defined in the Cluster's spec.metadataPropagation.
+	capilabels.PropagateClusterMetadata(cluster, machineSet)
+
 	// If the machine set is a stand alone one, meaning not originated from a MachineDeployment, then set it as directly
 	// owned by the Cluster (if not already present).
 	if r.shouldAdopt(machineSet) {
@@ -245,6 +269,10 @@ func (r *Reconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster,
 		}
 	}
 
+	if err := r.reconcileArchitecture(ctx, machineSet); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Make sure selector and template to be in the same cluster.
 	if machineSet.Spec.Selector.MatchLabels == nil {
 		machineSet.Spec.Selector.MatchLabels = make(map[string]string)
@@ -287,11 +315,11 @@ func (r *Reconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster,
 		if metav1.GetControllerOf(machine) == nil {
 			if err := r.adoptOrphan(ctx, machineSet, machine); err != nil {
 				log.Error(err, "Failed to adopt Machine")
-				r.recorder.Eventf(machineSet, corev1.EventTypeWarning, "FailedAdopt", "Failed to adopt Machine %q: %v", machine.Name, err)
+				capirecord.Emit(r.recorder, machineSet, capirecord.FailedAdoptReason, "Machine", klog.KObj(machine), err)
 				continue
 			}
 			log.Info("Adopted Machine")
-			r.recorder.Eventf(machineSet, corev1.EventTypeNormal, "SuccessfulAdopt", "Adopted Machine %q", machine.Name)
+			capirecord.Emit(r.recorder, machineSet, capirecord.SuccessfulAdoptReason, "Machine", klog.KObj(machine))
 		}
 
 		filteredMachines = append(filteredMachines, machine)
@@ -525,7 +553,7 @@ func (r *Reconciler) syncReplicas(ctx context.Context, cluster *clusterv1.Cluste
 			// Create the Machine.
 			if err := ssa.Patch(ctx, r.Client, machineSetManagerName, machine); err != nil {
 				log.Error(err, "Error while creating a machine")
-				r.recorder.Eventf(ms, corev1.EventTypeWarning, "FailedCreate", "Failed to create machine: %v", err)
+				capirecord.Emit(r.recorder, ms, capirecord.FailedCreateReason, "Machine", klog.KObj(machine), err)
 				errs = append(errs, err)
 				conditions.MarkFalse(ms, clusterv1.MachinesCreatedCondition, clusterv1.MachineCreationFailedReason,
 					clusterv1.ConditionSeverityError, err.Error())
@@ -543,7 +571,7 @@ func (r *Reconciler) syncReplicas(ctx context.Context, cluster *clusterv1.Cluste
 			}
 
 			log.Info(fmt.Sprintf("Created machine %d of %d", i+1, diff), "Machine", klog.KObj(machine))
-			r.recorder.Eventf(ms, corev1.EventTypeNormal, "SuccessfulCreate", "Created machine %q", machine.Name)
+			capirecord.Emit(r.recorder, ms, capirecord.SuccessfulCreateReason, "Machine", klog.KObj(machine))
 			machineList = append(machineList, machine)
 		}
 
@@ -565,13 +593,21 @@ func (r *Reconciler) syncReplicas(ctx context.Context, cluster *clusterv1.Cluste
 			log := log.WithValues("Machine", klog.KObj(machine))
 			if machine.GetDeletionTimestamp().IsZero() {
 				log.Info(fmt.Sprintf("Deleting machine %d of %d", i+1, diff))
+				if err := audit.RecordMachineDecision(ctx, r.Client, r.recorder, machine, audit.Decision{
+					Action:     audit.DeleteAction,
+					Reason:     fmt.Sprintf("MachineSet %s is scaling down to %d replicas (delete policy %q)", klog.KObj(ms), *(ms.Spec.Replicas), ms.Spec.DeletePolicy),
+					Controller: "machineset",
+				}); err != nil {
+					errs = append(errs, err)
+					continue
+				}
 				if err := r.Client.Delete(ctx, machine); err != nil {
 					log.Error(err, "Unable to delete Machine")
-					r.recorder.Eventf(ms, corev1.EventTypeWarning, "FailedDelete", "Failed to delete machine %q: %v", machine.Name, err)
+					capirecord.Emit(r.recorder, ms, capirecord.FailedDeleteReason, "Machine", klog.KObj(machine), err)
 					errs = append(errs, err)
 					continue
 				}
-				r.recorder.Eventf(ms, corev1.EventTypeNormal, "SuccessfulDelete", "Deleted machine %q", machine.Name)
+				capirecord.Emit(r.recorder, ms, capirecord.SuccessfulDeleteReason, "Machine", klog.KObj(machine))
 			} else {
 				log.Info(fmt.Sprintf("Waiting for machine %d of %d to be deleted", i+1, diff))
 			}
@@ -675,14 +711,11 @@ func (r *Reconciler) updateExternalObject(ctx context.Context, obj client.Object
 
 // machineLabelsFromMachineSet computes the labels the Machine created from this MachineSet should have.
 func machineLabelsFromMachineSet(machineSet *clusterv1.MachineSet) map[string]string {
-	machineLabels := map[string]string{}
 	// Note: We can't just set `machineSet.Spec.Template.Labels` directly and thus "share" the labels
 	// map between Machine and machineSet.Spec.Template.Labels. This would mean that adding the
 	// MachineSetNameLabel and MachineDeploymentNameLabel later on the Machine would also add the labels
 	// to machineSet.Spec.Template.Labels and thus modify the labels of the MachineSet.
-	for k, v := range machineSet.Spec.Template.Labels {
-		machineLabels[k] = v
-	}
+	machineLabels := capilabels.TemplateToObject.Select(machineSet.Spec.Template.Labels)
 	// Always set the MachineSetNameLabel.
 	// Note: If a client tries to create a MachineSet without a selector, the MachineSet webhook
 	// will add this label automatically. But we want this label to always be present even if the MachineSet
@@ -696,12 +729,57 @@ func machineLabelsFromMachineSet(machineSet *clusterv1.MachineSet) map[string]st
 }
 
 // machineAnnotationsFromMachineSet computes the annotations the Machine created from this MachineSet should have.
+// The architecture of the MachineSet is propagated if it is not set in the template.
 func machineAnnotationsFromMachineSet(machineSet *clusterv1.MachineSet) map[string]string {
-	annotations := map[string]string{}
-	for k, v := range machineSet.Spec.Template.Annotations {
-		annotations[k] = v
+	machineAnnotations := capilabels.TemplateToObject.Select(machineSet.Spec.Template.Annotations)
+	if arch, ok := machineSet.Annotations[clusterv1.MachineArchitectureAnnotation]; ok {
+		if _, ok := machineAnnotations[clusterv1.MachineArchitectureAnnotation]; !ok {
+			if machineAnnotations == nil {
+				machineAnnotations = map[string]string{}
+			}
+			machineAnnotations[clusterv1.MachineArchitectureAnnotation] = arch
+		}
+	}
+	return machineAnnotations
+}
+
+// reconcileArchitecture sets the architecture annotation on the MachineSet, if not already set, from the template
+// annotations or from status.nodeInfo.architecture of the infrastructure machine template.
+// If the architecture is known, it is also added to the autoscaler capacity labels used when scaling from zero,
+// unless they are already set. MachineSets owned by a MachineDeployment are not node groups of the autoscaler, so
+// the capacity labels are set on the MachineDeployment by the MachineDeployment controller instead.
+func (r *Reconciler) reconcileArchitecture(ctx context.Context, machineSet *clusterv1.MachineSet) error {
+	arch := machineSet.Annotations[clusterv1.MachineArchitectureAnnotation]
+	if arch == "" {
+		arch = machineSet.Spec.Template.Annotations[clusterv1.MachineArchitectureAnnotation]
+	}
+	if arch == "" && strings.HasSuffix(machineSet.Spec.Template.Spec.InfrastructureRef.Kind, clusterv1.TemplateSuffix) {
+		template, err := external.Get(ctx, r.UnstructuredCachingClient, &machineSet.Spec.Template.Spec.InfrastructureRef, machineSet.Namespace)
+		if err != nil {
+			return err
+		}
+		templateArch, err := contract.InfrastructureMachineTemplate().NodeInfo().Architecture().Get(template)
+		if err != nil && !errors.Is(err, contract.ErrFieldNotFound) {
+			return errors.Wrapf(err, "failed to get architecture from %s %s", template.GetKind(), klog.KObj(template))
+		}
+		if templateArch != nil {
+			arch = *templateArch
+		}
+	}
+	if arch == "" {
+		return nil
+	}
+
+	newAnnotations := map[string]string{clusterv1.MachineArchitectureAnnotation: arch}
+	if owner := metav1.GetControllerOf(machineSet); owner != nil && owner.Kind == "MachineDeployment" {
+		annotations.AddAnnotations(machineSet, newAnnotations)
+		return nil
+	}
+	if _, ok := machineSet.Annotations[clusterv1.AutoscalerCapacityLabelsAnnotation]; !ok {
+		newAnnotations[clusterv1.AutoscalerCapacityLabelsAnnotation] = fmt.Sprintf("%s=%s", corev1.LabelArchStable, arch)
 	}
-	return annotations
+	annotations.AddAnnotations(machineSet, newAnnotations)
+	return nil
 }
 
 // shouldExcludeMachine returns true if the machine should be filtered out, false otherwise.
@@ -869,6 +947,9 @@ func (r *Reconciler) updateStatus(ctx context.Context, cluster *clusterv1.Cluste
 	desiredReplicas := *ms.Spec.Replicas
 	templateLabel := labels.Set(ms.Spec.Template.Labels).AsSelectorPreValidated()
 
+	// The state of the Machines is aggregated from the informer cache, and the state of their Nodes from the cache of
+	// the workload cluster, using a single client for all the Machines.
+	var remoteClient client.Reader
 	for _, machine := range filteredMachines {
 		log := log.WithValues("Machine", klog.KObj(machine))
 
@@ -881,7 +962,17 @@ func (r *Reconciler) updateStatus(ctx context.Context, cluster *clusterv1.Cluste
 			continue
 		}
 
-		node, err := r.getMachineNode(ctx, cluster, machine)
+		if remoteClient == nil {
+			remoteClient, err = r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
+			if err != nil {
+				// Note: the replica counts are not updated if the state of the Nodes can't be read, instead of
+				// reporting all the Machines as not ready, which would write a transient status to be reverted
+				// on the next reconcile.
+				return errors.Wrapf(err, "failed to update status for MachineSet %s/%s", ms.Namespace, ms.Name)
+			}
+		}
+
+		node, err := getMachineNode(ctx, remoteClient, machine)
 		if err != nil && machine.GetDeletionTimestamp().IsZero() {
 			log.Error(err, "Unable to retrieve Node status", "node", klog.KObj(node))
 			continue
@@ -950,11 +1041,7 @@ func (r *Reconciler) updateStatus(ctx context.Context, cluster *clusterv1.Cluste
 	return nil
 }
 
-func (r *Reconciler) getMachineNode(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine) (*corev1.Node, error) {
-	remoteClient, err := r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
-	if err != nil {
-		return nil, err
-	}
+func getMachineNode(ctx context.Context, remoteClient client.Reader, machine *clusterv1.Machine) (*corev1.Node, error) {
 	node := &corev1.Node{}
 	if err := remoteClient.Get(ctx, client.ObjectKey{Name: machine.Status.NodeRef.Name}, node); err != nil {
 		return nil, errors.Wrapf(err, "error retrieving node %s for machine %s/%s", machine.Status.NodeRef.Name, machine.Namespace, machine.Name)
@@ -1016,6 +1103,14 @@ func (r *Reconciler) reconcileUnhealthyMachines(ctx context.Context, cluster *cl
 	var errs []error
 	for _, m := range machinesToRemediate {
 		log.Info(fmt.Sprintf("Deleting Machine %s because it was marked as unhealthy by the MachineHealthCheck controller", klog.KObj(m)))
+		if err := audit.RecordMachineDecision(ctx, r.Client, r.recorder, m, audit.Decision{
+			Action:     audit.RemediateAction,
+			Reason:     "Machine was marked as unhealthy by the MachineHealthCheck controller",
+			Controller: "machineset",
+		}); err != nil {
+			errs = append(errs, err)
+			continue
+		}
 		patch := client.MergeFrom(m.DeepCopy())
 		if err := r.Client.Delete(ctx, m); err != nil {
 			errs = append(errs, errors.Wrapf(err, "failed to delete Machine %s", klog.KObj(m)))
@@ -20,20 +20,24 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
 	utilfeature "k8s.io/component-base/featuregate/testing"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/controllers/remote"
 	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/internal/contract"
 	"sigs.k8s.io/cluster-api/internal/test/builder"
@@ -973,6 +977,80 @@ func TestMachineSetReconciler_updateStatusResizedCondition(t *testing.T) {
 	}
 }
 
+func TestMachineSetReconciler_updateStatusReplicas(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: metav1.NamespaceDefault,
+		},
+	}
+	machine := func(name string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: metav1.NamespaceDefault,
+			},
+			Status: clusterv1.MachineStatus{
+				NodeRef: &corev1.ObjectReference{Name: name},
+			},
+		}
+	}
+	node := func(name string, ready corev1.ConditionStatus) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{
+					Type:               corev1.NodeReady,
+					Status:             ready,
+					LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+				}},
+			},
+		}
+	}
+	machines := []*clusterv1.Machine{machine("machine-a"), machine("machine-b"), machine("machine-c")}
+
+	t.Run("counts the ready and available replicas from the Nodes of the workload cluster", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewClientBuilder().Build()
+		remoteClient := fake.NewClientBuilder().WithObjects(
+			node("machine-a", corev1.ConditionTrue),
+			node("machine-b", corev1.ConditionFalse),
+		).Build()
+		msr := &Reconciler{
+			Client:   c,
+			Tracker:  remote.NewTestClusterCacheTracker(logr.New(log.NullLogSink{}), c, remoteClient, scheme.Scheme, util.ObjectKey(cluster)),
+			recorder: record.NewFakeRecorder(32),
+		}
+
+		ms := newMachineSet("ms", cluster.Name, int32(3))
+		g.Expect(msr.updateStatus(ctx, cluster, ms, machines)).To(Succeed())
+		g.Expect(ms.Status.Replicas).To(Equal(int32(3)))
+		g.Expect(ms.Status.ReadyReplicas).To(Equal(int32(1)))
+		g.Expect(ms.Status.AvailableReplicas).To(Equal(int32(1)))
+	})
+
+	t.Run("does not change the replicas if the workload cluster can't be accessed", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewClientBuilder().Build()
+		otherCluster := client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "other"}
+		msr := &Reconciler{
+			Client:   c,
+			Tracker:  remote.NewTestClusterCacheTracker(logr.New(log.NullLogSink{}), c, c, scheme.Scheme, otherCluster),
+			recorder: record.NewFakeRecorder(32),
+		}
+
+		ms := newMachineSet("ms", cluster.Name, int32(3))
+		ms.Status.Replicas = 3
+		ms.Status.ReadyReplicas = 3
+		ms.Status.AvailableReplicas = 3
+		original := ms.Status.DeepCopy()
+		g.Expect(msr.updateStatus(ctx, cluster, ms, machines)).ToNot(Succeed())
+		g.Expect(ms.Status).To(BeComparableTo(*original))
+	})
+}
+
 func TestMachineSetReconciler_syncMachines(t *testing.T) {
 	setup := func(t *testing.T, g *WithT) (*corev1.Namespace, *clusterv1.Cluster) {
 		t.Helper()
@@ -1692,3 +1770,66 @@ func assertMachine(g *WithT, actualMachine *clusterv1.Machine, expectedMachine *
 		g.Expect(actualMachine.Finalizers).Should(Equal(expectedMachine.Finalizers))
 	}
 }
+
+func TestMachineSetReconciler_reconcileArchitecture(t *testing.T) {
+	infraTmpl := builder.InfrastructureMachineTemplate("default", "infra-template").Build()
+	g := NewWithT(t)
+	g.Expect(unstructured.SetNestedField(infraTmpl.Object, "arm64", "status", "nodeInfo", "architecture")).To(Succeed())
+
+	newMachineSet := func() *clusterv1.MachineSet {
+		return &clusterv1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-machineset",
+				Namespace: "default",
+			},
+			Spec: clusterv1.MachineSetSpec{
+				Template: clusterv1.MachineTemplateSpec{
+					Spec: clusterv1.MachineSpec{
+						InfrastructureRef: *contract.ObjToRef(infraTmpl),
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("architecture from the infrastructure machine template", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r := &Reconciler{UnstructuredCachingClient: fake.NewClientBuilder().WithObjects(infraTmpl.DeepCopy()).Build()}
+		machineSet := newMachineSet()
+		g.Expect(r.reconcileArchitecture(ctx, machineSet)).To(Succeed())
+		g.Expect(machineSet.Annotations).To(Equal(map[string]string{
+			clusterv1.MachineArchitectureAnnotation:      "arm64",
+			clusterv1.AutoscalerCapacityLabelsAnnotation: "kubernetes.io/arch=arm64",
+		}))
+		g.Expect(machineAnnotationsFromMachineSet(machineSet)).To(HaveKeyWithValue(clusterv1.MachineArchitectureAnnotation, "arm64"))
+	})
+
+	t.Run("architecture set in the template takes precedence and capacity labels are preserved", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r := &Reconciler{UnstructuredCachingClient: fake.NewClientBuilder().WithObjects(infraTmpl.DeepCopy()).Build()}
+		machineSet := newMachineSet()
+		machineSet.Annotations = map[string]string{clusterv1.AutoscalerCapacityLabelsAnnotation: "foo=bar"}
+		machineSet.Spec.Template.Annotations = map[string]string{clusterv1.MachineArchitectureAnnotation: "amd64"}
+		g.Expect(r.reconcileArchitecture(ctx, machineSet)).To(Succeed())
+		g.Expect(machineSet.Annotations).To(Equal(map[string]string{
+			clusterv1.MachineArchitectureAnnotation:      "amd64",
+			clusterv1.AutoscalerCapacityLabelsAnnotation: "foo=bar",
+		}))
+	})
+
+	t.Run("capacity labels are not set on MachineSets owned by a MachineDeployment", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r := &Reconciler{UnstructuredCachingClient: fake.NewClientBuilder().WithObjects(infraTmpl.DeepCopy()).Build()}
+		machineSet := newMachineSet()
+		machineSet.OwnerReferences = []metav1.OwnerReference{
+			*metav1.NewControllerRef(&clusterv1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Name: "md"}}, clusterv1.GroupVersion.WithKind("MachineDeployment")),
+		}
+		g.Expect(r.reconcileArchitecture(ctx, machineSet)).To(Succeed())
+		g.Expect(machineSet.Annotations).To(Equal(map[string]string{
+			clusterv1.MachineArchitectureAnnotation: "arm64",
+		}))
+	})
+}
@@ -47,12 +47,18 @@ import (
 	"sigs.k8s.io/cluster-api/internal/hooks"
 	tlog "sigs.k8s.io/cluster-api/internal/log"
 	runtimeclient "sigs.k8s.io/cluster-api/internal/runtime/client"
+	"sigs.k8s.io/cluster-api/internal/util/metrics"
 	"sigs.k8s.io/cluster-api/internal/util/ssa"
 	"sigs.k8s.io/cluster-api/internal/webhooks"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
+	clog "sigs.k8s.io/cluster-api/util/log"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
+	"sigs.k8s.io/cluster-api/util/priority"
+	"sigs.k8s.io/cluster-api/util/requeue"
+	"sigs.k8s.io/cluster-api/util/throttle"
+	"sigs.k8s.io/cluster-api/util/tracing"
 )
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io;bootstrap.cluster.x-k8s.io;controlplane.cluster.x-k8s.io,resources=*,verbs=get;list;watch;create;update;patch;delete
@@ -91,11 +97,10 @@ type Reconciler struct {
 }
 
 func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
-	c, err := ctrl.NewControllerManagedBy(mgr).
-		For(&clusterv1.Cluster{}, builder.WithPredicates(
-			// Only reconcile Cluster with topology.
-			predicates.ClusterHasTopology(ctrl.LoggerFrom(ctx)),
-		)).
+	c, err := priority.For(ctrl.NewControllerManagedBy(mgr), &clusterv1.Cluster{},
+		// Only reconcile Cluster with topology.
+		predicates.ClusterHasTopology(ctrl.LoggerFrom(ctx)),
+	).
 		Named("topology/cluster").
 		Watches(
 			&clusterv1.ClusterClass{},
@@ -115,7 +120,8 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opt
 		).
 		WithOptions(options).
 		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
-		Build(r)
+		Build(requeue.Reconciler(throttle.Reconciler("topology/cluster", r.Client, &clusterv1.Cluster{},
+			metrics.InstrumentReconciler("topology/cluster", r.Client, &clusterv1.Cluster{}, r))))
 
 	if err != nil {
 		return errors.Wrap(err, "failed setting up with a controller manager")
@@ -141,6 +147,9 @@ func (r *Reconciler) SetupForDryRun(recorder record.EventRecorder) {
 }
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx, span := tracing.StartReconcile(ctx, "ClusterTopology", req)
+	defer func() { tracing.End(span, reterr) }()
+
 	log := ctrl.LoggerFrom(ctx)
 
 	// Fetch the Cluster instance.
@@ -155,6 +164,8 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Re
 	cluster.APIVersion = clusterv1.GroupVersion.String()
 	cluster.Kind = "Cluster"
 
+	ctx, log = clog.AddCorrelationID(ctx, cluster)
+
 	// Return early, if the Cluster does not use a managed topology.
 	// NOTE: We're already filtering events, but this is a safeguard for cases like e.g. when
 	// there are MachineDeployments which have the topology owned label, but the corresponding
@@ -272,13 +283,18 @@ func (r *Reconciler) reconcile(ctx context.Context, s *scope.Scope) (ctrl.Result
 	}
 
 	// Computes the desired state of the Cluster and store it in the request scope.
-	s.Desired, err = r.desiredStateGenerator.Generate(ctx, s)
+	generateCtx, span := tracing.Start(ctx, "computeDesiredState")
+	s.Desired, err = r.desiredStateGenerator.Generate(generateCtx, s)
+	tracing.End(span, err)
 	if err != nil {
 		return ctrl.Result{}, errors.Wrap(err, "error computing the desired state of the Cluster topology")
 	}
 
 	// Reconciles current and desired state of the Cluster
-	if err := r.reconcileState(ctx, s); err != nil {
+	reconcileStateCtx, span := tracing.Start(ctx, "reconcileState")
+	err = r.reconcileState(reconcileStateCtx, s)
+	tracing.End(span, err)
+	if err != nil {
 		return ctrl.Result{}, errors.Wrap(err, "error reconciling the Cluster topology")
 	}
 
@@ -288,6 +304,11 @@ func (r *Reconciler) reconcile(ctx context.Context, s *scope.Scope) (ctrl.Result
 		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
+	// Requeue when the next rollout window starts if the upgrade has been deferred.
+	if s.UpgradeTracker.ControlPlane.IsRolloutDeferred && !s.UpgradeTracker.ControlPlane.NextRolloutWindowStart.IsZero() {
+		return ctrl.Result{RequeueAfter: time.Until(s.UpgradeTracker.ControlPlane.NextRolloutWindowStart)}, nil
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -19,6 +19,7 @@ package cluster
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -127,6 +128,12 @@ func (r *Reconciler) reconcileTopologyReconciledCondition(s *scope.Scope, cluste
 		// occur at the same time). Find better wording and `Reason` for the condition so that the condition can be rich
 		// with all the relevant information.
 		switch {
+		case s.UpgradeTracker.ControlPlane.IsRolloutDeferred:
+			fmt.Fprintf(msgBuilder, "Control plane rollout and upgrade to version %s deferred until the next rollout window starting at %s.",
+				s.Blueprint.Topology.Version,
+				s.UpgradeTracker.ControlPlane.NextRolloutWindowStart.UTC().Format(time.RFC3339),
+			)
+			reason = clusterv1.TopologyReconciledRolloutDeferredReason
 		case s.UpgradeTracker.ControlPlane.IsPendingUpgrade:
 			fmt.Fprintf(msgBuilder, "Control plane rollout and upgrade to version %s on hold.", s.Blueprint.Topology.Version)
 			reason = clusterv1.TopologyReconciledControlPlaneUpgradePendingReason
@@ -18,6 +18,7 @@ package cluster
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
@@ -167,6 +168,38 @@ func TestReconcileTopologyReconciledCondition(t *testing.T) {
 			wantConditionReason:  clusterv1.TopologyReconciledControlPlaneUpgradePendingReason,
 			wantConditionMessage: "Control plane rollout and upgrade to version v1.22.0 on hold. Control plane is upgrading to version v1.21.2",
 		},
+		{
+			name:         "should set the condition to false if new version is not picked up because the rollout is deferred",
+			reconcileErr: nil,
+			cluster:      &clusterv1.Cluster{},
+			s: &scope.Scope{
+				Blueprint: &scope.ClusterBlueprint{
+					Topology: &clusterv1.Topology{
+						Version: "v1.22.0",
+					},
+				},
+				Current: &scope.ClusterState{
+					Cluster: &clusterv1.Cluster{},
+					ControlPlane: &scope.ControlPlaneState{
+						Object: builder.ControlPlane("ns1", "controlplane1").
+							WithVersion("v1.21.2").
+							WithReplicas(3).
+							Build(),
+					},
+				},
+				UpgradeTracker: func() *scope.UpgradeTracker {
+					ut := scope.NewUpgradeTracker()
+					ut.ControlPlane.IsPendingUpgrade = true
+					ut.ControlPlane.IsRolloutDeferred = true
+					ut.ControlPlane.NextRolloutWindowStart = time.Date(2024, 3, 4, 22, 0, 0, 0, time.UTC)
+					return ut
+				}(),
+				HookResponseTracker: scope.NewHookResponseTracker(),
+			},
+			wantConditionStatus:  corev1.ConditionFalse,
+			wantConditionReason:  clusterv1.TopologyReconciledRolloutDeferredReason,
+			wantConditionMessage: "Control plane rollout and upgrade to version v1.22.0 deferred until the next rollout window starting at 2024-03-04T22:00:00Z.",
+		},
 		{
 			name:         "should set the condition to false if new version is not picked up because control plane is scaling",
 			reconcileErr: nil,
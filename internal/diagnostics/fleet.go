@@ -0,0 +1,217 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diagnostics implements the diagnostics endpoints served by the Cluster API manager.
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/internal/util/metrics"
+	"sigs.k8s.io/cluster-api/util/annotations"
+)
+
+// FleetSummaryPath is the path of the diagnostics endpoint serving the FleetSummary.
+const FleetSummaryPath = "/debug/fleet"
+
+// StuckConditionThreshold is the time after which a condition which is not true is reported as stuck.
+var StuckConditionThreshold = 15 * time.Minute
+
+// FleetSummary is a summary of the state of all the Clusters managed by the manager.
+type FleetSummary struct {
+	// Time is when the summary was computed.
+	Time metav1.Time `json:"time"`
+
+	// Clusters are the summaries of the Clusters, sorted by namespace and name.
+	Clusters []ClusterSummary `json:"clusters"`
+}
+
+// ClusterSummary is a summary of the state of a Cluster.
+type ClusterSummary struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Phase     string `json:"phase,omitempty"`
+	Paused    bool   `json:"paused"`
+
+	// StuckConditions are the conditions of the Cluster which are not true since more than StuckConditionThreshold.
+	StuckConditions []ConditionSummary `json:"stuckConditions,omitempty"`
+
+	// Rollouts are the rollouts in progress for the Cluster.
+	Rollouts []RolloutSummary `json:"rollouts,omitempty"`
+
+	// LastReconcileErrors are the errors returned by the last reconcile of the objects belonging to the Cluster.
+	LastReconcileErrors []metrics.ReconcileError `json:"lastReconcileErrors,omitempty"`
+}
+
+// ConditionSummary is a summary of a condition.
+type ConditionSummary struct {
+	Type               clusterv1.ConditionType `json:"type"`
+	Status             corev1.ConditionStatus  `json:"status"`
+	Reason             string                  `json:"reason,omitempty"`
+	Message            string                  `json:"message,omitempty"`
+	LastTransitionTime metav1.Time             `json:"lastTransitionTime,omitempty"`
+}
+
+// RolloutSummary is a summary of a rollout in progress.
+type RolloutSummary struct {
+	Kind            string `json:"kind"`
+	Name            string `json:"name"`
+	Replicas        int32  `json:"replicas"`
+	UpdatedReplicas int32  `json:"updatedReplicas"`
+}
+
+// FleetSummaryHandler is a http.Handler serving the FleetSummary as JSON.
+// It reads objects through the client set with SetClient, which is expected to be backed by the manager cache,
+// so serving the summary does not issue list calls against the API server; until the client is set,
+// the handler responds with 503 Service Unavailable.
+// The handler is meant to be served by the diagnostics endpoint, which is authenticated and authorized.
+type FleetSummaryHandler struct {
+	lock   sync.RWMutex
+	client client.Reader
+}
+
+// NewFleetSummaryHandler returns a new FleetSummaryHandler.
+func NewFleetSummaryHandler() *FleetSummaryHandler {
+	return &FleetSummaryHandler{}
+}
+
+// SetClient sets the client used to read the objects; it is set after the handler is created because the
+// diagnostics handlers must be passed to the manager before the manager and its cache are created.
+func (h *FleetSummaryHandler) SetClient(c client.Reader) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.client = c
+}
+
+func (h *FleetSummaryHandler) getClient() client.Reader {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.client
+}
+
+// ServeHTTP serves the FleetSummary as JSON.
+func (h *FleetSummaryHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	c := h.getClient()
+	if c == nil {
+		http.Error(w, "fleet summary not available yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	summary, err := GetFleetSummary(req.Context(), c)
+	if err != nil {
+		ctrl.LoggerFrom(req.Context()).Error(err, "Failed to compute fleet summary")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		ctrl.LoggerFrom(req.Context()).Error(err, "Failed to write fleet summary")
+	}
+}
+
+// GetFleetSummary computes the FleetSummary.
+func GetFleetSummary(ctx context.Context, c client.Reader) (*FleetSummary, error) {
+	clusterList := &clusterv1.ClusterList{}
+	if err := c.List(ctx, clusterList); err != nil {
+		return nil, errors.Wrap(err, "failed to list Clusters")
+	}
+	mdList := &clusterv1.MachineDeploymentList{}
+	if err := c.List(ctx, mdList); err != nil {
+		return nil, errors.Wrap(err, "failed to list MachineDeployments")
+	}
+
+	rollouts := map[types.NamespacedName][]RolloutSummary{}
+	for i := range mdList.Items {
+		md := &mdList.Items[i]
+		if !isRollingOut(md) {
+			continue
+		}
+		cluster := types.NamespacedName{Namespace: md.Namespace, Name: md.Spec.ClusterName}
+		rollouts[cluster] = append(rollouts[cluster], RolloutSummary{
+			Kind:            "MachineDeployment",
+			Name:            md.Name,
+			Replicas:        ptr.Deref(md.Spec.Replicas, 0),
+			UpdatedReplicas: md.Status.UpdatedReplicas,
+		})
+	}
+
+	now := time.Now()
+	summary := &FleetSummary{
+		Time:     metav1.NewTime(now),
+		Clusters: make([]ClusterSummary, 0, len(clusterList.Items)),
+	}
+	for i := range clusterList.Items {
+		cluster := &clusterList.Items[i]
+		key := client.ObjectKeyFromObject(cluster)
+		clusterSummary := ClusterSummary{
+			Namespace:           cluster.Namespace,
+			Name:                cluster.Name,
+			Phase:               cluster.Status.Phase,
+			Paused:              annotations.IsPaused(cluster, cluster),
+			Rollouts:            rollouts[key],
+			LastReconcileErrors: metrics.LastReconcileErrors(key.String()),
+		}
+		for _, condition := range cluster.Status.Conditions {
+			if condition.Status == corev1.ConditionTrue || now.Sub(condition.LastTransitionTime.Time) < StuckConditionThreshold {
+				continue
+			}
+			clusterSummary.StuckConditions = append(clusterSummary.StuckConditions, ConditionSummary{
+				Type:               condition.Type,
+				Status:             condition.Status,
+				Reason:             condition.Reason,
+				Message:            condition.Message,
+				LastTransitionTime: condition.LastTransitionTime,
+			})
+		}
+		summary.Clusters = append(summary.Clusters, clusterSummary)
+	}
+	sort.Slice(summary.Clusters, func(i, j int) bool {
+		if summary.Clusters[i].Namespace != summary.Clusters[j].Namespace {
+			return summary.Clusters[i].Namespace < summary.Clusters[j].Namespace
+		}
+		return summary.Clusters[i].Name < summary.Clusters[j].Name
+	})
+	return summary, nil
+}
+
+// isRollingOut returns true if the MachineDeployment has not yet observed its latest spec, or if not all of its
+// replicas are up-to-date.
+func isRollingOut(md *clusterv1.MachineDeployment) bool {
+	if md.Status.ObservedGeneration < md.Generation {
+		return true
+	}
+	replicas := ptr.Deref(md.Spec.Replicas, 0)
+	return md.Status.UpdatedReplicas < replicas || md.Status.Replicas > md.Status.UpdatedReplicas
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestFleetSummaryHandler(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	oldTransition := metav1.NewTime(time.Now().Add(-time.Hour))
+	recentTransition := metav1.NewTime(time.Now().Add(-time.Minute))
+	objs := []runtime.Object{
+		&clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "b"},
+			Spec:       clusterv1.ClusterSpec{Paused: true},
+			Status: clusterv1.ClusterStatus{
+				Phase: string(clusterv1.ClusterPhaseProvisioned),
+				Conditions: clusterv1.Conditions{
+					{Type: clusterv1.ReadyCondition, Status: corev1.ConditionFalse, Reason: "Foo", LastTransitionTime: oldTransition},
+					{Type: clusterv1.ControlPlaneReadyCondition, Status: corev1.ConditionFalse, LastTransitionTime: recentTransition},
+					{Type: clusterv1.InfrastructureReadyCondition, Status: corev1.ConditionTrue, LastTransitionTime: oldTransition},
+				},
+			},
+		},
+		&clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "a"},
+		},
+		&clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "md-rolling-out"},
+			Spec:       clusterv1.MachineDeploymentSpec{ClusterName: "b", Replicas: ptr.To[int32](3)},
+			Status:     clusterv1.MachineDeploymentStatus{Replicas: 4, UpdatedReplicas: 1},
+		},
+		&clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "md-up-to-date"},
+			Spec:       clusterv1.MachineDeploymentSpec{ClusterName: "b", Replicas: ptr.To[int32](3)},
+			Status:     clusterv1.MachineDeploymentStatus{Replicas: 3, UpdatedReplicas: 3},
+		},
+	}
+
+	h := NewFleetSummaryHandler()
+
+	// The handler is not available until the client is set.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, FleetSummaryPath, http.NoBody))
+	g.Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+
+	h.SetClient(fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build())
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, FleetSummaryPath, http.NoBody))
+	g.Expect(rec.Code).To(Equal(http.StatusOK))
+
+	summary := &FleetSummary{}
+	g.Expect(json.Unmarshal(rec.Body.Bytes(), summary)).To(Succeed())
+	g.Expect(summary.Clusters).To(HaveLen(2))
+	g.Expect(summary.Clusters[0].Name).To(Equal("a"))
+	g.Expect(summary.Clusters[0].Paused).To(BeFalse())
+	g.Expect(summary.Clusters[0].StuckConditions).To(BeEmpty())
+	g.Expect(summary.Clusters[0].Rollouts).To(BeEmpty())
+
+	b := summary.Clusters[1]
+	g.Expect(b.Name).To(Equal("b"))
+	g.Expect(b.Phase).To(Equal(string(clusterv1.ClusterPhaseProvisioned)))
+	g.Expect(b.Paused).To(BeTrue())
+	g.Expect(b.StuckConditions).To(HaveLen(1))
+	g.Expect(b.StuckConditions[0].Type).To(Equal(clusterv1.ReadyCondition))
+	g.Expect(b.StuckConditions[0].Reason).To(Equal("Foo"))
+	g.Expect(b.Rollouts).To(Equal([]RolloutSummary{{Kind: "MachineDeployment", Name: "md-rolling-out", Replicas: 3, UpdatedReplicas: 1}}))
+
+	// Only GET is allowed.
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, FleetSummaryPath, http.NoBody))
+	g.Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+}
@@ -39,6 +39,7 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/transport"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -50,6 +51,7 @@ import (
 	runtimemetrics "sigs.k8s.io/cluster-api/internal/runtime/metrics"
 	runtimeregistry "sigs.k8s.io/cluster-api/internal/runtime/registry"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/identity"
 )
 
 type errCallingExtensionHandler error
@@ -61,14 +63,27 @@ type Options struct {
 	Catalog  *runtimecatalog.Catalog
 	Registry runtimeregistry.ExtensionRegistry
 	Client   ctrlclient.Client
+
+	// TokenSource, if set, provides the token presented as bearer token to the extensions
+	// to prove the identity of the controllers calling them.
+	TokenSource identity.TokenSource
+
+	// ServiceProxy, if set, is the config for the API server used for calling the extensions exposed by a Service
+	// through the API server service proxy, instead of calling the Service directly; this allows calling the
+	// extensions from outside the cluster, e.g. from clusterctl.
+	// NOTE: ServiceProxy cannot be used together with TokenSource, because the API server consumes the
+	// Authorization header of the requests.
+	ServiceProxy *rest.Config
 }
 
 // New returns a new Client.
 func New(options Options) Client {
 	return &client{
-		catalog:  options.Catalog,
-		registry: options.Registry,
-		client:   options.Client,
+		catalog:      options.Catalog,
+		registry:     options.Registry,
+		client:       options.Client,
+		tokenSource:  options.TokenSource,
+		serviceProxy: options.ServiceProxy,
 	}
 }
 
@@ -102,9 +117,11 @@ type Client interface {
 var _ Client = &client{}
 
 type client struct {
-	catalog  *runtimecatalog.Catalog
-	registry runtimeregistry.ExtensionRegistry
-	client   ctrlclient.Client
+	catalog      *runtimecatalog.Catalog
+	registry     runtimeregistry.ExtensionRegistry
+	client       ctrlclient.Client
+	tokenSource  identity.TokenSource
+	serviceProxy *rest.Config
 }
 
 func (c *client) WarmUp(extensionConfigList *runtimev1.ExtensionConfigList) error {
@@ -132,6 +149,8 @@ func (c *client) Discover(ctx context.Context, extensionConfig *runtimev1.Extens
 		registrationGVH: hookGVH,
 		hookGVH:         hookGVH,
 		timeout:         defaultDiscoveryTimeout,
+		tokenSource:     c.tokenSource,
+		serviceProxy:    c.serviceProxy,
 	}
 	if err := httpCall(ctx, request, response, opts); err != nil {
 		return nil, errors.Wrapf(err, "failed to discover extension %q", extensionConfig.Name)
@@ -338,6 +357,8 @@ func (c *client) CallExtension(ctx context.Context, hook runtimecatalog.Hook, fo
 		hookGVH:         hookGVH,
 		name:            strings.TrimSuffix(registration.Name, "."+registration.ExtensionConfigName),
 		timeout:         timeoutDuration,
+		tokenSource:     c.tokenSource,
+		serviceProxy:    c.serviceProxy,
 	}
 	err = httpCall(ctx, request, response, opts)
 	if err != nil {
@@ -397,6 +418,8 @@ type httpCallOptions struct {
 	hookGVH         runtimecatalog.GroupVersionHook
 	name            string
 	timeout         time.Duration
+	tokenSource     identity.TokenSource
+	serviceProxy    *rest.Config
 }
 
 func httpCall(ctx context.Context, request, response runtime.Object, opts *httpCallOptions) error {
@@ -408,7 +431,18 @@ func httpCall(ctx context.Context, request, response runtime.Object, opts *httpC
 		return errors.New("http call failed: opts.Catalog cannot be nil")
 	}
 
-	extensionURL, err := urlForExtension(opts.config, opts.registrationGVH, opts.name)
+	useServiceProxy := opts.serviceProxy != nil && opts.config.Service != nil
+	if useServiceProxy && opts.tokenSource != nil {
+		return errors.New("http call failed: opts.serviceProxy and opts.tokenSource cannot be used together")
+	}
+
+	var extensionURL *url.URL
+	var err error
+	if useServiceProxy {
+		extensionURL, err = serviceProxyURLForExtension(opts.serviceProxy, opts.config, opts.registrationGVH, opts.name)
+	} else {
+		extensionURL, err = urlForExtension(opts.config, opts.registrationGVH, opts.name)
+	}
 	if err != nil {
 		return errors.Wrap(err, "http call failed")
 	}
@@ -474,22 +508,37 @@ func httpCall(ctx context.Context, request, response runtime.Object, opts *httpC
 	if err != nil {
 		return errors.Wrap(err, "http call failed: failed to create http request")
 	}
+	if opts.tokenSource != nil {
+		token, err := opts.tokenSource.Token()
+		if err != nil {
+			return errors.Wrap(err, "http call failed: failed to get identity token")
+		}
+		httpRequest.Header.Set("Authorization", "Bearer "+token)
+	}
 
-	// Use client-go's transport.TLSConfigureFor to ensure good defaults for tls
 	client := http.DefaultClient
-	tlsConfig, err := transport.TLSConfigFor(&transport.Config{
-		TLS: transport.TLSConfig{
-			CAData:     opts.config.CABundle,
-			ServerName: extensionURL.Hostname(),
-		},
-	})
-	if err != nil {
-		return errors.Wrap(err, "http call failed: failed to create tls config")
+	if useServiceProxy {
+		// Use the API server credentials and TLS config when calling the extension through the service proxy.
+		client, err = rest.HTTPClientFor(opts.serviceProxy)
+		if err != nil {
+			return errors.Wrap(err, "http call failed: failed to create http client for the API server service proxy")
+		}
+	} else {
+		// Use client-go's transport.TLSConfigureFor to ensure good defaults for tls
+		tlsConfig, err := transport.TLSConfigFor(&transport.Config{
+			TLS: transport.TLSConfig{
+				CAData:     opts.config.CABundle,
+				ServerName: extensionURL.Hostname(),
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "http call failed: failed to create tls config")
+		}
+		// This also adds http2
+		client.Transport = utilnet.SetTransportDefaults(&http.Transport{
+			TLSClientConfig: tlsConfig,
+		})
 	}
-	// This also adds http2
-	client.Transport = utilnet.SetTransportDefaults(&http.Transport{
-		TLSClientConfig: tlsConfig,
-	})
 
 	resp, err := client.Do(httpRequest)
 
@@ -572,6 +621,34 @@ func urlForExtension(config runtimev1.ClientConfig, gvh runtimecatalog.GroupVers
 	return u, nil
 }
 
+// serviceProxyURLForExtension returns the URL for calling the extension exposed by a Service through the API server service proxy,
+// e.g. https://api-server/api/v1/namespaces/ns/services/https:name:443/proxy/path.
+func serviceProxyURLForExtension(serviceProxy *rest.Config, config runtimev1.ClientConfig, gvh runtimecatalog.GroupVersionHook, name string) (*url.URL, error) {
+	u, err := url.Parse(serviceProxy.Host)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compute URL: failed to parse API server host")
+	}
+	if u.Scheme == "" {
+		u.Scheme = "https"
+	}
+	if u.Host == "" {
+		// Host without scheme, e.g. 127.0.0.1:6443, is parsed as path.
+		u.Host, u.Path = u.Path, ""
+	}
+
+	svc := config.Service
+	port := int32(443)
+	if svc.Port != nil {
+		port = *svc.Port
+	}
+	servicePath := ""
+	if svc.Path != nil {
+		servicePath = *svc.Path
+	}
+	u.Path = path.Join(u.Path, "api", "v1", "namespaces", svc.Namespace, "services", fmt.Sprintf("https:%s:%d", svc.Name, port), "proxy", servicePath, runtimecatalog.GVHToPath(gvh, name))
+	return u, nil
+}
+
 // defaultAndValidateDiscoveryResponse defaults unset values and runs a set of validations on the Discovery Response.
 // If any of these checks fails the response is invalid and an error is returned.
 func defaultAndValidateDiscoveryResponse(cat *runtimecatalog.Catalog, discovery *runtimehooksv1.DiscoveryResponse) error {
@@ -23,6 +23,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"testing"
@@ -33,6 +35,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apiserver/pkg/admission/plugin/webhook/testcerts"
+	"k8s.io/client-go/rest"
 	"k8s.io/utils/ptr"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -44,6 +47,7 @@ import (
 	runtimeregistry "sigs.k8s.io/cluster-api/internal/runtime/registry"
 	fakev1alpha1 "sigs.k8s.io/cluster-api/internal/runtime/test/v1alpha1"
 	fakev1alpha2 "sigs.k8s.io/cluster-api/internal/runtime/test/v1alpha2"
+	"sigs.k8s.io/cluster-api/util/identity"
 )
 
 func TestClient_httpCall(t *testing.T) {
@@ -208,6 +212,44 @@ func TestClient_httpCall(t *testing.T) {
 	}
 }
 
+func TestClient_httpCallWithIdentityToken(t *testing.T) {
+	g := NewWithT(t)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	g.Expect(os.WriteFile(tokenFile, []byte("identity-token\n"), 0600)).To(Succeed())
+
+	var authorization string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		fakeHookHandler(w, r)
+	})
+	srv := newUnstartedTLSServer(mux)
+	srv.StartTLS()
+	defer srv.Close()
+
+	c := runtimecatalog.New()
+	g.Expect(fakev1alpha1.AddToCatalog(c)).To(Succeed())
+	gvh, err := c.GroupVersionHook(fakev1alpha1.FakeHook)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	opts := &httpCallOptions{
+		catalog:         c,
+		config:          runtimev1.ClientConfig{URL: ptr.To(srv.URL), CABundle: testcerts.CACert},
+		registrationGVH: gvh,
+		hookGVH:         gvh,
+		tokenSource:     identity.NewFileTokenSource(tokenFile),
+	}
+	g.Expect(httpCall(context.TODO(), &fakev1alpha1.FakeRequest{}, &fakev1alpha1.FakeResponse{}, opts)).To(Succeed())
+	g.Expect(authorization).To(Equal("Bearer identity-token"))
+
+	// The call fails without reaching out to the extension if the token is not available.
+	authorization = ""
+	opts.tokenSource = identity.NewFileTokenSource(filepath.Join(t.TempDir(), "missing"))
+	g.Expect(httpCall(context.TODO(), &fakev1alpha1.FakeRequest{}, &fakev1alpha1.FakeResponse{}, opts)).ToNot(Succeed())
+	g.Expect(authorization).To(BeEmpty())
+}
+
 func fakeHookHandler(w http.ResponseWriter, _ *http.Request) {
 	response := &fakev1alpha1.FakeResponse{
 		TypeMeta: metav1.TypeMeta{
@@ -225,6 +267,52 @@ func fakeHookHandler(w http.ResponseWriter, _ *http.Request) {
 	_, _ = w.Write(respBody)
 }
 
+func TestClient_httpCallWithServiceProxy(t *testing.T) {
+	g := NewWithT(t)
+
+	var requestPath, authorization string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		requestPath = r.URL.Path
+		authorization = r.Header.Get("Authorization")
+		fakeHookHandler(w, r)
+	})
+	srv := newUnstartedTLSServer(mux)
+	srv.StartTLS()
+	defer srv.Close()
+
+	c := runtimecatalog.New()
+	g.Expect(fakev1alpha1.AddToCatalog(c)).To(Succeed())
+	gvh, err := c.GroupVersionHook(fakev1alpha1.FakeHook)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// The extension is called through the API server service proxy, using the API server credentials.
+	opts := &httpCallOptions{
+		catalog: c,
+		config: runtimev1.ClientConfig{Service: &runtimev1.ServiceReference{
+			Namespace: "test-namespace",
+			Name:      "test-service",
+			Port:      ptr.To[int32](9443),
+			Path:      ptr.To("/hooks"),
+		}},
+		registrationGVH: gvh,
+		hookGVH:         gvh,
+		name:            "fake-extension",
+		serviceProxy: &rest.Config{
+			Host:            srv.URL,
+			BearerToken:     "api-server-token",
+			TLSClientConfig: rest.TLSClientConfig{CAData: testcerts.CACert},
+		},
+	}
+	g.Expect(httpCall(context.TODO(), &fakev1alpha1.FakeRequest{}, &fakev1alpha1.FakeResponse{}, opts)).To(Succeed())
+	g.Expect(requestPath).To(Equal("/api/v1/namespaces/test-namespace/services/https:test-service:9443/proxy/hooks" + runtimecatalog.GVHToPath(gvh, "fake-extension")))
+	g.Expect(authorization).To(Equal("Bearer api-server-token"))
+
+	// The service proxy cannot be used together with a token source.
+	opts.tokenSource = identity.NewFileTokenSource(filepath.Join(t.TempDir(), "token"))
+	g.Expect(httpCall(context.TODO(), &fakev1alpha1.FakeRequest{}, &fakev1alpha1.FakeResponse{}, opts)).ToNot(Succeed())
+}
+
 func TestURLForExtension(t *testing.T) {
 	type args struct {
 		config               runtimev1.ClientConfig
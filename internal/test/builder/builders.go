@@ -120,6 +120,7 @@ type ClusterTopologyBuilder struct {
 	controlPlaneReplicas int32
 	controlPlaneMHC      *clusterv1.MachineHealthCheckTopology
 	variables            []clusterv1.ClusterVariable
+	rollout              *clusterv1.TopologyRollout
 }
 
 // ClusterTopology returns a ClusterTopologyBuilder.
@@ -171,6 +172,12 @@ func (c *ClusterTopologyBuilder) WithVariables(vars ...clusterv1.ClusterVariable
 	return c
 }
 
+// WithRolloutWindows adds the passed rollout windows to the ClusterTopologyBuilder.
+func (c *ClusterTopologyBuilder) WithRolloutWindows(windows ...clusterv1.RolloutWindow) *ClusterTopologyBuilder {
+	c.rollout = &clusterv1.TopologyRollout{Windows: windows}
+	return c
+}
+
 // Build returns a testable cluster Topology object with any values passed to the builder.
 func (c *ClusterTopologyBuilder) Build() *clusterv1.Topology {
 	return &clusterv1.Topology{
@@ -182,6 +189,7 @@ func (c *ClusterTopologyBuilder) Build() *clusterv1.Topology {
 			MachineHealthCheck: c.controlPlaneMHC,
 		},
 		Variables: c.variables,
+		Rollout:   c.rollout,
 	}
 }
 
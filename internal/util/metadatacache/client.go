@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metadatacache implements a client which reads objects using metadata-only informers,
+// fetching full objects from the API server only when they changed.
+package metadatacache
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// DefaultSize is the default maximum number of full objects kept in memory by the Client.
+	DefaultSize = 1024
+
+	// DefaultTTL is the default time after which full objects kept in memory by the Client are fetched again.
+	DefaultTTL = 10 * time.Minute
+)
+
+// Options are the options for NewClient.
+type Options struct {
+	// Objects are the types of the objects read using the metadata-only informers, e.g. &corev1.Secret{}.
+	Objects []client.Object
+
+	// Size is the maximum number of full objects kept in memory; defaults to DefaultSize.
+	Size int
+
+	// TTL is the time after which full objects kept in memory are fetched again; defaults to DefaultTTL.
+	TTL time.Duration
+}
+
+// Client is a client.Client which reads objects of the types in Options.Objects by first reading their metadata
+// from the metadata-only informers of cache, and then fetching the full objects from the API server using the
+// underlying client only if they are not already in memory with the same resourceVersion.
+// Compared to caching full objects, this keeps in memory only the full objects which are actually read, up to
+// Options.Size, and it is intended for objects like Secrets, which are many and big in large management clusters
+// but only a few of them are read by controllers.
+//
+// Writes, lists and reads of objects of other types are delegated to the underlying client; the underlying client
+// must read the objects of the types in Options.Objects from the API server, e.g. using client.CacheOptions.DisableFor.
+type Client struct {
+	client.Client
+
+	cache   client.Reader
+	gvks    map[schema.GroupVersionKind]bool
+	objects *cache.LRUExpireCache
+	ttl     time.Duration
+}
+
+var _ client.Client = &Client{}
+
+// NewClient returns a new Client using c to read full objects from the API server and metadataCache,
+// usually the manager cache, to read their metadata.
+func NewClient(c client.Client, metadataCache client.Reader, options Options) (*Client, error) {
+	if options.Size <= 0 {
+		options.Size = DefaultSize
+	}
+	if options.TTL <= 0 {
+		options.TTL = DefaultTTL
+	}
+
+	mc := &Client{
+		Client:  c,
+		cache:   metadataCache,
+		gvks:    map[schema.GroupVersionKind]bool{},
+		objects: cache.NewLRUExpireCache(options.Size),
+		ttl:     options.TTL,
+	}
+	for _, obj := range options.Objects {
+		gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get GroupVersionKind for %T", obj)
+		}
+		mc.gvks[gvk] = true
+	}
+	return mc, nil
+}
+
+// cachedObject is a full object kept in memory by the Client.
+type cachedObject struct {
+	resourceVersion string
+	object          runtime.Object
+}
+
+// Get reads an object; objects of the types in Options.Objects are read as described in Client.
+func (c *Client) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	if !c.gvks[gvk] || len(opts) > 0 {
+		return c.Client.Get(ctx, key, obj, opts...)
+	}
+
+	metadata := &metav1.PartialObjectMetadata{}
+	metadata.SetGroupVersionKind(gvk)
+	if err := c.cache.Get(ctx, key, metadata); err != nil {
+		return err
+	}
+
+	cacheKey := gvk.String() + "/" + key.String()
+	if v, ok := c.objects.Get(cacheKey); ok {
+		if cached := v.(cachedObject); cached.resourceVersion == metadata.GetResourceVersion() {
+			return copyInto(cached.object, obj)
+		}
+	}
+
+	if err := c.Client.Get(ctx, key, obj); err != nil {
+		return err
+	}
+	// Note: The object read from the API server can be newer than the one in the cache, in this case it will be fetched
+	// again on the next Get, as soon as the cache catches up.
+	c.objects.Add(cacheKey, cachedObject{resourceVersion: obj.GetResourceVersion(), object: obj.DeepCopyObject()}, c.ttl)
+	return nil
+}
+
+// copyInto copies the cached object into obj, like controller-runtime does when reading from its cache.
+func copyInto(cached runtime.Object, obj client.Object) error {
+	outVal := reflect.ValueOf(obj)
+	objVal := reflect.ValueOf(cached.DeepCopyObject())
+	if !objVal.Type().AssignableTo(outVal.Type()) {
+		return errors.Errorf("cache had type %s, but %s was asked for", objVal.Type(), outVal.Type())
+	}
+	reflect.Indirect(outVal).Set(reflect.Indirect(objVal))
+	return nil
+}
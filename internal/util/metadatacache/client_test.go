@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metadatacache
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestClientGet(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "secret"},
+		Data:       map[string][]byte{"key": []byte("value")},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "configmap"},
+	}
+
+	liveGets := 0
+	live := fake.NewClientBuilder().WithObjects(secret, configMap).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			liveGets++
+			return c.Get(ctx, key, obj, opts...)
+		},
+	}).Build()
+	// Note: Metadata is read from a separate client, like the manager cache is separate from the API server.
+	metadataCache := fake.NewClientBuilder().WithObjects(secret.DeepCopy(), configMap.DeepCopy()).Build()
+
+	c, err := NewClient(live, metadataCache, Options{Objects: []client.Object{&corev1.Secret{}}})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// The first read fetches the full Secret.
+	got := &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(secret), got)).To(Succeed())
+	g.Expect(got.Data).To(Equal(secret.Data))
+	g.Expect(liveGets).To(Equal(1))
+
+	// Subsequent reads of the unchanged Secret are served from memory.
+	got = &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(secret), got)).To(Succeed())
+	g.Expect(got.Data).To(Equal(secret.Data))
+	g.Expect(liveGets).To(Equal(1))
+
+	// Changes to the returned Secret do not change the Secret kept in memory.
+	got.Data["key"] = []byte("changed")
+	got = &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(secret), got)).To(Succeed())
+	g.Expect(got.Data).To(Equal(map[string][]byte{"key": []byte("value")}))
+
+	// When the Secret changes, the full Secret is fetched again.
+	updated := got.DeepCopy()
+	updated.Data["key"] = []byte("updated")
+	g.Expect(live.Update(ctx, updated)).To(Succeed())
+	cached := &corev1.Secret{}
+	g.Expect(metadataCache.Get(ctx, client.ObjectKeyFromObject(secret), cached)).To(Succeed())
+	cached.Data = updated.Data
+	g.Expect(metadataCache.Update(ctx, cached)).To(Succeed())
+	liveGets = 0
+	got = &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(secret), got)).To(Succeed())
+	g.Expect(got.Data).To(Equal(updated.Data))
+	g.Expect(liveGets).To(Equal(1))
+
+	// Secrets not in the metadata cache are not found.
+	err = c.Get(ctx, client.ObjectKey{Namespace: "ns", Name: "does-not-exist"}, &corev1.Secret{})
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	g.Expect(liveGets).To(Equal(1))
+
+	// Objects of other types are read using the underlying client.
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(configMap), &corev1.ConfigMap{})).To(Succeed())
+	g.Expect(liveGets).To(Equal(2))
+}
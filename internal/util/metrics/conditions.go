@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+var conditionDesc = prometheus.NewDesc(
+	"capi_condition",
+	"The conditions of the Cluster API objects, broken down by kind, namespace, name, type, status and reason; the value is always 1.",
+	[]string{"kind", "namespace", "name", "type", "status", "reason"}, nil,
+)
+
+// NewConditionsCollector returns a prometheus.Collector exposing the conditions with the given types of
+// the objects in the given lists, e.g. &clusterv1.ClusterList{}, as the capi_condition gauge.
+// The objects are listed on every scrape using c; c is expected to be a cached client, so that the objects are read
+// from the informers of the manager and scrapes don't hit the API server. The items of the lists must implement
+// conditions.Getter.
+func NewConditionsCollector(c client.Client, conditionTypes []string, lists ...client.ObjectList) prometheus.Collector {
+	return &conditionsCollector{
+		client:         c,
+		conditionTypes: sets.New(conditionTypes...),
+		lists:          lists,
+	}
+}
+
+type conditionsCollector struct {
+	client         client.Client
+	conditionTypes sets.Set[string]
+	lists          []client.ObjectList
+}
+
+// Describe implements prometheus.Collector.
+func (c *conditionsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- conditionDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *conditionsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, list := range c.lists {
+		if err := c.collect(context.Background(), list.DeepCopyObject().(client.ObjectList), ch); err != nil {
+			ch <- prometheus.NewInvalidMetric(conditionDesc, err)
+		}
+	}
+}
+
+func (c *conditionsCollector) collect(ctx context.Context, list client.ObjectList, ch chan<- prometheus.Metric) error {
+	gvk, err := apiutil.GVKForObject(list, c.client.Scheme())
+	if err != nil {
+		return errors.Wrapf(err, "failed to get GroupVersionKind of %T", list)
+	}
+	if err := c.client.List(ctx, list); err != nil {
+		return errors.Wrapf(err, "failed to list %s", gvk.Kind)
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return errors.Wrapf(err, "failed to extract items from %s", gvk.Kind)
+	}
+
+	for _, item := range items {
+		obj, ok := item.(conditions.Getter)
+		if !ok {
+			return errors.Errorf("%T does not implement conditions.Getter", item)
+		}
+		kind, err := apiutil.GVKForObject(obj, c.client.Scheme())
+		if err != nil {
+			return errors.Wrapf(err, "failed to get GroupVersionKind of %T", obj)
+		}
+		for _, condition := range obj.GetConditions() {
+			if !c.conditionTypes.Has(string(condition.Type)) {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(conditionDesc, prometheus.GaugeValue, 1,
+				kind.Kind, obj.GetNamespace(), obj.GetName(), string(condition.Type), string(condition.Status), condition.Reason)
+		}
+	}
+	return nil
+}
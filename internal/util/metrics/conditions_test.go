@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestConditionsCollector(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "cluster1"},
+		Status: clusterv1.ClusterStatus{Conditions: clusterv1.Conditions{
+			{Type: clusterv1.ReadyCondition, Status: corev1.ConditionFalse, Reason: "WaitingForControlPlane"},
+			{Type: clusterv1.InfrastructureReadyCondition, Status: corev1.ConditionTrue},
+		}},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "machine1"},
+		Status: clusterv1.MachineStatus{Conditions: clusterv1.Conditions{
+			{Type: clusterv1.ReadyCondition, Status: corev1.ConditionTrue},
+		}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, machine).Build()
+
+	collector := NewConditionsCollector(c, []string{string(clusterv1.ReadyCondition)}, &clusterv1.ClusterList{}, &clusterv1.MachineList{})
+	g.Expect(testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP capi_condition The conditions of the Cluster API objects, broken down by kind, namespace, name, type, status and reason; the value is always 1.
+# TYPE capi_condition gauge
+capi_condition{kind="Cluster",name="cluster1",namespace="ns1",reason="WaitingForControlPlane",status="False",type="Ready"} 1
+capi_condition{kind="Machine",name="machine1",namespace="ns1",reason="",status="True",type="Ready"} 1
+`))).To(Succeed())
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		infrastructureProvisioningDuration,
+		nodeProvisioningDuration,
+		deletionDuration,
+	)
+}
+
+// lifecycleDurationBuckets are the buckets of the Machine lifecycle duration histograms, from 10 seconds to 2 hours.
+var lifecycleDurationBuckets = []float64{10, 30, 60, 120, 180, 240, 300, 450, 600, 900, 1200, 1800, 2700, 3600, 5400, 7200}
+
+var (
+	infrastructureProvisioningDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "capi_machine_infrastructure_provisioning_duration_seconds",
+		Help:    "Time from the creation of a Machine to its infrastructure being ready.",
+		Buckets: lifecycleDurationBuckets,
+	}, []string{"cluster", "machine_deployment"})
+
+	nodeProvisioningDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "capi_machine_node_provisioning_duration_seconds",
+		Help:    "Time from the infrastructure of a Machine being ready to the Machine being linked to its Node.",
+		Buckets: lifecycleDurationBuckets,
+	}, []string{"cluster", "machine_deployment"})
+
+	deletionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "capi_machine_deletion_duration_seconds",
+		Help:    "Time from the deletion of a Machine being requested to the Machine being gone.",
+		Buckets: lifecycleDurationBuckets,
+	}, []string{"cluster", "machine_deployment"})
+)
+
+// lifecycleLabels returns the label values of the Machine lifecycle duration histograms; machine_deployment is
+// empty for Machines not belonging to a MachineDeployment, e.g. control plane Machines.
+func lifecycleLabels(m *clusterv1.Machine) []string {
+	return []string{
+		types.NamespacedName{Namespace: m.Namespace, Name: m.Spec.ClusterName}.String(),
+		m.Labels[clusterv1.MachineDeploymentNameLabel],
+	}
+}
+
+// RecordMachineLifecycleTransitions records the durations of the lifecycle transitions of the Machine from original to m;
+// it must be called only after m has been patched successfully, so each transition is persisted and recorded once per Machine.
+func RecordMachineLifecycleTransitions(original, m *clusterv1.Machine) {
+	if !original.Status.InfrastructureReady && m.Status.InfrastructureReady {
+		recordInfrastructureReady(m)
+	}
+	if original.Status.NodeRef == nil && m.Status.NodeRef != nil {
+		recordNodeProvisioned(m)
+	}
+	if controllerutil.ContainsFinalizer(original, clusterv1.MachineFinalizer) && !controllerutil.ContainsFinalizer(m, clusterv1.MachineFinalizer) {
+		recordDeleted(m)
+	}
+}
+
+// recordInfrastructureReady records the duration of the infrastructure provisioning of the Machine.
+func recordInfrastructureReady(m *clusterv1.Machine) {
+	infrastructureProvisioningDuration.WithLabelValues(lifecycleLabels(m)...).Observe(time.Since(m.CreationTimestamp.Time).Seconds())
+}
+
+// recordNodeProvisioned records the duration of the Node provisioning of the Machine, i.e. from the
+// infrastructure being ready to the Machine being linked to its Node.
+func recordNodeProvisioned(m *clusterv1.Machine) {
+	infrastructureReadyTime := conditions.GetLastTransitionTime(m, clusterv1.InfrastructureReadyCondition)
+	if !m.Status.InfrastructureReady || infrastructureReadyTime == nil {
+		return
+	}
+	nodeProvisioningDuration.WithLabelValues(lifecycleLabels(m)...).Observe(time.Since(infrastructureReadyTime.Time).Seconds())
+}
+
+// recordDeleted records the duration of the deletion of the Machine.
+func recordDeleted(m *clusterv1.Machine) {
+	if m.DeletionTimestamp.IsZero() {
+		return
+	}
+	deletionDuration.WithLabelValues(lifecycleLabels(m)...).Observe(time.Since(m.DeletionTimestamp.Time).Seconds())
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestMachineLifecycleMetrics(t *testing.T) {
+	g := NewWithT(t)
+
+	m := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "ns",
+			Name:              "metrics-machine",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-10 * time.Minute)),
+			Labels:            map[string]string{clusterv1.MachineDeploymentNameLabel: "metrics-md"},
+			Finalizers:        []string{clusterv1.MachineFinalizer},
+		},
+		Spec: clusterv1.MachineSpec{ClusterName: "metrics-cluster"},
+	}
+	labels := prometheus.Labels{"cluster": "ns/metrics-cluster", "machine_deployment": "metrics-md"}
+
+	// The infrastructure provisioning duration is observed when the infrastructure becomes ready.
+	original := m.DeepCopy()
+	m.Status.InfrastructureReady = true
+	conditions.MarkTrue(m, clusterv1.InfrastructureReadyCondition)
+	RecordMachineLifecycleTransitions(original, m)
+	g.Expect(sampleCount(g, infrastructureProvisioningDuration, labels)).To(Equal(uint64(1)))
+	g.Expect(sampleSum(g, infrastructureProvisioningDuration, labels)).To(BeNumerically(">=", 600))
+	g.Expect(sampleCount(g, nodeProvisioningDuration, labels)).To(Equal(uint64(0)))
+
+	// The Node provisioning duration is observed when the Machine is linked to its Node, regardless of the Node health.
+	original = m.DeepCopy()
+	m.Status.NodeRef = &corev1.ObjectReference{Name: "metrics-node"}
+	conditions.MarkFalse(m, clusterv1.MachineNodeHealthyCondition, clusterv1.NodeConditionsFailedReason, clusterv1.ConditionSeverityWarning, "")
+	RecordMachineLifecycleTransitions(original, m)
+	g.Expect(sampleCount(g, nodeProvisioningDuration, labels)).To(Equal(uint64(1)))
+
+	// Durations are not observed again without a transition.
+	original = m.DeepCopy()
+	conditions.MarkTrue(m, clusterv1.MachineNodeHealthyCondition)
+	RecordMachineLifecycleTransitions(original, m)
+	g.Expect(sampleCount(g, infrastructureProvisioningDuration, labels)).To(Equal(uint64(1)))
+	g.Expect(sampleCount(g, nodeProvisioningDuration, labels)).To(Equal(uint64(1)))
+	g.Expect(sampleCount(g, deletionDuration, labels)).To(Equal(uint64(0)))
+
+	// The deletion duration is observed when the finalizer is removed.
+	m.DeletionTimestamp = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+	original = m.DeepCopy()
+	m.Finalizers = nil
+	RecordMachineLifecycleTransitions(original, m)
+	g.Expect(sampleCount(g, deletionDuration, labels)).To(Equal(uint64(1)))
+
+	// The durations are deleted when the Cluster is deleted.
+	histograms := []*prometheus.HistogramVec{infrastructureProvisioningDuration, nodeProvisioningDuration, deletionDuration}
+	series := make([]int, len(histograms))
+	for i, h := range histograms {
+		series[i] = testutil.CollectAndCount(h)
+	}
+	ForgetCluster(types.NamespacedName{Namespace: "ns", Name: "metrics-cluster"})
+	for i, h := range histograms {
+		g.Expect(testutil.CollectAndCount(h)).To(Equal(series[i] - 1))
+	}
+}
+
+func histogram(g *WithT, h *prometheus.HistogramVec, labels prometheus.Labels) *dto.Histogram {
+	metric := &dto.Metric{}
+	g.Expect(h.With(labels).(prometheus.Histogram).Write(metric)).To(Succeed())
+	return metric.GetHistogram()
+}
+
+func sampleCount(g *WithT, h *prometheus.HistogramVec, labels prometheus.Labels) uint64 {
+	return histogram(g, h, labels).GetSampleCount()
+}
+
+func sampleSum(g *WithT, h *prometheus.HistogramVec, labels prometheus.Labels) float64 {
+	return histogram(g, h, labels).GetSampleSum()
+}
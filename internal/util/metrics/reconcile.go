@@ -0,0 +1,267 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics provides functions for creating per-cluster reconcile metrics.
+package metrics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+)
+
+func init() {
+	// Register the metrics at the controller-runtime metrics registry.
+	ctrlmetrics.Registry.MustRegister(reconcileTotal, reconcileErrors, reconcileDuration, reconcileBacklog)
+}
+
+// Metrics subsystem and all of the result labels used by the reconcile metrics.
+const (
+	reconcileSubsystem = "capi_reconcile"
+
+	resultSuccess      = "success"
+	resultError        = "error"
+	resultRequeue      = "requeue"
+	resultRequeueAfter = "requeue_after"
+)
+
+var (
+	reconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: reconcileSubsystem,
+		Name:      "total",
+		Help:      "Total number of reconciles, broken down by controller, cluster and result.",
+	}, []string{"controller", "cluster", "result"})
+
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: reconcileSubsystem,
+		Name:      "errors_total",
+		Help:      "Total number of reconcile errors, broken down by controller and cluster.",
+	}, []string{"controller", "cluster"})
+
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: reconcileSubsystem,
+		Name:      "duration_seconds",
+		Help:      "Reconcile duration in seconds, broken down by controller and cluster.",
+		Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.15, 0.2, 0.25, 0.3, 0.35, 0.4, 0.45, 0.5, 0.6, 0.7, 0.8, 0.9, 1.0,
+			1.25, 1.5, 1.75, 2.0, 2.5, 3.0, 3.5, 4.0, 4.5, 5, 6, 7, 8, 9, 10, 15, 20, 25, 30, 40, 50, 60},
+	}, []string{"controller", "cluster"})
+
+	reconcileBacklog = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: reconcileSubsystem,
+		Name:      "backlog",
+		Help: "Number of objects waiting in the workqueue to be reconciled again because their last reconcile failed, " +
+			"broken down by controller and cluster.",
+	}, []string{"controller", "cluster"})
+)
+
+// InstrumentReconciler returns a reconcile.Reconciler wrapping r which records reconcile metrics broken down
+// by controller and by cluster. The cluster of the reconciled object is determined with util.ClusterNameForObject
+// after reading the object, of the same type as obj, using c; c is expected to be a cached client, so reading the object is cheap.
+// If the cluster can't be determined, e.g. because the object has been deleted, the cluster label is empty.
+func InstrumentReconciler(controllerName string, c client.Reader, obj client.Object, r reconcile.Reconciler) reconcile.Reconciler {
+	return &instrumentedReconciler{
+		controllerName: controllerName,
+		client:         c,
+		obj:            obj,
+		reconciler:     r,
+	}
+}
+
+type instrumentedReconciler struct {
+	controllerName string
+	client         client.Reader
+	obj            client.Object
+	reconciler     reconcile.Reconciler
+}
+
+var (
+	backlogLock sync.Mutex
+	// backlog maps the objects waiting to be reconciled again, in the controller/namespace/name form, to their cluster.
+	backlog = map[string]string{}
+)
+
+// Reconcile reconciles the object calling the wrapped reconciler and records the reconcile metrics.
+func (r *instrumentedReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	start := time.Now()
+	cluster := r.clusterFor(ctx, req)
+
+	res, err := r.reconciler.Reconcile(ctx, req)
+
+	reconcileDuration.WithLabelValues(r.controllerName, cluster).Observe(time.Since(start).Seconds())
+	result := resultSuccess
+	switch {
+	case err != nil:
+		result = resultError
+		reconcileErrors.WithLabelValues(r.controllerName, cluster).Inc()
+	case res.RequeueAfter > 0:
+		result = resultRequeueAfter
+	case res.Requeue:
+		result = resultRequeue
+	}
+	reconcileTotal.WithLabelValues(r.controllerName, cluster, result).Inc()
+	r.updateBacklog(req.NamespacedName, cluster, err != nil)
+	recordLastReconcileError(r.controllerName, req.NamespacedName, cluster, err)
+
+	return res, err
+}
+
+// clusterFor returns the cluster of the object reconciled for req in the namespace/name form.
+func (r *instrumentedReconciler) clusterFor(ctx context.Context, req reconcile.Request) string {
+	if _, ok := r.obj.(*clusterv1.Cluster); ok {
+		return req.String()
+	}
+
+	obj, ok := r.obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return ""
+	}
+	if err := r.client.Get(ctx, req.NamespacedName, obj); err != nil {
+		return ""
+	}
+	name, ok := util.ClusterNameForObject(obj)
+	if !ok {
+		return ""
+	}
+	return types.NamespacedName{Namespace: req.Namespace, Name: name}.String()
+}
+
+// updateBacklog tracks the objects waiting to be reconciled again because their last reconcile failed;
+// objects requeued by a successful reconcile, e.g. to be resynced periodically, are not part of the backlog.
+func (r *instrumentedReconciler) updateBacklog(key types.NamespacedName, cluster string, pending bool) {
+	backlogLock.Lock()
+	defer backlogLock.Unlock()
+
+	objectKey := r.controllerName + "/" + key.String()
+	previousCluster, wasPending := backlog[objectKey]
+	if wasPending {
+		reconcileBacklog.WithLabelValues(r.controllerName, previousCluster).Dec()
+		delete(backlog, objectKey)
+	}
+	if pending {
+		reconcileBacklog.WithLabelValues(r.controllerName, cluster).Inc()
+		backlog[objectKey] = cluster
+	}
+}
+
+// ForgetCluster deletes the metrics and the errors recorded for the objects of the cluster, including the Machine
+// lifecycle durations,
+// e.g. after the Cluster has been deleted.
+func ForgetCluster(cluster client.ObjectKey) {
+	name := cluster.String()
+
+	backlogLock.Lock()
+	for objectKey, c := range backlog {
+		if c == name {
+			delete(backlog, objectKey)
+		}
+	}
+	labels := prometheus.Labels{"cluster": name}
+	reconcileTotal.DeletePartialMatch(labels)
+	reconcileErrors.DeletePartialMatch(labels)
+	reconcileDuration.DeletePartialMatch(labels)
+	reconcileBacklog.DeletePartialMatch(labels)
+	backlogLock.Unlock()
+
+	infrastructureProvisioningDuration.DeletePartialMatch(labels)
+	nodeProvisioningDuration.DeletePartialMatch(labels)
+	deletionDuration.DeletePartialMatch(labels)
+
+	lastReconcileErrorsLock.Lock()
+	defer lastReconcileErrorsLock.Unlock()
+	delete(lastReconcileErrors, name)
+}
+
+// maxReconcileErrorLength is the maximum length of the errors tracked by LastReconcileErrors.
+const maxReconcileErrorLength = 1024
+
+// ReconcileError is the error returned by the last reconcile of an object belonging to a cluster.
+type ReconcileError struct {
+	// Controller is the name of the controller which reconciled the object.
+	Controller string `json:"controller"`
+
+	// Object is the object, in the namespace/name form.
+	Object string `json:"object"`
+
+	// Error is the error returned by the reconcile.
+	Error string `json:"error"`
+
+	// Time is when the reconcile failed.
+	Time metav1.Time `json:"time"`
+}
+
+var (
+	lastReconcileErrorsLock sync.RWMutex
+	lastReconcileErrors     = map[string]map[string]ReconcileError{}
+)
+
+// LastReconcileErrors returns the errors returned by the last reconcile of the objects belonging to the cluster,
+// in the namespace/name form, as recorded by the reconcilers wrapped with InstrumentReconciler; objects whose
+// last reconcile succeeded are not included.
+func LastReconcileErrors(cluster string) []ReconcileError {
+	lastReconcileErrorsLock.RLock()
+	defer lastReconcileErrorsLock.RUnlock()
+
+	errs := make([]ReconcileError, 0, len(lastReconcileErrors[cluster]))
+	for _, e := range lastReconcileErrors[cluster] {
+		errs = append(errs, e)
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		if errs[i].Controller != errs[j].Controller {
+			return errs[i].Controller < errs[j].Controller
+		}
+		return errs[i].Object < errs[j].Object
+	})
+	return errs
+}
+
+func recordLastReconcileError(controllerName string, key types.NamespacedName, cluster string, err error) {
+	lastReconcileErrorsLock.Lock()
+	defer lastReconcileErrorsLock.Unlock()
+
+	objectKey := controllerName + "/" + key.String()
+	if err == nil {
+		delete(lastReconcileErrors[cluster], objectKey)
+		if len(lastReconcileErrors[cluster]) == 0 {
+			delete(lastReconcileErrors, cluster)
+		}
+		return
+	}
+
+	message := err.Error()
+	if len(message) > maxReconcileErrorLength {
+		message = message[:maxReconcileErrorLength] + "..."
+	}
+	if lastReconcileErrors[cluster] == nil {
+		lastReconcileErrors[cluster] = map[string]ReconcileError{}
+	}
+	lastReconcileErrors[cluster][objectKey] = ReconcileError{
+		Controller: controllerName,
+		Object:     key.String(),
+		Error:      message,
+		Time:       metav1.Now(),
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+type fakeReconciler struct {
+	result reconcile.Result
+	err    error
+}
+
+func (r *fakeReconciler) Reconcile(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	return r.result, r.err
+}
+
+func TestInstrumentReconciler(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machine",
+			Namespace: "ns",
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: "cluster"},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(machine).Build()
+
+	fr := &fakeReconciler{}
+	r := InstrumentReconciler("test-machine", c, &clusterv1.Machine{}, fr)
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "machine"}}
+
+	// A failed reconcile is counted as error and adds the object to the backlog.
+	fr.err = errors.New("failed")
+	_, err := r.Reconcile(ctx, req)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(testutil.ToFloat64(reconcileErrors.WithLabelValues("test-machine", "ns/cluster"))).To(Equal(1.0))
+	g.Expect(testutil.ToFloat64(reconcileTotal.WithLabelValues("test-machine", "ns/cluster", resultError))).To(Equal(1.0))
+	g.Expect(testutil.ToFloat64(reconcileBacklog.WithLabelValues("test-machine", "ns/cluster"))).To(Equal(1.0))
+	g.Expect(LastReconcileErrors("ns/cluster")).To(ConsistOf(And(
+		HaveField("Controller", "test-machine"),
+		HaveField("Object", "ns/machine"),
+		HaveField("Error", "failed"),
+	)))
+
+	// Failing again keeps the object in the backlog, without counting it twice.
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(testutil.ToFloat64(reconcileBacklog.WithLabelValues("test-machine", "ns/cluster"))).To(Equal(1.0))
+
+	// A successful reconcile requesting a requeue removes the object from the backlog.
+	fr.err = nil
+	fr.result = reconcile.Result{RequeueAfter: time.Minute}
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(testutil.ToFloat64(reconcileTotal.WithLabelValues("test-machine", "ns/cluster", resultRequeueAfter))).To(Equal(1.0))
+	g.Expect(testutil.ToFloat64(reconcileBacklog.WithLabelValues("test-machine", "ns/cluster"))).To(Equal(0.0))
+	g.Expect(LastReconcileErrors("ns/cluster")).To(BeEmpty())
+
+	// A successful reconcile is counted as success.
+	fr.result = reconcile.Result{}
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(testutil.ToFloat64(reconcileTotal.WithLabelValues("test-machine", "ns/cluster", resultSuccess))).To(Equal(1.0))
+	g.Expect(testutil.ToFloat64(reconcileBacklog.WithLabelValues("test-machine", "ns/cluster"))).To(Equal(0.0))
+	g.Expect(testutil.CollectAndCount(reconcileDuration, "capi_reconcile_duration_seconds")).To(BeNumerically(">=", 1))
+
+	// Objects which can't be read are recorded with an empty cluster label.
+	_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "does-not-exist"}})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(testutil.ToFloat64(reconcileTotal.WithLabelValues("test-machine", "", resultSuccess))).To(Equal(1.0))
+}
+
+func TestInstrumentReconcilerCluster(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	c := fake.NewClientBuilder().Build()
+	r := InstrumentReconciler("test-cluster", c, &clusterv1.Cluster{}, &fakeReconciler{})
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "cluster"}})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(testutil.ToFloat64(reconcileTotal.WithLabelValues("test-cluster", "ns/cluster", resultSuccess))).To(Equal(1.0))
+}
+
+func TestForgetCluster(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machine",
+			Namespace: "ns",
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: "forget"},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(machine).Build()
+
+	fr := &fakeReconciler{err: errors.New("failed")}
+	r := InstrumentReconciler("test-forget", c, &clusterv1.Machine{}, fr)
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "machine"}}
+
+	_, err := r.Reconcile(ctx, req)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(testutil.ToFloat64(reconcileBacklog.WithLabelValues("test-forget", "ns/forget"))).To(Equal(1.0))
+	g.Expect(LastReconcileErrors("ns/forget")).To(HaveLen(1))
+
+	durationSeries := testutil.CollectAndCount(reconcileDuration)
+	ForgetCluster(types.NamespacedName{Namespace: "ns", Name: "forget"})
+	g.Expect(LastReconcileErrors("ns/forget")).To(BeEmpty())
+	g.Expect(testutil.CollectAndCount(reconcileDuration)).To(Equal(durationSeries - 1))
+	g.Expect(testutil.ToFloat64(reconcileTotal.WithLabelValues("test-forget", "ns/forget", resultError))).To(BeZero())
+	g.Expect(testutil.ToFloat64(reconcileErrors.WithLabelValues("test-forget", "ns/forget"))).To(BeZero())
+
+	// The object is not part of the backlog anymore, so the backlog does not go negative when it is reconciled again.
+	fr.err = nil
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(testutil.ToFloat64(reconcileBacklog.WithLabelValues("test-forget", "ns/forget"))).To(Equal(0.0))
+	ForgetCluster(types.NamespacedName{Namespace: "ns", Name: "forget"})
+}
+
+func TestInstrumentReconcilerClusterFromOwnerReference(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	// An object without the cluster name label, linked to its Cluster only by the owner reference.
+	machineSet := &clusterv1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "ms",
+			Namespace:       "ns",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: clusterv1.GroupVersion.String(), Kind: "Cluster", Name: "owner"}},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(machineSet).Build()
+	r := InstrumentReconciler("test-owner", c, &clusterv1.MachineSet{}, &fakeReconciler{})
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "ms"}})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(testutil.ToFloat64(reconcileTotal.WithLabelValues("test-owner", "ns/owner", resultSuccess))).To(Equal(1.0))
+}
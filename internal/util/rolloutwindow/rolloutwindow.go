@@ -0,0 +1,243 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rolloutwindow implements helpers to evaluate the rollout windows of a Cluster.
+package rolloutwindow
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	// Embed the IANA time zone database, so time zones can be loaded also when it is not available in the container image.
+	_ "time/tzdata"
+
+	"github.com/pkg/errors"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// maxSearchYears is the maximum number of years to look ahead for the next start of a schedule;
+// schedules that never match, e.g. "0 0 30 2 *", are reported as having no next start.
+const maxSearchYears = 5
+
+// Schedule is a parsed cron schedule.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// domRestricted and dowRestricted are true if the day of month or the day of week fields are not "*";
+	// as in cron, if both are restricted a day matches when either of them matches.
+	domRestricted, dowRestricted bool
+}
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// ParseSchedule parses a cron schedule with five space-separated fields: minute, hour, day of month, month and day of week.
+func ParseSchedule(spec string) (*Schedule, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, errors.Errorf("invalid schedule %q: expected %d space-separated fields, got %d", spec, len(fields), len(parts))
+	}
+
+	bits := make([]uint64, len(fields))
+	for i, f := range fields {
+		b, err := parseField(parts[i], f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid schedule %q", spec)
+		}
+		bits[i] = b
+	}
+
+	// Sunday can be expressed both as 0 and 7.
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = (bits[4] | 1) &^ (1 << 7)
+	}
+
+	return &Schedule{
+		minute:        bits[0],
+		hour:          bits[1],
+		dom:           bits[2],
+		month:         bits[3],
+		dow:           bits[4],
+		domRestricted: parts[2] != "*",
+		dowRestricted: parts[4] != "*",
+	}, nil
+}
+
+// parseField parses a comma-separated list of "*", values, ranges and steps into a bit set.
+func parseField(value string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+
+		step := 1
+		if hasStep {
+			s, err := strconv.Atoi(stepPart)
+			if err != nil || s <= 0 {
+				return 0, errors.Errorf("invalid step %q in %s field", stepPart, f.name)
+			}
+			step = s
+		}
+
+		var start, end int
+		switch {
+		case rangePart == "*":
+			start, end = f.min, f.max
+		case strings.Contains(rangePart, "-"):
+			lo, hi, _ := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = parseValue(lo, f); err != nil {
+				return 0, err
+			}
+			if end, err = parseValue(hi, f); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, errors.Errorf("invalid range %q in %s field", rangePart, f.name)
+			}
+		default:
+			v, err := parseValue(rangePart, f)
+			if err != nil {
+				return 0, err
+			}
+			start, end = v, v
+			// As in cron, a step after a single value means from the value to the end of the field.
+			if hasStep {
+				end = f.max
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(value string, f field) (int, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil || v < f.min || v > f.max {
+		return 0, errors.Errorf("invalid value %q in %s field, must be between %d and %d", value, f.name, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first start of the schedule strictly after t, in the location of t.
+// It returns the zero time if the schedule does not start in the next years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	// Start from the beginning of the next minute.
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+	limit := t.Year() + maxSearchYears
+
+	for t.Year() <= limit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			next := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc).Add(time.Hour)
+			// Always move forward, also across daylight saving time changes.
+			if !next.After(t) {
+				next = t.Add(time.Minute)
+			}
+			t = next
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// Validate returns an error if the rollout window is not valid.
+func Validate(window clusterv1.RolloutWindow) error {
+	_, _, err := parse(window)
+	return err
+}
+
+func parse(window clusterv1.RolloutWindow) (*Schedule, *time.Location, error) {
+	schedule, err := ParseSchedule(window.Schedule)
+	if err != nil {
+		return nil, nil, err
+	}
+	if window.Duration.Duration <= 0 {
+		return nil, nil, errors.Errorf("invalid duration %q: must be greater than zero", window.Duration.Duration)
+	}
+	loc := time.UTC
+	if window.TimeZone != nil {
+		loc, err = time.LoadLocation(*window.TimeZone)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid time zone %q", *window.TimeZone)
+		}
+	}
+	return schedule, loc, nil
+}
+
+// Check returns true if rollouts of the Cluster can be started at the given time, i.e. if the Cluster does not
+// define rollout windows or if one of them is open; otherwise it also returns the time the next window starts,
+// which is the zero time if none of the windows starts in the next years.
+func Check(cluster *clusterv1.Cluster, now time.Time) (allowed bool, nextStart time.Time, err error) {
+	if cluster == nil || cluster.Spec.Topology == nil || cluster.Spec.Topology.Rollout == nil || len(cluster.Spec.Topology.Rollout.Windows) == 0 {
+		return true, time.Time{}, nil
+	}
+
+	for _, window := range cluster.Spec.Topology.Rollout.Windows {
+		schedule, loc, err := parse(window)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+
+		// The window is open if it started within its duration before now.
+		start := schedule.Next(now.In(loc).Add(-window.Duration.Duration))
+		if start.IsZero() {
+			continue
+		}
+		if !start.After(now) {
+			return true, time.Time{}, nil
+		}
+		if nextStart.IsZero() || start.Before(nextStart) {
+			nextStart = start
+		}
+	}
+	return false, nextStart, nil
+}
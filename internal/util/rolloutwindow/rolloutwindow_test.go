@@ -0,0 +1,240 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rolloutwindow
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		schedule string
+		wantErr  bool
+	}{
+		{schedule: "* * * * *"},
+		{schedule: "0 22 * * 1-5"},
+		{schedule: "*/15 0-6,22,23 1,15 */2 0,7"},
+		{schedule: "5/10 * * * *"},
+		{schedule: "0 22 * *", wantErr: true},
+		{schedule: "60 * * * *", wantErr: true},
+		{schedule: "0 24 * * *", wantErr: true},
+		{schedule: "0 0 0 * *", wantErr: true},
+		{schedule: "0 0 * 13 *", wantErr: true},
+		{schedule: "0 0 * * 8", wantErr: true},
+		{schedule: "0 5-1 * * *", wantErr: true},
+		{schedule: "*/0 * * * *", wantErr: true},
+		{schedule: "a * * * *", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schedule, func(t *testing.T) {
+			g := NewWithT(t)
+
+			_, err := ParseSchedule(tt.schedule)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	rome, err := time.LoadLocation("Europe/Rome")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		schedule string
+		from     time.Time
+		want     time.Time
+	}{
+		{
+			name:     "every minute",
+			schedule: "* * * * *",
+			from:     time.Date(2024, 3, 4, 10, 20, 30, 0, time.UTC),
+			want:     time.Date(2024, 3, 4, 10, 21, 0, 0, time.UTC),
+		},
+		{
+			name:     "strictly after the given time",
+			schedule: "0 22 * * *",
+			from:     time.Date(2024, 3, 4, 22, 0, 0, 0, time.UTC),
+			want:     time.Date(2024, 3, 5, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "weekdays only",
+			schedule: "0 22 * * 1-5",
+			from:     time.Date(2024, 3, 8, 23, 0, 0, 0, time.UTC), // Friday.
+			want:     time.Date(2024, 3, 11, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "sunday as 7",
+			schedule: "0 0 * * 7",
+			from:     time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), // Monday.
+			want:     time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "day of month or day of week if both are restricted",
+			schedule: "0 0 15 * 1",
+			from:     time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC), // Tuesday.
+			want:     time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "steps",
+			schedule: "*/20 */6 * * *",
+			from:     time.Date(2024, 3, 4, 6, 41, 0, 0, time.UTC),
+			want:     time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "next year",
+			schedule: "0 0 1 1 *",
+			from:     time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+			want:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "leap day",
+			schedule: "0 0 29 2 *",
+			from:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			want:     time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "never",
+			schedule: "0 0 30 2 *",
+			from:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			want:     time.Time{},
+		},
+		{
+			name:     "time zone",
+			schedule: "0 2 * * *",
+			from:     time.Date(2024, 3, 4, 12, 0, 0, 0, rome),
+			want:     time.Date(2024, 3, 5, 2, 0, 0, 0, rome),
+		},
+		{
+			name:     "across a daylight saving time change",
+			schedule: "30 3 * * *",
+			from:     time.Date(2024, 3, 30, 12, 0, 0, 0, rome),
+			want:     time.Date(2024, 3, 31, 3, 30, 0, 0, rome),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			s, err := ParseSchedule(tt.schedule)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(s.Next(tt.from).Equal(tt.want)).To(BeTrue(), "got %s, want %s", s.Next(tt.from), tt.want)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(Validate(clusterv1.RolloutWindow{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: ptr.To("Europe/Rome")})).To(Succeed())
+	g.Expect(Validate(clusterv1.RolloutWindow{Schedule: "0 22 * *", Duration: metav1.Duration{Duration: time.Hour}})).ToNot(Succeed())
+	g.Expect(Validate(clusterv1.RolloutWindow{Schedule: "0 22 * * *"})).ToNot(Succeed())
+	g.Expect(Validate(clusterv1.RolloutWindow{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: ptr.To("Mars/Olympus")})).ToNot(Succeed())
+}
+
+func TestCheck(t *testing.T) {
+	clusterWithWindows := func(windows ...clusterv1.RolloutWindow) *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			Spec: clusterv1.ClusterSpec{
+				Topology: &clusterv1.Topology{
+					Rollout: &clusterv1.TopologyRollout{Windows: windows},
+				},
+			},
+		}
+	}
+	nightly := clusterv1.RolloutWindow{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: 4 * time.Hour}}
+	weekend := clusterv1.RolloutWindow{Schedule: "0 8 * * 6", Duration: metav1.Duration{Duration: 2 * time.Hour}, TimeZone: ptr.To("America/New_York")}
+
+	tests := []struct {
+		name          string
+		cluster       *clusterv1.Cluster
+		now           time.Time
+		wantAllowed   bool
+		wantNextStart time.Time
+		wantErr       bool
+	}{
+		{
+			name:        "allowed without rollout windows",
+			cluster:     &clusterv1.Cluster{Spec: clusterv1.ClusterSpec{Topology: &clusterv1.Topology{}}},
+			now:         time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC),
+			wantAllowed: true,
+		},
+		{
+			name:        "allowed at the start of a window",
+			cluster:     clusterWithWindows(nightly),
+			now:         time.Date(2024, 3, 4, 22, 0, 0, 0, time.UTC),
+			wantAllowed: true,
+		},
+		{
+			name:        "allowed during a window spanning midnight",
+			cluster:     clusterWithWindows(nightly),
+			now:         time.Date(2024, 3, 5, 1, 59, 0, 0, time.UTC),
+			wantAllowed: true,
+		},
+		{
+			name:          "deferred after a window ends",
+			cluster:       clusterWithWindows(nightly),
+			now:           time.Date(2024, 3, 5, 2, 0, 0, 0, time.UTC),
+			wantNextStart: time.Date(2024, 3, 5, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "deferred until the first of the next windows",
+			cluster:       clusterWithWindows(nightly, weekend),
+			now:           time.Date(2024, 3, 9, 2, 0, 0, 0, time.UTC), // Saturday.
+			wantNextStart: time.Date(2024, 3, 9, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "allowed during a window in another time zone",
+			cluster:     clusterWithWindows(nightly, weekend),
+			now:         time.Date(2024, 3, 9, 14, 0, 0, 0, time.UTC),
+			wantAllowed: true,
+		},
+		{
+			name:    "fails for invalid windows",
+			cluster: clusterWithWindows(clusterv1.RolloutWindow{Schedule: "0 22 * *", Duration: metav1.Duration{Duration: time.Hour}}),
+			now:     time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			allowed, nextStart, err := Check(tt.cluster, tt.now)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(allowed).To(Equal(tt.wantAllowed))
+			g.Expect(nextStart.Equal(tt.wantNextStart)).To(BeTrue(), "got %s, want %s", nextStart, tt.wantNextStart)
+		})
+	}
+}
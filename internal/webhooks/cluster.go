@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -45,7 +46,9 @@ import (
 	"sigs.k8s.io/cluster-api/internal/contract"
 	"sigs.k8s.io/cluster-api/internal/topology/check"
 	"sigs.k8s.io/cluster-api/internal/topology/variables"
+	"sigs.k8s.io/cluster-api/internal/util/rolloutwindow"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	capilabels "sigs.k8s.io/cluster-api/util/labels"
 	"sigs.k8s.io/cluster-api/util/version"
 )
 
@@ -61,6 +64,32 @@ func (webhook *Cluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
 // +kubebuilder:webhook:verbs=create;update;delete,path=/validate-cluster-x-k8s-io-v1beta1-cluster,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=cluster.x-k8s.io,resources=clusters,versions=v1beta1,name=validation.cluster.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
 // +kubebuilder:webhook:verbs=create;update,path=/mutate-cluster-x-k8s-io-v1beta1-cluster,mutating=true,failurePolicy=fail,matchPolicy=Equivalent,groups=cluster.x-k8s.io,resources=clusters,versions=v1beta1,name=default.cluster.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
 
+// reservedMetadataPropagationKeys matches the keys of the labels and annotations which can't be propagated from
+// the Cluster, because they are in the domains reserved to Kubernetes and Cluster API, e.g. the cluster name label,
+// the paused annotation or the annotations tracking the propagated keys. The node.cluster.x-k8s.io domain is allowed,
+// because it is meant for the labels managed by Cluster API on Nodes.
+var reservedMetadataPropagationKeys = func(key string) bool {
+	return capilabels.MatchDomains(clusterv1.GroupVersion.Group, "kubernetes.io", "k8s.io")(key) &&
+		!capilabels.MatchDomains(clusterv1.ManagedNodeLabelDomain)(key)
+}
+
+// validateMetadataPropagationKeys rejects the labels and annotations in reserved domains.
+func validateMetadataPropagationKeys(metadata *clusterv1.ObjectMeta, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for path, keys := range map[string]map[string]string{"labels": metadata.Labels, "annotations": metadata.Annotations} {
+		for key := range keys {
+			if reservedMetadataPropagationKeys(key) {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Child(path).Key(key),
+					fmt.Sprintf("keys in the %s, kubernetes.io and k8s.io domains can't be propagated, except for the %s domain",
+						clusterv1.GroupVersion.Group, clusterv1.ManagedNodeLabelDomain)))
+			}
+		}
+	}
+	// Sort the errors, so they are returned in a stable order.
+	sort.Slice(allErrs, func(i, j int) bool { return allErrs[i].Field < allErrs[j].Field })
+	return allErrs
+}
+
 // ClusterCacheTrackerReader is a scoped-down interface from ClusterCacheTracker that only allows to get a reader client.
 type ClusterCacheTrackerReader interface {
 	GetReader(ctx context.Context, cluster client.ObjectKey) (client.Reader, error)
@@ -199,6 +228,12 @@ func (webhook *Cluster) validate(ctx context.Context, oldCluster, newCluster *cl
 		}
 	}
 
+	// Validate the labels and annotations propagated to the objects of the Cluster, if defined.
+	if newCluster.Spec.MetadataPropagation != nil {
+		allErrs = append(allErrs, newCluster.Spec.MetadataPropagation.Validate(specPath.Child("metadataPropagation"))...)
+		allErrs = append(allErrs, validateMetadataPropagationKeys(newCluster.Spec.MetadataPropagation, specPath.Child("metadataPropagation"))...)
+	}
+
 	topologyPath := specPath.Child("topology")
 
 	// Validate the managed topology, if defined.
@@ -269,6 +304,9 @@ func (webhook *Cluster) validateTopology(ctx context.Context, oldCluster, newClu
 	// metadata in topology should be valid
 	allErrs = append(allErrs, validateTopologyMetadata(newCluster.Spec.Topology, fldPath)...)
 
+	// rollout windows should be valid.
+	allErrs = append(allErrs, validateTopologyRollout(newCluster.Spec.Topology, fldPath)...)
+
 	// upgrade concurrency should be a numeric value.
 	if concurrency, ok := newCluster.Annotations[clusterv1.ClusterTopologyUpgradeConcurrencyAnnotation]; ok {
 		concurrencyAnnotationField := field.NewPath("metadata", "annotations", clusterv1.ClusterTopologyUpgradeConcurrencyAnnotation)
@@ -882,3 +920,20 @@ func validateTopologyMetadata(topology *clusterv1.Topology, fldPath *field.Path)
 	}
 	return allErrs
 }
+
+func validateTopologyRollout(topology *clusterv1.Topology, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if topology.Rollout == nil {
+		return allErrs
+	}
+	for idx, window := range topology.Rollout.Windows {
+		if err := rolloutwindow.Validate(window); err != nil {
+			allErrs = append(allErrs, field.Invalid(
+				fldPath.Child("rollout", "windows").Index(idx),
+				window,
+				err.Error(),
+			))
+		}
+	}
+	return allErrs
+}
@@ -19,6 +19,7 @@ package webhooks
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/blang/semver/v4"
 	. "github.com/onsi/gomega"
@@ -1087,6 +1088,73 @@ func TestClusterValidation(t *testing.T) {
 				in:        builder.Cluster("fooNamespace", "thisNameContainsInvalid!@NonAlphanumerics").Build(),
 				expectErr: true,
 			},
+			{
+				name:      "pass with valid metadata propagation",
+				expectErr: false,
+				in: func() *clusterv1.Cluster {
+					c := builder.Cluster("fooNamespace", "cluster1").Build()
+					c.Spec.MetadataPropagation = &clusterv1.ObjectMeta{
+						Labels:      map[string]string{"example.com/team": "a"},
+						Annotations: map[string]string{"example.com/owner": "team-a"},
+					}
+					return c
+				}(),
+			},
+			{
+				name:      "pass with metadata propagation labels in the node.cluster.x-k8s.io domain",
+				expectErr: false,
+				in: func() *clusterv1.Cluster {
+					c := builder.Cluster("fooNamespace", "cluster1").Build()
+					c.Spec.MetadataPropagation = &clusterv1.ObjectMeta{
+						Labels: map[string]string{"node.cluster.x-k8s.io/team": "a"},
+					}
+					return c
+				}(),
+			},
+			{
+				name:      "fails if metadata propagation labels are in the cluster.x-k8s.io domain",
+				expectErr: true,
+				in: func() *clusterv1.Cluster {
+					c := builder.Cluster("fooNamespace", "cluster1").Build()
+					c.Spec.MetadataPropagation = &clusterv1.ObjectMeta{
+						Labels: map[string]string{clusterv1.ClusterNameLabel: "other"},
+					}
+					return c
+				}(),
+			},
+			{
+				name:      "fails if metadata propagation labels are in a kubernetes.io subdomain",
+				expectErr: true,
+				in: func() *clusterv1.Cluster {
+					c := builder.Cluster("fooNamespace", "cluster1").Build()
+					c.Spec.MetadataPropagation = &clusterv1.ObjectMeta{
+						Labels: map[string]string{"node-role.kubernetes.io/control-plane": ""},
+					}
+					return c
+				}(),
+			},
+			{
+				name:      "fails if metadata propagation annotations are reserved",
+				expectErr: true,
+				in: func() *clusterv1.Cluster {
+					c := builder.Cluster("fooNamespace", "cluster1").Build()
+					c.Spec.MetadataPropagation = &clusterv1.ObjectMeta{
+						Annotations: map[string]string{clusterv1.PausedAnnotation: ""},
+					}
+					return c
+				}(),
+			},
+			{
+				name:      "fails if metadata propagation labels are not valid",
+				expectErr: true,
+				in: func() *clusterv1.Cluster {
+					c := builder.Cluster("fooNamespace", "cluster1").Build()
+					c.Spec.MetadataPropagation = &clusterv1.ObjectMeta{
+						Labels: map[string]string{"example.com/team": "not valid!"},
+					}
+					return c
+				}(),
+			},
 		}
 	)
 	for _, tt := range tests {
@@ -1269,6 +1337,36 @@ func TestClusterTopologyValidation(t *testing.T) {
 					Build()).
 				Build(),
 		},
+		{
+			name:      "should pass with valid rollout windows",
+			expectErr: false,
+			in: builder.Cluster("fooboo", "cluster1").
+				WithTopology(builder.ClusterTopology().
+					WithClass("foo").
+					WithVersion("v1.19.1").
+					WithRolloutWindows(clusterv1.RolloutWindow{
+						Schedule: "0 22 * * 1-5",
+						Duration: metav1.Duration{Duration: 4 * time.Hour},
+						TimeZone: ptr.To("Europe/Rome"),
+					}).
+					Build()).
+				Build(),
+		},
+		{
+			name:      "should return error when rollout windows are not valid",
+			expectErr: true,
+			in: builder.Cluster("fooboo", "cluster1").
+				WithTopology(builder.ClusterTopology().
+					WithClass("foo").
+					WithVersion("v1.19.1").
+					WithRolloutWindows(
+						clusterv1.RolloutWindow{Schedule: "0 25 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+						clusterv1.RolloutWindow{Schedule: "0 22 * * *"},
+						clusterv1.RolloutWindow{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: ptr.To("Mars/Olympus")},
+					).
+					Build()).
+				Build(),
+		},
 		{
 			name:      "should pass when MachineDeployments names in a Topology are unique",
 			expectErr: false,
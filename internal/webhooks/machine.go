@@ -121,7 +121,9 @@ func (webhook *Machine) validate(oldM, newM *clusterv1.Machine) error {
 	specPath := field.NewPath("spec")
 	if newM.Spec.Bootstrap.ConfigRef == nil && newM.Spec.Bootstrap.DataSecretName == nil {
 		// MachinePool Machines don't have a bootstrap configRef, so don't require it. The bootstrap config is instead owned by the MachinePool.
-		if !labels.IsMachinePoolOwned(newM) {
+		// Machines adopting an existing Node don't require bootstrap, given the host is already bootstrapped.
+		_, adoptingNode := newM.Annotations[clusterv1.AdoptNodeAnnotation]
+		if !labels.IsMachinePoolOwned(newM) && !adoptingNode {
 			allErrs = append(
 				allErrs,
 				field.Required(
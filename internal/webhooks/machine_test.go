@@ -55,9 +55,10 @@ func TestMachineDefault(t *testing.T) {
 
 func TestMachineBootstrapValidation(t *testing.T) {
 	tests := []struct {
-		name      string
-		bootstrap clusterv1.Bootstrap
-		expectErr bool
+		name        string
+		bootstrap   clusterv1.Bootstrap
+		annotations map[string]string
+		expectErr   bool
 	}{
 		{
 			name:      "should return error if configref and data are nil",
@@ -74,13 +75,20 @@ func TestMachineBootstrapValidation(t *testing.T) {
 			bootstrap: clusterv1.Bootstrap{ConfigRef: &corev1.ObjectReference{}, DataSecretName: nil},
 			expectErr: false,
 		},
+		{
+			name:        "should not return error if configref and data are nil for a Machine adopting a Node",
+			bootstrap:   clusterv1.Bootstrap{ConfigRef: nil, DataSecretName: nil},
+			annotations: map[string]string{clusterv1.AdoptNodeAnnotation: "node-1"},
+			expectErr:   false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := NewWithT(t)
 			m := &clusterv1.Machine{
-				Spec: clusterv1.MachineSpec{Bootstrap: tt.bootstrap},
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				Spec:       clusterv1.MachineSpec{Bootstrap: tt.bootstrap},
 			}
 			webhook := &Machine{}
 
@@ -43,6 +43,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -68,10 +69,22 @@ import (
 	expv1alpha4 "sigs.k8s.io/cluster-api/internal/apis/core/exp/v1alpha4"
 	clusterv1alpha3 "sigs.k8s.io/cluster-api/internal/apis/core/v1alpha3"
 	clusterv1alpha4 "sigs.k8s.io/cluster-api/internal/apis/core/v1alpha4"
+	"sigs.k8s.io/cluster-api/internal/diagnostics"
 	runtimeclient "sigs.k8s.io/cluster-api/internal/runtime/client"
 	runtimeregistry "sigs.k8s.io/cluster-api/internal/runtime/registry"
+	"sigs.k8s.io/cluster-api/internal/util/metadatacache"
+	"sigs.k8s.io/cluster-api/internal/util/metrics"
 	runtimewebhooks "sigs.k8s.io/cluster-api/internal/webhooks/runtime"
+	"sigs.k8s.io/cluster-api/util/cachelimit"
+	"sigs.k8s.io/cluster-api/util/certs"
 	"sigs.k8s.io/cluster-api/util/flags"
+	"sigs.k8s.io/cluster-api/util/identity"
+	"sigs.k8s.io/cluster-api/util/priority"
+	"sigs.k8s.io/cluster-api/util/requeue"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/cluster-api/util/shard"
+	"sigs.k8s.io/cluster-api/util/throttle"
+	"sigs.k8s.io/cluster-api/util/webhookcerts"
 	"sigs.k8s.io/cluster-api/version"
 	"sigs.k8s.io/cluster-api/webhooks"
 )
@@ -88,7 +101,6 @@ var (
 	leaderElectionRenewDeadline time.Duration
 	leaderElectionRetryPeriod   time.Duration
 	watchFilterValue            string
-	watchNamespace              string
 	profilerAddress             string
 	enableContentionProfiling   bool
 	syncPeriod                  time.Duration
@@ -98,11 +110,27 @@ var (
 	webhookCertDir              string
 	healthAddr                  string
 	tlsOptions                  = flags.TLSOptions{}
-	diagnosticsOptions          = flags.DiagnosticsOptions{}
-	logOptions                  = logs.NewOptions()
+	namespaceOptions            = flags.NamespaceOptions{}
+	webhookCertOptions          = flags.WebhookCertOptions{
+		ServiceName:  "capi-webhook-service",
+		SecretName:   "capi-webhook-service-cert",
+		ProviderName: "cluster-api",
+	}
+	keyEncryptionOptions       = flags.KeyEncryptionOptions{}
+	requeueOptions             = flags.RequeueOptions{}
+	workloadClusterAuthOptions = flags.WorkloadClusterAuthOptions{}
+	priorityOptions            = flags.PriorityOptions{}
+	throttleOptions            = flags.ThrottleOptions{}
+	cacheLimitOptions          = flags.CacheLimitOptions{}
+	shardingOptions            = flags.ShardingOptions{}
+	tracingOptions             = flags.TracingOptions{}
+	conditionsMetricsOptions   = flags.ConditionsMetricsOptions{}
+	diagnosticsOptions         = flags.DiagnosticsOptions{}
+	logOptions                 = logs.NewOptions()
 	// core Cluster API specific flags.
 	clusterTopologyConcurrency     int
 	clusterCacheTrackerConcurrency int
+	clusterCacheTrackerIdleTimeout time.Duration
 	clusterClassConcurrency        int
 	clusterConcurrency             int
 	extensionConfigConcurrency     int
@@ -110,9 +138,13 @@ var (
 	machineSetConcurrency          int
 	machineDeploymentConcurrency   int
 	machinePoolConcurrency         int
+	orphanReportConcurrency        int
+	clusterGroupConcurrency        int
 	clusterResourceSetConcurrency  int
 	machineHealthCheckConcurrency  int
 	nodeDrainClientTimeout         time.Duration
+	kubeconfigRotationThreshold    time.Duration
+	runtimeExtensionTokenFile      string
 )
 
 func init() {
@@ -155,9 +187,6 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&leaderElectionRetryPeriod, "leader-elect-retry-period", 2*time.Second,
 		"Duration the LeaderElector clients should wait between tries of actions (duration string)")
 
-	fs.StringVar(&watchNamespace, "namespace", "",
-		"Namespace that the controller watches to reconcile cluster-api objects. If unspecified, the controller watches for cluster-api objects across all namespaces.")
-
 	fs.StringVar(&watchFilterValue, "watch-filter", "",
 		fmt.Sprintf("Label value that the controller watches to reconcile cluster-api objects. Label key is always %s. If unspecified, the controller watches for all cluster-api objects.", clusterv1.WatchLabel))
 
@@ -179,6 +208,13 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&clusterCacheTrackerConcurrency, "clustercachetracker-concurrency", 10,
 		"Number of clusters to process simultaneously")
 
+	fs.DurationVar(&clusterCacheTrackerIdleTimeout, "clustercachetracker-idle-timeout", 0,
+		"Duration after which the connection to a workload cluster which is not used by any controller is torn down; "+
+			"it is re-established on the next use. If zero, connections are never torn down for being idle.")
+
+	fs.DurationVar(&kubeconfigRotationThreshold, "kubeconfig-rotation-threshold", certs.ClientCertificateRenewalDuration,
+		"How long before their expiry the client certificates in the kubeconfig Secrets generated by Cluster API are rotated")
+
 	fs.IntVar(&extensionConfigConcurrency, "extensionconfig-concurrency", 10,
 		"Number of extension configs to process simultaneously")
 
@@ -194,6 +230,12 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&machinePoolConcurrency, "machinepool-concurrency", 10,
 		"Number of machine pools to process simultaneously")
 
+	fs.IntVar(&orphanReportConcurrency, "orphanreport-concurrency", 1,
+		"Number of orphan reports to process simultaneously")
+
+	fs.IntVar(&clusterGroupConcurrency, "clustergroup-concurrency", 10,
+		"Number of cluster groups to process simultaneously")
+
 	fs.IntVar(&clusterResourceSetConcurrency, "clusterresourceset-concurrency", 10,
 		"Number of cluster resource sets to process simultaneously")
 
@@ -209,6 +251,10 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&restConfigBurst, "kube-api-burst", 30,
 		"Maximum number of queries that should be allowed in one burst from the controller client to the Kubernetes API server. Default 30")
 
+	fs.StringVar(&runtimeExtensionTokenFile, "runtime-extension-identity-token-file", "",
+		"Path of a projected service account token, e.g. with audience "+identity.DefaultAudience+", presented to Runtime Extensions "+
+			"to prove the identity of the controllers. If empty, no identity token is presented.")
+
 	fs.DurationVar(&nodeDrainClientTimeout, "node-drain-client-timeout-duration", time.Second*10,
 		"The timeout of the client used for draining nodes. Defaults to 10s")
 
@@ -223,6 +269,17 @@ func InitFlags(fs *pflag.FlagSet) {
 
 	flags.AddDiagnosticsOptions(fs, &diagnosticsOptions)
 	flags.AddTLSOptions(fs, &tlsOptions)
+	flags.AddNamespaceOptions(fs, &namespaceOptions)
+	flags.AddWebhookCertOptions(fs, &webhookCertOptions)
+	flags.AddKeyEncryptionOptions(fs, &keyEncryptionOptions)
+	flags.AddRequeueOptions(fs, &requeueOptions)
+	flags.AddWorkloadClusterAuthOptions(fs, &workloadClusterAuthOptions)
+	flags.AddPriorityOptions(fs, &priorityOptions)
+	flags.AddThrottleOptions(fs, &throttleOptions)
+	flags.AddCacheLimitOptions(fs, &cacheLimitOptions)
+	flags.AddShardingOptions(fs, &shardingOptions)
+	flags.AddTracingOptions(fs, &tracingOptions)
+	flags.AddConditionsMetricsOptions(fs, &conditionsMetricsOptions)
 
 	feature.MutableGates.AddFlag(fs)
 }
@@ -276,13 +333,65 @@ func main() {
 		os.Exit(1)
 	}
 
+	webhookCerts, err := flags.GetWebhookCertOptions(webhookCertOptions, webhookCertDir)
+	if err != nil {
+		setupLog.Error(err, "unable to configure webhook certificates")
+		os.Exit(1)
+	}
+
+	keyEncryptionService, err := flags.GetKeyEncryptionService(keyEncryptionOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure cluster certificate key encryption")
+		os.Exit(1)
+	}
+	secret.SetKeyEncryptionService(keyEncryptionService)
+
+	requeuePolicy, err := flags.GetRequeuePolicy(requeueOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure requeue policy")
+		os.Exit(1)
+	}
+	requeue.SetPolicy(requeuePolicy)
+
+	workloadClusterAuthPolicy, err := flags.GetWorkloadClusterAuthPolicy(workloadClusterAuthOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure workload cluster authentication")
+		os.Exit(1)
+	}
+	remote.SetWorkloadClusterAuthPolicy(workloadClusterAuthPolicy)
+
+	priorityPolicy, err := flags.GetPriorityPolicy(priorityOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure priority policy")
+		os.Exit(1)
+	}
+	priority.SetPolicy(priorityPolicy)
+
+	throttlePolicy, err := flags.GetThrottlePolicy(throttleOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure per-cluster reconcile limits")
+		os.Exit(1)
+	}
+	throttle.SetPolicy(throttlePolicy)
+
+	cacheLimitPolicy, err := flags.GetCacheLimitPolicy(cacheLimitOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure cache safeguards")
+		os.Exit(1)
+	}
+	cachelimit.SetPolicy(cacheLimitPolicy)
+
 	diagnosticsOpts := flags.GetDiagnosticsOptions(diagnosticsOptions)
+	// The fleet summary is served only if the diagnostics endpoint is protected via authentication and authorization.
+	fleetSummaryHandler := diagnostics.NewFleetSummaryHandler()
+	if diagnosticsOpts.ExtraHandlers != nil {
+		diagnosticsOpts.ExtraHandlers[diagnostics.FleetSummaryPath] = fleetSummaryHandler
+	}
 
-	var watchNamespaces map[string]cache.Config
-	if watchNamespace != "" {
-		watchNamespaces = map[string]cache.Config{
-			watchNamespace: {},
-		}
+	watchNamespaces, err := flags.GetWatchNamespaces(namespaceOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure watch namespaces")
+		os.Exit(1)
 	}
 
 	if enableContentionProfiling {
@@ -295,7 +404,7 @@ func main() {
 	ctrlOptions := ctrl.Options{
 		Scheme:                     scheme,
 		LeaderElection:             enableLeaderElection,
-		LeaderElectionID:           "controller-leader-election-capi",
+		LeaderElectionID:           flags.GetShardLeaderElectionID("controller-leader-election-capi", shardingOptions),
 		LeaseDuration:              &leaderElectionLeaseDuration,
 		RenewDeadline:              &leaderElectionRenewDeadline,
 		RetryPeriod:                &leaderElectionRetryPeriod,
@@ -303,13 +412,15 @@ func main() {
 		HealthProbeBindAddress:     healthAddr,
 		PprofBindAddress:           profilerAddress,
 		Metrics:                    diagnosticsOpts,
+		NewCache:                   cachelimit.NewCacheFunc(cachelimit.ManagementCacheName),
 		Cache: cache.Options{
 			DefaultNamespaces: watchNamespaces,
 			SyncPeriod:        &syncPeriod,
 			ByObject: map[client.Object]cache.ByObject{
-				// Note: Only Secrets with the cluster name label are cached.
+				// Note: Only Secrets with the cluster name label are cached, and only their metadata is cached.
 				// The default client of the manager won't use the cache for secrets at all (see Client.Cache.DisableFor).
-				// The cached secrets will only be used by the secretCachingClient we create below.
+				// The cached metadata will only be used by the secretCachingClient we create below, which fetches
+				// the full Secrets from the API server only when they changed.
 				&corev1.Secret{}: {
 					Label: clusterSecretCacheSelector,
 				},
@@ -337,22 +448,66 @@ func main() {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
+	fleetSummaryHandler.SetClient(mgr.GetClient())
+
+	managerShard, err := flags.GetShard(shardingOptions, mgr.GetClient())
+	if err != nil {
+		setupLog.Error(err, "unable to configure sharding")
+		os.Exit(1)
+	}
+	shard.Set(managerShard)
 
 	// Setup the context that's going to be used in controllers and for the manager.
 	ctx := ctrl.SetupSignalHandler()
 
+	setupWebhookCerts(ctx, mgr, webhookCerts)
 	setupChecks(mgr)
 	setupIndexes(ctx, mgr)
+	setupConditionsMetrics(mgr)
 	tracker := setupReconcilers(ctx, mgr)
 	setupWebhooks(mgr, tracker)
 
+	shutdownTracing, err := flags.SetupTracing(ctx, "capi-controller-manager", tracingOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to setup tracing")
+		os.Exit(1)
+	}
+
 	setupLog.Info("starting manager", "version", version.Get().String())
-	if err := mgr.Start(ctx); err != nil {
+	err = mgr.Start(ctx)
+	// Flush the spans not exported yet before exiting.
+	if err := shutdownTracing(context.Background()); err != nil {
+		setupLog.Error(err, "unable to shutdown tracing")
+	}
+	if err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
 
+// setupWebhookCerts ensures the self-managed webhook certificates exist before the webhook server is started
+// and keeps them valid while the manager is running.
+func setupWebhookCerts(ctx context.Context, mgr ctrl.Manager, options *webhookcerts.Options) {
+	if options == nil {
+		return
+	}
+	// The cache of the manager is not started yet, and there is no need to cache these objects anyway.
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		setupLog.Error(err, "unable to create client for webhook certificates")
+		os.Exit(1)
+	}
+	certManager := webhookcerts.New(c, *options)
+	if _, err := certManager.Ensure(ctx); err != nil {
+		setupLog.Error(err, "unable to ensure webhook certificates")
+		os.Exit(1)
+	}
+	if err := mgr.Add(certManager); err != nil {
+		setupLog.Error(err, "unable to add webhook certificates manager")
+		os.Exit(1)
+	}
+}
+
 func setupChecks(mgr ctrl.Manager) {
 	if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
 		setupLog.Error(err, "unable to create ready check")
@@ -372,12 +527,18 @@ func setupIndexes(ctx context.Context, mgr ctrl.Manager) {
 	}
 }
 
+// setupConditionsMetrics registers the collector exposing the conditions of the objects reconciled by the manager,
+// if enabled with --conditions-metrics-types.
+func setupConditionsMetrics(mgr ctrl.Manager) {
+	if len(conditionsMetricsOptions.ConditionTypes) == 0 {
+		return
+	}
+	ctrlmetrics.Registry.MustRegister(metrics.NewConditionsCollector(mgr.GetClient(), conditionsMetricsOptions.ConditionTypes, &clusterv1.ClusterList{}, &clusterv1.MachineList{}))
+}
+
 func setupReconcilers(ctx context.Context, mgr ctrl.Manager) webhooks.ClusterCacheTrackerReader {
-	secretCachingClient, err := client.New(mgr.GetConfig(), client.Options{
-		HTTPClient: mgr.GetHTTPClient(),
-		Cache: &client.CacheOptions{
-			Reader: mgr.GetCache(),
-		},
+	secretCachingClient, err := metadatacache.NewClient(mgr.GetClient(), mgr.GetCache(), metadatacache.Options{
+		Objects: []client.Object{&corev1.Secret{}},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to create secret caching client")
@@ -391,6 +552,7 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) webhooks.ClusterCac
 		remote.ClusterCacheTrackerOptions{
 			SecretCachingClient: secretCachingClient,
 			ControllerName:      controllerName,
+			IdleTimeout:         clusterCacheTrackerIdleTimeout,
 			Log:                 &ctrl.Log,
 			Indexes:             []remote.Index{remote.NodeProviderIDIndex},
 		},
@@ -412,10 +574,15 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) webhooks.ClusterCac
 	var runtimeClient runtimeclient.Client
 	if feature.Gates.Enabled(feature.RuntimeSDK) {
 		// This is the creation of the runtimeClient for the controllers, embedding a shared catalog and registry instance.
+		var tokenSource identity.TokenSource
+		if runtimeExtensionTokenFile != "" {
+			tokenSource = identity.NewFileTokenSource(runtimeExtensionTokenFile)
+		}
 		runtimeClient = runtimeclient.New(runtimeclient.Options{
-			Catalog:  catalog,
-			Registry: runtimeregistry.New(),
-			Client:   mgr.GetClient(),
+			Catalog:     catalog,
+			Registry:    runtimeregistry.New(),
+			Client:      mgr.GetClient(),
+			TokenSource: tokenSource,
 		})
 	}
 
@@ -494,11 +661,22 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) webhooks.ClusterCac
 		setupLog.Error(err, "unable to create controller", "controller", "Cluster")
 		os.Exit(1)
 	}
+	if err := (&controllers.KubeconfigRotationReconciler{
+		Client:              mgr.GetClient(),
+		SecretCachingClient: secretCachingClient,
+		Tracker:             tracker,
+		WatchFilterValue:    watchFilterValue,
+		RenewalThreshold:    kubeconfigRotationThreshold,
+	}).SetupWithManager(ctx, mgr, concurrency(clusterConcurrency)); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KubeconfigRotation")
+		os.Exit(1)
+	}
 	if err := (&controllers.MachineReconciler{
 		Client:                    mgr.GetClient(),
 		UnstructuredCachingClient: unstructuredCachingClient,
 		APIReader:                 mgr.GetAPIReader(),
 		Tracker:                   tracker,
+		RuntimeClient:             runtimeClient,
 		WatchFilterValue:          watchFilterValue,
 		NodeDrainClientTimeout:    nodeDrainClientTimeout,
 	}).SetupWithManager(ctx, mgr, concurrency(machineConcurrency)); err != nil {
@@ -537,9 +715,41 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) webhooks.ClusterCac
 		}
 	}
 
+	if feature.Gates.Enabled(feature.OrphanDetection) {
+		if err := (&expcontrollers.OrphanReportReconciler{
+			Client:           mgr.GetClient(),
+			APIReader:        mgr.GetAPIReader(),
+			WatchFilterValue: watchFilterValue,
+		}).SetupWithManager(ctx, mgr, concurrency(orphanReportConcurrency)); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "OrphanReport")
+			os.Exit(1)
+		}
+	}
+
+	if feature.Gates.Enabled(feature.ClusterGroup) {
+		if err := (&expcontrollers.ClusterGroupReconciler{
+			Client:           mgr.GetClient(),
+			WatchFilterValue: watchFilterValue,
+		}).SetupWithManager(ctx, mgr, concurrency(clusterGroupConcurrency)); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ClusterGroup")
+			os.Exit(1)
+		}
+	}
+
 	if feature.Gates.Enabled(feature.ClusterResourceSet) {
+		// The ClusterResourceSet controller already watches the metadata of ConfigMaps, so it reads
+		// ConfigMaps using the metadata cache instead of issuing a GET for every ConfigMap at every reconcile.
+		// Note: Secrets are still read from the API server, because the referenced Secrets usually don't
+		// have the cluster name label and thus are not in the cache.
+		clusterResourceSetClient, err := metadatacache.NewClient(mgr.GetClient(), mgr.GetCache(), metadatacache.Options{
+			Objects: []client.Object{&corev1.ConfigMap{}},
+		})
+		if err != nil {
+			setupLog.Error(err, "unable to create ClusterResourceSet client")
+			os.Exit(1)
+		}
 		if err := (&addonscontrollers.ClusterResourceSetReconciler{
-			Client:           mgr.GetClient(),
+			Client:           clusterResourceSetClient,
 			Tracker:          tracker,
 			WatchFilterValue: watchFilterValue,
 		}).SetupWithManager(ctx, mgr, concurrency(clusterResourceSetConcurrency)); err != nil {
@@ -0,0 +1,24 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conformance implements checks that infrastructure, bootstrap and control plane providers
+// can embed in their envtest or e2e tests to verify they comply with the Cluster API contract.
+//
+// The checks are grouped in versioned Profiles, so providers can pin the contract they implement
+// and run the checks of the next contract to catch drift early.
+// ValidateCRD and ValidateObject only look at the given objects, while the Verify functions
+// run against a management cluster where the provider controllers are running.
+package conformance
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"github.com/pkg/errors"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// Kind is the kind of provider object verified by the checks.
+type Kind string
+
+const (
+	// InfrastructureCluster is the kind of the objects referenced by Cluster.spec.infrastructureRef.
+	InfrastructureCluster Kind = "InfrastructureCluster"
+
+	// InfrastructureMachine is the kind of the objects referenced by Machine.spec.infrastructureRef.
+	InfrastructureMachine Kind = "InfrastructureMachine"
+
+	// BootstrapConfig is the kind of the objects referenced by Machine.spec.bootstrap.configRef.
+	BootstrapConfig Kind = "BootstrapConfig"
+
+	// ControlPlane is the kind of the objects referenced by Cluster.spec.controlPlaneRef.
+	ControlPlane Kind = "ControlPlane"
+)
+
+// Profile is a versioned set of rules of the Cluster API contract.
+type Profile struct {
+	// Name is the name of the profile.
+	Name string
+
+	// Contract is the Cluster API contract version, e.g. v1beta1.
+	// CRDs must have the cluster.x-k8s.io/<contract> label pointing to served versions.
+	Contract string
+
+	// V1Beta2Conditions requires objects to report conditions in the metav1.Condition format
+	// under status.v1beta2.conditions, as defined by the v1beta2 API.
+	V1Beta2Conditions bool
+
+	// RequiredV1Beta2Conditions are the condition types every object must report under status.v1beta2.conditions,
+	// e.g. Paused.
+	RequiredV1Beta2Conditions []string
+}
+
+const (
+	// V1Beta1ProfileName is the name of the profile of the v1beta1 contract.
+	V1Beta1ProfileName = "v1beta1"
+
+	// V1Beta1WithV1Beta2ConditionsProfileName is the name of the profile of the v1beta1 contract with the conditions
+	// of the v1beta2 API, which are going to be required by the v1beta2 contract.
+	V1Beta1WithV1Beta2ConditionsProfileName = "v1beta1-v1beta2conditions"
+)
+
+// V1Beta1 returns the profile of the v1beta1 contract.
+func V1Beta1() Profile {
+	return Profile{
+		Name:     V1Beta1ProfileName,
+		Contract: clusterv1.GroupVersion.Version,
+	}
+}
+
+// V1Beta1WithV1Beta2Conditions returns the profile of the v1beta1 contract with the conditions of the v1beta2 API.
+func V1Beta1WithV1Beta2Conditions() Profile {
+	return Profile{
+		Name:                      V1Beta1WithV1Beta2ConditionsProfileName,
+		Contract:                  clusterv1.GroupVersion.Version,
+		V1Beta2Conditions:         true,
+		RequiredV1Beta2Conditions: []string{clusterv1.PausedV1Beta2Condition},
+	}
+}
+
+// Latest returns the profile with the most recent rules of the contract; providers can run it
+// next to the profile they implement to catch drift early.
+func Latest() Profile {
+	return V1Beta1WithV1Beta2Conditions()
+}
+
+// GetProfile returns the profile with the given name.
+func GetProfile(name string) (Profile, error) {
+	for _, p := range []Profile{V1Beta1(), V1Beta1WithV1Beta2Conditions()} {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return Profile{}, errors.Errorf("unknown contract profile %q", name)
+}
@@ -0,0 +1,337 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/internal/contract"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	utilcontract "sigs.k8s.io/cluster-api/util/contract"
+	"sigs.k8s.io/cluster-api/util/controlplane"
+)
+
+// v1beta2ConditionsPath is the path of the conditions in the metav1.Condition format defined by the v1beta2 API.
+var v1beta2ConditionsPath = []string{"status", "v1beta2", "conditions"}
+
+// ValidateCRD validates the CRD of a provider object against the profile:
+// the CRD must be named according to the contract and it must have the API version label of the contract,
+// pointing to served versions.
+func ValidateCRD(profile Profile, crd *apiextensionsv1.CustomResourceDefinition) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if name := utilcontract.CalculateCRDName(crd.Spec.Group, crd.Spec.Names.Kind); crd.Name != name {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), crd.Name, fmt.Sprintf("must be %q", name)))
+	}
+
+	label := fmt.Sprintf("%s/%s", clusterv1.GroupVersion.Group, profile.Contract)
+	labelPath := field.NewPath("metadata", "labels").Key(label)
+	versions, ok := crd.Labels[label]
+	if !ok {
+		allErrs = append(allErrs, field.Required(labelPath, "must point to the CRD versions implementing the contract"))
+		return allErrs
+	}
+	served := sets.Set[string]{}
+	for _, v := range crd.Spec.Versions {
+		if v.Served {
+			served.Insert(v.Name)
+		}
+	}
+	for _, v := range strings.Split(versions, "_") {
+		if !served.Has(v) {
+			allErrs = append(allErrs, field.Invalid(labelPath, versions, fmt.Sprintf("version %q is not served by the CRD", v)))
+		}
+	}
+	return allErrs
+}
+
+// ValidateObject validates a provider object of the given kind against the profile:
+// the fields required by the contract must be set, and conditions must follow the condition semantics.
+func ValidateObject(profile Profile, kind Kind, obj *unstructured.Unstructured) field.ErrorList {
+	var allErrs field.ErrorList
+
+	switch kind {
+	case InfrastructureCluster:
+		allErrs = append(allErrs, validateInfrastructureCluster(obj)...)
+	case InfrastructureMachine:
+		allErrs = append(allErrs, validateInfrastructureMachine(obj)...)
+	case BootstrapConfig:
+		allErrs = append(allErrs, validateBootstrapConfig(obj)...)
+	case ControlPlane:
+		allErrs = append(allErrs, validateControlPlane(obj)...)
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("kind"), kind, []Kind{InfrastructureCluster, InfrastructureMachine, BootstrapConfig, ControlPlane}))
+		return allErrs
+	}
+
+	allErrs = append(allErrs, validateConditions(obj)...)
+	if profile.V1Beta2Conditions {
+		allErrs = append(allErrs, validateV1Beta2Conditions(profile, obj)...)
+	}
+	return allErrs
+}
+
+func validateInfrastructureCluster(obj *unstructured.Unstructured) field.ErrorList {
+	var allErrs field.ErrorList
+
+	ready, readyErrs := getBool(obj, contract.InfrastructureCluster().Ready())
+	allErrs = append(allErrs, readyErrs...)
+	if _, err := contract.InfrastructureCluster().FailureDomains().Get(obj); err != nil && !errors.Is(err, contract.ErrFieldNotFound) {
+		allErrs = append(allErrs, field.Invalid(toFieldPath(contract.InfrastructureCluster().FailureDomains().Path()), nil, err.Error()))
+	}
+	if !ready {
+		return allErrs
+	}
+
+	host, hostErrs := getString(obj, contract.InfrastructureCluster().ControlPlaneEndpoint().Host())
+	allErrs = append(allErrs, hostErrs...)
+	port, portErrs := getInt64(obj, contract.InfrastructureCluster().ControlPlaneEndpoint().Port())
+	allErrs = append(allErrs, portErrs...)
+	if host == "" || port == 0 {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "controlPlaneEndpoint"), "must be set when status.ready is true"))
+	}
+	return allErrs
+}
+
+func validateInfrastructureMachine(obj *unstructured.Unstructured) field.ErrorList {
+	var allErrs field.ErrorList
+
+	ready, readyErrs := getBool(obj, contract.InfrastructureMachine().Ready())
+	allErrs = append(allErrs, readyErrs...)
+	if _, err := contract.InfrastructureMachine().Addresses().Get(obj); err != nil && !errors.Is(err, contract.ErrFieldNotFound) {
+		allErrs = append(allErrs, field.Invalid(toFieldPath(contract.InfrastructureMachine().Addresses().Path()), nil, err.Error()))
+	}
+	if !ready {
+		return allErrs
+	}
+
+	providerID, providerIDErrs := getString(obj, contract.InfrastructureMachine().ProviderID())
+	allErrs = append(allErrs, providerIDErrs...)
+	if providerID == "" {
+		allErrs = append(allErrs, field.Required(toFieldPath(contract.InfrastructureMachine().ProviderID().Path()), "must be set when status.ready is true"))
+	}
+	return allErrs
+}
+
+func validateBootstrapConfig(obj *unstructured.Unstructured) field.ErrorList {
+	var allErrs field.ErrorList
+
+	ready, readyErrs := getBool(obj, contract.Bootstrap().Ready())
+	allErrs = append(allErrs, readyErrs...)
+	if !ready {
+		return allErrs
+	}
+
+	dataSecretName, dataSecretNameErrs := getString(obj, contract.Bootstrap().DataSecretName())
+	allErrs = append(allErrs, dataSecretNameErrs...)
+	if dataSecretName == "" {
+		allErrs = append(allErrs, field.Required(toFieldPath(contract.Bootstrap().DataSecretName().Path()), "must be set when status.ready is true"))
+	}
+	return allErrs
+}
+
+func validateControlPlane(obj *unstructured.Unstructured) field.ErrorList {
+	var allErrs field.ErrorList
+
+	ready, readyErrs := getBool(obj, contract.ControlPlane().Ready())
+	allErrs = append(allErrs, readyErrs...)
+	_, initializedErrs := getBool(obj, contract.ControlPlane().Initialized())
+	allErrs = append(allErrs, initializedErrs...)
+	// Externally managed control planes can omit status.initialized, see controlplane.IsInitialized.
+	initialized, _ := controlplane.IsInitialized(obj)
+	if ready && !initialized {
+		allErrs = append(allErrs, field.Required(toFieldPath(contract.ControlPlane().Initialized().Path()), "must be true when status.ready is true"))
+	}
+
+	for _, version := range []*contract.String{contract.ControlPlane().Version(), contract.ControlPlane().StatusVersion()} {
+		v, errs := getString(obj, version)
+		allErrs = append(allErrs, errs...)
+		if v == "" {
+			continue
+		}
+		if _, err := semver.ParseTolerant(v); err != nil {
+			allErrs = append(allErrs, field.Invalid(toFieldPath(version.Path()), v, "must be a valid semantic version"))
+		}
+	}
+
+	// Control planes implementing replicas must report the selector for the control plane Machines.
+	if _, err := contract.ControlPlane().Replicas().Get(obj); err == nil {
+		statusReplicas, errs := getInt64(obj, contract.ControlPlane().StatusReplicas())
+		allErrs = append(allErrs, errs...)
+		selector, errs := getString(obj, contract.ControlPlane().Selector())
+		allErrs = append(allErrs, errs...)
+		if statusReplicas > 0 && selector == "" {
+			allErrs = append(allErrs, field.Required(toFieldPath(contract.ControlPlane().Selector().Path()), "must be set when status.replicas is greater than zero"))
+		}
+	}
+	return allErrs
+}
+
+// validateConditions validates the conditions in the Cluster API condition format under status.conditions.
+func validateConditions(obj *unstructured.Unstructured) field.ErrorList {
+	var allErrs field.ErrorList
+	fldPath := field.NewPath("status", "conditions")
+
+	if _, err := getSlice(obj, "status", "conditions"); err != nil {
+		return append(allErrs, field.Invalid(fldPath, nil, err.Error()))
+	}
+	conds := conditions.UnstructuredGetter(obj).GetConditions()
+	if len(conds) == 0 {
+		return allErrs
+	}
+
+	types := sets.Set[clusterv1.ConditionType]{}
+	for i, c := range conds {
+		idxPath := fldPath.Index(i)
+		if c.Type == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("type"), ""))
+		}
+		if types.Has(c.Type) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("type"), c.Type))
+		}
+		types.Insert(c.Type)
+		if c.LastTransitionTime.IsZero() {
+			allErrs = append(allErrs, field.Required(idxPath.Child("lastTransitionTime"), ""))
+		}
+
+		switch c.Status {
+		case corev1.ConditionTrue:
+			if c.Severity != clusterv1.ConditionSeverityNone {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("severity"), c.Severity, "must be empty when status is True"))
+			}
+		case corev1.ConditionFalse:
+			if c.Severity == clusterv1.ConditionSeverityNone {
+				allErrs = append(allErrs, field.Required(idxPath.Child("severity"), "must be set when status is False"))
+			}
+			if c.Reason == "" {
+				allErrs = append(allErrs, field.Required(idxPath.Child("reason"), "must be set when status is False"))
+			}
+		case corev1.ConditionUnknown:
+		default:
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("status"), c.Status, []corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionUnknown}))
+		}
+	}
+
+	// The Ready condition summarizes the other conditions.
+	if !types.Has(clusterv1.ReadyCondition) {
+		allErrs = append(allErrs, field.Required(fldPath, fmt.Sprintf("must include the %s condition", clusterv1.ReadyCondition)))
+	}
+	return allErrs
+}
+
+// validateV1Beta2Conditions validates the conditions in the metav1.Condition format under status.v1beta2.conditions.
+func validateV1Beta2Conditions(profile Profile, obj *unstructured.Unstructured) field.ErrorList {
+	var allErrs field.ErrorList
+	fldPath := toFieldPath(v1beta2ConditionsPath)
+
+	conds, err := getV1Beta2Conditions(obj)
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath, nil, err.Error()))
+	}
+	allErrs = append(allErrs, metav1validation.ValidateConditions(conds, fldPath)...)
+
+	types := sets.Set[string]{}
+	for i, c := range conds {
+		types.Insert(c.Type)
+		if c.ObservedGeneration > obj.GetGeneration() {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("observedGeneration"), c.ObservedGeneration, "must not be greater than metadata.generation"))
+		}
+	}
+	for _, t := range profile.RequiredV1Beta2Conditions {
+		if !types.Has(t) {
+			allErrs = append(allErrs, field.Required(fldPath, fmt.Sprintf("must include the %s condition", t)))
+		}
+	}
+	return allErrs
+}
+
+// getV1Beta2Conditions returns the conditions under status.v1beta2.conditions.
+func getV1Beta2Conditions(obj *unstructured.Unstructured) ([]metav1.Condition, error) {
+	items, err := getSlice(obj, v1beta2ConditionsPath...)
+	if err != nil {
+		return nil, err
+	}
+	conds := make([]metav1.Condition, 0, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("condition must be an object, got %T", item)
+		}
+		c := metav1.Condition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, &c); err != nil {
+			return nil, errors.Wrap(err, "failed to convert condition")
+		}
+		conds = append(conds, c)
+	}
+	return conds, nil
+}
+
+// getSlice returns the slice at the given path, or nil if the path does not exist.
+func getSlice(obj *unstructured.Unstructured, fields ...string) ([]interface{}, error) {
+	items, _, err := unstructured.NestedSlice(obj.UnstructuredContent(), fields...)
+	return items, err
+}
+
+func getBool(obj *unstructured.Unstructured, b *contract.Bool) (bool, field.ErrorList) {
+	v, err := b.Get(obj)
+	if err != nil {
+		if errors.Is(err, contract.ErrFieldNotFound) {
+			return false, nil
+		}
+		return false, field.ErrorList{field.TypeInvalid(toFieldPath(b.Path()), nil, err.Error())}
+	}
+	return *v, nil
+}
+
+func getString(obj *unstructured.Unstructured, s *contract.String) (string, field.ErrorList) {
+	v, err := s.Get(obj)
+	if err != nil {
+		if errors.Is(err, contract.ErrFieldNotFound) {
+			return "", nil
+		}
+		return "", field.ErrorList{field.TypeInvalid(toFieldPath(s.Path()), nil, err.Error())}
+	}
+	return *v, nil
+}
+
+func getInt64(obj *unstructured.Unstructured, i *contract.Int64) (int64, field.ErrorList) {
+	v, err := i.Get(obj)
+	if err != nil {
+		if errors.Is(err, contract.ErrFieldNotFound) {
+			return 0, nil
+		}
+		return 0, field.ErrorList{field.TypeInvalid(toFieldPath(i.Path()), nil, err.Error())}
+	}
+	return *v, nil
+}
+
+func toFieldPath(path []string) *field.Path {
+	return field.NewPath(path[0], path[1:]...)
+}
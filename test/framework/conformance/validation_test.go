@@ -0,0 +1,237 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestValidateCRD(t *testing.T) {
+	crd := func(name string, labels map[string]string) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Group: "infrastructure.cluster.x-k8s.io",
+				Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "FooMachine"},
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+					{Name: "v1alpha1", Served: false},
+					{Name: "v1beta1", Served: true},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		crd     *apiextensionsv1.CustomResourceDefinition
+		wantErr bool
+	}{
+		{
+			name: "valid CRD",
+			crd:  crd("foomachines.infrastructure.cluster.x-k8s.io", map[string]string{"cluster.x-k8s.io/v1beta1": "v1beta1"}),
+		},
+		{
+			name:    "CRD name not following the contract",
+			crd:     crd("foo.infrastructure.cluster.x-k8s.io", map[string]string{"cluster.x-k8s.io/v1beta1": "v1beta1"}),
+			wantErr: true,
+		},
+		{
+			name:    "missing contract label",
+			crd:     crd("foomachines.infrastructure.cluster.x-k8s.io", map[string]string{"cluster.x-k8s.io/v1alpha4": "v1beta1"}),
+			wantErr: true,
+		},
+		{
+			name:    "contract label pointing to a version not served",
+			crd:     crd("foomachines.infrastructure.cluster.x-k8s.io", map[string]string{"cluster.x-k8s.io/v1beta1": "v1alpha1_v1beta1"}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			errs := ValidateCRD(V1Beta1(), tt.crd)
+			if tt.wantErr {
+				g.Expect(errs).ToNot(BeEmpty())
+				return
+			}
+			g.Expect(errs).To(BeEmpty())
+		})
+	}
+}
+
+func TestValidateObject(t *testing.T) {
+	readyCondition := map[string]interface{}{"type": "Ready", "status": "True", "lastTransitionTime": "2024-01-01T00:00:00Z"}
+	pausedCondition := map[string]interface{}{"type": "Paused", "status": "False", "reason": "NotPaused", "lastTransitionTime": "2024-01-01T00:00:00Z", "observedGeneration": int64(1)}
+
+	tests := []struct {
+		name    string
+		profile Profile
+		kind    Kind
+		obj     map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:    "ready InfrastructureMachine with providerID",
+			profile: V1Beta1(),
+			kind:    InfrastructureMachine,
+			obj: map[string]interface{}{
+				"spec":   map[string]interface{}{"providerID": "foo://machine"},
+				"status": map[string]interface{}{"ready": true, "conditions": []interface{}{readyCondition}},
+			},
+		},
+		{
+			name:    "ready InfrastructureMachine without providerID",
+			profile: V1Beta1(),
+			kind:    InfrastructureMachine,
+			obj: map[string]interface{}{
+				"status": map[string]interface{}{"ready": true},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "ready InfrastructureCluster without control plane endpoint",
+			profile: V1Beta1(),
+			kind:    InfrastructureCluster,
+			obj: map[string]interface{}{
+				"status": map[string]interface{}{"ready": true},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "ready BootstrapConfig without data secret",
+			profile: V1Beta1(),
+			kind:    BootstrapConfig,
+			obj: map[string]interface{}{
+				"status": map[string]interface{}{"ready": true},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "ready ControlPlane not initialized",
+			profile: V1Beta1(),
+			kind:    ControlPlane,
+			obj: map[string]interface{}{
+				"status": map[string]interface{}{"ready": true},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "ControlPlane with replicas and without selector",
+			profile: V1Beta1(),
+			kind:    ControlPlane,
+			obj: map[string]interface{}{
+				"spec":   map[string]interface{}{"replicas": int64(3), "version": "v1.29.0"},
+				"status": map[string]interface{}{"replicas": int64(3), "initialized": true},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "ready field with invalid type",
+			profile: V1Beta1(),
+			kind:    BootstrapConfig,
+			obj: map[string]interface{}{
+				"status": map[string]interface{}{"ready": "true"},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "conditions without the Ready condition",
+			profile: V1Beta1(),
+			kind:    BootstrapConfig,
+			obj: map[string]interface{}{
+				"status": map[string]interface{}{"conditions": []interface{}{
+					map[string]interface{}{"type": "DataSecretAvailable", "status": "True", "lastTransitionTime": "2024-01-01T00:00:00Z"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "false condition without severity",
+			profile: V1Beta1(),
+			kind:    BootstrapConfig,
+			obj: map[string]interface{}{
+				"status": map[string]interface{}{"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": "False", "reason": "WaitingForControlPlane", "lastTransitionTime": "2024-01-01T00:00:00Z"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "missing v1beta2 conditions are allowed by the v1beta1 profile",
+			profile: V1Beta1(),
+			kind:    BootstrapConfig,
+			obj:     map[string]interface{}{},
+		},
+		{
+			name:    "missing v1beta2 conditions",
+			profile: V1Beta1WithV1Beta2Conditions(),
+			kind:    BootstrapConfig,
+			obj:     map[string]interface{}{},
+			wantErr: true,
+		},
+		{
+			name:    "v1beta2 conditions",
+			profile: V1Beta1WithV1Beta2Conditions(),
+			kind:    BootstrapConfig,
+			obj: map[string]interface{}{
+				"status": map[string]interface{}{"v1beta2": map[string]interface{}{"conditions": []interface{}{pausedCondition}}},
+			},
+		},
+		{
+			name:    "v1beta2 conditions without reason",
+			profile: V1Beta1WithV1Beta2Conditions(),
+			kind:    BootstrapConfig,
+			obj: map[string]interface{}{
+				"status": map[string]interface{}{"v1beta2": map[string]interface{}{"conditions": []interface{}{
+					map[string]interface{}{"type": "Paused", "status": "False", "lastTransitionTime": "2024-01-01T00:00:00Z"},
+				}}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "v1beta2 conditions observing a future generation",
+			profile: V1Beta1WithV1Beta2Conditions(),
+			kind:    BootstrapConfig,
+			obj: map[string]interface{}{
+				"status": map[string]interface{}{"v1beta2": map[string]interface{}{"conditions": []interface{}{
+					map[string]interface{}{"type": "Paused", "status": "False", "reason": "NotPaused", "lastTransitionTime": "2024-01-01T00:00:00Z", "observedGeneration": int64(2)},
+				}}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &unstructured.Unstructured{Object: tt.obj}
+			obj.SetGeneration(1)
+			errs := ValidateObject(tt.profile, tt.kind, obj)
+			if tt.wantErr {
+				g.Expect(errs).ToNot(BeEmpty())
+				return
+			}
+			g.Expect(errs).To(BeEmpty())
+		})
+	}
+}
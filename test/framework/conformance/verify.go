@@ -0,0 +1,266 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	utilcontract "sigs.k8s.io/cluster-api/util/contract"
+)
+
+const (
+	// reconcileTriggerAnnotation is changed on the provider object to trigger a reconcile while it is paused.
+	reconcileTriggerAnnotation = "conformance.cluster.x-k8s.io/reconcile-trigger"
+
+	defaultTimeout      = 2 * time.Minute
+	defaultPollInterval = time.Second
+	defaultPausedPeriod = 10 * time.Second
+)
+
+// VerifyInput is the input for the checks running against a management cluster, e.g. envtest or the
+// management cluster of an e2e test, where the provider controllers are running.
+type VerifyInput struct {
+	// Client is the client for the management cluster; its scheme must include the Cluster API and the apiextensions types.
+	Client client.Client
+
+	// Profile is the contract profile to verify.
+	Profile Profile
+
+	// Kind is the kind of the provider object.
+	Kind Kind
+
+	// Object identifies the provider object to verify by apiVersion, kind, namespace and name.
+	Object *unstructured.Unstructured
+
+	// Cluster is the Cluster the provider object belongs to; it is required by VerifyPaused.
+	Cluster *clusterv1.Cluster
+
+	// Timeout is how long to wait for the provider to react, defaulting to 2 minutes.
+	Timeout time.Duration
+
+	// PollInterval is how often the provider object is read while waiting, defaulting to 1 second.
+	PollInterval time.Duration
+
+	// PausedPeriod is how long the provider object must not be reconciled after pausing, defaulting to 10 seconds.
+	PausedPeriod time.Duration
+}
+
+func (input *VerifyInput) defaults() {
+	if input.Timeout == 0 {
+		input.Timeout = defaultTimeout
+	}
+	if input.PollInterval == 0 {
+		input.PollInterval = defaultPollInterval
+	}
+	if input.PausedPeriod == 0 {
+		input.PausedPeriod = defaultPausedPeriod
+	}
+}
+
+// Verify runs all the checks of the profile against the provider object.
+func Verify(ctx context.Context, input VerifyInput) error {
+	var errs []error
+	if err := VerifyCRD(ctx, input); err != nil {
+		errs = append(errs, err)
+	}
+	if err := VerifyObject(ctx, input); err != nil {
+		errs = append(errs, err)
+	}
+	if err := VerifyPaused(ctx, input); err != nil {
+		errs = append(errs, err)
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+// VerifyCRD validates the CRD of the provider object, see ValidateCRD.
+func VerifyCRD(ctx context.Context, input VerifyInput) error {
+	gvk := input.Object.GroupVersionKind()
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := input.Client.Get(ctx, client.ObjectKey{Name: utilcontract.CalculateCRDName(gvk.Group, gvk.Kind)}, crd); err != nil {
+		return errors.Wrapf(err, "failed to get CRD for %s", gvk.GroupKind())
+	}
+	if errs := ValidateCRD(input.Profile, crd); len(errs) > 0 {
+		return errors.Wrapf(errs.ToAggregate(), "CRD %s does not comply with the %s contract profile", crd.Name, input.Profile.Name)
+	}
+	return nil
+}
+
+// VerifyObject validates the provider object as currently stored in the management cluster, see ValidateObject.
+func VerifyObject(ctx context.Context, input VerifyInput) error {
+	obj, err := getObject(ctx, input.Client, input.Object)
+	if err != nil {
+		return err
+	}
+	if errs := ValidateObject(input.Profile, input.Kind, obj); len(errs) > 0 {
+		return errors.Wrapf(errs.ToAggregate(), "%s %s does not comply with the %s contract profile", obj.GetKind(), klog.KObj(obj), input.Profile.Name)
+	}
+	return nil
+}
+
+// VerifyPaused verifies the provider handles the Cluster being paused:
+//   - the clusterctl block-move annotation, if any, is removed once the provider object has actually been paused,
+//   - with profiles requiring v1beta2 conditions, the Paused condition is set to true,
+//   - the provider object is not reconciled while paused, i.e. its status and finalizers do not change
+//     after a reconcile is triggered.
+//
+// The Cluster is restored to its initial spec.paused value before returning.
+func VerifyPaused(ctx context.Context, input VerifyInput) (retErr error) {
+	input.defaults()
+	if input.Cluster == nil {
+		return errors.New("Cluster must be set to verify the paused handling")
+	}
+
+	cluster := &clusterv1.Cluster{}
+	if err := input.Client.Get(ctx, client.ObjectKeyFromObject(input.Cluster), cluster); err != nil {
+		return errors.Wrapf(err, "failed to get Cluster %s", klog.KObj(input.Cluster))
+	}
+	if cluster.Spec.Paused {
+		return errors.Errorf("Cluster %s must not be paused before verifying the paused handling", klog.KObj(cluster))
+	}
+	if err := setClusterPaused(ctx, input.Client, cluster, true); err != nil {
+		return err
+	}
+	defer func() {
+		if err := setClusterPaused(ctx, input.Client, cluster, false); err != nil {
+			retErr = kerrors.NewAggregate([]error{retErr, err})
+		}
+	}()
+
+	// Wait for the provider to acknowledge the pause.
+	var obj *unstructured.Unstructured
+	var lastErr error
+	if err := wait.PollUntilContextTimeout(ctx, input.PollInterval, input.Timeout, true, func(ctx context.Context) (bool, error) {
+		var err error
+		if obj, err = getObject(ctx, input.Client, input.Object); err != nil {
+			return false, err
+		}
+		lastErr = pausedAcknowledged(input.Profile, obj)
+		return lastErr == nil, nil
+	}); err != nil {
+		if lastErr != nil {
+			return errors.Wrapf(lastErr, "%s %s did not acknowledge the paused Cluster", input.Object.GetKind(), klog.KObj(input.Object))
+		}
+		return err
+	}
+
+	// Trigger a reconcile and check the provider does not act on the object.
+	patch := client.MergeFrom(obj.DeepCopy())
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[reconcileTriggerAnnotation] = time.Now().UTC().Format(time.RFC3339Nano)
+	obj.SetAnnotations(annotations)
+	if err := input.Client.Patch(ctx, obj, patch); err != nil {
+		return errors.Wrapf(err, "failed to trigger reconcile of %s %s", obj.GetKind(), klog.KObj(obj))
+	}
+	defer func() {
+		if err := removeAnnotation(ctx, input.Client, input.Object, reconcileTriggerAnnotation); err != nil {
+			retErr = kerrors.NewAggregate([]error{retErr, err})
+		}
+	}()
+
+	status, _, _ := unstructured.NestedFieldCopy(obj.UnstructuredContent(), "status")
+	finalizers := obj.GetFinalizers()
+	deadline := time.Now().Add(input.PausedPeriod)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(input.PollInterval):
+		}
+
+		current, err := getObject(ctx, input.Client, input.Object)
+		if err != nil {
+			return err
+		}
+		currentStatus, _, _ := unstructured.NestedFieldCopy(current.UnstructuredContent(), "status")
+		if !apiequality.Semantic.DeepEqual(status, currentStatus) || !apiequality.Semantic.DeepEqual(finalizers, current.GetFinalizers()) {
+			return errors.Errorf("%s %s has been reconciled while the Cluster is paused", current.GetKind(), klog.KObj(current))
+		}
+	}
+	return nil
+}
+
+// pausedAcknowledged returns an error if the provider object does not show it has been paused yet.
+func pausedAcknowledged(profile Profile, obj *unstructured.Unstructured) error {
+	if _, ok := obj.GetAnnotations()[clusterctlv1.BlockMoveAnnotation]; ok {
+		return errors.Errorf("the %s annotation must be removed when paused", clusterctlv1.BlockMoveAnnotation)
+	}
+	if !profile.V1Beta2Conditions {
+		return nil
+	}
+	conds, err := getV1Beta2Conditions(obj)
+	if err != nil {
+		return err
+	}
+	for _, c := range conds {
+		if c.Type == clusterv1.PausedV1Beta2Condition {
+			if c.Status != metav1.ConditionTrue {
+				return errors.Errorf("the %s condition must be true when paused", clusterv1.PausedV1Beta2Condition)
+			}
+			return nil
+		}
+	}
+	return errors.Errorf("the %s condition must be set", clusterv1.PausedV1Beta2Condition)
+}
+
+func setClusterPaused(ctx context.Context, c client.Client, cluster *clusterv1.Cluster, paused bool) error {
+	patch := client.MergeFrom(cluster.DeepCopy())
+	cluster.Spec.Paused = paused
+	if err := c.Patch(ctx, cluster, patch); err != nil {
+		return errors.Wrapf(err, "failed to set spec.paused to %t on Cluster %s", paused, klog.KObj(cluster))
+	}
+	return nil
+}
+
+func removeAnnotation(ctx context.Context, c client.Client, ref *unstructured.Unstructured, annotation string) error {
+	obj, err := getObject(ctx, c, ref)
+	if err != nil {
+		return err
+	}
+	patch := client.MergeFrom(obj.DeepCopy())
+	annotations := obj.GetAnnotations()
+	delete(annotations, annotation)
+	obj.SetAnnotations(annotations)
+	if err := c.Patch(ctx, obj, patch); err != nil {
+		return errors.Wrapf(err, "failed to remove annotation %s from %s %s", annotation, obj.GetKind(), klog.KObj(obj))
+	}
+	return nil
+}
+
+func getObject(ctx context.Context, c client.Client, ref *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(ref.GroupVersionKind())
+	if err := c.Get(ctx, client.ObjectKeyFromObject(ref), obj); err != nil {
+		return nil, errors.Wrapf(err, "failed to get %s %s", ref.GetKind(), klog.KObj(ref))
+	}
+	return obj, nil
+}
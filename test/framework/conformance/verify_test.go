@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	infrav1 "sigs.k8s.io/cluster-api/test/infrastructure/docker/api/v1beta1"
+)
+
+func TestVerifyPaused(t *testing.T) {
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	_ = clusterv1.AddToScheme(scheme)
+	_ = infrav1.AddToScheme(scheme)
+
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cluster"}}
+	newInput := func(c client.Client, profile Profile) VerifyInput {
+		ref := &unstructured.Unstructured{}
+		ref.SetGroupVersionKind(infrav1.GroupVersion.WithKind("DockerMachine"))
+		ref.SetNamespace("default")
+		ref.SetName("machine")
+		return VerifyInput{
+			Client:       c,
+			Profile:      profile,
+			Kind:         InfrastructureMachine,
+			Object:       ref,
+			Cluster:      cluster,
+			Timeout:      200 * time.Millisecond,
+			PollInterval: 10 * time.Millisecond,
+			PausedPeriod: 50 * time.Millisecond,
+		}
+	}
+
+	t.Run("object not reconciled while paused", func(t *testing.T) {
+		g := NewWithT(t)
+
+		machine := &infrav1.DockerMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "machine"}}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster.DeepCopy(), machine).Build()
+
+		g.Expect(VerifyPaused(ctx, newInput(c, V1Beta1()))).To(Succeed())
+
+		// The Cluster and the object are restored.
+		gotCluster := &clusterv1.Cluster{}
+		g.Expect(c.Get(ctx, client.ObjectKeyFromObject(cluster), gotCluster)).To(Succeed())
+		g.Expect(gotCluster.Spec.Paused).To(BeFalse())
+		g.Expect(c.Get(ctx, client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+		g.Expect(machine.Annotations).ToNot(HaveKey(reconcileTriggerAnnotation))
+	})
+
+	t.Run("block-move annotation not removed while paused", func(t *testing.T) {
+		g := NewWithT(t)
+
+		machine := &infrav1.DockerMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "machine", Annotations: map[string]string{
+			clusterctlv1.BlockMoveAnnotation: "true",
+		}}}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster.DeepCopy(), machine).Build()
+
+		g.Expect(VerifyPaused(ctx, newInput(c, V1Beta1()))).ToNot(Succeed())
+	})
+
+	t.Run("Paused condition not reported", func(t *testing.T) {
+		g := NewWithT(t)
+
+		machine := &infrav1.DockerMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "machine"}}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster.DeepCopy(), machine).Build()
+
+		g.Expect(VerifyPaused(ctx, newInput(c, V1Beta1WithV1Beta2Conditions()))).ToNot(Succeed())
+	})
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	// PreprovisionedProviderIDPrefix is the prefix of the provider ID assigned to PreprovisionedMachines
+	// not setting spec.providerID, followed by the name of the adopted Node.
+	PreprovisionedProviderIDPrefix = "preprovisioned://"
+)
+
+// PreprovisionedMachineSpec defines the desired state of PreprovisionedMachine.
+type PreprovisionedMachineSpec struct {
+	// ProviderID is the provider ID of the pre-provisioned host. If not set, it defaults to
+	// preprovisioned://<node name>, with the name of the Node set in the machine.cluster.x-k8s.io/adopt-node
+	// annotation of the Machine.
+	// +optional
+	ProviderID *string `json:"providerID,omitempty"`
+
+	// Addresses are the addresses of the pre-provisioned host.
+	// +optional
+	Addresses []clusterv1.MachineAddress `json:"addresses,omitempty"`
+}
+
+// PreprovisionedMachineStatus defines the observed state of PreprovisionedMachine.
+type PreprovisionedMachineStatus struct {
+	// Ready denotes that the pre-provisioned host is ready to be adopted.
+	// +optional
+	Ready bool `json:"ready"`
+
+	// Addresses contains the associated addresses for the pre-provisioned host.
+	// +optional
+	Addresses []clusterv1.MachineAddress `json:"addresses,omitempty"`
+}
+
+// +kubebuilder:resource:path=preprovisionedmachines,scope=Namespaced,categories=cluster-api
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels['cluster\\.x-k8s\\.io/cluster-name']",description="Cluster"
+// +kubebuilder:printcolumn:name="Machine",type="string",JSONPath=".metadata.ownerReferences[?(@.kind==\"Machine\")].name",description="Machine object which owns with this PreprovisionedMachine"
+// +kubebuilder:printcolumn:name="ProviderID",type="string",JSONPath=".spec.providerID",description="Provider ID"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.ready",description="Machine ready status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Time duration since creation of PreprovisionedMachine"
+
+// PreprovisionedMachine is the Schema for the preprovisionedmachines API.
+// A PreprovisionedMachine is a minimal infrastructure machine for hosts provisioned outside of Cluster API,
+// to be used by Machines adopting an existing Node with the machine.cluster.x-k8s.io/adopt-node annotation;
+// nothing is created or deleted for it.
+type PreprovisionedMachine struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PreprovisionedMachineSpec   `json:"spec,omitempty"`
+	Status PreprovisionedMachineStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PreprovisionedMachineList contains a list of PreprovisionedMachine.
+type PreprovisionedMachineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PreprovisionedMachine `json:"items"`
+}
+
+func init() {
+	objectTypes = append(objectTypes, &PreprovisionedMachine{}, &PreprovisionedMachineList{})
+}
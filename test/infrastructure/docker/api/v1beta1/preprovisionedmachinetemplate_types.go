@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// PreprovisionedMachineTemplateSpec defines the desired state of PreprovisionedMachineTemplate.
+type PreprovisionedMachineTemplateSpec struct {
+	Template PreprovisionedMachineTemplateResource `json:"template"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=preprovisionedmachinetemplates,scope=Namespaced,categories=cluster-api
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Time duration since creation of PreprovisionedMachineTemplate"
+
+// PreprovisionedMachineTemplate is the Schema for the preprovisionedmachinetemplates API.
+type PreprovisionedMachineTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PreprovisionedMachineTemplateSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PreprovisionedMachineTemplateList contains a list of PreprovisionedMachineTemplate.
+type PreprovisionedMachineTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PreprovisionedMachineTemplate `json:"items"`
+}
+
+func init() {
+	objectTypes = append(objectTypes, &PreprovisionedMachineTemplate{}, &PreprovisionedMachineTemplateList{})
+}
+
+// PreprovisionedMachineTemplateResource describes the data needed to create a PreprovisionedMachine from a template.
+type PreprovisionedMachineTemplateResource struct {
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	ObjectMeta clusterv1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec is the specification of the desired behavior of the machine.
+	Spec PreprovisionedMachineSpec `json:"spec"`
+}
@@ -507,3 +507,198 @@ func (in *Mount) DeepCopy() *Mount {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreprovisionedMachine) DeepCopyInto(out *PreprovisionedMachine) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreprovisionedMachine.
+func (in *PreprovisionedMachine) DeepCopy() *PreprovisionedMachine {
+	if in == nil {
+		return nil
+	}
+	out := new(PreprovisionedMachine)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PreprovisionedMachine) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreprovisionedMachineList) DeepCopyInto(out *PreprovisionedMachineList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PreprovisionedMachine, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreprovisionedMachineList.
+func (in *PreprovisionedMachineList) DeepCopy() *PreprovisionedMachineList {
+	if in == nil {
+		return nil
+	}
+	out := new(PreprovisionedMachineList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PreprovisionedMachineList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreprovisionedMachineSpec) DeepCopyInto(out *PreprovisionedMachineSpec) {
+	*out = *in
+	if in.ProviderID != nil {
+		in, out := &in.ProviderID, &out.ProviderID
+		*out = new(string)
+		**out = **in
+	}
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]apiv1beta1.MachineAddress, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreprovisionedMachineSpec.
+func (in *PreprovisionedMachineSpec) DeepCopy() *PreprovisionedMachineSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PreprovisionedMachineSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreprovisionedMachineStatus) DeepCopyInto(out *PreprovisionedMachineStatus) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]apiv1beta1.MachineAddress, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreprovisionedMachineStatus.
+func (in *PreprovisionedMachineStatus) DeepCopy() *PreprovisionedMachineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PreprovisionedMachineStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreprovisionedMachineTemplate) DeepCopyInto(out *PreprovisionedMachineTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreprovisionedMachineTemplate.
+func (in *PreprovisionedMachineTemplate) DeepCopy() *PreprovisionedMachineTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(PreprovisionedMachineTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PreprovisionedMachineTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreprovisionedMachineTemplateList) DeepCopyInto(out *PreprovisionedMachineTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PreprovisionedMachineTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreprovisionedMachineTemplateList.
+func (in *PreprovisionedMachineTemplateList) DeepCopy() *PreprovisionedMachineTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(PreprovisionedMachineTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PreprovisionedMachineTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreprovisionedMachineTemplateResource) DeepCopyInto(out *PreprovisionedMachineTemplateResource) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreprovisionedMachineTemplateResource.
+func (in *PreprovisionedMachineTemplateResource) DeepCopy() *PreprovisionedMachineTemplateResource {
+	if in == nil {
+		return nil
+	}
+	out := new(PreprovisionedMachineTemplateResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreprovisionedMachineTemplateSpec) DeepCopyInto(out *PreprovisionedMachineTemplateSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreprovisionedMachineTemplateSpec.
+func (in *PreprovisionedMachineTemplateSpec) DeepCopy() *PreprovisionedMachineTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PreprovisionedMachineTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
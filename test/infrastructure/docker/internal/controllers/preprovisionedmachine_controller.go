@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	infrav1 "sigs.k8s.io/cluster-api/test/infrastructure/docker/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+)
+
+// PreprovisionedMachineReconciler reconciles a PreprovisionedMachine object.
+// PreprovisionedMachines represent hosts provisioned outside of Cluster API; the reconciler only reports
+// their provider ID and addresses, so the Machine controller can adopt the corresponding Node.
+type PreprovisionedMachineReconciler struct {
+	client.Client
+
+	// WatchFilterValue is the label value used to filter events prior to reconciliation.
+	WatchFilterValue string
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=preprovisionedmachines,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=preprovisionedmachines/status,verbs=get;update;patch
+
+// Reconcile handles PreprovisionedMachine events.
+func (r *PreprovisionedMachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// Fetch the PreprovisionedMachine instance.
+	preprovisionedMachine := &infrav1.PreprovisionedMachine{}
+	if err := r.Client.Get(ctx, req.NamespacedName, preprovisionedMachine); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Fetch the Machine.
+	machine, err := util.GetOwnerMachine(ctx, r.Client, preprovisionedMachine.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if machine == nil {
+		log.Info("Waiting for Machine Controller to set OwnerRef on PreprovisionedMachine")
+		return ctrl.Result{}, nil
+	}
+
+	log = log.WithValues("Machine", klog.KObj(machine))
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	// Fetch the Cluster.
+	cluster, err := util.GetClusterFromMetadata(ctx, r.Client, machine.ObjectMeta)
+	if err != nil {
+		log.Info("PreprovisionedMachine owner Machine is missing cluster label or cluster does not exist")
+		return ctrl.Result{}, err
+	}
+	if cluster == nil {
+		log.Info(fmt.Sprintf("Please associate this machine with a cluster using the label %s: <name of cluster>", clusterv1.ClusterNameLabel))
+		return ctrl.Result{}, nil
+	}
+
+	log = log.WithValues("Cluster", klog.KObj(cluster))
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	// Return early if the object or Cluster is paused.
+	if annotations.IsPaused(cluster, preprovisionedMachine) {
+		log.Info("Reconciliation is paused for this object")
+		return ctrl.Result{}, nil
+	}
+
+	// Nothing to do on deletion, pre-provisioned hosts are not deleted by Cluster API.
+	if !preprovisionedMachine.ObjectMeta.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	// Initialize the patch helper
+	patchHelper, err := patch.NewHelper(preprovisionedMachine, r)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	// Always attempt to Patch the PreprovisionedMachine object and status after each reconciliation.
+	defer func() {
+		if err := patchHelper.Patch(ctx, preprovisionedMachine); err != nil {
+			log.Error(err, "failed to patch PreprovisionedMachine")
+			if rerr == nil {
+				rerr = err
+			}
+		}
+	}()
+
+	return r.reconcileNormal(ctx, machine, preprovisionedMachine)
+}
+
+func (r *PreprovisionedMachineReconciler) reconcileNormal(ctx context.Context, machine *clusterv1.Machine, preprovisionedMachine *infrav1.PreprovisionedMachine) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	nodeName, ok := machine.Annotations[clusterv1.AdoptNodeAnnotation]
+	if !ok {
+		log.Info(fmt.Sprintf("Machine must have the %s annotation to use a PreprovisionedMachine", clusterv1.AdoptNodeAnnotation))
+		return ctrl.Result{}, nil
+	}
+
+	if preprovisionedMachine.Spec.ProviderID == nil {
+		if nodeName == "" {
+			return ctrl.Result{}, errors.Errorf("either spec.providerID or the name of the Node in the %s annotation of the Machine must be set", clusterv1.AdoptNodeAnnotation)
+		}
+		preprovisionedMachine.Spec.ProviderID = ptr.To(infrav1.PreprovisionedProviderIDPrefix + nodeName)
+	}
+
+	preprovisionedMachine.Status.Addresses = preprovisionedMachine.Spec.Addresses
+	preprovisionedMachine.Status.Ready = true
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager will add watches for this controller.
+func (r *PreprovisionedMachineReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	clusterToPreprovisionedMachines, err := util.ClusterToTypedObjectsMapper(mgr.GetClient(), &infrav1.PreprovisionedMachineList{}, mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+
+	err = ctrl.NewControllerManagedBy(mgr).
+		For(&infrav1.PreprovisionedMachine{}).
+		WithOptions(options).
+		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
+		Watches(
+			&clusterv1.Machine{},
+			handler.EnqueueRequestsFromMapFunc(util.MachineToInfrastructureMapFunc(infrav1.GroupVersion.WithKind("PreprovisionedMachine"))),
+		).
+		Watches(
+			&clusterv1.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(clusterToPreprovisionedMachines),
+			builder.WithPredicates(
+				predicates.ClusterUnpaused(ctrl.LoggerFrom(ctx)),
+			),
+		).Complete(r)
+	if err != nil {
+		return errors.Wrap(err, "failed setting up with a controller manager")
+	}
+	return nil
+}
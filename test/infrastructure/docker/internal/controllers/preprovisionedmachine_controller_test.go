@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	infrav1 "sigs.k8s.io/cluster-api/test/infrastructure/docker/api/v1beta1"
+)
+
+func TestPreprovisionedMachineReconciler_reconcileNormal(t *testing.T) {
+	addresses := []clusterv1.MachineAddress{{Type: clusterv1.MachineInternalIP, Address: "10.0.0.1"}}
+
+	t.Run("defaults the providerID from the name of the adopted Node", func(t *testing.T) {
+		g := NewWithT(t)
+
+		machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{clusterv1.AdoptNodeAnnotation: "node-1"}}}
+		preprovisionedMachine := &infrav1.PreprovisionedMachine{Spec: infrav1.PreprovisionedMachineSpec{Addresses: addresses}}
+
+		_, err := (&PreprovisionedMachineReconciler{}).reconcileNormal(context.Background(), machine, preprovisionedMachine)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(preprovisionedMachine.Spec.ProviderID).To(HaveValue(Equal("preprovisioned://node-1")))
+		g.Expect(preprovisionedMachine.Status.Ready).To(BeTrue())
+		g.Expect(preprovisionedMachine.Status.Addresses).To(Equal(addresses))
+	})
+
+	t.Run("keeps the providerID set by the user", func(t *testing.T) {
+		g := NewWithT(t)
+
+		machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{clusterv1.AdoptNodeAnnotation: ""}}}
+		preprovisionedMachine := &infrav1.PreprovisionedMachine{Spec: infrav1.PreprovisionedMachineSpec{ProviderID: ptr.To("metal://rack-1/host-1")}}
+
+		_, err := (&PreprovisionedMachineReconciler{}).reconcileNormal(context.Background(), machine, preprovisionedMachine)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(preprovisionedMachine.Spec.ProviderID).To(HaveValue(Equal("metal://rack-1/host-1")))
+		g.Expect(preprovisionedMachine.Status.Ready).To(BeTrue())
+	})
+
+	t.Run("fails without providerID and Node name", func(t *testing.T) {
+		g := NewWithT(t)
+
+		machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{clusterv1.AdoptNodeAnnotation: ""}}}
+		preprovisionedMachine := &infrav1.PreprovisionedMachine{}
+
+		_, err := (&PreprovisionedMachineReconciler{}).reconcileNormal(context.Background(), machine, preprovisionedMachine)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(preprovisionedMachine.Status.Ready).To(BeFalse())
+	})
+
+	t.Run("waits for Machines not adopting a Node", func(t *testing.T) {
+		g := NewWithT(t)
+
+		preprovisionedMachine := &infrav1.PreprovisionedMachine{}
+
+		_, err := (&PreprovisionedMachineReconciler{}).reconcileNormal(context.Background(), &clusterv1.Machine{}, preprovisionedMachine)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(preprovisionedMachine.Status.Ready).To(BeFalse())
+	})
+}
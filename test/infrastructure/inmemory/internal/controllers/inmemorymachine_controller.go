@@ -499,7 +499,10 @@ func (r *InMemoryMachineReconciler) reconcileNormalETCD(ctx context.Context, clu
 			return ctrl.Result{}, errors.Wrapf(err, "invalid etcd CA: invalid %s", secret.TLSCrtDataName)
 		}
 
-		keyData, exists := s.Data[secret.TLSKeyDataName]
+		keyData, exists, err := secret.KeyData(ctx, s)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "invalid etcd CA: failed to read %s", secret.TLSKeyDataName)
+		}
 		if !exists {
 			return ctrl.Result{}, errors.Errorf("invalid etcd CA: missing data for %s", secret.TLSKeyDataName)
 		}
@@ -667,7 +670,10 @@ func (r *InMemoryMachineReconciler) reconcileNormalAPIServer(ctx context.Context
 			return ctrl.Result{}, errors.Wrapf(err, "invalid cluster CA: invalid %s", secret.TLSCrtDataName)
 		}
 
-		keyData, exists := s.Data[secret.TLSKeyDataName]
+		keyData, exists, err := secret.KeyData(ctx, s)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "invalid cluster CA: failed to read %s", secret.TLSKeyDataName)
+		}
 		if !exists {
 			return ctrl.Result{}, errors.Errorf("invalid cluster CA: missing data for %s", secret.TLSKeyDataName)
 		}
@@ -53,6 +53,7 @@ import (
 	inmemoryserver "sigs.k8s.io/cluster-api/test/infrastructure/inmemory/pkg/server"
 	"sigs.k8s.io/cluster-api/test/infrastructure/inmemory/webhooks"
 	"sigs.k8s.io/cluster-api/util/flags"
+	"sigs.k8s.io/cluster-api/util/secret"
 	"sigs.k8s.io/cluster-api/version"
 )
 
@@ -79,6 +80,7 @@ var (
 	healthAddr                  string
 	tlsOptions                  = flags.TLSOptions{}
 	diagnosticsOptions          = flags.DiagnosticsOptions{}
+	keyEncryptionOptions        = flags.KeyEncryptionOptions{}
 	logOptions                  = logs.NewOptions()
 	// CAPIM specific flags.
 	clusterConcurrency int
@@ -152,6 +154,7 @@ func InitFlags(fs *pflag.FlagSet) {
 
 	flags.AddDiagnosticsOptions(fs, &diagnosticsOptions)
 	flags.AddTLSOptions(fs, &tlsOptions)
+	flags.AddKeyEncryptionOptions(fs, &keyEncryptionOptions)
 
 	feature.MutableGates.AddFlag(fs)
 }
@@ -192,6 +195,13 @@ func main() {
 
 	diagnosticsOpts := flags.GetDiagnosticsOptions(diagnosticsOptions)
 
+	keyEncryptionService, err := flags.GetKeyEncryptionService(keyEncryptionOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to configure cluster certificate key encryption")
+		os.Exit(1)
+	}
+	secret.SetKeyEncryptionService(keyEncryptionService)
+
 	var watchNamespaces map[string]cache.Config
 	if watchNamespace != "" {
 		watchNamespaces = map[string]cache.Config{
@@ -38,9 +38,9 @@ func IsExternallyManaged(o metav1.Object) bool {
 	return hasAnnotation(o, clusterv1.ManagedByAnnotation)
 }
 
-// HasPaused returns true if the object has the `paused` annotation.
+// HasPaused returns true if the object has the `paused` annotation or at least one pause reason annotation.
 func HasPaused(o metav1.Object) bool {
-	return hasAnnotation(o, clusterv1.PausedAnnotation)
+	return hasAnnotation(o, clusterv1.PausedAnnotation) || HasWithPrefix(clusterv1.PausedAnnotationPrefix, o.GetAnnotations())
 }
 
 // HasSkipRemediation returns true if the object has the `skip-remediation` annotation.
@@ -53,6 +53,11 @@ func HasRemediateMachine(o metav1.Object) bool {
 	return hasAnnotation(o, clusterv1.RemediateMachineAnnotation)
 }
 
+// HasWorkloadClusterAuth returns true if the object has the `workload-cluster-auth` annotation.
+func HasWorkloadClusterAuth(o metav1.Object) bool {
+	return hasAnnotation(o, clusterv1.WorkloadClusterAuthAnnotation)
+}
+
 // HasWithPrefix returns true if at least one of the annotations has the prefix specified.
 func HasWithPrefix(prefix string, annotations map[string]string) bool {
 	for key := range annotations {
@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package annotations
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// PauseReason is a reason for which an actor paused the reconciliation of an object.
+type PauseReason struct {
+	// Actor is the name of who paused the object, e.g. clusterctl.
+	Actor string
+
+	// Reason is a human readable explanation of why the object has been paused.
+	Reason string
+}
+
+// String returns the PauseReason in the `actor: reason` format.
+func (r PauseReason) String() string {
+	if r.Reason == "" {
+		return r.Actor
+	}
+	return fmt.Sprintf("%s: %s", r.Actor, r.Reason)
+}
+
+const (
+	// clusterPausedActor is the actor reported for a Cluster with spec.paused set to true.
+	clusterPausedActor = "cluster.spec.paused"
+
+	// legacyPausedActor is the actor reported for objects with the `paused` annotation.
+	legacyPausedActor = clusterv1.PausedAnnotation
+)
+
+// SetPauseReason adds or updates the pause reason annotation for the given actor and returns true
+// if the annotations have changed.
+// Reconciliation of the object is resumed only after the pause reasons of all the actors have been removed.
+func SetPauseReason(o metav1.Object, actor, reason string) (bool, error) {
+	key := clusterv1.PausedAnnotationPrefix + actor
+	if actor == "" {
+		return false, errors.New("actor must not be empty")
+	}
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		return false, errors.Errorf("invalid actor %q: %s", actor, strings.Join(errs, "; "))
+	}
+	return AddAnnotations(o, map[string]string{key: reason}), nil
+}
+
+// RemovePauseReason removes the pause reason annotation for the given actor and returns true
+// if the annotations have changed.
+func RemovePauseReason(o metav1.Object, actor string) bool {
+	annotations := o.GetAnnotations()
+	key := clusterv1.PausedAnnotationPrefix + actor
+	if _, ok := annotations[key]; !ok {
+		return false
+	}
+	delete(annotations, key)
+	o.SetAnnotations(annotations)
+	return true
+}
+
+// GetPauseReasons returns the pause reasons of the object sorted by actor, including the ones
+// derived from the `paused` annotation and from spec.paused on the Cluster, if a Cluster is provided.
+func GetPauseReasons(cluster *clusterv1.Cluster, o metav1.Object) []PauseReason {
+	reasons := []PauseReason{}
+	if cluster != nil && cluster.Spec.Paused {
+		reasons = append(reasons, PauseReason{Actor: clusterPausedActor})
+	}
+	for key, value := range o.GetAnnotations() {
+		switch {
+		case key == clusterv1.PausedAnnotation:
+			reasons = append(reasons, PauseReason{Actor: legacyPausedActor, Reason: value})
+		case strings.HasPrefix(key, clusterv1.PausedAnnotationPrefix):
+			reasons = append(reasons, PauseReason{Actor: strings.TrimPrefix(key, clusterv1.PausedAnnotationPrefix), Reason: value})
+		}
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		return reasons[i].Actor < reasons[j].Actor
+	})
+	return reasons
+}
+
+// PausedCondition returns the Paused condition for the object, which is true if the Cluster or
+// the object are paused and lists the active pause reasons in the message.
+func PausedCondition(cluster *clusterv1.Cluster, o metav1.Object) metav1.Condition {
+	reasons := GetPauseReasons(cluster, o)
+	if len(reasons) == 0 {
+		return metav1.Condition{
+			Type:               clusterv1.PausedV1Beta2Condition,
+			Status:             metav1.ConditionFalse,
+			Reason:             clusterv1.NotPausedV1Beta2Reason,
+			ObservedGeneration: o.GetGeneration(),
+		}
+	}
+
+	messages := make([]string, 0, len(reasons))
+	for _, r := range reasons {
+		messages = append(messages, fmt.Sprintf("* %s", r))
+	}
+	return metav1.Condition{
+		Type:               clusterv1.PausedV1Beta2Condition,
+		Status:             metav1.ConditionTrue,
+		Reason:             clusterv1.PausedV1Beta2Reason,
+		Message:            strings.Join(messages, "\n"),
+		ObservedGeneration: o.GetGeneration(),
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package annotations
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestPauseReasons(t *testing.T) {
+	g := NewWithT(t)
+
+	m := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Generation: 3}}
+	g.Expect(HasPaused(m)).To(BeFalse())
+
+	changed, err := SetPauseReason(m, "clusterctl", "move")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(changed).To(BeTrue())
+	changed, err = SetPauseReason(m, "clusterctl", "move")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(changed).To(BeFalse())
+	_, err = SetPauseReason(m, "backup", "velero backup in progress")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(HasPaused(m)).To(BeTrue())
+	g.Expect(m.Annotations).To(HaveKeyWithValue("paused.cluster.x-k8s.io/clusterctl", "move"))
+
+	_, err = SetPauseReason(m, "", "empty")
+	g.Expect(err).To(HaveOccurred())
+	_, err = SetPauseReason(m, "not/valid", "invalid")
+	g.Expect(err).To(HaveOccurred())
+
+	g.Expect(GetPauseReasons(nil, m)).To(Equal([]PauseReason{
+		{Actor: "backup", Reason: "velero backup in progress"},
+		{Actor: "clusterctl", Reason: "move"},
+	}))
+
+	cluster := &clusterv1.Cluster{Spec: clusterv1.ClusterSpec{Paused: true}}
+	m.Annotations[clusterv1.PausedAnnotation] = ""
+	c := PausedCondition(cluster, m)
+	g.Expect(c.Type).To(Equal(clusterv1.PausedV1Beta2Condition))
+	g.Expect(c.Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(c.Reason).To(Equal(clusterv1.PausedV1Beta2Reason))
+	g.Expect(c.ObservedGeneration).To(Equal(int64(3)))
+	g.Expect(c.Message).To(Equal("* backup: velero backup in progress\n" +
+		"* cluster.spec.paused\n" +
+		"* cluster.x-k8s.io/paused\n" +
+		"* clusterctl: move"))
+
+	delete(m.Annotations, clusterv1.PausedAnnotation)
+	g.Expect(RemovePauseReason(m, "clusterctl")).To(BeTrue())
+	g.Expect(RemovePauseReason(m, "clusterctl")).To(BeFalse())
+	g.Expect(HasPaused(m)).To(BeTrue())
+	g.Expect(RemovePauseReason(m, "backup")).To(BeTrue())
+	g.Expect(HasPaused(m)).To(BeFalse())
+
+	c = PausedCondition(&clusterv1.Cluster{}, m)
+	g.Expect(c.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(c.Reason).To(Equal(clusterv1.NotPausedV1Beta2Reason))
+	g.Expect(c.Message).To(BeEmpty())
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit implements utilities to record why Cluster API controllers decided to execute
+// destructive actions on Machines, so it is possible to answer questions like "why was this node deleted"
+// by looking at the Machine and its Events, without correlating the logs of different controllers.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
+)
+
+// Action is a destructive action a controller can decide to execute on a Machine.
+type Action string
+
+const (
+	// DeleteAction is used when a Machine is deleted, e.g. when scaling down.
+	DeleteAction Action = "Delete"
+
+	// RemediateAction is used when a Machine is deleted to remediate it, e.g. because it is unhealthy.
+	RemediateAction Action = "Remediate"
+
+	// RolloutAction is used when a Machine is deleted to be replaced by an up-to-date Machine.
+	RolloutAction Action = "Rollout"
+)
+
+// maxConditionMessageLength is the maximum length of the condition messages recorded in the snapshot.
+const maxConditionMessageLength = 256
+
+// Decision describes why a controller decided to execute a destructive action on a Machine.
+type Decision struct {
+	// Action is the destructive action.
+	Action Action
+
+	// Reason is a human readable description of why the action was decided.
+	Reason string
+
+	// Controller is the name of the controller which decided the action.
+	Controller string
+}
+
+// conditionSnapshot is the representation of a condition recorded in the DecisionConditionsAnnotation.
+type conditionSnapshot struct {
+	Type     clusterv1.ConditionType     `json:"type"`
+	Status   corev1.ConditionStatus      `json:"status"`
+	Severity clusterv1.ConditionSeverity `json:"severity,omitempty"`
+	Reason   string                      `json:"reason,omitempty"`
+	Message  string                      `json:"message,omitempty"`
+}
+
+// RecordMachineDecision records the decision on the Machine as annotations, together with a snapshot of the
+// Machine conditions which are not true, and emits an Event for it using recorder, if not nil; it must be called before executing the action.
+// The passed in Machine is not modified.
+func RecordMachineDecision(ctx context.Context, c client.Client, recorder record.EventRecorder, machine *clusterv1.Machine, decision Decision) error {
+	snapshot, err := conditionsSnapshot(machine)
+	if err != nil {
+		return err
+	}
+
+	m := machine.DeepCopy()
+	patch := client.MergeFrom(machine)
+	if m.Annotations == nil {
+		m.Annotations = map[string]string{}
+	}
+	m.Annotations[clusterv1.DecisionActionAnnotation] = string(decision.Action)
+	m.Annotations[clusterv1.DecisionReasonAnnotation] = decision.Reason
+	m.Annotations[clusterv1.DecisionControllerAnnotation] = decision.Controller
+	m.Annotations[clusterv1.DecisionTimestampAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	m.Annotations[clusterv1.DecisionConditionsAnnotation] = snapshot
+	if err := c.Patch(ctx, m, patch); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to record %s decision on Machine %s", decision.Action, klog.KObj(machine))
+	}
+
+	if recorder != nil {
+		capirecord.Emit(recorder, machine, capirecord.DestructiveActionDecidedReason, decision.Action, decision.Controller, decision.Reason)
+	}
+	return nil
+}
+
+func conditionsSnapshot(machine *clusterv1.Machine) (string, error) {
+	snapshot := []conditionSnapshot{}
+	for _, condition := range machine.GetConditions() {
+		if condition.Status == corev1.ConditionTrue {
+			continue
+		}
+		message := condition.Message
+		if len(message) > maxConditionMessageLength {
+			// Cut on a rune boundary, so the message is still valid UTF-8.
+			cut := maxConditionMessageLength
+			for cut > 0 && !utf8.RuneStart(message[cut]) {
+				cut--
+			}
+			message = message[:cut] + "..."
+		}
+		snapshot = append(snapshot, conditionSnapshot{
+			Type:     condition.Type,
+			Status:   condition.Status,
+			Severity: condition.Severity,
+			Reason:   condition.Reason,
+			Message:  message,
+		})
+	}
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to marshal conditions of Machine %s", klog.KObj(machine))
+	}
+	return string(b), nil
+}
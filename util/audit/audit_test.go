@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestRecordMachineDecision(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "machine",
+			Namespace:   "ns",
+			Annotations: map[string]string{"foo": "bar"},
+		},
+		Status: clusterv1.MachineStatus{
+			Conditions: clusterv1.Conditions{
+				{Type: clusterv1.ReadyCondition, Status: corev1.ConditionTrue},
+				{
+					Type:     clusterv1.MachineHealthCheckSucceededCondition,
+					Status:   corev1.ConditionFalse,
+					Severity: clusterv1.ConditionSeverityWarning,
+					Reason:   clusterv1.UnhealthyNodeConditionReason,
+					Message:  strings.Repeat("x", maxConditionMessageLength+10),
+				},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(machine).Build()
+	recorder := record.NewFakeRecorder(10)
+
+	g.Expect(RecordMachineDecision(ctx, c, recorder, machine, Decision{
+		Action:     RemediateAction,
+		Reason:     "Machine is unhealthy",
+		Controller: "machineset",
+	})).To(Succeed())
+
+	// The passed in Machine is not modified.
+	g.Expect(machine.Annotations).To(Equal(map[string]string{"foo": "bar"}))
+
+	got := &clusterv1.Machine{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(machine), got)).To(Succeed())
+	g.Expect(got.Annotations).To(HaveKeyWithValue("foo", "bar"))
+	g.Expect(got.Annotations).To(HaveKeyWithValue(clusterv1.DecisionActionAnnotation, "Remediate"))
+	g.Expect(got.Annotations).To(HaveKeyWithValue(clusterv1.DecisionReasonAnnotation, "Machine is unhealthy"))
+	g.Expect(got.Annotations).To(HaveKeyWithValue(clusterv1.DecisionControllerAnnotation, "machineset"))
+	g.Expect(got.Annotations).To(HaveKey(clusterv1.DecisionTimestampAnnotation))
+
+	snapshot := []conditionSnapshot{}
+	g.Expect(json.Unmarshal([]byte(got.Annotations[clusterv1.DecisionConditionsAnnotation]), &snapshot)).To(Succeed())
+	g.Expect(snapshot).To(HaveLen(1))
+	g.Expect(snapshot[0].Type).To(Equal(clusterv1.MachineHealthCheckSucceededCondition))
+	g.Expect(snapshot[0].Reason).To(Equal(clusterv1.UnhealthyNodeConditionReason))
+	g.Expect(snapshot[0].Message).To(HaveLen(maxConditionMessageLength + 3))
+
+	g.Expect(recorder.Events).To(Receive(Equal("Normal DestructiveActionDecided Remediate decided by machineset: Machine is unhealthy")))
+}
+
+func TestConditionsSnapshot(t *testing.T) {
+	g := NewWithT(t)
+
+	machine := &clusterv1.Machine{
+		Status: clusterv1.MachineStatus{
+			Conditions: clusterv1.Conditions{
+				{
+					Type:    clusterv1.MachineHealthCheckSucceededCondition,
+					Status:  corev1.ConditionFalse,
+					Message: "x" + strings.Repeat("é", maxConditionMessageLength),
+				},
+			},
+		},
+	}
+
+	got, err := conditionsSnapshot(machine)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	snapshot := []conditionSnapshot{}
+	g.Expect(json.Unmarshal([]byte(got), &snapshot)).To(Succeed())
+	g.Expect(snapshot).To(HaveLen(1))
+	// Multi-byte characters are not split.
+	g.Expect(utf8.ValidString(snapshot[0].Message)).To(BeTrue())
+	g.Expect(snapshot[0].Message).To(Equal("x" + strings.Repeat("é", (maxConditionMessageLength-1)/2) + "..."))
+}
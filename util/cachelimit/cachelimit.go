@@ -0,0 +1,343 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cachelimit implements safeguards against the runaway growth of the caches of a manager,
+// e.g. caused by a misbehaving provider flooding the management cluster or a workload cluster with objects.
+// The safeguards detect and report the growth, and can stop the controllers from accepting new Clusters;
+// the informers are never switched to metadata-only informers, because the controllers read full objects
+// from the caches.
+package cachelimit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// ManagementCacheName is the name of the cache of the manager, as opposed to the caches of the workload clusters.
+const ManagementCacheName = "management"
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(cachedObjects, limitExceeded)
+}
+
+var (
+	cachedObjects = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capi_cache_objects",
+		Help: "Number of objects in the informers of a cache, by cache and type.",
+	}, []string{"cache", "type"})
+
+	limitExceeded = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capi_cache_object_limit_exceeded",
+		Help: "Whether the number of objects of a type in a cache exceeds the configured limit (1) or not (0), by cache and type.",
+	}, []string{"cache", "type"})
+)
+
+// Policy defines the safeguards applied to the caches wrapped with NewCache.
+type Policy struct {
+	// MaxObjectsPerType is the number of objects of a type in a cache above which the cache is considered
+	// to be growing out of control; this is logged and reported by the capi_cache_object_limit_exceeded metric.
+	// If zero, the number of objects is not limited.
+	MaxObjectsPerType int
+
+	// RejectNewClusters instructs the controllers to not accept new Clusters for provisioning
+	// while the number of objects of a type in a cache exceeds MaxObjectsPerType.
+	RejectNewClusters bool
+}
+
+var (
+	policyLock sync.RWMutex
+	policy     Policy
+)
+
+// SetPolicy sets the Policy applied to the caches wrapped with NewCache.
+func SetPolicy(p Policy) {
+	policyLock.Lock()
+	defer policyLock.Unlock()
+	policy = p
+}
+
+// GetPolicy returns the Policy applied to the caches wrapped with NewCache.
+func GetPolicy() Policy {
+	policyLock.RLock()
+	defer policyLock.RUnlock()
+	return policy
+}
+
+var (
+	registryLock sync.Mutex
+	registry     = map[string]*Cache{}
+)
+
+// Exceeded returns a description of every type whose number of objects in a cache exceeds Policy.MaxObjectsPerType,
+// sorted; it returns nil if no limit is exceeded.
+func Exceeded() []string {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	var exceeded []string
+	for name, c := range registry {
+		for _, t := range c.exceeded() {
+			exceeded = append(exceeded, fmt.Sprintf("%s in cache %s", t, name))
+		}
+	}
+	sort.Strings(exceeded)
+	return exceeded
+}
+
+// RejectNewClusters returns true if new Clusters should not be accepted for provisioning, together with
+// a message describing the limits which are exceeded.
+func RejectNewClusters() (bool, string) {
+	if !GetPolicy().RejectNewClusters {
+		return false, ""
+	}
+	exceeded := Exceeded()
+	if len(exceeded) == 0 {
+		return false, ""
+	}
+	return true, fmt.Sprintf("Too many objects cached: %s", strings.Join(exceeded, ", "))
+}
+
+// Forget stops taking into account the cache with the given name, e.g. after it has been stopped,
+// and deletes its metrics.
+func Forget(name string) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	delete(registry, name)
+	labels := prometheus.Labels{"cache": name}
+	cachedObjects.DeletePartialMatch(labels)
+	limitExceeded.DeletePartialMatch(labels)
+}
+
+// NewCacheFunc returns a cache.NewCacheFunc, e.g. for the NewCache option of the manager,
+// creating a cache wrapped with NewCache.
+func NewCacheFunc(name string) cache.NewCacheFunc {
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		c, err := cache.New(config, opts)
+		if err != nil {
+			return nil, err
+		}
+		return NewCache(name, c, opts.Scheme), nil
+	}
+}
+
+// Cache is a cache.Cache counting the objects in its informers by type.
+type Cache struct {
+	cache.Cache
+
+	name     string
+	scheme   *runtime.Scheme
+	lock     sync.Mutex
+	counters map[string]*counter
+}
+
+var _ cache.Cache = &Cache{}
+
+// NewCache returns a Cache counting the objects in the informers of c created through it, that are the informers
+// used for watches and reads; it replaces any previous Cache with the same name.
+func NewCache(name string, c cache.Cache, scheme *runtime.Scheme) *Cache {
+	lc := &Cache{
+		Cache:    c,
+		name:     name,
+		scheme:   scheme,
+		counters: map[string]*counter{},
+	}
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	registry[name] = lc
+	return lc
+}
+
+// Get implements client.Reader.
+func (c *Cache) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	c.track(ctx, obj)
+	return c.Cache.Get(ctx, key, obj, opts...)
+}
+
+// List implements client.Reader.
+func (c *Cache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if obj, ok := c.objectForList(list); ok {
+		c.track(ctx, obj)
+	}
+	return c.Cache.List(ctx, list, opts...)
+}
+
+// GetInformer implements cache.Informers.
+func (c *Cache) GetInformer(ctx context.Context, obj client.Object, opts ...cache.InformerGetOption) (cache.Informer, error) {
+	informer, err := c.Cache.GetInformer(ctx, obj, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if key, ok := c.typeKey(obj); ok {
+		c.count(key, informer)
+	}
+	return informer, nil
+}
+
+// GetInformerForKind implements cache.Informers.
+func (c *Cache) GetInformerForKind(ctx context.Context, gvk schema.GroupVersionKind, opts ...cache.InformerGetOption) (cache.Informer, error) {
+	informer, err := c.Cache.GetInformerForKind(ctx, gvk, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.count(gvk.String(), informer)
+	return informer, nil
+}
+
+// RemoveInformer implements cache.Informers.
+func (c *Cache) RemoveInformer(ctx context.Context, obj client.Object) error {
+	if err := c.Cache.RemoveInformer(ctx, obj); err != nil {
+		return err
+	}
+	if key, ok := c.typeKey(obj); ok {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		delete(c.counters, key)
+		cachedObjects.DeleteLabelValues(c.name, key)
+		limitExceeded.DeleteLabelValues(c.name, key)
+	}
+	return nil
+}
+
+// track makes sure the objects of the type of obj are counted.
+func (c *Cache) track(ctx context.Context, obj client.Object) {
+	key, ok := c.typeKey(obj)
+	if !ok {
+		return
+	}
+	c.lock.Lock()
+	_, tracked := c.counters[key]
+	c.lock.Unlock()
+	if tracked {
+		return
+	}
+	// Note: errors are ignored, they are surfaced by the read which is going to use the same informer.
+	_, _ = c.GetInformer(ctx, obj)
+}
+
+// count starts counting the objects in informer, unless they are already counted.
+func (c *Cache) count(key string, informer cache.Informer) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.counters[key]; ok {
+		return
+	}
+
+	cnt := &counter{cache: c.name, typ: key}
+	if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { cnt.add(1) },
+		DeleteFunc: func(interface{}) { cnt.add(-1) },
+	}); err != nil {
+		return
+	}
+	c.counters[key] = cnt
+}
+
+// exceeded returns the types whose number of objects exceeds the limit.
+func (c *Cache) exceeded() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var exceeded []string
+	for key, cnt := range c.counters {
+		if cnt.exceeded.Load() {
+			exceeded = append(exceeded, key)
+		}
+	}
+	return exceeded
+}
+
+// typeKey returns the key identifying the type of obj in the metrics.
+func (c *Cache) typeKey(obj runtime.Object) (string, bool) {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return "", false
+	}
+	if _, ok := obj.(*metav1.PartialObjectMetadata); ok {
+		return gvk.String() + " (metadata)", true
+	}
+	return gvk.String(), true
+}
+
+// objectForList returns an object of the type of the items of list.
+func (c *Cache) objectForList(list client.ObjectList) (client.Object, bool) {
+	gvk, err := apiutil.GVKForObject(list, c.scheme)
+	if err != nil {
+		return nil, false
+	}
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+
+	switch list.(type) {
+	case *metav1.PartialObjectMetadataList:
+		obj := &metav1.PartialObjectMetadata{}
+		obj.SetGroupVersionKind(gvk)
+		return obj, true
+	case *unstructured.UnstructuredList:
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		return obj, true
+	}
+	o, err := c.scheme.New(gvk)
+	if err != nil {
+		return nil, false
+	}
+	obj, ok := o.(client.Object)
+	return obj, ok
+}
+
+// counter counts the objects of a type in a cache.
+type counter struct {
+	cache    string
+	typ      string
+	count    atomic.Int64
+	exceeded atomic.Bool
+}
+
+func (c *counter) add(delta int64) {
+	n := c.count.Add(delta)
+	cachedObjects.WithLabelValues(c.cache, c.typ).Set(float64(n))
+
+	limit := GetPolicy().MaxObjectsPerType
+	exceeded := limit > 0 && n > int64(limit)
+	if c.exceeded.Swap(exceeded) == exceeded {
+		return
+	}
+	if exceeded {
+		limitExceeded.WithLabelValues(c.cache, c.typ).Set(1)
+		ctrl.Log.WithName("cachelimit").Error(nil, "Too many objects cached, the cache is growing out of control", "cache", c.cache, "type", c.typ, "count", n, "limit", limit)
+		return
+	}
+	limitExceeded.WithLabelValues(c.cache, c.typ).Set(0)
+	ctrl.Log.WithName("cachelimit").Info("Number of objects cached is back within the limit", "cache", c.cache, "type", c.typ, "count", n, "limit", limit)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cachelimit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestCache(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	informers := &informertest.FakeInformers{Scheme: scheme}
+
+	SetPolicy(Policy{MaxObjectsPerType: 2, RejectNewClusters: true})
+	defer SetPolicy(Policy{})
+
+	c := NewCache("test", informers, scheme)
+	defer Forget("test")
+
+	// Objects are counted for the types read from the cache.
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "s1"}, &corev1.Secret{})).To(Succeed())
+	g.Expect(c.List(ctx, &corev1.ConfigMapList{})).To(Succeed())
+	g.Expect(c.counters).To(HaveKey("/v1, Kind=Secret"))
+	g.Expect(c.counters).To(HaveKey("/v1, Kind=ConfigMap"))
+
+	secrets, err := informers.FakeInformerFor(ctx, &corev1.Secret{})
+	g.Expect(err).ToNot(HaveOccurred())
+	for i := 0; i < 3; i++ {
+		secrets.Add(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("s%d", i)}})
+	}
+	g.Expect(Exceeded()).To(ConsistOf("/v1, Kind=Secret in cache test"))
+	reject, message := RejectNewClusters()
+	g.Expect(reject).To(BeTrue())
+	g.Expect(message).To(ContainSubstring("/v1, Kind=Secret in cache test"))
+
+	// New Clusters are accepted again when the number of objects is back within the limit.
+	secrets.Delete(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "s0"}})
+	g.Expect(Exceeded()).To(BeEmpty())
+	reject, _ = RejectNewClusters()
+	g.Expect(reject).To(BeFalse())
+
+	// Forgotten caches are not taken into account anymore.
+	secrets.Add(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "s3"}})
+	g.Expect(Exceeded()).ToNot(BeEmpty())
+	Forget("test")
+	g.Expect(Exceeded()).To(BeEmpty())
+}
+
+func TestRejectNewClusters(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	informers := &informertest.FakeInformers{Scheme: scheme}
+
+	SetPolicy(Policy{MaxObjectsPerType: 1})
+	defer SetPolicy(Policy{})
+
+	c := NewCache("test", informers, scheme)
+	defer Forget("test")
+
+	nodes, err := c.GetInformer(context.Background(), &corev1.Node{})
+	g.Expect(err).ToNot(HaveOccurred())
+	nodes.(interface{ Add(metav1.Object) }).Add(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n1"}})
+	nodes.(interface{ Add(metav1.Object) }).Add(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n2"}})
+	g.Expect(Exceeded()).To(ConsistOf("/v1, Kind=Node in cache test"))
+
+	// New Clusters are rejected only if enabled by the Policy.
+	reject, _ := RejectNewClusters()
+	g.Expect(reject).To(BeFalse())
+}
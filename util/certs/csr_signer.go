@@ -0,0 +1,204 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"time"
+
+	"github.com/pkg/errors"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/cert"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// csrGenerateNamePrefix is the prefix of the name of the CertificateSigningRequests created by the CSR signer.
+	csrGenerateNamePrefix = "cluster-api-"
+
+	// csrRequestIsCAAnnotation is the annotation used by cert-manager to issue CA certificates
+	// for CertificateSigningRequests; it is ignored by other signers.
+	csrRequestIsCAAnnotation = "experimental.cert-manager.io/request-is-ca"
+)
+
+var (
+	// csrPollInterval is the interval at which the CSR signer checks if a CertificateSigningRequest is issued.
+	csrPollInterval = 2 * time.Second
+
+	// csrTimeout is the time the CSR signer waits for a CertificateSigningRequest to be issued.
+	csrTimeout = 30 * time.Second
+
+	// oidExtensionBasicConstraints is the OID of the X.509 basic constraints extension.
+	oidExtensionBasicConstraints = asn1.ObjectIdentifier{2, 5, 29, 19}
+)
+
+// NewCSRSigner returns a Signer issuing certificates through Kubernetes CertificateSigningRequests
+// for the given signer name, so the issuing certificate authority is held by an external signer
+// integrated with the certificates.k8s.io API, e.g. a cert-manager Issuer backed by Vault PKI or a cloud CA
+// ("clusterissuers.cert-manager.io/<name>").
+// If approve is true the signer approves its own CertificateSigningRequests, which requires permissions to approve
+// requests for signerName; otherwise they must be approved by an external approver before the timeout expires.
+func NewCSRSigner(c client.Client, signerName string, approve bool) Signer {
+	return &csrSigner{client: c, signerName: signerName, approve: approve}
+}
+
+type csrSigner struct {
+	client     client.Client
+	signerName string
+	approve    bool
+}
+
+// Sign creates a CertificateSigningRequest for template and waits for the external signer to issue it.
+func (s *csrSigner) Sign(ctx context.Context, template *x509.Certificate, key crypto.Signer) ([]*x509.Certificate, error) {
+	request, err := newCertificateRequestPEM(template, key)
+	if err != nil {
+		return nil, err
+	}
+
+	csr := &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: csrGenerateNamePrefix,
+		},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			Request:    request,
+			SignerName: s.signerName,
+			Usages:     keyUsages(template),
+		},
+	}
+	if template.IsCA {
+		csr.Annotations = map[string]string{csrRequestIsCAAnnotation: "true"}
+	}
+	if !template.NotAfter.IsZero() {
+		csr.Spec.ExpirationSeconds = ptr.To(int32(time.Until(template.NotAfter).Seconds()))
+	}
+	if err := s.client.Create(ctx, csr); err != nil {
+		return nil, errors.Wrapf(err, "failed to create CertificateSigningRequest for signer %q", s.signerName)
+	}
+	// The issued certificate is returned to the caller, so the CertificateSigningRequest is not required anymore.
+	defer func() {
+		_ = client.IgnoreNotFound(s.client.Delete(ctx, csr))
+	}()
+
+	if s.approve {
+		csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+			Type:    certificatesv1.CertificateApproved,
+			Status:  corev1.ConditionTrue,
+			Reason:  "ClusterAPIApproved",
+			Message: "This CertificateSigningRequest was approved by Cluster API",
+		})
+		if err := s.client.SubResource("approval").Update(ctx, csr); err != nil {
+			return nil, errors.Wrapf(err, "failed to approve CertificateSigningRequest %s", csr.Name)
+		}
+	}
+
+	var issued []byte
+	err = wait.PollUntilContextTimeout(ctx, csrPollInterval, csrTimeout, true, func(ctx context.Context) (bool, error) {
+		if err := s.client.Get(ctx, client.ObjectKeyFromObject(csr), csr); err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, err
+			}
+			return false, nil
+		}
+		for _, c := range csr.Status.Conditions {
+			if (c.Type == certificatesv1.CertificateDenied || c.Type == certificatesv1.CertificateFailed) && c.Status == corev1.ConditionTrue {
+				return false, errors.Errorf("CertificateSigningRequest %s is %s: %s", csr.Name, c.Type, c.Message)
+			}
+		}
+		issued = csr.Status.Certificate
+		return len(issued) > 0, nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to wait for CertificateSigningRequest %s to be issued by signer %q", csr.Name, s.signerName)
+	}
+
+	chain, err := cert.ParseCertsPEM(issued)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse certificate issued for CertificateSigningRequest %s", csr.Name)
+	}
+	return chain, nil
+}
+
+// newCertificateRequestPEM returns a PEM encoded certificate signing request for template signed with key.
+func newCertificateRequestPEM(template *x509.Certificate, key crypto.Signer) ([]byte, error) {
+	req := &x509.CertificateRequest{
+		Subject:     template.Subject,
+		DNSNames:    template.DNSNames,
+		IPAddresses: template.IPAddresses,
+	}
+	if template.IsCA {
+		// CertificateRequest has no fields for the basic constraints, so they are requested as an extension.
+		constraints, err := asn1.Marshal(struct {
+			IsCA       bool `asn1:"optional"`
+			MaxPathLen int  `asn1:"optional,default:-1"`
+		}{IsCA: true, MaxPathLen: maxPathLen(template)})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode basic constraints")
+		}
+		req.ExtraExtensions = []pkix.Extension{{Id: oidExtensionBasicConstraints, Critical: true, Value: constraints}}
+	}
+
+	b, err := x509.CreateCertificateRequest(rand.Reader, req, key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create certificate signing request for %q", template.Subject.CommonName)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: cert.CertificateRequestBlockType, Bytes: b}), nil
+}
+
+// maxPathLen returns the max path length of template as encoded in the basic constraints extension.
+func maxPathLen(template *x509.Certificate) int {
+	if template.MaxPathLen == 0 && !template.MaxPathLenZero {
+		return -1
+	}
+	return template.MaxPathLen
+}
+
+// keyUsages returns the CertificateSigningRequest usages for the key usages of template.
+func keyUsages(template *x509.Certificate) []certificatesv1.KeyUsage {
+	usages := []certificatesv1.KeyUsage{}
+	for _, u := range []struct {
+		keyUsage x509.KeyUsage
+		usage    certificatesv1.KeyUsage
+	}{
+		{x509.KeyUsageDigitalSignature, certificatesv1.UsageDigitalSignature},
+		{x509.KeyUsageKeyEncipherment, certificatesv1.UsageKeyEncipherment},
+		{x509.KeyUsageCertSign, certificatesv1.UsageCertSign},
+	} {
+		if template.KeyUsage&u.keyUsage != 0 {
+			usages = append(usages, u.usage)
+		}
+	}
+	for _, u := range template.ExtKeyUsage {
+		switch u {
+		case x509.ExtKeyUsageServerAuth:
+			usages = append(usages, certificatesv1.UsageServerAuth)
+		case x509.ExtKeyUsageClientAuth:
+			usages = append(usages, certificatesv1.UsageClientAuth)
+		}
+	}
+	return usages
+}
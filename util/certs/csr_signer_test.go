@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/cert"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestCSRSigner(t *testing.T) {
+	defer func(interval, timeout time.Duration) {
+		csrPollInterval, csrTimeout = interval, timeout
+	}(csrPollInterval, csrTimeout)
+	csrPollInterval = 10 * time.Millisecond
+	csrTimeout = 100 * time.Millisecond
+
+	scheme := runtime.NewScheme()
+	_ = certificatesv1.AddToScheme(scheme)
+
+	g := NewWithT(t)
+	rootCert, rootSigner := newTestRootCA(g)
+
+	// issue simulates an external signer issuing the CertificateSigningRequest with the test root CA.
+	issue := func(ctx context.Context, c client.Client, csr *certificatesv1.CertificateSigningRequest) error {
+		block, _ := pem.Decode(csr.Spec.Request)
+		req, err := x509.ParseCertificateRequest(block.Bytes)
+		if err != nil {
+			return err
+		}
+		if err := req.CheckSignature(); err != nil {
+			return err
+		}
+		b, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber:    big.NewInt(2),
+			Subject:         req.Subject,
+			NotBefore:       time.Now().Add(-time.Minute),
+			NotAfter:        time.Now().Add(time.Hour),
+			KeyUsage:        x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+			ExtraExtensions: req.Extensions,
+		}, rootCert, req.PublicKey, rootSigner.(*keyPairSigner).caKey)
+		if err != nil {
+			return err
+		}
+		csr.Status.Certificate = append(pem.EncodeToMemory(&pem.Block{Type: cert.CertificateBlockType, Bytes: b}), EncodeCertPEM(rootCert)...)
+		return c.Status().Update(ctx, csr)
+	}
+
+	t.Run("issues an intermediate CA through an approved CertificateSigningRequest", func(t *testing.T) {
+		g := NewWithT(t)
+
+		var created *certificatesv1.CertificateSigningRequest
+		c := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				created = obj.(*certificatesv1.CertificateSigningRequest).DeepCopy()
+				return c.Create(ctx, obj, opts...)
+			},
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				g.Expect(subResource).To(Equal("approval"))
+				if err := c.SubResource(subResource).Update(ctx, obj, opts...); err != nil {
+					return err
+				}
+				return issue(ctx, c, obj.(*certificatesv1.CertificateSigningRequest))
+			},
+		}).Build()
+
+		kp, err := NewIntermediateCA(context.Background(), NewCSRSigner(c, "clusterissuers.cert-manager.io/ca", true), &Config{CommonName: "kubernetes"})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(created.Spec.SignerName).To(Equal("clusterissuers.cert-manager.io/ca"))
+		g.Expect(created.Spec.Usages).To(ConsistOf(certificatesv1.UsageDigitalSignature, certificatesv1.UsageKeyEncipherment, certificatesv1.UsageCertSign))
+		g.Expect(created.Annotations).To(HaveKeyWithValue(csrRequestIsCAAnnotation, "true"))
+		g.Expect(created.Spec.ExpirationSeconds).ToNot(BeNil())
+
+		chain, err := cert.ParseCertsPEM(kp.Cert)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(chain).To(HaveLen(2))
+		g.Expect(chain[0].IsCA).To(BeTrue())
+		g.Expect(chain[0].CheckSignatureFrom(rootCert)).To(Succeed())
+
+		// The CertificateSigningRequest is deleted once the certificate is issued.
+		csrs := &certificatesv1.CertificateSigningRequestList{}
+		g.Expect(c.List(context.Background(), csrs)).To(Succeed())
+		g.Expect(csrs.Items).To(BeEmpty())
+	})
+
+	t.Run("fails if the CertificateSigningRequest is denied", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if err := c.Create(ctx, obj, opts...); err != nil {
+					return err
+				}
+				csr := obj.(*certificatesv1.CertificateSigningRequest)
+				csr.Status.Conditions = []certificatesv1.CertificateSigningRequestCondition{
+					{Type: certificatesv1.CertificateDenied, Status: corev1.ConditionTrue, Message: "not allowed"},
+				}
+				return c.Status().Update(ctx, csr)
+			},
+		}).Build()
+
+		_, err := NewIntermediateCA(context.Background(), NewCSRSigner(c, "example.com/signer", false), &Config{CommonName: "kubernetes"})
+		g.Expect(err).To(MatchError(ContainSubstring("not allowed")))
+	})
+
+	t.Run("fails if the CertificateSigningRequest is not issued before the timeout", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		_, err := NewIntermediateCA(context.Background(), NewCSRSigner(c, "example.com/signer", false), &Config{CommonName: "kubernetes"})
+		g.Expect(err).To(HaveOccurred())
+
+		csrs := &certificatesv1.CertificateSigningRequestList{}
+		g.Expect(c.List(context.Background(), csrs)).To(Succeed())
+		g.Expect(csrs.Items).To(BeEmpty())
+	})
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Signer signs certificates on behalf of a certificate authority whose private key
+// is never exposed to Cluster API, e.g. a Vault PKI backend, a cloud KMS/CA service or a cert-manager Issuer.
+type Signer interface {
+	// Sign signs a certificate for the public key of key using template as a base; implementations
+	// are allowed to override fields of the template according to their own policies.
+	// The private key is only used to prove possession of the key, e.g. by signing a certificate
+	// signing request, and it must not be sent to the signer.
+	// It returns the signed certificate followed by the chain of the issuing certificate authorities,
+	// ordered from the issuer of the signed certificate up to the root.
+	Sign(ctx context.Context, template *x509.Certificate, key crypto.Signer) ([]*x509.Certificate, error)
+}
+
+// NewKeyPairSigner returns a Signer using a CA certificate and key available in memory, e.g. an issuing CA
+// read from files provided from outside of the management cluster.
+// Use NewCSRSigner for certificate authorities whose key is held by an external system.
+func NewKeyPairSigner(caCert *x509.Certificate, caKey crypto.Signer) Signer {
+	return &keyPairSigner{caCert: caCert, caKey: caKey}
+}
+
+type keyPairSigner struct {
+	caCert *x509.Certificate
+	caKey  crypto.Signer
+}
+
+// Sign signs a certificate with the in memory CA key.
+func (s *keyPairSigner) Sign(_ context.Context, template *x509.Certificate, key crypto.Signer) ([]*x509.Certificate, error) {
+	b, err := x509.CreateCertificate(rand.Reader, template, s.caCert, key.Public(), s.caKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create signed certificate: %+v", template)
+	}
+	c, err := x509.ParseCertificate(b)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse signed certificate")
+	}
+	return []*x509.Certificate{c, s.caCert}, nil
+}
+
+// NewIntermediateCA generates a new private key and uses the given Signer to issue an intermediate
+// certificate authority for it, so that the root key is never held by the caller.
+// The returned KeyPair contains the PEM-encoded intermediate certificate followed by its issuing chain
+// and the PEM-encoded intermediate private key.
+func NewIntermediateCA(ctx context.Context, signer Signer, cfg *Config) (*KeyPair, error) {
+	if signer == nil {
+		return nil, errors.New("signer must not be nil")
+	}
+	if cfg.CommonName == "" {
+		return nil, errors.New("must specify a CommonName")
+	}
+
+	key, err := NewPrivateKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create private key for intermediate CA")
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).SetInt64(math.MaxInt64))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate random integer for intermediate CA certificate")
+	}
+
+	now := time.Now().UTC()
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:   cfg.CommonName,
+			Organization: cfg.Organization,
+		},
+		NotBefore:             now.Add(time.Minute * -5),
+		NotAfter:              now.Add(time.Hour * 24 * 365 * 10), // 10 years
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		MaxPathLenZero:        true,
+		BasicConstraintsValid: true,
+		MaxPathLen:            0,
+		IsCA:                  true,
+	}
+
+	chain, err := signer.Sign(ctx, tmpl, key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to sign intermediate CA certificate %q", cfg.CommonName)
+	}
+	if len(chain) == 0 {
+		return nil, errors.Errorf("signer returned an empty certificate chain for intermediate CA certificate %q", cfg.CommonName)
+	}
+	if !key.PublicKey.Equal(chain[0].PublicKey) {
+		return nil, errors.Errorf("signer returned a certificate for a different public key for intermediate CA certificate %q", cfg.CommonName)
+	}
+	if !chain[0].IsCA {
+		return nil, errors.Errorf("signer returned a certificate which is not a CA for intermediate CA certificate %q", cfg.CommonName)
+	}
+
+	bundle := make([]byte, 0)
+	for _, c := range chain {
+		bundle = append(bundle, EncodeCertPEM(c)...)
+	}
+	return &KeyPair{
+		Cert: bundle,
+		Key:  EncodePrivateKeyPEM(key),
+	}, nil
+}
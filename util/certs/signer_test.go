@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/util/cert"
+)
+
+func TestNewIntermediateCA(t *testing.T) {
+	g := NewWithT(t)
+
+	rootCert, rootSigner := newTestRootCA(g)
+
+	kp, err := NewIntermediateCA(context.Background(), rootSigner, &Config{CommonName: "kubernetes"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(kp.IsValid()).To(BeTrue())
+
+	chain, err := cert.ParseCertsPEM(kp.Cert)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(chain).To(HaveLen(2))
+	g.Expect(chain[0].IsCA).To(BeTrue())
+	g.Expect(chain[0].Subject.CommonName).To(Equal("kubernetes"))
+	g.Expect(chain[0].CheckSignatureFrom(rootCert)).To(Succeed())
+	g.Expect(chain[1].Equal(rootCert)).To(BeTrue())
+
+	// The intermediate CA can sign leaf certificates which are trusted by the root.
+	intermediateKey, err := DecodePrivateKeyPEM(kp.Key)
+	g.Expect(err).ToNot(HaveOccurred())
+	leafKey, err := NewPrivateKey()
+	g.Expect(err).ToNot(HaveOccurred())
+	leaf, err := (&Config{CommonName: "leaf", Usages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}).NewSignedCert(leafKey, chain[0], intermediateKey)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	roots := x509.NewCertPool()
+	roots.AddCert(rootCert)
+	intermediates := x509.NewCertPool()
+	intermediates.AddCert(chain[0])
+	_, err = leaf.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	g.Expect(err).ToNot(HaveOccurred())
+}
+
+func TestNewIntermediateCAErrors(t *testing.T) {
+	_, rootSigner := newTestRootCA(NewWithT(t))
+
+	tests := []struct {
+		name   string
+		signer Signer
+		cfg    *Config
+	}{
+		{
+			name:   "nil signer",
+			signer: nil,
+			cfg:    &Config{CommonName: "kubernetes"},
+		},
+		{
+			name:   "missing common name",
+			signer: rootSigner,
+			cfg:    &Config{},
+		},
+		{
+			name: "signer returning an empty chain",
+			signer: signerFunc(func(context.Context, *x509.Certificate, crypto.Signer) ([]*x509.Certificate, error) {
+				return nil, nil
+			}),
+			cfg: &Config{CommonName: "kubernetes"},
+		},
+		{
+			name: "signer returning a certificate for another key",
+			signer: signerFunc(func(ctx context.Context, tmpl *x509.Certificate, _ crypto.Signer) ([]*x509.Certificate, error) {
+				otherKey, err := NewPrivateKey()
+				if err != nil {
+					return nil, err
+				}
+				return rootSigner.Sign(ctx, tmpl, otherKey)
+			}),
+			cfg: &Config{CommonName: "kubernetes"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			_, err := NewIntermediateCA(context.Background(), tt.signer, tt.cfg)
+			g.Expect(err).To(HaveOccurred())
+		})
+	}
+}
+
+type signerFunc func(ctx context.Context, template *x509.Certificate, key crypto.Signer) ([]*x509.Certificate, error)
+
+func (f signerFunc) Sign(ctx context.Context, template *x509.Certificate, key crypto.Signer) ([]*x509.Certificate, error) {
+	return f(ctx, template, key)
+}
+
+func newTestRootCA(g *WithT) (*x509.Certificate, Signer) {
+	key, err := NewPrivateKey()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	now := time.Now().UTC()
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "root"},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	b, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	g.Expect(err).ToNot(HaveOccurred())
+	rootCert, err := x509.ParseCertificate(b)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	return rootCert, NewKeyPairSigner(rootCert, key)
+}
@@ -24,6 +24,7 @@ limitations under the License.
 //   - Added FromMachineList initializer
 //   - Updated Has to also check for equality of Machines
 //   - Removed unused methods
+//   - Added Intersection and Union methods
 
 package collections
 
@@ -110,10 +111,41 @@ func (s Machines) Insert(machines ...*clusterv1.Machine) {
 
 // Difference returns a copy without machines that are in the given collection.
 func (s Machines) Difference(machines Machines) Machines {
-	return s.Filter(func(m *clusterv1.Machine) bool {
-		_, found := machines[m.Name]
-		return !found
-	})
+	result := make(Machines, len(s))
+	for name, m := range s {
+		if _, found := machines[name]; !found {
+			result[name] = m
+		}
+	}
+	return result
+}
+
+// Intersection returns a copy containing only the machines that are also in the given collection.
+func (s Machines) Intersection(machines Machines) Machines {
+	smaller, larger := s, machines
+	if len(larger) < len(smaller) {
+		smaller, larger = larger, smaller
+	}
+	result := make(Machines, len(smaller))
+	for name := range smaller {
+		if _, found := larger[name]; found {
+			result[name] = s[name]
+		}
+	}
+	return result
+}
+
+// Union returns a copy containing the machines that are in either collection.
+// If a machine exists in both collections, the one from this collection is retained.
+func (s Machines) Union(machines Machines) Machines {
+	result := make(Machines, len(s)+len(machines))
+	for name, m := range machines {
+		result[name] = m
+	}
+	for name, m := range s {
+		result[name] = m
+	}
+	return result
 }
 
 // SortedByCreationTimestamp returns the machines sorted by creation timestamp.
@@ -140,13 +172,12 @@ func (s Machines) Len() int {
 	return len(s)
 }
 
-// newFilteredMachineCollection creates a Machines from a filtered list of values.
-func newFilteredMachineCollection(filter Func, machines ...*clusterv1.Machine) Machines {
+// newFilteredMachineCollection creates a Machines from a filtered set of values.
+func newFilteredMachineCollection(filter Func, machines Machines) Machines {
 	ss := make(Machines, len(machines))
-	for i := range machines {
-		m := machines[i]
-		if filter(m) {
-			ss.Insert(m)
+	for name, m := range machines {
+		if m != nil && filter(m) {
+			ss[name] = m
 		}
 	}
 	return ss
@@ -154,12 +185,12 @@ func newFilteredMachineCollection(filter Func, machines ...*clusterv1.Machine) M
 
 // Filter returns a Machines containing only the Machines that match all of the given MachineFilters.
 func (s Machines) Filter(filters ...Func) Machines {
-	return newFilteredMachineCollection(And(filters...), s.UnsortedList()...)
+	return newFilteredMachineCollection(And(filters...), s)
 }
 
 // AnyFilter returns a Machines containing only the Machines that match any of the given MachineFilters.
 func (s Machines) AnyFilter(filters ...Func) Machines {
-	return newFilteredMachineCollection(Or(filters...), s.UnsortedList()...)
+	return newFilteredMachineCollection(Or(filters...), s)
 }
 
 // Oldest returns the Machine with the oldest CreationTimestamp.
@@ -52,6 +52,82 @@ func TestMachineCollection(t *testing.T) {
 			g.Expect(c3.Names()).To(ConsistOf("machine-1"))
 		})
 	})
+	t.Run("Intersection", func(t *testing.T) {
+		t.Run("should return the collection with only elements present in both collections", func(t *testing.T) {
+			g := NewWithT(t)
+			collection := machines()
+			c2 := collections.FromMachines(machine("machine-1"), machine("machine-3"), machine("machine-6"))
+			c3 := collection.Intersection(c2)
+			g.Expect(c3.Names()).To(ConsistOf("machine-1", "machine-3"))
+			// retains machines from the receiver
+			g.Expect(c3["machine-1"]).To(BeIdenticalTo(collection["machine-1"]))
+			g.Expect(collection.Intersection(collections.New())).To(BeEmpty())
+		})
+	})
+	t.Run("Union", func(t *testing.T) {
+		t.Run("should return the collection with elements of both collections", func(t *testing.T) {
+			g := NewWithT(t)
+			collection := machines()
+			c2 := collections.FromMachines(machine("machine-1"), machine("machine-6"))
+			c3 := collection.Union(c2)
+			g.Expect(c3.Names()).To(ConsistOf("machine-1", "machine-2", "machine-3", "machine-4", "machine-5", "machine-6"))
+			// retains machines from the receiver
+			g.Expect(c3["machine-1"]).To(BeIdenticalTo(collection["machine-1"]))
+			// does not mutate
+			g.Expect(collection.Names()).ToNot(ContainElement("machine-6"))
+		})
+	})
+	t.Run("SortedBy", func(t *testing.T) {
+		t.Run("should sort by name when no compare func is given", func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(names(machines().SortedBy())).To(Equal([]string{"machine-1", "machine-2", "machine-3", "machine-4", "machine-5"}))
+		})
+		t.Run("should sort by creation timestamp", func(t *testing.T) {
+			g := NewWithT(t)
+			collection := machines()
+			collection.Insert(machine("machine-0", withCreationTimestamp(collection["machine-3"].CreationTimestamp)))
+			g.Expect(names(collection.SortedBy(collections.ByCreationTimestamp))).To(Equal([]string{"machine-1", "machine-2", "machine-0", "machine-3", "machine-4", "machine-5"}))
+			g.Expect(names(collection.SortedBy(collections.Reverse(collections.ByCreationTimestamp)))).To(Equal([]string{"machine-5", "machine-4", "machine-0", "machine-3", "machine-2", "machine-1"}))
+		})
+		t.Run("should sort by deletion priority using the following compare funcs as tie breakers", func(t *testing.T) {
+			g := NewWithT(t)
+			collection := machines()
+			collection["machine-5"].DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			collection["machine-4"].Annotations = map[string]string{clusterv1.DeleteMachineAnnotation: ""}
+			collection["machine-3"].Status.FailureMessage = ptr.To("failure")
+			collection["machine-2"].Annotations = map[string]string{clusterv1.DeleteMachineAnnotation: ""}
+			g.Expect(names(collection.SortedBy(collections.ByDeletionPriority, collections.ByCreationTimestamp))).To(Equal([]string{"machine-5", "machine-2", "machine-4", "machine-3", "machine-1"}))
+		})
+	})
+	t.Run("Chunk", func(t *testing.T) {
+		t.Run("should split machines in chunks", func(t *testing.T) {
+			g := NewWithT(t)
+			sorted := machines().SortedBy()
+			chunks := collections.Chunk(sorted, 2)
+			g.Expect(chunks).To(HaveLen(3))
+			g.Expect(names(chunks[0])).To(Equal([]string{"machine-1", "machine-2"}))
+			g.Expect(names(chunks[1])).To(Equal([]string{"machine-3", "machine-4"}))
+			g.Expect(names(chunks[2])).To(Equal([]string{"machine-5"}))
+			// appending to a chunk does not overwrite the following one
+			_ = append(chunks[0], machine("machine-6"))
+			g.Expect(names(chunks[1])).To(Equal([]string{"machine-3", "machine-4"}))
+
+			g.Expect(collections.Chunk(sorted, 10)).To(HaveLen(1))
+			g.Expect(collections.Chunk(sorted, 0)).To(BeEmpty())
+			g.Expect(collections.Chunk(nil, 2)).To(BeEmpty())
+		})
+	})
+	t.Run("Page", func(t *testing.T) {
+		t.Run("should return a page of machines", func(t *testing.T) {
+			g := NewWithT(t)
+			sorted := machines().SortedBy()
+			g.Expect(names(collections.Page(sorted, 0, 2))).To(Equal([]string{"machine-1", "machine-2"}))
+			g.Expect(names(collections.Page(sorted, 3, 10))).To(Equal([]string{"machine-4", "machine-5"}))
+			g.Expect(names(collections.Page(sorted, 1, 0))).To(Equal([]string{"machine-2", "machine-3", "machine-4", "machine-5"}))
+			g.Expect(collections.Page(sorted, 5, 2)).To(BeEmpty())
+			g.Expect(collections.Page(sorted, -1, 2)).To(BeEmpty())
+		})
+	})
 	t.Run("Names", func(t *testing.T) {
 		t.Run("should return a slice of names of each machine in the collection", func(t *testing.T) {
 			g := NewWithT(t)
@@ -164,6 +240,14 @@ func machine(name string, opts ...machineOpt) *clusterv1.Machine {
 	return m
 }
 
+func names(machines []*clusterv1.Machine) []string {
+	res := make([]string, 0, len(machines))
+	for _, m := range machines {
+		res = append(res, m.Name)
+	}
+	return res
+}
+
 func machines() collections.Machines {
 	return collections.Machines{
 		"machine-4": machine("machine-4", withCreationTimestamp(metav1.Time{Time: time.Date(2018, 04, 02, 03, 04, 05, 06, time.UTC)})),
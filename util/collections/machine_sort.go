@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collections
+
+import (
+	"slices"
+	"strings"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// CompareFunc compares two Machines and returns a negative number if a should be ordered before b,
+// a positive number if a should be ordered after b, and zero if the order is not determined by this func.
+type CompareFunc func(a, b *clusterv1.Machine) int
+
+// ByName orders Machines by name.
+func ByName(a, b *clusterv1.Machine) int {
+	return strings.Compare(a.Name, b.Name)
+}
+
+// ByCreationTimestamp orders Machines by creation timestamp, oldest first.
+func ByCreationTimestamp(a, b *clusterv1.Machine) int {
+	if a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return 0
+	}
+	if a.CreationTimestamp.Before(&b.CreationTimestamp) {
+		return -1
+	}
+	return 1
+}
+
+// ByDeletionPriority orders Machines by deletion priority, highest priority first.
+// Machines already being deleted come first, followed by Machines marked with the delete machine annotation
+// and then by unhealthy Machines; Machines without any of those properties come last.
+func ByDeletionPriority(a, b *clusterv1.Machine) int {
+	return deletionPriority(b) - deletionPriority(a)
+}
+
+func deletionPriority(m *clusterv1.Machine) int {
+	switch {
+	case !m.DeletionTimestamp.IsZero():
+		return 3
+	case HasAnnotationKey(clusterv1.DeleteMachineAnnotation)(m):
+		return 2
+	case m.Status.FailureReason != nil || m.Status.FailureMessage != nil || HasUnhealthyCondition(m):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Reverse inverts the order defined by the given CompareFunc.
+func Reverse(compare CompareFunc) CompareFunc {
+	return func(a, b *clusterv1.Machine) int {
+		return compare(b, a)
+	}
+}
+
+// SortedBy returns the machines sorted using the given CompareFuncs; each func is used as a tie breaker
+// for the previous ones, and the Machine name is always used as the last tie breaker so the order is stable.
+func (s Machines) SortedBy(compares ...CompareFunc) []*clusterv1.Machine {
+	res := s.UnsortedList()
+	slices.SortFunc(res, func(a, b *clusterv1.Machine) int {
+		for _, compare := range compares {
+			if c := compare(a, b); c != 0 {
+				return c
+			}
+		}
+		return ByName(a, b)
+	})
+	return res
+}
+
+// Chunk splits the given list of machines in chunks of at most size elements.
+// Chunks share the backing array of the input list, so no Machine pointers are copied.
+func Chunk(machines []*clusterv1.Machine, size int) [][]*clusterv1.Machine {
+	if size <= 0 || len(machines) == 0 {
+		return nil
+	}
+	chunks := make([][]*clusterv1.Machine, 0, (len(machines)+size-1)/size)
+	for size < len(machines) {
+		machines, chunks = machines[size:], append(chunks, machines[0:size:size])
+	}
+	return append(chunks, machines)
+}
+
+// Page returns at most limit machines starting from offset from the given list of machines.
+// The returned list shares the backing array of the input list; an empty list is returned
+// if offset is out of range, while a limit <= 0 returns all the machines after offset.
+func Page(machines []*clusterv1.Machine, offset, limit int) []*clusterv1.Machine {
+	if offset < 0 || offset >= len(machines) {
+		return []*clusterv1.Machine{}
+	}
+	end := len(machines)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return machines[offset:end:end]
+}
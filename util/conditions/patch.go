@@ -145,7 +145,7 @@ func (p Patch) Apply(latest Setter, options ...ApplyOption) error {
 		case AddConditionPatch:
 			// If the conditions is owned, always keep the after value.
 			if applyOpt.forceOverwrite || applyOpt.isOwnedCondition(conditionPatch.After.Type) {
-				Set(latest, conditionPatch.After)
+				setPreservingLastTransitionTime(latest, conditionPatch.After)
 				continue
 			}
 
@@ -160,12 +160,12 @@ func (p Patch) Apply(latest Setter, options ...ApplyOption) error {
 				continue
 			}
 			// If the condition does not exists on the latest, add the new after condition.
-			Set(latest, conditionPatch.After)
+			setPreservingLastTransitionTime(latest, conditionPatch.After)
 
 		case ChangeConditionPatch:
 			// If the conditions is owned, always keep the after value.
 			if applyOpt.forceOverwrite || applyOpt.isOwnedCondition(conditionPatch.After.Type) {
-				Set(latest, conditionPatch.After)
+				setPreservingLastTransitionTime(latest, conditionPatch.After)
 				continue
 			}
 
@@ -187,7 +187,7 @@ func (p Patch) Apply(latest Setter, options ...ApplyOption) error {
 				continue
 			}
 			// Otherwise apply the new after condition.
-			Set(latest, conditionPatch.After)
+			setPreservingLastTransitionTime(latest, conditionPatch.After)
 
 		case RemoveConditionPatch:
 			// If the conditions is owned, always keep the after value (condition should be deleted).
@@ -210,6 +210,18 @@ func (p Patch) Apply(latest Setter, options ...ApplyOption) error {
 	return nil
 }
 
+// setPreservingLastTransitionTime sets a copy of the condition on the given object, preserving the LastTransitionTime
+// of the condition, if set, instead of using the time of the patch; if the object already has a condition with the same
+// state, its LastTransitionTime is preserved instead.
+// NOTE: Using a copy of the condition avoids altering the patch, which can be applied more than once, e.g. on conflicts.
+func setPreservingLastTransitionTime(to Setter, condition *clusterv1.Condition) {
+	condition = condition.DeepCopy()
+	if existing := Get(to, condition.Type); existing != nil && !hasSameState(existing, condition) && !condition.LastTransitionTime.IsZero() {
+		Delete(to, condition.Type)
+	}
+	Set(to, condition)
+}
+
 // IsZero returns true if the patch is nil or has no changes.
 func (p Patch) IsZero() bool {
 	if p == nil {
@@ -339,3 +339,35 @@ func TestApplyDoesNotAlterLastTransitionTime(t *testing.T) {
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(latest.GetConditions()).To(BeComparableTo(after.GetConditions()))
 }
+
+func TestApplyPreservesLastTransitionTimeOnChange(t *testing.T) {
+	g := NewWithT(t)
+
+	lastTransitionTime := metav1.NewTime(time.Now().Add(-time.Hour).UTC().Truncate(time.Second))
+	before := &clusterv1.Cluster{
+		Status: clusterv1.ClusterStatus{
+			Conditions: clusterv1.Conditions{
+				{Type: "foo", Status: corev1.ConditionFalse, Severity: clusterv1.ConditionSeverityInfo, Reason: "Foo", LastTransitionTime: lastTransitionTime},
+			},
+		},
+	}
+	after := &clusterv1.Cluster{
+		Status: clusterv1.ClusterStatus{
+			Conditions: clusterv1.Conditions{
+				{Type: "foo", Status: corev1.ConditionTrue, LastTransitionTime: lastTransitionTime},
+			},
+		},
+	}
+	latest := before.DeepCopy()
+
+	// The condition is changed, but the LastTransitionTime computed when changing after must be preserved,
+	// even if the patch is applied more than once, e.g. when retrying on conflicts.
+	diff, err := NewPatch(before, after)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(diff.Apply(latest)).To(Succeed())
+	g.Expect(latest.GetConditions()).To(BeComparableTo(after.GetConditions()))
+
+	latest = before.DeepCopy()
+	g.Expect(diff.Apply(latest)).To(Succeed())
+	g.Expect(latest.GetConditions()).To(BeComparableTo(after.GetConditions()))
+}
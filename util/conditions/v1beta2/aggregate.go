@@ -0,0 +1,251 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// AggregateOption is some configuration that modifies options for an aggregate call.
+type AggregateOption interface {
+	// ApplyToAggregate applies this configuration to the given aggregate options.
+	ApplyToAggregate(*AggregateOptions)
+}
+
+// AggregateOptions allows to set options for the aggregate operation.
+type AggregateOptions struct {
+	targetConditionType string
+	customMessageFunc   CustomMessageFunc
+	transitionHistory   *TransitionHistory
+	messageGroupFunc    MessageGroupFunc
+	maxMessageLength    int
+	groupMessagesByKind bool
+	severityHint        clusterv1.ConditionSeverity
+}
+
+// MessageGroupFunc returns the group of a source condition in the aggregate message, e.g. its reason or the
+// failure domain of the source object; when set, instead of listing the message of each source object, the aggregate
+// message has one line for each group listing the source objects in the group, e.g. "* NotReady: m1, m2, m3, ... (5 more)".
+// Groups are sorted by number of source objects, from the largest to the smallest, and then by name.
+// NOTE: The function must not modify the given object and condition.
+type MessageGroupFunc func(sourceObj Getter, condition *metav1.Condition) string
+
+// ApplyToAggregate applies this configuration to the given aggregate options.
+func (f MessageGroupFunc) ApplyToAggregate(opts *AggregateOptions) {
+	opts.messageGroupFunc = f
+}
+
+// GroupByReason is a MessageGroupFunc grouping source conditions by reason.
+var GroupByReason MessageGroupFunc = func(_ Getter, condition *metav1.Condition) string {
+	return condition.Reason
+}
+
+// GroupMessagesByKind allows to group the messages of the aggregate condition by the kind of the source objects, e.g.
+// when aggregating a condition from both Machines and MachinePools, e.g. "* Machine:\n  * m1: foo\n* MachinePool:\n  * mp1: bar";
+// kinds are sorted by name. The kind is read from the TypeMeta of the source objects, or derived from their Go type if not set.
+type GroupMessagesByKind bool
+
+// ApplyToAggregate applies this configuration to the given aggregate options.
+func (g GroupMessagesByKind) ApplyToAggregate(opts *AggregateOptions) {
+	opts.groupMessagesByKind = bool(g)
+}
+
+// maxNamesPerGroup is the maximum number of source objects names listed for each group in an aggregate message.
+const maxNamesPerGroup = 3
+
+// ApplyOptions applies the given list options on these options,
+// and then returns itself (for convenient chaining).
+func (o *AggregateOptions) ApplyOptions(opts []AggregateOption) *AggregateOptions {
+	for _, opt := range opts {
+		opt.ApplyToAggregate(o)
+	}
+	return o
+}
+
+// NewAggregateCondition creates a new condition aggregating the conditions with the given type from the source objects.
+//
+// The aggregate condition is False if any of the source conditions is False, Unknown if any of the source conditions is
+// Unknown or not yet reported, and True otherwise; for conditions with negative polarity, see RegisterNegativePolarityConditionTypes,
+// the aggregate condition is True if any of the source conditions is True, Unknown if any of the source conditions is
+// Unknown or not yet reported, and False otherwise. The reason is the reason of the source conditions determining the
+// aggregate status if they all have the same reason, otherwise MultipleReasonsReported; the message lists the messages
+// of the source conditions determining the aggregate status, prefixed by the name of the source object, or the groups
+// of these conditions if a MessageGroupFunc is set.
+//
+// Source objects can be of different kinds, e.g. Machines and MachinePools; use GroupMessagesByKind to group the
+// message by the kind of the source objects.
+func NewAggregateCondition(sourceObjs []Getter, sourceConditionType string, opts ...AggregateOption) *metav1.Condition {
+	aggregateOpt := (&AggregateOptions{targetConditionType: sourceConditionType}).ApplyOptions(opts)
+
+	type sourceCondition struct {
+		obj       Getter
+		name      string
+		condition *metav1.Condition
+	}
+	sourceConditions := map[metav1.ConditionStatus][]sourceCondition{}
+	for _, sourceObj := range sourceObjs {
+		condition := Get(sourceObj, sourceConditionType)
+		if condition == nil {
+			condition = &metav1.Condition{
+				Type:    sourceConditionType,
+				Status:  metav1.ConditionUnknown,
+				Reason:  clusterv1.NotYetReportedV1Beta2Reason,
+				Message: fmt.Sprintf("Condition %s not yet reported", sourceConditionType),
+			}
+		}
+		var name string
+		if objWithName, ok := sourceObj.(interface{ GetName() string }); ok {
+			name = objWithName.GetName()
+		}
+		sourceConditions[condition.Status] = append(sourceConditions[condition.Status], sourceCondition{obj: sourceObj, name: name, condition: condition})
+	}
+
+	// The aggregate status is the worst status, i.e. the status not reporting the desired state.
+	desired, problem := desiredStatus(sourceConditionType), metav1.ConditionFalse
+	if desired == metav1.ConditionFalse {
+		problem = metav1.ConditionTrue
+	}
+	status := desired
+	switch {
+	case len(sourceConditions[problem]) > 0:
+		status = problem
+	case len(sourceConditions[metav1.ConditionUnknown]) > 0:
+		status = metav1.ConditionUnknown
+	}
+
+	var reason string
+	messagesByKind := map[string][]string{}
+	groupsByKind := map[string]map[string][]string{}
+	for i, s := range sourceConditions[status] {
+		switch {
+		case i == 0:
+			reason = s.condition.Reason
+		case reason != s.condition.Reason:
+			reason = clusterv1.MultipleReasonsReportedV1Beta2Reason
+		}
+
+		var kind string
+		if aggregateOpt.groupMessagesByKind {
+			kind = kindOf(s.obj)
+		}
+
+		if aggregateOpt.messageGroupFunc != nil {
+			group := aggregateOpt.messageGroupFunc(s.obj, s.condition.DeepCopy())
+			if groupsByKind[kind] == nil {
+				groupsByKind[kind] = map[string][]string{}
+			}
+			groupsByKind[kind][group] = append(groupsByKind[kind][group], s.name)
+			continue
+		}
+
+		message := s.condition.Message
+		if aggregateOpt.customMessageFunc != nil {
+			message = aggregateOpt.customMessageFunc(s.condition.DeepCopy())
+		}
+		if message == "" {
+			continue
+		}
+		if s.name != "" {
+			message = fmt.Sprintf("%s: %s", s.name, message)
+		}
+		messagesByKind[kind] = append(messagesByKind[kind], fmt.Sprintf("* %s", message))
+	}
+	for kind, groups := range groupsByKind {
+		messagesByKind[kind] = groupMessages(groups)
+	}
+
+	messages := messagesByKind[""]
+	if aggregateOpt.groupMessagesByKind {
+		messages = []string{}
+		kinds := make([]string, 0, len(messagesByKind))
+		for kind := range messagesByKind {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		for _, kind := range kinds {
+			messages = append(messages, fmt.Sprintf("* %s:", kind))
+			for _, m := range messagesByKind[kind] {
+				messages = append(messages, fmt.Sprintf("  %s", m))
+			}
+		}
+	}
+
+	aggregate := &metav1.Condition{
+		Type:    aggregateOpt.targetConditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: joinMessages(messages, aggregateOpt.maxMessageLength),
+	}
+	aggregate.Message = withSeverityHint(*aggregate, aggregateOpt.severityHint)
+	return aggregate
+}
+
+// SetAggregateCondition is a convenience method that calls NewAggregateCondition to create an aggregate condition from
+// the source objects, and then calls Set to add the new condition to the target object.
+func SetAggregateCondition(sourceObjs []Getter, targetObj Setter, sourceConditionType string, opts ...AggregateOption) {
+	condition := NewAggregateCondition(sourceObjs, sourceConditionType, opts...)
+
+	var setOpts []SetOption
+	if aggregateOpt := (&AggregateOptions{}).ApplyOptions(opts); aggregateOpt.transitionHistory != nil {
+		setOpts = append(setOpts, aggregateOpt.transitionHistory.forConditionType(condition.Type))
+	}
+	Set(targetObj, *condition, setOpts...)
+}
+
+// kindOf returns the kind of the given object, read from its TypeMeta or derived from its Go type if not set.
+func kindOf(obj Getter) string {
+	if objWithKind, ok := obj.(interface{ GetObjectKind() schema.ObjectKind }); ok {
+		if kind := objWithKind.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+			return kind
+		}
+	}
+	return reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+}
+
+// groupMessages returns a message line for each group, listing the names of the source objects in the group.
+func groupMessages(groups map[string][]string) []string {
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(groups[keys[i]]) != len(groups[keys[j]]) {
+			return len(groups[keys[i]]) > len(groups[keys[j]])
+		}
+		return keys[i] < keys[j]
+	})
+
+	messages := make([]string, 0, len(keys))
+	for _, k := range keys {
+		names := groups[k]
+		sort.Strings(names)
+		list := strings.Join(names, ", ")
+		if len(names) > maxNamesPerGroup {
+			list = fmt.Sprintf("%s, ... (%d more)", strings.Join(names[:maxNamesPerGroup], ", "), len(names)-maxNamesPerGroup)
+		}
+		messages = append(messages, fmt.Sprintf("* %s: %s", k, list))
+	}
+	return messages
+}
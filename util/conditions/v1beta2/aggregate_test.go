@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestNewAggregateCondition(t *testing.T) {
+	withCondition := func(name string, status metav1.ConditionStatus, reason, message string) Getter {
+		return &fakeObject{name: name, conditions: []metav1.Condition{{Type: "Ready", Status: status, Reason: reason, Message: message}}}
+	}
+
+	withKind := func(kind, name string, status metav1.ConditionStatus, reason, message string) Getter {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(clusterv1.GroupVersion.WithKind(kind))
+		u.SetName(name)
+		UnstructuredSet(u, metav1.Condition{Type: "Ready", Status: status, Reason: reason, Message: message})
+		return UnstructuredGetter(u)
+	}
+
+	tests := []struct {
+		name       string
+		sourceObjs []Getter
+		opts       []AggregateOption
+		want       *metav1.Condition
+	}{
+		{
+			name: "All conditions are true",
+			sourceObjs: []Getter{
+				withCondition("m1", metav1.ConditionTrue, "Ready", ""),
+				withCondition("m2", metav1.ConditionTrue, "Ready", ""),
+			},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"},
+		},
+		{
+			name: "False conditions win over unknown conditions",
+			sourceObjs: []Getter{
+				withCondition("m1", metav1.ConditionTrue, "Ready", ""),
+				withCondition("m2", metav1.ConditionUnknown, "Foo", "foo"),
+				withCondition("m3", metav1.ConditionFalse, "NotReady", "bar"),
+			},
+			opts: []AggregateOption{TargetConditionType("MachinesReady")},
+			want: &metav1.Condition{Type: "MachinesReady", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "* m3: bar"},
+		},
+		{
+			name: "Conditions not yet reported are unknown",
+			sourceObjs: []Getter{
+				withCondition("m1", metav1.ConditionTrue, "Ready", ""),
+				&fakeObject{name: "m2"},
+			},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionUnknown, Reason: clusterv1.NotYetReportedV1Beta2Reason, Message: "* m2: Condition Ready not yet reported"},
+		},
+		{
+			name: "Different reasons are reported as multiple reasons",
+			sourceObjs: []Getter{
+				withCondition("m1", metav1.ConditionFalse, "Foo", "foo"),
+				withCondition("m2", metav1.ConditionFalse, "Bar", "bar"),
+			},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: clusterv1.MultipleReasonsReportedV1Beta2Reason, Message: "* m1: foo\n* m2: bar"},
+		},
+		{
+			name: "Messages are truncated to the max message length",
+			sourceObjs: []Getter{
+				withCondition("m1", metav1.ConditionFalse, "Foo", "foo"),
+				withCondition("m2", metav1.ConditionFalse, "Foo", "foo"),
+				withCondition("m3", metav1.ConditionFalse, "Foo", "foo"),
+			},
+			opts: []AggregateOption{MaxMessageLength(25)},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "Foo", Message: "* m1: foo\n* ... (2 more)"},
+		},
+		{
+			name: "Messages are computed with the custom message func",
+			sourceObjs: []Getter{
+				withCondition("m1", metav1.ConditionFalse, "NotReady", "failed to call https://internal.example.com: timeout"),
+				withCondition("m2", metav1.ConditionFalse, "NotReady", "instance is not running"),
+			},
+			opts: []AggregateOption{CustomMessageFunc(func(c *metav1.Condition) string {
+				if strings.Contains(c.Message, "internal.example.com") {
+					return "provider API not reachable"
+				}
+				return c.Message
+			})},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "* m1: provider API not reachable\n* m2: instance is not running"},
+		},
+		{
+			name: "Messages are grouped by reason",
+			sourceObjs: []Getter{
+				withCondition("m1", metav1.ConditionFalse, "InfrastructureNotReady", "foo"),
+				withCondition("m5", metav1.ConditionFalse, "InfrastructureNotReady", "bar"),
+				withCondition("m3", metav1.ConditionFalse, "InfrastructureNotReady", "foo"),
+				withCondition("m2", metav1.ConditionFalse, "InfrastructureNotReady", "foo"),
+				withCondition("m4", metav1.ConditionFalse, "BootstrapNotReady", "foo"),
+				withCondition("m6", metav1.ConditionTrue, "Ready", ""),
+			},
+			opts: []AggregateOption{GroupByReason},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: clusterv1.MultipleReasonsReportedV1Beta2Reason, Message: "* InfrastructureNotReady: m1, m2, m3, ... (1 more)\n* BootstrapNotReady: m4"},
+		},
+		{
+			name: "Messages are grouped with a custom func",
+			sourceObjs: []Getter{
+				withCondition("m1", metav1.ConditionFalse, "NotReady", "foo"),
+				withCondition("m2", metav1.ConditionFalse, "NotReady", "foo"),
+				withCondition("m3", metav1.ConditionFalse, "NotReady", "foo"),
+			},
+			opts: []AggregateOption{MessageGroupFunc(func(sourceObj Getter, _ *metav1.Condition) string {
+				if sourceObj.(*fakeObject).name == "m2" {
+					return "Failure domain b"
+				}
+				return "Failure domain a"
+			})},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "* Failure domain a: m1, m3\n* Failure domain b: m2"},
+		},
+		{
+			name: "Messages are grouped by kind",
+			sourceObjs: []Getter{
+				withKind("MachinePool", "mp1", metav1.ConditionFalse, "NotReady", "foo"),
+				withKind("Machine", "m1", metav1.ConditionFalse, "NotReady", "bar"),
+				withCondition("f1", metav1.ConditionFalse, "NotReady", "baz"),
+				withKind("Machine", "m2", metav1.ConditionFalse, "NotReady", "baz"),
+				withKind("Machine", "m3", metav1.ConditionTrue, "Ready", ""),
+			},
+			opts: []AggregateOption{GroupMessagesByKind(true)},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "* Machine:\n  * m1: bar\n  * m2: baz\n* MachinePool:\n  * mp1: foo\n* fakeObject:\n  * f1: baz"},
+		},
+		{
+			name: "Messages are grouped by kind and with a message group func",
+			sourceObjs: []Getter{
+				withKind("MachinePool", "mp1", metav1.ConditionFalse, "NotReady", "foo"),
+				withKind("Machine", "m1", metav1.ConditionFalse, "NotReady", "bar"),
+				withKind("Machine", "m2", metav1.ConditionFalse, "NotReady", "baz"),
+			},
+			opts: []AggregateOption{GroupMessagesByKind(true), GroupByReason},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "* Machine:\n  * NotReady: m1, m2\n* MachinePool:\n  * NotReady: mp1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(NewAggregateCondition(tt.sourceObjs, "Ready", tt.opts...)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestSetAggregateCondition(t *testing.T) {
+	g := NewWithT(t)
+
+	target := &fakeObject{generation: 2}
+	SetAggregateCondition([]Getter{&fakeObject{name: "m1"}}, target, "Ready", TargetConditionType("MachinesReady"))
+
+	condition := Get(target, "MachinesReady")
+	g.Expect(condition).ToNot(BeNil())
+	g.Expect(condition.Status).To(Equal(metav1.ConditionUnknown))
+	g.Expect(condition.ObservedGeneration).To(Equal(int64(2)))
+}
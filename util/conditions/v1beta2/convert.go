@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// ConvertFromV1Beta1Conditions converts conditions in the clusterv1.Conditions format to the metav1.Condition format,
+// preserving the LastTransitionTime; the ObservedGeneration is not set, given that it does not exist in the v1beta1 format.
+//
+// Given that the reason is required in the metav1.Condition format, an empty reason is converted to the NoReasonReported
+// reason; given that the severity does not exist in the metav1.Condition format, the severity of False conditions is
+// surfaced as a prefix of the message, e.g. "[Warning] message", so it can be restored by ConvertToV1Beta1Conditions.
+func ConvertFromV1Beta1Conditions(conditions clusterv1.Conditions) []metav1.Condition {
+	if conditions == nil {
+		return nil
+	}
+
+	converted := make([]metav1.Condition, 0, len(conditions))
+	for _, c := range conditions {
+		reason := c.Reason
+		if reason == "" {
+			reason = clusterv1.NoReasonReportedV1Beta2Reason
+		}
+		message := c.Message
+		if c.Status == corev1.ConditionFalse && c.Severity != clusterv1.ConditionSeverityNone {
+			message = strings.TrimSpace(fmt.Sprintf("[%s] %s", c.Severity, c.Message))
+		}
+		converted = append(converted, metav1.Condition{
+			Type:               string(c.Type),
+			Status:             metav1.ConditionStatus(c.Status),
+			Reason:             reason,
+			Message:            message,
+			LastTransitionTime: c.LastTransitionTime,
+		})
+	}
+	return converted
+}
+
+// ConvertToV1Beta1Conditions converts conditions in the metav1.Condition format to the clusterv1.Conditions format,
+// preserving the LastTransitionTime; it is the inverse of ConvertFromV1Beta1Conditions.
+//
+// The severity of False conditions is read from the prefix of the message, if any, and it defaults to Error otherwise;
+// the NoReasonReported reason is converted to an empty reason.
+func ConvertToV1Beta1Conditions(conditions []metav1.Condition) clusterv1.Conditions {
+	if conditions == nil {
+		return nil
+	}
+
+	converted := make(clusterv1.Conditions, 0, len(conditions))
+	for _, c := range conditions {
+		reason := c.Reason
+		if reason == clusterv1.NoReasonReportedV1Beta2Reason {
+			reason = ""
+		}
+		message, severity := c.Message, clusterv1.ConditionSeverityNone
+		if c.Status == metav1.ConditionFalse {
+			message, severity = trimSeverity(c.Message)
+		}
+		converted = append(converted, clusterv1.Condition{
+			Type:               clusterv1.ConditionType(c.Type),
+			Status:             corev1.ConditionStatus(c.Status),
+			Severity:           severity,
+			Reason:             reason,
+			Message:            message,
+			LastTransitionTime: c.LastTransitionTime,
+		})
+	}
+	return converted
+}
+
+// trimSeverity returns the message without the severity prefix, and the severity from the prefix, or Error if
+// the message has no severity prefix.
+func trimSeverity(message string) (string, clusterv1.ConditionSeverity) {
+	for _, severity := range []clusterv1.ConditionSeverity{clusterv1.ConditionSeverityError, clusterv1.ConditionSeverityWarning, clusterv1.ConditionSeverityInfo} {
+		prefix := fmt.Sprintf("[%s]", severity)
+		if message == prefix {
+			return "", severity
+		}
+		if trimmed, ok := strings.CutPrefix(message, prefix+" "); ok {
+			return trimmed, severity
+		}
+	}
+	return message, clusterv1.ConditionSeverityError
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestConvertConditions(t *testing.T) {
+	g := NewWithT(t)
+
+	now := metav1.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	v1beta1Conditions := clusterv1.Conditions{
+		{Type: clusterv1.ReadyCondition, Status: corev1.ConditionTrue, LastTransitionTime: now},
+		{Type: clusterv1.InfrastructureReadyCondition, Status: corev1.ConditionFalse, Severity: clusterv1.ConditionSeverityWarning, Reason: "Provisioning", Message: "foo", LastTransitionTime: now},
+		{Type: clusterv1.ControlPlaneReadyCondition, Status: corev1.ConditionFalse, Severity: clusterv1.ConditionSeverityInfo, Reason: "WaitingForInfrastructure", LastTransitionTime: now},
+		{Type: clusterv1.ControlPlaneInitializedCondition, Status: corev1.ConditionUnknown, Reason: "Foo", Message: "bar", LastTransitionTime: now},
+	}
+	v1beta2Conditions := []metav1.Condition{
+		{Type: "Ready", Status: metav1.ConditionTrue, Reason: clusterv1.NoReasonReportedV1Beta2Reason, LastTransitionTime: now},
+		{Type: "InfrastructureReady", Status: metav1.ConditionFalse, Reason: "Provisioning", Message: "[Warning] foo", LastTransitionTime: now},
+		{Type: "ControlPlaneReady", Status: metav1.ConditionFalse, Reason: "WaitingForInfrastructure", Message: "[Info]", LastTransitionTime: now},
+		{Type: "ControlPlaneInitialized", Status: metav1.ConditionUnknown, Reason: "Foo", Message: "bar", LastTransitionTime: now},
+	}
+
+	g.Expect(ConvertFromV1Beta1Conditions(v1beta1Conditions)).To(Equal(v1beta2Conditions))
+	g.Expect(ConvertToV1Beta1Conditions(v1beta2Conditions)).To(Equal(v1beta1Conditions))
+
+	g.Expect(ConvertFromV1Beta1Conditions(nil)).To(BeNil())
+	g.Expect(ConvertToV1Beta1Conditions(nil)).To(BeNil())
+
+	// False conditions without a severity prefix default to the Error severity.
+	g.Expect(ConvertToV1Beta1Conditions([]metav1.Condition{{Type: "Ready", Status: metav1.ConditionFalse, Reason: "Foo", Message: "foo"}})).To(Equal(clusterv1.Conditions{
+		{Type: clusterv1.ReadyCondition, Status: corev1.ConditionFalse, Severity: clusterv1.ConditionSeverityError, Reason: "Foo", Message: "foo"},
+	}))
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DerivedCondition defines a condition derived from other conditions of the same object.
+type DerivedCondition struct {
+	// Type is the type of the derived condition.
+	Type string
+
+	// DependsOn are the types of the conditions the derived condition depends on; they can be derived conditions too.
+	DependsOn []string
+
+	// Options are the options used for summarizing the conditions the derived condition depends on,
+	// e.g. ConditionWeights or SummaryThreshold; ForConditionTypes is always set to DependsOn.
+	Options []SummaryOption
+}
+
+// ConditionGraph is a directed acyclic graph of conditions derived from other conditions of the same object,
+// e.g. Ready depending on InfrastructureReady, BootstrapReady and NodeHealthy, where NodeHealthy depends on
+// other conditions in turn.
+type ConditionGraph struct {
+	// derivedConditions are sorted so that a derived condition comes after the derived conditions it depends on.
+	derivedConditions []DerivedCondition
+}
+
+// NewConditionGraph returns a ConditionGraph for the given derived conditions; derived conditions must have unique types,
+// and must not depend on themself, neither directly nor indirectly.
+func NewConditionGraph(derivedConditions ...DerivedCondition) (*ConditionGraph, error) {
+	byType := make(map[string]DerivedCondition, len(derivedConditions))
+	for _, d := range derivedConditions {
+		if _, ok := byType[d.Type]; ok {
+			return nil, errors.Errorf("failed to create condition graph: derived condition %s is defined more than once", d.Type)
+		}
+		byType[d.Type] = d
+	}
+
+	// Sort the derived conditions with a depth-first visit, preserving the order of definition where possible.
+	graph := &ConditionGraph{}
+	visited := map[string]bool{}
+	var visit func(d DerivedCondition, path []string) error
+	visit = func(d DerivedCondition, path []string) error {
+		for _, p := range path {
+			if p == d.Type {
+				return errors.Errorf("failed to create condition graph: dependency cycle %s", strings.Join(append(path, d.Type), " -> "))
+			}
+		}
+		if visited[d.Type] {
+			return nil
+		}
+		for _, t := range d.DependsOn {
+			if dependency, ok := byType[t]; ok {
+				if err := visit(dependency, append(path, d.Type)); err != nil {
+					return err
+				}
+			}
+		}
+		visited[d.Type] = true
+		graph.derivedConditions = append(graph.derivedConditions, d)
+		return nil
+	}
+	for _, d := range derivedConditions {
+		if err := visit(d, nil); err != nil {
+			return nil, err
+		}
+	}
+	return graph, nil
+}
+
+// Evaluate computes the derived conditions from the conditions of the source object, with the same semantic of
+// NewSummaryCondition; derived conditions are computed after the derived conditions they depend on, so changes are
+// consistently propagated in a single pass. The derived conditions are returned in dependency order.
+func (g *ConditionGraph) Evaluate(sourceObj Getter) []metav1.Condition {
+	var conditions conditionsList
+	if sourceObj != nil {
+		conditions = append(conditions, sourceObj.GetV1Beta2Conditions()...)
+	}
+
+	derived := make([]metav1.Condition, 0, len(g.derivedConditions))
+	for _, d := range g.derivedConditions {
+		opts := append(append([]SummaryOption{}, d.Options...), ForConditionTypes(d.DependsOn))
+		condition := NewSummaryCondition(conditions, d.Type, opts...)
+		derived = append(derived, *condition)
+
+		// Make the derived condition available to the derived conditions depending on it.
+		conditions = append(conditions.without(d.Type), *condition)
+	}
+	return derived
+}
+
+// Set is a convenience method that calls Evaluate to compute the derived conditions, and then sets all of them on
+// the target object in a single pass, so they get the same LastTransitionTime, if changed, and the same ObservedGeneration.
+func (g *ConditionGraph) Set(targetObj Setter, opts ...SetOption) {
+	setAll(targetObj, g.Evaluate(targetObj), (&SetOptions{}).ApplyOptions(opts))
+}
+
+// conditionsList is a list of conditions implementing Getter.
+type conditionsList []metav1.Condition
+
+func (l conditionsList) GetV1Beta2Conditions() []metav1.Condition {
+	return l
+}
+
+func (l conditionsList) without(conditionType string) conditionsList {
+	filtered := make(conditionsList, 0, len(l))
+	for _, c := range l {
+		if c.Type != conditionType {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewConditionGraph(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := NewConditionGraph(
+		DerivedCondition{Type: "Ready", DependsOn: []string{"NodeHealthy"}},
+		DerivedCondition{Type: "Ready", DependsOn: []string{"InfrastructureReady"}},
+	)
+	g.Expect(err).To(MatchError(ContainSubstring("defined more than once")))
+
+	_, err = NewConditionGraph(
+		DerivedCondition{Type: "Ready", DependsOn: []string{"NodeHealthy"}},
+		DerivedCondition{Type: "NodeHealthy", DependsOn: []string{"NodeReady", "Ready"}},
+	)
+	g.Expect(err).To(MatchError(ContainSubstring("dependency cycle Ready -> NodeHealthy -> Ready")))
+
+	graph, err := NewConditionGraph(
+		DerivedCondition{Type: "Ready", DependsOn: []string{"InfrastructureReady", "NodeHealthy"}},
+		DerivedCondition{Type: "NodeHealthy", DependsOn: []string{"NodeReady"}},
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(graph.derivedConditions[0].Type).To(Equal("NodeHealthy"))
+	g.Expect(graph.derivedConditions[1].Type).To(Equal("Ready"))
+}
+
+func TestConditionGraph(t *testing.T) {
+	g := NewWithT(t)
+
+	graph, err := NewConditionGraph(
+		DerivedCondition{Type: "Ready", DependsOn: []string{"InfrastructureReady", "BootstrapReady", "NodeHealthy"}},
+		DerivedCondition{Type: "NodeHealthy", DependsOn: []string{"NodeReady", "NodeDiskPressure"}},
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	obj := &fakeObject{
+		generation: 2,
+		conditions: []metav1.Condition{
+			{Type: "InfrastructureReady", Status: metav1.ConditionTrue, Reason: "Ready"},
+			{Type: "BootstrapReady", Status: metav1.ConditionTrue, Reason: "Ready"},
+			{Type: "NodeReady", Status: metav1.ConditionFalse, Reason: "KubeletNotReady", Message: "foo"},
+			{Type: "NodeDiskPressure", Status: metav1.ConditionFalse, Reason: "NoDiskPressure"},
+			// A stale value of a derived condition is ignored when computing the conditions depending on it.
+			{Type: "NodeHealthy", Status: metav1.ConditionTrue, Reason: "Ready"},
+		},
+	}
+	RegisterNegativePolarityConditionTypes("NodeDiskPressure")
+
+	g.Expect(graph.Evaluate(obj)).To(Equal([]metav1.Condition{
+		{Type: "NodeHealthy", Status: metav1.ConditionFalse, Reason: "KubeletNotReady", Message: "* NodeReady: foo"},
+		{Type: "Ready", Status: metav1.ConditionFalse, Reason: "KubeletNotReady", Message: "* NodeHealthy: * NodeReady: foo"},
+	}))
+
+	graph.Set(obj)
+	g.Expect(obj.setCalls).To(Equal(1))
+	g.Expect(Get(obj, "NodeHealthy").Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(Get(obj, "Ready").Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(Get(obj, "Ready").ObservedGeneration).To(Equal(int64(2)))
+}
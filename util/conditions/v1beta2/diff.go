@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionsDiff is the difference between two lists of conditions, as computed by Diff.
+type ConditionsDiff struct {
+	// Added are the conditions existing only in the after list, in the order of the after list.
+	Added []metav1.Condition
+
+	// Removed are the conditions existing only in the before list, in the order of the before list.
+	Removed []metav1.Condition
+
+	// Changed are the conditions existing in both lists with at least one different field, in the order of the after list.
+	Changed []ConditionChange
+}
+
+// ConditionChange is the change of a condition existing in both the lists compared by Diff.
+type ConditionChange struct {
+	// Type is the type of the condition.
+	Type string
+
+	// Fields are the changed fields of the condition, in the order Status, Reason, Message, ObservedGeneration
+	// and LastTransitionTime.
+	Fields []FieldChange
+}
+
+// FieldChange is the change of a field of a condition.
+type FieldChange struct {
+	// Field is the name of the field, e.g. Status.
+	Field string
+
+	// Before is the value of the field in the before list.
+	Before string
+
+	// After is the value of the field in the after list.
+	After string
+}
+
+// Diff returns the difference between the before and after lists of conditions; conditions are matched by type.
+func Diff(before, after []metav1.Condition) *ConditionsDiff {
+	diff := &ConditionsDiff{}
+	for _, a := range after {
+		b := getByType(before, a.Type)
+		if b == nil {
+			diff.Added = append(diff.Added, a)
+			continue
+		}
+		if fields := diffFields(*b, a); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, ConditionChange{Type: a.Type, Fields: fields})
+		}
+	}
+	for _, b := range before {
+		if getByType(after, b.Type) == nil {
+			diff.Removed = append(diff.Removed, b)
+		}
+	}
+	return diff
+}
+
+// IsEmpty returns true if the diff has no added, removed or changed conditions.
+func (d *ConditionsDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String returns a human-readable representation of the diff, with one line for each added, changed or removed condition,
+// e.g. "Ready: Status False -> True, Reason NotReady -> Ready".
+func (d *ConditionsDiff) String() string {
+	lines := make([]string, 0, len(d.Added)+len(d.Changed)+len(d.Removed))
+	for _, c := range d.Added {
+		lines = append(lines, fmt.Sprintf("%s: added with Status %s, Reason %s", c.Type, c.Status, c.Reason))
+	}
+	for _, c := range d.Changed {
+		fields := make([]string, 0, len(c.Fields))
+		for _, f := range c.Fields {
+			fields = append(fields, fmt.Sprintf("%s %s -> %s", f.Field, f.Before, f.After))
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", c.Type, strings.Join(fields, ", ")))
+	}
+	for _, c := range d.Removed {
+		lines = append(lines, fmt.Sprintf("%s: removed", c.Type))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func getByType(conditions []metav1.Condition, conditionType string) *metav1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+func diffFields(before, after metav1.Condition) []FieldChange {
+	var fields []FieldChange
+	add := func(field, b, a string) {
+		if b != a {
+			fields = append(fields, FieldChange{Field: field, Before: b, After: a})
+		}
+	}
+	add("Status", string(before.Status), string(after.Status))
+	add("Reason", before.Reason, after.Reason)
+	add("Message", strconv.Quote(before.Message), strconv.Quote(after.Message))
+	add("ObservedGeneration", strconv.FormatInt(before.ObservedGeneration, 10), strconv.FormatInt(after.ObservedGeneration, 10))
+	add("LastTransitionTime", before.LastTransitionTime.UTC().Format(time.RFC3339), after.LastTransitionTime.UTC().Format(time.RFC3339))
+	return fields
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDiff(t *testing.T) {
+	g := NewWithT(t)
+
+	t0 := metav1.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := metav1.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)
+	before := []metav1.Condition{
+		{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "foo", ObservedGeneration: 1, LastTransitionTime: t0},
+		{Type: "Paused", Status: metav1.ConditionFalse, Reason: "NotPaused", LastTransitionTime: t0},
+		{Type: "Available", Status: metav1.ConditionTrue, Reason: "Available", LastTransitionTime: t0},
+	}
+	after := []metav1.Condition{
+		{Type: "Available", Status: metav1.ConditionTrue, Reason: "Available", LastTransitionTime: t0},
+		{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", ObservedGeneration: 2, LastTransitionTime: t1},
+		{Type: "Deleting", Status: metav1.ConditionFalse, Reason: "NotDeleting", LastTransitionTime: t1},
+	}
+
+	diff := Diff(before, after)
+	g.Expect(diff.IsEmpty()).To(BeFalse())
+	g.Expect(diff.Added).To(Equal([]metav1.Condition{after[2]}))
+	g.Expect(diff.Removed).To(Equal([]metav1.Condition{before[1]}))
+	g.Expect(diff.Changed).To(Equal([]ConditionChange{
+		{
+			Type: "Ready",
+			Fields: []FieldChange{
+				{Field: "Status", Before: "False", After: "True"},
+				{Field: "Reason", Before: "NotReady", After: "Ready"},
+				{Field: "Message", Before: `"foo"`, After: `""`},
+				{Field: "ObservedGeneration", Before: "1", After: "2"},
+				{Field: "LastTransitionTime", Before: "2024-01-01T00:00:00Z", After: "2024-01-01T01:00:00Z"},
+			},
+		},
+	}))
+	g.Expect(diff.String()).To(Equal("Deleting: added with Status False, Reason NotDeleting\n" +
+		"Ready: Status False -> True, Reason NotReady -> Ready, Message \"foo\" -> \"\", ObservedGeneration 1 -> 2, LastTransitionTime 2024-01-01T00:00:00Z -> 2024-01-01T01:00:00Z\n" +
+		"Paused: removed"))
+
+	g.Expect(Diff(before, before).IsEmpty()).To(BeTrue())
+	g.Expect(Diff(before, before).String()).To(BeEmpty())
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Getter interface defines methods that an object should implement in order to
+// use the v1beta2 conditions package for getting conditions.
+type Getter interface {
+	GetV1Beta2Conditions() []metav1.Condition
+}
+
+// Get returns the condition with the given type, if the condition does not exist,
+// it returns nil.
+func Get(from Getter, conditionType string) *metav1.Condition {
+	if from == nil {
+		return nil
+	}
+	return meta.FindStatusCondition(from.GetV1Beta2Conditions(), conditionType)
+}
+
+// Has returns true if a condition with the given type exists.
+func Has(from Getter, conditionType string) bool {
+	return Get(from, conditionType) != nil
+}
+
+// IsStale returns true if the condition with the given type exists but it has not been reconciled recently.
+// Freshness is based on the ObservedGeneration: the condition is stale if its ObservedGeneration is older than
+// the generation of the object, and it is fresh otherwise, no matter how long ago it changed.
+// Only when the ObservedGeneration can't be checked, i.e. the object or the condition do not have it, the condition
+// is stale if maxAge is greater than zero and its LastTransitionTime is older than maxAge; please note that
+// maxAge measures the time since the last transition of the condition, not since it was last reconciled, so it
+// should be used only for conditions expected to transition at least once every maxAge.
+func IsStale(from Getter, conditionType string, maxAge time.Duration) bool {
+	condition := Get(from, conditionType)
+	if condition == nil {
+		return false
+	}
+	if objWithGeneration, ok := from.(interface{ GetGeneration() int64 }); ok && objWithGeneration.GetGeneration() > 0 && condition.ObservedGeneration > 0 {
+		return condition.ObservedGeneration < objWithGeneration.GetGeneration()
+	}
+	return maxAge > 0 && time.Since(condition.LastTransitionTime.Time) > maxAge
+}
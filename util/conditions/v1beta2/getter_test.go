@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsStale(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition *metav1.Condition
+		maxAge    time.Duration
+		want      bool
+	}{
+		{
+			name: "Condition does not exist",
+			want: false,
+		},
+		{
+			name:      "Condition is up to date",
+			condition: &metav1.Condition{Type: "Ready", ObservedGeneration: 2, LastTransitionTime: metav1.Now()},
+			maxAge:    time.Hour,
+			want:      false,
+		},
+		{
+			name:      "Condition has an old ObservedGeneration",
+			condition: &metav1.Condition{Type: "Ready", ObservedGeneration: 1, LastTransitionTime: metav1.Now()},
+			want:      true,
+		},
+		{
+			name:      "Condition without ObservedGeneration",
+			condition: &metav1.Condition{Type: "Ready", LastTransitionTime: metav1.Now()},
+			want:      false,
+		},
+		{
+			name:      "Condition has an old LastTransitionTime, but an up to date ObservedGeneration",
+			condition: &metav1.Condition{Type: "Ready", ObservedGeneration: 2, LastTransitionTime: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			maxAge:    time.Hour,
+			want:      false,
+		},
+		{
+			name:      "Condition without ObservedGeneration has an old LastTransitionTime",
+			condition: &metav1.Condition{Type: "Ready", LastTransitionTime: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			maxAge:    time.Hour,
+			want:      true,
+		},
+		{
+			name:      "Condition without ObservedGeneration has an old LastTransitionTime, but max age is not set",
+			condition: &metav1.Condition{Type: "Ready", LastTransitionTime: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &fakeObject{generation: 2}
+			if tt.condition != nil {
+				obj.conditions = []metav1.Condition{*tt.condition}
+			}
+			g.Expect(IsStale(obj, "Ready", tt.maxAge)).To(Equal(tt.want))
+		})
+	}
+}
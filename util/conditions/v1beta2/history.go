@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"encoding/json"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// DefaultMaxTransitions is the default number of transitions recorded for each condition type.
+const DefaultMaxTransitions = 10
+
+// Transition is a transition of a condition recorded in the ConditionsTransitionHistoryAnnotation.
+type Transition struct {
+	// Type is the type of the condition.
+	Type string `json:"type"`
+
+	// Status is the status of the condition after the transition.
+	Status metav1.ConditionStatus `json:"status"`
+
+	// Reason is the reason of the condition after the transition.
+	Reason string `json:"reason"`
+
+	// Time is when the transition has been recorded.
+	Time metav1.Time `json:"time"`
+}
+
+// TransitionHistory is an opt-in option for recording the last transitions of conditions, i.e. the changes of their
+// Status or Reason, in the ConditionsTransitionHistoryAnnotation of the target object; this makes flapping conditions
+// debuggable by looking at the object only.
+// NOTE: The target object must implement metav1.Object, otherwise no transition is recorded.
+type TransitionHistory struct {
+	// ConditionTypes are the types of the conditions for which transitions should be recorded;
+	// if empty, transitions are recorded for all the conditions set.
+	ConditionTypes []string
+
+	// MaxTransitions is the number of transitions recorded for each condition type, the oldest transitions
+	// are dropped first; defaults to DefaultMaxTransitions.
+	MaxTransitions int
+}
+
+// ApplyToSet applies this configuration to the given set options.
+func (h TransitionHistory) ApplyToSet(opts *SetOptions) {
+	opts.transitionHistory = append(opts.transitionHistory, h)
+}
+
+// ApplyToMirror applies this configuration to the given mirror options.
+func (h TransitionHistory) ApplyToMirror(opts *MirrorOptions) {
+	opts.transitionHistory = &h
+}
+
+// ApplyToAggregate applies this configuration to the given aggregate options.
+func (h TransitionHistory) ApplyToAggregate(opts *AggregateOptions) {
+	opts.transitionHistory = &h
+}
+
+// forConditionType returns the TransitionHistory to use when setting a condition with the given type,
+// defaulting the condition types to it if not set.
+func (h TransitionHistory) forConditionType(conditionType string) TransitionHistory {
+	if len(h.ConditionTypes) == 0 {
+		h.ConditionTypes = []string{conditionType}
+	}
+	return h
+}
+
+// maxTransitions returns the number of transitions to be recorded for the given condition type,
+// or 0 if the transitions of the condition should not be recorded.
+func (o *SetOptions) maxTransitions(conditionType string) int {
+	maxTransitions := 0
+	for _, h := range o.transitionHistory {
+		if len(h.ConditionTypes) > 0 && !slices.Contains(h.ConditionTypes, conditionType) {
+			continue
+		}
+		m := h.MaxTransitions
+		if m <= 0 {
+			m = DefaultMaxTransitions
+		}
+		maxTransitions = max(maxTransitions, m)
+	}
+	return maxTransitions
+}
+
+// GetTransitionHistory returns the recorded transitions for the condition with the given type, from the oldest to the most recent.
+func GetTransitionHistory(obj metav1.Object, conditionType string) ([]Transition, error) {
+	transitions, err := getTransitions(obj)
+	if err != nil {
+		return nil, err
+	}
+	history := []Transition{}
+	for _, t := range transitions {
+		if t.Type == conditionType {
+			history = append(history, t)
+		}
+	}
+	return history, nil
+}
+
+func getTransitions(obj metav1.Object) ([]Transition, error) {
+	value, ok := obj.GetAnnotations()[clusterv1.ConditionsTransitionHistoryAnnotation]
+	if !ok || value == "" {
+		return nil, nil
+	}
+	transitions := []Transition{}
+	if err := json.Unmarshal([]byte(value), &transitions); err != nil {
+		return nil, err
+	}
+	return transitions, nil
+}
+
+// recordTransitions appends the given transitions to the ConditionsTransitionHistoryAnnotation of the target object,
+// dropping the oldest transitions exceeding the max number of transitions for each condition type.
+func recordTransitions(to Setter, newTransitions []Transition, options *SetOptions) {
+	obj, ok := to.(metav1.Object)
+	if !ok {
+		return
+	}
+
+	// NOTE: An annotation that cannot be read is dropped, given that it is only used for troubleshooting.
+	transitions, err := getTransitions(obj)
+	if err != nil {
+		transitions = nil
+	}
+	transitions = append(transitions, newTransitions...)
+
+	// Keep the most recent transitions for each condition type, preserving their order.
+	count := map[string]int{}
+	kept := make([]Transition, 0, len(transitions))
+	for i := len(transitions) - 1; i >= 0; i-- {
+		t := transitions[i]
+		maxTransitions := options.maxTransitions(t.Type)
+		if maxTransitions == 0 {
+			// Transitions recorded by other calls are kept as is.
+			maxTransitions = len(transitions)
+		}
+		if count[t.Type] >= maxTransitions {
+			continue
+		}
+		count[t.Type]++
+		kept = append(kept, t)
+	}
+	slices.Reverse(kept)
+
+	value, err := json.Marshal(kept)
+	if err != nil {
+		return
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[clusterv1.ConditionsTransitionHistoryAnnotation] = string(value)
+	obj.SetAnnotations(annotations)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestTransitionHistory(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &fakeObjectWithMeta{}
+	history := TransitionHistory{ConditionTypes: []string{"Ready"}, MaxTransitions: 2}
+
+	// The first time a condition is set is a transition.
+	Set(obj, metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady"}, history)
+	// Setting the same status and reason is not a transition.
+	Set(obj, metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "foo"}, history)
+	// Changing the reason is a transition.
+	Set(obj, metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "Provisioning"}, history)
+	// Conditions not selected are not recorded.
+	Set(obj, metav1.Condition{Type: "Available", Status: metav1.ConditionFalse, Reason: "NotAvailable"}, history)
+
+	got, err := GetTransitionHistory(obj, "Ready")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(HaveLen(2))
+	g.Expect(got[0]).To(HaveField("Reason", "NotReady"))
+	g.Expect(got[1]).To(HaveField("Reason", "Provisioning"))
+
+	got, err = GetTransitionHistory(obj, "Available")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(BeEmpty())
+
+	// Oldest transitions are dropped first.
+	Set(obj, metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"}, history)
+	got, err = GetTransitionHistory(obj, "Ready")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(HaveLen(2))
+	g.Expect(got[0]).To(HaveField("Reason", "Provisioning"))
+	g.Expect(got[1]).To(HaveField("Status", metav1.ConditionTrue))
+}
+
+func TestTransitionHistory_MirrorAndAggregate(t *testing.T) {
+	g := NewWithT(t)
+
+	source := &fakeObject{name: "source", conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"}}}
+	obj := &fakeObjectWithMeta{}
+
+	// Without ConditionTypes, the transitions of the mirrored or aggregated condition are recorded.
+	SetMirrorConditions(source, obj,
+		MirroredCondition{SourceConditionType: "Ready", Options: []MirrorOption{TargetConditionType("InfrastructureReady"), TransitionHistory{}}},
+		MirroredCondition{SourceConditionType: "Ready", Options: []MirrorOption{TargetConditionType("BootstrapReady")}},
+	)
+	SetAggregateCondition([]Getter{source}, obj, "Ready", TargetConditionType("MachinesReady"), TransitionHistory{})
+
+	for conditionType, wantTransitions := range map[string]int{"InfrastructureReady": 1, "BootstrapReady": 0, "MachinesReady": 1} {
+		got, err := GetTransitionHistory(obj, conditionType)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(got).To(HaveLen(wantTransitions), "transitions of %s", conditionType)
+	}
+}
+
+func TestGetTransitionHistory(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &fakeObjectWithMeta{}
+	got, err := GetTransitionHistory(obj, "Ready")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(BeEmpty())
+
+	obj.SetAnnotations(map[string]string{clusterv1.ConditionsTransitionHistoryAnnotation: "not-json"})
+	_, err = GetTransitionHistory(obj, "Ready")
+	g.Expect(err).To(HaveOccurred())
+
+	// An annotation that cannot be read is replaced.
+	Set(obj, metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"}, TransitionHistory{})
+	got, err = GetTransitionHistory(obj, "Ready")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(HaveLen(1))
+}
+
+type fakeObjectWithMeta struct {
+	metav1.ObjectMeta
+	conditions []metav1.Condition
+}
+
+func (o *fakeObjectWithMeta) GetV1Beta2Conditions() []metav1.Condition {
+	return o.conditions
+}
+
+func (o *fakeObjectWithMeta) SetV1Beta2Conditions(conditions []metav1.Condition) {
+	o.conditions = conditions
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MatchOption is some configuration that modifies options for a match call.
+type MatchOption interface {
+	// ApplyToMatch applies this configuration to the given match options.
+	ApplyToMatch(*MatchOptions)
+}
+
+// MatchOptions allows to set options for the match operation.
+type MatchOptions struct {
+	ignoreLastTransitionTime    bool
+	lastTransitionTimeTolerance time.Duration
+	ignoreObservedGeneration    bool
+	allowExtraConditions        bool
+}
+
+// ApplyOptions applies the given list options on these options,
+// and then returns itself (for convenient chaining).
+func (o *MatchOptions) ApplyOptions(opts []MatchOption) *MatchOptions {
+	for _, opt := range opts {
+		opt.ApplyToMatch(o)
+	}
+	return o
+}
+
+// IgnoreLastTransitionTime allows to ignore the LastTransitionTime when matching conditions.
+type IgnoreLastTransitionTime bool
+
+// ApplyToMatch applies this configuration to the given match options.
+func (i IgnoreLastTransitionTime) ApplyToMatch(opts *MatchOptions) {
+	opts.ignoreLastTransitionTime = bool(i)
+}
+
+// LastTransitionTimeTolerance allows to match conditions with a LastTransitionTime differing at most by the given
+// duration from the expected one, e.g. when the expected condition is computed before the actual one is set.
+type LastTransitionTimeTolerance time.Duration
+
+// ApplyToMatch applies this configuration to the given match options.
+func (t LastTransitionTimeTolerance) ApplyToMatch(opts *MatchOptions) {
+	opts.lastTransitionTimeTolerance = time.Duration(t)
+}
+
+// IgnoreObservedGeneration allows to ignore the ObservedGeneration when matching conditions.
+type IgnoreObservedGeneration bool
+
+// ApplyToMatch applies this configuration to the given match options.
+func (i IgnoreObservedGeneration) ApplyToMatch(opts *MatchOptions) {
+	opts.ignoreObservedGeneration = bool(i)
+}
+
+// AllowExtraConditions allows MatchConditions to match lists of conditions with conditions not in the expected ones,
+// i.e. to check that the expected conditions are a subset of the actual ones.
+type AllowExtraConditions bool
+
+// ApplyToMatch applies this configuration to the given match options.
+func (a AllowExtraConditions) ApplyToMatch(opts *MatchOptions) {
+	opts.allowExtraConditions = bool(a)
+}
+
+// MatchConditions returns a custom matcher to check equality of []metav1.Condition, in any order.
+// By default all the fields of the conditions are compared; see MatchOption for relaxing the comparison.
+func MatchConditions(expected []metav1.Condition, opts ...MatchOption) types.GomegaMatcher {
+	return &matchConditions{
+		expected: expected,
+		opts:     opts,
+	}
+}
+
+type matchConditions struct {
+	expected []metav1.Condition
+	opts     []MatchOption
+}
+
+func (m matchConditions) Match(actual interface{}) (success bool, err error) {
+	elems := []interface{}{}
+	for _, condition := range m.expected {
+		elems = append(elems, MatchCondition(condition, m.opts...))
+	}
+
+	if (&MatchOptions{}).ApplyOptions(m.opts).allowExtraConditions {
+		return gomega.ContainElements(elems...).Match(actual)
+	}
+	return gomega.ConsistOf(elems...).Match(actual)
+}
+
+func (m matchConditions) FailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("expected\n\t%#v\nto match\n\t%#v\n", actual, m.expected)
+}
+
+func (m matchConditions) NegatedFailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("expected\n\t%#v\nto not match\n\t%#v\n", actual, m.expected)
+}
+
+// MatchCondition returns a custom matcher to check equality of metav1.Condition.
+// By default all the fields of the condition are compared; see MatchOption for relaxing the comparison.
+func MatchCondition(expected metav1.Condition, opts ...MatchOption) types.GomegaMatcher {
+	return &matchCondition{
+		expected:     expected,
+		matchOptions: (&MatchOptions{}).ApplyOptions(opts),
+	}
+}
+
+type matchCondition struct {
+	expected     metav1.Condition
+	matchOptions *MatchOptions
+}
+
+func (m matchCondition) Match(actual interface{}) (success bool, err error) {
+	actualCondition, ok := actual.(metav1.Condition)
+	if !ok {
+		return false, fmt.Errorf("actual should be of type metav1.Condition")
+	}
+
+	if actualCondition.Type != m.expected.Type ||
+		actualCondition.Status != m.expected.Status ||
+		actualCondition.Reason != m.expected.Reason ||
+		actualCondition.Message != m.expected.Message {
+		return false, nil
+	}
+	if !m.matchOptions.ignoreObservedGeneration && actualCondition.ObservedGeneration != m.expected.ObservedGeneration {
+		return false, nil
+	}
+	if !m.matchOptions.ignoreLastTransitionTime {
+		delta := actualCondition.LastTransitionTime.Sub(m.expected.LastTransitionTime.Time).Abs()
+		if delta > m.matchOptions.lastTransitionTimeTolerance {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (m matchCondition) FailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("expected\n\t%#v\nto match\n\t%#v\n", actual, m.expected)
+}
+
+func (m matchCondition) NegatedFailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("expected\n\t%#v\nto not match\n\t%#v\n", actual, m.expected)
+}
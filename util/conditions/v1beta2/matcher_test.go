@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMatchCondition(t *testing.T) {
+	now := metav1.Now()
+	expected := metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", Message: "foo", ObservedGeneration: 2, LastTransitionTime: now}
+
+	tests := []struct {
+		name        string
+		actual      interface{}
+		opts        []MatchOption
+		expectMatch bool
+	}{
+		{
+			name:        "Same condition",
+			actual:      expected,
+			expectMatch: true,
+		},
+		{
+			name:   "Different reason",
+			actual: metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Foo", Message: "foo", ObservedGeneration: 2, LastTransitionTime: now},
+		},
+		{
+			name:   "Different observed generation",
+			actual: metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", Message: "foo", ObservedGeneration: 1, LastTransitionTime: now},
+		},
+		{
+			name:        "Different observed generation ignored",
+			actual:      metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", Message: "foo", ObservedGeneration: 1, LastTransitionTime: now},
+			opts:        []MatchOption{IgnoreObservedGeneration(true)},
+			expectMatch: true,
+		},
+		{
+			name:   "Different last transition time",
+			actual: metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", Message: "foo", ObservedGeneration: 2, LastTransitionTime: metav1.NewTime(now.Add(-time.Second))},
+		},
+		{
+			name:        "Different last transition time within the tolerance",
+			actual:      metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", Message: "foo", ObservedGeneration: 2, LastTransitionTime: metav1.NewTime(now.Add(-time.Second))},
+			opts:        []MatchOption{LastTransitionTimeTolerance(5 * time.Second)},
+			expectMatch: true,
+		},
+		{
+			name:   "Different last transition time exceeding the tolerance",
+			actual: metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", Message: "foo", ObservedGeneration: 2, LastTransitionTime: metav1.NewTime(now.Add(10 * time.Second))},
+			opts:   []MatchOption{LastTransitionTimeTolerance(5 * time.Second)},
+		},
+		{
+			name:        "Different last transition time ignored",
+			actual:      metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", Message: "foo", ObservedGeneration: 2},
+			opts:        []MatchOption{IgnoreLastTransitionTime(true)},
+			expectMatch: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			if tt.expectMatch {
+				g.Expect(tt.actual).To(MatchCondition(expected, tt.opts...))
+			} else {
+				g.Expect(tt.actual).ToNot(MatchCondition(expected, tt.opts...))
+			}
+		})
+	}
+}
+
+func TestMatchConditions(t *testing.T) {
+	g := NewWithT(t)
+
+	actual := []metav1.Condition{
+		{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", LastTransitionTime: metav1.Now()},
+		{Type: "Available", Status: metav1.ConditionFalse, Reason: "NotAvailable", LastTransitionTime: metav1.Now()},
+	}
+
+	g.Expect(actual).To(MatchConditions([]metav1.Condition{
+		{Type: "Available", Status: metav1.ConditionFalse, Reason: "NotAvailable"},
+		{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"},
+	}, IgnoreLastTransitionTime(true)))
+	g.Expect(actual).ToNot(MatchConditions([]metav1.Condition{
+		{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"},
+	}, IgnoreLastTransitionTime(true)))
+	g.Expect(actual).To(MatchConditions([]metav1.Condition{
+		{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"},
+	}, IgnoreLastTransitionTime(true), AllowExtraConditions(true)))
+	g.Expect(actual).ToNot(MatchConditions([]metav1.Condition{
+		{Type: "Paused", Status: metav1.ConditionFalse, Reason: "NotPaused"},
+	}, IgnoreLastTransitionTime(true), AllowExtraConditions(true)))
+}
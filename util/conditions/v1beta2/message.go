@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxMessageLength allows to cap the length in bytes of the message of a mirror, aggregate or summary condition.
+// Messages listing several items, e.g. the messages of the source objects of an aggregate condition, are truncated
+// after the last item fitting in the limit, and end with a line reporting how many items were omitted, e.g.
+// "* ... (5 more)"; other messages are truncated after the last character fitting in the limit, and end with
+// the number of characters omitted, e.g. "... (120 more characters)".
+// If not set, or zero, messages are not truncated; if smaller than the marker reporting the omitted characters,
+// truncated messages consist of the marker only.
+type MaxMessageLength int
+
+// ApplyToMirror applies this configuration to the given mirror options.
+func (m MaxMessageLength) ApplyToMirror(opts *MirrorOptions) {
+	opts.maxMessageLength = int(m)
+}
+
+// ApplyToAggregate applies this configuration to the given aggregate options.
+func (m MaxMessageLength) ApplyToAggregate(opts *AggregateOptions) {
+	opts.maxMessageLength = int(m)
+}
+
+// ApplyToSummary applies this configuration to the given summary options.
+func (m MaxMessageLength) ApplyToSummary(opts *SummaryOptions) {
+	opts.maxMessageLength = int(m)
+}
+
+// joinMessages joins the given message lines, dropping the trailing lines not fitting in maxLength, if set,
+// and replacing them with a line reporting how many lines were omitted.
+func joinMessages(lines []string, maxLength int) string {
+	message := strings.Join(lines, "\n")
+	if maxLength <= 0 || len(message) <= maxLength {
+		return message
+	}
+
+	omitted := func(n int) string { return fmt.Sprintf("* ... (%d more)", n) }
+	keep, length := 0, 0
+	for i := 0; i < len(lines)-1; i++ {
+		if i > 0 {
+			length++
+		}
+		length += len(lines[i])
+		if length+1+len(omitted(len(lines)-i-1)) > maxLength {
+			break
+		}
+		keep = i + 1
+	}
+	if keep == 0 {
+		return truncateMessage(message, maxLength)
+	}
+	return strings.Join(lines[:keep], "\n") + "\n" + omitted(len(lines)-keep)
+}
+
+// truncateMessage truncates the given message after the last character fitting in maxLength, if set,
+// appending the number of characters omitted.
+func truncateMessage(message string, maxLength int) string {
+	if maxLength <= 0 || len(message) <= maxLength {
+		return message
+	}
+
+	// The cut is computed assuming the longest marker, so the result fits in maxLength unless maxLength is smaller than the marker.
+	cut := max(maxLength-len(fmt.Sprintf("... (%d more characters)", len(message))), 0)
+	for cut > 0 && !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s... (%d more characters)", message[:cut], len(message)-cut)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestJoinMessages(t *testing.T) {
+	lines := []string{"* m1: foo", "* m2: bar", "* m3: baz"}
+
+	tests := []struct {
+		name      string
+		lines     []string
+		maxLength int
+		want      string
+	}{
+		{
+			name:  "No max length",
+			lines: lines,
+			want:  "* m1: foo\n* m2: bar\n* m3: baz",
+		},
+		{
+			name:      "Message fitting the max length",
+			lines:     lines,
+			maxLength: 29,
+			want:      "* m1: foo\n* m2: bar\n* m3: baz",
+		},
+		{
+			name:      "Message truncated after the last line fitting the max length",
+			lines:     lines,
+			maxLength: 28,
+			want:      "* m1: foo\n* ... (2 more)",
+		},
+		{
+			name:      "Message truncated after the last character fitting the max length if the first line does not fit",
+			lines:     []string{"* m1: foo bar baz qux quux corge", "* m2: bar"},
+			maxLength: 40,
+			want:      "* m1: foo bar ba... (26 more characters)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got := joinMessages(tt.lines, tt.maxLength)
+			g.Expect(got).To(Equal(tt.want))
+			if tt.maxLength > 0 {
+				g.Expect(len(got)).To(BeNumerically("<=", tt.maxLength))
+			}
+		})
+	}
+}
+
+func TestTruncateMessage(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(truncateMessage("foo", 0)).To(Equal("foo"))
+	g.Expect(truncateMessage("foo", 3)).To(Equal("foo"))
+	g.Expect(truncateMessage("Instance i-1234567890 is not running, state is stopped", 40)).To(Equal("Instance i-12345... (38 more characters)"))
+	// Messages are never truncated in the middle of a multi-byte character.
+	g.Expect(truncateMessage("ééééééééééééééééééééééééé", 25)).To(Equal("... (50 more characters)"))
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// MirrorOption is some configuration that modifies options for a mirror call.
+type MirrorOption interface {
+	// ApplyToMirror applies this configuration to the given mirror options.
+	ApplyToMirror(*MirrorOptions)
+}
+
+// MirrorOptions allows to set options for the mirror operation.
+type MirrorOptions struct {
+	targetConditionType    string
+	fallbackCondition      *metav1.Condition
+	customMessageFunc      CustomMessageFunc
+	transitionHistory      *TransitionHistory
+	staleAfter             *time.Duration
+	reasonMapping          ReasonMapping
+	fallbackConditionTypes []string
+	maxMessageLength       int
+	recordMirrorSource     bool
+	severityHint           clusterv1.ConditionSeverity
+}
+
+// ApplyOptions applies the given list options on these options,
+// and then returns itself (for convenient chaining).
+func (o *MirrorOptions) ApplyOptions(opts []MirrorOption) *MirrorOptions {
+	for _, opt := range opts {
+		opt.ApplyToMirror(o)
+	}
+	return o
+}
+
+// TargetConditionType allows to specify the type of the condition set on the target object;
+// if not set, the type of the source condition is used.
+type TargetConditionType string
+
+// ApplyToMirror applies this configuration to the given mirror options.
+func (t TargetConditionType) ApplyToMirror(opts *MirrorOptions) {
+	opts.targetConditionType = string(t)
+}
+
+// ApplyToAggregate applies this configuration to the given aggregate options.
+func (t TargetConditionType) ApplyToAggregate(opts *AggregateOptions) {
+	opts.targetConditionType = string(t)
+}
+
+// CustomMessageFunc allows to compute the message surfaced in the target object from each source condition,
+// e.g. to rewrite or redact provider-internal details before surfacing them to users; the function must not
+// modify the given condition.
+type CustomMessageFunc func(condition *metav1.Condition) string
+
+// ApplyToMirror applies this configuration to the given mirror options.
+func (f CustomMessageFunc) ApplyToMirror(opts *MirrorOptions) {
+	opts.customMessageFunc = f
+}
+
+// ApplyToAggregate applies this configuration to the given aggregate options.
+func (f CustomMessageFunc) ApplyToAggregate(opts *AggregateOptions) {
+	opts.customMessageFunc = f
+}
+
+// FallbackCondition defines the condition to set on the target object when the source condition does not exist;
+// if not set, the target condition is set to Unknown with the NotYetReported reason.
+type FallbackCondition struct {
+	Status  metav1.ConditionStatus
+	Reason  string
+	Message string
+}
+
+// ApplyToMirror applies this configuration to the given mirror options.
+func (f FallbackCondition) ApplyToMirror(opts *MirrorOptions) {
+	opts.fallbackCondition = &metav1.Condition{
+		Status:  f.Status,
+		Reason:  f.Reason,
+		Message: f.Message,
+	}
+}
+
+// FallbackConditionTypes defines the condition types to mirror, in priority order, when the source condition does
+// not exist, e.g. to mirror either the new or the old condition while a provider is renaming its conditions;
+// the FallbackCondition is used only if none of the fallback condition types exist.
+type FallbackConditionTypes []string
+
+// ApplyToMirror applies this configuration to the given mirror options.
+func (f FallbackConditionTypes) ApplyToMirror(opts *MirrorOptions) {
+	opts.fallbackConditionTypes = f
+}
+
+// StaleAfter downgrades the mirrored condition to Unknown with the StaleReport reason when the source condition is stale,
+// i.e. its ObservedGeneration is older than the generation of the source object; only for source conditions without
+// ObservedGeneration, the condition is stale when its last transition is older than the given duration, and a zero
+// duration disables this check. See IsStale for more details.
+type StaleAfter time.Duration
+
+// ApplyToMirror applies this configuration to the given mirror options.
+func (s StaleAfter) ApplyToMirror(opts *MirrorOptions) {
+	d := time.Duration(s)
+	opts.staleAfter = &d
+}
+
+// ReasonMapping maps the reasons of the source condition to the reasons of the mirrored condition, e.g. to surface
+// a provider-specific reason like InstanceNotFound with the MachineDoesNotExist reason defined by Cluster API;
+// reasons not in the mapping are mirrored as is.
+type ReasonMapping map[string]string
+
+// ApplyToMirror applies this configuration to the given mirror options.
+func (m ReasonMapping) ApplyToMirror(opts *MirrorOptions) {
+	opts.reasonMapping = m
+}
+
+// MirroredCondition defines a condition to be mirrored by SetMirrorConditions.
+type MirroredCondition struct {
+	// SourceConditionType is the type of the condition to be mirrored from the source object.
+	SourceConditionType string
+
+	// Options are the options for mirroring this condition.
+	Options []MirrorOption
+}
+
+// NewMirrorCondition creates a new condition with the state of the condition with the given type from the source object;
+// the ObservedGeneration is not mirrored, given that it refers to the source object.
+// If the source condition does not exist, the first existing condition among the FallbackConditionTypes is mirrored.
+func NewMirrorCondition(sourceObj Getter, sourceConditionType string, opts ...MirrorOption) *metav1.Condition {
+	mirrorOpt := (&MirrorOptions{targetConditionType: sourceConditionType}).ApplyOptions(opts)
+
+	mirroredConditionType := sourceConditionType
+	condition := Get(sourceObj, sourceConditionType)
+	for _, fallbackConditionType := range mirrorOpt.fallbackConditionTypes {
+		if condition != nil {
+			break
+		}
+		mirroredConditionType = fallbackConditionType
+		condition = Get(sourceObj, fallbackConditionType)
+	}
+
+	if condition != nil {
+		if mirrorOpt.staleAfter != nil && IsStale(sourceObj, mirroredConditionType, *mirrorOpt.staleAfter) {
+			return &metav1.Condition{
+				Type:    mirrorOpt.targetConditionType,
+				Status:  metav1.ConditionUnknown,
+				Reason:  clusterv1.StaleReportV1Beta2Reason,
+				Message: fmt.Sprintf("Condition %s is stale, last reported with status %s and reason %s", mirroredConditionType, condition.Status, condition.Reason),
+			}
+		}
+
+		message := condition.Message
+		if mirrorOpt.customMessageFunc != nil {
+			message = mirrorOpt.customMessageFunc(condition.DeepCopy())
+		}
+		reason := condition.Reason
+		if r, ok := mirrorOpt.reasonMapping[reason]; ok {
+			reason = r
+		}
+		mirrored := &metav1.Condition{
+			Type:    mirrorOpt.targetConditionType,
+			Status:  condition.Status,
+			Reason:  reason,
+			Message: truncateMessage(message, mirrorOpt.maxMessageLength),
+		}
+		mirrored.Message = withSeverityHint(*mirrored, mirrorOpt.severityHint)
+		return mirrored
+	}
+
+	if mirrorOpt.fallbackCondition != nil {
+		fallback := &metav1.Condition{
+			Type:    mirrorOpt.targetConditionType,
+			Status:  mirrorOpt.fallbackCondition.Status,
+			Reason:  mirrorOpt.fallbackCondition.Reason,
+			Message: mirrorOpt.fallbackCondition.Message,
+		}
+		fallback.Message = withSeverityHint(*fallback, mirrorOpt.severityHint)
+		return fallback
+	}
+
+	return &metav1.Condition{
+		Type:    mirrorOpt.targetConditionType,
+		Status:  metav1.ConditionUnknown,
+		Reason:  clusterv1.NotYetReportedV1Beta2Reason,
+		Message: fmt.Sprintf("Condition %s not yet reported", sourceConditionType),
+	}
+}
+
+// SetMirrorCondition is a convenience method that calls NewMirrorCondition to create a mirror condition from the
+// source object, and then calls Set to add the new condition to the target object.
+func SetMirrorCondition(sourceObj Getter, targetObj Setter, sourceConditionType string, opts ...MirrorOption) {
+	SetMirrorConditions(sourceObj, targetObj, MirroredCondition{SourceConditionType: sourceConditionType, Options: opts})
+}
+
+// SetMirrorConditions mirrors several conditions from the source object to the target object; all the mirror conditions
+// are set in a single pass, so they get the same LastTransitionTime, if changed, and the same ObservedGeneration, and
+// the target object is updated only once.
+// If more than one mirrored condition has the same target condition type, the last one wins.
+func SetMirrorConditions(sourceObj Getter, targetObj Setter, mirroredConditions ...MirroredCondition) {
+	conditions := make([]metav1.Condition, 0, len(mirroredConditions))
+	setOptions := &SetOptions{}
+	sources := map[string]MirrorSource{}
+	for _, m := range mirroredConditions {
+		condition := NewMirrorCondition(sourceObj, m.SourceConditionType, m.Options...)
+		conditions = append(conditions, *condition)
+
+		mirrorOpt := (&MirrorOptions{}).ApplyOptions(m.Options)
+		if mirrorOpt.transitionHistory != nil {
+			setOptions.ApplyOptions([]SetOption{mirrorOpt.transitionHistory.forConditionType(condition.Type)})
+		}
+		if mirrorOpt.recordMirrorSource {
+			if source, ok := newMirrorSource(sourceObj, m.SourceConditionType); ok {
+				sources[condition.Type] = source
+			}
+		}
+	}
+	setAll(targetObj, conditions, setOptions)
+
+	if len(sources) > 0 {
+		recordMirrorSources(targetObj, sources)
+	}
+}
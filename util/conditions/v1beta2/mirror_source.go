@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// MirrorSource is the state of the source object a mirrored condition refers to, recorded in the
+// MirroredConditionsSourceAnnotation of the target object.
+type MirrorSource struct {
+	// Generation is the generation of the source object when the condition has been mirrored.
+	Generation int64 `json:"generation"`
+
+	// ObservedGeneration is the ObservedGeneration of the source condition when it has been mirrored,
+	// i.e. the generation of the source object the source condition reflects; it is not set if the source condition
+	// does not exist or it has no ObservedGeneration.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ResourceVersion is the resourceVersion of the source object when the condition has been mirrored.
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// RecordMirrorSource is an opt-in option for recording the generation of the source object a mirrored condition refers
+// to in the MirroredConditionsSourceAnnotation of the target object, so consumers can detect if the mirrored condition
+// lags behind the source object, see IsMirrorLagging.
+// NOTE: The source and target objects must implement metav1.Object, otherwise nothing is recorded; the option is used
+// by SetMirrorCondition and SetMirrorConditions only.
+type RecordMirrorSource bool
+
+// ApplyToMirror applies this configuration to the given mirror options.
+func (r RecordMirrorSource) ApplyToMirror(opts *MirrorOptions) {
+	opts.recordMirrorSource = bool(r)
+}
+
+// GetMirrorSource returns the recorded state of the source object the mirrored condition with the given type refers to,
+// or nil if not recorded.
+func GetMirrorSource(obj metav1.Object, targetConditionType string) (*MirrorSource, error) {
+	sources, err := getMirrorSources(obj)
+	if err != nil {
+		return nil, err
+	}
+	source, ok := sources[targetConditionType]
+	if !ok {
+		return nil, nil
+	}
+	return &source, nil
+}
+
+// IsMirrorLagging returns true if the mirrored condition with the given type of the target object does not reflect the
+// latest generation of the source object, i.e. if the source condition has been mirrored when it reflected an older
+// generation of the source object, or before the source object changed; it also returns true if the source of the
+// mirrored condition is not recorded, see RecordMirrorSource.
+func IsMirrorLagging(sourceObj, targetObj metav1.Object, targetConditionType string) (bool, error) {
+	source, err := GetMirrorSource(targetObj, targetConditionType)
+	if err != nil {
+		return false, err
+	}
+	if source == nil {
+		return true, nil
+	}
+
+	reflectedGeneration := source.ObservedGeneration
+	if reflectedGeneration == 0 {
+		reflectedGeneration = source.Generation
+	}
+	return reflectedGeneration < sourceObj.GetGeneration(), nil
+}
+
+// newMirrorSource returns the MirrorSource for the source condition with the given type, if the source object
+// implements metav1.Object.
+func newMirrorSource(sourceObj Getter, sourceConditionType string) (MirrorSource, bool) {
+	obj, ok := sourceObj.(metav1.Object)
+	if !ok {
+		return MirrorSource{}, false
+	}
+	source := MirrorSource{
+		Generation:      obj.GetGeneration(),
+		ResourceVersion: obj.GetResourceVersion(),
+	}
+	if condition := Get(sourceObj, sourceConditionType); condition != nil {
+		source.ObservedGeneration = condition.ObservedGeneration
+	}
+	return source, true
+}
+
+func getMirrorSources(obj metav1.Object) (map[string]MirrorSource, error) {
+	value, ok := obj.GetAnnotations()[clusterv1.MirroredConditionsSourceAnnotation]
+	if !ok || value == "" {
+		return nil, nil
+	}
+	sources := map[string]MirrorSource{}
+	if err := json.Unmarshal([]byte(value), &sources); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// recordMirrorSources records the given sources in the MirroredConditionsSourceAnnotation of the target object,
+// preserving the sources recorded for other mirrored conditions.
+func recordMirrorSources(to Setter, newSources map[string]MirrorSource) {
+	obj, ok := to.(metav1.Object)
+	if !ok {
+		return
+	}
+
+	// NOTE: An annotation that cannot be read is dropped, given that it is rebuilt on the next mirror.
+	sources, err := getMirrorSources(obj)
+	if err != nil || sources == nil {
+		sources = map[string]MirrorSource{}
+	}
+	for t, s := range newSources {
+		sources[t] = s
+	}
+
+	value, err := json.Marshal(sources)
+	if err != nil {
+		return
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[clusterv1.MirroredConditionsSourceAnnotation] = string(value)
+	obj.SetAnnotations(annotations)
+}
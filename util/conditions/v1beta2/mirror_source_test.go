@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRecordMirrorSource(t *testing.T) {
+	g := NewWithT(t)
+
+	source := &fakeObjectWithMeta{
+		ObjectMeta: metav1.ObjectMeta{Generation: 3, ResourceVersion: "42"},
+		conditions: []metav1.Condition{
+			{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", ObservedGeneration: 2},
+			{Type: "Available", Status: metav1.ConditionTrue, Reason: "Available"},
+		},
+	}
+	target := &fakeObjectWithMeta{}
+
+	// The source is not recorded without the RecordMirrorSource option.
+	SetMirrorCondition(source, target, "Ready", TargetConditionType("InfrastructureReady"))
+	got, err := GetMirrorSource(target, "InfrastructureReady")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(BeNil())
+	lagging, err := IsMirrorLagging(source, target, "InfrastructureReady")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(lagging).To(BeTrue())
+
+	SetMirrorConditions(source, target,
+		MirroredCondition{SourceConditionType: "Ready", Options: []MirrorOption{TargetConditionType("InfrastructureReady"), RecordMirrorSource(true)}},
+		MirroredCondition{SourceConditionType: "Available", Options: []MirrorOption{TargetConditionType("InfrastructureAvailable"), RecordMirrorSource(true)}},
+	)
+	got, err = GetMirrorSource(target, "InfrastructureReady")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(Equal(&MirrorSource{Generation: 3, ObservedGeneration: 2, ResourceVersion: "42"}))
+
+	// The source condition reflects an older generation of the source object.
+	lagging, err = IsMirrorLagging(source, target, "InfrastructureReady")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(lagging).To(BeTrue())
+
+	// The source condition has no ObservedGeneration, so the generation of the source object is used.
+	lagging, err = IsMirrorLagging(source, target, "InfrastructureAvailable")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(lagging).To(BeFalse())
+
+	// The source object changed after the condition has been mirrored.
+	source.Generation = 4
+	lagging, err = IsMirrorLagging(source, target, "InfrastructureAvailable")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(lagging).To(BeTrue())
+
+	// Sources recorded for other mirrored conditions are preserved.
+	source.conditions[0].ObservedGeneration = 4
+	SetMirrorCondition(source, target, "Ready", TargetConditionType("InfrastructureReady"), RecordMirrorSource(true))
+	lagging, err = IsMirrorLagging(source, target, "InfrastructureReady")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(lagging).To(BeFalse())
+	got, err = GetMirrorSource(target, "InfrastructureAvailable")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(Equal(&MirrorSource{Generation: 3, ResourceVersion: "42"}))
+}
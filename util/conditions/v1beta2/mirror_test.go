@@ -0,0 +1,169 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestNewMirrorCondition(t *testing.T) {
+	source := &fakeObject{
+		generation: 5,
+		conditions: []metav1.Condition{
+			{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "foo", ObservedGeneration: 5, LastTransitionTime: metav1.Now()},
+			{Type: "Stale", Status: metav1.ConditionTrue, Reason: "Foo", Message: "foo", ObservedGeneration: 4, LastTransitionTime: metav1.Now()},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		sourceType string
+		opts       []MirrorOption
+		want       *metav1.Condition
+	}{
+		{
+			name:       "Mirror a condition",
+			sourceType: "Ready",
+			want:       &metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "foo"},
+		},
+		{
+			name:       "Mirror a condition with a target condition type",
+			sourceType: "Ready",
+			opts:       []MirrorOption{TargetConditionType("InfrastructureReady")},
+			want:       &metav1.Condition{Type: "InfrastructureReady", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "foo"},
+		},
+		{
+			name:       "Mirror a condition with a custom message",
+			sourceType: "Ready",
+			opts: []MirrorOption{CustomMessageFunc(func(c *metav1.Condition) string {
+				return fmt.Sprintf("Infrastructure is %s", c.Reason)
+			})},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "Infrastructure is NotReady"},
+		},
+		{
+			name:       "Mirror a condition with a reason mapping",
+			sourceType: "Ready",
+			opts:       []MirrorOption{ReasonMapping{"NotReady": clusterv1.MachineDoesNotExistV1Beta2Reason}},
+			want:       &metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: clusterv1.MachineDoesNotExistV1Beta2Reason, Message: "foo"},
+		},
+		{
+			name:       "Mirror a condition with a reason not in the reason mapping",
+			sourceType: "Ready",
+			opts:       []MirrorOption{ReasonMapping{"InstanceNotFound": clusterv1.MachineDoesNotExistV1Beta2Reason}},
+			want:       &metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "foo"},
+		},
+		{
+			name:       "Mirror a condition not stale",
+			sourceType: "Ready",
+			opts:       []MirrorOption{StaleAfter(time.Hour)},
+			want:       &metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "foo"},
+		},
+		{
+			name:       "Mirror a stale condition",
+			sourceType: "Stale",
+			opts:       []MirrorOption{StaleAfter(time.Hour)},
+			want:       &metav1.Condition{Type: "Stale", Status: metav1.ConditionUnknown, Reason: clusterv1.StaleReportV1Beta2Reason, Message: "Condition Stale is stale, last reported with status True and reason Foo"},
+		},
+		{
+			name:       "Mirror a condition with fallback condition types",
+			sourceType: "Available",
+			opts:       []MirrorOption{FallbackConditionTypes{"Deleting", "Ready", "Stale"}},
+			want:       &metav1.Condition{Type: "Available", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "foo"},
+		},
+		{
+			name:       "Mirror a condition with fallback condition types, source condition first",
+			sourceType: "Stale",
+			opts:       []MirrorOption{FallbackConditionTypes{"Ready"}},
+			want:       &metav1.Condition{Type: "Stale", Status: metav1.ConditionTrue, Reason: "Foo", Message: "foo"},
+		},
+		{
+			name:       "Mirror a stale condition from a fallback condition type",
+			sourceType: "Available",
+			opts:       []MirrorOption{FallbackConditionTypes{"Stale"}, StaleAfter(time.Hour)},
+			want:       &metav1.Condition{Type: "Available", Status: metav1.ConditionUnknown, Reason: clusterv1.StaleReportV1Beta2Reason, Message: "Condition Stale is stale, last reported with status True and reason Foo"},
+		},
+		{
+			name:       "Mirror a condition not yet reported with fallback condition types",
+			sourceType: "Available",
+			opts:       []MirrorOption{FallbackConditionTypes{"Deleting"}, FallbackCondition{Status: metav1.ConditionFalse, Reason: "Bar", Message: "bar"}},
+			want:       &metav1.Condition{Type: "Available", Status: metav1.ConditionFalse, Reason: "Bar", Message: "bar"},
+		},
+		{
+			name:       "Mirror a condition not yet reported",
+			sourceType: "Available",
+			want:       &metav1.Condition{Type: "Available", Status: metav1.ConditionUnknown, Reason: clusterv1.NotYetReportedV1Beta2Reason, Message: "Condition Available not yet reported"},
+		},
+		{
+			name:       "Mirror a condition not yet reported with a fallback condition",
+			sourceType: "Available",
+			opts:       []MirrorOption{FallbackCondition{Status: metav1.ConditionFalse, Reason: "Bar", Message: "bar"}},
+			want:       &metav1.Condition{Type: "Available", Status: metav1.ConditionFalse, Reason: "Bar", Message: "bar"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(NewMirrorCondition(source, tt.sourceType, tt.opts...)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestSetMirrorConditions(t *testing.T) {
+	g := NewWithT(t)
+
+	source := &fakeObject{
+		conditions: []metav1.Condition{
+			{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"},
+			{Type: "Available", Status: metav1.ConditionFalse, Reason: "NotAvailable"},
+		},
+	}
+	target := &fakeObject{
+		generation: 3,
+		conditions: []metav1.Condition{
+			{Type: "Paused", Status: metav1.ConditionFalse, Reason: "NotPaused"},
+		},
+	}
+
+	SetMirrorConditions(source, target,
+		MirroredCondition{SourceConditionType: "Ready", Options: []MirrorOption{TargetConditionType("InfrastructureReady")}},
+		MirroredCondition{SourceConditionType: "Available", Options: []MirrorOption{TargetConditionType("InfrastructureAvailable")}},
+		MirroredCondition{SourceConditionType: "Deleting"},
+	)
+
+	// All the conditions are set with a single call, with the same LastTransitionTime and ObservedGeneration.
+	g.Expect(target.setCalls).To(Equal(1))
+	g.Expect(target.conditions).To(HaveLen(4))
+	g.Expect(target.conditions[0].Type).To(Equal("Paused"))
+	g.Expect(target.conditions[1]).To(Equal(metav1.Condition{Type: "InfrastructureReady", Status: metav1.ConditionTrue, Reason: "Ready", ObservedGeneration: 3, LastTransitionTime: target.conditions[1].LastTransitionTime}))
+	g.Expect(target.conditions[2]).To(Equal(metav1.Condition{Type: "InfrastructureAvailable", Status: metav1.ConditionFalse, Reason: "NotAvailable", ObservedGeneration: 3, LastTransitionTime: target.conditions[1].LastTransitionTime}))
+	g.Expect(target.conditions[3]).To(Equal(metav1.Condition{Type: "Deleting", Status: metav1.ConditionUnknown, Reason: clusterv1.NotYetReportedV1Beta2Reason, Message: "Condition Deleting not yet reported", ObservedGeneration: 3, LastTransitionTime: target.conditions[1].LastTransitionTime}))
+	g.Expect(target.conditions[1].LastTransitionTime.IsZero()).To(BeFalse())
+
+	// SetMirrorCondition mirrors a single condition.
+	SetMirrorCondition(source, target, "Ready")
+	g.Expect(target.setCalls).To(Equal(2))
+	g.Expect(Get(target, "Ready")).ToNot(BeNil())
+	g.Expect(Has(target, "Ready")).To(BeTrue())
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// messagePayloadPrefix is the prefix of the last line of a condition message carrying a machine-readable payload.
+const messagePayloadPrefix = "payload: "
+
+// MessagePayload is a machine-readable payload which can be encoded in a condition message with EncodeMessagePayload,
+// e.g. for surfacing the number of Machines in each state and the names of the Machines with issues to UIs and dashboards,
+// without the need to parse the human-readable message.
+type MessagePayload struct {
+	// Counts are counters, e.g. the number of Machines by state.
+	Counts map[string]int `json:"counts,omitempty"`
+
+	// Objects are the names of the objects relevant for the condition, e.g. the Machines reporting issues.
+	Objects []string `json:"objects,omitempty"`
+}
+
+// String returns a human-readable representation of the payload, e.g. "2 Provisioning, 1 Failed; objects: m1, m2".
+func (p MessagePayload) String() string {
+	var parts []string
+	if counts := FormatCounts(p.Counts); counts != "" {
+		parts = append(parts, counts)
+	}
+	if len(p.Objects) > 0 {
+		parts = append(parts, fmt.Sprintf("objects: %s", strings.Join(p.Objects, ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// FormatCounts returns a human-readable representation of the given counters, sorted by value from the largest
+// to the smallest and then by name, e.g. "2 Provisioning, 1 Failed"; counters with value zero are omitted.
+func FormatCounts(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for k, v := range counts {
+		if v != 0 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[k], k))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// EncodeMessagePayload returns the given human-readable message with the JSON encoding of the given payload appended
+// as the last line, e.g. "* m1: foo\npayload: {"objects":["m1"]}"; use DecodeMessagePayload to read the payload back,
+// and TrimMessagePayload to get the human-readable message only.
+// The payload can be any value that can be encoded to JSON, e.g. a MessagePayload.
+func EncodeMessagePayload(message string, payload interface{}) (string, error) {
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode message payload")
+	}
+	if message == "" {
+		return messagePayloadPrefix + string(value), nil
+	}
+	return fmt.Sprintf("%s\n%s%s", TrimMessagePayload(message), messagePayloadPrefix, value), nil
+}
+
+// DecodeMessagePayload decodes the payload encoded with EncodeMessagePayload in the given message into payload;
+// it returns false if the message has no payload.
+func DecodeMessagePayload(message string, payload interface{}) (bool, error) {
+	_, value, ok := splitMessagePayload(message)
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(value), payload); err != nil {
+		return false, errors.Wrap(err, "failed to decode message payload")
+	}
+	return true, nil
+}
+
+// TrimMessagePayload returns the human-readable part of a message, dropping the payload encoded with
+// EncodeMessagePayload, if any.
+func TrimMessagePayload(message string) string {
+	human, _, _ := splitMessagePayload(message)
+	return human
+}
+
+func splitMessagePayload(message string) (string, string, bool) {
+	human, lastLine := "", message
+	if i := strings.LastIndex(message, "\n"); i >= 0 {
+		human, lastLine = message[:i], message[i+1:]
+	}
+	value, ok := strings.CutPrefix(lastLine, messagePayloadPrefix)
+	if !ok || !strings.HasPrefix(value, "{") && !strings.HasPrefix(value, "[") {
+		return message, "", false
+	}
+	return human, value, true
+}
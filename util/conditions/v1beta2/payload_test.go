@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMessagePayload(t *testing.T) {
+	t.Run("Encode and decode a payload", func(t *testing.T) {
+		g := NewWithT(t)
+
+		payload := MessagePayload{Counts: map[string]int{"Provisioning": 2, "Failed": 1}, Objects: []string{"m1"}}
+		message, err := EncodeMessagePayload("* m1: foo", payload)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(message).To(Equal("* m1: foo\npayload: {\"counts\":{\"Failed\":1,\"Provisioning\":2},\"objects\":[\"m1\"]}"))
+		g.Expect(TrimMessagePayload(message)).To(Equal("* m1: foo"))
+
+		got := MessagePayload{}
+		ok, err := DecodeMessagePayload(message, &got)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(got).To(Equal(payload))
+	})
+	t.Run("Encode replaces an existing payload", func(t *testing.T) {
+		g := NewWithT(t)
+
+		message, err := EncodeMessagePayload("foo\npayload: {\"objects\":[\"m1\"]}", MessagePayload{Objects: []string{"m2"}})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(message).To(Equal("foo\npayload: {\"objects\":[\"m2\"]}"))
+	})
+	t.Run("Encode a payload without message", func(t *testing.T) {
+		g := NewWithT(t)
+
+		message, err := EncodeMessagePayload("", MessagePayload{Objects: []string{"m1"}})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(message).To(Equal("payload: {\"objects\":[\"m1\"]}"))
+		g.Expect(TrimMessagePayload(message)).To(BeEmpty())
+	})
+	t.Run("Messages without payload", func(t *testing.T) {
+		g := NewWithT(t)
+
+		message := "* m1: foo\n* m2: payload: bar"
+		g.Expect(TrimMessagePayload(message)).To(Equal(message))
+
+		ok, err := DecodeMessagePayload(message, &MessagePayload{})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeFalse())
+	})
+	t.Run("Invalid payload", func(t *testing.T) {
+		g := NewWithT(t)
+
+		ok, err := DecodeMessagePayload("foo\npayload: {\"counts\":", &MessagePayload{})
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(ok).To(BeFalse())
+	})
+}
+
+func TestFormatMessagePayload(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(FormatCounts(map[string]int{"Provisioning": 2, "Running": 2, "Failed": 1, "Deleting": 0})).To(Equal("2 Provisioning, 2 Running, 1 Failed"))
+	g.Expect(FormatCounts(nil)).To(BeEmpty())
+
+	g.Expect(MessagePayload{Counts: map[string]int{"Failed": 1}, Objects: []string{"m1", "m2"}}.String()).To(Equal("1 Failed; objects: m1, m2"))
+	g.Expect(MessagePayload{Objects: []string{"m1"}}.String()).To(Equal("objects: m1"))
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var (
+	negativePolarityConditionTypesLock sync.RWMutex
+	negativePolarityConditionTypes     = sets.New[string](
+		clusterv1.PausedV1Beta2Condition,
+		clusterv1.DeletingV1Beta2Condition,
+	)
+)
+
+// RegisterNegativePolarityConditionTypes registers condition types with negative polarity, i.e. conditions for which
+// the True status reports a state requiring the user attention, e.g. Paused or Deleting; summary, aggregate and
+// ConditionRecorder operations use the registry to interpret the status of these conditions.
+// Paused and Deleting are registered by default.
+// NOTE: Controllers should register their condition types at start up, e.g. in an init function.
+func RegisterNegativePolarityConditionTypes(conditionTypes ...string) {
+	negativePolarityConditionTypesLock.Lock()
+	defer negativePolarityConditionTypesLock.Unlock()
+	negativePolarityConditionTypes.Insert(conditionTypes...)
+}
+
+// IsNegativePolarity returns true if the condition type has been registered with negative polarity.
+func IsNegativePolarity(conditionType string) bool {
+	negativePolarityConditionTypesLock.RLock()
+	defer negativePolarityConditionTypesLock.RUnlock()
+	return negativePolarityConditionTypes.Has(conditionType)
+}
+
+// desiredStatus returns the status reporting the desired state for a condition type, i.e. False for conditions
+// with negative polarity and True for all the other conditions.
+func desiredStatus(conditionType string) metav1.ConditionStatus {
+	if IsNegativePolarity(conditionType) {
+		return metav1.ConditionFalse
+	}
+	return metav1.ConditionTrue
+}
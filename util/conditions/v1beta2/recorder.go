@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// DefaultEventInterval is the default minimum interval between two events emitted by a ConditionRecorder
+	// for the same condition of the same object.
+	DefaultEventInterval = time.Minute
+
+	// conditionRecorderCacheSize is the maximum number of recently emitted events tracked for rate limiting.
+	conditionRecorderCacheSize = 4096
+)
+
+// ConditionRecorderOption is some configuration that modifies a ConditionRecorder.
+type ConditionRecorderOption interface {
+	// ApplyToConditionRecorder applies this configuration to the given ConditionRecorder.
+	ApplyToConditionRecorder(*ConditionRecorder)
+}
+
+// NegativePolarityConditionTypes are the types of the conditions for which the True status reports a state requiring
+// the user attention, in addition to the ones registered with RegisterNegativePolarityConditionTypes; events for these
+// conditions are of the Warning type when the condition is not False. For all the other conditions events are of the
+// Warning type when the condition is not True.
+type NegativePolarityConditionTypes []string
+
+// ApplyToConditionRecorder applies this configuration to the given ConditionRecorder.
+func (t NegativePolarityConditionTypes) ApplyToConditionRecorder(r *ConditionRecorder) {
+	r.negativePolarityConditionTypes = t
+}
+
+// EventInterval is the minimum interval between two events emitted for the same condition of the same object;
+// changes of the Reason only happening within the interval from the last event are not reported, while transitions
+// to a different Status are always reported, so the last event always reports the current Status of the condition.
+// Defaults to DefaultEventInterval.
+type EventInterval time.Duration
+
+// ApplyToConditionRecorder applies this configuration to the given ConditionRecorder.
+func (i EventInterval) ApplyToConditionRecorder(r *ConditionRecorder) {
+	r.eventInterval = time.Duration(i)
+}
+
+// ConditionRecorder sets conditions like Set, and emits a Kubernetes event every time a condition changes
+// its Status or Reason, e.g. ReadyFalse; this gives event-stream visibility into condition transitions.
+// NOTE: Events are emitted only for target objects implementing runtime.Object.
+type ConditionRecorder struct {
+	recorder                       record.EventRecorder
+	negativePolarityConditionTypes []string
+	eventInterval                  time.Duration
+	recentEvents                   *cache.LRUExpireCache
+}
+
+// NewConditionRecorder returns a new ConditionRecorder emitting events using the given recorder.
+func NewConditionRecorder(recorder record.EventRecorder, opts ...ConditionRecorderOption) *ConditionRecorder {
+	r := &ConditionRecorder{
+		recorder:      recorder,
+		eventInterval: DefaultEventInterval,
+		recentEvents:  cache.NewLRUExpireCache(conditionRecorderCacheSize),
+	}
+	for _, opt := range opts {
+		opt.ApplyToConditionRecorder(r)
+	}
+	return r
+}
+
+// Set sets the given condition, and emits an event if the condition changed its Status or Reason.
+func (r *ConditionRecorder) Set(to Setter, condition metav1.Condition, opts ...SetOption) {
+	if to == nil {
+		return
+	}
+
+	existing := Get(to, condition.Type)
+	Set(to, condition, opts...)
+
+	if existing != nil && existing.Status == condition.Status && existing.Reason == condition.Reason {
+		return
+	}
+	obj, ok := to.(runtime.Object)
+	if !ok {
+		return
+	}
+
+	if r.eventInterval > 0 {
+		if key, ok := r.rateLimitKey(obj, condition.Type); ok {
+			if lastStatus, found := r.recentEvents.Get(key); found && lastStatus == condition.Status {
+				return
+			}
+			r.recentEvents.Add(key, condition.Status, r.eventInterval)
+		}
+	}
+
+	message := fmt.Sprintf("Condition %s changed to %s with reason %s", condition.Type, condition.Status, condition.Reason)
+	if condition.Message != "" {
+		message = fmt.Sprintf("%s: %s", message, condition.Message)
+	}
+	r.recorder.Event(obj, r.eventType(condition), fmt.Sprintf("%s%s", condition.Type, condition.Status), message)
+}
+
+// eventType returns Normal if the condition reports the desired state, Warning otherwise.
+func (r *ConditionRecorder) eventType(condition metav1.Condition) string {
+	desired := desiredStatus(condition.Type)
+	if slices.Contains(r.negativePolarityConditionTypes, condition.Type) {
+		desired = metav1.ConditionFalse
+	}
+	if condition.Status == desired {
+		return corev1.EventTypeNormal
+	}
+	return corev1.EventTypeWarning
+}
+
+func (r *ConditionRecorder) rateLimitKey(obj runtime.Object, conditionType string) (string, bool) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", false
+	}
+	id := string(accessor.GetUID())
+	if id == "" {
+		id = fmt.Sprintf("%T/%s/%s", obj, accessor.GetNamespace(), accessor.GetName())
+	}
+	return fmt.Sprintf("%s/%s", id, conditionType), true
+}
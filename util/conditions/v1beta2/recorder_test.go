@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestConditionRecorder(t *testing.T) {
+	g := NewWithT(t)
+
+	fakeRecorder := record.NewFakeRecorder(32)
+	r := NewConditionRecorder(fakeRecorder, NegativePolarityConditionTypes{"Deleting"}, EventInterval(0))
+
+	u := &unstructured.Unstructured{}
+	u.SetName("foo")
+	obj := UnstructuredSetter(u)
+
+	r.Set(obj, metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "foo"})
+	g.Expect(fakeRecorder.Events).To(Receive(Equal("Warning ReadyFalse Condition Ready changed to False with reason NotReady: foo")))
+
+	// No event if status and reason do not change.
+	r.Set(obj, metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "bar"})
+	g.Expect(fakeRecorder.Events).ToNot(Receive())
+
+	r.Set(obj, metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"})
+	g.Expect(fakeRecorder.Events).To(Receive(Equal("Normal ReadyTrue Condition Ready changed to True with reason Ready")))
+
+	// Events for conditions with negative polarity.
+	r.Set(obj, metav1.Condition{Type: "Deleting", Status: metav1.ConditionFalse, Reason: "NotDeleting"})
+	g.Expect(fakeRecorder.Events).To(Receive(HavePrefix("Normal DeletingFalse")))
+	r.Set(obj, metav1.Condition{Type: "Deleting", Status: metav1.ConditionTrue, Reason: "Deleting"})
+	g.Expect(fakeRecorder.Events).To(Receive(HavePrefix("Warning DeletingTrue")))
+
+	// Conditions are set also when no event is emitted.
+	g.Expect(Get(obj, "Ready").Status).To(Equal(metav1.ConditionTrue))
+}
+
+func TestConditionRecorder_RateLimiting(t *testing.T) {
+	g := NewWithT(t)
+
+	fakeRecorder := record.NewFakeRecorder(32)
+	r := NewConditionRecorder(fakeRecorder, EventInterval(time.Hour))
+
+	u := &unstructured.Unstructured{}
+	u.SetName("foo")
+	obj := UnstructuredSetter(u)
+
+	r.Set(obj, metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady"})
+	g.Expect(fakeRecorder.Events).To(Receive())
+
+	// Changes of the reason of the same condition within the interval are not reported.
+	r.Set(obj, metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "Provisioning"})
+	g.Expect(fakeRecorder.Events).ToNot(Receive())
+	g.Expect(Get(obj, "Ready").Reason).To(Equal("Provisioning"))
+
+	// Transitions to a different status within the interval are reported.
+	r.Set(obj, metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"})
+	g.Expect(fakeRecorder.Events).To(Receive(ContainSubstring("ReadyTrue")))
+	r.Set(obj, metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady"})
+	g.Expect(fakeRecorder.Events).To(Receive(ContainSubstring("ReadyFalse")))
+
+	// Transitions of other conditions are reported.
+	r.Set(obj, metav1.Condition{Type: "Available", Status: metav1.ConditionTrue, Reason: "Available"})
+	g.Expect(fakeRecorder.Events).To(Receive())
+}
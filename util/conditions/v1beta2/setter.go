@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Setter interface defines methods that an object should implement in order to
+// use the v1beta2 conditions package for setting conditions.
+type Setter interface {
+	Getter
+	SetV1Beta2Conditions([]metav1.Condition)
+}
+
+// SetOption is some configuration that modifies options for a set call.
+type SetOption interface {
+	// ApplyToSet applies this configuration to the given set options.
+	ApplyToSet(*SetOptions)
+}
+
+// SetOptions allows to set options for the set operation.
+type SetOptions struct {
+	transitionHistory []TransitionHistory
+}
+
+// ApplyOptions applies the given list options on these options,
+// and then returns itself (for convenient chaining).
+func (o *SetOptions) ApplyOptions(opts []SetOption) *SetOptions {
+	for _, opt := range opts {
+		opt.ApplyToSet(o)
+	}
+	return o
+}
+
+// Set sets the given condition.
+//
+// NOTE: If a condition already exists, the LastTransitionTime is updated only if the Status changes.
+// If the target object has a generation, the ObservedGeneration of the condition is set to it.
+func Set(to Setter, condition metav1.Condition, opts ...SetOption) {
+	setAll(to, []metav1.Condition{condition}, (&SetOptions{}).ApplyOptions(opts))
+}
+
+// setAll sets the given conditions with a single call to SetV1Beta2Conditions, so all the conditions
+// get the same LastTransitionTime, if changed, and the same ObservedGeneration.
+// Existing conditions keep their position, and new conditions are appended in the given order.
+func setAll(to Setter, conditions []metav1.Condition, options *SetOptions) {
+	if to == nil || len(conditions) == 0 {
+		return
+	}
+
+	var observedGeneration int64
+	if objWithGeneration, ok := to.(interface{ GetGeneration() int64 }); ok {
+		observedGeneration = objWithGeneration.GetGeneration()
+	}
+
+	now := metav1.Now()
+	targetConditions := append([]metav1.Condition{}, to.GetV1Beta2Conditions()...)
+	var transitions []Transition
+	for _, condition := range conditions {
+		condition.ObservedGeneration = observedGeneration
+		if condition.LastTransitionTime.IsZero() {
+			condition.LastTransitionTime = now
+		}
+		if options.maxTransitions(condition.Type) > 0 {
+			if existing := meta.FindStatusCondition(targetConditions, condition.Type); existing == nil || existing.Status != condition.Status || existing.Reason != condition.Reason {
+				transitions = append(transitions, Transition{Type: condition.Type, Status: condition.Status, Reason: condition.Reason, Time: now})
+			}
+		}
+		meta.SetStatusCondition(&targetConditions, condition)
+	}
+	to.SetV1Beta2Conditions(targetConditions)
+
+	if len(transitions) > 0 {
+		recordTransitions(to, transitions, options)
+	}
+}
+
+// Delete deletes the condition with the given type.
+func Delete(to Setter, conditionType string) {
+	if to == nil {
+		return
+	}
+
+	conditions := append([]metav1.Condition{}, to.GetV1Beta2Conditions()...)
+	if meta.RemoveStatusCondition(&conditions, conditionType) {
+		to.SetV1Beta2Conditions(conditions)
+	}
+}
+
+// Prune deletes all the conditions with a type not in the given list, e.g. to remove conditions not used
+// anymore after a controller changed the types of the conditions it sets; the other conditions keep their position.
+// NOTE: The conditions are set on the target object only if at least one condition is deleted.
+func Prune(to Setter, keep []string) {
+	if to == nil {
+		return
+	}
+
+	conditions := to.GetV1Beta2Conditions()
+	kept := make([]metav1.Condition, 0, len(conditions))
+	for _, c := range conditions {
+		if slices.Contains(keep, c.Type) {
+			kept = append(kept, c)
+		}
+	}
+	if len(kept) != len(conditions) {
+		to.SetV1Beta2Conditions(kept)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSet(t *testing.T) {
+	g := NewWithT(t)
+
+	past := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	obj := &fakeObject{
+		generation: 2,
+		conditions: []metav1.Condition{
+			{Type: "B", Status: metav1.ConditionTrue, Reason: "Foo", LastTransitionTime: past, ObservedGeneration: 1},
+			{Type: "A", Status: metav1.ConditionTrue, Reason: "Foo", LastTransitionTime: past, ObservedGeneration: 1},
+		},
+	}
+
+	// Changing only the reason does not change the LastTransitionTime.
+	Set(obj, metav1.Condition{Type: "A", Status: metav1.ConditionTrue, Reason: "Bar"})
+	g.Expect(obj.conditions[1].Reason).To(Equal("Bar"))
+	g.Expect(obj.conditions[1].LastTransitionTime).To(Equal(past))
+	g.Expect(obj.conditions[1].ObservedGeneration).To(Equal(int64(2)))
+
+	// Changing the status changes the LastTransitionTime.
+	Set(obj, metav1.Condition{Type: "B", Status: metav1.ConditionFalse, Reason: "Bar"})
+	g.Expect(obj.conditions[0].Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(obj.conditions[0].LastTransitionTime.After(past.Time)).To(BeTrue())
+
+	// New conditions are appended, existing conditions keep their position.
+	Set(obj, metav1.Condition{Type: "C", Status: metav1.ConditionTrue, Reason: "Foo"})
+	g.Expect(obj.conditions).To(HaveLen(3))
+	g.Expect(obj.conditions[0].Type).To(Equal("B"))
+	g.Expect(obj.conditions[1].Type).To(Equal("A"))
+	g.Expect(obj.conditions[2].Type).To(Equal("C"))
+	g.Expect(obj.setCalls).To(Equal(3))
+}
+
+func TestDelete(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &fakeObject{conditions: []metav1.Condition{{Type: "A"}, {Type: "B"}}}
+	Delete(obj, "C")
+	g.Expect(obj.setCalls).To(Equal(0))
+
+	Delete(obj, "A")
+	g.Expect(obj.setCalls).To(Equal(1))
+	g.Expect(obj.conditions).To(Equal([]metav1.Condition{{Type: "B"}}))
+}
+
+func TestPrune(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &fakeObject{conditions: []metav1.Condition{{Type: "C"}, {Type: "A"}, {Type: "Obsolete"}, {Type: "B"}}}
+	Prune(obj, []string{"A", "B", "C", "D"})
+	g.Expect(obj.setCalls).To(Equal(1))
+	g.Expect(obj.conditions).To(Equal([]metav1.Condition{{Type: "C"}, {Type: "A"}, {Type: "B"}}))
+
+	// Conditions are not set if there is nothing to prune.
+	Prune(obj, []string{"A", "B", "C"})
+	g.Expect(obj.setCalls).To(Equal(1))
+
+	Prune(obj, nil)
+	g.Expect(obj.conditions).To(BeEmpty())
+}
+
+type fakeObject struct {
+	name       string
+	generation int64
+	conditions []metav1.Condition
+	setCalls   int
+}
+
+func (o *fakeObject) GetName() string {
+	return o.name
+}
+
+func (o *fakeObject) GetGeneration() int64 {
+	return o.generation
+}
+
+func (o *fakeObject) GetV1Beta2Conditions() []metav1.Condition {
+	return o.conditions
+}
+
+func (o *fakeObject) SetV1Beta2Conditions(conditions []metav1.Condition) {
+	o.setCalls++
+	o.conditions = conditions
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// SeverityHint tags the condition created by mirror and aggregate operations with the given severity when the condition
+// reports a problem, i.e. it is False or, for conditions with negative polarity, True; the severity is surfaced as a
+// prefix of the message, e.g. "[Warning] message", the same format used by ConvertFromV1Beta1Conditions, and it can be
+// read with GetSeverity. The prefix is not counted in the MaxMessageLength.
+type SeverityHint clusterv1.ConditionSeverity
+
+// ApplyToMirror applies this configuration to the given mirror options.
+func (s SeverityHint) ApplyToMirror(opts *MirrorOptions) {
+	opts.severityHint = clusterv1.ConditionSeverity(s)
+}
+
+// ApplyToAggregate applies this configuration to the given aggregate options.
+func (s SeverityHint) ApplyToAggregate(opts *AggregateOptions) {
+	opts.severityHint = clusterv1.ConditionSeverity(s)
+}
+
+// GetSeverity returns the severity of a condition reporting a problem, i.e. a condition that is False or, for conditions
+// with negative polarity, True; the severity is read from the prefix of the message, if any, and it defaults to Error
+// otherwise. Conditions not reporting a problem have no severity.
+func GetSeverity(condition metav1.Condition) clusterv1.ConditionSeverity {
+	if !isProblem(condition) {
+		return clusterv1.ConditionSeverityNone
+	}
+	_, severity := trimSeverity(condition.Message)
+	return severity
+}
+
+// SortBySeverity sorts conditions for display, from the most to the least severe, i.e. conditions reporting a problem
+// with Error, Warning and Info severity, then Unknown conditions, then conditions reporting the desired state;
+// conditions with the same severity are sorted by type.
+func SortBySeverity(conditions []metav1.Condition) {
+	sort.SliceStable(conditions, func(i, j int) bool {
+		if ri, rj := severityRank(conditions[i]), severityRank(conditions[j]); ri != rj {
+			return ri < rj
+		}
+		return conditions[i].Type < conditions[j].Type
+	})
+}
+
+// severityRank returns the position of a condition in the SortBySeverity order.
+func severityRank(condition metav1.Condition) int {
+	if condition.Status == metav1.ConditionUnknown {
+		return 3
+	}
+	switch GetSeverity(condition) {
+	case clusterv1.ConditionSeverityError:
+		return 0
+	case clusterv1.ConditionSeverityWarning:
+		return 1
+	case clusterv1.ConditionSeverityInfo:
+		return 2
+	default:
+		return 4
+	}
+}
+
+// isProblem returns true if the condition is not Unknown and it does not report the desired state.
+func isProblem(condition metav1.Condition) bool {
+	return condition.Status != metav1.ConditionUnknown && condition.Status != desiredStatus(condition.Type)
+}
+
+// withSeverityHint returns the message of the given condition prefixed by the severity hint, if the condition reports
+// a problem and a severity hint is set; an existing severity prefix is replaced.
+func withSeverityHint(condition metav1.Condition, severity clusterv1.ConditionSeverity) string {
+	if severity == clusterv1.ConditionSeverityNone || !isProblem(condition) {
+		return condition.Message
+	}
+	message, _ := trimSeverity(condition.Message)
+	return strings.TrimSpace(fmt.Sprintf("[%s] %s", severity, message))
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestSeverityHint(t *testing.T) {
+	t.Run("Mirrored conditions reporting a problem are tagged with the severity hint", func(t *testing.T) {
+		g := NewWithT(t)
+
+		source := &fakeObject{conditions: []metav1.Condition{
+			{Type: "InfrastructureReady", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "[Error] foo"},
+			{Type: "BootstrapReady", Status: metav1.ConditionTrue, Reason: "Ready", Message: "bar"},
+		}}
+
+		got := NewMirrorCondition(source, "InfrastructureReady", SeverityHint(clusterv1.ConditionSeverityWarning))
+		g.Expect(got.Message).To(Equal("[Warning] foo"))
+		g.Expect(GetSeverity(*got)).To(Equal(clusterv1.ConditionSeverityWarning))
+
+		got = NewMirrorCondition(source, "BootstrapReady", SeverityHint(clusterv1.ConditionSeverityWarning))
+		g.Expect(got.Message).To(Equal("bar"))
+		g.Expect(GetSeverity(*got)).To(Equal(clusterv1.ConditionSeverityNone))
+
+		got = NewMirrorCondition(source, "Foo", SeverityHint(clusterv1.ConditionSeverityInfo), FallbackCondition{Status: metav1.ConditionFalse, Reason: "NotReported"})
+		g.Expect(got.Message).To(Equal("[Info]"))
+	})
+	t.Run("Aggregate conditions reporting a problem are tagged with the severity hint", func(t *testing.T) {
+		g := NewWithT(t)
+
+		sourceObjs := []Getter{
+			&fakeObject{name: "m1", conditions: []metav1.Condition{{Type: clusterv1.DeletingV1Beta2Condition, Status: metav1.ConditionTrue, Reason: "Deleting", Message: "foo"}}},
+			&fakeObject{name: "m2", conditions: []metav1.Condition{{Type: clusterv1.DeletingV1Beta2Condition, Status: metav1.ConditionFalse, Reason: "NotDeleting"}}},
+		}
+
+		got := NewAggregateCondition(sourceObjs, clusterv1.DeletingV1Beta2Condition, SeverityHint(clusterv1.ConditionSeverityInfo))
+		g.Expect(got.Message).To(Equal("[Info] * m1: foo"))
+		g.Expect(GetSeverity(*got)).To(Equal(clusterv1.ConditionSeverityInfo))
+	})
+}
+
+func TestSortBySeverity(t *testing.T) {
+	g := NewWithT(t)
+
+	conditions := []metav1.Condition{
+		{Type: "A", Status: metav1.ConditionTrue},
+		{Type: "B", Status: metav1.ConditionUnknown},
+		{Type: "C", Status: metav1.ConditionFalse, Message: "[Info] foo"},
+		{Type: "D", Status: metav1.ConditionFalse, Message: "foo"},
+		{Type: clusterv1.PausedV1Beta2Condition, Status: metav1.ConditionTrue, Message: "[Warning] foo"},
+		{Type: "E", Status: metav1.ConditionFalse, Message: "[Error] foo"},
+		{Type: clusterv1.DeletingV1Beta2Condition, Status: metav1.ConditionFalse},
+	}
+	SortBySeverity(conditions)
+
+	types := make([]string, 0, len(conditions))
+	for _, c := range conditions {
+		types = append(types, c.Type)
+	}
+	g.Expect(types).To(Equal([]string{"D", "E", clusterv1.PausedV1Beta2Condition, "C", "B", "A", clusterv1.DeletingV1Beta2Condition}))
+}
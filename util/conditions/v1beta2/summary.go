@@ -0,0 +1,219 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"fmt"
+	"regexp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// SummaryOption is some configuration that modifies options for a summary call.
+type SummaryOption interface {
+	// ApplyToSummary applies this configuration to the given summary options.
+	ApplyToSummary(*SummaryOptions)
+}
+
+// SummaryOptions allows to set options for the summary operation.
+type SummaryOptions struct {
+	conditionTypes   []string
+	weights          map[string]int
+	threshold        *int
+	maxMessageLength int
+	excludeFuncs     []ExcludeConditionTypesFunc
+}
+
+// ApplyOptions applies the given list options on these options,
+// and then returns itself (for convenient chaining).
+func (o *SummaryOptions) ApplyOptions(opts []SummaryOption) *SummaryOptions {
+	for _, opt := range opts {
+		opt.ApplyToSummary(o)
+	}
+	return o
+}
+
+// ForConditionTypes allows to define the types of the conditions to be summarized; conditions not yet reported are
+// considered Unknown. If not set, all the conditions of the source object are summarized.
+type ForConditionTypes []string
+
+// ApplyToSummary applies this configuration to the given summary options.
+func (t ForConditionTypes) ApplyToSummary(opts *SummaryOptions) {
+	opts.conditionTypes = t
+}
+
+// ExcludeConditionTypesFunc allows to exclude from the summary the conditions with the types for which the function
+// returns true, e.g. provider-specific or experimental conditions; the option can be set multiple times, and
+// a condition is excluded if any of the functions returns true.
+type ExcludeConditionTypesFunc func(conditionType string) bool
+
+// ApplyToSummary applies this configuration to the given summary options.
+func (f ExcludeConditionTypesFunc) ApplyToSummary(opts *SummaryOptions) {
+	opts.excludeFuncs = append(opts.excludeFuncs, f)
+}
+
+// ExcludeConditionTypesMatching returns an ExcludeConditionTypesFunc excluding from the summary the conditions with
+// types matching the given regular expression, e.g. regexp.MustCompile("^Experimental") or regexp.MustCompile("Paused$").
+func ExcludeConditionTypesMatching(re *regexp.Regexp) ExcludeConditionTypesFunc {
+	return re.MatchString
+}
+
+// isExcluded returns true if the given condition type is excluded from the summary.
+func (o *SummaryOptions) isExcluded(conditionType string) bool {
+	for _, f := range o.excludeFuncs {
+		if f(conditionType) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConditionWeights allows to define the weight of each condition type when computing the summary with a SummaryThreshold;
+// condition types without a weight have weight 1.
+type ConditionWeights map[string]int
+
+// ApplyToSummary applies this configuration to the given summary options.
+func (w ConditionWeights) ApplyToSummary(opts *SummaryOptions) {
+	opts.weights = w
+}
+
+// SummaryThreshold allows to define the minimum percentage, from 0 to 100, of the weight of the conditions reporting
+// the desired state for the summary condition to be True; e.g. with a SummaryThreshold of 90, the summary is True if
+// the conditions reporting the desired state account for at least 90% of the total weight, even if other conditions
+// report a problem. If the threshold is not reached, the summary is computed as usual.
+type SummaryThreshold int
+
+// ApplyToSummary applies this configuration to the given summary options.
+func (t SummaryThreshold) ApplyToSummary(opts *SummaryOptions) {
+	threshold := int(t)
+	opts.threshold = &threshold
+}
+
+// NewSummaryCondition creates a new condition with the given type summarizing the conditions of the source object.
+//
+// The summary condition is False if any of the conditions reports a problem, i.e. it is False or, for conditions
+// with negative polarity, see RegisterNegativePolarityConditionTypes, it is True; otherwise the summary condition is
+// Unknown if any of the conditions is Unknown or not yet reported, and True if all the conditions report the desired state;
+// with a SummaryThreshold, the summary condition is also True if enough conditions, by weight, report the desired state.
+// The reason is the reason of the conditions determining the summary status if they all have the same reason, otherwise
+// MultipleReasonsReported; the message lists the messages of these conditions, prefixed by the condition type.
+func NewSummaryCondition(sourceObj Getter, targetConditionType string, opts ...SummaryOption) *metav1.Condition {
+	summaryOpt := (&SummaryOptions{}).ApplyOptions(opts)
+
+	var conditions []metav1.Condition
+	if summaryOpt.conditionTypes == nil {
+		if sourceObj != nil {
+			for _, c := range sourceObj.GetV1Beta2Conditions() {
+				if c.Type != targetConditionType && !summaryOpt.isExcluded(c.Type) {
+					conditions = append(conditions, c)
+				}
+			}
+		}
+	} else {
+		for _, conditionType := range summaryOpt.conditionTypes {
+			if summaryOpt.isExcluded(conditionType) {
+				continue
+			}
+			condition := Get(sourceObj, conditionType)
+			if condition == nil {
+				condition = &metav1.Condition{
+					Type:    conditionType,
+					Status:  metav1.ConditionUnknown,
+					Reason:  clusterv1.NotYetReportedV1Beta2Reason,
+					Message: fmt.Sprintf("Condition %s not yet reported", conditionType),
+				}
+			}
+			conditions = append(conditions, *condition)
+		}
+	}
+
+	var problems, unknowns, others []metav1.Condition
+	for _, c := range conditions {
+		switch {
+		case c.Status == metav1.ConditionUnknown:
+			unknowns = append(unknowns, c)
+		case c.Status != desiredStatus(c.Type):
+			problems = append(problems, c)
+		default:
+			others = append(others, c)
+		}
+	}
+
+	status, summarized := metav1.ConditionTrue, others
+	var notSummarized []metav1.Condition
+	switch {
+	case summaryOpt.thresholdReached(conditions, others):
+		// Surface the conditions not reporting the desired state in the message, even if the threshold is reached.
+		notSummarized = append(append(notSummarized, problems...), unknowns...)
+	case len(problems) > 0:
+		status, summarized = metav1.ConditionFalse, problems
+	case len(unknowns) > 0:
+		status, summarized = metav1.ConditionUnknown, unknowns
+	}
+
+	var reason string
+	messages := []string{}
+	for i, c := range summarized {
+		switch {
+		case i == 0:
+			reason = c.Reason
+		case reason != c.Reason:
+			reason = clusterv1.MultipleReasonsReportedV1Beta2Reason
+		}
+	}
+	for _, c := range append(summarized, notSummarized...) {
+		if c.Message != "" {
+			messages = append(messages, fmt.Sprintf("* %s: %s", c.Type, c.Message))
+		}
+	}
+
+	return &metav1.Condition{
+		Type:    targetConditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: joinMessages(messages, summaryOpt.maxMessageLength),
+	}
+}
+
+// thresholdReached returns true if a threshold is set and the weight of the conditions reporting the desired state
+// is at least the threshold percentage of the weight of all the conditions.
+func (o *SummaryOptions) thresholdReached(conditions, desired []metav1.Condition) bool {
+	if o.threshold == nil || len(conditions) == 0 {
+		return false
+	}
+	return o.weight(desired)*100 >= o.weight(conditions)*(*o.threshold)
+}
+
+func (o *SummaryOptions) weight(conditions []metav1.Condition) int {
+	total := 0
+	for _, c := range conditions {
+		w, ok := o.weights[c.Type]
+		if !ok {
+			w = 1
+		}
+		total += w
+	}
+	return total
+}
+
+// SetSummaryCondition is a convenience method that calls NewSummaryCondition to create a summary condition from the
+// conditions of the source object, and then calls Set to add the new condition to the target object.
+func SetSummaryCondition(sourceObj Getter, targetObj Setter, targetConditionType string, opts ...SummaryOption) {
+	Set(targetObj, *NewSummaryCondition(sourceObj, targetConditionType, opts...))
+}
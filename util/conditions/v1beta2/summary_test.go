@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"regexp"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestNewSummaryCondition(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []metav1.Condition
+		opts       []SummaryOption
+		want       *metav1.Condition
+	}{
+		{
+			name: "All conditions report the desired state",
+			conditions: []metav1.Condition{
+				{Type: "InfrastructureReady", Status: metav1.ConditionTrue, Reason: "Ready"},
+				{Type: clusterv1.PausedV1Beta2Condition, Status: metav1.ConditionFalse, Reason: "Ready"},
+			},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"},
+		},
+		{
+			name: "True conditions with negative polarity are problems",
+			conditions: []metav1.Condition{
+				{Type: "InfrastructureReady", Status: metav1.ConditionTrue, Reason: "Ready"},
+				{Type: clusterv1.DeletingV1Beta2Condition, Status: metav1.ConditionTrue, Reason: "Deleting", Message: "deleting"},
+				{Type: "BootstrapReady", Status: metav1.ConditionUnknown, Reason: "Foo"},
+			},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "Deleting", Message: "* Deleting: deleting"},
+		},
+		{
+			name: "Unknown conditions",
+			conditions: []metav1.Condition{
+				{Type: "InfrastructureReady", Status: metav1.ConditionTrue, Reason: "Ready"},
+				{Type: "BootstrapReady", Status: metav1.ConditionUnknown, Reason: "Foo", Message: "foo"},
+			},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionUnknown, Reason: "Foo", Message: "* BootstrapReady: foo"},
+		},
+		{
+			name: "Summarize only the given condition types",
+			conditions: []metav1.Condition{
+				{Type: "InfrastructureReady", Status: metav1.ConditionTrue, Reason: "Ready"},
+				{Type: "BootstrapReady", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "foo"},
+			},
+			opts: []SummaryOption{ForConditionTypes{"InfrastructureReady", "NodeHealthy"}},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionUnknown, Reason: clusterv1.NotYetReportedV1Beta2Reason, Message: "* NodeHealthy: Condition NodeHealthy not yet reported"},
+		},
+		{
+			name: "Exclude condition types matching a regular expression",
+			conditions: []metav1.Condition{
+				{Type: "InfrastructureReady", Status: metav1.ConditionTrue, Reason: "Ready"},
+				{Type: "ExperimentalFooReady", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "foo"},
+				{Type: "BarPaused", Status: metav1.ConditionTrue, Reason: "Paused"},
+			},
+			opts: []SummaryOption{ExcludeConditionTypesMatching(regexp.MustCompile("^Experimental")), ExcludeConditionTypesMatching(regexp.MustCompile("Paused$"))},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"},
+		},
+		{
+			name: "Exclude condition types with a func, also from the given condition types",
+			conditions: []metav1.Condition{
+				{Type: "InfrastructureReady", Status: metav1.ConditionTrue, Reason: "Ready"},
+				{Type: "BootstrapReady", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "foo"},
+			},
+			opts: []SummaryOption{ForConditionTypes{"InfrastructureReady", "BootstrapReady"}, ExcludeConditionTypesFunc(func(conditionType string) bool {
+				return conditionType == "BootstrapReady"
+			})},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"},
+		},
+		{
+			name: "The target condition is not summarized",
+			conditions: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady"},
+				{Type: "InfrastructureReady", Status: metav1.ConditionTrue, Reason: "Ready"},
+			},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"},
+		},
+		{
+			name: "True if the weight of the conditions reporting the desired state reaches the threshold",
+			conditions: []metav1.Condition{
+				{Type: "MachinesReady", Status: metav1.ConditionTrue, Reason: "Ready"},
+				{Type: "EtcdHealthy", Status: metav1.ConditionTrue, Reason: "Ready"},
+				{Type: "CertificatesRenewed", Status: metav1.ConditionFalse, Reason: "Expiring", Message: "foo"},
+			},
+			opts: []SummaryOption{ConditionWeights{"MachinesReady": 5, "EtcdHealthy": 4}, SummaryThreshold(90)},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", Message: "* CertificatesRenewed: foo"},
+		},
+		{
+			name: "Computed as usual if the weight of the conditions reporting the desired state does not reach the threshold",
+			conditions: []metav1.Condition{
+				{Type: "MachinesReady", Status: metav1.ConditionTrue, Reason: "Ready"},
+				{Type: "EtcdHealthy", Status: metav1.ConditionFalse, Reason: "NotHealthy", Message: "foo"},
+				{Type: "CertificatesRenewed", Status: metav1.ConditionTrue, Reason: "Renewed"},
+			},
+			opts: []SummaryOption{ConditionWeights{"MachinesReady": 5, "EtcdHealthy": 4}, SummaryThreshold(90)},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotHealthy", Message: "* EtcdHealthy: foo"},
+		},
+		{
+			name: "Unknown conditions count as not reporting the desired state",
+			conditions: []metav1.Condition{
+				{Type: "MachinesReady", Status: metav1.ConditionTrue, Reason: "Ready"},
+				{Type: "EtcdHealthy", Status: metav1.ConditionUnknown, Reason: "Foo"},
+			},
+			opts: []SummaryOption{SummaryThreshold(60)},
+			want: &metav1.Condition{Type: "Ready", Status: metav1.ConditionUnknown, Reason: "Foo"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(NewSummaryCondition(&fakeObject{conditions: tt.conditions}, "Ready", tt.opts...)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestRegisterNegativePolarityConditionTypes(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(IsNegativePolarity(clusterv1.PausedV1Beta2Condition)).To(BeTrue())
+	g.Expect(IsNegativePolarity("TestRemediating")).To(BeFalse())
+
+	RegisterNegativePolarityConditionTypes("TestRemediating")
+	g.Expect(IsNegativePolarity("TestRemediating")).To(BeTrue())
+
+	// Summary and aggregate operations use the registry.
+	obj := &fakeObject{name: "m1", conditions: []metav1.Condition{{Type: "TestRemediating", Status: metav1.ConditionTrue, Reason: "Remediating"}}}
+	g.Expect(NewSummaryCondition(obj, "Ready").Status).To(Equal(metav1.ConditionFalse))
+
+	other := &fakeObject{name: "m2", conditions: []metav1.Condition{{Type: "TestRemediating", Status: metav1.ConditionFalse, Reason: "NotRemediating"}}}
+	aggregate := NewAggregateCondition([]Getter{obj, other}, "TestRemediating")
+	g.Expect(aggregate.Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(aggregate.Reason).To(Equal("Remediating"))
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"sigs.k8s.io/cluster-api/util"
+)
+
+// UnstructuredGetter return a Getter object that can read v1beta2 conditions from an Unstructured object.
+// Important. This method should be used only with types implementing Cluster API v1beta2 conditions,
+// stored in status.v1beta2.conditions.
+func UnstructuredGetter(u *unstructured.Unstructured) Getter {
+	return &unstructuredWrapper{Unstructured: u}
+}
+
+// UnstructuredSetter return a Setter object that can set v1beta2 conditions into an Unstructured object.
+// Important. This method should be used only with types implementing Cluster API v1beta2 conditions,
+// stored in status.v1beta2.conditions.
+func UnstructuredSetter(u *unstructured.Unstructured) Setter {
+	return &unstructuredWrapper{Unstructured: u}
+}
+
+// UnstructuredGet returns the v1beta2 condition with the given type from an Unstructured object,
+// if the condition does not exist, it returns nil.
+func UnstructuredGet(u *unstructured.Unstructured, conditionType string) *metav1.Condition {
+	return Get(UnstructuredGetter(u), conditionType)
+}
+
+// UnstructuredSet sets the given v1beta2 condition into an Unstructured object.
+func UnstructuredSet(u *unstructured.Unstructured, condition metav1.Condition, opts ...SetOption) {
+	Set(UnstructuredSetter(u), condition, opts...)
+}
+
+type unstructuredWrapper struct {
+	*unstructured.Unstructured
+}
+
+// GetV1Beta2Conditions returns the list of v1beta2 conditions from an Unstructured object.
+//
+// NOTE: Due to the constraints of JSON-unmarshal, this operation is to be considered best effort.
+// In more details:
+//   - Errors during JSON-unmarshal are ignored and a empty collection list is returned.
+//   - It's not possible to detect if the object has an empty condition list or if it does not implement conditions;
+//     in both cases the operation returns an empty slice is returned.
+func (c *unstructuredWrapper) GetV1Beta2Conditions() []metav1.Condition {
+	conditions := []metav1.Condition{}
+	if err := util.UnstructuredUnmarshalField(c.Unstructured, &conditions, "status", "v1beta2", "conditions"); err != nil {
+		return nil
+	}
+	return conditions
+}
+
+// SetV1Beta2Conditions set the v1beta2 conditions into an Unstructured object.
+func (c *unstructuredWrapper) SetV1Beta2Conditions(conditions []metav1.Condition) {
+	v := make([]interface{}, 0, len(conditions))
+	for i := range conditions {
+		m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&conditions[i])
+		if err != nil {
+			log.Log.Error(err, "Failed to convert Condition to unstructured map. This error shouldn't have occurred, please file an issue.", "groupVersionKind", c.GroupVersionKind(), "name", c.GetName(), "namespace", c.GetNamespace())
+			continue
+		}
+		v = append(v, m)
+	}
+	// unstructured.SetNestedField returns an error only if value cannot be set because one of
+	// the nesting levels is not a map[string]interface{}; this is not the case so the error should never happen here.
+	err := unstructured.SetNestedField(c.Unstructured.Object, v, "status", "v1beta2", "conditions")
+	if err != nil {
+		log.Log.Error(err, "Failed to set Conditions on unstructured object. This error shouldn't have occurred, please file an issue.", "groupVersionKind", c.GroupVersionKind(), "name", c.GetName(), "namespace", c.GetNamespace())
+	}
+}
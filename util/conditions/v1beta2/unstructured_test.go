@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestUnstructuredGetConditions(t *testing.T) {
+	g := NewWithT(t)
+
+	// GetV1Beta2Conditions returns nil for objects without v1beta2 conditions.
+	u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	g.Expect(UnstructuredGetter(u).GetV1Beta2Conditions()).To(BeNil())
+	g.Expect(UnstructuredGet(u, "Ready")).To(BeNil())
+
+	// GetV1Beta2Conditions reads conditions from status.v1beta2.conditions.
+	u = &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"v1beta2": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": "True", "reason": "Ready"},
+				},
+			},
+		},
+	}}
+	g.Expect(UnstructuredGetter(u).GetV1Beta2Conditions()).To(Equal([]metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"}}))
+	g.Expect(UnstructuredGet(u, "Ready")).ToNot(BeNil())
+}
+
+func TestUnstructuredSetConditions(t *testing.T) {
+	g := NewWithT(t)
+
+	u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	u.SetGeneration(3)
+
+	UnstructuredSet(u, metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "foo"}, TransitionHistory{})
+	UnstructuredSet(u, metav1.Condition{Type: "Available", Status: metav1.ConditionTrue, Reason: "Available"})
+
+	conditions, found, err := unstructured.NestedSlice(u.Object, "status", "v1beta2", "conditions")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(found).To(BeTrue())
+	g.Expect(conditions).To(HaveLen(2))
+
+	ready := UnstructuredGet(u, "Ready")
+	g.Expect(ready).ToNot(BeNil())
+	g.Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(ready.Message).To(Equal("foo"))
+	g.Expect(ready.ObservedGeneration).To(Equal(int64(3)))
+	g.Expect(ready.LastTransitionTime.IsZero()).To(BeFalse())
+
+	// Unstructured objects support the transition history.
+	history, err := GetTransitionHistory(u, "Ready")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(history).To(HaveLen(1))
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/distribution/reference"
+	"github.com/pkg/errors"
+)
+
+// ImageMirror defines a registry mirror; image references under Source are rewritten to the same path under Target.
+type ImageMirror struct {
+	// Source is a registry, optionally followed by a repository prefix, e.g. "registry.k8s.io" or "docker.io/library".
+	Source string
+
+	// Target is the registry, optionally followed by a repository prefix, serving the mirrored images,
+	// e.g. "mirror.example.com/registry.k8s.io".
+	Target string
+
+	// Architecture restricts the mirror to images deployed to Machines with the given CPU architecture,
+	// e.g. "arm64"; if empty the mirror applies to all the architectures.
+	Architecture string
+}
+
+// ImageRewriter rewrites image references according to a set of registry mirrors and digest pins.
+// A nil ImageRewriter returns image references unchanged.
+type ImageRewriter struct {
+	mirrors []ImageMirror
+	digests map[string]string
+}
+
+// NewImageRewriter returns an ImageRewriter for the given mirrors and digest pins.
+// Digest pins map an image reference in the form name:tag, as it is before mirrors are applied,
+// to the digest the image reference must be pinned to, e.g. "registry.k8s.io/pause:3.9" to "sha256:...".
+// When more than one mirror matches an image, mirrors for a specific architecture take precedence over the others,
+// and then the one with the longest Source is used.
+func NewImageRewriter(mirrors []ImageMirror, digests map[string]string) (*ImageRewriter, error) {
+	r := &ImageRewriter{
+		digests: map[string]string{},
+	}
+
+	for _, m := range mirrors {
+		source := strings.TrimSuffix(m.Source, "/")
+		if err := validateRepositoryPrefix(source); err != nil {
+			return nil, errors.Wrapf(err, "invalid image mirror source %q", m.Source)
+		}
+		target := strings.TrimSuffix(m.Target, "/")
+		if err := validateRepositoryPrefix(target); err != nil {
+			return nil, errors.Wrapf(err, "invalid image mirror target %q", m.Target)
+		}
+		r.mirrors = append(r.mirrors, ImageMirror{Source: source, Target: target, Architecture: m.Architecture})
+	}
+	sort.SliceStable(r.mirrors, func(i, j int) bool {
+		if (r.mirrors[i].Architecture == "") != (r.mirrors[j].Architecture == "") {
+			return r.mirrors[i].Architecture != ""
+		}
+		return len(r.mirrors[i].Source) > len(r.mirrors[j].Source)
+	})
+
+	for image, digest := range digests {
+		named, err := reference.ParseNormalizedNamed(image)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid image %q in digest pins", image)
+		}
+		tagged, ok := named.(reference.NamedTagged)
+		if !ok {
+			return nil, errors.Errorf("invalid image %q in digest pins: image must be tagged", image)
+		}
+		if _, isCanonical := named.(reference.Canonical); isCanonical {
+			return nil, errors.Errorf("invalid image %q in digest pins: image must not have a digest", image)
+		}
+		if err := ValidateImageReference(named.Name() + "@" + digest); err != nil {
+			return nil, errors.Wrapf(err, "invalid digest %q for image %q in digest pins", digest, image)
+		}
+		r.digests[tagged.String()] = digest
+	}
+	return r, nil
+}
+
+// Rewrite returns the image reference rewritten according to the mirrors and digest pins of the ImageRewriter.
+// If neither a mirror nor a digest pin applies, the image reference is returned unchanged; otherwise the result
+// is a fully qualified image reference which is validated before being returned.
+// Mirrors for a specific architecture are not applied; use RewriteForArchitecture for images deployed to Machines
+// with a known architecture.
+func (r *ImageRewriter) Rewrite(image string) (string, error) {
+	return r.RewriteForArchitecture(image, "")
+}
+
+// RewriteForArchitecture is like Rewrite, but it also applies the mirrors for the given CPU architecture.
+func (r *ImageRewriter) RewriteForArchitecture(image, architecture string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse image %q", image)
+	}
+	if r == nil {
+		return image, nil
+	}
+
+	name, mirrored := r.rewriteName(named.Name(), architecture)
+
+	tag := ""
+	if tagged, ok := named.(reference.Tagged); ok {
+		tag = tagged.Tag()
+	}
+	digest := ""
+	if digested, ok := named.(reference.Digested); ok {
+		digest = digested.Digest().String()
+	}
+	pinned := false
+	if tag != "" && digest == "" {
+		digest, pinned = r.digests[named.Name()+":"+tag]
+	}
+
+	if !mirrored && !pinned {
+		return image, nil
+	}
+
+	rewritten := name
+	if tag != "" {
+		rewritten += ":" + tag
+	}
+	if digest != "" {
+		rewritten += "@" + digest
+	}
+	if err := ValidateImageReference(rewritten); err != nil {
+		return "", errors.Wrapf(err, "invalid image %q rewritten from %q", rewritten, image)
+	}
+	return rewritten, nil
+}
+
+// RewriteRepository returns the image repository, e.g. the imageRepository of a kubeadm ClusterConfiguration,
+// rewritten according to the mirrors of the ImageRewriter; it is returned unchanged if no mirror applies.
+// Mirrors for a specific architecture are not applied.
+func (r *ImageRewriter) RewriteRepository(repository string) (string, error) {
+	return r.RewriteRepositoryForArchitecture(repository, "")
+}
+
+// RewriteRepositoryForArchitecture is like RewriteRepository, but it also applies the mirrors for the given CPU architecture.
+func (r *ImageRewriter) RewriteRepositoryForArchitecture(repository, architecture string) (string, error) {
+	repository = strings.TrimSuffix(repository, "/")
+	if err := validateRepositoryPrefix(repository); err != nil {
+		return "", errors.Wrapf(err, "invalid image repository %q", repository)
+	}
+	if r == nil {
+		return repository, nil
+	}
+	rewritten, _ := r.rewriteName(repository, architecture)
+	return rewritten, nil
+}
+
+func (r *ImageRewriter) rewriteName(name, architecture string) (string, bool) {
+	for _, m := range r.mirrors {
+		if m.Architecture != "" && m.Architecture != architecture {
+			continue
+		}
+		if name == m.Source || strings.HasPrefix(name, m.Source+"/") {
+			return m.Target + strings.TrimPrefix(name, m.Source), true
+		}
+	}
+	return name, false
+}
+
+// ValidateImageReference returns an error if the image is not a valid image reference.
+func ValidateImageReference(image string) error {
+	if _, err := reference.ParseNormalizedNamed(image); err != nil {
+		return errors.Wrapf(err, "invalid image reference %q", image)
+	}
+	return nil
+}
+
+// validateRepositoryPrefix validates a registry, optionally followed by a repository prefix.
+func validateRepositoryPrefix(prefix string) error {
+	if prefix == "" {
+		return errors.New("must not be empty")
+	}
+	named, err := reference.ParseNamed(prefix + "/image")
+	if err != nil {
+		return errors.Wrap(err, "must be a registry optionally followed by a repository prefix")
+	}
+	if reference.Domain(named) == "" {
+		return errors.New("must start with a registry")
+	}
+	return nil
+}
+
+var (
+	imageRewriterLock sync.RWMutex
+	imageRewriter     *ImageRewriter
+)
+
+// SetImageRewriter sets the ImageRewriter used to rewrite the images Cluster API deploys to workload clusters;
+// passing nil disables rewriting.
+func SetImageRewriter(r *ImageRewriter) {
+	imageRewriterLock.Lock()
+	defer imageRewriterLock.Unlock()
+	imageRewriter = r
+}
+
+// GetImageRewriter returns the ImageRewriter set by SetImageRewriter; it can be nil.
+func GetImageRewriter() *ImageRewriter {
+	imageRewriterLock.RLock()
+	defer imageRewriterLock.RUnlock()
+	return imageRewriter
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+const testDigest = "sha256:4bcdcbb4a4a6a3a9d4d2a5b84c48d2b5f1ab0a1e1d1c5b4a4a6a3a9d4d2a5b84"
+
+func TestNewImageRewriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		mirrors []ImageMirror
+		digests map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "valid mirrors and digest pins",
+			mirrors: []ImageMirror{{Source: "registry.k8s.io", Target: "mirror.example.com/k8s/"}},
+			digests: map[string]string{"registry.k8s.io/pause:3.9": testDigest},
+		},
+		{
+			name:    "mirror source without registry",
+			mirrors: []ImageMirror{{Source: "library", Target: "mirror.example.com"}},
+			wantErr: true,
+		},
+		{
+			name:    "empty mirror target",
+			mirrors: []ImageMirror{{Source: "registry.k8s.io", Target: ""}},
+			wantErr: true,
+		},
+		{
+			name:    "digest pin for an untagged image",
+			digests: map[string]string{"registry.k8s.io/pause": testDigest},
+			wantErr: true,
+		},
+		{
+			name:    "invalid digest",
+			digests: map[string]string{"registry.k8s.io/pause:3.9": "sha256:foo"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			_, err := NewImageRewriter(tt.mirrors, tt.digests)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
+
+func TestImageRewriterRewrite(t *testing.T) {
+	g := NewWithT(t)
+
+	r, err := NewImageRewriter(
+		[]ImageMirror{
+			{Source: "registry.k8s.io", Target: "mirror.example.com/k8s"},
+			{Source: "registry.k8s.io/coredns", Target: "dns.example.com"},
+			{Source: "docker.io/library", Target: "mirror.example.com/hub"},
+		},
+		map[string]string{
+			"registry.k8s.io/pause:3.9": testDigest,
+		},
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	tests := []struct {
+		name    string
+		image   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "image under a mirrored registry",
+			image: "registry.k8s.io/kube-proxy:v1.29.0",
+			want:  "mirror.example.com/k8s/kube-proxy:v1.29.0",
+		},
+		{
+			name:  "the longest matching source wins",
+			image: "registry.k8s.io/coredns/coredns:v1.11.1",
+			want:  "dns.example.com/coredns:v1.11.1",
+		},
+		{
+			name:  "source only matches whole path components",
+			image: "registry.k8s.io/corednsfoo:v1.11.1",
+			want:  "mirror.example.com/k8s/corednsfoo:v1.11.1",
+		},
+		{
+			name:  "familiar images are normalized before matching",
+			image: "nginx:1.25",
+			want:  "mirror.example.com/hub/nginx:1.25",
+		},
+		{
+			name:  "digest is preserved",
+			image: "registry.k8s.io/etcd:3.5.10-0@" + testDigest,
+			want:  "mirror.example.com/k8s/etcd:3.5.10-0@" + testDigest,
+		},
+		{
+			name:  "pinned image is mirrored and pinned",
+			image: "registry.k8s.io/pause:3.9",
+			want:  "mirror.example.com/k8s/pause:3.9@" + testDigest,
+		},
+		{
+			name:  "image not matching any mirror is returned unchanged",
+			image: "quay.io/foo/bar:v1",
+			want:  "quay.io/foo/bar:v1",
+		},
+		{
+			name:    "invalid image",
+			image:   "registry.k8s.io/Kube-Proxy:v1.29.0",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := r.Rewrite(tt.image)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestImageRewriterRewriteRepository(t *testing.T) {
+	g := NewWithT(t)
+
+	r, err := NewImageRewriter([]ImageMirror{{Source: "registry.k8s.io", Target: "mirror.example.com/k8s"}}, nil)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(r.RewriteRepository("registry.k8s.io")).To(Equal("mirror.example.com/k8s"))
+	g.Expect(r.RewriteRepository("registry.k8s.io/sig-storage/")).To(Equal("mirror.example.com/k8s/sig-storage"))
+	g.Expect(r.RewriteRepository("quay.io/foo")).To(Equal("quay.io/foo"))
+	_, err = r.RewriteRepository("")
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestImageRewriterRewriteForArchitecture(t *testing.T) {
+	g := NewWithT(t)
+
+	r, err := NewImageRewriter(
+		[]ImageMirror{
+			{Source: "registry.k8s.io/coredns", Target: "dns.example.com"},
+			{Source: "registry.k8s.io", Target: "arm64.example.com/k8s", Architecture: "arm64"},
+		},
+		nil,
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// Mirrors for a specific architecture take precedence, even if their source is shorter.
+	g.Expect(r.RewriteForArchitecture("registry.k8s.io/coredns/coredns:v1.11.1", "arm64")).To(Equal("arm64.example.com/k8s/coredns/coredns:v1.11.1"))
+	g.Expect(r.RewriteForArchitecture("registry.k8s.io/coredns/coredns:v1.11.1", "amd64")).To(Equal("dns.example.com/coredns:v1.11.1"))
+	g.Expect(r.Rewrite("registry.k8s.io/kube-proxy:v1.29.0")).To(Equal("registry.k8s.io/kube-proxy:v1.29.0"))
+	g.Expect(r.RewriteRepositoryForArchitecture("registry.k8s.io", "arm64")).To(Equal("arm64.example.com/k8s"))
+	g.Expect(r.RewriteRepository("registry.k8s.io")).To(Equal("registry.k8s.io"))
+}
+
+func TestNilImageRewriter(t *testing.T) {
+	g := NewWithT(t)
+
+	var r *ImageRewriter
+	g.Expect(r.Rewrite("registry.k8s.io/kube-proxy:v1.29.0")).To(Equal("registry.k8s.io/kube-proxy:v1.29.0"))
+	g.Expect(r.RewriteRepository("registry.k8s.io")).To(Equal("registry.k8s.io"))
+}
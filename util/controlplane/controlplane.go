@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controlplane implements helpers for control plane providers, and in particular
+// for externally managed control planes such as AKS, EKS or GKE.
+package controlplane
+
+import (
+	"github.com/blang/semver/v4"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+const (
+	// ManagedControlPlaneProvisioningReason surfaces when the managed service is still creating the control plane.
+	ManagedControlPlaneProvisioningReason = "ManagedControlPlaneProvisioning"
+
+	// ManagedControlPlaneUnavailableReason surfaces when the managed service reports the control plane as
+	// not available, e.g. while it is being updated or repaired.
+	ManagedControlPlaneUnavailableReason = "ManagedControlPlaneUnavailable"
+)
+
+// IsInitialized returns true if the control plane reports status.initialized.
+// Externally managed control planes not reporting status.initialized are considered initialized
+// once they report status.ready, given there are no Machines to wait for.
+func IsInitialized(controlPlane *unstructured.Unstructured) (bool, error) {
+	initialized, found, err := unstructured.NestedBool(controlPlane.Object, "status", "initialized")
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to determine %v %q initialized", controlPlane.GroupVersionKind(), controlPlane.GetName())
+	}
+	if found || !util.IsExternalManagedControlPlane(controlPlane) {
+		return initialized, nil
+	}
+
+	ready, _, err := unstructured.NestedBool(controlPlane.Object, "status", "ready")
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to determine %v %q readiness", controlPlane.GroupVersionKind(), controlPlane.GetName())
+	}
+	return ready, nil
+}
+
+// ManagedStatus is the state of an externally managed control plane as reported by the managed service.
+type ManagedStatus struct {
+	// Provisioned is true once the managed service has created the control plane,
+	// i.e. its API server has been reachable at least once.
+	Provisioned bool
+
+	// Available is true if the managed service reports the control plane as available.
+	Available bool
+
+	// Version is the Kubernetes version reported by the managed service, e.g. 1.29 or v1.29.1-gke.1589018.
+	Version string
+
+	// Message explains why the control plane is not available, if any.
+	Message string
+}
+
+// Status are the status fields of the control plane contract for externally managed control planes.
+type Status struct {
+	// ExternalManagedControlPlane is always true for externally managed control planes.
+	ExternalManagedControlPlane bool
+
+	// Initialized is true once the control plane has been provisioned; it never goes back to false.
+	Initialized bool
+
+	// Ready is true if the control plane has been provisioned and it is available.
+	Ready bool
+
+	// Version is the Kubernetes version reported by the managed service, normalized to the vMAJOR.MINOR.PATCH format,
+	// or empty if the managed service did not report a valid version.
+	Version string
+}
+
+// ComputeStatus returns the status fields of the control plane contract from the state reported by the managed service
+// and the current status of the control plane.
+func ComputeStatus(current Status, managed ManagedStatus) Status {
+	status := Status{
+		ExternalManagedControlPlane: true,
+		Initialized:                 current.Initialized || managed.Provisioned,
+		Ready:                       managed.Provisioned && managed.Available,
+		Version:                     current.Version,
+	}
+	if v, err := semver.ParseTolerant(managed.Version); err == nil {
+		status.Version = "v" + semver.Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}.String()
+	}
+	return status
+}
+
+// SetReadyCondition sets the Ready condition of the control plane from the state reported by the managed service.
+func SetReadyCondition(to conditions.Setter, managed ManagedStatus) {
+	switch {
+	case !managed.Provisioned:
+		conditions.MarkFalse(to, clusterv1.ReadyCondition, ManagedControlPlaneProvisioningReason, clusterv1.ConditionSeverityInfo, managed.Message)
+	case !managed.Available:
+		conditions.MarkFalse(to, clusterv1.ReadyCondition, ManagedControlPlaneUnavailableReason, clusterv1.ConditionSeverityWarning, managed.Message)
+	default:
+		conditions.MarkTrue(to, clusterv1.ReadyCondition)
+	}
+}
+
+// CheckReportedVersion returns an error if the version reported by the control plane is newer than the desired version
+// in spec.version, e.g. because the managed service upgraded the control plane automatically.
+// Only major, minor and patch are compared, given managed services append their own build identifiers.
+func CheckReportedVersion(desiredVersion, reportedVersion string) error {
+	desired, err := semver.ParseTolerant(desiredVersion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse desired version %q", desiredVersion)
+	}
+	reported, err := semver.ParseTolerant(reportedVersion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse reported version %q", reportedVersion)
+	}
+	desired.Pre, desired.Build = nil, nil
+	reported.Pre, reported.Build = nil, nil
+	if reported.GT(desired) {
+		return errors.Errorf("reported version %s is newer than the desired version %s", reportedVersion, desiredVersion)
+	}
+	return nil
+}
+
+// CheckVersionSkew returns an error if a kubelet at the given version can't join a control plane at the given version
+// according to the Kubernetes version skew policy: the kubelet must not be newer than the control plane, and it can be
+// up to three minor versions older starting with Kubernetes v1.28, two before.
+func CheckVersionSkew(controlPlaneVersion, kubeletVersion string) error {
+	cp, err := semver.ParseTolerant(controlPlaneVersion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse control plane version %q", controlPlaneVersion)
+	}
+	kubelet, err := semver.ParseTolerant(kubeletVersion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse kubelet version %q", kubeletVersion)
+	}
+
+	if cp.Major != kubelet.Major || kubelet.Minor > cp.Minor {
+		return errors.Errorf("kubelet version %s must not be newer than control plane version %s", kubeletVersion, controlPlaneVersion)
+	}
+	maxSkew := uint64(2)
+	if cp.Major == 1 && cp.Minor >= 28 {
+		maxSkew = 3
+	}
+	if cp.Minor-kubelet.Minor > maxSkew {
+		return errors.Errorf("kubelet version %s must not be older than control plane version %s by more than %d minor versions", kubeletVersion, controlPlaneVersion, maxSkew)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplane
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestIsInitialized(t *testing.T) {
+	tests := []struct {
+		name   string
+		status map[string]interface{}
+		want   bool
+	}{
+		{
+			name:   "initialized",
+			status: map[string]interface{}{"initialized": true},
+			want:   true,
+		},
+		{
+			name:   "ready but not initialized",
+			status: map[string]interface{}{"initialized": false, "ready": true},
+			want:   false,
+		},
+		{
+			name:   "ready without initialized",
+			status: map[string]interface{}{"ready": true},
+			want:   false,
+		},
+		{
+			name:   "externally managed and ready without initialized",
+			status: map[string]interface{}{"ready": true, "externalManagedControlPlane": true},
+			want:   true,
+		},
+		{
+			name:   "externally managed and not ready without initialized",
+			status: map[string]interface{}{"externalManagedControlPlane": true},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := IsInitialized(&unstructured.Unstructured{Object: map[string]interface{}{"status": tt.status}})
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestComputeStatus(t *testing.T) {
+	g := NewWithT(t)
+
+	status := ComputeStatus(Status{}, ManagedStatus{})
+	g.Expect(status).To(Equal(Status{ExternalManagedControlPlane: true}))
+
+	status = ComputeStatus(status, ManagedStatus{Provisioned: true, Available: true, Version: "1.29.1-gke.1589018"})
+	g.Expect(status).To(Equal(Status{ExternalManagedControlPlane: true, Initialized: true, Ready: true, Version: "v1.29.1"}))
+
+	// Initialized does not go back to false, and the last valid version is kept.
+	status = ComputeStatus(status, ManagedStatus{Version: "unknown"})
+	g.Expect(status).To(Equal(Status{ExternalManagedControlPlane: true, Initialized: true, Ready: false, Version: "v1.29.1"}))
+}
+
+func TestSetReadyCondition(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{}
+	SetReadyCondition(cluster, ManagedStatus{Message: "creating"})
+	g.Expect(conditions.GetReason(cluster, clusterv1.ReadyCondition)).To(Equal(ManagedControlPlaneProvisioningReason))
+	g.Expect(conditions.GetMessage(cluster, clusterv1.ReadyCondition)).To(Equal("creating"))
+
+	SetReadyCondition(cluster, ManagedStatus{Provisioned: true, Message: "updating"})
+	g.Expect(conditions.GetReason(cluster, clusterv1.ReadyCondition)).To(Equal(ManagedControlPlaneUnavailableReason))
+	g.Expect(conditions.GetSeverity(cluster, clusterv1.ReadyCondition)).To(HaveValue(Equal(clusterv1.ConditionSeverityWarning)))
+
+	SetReadyCondition(cluster, ManagedStatus{Provisioned: true, Available: true})
+	g.Expect(conditions.IsTrue(cluster, clusterv1.ReadyCondition)).To(BeTrue())
+}
+
+func TestCheckReportedVersion(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(CheckReportedVersion("v1.29.1", "1.29.1-eks-508b6b3")).To(Succeed())
+	g.Expect(CheckReportedVersion("v1.29.1", "v1.29.0")).To(Succeed())
+	g.Expect(CheckReportedVersion("v1.29.1", "v1.29.2")).ToNot(Succeed())
+	g.Expect(CheckReportedVersion("v1.29.1", "v1.30.0")).ToNot(Succeed())
+	g.Expect(CheckReportedVersion("v1.29.1", "invalid")).ToNot(Succeed())
+}
+
+func TestCheckVersionSkew(t *testing.T) {
+	tests := []struct {
+		controlPlaneVersion string
+		kubeletVersion      string
+		wantErr             bool
+	}{
+		{controlPlaneVersion: "v1.29.0", kubeletVersion: "v1.29.0"},
+		{controlPlaneVersion: "v1.29.0", kubeletVersion: "v1.26.5"},
+		{controlPlaneVersion: "v1.29.0", kubeletVersion: "v1.25.0", wantErr: true},
+		{controlPlaneVersion: "v1.27.0", kubeletVersion: "v1.25.0"},
+		{controlPlaneVersion: "v1.27.0", kubeletVersion: "v1.24.0", wantErr: true},
+		{controlPlaneVersion: "v1.29.0", kubeletVersion: "v1.30.0", wantErr: true},
+		{controlPlaneVersion: "1.29", kubeletVersion: "v1.29.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.controlPlaneVersion+"/"+tt.kubeletVersion, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := CheckVersionSkew(tt.controlPlaneVersion, tt.kubeletVersion)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package failuredomains
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/collections"
+)
+
+// WeightAttribute is the failure domain attribute infrastructure providers can set to hint the relative
+// capacity of a failure domain; it must be a positive integer and defaults to 1.
+const WeightAttribute = "cluster.x-k8s.io/weight"
+
+// Selector selects the failure domains machines are created in and deleted from.
+type Selector interface {
+	// PickForScaleUp returns the failure domain a new machine should be created in; key identifies the new machine
+	// for selectors placing machines deterministically, so it must be stable, e.g. across rollouts.
+	PickForScaleUp(ctx context.Context, failureDomains clusterv1.FailureDomains, machines collections.Machines, key string) *string
+
+	// PickForScaleDown returns the failure domain a machine should be deleted from; groupMachines are all
+	// the machines of the group being scaled down, while the returned failure domain must contain at least one of machines.
+	PickForScaleDown(ctx context.Context, failureDomains clusterv1.FailureDomains, groupMachines, machines collections.Machines) *string
+}
+
+// NewBalancedSelector returns a Selector spreading machines so that all the failure domains have
+// the same number of machines, regardless of their capacity.
+func NewBalancedSelector() Selector {
+	return balancedSelector{}
+}
+
+type balancedSelector struct{}
+
+func (balancedSelector) PickForScaleUp(ctx context.Context, failureDomains clusterv1.FailureDomains, machines collections.Machines, _ string) *string {
+	return PickFewest(ctx, failureDomains, machines)
+}
+
+func (balancedSelector) PickForScaleDown(ctx context.Context, failureDomains clusterv1.FailureDomains, groupMachines, machines collections.Machines) *string {
+	return PickMost(ctx, failureDomains, groupMachines, machines)
+}
+
+// NewWeightedSelector returns a Selector spreading machines proportionally to the capacity of each failure domain,
+// as hinted by the WeightAttribute set by the infrastructure provider.
+func NewWeightedSelector() Selector {
+	return weightedSelector{}
+}
+
+type weightedSelector struct{}
+
+func (weightedSelector) PickForScaleUp(ctx context.Context, failureDomains clusterv1.FailureDomains, machines collections.Machines, _ string) *string {
+	aggregations := pick(ctx, failureDomains, machines)
+	if len(aggregations) == 0 {
+		return nil
+	}
+	weights := failureDomainWeights(ctx, failureDomains)
+	// Pick the failure domain which would have the lowest load after adding the new machine.
+	sort.Slice(aggregations, func(i, j int) bool {
+		li := float64(aggregations[i].count+1) / weights[aggregations[i].id]
+		lj := float64(aggregations[j].count+1) / weights[aggregations[j].id]
+		if li != lj {
+			return li < lj
+		}
+		return aggregations[i].id < aggregations[j].id
+	})
+	return ptr.To(aggregations[0].id)
+}
+
+func (weightedSelector) PickForScaleDown(ctx context.Context, failureDomains clusterv1.FailureDomains, groupMachines, machines collections.Machines) *string {
+	aggregations := pick(ctx, failureDomains, groupMachines)
+	if len(aggregations) == 0 {
+		return nil
+	}
+	weights := failureDomainWeights(ctx, failureDomains)
+	// Order failure domains by decreasing load.
+	sort.Slice(aggregations, func(i, j int) bool {
+		li := float64(aggregations[i].count) / weights[aggregations[i].id]
+		lj := float64(aggregations[j].count) / weights[aggregations[j].id]
+		if li != lj {
+			return li > lj
+		}
+		return aggregations[i].id < aggregations[j].id
+	})
+	for _, fd := range aggregations {
+		if len(machines.Filter(collections.InFailureDomains(ptr.To(fd.id)))) > 0 {
+			return ptr.To(fd.id)
+		}
+	}
+	return nil
+}
+
+// failureDomainWeights returns the weight of each failure domain, defaulting to 1 when the
+// WeightAttribute is not set or invalid.
+func failureDomainWeights(ctx context.Context, failureDomains clusterv1.FailureDomains) map[string]float64 {
+	log := ctrl.LoggerFrom(ctx)
+
+	weights := make(map[string]float64, len(failureDomains))
+	for id, fd := range failureDomains {
+		weights[id] = 1
+		value, ok := fd.Attributes[WeightAttribute]
+		if !ok {
+			continue
+		}
+		weight, err := strconv.Atoi(value)
+		if err != nil || weight <= 0 {
+			log.Info(fmt.Sprintf("Ignoring invalid %s attribute %q for failure domain %q, it must be a positive integer", WeightAttribute, value, id))
+			continue
+		}
+		weights[id] = float64(weight)
+	}
+	return weights
+}
+
+// NewStickySelector returns a Selector which always places a machine with a given key in the same failure domain,
+// as long as the set of failure domains does not change, using rendezvous hashing on the key; machines with different
+// keys are not guaranteed to be spread across failure domains.
+// Machines without a key are placed like with the balanced Selector; scale down is always balanced.
+func NewStickySelector() Selector {
+	return stickySelector{}
+}
+
+type stickySelector struct {
+	balancedSelector
+}
+
+func (s stickySelector) PickForScaleUp(ctx context.Context, failureDomains clusterv1.FailureDomains, machines collections.Machines, key string) *string {
+	if key == "" {
+		return s.balancedSelector.PickForScaleUp(ctx, failureDomains, machines, key)
+	}
+
+	var (
+		selected  *string
+		bestScore uint64
+	)
+	for id := range failureDomains {
+		sum := sha256.Sum256([]byte(id + "/" + key))
+		score := binary.BigEndian.Uint64(sum[:8])
+		if selected == nil || score > bestScore || (score == bestScore && id < *selected) {
+			selected = ptr.To(id)
+			bestScore = score
+		}
+	}
+	return selected
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package failuredomains
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/collections"
+)
+
+func machinesIn(fds ...string) collections.Machines {
+	machines := collections.New()
+	for i, fd := range fds {
+		machines.Insert(&clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("m%d", i)},
+			Spec:       clusterv1.MachineSpec{FailureDomain: ptr.To(fd)},
+		})
+	}
+	return machines
+}
+
+func TestBalancedSelector(t *testing.T) {
+	g := NewWithT(t)
+
+	fds := clusterv1.FailureDomains{"a": {}, "b": {}}
+	s := NewBalancedSelector()
+
+	g.Expect(s.PickForScaleUp(ctx, fds, machinesIn("a", "a", "b"), "")).To(Equal(ptr.To("b")))
+	machines := machinesIn("a", "a", "b")
+	g.Expect(s.PickForScaleDown(ctx, fds, machines, machines)).To(Equal(ptr.To("a")))
+	g.Expect(s.PickForScaleUp(ctx, nil, machines, "")).To(BeNil())
+}
+
+func TestWeightedSelector(t *testing.T) {
+	fds := clusterv1.FailureDomains{
+		"large": {Attributes: map[string]string{WeightAttribute: "3"}},
+		"small": {},
+		"bogus": {Attributes: map[string]string{WeightAttribute: "-1"}},
+	}
+	s := NewWeightedSelector()
+
+	t.Run("scale up fills failure domains proportionally to their weight", func(t *testing.T) {
+		g := NewWithT(t)
+
+		machines := collections.New()
+		counts := map[string]int{}
+		for i := 0; i < 10; i++ {
+			fd := s.PickForScaleUp(ctx, fds, machines, "")
+			g.Expect(fd).ToNot(BeNil())
+			counts[*fd]++
+			machines.Insert(&clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("m%d", i)},
+				Spec:       clusterv1.MachineSpec{FailureDomain: fd},
+			})
+		}
+		g.Expect(counts).To(Equal(map[string]int{"large": 6, "small": 2, "bogus": 2}))
+	})
+
+	t.Run("scale down picks the most loaded failure domain", func(t *testing.T) {
+		g := NewWithT(t)
+
+		// large has load 3/3, small has load 2/1.
+		machines := machinesIn("large", "large", "large", "small", "small")
+		g.Expect(s.PickForScaleDown(ctx, fds, machines, machines)).To(Equal(ptr.To("small")))
+
+		// Only machines in large are eligible.
+		g.Expect(s.PickForScaleDown(ctx, fds, machines, machinesIn("large"))).To(Equal(ptr.To("large")))
+	})
+}
+
+func TestStickySelector(t *testing.T) {
+	g := NewWithT(t)
+
+	fds := clusterv1.FailureDomains{"a": {}, "b": {}, "c": {}}
+	s := NewStickySelector()
+
+	picked := map[string]int{}
+	for i := 0; i < 30; i++ {
+		name := fmt.Sprintf("machine-%d", i)
+		fd := s.PickForScaleUp(ctx, fds, nil, name)
+		g.Expect(fd).ToNot(BeNil())
+		// The same name is always placed in the same failure domain, regardless of the existing machines.
+		g.Expect(s.PickForScaleUp(ctx, fds, machinesIn(*fd, *fd, *fd), name)).To(Equal(fd))
+		picked[*fd]++
+	}
+	g.Expect(picked).To(HaveLen(3))
+
+	// Without a name, machines are balanced.
+	g.Expect(s.PickForScaleUp(ctx, fds, machinesIn("a", "b"), "")).To(Equal(ptr.To("c")))
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package finalizers implements finalizer helper functions.
+package finalizers
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+func init() {
+	// Register the metrics at the controller-runtime metrics registry.
+	ctrlmetrics.Registry.MustRegister(removalDuration)
+}
+
+// removalDuration reports the time elapsed between the deletion of an object and the removal of a finalizer.
+var removalDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Subsystem: "capi",
+	Name:      "finalizer_removal_duration_seconds",
+	Help:      "Time elapsed between the deletion timestamp of an object and the removal of a finalizer, broken down by finalizer.",
+	Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200, 1800, 3600, 7200},
+}, []string{"finalizer"})
+
+// EnsureFinalizer adds the finalizer to the object if the object doesn't have a deletionTimestamp set
+// and if the finalizer is not already set; the object is patched right away.
+//
+// This util is meant to be called in reconcilers directly after the reconciled object has been retrieved,
+// and before any other change is made to the object or to other resources; if the finalizer has been added,
+// reconcilers should return and wait for the next reconcile, so it is guaranteed that the finalizer is
+// persisted before the first reconcile acts on behalf of the object, avoiding the race condition between init and delete.
+func EnsureFinalizer(ctx context.Context, c client.Client, o client.Object, finalizer string) (finalizerAdded bool, err error) {
+	// Finalizers can only be added when the deletionTimestamp is not set.
+	if !o.GetDeletionTimestamp().IsZero() {
+		return false, nil
+	}
+	if controllerutil.ContainsFinalizer(o, finalizer) {
+		return false, nil
+	}
+
+	original, ok := o.DeepCopyObject().(client.Object)
+	if !ok {
+		return false, errors.Errorf("failed to add finalizer %q to %s: failed to copy object", finalizer, klog.KObj(o))
+	}
+	controllerutil.AddFinalizer(o, finalizer)
+	if err := c.Patch(ctx, o, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+		return false, errors.Wrapf(err, "failed to add finalizer %q to %s", finalizer, klog.KObj(o))
+	}
+	return true, nil
+}
+
+// RemoveFinalizer removes the finalizer from the object if it is set; the object is patched right away.
+// A NotFound error is ignored, given that the object has been already deleted.
+// The time elapsed since the object has been deleted is recorded in the finalizer removal latency metric.
+//
+// NOTE: Reconcilers patching the object with a patch.Helper at the end of the reconcile should instead
+// remove the finalizer in memory, in order to not patch an object which is possibly already gone.
+func RemoveFinalizer(ctx context.Context, c client.Client, o client.Object, finalizer string) (finalizerRemoved bool, err error) {
+	if !controllerutil.ContainsFinalizer(o, finalizer) {
+		return false, nil
+	}
+
+	original, ok := o.DeepCopyObject().(client.Object)
+	if !ok {
+		return false, errors.Errorf("failed to remove finalizer %q from %s: failed to copy object", finalizer, klog.KObj(o))
+	}
+	controllerutil.RemoveFinalizer(o, finalizer)
+	if err := c.Patch(ctx, o, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil && !apierrors.IsNotFound(err) {
+		return false, errors.Wrapf(err, "failed to remove finalizer %q from %s", finalizer, klog.KObj(o))
+	}
+
+	if deletionTimestamp := o.GetDeletionTimestamp(); !deletionTimestamp.IsZero() {
+		removalDuration.WithLabelValues(finalizer).Observe(time.Since(deletionTimestamp.Time).Seconds())
+	}
+	return true, nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package finalizers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestEnsureFinalizer(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	_ = clusterv1.AddToScheme(scheme)
+
+	t.Run("adds and persists the finalizer", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault}}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+
+		added, err := EnsureFinalizer(ctx, c, cluster, clusterv1.ClusterFinalizer)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(added).To(BeTrue())
+
+		actual := &clusterv1.Cluster{}
+		g.Expect(c.Get(ctx, client.ObjectKeyFromObject(cluster), actual)).To(Succeed())
+		g.Expect(actual.Finalizers).To(ConsistOf(clusterv1.ClusterFinalizer))
+
+		added, err = EnsureFinalizer(ctx, c, actual, clusterv1.ClusterFinalizer)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(added).To(BeFalse())
+	})
+
+	t.Run("does not add the finalizer to deleting objects", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{
+			Name:              "deleting",
+			Namespace:         metav1.NamespaceDefault,
+			DeletionTimestamp: &metav1.Time{Time: metav1.Now().Time},
+		}}
+		added, err := EnsureFinalizer(ctx, fake.NewClientBuilder().WithScheme(scheme).Build(), cluster, clusterv1.ClusterFinalizer)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(added).To(BeFalse())
+		g.Expect(cluster.Finalizers).To(BeEmpty())
+	})
+}
+
+func TestRemoveFinalizer(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	_ = clusterv1.AddToScheme(scheme)
+
+	t.Run("removes and persists the finalizer", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{
+			Name:       "test",
+			Namespace:  metav1.NamespaceDefault,
+			Finalizers: []string{clusterv1.ClusterFinalizer, "other"},
+		}}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+
+		removed, err := RemoveFinalizer(ctx, c, cluster, clusterv1.ClusterFinalizer)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(removed).To(BeTrue())
+
+		actual := &clusterv1.Cluster{}
+		g.Expect(c.Get(ctx, client.ObjectKeyFromObject(cluster), actual)).To(Succeed())
+		g.Expect(actual.Finalizers).To(ConsistOf("other"))
+
+		removed, err = RemoveFinalizer(ctx, c, actual, clusterv1.ClusterFinalizer)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(removed).To(BeFalse())
+	})
+
+	t.Run("ignores objects already gone", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{
+			Name:            "gone",
+			Namespace:       metav1.NamespaceDefault,
+			ResourceVersion: "1",
+			Finalizers:      []string{clusterv1.ClusterFinalizer},
+		}}
+		removed, err := RemoveFinalizer(ctx, fake.NewClientBuilder().WithScheme(scheme).Build(), cluster, clusterv1.ClusterFinalizer)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(removed).To(BeTrue())
+		g.Expect(cluster.Finalizers).To(BeEmpty())
+	})
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api/util/certs"
+)
+
+// CASignerOptions has the options to configure the signer issuing the cluster certificate authorities.
+type CASignerOptions struct {
+	CertFile   string
+	KeyFile    string
+	SignerName string
+	Approve    bool
+}
+
+// AddCASignerOptions adds the cluster CA signer flags to the flag set.
+func AddCASignerOptions(fs *pflag.FlagSet, options *CASignerOptions) {
+	fs.StringVar(&options.CertFile, "cluster-ca-signer-cert-file", "",
+		"Path of a file containing the PEM encoded certificate of a CA issuing the cluster certificate authorities as intermediate CAs, "+
+			"so the issuing CA key is never stored in the management cluster. If omitted, self-signed cluster certificate authorities are generated.")
+
+	fs.StringVar(&options.KeyFile, "cluster-ca-signer-key-file", "",
+		"Path of a file containing the PEM encoded private key of the CA set by --cluster-ca-signer-cert-file. "+
+			"The file should be provided from outside of the management cluster, e.g. by a secret store CSI driver.")
+
+	fs.StringVar(&options.SignerName, "cluster-ca-signer-name", "",
+		"Name of a Kubernetes CertificateSigningRequest signer issuing the cluster certificate authorities as intermediate CAs, "+
+			"e.g. clusterissuers.cert-manager.io/<name> for a cert-manager ClusterIssuer backed by Vault PKI or a cloud CA. "+
+			"The controller must be allowed to create, get and delete CertificateSigningRequests. Cannot be used with --cluster-ca-signer-cert-file.")
+
+	fs.BoolVar(&options.Approve, "cluster-ca-signer-approve", false,
+		"If true, the CertificateSigningRequests for --cluster-ca-signer-name are approved by the controller, "+
+			"which must be allowed to approve requests for the signer; otherwise they must be approved by an external approver.")
+}
+
+// GetCASigner returns the certs.Signer configured by the given options,
+// or nil if the cluster certificate authorities are self-signed.
+// The client is used to create CertificateSigningRequests if a signer name is set.
+func GetCASigner(options CASignerOptions, c client.Client) (certs.Signer, error) {
+	if options.SignerName != "" {
+		if options.CertFile != "" || options.KeyFile != "" {
+			return nil, errors.New("--cluster-ca-signer-name cannot be used with --cluster-ca-signer-cert-file and --cluster-ca-signer-key-file")
+		}
+		return certs.NewCSRSigner(c, options.SignerName, options.Approve), nil
+	}
+	if options.Approve {
+		return nil, errors.New("--cluster-ca-signer-approve requires --cluster-ca-signer-name")
+	}
+	if options.CertFile == "" && options.KeyFile == "" {
+		return nil, nil
+	}
+	if options.CertFile == "" || options.KeyFile == "" {
+		return nil, errors.New("--cluster-ca-signer-cert-file and --cluster-ca-signer-key-file must be set together")
+	}
+
+	certData, err := os.ReadFile(options.CertFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read cluster CA signer certificate file %q", options.CertFile)
+	}
+	caCert, err := certs.DecodeCertPEM(certData)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode cluster CA signer certificate file %q", options.CertFile)
+	}
+	if caCert == nil {
+		return nil, errors.Errorf("cluster CA signer certificate file %q does not contain a certificate", options.CertFile)
+	}
+	if !caCert.IsCA {
+		return nil, errors.Errorf("cluster CA signer certificate in file %q is not a CA", options.CertFile)
+	}
+
+	keyData, err := os.ReadFile(options.KeyFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read cluster CA signer key file %q", options.KeyFile)
+	}
+	caKey, err := certs.DecodePrivateKeyPEM(keyData)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode cluster CA signer key file %q", options.KeyFile)
+	}
+	return certs.NewKeyPairSigner(caCert, caKey), nil
+}
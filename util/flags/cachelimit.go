@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/cluster-api/util/cachelimit"
+)
+
+// CacheLimitOptions has the options to configure the safeguards against the runaway growth of the caches.
+type CacheLimitOptions struct {
+	MaxObjectsPerType int
+	RejectNewClusters bool
+}
+
+// AddCacheLimitOptions adds the cache safeguards flags to the flag set.
+func AddCacheLimitOptions(fs *pflag.FlagSet, options *CacheLimitOptions) {
+	fs.IntVar(&options.MaxObjectsPerType, "cache-max-objects-per-type", 0,
+		"Number of objects of a type in the cache of the management cluster or of a workload cluster above which the cache is considered "+
+			"to be growing out of control; this is logged and reported by the capi_cache_object_limit_exceeded metric. If zero, the number of objects is not limited.")
+
+	fs.BoolVar(&options.RejectNewClusters, "cache-limit-reject-new-clusters", false,
+		"Do not accept new Clusters for provisioning while the number of objects of a type in a cache exceeds --cache-max-objects-per-type.")
+}
+
+// GetCacheLimitPolicy returns the cachelimit.Policy configured by the given options.
+func GetCacheLimitPolicy(options CacheLimitOptions) (cachelimit.Policy, error) {
+	if options.MaxObjectsPerType < 0 {
+		return cachelimit.Policy{}, errors.New("--cache-max-objects-per-type must not be negative")
+	}
+	if options.RejectNewClusters && options.MaxObjectsPerType == 0 {
+		return cachelimit.Policy{}, errors.New("--cache-limit-reject-new-clusters requires --cache-max-objects-per-type to be set")
+	}
+	return cachelimit.Policy{
+		MaxObjectsPerType: options.MaxObjectsPerType,
+		RejectNewClusters: options.RejectNewClusters,
+	}, nil
+}
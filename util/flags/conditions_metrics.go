@@ -0,0 +1,34 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// ConditionsMetricsOptions has the options to configure the metrics exposing the conditions of the objects
+// reconciled by a manager.
+type ConditionsMetricsOptions struct {
+	ConditionTypes []string
+}
+
+// AddConditionsMetricsOptions adds the conditions metrics flags to the flag set.
+func AddConditionsMetricsOptions(fs *pflag.FlagSet, options *ConditionsMetricsOptions) {
+	fs.StringSliceVar(&options.ConditionTypes, "conditions-metrics-types", nil,
+		"Comma-separated list of condition types exposed by the capi_condition metric, e.g. Ready,ControlPlaneReady. "+
+			"If omitted, the metric is disabled.")
+}
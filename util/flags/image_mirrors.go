@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/cluster-api/util/container"
+)
+
+// ImageMirrorOptions has the options to rewrite the images deployed to workload clusters
+// against registry mirrors.
+type ImageMirrorOptions struct {
+	Mirrors               map[string]string
+	MirrorsByArchitecture map[string]string
+	DigestPins            map[string]string
+}
+
+// AddImageMirrorOptions adds the image mirror flags to the flag set.
+func AddImageMirrorOptions(fs *pflag.FlagSet, options *ImageMirrorOptions) {
+	fs.StringToStringVar(&options.Mirrors, "image-mirrors", map[string]string{},
+		"Comma separated list of source=target pairs of registries, optionally followed by a repository prefix, used to rewrite "+
+			"the images deployed to workload clusters, e.g. registry.k8s.io=mirror.example.com/k8s. "+
+			"When more than one source matches an image, the longest one is used.")
+
+	fs.StringToStringVar(&options.MirrorsByArchitecture, "image-mirrors-by-architecture", map[string]string{},
+		"Comma separated list of architecture/source=target pairs used like --image-mirrors, but only for the images deployed "+
+			"to Machines with the given CPU architecture, e.g. arm64/registry.k8s.io=mirror.example.com/k8s-arm64. "+
+			"Mirrors for an architecture take precedence over the ones set by --image-mirrors.")
+
+	fs.StringToStringVar(&options.DigestPins, "image-digest-pins", map[string]string{},
+		"Comma separated list of image=digest pairs used to pin the images deployed to workload clusters to a digest, "+
+			"e.g. registry.k8s.io/coredns/coredns:v1.11.1=sha256:<digest>. Images are matched before mirrors are applied.")
+}
+
+// GetImageRewriter returns the container.ImageRewriter configured by the given options,
+// or nil if neither mirrors nor digest pins are set.
+func GetImageRewriter(options ImageMirrorOptions) (*container.ImageRewriter, error) {
+	if len(options.Mirrors) == 0 && len(options.MirrorsByArchitecture) == 0 && len(options.DigestPins) == 0 {
+		return nil, nil
+	}
+
+	mirrors := make([]container.ImageMirror, 0, len(options.Mirrors)+len(options.MirrorsByArchitecture))
+	for source, target := range options.Mirrors {
+		mirrors = append(mirrors, container.ImageMirror{Source: source, Target: target})
+	}
+	for archSource, target := range options.MirrorsByArchitecture {
+		arch, source, ok := strings.Cut(archSource, "/")
+		if !ok || arch == "" {
+			return nil, errors.Errorf("invalid image mirror %q: must be in the form architecture/source", archSource)
+		}
+		mirrors = append(mirrors, container.ImageMirror{Source: source, Target: target, Architecture: arch})
+	}
+	return container.NewImageRewriter(mirrors, options.DigestPins)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+// KeyEncryptionOptions has the options to configure envelope encryption
+// of the private keys stored in cluster certificate Secrets.
+type KeyEncryptionOptions struct {
+	KeyEncryptionKeyFile string
+	KeyEncryptionKeyName string
+}
+
+// AddKeyEncryptionOptions adds the cluster certificate key encryption flags to the flag set.
+func AddKeyEncryptionOptions(fs *pflag.FlagSet, options *KeyEncryptionOptions) {
+	fs.StringVar(&options.KeyEncryptionKeyFile, "certificate-key-encryption-key-file", "",
+		"Path of a file containing a base64 encoded 16, 24 or 32 bytes AES key used to envelope encrypt the private keys "+
+			"stored in cluster certificate Secrets. If omitted, private keys of newly generated certificates are stored unencrypted. "+
+			"The file should be provided from outside of the management cluster, e.g. by a secret store CSI driver.")
+
+	fs.StringVar(&options.KeyEncryptionKeyName, "certificate-key-encryption-key-name", "default",
+		"Name of the key encryption key set by --certificate-key-encryption-key-file; it is recorded on the encrypted Secrets.")
+}
+
+// GetKeyEncryptionService returns the KeyEncryptionService configured by the given options,
+// or nil if envelope encryption is not enabled.
+func GetKeyEncryptionService(options KeyEncryptionOptions) (secret.KeyEncryptionService, error) {
+	if options.KeyEncryptionKeyFile == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(options.KeyEncryptionKeyFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read key encryption key file %q", options.KeyEncryptionKeyFile)
+	}
+	kek, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode key encryption key file %q", options.KeyEncryptionKeyFile)
+	}
+	return secret.NewAESKeyEncryptionService(options.KeyEncryptionKeyName, kek)
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// NamespaceOptions has the options to restrict the controllers to an allow-list of namespaces.
+type NamespaceOptions struct {
+	Namespaces []string
+}
+
+// AddNamespaceOptions adds the namespace flags to the flag set.
+func AddNamespaceOptions(fs *pflag.FlagSet, options *NamespaceOptions) {
+	fs.StringSliceVar(&options.Namespaces, "namespace", nil,
+		"Comma-separated list of namespaces that the controller watches to reconcile cluster-api objects; "+
+			"the webhooks must be restricted to the same namespaces with a namespaceSelector in the webhook configurations. "+
+			"If unspecified, the controller watches for cluster-api objects across all namespaces.")
+}
+
+// GetWatchNamespaces returns the namespaces to be used as the default namespaces of the cache of the manager,
+// or nil if the controllers are not restricted to an allow-list of namespaces.
+func GetWatchNamespaces(options NamespaceOptions) (map[string]cache.Config, error) {
+	if len(options.Namespaces) == 0 {
+		return nil, nil
+	}
+	namespaces := map[string]cache.Config{}
+	for _, namespace := range options.Namespaces {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return nil, errors.Errorf("invalid namespace %q in --namespace: %v", namespace, errs)
+		}
+		namespaces[namespace] = cache.Config{}
+	}
+	return namespaces, nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/cluster-api/util/priority"
+)
+
+// PriorityOptions has the options to configure how controllers prioritize
+// user-triggered changes over periodic resyncs and status-only updates.
+type PriorityOptions struct {
+	LowPriorityMaxDelay      time.Duration
+	RecentlyCreatedThreshold time.Duration
+	OwnedBatchPeriod         time.Duration
+}
+
+// AddPriorityOptions adds the priority flags to the flag set.
+func AddPriorityOptions(fs *pflag.FlagSet, options *PriorityOptions) {
+	fs.DurationVar(&options.LowPriorityMaxDelay, "low-priority-reconcile-max-delay", priority.DefaultPolicy.LowPriorityMaxDelay,
+		"Maximum delay after which objects are reconciled for periodic resyncs and status-only updates when the controller "+
+			"is busy, so that new objects, spec changes and deletions are reconciled first. If zero, all changes are reconciled in order.")
+
+	fs.DurationVar(&options.RecentlyCreatedThreshold, "low-priority-reconcile-created-threshold", priority.DefaultPolicy.RecentlyCreatedThreshold,
+		"Age above which objects are reconciled with low priority when the controller starts.")
+
+	fs.DurationVar(&options.OwnedBatchPeriod, "owned-status-update-batch-period", priority.DefaultPolicy.OwnedBatchPeriod,
+		"Period over which status-only updates of owned objects, e.g. the Machines of a MachineSet, are batched into a single "+
+			"reconcile of their owner. If zero, owners are reconciled for every update.")
+}
+
+// GetPriorityPolicy returns the priority.Policy configured by the given options.
+func GetPriorityPolicy(options PriorityOptions) (priority.Policy, error) {
+	if options.LowPriorityMaxDelay < 0 {
+		return priority.Policy{}, errors.New("--low-priority-reconcile-max-delay must not be negative")
+	}
+	if options.RecentlyCreatedThreshold <= 0 {
+		return priority.Policy{}, errors.New("--low-priority-reconcile-created-threshold must be greater than zero")
+	}
+	if options.OwnedBatchPeriod < 0 {
+		return priority.Policy{}, errors.New("--owned-status-update-batch-period must not be negative")
+	}
+	return priority.Policy{
+		LowPriorityMaxDelay:      options.LowPriorityMaxDelay,
+		RecentlyCreatedThreshold: options.RecentlyCreatedThreshold,
+		OwnedBatchPeriod:         options.OwnedBatchPeriod,
+	}, nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/cluster-api/util/requeue"
+)
+
+// RequeueOptions has the options to configure how controllers requeue objects
+// after errors of a given class.
+type RequeueOptions struct {
+	TransientRequeueAfter time.Duration
+	ConflictRequeueAfter  time.Duration
+	NotFoundRequeueAfter  time.Duration
+}
+
+// AddRequeueOptions adds the requeue flags to the flag set.
+func AddRequeueOptions(fs *pflag.FlagSet, options *RequeueOptions) {
+	fs.DurationVar(&options.TransientRequeueAfter, "requeue-after-transient-error", requeue.DefaultPolicy.TransientRequeueAfter,
+		"Delay after which objects are reconciled again after a transient error, e.g. a timeout or an unreachable infrastructure provider.")
+
+	fs.DurationVar(&options.ConflictRequeueAfter, "requeue-after-conflict-error", requeue.DefaultPolicy.ConflictRequeueAfter,
+		"Delay after which objects are reconciled again after a conflict error. If zero, objects are requeued immediately.")
+
+	fs.DurationVar(&options.NotFoundRequeueAfter, "requeue-after-not-found-error", requeue.DefaultPolicy.NotFoundRequeueAfter,
+		"Delay after which objects are reconciled again when waiting for a referenced object which does not exist yet.")
+}
+
+// GetRequeuePolicy returns the requeue.Policy configured by the given options.
+func GetRequeuePolicy(options RequeueOptions) (requeue.Policy, error) {
+	if options.TransientRequeueAfter <= 0 {
+		return requeue.Policy{}, errors.New("--requeue-after-transient-error must be greater than zero")
+	}
+	if options.ConflictRequeueAfter < 0 {
+		return requeue.Policy{}, errors.New("--requeue-after-conflict-error must not be negative")
+	}
+	if options.NotFoundRequeueAfter <= 0 {
+		return requeue.Policy{}, errors.New("--requeue-after-not-found-error must be greater than zero")
+	}
+	return requeue.Policy{
+		TransientRequeueAfter: options.TransientRequeueAfter,
+		ConflictRequeueAfter:  options.ConflictRequeueAfter,
+		NotFoundRequeueAfter:  options.NotFoundRequeueAfter,
+	}, nil
+}
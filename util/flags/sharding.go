@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api/util/shard"
+)
+
+// ShardingOptions has the options to configure sharding of the controllers by Cluster.
+type ShardingOptions struct {
+	ShardIndex int
+	ShardCount int
+}
+
+// AddShardingOptions adds the sharding flags to the flag set.
+func AddShardingOptions(fs *pflag.FlagSet, options *ShardingOptions) {
+	fs.IntVar(&options.ShardCount, "shard-count", 1,
+		"Number of shards the Clusters are split into; each shard must be reconciled by a separate Deployment started "+
+			"with a different --shard-index. Clusters are assigned to shards using a hash of their namespace and name, "+
+			"unless they have the cluster.x-k8s.io/shard label set to a shard index. If 1, sharding is disabled.")
+
+	fs.IntVar(&options.ShardIndex, "shard-index", 0,
+		"Index of the shard reconciled by this manager, between 0 and --shard-count - 1.")
+}
+
+// GetShard returns the shard.Shard configured by the given options, or nil if sharding is disabled;
+// reader is used to read Clusters and it is expected to be backed by a cache.
+func GetShard(options ShardingOptions, reader client.Reader) (*shard.Shard, error) {
+	if options.ShardCount == 1 && options.ShardIndex == 0 {
+		return nil, nil
+	}
+	return shard.New(options.ShardIndex, options.ShardCount, reader)
+}
+
+// GetShardLeaderElectionID returns the leader election ID to be used by the manager, so every shard elects its own leader.
+func GetShardLeaderElectionID(leaderElectionID string, options ShardingOptions) string {
+	if options.ShardCount <= 1 {
+		return leaderElectionID
+	}
+	return fmt.Sprintf("%s-shard-%d", leaderElectionID, options.ShardIndex)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/cluster-api/util/throttle"
+)
+
+// ThrottleOptions has the options to configure the limits applied to the reconciles
+// of the objects belonging to a single Cluster.
+type ThrottleOptions struct {
+	ClusterReconcileConcurrency int
+	ClusterReconcileQPS         float64
+	ClusterReconcileBurst       int
+}
+
+// AddThrottleOptions adds the per-cluster reconcile limits flags to the flag set.
+func AddThrottleOptions(fs *pflag.FlagSet, options *ThrottleOptions) {
+	fs.IntVar(&options.ClusterReconcileConcurrency, "cluster-reconcile-concurrency", 0,
+		"Maximum number of objects belonging to a single Cluster which are reconciled concurrently by all the controllers. "+
+			"It can be overridden for a Cluster with the cluster.x-k8s.io/reconcile-concurrency annotation. If zero, concurrency is not limited.")
+
+	fs.Float64Var(&options.ClusterReconcileQPS, "cluster-reconcile-qps", 0,
+		"Maximum number of reconciles per second of the objects belonging to a single Cluster by all the controllers. "+
+			"It can be overridden for a Cluster with the cluster.x-k8s.io/reconcile-qps annotation. If zero, the rate is not limited.")
+
+	fs.IntVar(&options.ClusterReconcileBurst, "cluster-reconcile-burst", 10,
+		"Maximum number of reconciles of the objects belonging to a single Cluster which can be executed at once exceeding --cluster-reconcile-qps.")
+}
+
+// GetThrottlePolicy returns the throttle.Policy configured by the given options.
+func GetThrottlePolicy(options ThrottleOptions) (throttle.Policy, error) {
+	if options.ClusterReconcileConcurrency < 0 {
+		return throttle.Policy{}, errors.New("--cluster-reconcile-concurrency must not be negative")
+	}
+	if options.ClusterReconcileQPS < 0 {
+		return throttle.Policy{}, errors.New("--cluster-reconcile-qps must not be negative")
+	}
+	if options.ClusterReconcileBurst < 1 {
+		return throttle.Policy{}, errors.New("--cluster-reconcile-burst must be greater than zero")
+	}
+	return throttle.Policy{
+		MaxConcurrentReconciles: options.ClusterReconcileConcurrency,
+		QPS:                     options.ClusterReconcileQPS,
+		Burst:                   options.ClusterReconcileBurst,
+	}, nil
+}
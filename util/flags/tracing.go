@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// TracingOptions has the options to configure the export of OpenTelemetry traces.
+type TracingOptions struct {
+	Endpoint      string
+	Insecure      bool
+	SamplingRatio float64
+}
+
+// AddTracingOptions adds the tracing flags to the flag set.
+func AddTracingOptions(fs *pflag.FlagSet, options *TracingOptions) {
+	fs.StringVar(&options.Endpoint, "tracing-otlp-endpoint", "",
+		"The host:port of the OTLP gRPC endpoint traces are exported to. If omitted, tracing is disabled.")
+
+	fs.BoolVar(&options.Insecure, "tracing-otlp-insecure", false,
+		"Disable TLS when connecting to the OTLP gRPC endpoint.")
+
+	fs.Float64Var(&options.SamplingRatio, "tracing-sampling-ratio", 0.1,
+		"The ratio of reconciles traced, between 0 and 1. Reconciles triggered by a traced parent are always traced.")
+}
+
+// SetupTracing configures the global OpenTelemetry TracerProvider according to the given options. It returns
+// a func which must be called before the process exits to flush the spans not exported yet.
+// If tracing is not enabled, the returned func is a no-op.
+func SetupTracing(ctx context.Context, serviceName string, options TracingOptions) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	if options.Endpoint == "" {
+		return noop, nil
+	}
+	if options.SamplingRatio < 0 || options.SamplingRatio > 1 {
+		return noop, errors.New("--tracing-sampling-ratio must be between 0 and 1")
+	}
+
+	exporterOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(options.Endpoint)}
+	if options.Insecure {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, exporterOpts...)
+	if err != nil {
+		return noop, errors.Wrap(err, "failed to create OTLP trace exporter")
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return noop, errors.Wrap(err, "failed to create tracing resource")
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.SamplingRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return tp.Shutdown, nil
+}
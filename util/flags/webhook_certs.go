@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/cluster-api/util/webhookcerts"
+)
+
+// WebhookCertOptions has the options to configure the self-management of the webhook serving certificates.
+// ServiceName, SecretName and ProviderName must be set to the defaults of the provider before adding the flags.
+type WebhookCertOptions struct {
+	SelfManaged      bool
+	ServiceName      string
+	ServiceNamespace string
+	SecretName       string
+	ProviderName     string
+	CertDuration     time.Duration
+}
+
+// AddWebhookCertOptions adds the webhook certificate flags to the flag set.
+func AddWebhookCertOptions(fs *pflag.FlagSet, options *WebhookCertOptions) {
+	fs.BoolVar(&options.SelfManaged, "webhook-cert-self-managed", false,
+		"Generate, inject into the webhook configurations and rotate the webhook serving certificates instead of relying on cert-manager. "+
+			"The certificates are written to --webhook-cert-dir, which must be writable.")
+
+	fs.StringVar(&options.ServiceName, "webhook-service-name", options.ServiceName,
+		"Name of the Service of the webhooks, used for self-managed webhook certificates.")
+
+	fs.StringVar(&options.ServiceNamespace, "webhook-service-namespace", "",
+		"Namespace of the Service of the webhooks and of the Secret storing the self-managed webhook certificates. "+
+			"If unspecified, the namespace of the controller from the POD_NAMESPACE environment variable is used.")
+
+	fs.StringVar(&options.SecretName, "webhook-cert-secret-name", options.SecretName,
+		"Name of the Secret storing the self-managed webhook certificates.")
+
+	fs.StringVar(&options.ProviderName, "webhook-provider-name", options.ProviderName,
+		"Value of the cluster.x-k8s.io/provider label of the webhook configurations and CustomResourceDefinitions "+
+			"the CA of the self-managed webhook certificates is injected into.")
+
+	fs.DurationVar(&options.CertDuration, "webhook-cert-duration", 0,
+		"Lifespan of the self-managed webhook serving certificates; they are renewed when a third of their lifespan is left. If zero, 1 year is used.")
+}
+
+// GetWebhookCertOptions returns the webhookcerts.Options configured by the given options,
+// or nil if the webhook certificates are not self-managed.
+func GetWebhookCertOptions(options WebhookCertOptions, certDir string) (*webhookcerts.Options, error) {
+	if !options.SelfManaged {
+		return nil, nil
+	}
+	if options.ServiceNamespace == "" {
+		options.ServiceNamespace = os.Getenv("POD_NAMESPACE")
+	}
+	if options.ServiceName == "" || options.ServiceNamespace == "" || options.SecretName == "" || options.ProviderName == "" {
+		return nil, errors.New("--webhook-service-name, --webhook-service-namespace, --webhook-cert-secret-name and --webhook-provider-name must be set when --webhook-cert-self-managed is set")
+	}
+	if options.CertDuration < 0 {
+		return nil, errors.New("--webhook-cert-duration must not be negative")
+	}
+	return &webhookcerts.Options{
+		ServiceName:      options.ServiceName,
+		ServiceNamespace: options.ServiceNamespace,
+		SecretName:       options.SecretName,
+		ProviderName:     options.ProviderName,
+		CertDir:          certDir,
+		CertDuration:     options.CertDuration,
+	}, nil
+}
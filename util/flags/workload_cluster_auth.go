@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"net/url"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"sigs.k8s.io/cluster-api/controllers/remote"
+)
+
+// WorkloadClusterAuthOptions has the options to configure the authentication methods
+// the owners of a Cluster can choose with the cluster.x-k8s.io/workload-cluster-auth annotation.
+type WorkloadClusterAuthOptions struct {
+	ExecPlugins       map[string]string
+	TokenURLs         []string
+	SubjectTokenFiles []string
+}
+
+// AddWorkloadClusterAuthOptions adds the workload cluster authentication flags to the flag set.
+func AddWorkloadClusterAuthOptions(fs *pflag.FlagSet, options *WorkloadClusterAuthOptions) {
+	fs.StringToStringVar(&options.ExecPlugins, "workload-cluster-auth-exec-plugin", map[string]string{},
+		"Exec credential plugins which can be selected by name in the <cluster>-auth Secret of Clusters using the exec authentication, "+
+			"in the form name=path; the file at path contains the plugin configuration in the format of the exec field of a kubeconfig user. "+
+			"If empty, the exec authentication can't be used.")
+
+	fs.StringSliceVar(&options.TokenURLs, "workload-cluster-auth-token-url", []string{},
+		"Token exchange endpoints which can be set in the <cluster>-auth Secret of Clusters using the oidc-token-exchange authentication. "+
+			"If empty, the oidc-token-exchange authentication can't be used.")
+
+	fs.StringSliceVar(&options.SubjectTokenFiles, "workload-cluster-auth-subject-token-file", []string{},
+		"Files storing the subject tokens which can be exchanged by Clusters using the oidc-token-exchange authentication, e.g. projected "+
+			"service account tokens with a dedicated audience. If a single file is allowed, it is used when the <cluster>-auth Secret does not set one.")
+}
+
+// GetWorkloadClusterAuthPolicy returns the remote.WorkloadClusterAuthPolicy configured by the given options.
+func GetWorkloadClusterAuthPolicy(options WorkloadClusterAuthOptions) (remote.WorkloadClusterAuthPolicy, error) {
+	policy := remote.WorkloadClusterAuthPolicy{
+		ExecPlugins:       map[string]*clientcmdapi.ExecConfig{},
+		SubjectTokenFiles: options.SubjectTokenFiles,
+	}
+
+	for name, path := range options.ExecPlugins {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return remote.WorkloadClusterAuthPolicy{}, errors.Wrapf(err, "--workload-cluster-auth-exec-plugin: failed to read exec credential plugin %q", name)
+		}
+		execConfig, err := remote.ParseExecConfig(raw)
+		if err != nil {
+			return remote.WorkloadClusterAuthPolicy{}, errors.Wrapf(err, "--workload-cluster-auth-exec-plugin: invalid exec credential plugin %q", name)
+		}
+		policy.ExecPlugins[name] = execConfig
+	}
+
+	for _, tokenURL := range options.TokenURLs {
+		u, err := url.Parse(tokenURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return remote.WorkloadClusterAuthPolicy{}, errors.Errorf("--workload-cluster-auth-token-url: %q is not a valid URL", tokenURL)
+		}
+		policy.TokenURLs = append(policy.TokenURLs, tokenURL)
+	}
+	return policy, nil
+}
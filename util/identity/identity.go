@@ -0,0 +1,226 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package identity implements the workload identity of the Cluster API controllers, allowing Runtime Extensions
+// and provider webhooks to verify which controller is calling them instead of trusting any in-cluster caller.
+//
+// Controllers present a projected service account token bound to an audience, e.g. DefaultAudience, as a bearer token;
+// receivers verify it using the TokenReview API and identify the caller by a SPIFFE ID derived from its service account,
+// e.g. spiffe://cluster.local/ns/capi-system/sa/capi-manager.
+package identity
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultAudience is the default audience of the tokens presented by the Cluster API controllers.
+	DefaultAudience = "runtime.cluster.x-k8s.io"
+
+	// DefaultTrustDomain is the default SPIFFE trust domain of the Cluster API controllers.
+	DefaultTrustDomain = "cluster.local"
+
+	// verifiedTokenCacheTTL is how long the result of the verification of a token is cached.
+	verifiedTokenCacheTTL = time.Minute
+
+	// verifiedTokenCacheSize is the maximum number of verified tokens which are cached.
+	verifiedTokenCacheSize = 1024
+)
+
+// Identity is the workload identity of a caller.
+type Identity struct {
+	// SPIFFEID is the SPIFFE ID of the caller, e.g. spiffe://cluster.local/ns/capi-system/sa/capi-manager.
+	SPIFFEID string
+
+	// Namespace is the namespace of the service account of the caller.
+	Namespace string
+
+	// ServiceAccount is the name of the service account of the caller.
+	ServiceAccount string
+}
+
+// SPIFFEID returns the SPIFFE ID of a service account.
+func SPIFFEID(trustDomain, namespace, serviceAccount string) string {
+	return fmt.Sprintf("spiffe://%s/ns/%s/sa/%s", trustDomain, namespace, serviceAccount)
+}
+
+// TokenSource returns the token a controller presents to prove its identity.
+type TokenSource interface {
+	Token() (string, error)
+}
+
+// NewFileTokenSource returns a TokenSource reading the token from a file, e.g. a projected service account token;
+// the file is read on every call, because the kubelet refreshes projected tokens before they expire.
+func NewFileTokenSource(path string) TokenSource {
+	return fileTokenSource(path)
+}
+
+type fileTokenSource string
+
+func (path fileTokenSource) Token() (string, error) {
+	data, err := os.ReadFile(string(path))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read identity token from %s", string(path))
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", errors.Errorf("identity token in %s is empty", string(path))
+	}
+	return token, nil
+}
+
+// VerifierOptions are the options of a Verifier.
+type VerifierOptions struct {
+	// Audiences are the audiences accepted by the Verifier; tokens must be bound to at least one of them.
+	// Defaults to DefaultAudience.
+	Audiences []string
+
+	// TrustDomain is the SPIFFE trust domain of the verified identities.
+	// Defaults to DefaultTrustDomain.
+	TrustDomain string
+}
+
+// Verifier verifies the tokens presented by the callers using the TokenReview API.
+type Verifier struct {
+	client      client.Client
+	audiences   []string
+	trustDomain string
+
+	lock   sync.Mutex
+	cached map[[sha256.Size]byte]cachedIdentity
+}
+
+type cachedIdentity struct {
+	identity *Identity
+	expiry   time.Time
+}
+
+// NewVerifier returns a Verifier creating TokenReviews with the given client.
+func NewVerifier(c client.Client, options VerifierOptions) *Verifier {
+	if len(options.Audiences) == 0 {
+		options.Audiences = []string{DefaultAudience}
+	}
+	if options.TrustDomain == "" {
+		options.TrustDomain = DefaultTrustDomain
+	}
+	return &Verifier{
+		client:      c,
+		audiences:   options.Audiences,
+		trustDomain: options.TrustDomain,
+		cached:      map[[sha256.Size]byte]cachedIdentity{},
+	}
+}
+
+// Verify returns the Identity of the caller presenting the token; it fails if the token is not valid,
+// is not bound to one of the audiences of the Verifier or it does not belong to a service account.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Identity, error) {
+	key := sha256.Sum256([]byte(token))
+	v.lock.Lock()
+	c, ok := v.cached[key]
+	v.lock.Unlock()
+	if ok && time.Now().Before(c.expiry) {
+		return c.identity, nil
+	}
+
+	review := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token:     token,
+			Audiences: v.audiences,
+		},
+	}
+	if err := v.client.Create(ctx, review); err != nil {
+		return nil, errors.Wrap(err, "failed to review token")
+	}
+	if !review.Status.Authenticated {
+		if review.Status.Error != "" {
+			return nil, errors.Errorf("token is not valid: %s", review.Status.Error)
+		}
+		return nil, errors.New("token is not valid")
+	}
+	if !sets.New(review.Status.Audiences...).HasAny(v.audiences...) {
+		return nil, errors.Errorf("token is not bound to any of the audiences %s", strings.Join(v.audiences, ", "))
+	}
+	parts := strings.Split(review.Status.User.Username, ":")
+	if len(parts) != 4 || parts[0] != "system" || parts[1] != "serviceaccount" {
+		return nil, errors.Errorf("token does not belong to a service account but to %q", review.Status.User.Username)
+	}
+	identity := &Identity{
+		SPIFFEID:       SPIFFEID(v.trustDomain, parts[2], parts[3]),
+		Namespace:      parts[2],
+		ServiceAccount: parts[3],
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if len(v.cached) >= verifiedTokenCacheSize {
+		v.cached = map[[sha256.Size]byte]cachedIdentity{}
+	}
+	v.cached[key] = cachedIdentity{identity: identity, expiry: time.Now().Add(verifiedTokenCacheTTL)}
+	return identity, nil
+}
+
+// Authorizer returns true if the caller with the given Identity is allowed to call the endpoint of the request.
+type Authorizer func(r *http.Request, identity *Identity) bool
+
+// AllowSPIFFEIDs returns an Authorizer allowing only the callers with one of the given SPIFFE IDs.
+func AllowSPIFFEIDs(ids ...string) Authorizer {
+	allowed := sets.New(ids...)
+	return func(_ *http.Request, identity *Identity) bool {
+		return allowed.Has(identity.SPIFFEID)
+	}
+}
+
+type identityKey struct{}
+
+// FromContext returns the Identity of the caller stored in the context by Middleware, if any.
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok
+}
+
+// Middleware returns a handler verifying the bearer token of the caller with the Verifier, authorizing the caller with
+// the Authorizer, if not nil, and then calling next with the Identity of the caller stored in the request context.
+func Middleware(verifier *Verifier, authorize Authorizer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		identity, err := verifier.Verify(r.Context(), token)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to verify caller identity: %v", err), http.StatusUnauthorized)
+			return
+		}
+		if authorize != nil && !authorize(r, identity) {
+			http.Error(w, fmt.Sprintf("%s is not allowed to call %s", identity.SPIFFEID, r.URL.Path), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, identity)))
+	})
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package identity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// newFakeClient returns a client reviewing tokens according to the given map of token to the TokenReview status.
+func newFakeClient(reviews map[string]authenticationv1.TokenReviewStatus, calls *int) client.Client {
+	scheme := runtime.NewScheme()
+	_ = authenticationv1.AddToScheme(scheme)
+	return interceptor.NewClient(fake.NewClientBuilder().WithScheme(scheme).Build(), interceptor.Funcs{
+		Create: func(_ context.Context, _ client.WithWatch, obj client.Object, _ ...client.CreateOption) error {
+			*calls++
+			review := obj.(*authenticationv1.TokenReview)
+			review.Status = reviews[review.Spec.Token]
+			return nil
+		},
+	})
+}
+
+func TestVerifier(t *testing.T) {
+	reviews := map[string]authenticationv1.TokenReviewStatus{
+		"capi-manager": {
+			Authenticated: true,
+			Audiences:     []string{DefaultAudience},
+			User:          authenticationv1.UserInfo{Username: "system:serviceaccount:capi-system:capi-manager"},
+		},
+		"other-audience": {
+			Authenticated: true,
+			Audiences:     []string{"https://kubernetes.default.svc"},
+			User:          authenticationv1.UserInfo{Username: "system:serviceaccount:capi-system:capi-manager"},
+		},
+		"user": {
+			Authenticated: true,
+			Audiences:     []string{DefaultAudience},
+			User:          authenticationv1.UserInfo{Username: "jane"},
+		},
+		"invalid": {
+			Error: "token expired",
+		},
+	}
+
+	t.Run("verifies service account tokens", func(t *testing.T) {
+		g := NewWithT(t)
+
+		calls := 0
+		v := NewVerifier(newFakeClient(reviews, &calls), VerifierOptions{})
+		identity, err := v.Verify(context.Background(), "capi-manager")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(identity).To(Equal(&Identity{
+			SPIFFEID:       "spiffe://cluster.local/ns/capi-system/sa/capi-manager",
+			Namespace:      "capi-system",
+			ServiceAccount: "capi-manager",
+		}))
+
+		// The result of the verification is cached.
+		_, err = v.Verify(context.Background(), "capi-manager")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(calls).To(Equal(1))
+	})
+
+	t.Run("rejects tokens which are not valid", func(t *testing.T) {
+		g := NewWithT(t)
+
+		calls := 0
+		v := NewVerifier(newFakeClient(reviews, &calls), VerifierOptions{TrustDomain: "example.com"})
+		_, err := v.Verify(context.Background(), "invalid")
+		g.Expect(err).To(MatchError(ContainSubstring("token expired")))
+		_, err = v.Verify(context.Background(), "other-audience")
+		g.Expect(err).To(MatchError(ContainSubstring("not bound to any of the audiences")))
+		_, err = v.Verify(context.Background(), "user")
+		g.Expect(err).To(MatchError(ContainSubstring("does not belong to a service account")))
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	reviews := map[string]authenticationv1.TokenReviewStatus{
+		"capi-manager": {
+			Authenticated: true,
+			Audiences:     []string{DefaultAudience},
+			User:          authenticationv1.UserInfo{Username: "system:serviceaccount:capi-system:capi-manager"},
+		},
+		"kcp-manager": {
+			Authenticated: true,
+			Audiences:     []string{DefaultAudience},
+			User:          authenticationv1.UserInfo{Username: "system:serviceaccount:capi-kubeadm-control-plane-system:capi-kubeadm-control-plane-manager"},
+		},
+	}
+	calls := 0
+	v := NewVerifier(newFakeClient(reviews, &calls), VerifierOptions{})
+
+	var caller *Identity
+	handler := Middleware(v, AllowSPIFFEIDs(SPIFFEID(DefaultTrustDomain, "capi-system", "capi-manager")), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		caller, _ = FromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+	}{
+		{
+			name:       "rejects requests without token",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:          "rejects requests with an invalid token",
+			authorization: "Bearer invalid",
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "rejects callers not allowed by the authorizer",
+			authorization: "Bearer kcp-manager",
+			wantStatus:    http.StatusForbidden,
+		},
+		{
+			name:          "allows callers allowed by the authorizer",
+			authorization: "Bearer capi-manager",
+			wantStatus:    http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			caller = nil
+			req := httptest.NewRequest(http.MethodPost, "/hooks.runtime.cluster.x-k8s.io/v1alpha1/beforeclustercreate/test", http.NoBody)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			g.Expect(rec.Code).To(Equal(tt.wantStatus))
+			if tt.wantStatus == http.StatusOK {
+				g.Expect(caller).ToNot(BeNil())
+				g.Expect(caller.ServiceAccount).To(Equal("capi-manager"))
+			} else {
+				g.Expect(caller).To(BeNil())
+			}
+		})
+	}
+}
@@ -34,6 +34,7 @@ import (
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/certs"
 	"sigs.k8s.io/cluster-api/util/secret"
 )
@@ -52,28 +53,59 @@ func FromSecret(ctx context.Context, c client.Reader, cluster client.ObjectKey)
 	return toKubeconfigBytes(out)
 }
 
-// New creates a new Kubeconfig using the cluster name and specified endpoint.
-func New(clusterName, endpoint string, caCert *x509.Certificate, caKey crypto.Signer) (*api.Config, error) {
-	cfg := &certs.Config{
-		CommonName:   "kubernetes-admin",
-		Organization: []string{"system:masters"},
-		Usages:       []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+// InternalFromSecret fetches the Kubeconfig used by the management cluster controllers for a Cluster,
+// that is the controller-internal Kubeconfig if it exists, otherwise the Cluster Kubeconfig.
+func InternalFromSecret(ctx context.Context, c client.Reader, cluster client.ObjectKey) ([]byte, error) {
+	out, err := secret.Get(ctx, c, cluster, secret.InternalKubeconfig)
+	if apierrors.IsNotFound(err) {
+		return FromSecret(ctx, c, cluster)
 	}
-
-	clientKey, err := certs.NewPrivateKey()
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to create private key")
+		return nil, err
+	}
+	return toKubeconfigBytes(out)
+}
+
+// Option configures the client certificate of a generated Kubeconfig.
+type Option func(*options)
+
+type options struct {
+	ttl               time.Duration
+	withoutClientCert bool
+}
+
+// WithClientCertificateTTL sets the lifespan of the Kubeconfig client certificate;
+// if not set, certs.DefaultCertDuration is used.
+func WithClientCertificateTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
 	}
+}
 
-	clientCert, err := cfg.NewSignedCert(clientKey, caCert, caKey)
-	if err != nil {
-		return nil, errors.Wrap(err, "unable to sign certificate")
+// WithoutClientCertificate generates a Kubeconfig without client certificate, i.e. only with the endpoint and the CA
+// of the cluster; it is used for the Clusters whose management cluster access is configured with the
+// WorkloadClusterAuthAnnotation, so the Kubeconfig secret does not store long-lived credentials.
+func WithoutClientCertificate() Option {
+	return func(o *options) {
+		o.withoutClientCert = true
 	}
+}
 
+func newOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// New creates a new Kubeconfig using the cluster name and specified endpoint.
+// caKey is not used, and it can be nil, if the Kubeconfig is generated WithoutClientCertificate.
+func New(clusterName, endpoint string, caCert *x509.Certificate, caKey crypto.Signer, opts ...Option) (*api.Config, error) {
+	o := newOptions(opts...)
 	userName := fmt.Sprintf("%s-admin", clusterName)
 	contextName := fmt.Sprintf("%s@%s", userName, clusterName)
-
-	return &api.Config{
+	config := &api.Config{
 		Clusters: map[string]*api.Cluster{
 			clusterName: {
 				Server:                   endpoint,
@@ -87,30 +119,58 @@ func New(clusterName, endpoint string, caCert *x509.Certificate, caKey crypto.Si
 			},
 		},
 		AuthInfos: map[string]*api.AuthInfo{
-			userName: {
-				ClientKeyData:         certs.EncodePrivateKeyPEM(clientKey),
-				ClientCertificateData: certs.EncodeCertPEM(clientCert),
-			},
+			userName: {},
 		},
 		CurrentContext: contextName,
-	}, nil
+	}
+	if o.withoutClientCert {
+		return config, nil
+	}
+
+	cfg := &certs.Config{
+		CommonName:   "kubernetes-admin",
+		Organization: []string{"system:masters"},
+		Usages:       []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		Duration:     o.ttl,
+	}
+
+	clientKey, err := certs.NewPrivateKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create private key")
+	}
+
+	clientCert, err := cfg.NewSignedCert(clientKey, caCert, caKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to sign certificate")
+	}
+
+	config.AuthInfos[userName] = &api.AuthInfo{
+		ClientKeyData:         certs.EncodePrivateKeyPEM(clientKey),
+		ClientCertificateData: certs.EncodeCertPEM(clientCert),
+	}
+	return config, nil
 }
 
-// CreateSecret creates the Kubeconfig secret for the given cluster.
+// CreateSecret creates the Kubeconfig secret for the given cluster; the Kubeconfig has no client certificate
+// if the cluster has the WorkloadClusterAuthAnnotation.
 func CreateSecret(ctx context.Context, c client.Client, cluster *clusterv1.Cluster) error {
 	name := util.ObjectKey(cluster)
+	var opts []Option
+	if annotations.HasWorkloadClusterAuth(cluster) {
+		opts = append(opts, WithoutClientCertificate())
+	}
 	return CreateSecretWithOwner(ctx, c, name, cluster.Spec.ControlPlaneEndpoint.String(), metav1.OwnerReference{
 		APIVersion: clusterv1.GroupVersion.String(),
 		Kind:       "Cluster",
 		Name:       cluster.Name,
 		UID:        cluster.UID,
-	})
+	}, opts...)
 }
 
 // CreateSecretWithOwner creates the Kubeconfig secret for the given cluster name, namespace, endpoint, and owner reference.
-func CreateSecretWithOwner(ctx context.Context, c client.Client, clusterName client.ObjectKey, endpoint string, owner metav1.OwnerReference) error {
+func CreateSecretWithOwner(ctx context.Context, c client.Client, clusterName client.ObjectKey, endpoint string, owner metav1.OwnerReference, opts ...Option) error {
 	server := fmt.Sprintf("https://%s", endpoint)
-	out, err := generateKubeconfig(ctx, c, clusterName, server)
+	out, err := generateKubeconfig(ctx, c, clusterName, server, opts...)
 	if err != nil {
 		return err
 	}
@@ -131,9 +191,19 @@ func GenerateSecret(cluster *clusterv1.Cluster, data []byte) *corev1.Secret {
 
 // GenerateSecretWithOwner returns a Kubernetes secret for the given Cluster name, namespace, kubeconfig data, and ownerReference.
 func GenerateSecretWithOwner(clusterName client.ObjectKey, data []byte, owner metav1.OwnerReference) *corev1.Secret {
+	return generateSecretWithOwner(clusterName, secret.Kubeconfig, data, owner)
+}
+
+// GenerateInternalSecretWithOwner returns a Kubernetes secret storing the Kubeconfig used by the management cluster
+// controllers for the given Cluster name, namespace, kubeconfig data, and ownerReference.
+func GenerateInternalSecretWithOwner(clusterName client.ObjectKey, data []byte, owner metav1.OwnerReference) *corev1.Secret {
+	return generateSecretWithOwner(clusterName, secret.InternalKubeconfig, data, owner)
+}
+
+func generateSecretWithOwner(clusterName client.ObjectKey, purpose secret.Purpose, data []byte, owner metav1.OwnerReference) *corev1.Secret {
 	return &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      secret.Name(clusterName.Name, secret.Kubeconfig),
+			Name:      secret.Name(clusterName.Name, purpose),
 			Namespace: clusterName.Namespace,
 			Labels: map[string]string{
 				clusterv1.ClusterNameLabel: clusterName.Name,
@@ -151,57 +221,92 @@ func GenerateSecretWithOwner(clusterName client.ObjectKey, data []byte, owner me
 
 // NeedsClientCertRotation returns whether any of the Kubeconfig secret's client certificates will expire before the given threshold.
 func NeedsClientCertRotation(configSecret *corev1.Secret, threshold time.Duration) (bool, error) {
-	now := time.Now()
+	rotationTime, err := ClientCertRotationTime(configSecret, threshold)
+	if err != nil {
+		return false, err
+	}
+	if rotationTime.IsZero() {
+		return false, nil
+	}
+	return time.Now().After(rotationTime), nil
+}
 
-	data, err := toKubeconfigBytes(configSecret)
+// HasClientCertificate returns whether the Kubeconfig secret contains any client certificate.
+func HasClientCertificate(configSecret *corev1.Secret) (bool, error) {
+	rotationTime, err := ClientCertRotationTime(configSecret, 0)
 	if err != nil {
 		return false, err
 	}
+	return !rotationTime.IsZero(), nil
+}
+
+// ClientCertRotationTime returns the time at which the first of the Kubeconfig secret's client certificates will
+// be within the given threshold from expiring, and thus should be rotated; the zero time is returned if the
+// Kubeconfig secret contains no client certificate.
+// This can be used to schedule the next refresh of Kubeconfigs using short-lived client certificates.
+func ClientCertRotationTime(configSecret *corev1.Secret, threshold time.Duration) (time.Time, error) {
+	data, err := toKubeconfigBytes(configSecret)
+	if err != nil {
+		return time.Time{}, err
+	}
 
 	config, err := clientcmd.Load(data)
 	if err != nil {
-		return false, errors.Wrap(err, "failed to convert kubeconfig Secret into a clientcmdapi.Config")
+		return time.Time{}, errors.Wrap(err, "failed to convert kubeconfig Secret into a clientcmdapi.Config")
 	}
 
+	var rotationTime time.Time
 	for _, authInfo := range config.AuthInfos {
+		if len(authInfo.ClientCertificateData) == 0 {
+			continue
+		}
 		cert, err := certs.DecodeCertPEM(authInfo.ClientCertificateData)
 		if err != nil {
-			return false, errors.Wrap(err, "failed to decode kubeconfig client certificate")
+			return time.Time{}, errors.Wrap(err, "failed to decode kubeconfig client certificate")
 		}
-		if cert.NotAfter.Sub(now) < threshold {
-			return true, nil
+		if t := cert.NotAfter.Add(-threshold); rotationTime.IsZero() || t.Before(rotationTime) {
+			rotationTime = t
 		}
 	}
 
-	return false, nil
+	return rotationTime, nil
 }
 
 // RegenerateSecret creates and stores a new Kubeconfig in the given secret.
-func RegenerateSecret(ctx context.Context, c client.Client, configSecret *corev1.Secret) error {
+func RegenerateSecret(ctx context.Context, c client.Client, configSecret *corev1.Secret, opts ...Option) error {
+	out, err := Regenerate(ctx, c, configSecret, opts...)
+	if err != nil {
+		return err
+	}
+	configSecret.Data[secret.KubeconfigDataName] = out
+	return c.Update(ctx, configSecret)
+}
+
+// Regenerate creates a new Kubeconfig for the same cluster and endpoint as the Kubeconfig in the given secret,
+// with a new client certificate; the secret is not modified.
+func Regenerate(ctx context.Context, c client.Reader, configSecret *corev1.Secret, opts ...Option) ([]byte, error) {
 	clusterName, _, err := secret.ParseSecretName(configSecret.Name)
 	if err != nil {
-		return errors.Wrap(err, "failed to parse secret name")
+		return nil, errors.Wrap(err, "failed to parse secret name")
 	}
 	data, err := toKubeconfigBytes(configSecret)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	config, err := clientcmd.Load(data)
 	if err != nil {
-		return errors.Wrap(err, "failed to convert kubeconfig Secret into a clientcmdapi.Config")
+		return nil, errors.Wrap(err, "failed to convert kubeconfig Secret into a clientcmdapi.Config")
 	}
-	endpoint := config.Clusters[clusterName].Server
-	key := client.ObjectKey{Name: clusterName, Namespace: configSecret.Namespace}
-	out, err := generateKubeconfig(ctx, c, key, endpoint)
-	if err != nil {
-		return err
+	cluster, ok := config.Clusters[clusterName]
+	if !ok {
+		return nil, errors.Errorf("kubeconfig Secret does not contain cluster %q", clusterName)
 	}
-	configSecret.Data[secret.KubeconfigDataName] = out
-	return c.Update(ctx, configSecret)
+	key := client.ObjectKey{Name: clusterName, Namespace: configSecret.Namespace}
+	return generateKubeconfig(ctx, c, key, cluster.Server, opts...)
 }
 
-func generateKubeconfig(ctx context.Context, c client.Client, clusterName client.ObjectKey, endpoint string) ([]byte, error) {
+func generateKubeconfig(ctx context.Context, c client.Reader, clusterName client.ObjectKey, endpoint string, opts ...Option) ([]byte, error) {
 	clusterCA, err := secret.GetFromNamespacedName(ctx, c, clusterName, secret.ClusterCA)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
@@ -217,14 +322,21 @@ func generateKubeconfig(ctx context.Context, c client.Client, clusterName client
 		return nil, errors.New("certificate not found in config")
 	}
 
-	key, err := certs.DecodePrivateKeyPEM(clusterCA.Data[secret.TLSKeyDataName])
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to decode private key")
-	} else if key == nil {
-		return nil, errors.New("CA private key not found")
+	var key crypto.Signer
+	if !newOptions(opts...).withoutClientCert {
+		keyData, _, err := secret.KeyData(ctx, clusterCA)
+		if err != nil {
+			return nil, err
+		}
+		key, err = certs.DecodePrivateKeyPEM(keyData)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode private key")
+		} else if key == nil {
+			return nil, errors.New("CA private key not found")
+		}
 	}
 
-	cfg, err := New(clusterName.Name, endpoint, cert, key)
+	cfg, err := New(clusterName.Name, endpoint, cert, key, opts...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to generate a kubeconfig")
 	}
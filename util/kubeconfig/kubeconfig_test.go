@@ -202,6 +202,32 @@ func TestGenerateSecretWithOwner(t *testing.T) {
 	g.Expect(kubeconfigSecret).To(BeComparableTo(expectedSecret))
 }
 
+func TestGenerateInternalSecretWithOwner(t *testing.T) {
+	g := NewWithT(t)
+
+	owner := metav1.OwnerReference{
+		Name:       "test1",
+		Kind:       "Cluster",
+		APIVersion: clusterv1.GroupVersion.String(),
+	}
+
+	expectedSecret := validSecret.DeepCopy()
+	expectedSecret.Name = "test1-kubeconfig-internal"
+	expectedSecret.SetOwnerReferences([]metav1.OwnerReference{owner})
+
+	kubeconfigSecret := GenerateInternalSecretWithOwner(
+		client.ObjectKey{
+			Name:      "test1",
+			Namespace: "test",
+		},
+		[]byte(validKubeConfig),
+		owner,
+	)
+
+	g.Expect(kubeconfigSecret).NotTo(BeNil())
+	g.Expect(kubeconfigSecret).To(BeComparableTo(expectedSecret))
+}
+
 func TestGenerateSecret(t *testing.T) {
 	g := NewWithT(t)
 
@@ -417,3 +443,102 @@ func TestRegenerateClientCerts(t *testing.T) {
 
 	g.Expect(newCert.NotAfter).To(BeTemporally(">", oldCert.NotAfter))
 }
+
+func TestNewWithClientCertificateTTL(t *testing.T) {
+	g := NewWithT(t)
+
+	caKey, err := certs.NewPrivateKey()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	caCert, err := getTestCACert(caKey)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	config, err := New("foo", "https://127:0.0.1:4003", caCert, caKey, WithClientCertificateTTL(time.Hour))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(config.CurrentContext).To(Equal("foo-admin@foo"))
+	clientCert, err := certs.DecodeCertPEM(config.AuthInfos["foo-admin"].ClientCertificateData)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(clientCert.Subject.CommonName).To(Equal("kubernetes-admin"))
+	g.Expect(clientCert.NotAfter).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+}
+
+func TestClientCertRotationTime(t *testing.T) {
+	g := NewWithT(t)
+
+	caKey, err := certs.NewPrivateKey()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	caCert, err := getTestCACert(caKey)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	config, err := New("foo", "https://127:0.0.1:4003", caCert, caKey, WithClientCertificateTTL(time.Hour))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	out, err := clientcmd.Write(*config)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	kubeconfigSecret := GenerateSecretWithOwner(client.ObjectKey{Name: "foo", Namespace: "test"}, out, metav1.OwnerReference{})
+
+	rotationTime, err := ClientCertRotationTime(kubeconfigSecret, 30*time.Minute)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(rotationTime).To(BeTemporally("~", time.Now().Add(30*time.Minute), time.Minute))
+
+	g.Expect(NeedsClientCertRotation(kubeconfigSecret, 30*time.Minute)).To(BeFalse())
+	g.Expect(NeedsClientCertRotation(kubeconfigSecret, 2*time.Hour)).To(BeTrue())
+}
+
+func TestCreateSecretWithoutClientCertificate(t *testing.T) {
+	g := NewWithT(t)
+
+	caKey, err := certs.NewPrivateKey()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	caCert, err := getTestCACert(caKey)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// The CA key is not required to generate a Kubeconfig without client certificate.
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test1-ca",
+			Namespace: "test",
+		},
+		Data: map[string][]byte{
+			secret.TLSCrtDataName: certs.EncodeCertPEM(caCert),
+		},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(caSecret).Build()
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test1",
+			Namespace:   "test",
+			Annotations: map[string]string{clusterv1.WorkloadClusterAuthAnnotation: "exec"},
+		},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{
+				Host: "localhost",
+				Port: 8443,
+			},
+		},
+	}
+	g.Expect(CreateSecret(ctx, c, cluster)).To(Succeed())
+
+	s := &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Name: "test1-kubeconfig", Namespace: "test"}, s)).To(Succeed())
+
+	config, err := clientcmd.Load(s.Data[secret.KubeconfigDataName])
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(config.Clusters["test1"].Server).To(Equal("https://localhost:8443"))
+	g.Expect(config.Clusters["test1"].CertificateAuthorityData).To(Equal(certs.EncodeCertPEM(caCert)))
+	g.Expect(config.AuthInfos["test1-admin"].ClientCertificateData).To(BeEmpty())
+	g.Expect(config.AuthInfos["test1-admin"].ClientKeyData).To(BeEmpty())
+
+	g.Expect(HasClientCertificate(s)).To(BeFalse())
+	g.Expect(HasClientCertificate(validSecret)).To(BeTrue())
+	rotationTime, err := ClientCertRotationTime(s, time.Hour)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(rotationTime.IsZero()).To(BeTrue())
+	g.Expect(NeedsClientCertRotation(s, time.Hour)).To(BeFalse())
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package labels
+
+import (
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// KeyMatcher reports whether a label or annotation key matches.
+type KeyMatcher func(key string) bool
+
+// MatchPrefixes returns a KeyMatcher matching keys whose prefix (the part before "/", or the key itself
+// if it has no prefix) is exactly one of the given prefixes.
+func MatchPrefixes(prefixes ...string) KeyMatcher {
+	return func(key string) bool {
+		keyPrefix := keyPrefix(key)
+		for _, prefix := range prefixes {
+			if keyPrefix == prefix {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchDomains returns a KeyMatcher matching keys whose prefix (the part before "/", or the key itself
+// if it has no prefix) is one of the given domains or one of their subdomains.
+func MatchDomains(domains ...string) KeyMatcher {
+	return func(key string) bool {
+		keyPrefix := keyPrefix(key)
+		for _, domain := range domains {
+			if keyPrefix == domain || strings.HasSuffix(keyPrefix, "."+domain) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchAll returns a KeyMatcher matching all keys.
+func MatchAll() KeyMatcher {
+	return func(string) bool { return true }
+}
+
+// MatchAny returns a KeyMatcher matching keys matched by at least one of the given matchers.
+func MatchAny(matchers ...KeyMatcher) KeyMatcher {
+	return func(key string) bool {
+		for _, m := range matchers {
+			if m != nil && m(key) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func keyPrefix(key string) string {
+	return strings.Split(key, "/")[0]
+}
+
+// PropagationModel defines how labels or annotations are propagated from a source object to a target object.
+//
+// Keys matching Authoritative are owned by the controller and continuously enforced on the target: they are
+// added and updated to match the source, and removed from the target when they are removed from the source.
+// Keys matching OnCreate are only set when the target is created, and users or other controllers are free
+// to change them afterwards. All the other keys are never propagated, and keys on the target not propagated
+// from the source are always preserved.
+type PropagationModel struct {
+	// Authoritative matches the keys continuously enforced on the target.
+	Authoritative KeyMatcher
+
+	// OnCreate matches the keys only set on the target when it is created.
+	OnCreate KeyMatcher
+}
+
+// Select returns the subset of the source keys to be propagated to the target.
+func (p PropagationModel) Select(source map[string]string) map[string]string {
+	selected := make(map[string]string)
+	for k, v := range source {
+		if p.isAuthoritative(k) || p.isOnCreate(k) {
+			selected[k] = v
+		}
+	}
+	return selected
+}
+
+// MergeResult is the result of merging the source keys into the target.
+type MergeResult struct {
+	// Merged are the keys the target should have.
+	Merged map[string]string
+
+	// Propagated are the authoritative keys propagated from the source, sorted; they should be tracked on
+	// the target and passed as previouslyPropagated to the next Merge, so keys removed from the source can be removed from the target.
+	Propagated []string
+
+	// Changed is true if Merged is different from the current keys of the target.
+	Changed bool
+}
+
+// Merge merges the keys of the source to be propagated into the current keys of the target.
+// previouslyPropagated are the authoritative keys propagated by the previous Merge; only those are removed from the
+// target when they are not set anymore on the source, so keys set on the target by others are always preserved.
+// creating must be true when the target is being created, so keys matching OnCreate are set too.
+// The current map is not modified.
+func (p PropagationModel) Merge(current, source map[string]string, previouslyPropagated []string, creating bool) MergeResult {
+	result := MergeResult{
+		Merged:     make(map[string]string, len(current)),
+		Propagated: []string{},
+	}
+	for k, v := range current {
+		result.Merged[k] = v
+	}
+
+	for k, v := range source {
+		switch {
+		case p.isAuthoritative(k):
+			result.Propagated = append(result.Propagated, k)
+		case creating && p.isOnCreate(k):
+		default:
+			continue
+		}
+		if cur, ok := result.Merged[k]; !ok || cur != v {
+			result.Merged[k] = v
+			result.Changed = true
+		}
+	}
+
+	for _, k := range previouslyPropagated {
+		if !p.isAuthoritative(k) {
+			continue
+		}
+		if _, ok := source[k]; ok {
+			continue
+		}
+		if _, ok := result.Merged[k]; ok {
+			delete(result.Merged, k)
+			result.Changed = true
+		}
+	}
+
+	sort.Strings(result.Propagated)
+	return result
+}
+
+func (p PropagationModel) isAuthoritative(key string) bool {
+	return p.Authoritative != nil && p.Authoritative(key)
+}
+
+func (p PropagationModel) isOnCreate(key string) bool {
+	return p.OnCreate != nil && p.OnCreate(key)
+}
+
+// MachineToNodeLabels is the propagation model of labels from Machines to Nodes: only the labels with the
+// node-role.kubernetes.io prefix, or within the node-restriction.kubernetes.io and node.cluster.x-k8s.io domains,
+// are propagated and continuously enforced.
+var MachineToNodeLabels = PropagationModel{
+	Authoritative: MatchAny(
+		MatchPrefixes(clusterv1.NodeRoleLabelPrefix),
+		MatchDomains(clusterv1.NodeRestrictionLabelDomain, clusterv1.ManagedNodeLabelDomain),
+	),
+}
+
+// TemplateToObject is the propagation model of labels and annotations from the template of a MachineDeployment
+// or MachineSet to the MachineSets and Machines created from it: all the keys are propagated and continuously enforced.
+// NOTE: Those objects are reconciled using server-side apply, so keys removed from the template are removed
+// from the objects via field ownership, without the need of tracking the propagated keys.
+var TemplateToObject = PropagationModel{
+	Authoritative: MatchAll(),
+}
+
+// ClusterToObject is the propagation model of labels and annotations from the spec.metadataPropagation of a Cluster
+// to all the objects of the Cluster: all the keys are propagated and continuously enforced, overriding conflicting
+// keys set on the objects. The propagated keys are tracked on each object using the
+// clusterv1.LabelsFromClusterAnnotation and clusterv1.AnnotationsFromClusterAnnotation annotations.
+var ClusterToObject = PropagationModel{
+	Authoritative: MatchAll(),
+}
+
+// PropagateClusterMetadata propagates the labels and annotations defined in the spec.metadataPropagation of the
+// Cluster to obj according to ClusterToObject, removing the keys previously propagated and not defined anymore;
+// it returns true if the labels or annotations of obj have been changed.
+func PropagateClusterMetadata(cluster *clusterv1.Cluster, obj metav1.Object) bool {
+	return propagateClusterMetadata(cluster, obj, MatchAll())
+}
+
+// PropagateClusterMetadataToNode propagates the labels and annotations defined in the spec.metadataPropagation of the
+// Cluster to a Node like PropagateClusterMetadata, but only the labels allowed by MachineToNodeLabels are propagated,
+// like for the labels propagated from Machines.
+func PropagateClusterMetadataToNode(cluster *clusterv1.Cluster, node metav1.Object) bool {
+	return propagateClusterMetadata(cluster, node, MachineToNodeLabels.Authoritative)
+}
+
+// propagateClusterMetadata implements PropagateClusterMetadata, only propagating the labels matching selectLabels.
+// NOTE: The labels not matching selectLabels are removed from the source, but not from the merge, so the labels
+// previously propagated are removed from obj if they don't match anymore.
+func propagateClusterMetadata(cluster *clusterv1.Cluster, obj metav1.Object, selectLabels KeyMatcher) bool {
+	var sourceLabels, sourceAnnotations map[string]string
+	if cluster.Spec.MetadataPropagation != nil {
+		sourceLabels = PropagationModel{Authoritative: selectLabels}.Select(cluster.Spec.MetadataPropagation.Labels)
+		sourceAnnotations = cluster.Spec.MetadataPropagation.Annotations
+	}
+
+	currentAnnotations := obj.GetAnnotations()
+	previousLabels := splitTrackedKeys(currentAnnotations[clusterv1.LabelsFromClusterAnnotation])
+	previousAnnotations := splitTrackedKeys(currentAnnotations[clusterv1.AnnotationsFromClusterAnnotation])
+	if len(sourceLabels) == 0 && len(sourceAnnotations) == 0 && len(previousLabels) == 0 && len(previousAnnotations) == 0 {
+		return false
+	}
+
+	mergedLabels := ClusterToObject.Merge(obj.GetLabels(), sourceLabels, previousLabels, false)
+	mergedAnnotations := ClusterToObject.Merge(currentAnnotations, sourceAnnotations, previousAnnotations, false)
+	changed := mergedLabels.Changed || mergedAnnotations.Changed
+
+	// Track the propagated keys, so they can be removed when they are removed from the Cluster.
+	for trackingAnnotation, propagated := range map[string][]string{
+		clusterv1.LabelsFromClusterAnnotation:      mergedLabels.Propagated,
+		clusterv1.AnnotationsFromClusterAnnotation: mergedAnnotations.Propagated,
+	} {
+		current, ok := mergedAnnotations.Merged[trackingAnnotation]
+		switch {
+		case len(propagated) == 0 && ok:
+			delete(mergedAnnotations.Merged, trackingAnnotation)
+			changed = true
+		case len(propagated) > 0 && current != strings.Join(propagated, ","):
+			mergedAnnotations.Merged[trackingAnnotation] = strings.Join(propagated, ",")
+			changed = true
+		}
+	}
+
+	if !changed {
+		return false
+	}
+	obj.SetLabels(mergedLabels.Merged)
+	obj.SetAnnotations(mergedAnnotations.Merged)
+	return true
+}
+
+func splitTrackedKeys(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
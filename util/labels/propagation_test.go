@@ -0,0 +1,251 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package labels
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestMachineToNodeLabelsSelect(t *testing.T) {
+	// Create managedLabels map from known managed prefixes.
+	managedLabels := map[string]string{
+		clusterv1.NodeRoleLabelPrefix + "/anyRole": "",
+
+		clusterv1.ManagedNodeLabelDomain:                                  "",
+		"custom-prefix." + clusterv1.ManagedNodeLabelDomain:               "",
+		clusterv1.ManagedNodeLabelDomain + "/anything":                    "",
+		"custom-prefix." + clusterv1.ManagedNodeLabelDomain + "/anything": "",
+
+		clusterv1.NodeRestrictionLabelDomain:                                  "",
+		"custom-prefix." + clusterv1.NodeRestrictionLabelDomain:               "",
+		clusterv1.NodeRestrictionLabelDomain + "/anything":                    "",
+		"custom-prefix." + clusterv1.NodeRestrictionLabelDomain + "/anything": "",
+	}
+
+	// Append arbitrary labels.
+	allLabels := map[string]string{
+		"foo":                               "",
+		"bar":                               "",
+		"company.xyz/node.cluster.x-k8s.io": "not-managed",
+		"gpu-node.cluster.x-k8s.io":         "not-managed",
+		"company.xyz/node-restriction.kubernetes.io": "not-managed",
+		"gpu-node-restriction.kubernetes.io":         "not-managed",
+	}
+	for k, v := range managedLabels {
+		allLabels[k] = v
+	}
+
+	g := NewWithT(t)
+	got := MachineToNodeLabels.Select(allLabels)
+	g.Expect(got).To(BeEquivalentTo(managedLabels))
+}
+
+func TestPropagationModelMerge(t *testing.T) {
+	model := PropagationModel{
+		Authoritative: MatchDomains("owned.example.com"),
+		OnCreate:      MatchPrefixes("initial"),
+	}
+
+	tests := []struct {
+		name                 string
+		current              map[string]string
+		source               map[string]string
+		previouslyPropagated []string
+		creating             bool
+		want                 MergeResult
+	}{
+		{
+			name:    "authoritative keys are added and updated, other keys are preserved",
+			current: map[string]string{"owned.example.com/a": "old", "foo": "bar"},
+			source:  map[string]string{"owned.example.com/a": "new", "sub.owned.example.com/b": "b", "not-propagated": "x"},
+			want: MergeResult{
+				Merged:     map[string]string{"owned.example.com/a": "new", "sub.owned.example.com/b": "b", "foo": "bar"},
+				Propagated: []string{"owned.example.com/a", "sub.owned.example.com/b"},
+				Changed:    true,
+			},
+		},
+		{
+			name:                 "previously propagated authoritative keys are removed when removed from the source",
+			current:              map[string]string{"owned.example.com/a": "a", "owned.example.com/b": "b", "foo": "bar"},
+			source:               map[string]string{"owned.example.com/a": "a"},
+			previouslyPropagated: []string{"owned.example.com/a", "owned.example.com/b", "foo"},
+			want: MergeResult{
+				Merged:     map[string]string{"owned.example.com/a": "a", "foo": "bar"},
+				Propagated: []string{"owned.example.com/a"},
+				Changed:    true,
+			},
+		},
+		{
+			name:    "authoritative keys not propagated before are preserved",
+			current: map[string]string{"owned.example.com/a": "set-by-others"},
+			source:  map[string]string{},
+			want: MergeResult{
+				Merged:     map[string]string{"owned.example.com/a": "set-by-others"},
+				Propagated: []string{},
+			},
+		},
+		{
+			name:     "on create keys are set when creating",
+			source:   map[string]string{"initial/a": "a"},
+			creating: true,
+			want: MergeResult{
+				Merged:     map[string]string{"initial/a": "a"},
+				Propagated: []string{},
+				Changed:    true,
+			},
+		},
+		{
+			name:    "on create keys are not enforced after create",
+			current: map[string]string{"initial/a": "changed"},
+			source:  map[string]string{"initial/a": "a", "initial/b": "b"},
+			want: MergeResult{
+				Merged:     map[string]string{"initial/a": "changed"},
+				Propagated: []string{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			current := map[string]string{}
+			for k, v := range tt.current {
+				current[k] = v
+			}
+			g.Expect(model.Merge(current, tt.source, tt.previouslyPropagated, tt.creating)).To(Equal(tt.want))
+			// The current map must not be modified.
+			g.Expect(current).To(HaveLen(len(tt.current)))
+		})
+	}
+}
+
+func TestPropagateClusterMetadata(t *testing.T) {
+	tests := []struct {
+		name            string
+		metadata        *clusterv1.ObjectMeta
+		labels          map[string]string
+		annotations     map[string]string
+		wantChanged     bool
+		wantLabels      map[string]string
+		wantAnnotations map[string]string
+	}{
+		{
+			name:        "no-op if nothing is or was propagated",
+			labels:      map[string]string{"foo": "bar"},
+			wantLabels:  map[string]string{"foo": "bar"},
+			wantChanged: false,
+		},
+		{
+			name: "propagates labels and annotations, overriding conflicting keys",
+			metadata: &clusterv1.ObjectMeta{
+				Labels:      map[string]string{"team": "a", "env": "prod"},
+				Annotations: map[string]string{"owner": "team-a"},
+			},
+			labels:      map[string]string{"env": "dev", "foo": "bar"},
+			annotations: map[string]string{"baz": "qux"},
+			wantChanged: true,
+			wantLabels:  map[string]string{"team": "a", "env": "prod", "foo": "bar"},
+			wantAnnotations: map[string]string{
+				"baz":                                 "qux",
+				"owner":                               "team-a",
+				clusterv1.LabelsFromClusterAnnotation: "env,team",
+				clusterv1.AnnotationsFromClusterAnnotation: "owner",
+			},
+		},
+		{
+			name: "removes keys previously propagated and preserves the others",
+			metadata: &clusterv1.ObjectMeta{
+				Labels: map[string]string{"team": "a"},
+			},
+			labels: map[string]string{"team": "a", "env": "prod", "foo": "bar"},
+			annotations: map[string]string{
+				"owner":                               "team-a",
+				clusterv1.LabelsFromClusterAnnotation: "env,team",
+				clusterv1.AnnotationsFromClusterAnnotation: "owner",
+			},
+			wantChanged: true,
+			wantLabels:  map[string]string{"team": "a", "foo": "bar"},
+			wantAnnotations: map[string]string{
+				clusterv1.LabelsFromClusterAnnotation: "team",
+			},
+		},
+		{
+			name:   "removes all the keys previously propagated and the tracking annotations",
+			labels: map[string]string{"team": "a", "foo": "bar"},
+			annotations: map[string]string{
+				clusterv1.LabelsFromClusterAnnotation: "team",
+			},
+			wantChanged:     true,
+			wantLabels:      map[string]string{"foo": "bar"},
+			wantAnnotations: map[string]string{},
+		},
+		{
+			name: "no-op if already propagated",
+			metadata: &clusterv1.ObjectMeta{
+				Labels: map[string]string{"team": "a"},
+			},
+			labels: map[string]string{"team": "a"},
+			annotations: map[string]string{
+				clusterv1.LabelsFromClusterAnnotation: "team",
+			},
+			wantChanged: false,
+			wantLabels:  map[string]string{"team": "a"},
+			wantAnnotations: map[string]string{
+				clusterv1.LabelsFromClusterAnnotation: "team",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := &clusterv1.Cluster{Spec: clusterv1.ClusterSpec{MetadataPropagation: tt.metadata}}
+			obj := &metav1.ObjectMeta{Labels: tt.labels, Annotations: tt.annotations}
+
+			g.Expect(PropagateClusterMetadata(cluster, obj)).To(Equal(tt.wantChanged))
+			g.Expect(obj.Labels).To(Equal(tt.wantLabels))
+			g.Expect(obj.Annotations).To(Equal(tt.wantAnnotations))
+		})
+	}
+}
+
+func TestPropagateClusterMetadataToNode(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{Spec: clusterv1.ClusterSpec{MetadataPropagation: &clusterv1.ObjectMeta{
+		Labels:      map[string]string{"team": "a", "node.cluster.x-k8s.io/team": "a"},
+		Annotations: map[string]string{"owner": "team-a"},
+	}}}
+	// The team label has been propagated before it was filtered, so it must be removed.
+	node := &metav1.ObjectMeta{
+		Labels:      map[string]string{"team": "a", "foo": "bar"},
+		Annotations: map[string]string{clusterv1.LabelsFromClusterAnnotation: "team"},
+	}
+
+	g.Expect(PropagateClusterMetadataToNode(cluster, node)).To(BeTrue())
+	g.Expect(node.Labels).To(Equal(map[string]string{"node.cluster.x-k8s.io/team": "a", "foo": "bar"}))
+	g.Expect(node.Annotations).To(Equal(map[string]string{
+		"owner":                               "team-a",
+		clusterv1.LabelsFromClusterAnnotation: "node.cluster.x-k8s.io/team",
+		clusterv1.AnnotationsFromClusterAnnotation: "owner",
+	}))
+}
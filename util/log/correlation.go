@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// CorrelationIDKey is the key used for the correlation ID in the k/v pairs of the logger.
+const CorrelationIDKey = "correlationID"
+
+// CorrelationID returns the ID correlating the reconciles of all the controllers working on a Cluster
+// in response to the same change of the Cluster, e.g. its creation, a Kubernetes version upgrade or its deletion.
+// If the Cluster has the CorrelationIDAnnotation its value is used, otherwise the ID is derived from the UID,
+// the generation and the deletion state of the Cluster, so it is stable across controllers and controller restarts
+// without requiring any additional write to the API server.
+func CorrelationID(cluster *clusterv1.Cluster) string {
+	if id, ok := cluster.GetAnnotations()[clusterv1.CorrelationIDAnnotation]; ok && id != "" {
+		return id
+	}
+
+	cause := fmt.Sprintf("generation-%d", cluster.GetGeneration())
+	if !cluster.GetDeletionTimestamp().IsZero() {
+		cause = "delete"
+	}
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s/%s", cluster.GetUID(), cause)))
+	return hex.EncodeToString(hash[:8])
+}
+
+// AddCorrelationID adds the correlation ID of the Cluster as k/v pair to the logger in ctx.
+func AddCorrelationID(ctx context.Context, cluster *clusterv1.Cluster) (context.Context, logr.Logger) {
+	log := ctrl.LoggerFrom(ctx).WithValues(CorrelationIDKey, CorrelationID(cluster))
+	return ctrl.LoggerInto(ctx, log), log
+}
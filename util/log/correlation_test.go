@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestCorrelationID(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-cluster",
+			Namespace:  metav1.NamespaceDefault,
+			UID:        "7c8d1a9e-1f4e-4b8a-9a3c-5a1f0f0b2c3d",
+			Generation: 1,
+		},
+	}
+
+	id := CorrelationID(cluster)
+	g.Expect(id).To(HaveLen(16))
+
+	// The ID is stable for the same Cluster generation.
+	g.Expect(CorrelationID(cluster.DeepCopy())).To(Equal(id))
+
+	// The ID changes when the Cluster spec changes.
+	updated := cluster.DeepCopy()
+	updated.Generation = 2
+	g.Expect(CorrelationID(updated)).ToNot(Equal(id))
+
+	// The ID changes when the Cluster is deleted.
+	deleted := cluster.DeepCopy()
+	now := metav1.Now()
+	deleted.DeletionTimestamp = &now
+	g.Expect(CorrelationID(deleted)).ToNot(Equal(id))
+
+	// The ID can be overridden with the CorrelationIDAnnotation.
+	annotated := cluster.DeepCopy()
+	annotated.Annotations = map[string]string{clusterv1.CorrelationIDAnnotation: "upgrade-1.29"}
+	g.Expect(CorrelationID(annotated)).To(Equal("upgrade-1.29"))
+}
+
+func TestAddCorrelationID(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-cluster",
+			Namespace:   metav1.NamespaceDefault,
+			Annotations: map[string]string{clusterv1.CorrelationIDAnnotation: "upgrade-1.29"},
+		},
+	}
+
+	// Create fake log sink so we can later verify the added k/v pairs.
+	ctx := ctrl.LoggerInto(context.Background(), logr.New(&fakeLogSink{}))
+
+	_, logger := AddCorrelationID(ctx, cluster)
+	g.Expect(logger.GetSink().(fakeLogSink).keysAndValues).To(Equal([]interface{}{CorrelationIDKey, "upgrade-1.29"}))
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patch
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// ConditionsListMapObject is implemented by the API types opting in to ApplyConditions.
+//
+// The status.conditions field of those types must be a list map keyed by type, i.e. it must have the
+// +listType=map and +listMapKey=type markers on the field; clusterv1.Conditions is an atomic list, so without
+// those markers applying a condition would take ownership of all the conditions and remove the ones not applied.
+type ConditionsListMapObject interface {
+	client.Object
+	conditions.Getter
+
+	// ConditionsListMap is a marker method signaling that status.conditions is a list map keyed by type.
+	ConditionsListMap()
+}
+
+// ApplyConditions applies the conditions with the given types from obj to the status of the object in the API server
+// using server-side apply, with fieldManager as the field owner; other fields of the object are not changed.
+//
+// As status.conditions of obj is a list map keyed by type, each condition type is owned by the field manager applying
+// it, and different controllers can set different conditions of the same object without conflicts, and without the
+// need to coordinate using WithOwnedConditions. Conditions with the given types which do not exist in obj are removed
+// from the object, if not owned by other field managers too. Ownership of the applied conditions is forced, so the
+// controller applying a condition always wins over other field managers.
+//
+// NOTE: ApplyConditions and Helper can't be used to set the conditions of the same object: Helper patches the
+// conditions using a JSON merge patch which replaces the whole list, thus taking ownership of all the conditions and
+// removing the ones applied by other field managers since the object was read.
+// NOTE: The status of the object in the API server is not read back into obj.
+func ApplyConditions(ctx context.Context, c client.Client, obj ConditionsListMapObject, fieldManager string, conditionTypes ...clusterv1.ConditionType) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return errors.Wrapf(err, "failed to apply conditions to %s", klog.KObj(obj))
+	}
+
+	applyObj, err := conditionsApplyObject(obj, gvk, conditionTypes)
+	if err != nil {
+		return errors.Wrapf(err, "failed to apply conditions to %s %s", gvk.Kind, klog.KObj(obj))
+	}
+
+	if err := c.Status().Patch(ctx, applyObj, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership); err != nil {
+		return errors.Wrapf(err, "failed to apply conditions to %s %s", gvk.Kind, klog.KObj(obj))
+	}
+	return nil
+}
+
+// conditionsApplyObject returns the object to be applied for setting the conditions with the given types from obj,
+// i.e. an object with only the identifying fields and the conditions in status.
+func conditionsApplyObject(obj ConditionsListMapObject, gvk schema.GroupVersionKind, conditionTypes []clusterv1.ConditionType) (*unstructured.Unstructured, error) {
+	applyConditions := []interface{}{}
+	for _, t := range conditionTypes {
+		condition := conditions.Get(obj, t)
+		if condition == nil {
+			continue
+		}
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(condition)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert condition %s to Unstructured", t)
+		}
+		applyConditions = append(applyConditions, u)
+	}
+
+	applyObj := &unstructured.Unstructured{}
+	applyObj.SetGroupVersionKind(gvk)
+	applyObj.SetNamespace(obj.GetNamespace())
+	applyObj.SetName(obj.GetName())
+	if err := unstructured.SetNestedSlice(applyObj.Object, applyConditions, "status", "conditions"); err != nil {
+		return nil, errors.Wrap(err, "failed to set status.conditions")
+	}
+	return applyObj, nil
+}
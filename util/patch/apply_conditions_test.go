@@ -0,0 +1,204 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patch
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// conditionsListMapObject is an Unstructured object opting in to ApplyConditions.
+type conditionsListMapObject struct {
+	*unstructured.Unstructured
+}
+
+func (o conditionsListMapObject) GetConditions() clusterv1.Conditions {
+	return conditions.UnstructuredGetter(o.Unstructured).GetConditions()
+}
+
+func (o conditionsListMapObject) ConditionsListMap() {}
+
+var conditionsListMapGVK = schema.GroupVersionKind{Group: "test.cluster.x-k8s.io", Version: "v1beta1", Kind: "ConditionsListMap"}
+
+// conditionsListMapCRD returns a CRD for conditionsListMapGVK with status.conditions defined as a list map keyed by type.
+func conditionsListMapCRD() *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "conditionslistmaps.test.cluster.x-k8s.io",
+		},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: conditionsListMapGVK.Group,
+			Scope: apiextensionsv1.NamespaceScoped,
+			Names: apiextensionsv1.CustomResourceDefinitionNames{
+				Kind:   conditionsListMapGVK.Kind,
+				Plural: "conditionslistmaps",
+			},
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{
+					Name:    conditionsListMapGVK.Version,
+					Served:  true,
+					Storage: true,
+					Subresources: &apiextensionsv1.CustomResourceSubresources{
+						Status: &apiextensionsv1.CustomResourceSubresourceStatus{},
+					},
+					Schema: &apiextensionsv1.CustomResourceValidation{
+						OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+							Type: "object",
+							Properties: map[string]apiextensionsv1.JSONSchemaProps{
+								"status": {
+									Type: "object",
+									Properties: map[string]apiextensionsv1.JSONSchemaProps{
+										"conditions": {
+											Type:         "array",
+											XListType:    ptr.To("map"),
+											XListMapKeys: []string{"type"},
+											Items: &apiextensionsv1.JSONSchemaPropsOrArray{
+												Schema: &apiextensionsv1.JSONSchemaProps{
+													Type:                   "object",
+													Required:               []string{"type"},
+													XPreserveUnknownFields: ptr.To(true),
+													Properties: map[string]apiextensionsv1.JSONSchemaProps{
+														"type": {Type: "string"},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestConditionsApplyObject(t *testing.T) {
+	g := NewWithT(t)
+
+	now := metav1.Now()
+	obj := conditionsListMapObject{&unstructured.Unstructured{}}
+	obj.SetNamespace("ns1")
+	obj.SetName("obj1")
+	obj.SetLabels(map[string]string{"foo": "bar"})
+	g.Expect(unstructured.SetNestedField(obj.Object, true, "spec", "paused")).To(Succeed())
+	conditions.UnstructuredSetter(obj.Unstructured).SetConditions(clusterv1.Conditions{
+		{Type: clusterv1.ReadyCondition, Status: corev1.ConditionTrue, LastTransitionTime: now},
+		{Type: clusterv1.InfrastructureReadyCondition, Status: corev1.ConditionFalse, Severity: clusterv1.ConditionSeverityWarning, Reason: "Foo", LastTransitionTime: now},
+	})
+
+	got, err := conditionsApplyObject(obj, conditionsListMapGVK, []clusterv1.ConditionType{clusterv1.InfrastructureReadyCondition, clusterv1.ControlPlaneReadyCondition})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got.Object).To(Equal(map[string]interface{}{
+		"apiVersion": conditionsListMapGVK.GroupVersion().String(),
+		"kind":       conditionsListMapGVK.Kind,
+		"metadata": map[string]interface{}{
+			"namespace": "ns1",
+			"name":      "obj1",
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{
+					"type":               "InfrastructureReady",
+					"status":             "False",
+					"severity":           "Warning",
+					"reason":             "Foo",
+					"lastTransitionTime": now.UTC().Format("2006-01-02T15:04:05Z"),
+				},
+			},
+		},
+	}))
+}
+
+func TestApplyConditions(t *testing.T) {
+	g := NewWithT(t)
+
+	crd := conditionsListMapCRD()
+	g.Expect(env.Create(ctx, crd)).To(Succeed())
+	defer func() {
+		g.Expect(env.Delete(ctx, crd)).To(Succeed())
+	}()
+	g.Eventually(func(g Gomega) {
+		g.Expect(env.Get(ctx, client.ObjectKeyFromObject(crd), crd)).To(Succeed())
+		established := false
+		for _, c := range crd.Status.Conditions {
+			if c.Type == apiextensionsv1.Established && c.Status == apiextensionsv1.ConditionTrue {
+				established = true
+			}
+		}
+		g.Expect(established).To(BeTrue())
+	}, timeout).Should(Succeed())
+
+	ns, err := env.CreateNamespace(ctx, "test-apply-conditions")
+	g.Expect(err).ToNot(HaveOccurred())
+	defer func() {
+		g.Expect(env.Delete(ctx, ns)).To(Succeed())
+	}()
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(conditionsListMapGVK)
+	obj.SetGenerateName("test-")
+	obj.SetNamespace(ns.Name)
+	g.Eventually(func() error {
+		return env.Create(ctx, obj)
+	}, timeout).Should(Succeed())
+	defer func() {
+		g.Expect(env.Delete(ctx, obj)).To(Succeed())
+	}()
+	key := client.ObjectKeyFromObject(obj)
+
+	getConditions := func() clusterv1.Conditions {
+		objAfter := &unstructured.Unstructured{}
+		objAfter.SetGroupVersionKind(conditionsListMapGVK)
+		if err := env.Get(ctx, key, objAfter); err != nil {
+			return clusterv1.Conditions{}
+		}
+		return conditions.UnstructuredGetter(objAfter).GetConditions()
+	}
+
+	t.Log("Applying a condition with a first field manager")
+	first := conditionsListMapObject{obj.DeepCopy()}
+	conditions.MarkTrue(conditions.UnstructuredSetter(first.Unstructured), clusterv1.InfrastructureReadyCondition)
+	g.Expect(ApplyConditions(ctx, env, first, "first", clusterv1.InfrastructureReadyCondition)).To(Succeed())
+
+	t.Log("Applying another condition with a second field manager, using an object not aware of the first condition")
+	second := conditionsListMapObject{obj.DeepCopy()}
+	conditions.MarkFalse(conditions.UnstructuredSetter(second.Unstructured), clusterv1.ControlPlaneReadyCondition, "Foo", clusterv1.ConditionSeverityInfo, "")
+	g.Expect(ApplyConditions(ctx, env, second, "second", clusterv1.ControlPlaneReadyCondition)).To(Succeed())
+
+	t.Log("Validating both the conditions are set")
+	g.Eventually(getConditions, timeout).Should(conditions.MatchConditions(append(first.GetConditions(), second.GetConditions()...)))
+
+	t.Log("Removing the condition owned by the first field manager")
+	conditions.Delete(conditions.UnstructuredSetter(first.Unstructured), clusterv1.InfrastructureReadyCondition)
+	g.Expect(ApplyConditions(ctx, env, first, "first", clusterv1.InfrastructureReadyCondition)).To(Succeed())
+
+	t.Log("Validating only the condition owned by the second field manager is set")
+	g.Eventually(getConditions, timeout).Should(conditions.MatchConditions(second.GetConditions()))
+}
@@ -16,7 +16,11 @@ limitations under the License.
 
 package patch
 
-import clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+import (
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
 
 // Option is some configuration that modifies options for a patch request.
 type Option interface {
@@ -37,6 +41,11 @@ type HelperOptions struct {
 	// OwnedConditions defines condition types owned by the controller.
 	// In case of conflicts for the owned conditions, the patch helper will always use the value provided by the controller.
 	OwnedConditions []clusterv1.ConditionType
+
+	// ConditionsRetryBackoff defines the backoff used to retry the patch of the conditions on conflicts, i.e. when
+	// the object has been changed by another process; if not set, the patch is retried up to 5 times, starting
+	// with a 100ms delay.
+	ConditionsRetryBackoff *wait.Backoff
 }
 
 // WithForceOverwriteConditions allows the patch helper to overwrite conditions in case of conflicts.
@@ -67,3 +76,16 @@ type WithOwnedConditions struct {
 func (w WithOwnedConditions) ApplyToHelper(in *HelperOptions) {
 	in.OwnedConditions = w.Conditions
 }
+
+// WithConditionsRetryBackoff allows to define the backoff used to retry the patch of the conditions on conflicts;
+// on each retry the latest version of the object is read again, and the condition changes are merged on it.
+// Controllers changing conditions of objects with high churn, e.g. objects changed by several controllers,
+// might use a backoff with more steps than the default.
+type WithConditionsRetryBackoff struct {
+	Backoff wait.Backoff
+}
+
+// ApplyToHelper applies this configuration to the given HelperOptions.
+func (w WithConditionsRetryBackoff) ApplyToHelper(in *HelperOptions) {
+	in.ConditionsRetryBackoff = &w.Backoff
+}
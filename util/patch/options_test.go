@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestWithConditionsRetryBackoff(t *testing.T) {
+	tests := []struct {
+		name      string
+		conflicts int
+		opts      []Option
+		wantErr   bool
+	}{
+		{
+			name:      "Retries conflicts with the default backoff",
+			conflicts: 2,
+		},
+		{
+			name:      "Fails if conflicts exceed the default backoff steps",
+			conflicts: 5,
+			wantErr:   true,
+		},
+		{
+			name:      "Retries conflicts with a custom backoff",
+			conflicts: 5,
+			opts:      []Option{WithConditionsRetryBackoff{Backoff: wait.Backoff{Steps: 10, Duration: time.Millisecond}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			scheme := runtime.NewScheme()
+			g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+			cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "cluster1"}}
+			conflicts := tt.conflicts
+			c := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(cluster).
+				WithStatusSubresource(&clusterv1.Cluster{}).
+				WithInterceptorFuncs(interceptor.Funcs{
+					SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+						if conflicts > 0 {
+							conflicts--
+							return apierrors.NewConflict(schema.GroupResource{Group: clusterv1.GroupVersion.Group, Resource: "clusters"}, obj.GetName(), nil)
+						}
+						return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+					},
+				}).
+				Build()
+
+			g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(cluster), cluster)).To(Succeed())
+			patcher, err := NewHelper(cluster, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			conditions.MarkTrue(cluster, clusterv1.ReadyCondition)
+			err = patcher.Patch(context.Background(), cluster, tt.opts...)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+
+			got := &clusterv1.Cluster{}
+			g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(cluster), got)).To(Succeed())
+			g.Expect(conditions.IsTrue(got, clusterv1.ReadyCondition)).To(BeTrue())
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -35,8 +36,19 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/tracing"
 )
 
+// defaultConditionsRetryBackoff is the backoff used to retry the patch of the conditions on conflicts,
+// if not set with WithConditionsRetryBackoff.
+//
+// This has been copied from https://github.com/kubernetes/kubernetes/blob/release-1.16/pkg/controller/controller_utils.go#L86-L88.
+var defaultConditionsRetryBackoff = wait.Backoff{
+	Steps:    5,
+	Duration: 100 * time.Millisecond,
+	Jitter:   1.0,
+}
+
 // Helper is a utility for ensuring the proper patching of objects.
 type Helper struct {
 	client       client.Client
@@ -51,6 +63,7 @@ type Helper struct {
 
 // NewHelper returns an initialized Helper. Use NewHelper before changing
 // obj. After changing obj use Helper.Patch to persist your changes.
+// NOTE: Helper must not be used to set the conditions of objects whose conditions are set using ApplyConditions.
 func NewHelper(obj client.Object, crClient client.Client) (*Helper, error) {
 	// Return early if the object is nil.
 	if util.IsNil(obj) {
@@ -83,7 +96,14 @@ func NewHelper(obj client.Object, crClient client.Client) (*Helper, error) {
 }
 
 // Patch will attempt to patch the given object, including its status.
-func (h *Helper) Patch(ctx context.Context, obj client.Object, opts ...Option) error {
+func (h *Helper) Patch(ctx context.Context, obj client.Object, opts ...Option) (reterr error) {
+	ctx, span := tracing.Start(ctx, "patch.Helper.Patch",
+		attribute.String("k8s.object.kind", h.gvk.Kind),
+		attribute.String("k8s.namespace.name", h.beforeObject.GetNamespace()),
+		attribute.String("k8s.object.name", h.beforeObject.GetName()),
+	)
+	defer func() { tracing.End(span, reterr) }()
+
 	// Return early if the object is nil.
 	if util.IsNil(obj) {
 		return errors.Errorf("failed to patch %s %s: modified object is nil", h.gvk.Kind, klog.KObj(h.beforeObject))
@@ -138,7 +158,11 @@ func (h *Helper) Patch(ctx context.Context, obj client.Object, opts ...Option) e
 	// Given that we pass in metadata.resourceVersion to perform a 3-way-merge conflict resolution,
 	// patching conditions first avoids an extra loop if spec or status patch succeeds first
 	// given that causes the resourceVersion to mutate.
-	if err := h.patchStatusConditions(ctx, obj, options.ForceOverwriteConditions, options.OwnedConditions); err != nil {
+	backoff := defaultConditionsRetryBackoff
+	if options.ConditionsRetryBackoff != nil {
+		backoff = *options.ConditionsRetryBackoff
+	}
+	if err := h.patchStatusConditions(ctx, obj, options.ForceOverwriteConditions, options.OwnedConditions, backoff); err != nil {
 		errs = append(errs, err)
 	}
 	// Then proceed to patch the rest of the object.
@@ -188,7 +212,7 @@ func (h *Helper) patchStatus(ctx context.Context, obj client.Object) error {
 //
 // Condition changes are then applied to the latest version of the object, and if there are
 // no unresolvable conflicts, the patch is sent again.
-func (h *Helper) patchStatusConditions(ctx context.Context, obj client.Object, forceOverwrite bool, ownedConditions []clusterv1.ConditionType) error {
+func (h *Helper) patchStatusConditions(ctx context.Context, obj client.Object, forceOverwrite bool, ownedConditions []clusterv1.ConditionType, backoff wait.Backoff) error {
 	// Nothing to do if the object isn't a condition patcher.
 	if !h.isConditionsSetter {
 		return nil
@@ -222,17 +246,7 @@ func (h *Helper) patchStatusConditions(ctx context.Context, obj client.Object, f
 	// Make a copy of the object and store the key used if we have conflicts.
 	key := client.ObjectKeyFromObject(after)
 
-	// Define and start a backoff loop to handle conflicts
-	// between controllers working on the same object.
-	//
-	// This has been copied from https://github.com/kubernetes/kubernetes/blob/release-1.16/pkg/controller/controller_utils.go#L86-L88.
-	backoff := wait.Backoff{
-		Steps:    5,
-		Duration: 100 * time.Millisecond,
-		Jitter:   1.0,
-	}
-
-	// Start the backoff loop and return errors if any.
+	// Start the backoff loop to handle conflicts between controllers working on the same object, and return errors if any.
 	return wait.ExponentialBackoff(backoff, func() (bool, error) {
 		latest, ok := before.DeepCopyObject().(conditions.Setter)
 		if !ok {
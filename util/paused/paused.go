@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package paused implements helpers for reconcilers of paused objects.
+package paused
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// EnsurePausedCondition sets the Paused condition on the object, listing the active pause reasons of the Cluster
+// and of the object; the object is patched right away if the condition changed.
+// It returns true if the Cluster or the object is paused.
+//
+// Given that the object types do not have v1beta2 conditions yet, the condition is stored in the v1beta1 conditions
+// of the object, in a format which is converted to the Paused v1beta2 condition by ConvertFromV1Beta1Conditions.
+//
+// This util is meant to be called in reconcilers instead of checking if the object is paused; if the object is paused,
+// reconcilers should return without making any other change to the object or to other resources.
+func EnsurePausedCondition(ctx context.Context, c client.Client, cluster *clusterv1.Cluster, o conditions.Setter) (isPaused bool, err error) {
+	paused := annotations.PausedCondition(cluster, o)
+	condition := &clusterv1.Condition{
+		Type:    clusterv1.ConditionType(paused.Type),
+		Status:  corev1.ConditionStatus(paused.Status),
+		Reason:  paused.Reason,
+		Message: paused.Message,
+	}
+	isPaused = condition.Status == corev1.ConditionTrue
+
+	if current := conditions.Get(o, condition.Type); current != nil &&
+		current.Status == condition.Status && current.Reason == condition.Reason && current.Message == condition.Message {
+		return isPaused, nil
+	}
+
+	original, ok := o.DeepCopyObject().(client.Object)
+	if !ok {
+		return isPaused, errors.Errorf("failed to set %s condition on %s: failed to copy object", condition.Type, klog.KObj(o))
+	}
+	conditions.Set(o, condition)
+	if err := c.Status().Patch(ctx, o, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+		return isPaused, errors.Wrapf(err, "failed to set %s condition on %s", condition.Type, klog.KObj(o))
+	}
+	return isPaused, nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package paused
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestEnsurePausedCondition(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	_ = clusterv1.AddToScheme(scheme)
+
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault}}
+	machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault}}
+	patches := 0
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, machine).WithStatusSubresource(&clusterv1.Machine{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourcePatch: func(ctx context.Context, c client.Client, subResource string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+				patches++
+				return c.SubResource(subResource).Patch(ctx, obj, patch, opts...)
+			},
+		}).Build()
+
+	// pausedCondition returns the Paused condition persisted on the Machine.
+	pausedCondition := func() *clusterv1.Condition {
+		actual := &clusterv1.Machine{}
+		g.Expect(c.Get(ctx, client.ObjectKeyFromObject(machine), actual)).To(Succeed())
+		return conditions.Get(actual, clusterv1.PausedV1Beta2Condition)
+	}
+
+	// Sets the condition to false if not paused.
+	isPaused, err := EnsurePausedCondition(ctx, c, cluster, machine)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(isPaused).To(BeFalse())
+	g.Expect(pausedCondition().Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(pausedCondition().Reason).To(Equal(clusterv1.NotPausedV1Beta2Reason))
+
+	// Does not patch the object if the condition did not change.
+	patchesBefore := patches
+	isPaused, err = EnsurePausedCondition(ctx, c, cluster, machine)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(isPaused).To(BeFalse())
+	g.Expect(patches).To(Equal(patchesBefore))
+
+	// Sets the condition to true listing the pause reasons if paused.
+	_, err = annotations.SetPauseReason(machine, "clusterctl", "move in progress")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(c.Update(ctx, machine)).To(Succeed())
+	cluster.Spec.Paused = true
+
+	isPaused, err = EnsurePausedCondition(ctx, c, cluster, machine)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(isPaused).To(BeTrue())
+	g.Expect(pausedCondition().Status).To(Equal(corev1.ConditionTrue))
+	g.Expect(pausedCondition().Reason).To(Equal(clusterv1.PausedV1Beta2Reason))
+	g.Expect(pausedCondition().Message).To(Equal("* cluster.spec.paused\n* clusterctl: move in progress"))
+
+	// Sets the condition back to false when unpaused.
+	annotations.RemovePauseReason(machine, "clusterctl")
+	g.Expect(c.Update(ctx, machine)).To(Succeed())
+	cluster.Spec.Paused = false
+
+	isPaused, err = EnsurePausedCondition(ctx, c, cluster, machine)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(isPaused).To(BeFalse())
+	g.Expect(pausedCondition().Status).To(Equal(corev1.ConditionFalse))
+}
@@ -19,6 +19,7 @@ package predicates
 
 import (
 	"fmt"
+	"reflect"
 
 	"github.com/go-logr/logr"
 	"k8s.io/klog/v2"
@@ -150,6 +151,48 @@ func ClusterUpdateUnpaused(logger logr.Logger) predicate.Funcs {
 	}
 }
 
+// ClusterUpdatePaused returns a predicate that returns true for an update event when a cluster has Spec.Paused changed from false to true
+// it also returns true if the resource provided is not a Cluster to allow for use with controller-runtime NewControllerManagedBy.
+func ClusterUpdatePaused(logger logr.Logger) predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			log := logger.WithValues("predicate", "ClusterUpdatePaused", "eventType", "update")
+
+			oldCluster, ok := e.ObjectOld.(*clusterv1.Cluster)
+			if !ok {
+				log.V(4).Info("Expected Cluster", "type", fmt.Sprintf("%T", e.ObjectOld))
+				return false
+			}
+			log = log.WithValues("Cluster", klog.KObj(oldCluster))
+
+			newCluster := e.ObjectNew.(*clusterv1.Cluster)
+
+			if !oldCluster.Spec.Paused && newCluster.Spec.Paused {
+				log.V(4).Info("Cluster was paused, allowing further processing")
+				return true
+			}
+
+			// This predicate always work in "or" with Unpaused predicates
+			// so the logs are adjusted to not provide false negatives/verbosity al V<=5.
+			log.V(6).Info("Cluster was not paused, blocking further processing")
+			return false
+		},
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
+
+// ClusterPausedTransitions returns a Predicate that returns true on Cluster creation events where Cluster.Spec.Paused is false
+// and Update events when Cluster.Spec.Paused changes.
+// It is meant to be used instead of ClusterUnpaused by controllers reconciling paused objects too,
+// e.g. to set the Paused condition when the Cluster is paused.
+func ClusterPausedTransitions(logger logr.Logger) predicate.Funcs {
+	log := logger.WithValues("predicate", "ClusterPausedTransitions")
+
+	return Any(log, ClusterUnpaused(log), ClusterUpdatePaused(log))
+}
+
 // ClusterUnpaused returns a Predicate that returns true on Cluster creation events where Cluster.Spec.Paused is false
 // and Update events when Cluster.Spec.Paused transitions to false.
 // This implements a common requirement for many cluster-api and provider controllers (such as Cluster Infrastructure
@@ -206,6 +249,43 @@ func ClusterControlPlaneInitialized(logger logr.Logger) predicate.Funcs {
 	}
 }
 
+// ClusterMetadataPropagationChanged returns a Predicate that returns true on Update events
+// when Cluster.Spec.MetadataPropagation changes and the Cluster is not paused.
+// Example use:
+//
+//	err := controller.Watch(
+//	    source.Kind(cache, &clusterv1.Cluster{}),
+//	    handler.EnqueueRequestsFromMapFunc(clusterToMachines)
+//	    predicates.ClusterMetadataPropagationChanged(r.Log),
+//	)
+func ClusterMetadataPropagationChanged(logger logr.Logger) predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			log := logger.WithValues("predicate", "ClusterMetadataPropagationChanged", "eventType", "update")
+
+			oldCluster, ok := e.ObjectOld.(*clusterv1.Cluster)
+			if !ok {
+				log.V(4).Info("Expected Cluster", "type", fmt.Sprintf("%T", e.ObjectOld))
+				return false
+			}
+			log = log.WithValues("Cluster", klog.KObj(oldCluster))
+
+			newCluster := e.ObjectNew.(*clusterv1.Cluster)
+
+			if !newCluster.Spec.Paused && !reflect.DeepEqual(oldCluster.Spec.MetadataPropagation, newCluster.Spec.MetadataPropagation) {
+				log.V(6).Info("Cluster MetadataPropagation was changed, allow further processing")
+				return true
+			}
+
+			log.V(6).Info("Cluster MetadataPropagation hasn't changed, blocking further processing")
+			return false
+		},
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
+
 // ClusterUnpausedAndInfrastructureReady returns a Predicate that returns true on Cluster creation events where
 // both Cluster.Spec.Paused is false and Cluster.Status.InfrastructureReady is true and Update events when
 // either Cluster.Spec.Paused transitions to false or Cluster.Status.InfrastructureReady transitions to true.
@@ -231,6 +311,16 @@ func ClusterUnpausedAndInfrastructureReady(logger logr.Logger) predicate.Funcs {
 	return Any(log, createPredicates, updatePredicates)
 }
 
+// ClusterPausedTransitionsOrInfrastructureReady returns a Predicate that returns true on the same events as
+// ClusterUnpausedAndInfrastructureReady and on Update events when Cluster.Spec.Paused changes from false to true.
+// It is meant to be used instead of ClusterUnpausedAndInfrastructureReady by controllers reconciling paused objects too,
+// e.g. to set the Paused condition when the Cluster is paused.
+func ClusterPausedTransitionsOrInfrastructureReady(logger logr.Logger) predicate.Funcs {
+	log := logger.WithValues("predicate", "ClusterPausedTransitionsOrInfrastructureReady")
+
+	return Any(log, ClusterUnpausedAndInfrastructureReady(log), ClusterUpdatePaused(log))
+}
+
 // ClusterHasTopology returns a Predicate that returns true when cluster.Spec.Topology
 // is NOT nil and false otherwise.
 func ClusterHasTopology(logger logr.Logger) predicate.Funcs {
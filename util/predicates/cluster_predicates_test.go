@@ -97,3 +97,124 @@ func TestClusterControlplaneInitializedPredicate(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterMetadataPropagationChangedPredicate(t *testing.T) {
+	predicate := predicates.ClusterMetadataPropagationChanged(logr.New(log.NullLogSink{}))
+
+	noMetadata := clusterv1.Cluster{}
+	withLabels := clusterv1.Cluster{Spec: clusterv1.ClusterSpec{MetadataPropagation: &clusterv1.ObjectMeta{Labels: map[string]string{"foo": "bar"}}}}
+	withOtherLabels := clusterv1.Cluster{Spec: clusterv1.ClusterSpec{MetadataPropagation: &clusterv1.ObjectMeta{Labels: map[string]string{"foo": "baz"}}}}
+	pausedWithLabels := *withLabels.DeepCopy()
+	pausedWithLabels.Spec.Paused = true
+
+	testcases := []struct {
+		name       string
+		oldCluster clusterv1.Cluster
+		newCluster clusterv1.Cluster
+		expected   bool
+	}{
+		{
+			name:       "no metadata -> no metadata: should return false",
+			oldCluster: noMetadata,
+			newCluster: noMetadata,
+			expected:   false,
+		},
+		{
+			name:       "no metadata -> labels: should return true",
+			oldCluster: noMetadata,
+			newCluster: withLabels,
+			expected:   true,
+		},
+		{
+			name:       "labels -> other labels: should return true",
+			oldCluster: withLabels,
+			newCluster: withOtherLabels,
+			expected:   true,
+		},
+		{
+			name:       "labels -> no metadata: should return true",
+			oldCluster: withLabels,
+			newCluster: noMetadata,
+			expected:   true,
+		},
+		{
+			name:       "labels -> labels: should return false",
+			oldCluster: withLabels,
+			newCluster: withLabels,
+			expected:   false,
+		},
+		{
+			name:       "no metadata -> labels on a paused cluster: should return false",
+			oldCluster: noMetadata,
+			newCluster: pausedWithLabels,
+			expected:   false,
+		},
+	}
+
+	for i := range testcases {
+		tc := testcases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			ev := event.UpdateEvent{
+				ObjectOld: &tc.oldCluster,
+				ObjectNew: &tc.newCluster,
+			}
+
+			g.Expect(predicate.Update(ev)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestClusterPausedTransitionsPredicate(t *testing.T) {
+	predicate := predicates.ClusterPausedTransitions(logr.New(log.NullLogSink{}))
+
+	paused := clusterv1.Cluster{Spec: clusterv1.ClusterSpec{Paused: true}}
+	unpaused := clusterv1.Cluster{}
+
+	testcases := []struct {
+		name       string
+		oldCluster clusterv1.Cluster
+		newCluster clusterv1.Cluster
+		expected   bool
+	}{
+		{
+			name:       "unpaused -> paused: should return true",
+			oldCluster: unpaused,
+			newCluster: paused,
+			expected:   true,
+		},
+		{
+			name:       "paused -> unpaused: should return true",
+			oldCluster: paused,
+			newCluster: unpaused,
+			expected:   true,
+		},
+		{
+			name:       "paused -> paused: should return false",
+			oldCluster: paused,
+			newCluster: paused,
+			expected:   false,
+		},
+		{
+			name:       "unpaused -> unpaused: should return false",
+			oldCluster: unpaused,
+			newCluster: unpaused,
+			expected:   false,
+		},
+	}
+
+	for i := range testcases {
+		tc := testcases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			ev := event.UpdateEvent{
+				ObjectOld: &tc.oldCluster,
+				ObjectNew: &tc.newCluster,
+			}
+
+			g.Expect(predicate.Update(ev)).To(Equal(tc.expected))
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicates
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// ResourceConditionsChanged returns a predicate that returns true for an update event only when the status or the reason
+// of at least one condition changed, or when a condition was added or removed; if condition types are provided, only
+// changes to those conditions are considered.
+// Changes to LastTransitionTime, Severity or Message are ignored, so heartbeat-style status updates do not trigger reconciliation.
+// Create, delete and generic events are always allowed.
+// The predicate works with both typed objects implementing conditions.Getter and Unstructured objects.
+func ResourceConditionsChanged(logger logr.Logger, conditionTypes ...clusterv1.ConditionType) predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return processIfConditionsChanged(logger.WithValues("predicate", "ResourceConditionsChanged", "eventType", "update"), e.ObjectOld, e.ObjectNew, conditionTypes...)
+		},
+		CreateFunc:  func(event.CreateEvent) bool { return true },
+		DeleteFunc:  func(event.DeleteEvent) bool { return true },
+		GenericFunc: func(event.GenericEvent) bool { return true },
+	}
+}
+
+// ResourceGenerationOrConditionsChanged returns a predicate that returns true for an update event when either the
+// generation of the object changed or ResourceConditionsChanged returns true for the given condition types.
+// Create, delete and generic events are always allowed.
+func ResourceGenerationOrConditionsChanged(logger logr.Logger, conditionTypes ...clusterv1.ConditionType) predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			log := logger.WithValues("predicate", "ResourceGenerationOrConditionsChanged", "eventType", "update")
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				log.V(4).Info("Update event has no old or new object, blocking further processing")
+				return false
+			}
+			if e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() {
+				log.V(6).Info("Resource generation changed, allowing further processing", "oldGeneration", e.ObjectOld.GetGeneration(), "newGeneration", e.ObjectNew.GetGeneration())
+				return true
+			}
+			return processIfConditionsChanged(log, e.ObjectOld, e.ObjectNew, conditionTypes...)
+		},
+		CreateFunc:  func(event.CreateEvent) bool { return true },
+		DeleteFunc:  func(event.DeleteEvent) bool { return true },
+		GenericFunc: func(event.GenericEvent) bool { return true },
+	}
+}
+
+func processIfConditionsChanged(logger logr.Logger, oldObj, newObj client.Object, conditionTypes ...clusterv1.ConditionType) bool {
+	if oldObj == nil || newObj == nil {
+		logger.V(4).Info("Update event has no old or new object, blocking further processing")
+		return false
+	}
+	kind := strings.ToLower(newObj.GetObjectKind().GroupVersionKind().Kind)
+	log := logger.WithValues("namespace", newObj.GetNamespace(), kind, newObj.GetName())
+
+	oldGetter, ok := conditionsGetter(oldObj)
+	if !ok {
+		log.V(4).Info("Expected an object implementing conditions", "type", fmt.Sprintf("%T", oldObj))
+		return false
+	}
+	newGetter, ok := conditionsGetter(newObj)
+	if !ok {
+		log.V(4).Info("Expected an object implementing conditions", "type", fmt.Sprintf("%T", newObj))
+		return false
+	}
+
+	if len(conditionTypes) == 0 {
+		for _, c := range oldGetter.GetConditions() {
+			conditionTypes = append(conditionTypes, c.Type)
+		}
+		for _, c := range newGetter.GetConditions() {
+			if !conditions.Has(oldGetter, c.Type) {
+				conditionTypes = append(conditionTypes, c.Type)
+			}
+		}
+	}
+
+	for _, t := range conditionTypes {
+		oldCondition := conditions.Get(oldGetter, t)
+		newCondition := conditions.Get(newGetter, t)
+		if (oldCondition == nil) != (newCondition == nil) {
+			log.V(6).Info("Condition added or removed, will attempt to map resource", "condition", t)
+			return true
+		}
+		if oldCondition == nil {
+			continue
+		}
+		if oldCondition.Status != newCondition.Status || oldCondition.Reason != newCondition.Reason {
+			log.V(6).Info("Condition changed, will attempt to map resource", "condition", t, "status", newCondition.Status, "reason", newCondition.Reason)
+			return true
+		}
+	}
+
+	log.V(6).Info("Conditions did not change, will not attempt to map resource")
+	return false
+}
+
+func conditionsGetter(obj client.Object) (conditions.Getter, bool) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return conditions.UnstructuredGetter(u), true
+	}
+	getter, ok := obj.(conditions.Getter)
+	return getter, ok
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicates_test
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/predicates"
+)
+
+func TestResourceConditionsChangedPredicate(t *testing.T) {
+	ready := clusterWithConditions(1, *conditions.TrueCondition(clusterv1.ReadyCondition))
+	readyHeartbeat := clusterWithConditions(1, *conditions.TrueCondition(clusterv1.ReadyCondition))
+	readyHeartbeat.Status.Conditions[0].LastTransitionTime = metav1.Now()
+	readyHeartbeat.Status.Conditions[0].Message = "a new message"
+	notReady := clusterWithConditions(1, *conditions.FalseCondition(clusterv1.ReadyCondition, "Foo", clusterv1.ConditionSeverityInfo, ""))
+	notReadyOtherReason := clusterWithConditions(1, *conditions.FalseCondition(clusterv1.ReadyCondition, "Bar", clusterv1.ConditionSeverityInfo, ""))
+	readyAndInfraReady := clusterWithConditions(1, *conditions.TrueCondition(clusterv1.ReadyCondition), *conditions.TrueCondition(clusterv1.InfrastructureReadyCondition))
+	readyAndInfraNotReady := clusterWithConditions(1, *conditions.TrueCondition(clusterv1.ReadyCondition), *conditions.FalseCondition(clusterv1.InfrastructureReadyCondition, "Foo", clusterv1.ConditionSeverityInfo, ""))
+
+	testcases := []struct {
+		name           string
+		conditionTypes []clusterv1.ConditionType
+		oldObj         client.Object
+		newObj         client.Object
+		expected       bool
+	}{
+		{
+			name:     "no changes: should return false",
+			oldObj:   ready,
+			newObj:   ready,
+			expected: false,
+		},
+		{
+			name:     "only message and last transition time changed: should return false",
+			oldObj:   ready,
+			newObj:   readyHeartbeat,
+			expected: false,
+		},
+		{
+			name:     "status changed: should return true",
+			oldObj:   ready,
+			newObj:   notReady,
+			expected: true,
+		},
+		{
+			name:     "reason changed: should return true",
+			oldObj:   notReady,
+			newObj:   notReadyOtherReason,
+			expected: true,
+		},
+		{
+			name:     "condition added: should return true",
+			oldObj:   ready,
+			newObj:   readyAndInfraReady,
+			expected: true,
+		},
+		{
+			name:     "condition removed: should return true",
+			oldObj:   readyAndInfraReady,
+			newObj:   ready,
+			expected: true,
+		},
+		{
+			name:           "change to a condition not in the filter: should return false",
+			conditionTypes: []clusterv1.ConditionType{clusterv1.ReadyCondition},
+			oldObj:         readyAndInfraReady,
+			newObj:         readyAndInfraNotReady,
+			expected:       false,
+		},
+		{
+			name:           "change to a condition in the filter: should return true",
+			conditionTypes: []clusterv1.ConditionType{clusterv1.InfrastructureReadyCondition},
+			oldObj:         readyAndInfraReady,
+			newObj:         readyAndInfraNotReady,
+			expected:       true,
+		},
+		{
+			name:     "unstructured status changed: should return true",
+			oldObj:   toUnstructured(t, ready),
+			newObj:   toUnstructured(t, notReady),
+			expected: true,
+		},
+		{
+			name:     "unstructured only message changed: should return false",
+			oldObj:   toUnstructured(t, ready),
+			newObj:   toUnstructured(t, readyHeartbeat),
+			expected: false,
+		},
+		{
+			name:     "object not implementing conditions: should return false",
+			oldObj:   &corev1.Node{},
+			newObj:   &corev1.Node{},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			predicate := predicates.ResourceConditionsChanged(logr.New(log.NullLogSink{}), tc.conditionTypes...)
+			g.Expect(predicate.Update(event.UpdateEvent{ObjectOld: tc.oldObj, ObjectNew: tc.newObj})).To(Equal(tc.expected))
+			g.Expect(predicate.Create(event.CreateEvent{Object: tc.newObj})).To(BeTrue())
+			g.Expect(predicate.Delete(event.DeleteEvent{Object: tc.newObj})).To(BeTrue())
+			g.Expect(predicate.Generic(event.GenericEvent{Object: tc.newObj})).To(BeTrue())
+		})
+	}
+}
+
+func TestResourceGenerationOrConditionsChangedPredicate(t *testing.T) {
+	ready := clusterWithConditions(1, *conditions.TrueCondition(clusterv1.ReadyCondition))
+	readyGeneration2 := clusterWithConditions(2, *conditions.TrueCondition(clusterv1.ReadyCondition))
+	readyHeartbeat := clusterWithConditions(1, *conditions.TrueCondition(clusterv1.ReadyCondition))
+	readyHeartbeat.Status.Conditions[0].Message = "a new message"
+	notReady := clusterWithConditions(1, *conditions.FalseCondition(clusterv1.ReadyCondition, "Foo", clusterv1.ConditionSeverityInfo, ""))
+
+	testcases := []struct {
+		name     string
+		oldObj   client.Object
+		newObj   client.Object
+		expected bool
+	}{
+		{
+			name:     "nothing changed: should return false",
+			oldObj:   ready,
+			newObj:   readyHeartbeat,
+			expected: false,
+		},
+		{
+			name:     "generation changed: should return true",
+			oldObj:   ready,
+			newObj:   readyGeneration2,
+			expected: true,
+		},
+		{
+			name:     "conditions changed: should return true",
+			oldObj:   ready,
+			newObj:   notReady,
+			expected: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			predicate := predicates.ResourceGenerationOrConditionsChanged(logr.New(log.NullLogSink{}))
+			g.Expect(predicate.Update(event.UpdateEvent{ObjectOld: tc.oldObj, ObjectNew: tc.newObj})).To(Equal(tc.expected))
+		})
+	}
+}
+
+func clusterWithConditions(generation int64, conds ...clusterv1.Condition) *clusterv1.Cluster {
+	return &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "cluster",
+			Namespace:  "default",
+			Generation: generation,
+		},
+		Status: clusterv1.ClusterStatus{
+			Conditions: conds,
+		},
+	}
+}
+
+func toUnstructured(t *testing.T, obj runtime.Object) *unstructured.Unstructured {
+	t.Helper()
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &unstructured.Unstructured{Object: u}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package predicates
 
 import (
+	"context"
 	"strings"
 
 	"github.com/go-logr/logr"
@@ -26,6 +27,7 @@ import (
 
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/labels"
+	"sigs.k8s.io/cluster-api/util/shard"
 )
 
 // All returns a predicate that returns true only if all given predicates return true.
@@ -130,6 +132,8 @@ func Any(logger logr.Logger, predicates ...predicate.Funcs) predicate.Funcs {
 
 // ResourceHasFilterLabel returns a predicate that returns true only if the provided resource contains
 // a label with the WatchLabel key and the configured label value exactly.
+// When controllers are sharded (see shard.Set), the predicate also returns true only if the resource
+// is owned by the shard of the manager.
 func ResourceHasFilterLabel(logger logr.Logger, labelValue string) predicate.Funcs {
 	return predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
@@ -196,13 +200,19 @@ func processIfNotPaused(logger logr.Logger, obj client.Object) bool {
 }
 
 func processIfLabelMatch(logger logr.Logger, obj client.Object, labelValue string) bool {
+	kind := strings.ToLower(obj.GetObjectKind().GroupVersionKind().Kind)
+	log := logger.WithValues("namespace", obj.GetNamespace(), kind, obj.GetName())
+
+	if s := shard.Get(); !s.Owns(context.Background(), obj) {
+		log.V(4).Info("Resource is not owned by this shard, will not attempt to map resource", "shard", s.String())
+		return false
+	}
+
 	// Return early if no labelValue was set.
 	if labelValue == "" {
 		return true
 	}
 
-	kind := strings.ToLower(obj.GetObjectKind().GroupVersionKind().Kind)
-	log := logger.WithValues("namespace", obj.GetNamespace(), kind, obj.GetName())
 	if labels.HasWatchLabel(obj, labelValue) {
 		log.V(6).Info("Resource matches label, will attempt to map resource")
 		return true
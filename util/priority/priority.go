@@ -0,0 +1,231 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package priority implements utilities to reconcile user-triggered changes, like new objects, spec changes and
+// deletions, ahead of periodic resyncs and status-only updates.
+package priority
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Priority is the priority of an event.
+type Priority int
+
+const (
+	// LowPriority is the priority of periodic resyncs, status-only updates and of the create events
+	// for pre-existing objects received when the controller starts.
+	LowPriority Priority = iota
+
+	// HighPriority is the priority of all the other events, e.g. new objects, spec changes and deletions.
+	HighPriority
+)
+
+// Policy defines how low priority events are enqueued.
+type Policy struct {
+	// LowPriorityMaxDelay is the maximum delay after which the objects of low priority events are enqueued when the
+	// queue of the controller is not empty; the delays are spread uniformly between zero and LowPriorityMaxDelay,
+	// so high priority events are reconciled first and a resync of all the objects is spread over time.
+	// If zero, low priority events are enqueued immediately, like high priority events.
+	LowPriorityMaxDelay time.Duration
+
+	// RecentlyCreatedThreshold is the age below which objects are considered new in create events;
+	// create events for older objects are received when the controller starts, and are low priority.
+	RecentlyCreatedThreshold time.Duration
+
+	// OwnedBatchPeriod is the period over which the low priority events of owned objects, e.g. the status-only
+	// updates of the Machines of a MachineSet, are batched into a single reconcile of their owner.
+	// If zero, owners are enqueued immediately for every event of the owned objects.
+	OwnedBatchPeriod time.Duration
+}
+
+// DefaultPolicy is the default Policy.
+var DefaultPolicy = Policy{
+	LowPriorityMaxDelay:      30 * time.Second,
+	RecentlyCreatedThreshold: 1 * time.Minute,
+	OwnedBatchPeriod:         5 * time.Second,
+}
+
+var (
+	policyLock sync.RWMutex
+	policy     = DefaultPolicy
+)
+
+// SetPolicy sets the Policy used by the controllers.
+func SetPolicy(p Policy) {
+	policyLock.Lock()
+	defer policyLock.Unlock()
+	policy = p
+}
+
+// GetPolicy returns the Policy used by the controllers.
+func GetPolicy() Policy {
+	policyLock.RLock()
+	defer policyLock.RUnlock()
+	return policy
+}
+
+// OfCreate returns the priority of a create event.
+func OfCreate(e event.CreateEvent) Priority {
+	if time.Since(e.Object.GetCreationTimestamp().Time) > GetPolicy().RecentlyCreatedThreshold {
+		return LowPriority
+	}
+	return HighPriority
+}
+
+// OfUpdate returns the priority of an update event; updates are low priority if they are periodic
+// resyncs, i.e. the object is unchanged, or if only the status of the object changed.
+func OfUpdate(e event.UpdateEvent) Priority {
+	oldObj, newObj := e.ObjectOld, e.ObjectNew
+	if oldObj.GetResourceVersion() == newObj.GetResourceVersion() {
+		return LowPriority
+	}
+	if oldObj.GetGeneration() != newObj.GetGeneration() ||
+		!oldObj.GetDeletionTimestamp().Equal(newObj.GetDeletionTimestamp()) ||
+		!reflect.DeepEqual(oldObj.GetLabels(), newObj.GetLabels()) ||
+		!reflect.DeepEqual(oldObj.GetAnnotations(), newObj.GetAnnotations()) ||
+		!reflect.DeepEqual(oldObj.GetFinalizers(), newObj.GetFinalizers()) ||
+		!reflect.DeepEqual(oldObj.GetOwnerReferences(), newObj.GetOwnerReferences()) {
+		return HighPriority
+	}
+	return LowPriority
+}
+
+// For sets up b to reconcile obj like builder.Builder.For, with the given predicates, but enqueuing the objects
+// of low priority events after a delay as defined by the Policy when the queue of the controller is not empty.
+// Note: This is implemented using two watches for obj, the one of For, filtering out low priority events,
+// and one for the low priority events only.
+func For(b *builder.Builder, obj client.Object, predicates ...predicate.Predicate) *builder.Builder {
+	highPriorityPredicates := append([]predicate.Predicate{isHighPriority()}, predicates...)
+	return b.
+		For(obj, builder.WithPredicates(highPriorityPredicates...)).
+		Watches(obj, &enqueueLowPriorityRequestForObject{}, builder.WithPredicates(predicates...))
+}
+
+// Owns sets up b to watch obj like builder.Builder.Owns, enqueuing the controller owner of type owner,
+// but batching the low priority events of obj as defined by the Policy; this avoids recomputing and writing
+// the status of the owner for every individual change of the status of the objects it owns.
+func Owns(b *builder.Builder, mgr manager.Manager, owner, obj client.Object) *builder.Builder {
+	return b.
+		Owns(obj, builder.WithPredicates(isHighPriority())).
+		Watches(obj, &batchLowPriorityRequests{
+			handler: handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), owner, handler.OnlyControllerOwner()),
+		})
+}
+
+// isHighPriority returns a predicate filtering out low priority create and update events.
+func isHighPriority() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return OfCreate(e) == HighPriority
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return OfUpdate(e) == HighPriority
+		},
+	}
+}
+
+// enqueueLowPriorityRequestForObject enqueues the objects of low priority create and update events.
+type enqueueLowPriorityRequestForObject struct{}
+
+var _ handler.EventHandler = &enqueueLowPriorityRequestForObject{}
+
+func (h *enqueueLowPriorityRequestForObject) Create(_ context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
+	if OfCreate(e) == LowPriority {
+		addLowPriority(q, e.Object)
+	}
+}
+
+func (h *enqueueLowPriorityRequestForObject) Update(_ context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	if OfUpdate(e) == LowPriority {
+		addLowPriority(q, e.ObjectNew)
+	}
+}
+
+func (h *enqueueLowPriorityRequestForObject) Delete(context.Context, event.DeleteEvent, workqueue.RateLimitingInterface) {
+}
+
+func (h *enqueueLowPriorityRequestForObject) Generic(context.Context, event.GenericEvent, workqueue.RateLimitingInterface) {
+}
+
+func addLowPriority(q workqueue.RateLimitingInterface, obj client.Object) {
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(obj)}
+	maxDelay := GetPolicy().LowPriorityMaxDelay
+	if maxDelay <= 0 || q.Len() == 0 {
+		q.Add(req)
+		return
+	}
+	q.AddAfter(req, time.Duration(rand.Int63n(int64(maxDelay)))) //nolint:gosec // Cryptographically secure random numbers are not required to spread delays.
+}
+
+// batchLowPriorityRequests calls handler for low priority create and update events only, enqueuing the resulting
+// requests after the OwnedBatchPeriod; as the queue deduplicates requests, all the events for the same
+// request received during the OwnedBatchPeriod result in a single reconcile.
+type batchLowPriorityRequests struct {
+	handler handler.EventHandler
+}
+
+var _ handler.EventHandler = &batchLowPriorityRequests{}
+
+func (h *batchLowPriorityRequests) Create(ctx context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
+	if OfCreate(e) == LowPriority {
+		h.handler.Create(ctx, e, batchingQueue(q))
+	}
+}
+
+func (h *batchLowPriorityRequests) Update(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	if OfUpdate(e) == LowPriority {
+		h.handler.Update(ctx, e, batchingQueue(q))
+	}
+}
+
+func (h *batchLowPriorityRequests) Delete(context.Context, event.DeleteEvent, workqueue.RateLimitingInterface) {
+}
+
+func (h *batchLowPriorityRequests) Generic(context.Context, event.GenericEvent, workqueue.RateLimitingInterface) {
+}
+
+// batchingQueue returns a queue adding items after the OwnedBatchPeriod.
+func batchingQueue(q workqueue.RateLimitingInterface) workqueue.RateLimitingInterface {
+	period := GetPolicy().OwnedBatchPeriod
+	if period <= 0 {
+		return q
+	}
+	return &delayedAddQueue{RateLimitingInterface: q, delay: period}
+}
+
+type delayedAddQueue struct {
+	workqueue.RateLimitingInterface
+	delay time.Duration
+}
+
+// Add adds the item after the delay; if the item is already waiting to be added, the earliest time is kept.
+func (q *delayedAddQueue) Add(item interface{}) {
+	q.RateLimitingInterface.AddAfter(item, q.delay)
+}
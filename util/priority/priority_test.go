@@ -0,0 +1,214 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priority
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestOfCreate(t *testing.T) {
+	g := NewWithT(t)
+
+	newCluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.Now()}}
+	g.Expect(OfCreate(event.CreateEvent{Object: newCluster})).To(Equal(HighPriority))
+
+	oldCluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))}}
+	g.Expect(OfCreate(event.CreateEvent{Object: oldCluster})).To(Equal(LowPriority))
+}
+
+func TestOfUpdate(t *testing.T) {
+	base := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "cluster",
+			ResourceVersion: "1",
+			Generation:      1,
+			Labels:          map[string]string{"foo": "bar"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(c *clusterv1.Cluster)
+		want   Priority
+	}{
+		{
+			name:   "resync",
+			mutate: func(*clusterv1.Cluster) {},
+			want:   LowPriority,
+		},
+		{
+			name: "status-only update",
+			mutate: func(c *clusterv1.Cluster) {
+				c.ResourceVersion = "2"
+				c.Status.Phase = string(clusterv1.ClusterPhaseProvisioned)
+			},
+			want: LowPriority,
+		},
+		{
+			name: "spec change",
+			mutate: func(c *clusterv1.Cluster) {
+				c.ResourceVersion = "2"
+				c.Generation = 2
+			},
+			want: HighPriority,
+		},
+		{
+			name: "deletion",
+			mutate: func(c *clusterv1.Cluster) {
+				c.ResourceVersion = "2"
+				c.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			},
+			want: HighPriority,
+		},
+		{
+			name: "labels change",
+			mutate: func(c *clusterv1.Cluster) {
+				c.ResourceVersion = "2"
+				c.Labels = map[string]string{"foo": "baz"}
+			},
+			want: HighPriority,
+		},
+		{
+			name: "annotations change",
+			mutate: func(c *clusterv1.Cluster) {
+				c.ResourceVersion = "2"
+				c.Annotations = map[string]string{clusterv1.PausedAnnotation: ""}
+			},
+			want: HighPriority,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			newObj := base.DeepCopy()
+			tt.mutate(newObj)
+			g.Expect(OfUpdate(event.UpdateEvent{ObjectOld: base, ObjectNew: newObj})).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestEnqueueLowPriorityRequestForObject(t *testing.T) {
+	defer SetPolicy(GetPolicy())
+
+	oldCluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "old", CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))}}
+	newCluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "new", CreationTimestamp: metav1.Now()}}
+
+	t.Run("enqueues immediately when the queue is empty", func(t *testing.T) {
+		g := NewWithT(t)
+		SetPolicy(Policy{LowPriorityMaxDelay: time.Hour, RecentlyCreatedThreshold: time.Minute})
+
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer q.ShutDown()
+
+		h := &enqueueLowPriorityRequestForObject{}
+		h.Create(context.Background(), event.CreateEvent{Object: oldCluster}, q)
+		g.Expect(q.Len()).To(Equal(1))
+	})
+
+	t.Run("delays low priority events when the queue is not empty", func(t *testing.T) {
+		g := NewWithT(t)
+		SetPolicy(Policy{LowPriorityMaxDelay: time.Hour, RecentlyCreatedThreshold: time.Minute})
+
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer q.ShutDown()
+		q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: newCluster.Namespace, Name: newCluster.Name}})
+
+		h := &enqueueLowPriorityRequestForObject{}
+		h.Create(context.Background(), event.CreateEvent{Object: newCluster}, q)
+		h.Create(context.Background(), event.CreateEvent{Object: oldCluster}, q)
+		g.Consistently(q.Len, 100*time.Millisecond).Should(Equal(1))
+	})
+
+	t.Run("enqueues immediately when delays are disabled", func(t *testing.T) {
+		g := NewWithT(t)
+		SetPolicy(Policy{LowPriorityMaxDelay: 0, RecentlyCreatedThreshold: time.Minute})
+
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer q.ShutDown()
+		q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: newCluster.Namespace, Name: newCluster.Name}})
+
+		h := &enqueueLowPriorityRequestForObject{}
+		h.Update(context.Background(), event.UpdateEvent{ObjectOld: oldCluster, ObjectNew: oldCluster}, q)
+		g.Expect(q.Len()).To(Equal(2))
+	})
+}
+
+func TestBatchLowPriorityRequests(t *testing.T) {
+	defer SetPolicy(GetPolicy())
+
+	machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "machine", ResourceVersion: "1", CreationTimestamp: metav1.Now()}}
+	updatedMachine := machine.DeepCopy()
+	updatedMachine.ResourceVersion = "2"
+	updatedMachine.Status.Phase = string(clusterv1.MachinePhaseRunning)
+
+	h := &batchLowPriorityRequests{
+		handler: handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "owner"}}}
+		}),
+	}
+
+	t.Run("batches status-only updates", func(t *testing.T) {
+		g := NewWithT(t)
+		SetPolicy(Policy{OwnedBatchPeriod: 200 * time.Millisecond, RecentlyCreatedThreshold: time.Minute})
+
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer q.ShutDown()
+
+		for i := 0; i < 10; i++ {
+			h.Update(context.Background(), event.UpdateEvent{ObjectOld: machine, ObjectNew: updatedMachine}, q)
+		}
+		g.Expect(q.Len()).To(Equal(0))
+		g.Eventually(q.Len, time.Second).Should(Equal(1))
+		g.Consistently(q.Len, 300*time.Millisecond).Should(Equal(1))
+	})
+
+	t.Run("ignores high priority events", func(t *testing.T) {
+		g := NewWithT(t)
+		SetPolicy(Policy{OwnedBatchPeriod: 0, RecentlyCreatedThreshold: time.Minute})
+
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer q.ShutDown()
+
+		h.Create(context.Background(), event.CreateEvent{Object: machine}, q)
+		g.Expect(q.Len()).To(Equal(0))
+	})
+
+	t.Run("enqueues immediately when batching is disabled", func(t *testing.T) {
+		g := NewWithT(t)
+		SetPolicy(Policy{OwnedBatchPeriod: 0, RecentlyCreatedThreshold: time.Minute})
+
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer q.ShutDown()
+
+		h.Update(context.Background(), event.UpdateEvent{ObjectOld: machine, ObjectNew: updatedMachine}, q)
+		g.Expect(q.Len()).To(Equal(1))
+	})
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package record
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// deduplicationCacheSize is the maximum number of recently emitted events tracked for deduplication.
+	deduplicationCacheSize = 4096
+)
+
+var (
+	// DeduplicationWindow is the time window during which identical events for the same object are emitted only once.
+	DeduplicationWindow = 10 * time.Minute
+
+	recentEvents = cache.NewLRUExpireCache(deduplicationCacheSize)
+)
+
+// Emit emits an event with the given Reason for object using recorder; the type of the event and the template
+// of its message, which is rendered with args, are defined by the reason catalog.
+// Identical events for the same object are emitted only once within the DeduplicationWindow, so controllers
+// can emit events on every reconcile without flooding the API server; note that events whose message embeds
+// values like errors are deduplicated only if the message is identical.
+func Emit(recorder record.EventRecorder, object runtime.Object, reason Reason, args ...interface{}) {
+	message := Message(reason, args...)
+
+	if key, ok := deduplicationKey(recorder, object, reason, message); ok {
+		if _, found := recentEvents.Get(key); found {
+			return
+		}
+		recentEvents.Add(key, struct{}{}, DeduplicationWindow)
+	}
+
+	recorder.Event(object, reason.EventType(), string(reason), message)
+}
+
+// Message renders the message of an event with the given Reason; for reasons not in the catalog
+// args are concatenated.
+func Message(reason Reason, args ...interface{}) string {
+	info, ok := reasons[reason]
+	if !ok {
+		return fmt.Sprint(args...)
+	}
+	return fmt.Sprintf(info.template, args...)
+}
+
+func deduplicationKey(recorder record.EventRecorder, object runtime.Object, reason Reason, message string) (string, bool) {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return "", false
+	}
+	id := string(accessor.GetUID())
+	if id == "" {
+		id = fmt.Sprintf("%T/%s/%s", object, accessor.GetNamespace(), accessor.GetName())
+	}
+	// Include the recorder, so events emitted by different recorders, e.g. in tests, are not deduplicated.
+	return fmt.Sprintf("%p/%s/%s/%s", recorder, id, reason, message), true
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package record
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestReasonCatalog(t *testing.T) {
+	g := NewWithT(t)
+
+	// Reasons must be CamelCase identifiers, so they can be consumed by machines.
+	for _, r := range Reasons() {
+		g.Expect(string(r)).To(MatchRegexp(`^[A-Z][a-zA-Z]*$`))
+		g.Expect(r.EventType()).To(BeElementOf(corev1.EventTypeNormal, corev1.EventTypeWarning))
+		g.Expect(strings.TrimSpace(reasons[r].template)).ToNot(BeEmpty())
+	}
+}
+
+func TestEmit(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "test", UID: "uid-1"}}
+
+	Emit(recorder, obj, FailedDeleteReason, "Machine", "default/m1", errors.New("boom"))
+	g.Expect(recorder.Events).To(Receive(Equal("Warning FailedDelete Failed to delete Machine default/m1: boom")))
+
+	// Identical events are deduplicated.
+	Emit(recorder, obj, FailedDeleteReason, "Machine", "default/m1", errors.New("boom"))
+	g.Expect(recorder.Events).ToNot(Receive())
+
+	// Events with a different message are not deduplicated.
+	Emit(recorder, obj, SuccessfulScaleReason, "MachineSet", "default/ms1", 1, 2)
+	g.Expect(recorder.Events).To(Receive(Equal("Normal SuccessfulScale Scaled MachineSet default/ms1: 1 -> 2")))
+
+	// Events for other objects are not deduplicated.
+	other := obj.DeepCopy()
+	other.UID = "uid-2"
+	Emit(recorder, other, FailedDeleteReason, "Machine", "default/m1", errors.New("boom"))
+	g.Expect(recorder.Events).To(Receive())
+
+	// Events emitted by other recorders are not deduplicated.
+	otherRecorder := record.NewFakeRecorder(10)
+	Emit(otherRecorder, obj, FailedDeleteReason, "Machine", "default/m1", errors.New("boom"))
+	g.Expect(otherRecorder.Events).To(Receive())
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package record
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Reason is the reason of an event emitted by Cluster API controllers.
+// Reasons are machine-consumable and are kept stable across releases, so alerting systems can key off them;
+// messages are intended for humans and might change.
+type Reason string
+
+// reasonInfo defines the type and the message template of the events with a given Reason.
+type reasonInfo struct {
+	eventType string
+	template  string
+}
+
+const (
+	// ReconcileErrorReason is used when a reconcile fails; the message is the error.
+	ReconcileErrorReason Reason = "ReconcileError"
+
+	// SuccessfulCreateReason is used when an object is created; the args are the kind and the reference of the object.
+	SuccessfulCreateReason Reason = "SuccessfulCreate"
+
+	// FailedCreateReason is used when an object can't be created; the args are the kind and the reference of the object,
+	// and the error.
+	FailedCreateReason Reason = "FailedCreate"
+
+	// FailedUpdateReason is used when an object can't be updated; the args are the kind and the reference of the object,
+	// and the error.
+	FailedUpdateReason Reason = "FailedUpdate"
+
+	// SuccessfulDeleteReason is used when an object is deleted; the args are the kind and the reference of the object.
+	SuccessfulDeleteReason Reason = "SuccessfulDelete"
+
+	// FailedDeleteReason is used when an object can't be deleted; the args are the kind and the reference of the object,
+	// and the error.
+	FailedDeleteReason Reason = "FailedDelete"
+
+	// SuccessfulAdoptReason is used when an object is adopted; the args are the kind and the reference of the object.
+	SuccessfulAdoptReason Reason = "SuccessfulAdopt"
+
+	// FailedAdoptReason is used when an object can't be adopted; the args are the kind and the reference of the object,
+	// and the error.
+	FailedAdoptReason Reason = "FailedAdopt"
+
+	// SuccessfulScaleReason is used when an object is scaled; the args are the kind and the reference of the object,
+	// and the old and new replicas.
+	SuccessfulScaleReason Reason = "SuccessfulScale"
+
+	// FailedScaleReason is used when an object can't be scaled; the args are the kind and the reference of the object,
+	// and the error.
+	FailedScaleReason Reason = "FailedScale"
+
+	// SuccessfulDrainNodeReason is used when a Node is drained; the arg is the name of the Node.
+	SuccessfulDrainNodeReason Reason = "SuccessfulDrainNode"
+
+	// FailedDrainNodeReason is used when a Node can't be drained; the args are the name of the Node and the error.
+	FailedDrainNodeReason Reason = "FailedDrainNode"
+
+	// NodeVolumesDetachedReason is used when all the volumes are detached from a Node; the arg is the name of the Node.
+	NodeVolumesDetachedReason Reason = "NodeVolumesDetached"
+
+	// FailedWaitForVolumeDetachReason is used when it is not possible to check if volumes are detached from a Node;
+	// the args are the name of the Node and the error.
+	FailedWaitForVolumeDetachReason Reason = "FailedWaitForVolumeDetach"
+
+	// FailedDeleteNodeReason is used when a Node can't be deleted; the arg is the error.
+	FailedDeleteNodeReason Reason = "FailedDeleteNode"
+
+	// SuccessfulSetNodeRefReason is used when the NodeRef of a Machine is set; the arg is the name of the Node.
+	SuccessfulSetNodeRefReason Reason = "SuccessfulSetNodeRef"
+
+	// FailedSetNodeRefReason is used when the NodeRef of a Machine can't be set; the arg is the error.
+	FailedSetNodeRefReason Reason = "FailedSetNodeRef"
+
+	// FailedVerifyNodeIdentityReason is used when the identity of a Node can't be verified before setting the NodeRef
+	// of a Machine; the args are the name of the Node and the error.
+	FailedVerifyNodeIdentityReason Reason = "FailedVerifyNodeIdentity"
+
+	// SuccessfulSetInterruptibleNodeLabelReason is used when the interruptible label is set on a Node;
+	// the arg is the name of the Node.
+	SuccessfulSetInterruptibleNodeLabelReason Reason = "SuccessfulSetInterruptibleNodeLabel"
+
+	// FailedInitializationReason is used when the first control plane Machine can't be created;
+	// the args are the reference of the Cluster and the error.
+	FailedInitializationReason Reason = "FailedInitialization"
+
+	// FailedScaleUpReason is used when a control plane Machine can't be created while scaling up;
+	// the args are the reference of the Cluster and the error.
+	FailedScaleUpReason Reason = "FailedScaleUp"
+
+	// FailedScaleDownReason is used when a control plane Machine can't be deleted while scaling down;
+	// the args are the reference of the Machine, the reference of the Cluster and the error.
+	FailedScaleDownReason Reason = "FailedScaleDown"
+
+	// ControlPlaneUnhealthyReason is used when the control plane does not pass preflight checks; the arg is the error.
+	ControlPlaneUnhealthyReason Reason = "ControlPlaneUnhealthy"
+
+	// AdoptionFailedReason is used when a control plane Machine can't be adopted; the args are the reference
+	// of the Machine and why it can't be adopted.
+	AdoptionFailedReason Reason = "AdoptionFailed"
+
+	// DestructiveActionDecidedReason is used when a controller decides to execute a destructive action on a Machine,
+	// e.g. delete, remediate or roll out the Machine; the args are the action, the controller and the reason.
+	DestructiveActionDecidedReason Reason = "DestructiveActionDecided"
+)
+
+// reasons is the catalog of the reasons of the events emitted by Cluster API controllers.
+var reasons = map[Reason]reasonInfo{
+	ReconcileErrorReason:                      {eventType: corev1.EventTypeWarning, template: "%v"},
+	SuccessfulCreateReason:                    {eventType: corev1.EventTypeNormal, template: "Created %s %s"},
+	FailedCreateReason:                        {eventType: corev1.EventTypeWarning, template: "Failed to create %s %s: %v"},
+	FailedUpdateReason:                        {eventType: corev1.EventTypeWarning, template: "Failed to update %s %s: %v"},
+	SuccessfulDeleteReason:                    {eventType: corev1.EventTypeNormal, template: "Deleted %s %s"},
+	FailedDeleteReason:                        {eventType: corev1.EventTypeWarning, template: "Failed to delete %s %s: %v"},
+	SuccessfulAdoptReason:                     {eventType: corev1.EventTypeNormal, template: "Adopted %s %s"},
+	FailedAdoptReason:                         {eventType: corev1.EventTypeWarning, template: "Failed to adopt %s %s: %v"},
+	SuccessfulScaleReason:                     {eventType: corev1.EventTypeNormal, template: "Scaled %s %s: %d -> %d"},
+	FailedScaleReason:                         {eventType: corev1.EventTypeWarning, template: "Failed to scale %s %s: %v"},
+	SuccessfulDrainNodeReason:                 {eventType: corev1.EventTypeNormal, template: "Drained Node %q"},
+	FailedDrainNodeReason:                     {eventType: corev1.EventTypeWarning, template: "Failed to drain Node %q: %v"},
+	NodeVolumesDetachedReason:                 {eventType: corev1.EventTypeNormal, template: "Volumes detached from Node %q"},
+	FailedWaitForVolumeDetachReason:           {eventType: corev1.EventTypeWarning, template: "Failed to wait for volumes to be detached from Node %q: %v"},
+	FailedDeleteNodeReason:                    {eventType: corev1.EventTypeWarning, template: "Failed to delete Node: %v"},
+	SuccessfulSetNodeRefReason:                {eventType: corev1.EventTypeNormal, template: "Set NodeRef to Node %q"},
+	FailedSetNodeRefReason:                    {eventType: corev1.EventTypeWarning, template: "Failed to set NodeRef: %v"},
+	FailedVerifyNodeIdentityReason:            {eventType: corev1.EventTypeWarning, template: "Failed to verify the identity of Node %q: %v"},
+	SuccessfulSetInterruptibleNodeLabelReason: {eventType: corev1.EventTypeNormal, template: "Set interruptible label on Node %q"},
+	FailedInitializationReason:                {eventType: corev1.EventTypeWarning, template: "Failed to create initial control plane Machine for Cluster %s: %v"},
+	FailedScaleUpReason:                       {eventType: corev1.EventTypeWarning, template: "Failed to create additional control plane Machine for Cluster %s: %v"},
+	FailedScaleDownReason:                     {eventType: corev1.EventTypeWarning, template: "Failed to delete control plane Machine %s for Cluster %s: %v"},
+	ControlPlaneUnhealthyReason:               {eventType: corev1.EventTypeWarning, template: "Waiting for control plane to pass preflight checks to continue reconciliation: %v"},
+	AdoptionFailedReason:                      {eventType: corev1.EventTypeWarning, template: "Could not adopt Machine %s: %s"},
+	DestructiveActionDecidedReason:            {eventType: corev1.EventTypeNormal, template: "%s decided by %s: %s"},
+}
+
+// Reasons returns all the reasons in the catalog, sorted.
+func Reasons() []Reason {
+	ret := make([]Reason, 0, len(reasons))
+	for r := range reasons {
+		ret = append(ret, r)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i] < ret[j] })
+	return ret
+}
+
+// EventType returns the type of the events with the given Reason; it is Warning for reasons not in the catalog.
+func (r Reason) EventType() string {
+	if info, ok := reasons[r]; ok {
+		return info.eventType
+	}
+	return corev1.EventTypeWarning
+}
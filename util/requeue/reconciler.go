@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requeue
+
+import (
+	"context"
+	"errors"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Reconciler returns a reconcile.Reconciler wrapping r which handles the errors returned by r according to their
+// ErrorClass and to the Policy set with SetPolicy:
+//   - terminal errors are returned as reconcile.TerminalError, so the object is not requeued until it changes.
+//   - transient, conflict and not found errors are logged, and the object is requeued after the delay of the class
+//     instead of being retried with exponential backoff; conflicts are requeued immediately if their delay is zero.
+//   - unknown errors are returned unchanged.
+func Reconciler(r reconcile.Reconciler) reconcile.Reconciler {
+	return &requeueReconciler{reconciler: r}
+}
+
+type requeueReconciler struct {
+	reconciler reconcile.Reconciler
+}
+
+// Reconcile calls the wrapped reconciler and handles the returned error.
+func (r *requeueReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	result, err := r.reconciler.Reconcile(ctx, req)
+	return handleError(ctx, result, err)
+}
+
+// handleError returns the result and the error to be returned to controller-runtime for err.
+func handleError(ctx context.Context, result reconcile.Result, err error) (reconcile.Result, error) {
+	class := Classify(err)
+	switch class {
+	case "", UnknownErrorClass:
+		return result, err
+	case TerminalErrorClass:
+		if errors.Is(err, reconcile.TerminalError(nil)) {
+			return result, err
+		}
+		return result, reconcile.TerminalError(err)
+	}
+
+	requeueAfter := After(class)
+	ctrl.LoggerFrom(ctx).Error(err, "Reconciler error", "errorClass", class, "requeueAfter", requeueAfter)
+	if requeueAfter <= 0 {
+		return reconcile.Result{Requeue: true}, nil
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requeue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconciler(t *testing.T) {
+	defer SetPolicy(GetPolicy())
+	SetPolicy(Policy{
+		TransientRequeueAfter: 20 * time.Second,
+		ConflictRequeueAfter:  0,
+		NotFoundRequeueAfter:  30 * time.Second,
+	})
+
+	gr := schema.GroupResource{Group: "cluster.x-k8s.io", Resource: "machines"}
+
+	tests := []struct {
+		name         string
+		result       reconcile.Result
+		err          error
+		wantResult   reconcile.Result
+		wantErr      bool
+		wantTerminal bool
+	}{
+		{
+			name:       "returns the result if there are no errors",
+			result:     reconcile.Result{RequeueAfter: time.Minute},
+			wantResult: reconcile.Result{RequeueAfter: time.Minute},
+		},
+		{
+			name:    "returns unknown errors unchanged",
+			err:     errors.New("unknown"),
+			wantErr: true,
+		},
+		{
+			name:         "returns terminal errors",
+			err:          Terminal(errors.New("invalid configuration")),
+			wantErr:      true,
+			wantTerminal: true,
+		},
+		{
+			name:         "returns invalid errors as terminal errors",
+			err:          apierrors.NewInvalid(schema.GroupKind{Group: "cluster.x-k8s.io", Kind: "Machine"}, "m1", nil),
+			wantErr:      true,
+			wantTerminal: true,
+		},
+		{
+			name:       "requeues after the transient delay on transient errors",
+			err:        Transient(errors.New("timeout")),
+			wantResult: reconcile.Result{RequeueAfter: 20 * time.Second},
+		},
+		{
+			name:       "requeues after the not found delay on not found errors",
+			err:        apierrors.NewNotFound(gr, "m1"),
+			wantResult: reconcile.Result{RequeueAfter: 30 * time.Second},
+		},
+		{
+			name:       "requeues immediately on conflict errors if the conflict delay is zero",
+			err:        apierrors.NewConflict(gr, "m1", errors.New("conflict")),
+			wantResult: reconcile.Result{Requeue: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := Reconciler(reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				return tt.result, tt.err
+			}))
+			result, err := r.Reconcile(context.Background(), reconcile.Request{})
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(errors.Is(err, tt.err)).To(BeTrue())
+				g.Expect(errors.Is(err, reconcile.TerminalError(nil))).To(Equal(tt.wantTerminal))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(result).To(Equal(tt.wantResult))
+		})
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package requeue implements helpers to classify reconcile errors and to compute
+// consistent requeue decisions for them; reconcilers wrapped with Reconciler have
+// the returned errors handled according to their class.
+package requeue
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ErrorClass classifies errors surfacing during reconcile according to how they should be retried.
+type ErrorClass string
+
+const (
+	// UnknownErrorClass is the class of errors which can't be classified; they are returned to controller-runtime,
+	// which retries them with exponential backoff.
+	UnknownErrorClass ErrorClass = "Unknown"
+
+	// TerminalErrorClass is the class of errors which can't be fixed by retrying, e.g. invalid configurations;
+	// the object is not requeued until it changes.
+	TerminalErrorClass ErrorClass = "Terminal"
+
+	// TransientErrorClass is the class of errors caused by temporary issues of the infrastructure or
+	// of the API servers, e.g. timeouts, throttling or unreachable endpoints.
+	TransientErrorClass ErrorClass = "Transient"
+
+	// ConflictErrorClass is the class of errors caused by updating an object using a stale copy.
+	ConflictErrorClass ErrorClass = "Conflict"
+
+	// NotFoundErrorClass is the class of errors caused by an object which does not exist yet, e.g. a referenced object
+	// which has not been created yet.
+	NotFoundErrorClass ErrorClass = "NotFound"
+)
+
+// Terminal marks err as a terminal error.
+func Terminal(err error) error {
+	return reconcile.TerminalError(err)
+}
+
+// Transient marks err as a transient error.
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &transientError{err: err}
+}
+
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientError) Unwrap() error {
+	return e.err
+}
+
+// Classify returns the ErrorClass of err; errors wrapped with github.com/pkg/errors or fmt.Errorf are classified
+// according to the error they wrap.
+func Classify(err error) ErrorClass {
+	if err == nil {
+		return ""
+	}
+
+	// Explicitly marked errors take precedence.
+	if errors.Is(err, reconcile.TerminalError(nil)) {
+		return TerminalErrorClass
+	}
+	var transient *transientError
+	if errors.As(err, &transient) {
+		return TransientErrorClass
+	}
+
+	switch {
+	case apierrors.IsConflict(err):
+		return ConflictErrorClass
+	case apierrors.IsNotFound(err):
+		return NotFoundErrorClass
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		return TerminalErrorClass
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err), apierrors.IsTooManyRequests(err),
+		apierrors.IsServiceUnavailable(err), apierrors.IsInternalError(err), apierrors.IsUnexpectedServerError(err):
+		return TransientErrorClass
+	case errors.Is(err, context.DeadlineExceeded):
+		return TransientErrorClass
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return TransientErrorClass
+	}
+	return UnknownErrorClass
+}
+
+// Policy defines after how long objects are requeued for each ErrorClass.
+type Policy struct {
+	// TransientRequeueAfter is the requeue delay for transient errors.
+	TransientRequeueAfter time.Duration
+
+	// ConflictRequeueAfter is the requeue delay for conflict errors; if zero, the object is requeued immediately.
+	ConflictRequeueAfter time.Duration
+
+	// NotFoundRequeueAfter is the requeue delay for not found errors.
+	NotFoundRequeueAfter time.Duration
+}
+
+// DefaultPolicy is the Policy used if none is set.
+var DefaultPolicy = Policy{
+	TransientRequeueAfter: 20 * time.Second,
+	ConflictRequeueAfter:  0,
+	NotFoundRequeueAfter:  30 * time.Second,
+}
+
+var (
+	policyLock sync.RWMutex
+	policy     = DefaultPolicy
+)
+
+// SetPolicy sets the Policy used by the package level functions.
+func SetPolicy(p Policy) {
+	policyLock.Lock()
+	defer policyLock.Unlock()
+	policy = p
+}
+
+// GetPolicy returns the Policy used by the package level functions.
+func GetPolicy() Policy {
+	policyLock.RLock()
+	defer policyLock.RUnlock()
+	return policy
+}
+
+// After returns the requeue delay for the given ErrorClass according to the configured Policy.
+func After(class ErrorClass) time.Duration {
+	return GetPolicy().After(class)
+}
+
+// After returns the requeue delay for the given ErrorClass; it is zero for classes which are not requeued after a delay.
+func (p Policy) After(class ErrorClass) time.Duration {
+	switch class {
+	case TransientErrorClass:
+		return p.TransientRequeueAfter
+	case ConflictErrorClass:
+		return p.ConflictRequeueAfter
+	case NotFoundErrorClass:
+		return p.NotFoundRequeueAfter
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requeue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestClassify(t *testing.T) {
+	gr := schema.GroupResource{Group: "cluster.x-k8s.io", Resource: "machines"}
+
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{
+			name: "nil error",
+			err:  nil,
+			want: "",
+		},
+		{
+			name: "generic error",
+			err:  errors.New("boom"),
+			want: UnknownErrorClass,
+		},
+		{
+			name: "terminal error",
+			err:  Terminal(errors.New("invalid configuration")),
+			want: TerminalErrorClass,
+		},
+		{
+			name: "wrapped terminal error",
+			err:  pkgerrors.Wrap(Terminal(errors.New("invalid configuration")), "failed to reconcile"),
+			want: TerminalErrorClass,
+		},
+		{
+			name: "transient error",
+			err:  Transient(errors.New("infrastructure not reachable")),
+			want: TransientErrorClass,
+		},
+		{
+			name: "transient error takes precedence over the wrapped error",
+			err:  Transient(apierrors.NewNotFound(gr, "foo")),
+			want: TransientErrorClass,
+		},
+		{
+			name: "conflict error",
+			err:  pkgerrors.Wrap(apierrors.NewConflict(gr, "foo", errors.New("stale")), "failed to update"),
+			want: ConflictErrorClass,
+		},
+		{
+			name: "not found error",
+			err:  pkgerrors.Wrap(apierrors.NewNotFound(gr, "foo"), "failed to get"),
+			want: NotFoundErrorClass,
+		},
+		{
+			name: "invalid error",
+			err:  apierrors.NewInvalid(schema.GroupKind{Group: gr.Group, Kind: "Machine"}, "foo", nil),
+			want: TerminalErrorClass,
+		},
+		{
+			name: "too many requests error",
+			err:  apierrors.NewTooManyRequests("slow down", 1),
+			want: TransientErrorClass,
+		},
+		{
+			name: "service unavailable error",
+			err:  apierrors.NewServiceUnavailable("unavailable"),
+			want: TransientErrorClass,
+		},
+		{
+			name: "context deadline exceeded",
+			err:  pkgerrors.Wrap(context.DeadlineExceeded, "failed to connect"),
+			want: TransientErrorClass,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(Classify(tt.err)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestPolicyAfter(t *testing.T) {
+	p := Policy{
+		TransientRequeueAfter: 10 * time.Second,
+		ConflictRequeueAfter:  0,
+		NotFoundRequeueAfter:  time.Minute,
+	}
+
+	tests := []struct {
+		class ErrorClass
+		want  time.Duration
+	}{
+		{class: TransientErrorClass, want: 10 * time.Second},
+		{class: ConflictErrorClass, want: 0},
+		{class: NotFoundErrorClass, want: time.Minute},
+		{class: TerminalErrorClass, want: 0},
+		{class: UnknownErrorClass, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.class), func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(p.After(tt.class)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestSetPolicy(t *testing.T) {
+	g := NewWithT(t)
+
+	defer SetPolicy(DefaultPolicy)
+
+	g.Expect(After(TransientErrorClass)).To(Equal(DefaultPolicy.TransientRequeueAfter))
+
+	SetPolicy(Policy{TransientRequeueAfter: 5 * time.Second})
+	g.Expect(After(TransientErrorClass)).To(Equal(5 * time.Second))
+	g.Expect(After(UnknownErrorClass)).To(Equal(time.Duration(0)))
+}
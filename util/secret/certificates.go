@@ -215,7 +215,7 @@ func (c Certificates) LookupCached(ctx context.Context, secretCachingClient, ctr
 			return err
 		}
 		// If a user has a badly formatted secret it will prevent the cluster from working.
-		kp, err := secretToKeyPair(s)
+		kp, err := secretToKeyPair(ctx, s)
 		if err != nil {
 			return errors.Wrapf(err, "failed to read keypair from certificate %s", klog.KObj(s))
 		}
@@ -267,9 +267,16 @@ func (c Certificates) EnsureAllExist() error {
 
 // Generate will generate any certificates that do not have KeyPair data.
 func (c Certificates) Generate() error {
+	return c.GenerateWithSigner(context.Background(), nil)
+}
+
+// GenerateWithSigner will generate any certificates that do not have KeyPair data.
+// If signer is not nil, certificate authorities are generated as intermediate CAs issued by the signer
+// instead of self-signed root CAs.
+func (c Certificates) GenerateWithSigner(ctx context.Context, signer certs.Signer) error {
 	for _, certificate := range c {
 		if certificate.KeyPair == nil {
-			err := certificate.Generate()
+			err := certificate.GenerateWithSigner(ctx, signer)
 			if err != nil {
 				return err
 			}
@@ -285,6 +292,9 @@ func (c Certificates) SaveGenerated(ctx context.Context, ctrlclient client.Clien
 			continue
 		}
 		s := certificate.AsSecret(clusterName, owner)
+		if err := encryptKeyData(ctx, s); err != nil {
+			return err
+		}
 		if err := ctrlclient.Create(ctx, s); err != nil {
 			return errors.WithStack(err)
 		}
@@ -302,13 +312,19 @@ func (c Certificates) LookupOrGenerate(ctx context.Context, ctrlclient client.Cl
 // During lookup we first try to lookup the certificate secret via the secretCachingClient. If we get a NotFound error
 // we fall back to the regular uncached client.
 func (c Certificates) LookupOrGenerateCached(ctx context.Context, secretCachingClient, ctrlclient client.Client, clusterName client.ObjectKey, owner metav1.OwnerReference) error {
+	return c.LookupOrGenerateCachedWithSigner(ctx, secretCachingClient, ctrlclient, clusterName, owner, nil)
+}
+
+// LookupOrGenerateCachedWithSigner is like LookupOrGenerateCached, but if signer is not nil certificate authorities
+// that don't exist are generated as intermediate CAs issued by the signer.
+func (c Certificates) LookupOrGenerateCachedWithSigner(ctx context.Context, secretCachingClient, ctrlclient client.Client, clusterName client.ObjectKey, owner metav1.OwnerReference, signer certs.Signer) error {
 	// Find the certificates that exist
 	if err := c.LookupCached(ctx, secretCachingClient, ctrlclient, clusterName); err != nil {
 		return err
 	}
 
 	// Generate the certificates that don't exist
-	if err := c.Generate(); err != nil {
+	if err := c.GenerateWithSigner(ctx, signer); err != nil {
 		return err
 	}
 
@@ -396,14 +412,26 @@ func (c *Certificate) AsFiles() []bootstrapv1.File {
 
 // Generate generates a certificate.
 func (c *Certificate) Generate() error {
+	return c.GenerateWithSigner(context.Background(), nil)
+}
+
+// GenerateWithSigner generates a certificate.
+// If signer is not nil and the certificate is a certificate authority, an intermediate CA issued by the
+// signer is generated instead of a self-signed root CA; service account keys are always generated locally.
+func (c *Certificate) GenerateWithSigner(ctx context.Context, signer certs.Signer) error {
 	// Do not generate the APIServerEtcdClient key pair. It is user supplied
 	if c.Purpose == APIServerEtcdClient {
 		return nil
 	}
 
 	generator := generateCACert
-	if c.Purpose == ServiceAccount {
+	switch {
+	case c.Purpose == ServiceAccount:
 		generator = generateServiceAccountKeys
+	case signer != nil:
+		generator = func() (*certs.KeyPair, error) {
+			return certs.NewIntermediateCA(ctx, signer, &certs.Config{CommonName: "kubernetes"})
+		}
 	}
 
 	kp, err := generator()
@@ -440,7 +468,7 @@ func (c Certificates) AsFiles() []bootstrapv1.File {
 	return certFiles
 }
 
-func secretToKeyPair(s *corev1.Secret) (*certs.KeyPair, error) {
+func secretToKeyPair(ctx context.Context, s *corev1.Secret) (*certs.KeyPair, error) {
 	c, exists := s.Data[TLSCrtDataName]
 	if !exists {
 		return nil, errors.Errorf("missing data for key %s", TLSCrtDataName)
@@ -448,7 +476,10 @@ func secretToKeyPair(s *corev1.Secret) (*certs.KeyPair, error) {
 
 	// In some cases (external etcd) it's ok if the etcd.key does not exist.
 	// TODO: some other function should ensure that the certificates we need exist.
-	key, exists := s.Data[TLSKeyDataName]
+	key, exists, err := KeyData(ctx, s)
+	if err != nil {
+		return nil, err
+	}
 	if !exists {
 		key = []byte("")
 	}
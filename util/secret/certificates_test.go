@@ -17,11 +17,19 @@ limitations under the License.
 package secret_test
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
+	"k8s.io/client-go/util/cert"
 
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/certs"
 	"sigs.k8s.io/cluster-api/util/secret"
 )
 
@@ -53,3 +61,44 @@ func TestNewControlPlaneJoinCertsAsFilesNotPanicsWhenEmpty(t *testing.T) {
 	certs := secret.NewControlPlaneJoinCerts(config)
 	g.Expect(certs.AsFiles()).To(BeEmpty())
 }
+
+func TestGenerateWithSigner(t *testing.T) {
+	g := NewWithT(t)
+
+	rootKey, err := certs.NewPrivateKey()
+	g.Expect(err).ToNot(HaveOccurred())
+	rootTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "root"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	b, err := x509.CreateCertificate(rand.Reader, rootTmpl, rootTmpl, rootKey.Public(), rootKey)
+	g.Expect(err).ToNot(HaveOccurred())
+	rootCert, err := x509.ParseCertificate(b)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	certificates := secret.NewCertificatesForInitialControlPlane(&bootstrapv1.ClusterConfiguration{})
+	g.Expect(certificates.GenerateWithSigner(context.Background(), certs.NewKeyPairSigner(rootCert, rootKey))).To(Succeed())
+	g.Expect(certificates.EnsureAllExist()).To(Succeed())
+
+	for _, purpose := range []secret.Purpose{secret.ClusterCA, secret.EtcdCA, secret.FrontProxyCA} {
+		c := certificates.GetByPurpose(purpose)
+		g.Expect(c.Generated).To(BeTrue())
+
+		chain, err := cert.ParseCertsPEM(c.KeyPair.Cert)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(chain).To(HaveLen(2), "%s should be an intermediate CA", purpose)
+		g.Expect(chain[0].CheckSignatureFrom(rootCert)).To(Succeed())
+		g.Expect(c.KeyPair.Key).ToNot(Equal(certs.EncodePrivateKeyPEM(rootKey)))
+	}
+
+	// Service account keys are not certificates and are always generated locally.
+	sa := certificates.GetByPurpose(secret.ServiceAccount)
+	g.Expect(sa.Generated).To(BeTrue())
+	_, err = cert.ParseCertsPEM(sa.KeyPair.Cert)
+	g.Expect(err).To(HaveOccurred())
+}
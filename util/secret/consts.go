@@ -25,6 +25,14 @@ const (
 
 	// TLSCrtDataName is the key used to store a TLS certificate in the secret's data field.
 	TLSCrtDataName = "tls.crt"
+
+	// TLSKeyDEKDataName is the key used to store the wrapped data encryption key in the secret's data field
+	// when the TLS private key is envelope encrypted.
+	TLSKeyDEKDataName = "tls.key.dek"
+
+	// KeyEncryptionKeyAnnotation is the annotation recording the name of the key encryption key
+	// used to envelope encrypt the TLS private key stored in the secret.
+	KeyEncryptionKeyAnnotation = "cluster.x-k8s.io/key-encryption-key"
 )
 
 // Purpose is the name to append to the secret generated for a cluster.
@@ -34,6 +42,10 @@ const (
 	// Kubeconfig is the secret name suffix storing the Cluster Kubeconfig.
 	Kubeconfig = Purpose("kubeconfig")
 
+	// InternalKubeconfig is the secret name suffix storing the Kubeconfig used by the management cluster controllers
+	// to connect to the Cluster, when they must use credentials separate from the ones handed out to users.
+	InternalKubeconfig = Purpose("kubeconfig-internal")
+
 	// ClusterCA is the secret name suffix for APIServer CA.
 	ClusterCA = Purpose("ca")
 
@@ -48,9 +60,14 @@ const (
 
 	// APIServerEtcdClient is the secret name of user-supplied secret containing the apiserver-etcd-client key/cert.
 	APIServerEtcdClient = Purpose("apiserver-etcd-client")
+
+	// WorkloadClusterAuth is the secret name suffix storing the configuration used by the management cluster
+	// to authenticate to the workload cluster instead of the credentials in the Cluster Kubeconfig.
+	// Like the other Secrets read by the controllers, it must have the cluster.x-k8s.io/cluster-name label.
+	WorkloadClusterAuth = Purpose("auth")
 )
 
 var (
 	// allSecretPurposes defines a lists with all the secret suffix used by Cluster API.
-	allSecretPurposes = []Purpose{Kubeconfig, ClusterCA, EtcdCA, ServiceAccount, FrontProxyCA, APIServerEtcdClient}
+	allSecretPurposes = []Purpose{Kubeconfig, InternalKubeconfig, ClusterCA, EtcdCA, ServiceAccount, FrontProxyCA, APIServerEtcdClient, WorkloadClusterAuth}
 )
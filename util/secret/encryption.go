@@ -0,0 +1,197 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// KeyEncryptionService wraps and unwraps data encryption keys with a key encryption key (KEK)
+// which is managed outside of the management cluster, e.g. by a KMS plugin.
+type KeyEncryptionService interface {
+	// Name identifies the key encryption key; it is recorded on the Secrets it has been used for.
+	Name() string
+
+	// Encrypt wraps the given data encryption key.
+	Encrypt(ctx context.Context, plaintext []byte) ([]byte, error)
+
+	// Decrypt unwraps a data encryption key previously wrapped by Encrypt.
+	Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+var (
+	keyEncryptionServiceLock sync.RWMutex
+	keyEncryptionService     KeyEncryptionService
+)
+
+// SetKeyEncryptionService sets the KeyEncryptionService used to envelope encrypt the private keys
+// of the certificate Secrets generated from now on; passing nil disables encryption of new Secrets.
+// Secrets already encrypted can only be read while a KeyEncryptionService is set.
+func SetKeyEncryptionService(s KeyEncryptionService) {
+	keyEncryptionServiceLock.Lock()
+	defer keyEncryptionServiceLock.Unlock()
+	keyEncryptionService = s
+}
+
+func getKeyEncryptionService() KeyEncryptionService {
+	keyEncryptionServiceLock.RLock()
+	defer keyEncryptionServiceLock.RUnlock()
+	return keyEncryptionService
+}
+
+// IsKeyEncrypted returns true if the private key stored in the certificate Secret is envelope encrypted.
+func IsKeyEncrypted(s *corev1.Secret) bool {
+	_, ok := s.Data[TLSKeyDEKDataName]
+	return ok
+}
+
+// KeyData returns the private key stored in a certificate Secret, transparently decrypting it if it
+// has been envelope encrypted; the returned bool is false if the Secret does not contain a private key.
+func KeyData(ctx context.Context, s *corev1.Secret) ([]byte, bool, error) {
+	key, ok := s.Data[TLSKeyDataName]
+	if !ok || !IsKeyEncrypted(s) {
+		return key, ok, nil
+	}
+
+	kes := getKeyEncryptionService()
+	if kes == nil {
+		return nil, false, errors.Errorf("failed to decrypt key from secret %s: key is encrypted with %q, but no key encryption service is configured", klog.KObj(s), s.Annotations[KeyEncryptionKeyAnnotation])
+	}
+
+	dek, err := kes.Decrypt(ctx, s.Data[TLSKeyDEKDataName])
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to decrypt data encryption key from secret %s", klog.KObj(s))
+	}
+	plaintext, err := aesGCMDecrypt(dek, key)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to decrypt key from secret %s", klog.KObj(s))
+	}
+	return plaintext, true, nil
+}
+
+// encryptKeyData envelope encrypts the private key of a certificate Secret using a new data encryption key
+// wrapped by the configured KeyEncryptionService; it is a no-op if no KeyEncryptionService is configured,
+// the Secret doesn't contain a private key, or the key is already encrypted.
+func encryptKeyData(ctx context.Context, s *corev1.Secret) error {
+	kes := getKeyEncryptionService()
+	if kes == nil || IsKeyEncrypted(s) || len(s.Data[TLSKeyDataName]) == 0 {
+		return nil
+	}
+
+	dek := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return errors.Wrap(err, "failed to generate data encryption key")
+	}
+	ciphertext, err := aesGCMEncrypt(dek, s.Data[TLSKeyDataName])
+	if err != nil {
+		return errors.Wrapf(err, "failed to encrypt key for secret %s", klog.KObj(s))
+	}
+	wrappedDEK, err := kes.Encrypt(ctx, dek)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encrypt data encryption key for secret %s", klog.KObj(s))
+	}
+
+	s.Data[TLSKeyDataName] = ciphertext
+	s.Data[TLSKeyDEKDataName] = wrappedDEK
+	if s.Annotations == nil {
+		s.Annotations = map[string]string{}
+	}
+	s.Annotations[KeyEncryptionKeyAnnotation] = kes.Name()
+	return nil
+}
+
+// NewAESKeyEncryptionService returns a KeyEncryptionService wrapping data encryption keys with AES-GCM
+// using the given key encryption key, which must be 16, 24 or 32 bytes long.
+// The key encryption key is expected to be provided to the controllers from outside the management cluster,
+// e.g. mounted from a file provided by a secret store.
+func NewAESKeyEncryptionService(name string, kek []byte) (KeyEncryptionService, error) {
+	if name == "" {
+		return nil, errors.New("key encryption key name must not be empty")
+	}
+	if _, err := aes.NewCipher(kek); err != nil {
+		return nil, errors.Wrap(err, "invalid key encryption key")
+	}
+	return &aesKeyEncryptionService{name: name, kek: kek}, nil
+}
+
+type aesKeyEncryptionService struct {
+	name string
+	kek  []byte
+}
+
+func (s *aesKeyEncryptionService) Name() string {
+	return s.name
+}
+
+func (s *aesKeyEncryptionService) Encrypt(_ context.Context, plaintext []byte) ([]byte, error) {
+	return aesGCMEncrypt(s.kek, plaintext)
+}
+
+func (s *aesKeyEncryptionService) Decrypt(_ context.Context, ciphertext []byte) ([]byte, error) {
+	return aesGCMDecrypt(s.kek, ciphertext)
+}
+
+// aesGCMEncrypt encrypts plaintext with AES-GCM, prepending the random nonce to the ciphertext.
+func aesGCMEncrypt(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// aesGCMDecrypt decrypts data encrypted by aesGCMEncrypt.
+func aesGCMDecrypt(key, ciphertext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt")
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cipher")
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create GCM cipher")
+	}
+	return gcm, nil
+}
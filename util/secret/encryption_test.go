@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+)
+
+func TestNewAESKeyEncryptionService(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := NewAESKeyEncryptionService("", bytes.Repeat([]byte("k"), 32))
+	g.Expect(err).To(HaveOccurred())
+	_, err = NewAESKeyEncryptionService("test", []byte("too-short"))
+	g.Expect(err).To(HaveOccurred())
+
+	kes, err := NewAESKeyEncryptionService("test", bytes.Repeat([]byte("k"), 32))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(kes.Name()).To(Equal("test"))
+
+	ciphertext, err := kes.Encrypt(context.Background(), []byte("dek"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ciphertext).ToNot(ContainSubstring("dek"))
+	plaintext, err := kes.Decrypt(context.Background(), ciphertext)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(plaintext).To(Equal([]byte("dek")))
+
+	other, err := NewAESKeyEncryptionService("other", bytes.Repeat([]byte("o"), 32))
+	g.Expect(err).ToNot(HaveOccurred())
+	_, err = other.Decrypt(context.Background(), ciphertext)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestCertificatesKeyEncryption(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	kes, err := NewAESKeyEncryptionService("test", bytes.Repeat([]byte("k"), 32))
+	g.Expect(err).ToNot(HaveOccurred())
+	SetKeyEncryptionService(kes)
+	defer SetKeyEncryptionService(nil)
+
+	c := fake.NewClientBuilder().Build()
+	clusterKey := client.ObjectKey{Name: "test1", Namespace: "test"}
+
+	generated := NewCertificatesForInitialControlPlane(&bootstrapv1.ClusterConfiguration{})
+	g.Expect(generated.LookupOrGenerate(ctx, c, clusterKey, metav1.OwnerReference{})).To(Succeed())
+
+	// Private keys are stored encrypted.
+	s := &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Name: Name(clusterKey.Name, ClusterCA), Namespace: clusterKey.Namespace}, s)).To(Succeed())
+	g.Expect(IsKeyEncrypted(s)).To(BeTrue())
+	g.Expect(s.Annotations).To(HaveKeyWithValue(KeyEncryptionKeyAnnotation, "test"))
+	g.Expect(s.Data[TLSKeyDataName]).ToNot(Equal(generated.GetByPurpose(ClusterCA).KeyPair.Key))
+
+	// Private keys are transparently decrypted on lookup.
+	lookedUp := NewCertificatesForInitialControlPlane(&bootstrapv1.ClusterConfiguration{})
+	g.Expect(lookedUp.Lookup(ctx, c, clusterKey)).To(Succeed())
+	for _, purpose := range []Purpose{ClusterCA, EtcdCA, FrontProxyCA, ServiceAccount} {
+		g.Expect(lookedUp.GetByPurpose(purpose).KeyPair).To(Equal(generated.GetByPurpose(purpose).KeyPair), "key pair for %s does not match", purpose)
+	}
+
+	keyData, ok, err := KeyData(ctx, s)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ok).To(BeTrue())
+	g.Expect(keyData).To(Equal(generated.GetByPurpose(ClusterCA).KeyPair.Key))
+
+	// Encrypted keys can't be read without a key encryption service.
+	SetKeyEncryptionService(nil)
+	g.Expect(NewCertificatesForInitialControlPlane(&bootstrapv1.ClusterConfiguration{}).Lookup(ctx, c, clusterKey)).ToNot(Succeed())
+}
+
+func TestKeyDataNotEncrypted(t *testing.T) {
+	g := NewWithT(t)
+
+	s := &corev1.Secret{Data: map[string][]byte{TLSKeyDataName: []byte("key")}}
+	keyData, ok, err := KeyData(context.Background(), s)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ok).To(BeTrue())
+	g.Expect(keyData).To(Equal([]byte("key")))
+
+	_, ok, err = KeyData(context.Background(), &corev1.Secret{})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ok).To(BeFalse())
+}
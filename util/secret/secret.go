@@ -56,16 +56,20 @@ func Name(cluster string, suffix Purpose) string {
 // ParseSecretName return the cluster name and the suffix Purpose in name is a valid cluster secret,
 // otherwise it return error.
 func ParseSecretName(name string) (string, Purpose, error) {
-	separatorPos := strings.LastIndex(name, "-")
-	if separatorPos == -1 {
+	if !strings.Contains(name, "-") {
 		return "", "", errors.Errorf("%q is not a valid cluster secret name. The purpose suffix is missing", name)
 	}
-	clusterName := name[:separatorPos]
-	purposeSuffix := Purpose(name[separatorPos+1:])
+	// Note: purposes can contain "-", the longest matching purpose wins, e.g. "kubeconfig-internal" over "internal".
+	var clusterName string
+	var purposeSuffix Purpose
 	for _, purpose := range allSecretPurposes {
-		if purpose == purposeSuffix {
-			return clusterName, purposeSuffix, nil
+		if strings.HasSuffix(name, "-"+string(purpose)) && len(purpose) > len(purposeSuffix) {
+			clusterName = strings.TrimSuffix(name, "-"+string(purpose))
+			purposeSuffix = purpose
 		}
 	}
-	return "", "", errors.Errorf("%q is not a valid cluster secret name. Invalid purpose suffix", name)
+	if clusterName == "" {
+		return "", "", errors.Errorf("%q is not a valid cluster secret name. Invalid purpose suffix", name)
+	}
+	return clusterName, purposeSuffix, nil
 }
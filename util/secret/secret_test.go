@@ -51,6 +51,15 @@ func TestParseSecretName(t *testing.T) {
 			want1:   ClusterCA,
 			wantErr: false,
 		},
+		{
+			name: "A secret with - in the purpose suffix",
+			args: args{
+				name: "test-capa-kubeconfig-internal",
+			},
+			want:    "test-capa",
+			want1:   InternalKubeconfig,
+			wantErr: false,
+		},
 		{
 			name: "Not a Cluster API secret",
 			args: args{
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package shard implements utilities to shard controllers by Cluster, so multiple manager replicas
+// can each reconcile a subset of the Clusters and of the objects belonging to them.
+package shard
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+)
+
+// Shard is the subset of the Clusters, and of the objects belonging to them, owned by a manager replica.
+// A Cluster is owned by the shard whose index is the value of its ClusterShardLabel, if set and valid,
+// or otherwise by the shard whose index is the hash of the Cluster namespace and name modulo the number of shards.
+type Shard struct {
+	index  int
+	count  int
+	reader client.Reader
+}
+
+// New returns the Shard with the given index out of count shards; reader is used to read the Clusters
+// the objects belong to, in order to check their ClusterShardLabel, and it is expected to be backed by a cache.
+func New(index, count int, reader client.Reader) (*Shard, error) {
+	if count < 1 {
+		return nil, errors.Errorf("invalid shard count %d: must be greater than zero", count)
+	}
+	if index < 0 || index >= count {
+		return nil, errors.Errorf("invalid shard index %d: must be between 0 and %d", index, count-1)
+	}
+	return &Shard{index: index, count: count, reader: reader}, nil
+}
+
+// String returns the Shard in the "index-of-count" form, e.g. "0-of-3".
+func (s *Shard) String() string {
+	return fmt.Sprintf("%d-of-%d", s.index, s.count)
+}
+
+// Owns returns true if the object belongs to a Cluster owned by the Shard; a nil Shard owns all the objects.
+// The Cluster of an object is determined with util.ClusterNameForObject; objects which do not belong to a Cluster,
+// e.g. ClusterClasses and ExtensionConfigs, are owned by the first shard only, so they are never reconciled concurrently
+// by multiple shards.
+func (s *Shard) Owns(ctx context.Context, obj client.Object) bool {
+	if s == nil || s.count == 1 {
+		return true
+	}
+	if cluster, ok := obj.(*clusterv1.Cluster); ok {
+		return s.ownsCluster(client.ObjectKeyFromObject(cluster), cluster.GetLabels())
+	}
+	clusterName, ok := util.ClusterNameForObject(obj)
+	if !ok {
+		return s.index == 0
+	}
+	return s.OwnsCluster(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: clusterName})
+}
+
+// OwnsCluster returns true if the Cluster is owned by the Shard; a nil Shard owns all the Clusters.
+func (s *Shard) OwnsCluster(ctx context.Context, cluster types.NamespacedName) bool {
+	if s == nil || s.count == 1 {
+		return true
+	}
+	var clusterLabels map[string]string
+	if s.reader != nil {
+		c := &clusterv1.Cluster{}
+		if err := s.reader.Get(ctx, cluster, c); err == nil {
+			clusterLabels = c.GetLabels()
+		}
+	}
+	return s.ownsCluster(cluster, clusterLabels)
+}
+
+func (s *Shard) ownsCluster(cluster types.NamespacedName, clusterLabels map[string]string) bool {
+	if value, ok := clusterLabels[clusterv1.ClusterShardLabel]; ok {
+		if index, err := strconv.Atoi(value); err == nil && index >= 0 && index < s.count {
+			return index == s.index
+		}
+	}
+	return IndexForCluster(cluster, s.count) == s.index
+}
+
+// IndexForCluster returns the index of the shard a Cluster without the ClusterShardLabel is assigned to.
+func IndexForCluster(cluster types.NamespacedName, count int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(cluster.String()))
+	return int(h.Sum32() % uint32(count))
+}
+
+var (
+	currentLock sync.RWMutex
+	current     *Shard
+)
+
+// Set sets the Shard of the manager, which is used by the predicates filtering the events of the controllers;
+// passing nil disables sharding.
+func Set(s *Shard) {
+	currentLock.Lock()
+	defer currentLock.Unlock()
+	current = s
+}
+
+// Get returns the Shard set by Set; it can be nil.
+func Get() *Shard {
+	currentLock.RLock()
+	defer currentLock.RUnlock()
+	return current
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shard
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestNew(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := New(0, 0, nil)
+	g.Expect(err).To(HaveOccurred())
+	_, err = New(3, 3, nil)
+	g.Expect(err).To(HaveOccurred())
+	_, err = New(-1, 3, nil)
+	g.Expect(err).To(HaveOccurred())
+
+	s, err := New(2, 3, nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(s.String()).To(Equal("2-of-3"))
+}
+
+func TestOwns(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	labeledCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "labeled",
+			Labels:    map[string]string{clusterv1.ClusterShardLabel: "1"},
+		},
+	}
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(labeledCluster).Build()
+
+	shards := make([]*Shard, 3)
+	for i := range shards {
+		s, err := New(i, len(shards), reader)
+		g.Expect(err).ToNot(HaveOccurred())
+		shards[i] = s
+	}
+
+	ownedBy := func(obj func() *clusterv1.Machine) []int {
+		owners := []int{}
+		for i, s := range shards {
+			if s.Owns(ctx, obj()) {
+				owners = append(owners, i)
+			}
+		}
+		return owners
+	}
+
+	// Every Cluster, and the objects belonging to it, is owned by exactly one shard.
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("cluster-%d", i)
+		cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name}}
+		index := IndexForCluster(types.NamespacedName{Namespace: "ns", Name: name}, len(shards))
+		for j, s := range shards {
+			g.Expect(s.Owns(ctx, cluster)).To(Equal(j == index))
+		}
+		g.Expect(ownedBy(func() *clusterv1.Machine {
+			return &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "m", Labels: map[string]string{clusterv1.ClusterNameLabel: name}}}
+		})).To(Equal([]int{index}))
+	}
+
+	// Clusters with the shard label are owned by the shard in the label, like the objects belonging to them.
+	for j, s := range shards {
+		g.Expect(s.Owns(ctx, labeledCluster)).To(Equal(j == 1))
+	}
+	g.Expect(ownedBy(func() *clusterv1.Machine {
+		return &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "m", Labels: map[string]string{clusterv1.ClusterNameLabel: "labeled"}}}
+	})).To(Equal([]int{1}))
+
+	// Objects without the cluster name label are owned by the shard of the Cluster in spec.clusterName.
+	g.Expect(ownedBy(func() *clusterv1.Machine {
+		return &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "m"}, Spec: clusterv1.MachineSpec{ClusterName: "labeled"}}
+	})).To(Equal([]int{1}))
+
+	// Objects not belonging to a Cluster are owned by the first shard only.
+	g.Expect(ownedBy(func() *clusterv1.Machine {
+		return &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "m"}}
+	})).To(Equal([]int{0}))
+
+	// A nil shard owns everything.
+	var s *Shard
+	g.Expect(s.Owns(ctx, labeledCluster)).To(BeTrue())
+}
+
+func TestOwnsUnlabeledControlPlane(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cluster", Labels: map[string]string{clusterv1.ClusterShardLabel: "1"}}}
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+
+	// A KubeadmControlPlane created without topology, linked to its Cluster only by the owner reference.
+	kcp := &unstructured.Unstructured{}
+	kcp.SetAPIVersion("controlplane.cluster.x-k8s.io/v1beta1")
+	kcp.SetKind("KubeadmControlPlane")
+	kcp.SetNamespace("ns")
+	kcp.SetName("kcp")
+	kcp.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: clusterv1.GroupVersion.String(), Kind: "Cluster", Name: cluster.Name}})
+
+	shard0, err := New(0, 2, reader)
+	g.Expect(err).ToNot(HaveOccurred())
+	shard1, err := New(1, 2, reader)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(shard0.Owns(ctx, kcp)).To(BeFalse())
+	g.Expect(shard1.Owns(ctx, kcp)).To(BeTrue())
+}
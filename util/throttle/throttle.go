@@ -0,0 +1,246 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package throttle implements utilities to bound the concurrency and the rate of the reconciles
+// of the objects belonging to a single Cluster.
+package throttle
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(throttledTotal)
+}
+
+var throttledTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "capi_reconcile_throttled_total",
+	Help: "Number of reconciles delayed because the concurrency or the rate limit of the cluster was reached, by controller, cluster and reason.",
+}, []string{"controller", "cluster", "reason"})
+
+const (
+	reasonConcurrency = "concurrency"
+	reasonRate        = "rate"
+
+	// concurrencyRequeueAfter is the delay after which an object is reconciled again
+	// when the concurrency limit of its Cluster has been reached.
+	concurrencyRequeueAfter = time.Second
+)
+
+// Policy defines the limits applied to the reconciles of the objects belonging to a single Cluster;
+// the limits are shared by all the controllers of a manager. Clusters can override MaxConcurrentReconciles
+// and QPS using the ReconcileConcurrencyAnnotation and the ReconcileQPSAnnotation.
+type Policy struct {
+	// MaxConcurrentReconciles is the maximum number of objects belonging to a Cluster which are reconciled
+	// concurrently. If zero, concurrency is not limited.
+	MaxConcurrentReconciles int
+
+	// QPS is the maximum number of reconciles per second of the objects belonging to a Cluster.
+	// If zero, the rate of reconciles is not limited.
+	QPS float64
+
+	// Burst is the maximum number of reconciles of the objects belonging to a Cluster which can be
+	// executed at once exceeding QPS.
+	Burst int
+}
+
+var (
+	policyLock sync.RWMutex
+	policy     Policy
+)
+
+// SetPolicy sets the Policy applied to the reconcilers wrapped with Reconciler.
+func SetPolicy(p Policy) {
+	policyLock.Lock()
+	defer policyLock.Unlock()
+	policy = p
+}
+
+// GetPolicy returns the Policy applied to the reconcilers wrapped with Reconciler.
+func GetPolicy() Policy {
+	policyLock.RLock()
+	defer policyLock.RUnlock()
+	return policy
+}
+
+// clusterLimiter tracks the reconciles in flight and the rate of reconciles of a Cluster.
+type clusterLimiter struct {
+	inFlight int
+	limiter  *rate.Limiter
+}
+
+var (
+	limitersLock sync.Mutex
+	limiters     = map[client.ObjectKey]*clusterLimiter{}
+)
+
+// Reconciler returns a reconcile.Reconciler wrapping r which enforces the Policy on the Cluster of the reconciled
+// object; reconciles exceeding the limits are not executed, and the object is requeued instead.
+// The Cluster of the reconciled object is determined with util.ClusterNameForObject after reading the object,
+// of the same type as obj, and its Cluster using c; c is expected to be a cached client, so reading the objects is cheap.
+// If the Cluster can't be determined, e.g. because the object has been deleted, no limits are enforced.
+func Reconciler(controllerName string, c client.Reader, obj client.Object, r reconcile.Reconciler) reconcile.Reconciler {
+	return &throttledReconciler{
+		controllerName: controllerName,
+		client:         c,
+		obj:            obj,
+		reconciler:     r,
+	}
+}
+
+type throttledReconciler struct {
+	controllerName string
+	client         client.Reader
+	obj            client.Object
+	reconciler     reconcile.Reconciler
+}
+
+// Reconcile calls the wrapped reconciler if the limits of the Cluster of the reconciled object have not been reached.
+func (r *throttledReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	cluster, ok := r.clusterFor(ctx, req)
+	if !ok {
+		return r.reconciler.Reconcile(ctx, req)
+	}
+	p := policyFor(cluster, GetPolicy())
+	if p.MaxConcurrentReconciles <= 0 && p.QPS <= 0 {
+		return r.reconciler.Reconcile(ctx, req)
+	}
+
+	key := client.ObjectKeyFromObject(cluster)
+	delay, reason := acquire(key, p)
+	if delay > 0 {
+		ctrl.LoggerFrom(ctx).V(4).Info("Delaying reconcile because the limits of the Cluster have been reached", "reason", reason, "requeueAfter", delay)
+		throttledTotal.WithLabelValues(r.controllerName, key.String(), reason).Inc()
+		return reconcile.Result{RequeueAfter: delay}, nil
+	}
+	defer release(key)
+
+	return r.reconciler.Reconcile(ctx, req)
+}
+
+// clusterFor returns the Cluster of the object reconciled for req.
+func (r *throttledReconciler) clusterFor(ctx context.Context, req reconcile.Request) (*clusterv1.Cluster, bool) {
+	clusterKey := req.NamespacedName
+	if _, ok := r.obj.(*clusterv1.Cluster); !ok {
+		obj, ok := r.obj.DeepCopyObject().(client.Object)
+		if !ok {
+			return nil, false
+		}
+		if err := r.client.Get(ctx, req.NamespacedName, obj); err != nil {
+			return nil, false
+		}
+		name, ok := util.ClusterNameForObject(obj)
+		if !ok {
+			return nil, false
+		}
+		clusterKey = client.ObjectKey{Namespace: req.Namespace, Name: name}
+	}
+
+	cluster := &clusterv1.Cluster{}
+	if err := r.client.Get(ctx, clusterKey, cluster); err != nil {
+		return nil, false
+	}
+	return cluster, true
+}
+
+// policyFor returns the Policy for the Cluster, applying the overrides defined by its annotations to p;
+// invalid overrides are ignored.
+func policyFor(cluster *clusterv1.Cluster, p Policy) Policy {
+	if v, ok := cluster.GetAnnotations()[clusterv1.ReconcileConcurrencyAnnotation]; ok {
+		if concurrency, err := strconv.Atoi(v); err == nil && concurrency >= 0 {
+			p.MaxConcurrentReconciles = concurrency
+		}
+	}
+	if v, ok := cluster.GetAnnotations()[clusterv1.ReconcileQPSAnnotation]; ok {
+		if qps, err := strconv.ParseFloat(v, 64); err == nil && qps >= 0 {
+			p.QPS = qps
+		}
+	}
+	return p
+}
+
+// acquire reserves a reconcile for the Cluster; if the limits of the Cluster have been reached it returns
+// the delay after which the reconcile should be attempted again and the reason.
+func acquire(cluster client.ObjectKey, p Policy) (time.Duration, string) {
+	limitersLock.Lock()
+	defer limitersLock.Unlock()
+
+	limit, burst := rate.Inf, 0
+	if p.QPS > 0 {
+		limit, burst = rate.Limit(p.QPS), p.Burst
+		if burst < 1 {
+			burst = 1
+		}
+	}
+	l, ok := limiters[cluster]
+	if !ok {
+		l = &clusterLimiter{limiter: rate.NewLimiter(limit, burst)}
+		limiters[cluster] = l
+	}
+
+	if p.MaxConcurrentReconciles > 0 && l.inFlight >= p.MaxConcurrentReconciles {
+		return concurrencyRequeueAfter, reasonConcurrency
+	}
+
+	now := time.Now()
+	if l.limiter.Limit() != limit {
+		l.limiter.SetLimitAt(now, limit)
+	}
+	if l.limiter.Burst() != burst {
+		l.limiter.SetBurstAt(now, burst)
+	}
+	if reservation := l.limiter.ReserveN(now, 1); reservation.OK() {
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			reservation.CancelAt(now)
+			return delay, reasonRate
+		}
+	}
+
+	l.inFlight++
+	return 0, ""
+}
+
+// release releases a reconcile reserved for the Cluster by acquire.
+func release(cluster client.ObjectKey) {
+	limitersLock.Lock()
+	defer limitersLock.Unlock()
+
+	if l, ok := limiters[cluster]; ok {
+		l.inFlight--
+	}
+}
+
+// ForgetCluster deletes the limiter and the metrics of the Cluster, e.g. after the Cluster has been deleted.
+func ForgetCluster(cluster client.ObjectKey) {
+	limitersLock.Lock()
+	defer limitersLock.Unlock()
+
+	delete(limiters, cluster)
+	throttledTotal.DeletePartialMatch(prometheus.Labels{"cluster": cluster.String()})
+}
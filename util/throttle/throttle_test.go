@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package throttle
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestPolicyFor(t *testing.T) {
+	g := NewWithT(t)
+
+	p := Policy{MaxConcurrentReconciles: 5, QPS: 10, Burst: 10}
+
+	cluster := &clusterv1.Cluster{}
+	g.Expect(policyFor(cluster, p)).To(Equal(p))
+
+	cluster.Annotations = map[string]string{
+		clusterv1.ReconcileConcurrencyAnnotation: "1",
+		clusterv1.ReconcileQPSAnnotation:         "0.5",
+	}
+	g.Expect(policyFor(cluster, p)).To(Equal(Policy{MaxConcurrentReconciles: 1, QPS: 0.5, Burst: 10}))
+
+	cluster.Annotations = map[string]string{
+		clusterv1.ReconcileConcurrencyAnnotation: "-1",
+		clusterv1.ReconcileQPSAnnotation:         "invalid",
+	}
+	g.Expect(policyFor(cluster, p)).To(Equal(p))
+}
+
+func TestReconciler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clusterv1.AddToScheme(scheme)
+
+	t.Run("limits the concurrency of the reconciles of a Cluster", func(t *testing.T) {
+		g := NewWithT(t)
+		defer SetPolicy(GetPolicy())
+		SetPolicy(Policy{MaxConcurrentReconciles: 1})
+
+		cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "concurrency"}}
+		machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "machine", Labels: map[string]string{clusterv1.ClusterNameLabel: cluster.Name}}}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, machine).Build()
+
+		var nested reconcile.Result
+		r := Reconciler("machine", c, &clusterv1.Machine{}, reconcile.Func(func(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+			// A reconcile of another object of the same Cluster, while this one is in flight, is throttled.
+			var err error
+			nested, err = Reconciler("cluster", c, &clusterv1.Cluster{}, reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				return reconcile.Result{}, nil
+			})).Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(cluster)})
+			return reconcile.Result{}, err
+		}))
+
+		res, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(machine)})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(res).To(Equal(reconcile.Result{}))
+		g.Expect(nested.RequeueAfter).To(Equal(concurrencyRequeueAfter))
+
+		// Once the first reconcile is completed, the Cluster can be reconciled again.
+		res, err = r.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(machine)})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(res).To(Equal(reconcile.Result{}))
+	})
+
+	t.Run("limits the rate of the reconciles of a Cluster", func(t *testing.T) {
+		g := NewWithT(t)
+		defer SetPolicy(GetPolicy())
+		SetPolicy(Policy{QPS: 0.1, Burst: 2})
+
+		cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "rate"}}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+
+		calls := 0
+		r := Reconciler("cluster", c, &clusterv1.Cluster{}, reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+			calls++
+			return reconcile.Result{}, nil
+		}))
+
+		req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(cluster)}
+		for i := 0; i < 2; i++ {
+			res, err := r.Reconcile(context.Background(), req)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(res).To(Equal(reconcile.Result{}))
+		}
+		res, err := r.Reconcile(context.Background(), req)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+		g.Expect(calls).To(Equal(2))
+	})
+
+	t.Run("applies the overrides of the Cluster", func(t *testing.T) {
+		g := NewWithT(t)
+		defer SetPolicy(GetPolicy())
+		SetPolicy(Policy{QPS: 0.1, Burst: 1})
+
+		cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "override", Annotations: map[string]string{
+			clusterv1.ReconcileQPSAnnotation: "0",
+		}}}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+
+		calls := 0
+		r := Reconciler("cluster", c, &clusterv1.Cluster{}, reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+			calls++
+			return reconcile.Result{}, nil
+		}))
+
+		for i := 0; i < 5; i++ {
+			res, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(cluster)})
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(res).To(Equal(reconcile.Result{}))
+		}
+		g.Expect(calls).To(Equal(5))
+	})
+}
+
+func TestForgetCluster(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := client.ObjectKey{Namespace: "ns", Name: "forget"}
+	delay, _ := acquire(cluster, Policy{QPS: 0.1, Burst: 1})
+	g.Expect(delay).To(BeZero())
+	release(cluster)
+	throttledTotal.WithLabelValues("cluster", cluster.String(), reasonRate).Inc()
+
+	ForgetCluster(cluster)
+
+	limitersLock.Lock()
+	g.Expect(limiters).ToNot(HaveKey(cluster))
+	limitersLock.Unlock()
+	g.Expect(testutil.ToFloat64(throttledTotal.WithLabelValues("cluster", cluster.String(), reasonRate))).To(BeZero())
+
+	// A new limiter is created if the Cluster is reconciled again.
+	delay, _ = acquire(cluster, Policy{QPS: 0.1, Burst: 1})
+	g.Expect(delay).To(BeZero())
+	release(cluster)
+	ForgetCluster(cluster)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing implements helpers to instrument controllers with OpenTelemetry spans.
+//
+// Spans are created using the global OpenTelemetry TracerProvider; unless a TracerProvider is configured,
+// e.g. using flags.SetupTracing, spans are no-ops.
+package tracing
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// InstrumentationName is the name of the OpenTelemetry tracer used by Cluster API.
+const InstrumentationName = "sigs.k8s.io/cluster-api"
+
+// Start starts a span with the given name as a child of the span in ctx, if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(InstrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartReconcile starts the root span for the reconcile of the object identified by req.
+func StartReconcile(ctx context.Context, kind string, req ctrl.Request) (context.Context, trace.Span) {
+	return Start(ctx, kind+".Reconcile",
+		attribute.String("k8s.object.kind", kind),
+		attribute.String("k8s.namespace.name", req.Namespace),
+		attribute.String("k8s.object.name", req.Name),
+	)
+}
+
+// StartPhase starts a span for a reconcile phase; the span is named after the phase func, e.g. "reconcileInfrastructure".
+func StartPhase(ctx context.Context, phase interface{}) (context.Context, trace.Span) {
+	return Start(ctx, funcName(phase))
+}
+
+// End records err, if any, on the span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// funcName returns the name of a func without package and receiver, e.g. "reconcileInfrastructure".
+func funcName(fn interface{}) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		return "unknown"
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return "unknown"
+	}
+	name := f.Name()
+	// Method values are suffixed with "-fm".
+	name = strings.TrimSuffix(name, "-fm")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+type fakeReconciler struct{}
+
+func (r *fakeReconciler) reconcileInfrastructure(_ context.Context) error {
+	return nil
+}
+
+func TestSpans(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+	otel.SetTracerProvider(tp)
+
+	r := &fakeReconciler{}
+	ctx, reconcileSpan := StartReconcile(context.Background(), "Cluster", ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "test"}})
+	_, phaseSpan := StartPhase(ctx, r.reconcileInfrastructure)
+	End(phaseSpan, errors.New("failed to reconcile infrastructure"))
+	End(reconcileSpan, nil)
+
+	spans := recorder.Ended()
+	g.Expect(spans).To(HaveLen(2))
+
+	g.Expect(spans[0].Name()).To(Equal("reconcileInfrastructure"))
+	g.Expect(spans[0].Parent().SpanID()).To(Equal(spans[1].SpanContext().SpanID()))
+	g.Expect(spans[0].Status().Code).To(Equal(codes.Error))
+	g.Expect(spans[0].Events()).To(HaveLen(1))
+
+	g.Expect(spans[1].Name()).To(Equal("Cluster.Reconcile"))
+	g.Expect(spans[1].Status().Code).To(Equal(codes.Unset))
+	g.Expect(spans[1].Attributes()).To(ContainElements(
+		attribute.String("k8s.object.kind", "Cluster"),
+		attribute.String("k8s.namespace.name", "default"),
+		attribute.String("k8s.object.name", "test"),
+	))
+}
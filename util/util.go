@@ -172,6 +172,52 @@ func GetOwnerCluster(ctx context.Context, c client.Client, obj metav1.ObjectMeta
 	return nil, nil
 }
 
+// ClusterNameForObject returns the name of the Cluster the object belongs to, read from the ClusterNameLabel,
+// from the spec.clusterName field or from the owner reference to a Cluster, in this order; it returns false
+// if the object does not belong to a Cluster, e.g. for ClusterClasses or for objects not yet linked to their Cluster.
+// The Cluster, if any, is in the same namespace of the object.
+func ClusterNameForObject(obj client.Object) (string, bool) {
+	if cluster, ok := obj.(*clusterv1.Cluster); ok {
+		return cluster.Name, true
+	}
+	if name := obj.GetLabels()[clusterv1.ClusterNameLabel]; name != "" {
+		return name, true
+	}
+	if name := specClusterName(obj); name != "" {
+		return name, true
+	}
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.Kind != "Cluster" {
+			continue
+		}
+		if gv, err := schema.ParseGroupVersion(ref.APIVersion); err == nil && gv.Group == clusterv1.GroupVersion.Group {
+			return ref.Name, true
+		}
+	}
+	return "", false
+}
+
+// specClusterName returns the value of the spec.clusterName field of the object, if any.
+func specClusterName(obj client.Object) string {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		name, _, _ := unstructured.NestedString(u.Object, "spec", "clusterName")
+		return name
+	}
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ""
+	}
+	spec := v.Elem().FieldByName("Spec")
+	if !spec.IsValid() || spec.Kind() != reflect.Struct {
+		return ""
+	}
+	clusterName := spec.FieldByName("ClusterName")
+	if !clusterName.IsValid() || clusterName.Kind() != reflect.String {
+		return ""
+	}
+	return clusterName.String()
+}
+
 // GetClusterByName finds and return a Cluster object using the specified params.
 func GetClusterByName(ctx context.Context, c client.Client, namespace, name string) (*clusterv1.Cluster, error) {
 	cluster := &clusterv1.Cluster{}
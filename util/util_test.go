@@ -480,6 +480,77 @@ func TestGetOwnerClusterSuccessByName(t *testing.T) {
 	g.Expect(cluster).NotTo(BeNil())
 }
 
+func TestClusterNameForObject(t *testing.T) {
+	clusterOwnerRef := metav1.OwnerReference{APIVersion: clusterv1.GroupVersion.String(), Kind: "Cluster", Name: "from-owner"}
+	kcp := &unstructured.Unstructured{}
+	kcp.SetOwnerReferences([]metav1.OwnerReference{clusterOwnerRef})
+
+	tests := []struct {
+		name     string
+		obj      client.Object
+		wantName string
+		wantOK   bool
+	}{
+		{
+			name:     "Cluster",
+			obj:      &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
+			wantName: "cluster",
+			wantOK:   true,
+		},
+		{
+			name: "Object with the cluster name label",
+			obj: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{clusterv1.ClusterNameLabel: "from-label"}, OwnerReferences: []metav1.OwnerReference{clusterOwnerRef}},
+				Spec:       clusterv1.MachineSpec{ClusterName: "from-spec"},
+			},
+			wantName: "from-label",
+			wantOK:   true,
+		},
+		{
+			name: "Object with spec.clusterName",
+			obj: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{clusterOwnerRef}},
+				Spec:       clusterv1.MachineSpec{ClusterName: "from-spec"},
+			},
+			wantName: "from-spec",
+			wantOK:   true,
+		},
+		{
+			name:     "Unstructured object with spec.clusterName",
+			obj:      &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{"clusterName": "from-spec"}}},
+			wantName: "from-spec",
+			wantOK:   true,
+		},
+		{
+			name:     "Object with an owner reference to a Cluster",
+			obj:      kcp,
+			wantName: "from-owner",
+			wantOK:   true,
+		},
+		{
+			name: "Object with an owner reference to a Cluster of another group",
+			obj: &clusterv1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "other.io/v1", Kind: "Cluster", Name: "other"},
+			}}},
+			wantOK: false,
+		},
+		{
+			name:   "Object not belonging to a Cluster",
+			obj:    &clusterv1.ClusterClass{},
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			name, ok := ClusterNameForObject(tt.obj)
+			g.Expect(ok).To(Equal(tt.wantOK))
+			g.Expect(name).To(Equal(tt.wantName))
+		})
+	}
+}
+
 func TestGetOwnerMachineSuccessByName(t *testing.T) {
 	g := NewWithT(t)
 
@@ -0,0 +1,474 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhookcerts implements the self-management of the serving certificates of the webhooks of the
+// Cluster API controllers, so they can run without cert-manager.
+//
+// The CA and the serving certificate are stored in a Secret shared by all the replicas of a controller; the serving
+// certificate is written to the certificate directory of the webhook server and the CA is injected into the
+// webhook configurations and CustomResourceDefinitions of the provider using the webhook Service. Both are rotated before they
+// expire; the previous CA is kept in the injected CA bundle until it expires, so the API server keeps trusting
+// replicas which did not pick up the new serving certificate yet, and the CA bundle is injected before a renewed serving
+// certificate is written, so the API server trusts the new CA before the webhook server uses it.
+package webhookcerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/certs"
+)
+
+const (
+	// CACertDataName is the key of the CA bundle in the Secret storing the webhook certificates;
+	// the first certificate is the current CA, the following ones are previous CAs which did not expire yet.
+	CACertDataName = "ca.crt"
+
+	// CAKeyDataName is the key of the private key of the current CA in the Secret storing the webhook certificates.
+	CAKeyDataName = "ca.key"
+
+	// caDuration is the lifespan of the CA.
+	caDuration = 10 * 365 * 24 * time.Hour
+
+	// resyncPeriod is the period after which the certificates are checked and the CA bundle is injected again,
+	// e.g. because the webhook configurations have been applied again by an upgrade.
+	resyncPeriod = 10 * time.Minute
+)
+
+// Options are the options of a Manager.
+type Options struct {
+	// ServiceName is the name of the Service of the webhooks.
+	ServiceName string
+
+	// ServiceNamespace is the namespace of the Service of the webhooks and of the Secret storing the certificates.
+	ServiceNamespace string
+
+	// SecretName is the name of the Secret storing the certificates.
+	SecretName string
+
+	// ProviderName is the value of the cluster.x-k8s.io/provider label of the webhook configurations and
+	// CustomResourceDefinitions of the provider; only these objects get the CA bundle injected.
+	ProviderName string
+
+	// CertDir is the directory of the webhook server the serving certificate is written to.
+	CertDir string
+
+	// CertDuration is the lifespan of the serving certificate; defaults to certs.DefaultCertDuration.
+	CertDuration time.Duration
+
+	// RenewBefore is the time before its expiry after which the CA or the serving certificate is renewed;
+	// defaults to a third of CertDuration.
+	RenewBefore time.Duration
+}
+
+// Manager manages the serving certificates of the webhooks.
+type Manager struct {
+	client  client.Client
+	options Options
+}
+
+// New returns a Manager for the given options; the client must not be backed by a cache,
+// because the certificates must exist before the webhook server, and the caches, are started.
+func New(c client.Client, options Options) *Manager {
+	if options.CertDuration == 0 {
+		options.CertDuration = certs.DefaultCertDuration
+	}
+	if options.RenewBefore == 0 {
+		options.RenewBefore = options.CertDuration / 3
+	}
+	return &Manager{client: c, options: options}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable; every replica needs the serving certificate.
+func (m *Manager) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable, ensuring the certificates are valid until the context is cancelled.
+func (m *Manager) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName("webhook-certs")
+	for {
+		next := resyncPeriod
+		renewAt, err := m.Ensure(ctx)
+		if err != nil {
+			log.Error(err, "Failed to ensure webhook certificates")
+			next = time.Minute
+		} else if d := time.Until(renewAt); d < next {
+			next = d
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(next):
+		}
+	}
+}
+
+// Ensure ensures the CA and the serving certificate exist and are not going to expire, writes the serving
+// certificate to the certificate directory and injects the CA bundle; it returns when the certificates must be renewed.
+func (m *Manager) Ensure(ctx context.Context) (time.Time, error) {
+	var s *corev1.Secret
+	// Retry if another replica created or renewed the certificates in the meantime.
+	err := retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() error {
+		var err error
+		s, err = m.reconcileSecret(ctx)
+		return err
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	caCerts, err := decodeCerts(s.Data[CACertDataName])
+	if err != nil {
+		return time.Time{}, err
+	}
+	servingCert, err := certs.DecodeCertPEM(s.Data[corev1.TLSCertKey])
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to decode serving certificate in Secret %s", client.ObjectKeyFromObject(s))
+	}
+	renewAt := servingCert.NotAfter.Add(-m.options.RenewBefore)
+	if caRenewAt := caCerts[0].NotAfter.Add(-m.options.RenewBefore); caRenewAt.Before(renewAt) {
+		renewAt = caRenewAt
+	}
+
+	// The CA bundle, holding both the current and the previous CAs, is injected before the serving certificate is
+	// written, so the API server never gets a serving certificate signed by a CA it does not trust yet.
+	if err := m.injectCABundle(ctx, s.Data[CACertDataName]); err != nil {
+		// The webhook server can't start without a serving certificate, so the first one is written anyway;
+		// otherwise the current serving certificate is kept till the CA bundle is injected.
+		if m.hasServingCert() {
+			return time.Time{}, err
+		}
+		return time.Time{}, kerrors.NewAggregate([]error{err, m.writeFiles(s)})
+	}
+	if err := m.writeFiles(s); err != nil {
+		return time.Time{}, err
+	}
+	return renewAt, nil
+}
+
+// reconcileSecret returns the Secret storing the certificates, generating or renewing them if required.
+func (m *Manager) reconcileSecret(ctx context.Context) (*corev1.Secret, error) {
+	key := client.ObjectKey{Namespace: m.options.ServiceNamespace, Name: m.options.SecretName}
+	s := &corev1.Secret{}
+	if err := m.client.Get(ctx, key, s); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "failed to get Secret %s", key)
+		}
+		s = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{},
+		}
+		if err := m.renew(s, time.Now()); err != nil {
+			return nil, err
+		}
+		if err := m.client.Create(ctx, s); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				return nil, err
+			}
+			return nil, errors.Wrapf(err, "failed to create Secret %s", key)
+		}
+		ctrl.LoggerFrom(ctx).Info("Generated webhook certificates", "Secret", klog.KObj(s))
+		return s, nil
+	}
+
+	original := s.DeepCopy()
+	if s.Data == nil {
+		s.Data = map[string][]byte{}
+	}
+	if err := m.renew(s, time.Now()); err != nil {
+		return nil, err
+	}
+	if equalData(original.Data, s.Data) {
+		return s, nil
+	}
+	if err := m.client.Patch(ctx, s, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+		if apierrors.IsConflict(err) {
+			return nil, err
+		}
+		return nil, errors.Wrapf(err, "failed to patch Secret %s", key)
+	}
+	ctrl.LoggerFrom(ctx).Info("Renewed webhook certificates", "Secret", klog.KObj(s))
+	return s, nil
+}
+
+// renew renews, in the data of the Secret, the CA and the serving certificate if they are missing, are not valid or
+// are going to expire; expired CAs are removed from the CA bundle.
+func (m *Manager) renew(s *corev1.Secret, now time.Time) error {
+	caCerts, _ := decodeCerts(s.Data[CACertDataName])
+	caKey, _ := certs.DecodePrivateKeyPEM(s.Data[CAKeyDataName])
+	if len(caCerts) == 0 || caKey == nil || now.After(caCerts[0].NotAfter.Add(-m.options.RenewBefore)) {
+		caCert, key, err := newCA(m.options.ServiceName, now)
+		if err != nil {
+			return err
+		}
+		caCerts = append([]*x509.Certificate{caCert}, caCerts...)
+		caKey = key
+		s.Data[CAKeyDataName] = certs.EncodePrivateKeyPEM(key)
+	}
+
+	bundle := certs.EncodeCertPEM(caCerts[0])
+	for _, c := range caCerts[1:] {
+		if now.Before(c.NotAfter) {
+			bundle = append(bundle, certs.EncodeCertPEM(c)...)
+		}
+	}
+	s.Data[CACertDataName] = bundle
+
+	servingCert, err := certs.DecodeCertPEM(s.Data[corev1.TLSCertKey])
+	if err == nil && servingCert != nil && m.isValid(servingCert, caCerts[0], now) {
+		return nil
+	}
+	key, err := certs.NewPrivateKey()
+	if err != nil {
+		return errors.Wrap(err, "failed to generate serving certificate private key")
+	}
+	cfg := certs.Config{
+		CommonName: fmt.Sprintf("%s.%s.svc", m.options.ServiceName, m.options.ServiceNamespace),
+		AltNames:   certs.AltNames{DNSNames: m.dnsNames()},
+		Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		Duration:   m.options.CertDuration,
+	}
+	caSigner, ok := caKey.(*rsa.PrivateKey)
+	if !ok {
+		return errors.New("CA private key is not an RSA key")
+	}
+	servingCert, err = cfg.NewSignedCert(key, caCerts[0], caSigner)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate serving certificate")
+	}
+	s.Data[corev1.TLSCertKey] = certs.EncodeCertPEM(servingCert)
+	s.Data[corev1.TLSPrivateKeyKey] = certs.EncodePrivateKeyPEM(key)
+	return nil
+}
+
+// isValid returns true if the serving certificate is signed by the CA, is valid for the Service and is not going to expire.
+func (m *Manager) isValid(servingCert, caCert *x509.Certificate, now time.Time) bool {
+	if err := servingCert.CheckSignatureFrom(caCert); err != nil {
+		return false
+	}
+	for _, name := range m.dnsNames() {
+		if servingCert.VerifyHostname(name) != nil {
+			return false
+		}
+	}
+	return now.Before(servingCert.NotAfter.Add(-m.options.RenewBefore))
+}
+
+// dnsNames returns the DNS names of the Service of the webhooks.
+func (m *Manager) dnsNames() []string {
+	return []string{
+		m.options.ServiceName,
+		fmt.Sprintf("%s.%s", m.options.ServiceName, m.options.ServiceNamespace),
+		fmt.Sprintf("%s.%s.svc", m.options.ServiceName, m.options.ServiceNamespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", m.options.ServiceName, m.options.ServiceNamespace),
+	}
+}
+
+// writeFiles writes the serving certificate to the certificate directory; the files are replaced atomically,
+// so the certificate watcher of the webhook server never reads a partially written certificate.
+func (m *Manager) writeFiles(s *corev1.Secret) error {
+	if err := os.MkdirAll(m.options.CertDir, 0o700); err != nil {
+		return errors.Wrapf(err, "failed to create webhook certificate directory %s", m.options.CertDir)
+	}
+	for _, name := range []string{corev1.TLSPrivateKeyKey, corev1.TLSCertKey} {
+		path := filepath.Join(m.options.CertDir, name)
+		if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, s.Data[name]) {
+			continue
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, s.Data[name], 0o600); err != nil {
+			return errors.Wrapf(err, "failed to write %s", tmp)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return errors.Wrapf(err, "failed to write %s", path)
+		}
+	}
+	return nil
+}
+
+// hasServingCert returns true if a serving certificate has already been written to the certificate directory.
+func (m *Manager) hasServingCert() bool {
+	_, err := os.Stat(filepath.Join(m.options.CertDir, corev1.TLSCertKey))
+	return err == nil
+}
+
+// injectCABundle injects the CA bundle into the webhooks and the conversion webhooks of the provider using the Service.
+func (m *Manager) injectCABundle(ctx context.Context, caBundle []byte) error {
+	errs := []error{}
+	providerLabels := client.MatchingLabels{clusterv1.ProviderNameLabel: m.options.ProviderName}
+
+	mutatingWebhookConfigurations := &admissionregistrationv1.MutatingWebhookConfigurationList{}
+	if err := m.client.List(ctx, mutatingWebhookConfigurations, providerLabels); err != nil {
+		return errors.Wrap(err, "failed to list MutatingWebhookConfigurations")
+	}
+	for i := range mutatingWebhookConfigurations.Items {
+		c := &mutatingWebhookConfigurations.Items[i]
+		original := c.DeepCopy()
+		for j := range c.Webhooks {
+			m.inject(&c.Webhooks[j].ClientConfig, caBundle)
+		}
+		errs = append(errs, m.patch(ctx, original, c))
+	}
+
+	validatingWebhookConfigurations := &admissionregistrationv1.ValidatingWebhookConfigurationList{}
+	if err := m.client.List(ctx, validatingWebhookConfigurations, providerLabels); err != nil {
+		return errors.Wrap(err, "failed to list ValidatingWebhookConfigurations")
+	}
+	for i := range validatingWebhookConfigurations.Items {
+		c := &validatingWebhookConfigurations.Items[i]
+		original := c.DeepCopy()
+		for j := range c.Webhooks {
+			m.inject(&c.Webhooks[j].ClientConfig, caBundle)
+		}
+		errs = append(errs, m.patch(ctx, original, c))
+	}
+
+	crds := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := m.client.List(ctx, crds, providerLabels); err != nil {
+		return errors.Wrap(err, "failed to list CustomResourceDefinitions")
+	}
+	for i := range crds.Items {
+		crd := &crds.Items[i]
+		if crd.Spec.Conversion == nil || crd.Spec.Conversion.Webhook == nil || crd.Spec.Conversion.Webhook.ClientConfig == nil {
+			continue
+		}
+		original := crd.DeepCopy()
+		clientConfig := crd.Spec.Conversion.Webhook.ClientConfig
+		if service := clientConfig.Service; service != nil && service.Name == m.options.ServiceName && service.Namespace == m.options.ServiceNamespace {
+			clientConfig.CABundle = caBundle
+		}
+		errs = append(errs, m.patch(ctx, original, crd))
+	}
+
+	return kerrors.NewAggregate(errs)
+}
+
+func (m *Manager) inject(clientConfig *admissionregistrationv1.WebhookClientConfig, caBundle []byte) {
+	if service := clientConfig.Service; service != nil && service.Name == m.options.ServiceName && service.Namespace == m.options.ServiceNamespace {
+		clientConfig.CABundle = caBundle
+	}
+}
+
+// patch patches the object if it has been changed; the patch fails if the object has been changed in the meantime,
+// e.g. by an upgrade applying it again, and the CA bundle is then injected at the next resync.
+func (m *Manager) patch(ctx context.Context, original, obj client.Object) error {
+	data, err := client.MergeFrom(original).Data(obj)
+	if err != nil {
+		return errors.Wrapf(err, "failed to compute patch for %s", obj.GetName())
+	}
+	if string(data) == "{}" {
+		return nil
+	}
+	if err := m.client.Patch(ctx, obj, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+		return errors.Wrapf(err, "failed to inject CA bundle into %s", obj.GetName())
+	}
+	return nil
+}
+
+// newCA returns a new self-signed CA.
+func newCA(name string, now time.Time) (*x509.Certificate, *rsa.PrivateKey, error) {
+	key, err := certs.NewPrivateKey()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate CA private key")
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).SetInt64(math.MaxInt64))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate CA serial number")
+	}
+	tmpl := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("%s-ca", name)},
+		NotBefore:             now.Add(-5 * time.Minute).UTC(),
+		NotAfter:              now.Add(caDuration).UTC(),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	b, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, key.Public(), key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to create CA certificate")
+	}
+	c, err := x509.ParseCertificate(b)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse CA certificate")
+	}
+	return c, key, nil
+}
+
+// decodeCerts decodes a PEM bundle of certificates.
+func decodeCerts(data []byte) ([]*x509.Certificate, error) {
+	var result []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse CA bundle")
+		}
+		result = append(result, c)
+	}
+	if len(result) == 0 {
+		return nil, errors.New("CA bundle is empty")
+	}
+	return result, nil
+}
+
+func equalData(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if !bytes.Equal(v, b[k]) {
+			return false
+		}
+	}
+	return true
+}
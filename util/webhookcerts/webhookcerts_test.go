@@ -0,0 +1,230 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookcerts
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/certs"
+)
+
+func TestEnsure(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = admissionregistrationv1.AddToScheme(scheme)
+	_ = apiextensionsv1.AddToScheme(scheme)
+
+	serviceRef := func(name string) *admissionregistrationv1.ServiceReference {
+		return &admissionregistrationv1.ServiceReference{Name: name, Namespace: "capi-system"}
+	}
+	providerLabels := map[string]string{clusterv1.ProviderNameLabel: "cluster-api"}
+	validating := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "capi-validating-webhook-configuration", Labels: providerLabels},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{Name: "validation.cluster.cluster.x-k8s.io", ClientConfig: admissionregistrationv1.WebhookClientConfig{Service: serviceRef("capi-webhook-service")}},
+			{Name: "validation.other.example.com", ClientConfig: admissionregistrationv1.WebhookClientConfig{Service: serviceRef("other-webhook-service")}},
+		},
+	}
+	mutating := &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "capi-mutating-webhook-configuration", Labels: providerLabels},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{
+			{Name: "default.cluster.cluster.x-k8s.io", ClientConfig: admissionregistrationv1.WebhookClientConfig{Service: serviceRef("capi-webhook-service")}},
+		},
+	}
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "clusters.cluster.x-k8s.io", Labels: providerLabels},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Conversion: &apiextensionsv1.CustomResourceConversion{
+				Strategy: apiextensionsv1.WebhookConverter,
+				Webhook: &apiextensionsv1.WebhookConversion{
+					ClientConfig: &apiextensionsv1.WebhookClientConfig{
+						Service: &apiextensionsv1.ServiceReference{Name: "capi-webhook-service", Namespace: "capi-system"},
+					},
+				},
+			},
+		},
+	}
+	otherProvider := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "other-validating-webhook-configuration", Labels: map[string]string{clusterv1.ProviderNameLabel: "other"}},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{Name: "validation.other.example.com", ClientConfig: admissionregistrationv1.WebhookClientConfig{Service: serviceRef("capi-webhook-service")}},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(validating, mutating, crd, otherProvider).Build()
+
+	certDir := t.TempDir()
+	m := New(c, Options{
+		ServiceName:      "capi-webhook-service",
+		ServiceNamespace: "capi-system",
+		SecretName:       "capi-webhook-service-cert",
+		ProviderName:     "cluster-api",
+		CertDir:          certDir,
+	})
+
+	renewAt, err := m.Ensure(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(renewAt).To(BeTemporally("~", time.Now().Add(certs.DefaultCertDuration*2/3), time.Hour))
+
+	s := &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "capi-system", Name: "capi-webhook-service-cert"}, s)).To(Succeed())
+	caBundle := s.Data[CACertDataName]
+
+	// The serving certificate is written to the certificate directory and is valid for the Service.
+	certPEM, err := os.ReadFile(filepath.Join(certDir, corev1.TLSCertKey))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(certPEM).To(Equal(s.Data[corev1.TLSCertKey]))
+	g.Expect(filepath.Join(certDir, corev1.TLSPrivateKeyKey)).To(BeAnExistingFile())
+	servingCert, err := certs.DecodeCertPEM(certPEM)
+	g.Expect(err).ToNot(HaveOccurred())
+	roots := x509.NewCertPool()
+	g.Expect(roots.AppendCertsFromPEM(caBundle)).To(BeTrue())
+	_, err = servingCert.Verify(x509.VerifyOptions{DNSName: "capi-webhook-service.capi-system.svc", Roots: roots})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// The CA bundle is only injected into the webhooks using the Service.
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(validating), validating)).To(Succeed())
+	g.Expect(validating.Webhooks[0].ClientConfig.CABundle).To(Equal(caBundle))
+	g.Expect(validating.Webhooks[1].ClientConfig.CABundle).To(BeEmpty())
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(mutating), mutating)).To(Succeed())
+	g.Expect(mutating.Webhooks[0].ClientConfig.CABundle).To(Equal(caBundle))
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(crd), crd)).To(Succeed())
+	g.Expect(crd.Spec.Conversion.Webhook.ClientConfig.CABundle).To(Equal(caBundle))
+
+	// The CA bundle is not injected into the objects of other providers.
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(otherProvider), otherProvider)).To(Succeed())
+	g.Expect(otherProvider.Webhooks[0].ClientConfig.CABundle).To(BeEmpty())
+
+	// The certificates are not renewed if they are not going to expire.
+	_, err = m.Ensure(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	renewed := &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(s), renewed)).To(Succeed())
+	g.Expect(renewed.Data).To(Equal(s.Data))
+}
+
+func TestEnsureInjectsCABundleBeforeWritingServingCert(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = admissionregistrationv1.AddToScheme(scheme)
+	_ = apiextensionsv1.AddToScheme(scheme)
+
+	validating := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "capi-validating-webhook-configuration", Labels: map[string]string{clusterv1.ProviderNameLabel: "cluster-api"}},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{Name: "validation.cluster.cluster.x-k8s.io", ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{Name: "capi-webhook-service", Namespace: "capi-system"},
+			}},
+		},
+	}
+	failInjection := true
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(validating).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				if _, ok := obj.(*admissionregistrationv1.ValidatingWebhookConfiguration); ok && failInjection {
+					return errors.New("injection failed")
+				}
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+		}).Build()
+
+	certDir := t.TempDir()
+	m := New(c, Options{
+		ServiceName:      "capi-webhook-service",
+		ServiceNamespace: "capi-system",
+		SecretName:       "capi-webhook-service-cert",
+		ProviderName:     "cluster-api",
+		CertDir:          certDir,
+	})
+	certPath := filepath.Join(certDir, corev1.TLSCertKey)
+
+	// The first serving certificate is written even if the CA bundle can't be injected, so the webhook server can start.
+	_, err := m.Ensure(ctx)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(certPath).To(BeAnExistingFile())
+	initialCert, err := os.ReadFile(certPath)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// A renewed serving certificate is not written till the CA bundle including its CA is injected.
+	s := &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "capi-system", Name: "capi-webhook-service-cert"}, s)).To(Succeed())
+	delete(s.Data, CACertDataName)
+	g.Expect(c.Update(ctx, s)).To(Succeed())
+
+	_, err = m.Ensure(ctx)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(os.ReadFile(certPath)).To(Equal(initialCert))
+
+	failInjection = false
+	_, err = m.Ensure(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(s), s)).To(Succeed())
+	g.Expect(os.ReadFile(certPath)).To(Equal(s.Data[corev1.TLSCertKey]))
+	g.Expect(s.Data[corev1.TLSCertKey]).ToNot(Equal(initialCert))
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(validating), validating)).To(Succeed())
+	g.Expect(validating.Webhooks[0].ClientConfig.CABundle).To(Equal(s.Data[CACertDataName]))
+}
+
+func TestRenew(t *testing.T) {
+	g := NewWithT(t)
+
+	m := New(nil, Options{ServiceName: "capi-webhook-service", ServiceNamespace: "capi-system"})
+	s := &corev1.Secret{Data: map[string][]byte{}}
+	g.Expect(m.renew(s, time.Now())).To(Succeed())
+	initial := s.DeepCopy()
+
+	// The serving certificate is renewed before it expires; the CA is kept.
+	g.Expect(m.renew(s, time.Now().Add(certs.DefaultCertDuration*3/4))).To(Succeed())
+	g.Expect(s.Data[CACertDataName]).To(Equal(initial.Data[CACertDataName]))
+	g.Expect(s.Data[corev1.TLSCertKey]).ToNot(Equal(initial.Data[corev1.TLSCertKey]))
+
+	// The CA is renewed before it expires; the previous CA is kept in the bundle until it expires.
+	g.Expect(m.renew(s, time.Now().Add(caDuration-certs.DefaultCertDuration/6))).To(Succeed())
+	caCerts, err := decodeCerts(s.Data[CACertDataName])
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(caCerts).To(HaveLen(2))
+	servingCert, err := certs.DecodeCertPEM(s.Data[corev1.TLSCertKey])
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(servingCert.CheckSignatureFrom(caCerts[0])).To(Succeed())
+
+	g.Expect(m.renew(s, time.Now().Add(caDuration+time.Hour))).To(Succeed())
+	caCerts, err = decodeCerts(s.Data[CACertDataName])
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(caCerts).To(HaveLen(1))
+}
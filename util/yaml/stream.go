@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yaml
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	apiyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/cluster-api/util"
+)
+
+// TransformFunc transforms a single object while streaming a multi-document YAML.
+// Returning a nil object drops the document from the output.
+type TransformFunc func(u *unstructured.Unstructured) (*unstructured.Unstructured, error)
+
+// ReadUnstructured reads a multi-document YAML from r one document at a time and invokes fn
+// for each of them, without loading the entire stream in memory.
+// Empty documents are skipped; if fn returns an error reading stops and the error is returned.
+func ReadUnstructured(r io.Reader, fn func(u *unstructured.Unstructured) error) error {
+	reader := apiyaml.NewYAMLReader(bufio.NewReader(r))
+	count := 1
+	for {
+		// Read one YAML document at a time, until io.EOF is returned
+		b, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return errors.Wrapf(err, "failed to read yaml")
+		}
+		if len(b) == 0 {
+			break
+		}
+
+		var m map[string]interface{}
+		if err := yaml.Unmarshal(b, &m); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal the %s yaml document: %q", util.Ordinalize(count), string(b))
+		}
+
+		u := &unstructured.Unstructured{}
+		u.SetUnstructuredContent(m)
+
+		// Ignore empty objects.
+		// Empty objects are generated if there are weird things in manifest files like e.g. two --- in a row without a yaml doc in the middle
+		if u.Object == nil {
+			continue
+		}
+
+		if err := fn(u); err != nil {
+			return err
+		}
+		count++
+	}
+	return nil
+}
+
+// Writer writes Unstructured objects to an io.Writer as a multi-document YAML,
+// one document at a time.
+type Writer struct {
+	w     io.Writer
+	count int
+}
+
+// NewWriter returns a Writer writing to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write serializes the object to YAML and writes it to the underlying io.Writer,
+// preceded by a document separator if it is not the first document.
+func (w *Writer) Write(u *unstructured.Unstructured) error {
+	content, err := yaml.Marshal(u.UnstructuredContent())
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal yaml for %s, %s/%s", u.GroupVersionKind(), u.GetNamespace(), u.GetName())
+	}
+	if w.count > 0 {
+		if _, err := io.WriteString(w.w, "---\n"); err != nil {
+			return errors.Wrap(err, "failed to write yaml separator")
+		}
+	}
+	if _, err := w.w.Write(content); err != nil {
+		return errors.Wrapf(err, "failed to write yaml for %s, %s/%s", u.GroupVersionKind(), u.GetNamespace(), u.GetName())
+	}
+	w.count++
+	return nil
+}
+
+// Transform reads a multi-document YAML from r, applies the given transformations to each document
+// in order, and writes the result to w as soon as each document is processed.
+func Transform(r io.Reader, w io.Writer, transforms ...TransformFunc) error {
+	writer := NewWriter(w)
+	return ReadUnstructured(r, func(u *unstructured.Unstructured) error {
+		for _, transform := range transforms {
+			transformed, err := transform(u)
+			if err != nil {
+				return errors.Wrapf(err, "failed to transform %s, %s/%s", u.GroupVersionKind(), u.GetNamespace(), u.GetName())
+			}
+			if transformed == nil {
+				return nil
+			}
+			u = transformed
+		}
+		return writer.Write(u)
+	})
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yaml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestReadUnstructured(t *testing.T) {
+	t.Run("invokes the callback for each document", func(t *testing.T) {
+		g := NewWithT(t)
+
+		var kinds []string
+		err := ReadUnstructured(strings.NewReader("---\n"+
+			"apiVersion: v1\n"+
+			"kind: ConfigMap\n"+
+			"---\n"+
+			"---\n"+
+			"apiVersion: v1\n"+
+			"kind: Secret\n"), func(u *unstructured.Unstructured) error {
+			kinds = append(kinds, u.GetKind())
+			return nil
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(kinds).To(Equal([]string{"ConfigMap", "Secret"}))
+	})
+	t.Run("stops at the first error returned by the callback", func(t *testing.T) {
+		g := NewWithT(t)
+
+		calls := 0
+		err := ReadUnstructured(strings.NewReader("apiVersion: v1\n"+
+			"kind: ConfigMap\n"+
+			"---\n"+
+			"apiVersion: v1\n"+
+			"kind: Secret\n"), func(*unstructured.Unstructured) error {
+			calls++
+			return errors.New("callback error")
+		})
+		g.Expect(err).To(MatchError("callback error"))
+		g.Expect(calls).To(Equal(1))
+	})
+	t.Run("returns error for invalid yaml", func(t *testing.T) {
+		g := NewWithT(t)
+
+		err := ReadUnstructured(strings.NewReader("apiVersion: v1\n"+
+			"kind: ConfigMap\n"+
+			"---\n"+
+			"apiVersion: v1\n"+
+			"foobar\n"+
+			"kind: Secret\n"), func(*unstructured.Unstructured) error { return nil })
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("failed to unmarshal the 2nd yaml document"))
+	})
+}
+
+func TestWriter(t *testing.T) {
+	g := NewWithT(t)
+
+	out := &bytes.Buffer{}
+	w := NewWriter(out)
+	g.Expect(w.Write(&unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"}})).To(Succeed())
+	g.Expect(w.Write(&unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "Secret"}})).To(Succeed())
+	g.Expect(out.String()).To(Equal("apiVersion: v1\n" +
+		"kind: ConfigMap\n" +
+		"---\n" +
+		"apiVersion: v1\n" +
+		"kind: Secret\n"))
+}
+
+func TestTransform(t *testing.T) {
+	in := "apiVersion: v1\n" +
+		"kind: ConfigMap\n" +
+		"metadata:\n" +
+		"  name: foo\n" +
+		"---\n" +
+		"apiVersion: v1\n" +
+		"kind: Secret\n" +
+		"metadata:\n" +
+		"  name: bar\n"
+
+	t.Run("applies transformations in order", func(t *testing.T) {
+		g := NewWithT(t)
+
+		out := &bytes.Buffer{}
+		err := Transform(strings.NewReader(in), out,
+			func(u *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+				u.SetNamespace("ns")
+				return u, nil
+			},
+			func(u *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+				u.SetName(u.GetName() + "-" + u.GetNamespace())
+				return u, nil
+			},
+		)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		objs, err := ToUnstructured(out.Bytes())
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(objs).To(HaveLen(2))
+		g.Expect(objs[0].GetName()).To(Equal("foo-ns"))
+		g.Expect(objs[1].GetName()).To(Equal("bar-ns"))
+	})
+	t.Run("drops documents when a transformation returns nil", func(t *testing.T) {
+		g := NewWithT(t)
+
+		out := &bytes.Buffer{}
+		err := Transform(strings.NewReader(in), out, func(u *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			if u.GetKind() == "Secret" {
+				return nil, nil
+			}
+			return u, nil
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		objs, err := ToUnstructured(out.Bytes())
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(objs).To(HaveLen(1))
+		g.Expect(objs[0].GetKind()).To(Equal("ConfigMap"))
+	})
+	t.Run("returns transformation errors", func(t *testing.T) {
+		g := NewWithT(t)
+
+		err := Transform(strings.NewReader(in), &bytes.Buffer{}, func(*unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			return nil, errors.New("transform error")
+		})
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("failed to transform /v1, Kind=ConfigMap, /foo: transform error"))
+	})
+}
@@ -36,7 +36,6 @@ import (
 	"sigs.k8s.io/yaml"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
-	"sigs.k8s.io/cluster-api/util"
 )
 
 // ExtractClusterReferences returns the references in a Cluster object.
@@ -197,40 +196,12 @@ func NewYAMLDecoder(r io.ReadCloser) streaming.Decoder {
 // ToUnstructured takes a YAML and converts it to a list of Unstructured objects.
 func ToUnstructured(rawyaml []byte) ([]unstructured.Unstructured, error) {
 	var ret []unstructured.Unstructured
-
-	reader := apiyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(rawyaml)))
-	count := 1
-	for {
-		// Read one YAML document at a time, until io.EOF is returned
-		b, err := reader.Read()
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
-			return nil, errors.Wrapf(err, "failed to read yaml")
-		}
-		if len(b) == 0 {
-			break
-		}
-
-		var m map[string]interface{}
-		if err := yaml.Unmarshal(b, &m); err != nil {
-			return nil, errors.Wrapf(err, "failed to unmarshal the %s yaml document: %q", util.Ordinalize(count), string(b))
-		}
-
-		var u unstructured.Unstructured
-		u.SetUnstructuredContent(m)
-
-		// Ignore empty objects.
-		// Empty objects are generated if there are weird things in manifest files like e.g. two --- in a row without a yaml doc in the middle
-		if u.Object == nil {
-			continue
-		}
-
-		ret = append(ret, u)
-		count++
+	if err := ReadUnstructured(bytes.NewReader(rawyaml), func(u *unstructured.Unstructured) error {
+		ret = append(ret, *u)
+		return nil
+	}); err != nil {
+		return nil, err
 	}
-
 	return ret, nil
 }
 